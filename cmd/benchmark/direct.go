@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/tadp"
+)
+
+// directEvaluator evaluates transactions in-process, with the rule engine,
+// typology engine and decision processor the server builds, so the engines
+// can be measured without network or server overhead. There is no velocity
+// service, so velocity_count reads as zero, and nothing is stored.
+type directEvaluator struct {
+	engine     *rules.Engine
+	typologies *rules.TypologyEngine
+	processor  *tadp.Processor
+	mode       domain.EvaluationMode
+}
+
+// newDirectEvaluator loads the rules file and, if set, the typologies file,
+// both in the format of configs/rules and configs/typologies, as global
+// configuration. With typologies it evaluates in compliance mode.
+func newDirectEvaluator(rulesPath, typologiesPath string, workers int) (*directEvaluator, error) {
+	spec := &state.Spec{}
+	if err := readSpecFile(rulesPath, spec); err != nil {
+		return nil, err
+	}
+	if typologiesPath != "" {
+		if err := readSpecFile(typologiesPath, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	engine, err := rules.NewEngine(nil, max(workers, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rule engine: %w", err)
+	}
+	typologies := rules.NewTypologyEngine()
+
+	// The state manager validates the files and loads both engines, through
+	// a throwaway in-memory repository
+	ctx := context.Background()
+	repo, err := repository.New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+	manager := state.NewManager(repo, engine, typologies)
+	plan, err := manager.Plan(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.Apply(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	mode := domain.ModeDetection
+	if typologies.TypologyCount() > 0 {
+		mode = domain.ModeCompliance
+	}
+	scoring := domain.DefaultConfig().Scoring
+	processor := tadp.NewProcessor()
+	processor.AlertThreshold = scoring.AlertThreshold
+	processor.UseWeightedScoring = scoring.WeightedScoring
+	processor.CriticalFail = scoring.CriticalFail
+	processor.Mode = string(mode)
+
+	return &directEvaluator{engine: engine, typologies: typologies, processor: processor, mode: mode}, nil
+}
+
+// readSpecFile adds the rules and typologies of a JSON file to spec.
+// Underscore-prefixed keys such as "_description" are ignored.
+func readSpecFile(path string, spec *state.Spec) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file state.Spec
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	spec.Rules = append(spec.Rules, file.Rules...)
+	spec.Typologies = append(spec.Typologies, file.Typologies...)
+	return nil
+}
+
+// evaluate returns an evaluateFunc for the tenant. Transactions carry the
+// same fields and metadata as over HTTP.
+func (d *directEvaluator) evaluate(tenantID string) evaluateFunc {
+	return func(tx PaySimTransaction) (*EvaluateResponse, error) {
+		start := time.Now()
+		txID := uuid.New().String()
+
+		ctx, degradations := domain.WithDegradations(context.Background())
+		ctx, velocity := domain.WithVelocitySnapshots(ctx)
+		ruleResults, err := d.engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:          tenantID,
+			TxID:              txID,
+			Type:              tx.Type,
+			DebtorID:          tx.NameOrig,
+			CreditorID:        tx.NameDest,
+			DebtorAccountID:   tx.NameOrig + "-acc",
+			CreditorAccountID: tx.NameDest + "-acc",
+			Amount:            domain.DecimalFromFloat(tx.Amount),
+			Currency:          "USD",
+			Timestamp:         start,
+			// Numbers as JSON decodes them on the HTTP path
+			AdditionalData: map[string]any{
+				"old_balance": tx.OldBalanceOrg,
+				"new_balance": tx.NewBalanceOrig,
+				"step":        float64(tx.Step),
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("rule evaluation failed: %w", err)
+		}
+
+		var typologyResults []domain.TypologyResult
+		if d.mode == domain.ModeCompliance {
+			typologyResults = d.typologies.EvaluateTypologies(tenantID, ruleResults)
+		}
+
+		evaluation := d.processor.Process(ctx, &tadp.DecisionInput{
+			TenantID:        tenantID,
+			TxID:            txID,
+			TraceID:         txID,
+			RuleResults:     ruleResults,
+			TypologyResults: typologyResults,
+			StartTime:       start,
+			Degradations:    degradations.List(),
+			Velocity:        velocity.List(),
+		})
+		return &EvaluateResponse{
+			EvaluationID: evaluation.ID,
+			Status:       string(evaluation.Status),
+			Score:        evaluation.Score,
+			Reasons:      tadp.GetReasons(evaluation),
+		}, nil
+	}
+}
@@ -1,13 +1,17 @@
 // Benchmark tool for testing Osprey against PaySim fraud data.
 //
 // Usage:
-//   go run cmd/benchmark/main.go -csv /path/to/paysim.csv -url http://localhost:8080
+//   go run ./cmd/benchmark -csv /path/to/paysim.csv -url http://localhost:8080
+//   go run ./cmd/benchmark -csv /path/to/paysim.csv -direct -rules configs/rules/paysim-rules.json
 //
 // This tool:
 //   1. Reads PaySim transaction data (with fraud labels)
-//   2. Sends each transaction to Osprey for evaluation
+//   2. Sends each transaction to Osprey for evaluation, or with -direct
+//      evaluates it in-process, without a server or HTTP
 //   3. Compares Osprey's verdict (ALRT/NALT) with actual fraud labels
 //   4. Calculates precision, recall, F1-score, and confusion matrix
+//   5. Reports latency percentiles and precision/recall at other alert thresholds
+//   6. Optionally writes the results to a JSON or CSV report (-report)
 package main
 
 import (
@@ -80,7 +84,15 @@ type Metrics struct {
 	TotalNonFraud  int64
 	TotalErrors    int64
 
-	ProcessingTimeMs int64
+	mu        sync.Mutex
+	Latencies []time.Duration // Every request's, including failed ones
+	Scores    []ScoredResult  // Every evaluated transaction's score and label
+}
+
+// ScoredResult is an evaluated transaction's score and fraud label.
+type ScoredResult struct {
+	Score   float64
+	IsFraud bool
 }
 
 func main() {
@@ -93,6 +105,12 @@ func main() {
 	fraudOnly := flag.Bool("fraud-only", false, "Only test fraud transactions")
 	sampleRate := flag.Float64("sample", 1.0, "Sample rate for non-fraud (0.0-1.0)")
 	verbose := flag.Bool("verbose", false, "Print each transaction result")
+	thresholdList := flag.String("thresholds", "0.1,0.2,0.3,0.4,0.5,0.6,0.7,0.8,0.9", "Comma-separated alert thresholds to sweep")
+	reportPath := flag.String("report", "", "Write the results to this file: .csv for CSV, otherwise JSON")
+	label := flag.String("label", "", "Name of the rule configuration, recorded in the report")
+	direct := flag.Bool("direct", false, "Evaluate in-process instead of over HTTP; no server needed")
+	rulesPath := flag.String("rules", "configs/rules/paysim-rules.json", "Rules file for -direct")
+	typologiesPath := flag.String("typologies", "", "Typologies file for -direct; enables compliance mode")
 	flag.Parse()
 
 	if *csvPath == "" {
@@ -102,11 +120,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	thresholds, err := parseThresholds(*thresholdList)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("╔═══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║          OSPREY BENCHMARK - PaySim Fraud Detection            ║")
 	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
 	fmt.Printf("\nCSV File:    %s\n", *csvPath)
-	fmt.Printf("Osprey URL:  %s\n", *baseURL)
+	if *direct {
+		fmt.Printf("Mode:        direct (%s)\n", *rulesPath)
+	} else {
+		fmt.Printf("Osprey URL:  %s\n", *baseURL)
+	}
 	fmt.Printf("Tenant ID:   %s\n", *tenantID)
 	fmt.Printf("Workers:     %d\n", *workers)
 	fmt.Printf("Limit:       %d\n", *limit)
@@ -114,14 +142,26 @@ func main() {
 	fmt.Printf("Sample Rate: %.2f\n", *sampleRate)
 	fmt.Println()
 
-	// Check Osprey is running
-	if err := checkHealth(*baseURL); err != nil {
-		fmt.Printf("ERROR: Osprey not reachable at %s: %v\n", *baseURL, err)
-		fmt.Println("\nMake sure Osprey is running:")
-		fmt.Println("  cd osprey && go run cmd/osprey/main.go")
-		os.Exit(1)
+	var evaluate evaluateFunc
+	if *direct {
+		evaluator, err := newDirectEvaluator(*rulesPath, *typologiesPath, *workers)
+		if err != nil {
+			fmt.Printf("ERROR: Failed to load rules: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Loaded %d rules and %d typologies in-process\n", evaluator.engine.RulesCount(), evaluator.typologies.TypologyCount())
+		evaluate = evaluator.evaluate(*tenantID)
+	} else {
+		// Check Osprey is running
+		if err := checkHealth(*baseURL); err != nil {
+			fmt.Printf("ERROR: Osprey not reachable at %s: %v\n", *baseURL, err)
+			fmt.Println("\nMake sure Osprey is running, or use -direct:")
+			fmt.Println("  cd osprey && go run cmd/osprey/main.go")
+			os.Exit(1)
+		}
+		fmt.Println("✓ Osprey is healthy")
+		evaluate = httpEvaluator(*baseURL, *tenantID)
 	}
-	fmt.Println("✓ Osprey is healthy")
 
 	// Read PaySim data
 	fmt.Printf("\nReading PaySim data from %s...\n", *csvPath)
@@ -145,11 +185,21 @@ func main() {
 	// Run benchmark
 	fmt.Printf("\nRunning benchmark with %d workers...\n", *workers)
 	startTime := time.Now()
-	metrics := runBenchmark(transactions, *baseURL, *tenantID, *workers, *verbose)
+	metrics := runBenchmark(transactions, evaluate, *workers, *verbose)
 	duration := time.Since(startTime)
 
 	// Print results
-	printResults(metrics, duration)
+	report := buildReport(metrics, duration, thresholds)
+	report.Label = *label
+	printResults(metrics, report)
+
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, report); err != nil {
+			fmt.Printf("ERROR: Failed to write report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Report written to %s\n", *reportPath)
+	}
 }
 
 func checkHealth(baseURL string) error {
@@ -244,7 +294,18 @@ func readPaySimCSV(path string, limit int, fraudOnly bool, sampleRate float64) (
 	return transactions, nil
 }
 
-func runBenchmark(transactions []PaySimTransaction, baseURL, tenantID string, numWorkers int, verbose bool) *Metrics {
+// evaluateFunc evaluates one transaction. It is called from several workers.
+type evaluateFunc func(tx PaySimTransaction) (*EvaluateResponse, error)
+
+// httpEvaluator evaluates transactions with POST /evaluate.
+func httpEvaluator(baseURL, tenantID string) evaluateFunc {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(tx PaySimTransaction) (*EvaluateResponse, error) {
+		return evaluateTransaction(client, baseURL, tenantID, tx)
+	}
+}
+
+func runBenchmark(transactions []PaySimTransaction, evaluate evaluateFunc, numWorkers int, verbose bool) *Metrics {
 	metrics := &Metrics{}
 
 	// Create work channel
@@ -256,14 +317,20 @@ func runBenchmark(transactions []PaySimTransaction, baseURL, tenantID string, nu
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			client := &http.Client{Timeout: 10 * time.Second}
+			var latencies []time.Duration
+			var scores []ScoredResult
+			defer func() {
+				metrics.mu.Lock()
+				metrics.Latencies = append(metrics.Latencies, latencies...)
+				metrics.Scores = append(metrics.Scores, scores...)
+				metrics.mu.Unlock()
+			}()
 
 			for tx := range work {
 				start := time.Now()
-				result, err := evaluateTransaction(client, baseURL, tenantID, tx)
-				elapsed := time.Since(start).Milliseconds()
+				result, err := evaluate(tx)
+				latencies = append(latencies, time.Since(start))
 
-				atomic.AddInt64(&metrics.ProcessingTimeMs, elapsed)
 				atomic.AddInt64(&metrics.TotalProcessed, 1)
 
 				if err != nil {
@@ -281,6 +348,8 @@ func runBenchmark(transactions []PaySimTransaction, baseURL, tenantID string, nu
 					atomic.AddInt64(&metrics.TotalNonFraud, 1)
 				}
 
+				scores = append(scores, ScoredResult{Score: result.Score, IsFraud: tx.IsFraud})
+
 				// Calculate confusion matrix
 				predicted := result.Status == "ALRT"
 				actual := tx.IsFraud
@@ -386,7 +455,7 @@ func evaluateTransaction(client *http.Client, baseURL, tenantID string, tx PaySi
 	return &result, nil
 }
 
-func printResults(m *Metrics, duration time.Duration) {
+func printResults(m *Metrics, r *Report) {
 	fmt.Println("\n╔═══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                      BENCHMARK RESULTS                        ║")
 	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
@@ -406,27 +475,7 @@ func printResults(m *Metrics, duration time.Duration) {
 	fmt.Printf("          NF  │ %8d │ %8d │  (FP, TN)\n", m.FalsePositives, m.TrueNegatives)
 	fmt.Println("              └──────────┴──────────┘")
 
-	// Calculate metrics
-	precision := float64(0)
-	if m.TruePositives+m.FalsePositives > 0 {
-		precision = float64(m.TruePositives) / float64(m.TruePositives+m.FalsePositives)
-	}
-
-	recall := float64(0)
-	if m.TruePositives+m.FalseNegatives > 0 {
-		recall = float64(m.TruePositives) / float64(m.TruePositives+m.FalseNegatives)
-	}
-
-	f1 := float64(0)
-	if precision+recall > 0 {
-		f1 = 2 * (precision * recall) / (precision + recall)
-	}
-
-	accuracy := float64(0)
-	total := m.TruePositives + m.TrueNegatives + m.FalsePositives + m.FalseNegatives
-	if total > 0 {
-		accuracy = float64(m.TruePositives+m.TrueNegatives) / float64(total)
-	}
+	precision, recall, f1, accuracy := r.Precision, r.Recall, r.F1, r.Accuracy
 
 	fmt.Printf("\n🎯 DETECTION METRICS\n")
 	fmt.Printf("   Precision:  %.4f  (of alerts, how many were actual fraud)\n", precision)
@@ -448,12 +497,22 @@ func printResults(m *Metrics, duration time.Duration) {
 	}
 
 	fmt.Printf("\n⏱️  PERFORMANCE\n")
-	fmt.Printf("   Total Duration:   %v\n", duration.Round(time.Millisecond))
+	fmt.Printf("   Total Duration:   %v\n", time.Duration(r.DurationMs*float64(time.Millisecond)).Round(time.Millisecond))
 	if m.TotalProcessed > 0 {
-		avgMs := float64(m.ProcessingTimeMs) / float64(m.TotalProcessed)
-		tps := float64(m.TotalProcessed) / duration.Seconds()
-		fmt.Printf("   Avg Latency:      %.2f ms\n", avgMs)
-		fmt.Printf("   Throughput:       %.2f tx/sec\n", tps)
+		fmt.Printf("   Avg Latency:      %.2f ms\n", r.Latency.AvgMs)
+		fmt.Printf("   p50 Latency:      %.2f ms\n", r.Latency.P50Ms)
+		fmt.Printf("   p95 Latency:      %.2f ms\n", r.Latency.P95Ms)
+		fmt.Printf("   p99 Latency:      %.2f ms\n", r.Latency.P99Ms)
+		fmt.Printf("   Max Latency:      %.2f ms\n", r.Latency.MaxMs)
+		fmt.Printf("   Throughput:       %.2f tx/sec\n", r.Throughput)
+	}
+
+	if len(r.Thresholds) > 0 {
+		fmt.Printf("\n🎚️  THRESHOLD SWEEP (alert when score >= threshold)\n")
+		fmt.Println("   Threshold   Alerts   Precision   Recall      F1")
+		for _, t := range r.Thresholds {
+			fmt.Printf("   %9.2f %8d   %9.4f   %6.4f  %6.4f\n", t.Threshold, t.TruePositives+t.FalsePositives, t.Precision, t.Recall, t.F1)
+		}
 	}
 
 	// Interpretation
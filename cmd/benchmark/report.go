@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is a benchmark run's results, as exported with -report.
+type Report struct {
+	Label     string    `json:"label,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+
+	Processed int64 `json:"processed"`
+	Fraud     int64 `json:"fraud"`
+	NonFraud  int64 `json:"nonFraud"`
+	Errors    int64 `json:"errors"`
+
+	// Detection as decided by Osprey (ALRT/NALT)
+	TruePositives  int64   `json:"truePositives"`
+	FalsePositives int64   `json:"falsePositives"`
+	TrueNegatives  int64   `json:"trueNegatives"`
+	FalseNegatives int64   `json:"falseNegatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+	Accuracy       float64 `json:"accuracy"`
+
+	DurationMs float64        `json:"durationMs"`
+	Throughput float64        `json:"throughput"` // transactions per second
+	Latency    LatencySummary `json:"latency"`
+
+	Thresholds []ThresholdResult `json:"thresholds,omitempty"`
+}
+
+// LatencySummary summarizes request latencies in milliseconds.
+type LatencySummary struct {
+	AvgMs float64 `json:"avgMs"`
+	P50Ms float64 `json:"p50Ms"`
+	P95Ms float64 `json:"p95Ms"`
+	P99Ms float64 `json:"p99Ms"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+// ThresholdResult is the detection had transactions alerted whenever their
+// score reached Threshold, as with that TADP alert threshold.
+type ThresholdResult struct {
+	Threshold      float64 `json:"threshold"`
+	TruePositives  int64   `json:"truePositives"`
+	FalsePositives int64   `json:"falsePositives"`
+	TrueNegatives  int64   `json:"trueNegatives"`
+	FalseNegatives int64   `json:"falseNegatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// parseThresholds parses a comma-separated list of thresholds in [0, 1].
+func parseThresholds(list string) ([]float64, error) {
+	var thresholds []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		t, err := strconv.ParseFloat(field, 64)
+		if err != nil || t < 0 || t > 1 {
+			return nil, fmt.Errorf("invalid threshold %q: must be between 0 and 1", field)
+		}
+		thresholds = append(thresholds, t)
+	}
+	sort.Float64s(thresholds)
+	return thresholds, nil
+}
+
+func buildReport(m *Metrics, duration time.Duration, thresholds []float64) *Report {
+	r := &Report{
+		Timestamp:      time.Now().UTC(),
+		Processed:      m.TotalProcessed,
+		Fraud:          m.TotalFraud,
+		NonFraud:       m.TotalNonFraud,
+		Errors:         m.TotalErrors,
+		TruePositives:  m.TruePositives,
+		FalsePositives: m.FalsePositives,
+		TrueNegatives:  m.TrueNegatives,
+		FalseNegatives: m.FalseNegatives,
+		DurationMs:     milliseconds(duration),
+		Latency:        summarizeLatencies(m.Latencies),
+	}
+	r.Precision, r.Recall, r.F1 = detectionRates(r.TruePositives, r.FalsePositives, r.FalseNegatives)
+	if total := r.TruePositives + r.TrueNegatives + r.FalsePositives + r.FalseNegatives; total > 0 {
+		r.Accuracy = float64(r.TruePositives+r.TrueNegatives) / float64(total)
+	}
+	if duration > 0 {
+		r.Throughput = float64(m.TotalProcessed) / duration.Seconds()
+	}
+
+	for _, t := range thresholds {
+		result := ThresholdResult{Threshold: t}
+		for _, s := range m.Scores {
+			switch predicted := s.Score >= t; {
+			case predicted && s.IsFraud:
+				result.TruePositives++
+			case predicted:
+				result.FalsePositives++
+			case s.IsFraud:
+				result.FalseNegatives++
+			default:
+				result.TrueNegatives++
+			}
+		}
+		result.Precision, result.Recall, result.F1 = detectionRates(result.TruePositives, result.FalsePositives, result.FalseNegatives)
+		r.Thresholds = append(r.Thresholds, result)
+	}
+	return r
+}
+
+func detectionRates(tp, fp, fn int64) (precision, recall, f1 float64) {
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * (precision * recall) / (precision + recall)
+	}
+	return precision, recall, f1
+}
+
+// summarizeLatencies returns the average, the nearest-rank percentiles and
+// the maximum of the latencies.
+func summarizeLatencies(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return milliseconds(sorted[max(rank, 1)-1])
+	}
+	return LatencySummary{
+		AvgMs: milliseconds(total) / float64(len(sorted)),
+		P50Ms: percentile(50),
+		P95Ms: percentile(95),
+		P99Ms: percentile(99),
+		MaxMs: milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// writeReport writes the report as CSV when path ends in .csv, else as JSON.
+func writeReport(path string, r *Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = writeCSV(file, r)
+	} else {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(r)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeCSV writes one row per swept threshold, each repeating the run's
+// totals and latencies, so reports of several runs can be concatenated.
+// Without thresholds it writes a single row with empty threshold columns.
+func writeCSV(file *os.File, r *Report) error {
+	w := csv.NewWriter(file)
+	w.Write([]string{
+		"label", "timestamp", "processed", "errors", "precision", "recall", "f1",
+		"avg_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms", "throughput",
+		"threshold", "threshold_tp", "threshold_fp", "threshold_tn", "threshold_fn",
+		"threshold_precision", "threshold_recall", "threshold_f1",
+	})
+
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	i := func(v int64) string { return strconv.FormatInt(v, 10) }
+	run := []string{
+		r.Label, r.Timestamp.Format(time.RFC3339), i(r.Processed), i(r.Errors), f(r.Precision), f(r.Recall), f(r.F1),
+		f(r.Latency.AvgMs), f(r.Latency.P50Ms), f(r.Latency.P95Ms), f(r.Latency.P99Ms), f(r.Latency.MaxMs), f(r.Throughput),
+	}
+	if len(r.Thresholds) == 0 {
+		w.Write(append(run, "", "", "", "", "", "", "", ""))
+	}
+	for _, t := range r.Thresholds {
+		w.Write(append(append([]string(nil), run...),
+			f(t.Threshold), i(t.TruePositives), i(t.FalsePositives), i(t.TrueNegatives), i(t.FalseNegatives),
+			f(t.Precision), f(t.Recall), f(t.F1),
+		))
+	}
+	w.Flush()
+	return w.Error()
+}
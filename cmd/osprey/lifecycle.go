@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// probeTimeout bounds a healthcheck or drain request.
+const probeTimeout = 3 * time.Second
+
+// runHealthcheck probes the local server, so a container image needs no
+// shell or curl: `osprey healthcheck` checks GET /ready and
+// `osprey healthcheck live` GET /health. It returns the exit code: 0 when
+// the server answers 200, 1 otherwise.
+func runHealthcheck(cfg *domain.Config, args []string) int {
+	path := "/ready"
+	if len(args) > 0 {
+		switch args[0] {
+		case "ready":
+		case "live":
+			path = "/health"
+		default:
+			fmt.Fprintln(os.Stderr, "usage: osprey healthcheck [ready|live]")
+			return 2
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	code, err := localRequest(ctx, cfg, http.MethodGet, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "healthcheck failed:", err)
+		return 1
+	}
+	if code != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: %s answered %d\n", path, code)
+		return 1
+	}
+	return 0
+}
+
+// runDrain is the preStop hook: it asks the local server to fail readiness,
+// then waits DrainDelay so load balancers stop routing to it before the
+// shutdown signal. It returns the exit code.
+func runDrain(cfg *domain.Config) int {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	code, err := localRequest(ctx, cfg, http.MethodPost, "/drain")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "drain failed:", err)
+		return 1
+	}
+	if code != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "drain failed: /drain answered %d\n", code)
+		return 1
+	}
+	time.Sleep(cfg.Server.DrainDelay)
+	return 0
+}
+
+// localRequest sends a bodyless request to the server on this host and
+// returns the response status.
+func localRequest(ctx context.Context, cfg *domain.Config, method, path string) (int, error) {
+	host := cfg.Server.Host
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	url := "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port)) + path
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -16,15 +16,51 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/opensource-finance/osprey/internal/alerts"
 	"github.com/opensource-finance/osprey/internal/api"
+	"github.com/opensource-finance/osprey/internal/auditlog"
+	"github.com/opensource-finance/osprey/internal/balances"
+	"github.com/opensource-finance/osprey/internal/baseline"
+	"github.com/opensource-finance/osprey/internal/breaker"
 	"github.com/opensource-finance/osprey/internal/bus"
 	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/cluster"
+	"github.com/opensource-finance/osprey/internal/corridor"
+	"github.com/opensource-finance/osprey/internal/demo"
 	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/evalcache"
+	"github.com/opensource-finance/osprey/internal/features"
+	"github.com/opensource-finance/osprey/internal/fx"
+	"github.com/opensource-finance/osprey/internal/gitsync"
+	"github.com/opensource-finance/osprey/internal/graph"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+	"github.com/opensource-finance/osprey/internal/kyc"
+	"github.com/opensource-finance/osprey/internal/logging"
+	"github.com/opensource-finance/osprey/internal/maintenance"
+	"github.com/opensource-finance/osprey/internal/migration"
+	"github.com/opensource-finance/osprey/internal/ml"
+	"github.com/opensource-finance/osprey/internal/outcomes"
+	"github.com/opensource-finance/osprey/internal/plugins"
 	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/retention"
+	"github.com/opensource-finance/osprey/internal/review"
+	"github.com/opensource-finance/osprey/internal/ruledir"
 	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/sampling"
+	"github.com/opensource-finance/osprey/internal/sandbox"
+	"github.com/opensource-finance/osprey/internal/scoring"
+	"github.com/opensource-finance/osprey/internal/screening"
+	"github.com/opensource-finance/osprey/internal/signing"
+	"github.com/opensource-finance/osprey/internal/slo"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/stats"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/txtypes"
 	"github.com/opensource-finance/osprey/internal/velocity"
+	"github.com/opensource-finance/osprey/internal/watchlist"
+	"github.com/opensource-finance/osprey/internal/webhooks"
 	"github.com/opensource-finance/osprey/internal/worker"
+	"google.golang.org/grpc"
 )
 
 // Version information (set via ldflags)
@@ -35,15 +71,33 @@ var (
 )
 
 func main() {
+	// `osprey demo` runs a throwaway in-memory instance with demo rules and
+	// synthetic traffic, printing an alert feed
+	demoMode := len(os.Args) > 1 && os.Args[1] == "demo"
+
 	// Initialize structured logger
 	logLevel := slog.LevelInfo
+	if demoMode {
+		logLevel = slog.LevelWarn // keep the alert feed readable
+	}
 	if os.Getenv("OSPREY_DEBUG") == "true" {
 		logLevel = slog.LevelDebug
 	}
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logLevel,
-	}))
-	slog.SetDefault(logger)
+	})
+	slog.SetDefault(slog.New(logHandler))
+
+	// `osprey healthcheck` and `osprey drain` are the container probe and
+	// preStop hook; they only need the server's address
+	if len(os.Args) > 1 && (os.Args[1] == "healthcheck" || os.Args[1] == "drain") {
+		cfg := domain.DefaultConfig()
+		applyEnvOverrides(cfg)
+		if os.Args[1] == "drain" {
+			os.Exit(runDrain(cfg))
+		}
+		os.Exit(runHealthcheck(cfg, os.Args[2:]))
+	}
 
 	// Log startup
 	slog.Info("starting osprey",
@@ -79,6 +133,37 @@ func main() {
 	// Apply environment variable overrides for production deployment
 	applyEnvOverrides(cfg)
 
+	if cfg.EvaluationMode == domain.ModeCompliance {
+		if err := cfg.Retention.Defaults.CheckCompliance(); err != nil {
+			slog.Error("invalid OSPREY_RETENTION defaults for compliance mode", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if demoMode {
+		cfg.Repository = domain.RepositoryConfig{Driver: "memory"}
+		cfg.GitSync.Repo = ""
+		cfg.RuleDir.Dir = ""
+	}
+
+	// Redact sensitive log fields from here on
+	redacting, err := logging.NewRedactingHandler(logHandler, cfg.Logging.Redaction)
+	if err != nil {
+		slog.Error("invalid log redaction config", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(slog.New(redacting))
+	if cfg.Logging.Redaction.Mode != logging.ModeOff && cfg.Logging.Redaction.Salt == "" {
+		slog.Warn("log redaction has no salt; hashed identifiers can be guessed by brute force",
+			"hint", "set OSPREY_LOG_REDACT_SALT",
+		)
+	}
+
+	// `osprey migrate TENANT...` copies tenants to the migration target and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	}
+
 	slog.Info("configuration loaded",
 		"tier", cfg.Tier,
 		"mode", cfg.EvaluationMode,
@@ -110,6 +195,64 @@ func main() {
 	defer repo.Close()
 	slog.Info("repository initialized", "driver", cfg.Repository.Driver)
 
+	// Compliance mode keeps a tamper-evident, hash-chained log of every evaluation
+	if cfg.EvaluationMode == domain.ModeCompliance {
+		repo = auditlog.Wrap(repo)
+		slog.Info("evaluation log enabled")
+	}
+
+	// Every saved ALRT evaluation becomes an alert that can be acknowledged
+	repo = alerts.Wrap(repo)
+
+	// Detached JWS signatures on evaluation responses and webhooks
+	var signer *signing.Signer
+	if cfg.Signing.KeyFile != "" {
+		signer, err = signing.LoadKey(cfg.Signing.KeyFile)
+		if err != nil {
+			slog.Error("failed to load signing key", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("response signing enabled", "kid", signer.KeyID())
+	}
+
+	// ALRT evaluations are queued for delivery to tenant webhooks
+	webhookDispatcher := webhooks.NewDispatcher(repo, cfg.Webhooks)
+	webhookDispatcher.SetSigner(signer)
+	repo = webhooks.Wrap(repo, webhookDispatcher.Trigger, cfg.Webhooks.Digests)
+	if len(cfg.Webhooks.Digests) > 0 {
+		slog.Info("alert digests enabled", "tenants", len(cfg.Webhooks.Digests))
+	}
+
+	// Alerts raised during a tenant's maintenance window notify nobody
+	repo = maintenance.Wrap(repo)
+
+	// Every stored transaction adds to the tenant's counterparty network
+	repo = graph.Wrap(repo)
+
+	// Every stored payment updates its debtor's behavioral baseline
+	repo = baseline.Wrap(repo, cfg.Baseline)
+
+	// Every stored payment debits and credits the tracked account balances
+	balanceTracker := balances.NewTracker(repo)
+	repo = balances.Wrap(repo, balanceTracker)
+
+	// Evaluation and alert volume per tenant and debtor feeds /stats/top
+	topTracker := stats.NewTracker(cfg.Stats.TopWindow)
+	repo = stats.Wrap(repo, topTracker)
+
+	// Tenants can be copied to another repository, e.g. on an upgrade to Pro
+	var migrations *migration.Coordinator
+	if cfg.Migration.Target.Driver != "" {
+		target, err := repository.New(cfg.Migration.Target)
+		if err != nil {
+			slog.Error("failed to initialize migration target", "error", err)
+			os.Exit(1)
+		}
+		defer target.Close()
+		migrations = migration.NewCoordinator(migration.New(domain.BaseRepository(repo), target))
+		slog.Info("tenant migration enabled", "target", cfg.Migration.Target.Driver)
+	}
+
 	// Initialize Cache
 	cacheImpl, err := cache.New(cfg.Cache)
 	if err != nil {
@@ -119,6 +262,9 @@ func main() {
 	defer cacheImpl.Close()
 	slog.Info("cache initialized", "type", cfg.Cache.Type)
 
+	// Evaluations read by ID are served from the cache
+	repo = evalcache.Wrap(repo, cacheImpl, cfg.Cache.EvaluationTTL)
+
 	// Initialize EventBus
 	busImpl, err := bus.New(cfg.EventBus)
 	if err != nil {
@@ -126,11 +272,43 @@ func main() {
 		os.Exit(1)
 	}
 	defer busImpl.Close()
-	slog.Info("event bus initialized", "type", cfg.EventBus.Type)
+	slog.Info("event bus initialized", "type", cfg.EventBus.Type, "synchronous", cfg.EventBus.ChannelSynchronous, "jetstream", cfg.EventBus.NATSJetStream)
+
+	// Reloads after changes to rules, named lists and typologies are
+	// announced on the bus, so every replica reloads its engines, not only
+	// the one that made the change
+	clusterCoordinator := cluster.NewCoordinator(busImpl, cfg.Cluster.ReloadDelay)
+
+	// A circuit breaker keeps evaluations flowing, or failing fast, while
+	// the database is down. It wraps the wrappers above, so replayed writes
+	// still update baselines and balances; velocity counters, read through
+	// it, count queued transactions at once
+	var repoBreaker *breaker.Repository
+	if cfg.Breaker.Policy != "" {
+		repoBreaker, err = breaker.Wrap(repo, cfg.Breaker)
+		if err != nil {
+			slog.Error("failed to initialize circuit breaker", "error", err)
+			os.Exit(1)
+		}
+		repo = repoBreaker
+		go repoBreaker.Run(ctx)
+		slog.Info("repository circuit breaker enabled",
+			"policy", cfg.Breaker.Policy,
+			"failures", cfg.Breaker.Failures,
+			"cooldown", cfg.Breaker.Cooldown,
+			"queued", repoBreaker.Status().Queued,
+		)
+	}
 
 	// Initialize Velocity Service
 	velocitySvc := velocity.NewService(repo, cacheImpl)
-	slog.Info("velocity service initialized")
+	if cfg.Velocity.WriteThrough {
+		// Saved transactions are counted in the cache, so velocity_count
+		// reads the database only to reconcile
+		velocitySvc.EnableCounters(cfg.Velocity)
+		repo = velocity.Wrap(repo, velocitySvc)
+	}
+	slog.Info("velocity service initialized", "write_through", cfg.Velocity.WriteThrough)
 
 	// Initialize Rule Engine with velocity getter
 	engine, err := rules.NewEngine(velocitySvc.GetVelocityGetter(), 100)
@@ -138,12 +316,146 @@ func main() {
 		slog.Error("failed to initialize rule engine", "error", err)
 		os.Exit(1)
 	}
+	engine.SetVelocityWindows(cfg.Velocity)
+
+	// Expose party KYC profiles to rules as debtor_kyc / creditor_kyc
+	kycSvc := kyc.NewService(repo, cacheImpl)
+	if err := engine.RegisterEnricher(kycSvc.Enricher()); err != nil {
+		slog.Error("failed to register kyc enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose origin→destination country risk as corridor_risk
+	corridorSvc := corridor.NewService(repo, cacheImpl)
+	if err := engine.RegisterEnricher(corridorSvc.Enricher()); err != nil {
+		slog.Error("failed to register corridor enricher", "error", err)
+		os.Exit(1)
+	}
+	engine.SetCountryRisk(corridor.CountryRisk)
+
+	// Expose PEP / adverse-media screening as is_pep and adverse_media_score.
+	// The open-source build ships the offline NullProvider; plug in a vendor
+	// implementation of screening.Provider here.
+	screeningSvc := screening.NewService(screening.NullProvider{}, cacheImpl, screening.DefaultCacheTTL)
+	if err := engine.RegisterEnricher(screeningSvc.Enricher()); err != nil {
+		slog.Error("failed to register screening enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose sanctions, block and watch list matches as debtor_on_watchlist,
+	// creditor_on_watchlist and the matched list types
+	watchlistSvc := watchlist.NewService(repo)
+	if err := engine.RegisterEnricher(watchlistSvc.Enricher()); err != nil {
+		slog.Error("failed to register watchlist enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose each party's chargebacks and returns of the last 180 days as
+	// debtor_prior_chargebacks, creditor_prior_returns, ...
+	outcomeSvc := outcomes.NewService(repo, 0)
+	if err := engine.RegisterEnricher(outcomeSvc.Enricher()); err != nil {
+		slog.Error("failed to register outcomes enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose the debtor's 10-minute vs hourly-average rate as velocity_burst_ratio
+	if err := engine.RegisterEnricher(velocitySvc.BurstEnricher(0, 0)); err != nil {
+		slog.Error("failed to register velocity burst enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Let window_sum_below and window_count_below read the debtor's payments
+	engine.SetWindowSource(velocitySvc.Payments)
+
+	// Expose the debtor's amount sum, largest amount and distinct counterparties
+	// over the velocity window, net flow and reversals, cached between database
+	// reconciliations
+	if err := engine.RegisterEnricher(velocitySvc.AggregateEnricher(cfg.Velocity)); err != nil {
+		slog.Error("failed to register velocity aggregate enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose counterparty network signals: counterparty_first_seen,
+	// shared_counterparties_count, funds_return_to_origin, return_path_hops
+	graphSvc := graph.NewService(repo, cfg.Graph)
+	if err := engine.RegisterEnricher(graphSvc.Enricher()); err != nil {
+		slog.Error("failed to register graph enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose how far a payment strays from its debtor's baseline:
+	// amount_zscore, unusual_hour, new_counterparty
+	baselineSvc := baseline.NewService(repo, cfg.Baseline)
+	if err := engine.RegisterEnricher(baselineSvc.Enricher()); err != nil {
+		slog.Error("failed to register baseline enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose drain_ratio and balance_known, and old_balance and new_balance
+	// from the tracked balance when a transaction doesn't report them. The
+	// enricher reads through the circuit breaker, which balanceTracker, below
+	// it, doesn't
+	if err := engine.RegisterEnricher(balances.NewTracker(repo).Enricher()); err != nil {
+		slog.Error("failed to register balances enricher", "error", err)
+		os.Exit(1)
+	}
+
+	// Expose amounts converted to the tenant's base currency as amount_base
+	// and currency_base. A failed first fetch leaves them unconverted until
+	// the next refresh.
+	fxSvc, err := fx.NewService(cfg.FX)
+	if err != nil {
+		slog.Error("invalid FX config", "error", err)
+		os.Exit(1)
+	}
+	if fxSvc.Enabled() {
+		if err := fxSvc.Refresh(ctx); err != nil {
+			slog.Error("initial FX rate fetch failed", "source", cfg.FX.Source, "error", err)
+		}
+		if err := engine.RegisterEnricher(fxSvc.Enricher()); err != nil {
+			slog.Error("failed to register fx enricher", "error", err)
+			os.Exit(1)
+		}
+		go fxSvc.Run(ctx)
+		slog.Info("FX conversion enabled", "source", cfg.FX.Source, "base_currency", cfg.FX.BaseCurrency)
+	}
+
+	// Store activation samples of rules with a sampleRate, redacted like the logs
+	redactor, err := logging.NewRedactor(cfg.Logging.Redaction)
+	if err != nil {
+		slog.Error("invalid log redaction config", "error", err)
+		os.Exit(1)
+	}
+	engine.SetSampler(sampling.NewRecorder(repo, redactor))
+
+	// Register enrichment plugins after the built-ins so they can't shadow them
+	var pluginNames []string
+	if cfg.Plugins.Dir != "" {
+		loaded, err := plugins.Load(cfg.Plugins.Dir)
+		if err != nil {
+			slog.Error("failed to load enrichment plugins", "dir", cfg.Plugins.Dir, "error", err)
+			os.Exit(1)
+		}
+		for _, p := range loaded {
+			enricher, err := plugins.Enricher(p, cfg.Plugins.Timeout)
+			if err == nil {
+				err = engine.RegisterEnricher(enricher)
+			}
+			if err != nil {
+				slog.Error("failed to register enrichment plugin", "plugin", p.Name(), "error", err)
+				os.Exit(1)
+			}
+			pluginNames = append(pluginNames, p.Name())
+		}
+		slog.Info("enrichment plugins loaded", "dir", cfg.Plugins.Dir, "plugins", pluginNames)
+	}
 
 	// Load rules from database (no hardcoded defaults - configure via API)
 	if err := loadRulesFromDatabase(ctx, repo, engine); err != nil {
 		slog.Error("failed to load rules", "error", err)
 		os.Exit(1)
 	}
+	loadListsFromDatabase(ctx, repo, engine)
 	slog.Info("rule engine initialized", "rules_count", engine.RulesCount())
 
 	// Initialize Typology Engine
@@ -156,21 +468,109 @@ func main() {
 	}
 	slog.Info("typology engine initialized", "typologies_count", typologyEngine.TypologyCount())
 
+	// Other replicas' changes reload the engines from the database, and this
+	// replica's applies are announced to them
+	stateManager := state.NewManager(repo, engine, typologyEngine)
+	stateManager.OnApply(clusterCoordinator.Notify)
+	if err := clusterCoordinator.Start(ctx, stateManager.Reload); err != nil {
+		slog.Error("failed to subscribe to cluster reloads", "error", err)
+		os.Exit(1)
+	}
+	defer clusterCoordinator.Stop()
+
+	// Git sync: the repository replaces the API as the source of rules and typologies.
+	// A failed first sync keeps the stored configuration and is retried on the next poll.
+	gitSyncer := gitsync.NewSyncer(cfg.GitSync, stateManager)
+	if gitSyncer.Enabled() {
+		if _, err := gitSyncer.Sync(ctx); err != nil {
+			slog.Error("initial git sync failed", "repo", cfg.GitSync.Repo, "error", err)
+		}
+		go gitSyncer.Run(ctx)
+		slog.Info("git sync enabled", "repo", cfg.GitSync.Repo, "branch", cfg.GitSync.Branch, "interval", cfg.GitSync.Interval)
+	}
+
+	// Rule directory: files on disk replace the API as the source, as with Git sync.
+	// Both would own the same global rules, so only one may be enabled.
+	ruleDir := ruledir.NewWatcher(cfg.RuleDir, stateManager)
+	if ruleDir.Enabled() {
+		if gitSyncer.Enabled() {
+			slog.Error("OSPREY_RULE_DIR and OSPREY_GITSYNC_REPO cannot both be set")
+			os.Exit(1)
+		}
+		if _, err := ruleDir.Sync(ctx); err != nil {
+			slog.Error("initial rule directory sync failed", "dir", cfg.RuleDir.Dir, "error", err)
+		}
+		go ruleDir.Run(ctx)
+		slog.Info("rule directory enabled", "dir", cfg.RuleDir.Dir, "interval", cfg.RuleDir.Interval)
+	}
+
+	if demoMode {
+		if err := demo.Seed(ctx, stateManager); err != nil {
+			slog.Error("failed to seed demo rules", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Feature flags (config defaults, overridden per tenant via /features)
+	featureFlags := features.NewService(repo, cfg.Features)
+
 	// Initialize Decision Processor (TADP)
+	// Tenants may override the scoring defaults via /config/scoring
+	scoringSvc := scoring.NewService(repo, cacheImpl, cfg.Scoring)
 	processor := tadp.NewProcessor()
-	processor.AlertThreshold = 0.7              // Default threshold
+	processor.AlertThreshold = cfg.Scoring.AlertThreshold
+	processor.UseWeightedScoring = cfg.Scoring.WeightedScoring
+	processor.CriticalFail = cfg.Scoring.CriticalFail
 	processor.Mode = string(cfg.EvaluationMode) // Set mode from config
+	processor.MLWeight = cfg.Scoring.MLWeight
+	processor.MLBlend = cfg.Scoring.MLBlend
+	processor.Scoring = scoringSvc
 	slog.Info("TADP processor initialized",
 		"mode", processor.Mode,
 		"threshold", processor.AlertThreshold,
+		"weighted", processor.UseWeightedScoring,
+		"critical_fail", processor.CriticalFail,
 	)
 
+	// Blend an external model's score with the rules' for tenants with the
+	// ml_hook flag, as their scoring config says
+	mlScorer, err := ml.New(cfg.ML)
+	if err != nil {
+		slog.Error("invalid ML config", "error", err)
+		os.Exit(1)
+	}
+	if mlScorer != nil {
+		defer mlScorer.Close()
+		processor.ML = mlScorer
+		processor.MLEnabled = func(ctx context.Context, tenantID string) bool {
+			return featureFlags.Enabled(ctx, tenantID, features.MLHook)
+		}
+		processor.MLFallback = cfg.ML.Fallback
+		slog.Info("ML scoring hook enabled",
+			"protocol", cfg.ML.Protocol,
+			"timeout", cfg.ML.Timeout,
+			"fallback", cfg.ML.Fallback,
+		)
+	}
+
 	// Compliance mode validation: require typologies
 	if cfg.EvaluationMode == domain.ModeCompliance && typologyEngine.TypologyCount() == 0 {
 		slog.Warn("Compliance mode enabled but no typologies configured",
 			"hint", "Create typologies via POST /typologies or switch to Detection mode")
 	}
 
+	// Allowed transaction types per tenant
+	txTypePolicy := txtypes.NewPolicy(cfg.TxTypes)
+	if txTypePolicy.Enabled() {
+		slog.Info("transaction types restricted", "unknown", txTypePolicy.Action())
+	}
+
+	// Caps on how much one call may change a tenant's rules and typologies
+	guardrailPolicy := guardrails.NewPolicy(cfg.Guardrails)
+	if guardrailPolicy.Enabled() {
+		slog.Info("rule and typology changes capped", "tenants", len(cfg.Guardrails.Caps))
+	}
+
 	// Initialize async Worker (Pro tier)
 	var asyncWorker *worker.Worker
 	if cfg.Tier == domain.TierPro || os.Getenv("OSPREY_ASYNC_WORKER") == "true" {
@@ -184,8 +584,14 @@ func main() {
 		}
 
 		workerCfg := worker.Config{
-			TenantIDs:   tenantIDs,
-			WorkerCount: 5,
+			TenantIDs:    tenantIDs,
+			WorkerCount:  5,
+			MaxLag:       cfg.Queue.MaxLag,
+			MaxBacklog:   cfg.Queue.MaxBacklog,
+			Lanes:        cfg.Queue.Lanes,
+			TxTypes:      txTypePolicy,
+			Retries:      cfg.Queue.Retries,
+			RetryBackoff: cfg.Queue.RetryBackoff,
 		}
 
 		if err := asyncWorker.Start(workerCfg); err != nil {
@@ -195,8 +601,102 @@ func main() {
 		}
 	}
 
+	// Alert acknowledgment and escalation of unacknowledged alerts
+	alertService := alerts.NewService(repo, busImpl, cfg.Alerts)
+	if alertService.Enabled() {
+		go alertService.Run(ctx)
+		slog.Info("alert escalation enabled",
+			"ack_window", cfg.Alerts.AckWindow,
+			"max_escalations", cfg.Alerts.MaxEscalations,
+		)
+	}
+
+	// Signed webhook delivery, with retries for failed deliveries
+	go webhookDispatcher.Run(ctx)
+
+	// Sandbox tenants, whose activity expires
+	sandboxPurger := sandbox.NewPurger(repo, cfg.Sandbox)
+	if sandboxPurger.Enabled() {
+		go sandboxPurger.Run(ctx)
+		slog.Info("sandbox tenants enabled",
+			"tenants", cfg.Sandbox.Tenants,
+			"ttl", sandboxPurger.TTL(),
+		)
+	}
+
+	// Retention policies, expiring and archiving old activity
+	retentionSvc := retention.NewService(repo, cfg.Retention)
+	go retentionSvc.Run(ctx)
+
+	// Management endpoint network restrictions
+	adminNetworks, err := api.ParseAdminNetworks(cfg.Server.AdminNetworks, cfg.Server.TrustProxyHeaders)
+	if err != nil {
+		slog.Error("invalid OSPREY_ADMIN_NETWORKS", "error", err)
+		os.Exit(1)
+	}
+	if adminNetworks.Enabled() {
+		slog.Info("management endpoints restricted to admin networks",
+			"networks", cfg.Server.AdminNetworks,
+			"trust_proxy_headers", cfg.Server.TrustProxyHeaders,
+		)
+	}
+
+	// Browser origins allowed to call the API (none by default)
+	corsPolicy, err := api.NewCORSPolicy(cfg.Server.CORS)
+	if err != nil {
+		slog.Error("invalid CORS configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize Server
-	srv := api.NewServer(cfg.Server, repo, cacheImpl, busImpl, engine, typologyEngine, processor, Version, cfg.EvaluationMode)
+	srv := api.NewServer(cfg.Server, repo, cacheImpl, busImpl, engine, typologyEngine, processor, Version, cfg.EvaluationMode,
+		api.WithBuildInfo(api.BuildInfo{
+			Commit:    Commit,
+			BuildDate: BuildDate,
+			Tier:      cfg.Tier,
+			Subsystems: map[string]any{
+				"repository":      cfg.Repository.Driver,
+				"cache":           cfg.Cache.Type,
+				"eventBus":        cfg.EventBus.Type,
+				"asyncWorker":     asyncWorker != nil,
+				"evaluationLog":   cfg.EvaluationMode == domain.ModeCompliance,
+				"logRedaction":    cfg.Logging.Redaction.Mode,
+				"adminNetworks":   adminNetworks.Enabled(),
+				"alertEscalation": alertService.Enabled(),
+				"plugins":         pluginNames,
+				"gitSync":         gitSyncer.Enabled(),
+				"txTypes":         txTypePolicy.Enabled(),
+				"guardrails":      guardrailPolicy.Enabled(),
+				"alertDigests":    len(cfg.Webhooks.Digests) > 0,
+				"signing":         signer != nil,
+				"slo":             len(cfg.SLO.Objectives),
+				"migrationTarget": cfg.Migration.Target.Driver,
+			},
+		}),
+		api.WithFeatures(featureFlags),
+		api.WithAlerts(alertService),
+		api.WithReviewQueue(review.NewQueue(repo, cfg.Review)),
+		api.WithState(stateManager),
+		api.WithGitSync(gitSyncer),
+		api.WithRuleDir(ruleDir),
+		api.WithQueue(asyncWorker),
+		api.WithAdminNetworks(adminNetworks),
+		api.WithGlobalTenantWrites(cfg.Server.GlobalTenantWrites),
+		api.WithCORS(corsPolicy),
+		api.WithTxTypes(txTypePolicy),
+		api.WithGuardrails(guardrailPolicy),
+		api.WithSandbox(sandboxPurger),
+		api.WithSigner(signer),
+		api.WithScoring(scoringSvc),
+		api.WithRetention(retentionSvc),
+		api.WithSLO(slo.NewTracker(cfg.SLO)),
+		api.WithTopTracker(topTracker),
+		api.WithAggregatePrivacy(stats.NewPrivacy(cfg.Stats.Epsilon, cfg.Stats.MinCohort)),
+		api.WithMigrations(migrations),
+		api.WithBalances(balanceTracker),
+		api.WithCluster(clusterCoordinator),
+		api.WithBreaker(repoBreaker),
+	)
 
 	// Start Server in goroutine
 	go func() {
@@ -205,17 +705,51 @@ func main() {
 			os.Exit(1)
 		}
 	}()
+	if cfg.Server.GRPCPort > 0 {
+		go func() {
+			if err := srv.StartGRPC(); err != nil && err != grpc.ErrServerStopped {
+				slog.Error("grpc server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	slog.Info("osprey is ready",
 		"host", cfg.Server.Host,
 		"port", cfg.Server.Port,
+		"grpc_port", cfg.Server.GRPCPort,
 	)
 
-	printBanner(cfg, Version)
+	if cfg.Banner {
+		printBanner(cfg, Version)
+	}
+
+	if demoMode {
+		rate, _ := strconv.Atoi(os.Getenv("OSPREY_DEMO_RATE"))
+		generator := demo.NewGenerator(demo.Config{
+			URL:  fmt.Sprintf("http://127.0.0.1:%d", cfg.Server.Port),
+			Rate: rate,
+		}, os.Stdout)
+		go func() {
+			if err := generator.Run(ctx); err != nil {
+				slog.Error("demo traffic stopped", "error", err)
+			}
+		}()
+	}
 
 	// Wait for shutdown signal
 	<-ctx.Done()
 	slog.Info("shutting down...")
+	srv.Drain()
+
+	// Let queued channel bus messages finish before the worker unsubscribes
+	if channelBus, ok := busImpl.(*bus.ChannelBus); ok {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := channelBus.Flush(flushCtx); err != nil {
+			slog.Warn("event bus flush incomplete", "error", err)
+		}
+		flushCancel()
+	}
 
 	// Stop async worker first
 	if asyncWorker != nil {
@@ -234,13 +768,10 @@ func main() {
 	slog.Info("osprey shutdown complete")
 }
 
-// GlobalTenantID is used for rules that apply to all tenants.
-const GlobalTenantID = "*"
-
-// loadRulesFromDatabase loads rules from the database into the engine.
+// loadRulesFromDatabase loads the rules of every tenant from the database into the engine.
 // All rules must be configured via POST /rules API - no hardcoded defaults.
 func loadRulesFromDatabase(ctx context.Context, repo domain.Repository, engine *rules.Engine) error {
-	dbRules, err := repo.ListRuleConfigs(ctx, GlobalTenantID)
+	dbRules, err := repo.ListAllRuleConfigs(ctx)
 	if err != nil {
 		slog.Warn("failed to list rules from database", "error", err)
 		return nil // Start with empty rules - they can be added via API
@@ -255,10 +786,24 @@ func loadRulesFromDatabase(ctx context.Context, repo domain.Repository, engine *
 	return nil
 }
 
-// loadTypologiesFromDatabase loads typologies from the database into the engine.
+// loadListsFromDatabase loads the named lists of every tenant from the
+// database into the engine. Rules see empty lists until they load.
+func loadListsFromDatabase(ctx context.Context, repo domain.Repository, engine *rules.Engine) {
+	lists, err := repo.ListAllNamedLists(ctx)
+	if err != nil {
+		slog.Warn("failed to list named lists from database", "error", err)
+		return
+	}
+	if len(lists) > 0 {
+		slog.Info("loading named lists from database", "count", len(lists))
+	}
+	engine.ReloadLists(lists)
+}
+
+// loadTypologiesFromDatabase loads the typologies of every tenant from the database into the engine.
 // All typologies must be configured via POST /typologies API - no hardcoded defaults.
 func loadTypologiesFromDatabase(ctx context.Context, repo domain.Repository, engine *rules.TypologyEngine) error {
-	dbTypologies, err := repo.ListTypologies(ctx, GlobalTenantID)
+	dbTypologies, err := repo.ListAllTypologies(ctx)
 	if err != nil {
 		slog.Warn("failed to list typologies from database", "error", err)
 		return nil // Start with empty typologies - they can be added via API
@@ -276,11 +821,8 @@ func loadTypologiesFromDatabase(ctx context.Context, repo domain.Repository, eng
 
 func printBanner(cfg *domain.Config, version string) {
 	fmt.Println()
-	fmt.Println("  ╔═══════════════════════════════════════════╗")
-	fmt.Println("  ║               🦅 OSPREY                   ║")
-	fmt.Println("  ║     Real-time Fraud Detection Engine      ║")
-	fmt.Println("  ╚═══════════════════════════════════════════╝")
-	fmt.Println()
+	fmt.Println("  OSPREY - Real-time Fraud Detection Engine")
+	fmt.Println("  -----------------------------------------")
 	fmt.Printf("  Version:  %s\n", version)
 	fmt.Printf("  Tier:     %s\n", cfg.Tier)
 	fmt.Printf("  Mode:     %s\n", cfg.EvaluationMode)
@@ -290,23 +832,40 @@ func printBanner(cfg *domain.Config, version string) {
 	// Mode-specific messaging
 	if cfg.EvaluationMode == domain.ModeDetection {
 		fmt.Println("  Mode: DETECTION (default)")
-		fmt.Println("    → Fast, weighted rule scoring")
-		fmt.Println("    → No typologies required")
-		fmt.Println("    → Ideal for fraud detection, startups")
+		fmt.Println("    - Fast, weighted rule scoring")
+		fmt.Println("    - No typologies required")
+		fmt.Println("    - Ideal for fraud detection, startups")
 	} else {
 		fmt.Println("  Mode: COMPLIANCE")
-		fmt.Println("    → FATF-aligned typology evaluation")
-		fmt.Println("    → Full audit trails")
-		fmt.Println("    → Ideal for banks, regulated fintechs")
+		fmt.Println("    - FATF-aligned typology evaluation")
+		fmt.Println("    - Full audit trails")
+		fmt.Println("    - Ideal for banks, regulated fintechs")
 	}
 	fmt.Println()
 	fmt.Println("  Endpoints:")
-	fmt.Println("    POST /evaluate          - Evaluate a transaction")
+	fmt.Println("    POST /evaluate          - Evaluate a transaction (?async=true to queue it)")
+	if cfg.Server.GRPCPort > 0 {
+		fmt.Printf("    gRPC :%d              - Bidirectional evaluation stream (osprey.v1.Evaluation)\n", cfg.Server.GRPCPort)
+	}
+	fmt.Println("    GET  /evaluations       - Search evaluations (?status=&debtor=&typology=&since=&cursor=)")
 	fmt.Println("    GET  /evaluations/{id}  - Get evaluation by ID")
+	fmt.Println("    GET  /evaluations/{id}/explain - Rules, typologies and velocity behind a decision")
+	fmt.Println("    POST /evaluations/{id}/outcome - Report a challenge result, return or chargeback")
+	fmt.Println("    GET  /outcomes          - List reported outcomes (?party=&outcome=&label=)")
+	fmt.Println("    POST /outcomes/import   - Import chargebacks and returns by transaction")
+	fmt.Println("    GET  /outcomes/losses   - Losses by rule and typology (?since=)")
 	fmt.Println("    GET  /transactions/{id} - Get transaction by ID")
+	fmt.Println("    GET  /entities/{id}/transactions - Entity transaction history (?since=&type=&minAmount=)")
+	fmt.Println("    GET  /entities/{id}/counterparties - Entity's counterparty network edges (?since=)")
+	fmt.Println("    GET  /transaction-types - Allowed and unknown transaction types")
 	fmt.Println("    GET  /rules             - List all rules")
 	fmt.Println("    POST /rules             - Create a new rule")
+	fmt.Println("    PUT  /rules/{id}        - Update a rule and reload")
+	fmt.Println("    DELETE /rules/{id}      - Delete a rule and reload")
+	fmt.Println("    GET  /rules/{id}/samples - Sampled rule activations")
 	fmt.Println("    POST /rules/reload      - Hot-reload rules from database")
+	fmt.Println("    POST /rules/backtest    - Replay stored transactions through a candidate rule")
+	fmt.Println("    POST /rules/{id}/test   - Score a rule or ad-hoc expression against a sample transaction")
 	if cfg.EvaluationMode == domain.ModeCompliance {
 		fmt.Println("    GET  /typologies        - List all typologies")
 		fmt.Println("    POST /typologies        - Create a new typology")
@@ -314,7 +873,72 @@ func printBanner(cfg *domain.Config, version string) {
 		fmt.Println("    DELETE /typologies/{id} - Delete a typology")
 		fmt.Println("    POST /typologies/reload - Hot-reload typologies")
 	}
+	fmt.Println("    GET  /customers         - List customer risk profiles (?riskRating=&segment=)")
+	fmt.Println("    POST /customers         - Create a customer risk profile")
+	fmt.Println("    GET  /parties/{id}      - Get party KYC profile")
+	fmt.Println("    PUT  /parties/{id}      - Upsert party KYC profile")
+	fmt.Println("    GET  /balances/{accountId} - Get tracked account balance")
+	fmt.Println("    PUT  /balances/{accountId} - Set account balance from the ledger")
+	fmt.Println("    GET  /refdata/corridors - List corridor risk overrides")
+	fmt.Println("    PUT  /refdata/corridors/{origin}/{destination} - Set corridor risk")
+	fmt.Println("    GET  /lists             - List watchlist entries (?type=sanctions)")
+	fmt.Println("    POST /lists             - Add a party to a watchlist")
+	fmt.Println("    POST /jobs/batch        - Evaluate a CSV transactions file")
+	fmt.Println("    POST /jobs/reevaluate   - Re-evaluate stored transactions (throttled)")
+	fmt.Println("    GET  /jobs/{id}         - Get job progress")
+	fmt.Println("    GET  /alerts            - Search alerts (?status=open&assignee=...)")
+	fmt.Println("    PATCH /alerts/{id}      - Update alert status, assignee or notes")
+	fmt.Println("    POST /alerts/{id}/ack   - Acknowledge an alert")
+	fmt.Println("    GET  /review-queue      - Held transactions, alerts and cases by urgency")
+	fmt.Println("    POST /review-queue/{id}/claim - Claim a review item (and /release)")
+	fmt.Println("    POST /webhooks          - Register an alert webhook")
+	fmt.Println("    GET  /webhooks/{id}/deliveries - Webhook delivery log")
+	fmt.Println("    GET  /dlq               - Messages the async worker gave up on (?pending=true)")
+	fmt.Println("    POST /dlq/{id}/replay   - Requeue a dead-lettered message")
+	fmt.Println("    POST /maintenance       - Schedule a window that suppresses alert notifications")
+	fmt.Println("    GET  /state             - Export rules and typologies")
+	fmt.Println("    PUT  /state             - Declaratively apply rules and typologies (?dryRun=true)")
+	if cfg.GitSync.Repo != "" {
+		fmt.Println("    GET  /gitsync           - Git sync status")
+		fmt.Println("    POST /gitsync/sync      - Sync rules and typologies from Git now")
+		fmt.Println("    POST /gitsync/webhook   - Push webhook from the Git host")
+	}
+	if cfg.RuleDir.Dir != "" {
+		fmt.Println("    GET  /ruledir           - Rule directory status")
+		fmt.Println("    POST /ruledir/sync      - Apply the rule directory now")
+	}
+	fmt.Println("    GET  /features          - List effective feature flags")
+	fmt.Println("    PUT  /features/{name}   - Enable or disable a feature flag")
+	fmt.Println("    GET  /config/scoring    - Alert threshold and scoring in effect for the tenant")
+	fmt.Println("    PUT  /config/scoring    - Set the tenant's alert threshold and scoring")
+	fmt.Println("    GET  /config/retention  - How long the tenant's activity is kept")
+	fmt.Println("    PUT  /config/retention  - Set the tenant's retention policy")
+	if cfg.EvaluationMode == domain.ModeCompliance {
+		fmt.Println("    GET  /audit/evaluations/verify - Verify the evaluation log chain")
+	}
 	fmt.Println("    GET  /health            - Health check")
+	fmt.Println("    GET  /info              - Build and configuration details (JSON)")
+	if cfg.Signing.KeyFile != "" {
+		fmt.Println("    GET  /.well-known/jwks.json - Key that verifies X-JWS-Signature")
+	}
+	fmt.Println("    GET  /metrics           - Async queue lag, SLO and circuit breaker metrics (Prometheus)")
+	fmt.Println("    GET  /openapi.json      - OpenAPI document of the API")
+	fmt.Println("    POST /drain             - Fail readiness before shutdown (preStop hook)")
+	if len(cfg.SLO.Objectives) > 0 {
+		fmt.Println("    GET  /slo               - Error budgets and burn rates of the endpoint SLOs")
+	}
+	fmt.Println("    GET  /stats/top         - Heaviest tenants and entities by evaluations and alerts")
+	fmt.Println("    GET  /admin/tenants/health - Per-tenant rules, alert rate and last evaluation (?sandbox=true)")
+	fmt.Println("    GET  /admin/indexes     - Recommend indexes for the velocity and list queries")
+	fmt.Println("    POST /admin/indexes     - Create recommended indexes")
+	fmt.Println("    GET  /admin/isolation   - Audit cross-tenant references")
+	fmt.Println("    POST /admin/isolation   - Repair cross-tenant references")
+	fmt.Println("    GET  /admin/retention/runs - Latest retention runs on this instance")
+	fmt.Println("    POST /admin/retention/runs - Expire activity by the retention policies now")
+	if cfg.Migration.Target.Driver != "" {
+		fmt.Println("    POST /admin/migrations  - Copy a tenant to the migration target (cutover: freeze writes)")
+		fmt.Println("    GET  /admin/migrations/{tenantId} - Migration status and consistency report")
+	}
 	fmt.Println()
 }
 
@@ -353,6 +977,15 @@ func applyEnvOverrides(cfg *domain.Config) {
 		cfg.Cache.Type = cacheType
 	}
 
+	if ttl := os.Getenv("OSPREY_CACHE_EVALUATION_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			slog.Error("invalid OSPREY_CACHE_EVALUATION_TTL", "error", err)
+			os.Exit(1)
+		}
+		cfg.Cache.EvaluationTTL = d
+	}
+
 	// Redis settings
 	if addr := os.Getenv("OSPREY_REDIS_ADDR"); addr != "" {
 		cfg.Cache.RedisAddr = addr
@@ -370,11 +1003,33 @@ func applyEnvOverrides(cfg *domain.Config) {
 	if busType := os.Getenv("OSPREY_BUS_TYPE"); busType != "" {
 		cfg.EventBus.Type = busType
 	}
+	if busSync := os.Getenv("OSPREY_BUS_SYNC"); busSync != "" {
+		cfg.EventBus.ChannelSynchronous = busSync == "true"
+	}
 
 	// NATS settings
 	if url := os.Getenv("OSPREY_NATS_URL"); url != "" {
 		cfg.EventBus.NATSUrl = url
 	}
+	if jetStream := os.Getenv("OSPREY_NATS_JETSTREAM"); jetStream != "" {
+		cfg.EventBus.NATSJetStream = jetStream == "true"
+	}
+	if stream := os.Getenv("OSPREY_NATS_STREAM"); stream != "" {
+		cfg.EventBus.NATSStream = stream
+	}
+	if maxDeliver := os.Getenv("OSPREY_NATS_MAX_DELIVER"); maxDeliver != "" {
+		if n, err := strconv.Atoi(maxDeliver); err == nil {
+			cfg.EventBus.NATSMaxDeliver = n
+		}
+	}
+	if ackWait := os.Getenv("OSPREY_NATS_ACK_WAIT"); ackWait != "" {
+		d, err := time.ParseDuration(ackWait)
+		if err != nil {
+			slog.Error("invalid OSPREY_NATS_ACK_WAIT", "error", err)
+			os.Exit(1)
+		}
+		cfg.EventBus.NATSAckWait = d
+	}
 
 	// Server settings
 	if port := os.Getenv("OSPREY_PORT"); port != "" {
@@ -382,7 +1037,637 @@ func applyEnvOverrides(cfg *domain.Config) {
 			cfg.Server.Port = p
 		}
 	}
+	if port := os.Getenv("OSPREY_GRPC_PORT"); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			cfg.Server.GRPCPort = p
+		}
+	}
 	if host := os.Getenv("OSPREY_HOST"); host != "" {
 		cfg.Server.Host = host
 	}
+	if path := os.Getenv("OSPREY_READY_FILE"); path != "" {
+		cfg.Server.ReadyFile = path
+	}
+	if delay := os.Getenv("OSPREY_DRAIN_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil || d < 0 {
+			slog.Error("invalid OSPREY_DRAIN_DELAY", "value", delay)
+			os.Exit(1)
+		}
+		cfg.Server.DrainDelay = d
+	}
+	if skew := os.Getenv("OSPREY_TX_MAX_CLOCK_SKEW"); skew != "" {
+		d, err := time.ParseDuration(skew)
+		if err != nil || d < 0 {
+			slog.Error("invalid OSPREY_TX_MAX_CLOCK_SKEW", "value", skew)
+			os.Exit(1)
+		}
+		cfg.Server.MaxClockSkew = d
+	}
+	if age := os.Getenv("OSPREY_TX_MAX_AGE"); age != "" {
+		d, err := time.ParseDuration(age)
+		if err != nil || d < 0 {
+			slog.Error("invalid OSPREY_TX_MAX_AGE", "value", age)
+			os.Exit(1)
+		}
+		cfg.Server.MaxTransactionAge = d
+	}
+	if networks := os.Getenv("OSPREY_ADMIN_NETWORKS"); networks != "" {
+		cfg.Server.AdminNetworks = strings.Split(networks, ",")
+	}
+	if trust := os.Getenv("OSPREY_TRUST_PROXY_HEADERS"); trust != "" {
+		cfg.Server.TrustProxyHeaders = trust == "true"
+	}
+	if global := os.Getenv("OSPREY_GLOBAL_TENANT_WRITES"); global != "" {
+		cfg.Server.GlobalTenantWrites = global == "true"
+	}
+	if origins := os.Getenv("OSPREY_CORS_ORIGINS"); origins != "" {
+		cfg.Server.CORS.AllowedOrigins = strings.Split(origins, ",")
+	}
+	if tenantOrigins := os.Getenv("OSPREY_CORS_TENANT_ORIGINS"); tenantOrigins != "" {
+		parsed, err := api.ParseTenantOrigins(tenantOrigins)
+		if err != nil {
+			slog.Error("invalid OSPREY_CORS_TENANT_ORIGINS", "error", err)
+			os.Exit(1)
+		}
+		cfg.Server.CORS.TenantOrigins = parsed
+	}
+	if credentials := os.Getenv("OSPREY_CORS_CREDENTIALS"); credentials != "" {
+		cfg.Server.CORS.AllowCredentials = credentials == "true"
+	}
+
+	// Startup banner
+	if banner := os.Getenv("OSPREY_BANNER"); banner != "" {
+		cfg.Banner = banner == "true"
+	}
+
+	// Log redaction settings
+	if mode := os.Getenv("OSPREY_LOG_REDACTION"); mode != "" {
+		cfg.Logging.Redaction.Mode = strings.ToLower(mode)
+	}
+	if fields := os.Getenv("OSPREY_LOG_REDACT_FIELDS"); fields != "" {
+		parsed, err := logging.ParseFields(fields)
+		if err != nil {
+			slog.Error("invalid OSPREY_LOG_REDACT_FIELDS", "error", err)
+			os.Exit(1)
+		}
+		cfg.Logging.Redaction.Fields = parsed
+	}
+	if salt := os.Getenv("OSPREY_LOG_REDACT_SALT"); salt != "" {
+		cfg.Logging.Redaction.Salt = salt
+	}
+
+	// Feature flag defaults, e.g. "ml_hook=true,canary_rules=false"
+	if flags := os.Getenv("OSPREY_FEATURES"); flags != "" {
+		parsed, err := features.ParseDefaults(flags)
+		if err != nil {
+			slog.Error("invalid OSPREY_FEATURES", "error", err)
+			os.Exit(1)
+		}
+		cfg.Features = parsed
+	}
+
+	// Alert escalation
+	if window := os.Getenv("OSPREY_ALERT_ACK_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			slog.Error("invalid OSPREY_ALERT_ACK_WINDOW", "error", err)
+			os.Exit(1)
+		}
+		cfg.Alerts.AckWindow = d
+	}
+	if maxEscalations := os.Getenv("OSPREY_ALERT_MAX_ESCALATIONS"); maxEscalations != "" {
+		if n, err := strconv.Atoi(maxEscalations); err == nil {
+			cfg.Alerts.MaxEscalations = n
+		}
+	}
+	if interval := os.Getenv("OSPREY_ALERT_CHECK_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			slog.Error("invalid OSPREY_ALERT_CHECK_INTERVAL", "error", err)
+			os.Exit(1)
+		}
+		cfg.Alerts.CheckInterval = d
+	}
+
+	// Review queue deadlines and claim lease
+	for _, setting := range []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"OSPREY_REVIEW_HOLD_SLA", &cfg.Review.HoldSLA},
+		{"OSPREY_REVIEW_ALERT_SLA", &cfg.Review.AlertSLA},
+		{"OSPREY_REVIEW_CASE_SLA", &cfg.Review.CaseSLA},
+		{"OSPREY_REVIEW_HOLD_WINDOW", &cfg.Review.HoldWindow},
+		{"OSPREY_REVIEW_CLAIM_TTL", &cfg.Review.ClaimTTL},
+	} {
+		v := os.Getenv(setting.env)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			slog.Error("invalid "+setting.env, "value", v)
+			os.Exit(1)
+		}
+		*setting.dst = d
+	}
+
+	// Async queue lag thresholds
+	if maxLag := os.Getenv("OSPREY_QUEUE_MAX_LAG"); maxLag != "" {
+		d, err := time.ParseDuration(maxLag)
+		if err != nil {
+			slog.Error("invalid OSPREY_QUEUE_MAX_LAG", "error", err)
+			os.Exit(1)
+		}
+		cfg.Queue.MaxLag = d
+	}
+	if maxBacklog := os.Getenv("OSPREY_QUEUE_MAX_BACKLOG"); maxBacklog != "" {
+		if n, err := strconv.Atoi(maxBacklog); err == nil {
+			cfg.Queue.MaxBacklog = n
+		}
+	}
+
+	if retries := os.Getenv("OSPREY_QUEUE_RETRIES"); retries != "" {
+		if n, err := strconv.Atoi(retries); err == nil {
+			cfg.Queue.Retries = n
+		}
+	}
+	if backoff := os.Getenv("OSPREY_QUEUE_RETRY_BACKOFF"); backoff != "" {
+		d, err := time.ParseDuration(backoff)
+		if err != nil {
+			slog.Error("invalid OSPREY_QUEUE_RETRY_BACKOFF", "error", err)
+			os.Exit(1)
+		}
+		cfg.Queue.RetryBackoff = d
+	}
+
+	if lanes := os.Getenv("OSPREY_QUEUE_LANES"); lanes != "" {
+		parsed, err := worker.ParseLanes(lanes)
+		if err != nil {
+			slog.Error("invalid OSPREY_QUEUE_LANES", "error", err)
+			os.Exit(1)
+		}
+		cfg.Queue.Lanes.Capacity = parsed
+	}
+	if highValue := os.Getenv("OSPREY_QUEUE_HIGH_VALUE"); highValue != "" {
+		if v, err := strconv.ParseFloat(highValue, 64); err == nil {
+			cfg.Queue.Lanes.HighValueAmount = v
+		}
+	}
+	if batchTypes := os.Getenv("OSPREY_QUEUE_BATCH_TYPES"); batchTypes != "" {
+		cfg.Queue.Lanes.BatchTypes = strings.Split(batchTypes, ",")
+	}
+
+	// Service level objectives
+	if objectives := os.Getenv("OSPREY_SLO_OBJECTIVES"); objectives != "" {
+		parsed, err := slo.ParseObjectives(objectives)
+		if err != nil {
+			slog.Error("invalid OSPREY_SLO_OBJECTIVES", "error", err)
+			os.Exit(1)
+		}
+		cfg.SLO.Objectives = parsed
+	}
+	if window := os.Getenv("OSPREY_SLO_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			slog.Error("invalid OSPREY_SLO_WINDOW", "error", err)
+			os.Exit(1)
+		}
+		cfg.SLO.Window = d
+	}
+	if window := os.Getenv("OSPREY_STATS_TOP_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_STATS_TOP_WINDOW", "value", window)
+			os.Exit(1)
+		}
+		cfg.Stats.TopWindow = d
+	}
+	if epsilon := os.Getenv("OSPREY_STATS_EPSILON"); epsilon != "" {
+		e, err := strconv.ParseFloat(epsilon, 64)
+		if err != nil || e < 0 {
+			slog.Error("invalid OSPREY_STATS_EPSILON", "value", epsilon)
+			os.Exit(1)
+		}
+		cfg.Stats.Epsilon = e
+	}
+	if cohort := os.Getenv("OSPREY_STATS_MIN_COHORT"); cohort != "" {
+		k, err := strconv.Atoi(cohort)
+		if err != nil || k < 0 {
+			slog.Error("invalid OSPREY_STATS_MIN_COHORT", "value", cohort)
+			os.Exit(1)
+		}
+		cfg.Stats.MinCohort = k
+	}
+
+	// Default scoring
+	if threshold := os.Getenv("OSPREY_ALERT_THRESHOLD"); threshold != "" {
+		if v, err := strconv.ParseFloat(threshold, 64); err == nil && v > 0 && v <= 1 {
+			cfg.Scoring.AlertThreshold = v
+		}
+	}
+	if weighted := os.Getenv("OSPREY_WEIGHTED_SCORING"); weighted != "" {
+		cfg.Scoring.WeightedScoring = weighted == "true"
+	}
+	if criticalFail := os.Getenv("OSPREY_CRITICAL_FAIL"); criticalFail != "" {
+		if !domain.ValidCriticalFail(criticalFail) {
+			slog.Error("invalid OSPREY_CRITICAL_FAIL", "value", criticalFail, "valid", "alert, score")
+			os.Exit(1)
+		}
+		cfg.Scoring.CriticalFail = criticalFail
+	}
+	if weight := os.Getenv("OSPREY_ML_WEIGHT"); weight != "" {
+		v, err := strconv.ParseFloat(weight, 64)
+		if err != nil || v < 0 || v > 1 {
+			slog.Error("invalid OSPREY_ML_WEIGHT", "value", weight)
+			os.Exit(1)
+		}
+		cfg.Scoring.MLWeight = v
+	}
+	if blend := os.Getenv("OSPREY_ML_BLEND"); blend != "" {
+		if !domain.ValidMLBlend(blend) {
+			slog.Error("invalid OSPREY_ML_BLEND", "value", blend, "valid", "weighted, max")
+			os.Exit(1)
+		}
+		cfg.Scoring.MLBlend = blend
+	}
+
+	// External ML model
+	if url := os.Getenv("OSPREY_ML_URL"); url != "" {
+		cfg.ML.URL = url
+	}
+	if protocol := os.Getenv("OSPREY_ML_PROTOCOL"); protocol != "" {
+		cfg.ML.Protocol = protocol
+	}
+	if method := os.Getenv("OSPREY_ML_METHOD"); method != "" {
+		cfg.ML.Method = method
+	}
+	if timeout := os.Getenv("OSPREY_ML_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_ML_TIMEOUT", "value", timeout)
+			os.Exit(1)
+		}
+		cfg.ML.Timeout = d
+	}
+	if fallback := os.Getenv("OSPREY_ML_FALLBACK"); fallback != "" {
+		if !domain.ValidMLFallback(fallback) {
+			slog.Error("invalid OSPREY_ML_FALLBACK", "value", fallback, "valid", "rules, alert")
+			os.Exit(1)
+		}
+		cfg.ML.Fallback = fallback
+	}
+
+	// Velocity windows
+	if window := os.Getenv("OSPREY_VELOCITY_WINDOW"); window != "" {
+		d, err := velocity.ParseWindow(window)
+		if err != nil {
+			slog.Error("invalid OSPREY_VELOCITY_WINDOW", "error", err)
+			os.Exit(1)
+		}
+		cfg.Velocity.DefaultWindow = d
+	}
+	if tenantWindows := os.Getenv("OSPREY_VELOCITY_TENANT_WINDOWS"); tenantWindows != "" {
+		parsed, err := velocity.ParseTenantWindows(tenantWindows)
+		if err != nil {
+			slog.Error("invalid OSPREY_VELOCITY_TENANT_WINDOWS", "error", err)
+			os.Exit(1)
+		}
+		cfg.Velocity.TenantWindows = parsed
+	}
+	if reconcile := os.Getenv("OSPREY_VELOCITY_RECONCILE"); reconcile != "" {
+		d, err := time.ParseDuration(reconcile)
+		if err != nil {
+			slog.Error("invalid OSPREY_VELOCITY_RECONCILE", "error", err)
+			os.Exit(1)
+		}
+		cfg.Velocity.Reconcile = d
+	}
+	if writeThrough := os.Getenv("OSPREY_VELOCITY_WRITE_THROUGH"); writeThrough != "" {
+		cfg.Velocity.WriteThrough = writeThrough == "true"
+	}
+
+	// Counterparty network
+	if window := os.Getenv("OSPREY_GRAPH_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			slog.Error("invalid OSPREY_GRAPH_WINDOW", "error", err)
+			os.Exit(1)
+		}
+		cfg.Graph.Window = d
+	}
+	if hops := os.Getenv("OSPREY_GRAPH_MAX_HOPS"); hops != "" {
+		if n, err := strconv.Atoi(hops); err == nil {
+			cfg.Graph.MaxHops = n
+		}
+	}
+
+	// Behavioral baselines
+	if span := os.Getenv("OSPREY_BASELINE_SPAN"); span != "" {
+		n, err := strconv.Atoi(span)
+		if err != nil || n < 1 {
+			slog.Error("invalid OSPREY_BASELINE_SPAN", "value", span)
+			os.Exit(1)
+		}
+		cfg.Baseline.Span = n
+	}
+	if samples := os.Getenv("OSPREY_BASELINE_MIN_SAMPLES"); samples != "" {
+		n, err := strconv.Atoi(samples)
+		if err != nil || n < 1 {
+			slog.Error("invalid OSPREY_BASELINE_MIN_SAMPLES", "value", samples)
+			os.Exit(1)
+		}
+		cfg.Baseline.MinSamples = n
+	}
+
+	// FX conversion
+	if source := os.Getenv("OSPREY_FX_SOURCE"); source != "" {
+		cfg.FX.Source = source
+	}
+	if url := os.Getenv("OSPREY_FX_URL"); url != "" {
+		cfg.FX.URL = url
+	}
+	if rates := os.Getenv("OSPREY_FX_RATES"); rates != "" {
+		parsed, err := fx.ParseRates(rates)
+		if err != nil {
+			slog.Error("invalid OSPREY_FX_RATES", "error", err)
+			os.Exit(1)
+		}
+		cfg.FX.Rates = parsed
+	}
+	if refresh := os.Getenv("OSPREY_FX_REFRESH"); refresh != "" {
+		d, err := time.ParseDuration(refresh)
+		if err != nil {
+			slog.Error("invalid OSPREY_FX_REFRESH", "error", err)
+			os.Exit(1)
+		}
+		cfg.FX.Refresh = d
+	}
+	if base := os.Getenv("OSPREY_FX_BASE_CURRENCY"); base != "" {
+		cfg.FX.BaseCurrency = fx.Normalize(base)
+	}
+	if tenantBases := os.Getenv("OSPREY_FX_TENANT_BASE_CURRENCIES"); tenantBases != "" {
+		parsed, err := fx.ParseTenantBaseCurrencies(tenantBases)
+		if err != nil {
+			slog.Error("invalid OSPREY_FX_TENANT_BASE_CURRENCIES", "error", err)
+			os.Exit(1)
+		}
+		cfg.FX.TenantBaseCurrencies = parsed
+	}
+
+	// Response signing
+	if keyFile := os.Getenv("OSPREY_SIGNING_KEY_FILE"); keyFile != "" {
+		cfg.Signing.KeyFile = keyFile
+	}
+
+	// Tenant migration target; a host alone selects PostgreSQL
+	if driver := os.Getenv("OSPREY_MIGRATION_DB_DRIVER"); driver != "" {
+		cfg.Migration.Target.Driver = driver
+	}
+	if host := os.Getenv("OSPREY_MIGRATION_POSTGRES_HOST"); host != "" {
+		cfg.Migration.Target.PostgresHost = host
+		if cfg.Migration.Target.Driver == "" {
+			cfg.Migration.Target.Driver = "postgres"
+		}
+	}
+	if port := os.Getenv("OSPREY_MIGRATION_POSTGRES_PORT"); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			cfg.Migration.Target.PostgresPort = p
+		}
+	}
+	if user := os.Getenv("OSPREY_MIGRATION_POSTGRES_USER"); user != "" {
+		cfg.Migration.Target.PostgresUser = user
+	}
+	if password := os.Getenv("OSPREY_MIGRATION_POSTGRES_PASSWORD"); password != "" {
+		cfg.Migration.Target.PostgresPassword = password
+	}
+	if db := os.Getenv("OSPREY_MIGRATION_POSTGRES_DB"); db != "" {
+		cfg.Migration.Target.PostgresDB = db
+	}
+	if sslMode := os.Getenv("OSPREY_MIGRATION_POSTGRES_SSLMODE"); sslMode != "" {
+		cfg.Migration.Target.PostgresSSLMode = sslMode
+	}
+
+	// Sandbox tenants
+	if tenants := os.Getenv("OSPREY_SANDBOX_TENANTS"); tenants != "" {
+		cfg.Sandbox.Tenants = strings.Split(tenants, ",")
+	}
+	if ttl := os.Getenv("OSPREY_SANDBOX_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			slog.Error("invalid OSPREY_SANDBOX_TTL", "error", err)
+			os.Exit(1)
+		}
+		cfg.Sandbox.TTL = d
+	}
+
+	// Retention of tenants' activity
+	retentionDays := []struct {
+		env  string
+		days *int
+	}{
+		{"OSPREY_RETENTION_TRANSACTION_DAYS", &cfg.Retention.Defaults.TransactionDays},
+		{"OSPREY_RETENTION_EVALUATION_DAYS", &cfg.Retention.Defaults.EvaluationDays},
+		{"OSPREY_RETENTION_ALERT_DAYS", &cfg.Retention.Defaults.AlertDays},
+	}
+	for _, d := range retentionDays {
+		if days := os.Getenv(d.env); days != "" {
+			n, err := strconv.Atoi(days)
+			if err != nil || n < 0 {
+				slog.Error("invalid "+d.env, "value", days)
+				os.Exit(1)
+			}
+			*d.days = n
+		}
+	}
+	if interval := os.Getenv("OSPREY_RETENTION_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_RETENTION_INTERVAL", "value", interval)
+			os.Exit(1)
+		}
+		cfg.Retention.Interval = d
+	}
+	if dir := os.Getenv("OSPREY_RETENTION_ARCHIVE_DIR"); dir != "" {
+		cfg.Retention.ArchiveDir = dir
+	}
+	if archive := os.Getenv("OSPREY_RETENTION_ARCHIVE"); archive != "" {
+		cfg.Retention.Defaults.Archive = archive == "true"
+	}
+	if compact := os.Getenv("OSPREY_RETENTION_COMPACT"); compact != "" {
+		cfg.Retention.Compact = compact == "true"
+	}
+
+	// Allowed transaction types
+	if allowed := os.Getenv("OSPREY_TX_TYPES"); allowed != "" {
+		parsed, err := txtypes.ParseAllowed(allowed)
+		if err != nil {
+			slog.Error("invalid OSPREY_TX_TYPES", "error", err)
+			os.Exit(1)
+		}
+		cfg.TxTypes.Allowed = parsed
+	}
+	if unknown := os.Getenv("OSPREY_TX_TYPES_UNKNOWN"); unknown != "" {
+		action, err := txtypes.ParseAction(unknown)
+		if err != nil {
+			slog.Error("invalid OSPREY_TX_TYPES_UNKNOWN", "error", err)
+			os.Exit(1)
+		}
+		cfg.TxTypes.Unknown = action
+	}
+
+	// Caps on rule and typology changes
+	if caps := os.Getenv("OSPREY_GUARDRAILS"); caps != "" {
+		parsed, err := guardrails.ParseCaps(caps)
+		if err != nil {
+			slog.Error("invalid OSPREY_GUARDRAILS", "error", err)
+			os.Exit(1)
+		}
+		cfg.Guardrails.Caps = parsed
+	}
+
+	// Webhook delivery
+	if maxAttempts := os.Getenv("OSPREY_WEBHOOK_MAX_ATTEMPTS"); maxAttempts != "" {
+		if n, err := strconv.Atoi(maxAttempts); err == nil {
+			cfg.Webhooks.MaxAttempts = n
+		}
+	}
+	if timeout := os.Getenv("OSPREY_WEBHOOK_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			slog.Error("invalid OSPREY_WEBHOOK_TIMEOUT", "error", err)
+			os.Exit(1)
+		}
+		cfg.Webhooks.Timeout = d
+	}
+	if backoff := os.Getenv("OSPREY_WEBHOOK_BACKOFF"); backoff != "" {
+		d, err := time.ParseDuration(backoff)
+		if err != nil {
+			slog.Error("invalid OSPREY_WEBHOOK_BACKOFF", "error", err)
+			os.Exit(1)
+		}
+		cfg.Webhooks.InitialBackoff = d
+	}
+	if maxBackoff := os.Getenv("OSPREY_WEBHOOK_MAX_BACKOFF"); maxBackoff != "" {
+		d, err := time.ParseDuration(maxBackoff)
+		if err != nil {
+			slog.Error("invalid OSPREY_WEBHOOK_MAX_BACKOFF", "error", err)
+			os.Exit(1)
+		}
+		cfg.Webhooks.MaxBackoff = d
+	}
+	if digests := os.Getenv("OSPREY_WEBHOOK_DIGEST"); digests != "" {
+		parsed, err := webhooks.ParseDigests(digests)
+		if err != nil {
+			slog.Error("invalid OSPREY_WEBHOOK_DIGEST", "error", err)
+			os.Exit(1)
+		}
+		cfg.Webhooks.Digests = parsed
+	}
+
+	// Enrichment plugins
+	if dir := os.Getenv("OSPREY_PLUGIN_DIR"); dir != "" {
+		cfg.Plugins.Dir = dir
+	}
+	if timeout := os.Getenv("OSPREY_PLUGIN_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			slog.Error("invalid OSPREY_PLUGIN_TIMEOUT", "error", err)
+			os.Exit(1)
+		}
+		cfg.Plugins.Timeout = d
+	}
+
+	// Git sync
+	if repoURL := os.Getenv("OSPREY_GITSYNC_REPO"); repoURL != "" {
+		cfg.GitSync.Repo = repoURL
+	}
+	if branch := os.Getenv("OSPREY_GITSYNC_BRANCH"); branch != "" {
+		cfg.GitSync.Branch = branch
+	}
+	if path := os.Getenv("OSPREY_GITSYNC_PATH"); path != "" {
+		cfg.GitSync.Path = path
+	}
+	if dir := os.Getenv("OSPREY_GITSYNC_DIR"); dir != "" {
+		cfg.GitSync.Dir = dir
+	}
+	if interval := os.Getenv("OSPREY_GITSYNC_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			slog.Error("invalid OSPREY_GITSYNC_INTERVAL", "error", err)
+			os.Exit(1)
+		}
+		cfg.GitSync.Interval = d
+	}
+	if secret := os.Getenv("OSPREY_GITSYNC_WEBHOOK_SECRET"); secret != "" {
+		cfg.GitSync.WebhookSecret = secret
+	}
+
+	// Rule directory
+	if dir := os.Getenv("OSPREY_RULE_DIR"); dir != "" {
+		cfg.RuleDir.Dir = dir
+	}
+	if interval := os.Getenv("OSPREY_RULE_DIR_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_RULE_DIR_INTERVAL", "value", interval)
+			os.Exit(1)
+		}
+		cfg.RuleDir.Interval = d
+	}
+
+	// Cluster
+	if delay := os.Getenv("OSPREY_CLUSTER_RELOAD_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil || d < 0 {
+			slog.Error("invalid OSPREY_CLUSTER_RELOAD_DELAY", "value", delay)
+			os.Exit(1)
+		}
+		cfg.Cluster.ReloadDelay = d
+	}
+
+	// Repository circuit breaker
+	if policy := os.Getenv("OSPREY_BREAKER_POLICY"); policy != "" {
+		if !breaker.ValidPolicy(policy) {
+			slog.Error("invalid OSPREY_BREAKER_POLICY, must be queue or fail-fast", "value", policy)
+			os.Exit(1)
+		}
+		cfg.Breaker.Policy = policy
+	}
+	if failures := os.Getenv("OSPREY_BREAKER_FAILURES"); failures != "" {
+		n, err := strconv.Atoi(failures)
+		if err != nil || n <= 0 {
+			slog.Error("invalid OSPREY_BREAKER_FAILURES", "value", failures)
+			os.Exit(1)
+		}
+		cfg.Breaker.Failures = n
+	}
+	if cooldown := os.Getenv("OSPREY_BREAKER_COOLDOWN"); cooldown != "" {
+		d, err := time.ParseDuration(cooldown)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_BREAKER_COOLDOWN", "value", cooldown)
+			os.Exit(1)
+		}
+		cfg.Breaker.Cooldown = d
+	}
+	if timeout := os.Getenv("OSPREY_BREAKER_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			slog.Error("invalid OSPREY_BREAKER_TIMEOUT", "value", timeout)
+			os.Exit(1)
+		}
+		cfg.Breaker.Timeout = d
+	}
+	if walPath := os.Getenv("OSPREY_BREAKER_WAL"); walPath != "" {
+		cfg.Breaker.WALPath = walPath
+	}
+	if maxQueued := os.Getenv("OSPREY_BREAKER_MAX_QUEUED"); maxQueued != "" {
+		n, err := strconv.Atoi(maxQueued)
+		if err != nil || n < 0 {
+			slog.Error("invalid OSPREY_BREAKER_MAX_QUEUED", "value", maxQueued)
+			os.Exit(1)
+		}
+		cfg.Breaker.MaxQueued = n
+	}
 }
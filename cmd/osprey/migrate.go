@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/migration"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// runMigrate copies each named tenant from the configured repository to the
+// migration target, printing a JSON report per tenant. It returns the exit
+// code: 0 when every tenant is consistent, 1 otherwise.
+//
+// The CLI can't freeze a running server's tenants, so run it with the
+// server stopped, or use POST /admin/migrations with "cutover": true.
+func runMigrate(cfg *domain.Config, tenants []string) int {
+	if len(tenants) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: osprey migrate TENANT_ID...")
+		return 2
+	}
+	if cfg.Migration.Target.Driver == "" {
+		fmt.Fprintln(os.Stderr, "no migration target: set OSPREY_MIGRATION_POSTGRES_HOST or OSPREY_MIGRATION_DB_DRIVER")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	source, err := repository.New(cfg.Repository)
+	if err != nil {
+		slog.Error("failed to initialize repository", "error", err)
+		return 1
+	}
+	defer source.Close()
+	target, err := repository.New(cfg.Migration.Target)
+	if err != nil {
+		slog.Error("failed to initialize migration target", "error", err)
+		return 1
+	}
+	defer target.Close()
+
+	migrator := migration.New(source, target)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	code := 0
+	for _, tenantID := range tenants {
+		report, err := migrator.Run(ctx, tenantID)
+		if err != nil {
+			slog.Error("tenant migration failed", "tenant_id", tenantID, "error", err)
+			code = 1
+			continue
+		}
+		encoder.Encode(report)
+		if !report.Consistent {
+			slog.Error("record counts differ between source and target", "tenant_id", tenantID)
+			code = 1
+		}
+	}
+	return code
+}
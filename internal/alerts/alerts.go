@@ -0,0 +1,313 @@
+// Package alerts tracks acknowledgment and disposition of ALRT evaluations and
+// escalates alerts nobody acknowledges in time.
+//
+// Every saved ALRT evaluation becomes an open alert. Analysts assign it, add
+// notes and move it through investigating to a closed disposition; every
+// change is kept in the alert's history. When the acknowledgment window
+// passes without an ack, the escalator publishes an AlertEscalation on
+// TopicAlertEscalated and restarts the window, up to MaxEscalations times.
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// escalationBatch bounds how many due alerts are handled per check.
+const escalationBatch = 500
+
+var (
+	// ErrInvalidTransition is returned when an alert can't move to the requested status.
+	ErrInvalidTransition = errors.New("invalid alert status transition")
+
+	// ErrConflict is returned when an alert's status changed during an update.
+	ErrConflict = errors.New("alert was updated concurrently")
+)
+
+// Update is a change to an alert's disposition. Zero fields are left unchanged.
+type Update struct {
+	Status   string  // New status
+	Assignee *string // New assignee; "" unassigns
+	Note     string  // Added to the alert's history
+}
+// Repository records an alert for every ALRT evaluation it saves.
+type Repository struct {
+	domain.Repository
+}
+
+// Wrap returns repo with alert recording enabled.
+func Wrap(repo domain.Repository) *Repository {
+	return &Repository{Repository: repo}
+}
+
+// Unwrap returns the repository the alerts are recorded in.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveEvaluation saves the evaluation and, if it alerted, records an alert.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if err := r.Repository.SaveEvaluation(ctx, tenantID, eval); err != nil {
+		return err
+	}
+	if eval.Status != domain.StatusAlert {
+		return nil
+	}
+
+	// The evaluation's own publish to TopicAlert is the first notification
+	now := time.Now().UTC()
+	alert := &domain.Alert{
+		ID:             eval.ID,
+		TenantID:       tenantID,
+		TxID:           eval.TxID,
+		Score:          eval.Score,
+		Status:         domain.AlertOpen,
+		CreatedAt:      eval.Timestamp,
+		LastNotifiedAt: now,
+	}
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = now
+	}
+	alert.UpdatedAt = alert.CreatedAt
+	alert.SuppressedByMaintenance = eval.Metadata.SuppressedByMaintenance
+	if err := r.Repository.SaveAlert(ctx, tenantID, alert); err != nil {
+		return fmt.Errorf("failed to record alert: %w", err)
+	}
+
+	if alert.SuppressedByMaintenance != "" {
+		event := newEvent(alert.ID, domain.AlertEventSuppressed, "", now, "", alert.SuppressedByMaintenance, "")
+		if err := r.Repository.SaveAlertEvent(ctx, tenantID, event); err != nil {
+			slog.Error("failed to record alert history", "tenant_id", tenantID, "alert_id", alert.ID, "action", event.Action, "error", err)
+		}
+	}
+	return nil
+}
+
+// Service acknowledges alerts and escalates unacknowledged ones.
+type Service struct {
+	repo   domain.Repository
+	bus    domain.EventBus
+	policy domain.AlertConfig
+	now    func() time.Time
+}
+
+// NewService creates an alert service. bus may be nil, in which case
+// escalations are recorded but not published.
+func NewService(repo domain.Repository, bus domain.EventBus, policy domain.AlertConfig) *Service {
+	return &Service{repo: repo, bus: bus, policy: policy, now: time.Now}
+}
+
+// Enabled reports whether unacknowledged alerts are escalated.
+func (s *Service) Enabled() bool {
+	return s.policy.AckWindow > 0 && s.policy.MaxEscalations > 0
+}
+
+// Policy returns the escalation policy.
+func (s *Service) Policy() domain.AlertConfig {
+	return s.policy
+}
+
+// Get returns an alert with its history.
+func (s *Service) Get(ctx context.Context, tenantID, alertID string) (*domain.Alert, error) {
+	alert, err := s.repo.GetAlert(ctx, tenantID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.History, err = s.repo.ListAlertEvents(ctx, tenantID, alertID); err != nil {
+		return nil, fmt.Errorf("failed to list alert history: %w", err)
+	}
+	return alert, nil
+}
+
+// Ack acknowledges an alert. Acknowledging twice keeps the first ack and
+// returns the alert unchanged.
+func (s *Service) Ack(ctx context.Context, tenantID, alertID, by, note string) (*domain.Alert, error) {
+	now := s.now()
+	err := s.repo.AckAlert(ctx, tenantID, alertID, by, note, now)
+	if err == nil {
+		s.record(ctx, tenantID, newEvent(alertID, domain.AlertEventAck, by, now, "", "", note))
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	// ErrNotFound also covers an existing, already acknowledged alert
+	return s.Get(ctx, tenantID, alertID)
+}
+
+// Update changes an alert's status and assignee and records a note, keeping
+// each change in the alert's history. Moving an alert out of open also
+// acknowledges it, which stops escalation.
+func (s *Service) Update(ctx context.Context, tenantID, alertID, by string, update Update) (*domain.Alert, error) {
+	alert, err := s.repo.GetAlert(ctx, tenantID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+
+	status, assignee := alert.Status, alert.Assignee
+	var events []*domain.AlertEvent
+	if update.Status != "" && update.Status != alert.Status {
+		if !domain.AlertTransitionAllowed(alert.Status, update.Status) {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, alert.Status, update.Status)
+		}
+		status = update.Status
+		events = append(events, newEvent(alertID, domain.AlertEventStatus, by, now, alert.Status, status, ""))
+	}
+	if update.Assignee != nil && *update.Assignee != alert.Assignee {
+		assignee = *update.Assignee
+		events = append(events, newEvent(alertID, domain.AlertEventAssign, by, now, alert.Assignee, assignee, ""))
+	}
+
+	if len(events) > 0 {
+		err := s.repo.UpdateAlertCase(ctx, tenantID, alertID, alert.Status, status, assignee, now)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrConflict
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if update.Note != "" {
+		events = append(events, newEvent(alertID, domain.AlertEventNote, by, now, "", "", update.Note))
+	}
+
+	if status != domain.AlertOpen && !alert.Acked() {
+		err := s.repo.AckAlert(ctx, tenantID, alertID, by, "", now)
+		if err == nil {
+			events = append(events, newEvent(alertID, domain.AlertEventAck, by, now, "", "", ""))
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	for _, event := range events {
+		s.record(ctx, tenantID, event)
+	}
+	updated, err := s.Get(ctx, tenantID, alertID)
+	if err == nil && status != alert.Status && domain.AlertClosed(status) {
+		s.publishClosed(ctx, tenantID, updated, by, now)
+	}
+	return updated, err
+}
+
+// publishClosed publishes a closed alert on TopicCaseClosed. The alert is
+// already closed, so a failure is logged rather than returned.
+func (s *Service) publishClosed(ctx context.Context, tenantID string, alert *domain.Alert, by string, at time.Time) {
+	if s.bus == nil {
+		return
+	}
+	event := domain.NewLifecycleEvent(domain.TopicCaseClosed, tenantID, by, at)
+	event.Alert = alert
+	payload, _ := json.Marshal(event)
+	if err := s.bus.Publish(ctx, tenantID, domain.TopicCaseClosed, payload); err != nil {
+		slog.Error("failed to publish closed alert", "tenant_id", tenantID, "alert_id", alert.ID, "error", err)
+	}
+}
+
+// record appends an event to an alert's history. The change it describes is
+// already saved, so a failure is logged rather than returned.
+func (s *Service) record(ctx context.Context, tenantID string, event *domain.AlertEvent) {
+	if err := s.repo.SaveAlertEvent(ctx, tenantID, event); err != nil {
+		slog.Error("failed to record alert history", "tenant_id", tenantID, "alert_id", event.AlertID, "action", event.Action, "error", err)
+	}
+}
+
+// newEvent builds a history entry. Its ID is time-ordered, so entries written
+// at the same instant keep their order.
+func newEvent(alertID, action, actor string, at time.Time, from, to, note string) *domain.AlertEvent {
+	return &domain.AlertEvent{
+		ID:      uuid.Must(uuid.NewV7()).String(),
+		AlertID: alertID,
+		Action:  action,
+		Actor:   actor,
+		From:    from,
+		To:      to,
+		Note:    note,
+		At:      at.UTC(),
+	}
+}
+
+// Escalate handles every alert whose acknowledgment window has passed and
+// returns how many were escalated.
+func (s *Service) Escalate(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+
+	now := s.now()
+	due, err := s.repo.ListDueAlerts(ctx, now.Add(-s.policy.AckWindow), s.policy.MaxEscalations, escalationBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due alerts: %w", err)
+	}
+
+	escalated := 0
+	for _, alert := range due {
+		level := alert.EscalationLevel + 1
+
+		// Another instance may have escalated it, or an analyst acknowledged it
+		if err := s.repo.EscalateAlert(ctx, alert.TenantID, alert.ID, level, now); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				slog.Error("failed to escalate alert", "tenant_id", alert.TenantID, "alert_id", alert.ID, "error", err)
+			}
+			continue
+		}
+		alert.EscalationLevel = level
+		alert.LastNotifiedAt = now
+		escalated++
+		s.record(ctx, alert.TenantID, newEvent(alert.ID, domain.AlertEventEscalate, "", now, strconv.Itoa(level-1), strconv.Itoa(level), ""))
+
+		event := domain.AlertEscalation{Alert: alert, Level: level, Action: domain.EscalationRenotify}
+		if level == s.policy.MaxEscalations {
+			event.Action = domain.EscalationEscalate
+		}
+		slog.Warn("alert not acknowledged",
+			"tenant_id", alert.TenantID,
+			"alert_id", alert.ID,
+			"level", level,
+			"action", event.Action,
+		)
+
+		if s.bus == nil {
+			continue
+		}
+		payload, _ := json.Marshal(event)
+		if err := s.bus.Publish(ctx, alert.TenantID, domain.TopicAlertEscalated, payload); err != nil {
+			slog.Error("failed to publish alert escalation", "alert_id", alert.ID, "error", err)
+		}
+	}
+
+	return escalated, nil
+}
+
+// Run checks for due alerts every CheckInterval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+
+	interval := s.policy.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Escalate(ctx); err != nil {
+				slog.Error("alert escalation failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	repo := Wrap(base)
+
+	for _, eval := range []*domain.Evaluation{
+		{ID: "eval-alert", TxID: "tx-1", Status: domain.StatusAlert, Score: 0.9},
+		{ID: "eval-pass", TxID: "tx-2", Status: domain.StatusNoAlert, Score: 0.1},
+	} {
+		if err := repo.SaveEvaluation(ctx, "tenant-001", eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+
+	list, err := repo.ListAlerts(ctx, "tenant-001", domain.AlertFilter{})
+	if err != nil {
+		t.Fatalf("ListAlerts failed: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "eval-alert" || list[0].TxID != "tx-1" {
+		t.Fatalf("expected one alert for the ALRT evaluation, got %+v", list)
+	}
+	if list[0].CreatedAt.IsZero() || list[0].LastNotifiedAt.IsZero() {
+		t.Error("expected alert timestamps to be set")
+	}
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	clock := ospreytest.NewClock(time.Time{})
+	repo := ospreytest.NewRepository(clock)
+	bus := ospreytest.NewBus(clock)
+
+	svc := NewService(repo, bus, domain.AlertConfig{AckWindow: 15 * time.Minute, MaxEscalations: 2})
+	svc.now = clock.Now
+
+	for _, id := range []string{"alert-1", "alert-2"} {
+		now := clock.Now()
+		if err := repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: id, TxID: "tx-" + id, CreatedAt: now, LastNotifiedAt: now}); err != nil {
+			t.Fatalf("SaveAlert failed: %v", err)
+		}
+	}
+
+	escalate := func(want int) {
+		t.Helper()
+		n, err := svc.Escalate(ctx)
+		if err != nil {
+			t.Fatalf("Escalate failed: %v", err)
+		}
+		if n != want {
+			t.Errorf("expected %d escalations, got %d", want, n)
+		}
+	}
+
+	t.Run("WithinWindow", func(t *testing.T) {
+		clock.Advance(10 * time.Minute)
+		escalate(0)
+	})
+
+	t.Run("Ack", func(t *testing.T) {
+		alert, err := svc.Ack(ctx, "tenant-001", "alert-2", "analyst", "known customer")
+		if err != nil {
+			t.Fatalf("Ack failed: %v", err)
+		}
+		if !alert.Acked() || alert.AckedBy != "analyst" {
+			t.Errorf("expected acknowledged alert, got %+v", alert)
+		}
+
+		again, err := svc.Ack(ctx, "tenant-001", "alert-2", "someone-else", "")
+		if err != nil {
+			t.Fatalf("second Ack failed: %v", err)
+		}
+		if again.AckedBy != "analyst" {
+			t.Errorf("expected first acknowledgment to be kept, got %q", again.AckedBy)
+		}
+
+		if _, err := svc.Ack(ctx, "tenant-002", "alert-1", "analyst", ""); err == nil {
+			t.Error("expected error acknowledging another tenant's alert")
+		}
+	})
+
+	t.Run("RenotifyThenEscalate", func(t *testing.T) {
+		clock.Advance(10 * time.Minute)
+		escalate(1) // only the unacknowledged alert
+		escalate(0) // window restarted
+
+		clock.Advance(16 * time.Minute)
+		escalate(1)
+
+		clock.Advance(time.Hour)
+		escalate(0) // MaxEscalations reached
+
+		published := bus.Published("tenant-001", domain.TopicAlertEscalated)
+		if len(published) != 2 {
+			t.Fatalf("expected 2 escalation events, got %d", len(published))
+		}
+
+		var actions []string
+		for _, msg := range published {
+			var event domain.AlertEscalation
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				t.Fatalf("failed to decode escalation: %v", err)
+			}
+			if event.Alert.ID != "alert-1" {
+				t.Errorf("unexpected escalated alert %q", event.Alert.ID)
+			}
+			actions = append(actions, event.Action)
+		}
+		if actions[0] != domain.EscalationRenotify || actions[1] != domain.EscalationEscalate {
+			t.Errorf("expected renotify then escalate, got %v", actions)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := NewService(repo, bus, domain.AlertConfig{MaxEscalations: 3})
+		if disabled.Enabled() {
+			t.Error("expected escalation disabled without an ack window")
+		}
+		if n, _ := disabled.Escalate(ctx); n != 0 {
+			t.Errorf("expected no escalations, got %d", n)
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	clock := ospreytest.NewClock(ospreytest.Epoch)
+	repo := ospreytest.NewRepository(clock)
+	bus := ospreytest.NewBus(clock)
+
+	svc := NewService(repo, bus, domain.AlertConfig{AckWindow: 15 * time.Minute, MaxEscalations: 2})
+	svc.now = clock.Now
+
+	now := clock.Now()
+	if err := repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "alert-1", TxID: "tx-1", Status: domain.AlertOpen, CreatedAt: now, LastNotifiedAt: now}); err != nil {
+		t.Fatalf("SaveAlert failed: %v", err)
+	}
+	analyst := "analyst-1"
+
+	t.Run("AssignAndNote", func(t *testing.T) {
+		clock.Advance(time.Minute)
+		alert, err := svc.Update(ctx, "tenant-001", "alert-1", "lead", Update{Assignee: &analyst, Note: "please review"})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if alert.Assignee != analyst || alert.Status != domain.AlertOpen || alert.Acked() {
+			t.Errorf("expected an assigned, open, unacknowledged alert, got %+v", alert)
+		}
+		if !alert.UpdatedAt.Equal(clock.Now()) {
+			t.Errorf("expected UpdatedAt %v, got %v", clock.Now(), alert.UpdatedAt)
+		}
+		if len(alert.History) != 2 || alert.History[0].Action != domain.AlertEventAssign || alert.History[1].Note != "please review" {
+			t.Errorf("unexpected history: %+v", alert.History)
+		}
+	})
+
+	t.Run("InvestigatingAcknowledges", func(t *testing.T) {
+		alert, err := svc.Update(ctx, "tenant-001", "alert-1", analyst, Update{Status: domain.AlertInvestigating})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if !alert.Acked() || alert.AckedBy != analyst {
+			t.Errorf("expected the alert to be acknowledged, got %+v", alert)
+		}
+		last := alert.History[len(alert.History)-2:]
+		if last[0].Action != domain.AlertEventStatus || last[0].From != domain.AlertOpen || last[0].To != domain.AlertInvestigating ||
+			last[1].Action != domain.AlertEventAck {
+			t.Errorf("unexpected history: %+v", last)
+		}
+	})
+
+	t.Run("ClosedOnlyReopens", func(t *testing.T) {
+		if _, err := svc.Update(ctx, "tenant-001", "alert-1", analyst, Update{Status: domain.AlertClosedFalsePositive, Note: "known payroll"}); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		published := bus.Published("tenant-001", domain.TopicCaseClosed)
+		if len(published) != 1 {
+			t.Fatalf("expected one closed case event, got %d", len(published))
+		}
+		var event domain.LifecycleEvent
+		if err := json.Unmarshal(published[0].Payload, &event); err != nil {
+			t.Fatalf("invalid event payload: %v", err)
+		}
+		if event.Type != domain.TopicCaseClosed || event.Actor != analyst || event.Alert == nil || event.Alert.Status != domain.AlertClosedFalsePositive {
+			t.Errorf("unexpected closed case event: %+v", event)
+		}
+		_, err := svc.Update(ctx, "tenant-001", "alert-1", analyst, Update{Status: domain.AlertClosedConfirmed})
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("expected ErrInvalidTransition, got %v", err)
+		}
+
+		alert, err := svc.Update(ctx, "tenant-001", "alert-1", "lead", Update{Status: domain.AlertOpen})
+		if err != nil {
+			t.Fatalf("reopen failed: %v", err)
+		}
+		if alert.Status != domain.AlertOpen || !alert.Acked() {
+			t.Errorf("expected a reopened alert keeping its acknowledgment, got %+v", alert)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := svc.Update(ctx, "tenant-002", "alert-1", analyst, Update{Note: "x"})
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for another tenant's alert, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/alerts"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+const (
+	// maxListAlertsLimit caps the limit query parameter of GET /alerts.
+	maxListAlertsLimit = 1000
+
+	// alertStreamBuffer is how many alerts may wait for a slow client of
+	// GET /stream/alerts before its stream is ended.
+	alertStreamBuffer = 256
+
+	// alertStreamHeartbeat is how often an idle alert stream sends a comment,
+	// so proxies don't close the connection.
+	alertStreamHeartbeat = 15 * time.Second
+)
+
+// WithAlerts sets the alert service, so the API and the escalator share one
+// escalation policy.
+func WithAlerts(svc *alerts.Service) Option {
+	return func(h *Handler) {
+		h.alerts = svc
+	}
+}
+
+// AckAlertRequest is the request body for POST /alerts/{id}/ack. The body is optional.
+type AckAlertRequest struct {
+	By   string `json:"by,omitempty"` // Ignored when the request carries a principal
+	Note string `json:"note,omitempty"`
+}
+
+// UpdateAlertRequest is the request body for PATCH /alerts/{id}. Omitted
+// fields are left unchanged; an empty assignee unassigns the alert.
+type UpdateAlertRequest struct {
+	Status   string  `json:"status,omitempty"`
+	Assignee *string `json:"assignee,omitempty"`
+	Note     string  `json:"note,omitempty"`
+	By       string  `json:"by,omitempty"` // Ignored when the request carries a principal
+}
+
+// ListAlerts returns the tenant's alerts, newest first.
+// Query params: status (comma-separated), assignee, txId, minScore, rule,
+// typology, minContribution, as for GET /evaluations, unacked=true, limit
+// (default 100).
+func (h *Handler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	filter := domain.AlertFilter{
+		Unacked:  query.Get("unacked") == "true",
+		Assignee: query.Get("assignee"),
+		TxID:     query.Get("txId"),
+
+		RuleID:     query.Get("rule"),
+		TypologyID: query.Get("typology"),
+	}
+	if !parseMinContribution(w, query, filter.TypologyID, &filter.MinContribution) {
+		return
+	}
+	if v := query.Get("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			if !domain.ValidAlertStatus(status) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "status must be one of: " + strings.Join(domain.AlertStatuses, ", "),
+				})
+				return
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if v := query.Get("minScore"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil || score < 0 || score > 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "minScore must be between 0 and 1",
+			})
+			return
+		}
+		filter.MinScore = score
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxListAlertsLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	list, err := h.repo.ListAlerts(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list alerts", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list alerts",
+		})
+		return
+	}
+	if list == nil {
+		list = []*domain.Alert{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts": list,
+		"count":  len(list),
+	})
+}
+
+// GetAlert returns an alert with its acknowledgment, escalation and
+// disposition state and its history.
+func (h *Handler) GetAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	alertID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	alert, err := h.alerts.Get(ctx, tenantID, alertID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "alert not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get alert", "alert_id", alertID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get alert",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, alert)
+}
+
+// AckAlert acknowledges an alert, stopping further escalation. Acknowledging
+// an already acknowledged alert returns it with the original acknowledgment.
+func (h *Handler) AckAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	alertID := chi.URLParam(r, "id")
+
+	var req AckAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if principal := GetRequestContext(ctx).Principal; principal != "" {
+		req.By = principal
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	alert, err := h.alerts.Ack(ctx, tenantID, alertID, req.By, req.Note)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "alert not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to acknowledge alert", "alert_id", alertID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to acknowledge alert",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, alert)
+}
+
+// UpdateAlert changes an alert's status or assignee, or adds a note. Closed
+// alerts can only be reopened; an invalid transition returns 409.
+func (h *Handler) UpdateAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	alertID := chi.URLParam(r, "id")
+
+	var req UpdateAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.Status != "" && !domain.ValidAlertStatus(req.Status) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "status must be one of: " + strings.Join(domain.AlertStatuses, ", "),
+		})
+		return
+	}
+	if req.Status == "" && req.Assignee == nil && req.Note == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "one of status, assignee or note is required",
+		})
+		return
+	}
+	if principal := GetRequestContext(ctx).Principal; principal != "" {
+		req.By = principal
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	alert, err := h.alerts.Update(ctx, tenantID, alertID, req.By, alerts.Update{
+		Status:   req.Status,
+		Assignee: req.Assignee,
+		Note:     req.Note,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "alert not found",
+		})
+		return
+	}
+	if errors.Is(err, alerts.ErrInvalidTransition) || errors.Is(err, alerts.ErrConflict) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update alert", "alert_id", alertID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to update alert",
+		})
+		return
+	}
+
+	slog.Info("alert updated", "alert_id", alertID, "tenant_id", tenantID, "status", alert.Status, "assignee", alert.Assignee)
+	writeJSON(w, http.StatusOK, alert)
+}
+
+// StreamAlerts pushes the tenant's ALRT evaluations as server-sent events
+// while they are decided, synchronously or by the worker. Each "alert" event
+// carries the evaluation as JSON with its ID as the event ID. Alerts are not
+// replayed, so clients should catch up with GET /alerts after connecting. A
+// client that falls behind gets an "overflow" event and the stream ends, as
+// it does when the instance drains; EventSource clients reconnect on their own.
+func (h *Handler) StreamAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.bus == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "event bus not available",
+		})
+		return
+	}
+
+	// The bus may deliver in the publisher's goroutine, so never block it
+	pending := make(chan []byte, alertStreamBuffer)
+	overflow := make(chan struct{})
+	var overflowOnce sync.Once
+	sub, err := h.bus.Subscribe(ctx, tenantID, domain.TopicAlert, func(_ context.Context, msg *domain.Message) error {
+		select {
+		case pending <- msg.Payload:
+		default:
+			overflowOnce.Do(func() { close(overflow) })
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to subscribe to alerts", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "failed to subscribe to alerts",
+		})
+		return
+	}
+	defer sub.Unsubscribe()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Don't let nginx buffer events
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("alert stream can't be flushed", "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(alertStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.drained:
+			return
+		case <-overflow:
+			fmt.Fprint(w, "event: overflow\ndata: {\"error\":\"alerts were dropped, catch up with GET /alerts\"}\n\n")
+			_ = rc.Flush()
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case payload := <-pending:
+			var eval struct {
+				ID string `json:"id"`
+			}
+			_ = json.Unmarshal(payload, &eval)
+			if _, err := fmt.Fprintf(w, "event: alert\nid: %s\ndata: %s\n\n", eval.ID, payload); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
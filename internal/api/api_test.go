@@ -1,15 +1,55 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/opensource-finance/osprey/internal/alerts"
+	"github.com/opensource-finance/osprey/internal/auditlog"
+	"github.com/opensource-finance/osprey/internal/breaker"
+	"github.com/opensource-finance/osprey/internal/cluster"
 	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/gitsync"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+	"github.com/opensource-finance/osprey/internal/migration"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/retention"
+	"github.com/opensource-finance/osprey/internal/review"
+	"github.com/opensource-finance/osprey/internal/ruledir"
 	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/sandbox"
+	"github.com/opensource-finance/osprey/internal/scoring"
+	"github.com/opensource-finance/osprey/internal/signing"
+	"github.com/opensource-finance/osprey/internal/slo"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/stats"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/txtypes"
+	"github.com/opensource-finance/osprey/internal/worker"
+	"github.com/opensource-finance/osprey/pkg/ospreypb"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // createTestServer creates a server with engine and processor for testing.
@@ -17,7 +57,7 @@ func createTestServer() *Server {
 	return createTestServerWithMode(domain.ModeDetection, false)
 }
 
-func createTestServerWithMode(mode domain.EvaluationMode, loadTypologies bool) *Server {
+func createTestServerWithMode(mode domain.EvaluationMode, loadTypologies bool, opts ...Option) *Server {
 	cfg := domain.ServerConfig{
 		Host:         "localhost",
 		Port:         8080,
@@ -60,7 +100,7 @@ func createTestServerWithMode(mode domain.EvaluationMode, loadTypologies bool) *
 	// Create TADP processor
 	processor := tadp.NewProcessor()
 
-	return NewServer(cfg, nil, nil, nil, engine, typologyEngine, processor, "test-v1", mode)
+	return NewServer(cfg, nil, nil, nil, engine, typologyEngine, processor, "test-v1", mode, opts...)
 }
 
 func TestEvaluateEndpoint(t *testing.T) {
@@ -78,7 +118,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 				AccountID: "acc-002",
 			},
 			Amount: AmountInfo{
-				Value:    1000.50,
+				Value:    domain.MustDecimal("1000.50"),
 				Currency: "USD",
 			},
 		}
@@ -115,6 +155,90 @@ func TestEvaluateEndpoint(t *testing.T) {
 		if resp.Metadata.TraceID == "" {
 			t.Error("expected traceId in metadata")
 		}
+		if resp.Metadata.Degradations != nil {
+			t.Errorf("expected no degradations, got %v", resp.Metadata.Degradations)
+		}
+	})
+
+	t.Run("DegradedEvaluation", func(t *testing.T) {
+		engine, _ := rules.NewEngine(func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+			return 0, errors.New("database unavailable")
+		}, 5)
+		engine.LoadRule(&domain.RuleConfig{ID: "velocity", Expression: "velocity_count > 10 ? 1.0 : 0.0", Weight: 1.0, Enabled: true})
+		repo := ospreytest.NewRepository(nil)
+		degraded := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+		body, _ := json.Marshal(TransactionRequest{
+			Type:     "transfer",
+			Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
+			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		degraded.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp EvaluateResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		got := resp.Metadata.Degradations
+		if len(got) != 1 || got[0].Component != domain.DegradedVelocity || got[0].Status != domain.DegradationFailed {
+			t.Errorf("expected failed velocity degradation, got %v", got)
+		}
+
+		// The degradation is stored with the evaluation
+		eval, err := repo.GetEvaluation(context.Background(), "tenant-001", resp.EvaluationID)
+		if err != nil {
+			t.Fatalf("GetEvaluation failed: %v", err)
+		}
+		if len(eval.Metadata.Degradations) != 1 {
+			t.Errorf("expected stored degradation, got %v", eval.Metadata.Degradations)
+		}
+	})
+
+	t.Run("DecimalAmount", func(t *testing.T) {
+		engine, _ := rules.NewEngine(nil, 5)
+		engine.LoadRule(&domain.RuleConfig{ID: "ctr", Expression: "amount_minor >= 1000001", Weight: 1.0, Enabled: true})
+		repo := ospreytest.NewRepository(nil)
+		exact := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+		evaluate := func(txID, value string) *httptest.ResponseRecorder {
+			body := `{"txId": "` + txID + `", "type": "transfer", "debtor": {"id": "d1", "accountId": "a1"}, ` +
+				`"creditor": {"id": "c1", "accountId": "a2"}, "amount": {"value": ` + value + `, "currency": "USD"}}`
+			req := httptest.NewRequest(http.MethodPost, "/evaluate?explain=true", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Tenant-ID", "tenant-001")
+			rr := httptest.NewRecorder()
+			exact.Router().ServeHTTP(rr, req)
+			return rr
+		}
+
+		// 10000.005 is 10000.004999999999 as a float64
+		rr := evaluate("tx-exact", `"10000.005"`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for a string amount, got %d: %s", rr.Code, rr.Body.String())
+		}
+		tx, err := repo.GetTransaction(context.Background(), "tenant-001", "tx-exact")
+		if err != nil {
+			t.Fatalf("GetTransaction failed: %v", err)
+		}
+		if tx.Amount.String() != "10000.005" {
+			t.Errorf("expected the exact amount 10000.005 stored, got %s", tx.Amount)
+		}
+		if !strings.Contains(rr.Body.String(), `"ruleId":"ctr","score":1`) {
+			t.Errorf("expected amount_minor to reach the 1000001 cent threshold, got %s", rr.Body.String())
+		}
+
+		if rr := evaluate("tx-malformed", `"ten"`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for a malformed amount, got %d: %s", rr.Code, rr.Body.String())
+		}
 	})
 
 	t.Run("MissingTenantID", func(t *testing.T) {
@@ -147,7 +271,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 		reqBody := TransactionRequest{
 			Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
 			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
-			Amount:   AmountInfo{Value: 100, Currency: "USD"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
 		}
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
@@ -166,7 +290,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 		reqBody := TransactionRequest{
 			Type:     "transfer",
 			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
-			Amount:   AmountInfo{Value: 100, Currency: "USD"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
 		}
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
@@ -186,7 +310,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 			Type:     "transfer",
 			Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
 			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
-			Amount:   AmountInfo{Value: -100, Currency: "USD"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("-100"), Currency: "USD"},
 		}
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
@@ -201,12 +325,103 @@ func TestEvaluateEndpoint(t *testing.T) {
 		}
 	})
 
+	t.Run("InvalidAmountComponents", func(t *testing.T) {
+		reqBody := TransactionRequest{
+			Type:     "transfer",
+			Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
+			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
+			Amount: AmountInfo{
+				Value:      domain.MustDecimal("100"),
+				Currency:   "USD",
+				Components: &domain.AmountComponents{Principal: domain.MustDecimal("90"), Fee: domain.MustDecimal("5")},
+			},
+		}
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 when components do not sum to amount, got %d", rr.Code)
+		}
+	})
+
+	t.Run("AmountComponentsAtCurrencyExponent", func(t *testing.T) {
+		for _, tc := range []struct {
+			value, currency, principal, fee string
+			want                            int
+		}{
+			{"100.000", "KWD", "99.995", "0.005", http.StatusOK},
+			{"100.000", "KWD", "99.995", "0.004", http.StatusBadRequest}, // a thousandth short
+			{"1000", "JPY", "995", "5", http.StatusOK},
+			{"1000", "JPY", "995", "4", http.StatusBadRequest},
+			{"0.3", "USD", "0.1", "0.2", http.StatusOK}, // not 0.30000000000000004
+		} {
+			reqBody := TransactionRequest{
+				Type:     "transfer",
+				Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
+				Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
+				Amount: AmountInfo{
+					Value:      domain.MustDecimal(tc.value),
+					Currency:   tc.currency,
+					Components: &domain.AmountComponents{Principal: domain.MustDecimal(tc.principal), Fee: domain.MustDecimal(tc.fee)},
+				},
+			}
+			body, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Tenant-ID", "tenant-001")
+
+			rr := httptest.NewRecorder()
+			server.Router().ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Errorf("%s %s = %s + %s: expected status %d, got %d: %s", tc.value, tc.currency, tc.principal, tc.fee, tc.want, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("VelocityWindowBounds", func(t *testing.T) {
+		maxWindow := int(domain.MaxVelocityWindow / time.Second)
+		for _, tc := range []struct {
+			window, want int
+		}{
+			{-1, http.StatusBadRequest},
+			{0, http.StatusOK}, // the tenant's window
+			{1, http.StatusOK},
+			{maxWindow, http.StatusOK},
+			{maxWindow + 1, http.StatusBadRequest},
+		} {
+			window := tc.window
+			reqBody := TransactionRequest{
+				Type:           "transfer",
+				Debtor:         PartyInfo{ID: "d1", AccountID: "a1"},
+				Creditor:       PartyInfo{ID: "c1", AccountID: "a2"},
+				Amount:         AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
+				VelocityWindow: window,
+			}
+			body, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Tenant-ID", "tenant-001")
+
+			rr := httptest.NewRecorder()
+			server.Router().ServeHTTP(rr, req)
+
+			if rr.Code != tc.want {
+				t.Errorf("velocityWindow %d: expected status %d, got %d: %s", window, tc.want, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
 	t.Run("ResponseHeaders", func(t *testing.T) {
 		reqBody := TransactionRequest{
 			Type:     "transfer",
 			Debtor:   PartyInfo{ID: "d1", AccountID: "a1"},
 			Creditor: PartyInfo{ID: "c1", AccountID: "a2"},
-			Amount:   AmountInfo{Value: 100, Currency: "USD"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
 		}
 		body, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
@@ -241,7 +456,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 				AccountID: "acc-002",
 			},
 			Amount: AmountInfo{
-				Value:    1000.0,
+				Value:    domain.MustDecimal("1000.0"),
 				Currency: "USD",
 			},
 		}
@@ -331,7 +546,7 @@ func TestEvaluateEndpoint(t *testing.T) {
 				AccountID: "acc-002",
 			},
 			Amount: AmountInfo{
-				Value:    100.0,
+				Value:    domain.MustDecimal("100.0"),
 				Currency: "USD",
 			},
 		}
@@ -394,6 +609,78 @@ func TestHealthEndpoint(t *testing.T) {
 		}
 	})
 
+	t.Run("HealthReportsDependencies", func(t *testing.T) {
+		repo := ospreytest.NewRepository(nil)
+		engine, _ := rules.NewEngine(nil, 5)
+		server := NewServer(domain.ServerConfig{}, repo, nil, ospreytest.NewBus(nil), engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp HealthResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode health response: %v", err)
+		}
+		if rr.Code != http.StatusOK || resp.Status != "degraded" {
+			t.Fatalf("expected degraded health without rules, got %d: %s", rr.Code, rr.Body.String())
+		}
+		for name, want := range map[string]string{
+			"repository": DependencyUp,
+			"cache":      DependencyDisabled,
+			"eventBus":   DependencyUp,
+			"worker":     DependencyDisabled,
+			"rules":      DependencyDown,
+			"typologies": DependencyUp,
+		} {
+			if got := resp.Dependencies[name].Status; got != want {
+				t.Errorf("expected %s %s, got %q", name, want, got)
+			}
+		}
+		if rules := resp.Dependencies["rules"]; rules.Count == nil || *rules.Count != 0 || rules.Error == "" {
+			t.Errorf("expected no rules loaded, got %+v", rules)
+		}
+	})
+
+	t.Run("DetectionReadyIsUnavailableWithoutRules", func(t *testing.T) {
+		engine, _ := rules.NewEngine(nil, 5)
+		server := NewServer(domain.ServerConfig{}, nil, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var resp map[string]string
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusServiceUnavailable || !strings.Contains(resp["error"], "enabled rule") {
+			t.Fatalf("expected status 503 without rules, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		// Shadow rules decide nothing
+		engine.LoadRule(&domain.RuleConfig{ID: "shadow-rule", Expression: "1.0", Enabled: true, Shadow: true})
+		rr = httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503 with only a shadow rule, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("ReadyIsUnavailableWithoutRepository", func(t *testing.T) {
+		repo := ospreytest.NewRepository(nil)
+		engine, _ := rules.NewEngine(nil, 5)
+		engine.LoadRule(&domain.RuleConfig{ID: "test-rule-001", Expression: "1.0", Enabled: true})
+		server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+		ready := func() *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			return rr
+		}
+
+		if rr := ready(); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		repo.SetError(errors.New("connection refused"))
+		if rr := ready(); rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), "repository") {
+			t.Errorf("expected status 503 while the repository is down, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
 	t.Run("ComplianceHealthIsDegradedWithoutTypologies", func(t *testing.T) {
 		complianceServer := createTestServerWithMode(domain.ModeCompliance, false)
 
@@ -481,6 +768,35 @@ func TestMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("RequestContextPopulated", func(t *testing.T) {
+		var rc *domain.RequestContext
+
+		handler := TracingMiddleware(TenantMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc = GetRequestContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "[2001:db8::1]:54321"
+		req.Header.Set("X-Tenant-ID", "my-tenant-123")
+		req.Header.Set("X-Request-ID", "req-001")
+		req.Header.Set("X-Principal", "analyst@example.com")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		want := domain.RequestContext{
+			TenantID:  "my-tenant-123",
+			TraceID:   rr.Header().Get("X-Trace-ID"),
+			RequestID: "req-001",
+			Principal: "analyst@example.com",
+			ClientIP:  "2001:db8::1",
+		}
+		if rc == nil || *rc != want {
+			t.Errorf("expected %+v, got %+v", want, rc)
+		}
+	})
+
 	t.Run("RecoverMiddlewareHandlesPanic", func(t *testing.T) {
 		handler := RecoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			panic("test panic")
@@ -497,3 +813,4172 @@ func TestMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestAdminNetworks(t *testing.T) {
+	t.Run("Parse", func(t *testing.T) {
+		an, err := ParseAdminNetworks([]string{"10.0.0.0/8", " fd00::/8 ", "192.0.2.7"}, false)
+		if err != nil {
+			t.Fatalf("ParseAdminNetworks failed: %v", err)
+		}
+
+		tests := []struct {
+			ip      string
+			allowed bool
+		}{
+			{"10.1.2.3", true},
+			{"::ffff:10.1.2.3", true},
+			{"fd12::1", true},
+			{"192.0.2.7", true},
+			{"192.0.2.8", false},
+			{"2001:db8::1", false},
+			{"not-an-ip", false},
+		}
+		for _, tt := range tests {
+			if got := an.Allowed(tt.ip); got != tt.allowed {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.ip, got, tt.allowed)
+			}
+		}
+
+		if _, err := ParseAdminNetworks([]string{"10.0.0.0/33"}, false); err == nil {
+			t.Error("expected error for invalid CIDR")
+		}
+	})
+
+	an, _ := ParseAdminNetworks([]string{"10.0.0.0/8"}, false)
+	server := createTestServerWithMode(domain.ModeDetection, false, WithAdminNetworks(an))
+
+	send := func(method, path, remoteAddr string, headers map[string]string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(`{}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("MutationRejectedOutsideAdminNetwork", func(t *testing.T) {
+		if code := send(http.MethodPost, "/rules/reload", "203.0.113.5:4000", nil); code != http.StatusForbidden {
+			t.Errorf("expected status 403, got %d", code)
+		}
+	})
+
+	t.Run("ForwardedHeaderIgnoredByDefault", func(t *testing.T) {
+		code := send(http.MethodPost, "/rules/reload", "203.0.113.5:4000", map[string]string{"X-Forwarded-For": "10.0.0.1"})
+		if code != http.StatusForbidden {
+			t.Errorf("expected spoofed X-Forwarded-For to be ignored, got %d", code)
+		}
+	})
+
+	t.Run("MutationAllowedFromAdminNetwork", func(t *testing.T) {
+		if code := send(http.MethodPost, "/rules/reload", "10.0.0.1:4000", nil); code == http.StatusForbidden {
+			t.Error("expected request from admin network to pass the allowlist")
+		}
+	})
+
+	t.Run("EvaluateOpenToAllNetworks", func(t *testing.T) {
+		if code := send(http.MethodPost, "/evaluate", "203.0.113.5:4000", nil); code == http.StatusForbidden {
+			t.Error("expected /evaluate to be unrestricted")
+		}
+		if code := send(http.MethodGet, "/rules", "203.0.113.5:4000", nil); code == http.StatusForbidden {
+			t.Error("expected read endpoints to be unrestricted")
+		}
+	})
+}
+
+func TestPartyEndpoints(t *testing.T) {
+	server := createTestServer()
+
+	t.Run("InvalidRiskRating", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"riskRating":"extreme"}`)
+		req := httptest.NewRequest(http.MethodPut, "/parties/cust-001", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("RepositoryUnavailable", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"riskRating":"high","pep":true}`)
+		req := httptest.NewRequest(http.MethodPut, "/parties/cust-001", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rr.Code)
+		}
+	})
+}
+
+func TestBalanceEndpoints(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(http.MethodGet, "/balances/acct-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an untracked account, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPut, "/balances/acct-001", `{"currency":"USD"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a balance, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPut, "/balances/acct-001", `{"balance":10,"currency":"dollars"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid currency, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPut, "/balances/acct-001", `{"balance":2500.5,"currency":"USD"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := request(http.MethodGet, "/balances/acct-001", "")
+	var balance domain.AccountBalance
+	json.Unmarshal(rr.Body.Bytes(), &balance)
+	if rr.Code != http.StatusOK || balance.Balance.String() != "2500.5" || balance.Currency != "USD" {
+		t.Errorf("expected 2500.5 USD, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRepositoryBreaker(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	cfg := domain.DefaultConfig().Breaker
+	cfg.Policy, cfg.Failures = breaker.PolicyFailFast, 1
+	repoBreaker, err := breaker.Wrap(repo, cfg)
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repoBreaker, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithBreaker(repoBreaker))
+
+	request := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	body, _ := json.Marshal(ospreytest.NewTransaction().Request())
+
+	// The write that opens the breaker is the last to wait on the database
+	repo.SetError(errors.New("connection refused"))
+	if rr := request(http.MethodPost, "/evaluate", body); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodPost, "/evaluate", body); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 while the breaker is open, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var health HealthResponse
+	json.Unmarshal(request(http.MethodGet, "/health", nil).Body.Bytes(), &health)
+	if dep := health.Dependencies["breaker"]; dep.Status != DependencyDown || health.Status != "degraded" {
+		t.Errorf("expected a down breaker, got %+v", health)
+	}
+
+	metrics := request(http.MethodGet, "/metrics", nil).Body.String()
+	for _, line := range []string{
+		`osprey_repository_breaker_open{policy="fail-fast"} 1`,
+		`osprey_repository_breaker_opened_total{policy="fail-fast"} 1`,
+		`osprey_repository_writes_rejected_total{policy="fail-fast"} 1`,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, metrics)
+		}
+	}
+}
+
+func TestCustomerEndpoints(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	create := `{"entityId":"cust-001","riskRating":"high","residenceCountry":"GB","segment":"sme","onboardedAt":"2024-01-15T00:00:00Z"}`
+	if rr := request(http.MethodPost, "/customers", create); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodPost, "/customers", create); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for an existing customer, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/customers", `{"riskRating":"low"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without entityId, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPut, "/customers/cust-002", `{"riskRating":"low","segment":"retail"}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := request(http.MethodGet, "/customers/cust-001", "")
+	var customer domain.PartyKYC
+	json.Unmarshal(rr.Body.Bytes(), &customer)
+	if rr.Code != http.StatusOK || customer.RiskRating != domain.RiskRatingHigh || customer.Segment != "sme" {
+		t.Errorf("expected the high-risk sme customer, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = request(http.MethodGet, "/customers?riskRating=high", "")
+	var resp struct {
+		Customers []domain.PartyKYC `json:"customers"`
+		Count     int               `json:"count"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Count != 1 || resp.Customers[0].EntityID != "cust-001" {
+		t.Errorf("expected only the high-risk customer, got %s", rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/customers?riskRating=extreme", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown risk rating, got %d", rr.Code)
+	}
+
+	if rr := request(http.MethodDelete, "/customers/cust-001", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/parties/cust-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted customer gone from /parties, got %d", rr.Code)
+	}
+	if rr := request(http.MethodDelete, "/customers/cust-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 deleting twice, got %d", rr.Code)
+	}
+}
+
+func TestCorridorEndpoints(t *testing.T) {
+	server := createTestServer()
+
+	t.Run("DefaultsFromFATFLists", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/refdata/corridors/gb/ir", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+
+		var score map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &score)
+		if score["risk"] != 1.0 || score["source"] != "fatf_blacklist" {
+			t.Errorf("expected black list default, got %v", score)
+		}
+	})
+
+	t.Run("InvalidCountry", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"risk":0.5}`)
+		req := httptest.NewRequest(http.MethodPut, "/refdata/corridors/GBR/AE", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("RiskOutOfRange", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"risk":1.5}`)
+		req := httptest.NewRequest(http.MethodPut, "/refdata/corridors/GB/AE", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+}
+
+func TestFeatureEndpoints(t *testing.T) {
+	server := createTestServer()
+
+	t.Run("ListDefaults", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/features", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+
+		var resp map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp["count"] != 5.0 {
+			t.Errorf("expected 5 known flags, got %v", resp["count"])
+		}
+	})
+
+	t.Run("UnknownFlag", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"enabled":true}`)
+		req := httptest.NewRequest(http.MethodPut, "/features/not_a_flag", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("EnabledRequired", func(t *testing.T) {
+		body := bytes.NewBufferString(`{}`)
+		req := httptest.NewRequest(http.MethodPut, "/features/ml_hook", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+}
+
+func TestJobEndpoints(t *testing.T) {
+	server := createTestServer()
+
+	t.Run("RepositoryUnavailable", func(t *testing.T) {
+		body := bytes.NewBufferString("type,debtor_id,creditor_id,amount\ntransfer,d1,c1,100\n")
+		req := httptest.NewRequest(http.MethodPost, "/jobs/batch", body)
+		req.Header.Set("Content-Type", "text/csv")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rr.Code)
+		}
+	})
+
+	t.Run("ReevaluateRequiresSince", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"ratePerSecond":10}`)
+		req := httptest.NewRequest(http.MethodPost, "/jobs/reevaluate", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("ControlUnknownJob", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/jobs/job-001/pause", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rr.Code)
+		}
+	})
+
+	t.Run("GetJobRepositoryUnavailable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/jobs/job-001", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rr.Code)
+		}
+	})
+}
+
+func TestVerifyEvaluationLogEndpoint(t *testing.T) {
+	server := createTestServerWithMode(domain.ModeCompliance, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/audit/evaluations/verify", nil)
+	req.Header.Set("X-Tenant-ID", "tenant-001")
+
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 without repository, got %d", rr.Code)
+	}
+}
+
+func TestInfoEndpoint(t *testing.T) {
+	server := createTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/info", nil)
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 without tenant header, got %d", rr.Code)
+	}
+
+	var info InfoResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Version != "test-v1" || info.Mode != string(domain.ModeDetection) {
+		t.Errorf("unexpected version/mode: %+v", info)
+	}
+	if info.Rules != 1 {
+		t.Errorf("expected 1 loaded rule, got %d", info.Rules)
+	}
+	if info.Subsystems == nil || info.Features == nil || info.Enrichers == nil {
+		t.Error("expected subsystems, features and enrichers to be non-null")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	t.Run("InvalidOrigin", func(t *testing.T) {
+		for _, origin := range []string{"example.com", "https://example.com/path", "https://"} {
+			if _, err := NewCORSPolicy(domain.CORSConfig{AllowedOrigins: []string{origin}}); err == nil {
+				t.Errorf("expected error for origin %q", origin)
+			}
+		}
+	})
+
+	t.Run("ParseTenantOrigins", func(t *testing.T) {
+		parsed, err := ParseTenantOrigins("acme=https://a.com|https://b.com, globex=https://g.io")
+		if err != nil {
+			t.Fatalf("ParseTenantOrigins failed: %v", err)
+		}
+		if len(parsed["acme"]) != 2 || len(parsed["globex"]) != 1 {
+			t.Errorf("unexpected result: %v", parsed)
+		}
+		if _, err := ParseTenantOrigins("https://a.com"); err == nil {
+			t.Error("expected error for entry without tenant")
+		}
+	})
+
+	send := func(server *Server, method, origin, tenantID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/rules", nil)
+		req.Header.Set("Origin", origin)
+		if tenantID != "" {
+			req.Header.Set("X-Tenant-ID", tenantID)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("DenyByDefault", func(t *testing.T) {
+		server := createTestServer()
+
+		rr := send(server, http.MethodGet, "https://evil.example", "tenant-001")
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no Access-Control-Allow-Origin, got %q", got)
+		}
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected same-origin handling to proceed, got %d", rr.Code)
+		}
+
+		rr = send(server, http.MethodOptions, "https://evil.example", "")
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected preflight status 403, got %d", rr.Code)
+		}
+	})
+
+	policy, err := NewCORSPolicy(domain.CORSConfig{
+		AllowedOrigins:   []string{"https://ops.example.com", "https://*.osprey.test"},
+		TenantOrigins:    map[string][]string{"acme": {"https://dash.acme.com"}},
+		AllowCredentials: true,
+	})
+	if err != nil {
+		t.Fatalf("NewCORSPolicy failed: %v", err)
+	}
+	server := createTestServerWithMode(domain.ModeDetection, false, WithCORS(policy))
+
+	tests := []struct {
+		name     string
+		origin   string
+		tenantID string
+		allowed  bool
+	}{
+		{"GlobalOrigin", "https://ops.example.com", "tenant-001", true},
+		{"SubdomainPattern", "https://ui.osprey.test", "tenant-001", true},
+		{"PatternExcludesApex", "https://osprey.test", "tenant-001", false},
+		{"TenantOrigin", "https://dash.acme.com", "acme", true},
+		{"OtherTenantOrigin", "https://dash.acme.com", "tenant-001", false},
+		{"SchemeMismatch", "http://ops.example.com", "tenant-001", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := send(server, http.MethodGet, tt.origin, tt.tenantID)
+			got := rr.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed {
+				if got != tt.origin {
+					t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.origin, got)
+				}
+				if rr.Header().Get("Access-Control-Allow-Credentials") != "true" {
+					t.Error("expected credentials to be allowed")
+				}
+			} else if got != "" {
+				t.Errorf("expected no Access-Control-Allow-Origin, got %q", got)
+			}
+			if rr.Header().Get("Vary") != "Origin" {
+				t.Errorf("expected Vary: Origin, got %q", rr.Header().Get("Vary"))
+			}
+		})
+	}
+
+	t.Run("TenantPreflight", func(t *testing.T) {
+		rr := send(server, http.MethodOptions, "https://dash.acme.com", "")
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.acme.com" {
+			t.Errorf("unexpected Access-Control-Allow-Origin %q", got)
+		}
+	})
+
+	t.Run("WildcardWithoutCredentials", func(t *testing.T) {
+		policy, _ := NewCORSPolicy(domain.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+		server := createTestServerWithMode(domain.ModeDetection, false, WithCORS(policy))
+
+		rr := send(server, http.MethodGet, "https://anything.example", "tenant-001")
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("expected Access-Control-Allow-Origin *, got %q", got)
+		}
+		if rr.Header().Get("Access-Control-Allow-Credentials") != "" {
+			t.Error("credentials must not be allowed for wildcard origins")
+		}
+	})
+}
+
+func TestAlertEndpoints(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRule(&domain.RuleConfig{
+		ID:         "test-rule-001",
+		Name:       "High Value Test Rule",
+		Expression: "amount > 100000.0 ? 1.0 : 0.0",
+		Weight:     1.0,
+		Enabled:    true,
+	})
+	repo := alerts.Wrap(ospreytest.NewRepository(nil))
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	send := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send(http.MethodPost, "/evaluate", `{
+		"type": "transfer",
+		"debtor": {"id": "d1", "accountId": "a1"},
+		"creditor": {"id": "c1", "accountId": "a2"},
+		"amount": {"value": 250000, "currency": "USD"}
+	}`, nil)
+	var eval EvaluateResponse
+	json.Unmarshal(rr.Body.Bytes(), &eval)
+	if eval.Status != domain.StatusAlert {
+		t.Fatalf("expected ALRT evaluation, got %s: %s", eval.Status, rr.Body.String())
+	}
+
+	listAlerts := func(query string) []domain.Alert {
+		t.Helper()
+		rr := send(http.MethodGet, "/alerts"+query, "", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Alerts []domain.Alert `json:"alerts"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Alerts
+	}
+
+	t.Run("ListUnacked", func(t *testing.T) {
+		list := listAlerts("?unacked=true")
+		if len(list) != 1 || list[0].ID != eval.EvaluationID {
+			t.Fatalf("expected the evaluation's alert, got %+v", list)
+		}
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		if rr := send(http.MethodGet, "/alerts?limit=0", "", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("AckUnknown", func(t *testing.T) {
+		if rr := send(http.MethodPost, "/alerts/nonexistent/ack", "", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Ack", func(t *testing.T) {
+		rr := send(http.MethodPost, "/alerts/"+eval.EvaluationID+"/ack", `{"by":"spoofed","note":"customer confirmed"}`,
+			map[string]string{PrincipalHeader: "analyst@example.com"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		var alert domain.Alert
+		json.Unmarshal(rr.Body.Bytes(), &alert)
+		if !alert.Acked() || alert.AckedBy != "analyst@example.com" || alert.AckNote != "customer confirmed" {
+			t.Errorf("unexpected acknowledgment: %+v", alert)
+		}
+
+		if list := listAlerts("?unacked=true"); len(list) != 0 {
+			t.Errorf("expected no unacknowledged alerts, got %d", len(list))
+		}
+		if list := listAlerts(""); len(list) != 1 || !list[0].Acked() {
+			t.Errorf("expected acknowledged alert in listing, got %+v", list)
+		}
+	})
+
+	t.Run("AckTwiceKeepsFirst", func(t *testing.T) {
+		rr := send(http.MethodPost, "/alerts/"+eval.EvaluationID+"/ack", `{"by":"someone-else"}`, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+		var alert domain.Alert
+		json.Unmarshal(rr.Body.Bytes(), &alert)
+		if alert.AckedBy != "analyst@example.com" {
+			t.Errorf("expected original acknowledgment, got %q", alert.AckedBy)
+		}
+	})
+
+	t.Run("Disposition", func(t *testing.T) {
+		path := "/alerts/" + eval.EvaluationID
+		principal := map[string]string{PrincipalHeader: "analyst@example.com"}
+
+		rr := send(http.MethodPatch, path, `{"status": "investigating", "assignee": "analyst@example.com", "note": "pulling statements"}`, principal)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var alert domain.Alert
+		json.Unmarshal(rr.Body.Bytes(), &alert)
+		if alert.Status != domain.AlertInvestigating || alert.Assignee != "analyst@example.com" {
+			t.Errorf("unexpected alert: %+v", alert)
+		}
+		// ack, then status, assignee and note
+		if len(alert.History) != 4 || alert.History[3].Note != "pulling statements" || alert.History[3].Actor != "analyst@example.com" {
+			t.Errorf("unexpected history: %s", rr.Body.String())
+		}
+
+		if list := listAlerts("?status=investigating&assignee=analyst@example.com"); len(list) != 1 {
+			t.Errorf("expected the alert when searching by status and assignee, got %d", len(list))
+		}
+		if list := listAlerts("?status=open,closed-confirmed"); len(list) != 0 {
+			t.Errorf("expected no open or confirmed alerts, got %d", len(list))
+		}
+		if list := listAlerts("?txId=" + eval.TxID + "&minScore=0.5"); len(list) != 1 {
+			t.Errorf("expected the alert when searching by txId, got %d", len(list))
+		}
+
+		if rr := send(http.MethodPatch, path, `{"status": "closed-confirmed"}`, nil); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200 closing, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := send(http.MethodPatch, path, `{"status": "investigating"}`, nil); rr.Code != http.StatusConflict {
+			t.Errorf("expected status 409 moving a closed alert, got %d", rr.Code)
+		}
+
+		rr = send(http.MethodGet, path, "", nil)
+		json.Unmarshal(rr.Body.Bytes(), &alert)
+		if alert.Status != domain.AlertClosedConfirmed || len(alert.History) == 0 {
+			t.Errorf("expected the closed alert with its history, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("DispositionValidation", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"status": "closed"}`, `not json`} {
+			if rr := send(http.MethodPatch, "/alerts/"+eval.EvaluationID, body, nil); rr.Code != http.StatusBadRequest {
+				t.Errorf("body %s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+		if rr := send(http.MethodPatch, "/alerts/nonexistent", `{"note": "x"}`, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+		for _, query := range []string{"?status=closed", "?minScore=2"} {
+			if rr := send(http.MethodGet, "/alerts"+query, "", nil); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+			}
+		}
+	})
+
+	t.Run("NoRepository", func(t *testing.T) {
+		server := createTestServer()
+		req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rr.Code)
+		}
+	})
+}
+
+func TestStreamAlerts(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRule(&domain.RuleConfig{
+		ID:         "test-rule-001",
+		Name:       "High Value Test Rule",
+		Expression: "amount > 100000.0 ? 1.0 : 0.0",
+		Weight:     1.0,
+		Enabled:    true,
+	})
+	bus := ospreytest.NewBus(nil)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, bus, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+	ts := httptest.NewServer(server.Router())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/stream/alerts", nil)
+	req.Header.Set("X-Tenant-ID", "tenant-001")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	evaluate := func(tenantID string, amount int) EvaluateResponse {
+		t.Helper()
+		body := fmt.Sprintf(`{"type": "transfer", "debtor": {"id": "d1", "accountId": "a1"}, "creditor": {"id": "c1", "accountId": "a2"}, "amount": {"value": %d, "currency": "USD"}}`, amount)
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		var eval EvaluateResponse
+		json.Unmarshal(rr.Body.Bytes(), &eval)
+		return eval
+	}
+
+	// Neither another tenant's alert nor a NALT evaluation is streamed
+	evaluate("tenant-002", 250000)
+	evaluate("tenant-001", 100)
+	alert := evaluate("tenant-001", 250000)
+	if alert.Status != domain.StatusAlert {
+		t.Fatalf("expected ALRT evaluation, got %+v", alert)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var event []string
+	for len(event) == 0 || event[len(event)-1] != "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		event = append(event, strings.TrimSuffix(line, "\n"))
+	}
+	if len(event) != 4 || event[0] != "event: alert" || event[1] != "id: "+alert.EvaluationID {
+		t.Fatalf("expected the alert event, got %q", event)
+	}
+	var streamed domain.Evaluation
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &streamed); err != nil || streamed.TenantID != "tenant-001" || streamed.Status != domain.StatusAlert {
+		t.Fatalf("expected the ALRT evaluation as data, got %q (%v)", event[2], err)
+	}
+
+	// Draining ends the stream
+	server.Drain()
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Fatalf("expected the stream to end, got %v", err)
+	}
+
+	t.Run("NoBus", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stream/alerts", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		createTestServer().Router().ServeHTTP(rr, req)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rr.Code)
+		}
+	})
+}
+
+func TestReloadRulesDiff(t *testing.T) {
+	ctx := context.Background()
+	engine, _ := rules.NewEngine(nil, 5)
+	repo := ospreytest.NewRepository(nil)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	reload := func() map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/rules/reload", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Changes map[string]interface{} `json:"changes"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Changes
+	}
+
+	repo.SaveRuleConfig(ctx, rules.GlobalTenantID, &domain.RuleConfig{
+		ID: "rule-a", Name: "Rule A", Version: "1.0.0", Expression: "amount > 1.0 ? 1.0 : 0.0", Weight: 1, Enabled: true,
+	})
+	if changes := reload(); len(changes["added"].([]interface{})) != 1 {
+		t.Errorf("expected one added rule, got %v", changes)
+	}
+
+	repo.SaveRuleConfig(ctx, rules.GlobalTenantID, &domain.RuleConfig{
+		ID: "rule-a", Name: "Rule A", Version: "1.1.0", Expression: "amount > 2.0 ? 1.0 : 0.0", Weight: 1, Enabled: true,
+	})
+	changes := reload()
+	modified := changes["modified"].([]interface{})
+	if len(modified) != 1 {
+		t.Fatalf("expected one modified rule, got %v", changes)
+	}
+	change := modified[0].(map[string]interface{})
+	if change["previousVersion"] != "1.0.0" || change["version"] != "1.1.0" || change["versionBumped"] != true {
+		t.Errorf("unexpected version change: %v", change)
+	}
+	fields := change["fields"].([]interface{})
+	if len(fields) != 1 || fields[0].(map[string]interface{})["field"] != "expression" {
+		t.Errorf("expected an expression change, got %v", fields)
+	}
+
+	if changes := reload(); changes["unchanged"] != 1.0 || len(changes["modified"].([]interface{})) != 0 {
+		t.Errorf("expected no changes on repeated reload, got %v", changes)
+	}
+}
+
+func TestTenantRules(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	an, _ := ParseAdminNetworks([]string{"192.0.2.0/24"}, false) // httptest's RemoteAddr
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithAdminNetworks(an))
+
+	request := func(method, path, tenantID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	ruleIDs := func(tenantID string) map[string]string {
+		t.Helper()
+		rr := request(http.MethodGet, "/rules", tenantID, "")
+		var resp struct {
+			Rules []domain.RuleConfig `json:"rules"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		ids := make(map[string]string, len(resp.Rules))
+		for _, rule := range resp.Rules {
+			ids[rule.ID] = rule.TenantID
+		}
+		return ids
+	}
+
+	for tenantID, body := range map[string]string{
+		"*":        `{"id":"high-value","name":"High Value","expression":"amount > 1000.0","weight":1,"enabled":true}`,
+		"tenant-a": `{"id":"large-fx","name":"Large FX","expression":"fx_amount > 500.0","weight":1,"enabled":true}`,
+	} {
+		if rr := request(http.MethodPost, "/rules", tenantID, body); rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201 creating a rule for %s, got %d: %s", tenantID, rr.Code, rr.Body.String())
+		}
+	}
+	if rr := request(http.MethodPost, "/rules/reload", "tenant-a", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	t.Run("ScopedListing", func(t *testing.T) {
+		if got := ruleIDs("tenant-a"); len(got) != 2 || got["high-value"] != "*" || got["large-fx"] != "tenant-a" {
+			t.Errorf("expected global and own rules for tenant-a, got %v", got)
+		}
+		if got := ruleIDs("tenant-b"); len(got) != 1 || got["high-value"] != "*" {
+			t.Errorf("expected only global rules for tenant-b, got %v", got)
+		}
+		if rr := request(http.MethodGet, "/rules/large-fx", "tenant-b", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected another tenant's rule to be hidden, got %d", rr.Code)
+		}
+	})
+
+	t.Run("TypologyRulesMustApplyToTenant", func(t *testing.T) {
+		body := `{"id":"fx-typology","name":"FX","rules":[{"ruleId":"large-fx","weight":1}],"alertThreshold":0.5,"enabled":true}`
+		if rr := request(http.MethodPost, "/typologies", "tenant-b", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for another tenant's rule, got %d", rr.Code)
+		}
+		if rr := request(http.MethodPost, "/typologies", "tenant-a", body); rr.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("GlobalWritesNeedAdminNetwork", func(t *testing.T) {
+		rule := `{"id":"global-rule","name":"Global","expression":"amount > 1.0","weight":1,"enabled":true}`
+		for _, tc := range []struct {
+			name string
+			opts []Option
+			want int
+		}{
+			{"NoAdminNetworks", nil, http.StatusForbidden},
+			{"ExplicitlyAllowed", []Option{WithGlobalTenantWrites(true)}, http.StatusCreated},
+		} {
+			engine, _ := rules.NewEngine(nil, 5)
+			server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, tc.opts...)
+			for _, method := range []string{http.MethodPost, http.MethodGet} {
+				req := httptest.NewRequest(method, "/rules", bytes.NewBufferString(rule))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Tenant-ID", "*")
+				rr := httptest.NewRecorder()
+				server.Router().ServeHTTP(rr, req)
+
+				want := tc.want
+				if method == http.MethodGet {
+					want = http.StatusOK // reads stay open
+				}
+				if rr.Code != want {
+					t.Errorf("%s: expected status %d for %s /rules as *, got %d: %s", tc.name, want, method, rr.Code, rr.Body.String())
+				}
+			}
+		}
+	})
+}
+
+func TestTenantsHealth(t *testing.T) {
+	ctx := context.Background()
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRules([]*domain.RuleConfig{
+		{ID: "global", Expression: "amount > 1000.0", Enabled: true},
+		{ID: "own", TenantID: "tenant-c", Expression: "amount > 10.0", Enabled: true},
+	})
+	repo := ospreytest.NewRepository(nil)
+	// Aggregate privacy is for tenants; operators see exact counts
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection,
+		WithAggregatePrivacy(stats.NewPrivacy(0.1, 10)))
+
+	now := time.Now().UTC()
+	for i, eval := range []struct {
+		tenantID, status string
+		at               time.Time
+	}{
+		{"tenant-a", domain.StatusNoAlert, now.Add(-10 * time.Minute)},
+		{"tenant-a", domain.StatusNoAlert, now.Add(-5 * time.Minute)},
+		{"tenant-a", domain.StatusAlert, now.Add(-time.Minute)},
+		{"tenant-b", domain.StatusNoAlert, now.Add(-3 * time.Hour)},
+	} {
+		repo.SaveEvaluation(ctx, eval.tenantID, &domain.Evaluation{
+			ID: fmt.Sprintf("eval-%d", i), TxID: fmt.Sprintf("tx-%d", i), Status: eval.status, Timestamp: eval.at,
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/tenants/health", nil)
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Tenants []TenantHealth `json:"tenants"`
+		Count   int            `json:"count"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Count != 3 {
+		t.Fatalf("expected 3 tenants, got %+v", resp.Tenants)
+	}
+
+	a, b, c := resp.Tenants[0], resp.Tenants[1], resp.Tenants[2]
+	if a.TenantID != "tenant-a" || a.Evaluations != 3 || a.Alerts != 1 || a.Silent {
+		t.Errorf("unexpected tenant-a summary: %+v", a)
+	}
+	if a.AlertRate < 0.33 || a.AlertRate > 0.34 {
+		t.Errorf("expected an alert rate of 1/3, got %v", a.AlertRate)
+	}
+	if b.TenantID != "tenant-b" || b.Evaluations != 0 || !b.Silent || b.LastEvaluationAt == nil {
+		t.Errorf("expected tenant-b to be silent, got %+v", b)
+	}
+	if c.TenantID != "tenant-c" || c.Rules != 2 || c.LastEvaluationAt != nil || c.Silent {
+		t.Errorf("expected tenant-c known from its rule, got %+v", c)
+	}
+	if a.Rules != 1 || a.Worker != worker.SubscriptionNone {
+		t.Errorf("expected the global rule and no worker for tenant-a, got %+v", a)
+	}
+
+	t.Run("Sandbox", func(t *testing.T) {
+		purger := sandbox.NewPurger(repo, domain.SandboxConfig{Tenants: []string{"tenant-b"}})
+		server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithSandbox(purger))
+
+		for _, tc := range []struct {
+			query   string
+			tenants []string
+		}{
+			{"", []string{"tenant-a", "tenant-c"}},
+			{"?sandbox=true", []string{"tenant-a", "tenant-b", "tenant-c"}},
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/admin/tenants/health"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			server.Router().ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			var resp struct {
+				Tenants []TenantHealth `json:"tenants"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			var got []string
+			for _, tenant := range resp.Tenants {
+				got = append(got, tenant.TenantID)
+				if tenant.Sandbox != (tenant.TenantID == "tenant-b") {
+					t.Errorf("unexpected sandbox flag for %s", tenant.TenantID)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tc.tenants, ",") {
+				t.Errorf("%q: expected tenants %v, got %v", tc.query, tc.tenants, got)
+			}
+		}
+	})
+}
+
+func TestRuleLifecycle(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(http.MethodPost, "/rules", `{"id":"high-value","name":"High Value","expression":"amount > 1000.0","weight":1,"enabled":true}`); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	request(http.MethodPost, "/rules/reload", "")
+
+	t.Run("Update", func(t *testing.T) {
+		rr := request(http.MethodPut, "/rules/high-value", `{"name":"High Value","expression":"amount > 5000.0","weight":1,"enabled":true}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Rule    domain.RuleConfig `json:"rule"`
+			Changes rules.RuleDiff    `json:"changes"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Rule.Version != "1.0.0" || len(resp.Changes.Modified) != 1 {
+			t.Errorf("expected the rule modified in place, got %+v", resp)
+		}
+
+		loaded := engine.GetTenantRules("tenant-001")
+		if len(loaded) != 1 || loaded[0].Expression != "amount > 5000.0" {
+			t.Errorf("expected the engine to be reloaded, got %+v", loaded)
+		}
+	})
+
+	t.Run("UpdateValidation", func(t *testing.T) {
+		if rr := request(http.MethodPut, "/rules/unknown", `{"name":"X","expression":"amount > 1.0"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for an unknown rule, got %d", rr.Code)
+		}
+		if rr := request(http.MethodPut, "/rules/high-value", `{"name":"X","expression":"amount >"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an invalid expression, got %d", rr.Code)
+		}
+		if rr := request(http.MethodPut, "/rules/high-value", `{"name":"X"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 without an expression, got %d", rr.Code)
+		}
+	})
+
+	t.Run("ExprLanguage", func(t *testing.T) {
+		if rr := request(http.MethodPut, "/rules/high-value", `{"name":"X","language":"lua","expression":"amount > 1"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an unknown language, got %d", rr.Code)
+		}
+		rr := request(http.MethodPut, "/rules/high-value", `{"name":"High Value","language":"expr","expression":"amount > 5000 and tx_type in [\"transfer\"]","weight":1,"enabled":true}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		loaded := engine.GetTenantRules("tenant-001")
+		if len(loaded) != 1 || loaded[0].Language != domain.RuleLanguageExpr {
+			t.Errorf("expected the expr rule to be loaded, got %+v", loaded)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if rr := request(http.MethodDelete, "/rules/high-value", ""); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if engine.RulesCount() != 0 {
+			t.Errorf("expected the engine to be reloaded without the rule, got %d rules", engine.RulesCount())
+		}
+		if rr := request(http.MethodGet, "/rules/high-value", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 after delete, got %d", rr.Code)
+		}
+		if rr := request(http.MethodDelete, "/rules/unknown", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for an unknown rule, got %d", rr.Code)
+		}
+	})
+}
+
+func TestGitSyncEndpoints(t *testing.T) {
+	request := func(server *Server, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		server := createTestServer()
+		if rr := request(server, http.MethodGet, "/gitsync", "", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+		if rr := request(server, http.MethodPost, "/gitsync/webhook", "{}", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+
+	syncer := gitsync.NewSyncer(domain.GitSyncConfig{Repo: "https://git.example.com/rules.git", WebhookSecret: "s3cret"}, nil)
+	server := createTestServerWithMode(domain.ModeDetection, true, WithGitSync(syncer))
+
+	t.Run("status", func(t *testing.T) {
+		rr := request(server, http.MethodGet, "/gitsync", "", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var status gitsync.Status
+		json.Unmarshal(rr.Body.Bytes(), &status)
+		if status.Repo != "https://git.example.com/rules.git" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("API mutations are rejected", func(t *testing.T) {
+		cases := []struct{ method, path string }{
+			{http.MethodPost, "/rules"},
+			{http.MethodPut, "/state"},
+			{http.MethodPost, "/typologies"},
+			{http.MethodPut, "/typologies/typ-001"},
+			{http.MethodDelete, "/typologies/typ-001"},
+		}
+		for _, tc := range cases {
+			if rr := request(server, tc.method, tc.path, "{}", nil); rr.Code != http.StatusConflict {
+				t.Errorf("%s %s: expected status 409, got %d", tc.method, tc.path, rr.Code)
+			}
+		}
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		if rr := request(server, http.MethodPost, "/gitsync/webhook", "{}", http.Header{"X-Gitlab-Token": {"wrong"}}); rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status 401, got %d", rr.Code)
+		}
+		if rr := request(server, http.MethodPost, "/gitsync/webhook", "{}", http.Header{"X-Gitlab-Token": {"s3cret"}}); rr.Code != http.StatusAccepted {
+			t.Errorf("expected status 202, got %d", rr.Code)
+		}
+	})
+}
+
+func TestRuleDirEndpoints(t *testing.T) {
+	request := func(server *Server, method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString("{}"))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(createTestServer(), http.MethodGet, "/ruledir"); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 while disabled, got %d", rr.Code)
+	}
+
+	dir := t.TempDir()
+	watcher := ruledir.NewWatcher(domain.RuleDirConfig{Dir: dir}, nil)
+	server := createTestServerWithMode(domain.ModeDetection, true, WithRuleDir(watcher))
+
+	rr := request(server, http.MethodGet, "/ruledir")
+	var status ruledir.Status
+	json.Unmarshal(rr.Body.Bytes(), &status)
+	if rr.Code != http.StatusOK || status.Dir != dir {
+		t.Errorf("unexpected status: %d %s", rr.Code, rr.Body.String())
+	}
+
+	for _, path := range []string{"/rules", "/typologies", "/rulepacks/install"} {
+		if rr := request(server, http.MethodPost, path); rr.Code != http.StatusConflict {
+			t.Errorf("POST %s: expected status 409, got %d", path, rr.Code)
+		}
+	}
+}
+
+func TestClusterReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := ospreytest.NewRepository(nil)
+	eventBus := ospreytest.NewBus(nil)
+
+	// Two replicas sharing the database and the bus
+	type replica struct {
+		engine *rules.Engine
+		server *Server
+	}
+	newReplica := func() *replica {
+		engine, _ := rules.NewEngine(nil, 5)
+		typologies := rules.NewTypologyEngine()
+		mgr := state.NewManager(repo, engine, typologies)
+		coordinator := cluster.NewCoordinator(eventBus, 10*time.Millisecond)
+		if err := coordinator.Start(ctx, mgr.Reload); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		t.Cleanup(coordinator.Stop)
+		mgr.OnApply(coordinator.Notify)
+		server := NewServer(domain.ServerConfig{}, repo, nil, eventBus, engine, typologies, tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithState(mgr), WithCluster(coordinator))
+		return &replica{engine: engine, server: server}
+	}
+	a, b := newReplica(), newReplica()
+
+	request := func(rep *replica, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		rep.server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	waitForRules := func(rep *replica, want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for rep.engine.RulesCount() != want && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if got := rep.engine.RulesCount(); got != want {
+			t.Fatalf("expected %d rules loaded, got %d", want, got)
+		}
+	}
+
+	// A plain save waits for a reload on every replica, not only its own
+	rule := `{"id": "high-value", "name": "High value", "expression": "amount > 10000.0", "weight": 1, "enabled": true}`
+	if rr := request(a, http.MethodPost, "/rules", rule); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if a.engine.RulesCount() != 0 || b.engine.RulesCount() != 0 {
+		t.Fatalf("expected no replica to load a saved rule before a reload, got a %d and b %d", a.engine.RulesCount(), b.engine.RulesCount())
+	}
+
+	// Reloading one replica reloads the others
+	if rr := request(a, http.MethodPost, "/rules/reload", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	waitForRules(a, 1)
+	waitForRules(b, 1)
+
+	// So does an applied state
+	desired := `{"rules": [{"id": "global-high-value", "name": "High value", "expression": "amount > 10000.0", "weight": 1}]}`
+	if rr := request(b, http.MethodPut, "/state", desired); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	waitForRules(b, 2)
+	waitForRules(a, 2)
+
+	// And an auto-reloading delete
+	if rr := request(b, http.MethodDelete, "/rules/high-value", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	waitForRules(b, 1)
+	waitForRules(a, 1)
+}
+
+func TestStateEndpoints(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	type response struct {
+		Plan struct {
+			Rules     []map[string]interface{} `json:"rules"`
+			Unchanged int                      `json:"unchanged"`
+		} `json:"plan"`
+		Applied bool `json:"applied"`
+	}
+
+	desired := `{"rules": [{"id": "high-value", "name": "High value", "expression": "amount > 10000.0", "weight": 1}]}`
+
+	t.Run("dry run", func(t *testing.T) {
+		rr := request(http.MethodPut, "/state?dryRun=true", desired)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Applied || len(resp.Plan.Rules) != 1 || resp.Plan.Rules[0]["action"] != "create" {
+			t.Errorf("unexpected response: %s", rr.Body.String())
+		}
+		if engine.RulesCount() != 0 {
+			t.Error("dry run should not load rules")
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		rr := request(http.MethodPut, "/state", desired)
+		var resp response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || !resp.Applied || engine.RulesCount() != 1 {
+			t.Fatalf("expected rule to be applied, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = request(http.MethodPut, "/state", desired)
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if len(resp.Plan.Rules) != 0 || resp.Plan.Unchanged != 1 {
+			t.Errorf("expected no changes on repeated apply, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		rr := request(http.MethodGet, "/state", "")
+		var spec struct {
+			Rules []map[string]interface{} `json:"rules"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &spec)
+		if rr.Code != http.StatusOK || len(spec.Rules) != 1 || spec.Rules[0]["id"] != "high-value" {
+			t.Errorf("unexpected state: %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := []string{
+			`{"rules": [{"id": "bad", "name": "Bad", "expression": "amount >"}]}`,
+			`{"tenants": []}`,
+			`not json`,
+		}
+		for _, body := range cases {
+			if rr := request(http.MethodPut, "/state", body); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+		if engine.RulesCount() != 1 {
+			t.Errorf("expected rules unchanged, got %d", engine.RulesCount())
+		}
+	})
+}
+
+func TestRulePacks(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	typologies := rules.NewTypologyEngine()
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, typologies, tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, tenantID, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", tenantID)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	type response struct {
+		Pack string `json:"pack"`
+		Plan struct {
+			Rules      []map[string]interface{} `json:"rules"`
+			Typologies []map[string]interface{} `json:"typologies"`
+		} `json:"plan"`
+		Applied bool `json:"applied"`
+	}
+
+	t.Run("lists built-in packs", func(t *testing.T) {
+		rr := request(http.MethodGet, "/rulepacks", "tenant-a", "", "")
+		var resp struct {
+			Packs []RulePackSummary `json:"packs"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || len(resp.Packs) < 2 {
+			t.Fatalf("expected the built-in packs, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = request(http.MethodGet, "/rulepacks/fatf-structuring?format=yaml", "tenant-a", "", "")
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/yaml" ||
+			!strings.Contains(rr.Body.String(), "kind: osprey.rulepack/v1") {
+			t.Errorf("expected the pack as YAML, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := request(http.MethodGet, "/rulepacks/missing", "tenant-a", "", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("installs a built-in pack", func(t *testing.T) {
+		rr := request(http.MethodPost, "/rulepacks/install?dryRun=true", "tenant-a", "", `{"name": "fatf-structuring"}`)
+		var resp response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || resp.Applied || len(resp.Plan.Rules) != 3 || engine.RulesCount() != 0 {
+			t.Fatalf("expected a plan of 3 rules, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = request(http.MethodPost, "/rulepacks/install", "tenant-a", "", `{"name": "fatf-structuring"}`)
+		resp = response{}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || !resp.Applied || resp.Pack != "fatf-structuring" {
+			t.Fatalf("expected the pack applied, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if got := len(engine.GetTenantRules("tenant-a")); got != 3 {
+			t.Errorf("expected 3 rules loaded for the tenant, got %d", got)
+		}
+		if got := len(typologies.GetTenantTypologies("tenant-a")); got != 1 {
+			t.Errorf("expected 1 typology loaded for the tenant, got %d", got)
+		}
+
+		entries, _ := repo.ListAuditEntries(ctx, "tenant-a", domain.AuditFilter{Resource: domain.AuditRulePack})
+		if len(entries) != 1 || entries[0].ResourceID != "fatf-structuring" {
+			t.Errorf("expected the install to be audited, got %+v", entries)
+		}
+		if rr := request(http.MethodPost, "/rulepacks/install", "tenant-a", "", `{"name": "missing"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("exports and imports", func(t *testing.T) {
+		rr := request(http.MethodGet, "/rulepacks/export?format=yaml&name=acme", "tenant-a", "", "")
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "name: acme") {
+			t.Fatalf("unexpected export: %d %s", rr.Code, rr.Body.String())
+		}
+
+		rr = request(http.MethodPost, "/rulepacks/import", "tenant-b", "application/yaml", rr.Body.String())
+		var resp response
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || !resp.Applied || len(resp.Plan.Rules) != 3 || len(resp.Plan.Typologies) != 1 {
+			t.Fatalf("expected the export imported, got %d: %s", rr.Code, rr.Body.String())
+		}
+		stored, _ := repo.ListRuleConfigs(ctx, "tenant-b")
+		if len(stored) != 3 {
+			t.Errorf("expected 3 rules stored for the importing tenant, got %d", len(stored))
+		}
+	})
+
+	t.Run("rejects invalid packs as a whole", func(t *testing.T) {
+		cases := []string{
+			// the typology refers to a rule neither in the pack nor stored
+			`{"kind": "osprey.rulepack/v1", "name": "p",
+			  "rules": [{"id": "ok", "name": "OK", "expression": "amount > 1.0", "weight": 1}],
+			  "typologies": [{"id": "t", "name": "T", "alertThreshold": 0.5, "rules": [{"ruleId": "missing", "weight": 1}]}]}`,
+			`{"kind": "osprey.rulepack/v1", "name": "p", "rules": [{"id": "bad", "name": "Bad", "expression": "amount >"}]}`,
+			`{"kind": "osprey.rulepack/v2", "name": "p"}`,
+			`not json`,
+		}
+		for _, body := range cases {
+			if rr := request(http.MethodPost, "/rulepacks/import", "tenant-c", "application/json", body); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+		if stored, _ := repo.ListRuleConfigs(ctx, "tenant-c"); len(stored) != 0 {
+			t.Errorf("expected nothing saved, got %d rules", len(stored))
+		}
+	})
+}
+
+func TestRuleSamples(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("sampleRate is validated", func(t *testing.T) {
+		rr := request(http.MethodPost, "/rules", `{"id": "sampled", "name": "Sampled", "expression": "amount > 1.0", "sampleRate": 1.5}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	for _, tenantID := range []string{"tenant-001", "tenant-002"} {
+		repo.SaveActivationSample(ctx, tenantID, &domain.ActivationSample{
+			ID: "sample-" + tenantID, RuleID: "sampled", TxID: "tx-001", CreatedAt: time.Now(),
+			Activation: map[string]any{"amount": 5.0},
+		})
+	}
+
+	t.Run("lists the tenant's samples", func(t *testing.T) {
+		rr := request(http.MethodGet, "/rules/sampled/samples", "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Samples []domain.ActivationSample `json:"samples"`
+			Count   int                       `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Samples[0].ID != "sample-tenant-001" {
+			t.Errorf("unexpected response: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		for _, limit := range []string{"0", "501", "abc"} {
+			if rr := request(http.MethodGet, "/rules/sampled/samples?limit="+limit, ""); rr.Code != http.StatusBadRequest {
+				t.Errorf("limit=%s: expected status 400, got %d", limit, rr.Code)
+			}
+		}
+	})
+}
+
+func TestQueueMetrics(t *testing.T) {
+	// The fake bus stamps messages at ospreytest.Epoch, so they arrive lagging
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 2)
+	w := worker.NewWorker(eventBus, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	if err := w.Start(worker.Config{TenantIDs: []string{`tenant-"a"`}, MaxLag: time.Minute}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	server := createTestServerWithMode(domain.ModeDetection, false, WithQueue(w))
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	t.Run("no traffic", func(t *testing.T) {
+		rr := get("/health")
+		var resp map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp["status"] != "healthy" || resp["queue"] == nil {
+			t.Errorf("expected healthy status with queue details, got %s", rr.Body.String())
+		}
+	})
+
+	payload, _ := json.Marshal(ospreytest.NewTransaction().Tenant(`tenant-"a"`).Message())
+	eventBus.Publish(context.Background(), `tenant-"a"`, domain.TopicTransactionIngested, payload)
+
+	t.Run("metrics", func(t *testing.T) {
+		rr := get("/metrics")
+		if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain") {
+			t.Fatalf("expected text metrics, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+		}
+		body := rr.Body.String()
+		for _, want := range []string{
+			"# TYPE osprey_queue_backlog gauge",
+			`osprey_queue_processed_total{tenant="tenant-\"a\""} 1`,
+			`osprey_queue_lagging{tenant="tenant-\"a\""} 1`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %q in metrics:\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("health is degraded", func(t *testing.T) {
+		var resp struct {
+			Status string             `json:"status"`
+			Queue  worker.QueueStatus `json:"queue"`
+		}
+		json.Unmarshal(get("/health").Body.Bytes(), &resp)
+		if resp.Status != "degraded" || !resp.Queue.Lagging || len(resp.Queue.Tenants) != 1 {
+			t.Errorf("expected degraded health with a lagging tenant, got %+v", resp)
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("url is validated", func(t *testing.T) {
+		for _, url := range []string{"", "ftp://example.com/hook", "/relative", "https://"} {
+			rr := request(http.MethodPost, "/webhooks", `{"url": "`+url+`"}`)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("url %q: expected status 400, got %d", url, rr.Code)
+			}
+		}
+	})
+
+	t.Run("feed is validated", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url": "https://example.com/hook", "feed": "everything"}`,
+			`{"url": "https://example.com/hook", "feed": "decisions", "batchSize": 5000}`,
+			`{"url": "https://example.com/hook", "filter": {"statuses": ["PEND"]}}`,
+			`{"url": "https://example.com/hook", "filter": {"minScore": 0.8, "maxScore": 0.2}}`,
+		} {
+			rr := request(http.MethodPost, "/webhooks", body)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+
+		rr := request(http.MethodPost, "/webhooks", `{"url": "https://example.com/hook", "feed": "decisions", "batchSize": 10, "filter": {"statuses": ["NALT"]}}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var webhook domain.Webhook
+		json.Unmarshal(rr.Body.Bytes(), &webhook)
+		if webhook.Feed != domain.WebhookFeedDecisions || webhook.BatchSize != 10 || webhook.Filter == nil {
+			t.Errorf("unexpected decisions webhook: %s", rr.Body.String())
+		}
+		request(http.MethodDelete, "/webhooks/"+webhook.ID, "")
+	})
+
+	var created domain.Webhook
+	t.Run("create returns the secret once", func(t *testing.T) {
+		rr := request(http.MethodPost, "/webhooks", `{"url": "https://example.com/hooks/osprey"}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		json.Unmarshal(rr.Body.Bytes(), &created)
+		if created.ID == "" || len(created.Secret) != 64 {
+			t.Fatalf("expected an ID and a generated secret, got %s", rr.Body.String())
+		}
+
+		rr = request(http.MethodGet, "/webhooks", "")
+		var resp struct {
+			Webhooks []domain.Webhook `json:"webhooks"`
+			Count    int              `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Webhooks[0].ID != created.ID {
+			t.Fatalf("unexpected list response: %s", rr.Body.String())
+		}
+		if strings.Contains(rr.Body.String(), created.Secret) {
+			t.Error("expected secrets to be omitted from the list")
+		}
+	})
+
+	t.Run("lists deliveries", func(t *testing.T) {
+		repo.SaveWebhookDelivery(ctx, "tenant-001", &domain.WebhookDelivery{
+			ID: "delivery-1", WebhookID: created.ID, EvaluationID: "eval-1",
+			Event: domain.WebhookEventAlert, Status: domain.DeliveryPending, CreatedAt: time.Now(),
+		})
+
+		rr := request(http.MethodGet, "/webhooks/"+created.ID+"/deliveries", "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Deliveries []domain.WebhookDelivery `json:"deliveries"`
+			Count      int                      `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Deliveries[0].ID != "delivery-1" {
+			t.Errorf("unexpected response: %s", rr.Body.String())
+		}
+
+		if rr := request(http.MethodGet, "/webhooks/"+created.ID+"/deliveries?limit=0", ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for limit=0, got %d", rr.Code)
+		}
+		if rr := request(http.MethodGet, "/webhooks/missing/deliveries", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for an unknown webhook, got %d", rr.Code)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if rr := request(http.MethodDelete, "/webhooks/"+created.ID, ""); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := request(http.MethodDelete, "/webhooks/"+created.ID, ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 deleting twice, got %d", rr.Code)
+		}
+	})
+}
+
+func TestTxTypes(t *testing.T) {
+	evaluate := func(server *Server, tenantID, txType string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(TransactionRequest{
+			Type:     txType,
+			Debtor:   PartyInfo{ID: "debtor-001"},
+			Creditor: PartyInfo{ID: "creditor-001"},
+			Amount:   AmountInfo{Value: domain.MustDecimal("100"), Currency: "USD"},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewBuffer(body))
+		req.Header.Set("X-Tenant-ID", tenantID)
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	allowed := map[string][]string{"tenant-001": {"transfer"}}
+
+	t.Run("Reject", func(t *testing.T) {
+		policy := txtypes.NewPolicy(domain.TxTypeConfig{Allowed: allowed, Unknown: domain.TxTypeReject})
+		server := createTestServerWithMode(domain.ModeDetection, false, WithTxTypes(policy))
+
+		if rr := evaluate(server, "tenant-001", "transfer"); rr.Code != http.StatusOK {
+			t.Errorf("expected allowed type to evaluate, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := evaluate(server, "tenant-001", "trasnfer"); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected unknown type to be rejected, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := evaluate(server, "tenant-002", "anything"); rr.Code != http.StatusOK {
+			t.Errorf("expected tenant without a list to allow every type, got %d", rr.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/transaction-types", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		var resp struct {
+			Allowed []string              `json:"allowed"`
+			Unknown []txtypes.UnknownType `json:"unknown"`
+			Action  string                `json:"action"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if len(resp.Allowed) != 1 || resp.Action != domain.TxTypeReject {
+			t.Errorf("unexpected policy: %s", rr.Body.String())
+		}
+		if len(resp.Unknown) != 1 || resp.Unknown[0].Type != "trasnfer" || resp.Unknown[0].Count != 1 {
+			t.Errorf("expected unknown type statistics, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		policy := txtypes.NewPolicy(domain.TxTypeConfig{Allowed: allowed, Unknown: domain.TxTypeFlag})
+		server := createTestServerWithMode(domain.ModeDetection, false, WithTxTypes(policy))
+
+		rr := evaluate(server, "tenant-001", "trasnfer")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected flagged type to evaluate, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp EvaluateResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if !resp.Metadata.UnknownTxType {
+			t.Error("expected unknownTxType in metadata")
+		}
+
+		var allowedResp EvaluateResponse
+		json.Unmarshal(evaluate(server, "tenant-001", "transfer").Body.Bytes(), &allowedResp)
+		if allowedResp.Metadata.UnknownTxType {
+			t.Error("expected allowed type not to be flagged")
+		}
+	})
+}
+
+func TestIndexAdvisor(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 2)
+	request := func(server *Server, method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+		if rr := request(server, http.MethodGet, "/admin/indexes", ""); rr.Code != http.StatusNotImplemented {
+			t.Errorf("expected 501 without an index advisor, got %d", rr.Code)
+		}
+	})
+
+	repo, err := repository.New(domain.RepositoryConfig{Driver: "sqlite", SQLitePath: t.TempDir() + "/osprey.db"})
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	defer repo.Close()
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	t.Run("Advise", func(t *testing.T) {
+		rr := request(server, http.MethodGet, "/admin/indexes", "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var report domain.IndexReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		if report.Driver != "sqlite" || len(report.Indexes) == 0 {
+			t.Errorf("unexpected report: %s", rr.Body.String())
+		}
+		for _, advice := range report.Indexes {
+			if advice.Recommended {
+				t.Errorf("expected no recommendation without traffic, got %+v", advice)
+			}
+		}
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		rr := request(server, http.MethodPost, "/admin/indexes", `{"names":["idx_alerts_tenant_status"]}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var report domain.IndexReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		for _, advice := range report.Indexes {
+			if advice.Name == "idx_alerts_tenant_status" && (!advice.Created || !advice.Exists) {
+				t.Errorf("expected index to be created, got %+v", advice)
+			}
+		}
+
+		if rr := request(server, http.MethodPost, "/admin/indexes", `{"names":["idx_everything"]}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for unknown index, got %d", rr.Code)
+		}
+	})
+}
+
+func TestIsolationAudit(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 2)
+	request := func(server *Server, method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+		if rr := request(server, http.MethodGet, "/admin/isolation"); rr.Code != http.StatusNotImplemented {
+			t.Errorf("expected 501 without an isolation auditor, got %d", rr.Code)
+		}
+	})
+
+	ctx := context.Background()
+	repo, err := repository.New(domain.RepositoryConfig{Driver: "sqlite", SQLitePath: t.TempDir() + "/osprey.db"})
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	defer repo.Close()
+	repo.SaveRuleConfig(ctx, "tenant-b", &domain.RuleConfig{ID: "b-rule", Name: "b-rule", Version: "1.0.0", Expression: "true", Enabled: true})
+	repo.SaveTypology(ctx, "tenant-a", &domain.Typology{ID: "mixed", Name: "mixed", Version: "1.0.0", Enabled: true, Rules: []domain.TypologyRuleWeight{{RuleID: "b-rule", Weight: 1}}})
+
+	// The auditor is found behind the repository wrappers
+	server := NewServer(domain.ServerConfig{}, alerts.Wrap(repo), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	rr := request(server, http.MethodGet, "/admin/isolation")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var report domain.IsolationReport
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if len(report.Violations) != 1 || report.Violations[0].Check != domain.IsolationTypologyRule || report.Repaired != 0 {
+		t.Errorf("unexpected audit: %s", rr.Body.String())
+	}
+
+	rr = request(server, http.MethodPost, "/admin/isolation")
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if rr.Code != http.StatusOK || report.Repaired != 1 || !report.Violations[0].Repaired {
+		t.Errorf("expected the violation to be repaired, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("entries are validated", func(t *testing.T) {
+		for _, body := range []string{
+			`{"listType": "pep", "partyId": "cust-001"}`,
+			`{"listType": "sanctions"}`,
+			`{"listType": "sanctions", "name": "  "}`,
+			`{"listType": "sanctions", "name": "Ivan Petrov", "country": "Russia"}`,
+		} {
+			rr := request(http.MethodPost, "/lists", body)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+	})
+
+	var created domain.WatchlistEntry
+	t.Run("create, update and delete", func(t *testing.T) {
+		rr := request(http.MethodPost, "/lists", `{"listType": "sanctions", "name": " Ivan Petrov ", "country": "ru"}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		json.Unmarshal(rr.Body.Bytes(), &created)
+		if created.ID == "" || created.Name != "Ivan Petrov" || created.Country != "RU" {
+			t.Fatalf("expected a normalized entry, got %s", rr.Body.String())
+		}
+
+		rr = request(http.MethodPut, "/lists/"+created.ID, `{"listType": "blocklist", "accountId": "acct-bad"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		rr = request(http.MethodGet, "/lists/"+created.ID, "")
+		var got domain.WatchlistEntry
+		json.Unmarshal(rr.Body.Bytes(), &got)
+		if got.ListType != domain.WatchlistBlocklist || got.AccountID != "acct-bad" || got.Name != "" {
+			t.Errorf("expected the entry replaced, got %s", rr.Body.String())
+		}
+
+		if rr := request(http.MethodPut, "/lists/missing", `{"listType": "blocklist", "partyId": "x"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 updating a missing entry, got %d", rr.Code)
+		}
+	})
+
+	t.Run("lists by type", func(t *testing.T) {
+		request(http.MethodPost, "/lists", `{"listType": "watchlist", "partyId": "cust-009"}`)
+
+		rr := request(http.MethodGet, "/lists?type=watchlist", "")
+		var resp struct {
+			Entries []domain.WatchlistEntry `json:"entries"`
+			Count   int                     `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Entries[0].PartyID != "cust-009" {
+			t.Errorf("unexpected list response: %s", rr.Body.String())
+		}
+		if rr := request(http.MethodGet, "/lists?type=pep", ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an unknown type, got %d", rr.Code)
+		}
+		if rr := request(http.MethodGet, "/lists?limit=0", ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for limit=0, got %d", rr.Code)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if rr := request(http.MethodDelete, "/lists/"+created.ID, ""); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rr.Code)
+		}
+		if rr := request(http.MethodGet, "/lists/"+created.ID, ""); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 after delete, got %d", rr.Code)
+		}
+	})
+}
+
+func TestOutcomes(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("tx-001").Tenant("tenant-001").From("cust-001").To("shop-001").Build())
+	repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-001", TenantID: "tenant-001", TxID: "tx-001", Status: domain.StatusAlert})
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "ops@example.com")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("outcomes are validated", func(t *testing.T) {
+		for _, body := range []string{
+			`{"outcome": "refund"}`,
+			`{"outcome": "chargeback", "amount": -5}`,
+			`{"outcome": "chargeback", "occurredAt": "2999-01-01T00:00:00Z"}`,
+		} {
+			if rr := request(http.MethodPost, "/evaluations/eval-001/outcome", body); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+		if rr := request(http.MethodPost, "/evaluations/missing/outcome", `{"outcome": "chargeback"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for an unknown evaluation, got %d", rr.Code)
+		}
+	})
+
+	t.Run("report and list", func(t *testing.T) {
+		rr := request(http.MethodPost, "/evaluations/eval-001/outcome", `{"outcome": "challenge_failed", "reason": "wrong OTP"}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var outcome domain.EvaluationOutcome
+		json.Unmarshal(rr.Body.Bytes(), &outcome)
+		if outcome.ID == "" || outcome.TxID != "tx-001" || outcome.DebtorID != "cust-001" || outcome.CreditorID != "shop-001" {
+			t.Errorf("expected the transaction and parties to be copied, got %+v", outcome)
+		}
+		if outcome.ReportedBy != "ops@example.com" || outcome.OccurredAt.IsZero() {
+			t.Errorf("expected the principal and a default occurredAt, got %+v", outcome)
+		}
+
+		request(http.MethodPost, "/evaluations/eval-001/outcome", `{"outcome": "chargeback", "amount": 99.5}`)
+
+		var resp struct {
+			Outcomes []domain.EvaluationOutcome `json:"outcomes"`
+			Count    int                        `json:"count"`
+		}
+		rr = request(http.MethodGet, "/evaluations/eval-001/outcomes", "")
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || resp.Count != 2 {
+			t.Errorf("expected 2 outcomes for the evaluation, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = request(http.MethodGet, "/outcomes?party=shop-001&outcome=chargeback", "")
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != 1 || resp.Outcomes[0].Amount.String() != "99.5" {
+			t.Errorf("expected the party's chargeback, got %s", rr.Body.String())
+		}
+
+		for _, query := range []string{"outcome=refund", "label=fraud", "since=yesterday", "limit=0"} {
+			if rr := request(http.MethodGet, "/outcomes?"+query, ""); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+			}
+		}
+	})
+
+	t.Run("import and report losses", func(t *testing.T) {
+		for _, body := range []string{`{"events": []}`, `{"events": `} {
+			if rr := request(http.MethodPost, "/outcomes/import", body); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+			}
+		}
+
+		rr := request(http.MethodPost, "/outcomes/import", `{"events": [
+			{"id": "cb-881", "txId": "tx-001", "outcome": "chargeback", "reasonCode": "10.4", "amount": 20},
+			{"id": "cb-882", "txId": "tx-404", "outcome": "chargeback"}
+		]}`)
+		var resp struct {
+			Results  []domain.OutcomeImportResult `json:"results"`
+			Imported int                          `json:"imported"`
+			Rejected int                          `json:"rejected"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if rr.Code != http.StatusOK || resp.Imported != 1 || resp.Rejected != 1 {
+			t.Fatalf("expected one imported and one rejected event, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if resp.Results[0].EvaluationID != "eval-001" || resp.Results[0].Label != domain.OutcomeLabelCaught || resp.Results[1].Status != domain.OutcomeImportNotFound {
+			t.Errorf("unexpected results: %+v", resp.Results)
+		}
+
+		var listed struct {
+			Count int `json:"count"`
+		}
+		rr = request(http.MethodGet, "/outcomes?label=caught", "")
+		json.Unmarshal(rr.Body.Bytes(), &listed)
+		if listed.Count != 2 {
+			t.Errorf("expected the reported and imported chargebacks to be labelled caught, got %s", rr.Body.String())
+		}
+
+		rr = request(http.MethodGet, "/outcomes/losses", "")
+		var report domain.LossReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		if rr.Code != http.StatusOK || report.Losses != 2 || report.Amount.String() != "119.5" || report.Caught != 2 {
+			t.Errorf("unexpected loss report: %d %s", rr.Code, rr.Body.String())
+		}
+		if rr := request(http.MethodGet, "/outcomes/losses?since=yesterday", ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an invalid since, got %d", rr.Code)
+		}
+	})
+}
+
+func TestListEvaluations(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("tx-001").Tenant("tenant-001").From("cust-001").To("shop-001").Build())
+	for i := 0; i < 5; i++ {
+		repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{
+			ID:        fmt.Sprintf("eval-%03d", i),
+			TxID:      "tx-001",
+			Status:    domain.StatusAlert,
+			Score:     0.9,
+			Timestamp: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-pass", TxID: "tx-002", Status: domain.StatusNoAlert, Score: 0.1, Timestamp: base})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	type page struct {
+		Evaluations []domain.Evaluation `json:"evaluations"`
+		Count       int                 `json:"count"`
+		NextCursor  string              `json:"nextCursor"`
+	}
+
+	t.Run("pages through the matches", func(t *testing.T) {
+		var ids []string
+		path := "/evaluations?status=ALRT&debtor=cust-001&limit=2"
+		for pages := 0; pages < 5; pages++ {
+			rr := request(path)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var p page
+			json.Unmarshal(rr.Body.Bytes(), &p)
+			for _, e := range p.Evaluations {
+				ids = append(ids, e.ID)
+			}
+			if p.NextCursor == "" {
+				break
+			}
+			path = "/evaluations?status=ALRT&debtor=cust-001&limit=2&cursor=" + p.NextCursor
+		}
+		if got := strings.Join(ids, ","); got != "eval-004,eval-003,eval-002,eval-001,eval-000" {
+			t.Errorf("expected every alert latest first across pages, got %s", got)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		var p page
+		json.Unmarshal(request("/evaluations?maxScore=0.5").Body.Bytes(), &p)
+		if p.Count != 1 || p.Evaluations[0].ID != "eval-pass" || p.NextCursor != "" {
+			t.Errorf("expected the passed evaluation only, got %+v", p)
+		}
+		json.Unmarshal(request("/evaluations?since=2026-03-01T13:00:00Z&until=2026-03-01T15:00:00Z").Body.Bytes(), &p)
+		if p.Count != 2 {
+			t.Errorf("expected 2 evaluations in the time range, got %d", p.Count)
+		}
+	})
+
+	t.Run("contributions", func(t *testing.T) {
+		repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{
+			ID: "eval-rule", TxID: "tx-003", Status: domain.StatusAlert, Score: 0.8, Timestamp: base,
+			RuleResults:     []domain.RuleResult{{RuleID: "high-value", SubRuleRef: domain.RuleOutcomeFail}},
+			TypologyResults: []domain.TypologyResult{{TypologyID: "mule", Score: 0.6}},
+		})
+		repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "eval-rule", TxID: "tx-003", Score: 0.8, CreatedAt: base})
+		repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "eval-000", TxID: "tx-001", Score: 0.9, CreatedAt: base})
+
+		var p page
+		json.Unmarshal(request("/evaluations?rule=high-value").Body.Bytes(), &p)
+		if p.Count != 1 || p.Evaluations[0].ID != "eval-rule" {
+			t.Errorf("expected the evaluation the rule fired in, got %+v", p)
+		}
+		json.Unmarshal(request("/evaluations?typology=mule&minContribution=0.5").Body.Bytes(), &p)
+		if p.Count != 1 || p.Evaluations[0].ID != "eval-rule" {
+			t.Errorf("expected the evaluation the typology scored in, got %+v", p)
+		}
+
+		var alerts struct {
+			Alerts []domain.Alert `json:"alerts"`
+		}
+		json.Unmarshal(request("/alerts?rule=high-value").Body.Bytes(), &alerts)
+		if len(alerts.Alerts) != 1 || alerts.Alerts[0].ID != "eval-rule" {
+			t.Errorf("expected the alert the rule caused, got %+v", alerts.Alerts)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"status=ALERT", "minScore=2", "since=yesterday", "until=1", "limit=0", "cursor=bogus", "minContribution=0.5", "typology=mule&minContribution=x"} {
+			if rr := request("/evaluations?" + query); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+			}
+		}
+	})
+}
+
+func TestListEntityTransactions(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID(fmt.Sprintf("tx-out-%d", i)).Tenant("tenant-001").From("cust-001").To("shop-001").Amount(float64(100*(i+1)), "USD").At(base.Add(time.Duration(i)*time.Hour)).Build())
+	}
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("tx-in").Tenant("tenant-001").Type("refund").From("shop-001").To("cust-001").Amount(50, "USD").At(base.Add(30*time.Minute)).Build())
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("tx-other").Tenant("tenant-001").From("cust-002").To("shop-001").At(base).Build())
+	repo.SaveTransaction(ctx, "tenant-002", ospreytest.NewTransaction().ID("tx-foreign").Tenant("tenant-002").From("cust-001").To("shop-001").At(base).Build())
+
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	type page struct {
+		Transactions []domain.Transaction `json:"transactions"`
+		Count        int                  `json:"count"`
+		NextOffset   int                  `json:"nextOffset"`
+	}
+	ids := func(p page) string {
+		var out []string
+		for _, tx := range p.Transactions {
+			out = append(out, tx.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	since := "since=2026-03-01T00:00:00Z"
+
+	t.Run("pages through both directions", func(t *testing.T) {
+		var p page
+		rr := request("/entities/cust-001/transactions?limit=3&" + since)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		json.Unmarshal(rr.Body.Bytes(), &p)
+		if got := ids(p); got != "tx-out-3,tx-out-2,tx-out-1" || p.NextOffset != 3 {
+			t.Errorf("unexpected first page %s, next offset %d", got, p.NextOffset)
+		}
+		p = page{}
+		json.Unmarshal(request("/entities/cust-001/transactions?limit=3&offset=3&"+since).Body.Bytes(), &p)
+		if got := ids(p); got != "tx-in,tx-out-0" || p.NextOffset != 0 {
+			t.Errorf("unexpected last page %s, next offset %d", got, p.NextOffset)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		var p page
+		json.Unmarshal(request("/entities/cust-001/transactions?type=refund&"+since).Body.Bytes(), &p)
+		if got := ids(p); got != "tx-in" {
+			t.Errorf("expected the refund only, got %s", got)
+		}
+		json.Unmarshal(request("/entities/cust-001/transactions?minAmount=200&maxAmount=300&"+since).Body.Bytes(), &p)
+		if got := ids(p); got != "tx-out-2,tx-out-1" {
+			t.Errorf("expected the transactions in the amount range, got %s", got)
+		}
+		json.Unmarshal(request("/entities/cust-001/transactions?since=2026-03-01T13:00:00Z&until=2026-03-01T15:00:00Z").Body.Bytes(), &p)
+		if got := ids(p); got != "tx-out-2,tx-out-1" {
+			t.Errorf("expected the transactions in the time range, got %s", got)
+		}
+		json.Unmarshal(request("/entities/cust-001/transactions").Body.Bytes(), &p)
+		if p.Count != 0 || p.Transactions == nil {
+			t.Errorf("expected an empty list outside the default window, got %+v", p)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"since=yesterday", "until=1", "minAmount=-1", "maxAmount=lots", "limit=1001", "offset=-1"} {
+			if rr := request("/entities/cust-001/transactions?" + query); rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+			}
+		}
+	})
+}
+
+func TestBacktestRule(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	base := time.Now().UTC().Add(-48 * time.Hour)
+	for i, amount := range []float64{100, 2000, 7000, 9000} {
+		repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID(fmt.Sprintf("tx-%d", i)).Tenant("tenant-001").Amount(amount, "USD").At(base.Add(time.Duration(i)*time.Hour)).Build())
+	}
+
+	request := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/rules/backtest", strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request(`{"id": "large-amount", "expression": "amount >= 5000.0", "bands": [{"lowerLimit": 1, "subRuleRef": ".fail", "reason": "large amount"}]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var report domain.BacktestReport
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if report.RuleID != "large-amount" || report.Transactions != 4 || report.Matched != 2 || report.Alerts != 2 || report.AlertRate != 0.5 {
+		t.Errorf("unexpected report: %s", rr.Body.String())
+	}
+	if len(report.Scores) != 10 || report.Scores[0].Count != 2 || report.Scores[9].Count != 2 {
+		t.Errorf("unexpected score distribution: %+v", report.Scores)
+	}
+	if len(engine.GetLoadedRules()) != 0 {
+		t.Error("expected the candidate rule not to be loaded")
+	}
+
+	since := base.Add(90 * time.Minute).Format(time.RFC3339)
+	json.Unmarshal(request(`{"expression": "amount >= 5000.0", "since": "`+since+`"}`).Body.Bytes(), &report)
+	if report.Transactions != 2 {
+		t.Errorf("expected the transactions since %s, got %d", since, report.Transactions)
+	}
+
+	for _, body := range []string{`{`, `{"bands": []}`, `{"expression": "amount >"}`, `{"expression": "true", "since": "2026-03-02T00:00:00Z", "until": "2026-03-01T00:00:00Z"}`} {
+		if rr := request(body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+func TestExplainEvaluation(t *testing.T) {
+	engine, _ := rules.NewEngine(func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+		return 7, nil
+	}, 5)
+	engine.LoadRule(&domain.RuleConfig{
+		ID:         "velocity",
+		Expression: "velocity_count > 5",
+		Bands:      []domain.RuleBand{{SubRuleRef: domain.RuleOutcomeFail, Reason: "High velocity"}},
+		Weight:     1.0,
+		Enabled:    true,
+	})
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request(http.MethodPost, "/evaluate", `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-1"},"creditor":{"id":"creditor-001","accountId":"acc-2"},"amount":{"value":100,"currency":"USD"},"velocityWindow":3600}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var eval EvaluateResponse
+	json.Unmarshal(rr.Body.Bytes(), &eval)
+
+	rr = request(http.MethodGet, "/evaluations/"+eval.EvaluationID+"/explain", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var explanation EvaluationExplanation
+	json.Unmarshal(rr.Body.Bytes(), &explanation)
+	if len(explanation.FiredRules) != 1 || explanation.FiredRules[0].RuleID != "velocity" {
+		t.Errorf("expected the velocity rule to have fired, got %+v", explanation.FiredRules)
+	}
+	want := domain.VelocitySnapshot{Variable: "velocity_count", EntityID: "debtor-001", WindowSeconds: 3600, Value: 7}
+	if len(explanation.Velocity) != 1 || explanation.Velocity[0] != want {
+		t.Errorf("expected velocity %+v, got %+v", want, explanation.Velocity)
+	}
+
+	if rr := request(http.MethodGet, "/evaluations/unknown/explain", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown evaluation, got %d", rr.Code)
+	}
+}
+
+func TestEvaluateExplain(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRule(&domain.RuleConfig{
+		ID:         "new-device",
+		Expression: "0.8",
+		Bands:      []domain.RuleBand{{SubRuleRef: domain.RuleOutcomeReview, Reason: "New device", Action: domain.BandActionStepUpAuth}},
+		Weight:     3.0,
+		Enabled:    true,
+	})
+	engine.LoadRule(&domain.RuleConfig{ID: "amount", Expression: "0.4", Weight: 1.0, Enabled: true})
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	evaluate := func(path string) EvaluateResponse {
+		t.Helper()
+		body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-1"},"creditor":{"id":"creditor-001","accountId":"acc-2"},"amount":{"value":100,"currency":"USD"}}`
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp EvaluateResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp
+	}
+
+	if resp := evaluate("/evaluate"); resp.Explanation != nil {
+		t.Errorf("expected no explanation unless asked for, got %+v", resp.Explanation)
+	}
+
+	resp := evaluate("/evaluate?explain=true")
+	if resp.Explanation == nil {
+		t.Fatal("expected an explanation")
+	}
+
+	// Weighted: (0.8*3 + 0.4*1) / 4 = 0.7
+	near := func(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
+	rulesByID := make(map[string]RuleExplanation)
+	for _, rule := range resp.Explanation.Rules {
+		rulesByID[rule.RuleID] = rule
+	}
+	device := rulesByID["new-device"]
+	if device.Band != domain.RuleOutcomeReview || device.Weight != 3 || !near(device.Contribution, 0.6) || device.Action != domain.BandActionStepUpAuth {
+		t.Errorf("unexpected new-device breakdown: %+v", device)
+	}
+	if amount := rulesByID["amount"]; amount.Band != domain.RuleOutcomePass || !near(amount.Contribution, 0.1) {
+		t.Errorf("unexpected amount breakdown: %+v", amount)
+	}
+	if len(resp.Explanation.Typologies) != 1 {
+		t.Fatalf("expected the detection summary, got %+v", resp.Explanation.Typologies)
+	}
+	summary := resp.Explanation.Typologies[0]
+	if summary.TypologyID != tadp.DetectionSummaryID || !summary.Triggered || len(summary.Contributions) != 2 || !near(summary.Score, resp.Score) {
+		t.Errorf("unexpected detection summary: %+v", summary)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, eventBus, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	repo.SaveDeadLetter(ctx, "tenant-001", &domain.DeadLetter{
+		ID:        "dl-1",
+		Topic:     domain.TopicTransactionIngested,
+		MessageID: "msg-1",
+		Payload:   `{"txId":"tx-1"}`,
+		Error:     "rule evaluation failed",
+		Attempts:  4,
+		FailedAt:  ospreytest.Epoch,
+	})
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request(http.MethodGet, "/dlq?pending=true")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var list struct {
+		DeadLetters []domain.DeadLetter `json:"deadLetters"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.DeadLetters) != 1 || list.DeadLetters[0].Payload != `{"txId":"tx-1"}` {
+		t.Fatalf("expected the dead letter, got %+v", list.DeadLetters)
+	}
+
+	rr = request(http.MethodPost, "/dlq/dl-1/replay")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	replayed := eventBus.Published("tenant-001", domain.TopicTransactionIngested)
+	if len(replayed) != 1 || string(replayed[0].Payload) != `{"txId":"tx-1"}` {
+		t.Errorf("expected the payload to be republished, got %+v", replayed)
+	}
+	if rr := request(http.MethodPost, "/dlq/dl-1/replay"); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 replaying twice, got %d", rr.Code)
+	}
+
+	rr = request(http.MethodGet, "/dlq?pending=true")
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.DeadLetters) != 0 {
+		t.Errorf("expected no pending dead letters after the replay, got %+v", list.DeadLetters)
+	}
+	if rr := request(http.MethodGet, "/dlq/unknown"); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown dead letter, got %d", rr.Code)
+	}
+	if rr := request(http.MethodGet, "/dlq?limit=0"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid limit, got %d", rr.Code)
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "ops@example.com")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	endsAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for _, body := range []string{
+		`{"endsAt":"` + endsAt + `"}`,
+		`{"endsAt":"2020-01-01T00:00:00Z","reason":"rerun"}`,
+		`{"endsAt":"` + time.Now().Add(8*24*time.Hour).UTC().Format(time.RFC3339) + `","reason":"rerun"}`,
+	} {
+		if rr := request(http.MethodPost, "/maintenance", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+		}
+	}
+
+	rr := request(http.MethodPost, "/maintenance", `{"endsAt":"`+endsAt+`","reason":"upstream batch rerun"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var window MaintenanceWindowResponse
+	json.Unmarshal(rr.Body.Bytes(), &window)
+	if window.MaintenanceWindow == nil || !window.Active || window.CreatedBy != "ops@example.com" {
+		t.Fatalf("expected an active window created by the principal, got %s", rr.Body.String())
+	}
+
+	rr = request(http.MethodDelete, "/maintenance/"+window.ID, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodDelete, "/maintenance/"+window.ID, ""); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 cancelling twice, got %d", rr.Code)
+	}
+	if rr := request(http.MethodDelete, "/maintenance/unknown", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown window, got %d", rr.Code)
+	}
+
+	rr = request(http.MethodGet, "/maintenance", "")
+	var list struct {
+		Windows []MaintenanceWindowResponse `json:"windows"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Windows) != 1 || list.Windows[0].Active || list.Windows[0].CancelledBy != "ops@example.com" {
+		t.Errorf("expected the cancelled window to be kept, got %s", rr.Body.String())
+	}
+}
+
+func TestAsyncEvaluate(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, eventBus, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	evaluate := func(path, prefer string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"creditor-001","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		if prefer != "" {
+			req.Header.Set("Prefer", prefer)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := evaluate("/evaluate?async=true", "")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var queued QueuedEvaluationResponse
+	json.Unmarshal(rr.Body.Bytes(), &queued)
+	if queued.TxID == "" || queued.Status != StatusPending {
+		t.Fatalf("expected a pending txId, got %s", rr.Body.String())
+	}
+	if got := rr.Header().Get("Location"); got != "/evaluations?txId="+queued.TxID {
+		t.Errorf("expected Location to the evaluation search, got %q", got)
+	}
+	if got := rr.Header().Get("Preference-Applied"); got != "" {
+		t.Errorf("expected no Preference-Applied without Prefer, got %q", got)
+	}
+
+	published := eventBus.Published("tenant-001", domain.TopicTransactionIngested)
+	if len(published) != 1 {
+		t.Fatalf("expected the transaction to be queued, got %d messages", len(published))
+	}
+	var msg worker.TransactionMessage
+	if err := json.Unmarshal(published[0].Payload, &msg); err != nil {
+		t.Fatalf("failed to decode queued message: %v", err)
+	}
+	if msg.TxID != queued.TxID || msg.DebtorID != "debtor-001" || msg.Amount != domain.MustDecimal("250") || msg.Request == nil {
+		t.Errorf("unexpected queued message %+v", msg)
+	}
+	if _, err := repo.GetTransaction(ctx, "tenant-001", queued.TxID); err != nil {
+		t.Errorf("expected the transaction to be stored: %v", err)
+	}
+	evaluations, _ := repo.ListEvaluations(ctx, "tenant-001", domain.EvaluationFilter{TxID: queued.TxID})
+	if len(evaluations) != 0 {
+		t.Errorf("expected no evaluation before the worker runs, got %d", len(evaluations))
+	}
+
+	rr = evaluate("/evaluate", "handling=lenient, respond-async")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202 with Prefer, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Preference-Applied"); got != "respond-async" {
+		t.Errorf("expected Preference-Applied: respond-async, got %q", got)
+	}
+
+	if rr := evaluate("/evaluate", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected a synchronous evaluation without async, got %d", rr.Code)
+	}
+	if got := len(eventBus.Published("tenant-001", domain.TopicTransactionIngested)); got != 2 {
+		t.Errorf("expected 2 queued transactions, got %d", got)
+	}
+}
+
+func TestTransactionLinks(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("sale-1").From("cust-1").To("merchant").Amount(300, "USD").Build())
+
+	evaluate := func(links string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"type":"refund","debtor":{"id":"merchant","accountId":"acc-m"},"creditor":{"id":"cust-1","accountId":"acc-c"},"amount":{"value":120,"currency":"USD"}` + links + `}`
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := evaluate(`,"reversalOf":"sale-1","partOfBatch":"refunds-0601","relatedTo":["ticket-9"]`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp EvaluateResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	tx, err := repo.GetTransaction(ctx, "tenant-001", resp.TxID)
+	if err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if tx.ReversalOf != "sale-1" || tx.PartOfBatch != "refunds-0601" || len(tx.RelatedTo) != 1 {
+		t.Errorf("expected the links to be stored, got %+v", tx)
+	}
+
+	for links, want := range map[string]int{
+		`,"reversalOf":"unknown"`:  http.StatusBadRequest,
+		`,"relatedTo":[""]`:        http.StatusBadRequest,
+		`,"partOfBatch":"batch-1"`: http.StatusOK,
+	} {
+		if rr := evaluate(links); rr.Code != want {
+			t.Errorf("%s: expected status %d, got %d: %s", links, want, rr.Code, rr.Body.String())
+		}
+	}
+
+	repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("sale-2").From("cust-1").To("merchant").Amount(100, "USD").Build())
+	if rr := evaluate(`,"reversalOf":"sale-2"`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a reversal above the original amount, got %d", rr.Code)
+	}
+}
+
+func TestClientTransactionID(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{MaxClockSkew: 5 * time.Minute, MaxTransactionAge: 30 * 24 * time.Hour}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	evaluate := func(fields string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"type":"transfer","debtor":{"id":"cust-1","accountId":"acc-1"},"creditor":{"id":"cust-2","accountId":"acc-2"},"amount":{"value":120,"currency":"USD"}` + fields + `}`
+		req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	at := time.Now().UTC().Add(-72 * time.Hour).Truncate(time.Second)
+	rr := evaluate(`,"txId":"core:2026-0001","timestamp":"` + at.Format(time.RFC3339) + `"`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp EvaluateResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.TxID != "core:2026-0001" {
+		t.Errorf("expected the client's transaction ID, got %q", resp.TxID)
+	}
+	tx, err := repo.GetTransaction(ctx, "tenant-001", "core:2026-0001")
+	if err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if !tx.Timestamp.Equal(at) || tx.CreatedAt.Before(at.Add(time.Hour)) {
+		t.Errorf("expected the client's timestamp and a current creation time, got %v and %v", tx.Timestamp, tx.CreatedAt)
+	}
+
+	if rr := evaluate(`,"txId":"core:2026-0001"`); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a known transaction ID, got %d", rr.Code)
+	}
+
+	// Transaction IDs are per tenant, so another tenant's upstream may reuse it
+	body := `{"txId":"core:2026-0001","type":"transfer","debtor":{"id":"cust-9","accountId":"acc-9"},"creditor":{"id":"cust-2","accountId":"acc-2"},"amount":{"value":7,"currency":"USD"}}`
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+	req.Header.Set("X-Tenant-ID", "tenant-002")
+	rr = httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for another tenant's transaction ID, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if tx, err := repo.GetTransaction(ctx, "tenant-002", "core:2026-0001"); err != nil || tx.DebtorID != "cust-9" {
+		t.Errorf("expected the other tenant's transaction stored, got %+v, %v", tx, err)
+	}
+	for _, fields := range []string{
+		`,"txId":"core/2026"`,
+		`,"txId":"` + strings.Repeat("x", domain.MaxTransactionIDLength+1) + `"`,
+		`,"timestamp":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"`,
+		`,"timestamp":"` + time.Now().Add(-60*24*time.Hour).Format(time.RFC3339) + `"`,
+		`,"timestamp":"yesterday"`,
+	} {
+		if rr := evaluate(fields); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d: %s", fields, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestEntityCounterparties(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	now := time.Now().UTC()
+	repo.RecordCounterpartyEdge(ctx, "tenant-001", ospreytest.NewTransaction().From("cust-1").To("merchant").Amount(80, "USD").At(now).Build())
+	repo.RecordCounterpartyEdge(ctx, "tenant-001", ospreytest.NewTransaction().From("merchant").To("supplier").Amount(50, "USD").At(now.Add(-time.Hour)).Build())
+
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request("/entities/merchant/counterparties")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Counterparties []domain.CounterpartyEdge `json:"counterparties"`
+		Count          int                       `json:"count"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Count != 2 || resp.Counterparties[0].DebtorID != "cust-1" || resp.Counterparties[1].CreditorID != "supplier" {
+		t.Errorf("expected both edges, latest first, got %s", rr.Body.String())
+	}
+
+	since := now.Add(-time.Minute).Format(time.RFC3339)
+	rr = request("/entities/merchant/counterparties?since=" + since)
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Count != 1 {
+		t.Errorf("expected only the edge seen since %s, got %s", since, rr.Body.String())
+	}
+	if rr := request("/entities/merchant/counterparties?since=yesterday"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid since, got %d", rr.Code)
+	}
+}
+
+func TestResponseSigning(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	_, key, _ := ed25519.GenerateKey(nil)
+	signer, err := signing.NewSigner(key)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithSigner(signer))
+
+	body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"creditor-001","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", "tenant-001")
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if _, err := signing.Verify(signer.Public(), rr.Header().Get(signing.HeaderSignature), rr.Body.Bytes()); err != nil {
+		t.Errorf("expected a JWS of the evaluate response, got %v", err)
+	}
+
+	var resp EvaluateResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	req = httptest.NewRequest(http.MethodGet, "/evaluations/"+resp.EvaluationID, nil)
+	req.Header.Set("X-Tenant-ID", "tenant-001")
+	rr = httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	if _, err := signing.Verify(signer.Public(), rr.Header().Get(signing.HeaderSignature), rr.Body.Bytes()); err != nil {
+		t.Errorf("expected a JWS of the stored evaluation, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr = httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	var jwks signing.JWKS
+	json.Unmarshal(rr.Body.Bytes(), &jwks)
+	if rr.Code != http.StatusOK || len(jwks.Keys) != 1 || jwks.Keys[0].Kid != signer.KeyID() {
+		t.Errorf("expected the signing key in the JWKS, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	unsigned := createTestServer()
+	rr = httptest.NewRecorder()
+	unsigned.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a signing key, got %d", rr.Code)
+	}
+}
+
+func TestScoringConfig(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	if err := engine.LoadRule(&domain.RuleConfig{ID: "half", Expression: "0.5", Enabled: true}); err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+	svc := scoring.NewService(repo, nil, domain.DefaultConfig().Scoring)
+	processor := tadp.NewProcessor()
+	processor.Scoring = svc
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), processor, "test-v1", domain.ModeDetection, WithScoring(svc))
+
+	request := func(method, path, tenantID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		req.Header.Set(PrincipalHeader, "risk-ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	get := func(tenantID string) ScoringConfigResponse {
+		t.Helper()
+		var resp ScoringConfigResponse
+		rr := request(http.MethodGet, "/config/scoring", tenantID, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp
+	}
+	evaluate := func(tenantID string) string {
+		t.Helper()
+		body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"creditor-001","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+		var resp EvaluateResponse
+		json.Unmarshal(request(http.MethodPost, "/evaluate", tenantID, body).Body.Bytes(), &resp)
+		return resp.Status
+	}
+
+	if resp := get("tenant-001"); resp.Source != ScoringSourceDefault || resp.AlertThreshold != 0.7 {
+		t.Errorf("expected the default config, got %+v", resp)
+	}
+	if status := evaluate("tenant-001"); status != domain.StatusNoAlert {
+		t.Errorf("expected NALT at the default threshold, got %s", status)
+	}
+
+	for _, body := range []string{`{"alertThreshold":0}`, `{"alertThreshold":1.5}`, `{"alertThreshold":0.5,"criticalFail":"ignore"}`, `{"alertThreshold":0.5,"mlWeight":2}`, `{"alertThreshold":0.5,"mlBlend":"min"}`} {
+		if rr := request(http.MethodPut, "/config/scoring", "tenant-001", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, rr.Code)
+		}
+	}
+	if rr := request(http.MethodPut, "/config/scoring", "tenant-001", `{"alertThreshold":0.4}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resp := get("tenant-001")
+	if resp.Source != ScoringSourceTenant || resp.AlertThreshold != 0.4 || !resp.WeightedScoring || resp.CriticalFail != domain.CriticalFailAlert || resp.UpdatedBy != "risk-ops" {
+		t.Errorf("expected the tenant's config, got %+v", resp)
+	}
+	if status := evaluate("tenant-001"); status != domain.StatusAlert {
+		t.Errorf("expected ALRT at the tenant's threshold, got %s", status)
+	}
+	if status := evaluate("tenant-002"); status != domain.StatusNoAlert {
+		t.Errorf("expected other tenants to keep the default threshold, got %s", status)
+	}
+
+	if rr := request(http.MethodDelete, "/config/scoring", "tenant-001", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodDelete, "/config/scoring", "tenant-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a config, got %d", rr.Code)
+	}
+	if status := evaluate("tenant-001"); status != domain.StatusNoAlert {
+		t.Errorf("expected the default threshold after delete, got %s", status)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	svc := retention.NewService(repo, domain.RetentionConfig{Defaults: domain.RetentionPolicy{EvaluationDays: 2555}})
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection, WithRetention(svc))
+	defer svc.Stop()
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "risk-ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	var policy RetentionPolicyResponse
+	rr := request(http.MethodGet, "/config/retention", "")
+	json.Unmarshal(rr.Body.Bytes(), &policy)
+	if rr.Code != http.StatusOK || policy.Source != RetentionSourceDefault || policy.EvaluationDays != 2555 {
+		t.Fatalf("expected the default policy, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, body := range []string{`{"transactionDays": -1}`, `{"transactionDays": 396, "archive": true}`, `{`} {
+		if rr := request(http.MethodPut, "/config/retention", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, rr.Code)
+		}
+	}
+
+	rr = request(http.MethodPut, "/config/retention", `{"transactionDays": 396, "evaluationDays": 2555}`)
+	json.Unmarshal(rr.Body.Bytes(), &policy)
+	if rr.Code != http.StatusOK || policy.Source != RetentionSourceTenant || policy.TransactionDays != 396 || policy.UpdatedBy != "risk-ops" {
+		t.Fatalf("expected the tenant's policy, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if stored, err := repo.GetRetentionPolicy(context.Background(), "tenant-001"); err != nil || stored.TransactionDays != 396 {
+		t.Errorf("expected the policy stored, got %+v (%v)", stored, err)
+	}
+
+	var run retention.Run
+	rr = request(http.MethodPost, "/admin/retention/runs", `{"tenantId": "tenant-001"}`)
+	json.Unmarshal(rr.Body.Bytes(), &run)
+	if rr.Code != http.StatusAccepted || run.Trigger != retention.TriggerManual || run.TenantID != "tenant-001" || run.StartedBy != "risk-ops" {
+		t.Fatalf("expected a manual run, got %d: %s", rr.Code, rr.Body.String())
+	}
+	svc.Stop()
+	if rr := request(http.MethodGet, "/admin/retention/runs/"+run.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("expected the run, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/admin/retention/runs/missing", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown run, got %d", rr.Code)
+	}
+	var list struct {
+		Runs  []retention.Run `json:"runs"`
+		Count int             `json:"count"`
+	}
+	json.Unmarshal(request(http.MethodGet, "/admin/retention/runs", "").Body.Bytes(), &list)
+	if list.Count != 1 || list.Runs[0].ID != run.ID {
+		t.Errorf("expected the run listed, got %+v", list)
+	}
+
+	if rr := request(http.MethodDelete, "/config/retention", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rr.Code)
+	}
+	if rr := request(http.MethodDelete, "/config/retention", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a policy, got %d", rr.Code)
+	}
+
+	t.Run("ComplianceMinimum", func(t *testing.T) {
+		server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeCompliance, WithRetention(svc))
+		put := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPut, "/config/retention", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Tenant-ID", "tenant-001")
+			rr := httptest.NewRecorder()
+			server.Router().ServeHTTP(rr, req)
+			return rr
+		}
+
+		if rr := put(`{"evaluationDays": 1}`); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "evaluationDays") {
+			t.Errorf("expected status 400 below the compliance minimum, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := put(`{"transactionDays": 1825, "evaluationDays": 0, "alertDays": 2555}`); rr.Code != http.StatusOK {
+			t.Errorf("expected status 200 at the minimum or forever, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+}
+
+func TestEvaluationCacheHeaders(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	if err := repo.SaveEvaluation(context.Background(), "tenant-001", &domain.Evaluation{ID: "eval-001", TxID: "tx-001", Status: domain.StatusNoAlert}); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/evaluations/eval-001", nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status 200 with an ETag, got %d: %v", rr.Code, rr.Header())
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "private, max-age=60" {
+		t.Errorf("expected private caching, got %q", cc)
+	}
+	if vary := rr.Header().Get("Vary"); vary != TenantIDHeader {
+		t.Errorf("expected Vary: %s, got %q", TenantIDHeader, vary)
+	}
+
+	rr = get(`"stale", ` + etag)
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 without a body for a matching ETag, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := get(`"stale"`); rr.Code != http.StatusOK {
+		t.Errorf("expected status 200 for another ETag, got %d", rr.Code)
+	}
+}
+
+func TestSLO(t *testing.T) {
+	tracker := slo.NewTracker(domain.DefaultConfig().SLO)
+	server := createTestServerWithMode(domain.ModeDetection, false, WithSLO(tracker))
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"creditor-001","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+	if rr := request(http.MethodPost, "/evaluate", body); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	request(http.MethodGet, "/evaluations/unknown", "")
+
+	rr := request(http.MethodGet, "/slo", "")
+	var resp struct {
+		Objectives []slo.Report `json:"objectives"`
+		Count      int          `json:"count"`
+		Window     string       `json:"window"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Count != 2 || resp.Window != "720h0m0s" {
+		t.Fatalf("expected the default objectives, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if evaluate := resp.Objectives[0]; evaluate.Route != "POST /evaluate" || evaluate.Requests != 1 || evaluate.Availability.Bad != 0 {
+		t.Errorf("expected one good evaluate request, got %+v", evaluate)
+	}
+	// The test server has no repository, so the read fails with 503
+	if get := resp.Objectives[1]; get.Route != "GET /evaluations/{id}" || get.Requests != 1 || get.Availability.Bad != 1 || get.Availability.BudgetRemaining >= 0 {
+		t.Errorf("expected one failed read overspending the budget, got %+v", get)
+	}
+
+	metrics := request(http.MethodGet, "/metrics", "").Body.String()
+	for _, want := range []string{
+		`osprey_slo_target{route="POST /evaluate",sli="availability"} 0.999`,
+		`osprey_slo_good_requests{route="POST /evaluate",sli="latency"} 1`,
+		`osprey_slo_bad_requests{route="GET /evaluations/{id}",sli="availability"} 1`,
+		`osprey_slo_burn_rate{route="POST /evaluate",sli="availability",window="1h"} 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("expected %q in metrics:\n%s", want, metrics)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	createTestServer().Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slo", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without objectives, got %d", rr.Code)
+	}
+}
+
+func TestNamedLists(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	rule := &domain.RuleConfig{ID: "internal-transfer", Version: "1.0.0", Expression: `creditor_id in list("internal_accounts")`, Enabled: true}
+	if err := repo.SaveRuleConfig(context.Background(), rules.GlobalTenantID, rule); err != nil {
+		t.Fatalf("SaveRuleConfig failed: %v", err)
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, tenantID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		req.Header.Set(PrincipalHeader, "risk-ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	score := func(tenantID string) float64 {
+		t.Helper()
+		body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"treasury","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+		var resp EvaluateResponse
+		json.Unmarshal(request(http.MethodPost, "/evaluate", tenantID, body).Body.Bytes(), &resp)
+		return resp.Score
+	}
+
+	if s := score("tenant-001"); s != 0 {
+		t.Errorf("expected a missing list to be empty, got score %.2f", s)
+	}
+
+	for path, body := range map[string]string{
+		"/refdata/lists/Internal": `{"values":["treasury"]}`,
+		"/refdata/lists/internal": `{"values":`,
+	} {
+		if rr := request(http.MethodPut, path, "tenant-001", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s %s, got %d", path, body, rr.Code)
+		}
+	}
+
+	rr := request(http.MethodPut, "/refdata/lists/internal_accounts", "tenant-001", `{"description":"Own accounts","values":[" treasury ","payroll","treasury",""]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if s := score("tenant-001"); s != 1 {
+		t.Errorf("expected the saved list to apply at once, got score %.2f", s)
+	}
+	if s := score("tenant-002"); s != 0 {
+		t.Errorf("expected another tenant not to see the list, got score %.2f", s)
+	}
+
+	var list domain.NamedList
+	rr = request(http.MethodGet, "/refdata/lists/internal_accounts", "tenant-001", "")
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if rr.Code != http.StatusOK || len(list.Values) != 2 || list.Values[0] != "treasury" || list.UpdatedBy != "risk-ops" {
+		t.Errorf("expected the trimmed, deduplicated list, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/refdata/lists/internal_accounts", "tenant-002", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for another tenant, got %d", rr.Code)
+	}
+
+	var listed struct {
+		Lists []NamedListSummary `json:"lists"`
+		Count int                `json:"count"`
+	}
+	json.Unmarshal(request(http.MethodGet, "/refdata/lists", "tenant-001", "").Body.Bytes(), &listed)
+	if listed.Count != 1 || listed.Lists[0].Size != 2 || listed.Lists[0].Description != "Own accounts" {
+		t.Errorf("expected one summary of 2 values, got %+v", listed)
+	}
+
+	// Lists saved behind the API's back apply on reload
+	repo.SaveNamedList(context.Background(), "tenant-002", &domain.NamedList{Name: "internal_accounts", Values: []string{"treasury"}})
+	if rr := request(http.MethodPost, "/rules/reload", "tenant-002", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if s := score("tenant-002"); s != 1 {
+		t.Errorf("expected the reload to load the list, got score %.2f", s)
+	}
+
+	if rr := request(http.MethodDelete, "/refdata/lists/internal_accounts", "tenant-001", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if s := score("tenant-001"); s != 0 {
+		t.Errorf("expected the deleted list to be empty, got score %.2f", s)
+	}
+	if rr := request(http.MethodDelete, "/refdata/lists/internal_accounts", "tenant-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestScoreDistribution(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	now := time.Now().UTC()
+	for i, score := range []float64{0.1, 0.2, 0.35, 0.9} {
+		repo.SaveEvaluation(context.Background(), "tenant-001", &domain.Evaluation{
+			ID:              fmt.Sprintf("eval-%d", i),
+			TxID:            fmt.Sprintf("tx-%d", i),
+			Status:          domain.StatusNoAlert,
+			Score:           score,
+			Timestamp:       now.Add(-time.Minute),
+			TypologyResults: []domain.TypologyResult{{TypologyID: "structuring", Score: score}},
+		})
+	}
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, query := range []string{"?window=1x", "?window=91d", "?buckets=7", "?since=yesterday", "?since=2026-01-02T00:00:00Z&until=2026-01-01T00:00:00Z"} {
+		if rr := request("/stats/score-distribution" + query); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", query, rr.Code)
+		}
+	}
+
+	rr := request("/stats/score-distribution?window=1h&buckets=10")
+	var report stats.Report
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if rr.Code != http.StatusOK || report.Buckets != 10 || report.Evaluations.Count != 4 || len(report.Evaluations.Histogram) != 10 {
+		t.Fatalf("expected four scores in 10 buckets, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if report.Evaluations.Histogram[3].Count != 1 || report.Evaluations.Percentiles["p50"] != 0.21 {
+		t.Errorf("expected 0.35 in the fourth bucket and p50 = 0.21, got %+v", report.Evaluations)
+	}
+	if len(report.Typologies) != 1 || report.Typologies[0].TypologyID != "structuring" || report.Typologies[0].Count != 4 {
+		t.Errorf("expected the structuring typology's four scores, got %+v", report.Typologies)
+	}
+}
+
+func TestRuleDocs(t *testing.T) {
+	server := createTestServerWithMode(domain.ModeDetection, true)
+	request := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/rules/docs"+query, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request("")
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("expected a Markdown document, got %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{"# Rule set of tenant-001", "## High Value Test Rule (test-rule-001)", "amount > 100000.0 ? 1.0 : 0.0", "## Test Typology (test-typology-001)"} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("expected the document to contain %q, got:\n%s", want, rr.Body.String())
+		}
+	}
+
+	rr = request("?format=html")
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") || !strings.Contains(rr.Body.String(), "<h2>High Value Test Rule (test-rule-001)</h2>") {
+		t.Errorf("expected an HTML document, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request("?format=pdf"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown format, got %d", rr.Code)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	now := time.Now().UTC()
+	for i, tenantID := range []string{"tenant-001", "tenant-002", "tenant-003", "tenant-004", "tenant-005", "tenant-006"} {
+		repo.SaveEvaluation(context.Background(), tenantID, &domain.Evaluation{
+			ID:        fmt.Sprintf("eval-%d", i),
+			TxID:      fmt.Sprintf("tx-%d", i),
+			Status:    domain.StatusNoAlert,
+			Timestamp: now.Add(-time.Hour),
+			Metadata:  domain.EvaluationMetadata{TotalMs: int64(i + 1)},
+		})
+	}
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+	request := func(method, path, tenantID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenantID)
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(http.MethodGet, "/stats/benchmark", "tenant-001", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 before opting in, got %d", rr.Code)
+	}
+	for i := 1; i <= 6; i++ {
+		tenantID := fmt.Sprintf("tenant-%03d", i)
+		if rr := request(http.MethodPut, "/features/benchmarking", tenantID, `{"enabled":true}`); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200 opting in %s, got %d", tenantID, rr.Code)
+		}
+	}
+	if rr := request(http.MethodGet, "/stats/benchmark?window=91d", "tenant-001", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a window over 90d, got %d", rr.Code)
+	}
+
+	rr := request(http.MethodGet, "/stats/benchmark?window=7d", "tenant-001", "")
+	var report stats.BenchmarkReport
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if rr.Code != http.StatusOK || report.Evaluations != 1 || report.Peers != 5 || len(report.Metrics) != 3 {
+		t.Fatalf("expected tenant-001 against its 5 peers, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), "tenant-00") {
+		t.Errorf("expected no tenant named in the report, got %s", rr.Body.String())
+	}
+	for _, metric := range report.Metrics {
+		if metric.Name == stats.MetricLatencyMs && (metric.Bands["p50"] != 4 || *metric.Rank != 0) {
+			t.Errorf("expected the fastest tenant below every peer, got %+v", metric)
+		}
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	source, target := ospreytest.NewRepository(nil), ospreytest.NewRepository(nil)
+	source.SaveTransaction(context.Background(), "tenant-001", ospreytest.NewTransaction().ID("tx-001").Tenant("tenant-001").Build())
+	coordinator := migration.NewCoordinator(migration.New(source, target))
+	defer coordinator.Stop()
+	server := createTestServerWithMode(domain.ModeDetection, false, WithMigrations(coordinator))
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := request(http.MethodPost, "/admin/migrations", `{"tenantId":" "}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a tenant, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/admin/migrations", `{"tenantId":"tenant-001","cutover":true}`); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var m migration.Migration
+	for deadline := time.Now().Add(5 * time.Second); m.Status != migration.StatusCutover && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		json.Unmarshal(request(http.MethodGet, "/admin/migrations/tenant-001", "").Body.Bytes(), &m)
+	}
+	if !m.Frozen || m.Report == nil || m.Report.Kinds[2].Copied != 1 {
+		t.Fatalf("expected a frozen cutover that copied the transaction, got %+v", m)
+	}
+
+	body := `{"type":"transfer","debtor":{"id":"debtor-001","accountId":"acc-001"},"creditor":{"id":"creditor-001","accountId":"acc-002"},"amount":{"value":250,"currency":"USD"}}`
+	rr := request(http.MethodPost, "/evaluate", body)
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("expected writes to be rejected while frozen, got %d", rr.Code)
+	}
+	if rr := request(http.MethodGet, "/rules", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected reads to work while frozen, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/admin/migrations", `{"tenantId":"tenant-001"}`); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while frozen, got %d", rr.Code)
+	}
+
+	if rr := request(http.MethodDelete, "/admin/migrations/tenant-001", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodPost, "/evaluate", body); rr.Code != http.StatusOK {
+		t.Errorf("expected writes after the release, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/admin/migrations/tenant-001", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after the release, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	createTestServer().Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/migrations", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("expected status 501 without a migration target, got %d", rr.Code)
+	}
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, eventBus, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		if rr.Code >= 300 {
+			t.Fatalf("%s %s: expected success, got %d: %s", method, path, rr.Code, rr.Body.String())
+		}
+	}
+	event := func(topic string) *domain.LifecycleEvent {
+		t.Helper()
+		published := eventBus.Published("tenant-001", topic)
+		if len(published) != 1 {
+			t.Fatalf("expected one %s event, got %d", topic, len(published))
+		}
+		var event domain.LifecycleEvent
+		if err := json.Unmarshal(published[0].Payload, &event); err != nil {
+			t.Fatalf("invalid event payload: %v", err)
+		}
+		if event.Type != topic || event.TenantID != "tenant-001" || event.At.IsZero() {
+			t.Errorf("unexpected %s event envelope: %+v", topic, event)
+		}
+		return &event
+	}
+
+	request(http.MethodPost, "/rules", `{"id": "high-value", "name": "High value", "expression": "amount > 10000.0", "enabled": true}`)
+	if e := event(domain.TopicRuleCreated); e.Rule == nil || e.Rule.ID != "high-value" || e.Actor != "ops" {
+		t.Errorf("expected the created rule and actor, got %+v", e)
+	}
+
+	request(http.MethodDelete, "/rules/high-value", "")
+	if e := event(domain.TopicRuleDeleted); e.RuleID != "high-value" {
+		t.Errorf("expected the deleted rule ID, got %+v", e)
+	}
+
+	request(http.MethodPost, "/typologies/reload", "")
+	event(domain.TopicTypologyReloaded)
+}
+
+func TestTopStats(t *testing.T) {
+	tracker := stats.NewTracker(time.Minute)
+	tracker.Evaluated("tenant-001", "debtor-001")
+	server := createTestServerWithMode(domain.ModeDetection, false, WithTopTracker(tracker))
+
+	request := func(server *Server, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := request(server, "/stats/top?n=5")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var report stats.TopReport
+	json.NewDecoder(rr.Body).Decode(&report)
+	if got := report.Entities.Evaluations; len(got) != 1 || got[0].TenantID != "tenant-001" || got[0].EntityID != "debtor-001" {
+		t.Errorf("unexpected top entities: %+v", got)
+	}
+
+	if rr := request(server, "/stats/top?n=0"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for n=0, got %d", rr.Code)
+	}
+	if rr := request(createTestServer(), "/stats/top"); rr.Code != http.StatusNotImplemented {
+		t.Errorf("expected status 501 without a tracker, got %d", rr.Code)
+	}
+
+	// With a minimum cohort, entities and small tenants are left out
+	private := createTestServerWithMode(domain.ModeDetection, false, WithTopTracker(tracker), WithAggregatePrivacy(stats.NewPrivacy(0, 2)))
+	report = stats.TopReport{}
+	json.NewDecoder(request(private, "/stats/top").Body).Decode(&report)
+	if len(report.Tenants.Evaluations) != 0 || len(report.Entities.Evaluations) != 0 {
+		t.Errorf("expected nothing below the cohort, got %+v", report)
+	}
+}
+
+func TestEvaluationStream(t *testing.T) {
+	dial := func(server *Server) ospreypb.EvaluationClient {
+		t.Helper()
+		lis := bufconn.Listen(1 << 20)
+		grpcServer := grpc.NewServer()
+		ospreypb.RegisterEvaluationServer(grpcServer, NewStreamServer(server.Handler()))
+		go grpcServer.Serve(lis)
+		t.Cleanup(grpcServer.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return ospreypb.NewEvaluationClient(conn)
+	}
+	client := dial(createTestServer())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), TenantIDMetadata, "tenant-001")
+	stream, err := client.Stream(ctx)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+
+	// One account, so the decisions must come back in order
+	amounts := []float64{100, 0, 200000, 50}
+	for i, amount := range amounts {
+		err := stream.Send(&ospreypb.EvaluateRequest{
+			CorrelationId: fmt.Sprintf("corr-%d", i),
+			Type:          "transfer",
+			Debtor:        &ospreypb.Party{Id: "debtor-001", AccountId: "acct-001"},
+			Creditor:      &ospreypb.Party{Id: "creditor-001", AccountId: "acct-002"},
+			Amount:        &ospreypb.Amount{Value: amount, Currency: "USD"},
+		})
+		if err != nil {
+			t.Fatalf("failed to send: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	for i := range amounts {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("failed to receive decision %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("corr-%d", i); resp.CorrelationId != want {
+			t.Fatalf("expected decision for %s, got %s", want, resp.CorrelationId)
+		}
+		switch i {
+		case 1:
+			if codes.Code(resp.ErrorCode) != codes.InvalidArgument || resp.Error != "amount.value must be positive" {
+				t.Errorf("expected an invalid argument for a zero amount, got %d %q", resp.ErrorCode, resp.Error)
+			}
+		case 2:
+			if resp.Status != domain.StatusAlert {
+				t.Errorf("expected ALRT for a high amount, got %s", resp.Status)
+			}
+		default:
+			if resp.ErrorCode != 0 || resp.Status != domain.StatusNoAlert || resp.EvaluationId == "" {
+				t.Errorf("unexpected decision %d: %+v", i, resp)
+			}
+		}
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected the stream to end, got %v", err)
+	}
+
+	// The tenant is required
+	stream, err = client.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without a tenant, got %v", err)
+	}
+
+	t.Run("ClientTransactionID", func(t *testing.T) {
+		repo := ospreytest.NewRepository(nil)
+		engine, _ := rules.NewEngine(nil, 5)
+		client := dial(NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection))
+
+		stream, err := client.Stream(ctx)
+		if err != nil {
+			t.Fatalf("failed to open stream: %v", err)
+		}
+		at := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+		for i := range 2 {
+			err := stream.Send(&ospreypb.EvaluateRequest{
+				CorrelationId: fmt.Sprintf("corr-%d", i),
+				TxId:          "core:2026-0001",
+				Timestamp:     timestamppb.New(at),
+				Type:          "transfer",
+				Debtor:        &ospreypb.Party{Id: "debtor-001", AccountId: "acct-001"},
+				Creditor:      &ospreypb.Party{Id: "creditor-001", AccountId: "acct-002"},
+				Amount:        &ospreypb.Amount{Value: 100, Currency: "USD"},
+			})
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+		}
+		stream.CloseSend()
+
+		if resp, err := stream.Recv(); err != nil || resp.ErrorCode != 0 || resp.TxId != "core:2026-0001" {
+			t.Fatalf("expected the client's transaction ID, got %+v, %v", resp, err)
+		}
+		if resp, err := stream.Recv(); err != nil || codes.Code(resp.ErrorCode) != codes.AlreadyExists {
+			t.Errorf("expected AlreadyExists for a known transaction ID, got %+v, %v", resp, err)
+		}
+		tx, err := repo.GetTransaction(context.Background(), "tenant-001", "core:2026-0001")
+		if err != nil || !tx.Timestamp.Equal(at) {
+			t.Errorf("expected the client's timestamp stored, got %+v, %v", tx, err)
+		}
+	})
+
+	t.Run("DecimalAmount", func(t *testing.T) {
+		repo := ospreytest.NewRepository(nil)
+		engine, _ := rules.NewEngine(nil, 5)
+		client := dial(NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection))
+
+		stream, err := client.Stream(ctx)
+		if err != nil {
+			t.Fatalf("failed to open stream: %v", err)
+		}
+		for i, decimal := range []string{"12345678.0123456789", "12,50"} {
+			err := stream.Send(&ospreypb.EvaluateRequest{
+				CorrelationId: fmt.Sprintf("corr-%d", i),
+				TxId:          fmt.Sprintf("exact-%d", i),
+				Type:          "transfer",
+				Debtor:        &ospreypb.Party{Id: "debtor-001", AccountId: "acct-001"},
+				Creditor:      &ospreypb.Party{Id: "creditor-001", AccountId: "acct-002"},
+				Amount:        &ospreypb.Amount{Value: 1, Decimal: decimal, Currency: "USD"},
+			})
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+		}
+		stream.CloseSend()
+
+		if resp, err := stream.Recv(); err != nil || resp.ErrorCode != 0 {
+			t.Fatalf("expected a decision for the decimal amount, got %+v, %v", resp, err)
+		}
+		if resp, err := stream.Recv(); err != nil || codes.Code(resp.ErrorCode) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for a malformed decimal, got %+v, %v", resp, err)
+		}
+		tx, err := repo.GetTransaction(context.Background(), "tenant-001", "exact-0")
+		if err != nil || tx.Amount.String() != "12345678.0123456789" {
+			t.Errorf("expected the exact amount stored, got %+v, %v", tx, err)
+		}
+	})
+	t.Run("AmountComponents", func(t *testing.T) {
+		repo := ospreytest.NewRepository(nil)
+		engine, _ := rules.NewEngine(nil, 5)
+		client := dial(NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection))
+
+		stream, err := client.Stream(ctx)
+		if err != nil {
+			t.Fatalf("failed to open stream: %v", err)
+		}
+		for i, components := range []*ospreypb.AmountComponents{
+			{Principal: "100.00", Fee: "2.50", FxAmount: "91.80", FxCurrency: "EUR", FxRate: 0.918},
+			{Principal: "100.00", Fee: "1.00"},
+		} {
+			err := stream.Send(&ospreypb.EvaluateRequest{
+				CorrelationId: fmt.Sprintf("corr-%d", i),
+				TxId:          fmt.Sprintf("fx-%d", i),
+				Type:          "transfer",
+				Debtor:        &ospreypb.Party{Id: "debtor-001", AccountId: "acct-001"},
+				Creditor:      &ospreypb.Party{Id: "creditor-001", AccountId: "acct-002"},
+				Amount:        &ospreypb.Amount{Decimal: "102.50", Currency: "USD", Components: components},
+			})
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+		}
+		stream.CloseSend()
+
+		if resp, err := stream.Recv(); err != nil || resp.ErrorCode != 0 {
+			t.Fatalf("expected a decision with components, got %+v, %v", resp, err)
+		}
+		if resp, err := stream.Recv(); err != nil || codes.Code(resp.ErrorCode) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for components not adding up, got %+v, %v", resp, err)
+		}
+		tx, err := repo.GetTransaction(context.Background(), "tenant-001", "fx-0")
+		if err != nil || tx.Components == nil || tx.Components.Fee.String() != "2.5" || tx.Components.FXCurrency != "EUR" {
+			t.Errorf("expected the components stored, got %+v, %v", tx, err)
+		}
+	})
+}
+
+func TestTestRule(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	test := func(path, body string) TestRuleResponse {
+		t.Helper()
+		rr := request(http.MethodPost, path, body)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp TestRuleResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// Stored but not reloaded
+	body := `{"id":"high-value","name":"High Value","expression":"amount > 1000.0","weight":1,"enabled":true,
+		"bands":[{"subRuleRef":".pass","upperLimit":1,"reason":"ok"},{"subRuleRef":".fail","lowerLimit":1,"reason":"high value","action":"hold"}]}`
+	if rr := request(http.MethodPost, "/rules", body); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resp := test("/rules/high-value/test", `{"transaction":{"type":"transfer","amount":{"value":5000,"currency":"USD"}}}`)
+	if resp.RuleID != "high-value" || resp.Score != 1 || resp.Outcome != domain.RuleOutcomeFail || resp.Reason != "high value" || resp.Action != "hold" {
+		t.Errorf("unexpected result for the stored rule: %+v", resp)
+	}
+	if engine.RulesCount() != 0 {
+		t.Errorf("expected nothing loaded by a test, got %d rules", engine.RulesCount())
+	}
+
+	t.Run("Ad-hoc expression", func(t *testing.T) {
+		resp := test("/rules/draft/test", `{"expression":"metadata.channel == \"web\" ? 0.7 : 0.0",
+			"bands":[{"subRuleRef":".review","lowerLimit":0.5,"reason":"web"}],
+			"transaction":{"type":"transfer","amount":{"value":10},"metadata":{"channel":"web"}}}`)
+		if resp.RuleID != "draft" || resp.Score != 0.7 || resp.Outcome != domain.RuleOutcomeReview || resp.Reason != "web" {
+			t.Errorf("unexpected result for the expression: %+v", resp)
+		}
+	})
+
+	t.Run("Evaluation error", func(t *testing.T) {
+		resp := test("/rules/draft/test", `{"expression":"metadata.missing > 1.0","transaction":{"type":"transfer","amount":{"value":10}}}`)
+		if resp.Outcome != domain.RuleOutcomeError || resp.Error == "" || resp.Reason != "" {
+			t.Errorf("expected an evaluation error, got %+v", resp)
+		}
+	})
+
+	t.Run("Invalid expression", func(t *testing.T) {
+		if rr := request(http.MethodPost, "/rules/draft/test", `{"expression":"amount >"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Unknown rule", func(t *testing.T) {
+		if rr := request(http.MethodPost, "/rules/missing/test", `{"transaction":{}}`); rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rr.Code)
+		}
+	})
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := createTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("expected openapi 3.1.0, got %q", doc.OpenAPI)
+	}
+
+	// Every documented operation is routed (every route being documented is
+	// checked when the document is built)
+	count := 0
+	for _, ops := range doc.Paths {
+		count += len(ops)
+	}
+	if count != len(operations) {
+		t.Errorf("expected %d operations, got %d", len(operations), count)
+	}
+
+	for _, name := range []string{"TransactionRequest", "EvaluateResponse", "RuleConfig", "Typology", "Error"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("expected schema %s", name)
+		}
+	}
+
+	evaluate := doc.Paths["/evaluate"]["post"]
+	if evaluate == nil {
+		t.Fatal("expected POST /evaluate")
+	}
+	if params, _ := evaluate["parameters"].([]any); len(params) != 1 {
+		t.Errorf("expected the tenant header parameter, got %v", evaluate["parameters"])
+	}
+	body, _ := json.Marshal(evaluate["requestBody"])
+	if !strings.Contains(string(body), "#/components/schemas/TransactionRequest") {
+		t.Errorf("expected TransactionRequest body, got %s", body)
+	}
+
+	if _, ok := doc.Paths["/refdata/corridors/{origin}/{destination}"]["put"]; !ok {
+		t.Error("expected PUT /refdata/corridors/{origin}/{destination}")
+	}
+	if _, ok := doc.Paths["/health"]["get"]["parameters"]; ok {
+		t.Error("expected no tenant header on GET /health")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRule(&domain.RuleConfig{ID: "test-rule-001", Expression: "amount > 100000.0 ? 1.0 : 0.0", Enabled: true})
+	server := NewServer(domain.ServerConfig{ReadyFile: readyFile}, nil, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	ready := func() int {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return rr.Code
+	}
+
+	server.handler.markReady()
+	if _, err := os.Stat(readyFile); err != nil {
+		t.Fatalf("expected readiness file: %v", err)
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	rr := httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/drain", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 while draining, got %d", code)
+	}
+	if _, err := os.Stat(readyFile); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected readiness file to be removed, got %v", err)
+	}
+
+	// Still serving
+	rr = httptest.NewRecorder()
+	server.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status 200 from /health, got %d", rr.Code)
+	}
+
+	// Not marked ready again once draining
+	server.handler.markReady()
+	if _, err := os.Stat(readyFile); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected no readiness file after drain, got %v", err)
+	}
+}
+
+func TestGuardrails(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	policy := guardrails.NewPolicy(domain.GuardrailConfig{Caps: map[string]domain.MutationCaps{
+		"*": {MaxDisabledFraction: 0.25, MaxThresholdChange: 0.1},
+	}})
+	server := NewServer(domain.ServerConfig{}, repo, nil, eventBus, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection,
+		WithGuardrails(policy))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	expect := func(rr *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rr.Code != status {
+			t.Fatalf("expected status %d, got %d: %s", status, rr.Code, rr.Body.String())
+		}
+	}
+
+	for _, id := range []string{"rule-a", "rule-b", "rule-c", "rule-d"} {
+		expect(request(http.MethodPost, "/rules", `{"id": "`+id+`", "name": "`+id+`", "expression": "amount > 10000.0", "enabled": true}`), http.StatusCreated)
+	}
+	expect(request(http.MethodPost, "/rules/reload", ""), http.StatusOK)
+
+	// One of four is within the cap; a second of the remaining three is not
+	expect(request(http.MethodDelete, "/rules/rule-a", ""), http.StatusOK)
+	rr := request(http.MethodPut, "/rules/rule-b", `{"name": "rule-b", "expression": "amount > 10000.0", "enabled": false}`)
+	expect(rr, http.StatusConflict)
+	if !strings.Contains(rr.Body.String(), "disables 1 of 3 enabled rules") {
+		t.Errorf("expected the violation in the error, got %s", rr.Body.String())
+	}
+	expect(request(http.MethodDelete, "/rules/rule-b", ""), http.StatusConflict)
+	if len(eventBus.Published("tenant-001", domain.TopicGuardrailOverridden)) != 0 {
+		t.Error("expected no override event for a refused change")
+	}
+
+	// Updates that keep the rule enabled are not capped
+	expect(request(http.MethodPut, "/rules/rule-b", `{"name": "rule-b", "expression": "amount > 20000.0", "enabled": true}`), http.StatusOK)
+
+	expect(request(http.MethodDelete, "/rules/rule-b?force=true", ""), http.StatusOK)
+	published := eventBus.Published("tenant-001", domain.TopicGuardrailOverridden)
+	if len(published) != 1 {
+		t.Fatalf("expected one override event, got %d", len(published))
+	}
+	var event domain.LifecycleEvent
+	json.Unmarshal(published[0].Payload, &event)
+	if event.Actor != "ops" || event.Operation != "DELETE /rules/rule-b" || len(event.Violations) != 1 {
+		t.Errorf("unexpected override event: %+v", event)
+	}
+
+	// Alert thresholds
+	expect(request(http.MethodPost, "/typologies", `{"id": "typ-1", "name": "Typology", "rules": [{"ruleId": "rule-c", "weight": 1}], "alertThreshold": 0.5, "enabled": true}`), http.StatusCreated)
+	typology := func(threshold string) string {
+		return `{"name": "Typology", "rules": [{"ruleId": "rule-c", "weight": 1}], "alertThreshold": ` + threshold + `, "enabled": true}`
+	}
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("0.9")), http.StatusConflict)
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("0.4")), http.StatusOK)
+}
+
+func TestStrictTypologies(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	expect := func(rr *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rr.Code != status {
+			t.Fatalf("expected status %d, got %d: %s", status, rr.Code, rr.Body.String())
+		}
+	}
+	typology := func(id, rules string, normalize bool) string {
+		return fmt.Sprintf(`{"id": %q, "name": "Typology", "rules": %s, "alertThreshold": 0.5, "enabled": true, "normalizeWeights": %t}`, id, rules, normalize)
+	}
+
+	for _, id := range []string{"rule-a", "rule-b"} {
+		expect(request(http.MethodPost, "/rules", `{"id": "`+id+`", "name": "`+id+`", "expression": "amount > 10000.0", "enabled": true}`), http.StatusCreated)
+	}
+	expect(request(http.MethodPost, "/rules/reload", ""), http.StatusOK)
+
+	// Without strict mode, weights that don't sum to 1.0 are only logged
+	expect(request(http.MethodPost, "/typologies", typology("typ-1", `[{"ruleId": "rule-a", "weight": 0.5}]`, false)), http.StatusCreated)
+
+	rr := request(http.MethodPost, "/typologies", typology("typ-2", `[{"ruleId": "rule-a", "weight": 3}, {"ruleId": "rule-b", "weight": 1}]`, true))
+	expect(rr, http.StatusCreated)
+	var created struct {
+		Typology domain.Typology `json:"typology"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if w := created.Typology.Rules; len(w) != 2 || w[0].Weight != 0.75 || w[1].Weight != 0.25 {
+		t.Errorf("expected weights normalized to 0.75 and 0.25, got %+v", w)
+	}
+	expect(request(http.MethodPost, "/typologies", typology("typ-3", `[{"ruleId": "rule-a", "weight": 0}]`, true)), http.StatusBadRequest)
+
+	// Updates skip the rule check outside strict mode
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("", `[{"ruleId": "rule-x", "weight": 1}]`, false)), http.StatusOK)
+
+	expect(request(http.MethodPut, "/features/strict_typologies", `{"enabled": true}`), http.StatusOK)
+
+	rr = request(http.MethodPost, "/typologies", typology("typ-4", `[{"ruleId": "rule-a", "weight": 0.5}]`, false))
+	expect(rr, http.StatusBadRequest)
+	if !strings.Contains(rr.Body.String(), "sum to 0.5") {
+		t.Errorf("expected the weight total in the error, got %s", rr.Body.String())
+	}
+	expect(request(http.MethodPost, "/typologies", typology("typ-4", `[{"ruleId": "rule-a", "weight": 0.5}]`, true)), http.StatusCreated)
+
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("", `[{"ruleId": "rule-x", "weight": 1}]`, false)), http.StatusBadRequest)
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("", `[{"ruleId": "rule-a", "weight": 0.4}, {"ruleId": "rule-b", "weight": 0.4}]`, false)), http.StatusBadRequest)
+	expect(request(http.MethodPut, "/typologies/typ-1", `{"name": "Typology", "rules": [{"ruleId": "rule-a", "weight": 1}], "alertThreshold": 0, "enabled": true}`), http.StatusBadRequest)
+	expect(request(http.MethodPut, "/typologies/typ-1", typology("", `[{"ruleId": "rule-a", "weight": 0.4}, {"ruleId": "rule-b", "weight": 0.4}]`, true)), http.StatusOK)
+}
+
+func TestCompositeTypologies(t *testing.T) {
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, ospreytest.NewRepository(nil), nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	expect := func(rr *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rr.Code != status {
+			t.Fatalf("expected status %d, got %d: %s", status, rr.Code, rr.Body.String())
+		}
+	}
+
+	for _, id := range []string{"rapid-movement", "account-drain"} {
+		expect(request(http.MethodPost, "/rules", `{"id": "`+id+`", "name": "`+id+`", "expression": "amount > 10000.0", "enabled": true}`), http.StatusCreated)
+	}
+	expect(request(http.MethodPost, "/rules/reload", ""), http.StatusOK)
+	expect(request(http.MethodPost, "/typologies", `{"id": "typ-rapid", "name": "Rapid", "rules": [{"ruleId": "rapid-movement", "weight": 1}], "alertThreshold": 0.5, "enabled": true}`), http.StatusCreated)
+	expect(request(http.MethodPost, "/typologies/reload", ""), http.StatusOK)
+
+	composite := `{"id": "typ-composite", "name": "Composite", "typologies": [{"typologyId": "typ-rapid", "weight": 1}], "condition": {"all": [{"typology": "typ-rapid"}, {"rule": "account-drain"}]}, "alertThreshold": 0.5, "enabled": true}`
+	expect(request(http.MethodPost, "/typologies", composite), http.StatusCreated)
+	expect(request(http.MethodPost, "/typologies/reload", ""), http.StatusOK)
+
+	rr := request(http.MethodGet, "/typologies/typ-composite", "")
+	expect(rr, http.StatusOK)
+	var typology domain.Typology
+	json.Unmarshal(rr.Body.Bytes(), &typology)
+	if len(typology.Typologies) != 1 || typology.Condition == nil || len(typology.Condition.All) != 2 {
+		t.Errorf("expected the composite to be saved with its typologies and condition, got %+v", typology)
+	}
+
+	// The rapid movement typology can't refer back to the composite
+	rr = request(http.MethodPut, "/typologies/typ-rapid", `{"name": "Rapid", "rules": [{"ruleId": "rapid-movement", "weight": 0.5}], "typologies": [{"typologyId": "typ-composite", "weight": 0.5}], "alertThreshold": 0.5, "enabled": true}`)
+	expect(rr, http.StatusBadRequest)
+	if !strings.Contains(rr.Body.String(), "cycle") {
+		t.Errorf("expected a cycle error, got %s", rr.Body.String())
+	}
+
+	expect(request(http.MethodPost, "/typologies", `{"id": "typ-missing", "name": "Missing", "typologies": [{"typologyId": "typ-none", "weight": 1}], "alertThreshold": 0.5, "enabled": true}`), http.StatusBadRequest)
+	expect(request(http.MethodPost, "/typologies", `{"id": "typ-bad", "name": "Bad", "typologies": [{"typologyId": "typ-rapid", "weight": 1}], "condition": {"rule": "account-drain", "typology": "typ-rapid"}, "alertThreshold": 0.5, "enabled": true}`), http.StatusBadRequest)
+	expect(request(http.MethodPost, "/typologies", `{"id": "typ-bad", "name": "Bad", "typologies": [{"typologyId": "typ-rapid", "weight": 1}], "condition": {"rule": "no-such-rule"}, "alertThreshold": 0.5, "enabled": true}`), http.StatusBadRequest)
+}
+
+func TestReviewQueue(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	held := []domain.RuleResult{{RuleID: "large-transfer", Action: domain.BandActionHold}}
+	repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-hold", TxID: "tx-1", Status: domain.StatusNoAlert, Score: 0.5, RuleResults: held, Timestamp: now})
+	repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-alert", TxID: "tx-2", Status: domain.StatusAlert, Score: 0.9, Timestamp: now})
+	repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "eval-alert", TxID: "tx-2", Score: 0.9, Status: domain.AlertOpen, CreatedAt: now})
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, principal, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		if principal != "" {
+			req.Header.Set(PrincipalHeader, principal)
+		}
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+	list := func(query string) []review.Item {
+		t.Helper()
+		rr := request(http.MethodGet, "/review-queue"+query, "", "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Items []review.Item `json:"items"`
+			Count int           `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Items
+	}
+
+	if items := list(""); len(items) != 2 || items[0].ID != "eval-alert" || items[0].Kind != review.KindAlert || items[1].Kind != review.KindHold {
+		t.Fatalf("expected the alert then the hold, got %+v", items)
+	}
+	for _, query := range []string{"?kind=held", "?limit=0"} {
+		if rr := request(http.MethodGet, "/review-queue"+query, "", ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", query, rr.Code)
+		}
+	}
+
+	if rr := request(http.MethodPost, "/review-queue/eval-hold/claim", "", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a claimant, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/review-queue/missing/claim", "alice", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown item, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/review-queue/eval-hold/claim", "alice", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr := request(http.MethodPost, "/review-queue/eval-hold/claim", "", `{"by":"bob"}`)
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "alice") {
+		t.Errorf("expected status 409 naming alice, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if items := list("?kind=hold"); len(items) != 1 || items[0].ClaimedBy != "alice" {
+		t.Errorf("expected the hold claimed by alice, got %+v", items)
+	}
+	if items := list("?unclaimed=true"); len(items) != 1 || items[0].ID != "eval-alert" {
+		t.Errorf("expected only the unclaimed alert, got %+v", items)
+	}
+
+	if rr := request(http.MethodPost, "/review-queue/eval-hold/release", "bob", ""); rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 releasing another analyst's claim, got %d", rr.Code)
+	}
+	if rr := request(http.MethodPost, "/review-queue/eval-hold/release", "alice", ""); rr.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if items := list("?unclaimed=true"); len(items) != 2 {
+		t.Errorf("expected the released hold back in the queue, got %+v", items)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 5)
+	server := NewServer(domain.ServerConfig{}, repo, nil, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), "test-v1", domain.ModeDetection)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-001")
+		req.Header.Set(PrincipalHeader, "risk-ops")
+		rr := httptest.NewRecorder()
+		server.Router().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rule := `{"id": "high-value", "name": "High value", "expression": "amount > 1000.0", "weight": 1, "enabled": true}`
+	if rr := request(http.MethodPost, "/rules", rule); rr.Code != http.StatusCreated {
+		t.Fatalf("expected rule created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rule = `{"name": "High value", "expression": "amount > 5000.0", "weight": 1, "enabled": true}`
+	if rr := request(http.MethodPut, "/rules/high-value", rule); rr.Code != http.StatusOK {
+		t.Fatalf("expected rule updated, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodPost, "/webhooks", `{"url": "https://example.com/hook", "secret": "s3cret"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("expected webhook created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodDelete, "/rules/high-value", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected rule deleted, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var list struct {
+		Entries []*domain.AuditEntry `json:"entries"`
+	}
+	rr := request(http.MethodGet, "/audit?resource=rule", "")
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if rr.Code != http.StatusOK || len(list.Entries) != 3 {
+		t.Fatalf("expected 3 rule changes, got %d: %s", rr.Code, rr.Body.String())
+	}
+	update := list.Entries[1]
+	if update.Action != domain.AuditUpdate || update.ResourceID != "high-value" || update.Actor != "risk-ops" {
+		t.Errorf("expected risk-ops's update of high-value, got %+v", update)
+	}
+	if !strings.Contains(string(update.Before), "amount \\u003e 1000.0") || !strings.Contains(string(update.After), "amount \\u003e 5000.0") {
+		t.Errorf("expected the rule before and after the update, got %s and %s", update.Before, update.After)
+	}
+	if deleted := list.Entries[2]; deleted.Action != domain.AuditDelete || deleted.Before == nil || deleted.After != nil {
+		t.Errorf("expected the deleted rule as the previous state only, got %+v", deleted)
+	}
+
+	rr = request(http.MethodGet, "/audit?resource=webhook", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "example.com") || strings.Contains(rr.Body.String(), "s3cret") {
+		t.Errorf("expected the webhook change without its secret, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := request(http.MethodGet, "/audit/2", ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"action":"update"`) {
+		t.Errorf("expected entry 2, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/audit/99", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a missing entry, got %d", rr.Code)
+	}
+	if rr := request(http.MethodGet, "/audit?limit=0", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for limit 0, got %d", rr.Code)
+	}
+
+	var result auditlog.VerifyResult
+	rr = request(http.MethodGet, "/audit/verify", "")
+	json.Unmarshal(rr.Body.Bytes(), &result)
+	if rr.Code != http.StatusOK || !result.Valid || result.Records != 4 {
+		t.Errorf("expected a valid chain of 4 entries, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/worker"
+)
+
+// StatusPending is the status of a transaction queued for asynchronous
+// evaluation.
+const StatusPending = "PENDING"
+
+// preferAsync is the Prefer header token (RFC 7240) asking for an
+// asynchronous response.
+const preferAsync = "respond-async"
+
+// QueuedEvaluationResponse is the 202 response of POST /evaluate in
+// asynchronous mode.
+type QueuedEvaluationResponse struct {
+	TxID    string `json:"txId"`
+	Status  string `json:"status"` // always PENDING
+	TraceID string `json:"traceId"`
+}
+
+// asyncRequested reports whether a POST /evaluate asks for asynchronous
+// evaluation, with ?async=true or Prefer: respond-async.
+func asyncRequested(r *http.Request) bool {
+	if r.URL.Query().Get("async") == "true" {
+		return true
+	}
+	for _, prefer := range r.Header.Values("Prefer") {
+		for _, token := range strings.Split(prefer, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), preferAsync) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// queueEvaluation publishes a validated, stored transaction to the tenant's
+// ingest topic for the async worker and responds 202 with its txId. The
+// evaluation is later found with GET /evaluations?txId=, or pushed to the
+// tenant's webhooks.
+func (h *Handler) queueEvaluation(w http.ResponseWriter, r *http.Request, tx *domain.Transaction, req *TransactionRequest) {
+	ctx := r.Context()
+	traceID := GetTraceID(ctx)
+
+	if h.bus == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "event bus not available",
+		})
+		return
+	}
+
+	payload, err := json.Marshal(worker.TransactionMessage{
+		TxID:              tx.ID,
+		TenantID:          tx.TenantID,
+		TraceID:           traceID,
+		Type:              tx.Type,
+		DebtorID:          tx.DebtorID,
+		CreditorID:        tx.CreditorID,
+		DebtorAccountID:   tx.DebtorAccountID,
+		CreditorAccountID: tx.CreditorAcctID,
+		DebtorName:        req.Debtor.Name,
+		CreditorName:      req.Creditor.Name,
+		DebtorCountry:     req.Debtor.Country,
+		CreditorCountry:   req.Creditor.Country,
+		Amount:            tx.Amount,
+		Currency:          tx.Currency,
+		Timestamp:         tx.Timestamp,
+		Components:        tx.Components,
+		VelocityWindow:    req.VelocityWindow,
+		ReversalOf:        tx.ReversalOf,
+		PartOfBatch:       tx.PartOfBatch,
+		RelatedTo:         tx.RelatedTo,
+		AdditionalData:    tx.Metadata,
+		Request:           GetRequestContext(ctx),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to encode transaction",
+		})
+		return
+	}
+
+	if err := h.bus.Publish(ctx, tx.TenantID, domain.TopicTransactionIngested, payload); err != nil {
+		slog.Error("failed to queue transaction", "tx_id", tx.ID, "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "failed to queue transaction",
+		})
+		return
+	}
+
+	if r.URL.Query().Get("async") != "true" {
+		w.Header().Set("Preference-Applied", preferAsync)
+	}
+	w.Header().Set("Location", "/evaluations?txId="+tx.ID)
+	writeJSON(w, http.StatusAccepted, QueuedEvaluationResponse{
+		TxID:    tx.ID,
+		Status:  StatusPending,
+		TraceID: traceID,
+	})
+}
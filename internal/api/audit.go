@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+const (
+	defaultListAuditLimit = 100
+	maxListAuditLimit     = 1000
+)
+
+// VerifyEvaluationLog walks the tenant's hash-chained evaluation log and
+// reports whether every record is intact. The log is written in compliance mode.
+func (h *Handler) VerifyEvaluationLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	result, err := h.auditLog.Verify(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to verify evaluation log", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to verify evaluation log",
+		})
+		return
+	}
+
+	if !result.Valid {
+		slog.Warn("evaluation log verification failed",
+			"tenant_id", tenantID,
+			"broken_at", result.BrokenAt,
+			"reason", result.Reason,
+		)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// recordAudit appends a configuration change made by the request to the
+// tenant's audit log. before and after are the resource on either side of the
+// change, nil where it didn't exist. The change has already been made, so a
+// failure to record it is logged rather than returned.
+func (h *Handler) recordAudit(ctx context.Context, action, resource, resourceID string, before, after any) {
+	if h.repo == nil {
+		return
+	}
+
+	rc := GetRequestContext(ctx)
+	tenantID := GetTenantID(ctx)
+	change := &domain.AuditEntry{
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Actor:      rc.Principal,
+		ClientIP:   rc.ClientIP,
+		RequestID:  rc.RequestID,
+	}
+	// The change is made even if the client goes away, so record it regardless
+	if _, err := h.auditTrail.Record(context.WithoutCancel(ctx), tenantID, change, before, after); err != nil {
+		slog.Error("failed to record audit entry",
+			"tenant_id", tenantID,
+			"action", action,
+			"resource", resource,
+			"resource_id", resourceID,
+			"error", err,
+		)
+	}
+}
+
+// auditSaveAction returns the action of an upsert: an update if the resource
+// existed, a create otherwise.
+func auditSaveAction(existed bool) string {
+	if existed {
+		return domain.AuditUpdate
+	}
+	return domain.AuditCreate
+}
+
+// ListAuditEntries returns the tenant's configuration changes in the order
+// they were made. Query params: resource, resourceId, action, after (a
+// sequence number, to page) and limit (default 100).
+func (h *Handler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	filter := domain.AuditFilter{
+		Resource:   query.Get("resource"),
+		ResourceID: query.Get("resourceId"),
+		Action:     query.Get("action"),
+		Limit:      defaultListAuditLimit,
+	}
+	if v := query.Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "after must be a sequence number",
+			})
+			return
+		}
+		filter.AfterSeq = n
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListAuditLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	entries, err := h.repo.ListAuditEntries(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list audit entries", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list audit entries",
+		})
+		return
+	}
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// GetAuditEntry returns one configuration change by sequence number.
+func (h *Handler) GetAuditEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	seq, err := strconv.ParseInt(chi.URLParam(r, "seq"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "seq must be a sequence number",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	entry, err := h.repo.GetAuditEntry(ctx, tenantID, seq)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "audit entry not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get audit entry", "seq", seq, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get audit entry",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// VerifyAuditLog walks the tenant's hash-chained audit log and reports
+// whether every entry is intact.
+func (h *Handler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	result, err := h.auditTrail.Verify(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to verify audit log", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to verify audit log",
+		})
+		return
+	}
+
+	if !result.Valid {
+		slog.Warn("audit log verification failed",
+			"tenant_id", tenantID,
+			"broken_at", result.BrokenAt,
+			"reason", result.Reason,
+		)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// defaultBacktestWindow is the period of POST /rules/backtest without since.
+const defaultBacktestWindow = 30 * 24 * time.Hour
+
+// BacktestRuleRequest is the request body for POST /rules/backtest.
+type BacktestRuleRequest struct {
+	ID         string            `json:"id,omitempty"`
+	Language   string            `json:"language,omitempty"` // cel (default) or expr
+	Expression string            `json:"expression"`
+	Bands      []domain.RuleBand `json:"bands"`
+	Since      *time.Time        `json:"since,omitempty"` // default 30 days ago
+	Until      *time.Time        `json:"until,omitempty"` // exclusive; default now
+}
+
+// BacktestRule replays the tenant's stored transactions through a candidate
+// rule and reports how many it would have matched, its score distribution
+// and the alerts it would have raised. Nothing is stored.
+func (h *Handler) BacktestRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req BacktestRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.Expression == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "expression is required",
+		})
+		return
+	}
+
+	until := time.Now().UTC()
+	if req.Until != nil {
+		until = *req.Until
+	}
+	since := until.Add(-defaultBacktestWindow)
+	if req.Since != nil {
+		since = *req.Since
+	}
+	if !since.Before(until) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "since must be before until",
+		})
+		return
+	}
+
+	rule := &domain.RuleConfig{
+		ID:         req.ID,
+		Name:       req.ID,
+		Version:    "1.0.0",
+		Language:   req.Language,
+		Expression: req.Expression,
+		Bands:      req.Bands,
+	}
+	if rule.ID == "" {
+		rule.ID = "backtest"
+		rule.Name = rule.ID
+	}
+	if err := h.engine.ValidateRule(rule); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid CEL expression: " + err.Error(),
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	report, err := h.backtest.Run(ctx, tenantID, rule, since, until)
+	if err != nil {
+		slog.Error("failed to backtest rule", "tenant_id", tenantID, "id", rule.ID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to backtest rule",
+		})
+		return
+	}
+
+	slog.Info("rule backtested", "tenant_id", tenantID, "id", rule.ID, "transactions", report.Transactions, "alerts", report.Alerts)
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/balances"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// SetBalanceRequest is the request body for PUT /balances/{accountId}.
+type SetBalanceRequest struct {
+	Balance  *domain.Decimal `json:"balance"`
+	Currency string          `json:"currency,omitempty"`
+}
+
+// WithBalances sets the account balance tracker, so the balance feed and the
+// balances enricher share it.
+func WithBalances(tracker *balances.Tracker) Option {
+	return func(h *Handler) {
+		h.balances = tracker
+	}
+}
+
+// GetBalance returns an account's tracked balance.
+func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	accountID := chi.URLParam(r, "accountId")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	balance, err := h.balances.Get(ctx, tenantID, accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "balance not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get balance", "account_id", accountID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get balance",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// SetBalance sets an account's balance from the caller's ledger. Payments
+// stored afterwards debit and credit it.
+func (h *Handler) SetBalance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	accountID := chi.URLParam(r, "accountId")
+
+	var req SetBalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.Balance == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "balance is required",
+		})
+		return
+	}
+	if req.Currency != "" && len(req.Currency) != 3 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "currency must be a 3-letter ISO currency code",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	balance, err := h.balances.Set(ctx, tenantID, accountID, *req.Balance, req.Currency)
+	if err != nil {
+		slog.Error("failed to save balance", "account_id", accountID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save balance",
+		})
+		return
+	}
+
+	slog.Info("account balance set", "account_id", accountID, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, balance)
+}
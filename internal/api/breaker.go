@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/breaker"
+)
+
+// WithBreaker sets the repository circuit breaker, so evaluations fail fast
+// with its fail-fast policy and /health and /metrics report it.
+func WithBreaker(b *breaker.Repository) Option {
+	return func(h *Handler) {
+		h.breaker = b
+	}
+}
+
+// breakerDependency reports the circuit breaker for GET /health: down while
+// it is open, degraded while queued writes await replay.
+func (h *Handler) breakerDependency() DependencyHealth {
+	if h.breaker == nil {
+		return DependencyHealth{Status: DependencyDisabled}
+	}
+	status := h.breaker.Status()
+	dep := DependencyHealth{Status: DependencyUp, Count: &status.Queued}
+	switch {
+	case status.State == breaker.StateOpen:
+		dep.Status, dep.Error = DependencyDown, "open"
+	case status.Queued > 0:
+		dep.Status, dep.Error = DependencyDegraded, "replaying queued writes"
+	}
+	return dep
+}
+
+// writeBreakerMetrics appends the circuit breaker's state and counters in
+// the Prometheus text format.
+func (h *Handler) writeBreakerMetrics(b *strings.Builder) {
+	if h.breaker == nil {
+		return
+	}
+	status := h.breaker.Status()
+
+	open := 0
+	if status.State == breaker.StateOpen {
+		open = 1
+	}
+	write := func(name, kind, help string, value float64) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s{policy=\"%s\"} %g\n", name, help, name, kind, name, status.Policy, value)
+	}
+	write("osprey_repository_breaker_open", "gauge", "1 while the repository circuit breaker is open, else 0.", float64(open))
+	write("osprey_repository_breaker_consecutive_failures", "gauge", "Consecutive failed repository writes.", float64(status.Failures))
+	write("osprey_repository_breaker_opened_total", "counter", "Times the repository circuit breaker opened.", float64(status.Opened))
+	write("osprey_repository_writes_queued", "gauge", "Repository writes in the write-ahead log awaiting replay.", float64(status.Queued))
+	write("osprey_repository_writes_replayed_total", "counter", "Repository writes replayed from the write-ahead log.", float64(status.Replayed))
+	write("osprey_repository_writes_dropped_total", "counter", "Queued repository writes the database refused on replay.", float64(status.Dropped))
+	write("osprey_repository_writes_rejected_total", "counter", "Repository writes rejected while the circuit breaker was open.", float64(status.Rejected))
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// CORSPolicy decides which browser origins may call the API. Origins can be
+// allowed for every tenant or for specific tenants. With no origins
+// configured, no cross-origin access is granted.
+type CORSPolicy struct {
+	global      []string
+	tenants     map[string][]string
+	credentials bool
+}
+
+// NewCORSPolicy validates the configured origins. An origin is
+// "scheme://host[:port]", "scheme://*.domain" for any subdomain, or "*" for
+// any origin (never combined with credentials).
+func NewCORSPolicy(cfg domain.CORSConfig) (*CORSPolicy, error) {
+	p := &CORSPolicy{
+		tenants:     make(map[string][]string),
+		credentials: cfg.AllowCredentials,
+	}
+
+	var err error
+	if p.global, err = normalizeOrigins(cfg.AllowedOrigins); err != nil {
+		return nil, err
+	}
+	for tenantID, origins := range cfg.TenantOrigins {
+		if p.tenants[tenantID], err = normalizeOrigins(origins); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+	return p, nil
+}
+
+// WithCORS sets the CORS policy. Without it, cross-origin requests are refused.
+func WithCORS(p *CORSPolicy) Option {
+	return func(h *Handler) {
+		h.cors = p
+	}
+}
+
+// ParseTenantOrigins parses "tenant=origin|origin,tenant=origin".
+func ParseTenantOrigins(s string) (map[string][]string, error) {
+	tenants := make(map[string][]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, origins, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(tenantID) == "" {
+			return nil, fmt.Errorf("invalid tenant origins %q (want tenant=origin|origin)", entry)
+		}
+		tenantID = strings.TrimSpace(tenantID)
+		tenants[tenantID] = append(tenants[tenantID], strings.Split(origins, "|")...)
+	}
+	return tenants, nil
+}
+
+// normalizeOrigins validates and lowercases origin patterns.
+func normalizeOrigins(origins []string) ([]string, error) {
+	var out []string
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			out = append(out, origin)
+			continue
+		}
+
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return nil, fmt.Errorf("invalid CORS origin %q (want scheme://host[:port])", origin)
+		}
+		out = append(out, strings.TrimSuffix(origin, "/"))
+	}
+	return out, nil
+}
+
+// originMatches reports whether origin matches any pattern, and whether the
+// match was the "*" wildcard.
+func originMatches(patterns []string, origin string) (matched, anyOrigin bool) {
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "*":
+			anyOrigin = true
+		case pattern == origin:
+			return true, false
+		case strings.Contains(pattern, "://*."):
+			scheme, domain, _ := strings.Cut(pattern, "://*.")
+			if host, ok := strings.CutPrefix(origin, scheme+"://"); ok && strings.HasSuffix(host, "."+domain) {
+				return true, false
+			}
+		}
+	}
+	return anyOrigin, anyOrigin
+}
+
+// allowed checks an origin against the global list and the tenant's list.
+// Preflight requests carry no tenant header, so any tenant's origins count.
+func (p *CORSPolicy) allowed(origin, tenantID string, preflight bool) (matched, anyOrigin bool) {
+	if p == nil {
+		return false, false
+	}
+
+	matched, anyOrigin = originMatches(p.global, origin)
+	if matched && !anyOrigin {
+		return true, false
+	}
+
+	candidates := [][]string{p.tenants[tenantID]}
+	if preflight && tenantID == "" {
+		candidates = candidates[:0]
+		for _, origins := range p.tenants {
+			candidates = append(candidates, origins)
+		}
+	}
+	for _, origins := range candidates {
+		if m, any := originMatches(origins, origin); m && !any {
+			return true, false
+		}
+	}
+
+	return matched, anyOrigin
+}
+
+// Middleware applies the policy. Requests without an Origin header pass
+// through untouched; disallowed origins get no CORS headers, so browsers block
+// the response, and disallowed preflights are refused.
+func (p *CORSPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		matched, anyOrigin := p.allowed(strings.ToLower(origin), r.Header.Get(TenantIDHeader), preflight)
+
+		if !matched {
+			if preflight {
+				slog.Debug("CORS preflight rejected", "origin", origin, "path", r.URL.Path)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if p.credentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Trace-ID")
+
+		// Handle preflight requests
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Tenant-ID, X-Request-ID, X-Trace-ID, Authorization")
+			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// CreateCustomerRequest is the request body for POST /customers.
+type CreateCustomerRequest struct {
+	EntityID string `json:"entityId"` // The debtor or creditor ID the customer transacts as
+	UpsertPartyRequest
+}
+
+// ListCustomers returns the tenant's customer profiles sorted by entity ID,
+// optionally filtered by riskRating and segment.
+func (h *Handler) ListCustomers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	filter := domain.PartyFilter{
+		RiskRating: r.URL.Query().Get("riskRating"),
+		Segment:    r.URL.Query().Get("segment"),
+	}
+	if filter.RiskRating != "" && !domain.ValidRiskRating(filter.RiskRating) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "riskRating must be one of: low, medium, high",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	customers, err := h.repo.ListPartyKYC(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list customers", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list customers",
+		})
+		return
+	}
+	if customers == nil {
+		customers = []*domain.PartyKYC{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"customers": customers,
+		"count":     len(customers),
+	})
+}
+
+// CreateCustomer stores a new customer profile. Customers are the party KYC
+// profiles also served under /parties; an existing entity ID is a conflict.
+func (h *Handler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req CreateCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.EntityID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "entityId is required",
+		})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	_, err := h.repo.GetPartyKYC(ctx, tenantID, req.EntityID)
+	if err == nil {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "customer already exists",
+		})
+		return
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		slog.Error("failed to get customer", "id", req.EntityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get customer",
+		})
+		return
+	}
+
+	h.saveParty(w, r, req.EntityID, &req.UpsertPartyRequest, http.StatusCreated)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Dead-letter listing limits.
+const (
+	defaultListDeadLettersLimit = 100
+	maxListDeadLettersLimit     = 1000
+)
+
+// ListDeadLetters lists the tenant's dead letters, latest first. With
+// pending=true it skips the ones already replayed.
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	filter := domain.DeadLetterFilter{
+		Pending: query.Get("pending") == "true",
+		Limit:   defaultListDeadLettersLimit,
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListDeadLettersLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	dead, err := h.repo.ListDeadLetters(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list dead letters", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list dead letters",
+		})
+		return
+	}
+	if dead == nil {
+		dead = []*domain.DeadLetter{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deadLetters": dead,
+		"count":       len(dead),
+	})
+}
+
+// GetDeadLetter returns a dead letter with its payload and error.
+func (h *Handler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	dead, ok := h.getDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, dead)
+}
+
+// ReplayDeadLetter republishes a dead letter's payload to the topic it was
+// consumed from, so the worker evaluates it again. A dead letter can be
+// replayed once; if the replay fails again, it is dead-lettered anew.
+func (h *Handler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	dead, ok := h.getDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	if dead.ReplayedAt != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "dead letter already replayed",
+		})
+		return
+	}
+	if h.bus == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "event bus not available",
+		})
+		return
+	}
+
+	if err := h.bus.Publish(ctx, tenantID, dead.Topic, []byte(dead.Payload)); err != nil {
+		slog.Error("failed to replay dead letter", "id", dead.ID, "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "failed to replay dead letter",
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := h.repo.MarkDeadLetterReplayed(ctx, tenantID, dead.ID, now); err != nil {
+		// The message is already back on the queue
+		slog.Warn("failed to mark dead letter replayed", "id", dead.ID, "error", err)
+	}
+	dead.ReplayedAt = &now
+
+	writeJSON(w, http.StatusAccepted, dead)
+}
+
+// getDeadLetter loads the dead letter named by the id URL parameter, or
+// writes the error response.
+func (h *Handler) getDeadLetter(w http.ResponseWriter, r *http.Request) (*domain.DeadLetter, bool) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	deadLetterID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return nil, false
+	}
+
+	dead, err := h.repo.GetDeadLetter(ctx, tenantID, deadLetterID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "dead letter not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to get dead letter", "id", deadLetterID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get dead letter",
+		})
+		return nil, false
+	}
+	return dead, true
+}
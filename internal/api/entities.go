@@ -0,0 +1,186 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// defaultEntityHistoryWindow is the period of GET /entities/{id}/transactions
+// without since.
+const defaultEntityHistoryWindow = 30 * 24 * time.Hour
+
+// Limits of the limit query parameter of GET /entities/{id}/transactions.
+const (
+	defaultListEntityTransactionsLimit = 100
+	maxListEntityTransactionsLimit     = 1000
+)
+
+// ListEntityTransactions returns the transactions where the entity is the
+// debtor or the creditor, latest first.
+// Query params: since (RFC 3339, default 30 days ago), until (RFC 3339,
+// exclusive), type, minAmount, maxAmount, limit (default 100) and offset.
+// The response carries nextOffset while more transactions match.
+func (h *Handler) ListEntityTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entityID := chi.URLParam(r, "id")
+	query := r.URL.Query()
+
+	since := time.Now().UTC().Add(-defaultEntityHistoryWindow)
+	if v := query.Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		since = parsed
+	}
+	var until time.Time
+	if v := query.Get("until"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "until must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		until = parsed
+	}
+	var minAmount, maxAmount *domain.Decimal
+	if v := query.Get("minAmount"); v != "" {
+		amount, err := domain.ParseDecimal(v)
+		if err != nil || amount.Sign() < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "minAmount must be a non-negative number",
+			})
+			return
+		}
+		minAmount = &amount
+	}
+	if v := query.Get("maxAmount"); v != "" {
+		amount, err := domain.ParseDecimal(v)
+		if err != nil || amount.Sign() < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "maxAmount must be a non-negative number",
+			})
+			return
+		}
+		maxAmount = &amount
+	}
+	limit := defaultListEntityTransactionsLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListEntityTransactionsLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "offset must be a non-negative integer",
+			})
+			return
+		}
+		offset = n
+	}
+	txType := query.Get("type")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	txs, err := h.repo.GetTransactionsByEntity(ctx, tenantID, entityID, since)
+	if err != nil {
+		slog.Error("failed to get entity transactions", "id", entityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get entity transactions",
+		})
+		return
+	}
+
+	matched := make([]*domain.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		switch {
+		case !until.IsZero() && !tx.Timestamp.Before(until):
+		case txType != "" && tx.Type != txType:
+		case minAmount != nil && tx.Amount.Cmp(*minAmount) < 0:
+		case maxAmount != nil && tx.Amount.Cmp(*maxAmount) > 0:
+		default:
+			matched = append(matched, tx)
+		}
+	}
+
+	resp := map[string]interface{}{}
+	page := matched[min(offset, len(matched)):]
+	if len(page) > limit {
+		page = page[:limit]
+		resp["nextOffset"] = offset + limit
+	}
+	resp["transactions"] = page
+	resp["count"] = len(page)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ListEntityCounterparties returns the entity's edges in the counterparty
+// network, payments out and in, latest first. Each edge sums the payments
+// from its debtor to its creditor.
+// Query params: since (RFC 3339, default 30 days ago) skips edges last seen
+// before it.
+func (h *Handler) ListEntityCounterparties(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entityID := chi.URLParam(r, "id")
+
+	since := time.Now().UTC().Add(-defaultEntityHistoryWindow)
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		since = parsed
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	edges, err := h.repo.ListCounterpartyEdges(ctx, tenantID, entityID, since)
+	if err != nil {
+		slog.Error("failed to list counterparty edges", "id", entityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list counterparty edges",
+		})
+		return
+	}
+	if edges == nil {
+		edges = []*domain.CounterpartyEdge{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"counterparties": edges,
+		"count":          len(edges),
+	})
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Limits of the limit query parameter of GET /evaluations.
+const (
+	defaultListEvaluationsLimit = 100
+	maxListEvaluationsLimit     = 1000
+)
+
+// evaluationMaxAge is how long clients may reuse a stored evaluation without
+// revalidating it. Evaluations never change once stored.
+const evaluationMaxAge = time.Minute
+
+// writeCacheableJSON writes a stored evaluation with an ETag and cache
+// headers for clients and private proxies. A request whose If-None-Match
+// names the ETag gets 304 Not Modified without a body.
+func (h *Handler) writeCacheableJSON(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := encodeJSON(data)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to encode response",
+		})
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(evaluationMaxAge.Seconds())))
+	// The tenant comes from a header, so a cache must not share responses across tenants
+	w.Header().Set("Vary", TenantIDHeader)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.writeSignedBody(w, http.StatusOK, body)
+}
+
+// etagMatches reports whether an If-None-Match header names etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListEvaluations searches the tenant's evaluations, latest first.
+// Query params: status (ALRT or NALT), minScore, maxScore, since and until
+// (RFC 3339), debtor, creditor, txId, rule (failed or asked for review), typology
+// (triggered, or with minContribution scored above it), limit (default 100)
+// and cursor, the nextCursor of the previous page.
+func (h *Handler) ListEvaluations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	filter := domain.EvaluationFilter{
+		Status:     query.Get("status"),
+		DebtorID:   query.Get("debtor"),
+		CreditorID: query.Get("creditor"),
+		TxID:       query.Get("txId"),
+		TypologyID: query.Get("typology"),
+		RuleID:     query.Get("rule"),
+		Limit:      defaultListEvaluationsLimit,
+	}
+	if !parseMinContribution(w, query, filter.TypologyID, &filter.MinContribution) {
+		return
+	}
+	if filter.Status != "" && filter.Status != domain.StatusAlert && filter.Status != domain.StatusNoAlert {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "status must be one of: " + domain.StatusAlert + ", " + domain.StatusNoAlert,
+		})
+		return
+	}
+	if v := query.Get("minScore"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil || score < 0 || score > 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "minScore must be between 0 and 1",
+			})
+			return
+		}
+		filter.MinScore = &score
+	}
+	if v := query.Get("maxScore"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil || score < 0 || score > 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "maxScore must be between 0 and 1",
+			})
+			return
+		}
+		filter.MaxScore = &score
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		filter.Since = since
+	}
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "until must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		filter.Until = until
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListEvaluationsLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := domain.DecodeEvaluationCursor(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "cursor is invalid",
+			})
+			return
+		}
+		filter.After = cursor
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	// Read one extra evaluation to tell whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	evals, err := h.repo.ListEvaluations(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list evaluations", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list evaluations",
+		})
+		return
+	}
+
+	resp := map[string]interface{}{}
+	if len(evals) > limit {
+		evals = evals[:limit]
+		resp["nextCursor"] = domain.CursorOf(evals[limit-1]).Encode()
+	}
+	if evals == nil {
+		evals = []*domain.Evaluation{}
+	}
+	resp["evaluations"] = evals
+	resp["count"] = len(evals)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseMinContribution reads the minContribution query parameter into dst.
+// It needs a typology to apply to. On an invalid value it writes a 400 and
+// returns false.
+func parseMinContribution(w http.ResponseWriter, query url.Values, typologyID string, dst **float64) bool {
+	v := query.Get("minContribution")
+	if v == "" {
+		return true
+	}
+	contribution, err := strconv.ParseFloat(v, 64)
+	if err != nil || contribution < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "minContribution must be a non-negative number",
+		})
+		return false
+	}
+	if typologyID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "minContribution requires typology",
+		})
+		return false
+	}
+	*dst = &contribution
+	return true
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// EvaluationExplanation is the response of GET /evaluations/{id}/explain:
+// why an evaluation was decided the way it was, from what was stored with it.
+type EvaluationExplanation struct {
+	EvaluationID string    `json:"evaluationId"`
+	TxID         string    `json:"txId"`
+	Status       string    `json:"status"`
+	Score        float64   `json:"score"`
+	Timestamp    time.Time `json:"timestamp"`
+	Reasons      []string  `json:"reasons,omitempty"`
+	Actions      []string  `json:"actions,omitempty"`
+
+	// FiredRules are the non-shadow rules that failed or asked for review
+	FiredRules []domain.RuleResult `json:"firedRules"`
+
+	// Typologies are the IDs of the typologies that triggered
+	Typologies []string `json:"typologies,omitempty"`
+
+	// Velocity lists the velocity values the rules saw at decision time
+	Velocity []domain.VelocitySnapshot `json:"velocity,omitempty"`
+
+	// Degradations lists dependencies the decision was made without
+	Degradations []domain.Degradation `json:"degradations,omitempty"`
+}
+
+// DecisionExplanation is the machine-readable breakdown of a decision,
+// returned by POST /evaluate?explain=true.
+type DecisionExplanation struct {
+	Rules      []RuleExplanation     `json:"rules"`
+	Typologies []TypologyExplanation `json:"typologies,omitempty"`
+}
+
+// RuleExplanation is one rule's part in a decision.
+type RuleExplanation struct {
+	RuleID string  `json:"ruleId"`
+	Score  float64 `json:"score"`
+	Band   string  `json:"band"` // the matched band's outcome: ".pass", ".review", ".fail" or ".err"
+	Weight float64 `json:"weight"`
+
+	// Contribution is what the rule added to the evaluation's score: its
+	// share of the aggregate in detection mode, of the highest scoring
+	// typology in compliance mode. Shadow rules contribute nothing.
+	Contribution float64 `json:"contribution"`
+
+	Reason string `json:"reason,omitempty"`
+	Action string `json:"action,omitempty"`
+	Shadow bool   `json:"shadow,omitempty"`
+}
+
+// TypologyExplanation is one typology's part in a decision. Its rule
+// contributions and, for a composite typology, its typology contributions
+// add up to its score.
+type TypologyExplanation struct {
+	TypologyID            string                        `json:"typologyId"`
+	TypologyName          string                        `json:"typologyName"`
+	Score                 float64                       `json:"score"`
+	Threshold             float64                       `json:"threshold"`
+	Triggered             bool                          `json:"triggered"`
+	Contributions         []domain.RuleContribution     `json:"contributions"`
+	TypologyContributions []domain.TypologyContribution `json:"typologyContributions,omitempty"`
+	SuppressedReason      string                        `json:"suppressedReason,omitempty"`
+}
+
+// explainDecision breaks an evaluation down by rule and typology.
+func explainDecision(eval *domain.Evaluation) *DecisionExplanation {
+	explanation := &DecisionExplanation{Rules: make([]RuleExplanation, 0, len(eval.RuleResults))}
+
+	// The typology whose score the rule contributions are taken from
+	var scored *domain.TypologyResult
+	for i := range eval.TypologyResults {
+		typology := &eval.TypologyResults[i]
+		if scored == nil || typology.Score > scored.Score {
+			scored = typology
+		}
+
+		contributions := typology.Contributions
+		if contributions == nil {
+			contributions = []domain.RuleContribution{}
+		}
+		explanation.Typologies = append(explanation.Typologies, TypologyExplanation{
+			TypologyID:            typology.TypologyID,
+			TypologyName:          typology.TypologyName,
+			Score:                 typology.Score,
+			Threshold:             typology.Threshold,
+			Triggered:             typology.Triggered,
+			Contributions:         contributions,
+			TypologyContributions: typology.TypologyContributions,
+			SuppressedReason:      typology.SuppressedReason,
+		})
+	}
+
+	contributions := make(map[string]float64)
+	if scored != nil {
+		for _, c := range scored.Contributions {
+			contributions[c.RuleID] += c.Contribution
+		}
+	}
+
+	for _, result := range eval.RuleResults {
+		rule := RuleExplanation{
+			RuleID: result.RuleID,
+			Score:  result.Score,
+			Band:   result.SubRuleRef,
+			Weight: result.Weight,
+			Reason: result.Reason,
+			Action: result.Action,
+			Shadow: result.Shadow,
+		}
+		if !result.Shadow {
+			rule.Contribution = contributions[result.RuleID]
+		}
+		explanation.Rules = append(explanation.Rules, rule)
+	}
+	return explanation
+}
+
+// ExplainEvaluation returns the rules, typologies and velocity values
+// behind a stored evaluation.
+func (h *Handler) ExplainEvaluation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	evalID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	eval, err := h.repo.GetEvaluation(ctx, tenantID, evalID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "evaluation not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get evaluation", "id", evalID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get evaluation",
+		})
+		return
+	}
+
+	resp := eval.ToResponse()
+	explanation := EvaluationExplanation{
+		EvaluationID: eval.ID,
+		TxID:         eval.TxID,
+		Status:       eval.Status,
+		Score:        eval.Score,
+		Timestamp:    eval.Timestamp,
+		Reasons:      resp.Reasons,
+		Actions:      resp.Actions,
+		FiredRules:   []domain.RuleResult{},
+		Velocity:     eval.Metadata.Velocity,
+		Degradations: eval.Metadata.Degradations,
+	}
+	for _, result := range eval.RuleResults {
+		if !result.Shadow && (result.SubRuleRef == domain.RuleOutcomeFail || result.SubRuleRef == domain.RuleOutcomeReview) {
+			explanation.FiredRules = append(explanation.FiredRules, result)
+		}
+	}
+	for _, typology := range eval.TypologyResults {
+		if typology.Triggered {
+			explanation.Typologies = append(explanation.Typologies, typology.TypologyID)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, explanation)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/features"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// WithFeatures sets the feature flag service, so the API and the engine share
+// configured defaults and cached overrides.
+func WithFeatures(svc *features.Service) Option {
+	return func(h *Handler) {
+		h.features = svc
+	}
+}
+
+// SetFeatureRequest is the request body for PUT /features/{name}.
+type SetFeatureRequest struct {
+	Enabled *bool `json:"enabled"`
+	Global  bool  `json:"global,omitempty"` // Apply install-wide instead of to the calling tenant
+}
+
+// ListFeatures returns the effective feature flags for the tenant.
+func (h *Handler) ListFeatures(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	states := h.features.States(ctx, tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"features": states,
+		"count":    len(states),
+	})
+}
+
+// SetFeature stores a feature flag override for the tenant, or install-wide.
+func (h *Handler) SetFeature(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+
+	var req SetFeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.Enabled == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "enabled is required",
+		})
+		return
+	}
+	if _, ok := features.Lookup(name); !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "unknown feature flag",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	tenantID := featureScope(r, req.Global)
+	previous, err := h.featureOverride(ctx, tenantID, name)
+	if err != nil {
+		slog.Error("failed to load feature flags", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save feature flag",
+		})
+		return
+	}
+	if err := h.features.Set(ctx, tenantID, name, *req.Enabled); err != nil {
+		slog.Error("failed to save feature flag", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save feature flag",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditFeatureFlag, name, previous,
+		&domain.FeatureFlag{TenantID: tenantID, Name: name, Enabled: *req.Enabled})
+	slog.Info("feature flag updated", "name", name, "enabled", *req.Enabled, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"name":    name,
+		"enabled": *req.Enabled,
+		"global":  req.Global,
+	})
+}
+
+// DeleteFeature removes a feature flag override. Pass ?global=true to remove
+// the install-wide override.
+func (h *Handler) DeleteFeature(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	tenantID := featureScope(r, r.URL.Query().Get("global") == "true")
+	previous, err := h.featureOverride(ctx, tenantID, name)
+	if err != nil {
+		slog.Error("failed to load feature flags", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete feature flag",
+		})
+		return
+	}
+	err = h.features.Clear(ctx, tenantID, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "feature flag override not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete feature flag", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete feature flag",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditFeatureFlag, name, previous, nil)
+	slog.Info("feature flag override deleted", "name", name, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Feature flag override deleted; defaults apply.",
+	})
+}
+
+// featureScope returns the tenant a flag change applies to.
+func featureScope(r *http.Request, global bool) string {
+	if global {
+		return features.GlobalTenantID
+	}
+	return GetTenantID(r.Context())
+}
+
+// featureOverride returns the stored override of a flag for the scope, or
+// nil if there is none.
+func (h *Handler) featureOverride(ctx context.Context, tenantID, name string) (*domain.FeatureFlag, error) {
+	flags, err := h.repo.ListFeatureFlags(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range flags {
+		if flag.Name == name {
+			return flag, nil
+		}
+	}
+	return nil, nil
+}
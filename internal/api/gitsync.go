@@ -0,0 +1,97 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/gitsync"
+)
+
+// maxWebhookBodyBytes caps the body of POST /gitsync/webhook.
+const maxWebhookBodyBytes = 1 << 20
+
+// WithGitSync sets the Git syncer. While it is enabled, rules and typologies
+// are read-only through the API.
+func WithGitSync(s *gitsync.Syncer) Option {
+	return func(h *Handler) {
+		h.gitSync = s
+	}
+}
+
+// rejectManaged responds 409 and returns true when Git sync or the rule
+// directory watcher owns rule and typology configuration.
+func (h *Handler) rejectManaged(w http.ResponseWriter) bool {
+	switch {
+	case h.gitSync.Enabled():
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "rules and typologies are managed by Git sync; change them in the repository",
+		})
+	case h.ruleDir.Enabled():
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "rules and typologies are managed by the rule directory; change its files",
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// GetGitSyncStatus returns the result of the most recent Git sync.
+func (h *Handler) GetGitSyncStatus(w http.ResponseWriter, r *http.Request) {
+	if !h.gitSync.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "git sync is not enabled",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.gitSync.Status())
+}
+
+// SyncGit fetches and applies the repository now, returning the result.
+func (h *Handler) SyncGit(w http.ResponseWriter, r *http.Request) {
+	if !h.gitSync.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "git sync is not enabled",
+		})
+		return
+	}
+
+	status, err := h.gitSync.Sync(r.Context())
+	if err != nil {
+		slog.Error("git sync failed", "error", err)
+		writeJSON(w, http.StatusUnprocessableEntity, status)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GitWebhook accepts push webhooks from the Git host and schedules a sync.
+// It needs no tenant; requests must be signed with the webhook secret.
+func (h *Handler) GitWebhook(w http.ResponseWriter, r *http.Request) {
+	if !h.gitSync.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "git sync is not enabled",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "failed to read request body",
+		})
+		return
+	}
+	if !h.gitSync.VerifyWebhook(r.Header, body) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error": "invalid webhook signature",
+		})
+		return
+	}
+
+	h.gitSync.Trigger()
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "sync scheduled",
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+)
+
+// WithGuardrails sets the caps on rule and typology changes.
+func WithGuardrails(policy *guardrails.Policy) Option {
+	return func(h *Handler) {
+		h.guardrails = policy
+	}
+}
+
+// checkGuardrails checks a change against the tenant's caps. A change beyond
+// them is refused with 409 unless the request passes force=true; a forced
+// change is logged and published on TopicGuardrailOverridden as its audit
+// record. It reports whether the change may go ahead.
+func (h *Handler) checkGuardrails(w http.ResponseWriter, r *http.Request, tenantID, operation string, change guardrails.Change) bool {
+	var exceeded *guardrails.ExceededError
+	if err := h.guardrails.Check(tenantID, change); !errors.As(err, &exceeded) {
+		return true
+	}
+
+	ctx := r.Context()
+	if r.URL.Query().Get("force") != "true" {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": exceeded.Error() + "; pass force=true to apply it anyway",
+		})
+		return false
+	}
+
+	event := lifecycleEvent(ctx, domain.TopicGuardrailOverridden)
+	event.TenantID = tenantID
+	event.Operation = operation
+	event.Violations = exceeded.Violations
+	slog.Warn("guardrails overridden",
+		"tenant_id", tenantID,
+		"actor", event.Actor,
+		"operation", operation,
+		"violations", exceeded.Violations,
+	)
+	h.publishLifecycle(ctx, event)
+	return true
+}
+
+// enabledRules counts the rules that apply to a tenant.
+func (h *Handler) enabledRules(tenantID string) int {
+	return len(h.engine.GetTenantRules(tenantID))
+}
@@ -1,17 +1,47 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/alerts"
+	"github.com/opensource-finance/osprey/internal/auditlog"
+	"github.com/opensource-finance/osprey/internal/backtest"
+	"github.com/opensource-finance/osprey/internal/balances"
+	"github.com/opensource-finance/osprey/internal/breaker"
+	"github.com/opensource-finance/osprey/internal/cluster"
+	"github.com/opensource-finance/osprey/internal/corridor"
 	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/features"
+	"github.com/opensource-finance/osprey/internal/gitsync"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+	"github.com/opensource-finance/osprey/internal/jobs"
+	"github.com/opensource-finance/osprey/internal/kyc"
+	"github.com/opensource-finance/osprey/internal/migration"
+	"github.com/opensource-finance/osprey/internal/outcomes"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/retention"
+	"github.com/opensource-finance/osprey/internal/review"
+	"github.com/opensource-finance/osprey/internal/ruledir"
 	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/sandbox"
+	"github.com/opensource-finance/osprey/internal/scoring"
+	"github.com/opensource-finance/osprey/internal/signing"
+	"github.com/opensource-finance/osprey/internal/slo"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/stats"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/txtypes"
+	"github.com/opensource-finance/osprey/internal/worker"
 )
 
 // Handler holds dependencies for API handlers.
@@ -22,43 +52,121 @@ type Handler struct {
 	engine         *rules.Engine
 	typologyEngine *rules.TypologyEngine
 	processor      *tadp.Processor
+	kyc            *kyc.Service
+	balances       *balances.Tracker
+	corridors      *corridor.Service
+	jobs           *jobs.Runner
+	auditLog       *auditlog.Log
+	auditTrail     *auditlog.Trail
+	features       *features.Service
+	alerts         *alerts.Service
+	review         *review.Queue
+	outcomes       *outcomes.Service
+	backtest       *backtest.Service
+	gitSync        *gitsync.Syncer
+	ruleDir        *ruledir.Watcher
+	state          *state.Manager
+	cluster        *cluster.Coordinator
+	breaker        *breaker.Repository
+	queue          *worker.Worker
+	txTypes        *txtypes.Policy
+	guardrails     *guardrails.Policy
+	sandbox        *sandbox.Purger
+	signer         *signing.Signer
+	scoring        *scoring.Service
+	retention      *retention.Service
+	slo            *slo.Tracker
+	stats          *stats.Service
+	top            *stats.Tracker
+	privacy        *stats.Privacy
+	migrations     *migration.Coordinator
 	version        string
 	mode           domain.EvaluationMode // detection or compliance
+	buildInfo      BuildInfo
+	adminNetworks  *AdminNetworks
+	globalWrites   bool // any network may write for the "*" tenant
+	cors           *CORSPolicy
+	openAPI        []byte // OpenAPI document, built by NewServer
+	readyFile      string // written once listening, removed on drain
+	maxClockSkew   time.Duration
+	maxTxAge       time.Duration
+	draining       atomic.Bool
+	drained        chan struct{} // closed on drain, ending alert streams
 }
 
 // NewHandler creates a new API handler.
-func NewHandler(repo domain.Repository, cache domain.Cache, bus domain.EventBus, engine *rules.Engine, typologyEngine *rules.TypologyEngine, processor *tadp.Processor, version string, mode domain.EvaluationMode) *Handler {
-	return &Handler{
+func NewHandler(repo domain.Repository, cache domain.Cache, bus domain.EventBus, engine *rules.Engine, typologyEngine *rules.TypologyEngine, processor *tadp.Processor, version string, mode domain.EvaluationMode, opts ...Option) *Handler {
+	h := &Handler{
 		repo:           repo,
 		cache:          cache,
 		bus:            bus,
 		engine:         engine,
 		typologyEngine: typologyEngine,
 		processor:      processor,
+		kyc:            kyc.NewService(repo, cache),
+		balances:       balances.NewTracker(repo),
+		corridors:      corridor.NewService(repo, cache),
+		jobs:           jobs.NewRunner(repo, engine, typologyEngine, processor, mode),
+		auditLog:       auditlog.NewLog(repo),
+		auditTrail:     auditlog.NewTrail(repo),
+		features:       features.NewService(repo, nil),
+		alerts:         alerts.NewService(repo, bus, domain.AlertConfig{}),
+		review:         review.NewQueue(repo, domain.DefaultConfig().Review),
+		outcomes:       outcomes.NewService(repo, 0),
+		backtest:       backtest.NewService(repo, engine),
+		state:          state.NewManager(repo, engine, typologyEngine),
+		scoring:        scoring.NewService(repo, cache, domain.DefaultConfig().Scoring),
+		retention:      retention.NewService(repo, domain.DefaultConfig().Retention),
+		stats:          stats.NewService(repo),
 		version:        version,
 		mode:           mode,
+		drained:        make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // TransactionRequest is the request body for POST /evaluate.
 type TransactionRequest struct {
+	// TxID keeps the upstream system's transaction ID instead of a generated
+	// one; it must be new to the tenant
+	TxID string `json:"txId,omitempty"`
+
+	// Timestamp is when the transaction happened; it dates the stored
+	// transaction and ends its velocity windows. Default now.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
 	Type     string                 `json:"type"`
 	Debtor   PartyInfo              `json:"debtor"`
 	Creditor PartyInfo              `json:"creditor"`
 	Amount   AmountInfo             `json:"amount"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// VelocityWindow overrides the tenant's velocity window, in seconds; zero
+	// keeps the tenant's window
+	VelocityWindow int `json:"velocityWindow,omitempty"`
+
+	// Links to other transactions of the tenant
+	ReversalOf  string   `json:"reversalOf,omitempty"`  // ID of the stored transaction this one reverses
+	PartOfBatch string   `json:"partOfBatch,omitempty"` // batch ID
+	RelatedTo   []string `json:"relatedTo,omitempty"`   // IDs of related transactions
 }
 
 // PartyInfo represents a debtor or creditor.
 type PartyInfo struct {
 	ID        string `json:"id"`
 	AccountID string `json:"accountId"`
+	Name      string `json:"name,omitempty"`
+	Country   string `json:"country,omitempty"`
 }
 
 // AmountInfo represents the transaction amount.
 type AmountInfo struct {
-	Value    float64 `json:"value"`
-	Currency string  `json:"currency"`
+	Value      domain.Decimal           `json:"value"`
+	Currency   string                   `json:"currency"`
+	Components *domain.AmountComponents `json:"components,omitempty"`
 }
 
 // EvaluateResponse is the response for POST /evaluate.
@@ -68,15 +176,28 @@ type EvaluateResponse struct {
 	Status       string   `json:"status"`
 	Score        float64  `json:"score"`
 	Reasons      []string `json:"reasons,omitempty"`
+	Actions      []string `json:"actions,omitempty"` // recommended treatments, most restrictive first
 	Metadata     struct {
 		TraceID  string `json:"traceId"`
 		IngestMs int64  `json:"ingestMs"`
 		TotalMs  int64  `json:"totalMs"`
 		Version  string `json:"version"`
+
+		// Degradations lists optional dependencies that failed or were skipped
+		Degradations []domain.Degradation `json:"degradations,omitempty"`
+
+		// UnknownTxType marks a type missing from the tenant's allowed list
+		UnknownTxType bool `json:"unknownTxType,omitempty"`
 	} `json:"metadata"`
+
+	// Explanation breaks the decision down by rule and typology (?explain=true)
+	Explanation *DecisionExplanation `json:"explanation,omitempty"`
 }
 
-// Evaluate handles POST /evaluate requests.
+// Evaluate handles POST /evaluate requests. With ?async=true or Prefer:
+// respond-async, the transaction is queued for the async worker instead.
+// With ?explain=true, a synchronous response includes the per-rule and
+// per-typology breakdown of the decision.
 func (h *Handler) Evaluate(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	ctx := r.Context()
@@ -99,34 +220,168 @@ func (h *Handler) Evaluate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.Type == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "type is required",
-		})
+	unknownType, err := h.checkTransaction(ctx, tenantID, &req)
+	if err != nil {
+		writeEvaluationError(w, err)
 		return
 	}
-	if req.Debtor.ID == "" || req.Creditor.ID == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "debtor.id and creditor.id are required",
-		})
+
+	ingestMs := time.Since(start).Milliseconds()
+
+	// Create and save the transaction record
+	tx, err := h.saveTransaction(ctx, tenantID, &req)
+	if err != nil {
+		writeEvaluationError(w, err)
 		return
 	}
-	if req.Amount.Value <= 0 {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "amount.value must be positive",
-		})
+
+	// Asynchronous evaluation: queue it for the worker and return at once
+	if asyncRequested(r) {
+		h.queueEvaluation(w, r, tx, &req)
+		return
+	}
+
+	evaluation, err := h.decide(ctx, tx, &req, unknownType, traceID, start)
+	if err != nil {
+		writeEvaluationError(w, err)
 		return
 	}
 
-	// Generate IDs
-	txID := uuid.New().String()
+	totalMs := time.Since(start).Milliseconds()
 
-	ingestMs := time.Since(start).Milliseconds()
+	// Respond
+	resp := EvaluateResponse{
+		EvaluationID: evaluation.ID,
+		TxID:         tx.ID,
+		Status:       evaluation.Status,
+		Score:        evaluation.Score,
+		Reasons:      tadp.GetReasons(evaluation),
+		Actions:      evaluation.Actions(),
+	}
+	resp.Metadata.TraceID = traceID
+	resp.Metadata.IngestMs = ingestMs
+	resp.Metadata.TotalMs = totalMs
+	resp.Metadata.Version = h.version
+	resp.Metadata.Degradations = evaluation.Metadata.Degradations
+	resp.Metadata.UnknownTxType = evaluation.Metadata.UnknownTxType
+	if r.URL.Query().Get("explain") == "true" {
+		resp.Explanation = explainDecision(evaluation)
+	}
+
+	h.writeSignedJSON(w, http.StatusOK, resp)
+}
+
+// evaluationError is a transaction that can't be evaluated, with the HTTP
+// status to answer it with.
+type evaluationError struct {
+	status  int
+	message string
+}
+
+func (e *evaluationError) Error() string {
+	return e.message
+}
+
+func writeEvaluationError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var evalErr *evaluationError
+	if errors.As(err, &evalErr) {
+		status = evalErr.status
+	}
+	writeJSON(w, status, map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// checkTransaction validates a transaction request of the tenant and reports
+// whether its type is missing from the tenant's allowed list. Errors are
+// *evaluationError.
+func (h *Handler) checkTransaction(ctx context.Context, tenantID string, req *TransactionRequest) (unknownType bool, err error) {
+	invalid := func(message string) error {
+		return &evaluationError{status: http.StatusBadRequest, message: message}
+	}
+	if h.breaker.Rejecting() {
+		return false, &evaluationError{status: http.StatusServiceUnavailable, message: "repository unavailable"}
+	}
+
+	if req.TxID != "" && !domain.ValidTransactionID(req.TxID) {
+		return false, invalid(fmt.Sprintf("txId must be 1 to %d letters, digits, '.', '_', ':' or '-'", domain.MaxTransactionIDLength))
+	}
+	if req.Timestamp != nil {
+		now := time.Now()
+		if h.maxClockSkew > 0 && req.Timestamp.After(now.Add(h.maxClockSkew)) {
+			return false, invalid(fmt.Sprintf("timestamp is more than %s in the future", h.maxClockSkew))
+		}
+		if h.maxTxAge > 0 && req.Timestamp.Before(now.Add(-h.maxTxAge)) {
+			return false, invalid(fmt.Sprintf("timestamp is more than %s in the past", h.maxTxAge))
+		}
+	}
+	if req.Type == "" {
+		return false, invalid("type is required")
+	}
+	unknownType = !h.txTypes.Check(tenantID, req.Type)
+	if unknownType && h.txTypes.Action() == domain.TxTypeReject {
+		return false, invalid(fmt.Sprintf("transaction type %q is not allowed", req.Type))
+	}
+	if req.Debtor.ID == "" || req.Creditor.ID == "" {
+		return false, invalid("debtor.id and creditor.id are required")
+	}
+	if req.Amount.Value.Sign() <= 0 {
+		return false, invalid("amount.value must be positive")
+	}
+	if req.Amount.Components != nil {
+		if err := req.Amount.Components.Validate(req.Amount.Value, req.Amount.Currency); err != nil {
+			return false, invalid(err.Error())
+		}
+	}
+	if req.VelocityWindow < 0 || req.VelocityWindow > int(domain.MaxVelocityWindow/time.Second) {
+		return false, invalid(fmt.Sprintf("velocityWindow must be between 1 and %d seconds, or 0 for the tenant's window", int(domain.MaxVelocityWindow/time.Second)))
+	}
+
+	for _, id := range req.RelatedTo {
+		if id == "" {
+			return false, invalid("relatedTo cannot contain empty IDs")
+		}
+	}
+	if req.TxID != "" && h.repo != nil {
+		_, err := h.repo.GetTransaction(ctx, tenantID, req.TxID)
+		if err == nil {
+			return false, &evaluationError{status: http.StatusConflict, message: fmt.Sprintf("transaction %q already exists", req.TxID)}
+		}
+		// With the breaker open duplicates can't be checked; a queued
+		// duplicate is dropped when it is replayed
+		if !errors.Is(err, repository.ErrNotFound) && !errors.Is(err, breaker.ErrOpen) {
+			slog.Error("failed to get transaction", "tx_id", req.TxID, "error", err)
+			return false, &evaluationError{status: http.StatusInternalServerError, message: "failed to get transaction"}
+		}
+	}
+	if req.ReversalOf != "" && h.repo != nil {
+		original, err := h.repo.GetTransaction(ctx, tenantID, req.ReversalOf)
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, invalid(fmt.Sprintf("reversalOf: transaction %q not found", req.ReversalOf))
+		}
+		if errors.Is(err, breaker.ErrOpen) {
+			return false, &evaluationError{status: http.StatusServiceUnavailable, message: "repository unavailable"}
+		}
+		if err != nil {
+			slog.Error("failed to get reversed transaction", "tx_id", req.ReversalOf, "error", err)
+			return false, &evaluationError{status: http.StatusInternalServerError, message: "failed to get reversed transaction"}
+		}
+		if req.Amount.Value.Cmp(original.Amount) > 0 {
+			return false, invalid("a reversal cannot exceed the amount of the transaction it reverses")
+		}
+	}
+	return unknownType, nil
+}
 
-	// Create transaction record
+// saveTransaction creates the transaction record of a checked request and
+// saves it if the repository is available. Only a txId saved concurrently
+// by another request fails it; other save errors are logged and the
+// transaction is still evaluated.
+func (h *Handler) saveTransaction(ctx context.Context, tenantID string, req *TransactionRequest) (*domain.Transaction, error) {
+	now := time.Now().UTC()
 	tx := &domain.Transaction{
-		ID:              txID,
+		ID:              req.TxID,
 		TenantID:        tenantID,
 		Type:            req.Type,
 		DebtorID:        req.Debtor.ID,
@@ -135,60 +390,92 @@ func (h *Handler) Evaluate(w http.ResponseWriter, r *http.Request) {
 		CreditorAcctID:  req.Creditor.AccountID,
 		Amount:          req.Amount.Value,
 		Currency:        req.Amount.Currency,
-		Timestamp:       time.Now().UTC(),
-		CreatedAt:       time.Now().UTC(),
+		Components:      req.Amount.Components,
+		ReversalOf:      req.ReversalOf,
+		PartOfBatch:     req.PartOfBatch,
+		RelatedTo:       req.RelatedTo,
+		Timestamp:       now,
+		CreatedAt:       now,
 		Metadata:        req.Metadata,
 	}
+	if tx.ID == "" {
+		tx.ID = uuid.New().String()
+	}
+	if req.Timestamp != nil {
+		tx.Timestamp = req.Timestamp.UTC()
+	}
 
-	// Save transaction if repository is available
 	if h.repo != nil {
-		if err := h.repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+		err := h.repo.SaveTransaction(ctx, tenantID, tx)
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, &evaluationError{status: http.StatusConflict, message: fmt.Sprintf("transaction %q already exists", tx.ID)}
+		}
+		if err != nil {
 			slog.Error("failed to save transaction", "error", err)
 			// Continue even if save fails? For now, yes, to prioritize evaluation.
 		}
 	}
+	return tx, nil
+}
 
-	// Synchronous Evaluation
-	// Detection mode: Rules → Weighted Score → Alert
-	// Compliance mode: Rules → Typologies → FATF patterns → Alert
+// decide evaluates a saved transaction synchronously and saves the
+// evaluation.
+// Detection mode: Rules → Weighted Score → Alert
+// Compliance mode: Rules → Typologies → FATF patterns → Alert
+func (h *Handler) decide(ctx context.Context, tx *domain.Transaction, req *TransactionRequest, unknownType bool, traceID string, start time.Time) (*domain.Evaluation, error) {
+	tenantID := tx.TenantID
 
 	// 1. Prepare input
 	evalInput := &rules.EvaluateInput{
-		TenantID:       tenantID,
-		TxID:           txID,
-		Type:           tx.Type,
-		DebtorID:       tx.DebtorID,
-		CreditorID:     tx.CreditorID,
-		Amount:         tx.Amount,
-		Currency:       tx.Currency,
-		VelocityWindow: 3600, // Default 1 hour window
-		AdditionalData: tx.Metadata,
+		TenantID:          tenantID,
+		TxID:              tx.ID,
+		Type:              tx.Type,
+		DebtorID:          tx.DebtorID,
+		CreditorID:        tx.CreditorID,
+		DebtorAccountID:   tx.DebtorAccountID,
+		CreditorAccountID: tx.CreditorAcctID,
+		DebtorName:        req.Debtor.Name,
+		CreditorName:      req.Creditor.Name,
+		DebtorCountry:     req.Debtor.Country,
+		CreditorCountry:   req.Creditor.Country,
+		Amount:            tx.Amount,
+		Currency:          tx.Currency,
+		Timestamp:         tx.Timestamp,
+		Components:        tx.Components,
+		VelocityWindow:    req.VelocityWindow,
+		ReversalOf:        tx.ReversalOf,
+		AdditionalData:    tx.Metadata,
+		Request:           GetRequestContext(ctx),
 	}
 
-	// 2. Evaluate rules
+	// 2. Evaluate rules, collecting any dependency that failed along the way
+	ctx, degradations := domain.WithDegradations(ctx)
+	ctx, velocity := domain.WithVelocitySnapshots(ctx)
 	ruleResults, err := h.engine.EvaluateAll(ctx, evalInput)
 	if err != nil {
 		slog.Error("rule evaluation failed", "error", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "rule evaluation failed",
-		})
-		return
+		return nil, &evaluationError{status: http.StatusInternalServerError, message: "rule evaluation failed"}
 	}
 
 	// 3. Evaluate typologies ONLY in Compliance mode
 	var typologyResults []domain.TypologyResult
 	if h.mode == domain.ModeCompliance && h.typologyEngine != nil && h.typologyEngine.TypologyCount() > 0 {
-		typologyResults = h.typologyEngine.EvaluateTypologies(ruleResults)
+		typologyResults = h.typologyEngine.EvaluateTypologies(tenantID, ruleResults)
 	}
 
 	// 4. Process decision
 	decisionInput := &tadp.DecisionInput{
 		TenantID:        tenantID,
-		TxID:            txID,
+		TxID:            tx.ID,
 		TraceID:         traceID,
 		RuleResults:     ruleResults,
 		TypologyResults: typologyResults,
 		StartTime:       start,
+		Request:         GetRequestContext(ctx),
+		Degradations:    degradations.List(),
+		Velocity:        velocity.List(),
+		UnknownTxType:   unknownType,
+		Transaction:     tx,
 	}
 
 	evaluation := h.processor.Process(ctx, decisionInput)
@@ -200,66 +487,14 @@ func (h *Handler) Evaluate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	totalMs := time.Since(start).Milliseconds()
-
-	// 6. Respond
-	resp := EvaluateResponse{
-		EvaluationID: evaluation.ID,
-		TxID:         txID,
-		Status:       evaluation.Status,
-		Score:        evaluation.Score,
-		Reasons:      tadp.GetReasons(evaluation),
-	}
-	resp.Metadata.TraceID = traceID
-	resp.Metadata.IngestMs = ingestMs
-	resp.Metadata.TotalMs = totalMs
-	resp.Metadata.Version = h.version
-
-	writeJSON(w, http.StatusOK, resp)
-}
-
-// Health returns server health status.
-func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	status := "healthy"
-
-	// Check repository health
-	if h.repo != nil {
-		if err := h.repo.Ping(r.Context()); err != nil {
-			status = "degraded"
-		}
-	}
-
-	// Check cache health
-	if h.cache != nil {
-		if err := h.cache.Ping(r.Context()); err != nil {
-			status = "degraded"
+	// 6. If alert, publish to alert topic, as the worker does
+	if h.bus != nil && tadp.ShouldAlert(evaluation) {
+		payload, _ := json.Marshal(evaluation)
+		if err := h.bus.Publish(ctx, tenantID, domain.TopicAlert, payload); err != nil {
+			slog.Error("failed to publish alert", "tx_id", tx.ID, "error", err)
 		}
 	}
-
-	if h.mode == domain.ModeCompliance && !h.hasLoadedTypologies() {
-		status = "degraded"
-	}
-
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"status":  status,
-		"version": h.version,
-		"mode":    string(h.mode),
-	})
-}
-
-// Ready returns whether the server is ready to accept traffic.
-func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
-	if h.mode == domain.ModeCompliance && !h.hasLoadedTypologies() {
-		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
-			"ready": "false",
-			"error": "compliance mode requires typologies to be loaded",
-		})
-		return
-	}
-
-	writeJSON(w, http.StatusOK, map[string]string{
-		"ready": "true",
-	})
+	return evaluation, nil
 }
 
 // GetEvaluation retrieves an evaluation by ID.
@@ -291,7 +526,7 @@ func (h *Handler) GetEvaluation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, eval)
+	h.writeCacheableJSON(w, r, eval)
 }
 
 // GetTransaction retrieves a transaction by ID.
@@ -315,6 +550,12 @@ func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tx, err := h.repo.GetTransaction(ctx, tenantID, txID)
+	if errors.Is(err, breaker.ErrOpen) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository unavailable",
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("failed to get transaction", "id", txID, "error", err)
 		writeJSON(w, http.StatusNotFound, map[string]string{
@@ -326,11 +567,12 @@ func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, tx)
 }
 
-// ListRules returns all loaded rules from the engine.
+// ListRules returns the loaded rules that apply to the caller's tenant: its
+// own rules and the global rules it doesn't override.
 // Rules are loaded from the database at startup and can be reloaded via POST /rules/reload.
 func (h *Handler) ListRules(w http.ResponseWriter, r *http.Request) {
 	// Return rules currently loaded in the engine (sourced from database)
-	loadedRules := h.engine.GetLoadedRules()
+	loadedRules := h.engine.GetTenantRules(GetTenantID(r.Context()))
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"rules":  loadedRules,
@@ -351,7 +593,7 @@ func (h *Handler) GetRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check rules loaded in the engine (from database)
-	for _, rule := range h.engine.GetLoadedRules() {
+	for _, rule := range h.engine.GetTenantRules(GetTenantID(r.Context())) {
 		if rule.ID == ruleID {
 			writeJSON(w, http.StatusOK, rule)
 			return
@@ -368,17 +610,27 @@ type CreateRuleRequest struct {
 	ID          string            `json:"id"`
 	Name        string            `json:"name"`
 	Description string            `json:"description,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Language    string            `json:"language,omitempty"` // cel (default) or expr
 	Expression  string            `json:"expression"`
 	Bands       []domain.RuleBand `json:"bands"`
 	Weight      float64           `json:"weight"`
 	Enabled     bool              `json:"enabled"`
+	SampleRate  float64           `json:"sampleRate,omitempty"`
+	Shadow      bool              `json:"shadow,omitempty"`
 }
 
 // CreateRule creates a new rule and saves it to the database.
-// Rules are saved globally (tenant_id = "*") so they apply to all tenants.
+// Rules are saved for the caller's tenant; a tenant ID of "*" saves a global
+// rule that applies to every tenant without its own rule of that ID.
 // After saving, call POST /rules/reload to hot-reload into the engine.
 func (h *Handler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.rejectManaged(w) {
+		return
+	}
 
 	var req CreateRuleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -396,17 +648,34 @@ func (h *Handler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create rule config (global tenant)
+	if req.SampleRate < 0 || req.SampleRate > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "sampleRate must be between 0 and 1",
+		})
+		return
+	}
+
+	if !domain.ValidRuleLanguage(req.Language) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "language must be one of: cel, expr",
+		})
+		return
+	}
+
 	ruleConfig := &domain.RuleConfig{
 		ID:          req.ID,
-		TenantID:    GlobalTenantID,
+		TenantID:    tenantID,
 		Name:        req.Name,
 		Description: req.Description,
 		Version:     "1.0.0",
+		Owner:       req.Owner,
+		Language:    req.Language,
 		Expression:  req.Expression,
 		Bands:       req.Bands,
 		Weight:      req.Weight,
 		Enabled:     req.Enabled,
+		SampleRate:  req.SampleRate,
+		Shadow:      req.Shadow,
 	}
 
 	// Validate CEL expression without mutating loaded engine rules.
@@ -417,29 +686,243 @@ func (h *Handler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Persist to repository (global tenant ID)
 	if h.repo != nil {
-		if err := h.repo.SaveRuleConfig(ctx, GlobalTenantID, ruleConfig); err != nil {
-			slog.Error("failed to save rule config", "id", ruleConfig.ID, "error", err)
+		// A rule saved over an existing ID replaces it; the audit log keeps what it replaced
+		previous, err := h.repo.GetRuleConfig(ctx, tenantID, ruleConfig.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			slog.Error("failed to get rule config", "tenant_id", tenantID, "id", ruleConfig.ID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to save rule",
+			})
+			return
+		}
+		if err := h.repo.SaveRuleConfig(ctx, tenantID, ruleConfig); err != nil {
+			slog.Error("failed to save rule config", "tenant_id", tenantID, "id", ruleConfig.ID, "error", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]string{
 				"error": "failed to save rule",
 			})
 			return
 		}
+		h.recordAudit(ctx, domain.AuditCreate, domain.AuditRule, ruleConfig.ID, previous, ruleConfig)
 	}
 
-	slog.Info("rule created", "id", ruleConfig.ID, "name", ruleConfig.Name)
+	slog.Info("rule created", "tenant_id", tenantID, "id", ruleConfig.ID, "name", ruleConfig.Name)
+	event := lifecycleEvent(ctx, domain.TopicRuleCreated)
+	event.Rule = ruleConfig
+	h.publishLifecycle(ctx, event)
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"rule":    ruleConfig,
 		"message": "Rule created. Call POST /rules/reload to apply changes.",
 	})
 }
 
-// GlobalTenantID is used for rules that apply to all tenants.
-const GlobalTenantID = "*"
+// UpdateRule updates a rule of the caller's tenant in place, keeping its
+// version, and reloads the engine.
+func (h *Handler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
 
-// ReloadRules reloads all rules from the database into the engine.
-// This enables hot-reloading without server restart.
+	if h.rejectManaged(w) {
+		return
+	}
+
+	ruleID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	var req CreateRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	if req.Name == "" || req.Expression == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "name and expression are required",
+		})
+		return
+	}
+
+	if req.SampleRate < 0 || req.SampleRate > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "sampleRate must be between 0 and 1",
+		})
+		return
+	}
+
+	if !domain.ValidRuleLanguage(req.Language) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "language must be one of: cel, expr",
+		})
+		return
+	}
+
+	existing, err := h.repo.GetRuleConfig(ctx, tenantID, ruleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get rule config", "tenant_id", tenantID, "id", ruleID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to load rule",
+		})
+		return
+	}
+
+	ruleConfig := &domain.RuleConfig{
+		ID:          ruleID,
+		TenantID:    tenantID,
+		Name:        req.Name,
+		Description: req.Description,
+		Version:     existing.Version,
+		Owner:       req.Owner,
+		Language:    req.Language,
+		Expression:  req.Expression,
+		Bands:       req.Bands,
+		Weight:      req.Weight,
+		Enabled:     req.Enabled,
+		SampleRate:  req.SampleRate,
+		Shadow:      req.Shadow,
+	}
+
+	if err := h.engine.ValidateRule(ruleConfig); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid CEL expression: " + err.Error(),
+		})
+		return
+	}
+
+	if h.guardrails.Enabled() && existing.Enabled && !ruleConfig.Enabled {
+		change := guardrails.Change{EnabledRules: h.enabledRules(tenantID), DisabledRules: []string{ruleID}}
+		if !h.checkGuardrails(w, r, tenantID, "PUT /rules/"+ruleID, change) {
+			return
+		}
+	}
+
+	if err := h.repo.SaveRuleConfig(ctx, tenantID, ruleConfig); err != nil {
+		slog.Error("failed to update rule config", "tenant_id", tenantID, "id", ruleID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to update rule",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditUpdate, domain.AuditRule, ruleID, existing, ruleConfig)
+	slog.Info("rule updated", "tenant_id", tenantID, "id", ruleID)
+	event := lifecycleEvent(ctx, domain.TopicRuleUpdated)
+	event.Rule = ruleConfig
+	h.publishLifecycle(ctx, event)
+	resp := map[string]interface{}{
+		"rule":    ruleConfig,
+		"message": "Rule updated and engine reloaded.",
+	}
+	if diff, err := h.reloadRules(ctx); err != nil {
+		slog.Error("failed to reload rules after update", "error", err)
+		resp["message"] = "Rule updated, but the engine reload failed. Call POST /rules/reload to apply changes."
+	} else {
+		h.cluster.Notify(ctx, tenantID)
+		resp["changes"] = diff
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DeleteRule soft-deletes a rule of the caller's tenant and auto-reloads the engine.
+func (h *Handler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.rejectManaged(w) {
+		return
+	}
+
+	ruleID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	existing, err := h.repo.GetRuleConfig(ctx, tenantID, ruleID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		slog.Error("failed to get rule config", "tenant_id", tenantID, "id", ruleID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete rule",
+		})
+		return
+	}
+	if h.guardrails.Enabled() && existing != nil && existing.Enabled {
+		change := guardrails.Change{EnabledRules: h.enabledRules(tenantID), DisabledRules: []string{ruleID}}
+		if !h.checkGuardrails(w, r, tenantID, "DELETE /rules/"+ruleID, change) {
+			return
+		}
+	}
+
+	if err := h.repo.DeleteRuleConfig(ctx, tenantID, ruleID); err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			slog.Error("failed to delete rule", "tenant_id", tenantID, "id", ruleID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to delete rule",
+			})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule not found",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditRule, ruleID, existing, nil)
+	slog.Info("rule deleted", "tenant_id", tenantID, "id", ruleID)
+	event := lifecycleEvent(ctx, domain.TopicRuleDeleted)
+	event.RuleID = ruleID
+	h.publishLifecycle(ctx, event)
+	resp := map[string]interface{}{
+		"message": "Rule deleted and engine reloaded.",
+	}
+	if diff, err := h.reloadRules(ctx); err != nil {
+		slog.Error("failed to reload rules after delete", "error", err)
+		resp["message"] = "Rule deleted, but the engine reload failed. Call POST /rules/reload to apply changes."
+	} else {
+		h.cluster.Notify(ctx, tenantID)
+		resp["changes"] = diff
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// reloadRules loads every tenant's rules from the database into the engine.
+func (h *Handler) reloadRules(ctx context.Context) (*rules.RuleDiff, error) {
+	dbRules, err := h.repo.ListAllRuleConfigs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	return h.engine.ReloadRules(dbRules)
+}
+
+// reloadLists loads every tenant's named lists from the database into the
+// engine and returns how many were loaded.
+func (h *Handler) reloadLists(ctx context.Context) (int, error) {
+	lists, err := h.repo.ListAllNamedLists(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list named lists: %w", err)
+	}
+	h.engine.ReloadLists(lists)
+	return len(lists), nil
+}
+
+// ReloadRules reloads the rules and named lists of every tenant from the
+// database into the engine. This enables hot-reloading without server restart.
 func (h *Handler) ReloadRules(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -450,8 +933,7 @@ func (h *Handler) ReloadRules(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Load rules from database (global rules)
-	dbRules, err := h.repo.ListRuleConfigs(ctx, GlobalTenantID)
+	dbRules, err := h.repo.ListAllRuleConfigs(ctx)
 	if err != nil {
 		slog.Error("failed to list rules from database", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
@@ -461,7 +943,8 @@ func (h *Handler) ReloadRules(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Reload into engine
-	if err := h.engine.ReloadRules(dbRules); err != nil {
+	diff, err := h.engine.ReloadRules(dbRules)
+	if err != nil {
 		slog.Error("failed to reload rules into engine", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to reload rules: " + err.Error(),
@@ -469,10 +952,33 @@ func (h *Handler) ReloadRules(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slog.Info("rules reloaded from database", "count", len(dbRules))
+	listCount, err := h.reloadLists(ctx)
+	if err != nil {
+		slog.Error("failed to reload named lists into engine", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "rules reloaded, but the named lists failed to load",
+		})
+		return
+	}
+
+	h.cluster.Notify(ctx, "")
+	h.recordAudit(ctx, domain.AuditReload, domain.AuditRules, "", nil, map[string]interface{}{
+		"count":   len(dbRules),
+		"changes": diff,
+		"lists":   listCount,
+	})
+	slog.Info("rules reloaded from database",
+		"count", len(dbRules),
+		"added", len(diff.Added),
+		"removed", len(diff.Removed),
+		"modified", len(diff.Modified),
+		"lists", listCount,
+	)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "rules reloaded successfully",
 		"count":   len(dbRules),
+		"changes": diff,
+		"lists":   listCount,
 	})
 }
 
@@ -482,6 +988,24 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// lifecycleEvent returns an event of the topic for the caller's tenant and
+// principal.
+func lifecycleEvent(ctx context.Context, topic string) *domain.LifecycleEvent {
+	return domain.NewLifecycleEvent(topic, GetTenantID(ctx), GetRequestContext(ctx).Principal, time.Now())
+}
+
+// publishLifecycle publishes a lifecycle event on the event bus. The change
+// it describes is already made, so a failure is logged rather than returned.
+func (h *Handler) publishLifecycle(ctx context.Context, event *domain.LifecycleEvent) {
+	if h.bus == nil {
+		return
+	}
+	payload, _ := json.Marshal(event)
+	if err := h.bus.Publish(ctx, event.TenantID, event.Type, payload); err != nil {
+		slog.Error("failed to publish lifecycle event", "tenant_id", event.TenantID, "topic", event.Type, "error", err)
+	}
+}
+
 func (h *Handler) hasLoadedTypologies() bool {
 	return h.typologyEngine != nil && h.typologyEngine.TypologyCount() > 0
 }
@@ -497,10 +1021,123 @@ type CreateTypologyRequest struct {
 	Description    string                      `json:"description,omitempty"`
 	Rules          []domain.TypologyRuleWeight `json:"rules"`
 	AlertThreshold float64                     `json:"alertThreshold"`
+	MinRulesFired  int                         `json:"minRulesFired,omitempty"`
+	MinCoverage    float64                     `json:"minCoverage,omitempty"`
+	Typologies     []domain.TypologyWeight     `json:"typologies,omitempty"`
+	Condition      *domain.TypologyCondition   `json:"condition,omitempty"`
 	Enabled        bool                        `json:"enabled"`
+
+	// NormalizeWeights scales the rule weights to sum to 1.0 before saving.
+	NormalizeWeights bool `json:"normalizeWeights,omitempty"`
+}
+
+// validateCoverage checks a typology's minimum rule coverage options.
+func (req *CreateTypologyRequest) validateCoverage() string {
+	if req.MinRulesFired < 0 || req.MinRulesFired > len(req.Rules) {
+		return "minRulesFired must be between 0 and the number of rules"
+	}
+	if req.MinCoverage < 0 || req.MinCoverage > 1 {
+		return "minCoverage must be between 0 and 1"
+	}
+	return ""
+}
+
+// weightTolerance is how far a typology's rule weights may sum from 1.0.
+const weightTolerance = 0.01
+
+// validateTypologyRules checks a typology's rules, the typologies it refers
+// to and its condition for the tenant, and returns an error message, or "" if
+// they are valid. With normalizeWeights the weights are scaled to sum to 1.0
+// in place. Rules must exist for the tenant when checkRules is set; referenced
+// typologies must always be loaded for it and must not lead back to the
+// typology. Weights that don't sum to 1.0 are logged, or rejected under strict.
+func (h *Handler) validateTypologyRules(tenantID string, req *CreateTypologyRequest, checkRules, strict bool) string {
+	var ruleIDSet map[string]bool
+	if checkRules {
+		loadedRules := h.engine.GetTenantRules(tenantID)
+		ruleIDSet = make(map[string]bool, len(loadedRules))
+		for _, r := range loadedRules {
+			ruleIDSet[r.ID] = true
+		}
+	}
+
+	var totalWeight float64
+	for _, rule := range req.Rules {
+		if rule.RuleID == "" {
+			return "rule_id cannot be empty"
+		}
+		if checkRules && !ruleIDSet[rule.RuleID] {
+			return fmt.Sprintf("rule_id '%s' does not exist in rule engine", rule.RuleID)
+		}
+		// Weights to be normalized only need to be relative to each other
+		if rule.Weight < 0 || (rule.Weight > 1 && !req.NormalizeWeights) {
+			return "rule weight must be between 0 and 1"
+		}
+		totalWeight += rule.Weight
+	}
+	for _, typology := range req.Typologies {
+		if typology.TypologyID == "" {
+			return "typologyId cannot be empty"
+		}
+		if typology.Weight < 0 || (typology.Weight > 1 && !req.NormalizeWeights) {
+			return "typology weight must be between 0 and 1"
+		}
+		totalWeight += typology.Weight
+	}
+
+	if req.Condition != nil {
+		if err := rules.ValidateCondition(req.Condition); err != nil {
+			return err.Error()
+		}
+		ruleIDs, _ := rules.ConditionRefs(req.Condition)
+		for _, ruleID := range ruleIDs {
+			if checkRules && !ruleIDSet[ruleID] {
+				return fmt.Sprintf("condition rule '%s' does not exist in rule engine", ruleID)
+			}
+		}
+	}
+
+	if len(req.Typologies) > 0 || req.Condition != nil {
+		// Check the tenant's typologies as they would be with this one saved
+		graph := []*domain.Typology{{ID: req.ID, Typologies: req.Typologies, Condition: req.Condition}}
+		if h.typologyEngine != nil {
+			for _, t := range h.typologyEngine.GetTenantTypologies(tenantID) {
+				if t.ID != req.ID {
+					graph = append(graph, t)
+				}
+			}
+		}
+		if err := rules.ValidateTypologyGraph(graph); err != nil {
+			return err.Error()
+		}
+	}
+
+	if req.NormalizeWeights {
+		if totalWeight == 0 {
+			return "cannot normalize rule weights that sum to 0"
+		}
+		for i := range req.Rules {
+			req.Rules[i].Weight /= totalWeight
+		}
+		for i := range req.Typologies {
+			req.Typologies[i].Weight /= totalWeight
+		}
+		return ""
+	}
+
+	if math.Abs(totalWeight-1) > weightTolerance {
+		if strict {
+			return fmt.Sprintf("rule weights sum to %g, not 1.0; set normalizeWeights to scale them", totalWeight)
+		}
+		slog.Warn("typology weights do not sum to 1.0",
+			"typology_id", req.ID,
+			"total_weight", totalWeight,
+		)
+	}
+	return ""
 }
 
-// ListTypologies returns all loaded typologies.
+// ListTypologies returns the loaded typologies that apply to the caller's tenant.
 func (h *Handler) ListTypologies(w http.ResponseWriter, r *http.Request) {
 	if h.typologyEngine == nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
@@ -509,7 +1146,7 @@ func (h *Handler) ListTypologies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	typologies := h.typologyEngine.GetLoadedTypologies()
+	typologies := h.typologyEngine.GetTenantTypologies(GetTenantID(r.Context()))
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"typologies": typologies,
@@ -537,7 +1174,7 @@ func (h *Handler) GetTypology(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check typologies loaded in the engine
-	for _, t := range h.typologyEngine.GetLoadedTypologies() {
+	for _, t := range h.typologyEngine.GetTenantTypologies(GetTenantID(r.Context())) {
 		if t.ID == typologyID {
 			writeJSON(w, http.StatusOK, t)
 			return
@@ -549,9 +1186,15 @@ func (h *Handler) GetTypology(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// CreateTypology creates a new typology and saves it to the database.
+// CreateTypology creates a new typology for the caller's tenant and saves it
+// to the database. A tenant ID of "*" saves a global typology.
 func (h *Handler) CreateTypology(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.rejectManaged(w) {
+		return
+	}
 
 	var req CreateTypologyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -569,49 +1212,20 @@ func (h *Handler) CreateTypology(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Rules) == 0 {
+	if len(req.Rules) == 0 && len(req.Typologies) == 0 {
 		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "at least one rule is required",
+			"error": "at least one rule or typology is required",
 		})
 		return
 	}
 
-	// Validate rules exist in engine and weights are valid
-	loadedRules := h.engine.GetLoadedRules()
-	ruleIDSet := make(map[string]bool, len(loadedRules))
-	for _, r := range loadedRules {
-		ruleIDSet[r.ID] = true
-	}
-
-	var totalWeight float64
-	for _, rule := range req.Rules {
-		if rule.RuleID == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": "rule_id cannot be empty",
-			})
-			return
-		}
-		if !ruleIDSet[rule.RuleID] {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": fmt.Sprintf("rule_id '%s' does not exist in rule engine", rule.RuleID),
-			})
-			return
-		}
-		if rule.Weight < 0 || rule.Weight > 1 {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": "rule weight must be between 0 and 1",
-			})
-			return
-		}
-		totalWeight += rule.Weight
-	}
-
-	// Warn if weights don't sum to approximately 1.0 (allow 0.01 tolerance)
-	if totalWeight < 0.99 || totalWeight > 1.01 {
-		slog.Warn("typology weights do not sum to 1.0",
-			"typology_id", req.ID,
-			"total_weight", totalWeight,
-		)
+	// Validate rules apply to the tenant and weights are valid
+	strict := h.features.Enabled(ctx, tenantID, features.StrictTypologies)
+	if msg := h.validateTypologyRules(tenantID, &req, true, strict); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
 	}
 
 	// Validate threshold - must be > 0 to avoid triggering on every transaction
@@ -622,39 +1236,64 @@ func (h *Handler) CreateTypology(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create typology config (global tenant)
+	if msg := req.validateCoverage(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+
 	typology := &domain.Typology{
 		ID:             req.ID,
-		TenantID:       GlobalTenantID,
+		TenantID:       tenantID,
 		Name:           req.Name,
 		Description:    req.Description,
 		Version:        "1.0.0",
 		Rules:          req.Rules,
 		AlertThreshold: req.AlertThreshold,
+		MinRulesFired:  req.MinRulesFired,
+		MinCoverage:    req.MinCoverage,
+		Typologies:     req.Typologies,
+		Condition:      req.Condition,
 		Enabled:        req.Enabled,
 	}
 
 	// Persist to repository
 	if h.repo != nil {
-		if err := h.repo.SaveTypology(ctx, GlobalTenantID, typology); err != nil {
-			slog.Error("failed to save typology", "id", typology.ID, "error", err)
+		previous, err := h.repo.GetTypology(ctx, tenantID, typology.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			slog.Error("failed to get typology", "tenant_id", tenantID, "id", typology.ID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to save typology",
+			})
+			return
+		}
+		if err := h.repo.SaveTypology(ctx, tenantID, typology); err != nil {
+			slog.Error("failed to save typology", "tenant_id", tenantID, "id", typology.ID, "error", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]string{
 				"error": "failed to save typology",
 			})
 			return
 		}
+		h.recordAudit(ctx, domain.AuditCreate, domain.AuditTypology, typology.ID, previous, typology)
 	}
 
-	slog.Info("typology created", "id", typology.ID, "name", typology.Name)
+	slog.Info("typology created", "tenant_id", tenantID, "id", typology.ID, "name", typology.Name)
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"typology": typology,
 		"message":  "Typology created. Call POST /typologies/reload to apply changes.",
 	})
 }
 
-// UpdateTypology updates an existing typology.
+// UpdateTypology updates an existing typology of the caller's tenant.
 func (h *Handler) UpdateTypology(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.rejectManaged(w) {
+		return
+	}
+
 	typologyID := chi.URLParam(r, "id")
 
 	if typologyID == "" {
@@ -672,54 +1311,89 @@ func (h *Handler) UpdateTypology(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate rules
-	for _, rule := range req.Rules {
-		if rule.RuleID == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": "rule_id cannot be empty",
-			})
-			return
-		}
-		if rule.Weight < 0 || rule.Weight > 1 {
-			writeJSON(w, http.StatusBadRequest, map[string]string{
-				"error": "rule weight must be between 0 and 1",
-			})
-			return
-		}
+	// Validate rules. Strict mode also checks them against the rule engine
+	// and the threshold, as on create
+	req.ID = typologyID
+	strict := h.features.Enabled(ctx, tenantID, features.StrictTypologies)
+	if msg := h.validateTypologyRules(tenantID, &req, strict, strict); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+	if strict && (req.AlertThreshold <= 0 || req.AlertThreshold > 1) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "alertThreshold must be between 0 (exclusive) and 1",
+		})
+		return
+	}
+	if msg := req.validateCoverage(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
 	}
 
 	// Update typology
 	typology := &domain.Typology{
 		ID:             typologyID,
-		TenantID:       GlobalTenantID,
+		TenantID:       tenantID,
 		Name:           req.Name,
 		Description:    req.Description,
 		Version:        "1.0.0",
 		Rules:          req.Rules,
 		AlertThreshold: req.AlertThreshold,
+		MinRulesFired:  req.MinRulesFired,
+		MinCoverage:    req.MinCoverage,
+		Typologies:     req.Typologies,
+		Condition:      req.Condition,
 		Enabled:        req.Enabled,
 	}
 
 	if h.repo != nil {
-		if err := h.repo.SaveTypology(ctx, GlobalTenantID, typology); err != nil {
-			slog.Error("failed to update typology", "id", typologyID, "error", err)
+		existing, err := h.repo.GetTypology(ctx, tenantID, typologyID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			slog.Error("failed to get typology", "tenant_id", tenantID, "id", typologyID, "error", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]string{
 				"error": "failed to update typology",
 			})
 			return
 		}
+		if h.guardrails.Enabled() && existing != nil && existing.AlertThreshold != typology.AlertThreshold {
+			change := guardrails.Change{Thresholds: []guardrails.ThresholdChange{
+				{TypologyID: typologyID, From: existing.AlertThreshold, To: typology.AlertThreshold},
+			}}
+			if !h.checkGuardrails(w, r, tenantID, "PUT /typologies/"+typologyID, change) {
+				return
+			}
+		}
+
+		if err := h.repo.SaveTypology(ctx, tenantID, typology); err != nil {
+			slog.Error("failed to update typology", "tenant_id", tenantID, "id", typologyID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to update typology",
+			})
+			return
+		}
+		h.recordAudit(ctx, domain.AuditUpdate, domain.AuditTypology, typologyID, existing, typology)
 	}
 
-	slog.Info("typology updated", "id", typologyID)
+	slog.Info("typology updated", "tenant_id", tenantID, "id", typologyID)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"typology": typology,
 		"message":  "Typology updated. Call POST /typologies/reload to apply changes.",
 	})
 }
 
-// DeleteTypology deletes a typology and auto-reloads the engine.
+// DeleteTypology deletes a typology of the caller's tenant and auto-reloads the engine.
 func (h *Handler) DeleteTypology(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.rejectManaged(w) {
+		return
+	}
+
 	typologyID := chi.URLParam(r, "id")
 
 	if typologyID == "" {
@@ -730,33 +1404,46 @@ func (h *Handler) DeleteTypology(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if h.repo != nil {
-		if err := h.repo.DeleteTypology(ctx, GlobalTenantID, typologyID); err != nil {
-			slog.Error("failed to delete typology", "id", typologyID, "error", err)
+		existing, err := h.repo.GetTypology(ctx, tenantID, typologyID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			slog.Error("failed to get typology", "tenant_id", tenantID, "id", typologyID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to delete typology",
+			})
+			return
+		}
+		if err := h.repo.DeleteTypology(ctx, tenantID, typologyID); err != nil {
+			slog.Error("failed to delete typology", "tenant_id", tenantID, "id", typologyID, "error", err)
 			writeJSON(w, http.StatusNotFound, map[string]string{
 				"error": "typology not found",
 			})
 			return
 		}
+		h.recordAudit(ctx, domain.AuditDelete, domain.AuditTypology, typologyID, existing, nil)
 
 		// Auto-reload typology engine after delete
 		if h.typologyEngine != nil {
-			dbTypologies, err := h.repo.ListTypologies(ctx, GlobalTenantID)
+			dbTypologies, err := h.repo.ListAllTypologies(ctx)
 			if err != nil {
 				slog.Error("failed to reload typologies after delete", "error", err)
 			} else {
 				h.typologyEngine.ReloadTypologies(dbTypologies)
+				h.cluster.Notify(ctx, tenantID)
 				slog.Info("typologies auto-reloaded after delete", "count", len(dbTypologies))
+				event := lifecycleEvent(ctx, domain.TopicTypologyReloaded)
+				event.Typologies = len(dbTypologies)
+				h.publishLifecycle(ctx, event)
 			}
 		}
 	}
 
-	slog.Info("typology deleted", "id", typologyID)
+	slog.Info("typology deleted", "tenant_id", tenantID, "id", typologyID)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "Typology deleted and engine reloaded.",
 	})
 }
 
-// ReloadTypologies reloads all typologies from the database into the engine.
+// ReloadTypologies reloads the typologies of every tenant from the database into the engine.
 func (h *Handler) ReloadTypologies(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -774,8 +1461,7 @@ func (h *Handler) ReloadTypologies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Load typologies from database (global)
-	dbTypologies, err := h.repo.ListTypologies(ctx, GlobalTenantID)
+	dbTypologies, err := h.repo.ListAllTypologies(ctx)
 	if err != nil {
 		slog.Error("failed to list typologies from database", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
@@ -786,8 +1472,15 @@ func (h *Handler) ReloadTypologies(w http.ResponseWriter, r *http.Request) {
 
 	// Reload into engine
 	h.typologyEngine.ReloadTypologies(dbTypologies)
+	h.cluster.Notify(ctx, "")
 
+	h.recordAudit(ctx, domain.AuditReload, domain.AuditTypologies, "", nil, map[string]interface{}{
+		"count": len(dbTypologies),
+	})
 	slog.Info("typologies reloaded from database", "count", len(dbTypologies))
+	event := lifecycleEvent(ctx, domain.TopicTypologyReloaded)
+	event.Typologies = len(dbTypologies)
+	h.publishLifecycle(ctx, event)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "typologies reloaded successfully",
 		"count":   len(dbTypologies),
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/worker"
+)
+
+// healthTimeout bounds each dependency check of GET /health.
+const healthTimeout = 2 * time.Second
+
+// Dependency statuses reported by GET /health.
+const (
+	DependencyUp       = "up"
+	DependencyDown     = "down"
+	DependencyDegraded = "degraded" // working, but not as it should
+	DependencyDisabled = "disabled" // not configured on this instance
+)
+
+// DependencyHealth is the status of one dependency in GET /health.
+type DependencyHealth struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+
+	// Count is the active rules, the loaded typologies, the worker's
+	// subscriptions or the breaker's queued writes
+	Count *int `json:"count,omitempty"`
+}
+
+// HealthResponse is the response of GET /health.
+type HealthResponse struct {
+	Status       string                      `json:"status"` // healthy or degraded
+	Version      string                      `json:"version"`
+	Mode         string                      `json:"mode"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
+	Queue        *worker.QueueStatus         `json:"queue,omitempty"`
+}
+
+// Health returns the server's health with the status of each dependency:
+// the repository, cache and event bus with their ping latency, the async
+// worker's subscriptions, the loaded rules and typologies and the
+// repository circuit breaker. It answers 200 even when degraded, so
+// liveness probes don't restart an instance for an outage elsewhere; /ready
+// decides whether it takes traffic.
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deps := map[string]DependencyHealth{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ping := func(name string, configured bool, fn func(context.Context) error) {
+		if !configured {
+			mu.Lock()
+			deps[name] = DependencyHealth{Status: DependencyDisabled}
+			mu.Unlock()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dep := pingDependency(ctx, fn)
+			mu.Lock()
+			deps[name] = dep
+			mu.Unlock()
+		}()
+	}
+	ping("repository", h.repo != nil, func(ctx context.Context) error { return h.repo.Ping(ctx) })
+	ping("cache", h.cache != nil, func(ctx context.Context) error { return h.cache.Ping(ctx) })
+	ping("eventBus", h.bus != nil, func(ctx context.Context) error { return h.bus.Ping(ctx) })
+	wg.Wait()
+
+	resp := HealthResponse{
+		Version:      h.version,
+		Mode:         string(h.mode),
+		Dependencies: deps,
+	}
+
+	deps["rules"] = loadedDependency(h.engine.ActiveRulesCount(), h.mode == domain.ModeDetection)
+	typologies := 0
+	if h.typologyEngine != nil {
+		typologies = h.typologyEngine.TypologyCount()
+	}
+	deps["typologies"] = loadedDependency(typologies, h.mode == domain.ModeCompliance)
+	deps["breaker"] = h.breakerDependency()
+
+	// Async evaluation falling behind real-time traffic
+	if h.queue != nil {
+		queue := h.queue.QueueStatus()
+		resp.Queue = &queue
+		subs := h.queue.Subscriptions()
+		dep := DependencyHealth{Status: DependencyUp, Count: &subs}
+		switch {
+		case subs == 0:
+			dep.Status, dep.Error = DependencyDown, "no subscriptions"
+		case queue.Lagging:
+			dep.Status, dep.Error = DependencyDegraded, "lagging"
+		}
+		deps["worker"] = dep
+	} else {
+		deps["worker"] = DependencyHealth{Status: DependencyDisabled}
+	}
+
+	resp.Status = "healthy"
+	for _, dep := range deps {
+		if dep.Status == DependencyDown || dep.Status == DependencyDegraded {
+			resp.Status = "degraded"
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// pingDependency times fn, bounded by healthTimeout.
+func pingDependency(ctx context.Context, fn func(context.Context) error) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	dep := DependencyHealth{
+		Status:    DependencyUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		dep.Status, dep.Error = DependencyDown, err.Error()
+	}
+	return dep
+}
+
+// loadedDependency reports loaded rules or typologies, which are down when
+// the evaluation mode requires some and none are loaded.
+func loadedDependency(count int, required bool) DependencyHealth {
+	dep := DependencyHealth{Status: DependencyUp, Count: &count}
+	if count == 0 && required {
+		dep.Status, dep.Error = DependencyDown, "none loaded"
+	}
+	return dep
+}
+
+// Ready returns whether the server is ready to accept traffic: not
+// draining, with the rules, or in compliance mode the typologies, it needs
+// to evaluate, and with its repository answering.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	if msg := h.notReady(r.Context()); msg != "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"ready": "false",
+			"error": msg,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"ready": "true",
+	})
+}
+
+// notReady returns why the server can't take traffic, or "" if it can.
+// Shadow rules don't count, since they decide nothing.
+func (h *Handler) notReady(ctx context.Context) string {
+	switch {
+	case h.draining.Load():
+		return "draining"
+	case h.mode == domain.ModeCompliance && !h.hasLoadedTypologies():
+		return "compliance mode requires typologies to be loaded"
+	case h.mode == domain.ModeDetection && h.engine.ActiveRulesCount() == 0:
+		return "detection mode requires at least one enabled rule outside shadow mode to be loaded"
+	}
+	if h.repo != nil {
+		if dep := pingDependency(ctx, h.repo.Ping); dep.Status != DependencyUp {
+			return "repository unavailable: " + dep.Error
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// CreateIndexesRequest is the request body for POST /admin/indexes.
+type CreateIndexesRequest struct {
+	// Names lists the indexes to create; empty creates every recommended one
+	Names []string `json:"names,omitempty"`
+}
+
+// AdviseIndexes inspects query statistics and every tenant's entity
+// cardinality and velocity window, and reports which indexes the velocity
+// and list queries are missing.
+func (h *Handler) AdviseIndexes(w http.ResponseWriter, r *http.Request) {
+	advisor, ok := h.indexAdvisor(w)
+	if !ok {
+		return
+	}
+
+	report, err := advisor.AdviseIndexes(r.Context(), h.velocityWindows())
+	if err != nil {
+		slog.Error("failed to advise indexes", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to inspect query statistics",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// CreateIndexes creates the named indexes, or every recommended index that
+// doesn't exist yet, and returns the refreshed report.
+func (h *Handler) CreateIndexes(w http.ResponseWriter, r *http.Request) {
+	advisor, ok := h.indexAdvisor(w)
+	if !ok {
+		return
+	}
+
+	var req CreateIndexesRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "invalid JSON request body",
+			})
+			return
+		}
+	}
+
+	ctx := r.Context()
+	names := req.Names
+	if len(names) == 0 {
+		report, err := advisor.AdviseIndexes(ctx, h.velocityWindows())
+		if err != nil {
+			slog.Error("failed to advise indexes", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to inspect query statistics",
+			})
+			return
+		}
+		for _, advice := range report.Indexes {
+			if advice.Recommended {
+				names = append(names, advice.Name)
+			}
+		}
+	}
+
+	created := make(map[string]bool, len(names))
+	for _, name := range names {
+		if err := advisor.CreateIndex(ctx, name); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "unknown index: " + name,
+				})
+				return
+			}
+			slog.Error("failed to create index", "index", name, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to create index " + name,
+			})
+			return
+		}
+		slog.Info("index created", "index", name)
+		created[name] = true
+	}
+
+	report, err := advisor.AdviseIndexes(ctx, h.velocityWindows())
+	if err != nil {
+		slog.Error("failed to advise indexes", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to inspect query statistics",
+		})
+		return
+	}
+	for i := range report.Indexes {
+		report.Indexes[i].Created = created[report.Indexes[i].Name]
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// indexAdvisor returns the repository's index advisor, writing an error
+// response when there is none.
+func (h *Handler) indexAdvisor(w http.ResponseWriter) (domain.IndexAdvisor, bool) {
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return nil, false
+	}
+	advisor, ok := domain.BaseRepository(h.repo).(domain.IndexAdvisor)
+	if !ok {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{
+			"error": "repository does not support index advice",
+		})
+		return nil, false
+	}
+	return advisor, true
+}
+
+// velocityWindows returns the engine's velocity window configuration.
+func (h *Handler) velocityWindows() domain.VelocityConfig {
+	if h.engine == nil {
+		return domain.VelocityConfig{}
+	}
+	return h.engine.VelocityWindows()
+}
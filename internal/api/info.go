@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/features"
+)
+
+// BuildInfo describes the running binary and deployment for GET /info.
+type BuildInfo struct {
+	Commit     string
+	BuildDate  string
+	Tier       domain.Tier
+	Subsystems map[string]any // e.g. repository driver, cache type, async worker enabled
+}
+
+// Option configures optional Handler dependencies.
+type Option func(*Handler)
+
+// WithBuildInfo sets the build and deployment details reported by GET /info.
+func WithBuildInfo(info BuildInfo) Option {
+	return func(h *Handler) {
+		h.buildInfo = info
+	}
+}
+
+// InfoResponse is the response for GET /info.
+type InfoResponse struct {
+	Version    string          `json:"version"`
+	Commit     string          `json:"commit"`
+	BuildDate  string          `json:"buildDate"`
+	Tier       domain.Tier     `json:"tier"`
+	Mode       string          `json:"mode"`
+	Subsystems map[string]any  `json:"subsystems"`
+	Enrichers  []string        `json:"enrichers"`
+	Rules      int             `json:"rules"`
+	Typologies int             `json:"typologies"`
+	Features   map[string]bool `json:"features"`
+}
+
+// Info returns machine-readable build, configuration and engine details.
+func (h *Handler) Info(w http.ResponseWriter, r *http.Request) {
+	resp := InfoResponse{
+		Version:    h.version,
+		Commit:     h.buildInfo.Commit,
+		BuildDate:  h.buildInfo.BuildDate,
+		Tier:       h.buildInfo.Tier,
+		Mode:       string(h.mode),
+		Subsystems: h.buildInfo.Subsystems,
+		Enrichers:  []string{},
+		Features:   map[string]bool{},
+	}
+	if resp.Subsystems == nil {
+		resp.Subsystems = map[string]any{}
+	}
+
+	if h.engine != nil {
+		resp.Rules = h.engine.RulesCount()
+		resp.Enrichers = h.engine.Enrichers()
+	}
+	if h.typologyEngine != nil {
+		resp.Typologies = h.typologyEngine.TypologyCount()
+	}
+	for _, state := range h.features.States(r.Context(), features.GlobalTenantID) {
+		resp.Features[state.Name] = state.Enabled
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// AuditIsolation reports records that reference other tenants' data:
+// typologies using other tenants' rules, evaluations and alerts naming other
+// tenants' transactions, and outcomes linked to other tenants' evaluations.
+func (h *Handler) AuditIsolation(w http.ResponseWriter, r *http.Request) {
+	auditor, ok := h.isolationAuditor(w)
+	if !ok {
+		return
+	}
+
+	report, err := auditor.AuditIsolation(r.Context())
+	if err != nil {
+		slog.Error("failed to audit tenant isolation", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to audit tenant isolation",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// RepairIsolation repairs the repairable isolation violations and reports
+// every violation found, marking those it repaired.
+func (h *Handler) RepairIsolation(w http.ResponseWriter, r *http.Request) {
+	auditor, ok := h.isolationAuditor(w)
+	if !ok {
+		return
+	}
+
+	report, err := auditor.RepairIsolation(r.Context())
+	if err != nil {
+		slog.Error("failed to repair tenant isolation", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to repair tenant isolation",
+		})
+		return
+	}
+
+	slog.Info("tenant isolation repaired", "violations", len(report.Violations), "repaired", report.Repaired)
+	writeJSON(w, http.StatusOK, report)
+}
+
+// isolationAuditor returns the repository's isolation auditor, writing an
+// error response when there is none.
+func (h *Handler) isolationAuditor(w http.ResponseWriter) (domain.IsolationAuditor, bool) {
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return nil, false
+	}
+	auditor, ok := domain.BaseRepository(h.repo).(domain.IsolationAuditor)
+	if !ok {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{
+			"error": "repository does not support isolation audits",
+		})
+		return nil, false
+	}
+	return auditor, true
+}
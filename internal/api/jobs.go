@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/jobs"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// maxBatchFileBytes caps the size of an uploaded batch file.
+const maxBatchFileBytes = 32 << 20
+
+// maxReevaluateWindow caps how far back a single reevaluate job may reach.
+const maxReevaluateWindow = 366 * 24 * time.Hour
+
+// listJobsLimit is how many recent jobs GET /jobs returns.
+const listJobsLimit = 100
+
+// ReevaluateJobRequest is the request body for POST /jobs/reevaluate.
+type ReevaluateJobRequest struct {
+	Since         time.Time `json:"since"`
+	Until         time.Time `json:"until,omitempty"`         // Defaults to now
+	RatePerSecond int       `json:"ratePerSecond,omitempty"` // Defaults to jobs.DefaultRatePerSecond
+}
+
+// ListJobs returns the tenant's most recent jobs.
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	list, err := h.repo.ListJobs(ctx, tenantID, listJobsLimit)
+	if err != nil {
+		slog.Error("failed to list jobs", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list jobs",
+		})
+		return
+	}
+	if list == nil {
+		list = []*domain.Job{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"jobs":  list,
+		"count": len(list),
+	})
+}
+
+// SubmitBatchJob accepts a CSV transactions file and evaluates it in the background.
+// The request body is the raw file; progress is tracked at GET /jobs/{id}.
+func (h *Handler) SubmitBatchJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.mode == domain.ModeCompliance && !h.hasLoadedTypologies() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "compliance mode requires typologies to be loaded",
+		})
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchFileBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": "batch file exceeds 32MB limit",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "failed to read request body",
+		})
+		return
+	}
+
+	job, err := h.jobs.SubmitBatch(ctx, tenantID, data)
+	if errors.Is(err, jobs.ErrInvalidFile) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, jobs.ErrNoRepository) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to submit batch job", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to submit batch job",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// SubmitReevaluateJob starts a throttled backfill that re-evaluates stored
+// transactions under the currently loaded rules.
+func (h *Handler) SubmitReevaluateJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.mode == domain.ModeCompliance && !h.hasLoadedTypologies() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "compliance mode requires typologies to be loaded",
+		})
+		return
+	}
+
+	var req ReevaluateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	until := req.Until
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+	if req.Since.IsZero() || !req.Since.Before(until) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "since is required and must be before until",
+		})
+		return
+	}
+	if until.Sub(req.Since) > maxReevaluateWindow {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "window cannot exceed 366 days",
+		})
+		return
+	}
+	if req.RatePerSecond < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "ratePerSecond cannot be negative",
+		})
+		return
+	}
+
+	job, err := h.jobs.SubmitReevaluate(ctx, tenantID, domain.JobParams{
+		Since:         req.Since.UTC(),
+		Until:         until.UTC(),
+		RatePerSecond: req.RatePerSecond,
+	})
+	if errors.Is(err, jobs.ErrNoRepository) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to submit reevaluate job", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to submit reevaluate job",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// PauseJob suspends a running job.
+func (h *Handler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	h.controlJob(w, r, "paused", h.jobs.Pause)
+}
+
+// ResumeJob continues a paused job.
+func (h *Handler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	h.controlJob(w, r, "resumed", h.jobs.Resume)
+}
+
+// CancelJob stops a running or paused job; work already done is kept.
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	h.controlJob(w, r, "cancelled", h.jobs.Cancel)
+}
+
+// controlJob applies a pause/resume/cancel action to a job running in this process.
+func (h *Handler) controlJob(w http.ResponseWriter, r *http.Request, action string, apply func(tenantID, jobID string) error) {
+	tenantID := GetTenantID(r.Context())
+	jobID := chi.URLParam(r, "id")
+
+	if err := apply(tenantID, jobID); err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "job is not running on this instance",
+		})
+		return
+	}
+
+	slog.Info("job "+action, "job_id", jobID, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Job " + action + ".",
+	})
+}
+
+// GetJob returns a job's status and progress.
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	jobID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	job, err := h.repo.GetJob(ctx, tenantID, jobID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "job not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get job", "id", jobID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get job",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+// GetJobResults downloads the results file of a completed batch job as CSV.
+func (h *Handler) GetJobResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	jobID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	job, err := h.repo.GetJob(ctx, tenantID, jobID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "job not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get job", "id", jobID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get job",
+		})
+		return
+	}
+	if job.Status != domain.JobStatusCompleted {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "job results are not available (status: " + job.Status + ")",
+		})
+		return
+	}
+
+	data, err := h.repo.GetJobFile(ctx, tenantID, jobID, domain.JobFileResult)
+	if err != nil {
+		slog.Error("failed to get job results", "id", jobID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get job results",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+jobID+`-results.csv"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
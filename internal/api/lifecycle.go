@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// Drain takes the instance out of rotation ahead of a shutdown: GET /ready
+// starts failing and the readiness file is removed, while requests are still
+// served. It is the preStop hook's trigger, called by `osprey drain`.
+func (h *Handler) Drain(w http.ResponseWriter, r *http.Request) {
+	h.drain()
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "draining",
+	})
+}
+
+// drain marks the instance as draining and ends open alert streams, so their
+// clients reconnect elsewhere. It may be called more than once.
+func (h *Handler) drain() {
+	if h.draining.Swap(true) {
+		return
+	}
+	slog.Info("draining, readiness now fails")
+	close(h.drained)
+	if h.readyFile == "" {
+		return
+	}
+	if err := os.Remove(h.readyFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("failed to remove readiness file", "path", h.readyFile, "error", err)
+	}
+}
+
+// markReady writes the readiness file once the server is listening, unless
+// it is already draining.
+func (h *Handler) markReady() {
+	if h.readyFile == "" || h.draining.Load() {
+		return
+	}
+	if err := os.WriteFile(h.readyFile, []byte("ready\n"), 0o644); err != nil {
+		slog.Error("failed to write readiness file", "path", h.readyFile, "error", err)
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// maxMaintenanceWindow caps how long a maintenance window may suppress
+// alerts, so a forgotten window can't silence a tenant indefinitely.
+const maxMaintenanceWindow = 7 * 24 * time.Hour
+
+// CreateMaintenanceWindowRequest is the request body for POST /maintenance.
+type CreateMaintenanceWindowRequest struct {
+	StartsAt time.Time `json:"startsAt,omitempty"` // defaults to now
+	EndsAt   time.Time `json:"endsAt"`
+	Reason   string    `json:"reason"`
+	By       string    `json:"by,omitempty"` // Ignored when the request carries a principal
+}
+
+// CancelMaintenanceWindowRequest is the request body for DELETE
+// /maintenance/{id}. The body is optional.
+type CancelMaintenanceWindowRequest struct {
+	By string `json:"by,omitempty"` // Ignored when the request carries a principal
+}
+
+// MaintenanceWindowResponse is a maintenance window with whether it is
+// suppressing alerts right now.
+type MaintenanceWindowResponse struct {
+	*domain.MaintenanceWindow
+	Active bool `json:"active"`
+}
+
+func maintenanceWindowResponse(window *domain.MaintenanceWindow, now time.Time) MaintenanceWindowResponse {
+	return MaintenanceWindowResponse{MaintenanceWindow: window, Active: window.Active(now)}
+}
+
+// ListMaintenanceWindows returns the tenant's maintenance windows, including
+// past and cancelled ones, latest start first.
+func (h *Handler) ListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	windows, err := h.repo.ListMaintenanceWindows(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to list maintenance windows", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list maintenance windows",
+		})
+		return
+	}
+
+	now := time.Now()
+	list := make([]MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		list = append(list, maintenanceWindowResponse(window, now))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"windows": list,
+		"count":   len(list),
+	})
+}
+
+// CreateMaintenanceWindow schedules a maintenance window.
+func (h *Handler) CreateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req CreateMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if principal := GetRequestContext(ctx).Principal; principal != "" {
+		req.By = principal
+	}
+
+	now := time.Now().UTC()
+	if req.StartsAt.IsZero() {
+		req.StartsAt = now
+	}
+	if req.Reason == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "reason is required",
+		})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) || !req.EndsAt.After(now) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "endsAt must be in the future and after startsAt",
+		})
+		return
+	}
+	if req.EndsAt.Sub(req.StartsAt) > maxMaintenanceWindow {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "a maintenance window may last at most 7 days",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	window := &domain.MaintenanceWindow{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		Reason:    req.Reason,
+		CreatedBy: req.By,
+		CreatedAt: now,
+	}
+	if err := h.repo.SaveMaintenanceWindow(ctx, tenantID, window); err != nil {
+		slog.Error("failed to save maintenance window", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save maintenance window",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditCreate, domain.AuditMaintenanceWindow, window.ID, nil, window)
+	slog.Info("maintenance window scheduled",
+		"tenant_id", tenantID,
+		"window_id", window.ID,
+		"starts_at", window.StartsAt,
+		"ends_at", window.EndsAt,
+		"by", window.CreatedBy,
+	)
+	writeJSON(w, http.StatusCreated, maintenanceWindowResponse(window, now))
+}
+
+// CancelMaintenanceWindow cancels a maintenance window, ending it if it is
+// active. The window is kept, with who cancelled it and when.
+func (h *Handler) CancelMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	windowID := chi.URLParam(r, "id")
+
+	var req CancelMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if principal := GetRequestContext(ctx).Principal; principal != "" {
+		req.By = principal
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	window, err := h.repo.GetMaintenanceWindow(ctx, tenantID, windowID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "maintenance window not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get maintenance window", "id", windowID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get maintenance window",
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	err = h.repo.CancelMaintenanceWindow(ctx, tenantID, windowID, req.By, now)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "maintenance window already cancelled",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to cancel maintenance window", "id", windowID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to cancel maintenance window",
+		})
+		return
+	}
+	before := *window
+	window.CancelledBy = req.By
+	window.CancelledAt = &now
+	h.recordAudit(ctx, domain.AuditUpdate, domain.AuditMaintenanceWindow, windowID, &before, window)
+
+	slog.Info("maintenance window cancelled", "tenant_id", tenantID, "window_id", windowID, "by", req.By)
+	writeJSON(w, http.StatusOK, maintenanceWindowResponse(window, now))
+}
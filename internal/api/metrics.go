@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/worker"
+)
+
+// WithQueue sets the async worker whose queue lag is reported by /metrics
+// and /health.
+func WithQueue(w *worker.Worker) Option {
+	return func(h *Handler) {
+		h.queue = w
+	}
+}
+
+// queueMetrics describes the per-tenant queue metrics in the order they
+// are written.
+var queueMetrics = []struct {
+	name, kind, help string
+	value            func(worker.TenantLag) float64
+}{
+	{"osprey_queue_backlog", "gauge", "Messages received by the async worker but not yet evaluated.",
+		func(l worker.TenantLag) float64 { return float64(l.Backlog) }},
+	{"osprey_queue_lag_seconds", "gauge", "Age of the last evaluated message when it was handled.",
+		func(l worker.TenantLag) float64 { return float64(l.LagMs) / 1000 }},
+	{"osprey_queue_max_lag_seconds", "gauge", "Highest message age since the worker started.",
+		func(l worker.TenantLag) float64 { return float64(l.MaxLagMs) / 1000 }},
+	{"osprey_queue_processed_total", "counter", "Messages evaluated by the async worker.",
+		func(l worker.TenantLag) float64 { return float64(l.Processed) }},
+	{"osprey_queue_failed_total", "counter", "Messages the async worker failed to evaluate.",
+		func(l worker.TenantLag) float64 { return float64(l.Failed) }},
+	{"osprey_queue_lagging", "gauge", "1 when the tenant's async evaluation is falling behind.",
+		func(l worker.TenantLag) float64 {
+			if l.Lagging {
+				return 1
+			}
+			return 0
+		}},
+}
+
+// laneMetrics describes the per-lane metrics reported when priority lanes
+// are enabled.
+var laneMetrics = []struct {
+	name, kind, help string
+	value            func(worker.LaneStatus) float64
+}{
+	{"osprey_queue_lane_capacity", "gauge", "Concurrent evaluations allowed in the priority lane.",
+		func(l worker.LaneStatus) float64 { return float64(l.Capacity) }},
+	{"osprey_queue_lane_busy", "gauge", "Evaluations in progress in the priority lane.",
+		func(l worker.LaneStatus) float64 { return float64(l.Busy) }},
+	{"osprey_queue_lane_backlog", "gauge", "Messages waiting on the priority lane topic.",
+		func(l worker.LaneStatus) float64 { return float64(l.Backlog) }},
+	{"osprey_queue_lane_routed_total", "counter", "Messages classified into the priority lane.",
+		func(l worker.LaneStatus) float64 { return float64(l.Routed) }},
+}
+
+// labelEscaper escapes Prometheus label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics writes async queue, SLO and circuit breaker metrics in the Prometheus text format.
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	status := h.queue.QueueStatus()
+
+	var b strings.Builder
+	for _, m := range queueMetrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, lag := range status.Tenants {
+			fmt.Fprintf(&b, "%s{tenant=\"%s\"} %g\n", m.name, labelEscaper.Replace(lag.TenantID), m.value(lag))
+		}
+	}
+
+	if len(status.Lanes) > 0 {
+		for _, m := range laneMetrics {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+			for _, lane := range status.Lanes {
+				fmt.Fprintf(&b, "%s{lane=\"%s\"} %g\n", m.name, labelEscaper.Replace(lane.Lane), m.value(lane))
+			}
+		}
+	}
+
+	h.writeSLOMetrics(&b)
+	h.writeBreakerMetrics(&b)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
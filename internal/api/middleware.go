@@ -3,10 +3,12 @@ package api
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -33,6 +35,11 @@ const (
 
 	// TraceIDHeader is the HTTP header for trace ID.
 	TraceIDHeader = "X-Trace-ID"
+
+	// PrincipalHeader identifies the authenticated caller. It is trusted as-is,
+	// so it must be set (and stripped from client requests) by the auth proxy
+	// in front of Osprey.
+	PrincipalHeader = "X-Principal"
 )
 
 var tracer = otel.Tracer("osprey-api")
@@ -48,6 +55,16 @@ func TenantMiddleware(next http.Handler) http.Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), TenantIDKey, tenantID)
+
+		// Fill in the shared request context so outer middleware (logging) sees it too
+		rc := domain.RequestContextFrom(ctx)
+		if rc == nil {
+			rc = newRequestContext(r, "", "")
+			ctx = domain.WithRequestContext(ctx, rc)
+		}
+		rc.TenantID = tenantID
+		rc.Principal = r.Header.Get(PrincipalHeader)
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -80,6 +97,7 @@ func TracingMiddleware(next http.Handler) http.Handler {
 		// Add to context
 		ctx = context.WithValue(ctx, RequestIDKey, requestID)
 		ctx = context.WithValue(ctx, TraceIDKey, traceID)
+		ctx = domain.WithRequestContext(ctx, newRequestContext(r, requestID, traceID))
 
 		// Set response headers
 		w.Header().Set(RequestIDHeader, requestID)
@@ -101,47 +119,13 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		// Extract context values for logging
-		tenantID, _ := r.Context().Value(TenantIDKey).(string)
-		requestID, _ := r.Context().Value(RequestIDKey).(string)
-		traceID, _ := r.Context().Value(TraceIDKey).(string)
-
-		slog.Info("http request",
+		attrs := []any{
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", rw.statusCode,
 			"duration_ms", duration.Milliseconds(),
-			"tenant_id", tenantID,
-			"request_id", requestID,
-			"trace_id", traceID,
-		)
-	})
-}
-
-// CORSMiddleware handles Cross-Origin Resource Sharing for browser clients.
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow requests from any origin in development
-		// In production, this should be restricted to specific origins
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			origin = "*"
-		}
-
-		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Tenant-ID, X-Request-ID, X-Trace-ID, Authorization")
-		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Trace-ID")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
 		}
-
-		next.ServeHTTP(w, r)
+		slog.Info("http request", append(attrs, GetRequestContext(r.Context()).LogAttrs()...)...)
 	})
 }
 
@@ -171,6 +155,39 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush lets streamed responses such as GET /stream/alerts reach the client.
+func (rw *responseWriter) Flush() {
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// newRequestContext starts a request context for r. ClientIP relies on
+// middleware.RealIP having already rewritten RemoteAddr.
+func newRequestContext(r *http.Request, requestID, traceID string) *domain.RequestContext {
+	clientIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		clientIP = host
+	}
+	return &domain.RequestContext{
+		TraceID:   traceID,
+		RequestID: requestID,
+		ClientIP:  clientIP,
+	}
+}
+
+// GetRequestContext returns the request context populated by the middleware.
+// It never returns nil, so callers can read fields directly.
+func GetRequestContext(ctx context.Context) *domain.RequestContext {
+	if rc := domain.RequestContextFrom(ctx); rc != nil {
+		return rc
+	}
+	return &domain.RequestContext{}
+}
+
 // GetTenantID extracts tenant ID from context.
 func GetTenantID(ctx context.Context) string {
 	if v, ok := ctx.Value(TenantIDKey).(string); ok {
@@ -186,4 +203,3 @@ func GetTraceID(ctx context.Context) string {
 	}
 	return ""
 }
-
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/migration"
+)
+
+// migrationRetryAfter is the Retry-After, in seconds, of writes rejected
+// while a tenant is frozen for cutover.
+const migrationRetryAfter = "60"
+
+// WithMigrations sets the coordinator that migrates tenants to another
+// repository. Without one, the migration endpoints answer 501.
+func WithMigrations(c *migration.Coordinator) Option {
+	return func(h *Handler) {
+		h.migrations = c
+	}
+}
+
+// MigrationRequest is the request body for POST /admin/migrations.
+type MigrationRequest struct {
+	TenantID string `json:"tenantId"`
+	Cutover  bool   `json:"cutover"`
+}
+
+// MigrationFreeze rejects writes to a tenant frozen for migration cutover,
+// so nothing reaches the old store once the copy is final. Reads still work.
+func (h *Handler) MigrationFreeze(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if h.migrations.Frozen(GetTenantID(r.Context())) {
+				w.Header().Set("Retry-After", migrationRetryAfter)
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+					"error": "tenant is frozen for migration cutover",
+				})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StartMigration starts copying a tenant's rules, typologies, transactions
+// and evaluations to the migration target. With "cutover": true the tenant
+// is frozen for a final pass and stays frozen once consistent.
+func (h *Handler) StartMigration(w http.ResponseWriter, r *http.Request) {
+	if !h.migrationsEnabled(w) {
+		return
+	}
+
+	var req MigrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	req.TenantID = strings.TrimSpace(req.TenantID)
+	if req.TenantID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "tenantId is required",
+		})
+		return
+	}
+
+	m, err := h.migrations.Start(req.TenantID, req.Cutover, r.Header.Get(PrincipalHeader))
+	if errors.Is(err, migration.ErrRunning) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "the tenant is being migrated or is frozen; release it first",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to start migration",
+		})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, m)
+}
+
+// ListMigrations returns each tenant's latest migration on this instance.
+func (h *Handler) ListMigrations(w http.ResponseWriter, r *http.Request) {
+	if !h.migrationsEnabled(w) {
+		return
+	}
+
+	migrations := h.migrations.List()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"migrations": migrations,
+		"count":      len(migrations),
+	})
+}
+
+// GetMigration returns a tenant's latest migration with its report.
+func (h *Handler) GetMigration(w http.ResponseWriter, r *http.Request) {
+	if !h.migrationsEnabled(w) {
+		return
+	}
+
+	m, err := h.migrations.Get(chi.URLParam(r, "tenantId"))
+	if errors.Is(err, migration.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "migration not found",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// ReleaseMigration lifts a tenant's cutover freeze and forgets its
+// migration, e.g. to abandon a cutover or to migrate again.
+func (h *Handler) ReleaseMigration(w http.ResponseWriter, r *http.Request) {
+	if !h.migrationsEnabled(w) {
+		return
+	}
+
+	err := h.migrations.Release(chi.URLParam(r, "tenantId"))
+	switch {
+	case errors.Is(err, migration.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "migration not found",
+		})
+		return
+	case errors.Is(err, migration.ErrRunning):
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "the migration is still running",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "Migration released; the tenant accepts writes.",
+	})
+}
+
+// migrationsEnabled writes an error response when no migration target is
+// configured.
+func (h *Handler) migrationsEnabled(w http.ResponseWriter) bool {
+	if h.migrations == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{
+			"error": "no migration target configured",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// NamedListRequest is the request body for PUT /refdata/lists/{name}.
+type NamedListRequest struct {
+	Description string   `json:"description,omitempty"`
+	Values      []string `json:"values"`
+}
+
+// NamedListSummary describes a named list without its values.
+type NamedListSummary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Size        int       `json:"size"`
+	UpdatedBy   string    `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// normalizeListValues trims the values and drops blanks and duplicates,
+// keeping the first occurrence's position.
+func normalizeListValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// ListNamedLists returns the tenant's named lists by name, without their values.
+func (h *Handler) ListNamedLists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	lists, err := h.repo.ListNamedLists(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to list named lists", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list named lists",
+		})
+		return
+	}
+
+	summaries := make([]NamedListSummary, 0, len(lists))
+	for _, list := range lists {
+		summaries = append(summaries, NamedListSummary{
+			Name:        list.Name,
+			Description: list.Description,
+			Size:        len(list.Values),
+			UpdatedBy:   list.UpdatedBy,
+			UpdatedAt:   list.UpdatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"lists": summaries,
+		"count": len(summaries),
+	})
+}
+
+// GetNamedList returns one of the tenant's named lists with its values.
+func (h *Handler) GetNamedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	name := chi.URLParam(r, "name")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	list, err := h.repo.GetNamedList(ctx, tenantID, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "named list not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get named list", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get named list",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// PutNamedList creates or replaces one of the tenant's named lists and
+// reloads the engine's lists, so rules see it on the next evaluation.
+func (h *Handler) PutNamedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	name := chi.URLParam(r, "name")
+
+	var req NamedListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	if !domain.ValidNamedListName(name) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "name must be 1 to 64 lower-case letters, digits, underscores or hyphens",
+		})
+		return
+	}
+	values := normalizeListValues(req.Values)
+	if len(values) > domain.MaxNamedListValues {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("a list holds at most %d values", domain.MaxNamedListValues),
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.repo.GetNamedList(ctx, tenantID, name)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		slog.Error("failed to get named list", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save named list",
+		})
+		return
+	}
+
+	list := &domain.NamedList{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Values:      values,
+		UpdatedBy:   GetRequestContext(ctx).Principal,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := h.repo.SaveNamedList(ctx, tenantID, list); err != nil {
+		slog.Error("failed to save named list", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save named list",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditNamedList, name, previous, list)
+	slog.Info("named list saved", "name", name, "size", len(values), "tenant_id", tenantID)
+	if _, err := h.reloadLists(ctx); err != nil {
+		slog.Error("failed to reload named lists after save", "error", err)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"list":    list,
+			"message": "Named list saved, but the engine reload failed. Call POST /rules/reload to apply changes.",
+		})
+		return
+	}
+	h.cluster.Notify(ctx, tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"list":    list,
+		"message": "Named list saved and engine reloaded.",
+	})
+}
+
+// DeleteNamedList deletes one of the tenant's named lists and reloads the
+// engine's lists; rules then see it as empty.
+func (h *Handler) DeleteNamedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	name := chi.URLParam(r, "name")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	existing, err := h.repo.GetNamedList(ctx, tenantID, name)
+	if err == nil {
+		err = h.repo.DeleteNamedList(ctx, tenantID, name)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "named list not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete named list", "name", name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete named list",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditNamedList, name, existing, nil)
+	slog.Info("named list deleted", "name", name, "tenant_id", tenantID)
+	message := "Named list deleted and engine reloaded."
+	if _, err := h.reloadLists(ctx); err != nil {
+		slog.Error("failed to reload named lists after delete", "error", err)
+		message = "Named list deleted, but the engine reload failed. Call POST /rules/reload to apply changes."
+	} else {
+		h.cluster.Notify(ctx, tenantID)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+	})
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// peerAddrKey holds the TCP peer address before middleware.RealIP rewrites
+// RemoteAddr from client-supplied forwarding headers.
+const peerAddrKey contextKey = "peerAddr"
+
+// AdminNetworks restricts management endpoints to a set of networks.
+// An empty set allows every address.
+type AdminNetworks struct {
+	prefixes   []netip.Prefix
+	trustProxy bool
+}
+
+// ParseAdminNetworks parses CIDRs (IPv4 or IPv6). A bare address is treated as
+// a single-host network. With trustProxy, the client IP from X-Forwarded-For /
+// X-Real-IP is checked instead of the TCP peer; only enable it behind a proxy
+// that overwrites those headers.
+func ParseAdminNetworks(cidrs []string, trustProxy bool) (*AdminNetworks, error) {
+	an := &AdminNetworks{trustProxy: trustProxy}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid admin network %q: %w", cidr, err)
+			}
+			an.prefixes = append(an.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin network %q: %w", cidr, err)
+		}
+		an.prefixes = append(an.prefixes, prefix.Masked())
+	}
+	return an, nil
+}
+
+// WithAdminNetworks restricts management endpoints to the given networks.
+func WithAdminNetworks(an *AdminNetworks) Option {
+	return func(h *Handler) {
+		h.adminNetworks = an
+	}
+}
+
+// Enabled reports whether any restriction is configured.
+func (an *AdminNetworks) Enabled() bool {
+	return an != nil && len(an.prefixes) > 0
+}
+
+// Allowed reports whether ip falls in an admin network.
+func (an *AdminNetworks) Allowed(ip string) bool {
+	if !an.Enabled() {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap() // Match IPv4-mapped IPv6 peers against IPv4 networks
+
+	for _, prefix := range an.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects requests from outside the admin networks with 403 and
+// logs each rejection for audit.
+func (an *AdminNetworks) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !an.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rc := GetRequestContext(r.Context())
+		ip := an.clientIP(r)
+		if !an.Allowed(ip) {
+			attrs := []any{
+				"audit", true,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"source_ip", ip,
+			}
+			slog.Warn("management request rejected by admin network allowlist", append(attrs, rc.LogAttrs()...)...)
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error": "management endpoints are not available from this network",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP checked against the admin networks.
+func (an *AdminNetworks) clientIP(r *http.Request) string {
+	if an.trustProxy {
+		return GetRequestContext(r.Context()).ClientIP
+	}
+	return peerIP(r)
+}
+
+// WithGlobalTenantWrites lets any network write rules and typologies for the
+// global "*" tenant. Without it only admin networks may, once configured.
+func WithGlobalTenantWrites(allow bool) Option {
+	return func(h *Handler) {
+		h.globalWrites = allow
+	}
+}
+
+// GlobalTenantWrites rejects writes for the global "*" tenant, whose rules
+// and typologies apply to every tenant, unless WithGlobalTenantWrites is set
+// or they come from an admin network. Reads still work.
+func (h *Handler) GlobalTenantWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if GetTenantID(r.Context()) == rules.GlobalTenantID && !h.globalWrites &&
+				!(h.adminNetworks.Enabled() && h.adminNetworks.Allowed(h.adminNetworks.clientIP(r))) {
+				rc := GetRequestContext(r.Context())
+				attrs := []any{
+					"audit", true,
+					"method", r.Method,
+					"path", r.URL.Path,
+				}
+				slog.Warn("global tenant write rejected", append(attrs, rc.LogAttrs()...)...)
+				writeJSON(w, http.StatusForbidden, map[string]string{
+					"error": "writes for the global tenant \"*\" require an admin network",
+				})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PeerAddrMiddleware records the TCP peer address. It must run before
+// middleware.RealIP.
+func PeerAddrMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), peerAddrKey, r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// peerIP returns the TCP peer IP, falling back to RemoteAddr when
+// PeerAddrMiddleware did not run.
+func peerIP(r *http.Request) string {
+	addr, ok := r.Context().Value(peerAddrKey).(string)
+	if !ok {
+		addr = r.RemoteAddr
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,602 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/auditlog"
+	"github.com/opensource-finance/osprey/internal/corridor"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/features"
+	"github.com/opensource-finance/osprey/internal/gitsync"
+	"github.com/opensource-finance/osprey/internal/migration"
+	"github.com/opensource-finance/osprey/internal/retention"
+	"github.com/opensource-finance/osprey/internal/review"
+	"github.com/opensource-finance/osprey/internal/ruledir"
+	"github.com/opensource-finance/osprey/internal/rulepacks"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/signing"
+	"github.com/opensource-finance/osprey/internal/slo"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/stats"
+	"github.com/opensource-finance/osprey/internal/txtypes"
+)
+
+// operation documents a route for GET /openapi.json. Request and response
+// are values of the JSON bodies' Go types; their schemas are derived from
+// the types, so they follow the code.
+type operation struct {
+	summary     string
+	request     any    // request body, nil for none
+	requestType string // media type of the request body, default application/json
+	status      int    // success status, default 200
+	response    any    // success body, nil for none
+	contentType string // media type of the success body, default application/json
+}
+
+// messageResponse is the body of endpoints that only confirm an action.
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// operations documents every route of the API, keyed by method and chi
+// pattern. A route without an entry fails the tests.
+var operations = map[string]operation{
+	// Health and info
+	"GET /health":                {summary: "Health status of each dependency, with the async queue when a worker runs", response: HealthResponse{}},
+	"GET /ready":                 {summary: "Readiness status: 503 while draining or without the rules or typologies the mode needs", response: map[string]string{}},
+	"GET /info":                  {summary: "Build and configuration details", response: InfoResponse{}},
+	"GET /.well-known/jwks.json": {summary: "Public key that verifies X-JWS-Signature", response: signing.JWKS{}},
+	"GET /metrics":               {summary: "Queue and SLO metrics in the Prometheus text format", contentType: "text/plain"},
+	"POST /drain":                {summary: "Fail readiness ahead of a shutdown, while still serving requests", response: messageResponse{}},
+	"GET /openapi.json":          {summary: "This OpenAPI document", response: map[string]any{}},
+	"GET /slo": {summary: "Error budgets and burn rates of the service level objectives", response: struct {
+		Objectives []slo.Report `json:"objectives"`
+		Count      int          `json:"count"`
+		Window     string       `json:"window"`
+		Since      time.Time    `json:"since"`
+	}{}},
+	"GET /stats/top": {summary: "Tenants and debtors with the most evaluations and alerts, across tenants", response: stats.TopReport{}},
+
+	// Operators
+	"GET /admin/tenants/health":           {summary: "Per-tenant summary for operators", response: listOf("tenants", TenantHealth{})},
+	"GET /admin/indexes":                  {summary: "Indexes the velocity and alert queries are missing", response: domain.IndexReport{}},
+	"POST /admin/indexes":                 {summary: "Create the recommended or named indexes", request: CreateIndexesRequest{}, response: domain.IndexReport{}},
+	"GET /admin/isolation":                {summary: "Audit cross-tenant references", response: domain.IsolationReport{}},
+	"POST /admin/isolation":               {summary: "Repair cross-tenant references and return the audit", response: domain.IsolationReport{}},
+	"GET /admin/migrations":               {summary: "Each tenant's latest migration on this instance", response: listOf("migrations", migration.Migration{})},
+	"POST /admin/migrations":              {summary: "Copy a tenant to the migration target", request: MigrationRequest{}, status: http.StatusAccepted, response: migration.Migration{}},
+	"GET /admin/migrations/{tenantId}":    {summary: "A migration's status and consistency report", response: migration.Migration{}},
+	"DELETE /admin/migrations/{tenantId}": {summary: "Lift a cutover freeze and forget the migration", response: messageResponse{}},
+	"GET /admin/retention/runs":           {summary: "The latest retention runs on this instance, newest first", response: listOf("runs", retention.Run{})},
+	"POST /admin/retention/runs":          {summary: "Expire activity by the retention policies now", request: RetentionRunRequest{}, status: http.StatusAccepted, response: retention.Run{}},
+	"GET /admin/retention/runs/{id}":      {summary: "A retention run with the rows expired per tenant and class", response: retention.Run{}},
+
+	// Evaluation
+	"POST /evaluate": {summary: "Evaluate a transaction (?async=true queues it and answers 202; ?explain=true adds the breakdown)", request: TransactionRequest{}, response: EvaluateResponse{}},
+	"GET /transaction-types": {summary: "Allowed and unknown transaction types", response: struct {
+		Allowed []string              `json:"allowed"`
+		Unknown []txtypes.UnknownType `json:"unknown"`
+		Action  string                `json:"action"`
+	}{}},
+	"GET /evaluations": {summary: "Search evaluations, latest first", response: struct {
+		Evaluations []domain.Evaluation `json:"evaluations"`
+		Count       int                 `json:"count"`
+		NextCursor  string              `json:"nextCursor,omitempty"`
+	}{}},
+	"GET /evaluations/{id}":             {summary: "Get an evaluation", response: domain.Evaluation{}},
+	"GET /evaluations/{id}/explain":     {summary: "Why an evaluation was decided", response: EvaluationExplanation{}},
+	"GET /evaluations/{id}/outcomes":    {summary: "Outcomes reported for an evaluation", response: listOf("outcomes", domain.EvaluationOutcome{})},
+	"POST /evaluations/{id}/outcome":    {summary: "Report a challenge result, return or chargeback", request: ReportOutcomeRequest{}, status: http.StatusCreated, response: domain.EvaluationOutcome{}},
+	"GET /outcomes":                     {summary: "Reported outcomes, latest first", response: listOf("outcomes", domain.EvaluationOutcome{})},
+	"GET /outcomes/losses":              {summary: "Losses by rule and typology", response: domain.LossReport{}},
+	"GET /stats/score-distribution":     {summary: "Histograms of evaluation and typology scores", response: stats.Report{}},
+	"GET /stats/benchmark":              {summary: "Compare the tenant with opted-in tenants of similar volume", response: stats.BenchmarkReport{}},
+	"GET /transactions/{id}":            {summary: "Get a transaction", response: domain.Transaction{}},
+	"GET /entities/{id}/counterparties": {summary: "An entity's counterparty network edges", response: listOf("counterparties", domain.CounterpartyEdge{})},
+	"GET /entities/{id}/transactions": {summary: "An entity's transactions as debtor or creditor", response: struct {
+		Transactions []domain.Transaction `json:"transactions"`
+		Count        int                  `json:"count"`
+		NextOffset   int                  `json:"nextOffset,omitempty"`
+	}{}},
+	"POST /outcomes/import": {summary: "Import chargebacks and returns by transaction", request: ImportOutcomesRequest{}, response: struct {
+		Results  []domain.OutcomeImportResult `json:"results"`
+		Imported int                          `json:"imported"`
+		Rejected int                          `json:"rejected"`
+	}{}},
+
+	// Rules
+	"GET /rules": {summary: "The loaded rules that apply to the tenant", response: struct {
+		Rules  []domain.RuleConfig `json:"rules"`
+		Count  int                 `json:"count"`
+		Source string              `json:"source"`
+	}{}},
+	"GET /rules/docs":         {summary: "Loaded rules and typologies as a Markdown or HTML document for audits", contentType: "text/markdown"},
+	"GET /rules/{id}":         {summary: "Get a loaded rule", response: domain.RuleConfig{}},
+	"GET /rules/{id}/samples": {summary: "Sampled activations of a rule, newest first", response: listOf("samples", domain.ActivationSample{})},
+	"POST /rules": {summary: "Create a rule (applies on reload)", request: CreateRuleRequest{}, status: http.StatusCreated, response: struct {
+		Rule    domain.RuleConfig `json:"rule"`
+		Message string            `json:"message"`
+	}{}},
+	"PUT /rules/{id}": {summary: "Update a rule and reload", request: CreateRuleRequest{}, response: struct {
+		Rule    domain.RuleConfig `json:"rule"`
+		Message string            `json:"message"`
+		Changes *rules.RuleDiff   `json:"changes,omitempty"`
+	}{}},
+	"DELETE /rules/{id}": {summary: "Delete a rule and reload", response: struct {
+		Message string          `json:"message"`
+		Changes *rules.RuleDiff `json:"changes,omitempty"`
+	}{}},
+	"POST /rules/reload": {summary: "Reload every tenant's rules and named lists", response: struct {
+		Message string         `json:"message"`
+		Count   int            `json:"count"`
+		Changes rules.RuleDiff `json:"changes"`
+		Lists   int            `json:"lists"`
+	}{}},
+	"POST /rules/backtest":  {summary: "Replay stored transactions through a candidate rule", request: BacktestRuleRequest{}, response: domain.BacktestReport{}},
+	"POST /rules/{id}/test": {summary: "Score a rule or ad-hoc expression against a sample transaction", request: TestRuleRequest{}, response: TestRuleResponse{}},
+
+	// Typologies
+	"GET /typologies": {summary: "The loaded typologies that apply to the tenant", response: struct {
+		Typologies []domain.Typology `json:"typologies"`
+		Count      int               `json:"count"`
+		Source     string            `json:"source"`
+	}{}},
+	"GET /typologies/{id}": {summary: "Get a loaded typology", response: domain.Typology{}},
+	"POST /typologies": {summary: "Create a typology (applies on reload)", request: CreateTypologyRequest{}, status: http.StatusCreated, response: struct {
+		Typology domain.Typology `json:"typology"`
+		Message  string          `json:"message"`
+	}{}},
+	"PUT /typologies/{id}": {summary: "Update a typology (applies on reload)", request: CreateTypologyRequest{}, response: struct {
+		Typology domain.Typology `json:"typology"`
+		Message  string          `json:"message"`
+	}{}},
+	"DELETE /typologies/{id}": {summary: "Delete a typology and reload", response: messageResponse{}},
+	"POST /typologies/reload": {summary: "Reload typologies from the database", response: struct {
+		Message string `json:"message"`
+		Count   int    `json:"count"`
+	}{}},
+
+	// Rule packs
+	"GET /rulepacks":          {summary: "List the built-in rule packs", response: listOf("packs", RulePackSummary{})},
+	"GET /rulepacks/export":   {summary: "The tenant's rules and typologies as a rule pack (?format=yaml for YAML)", response: rulepacks.Pack{}},
+	"GET /rulepacks/{name}":   {summary: "A built-in rule pack (?format=yaml for YAML)", response: rulepacks.Pack{}},
+	"POST /rulepacks/import":  {summary: "Import a JSON or YAML rule pack into the tenant (?dryRun=true only plans it)", request: rulepacks.Pack{}, response: RulePackResult{}},
+	"POST /rulepacks/install": {summary: "Install a built-in rule pack into the tenant (?dryRun=true only plans it)", request: InstallRulePackRequest{}, response: RulePackResult{}},
+
+	// Parties and customers
+	"GET /parties/{id}":      {summary: "A party's KYC profile", response: domain.PartyKYC{}},
+	"PUT /parties/{id}":      {summary: "Create or replace a party's KYC profile", request: UpsertPartyRequest{}, response: domain.PartyKYC{}},
+	"DELETE /parties/{id}":   {summary: "Delete a party's KYC profile", response: messageResponse{}},
+	"GET /customers":         {summary: "Customer profiles sorted by entity ID", response: listOf("customers", domain.PartyKYC{})},
+	"POST /customers":        {summary: "Create a customer profile", request: CreateCustomerRequest{}, status: http.StatusCreated, response: domain.PartyKYC{}},
+	"GET /customers/{id}":    {summary: "A customer's KYC profile", response: domain.PartyKYC{}},
+	"PUT /customers/{id}":    {summary: "Create or replace a customer's KYC profile", request: UpsertPartyRequest{}, response: domain.PartyKYC{}},
+	"DELETE /customers/{id}": {summary: "Delete a customer's KYC profile", response: messageResponse{}},
+
+	// Account balances
+	"GET /balances/{accountId}": {summary: "An account's tracked balance", response: domain.AccountBalance{}},
+	"PUT /balances/{accountId}": {summary: "Set an account's balance from the caller's ledger", request: SetBalanceRequest{}, response: domain.AccountBalance{}},
+
+	// Reference data
+	"GET /refdata/corridors": {summary: "Corridor risk overrides and the FATF defaults", response: struct {
+		Corridors []domain.CorridorRisk `json:"corridors"`
+		Count     int                   `json:"count"`
+		Defaults  struct {
+			BlackList     []string `json:"blacklist"`
+			BlackListRisk float64  `json:"blacklistRisk"`
+			GreyList      []string `json:"greylist"`
+			GreyListRisk  float64  `json:"greylistRisk"`
+		} `json:"defaults"`
+	}{}},
+	"GET /refdata/corridors/{origin}/{destination}":    {summary: "A corridor's effective risk score", response: corridor.Score{}},
+	"PUT /refdata/corridors/{origin}/{destination}":    {summary: "Override a corridor's risk score", request: UpsertCorridorRequest{}, response: domain.CorridorRisk{}},
+	"DELETE /refdata/corridors/{origin}/{destination}": {summary: "Delete a corridor override", response: messageResponse{}},
+	"GET /refdata/lists":                               {summary: "The tenant's named lists", response: listOf("lists", NamedListSummary{})},
+	"GET /refdata/lists/{name}":                        {summary: "Get a named list", response: domain.NamedList{}},
+	"PUT /refdata/lists/{name}": {summary: "Create or replace a named list and reload", request: NamedListRequest{}, response: struct {
+		List    domain.NamedList `json:"list"`
+		Message string           `json:"message"`
+	}{}},
+	"DELETE /refdata/lists/{name}": {summary: "Delete a named list and reload", response: messageResponse{}},
+
+	// Watchlists
+	"GET /lists":         {summary: "Watchlist entries", response: listOf("entries", domain.WatchlistEntry{})},
+	"GET /lists/{id}":    {summary: "Get a watchlist entry", response: domain.WatchlistEntry{}},
+	"POST /lists":        {summary: "Add a watchlist entry", request: WatchlistEntryRequest{}, status: http.StatusCreated, response: domain.WatchlistEntry{}},
+	"PUT /lists/{id}":    {summary: "Replace a watchlist entry", request: WatchlistEntryRequest{}, response: domain.WatchlistEntry{}},
+	"DELETE /lists/{id}": {summary: "Delete a watchlist entry", response: messageResponse{}},
+
+	// Background jobs
+	"GET /jobs":              {summary: "The tenant's jobs, latest first", response: listOf("jobs", domain.Job{})},
+	"POST /jobs/batch":       {summary: "Evaluate a CSV file of transactions in the background", request: "", requestType: "text/csv", status: http.StatusAccepted, response: domain.Job{}},
+	"POST /jobs/reevaluate":  {summary: "Re-evaluate stored transactions, throttled", request: ReevaluateJobRequest{}, status: http.StatusAccepted, response: domain.Job{}},
+	"GET /jobs/{id}":         {summary: "Get a job and its progress", response: domain.Job{}},
+	"GET /jobs/{id}/results": {summary: "A completed job's results", contentType: "text/csv"},
+	"POST /jobs/{id}/pause":  {summary: "Pause a job", response: messageResponse{}},
+	"POST /jobs/{id}/resume": {summary: "Resume a paused job", response: messageResponse{}},
+	"POST /jobs/{id}/cancel": {summary: "Cancel a job", response: messageResponse{}},
+
+	// Configuration
+	"GET /config/scoring":      {summary: "The tenant's scoring config", response: ScoringConfigResponse{}},
+	"PUT /config/scoring":      {summary: "Set the tenant's scoring config", request: ScoringConfigRequest{}, response: ScoringConfigResponse{}},
+	"DELETE /config/scoring":   {summary: "Delete the tenant's scoring config", response: messageResponse{}},
+	"GET /config/retention":    {summary: "The tenant's retention policy", response: RetentionPolicyResponse{}},
+	"PUT /config/retention":    {summary: "Set the tenant's retention policy", request: RetentionPolicyRequest{}, response: RetentionPolicyResponse{}},
+	"DELETE /config/retention": {summary: "Delete the tenant's retention policy", response: messageResponse{}},
+	"GET /features":            {summary: "Feature flags and their state for the tenant", response: listOf("features", features.State{})},
+	"PUT /features/{name}": {summary: "Override a feature flag", request: SetFeatureRequest{}, response: struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Global  bool   `json:"global"`
+	}{}},
+	"DELETE /features/{name}": {summary: "Delete a feature flag override", response: messageResponse{}},
+	"GET /state":              {summary: "The rules and typologies as a declarative spec", response: state.Spec{}},
+	"PUT /state": {summary: "Apply a declarative spec (?dryRun=true only plans it)", request: state.Spec{}, response: struct {
+		Plan    state.Plan `json:"plan"`
+		Applied bool       `json:"applied"`
+	}{}},
+	"GET /gitsync":          {summary: "Git sync status", response: gitsync.Status{}},
+	"POST /gitsync/sync":    {summary: "Sync rules and typologies from Git now", response: gitsync.Status{}},
+	"POST /gitsync/webhook": {summary: "Git push webhook, authenticated by signature", request: map[string]any{}, status: http.StatusAccepted, response: messageResponse{}},
+	"GET /ruledir":          {summary: "Rule directory status", response: ruledir.Status{}},
+	"POST /ruledir/sync":    {summary: "Apply the rule directory now if it changed", response: ruledir.Status{}},
+
+	// Audit and alerts
+	"GET /audit/evaluations/verify": {summary: "Verify the tenant's evaluation log chain", response: auditlog.VerifyResult{}},
+	"GET /audit":                    {summary: "List the tenant's configuration changes", response: listOf("entries", domain.AuditEntry{})},
+	"GET /audit/verify":             {summary: "Verify the tenant's configuration audit log chain", response: auditlog.VerifyResult{}},
+	"GET /audit/{seq}":              {summary: "Get one configuration change", response: domain.AuditEntry{}},
+	"GET /alerts":                   {summary: "The tenant's alerts", response: listOf("alerts", domain.Alert{})},
+	"GET /alerts/{id}":              {summary: "Get an alert", response: domain.Alert{}},
+	"PATCH /alerts/{id}":            {summary: "Update an alert's case status, assignee or notes", request: UpdateAlertRequest{}, response: domain.Alert{}},
+	"POST /alerts/{id}/ack":         {summary: "Acknowledge an alert", request: AckAlertRequest{}, response: domain.Alert{}},
+	"GET /stream/alerts":            {summary: "Server-sent events with each ALRT evaluation of the tenant as it is decided", contentType: "text/event-stream"},
+
+	// Review queue
+	"GET /review-queue":               {summary: "Held transactions, unacked alerts and open cases, most urgent first", response: listOf("items", review.Item{})},
+	"POST /review-queue/{id}/claim":   {summary: "Claim a review item", request: ClaimReviewRequest{}, response: domain.ReviewClaim{}},
+	"POST /review-queue/{id}/release": {summary: "Release a claimed review item", request: ClaimReviewRequest{}, response: messageResponse{}},
+
+	// Webhooks and dead letters
+	"GET /webhooks":                 {summary: "The tenant's webhooks, without secrets", response: listOf("webhooks", domain.Webhook{})},
+	"POST /webhooks":                {summary: "Register a webhook", request: CreateWebhookRequest{}, status: http.StatusCreated, response: domain.Webhook{}},
+	"DELETE /webhooks/{id}":         {summary: "Delete a webhook", response: messageResponse{}},
+	"GET /webhooks/{id}/deliveries": {summary: "A webhook's deliveries, latest first", response: listOf("deliveries", domain.WebhookDelivery{})},
+	"GET /dlq":                      {summary: "Dead-lettered messages", response: listOf("deadLetters", domain.DeadLetter{})},
+	"GET /dlq/{id}":                 {summary: "Get a dead letter", response: domain.DeadLetter{}},
+	"POST /dlq/{id}/replay":         {summary: "Put a dead letter back on its topic", status: http.StatusAccepted, response: domain.DeadLetter{}},
+
+	// Maintenance windows
+	"GET /maintenance":         {summary: "The tenant's maintenance windows", response: listOf("windows", MaintenanceWindowResponse{})},
+	"POST /maintenance":        {summary: "Schedule a maintenance window", request: CreateMaintenanceWindowRequest{}, status: http.StatusCreated, response: MaintenanceWindowResponse{}},
+	"DELETE /maintenance/{id}": {summary: "Cancel a maintenance window", request: CancelMaintenanceWindowRequest{}, response: MaintenanceWindowResponse{}},
+}
+
+// listOf is the type of a list response: the items under key and their
+// count.
+func listOf(key string, item any) any {
+	return reflect.New(reflect.StructOf([]reflect.StructField{
+		{Name: "Items", Type: reflect.SliceOf(reflect.TypeOf(item)), Tag: reflect.StructTag(`json:"` + key + `"`)},
+		{Name: "Count", Type: reflect.TypeOf(0), Tag: `json:"count"`},
+	})).Elem().Interface()
+}
+
+// OpenAPI serves the OpenAPI 3 document of the API.
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	if h.openAPI == nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "OpenAPI document is not available",
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.openAPI)
+}
+
+// routeParam matches the path parameters of a chi pattern.
+var routeParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// buildOpenAPI describes the routes of router with their operations.
+func buildOpenAPI(router chi.Routes, h *Handler) ([]byte, error) {
+	tenant := reflect.ValueOf(TenantMiddleware).Pointer()
+	admin := reflect.ValueOf(h.adminNetworks.Middleware).Pointer()
+
+	schemas := newSchemaBuilder()
+	paths := map[string]map[string]any{}
+	err := chi.Walk(router, func(method, route string, _ http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = cleanRoute(route)
+		op, ok := operations[method+" "+route]
+		if !ok {
+			return fmt.Errorf("route %s %s is not documented", method, route)
+		}
+
+		var tenantScoped, adminOnly bool
+		for _, mw := range middlewares {
+			switch reflect.ValueOf(mw).Pointer() {
+			case tenant:
+				tenantScoped = true
+			case admin:
+				adminOnly = true
+			}
+		}
+
+		var parameters []any
+		if tenantScoped {
+			parameters = append(parameters, map[string]any{"$ref": "#/components/parameters/TenantID"})
+		}
+		for _, m := range routeParam.FindAllStringSubmatch(route, -1) {
+			parameters = append(parameters, map[string]any{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+
+		description := ""
+		if adminOnly {
+			description = "Limited to the admin networks."
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": http.StatusText(status)}
+		if op.response != nil || op.contentType != "" {
+			success["content"] = mediaType(op.contentType, op.response, schemas)
+		}
+
+		doc := map[string]any{
+			"summary":     op.summary,
+			"operationId": operationID(method, route),
+			"tags":        []string{tag(route)},
+			"responses": map[string]any{
+				fmt.Sprint(status): success,
+				"default":          map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		}
+		if description != "" {
+			doc["description"] = description
+		}
+		if parameters != nil {
+			doc["parameters"] = parameters
+		}
+		if op.request != nil {
+			doc["requestBody"] = map[string]any{
+				"required": true,
+				"content":  mediaType(op.requestType, op.request, schemas),
+			}
+		}
+
+		openAPIPath := routeParam.ReplaceAllString(route, "{$1}")
+		if paths[openAPIPath] == nil {
+			paths[openAPIPath] = map[string]any{}
+		}
+		paths[openAPIPath][strings.ToLower(method)] = doc
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schemas.components["Error"] = map[string]any{
+		"type":       "object",
+		"required":   []string{"error"},
+		"properties": map[string]any{"error": map[string]any{"type": "string"}},
+	}
+	return json.Marshal(map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Osprey",
+			"description": "Real-time transaction monitoring and fraud detection.",
+			"version":     h.version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"parameters": map[string]any{
+				"TenantID": map[string]any{
+					"name":     TenantIDHeader,
+					"in":       "header",
+					"required": true,
+					"schema":   map[string]any{"type": "string"},
+				},
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+					},
+				},
+			},
+		},
+	})
+}
+
+// cleanRoute turns a walked chi route into its pattern: the tenant routes
+// are mounted on "/", which chi walks as "//" and "/*/".
+func cleanRoute(route string) string {
+	route = strings.ReplaceAll(route, "/*/", "/")
+	if route != "/" {
+		route = strings.TrimSuffix(path.Clean(route), "/")
+	}
+	return route
+}
+
+// mediaType describes a body of the given media type, default JSON.
+func mediaType(contentType string, body any, schemas *schemaBuilder) map[string]any {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	schema := map[string]any{"type": "string"}
+	if contentType == "application/json" {
+		schema = schemas.schema(reflect.TypeOf(body))
+	}
+	return map[string]any{contentType: map[string]any{"schema": schema}}
+}
+
+// operationID names an operation after its method and path, e.g.
+// getEvaluationsIdExplain.
+func operationID(method, route string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(route, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// tag groups an operation by the first segment of its path.
+func tag(route string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+	return strings.TrimPrefix(segment, ".")
+}
+
+// schemaBuilder derives JSON schemas from Go types. Named struct types
+// become components referenced by name.
+type schemaBuilder struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: map[string]any{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawType      = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	case rawType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.componentName(t)
+			b.names[t] = name
+			b.components[name] = map[string]any{} // placeholder for recursive types
+			b.components[name] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// componentName names a struct type: api and domain types by their own
+// name, others prefixed with their package unless the name starts with it.
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	pkg := path.Base(t.PkgPath())
+	if pkg != "api" && pkg != "domain" {
+		prefix := strings.ToUpper(pkg[:1]) + pkg[1:]
+		if !strings.HasPrefix(name, prefix) {
+			name = prefix + name
+		}
+	}
+	for taken := name; ; taken += "_" {
+		if _, ok := b.components[taken]; !ok {
+			return taken
+		}
+	}
+}
+
+// object describes a struct's JSON fields, embedded structs included.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	b.fields(t, properties, &required)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.fields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Limits of the limit query parameter of GET /outcomes.
+const (
+	defaultListOutcomesLimit = 100
+	maxListOutcomesLimit     = 1000
+)
+
+// maxImportOutcomeEvents caps the events of one POST /outcomes/import.
+const maxImportOutcomeEvents = 1000
+
+// defaultLossReportWindow is the period of GET /outcomes/losses without since.
+const defaultLossReportWindow = 30 * 24 * time.Hour
+
+// ReportOutcomeRequest is the request body for POST /evaluations/{id}/outcome.
+type ReportOutcomeRequest struct {
+	Outcome    string         `json:"outcome"`
+	Reason     string         `json:"reason,omitempty"`
+	Amount     domain.Decimal `json:"amount,omitzero"`
+	OccurredAt time.Time      `json:"occurredAt,omitempty"` // defaults to now
+}
+
+// ReportOutcome records what happened after an evaluation's decision: the
+// result of a step-up challenge, a returned payment or a chargeback. An
+// evaluation may have several outcomes.
+func (h *Handler) ReportOutcome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	evalID := chi.URLParam(r, "id")
+
+	var req ReportOutcomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if !domain.ValidOutcomeType(req.Outcome) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "outcome must be one of: " + strings.Join(domain.OutcomeTypes, ", "),
+		})
+		return
+	}
+	if req.Amount.Sign() < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "amount cannot be negative",
+		})
+		return
+	}
+	now := time.Now().UTC()
+	if req.OccurredAt.After(now) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "occurredAt cannot be in the future",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	eval, err := h.repo.GetEvaluation(ctx, tenantID, evalID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "evaluation not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get evaluation", "id", evalID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get evaluation",
+		})
+		return
+	}
+
+	outcome := &domain.EvaluationOutcome{
+		ID:           uuid.New().String(),
+		TenantID:     tenantID,
+		EvaluationID: eval.ID,
+		TxID:         eval.TxID,
+		Outcome:      req.Outcome,
+		Reason:       req.Reason,
+		Label:        domain.OutcomeLabel(req.Outcome, eval.Status),
+		Amount:       req.Amount,
+		ReportedBy:   GetRequestContext(ctx).Principal,
+		OccurredAt:   req.OccurredAt.UTC(),
+		CreatedAt:    now,
+	}
+	if req.OccurredAt.IsZero() {
+		outcome.OccurredAt = now
+	}
+
+	// The parties are copied for per-party lookups; transactions that were
+	// not stored leave them empty
+	if tx, err := h.repo.GetTransaction(ctx, tenantID, eval.TxID); err == nil {
+		outcome.DebtorID = tx.DebtorID
+		outcome.CreditorID = tx.CreditorID
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		slog.Warn("failed to get transaction for outcome", "tx_id", eval.TxID, "error", err)
+	}
+
+	if err := h.repo.SaveOutcome(ctx, tenantID, outcome); err != nil {
+		slog.Error("failed to save outcome", "evaluation_id", evalID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save outcome",
+		})
+		return
+	}
+
+	slog.Info("evaluation outcome reported", "evaluation_id", evalID, "outcome", outcome.Outcome, "tenant_id", tenantID)
+	writeJSON(w, http.StatusCreated, outcome)
+}
+
+// ListEvaluationOutcomes returns the outcomes reported for an evaluation,
+// latest first.
+func (h *Handler) ListEvaluationOutcomes(w http.ResponseWriter, r *http.Request) {
+	h.listOutcomes(w, r, domain.OutcomeFilter{EvaluationID: chi.URLParam(r, "id")})
+}
+
+// ListOutcomes returns the tenant's reported outcomes, latest first.
+// Query params: party (debtor or creditor), outcome, label, since (RFC 3339),
+// limit (default 100).
+func (h *Handler) ListOutcomes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.OutcomeFilter{
+		PartyID: query.Get("party"),
+		Outcome: query.Get("outcome"),
+		Label:   query.Get("label"),
+	}
+	if filter.Outcome != "" && !domain.ValidOutcomeType(filter.Outcome) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "outcome must be one of: " + strings.Join(domain.OutcomeTypes, ", "),
+		})
+		return
+	}
+	if filter.Label != "" && filter.Label != domain.OutcomeLabelCaught && filter.Label != domain.OutcomeLabelMissed {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "label must be one of: " + domain.OutcomeLabelCaught + ", " + domain.OutcomeLabelMissed,
+		})
+		return
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		filter.Since = since
+	}
+	h.listOutcomes(w, r, filter)
+}
+
+// listOutcomes applies the limit query parameter to filter and writes the
+// matching outcomes.
+func (h *Handler) listOutcomes(w http.ResponseWriter, r *http.Request, filter domain.OutcomeFilter) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	filter.Limit = defaultListOutcomesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListOutcomesLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	outcomes, err := h.repo.ListOutcomes(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list outcomes", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list outcomes",
+		})
+		return
+	}
+	if outcomes == nil {
+		outcomes = []*domain.EvaluationOutcome{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"outcomes": outcomes,
+		"count":    len(outcomes),
+	})
+}
+
+// ImportOutcomesRequest is the request body for POST /outcomes/import.
+type ImportOutcomesRequest struct {
+	Events []domain.OutcomeEvent `json:"events"`
+}
+
+// ImportOutcomes imports chargebacks and returns from a payment network or
+// processor. Each event names the original transaction and is linked to its
+// latest evaluation; events are imported independently and the response
+// reports each one. Re-importing an event ID is a no-op.
+func (h *Handler) ImportOutcomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req ImportOutcomesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxImportOutcomeEvents {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "events must contain between 1 and 1000 events",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	results, err := h.outcomes.Import(ctx, tenantID, GetRequestContext(ctx).Principal, req.Events)
+	if err != nil {
+		slog.Error("failed to import outcomes", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to import outcomes",
+		})
+		return
+	}
+
+	imported := 0
+	for _, result := range results {
+		if result.Status == domain.OutcomeImportImported {
+			imported++
+		}
+	}
+
+	slog.Info("outcomes imported", "imported", imported, "events", len(results), "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"results":  results,
+		"imported": imported,
+		"rejected": len(results) - imported,
+	})
+}
+
+// OutcomeLosses reports the tenant's chargebacks and returns by the rules
+// and typologies that fired on them. Query param: since (RFC 3339, default
+// 30 days ago).
+func (h *Handler) OutcomeLosses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	since := time.Now().UTC().Add(-defaultLossReportWindow)
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		since = parsed
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	report, err := h.outcomes.Losses(ctx, tenantID, since)
+	if err != nil {
+		slog.Error("failed to report losses", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to report losses",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// UpsertPartyRequest is the request body for PUT /parties/{id} and PUT
+// /customers/{id}.
+type UpsertPartyRequest struct {
+	RiskRating       string    `json:"riskRating"`
+	PEP              bool      `json:"pep"`
+	OnboardedAt      time.Time `json:"onboardedAt,omitempty"`
+	ResidenceCountry string    `json:"residenceCountry,omitempty"`
+	Segment          string    `json:"segment,omitempty"`
+}
+
+// validate returns why the request is invalid, or "" if it is valid.
+func (req *UpsertPartyRequest) validate() string {
+	if !domain.ValidRiskRating(req.RiskRating) {
+		return "riskRating must be one of: low, medium, high"
+	}
+	if req.ResidenceCountry != "" && len(req.ResidenceCountry) != 2 {
+		return "residenceCountry must be a 2-letter ISO country code"
+	}
+	if req.OnboardedAt.After(time.Now()) {
+		return "onboardedAt cannot be in the future"
+	}
+	return ""
+}
+
+// GetParty returns the KYC profile for a party.
+func (h *Handler) GetParty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entityID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	profile, err := h.repo.GetPartyKYC(ctx, tenantID, entityID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "party not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get party", "id", entityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get party",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpsertParty creates or replaces the KYC profile for a party.
+func (h *Handler) UpsertParty(w http.ResponseWriter, r *http.Request) {
+	var req UpsertPartyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+
+	h.saveParty(w, r, chi.URLParam(r, "id"), &req, http.StatusOK)
+}
+
+// DeleteParty deletes the KYC profile for a party. Rules see the party as
+// unknown from the next evaluation.
+func (h *Handler) DeleteParty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entityID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	err := h.repo.DeletePartyKYC(ctx, tenantID, entityID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "party not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete party", "id", entityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete party",
+		})
+		return
+	}
+
+	if err := h.kyc.Invalidate(ctx, tenantID, entityID); err != nil {
+		slog.Warn("failed to invalidate cached party", "id", entityID, "error", err)
+	}
+
+	slog.Info("party kyc deleted", "id", entityID, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Party deleted; rules see it as unknown.",
+	})
+}
+
+// saveParty stores a validated profile and responds with it. The cached
+// profile is invalidated so the next evaluation sees the update.
+func (h *Handler) saveParty(w http.ResponseWriter, r *http.Request, entityID string, req *UpsertPartyRequest, status int) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	profile := &domain.PartyKYC{
+		EntityID:         entityID,
+		TenantID:         tenantID,
+		RiskRating:       req.RiskRating,
+		PEP:              req.PEP,
+		OnboardedAt:      req.OnboardedAt,
+		ResidenceCountry: req.ResidenceCountry,
+		Segment:          req.Segment,
+		UpdatedAt:        time.Now().UTC(),
+	}
+
+	if err := h.repo.SavePartyKYC(ctx, tenantID, profile); err != nil {
+		slog.Error("failed to save party", "id", entityID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save party",
+		})
+		return
+	}
+
+	if err := h.kyc.Invalidate(ctx, tenantID, entityID); err != nil {
+		slog.Warn("failed to invalidate cached party", "id", entityID, "error", err)
+	}
+
+	slog.Info("party kyc updated", "id", entityID, "tenant_id", tenantID)
+	writeJSON(w, status, profile)
+}
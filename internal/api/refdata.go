@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/corridor"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// UpsertCorridorRequest is the request body for PUT /refdata/corridors/{origin}/{destination}.
+type UpsertCorridorRequest struct {
+	Risk float64 `json:"risk"`
+	Note string  `json:"note,omitempty"`
+}
+
+// ListCorridors returns the tenant's corridor overrides and the built-in FATF defaults.
+func (h *Handler) ListCorridors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	overrides, err := h.corridors.Overrides(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to list corridors", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list corridors",
+		})
+		return
+	}
+	if overrides == nil {
+		overrides = []*domain.CorridorRisk{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"corridors": overrides,
+		"count":     len(overrides),
+		"defaults": map[string]interface{}{
+			"blacklist":     sortedCountries(corridor.BlackList),
+			"blacklistRisk": corridor.BlackListRisk,
+			"greylist":      sortedCountries(corridor.GreyList),
+			"greylistRisk":  corridor.GreyListRisk,
+		},
+	})
+}
+
+// GetCorridor returns the effective risk for a corridor, including where it came from.
+func (h *Handler) GetCorridor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	origin := corridor.NormalizeCountry(chi.URLParam(r, "origin"))
+	destination := corridor.NormalizeCountry(chi.URLParam(r, "destination"))
+
+	if !corridor.ValidCountry(origin) || !corridor.ValidCountry(destination) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "origin and destination must be 2-letter ISO country codes or *",
+		})
+		return
+	}
+
+	score, err := h.corridors.Lookup(ctx, tenantID, origin, destination)
+	if err != nil {
+		slog.Error("failed to look up corridor", "origin", origin, "destination", destination, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to look up corridor",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, score)
+}
+
+// UpsertCorridor creates or replaces a corridor risk override.
+func (h *Handler) UpsertCorridor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	origin := corridor.NormalizeCountry(chi.URLParam(r, "origin"))
+	destination := corridor.NormalizeCountry(chi.URLParam(r, "destination"))
+
+	var req UpsertCorridorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	if !corridor.ValidCountry(origin) || !corridor.ValidCountry(destination) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "origin and destination must be 2-letter ISO country codes or *",
+		})
+		return
+	}
+	if origin == domain.CorridorWildcard && destination == domain.CorridorWildcard {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "origin and destination cannot both be *",
+		})
+		return
+	}
+	if req.Risk < 0 || req.Risk > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "risk must be between 0 and 1",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.corridorOverride(ctx, tenantID, origin, destination)
+	if err != nil {
+		slog.Error("failed to list corridors", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save corridor",
+		})
+		return
+	}
+
+	c := &domain.CorridorRisk{
+		TenantID:    tenantID,
+		Origin:      origin,
+		Destination: destination,
+		Risk:        req.Risk,
+		Note:        req.Note,
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	if err := h.repo.SaveCorridorRisk(ctx, tenantID, c); err != nil {
+		slog.Error("failed to save corridor", "origin", origin, "destination", destination, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save corridor",
+		})
+		return
+	}
+
+	if err := h.corridors.Invalidate(ctx, tenantID); err != nil {
+		slog.Warn("failed to invalidate cached corridors", "error", err)
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditCorridor, origin+"-"+destination, previous, c)
+	slog.Info("corridor risk updated", "origin", origin, "destination", destination, "risk", req.Risk, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, c)
+}
+
+// DeleteCorridor removes a corridor risk override, reverting to the defaults.
+func (h *Handler) DeleteCorridor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	origin := corridor.NormalizeCountry(chi.URLParam(r, "origin"))
+	destination := corridor.NormalizeCountry(chi.URLParam(r, "destination"))
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	existing, err := h.corridorOverride(ctx, tenantID, origin, destination)
+	if err == nil {
+		err = h.repo.DeleteCorridorRisk(ctx, tenantID, origin, destination)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "corridor override not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete corridor", "origin", origin, "destination", destination, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete corridor",
+		})
+		return
+	}
+
+	if err := h.corridors.Invalidate(ctx, tenantID); err != nil {
+		slog.Warn("failed to invalidate cached corridors", "error", err)
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditCorridor, origin+"-"+destination, existing, nil)
+	slog.Info("corridor risk deleted", "origin", origin, "destination", destination, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Corridor override deleted; defaults apply.",
+	})
+}
+
+// corridorOverride returns the tenant's stored override of a corridor, or nil
+// if there is none.
+func (h *Handler) corridorOverride(ctx context.Context, tenantID, origin, destination string) (*domain.CorridorRisk, error) {
+	corridors, err := h.repo.ListCorridorRisks(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range corridors {
+		if c.Origin == origin && c.Destination == destination {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// sortedCountries returns the keys of a country set in sorted order.
+func sortedCountries(set map[string]bool) []string {
+	codes := make([]string, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/retention"
+)
+
+// WithRetention sets the retention service, so the API and the scheduled
+// runs share configured defaults and one run history.
+func WithRetention(svc *retention.Service) Option {
+	return func(h *Handler) {
+		h.retention = svc
+	}
+}
+
+// Retention policy sources.
+const (
+	RetentionSourceTenant  = "tenant"
+	RetentionSourceDefault = "default"
+)
+
+// RetentionPolicyRequest is the request body for PUT /config/retention.
+// Zero days keep a class for ever.
+type RetentionPolicyRequest struct {
+	TransactionDays int  `json:"transactionDays"`
+	EvaluationDays  int  `json:"evaluationDays"`
+	AlertDays       int  `json:"alertDays"`
+	Archive         bool `json:"archive"` // archive expired rows before deleting them
+}
+
+// RetentionPolicyResponse is the retention policy in effect for the tenant.
+type RetentionPolicyResponse struct {
+	domain.RetentionPolicy
+	Source string `json:"source"` // "tenant" or "default"
+}
+
+// RetentionRunRequest is the request body for POST /admin/retention/runs.
+// The body is optional.
+type RetentionRunRequest struct {
+	TenantID string `json:"tenantId,omitempty"` // only this tenant; default every tenant
+}
+
+// GetRetentionPolicy returns the retention policy the tenant's activity is
+// expired by.
+func (h *Handler) GetRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	policy, err := h.retention.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get retention policy", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get retention policy",
+		})
+		return
+	}
+
+	resp := RetentionPolicyResponse{Source: RetentionSourceTenant}
+	if policy == nil {
+		resp.RetentionPolicy = h.retention.Defaults()
+		resp.Source = RetentionSourceDefault
+	} else {
+		resp.RetentionPolicy = *policy
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PutRetentionPolicy stores the tenant's own retention policy. It applies
+// from the next retention run. In compliance mode, no class may be kept
+// for less than domain.ComplianceMinRetentionDays.
+func (h *Handler) PutRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req RetentionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.TransactionDays < 0 || req.EvaluationDays < 0 || req.AlertDays < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "retention days must not be negative",
+		})
+		return
+	}
+	if h.mode == domain.ModeCompliance {
+		requested := domain.RetentionPolicy{TransactionDays: req.TransactionDays, EvaluationDays: req.EvaluationDays, AlertDays: req.AlertDays}
+		if err := requested.CheckCompliance(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.Archive && !h.retention.ArchiveEnabled() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "archive requires OSPREY_RETENTION_ARCHIVE_DIR to be set",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.retention.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get retention policy", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save retention policy",
+		})
+		return
+	}
+
+	policy := &domain.RetentionPolicy{
+		TenantID:        tenantID,
+		TransactionDays: req.TransactionDays,
+		EvaluationDays:  req.EvaluationDays,
+		AlertDays:       req.AlertDays,
+		Archive:         req.Archive,
+		UpdatedBy:       GetRequestContext(ctx).Principal,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	if err := h.retention.Save(ctx, tenantID, policy); err != nil {
+		slog.Error("failed to save retention policy", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save retention policy",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditRetentionPolicy, "", previous, policy)
+	slog.Info("retention policy updated",
+		"tenant_id", tenantID,
+		"transaction_days", policy.TransactionDays,
+		"evaluation_days", policy.EvaluationDays,
+		"alert_days", policy.AlertDays,
+		"archive", policy.Archive,
+	)
+	writeJSON(w, http.StatusOK, RetentionPolicyResponse{RetentionPolicy: *policy, Source: RetentionSourceTenant})
+}
+
+// DeleteRetentionPolicy removes the tenant's own retention policy, so the
+// defaults apply again.
+func (h *Handler) DeleteRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.retention.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get retention policy", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete retention policy",
+		})
+		return
+	}
+
+	err = h.retention.Delete(ctx, tenantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "tenant has no retention policy of its own",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete retention policy", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete retention policy",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditRetentionPolicy, "", previous, nil)
+	slog.Info("retention policy deleted", "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Retention policy deleted; defaults apply.",
+	})
+}
+
+// StartRetentionRun expires activity by the retention policies now, for
+// every tenant or the one in the body, and returns the run as it starts.
+func (h *Handler) StartRetentionRun(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	var req RetentionRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	run, err := h.retention.Start(strings.TrimSpace(req.TenantID), r.Header.Get(PrincipalHeader))
+	if errors.Is(err, retention.ErrRunning) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "a retention run is in progress",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to start retention run",
+		})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// ListRetentionRuns returns the latest retention runs on this instance,
+// newest first.
+func (h *Handler) ListRetentionRuns(w http.ResponseWriter, r *http.Request) {
+	runs := h.retention.Runs()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// GetRetentionRun returns a retention run with the rows expired per tenant
+// and class.
+func (h *Handler) GetRetentionRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.retention.GetRun(chi.URLParam(r, "id"))
+	if errors.Is(err, retention.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "retention run not found",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
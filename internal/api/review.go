@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/review"
+)
+
+// WithReviewQueue sets the review queue, with the deadlines and claim lease
+// from the configuration.
+func WithReviewQueue(q *review.Queue) Option {
+	return func(h *Handler) {
+		h.review = q
+	}
+}
+
+// ClaimReviewRequest is the request body for POST /review-queue/{id}/claim
+// and /release. The body is optional.
+type ClaimReviewRequest struct {
+	By string `json:"by,omitempty"` // Ignored when the request carries a principal
+}
+
+// ListReviewQueue returns the tenant's held transactions, unacknowledged
+// alerts and open cases in one list, most urgent first.
+// Query params: kind (comma-separated hold, alert, case), unclaimed=true,
+// limit (default 100).
+func (h *Handler) ListReviewQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	filter := review.Filter{
+		Unclaimed: query.Get("unclaimed") == "true",
+		Limit:     100,
+	}
+	if v := query.Get("kind"); v != "" {
+		for _, kind := range strings.Split(v, ",") {
+			if !review.ValidKind(kind) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "kind must be one of: " + strings.Join(review.Kinds, ", "),
+				})
+				return
+			}
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxListAlertsLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	items, err := h.review.List(ctx, tenantID, filter)
+	if err != nil {
+		slog.Error("failed to list review queue", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list review queue",
+		})
+		return
+	}
+	if items == nil {
+		items = []*review.Item{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// ClaimReviewItem claims a review item for the caller, so other analysts
+// see it taken. Claiming it again renews the claim; an item another analyst
+// holds returns 409.
+func (h *Handler) ClaimReviewItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	itemID := chi.URLParam(r, "id")
+
+	by, ok := reviewer(w, r)
+	if !ok {
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	claim, err := h.review.Claim(ctx, tenantID, itemID, by)
+	if errors.Is(err, review.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, review.ErrClaimed) {
+		msg := err.Error()
+		if claim != nil {
+			msg += ": " + claim.ClaimedBy + " until " + claim.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to claim review item", "tenant_id", tenantID, "item_id", itemID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to claim review item",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claim)
+}
+
+// ReleaseReviewItem drops the caller's claim on a review item, returning it
+// to the queue. Releasing an item one doesn't hold returns 409.
+func (h *Handler) ReleaseReviewItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	itemID := chi.URLParam(r, "id")
+
+	by, ok := reviewer(w, r)
+	if !ok {
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	err := h.review.Release(ctx, tenantID, itemID, by)
+	if errors.Is(err, review.ErrNotClaimed) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to release review item", "tenant_id", tenantID, "item_id", itemID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to release review item",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "review item released",
+	})
+}
+
+// reviewer returns who claims or releases an item: the request's principal,
+// else the body's by. It writes a 400 and returns false when neither is set.
+func reviewer(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req ClaimReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return "", false
+	}
+	if principal := GetRequestContext(r.Context()).Principal; principal != "" {
+		req.By = principal
+	}
+	if req.By == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "by is required when the request carries no principal",
+		})
+		return "", false
+	}
+	return req.By, true
+}
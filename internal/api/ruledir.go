@@ -0,0 +1,47 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/ruledir"
+)
+
+// WithRuleDir sets the rule directory watcher. While it is enabled, rules
+// and typologies are read-only through the API.
+func WithRuleDir(w *ruledir.Watcher) Option {
+	return func(h *Handler) {
+		h.ruleDir = w
+	}
+}
+
+// GetRuleDirStatus returns the result of the most recent check of the rule
+// directory.
+func (h *Handler) GetRuleDirStatus(w http.ResponseWriter, r *http.Request) {
+	if !h.ruleDir.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule directory is not enabled",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.ruleDir.Status())
+}
+
+// SyncRuleDir checks the rule directory now and applies it if it changed,
+// returning the result.
+func (h *Handler) SyncRuleDir(w http.ResponseWriter, r *http.Request) {
+	if !h.ruleDir.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule directory is not enabled",
+		})
+		return
+	}
+
+	status, err := h.ruleDir.Sync(r.Context())
+	if err != nil {
+		slog.Error("rule directory sync failed", "error", err)
+		writeJSON(w, http.StatusUnprocessableEntity, status)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
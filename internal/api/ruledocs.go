@@ -0,0 +1,73 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/ruledocs"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// RuleDocs renders the tenant's loaded rules and typologies as a document
+// for audit submissions, with each rule's owner, typology memberships and
+// stored versions. Query param: format, markdown (default) or html.
+func (h *Handler) RuleDocs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	format := ruledocs.FormatMarkdown
+	if v := r.URL.Query().Get("format"); v != "" {
+		if !ruledocs.ValidFormat(v) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "format must be one of: markdown, html",
+			})
+			return
+		}
+		format = v
+	}
+
+	var typologies []*domain.Typology
+	if h.typologyEngine != nil {
+		typologies = h.typologyEngine.GetTenantTypologies(tenantID)
+	}
+
+	// Versions are read from storage; without it the document has no history
+	var history []*domain.RuleVersion
+	if h.repo != nil {
+		scopes := []string{rules.GlobalTenantID}
+		if tenantID != rules.GlobalTenantID {
+			scopes = append(scopes, tenantID)
+		}
+		for _, scope := range scopes {
+			versions, err := h.repo.ListRuleVersions(ctx, scope)
+			if err != nil {
+				slog.Error("failed to list rule versions", "tenant_id", scope, "error", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{
+					"error": "failed to list rule versions",
+				})
+				return
+			}
+			history = append(history, versions...)
+		}
+	}
+
+	doc := ruledocs.Build(tenantID, h.version, h.engine.GetTenantRules(tenantID), typologies, history, time.Now())
+	body, err := doc.Render(format)
+	if err != nil {
+		slog.Error("failed to render rule documentation", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to render rule documentation",
+		})
+		return
+	}
+
+	contentType := "text/markdown; charset=utf-8"
+	if format == ruledocs.FormatHTML {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
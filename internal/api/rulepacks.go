@@ -0,0 +1,312 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rulepacks"
+	"github.com/opensource-finance/osprey/internal/state"
+)
+
+// maxRulePackBytes caps the size of an imported rule pack.
+const maxRulePackBytes = 4 << 20
+
+// RulePackSummary describes a built-in rule pack.
+type RulePackSummary struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Rules       int    `json:"rules"`
+	Typologies  int    `json:"typologies"`
+}
+
+// RulePackResult is the response of importing or installing a rule pack.
+type RulePackResult struct {
+	Pack    string     `json:"pack"`
+	Version string     `json:"version,omitempty"`
+	Plan    state.Plan `json:"plan"`
+	Applied bool       `json:"applied"`
+}
+
+// InstallRulePackRequest names the built-in pack to install.
+type InstallRulePackRequest struct {
+	Name string `json:"name"`
+}
+
+// ListRulePacks lists the built-in rule packs.
+func (h *Handler) ListRulePacks(w http.ResponseWriter, r *http.Request) {
+	packs, err := rulepacks.Builtin()
+	if err != nil {
+		slog.Error("failed to read built-in rule packs", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to read built-in rule packs",
+		})
+		return
+	}
+
+	summaries := make([]RulePackSummary, 0, len(packs))
+	for _, p := range packs {
+		summaries = append(summaries, RulePackSummary{
+			Name:        p.Name,
+			Version:     p.Version,
+			Description: p.Description,
+			Source:      p.Source,
+			Rules:       len(p.Rules),
+			Typologies:  len(p.Typologies),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"packs": summaries,
+		"count": len(summaries),
+	})
+}
+
+// GetRulePack returns a built-in rule pack. Query param: format, json
+// (default) or yaml.
+func (h *Handler) GetRulePack(w http.ResponseWriter, r *http.Request) {
+	encoding, ok := rulePackFormat(w, r)
+	if !ok {
+		return
+	}
+
+	pack, err := rulepacks.Lookup(chi.URLParam(r, "name"))
+	if errors.Is(err, rulepacks.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule pack not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to read built-in rule packs", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to read built-in rule packs",
+		})
+		return
+	}
+
+	writeRulePack(w, pack, encoding)
+}
+
+// ExportRulePack returns the tenant's own enabled rules and typologies as a
+// rule pack, which POST /rulepacks/import loads into another tenant or
+// instance. Query params: format, json (default) or yaml; name (default
+// the tenant ID) and version (default 1.0.0).
+func (h *Handler) ExportRulePack(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	encoding, ok := rulePackFormat(w, r)
+	if !ok {
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	spec, err := h.state.CurrentOf(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to read rules and typologies", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to export rule pack",
+		})
+		return
+	}
+
+	name, version := r.URL.Query().Get("name"), r.URL.Query().Get("version")
+	if name == "" {
+		name = tenantID
+	}
+	if version == "" {
+		version = state.DefaultVersion
+	}
+	writeRulePack(w, rulepacks.New(name, version, spec), encoding)
+}
+
+// ImportRulePack loads a rule pack into the tenant. The body is the pack in
+// JSON, or in YAML with a YAML Content-Type. Every expression and typology
+// rule reference is validated before anything is saved, so an invalid pack
+// changes nothing. Rules and typologies not in the pack are kept. With
+// ?dryRun=true the plan is returned without applying it.
+func (h *Handler) ImportRulePack(w http.ResponseWriter, r *http.Request) {
+	if h.rejectManaged(w) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRulePackBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": "rule pack exceeds 4MB limit",
+		})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "failed to read request body",
+		})
+		return
+	}
+
+	encoding := rulepacks.JSON
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); isYAMLMediaType(mediaType) {
+		encoding = rulepacks.YAML
+	}
+	pack, err := rulepacks.Decode(data, encoding)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.applyRulePack(w, r, pack, "POST /rulepacks/import")
+}
+
+// InstallRulePack loads a built-in rule pack into the tenant, like
+// POST /rulepacks/import.
+func (h *Handler) InstallRulePack(w http.ResponseWriter, r *http.Request) {
+	if h.rejectManaged(w) {
+		return
+	}
+
+	var req InstallRulePackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "name is required",
+		})
+		return
+	}
+
+	pack, err := rulepacks.Lookup(req.Name)
+	if errors.Is(err, rulepacks.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "rule pack not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to read built-in rule packs", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to read built-in rule packs",
+		})
+		return
+	}
+
+	h.applyRulePack(w, r, pack, "POST /rulepacks/install")
+}
+
+// applyRulePack plans a pack against the tenant's rules and typologies and
+// applies it, subject to the guardrails.
+func (h *Handler) applyRulePack(w http.ResponseWriter, r *http.Request, pack *rulepacks.Pack, operation string) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	plan, err := h.state.PlanMerge(ctx, tenantID, pack.Spec())
+	if errors.Is(err, state.ErrInvalid) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to plan rule pack", "tenant_id", tenantID, "pack", pack.Name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to plan rule pack",
+		})
+		return
+	}
+
+	result := RulePackResult{Pack: pack.Name, Version: pack.Version, Plan: *plan}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	if dryRun || plan.Empty() {
+		result.Applied = !dryRun
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if !h.checkGuardrails(w, r, tenantID, operation, plan.Mutation()) {
+		return
+	}
+
+	if err := h.state.Apply(ctx, plan); err != nil {
+		slog.Error("failed to apply rule pack", "tenant_id", tenantID, "pack", pack.Name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to apply rule pack: " + err.Error(),
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditApply, domain.AuditRulePack, pack.Name, nil, plan)
+	slog.Info("rule pack applied",
+		"tenant_id", tenantID,
+		"pack", pack.Name,
+		"version", pack.Version,
+		"rule_changes", len(plan.Rules),
+		"typology_changes", len(plan.Typologies),
+	)
+	result.Applied = true
+	writeJSON(w, http.StatusOK, result)
+}
+
+// rulePackFormat reads the format query param, writing a 400 if it is invalid.
+func rulePackFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch v := r.URL.Query().Get("format"); v {
+	case "", rulepacks.JSON:
+		return rulepacks.JSON, true
+	case rulepacks.YAML:
+		return rulepacks.YAML, true
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error": "format must be one of: json, yaml",
+	})
+	return "", false
+}
+
+// writeRulePack writes a pack in the given encoding.
+func writeRulePack(w http.ResponseWriter, pack *rulepacks.Pack, encoding string) {
+	if encoding == rulepacks.JSON {
+		writeJSON(w, http.StatusOK, pack)
+		return
+	}
+
+	body, err := rulepacks.Encode(pack, encoding)
+	if err != nil {
+		slog.Error("failed to encode rule pack", "pack", pack.Name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to encode rule pack",
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// isYAMLMediaType reports whether a request body's media type is YAML.
+func isYAMLMediaType(mediaType string) bool {
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
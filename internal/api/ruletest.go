@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// TestRuleRequest is the request body for POST /rules/{id}/test.
+type TestRuleRequest struct {
+	// Expression tests an ad-hoc rule instead of the stored one
+	Language   string            `json:"language,omitempty"` // cel (default) or expr
+	Expression string            `json:"expression,omitempty"`
+	Bands      []domain.RuleBand `json:"bands,omitempty"` // replace the rule's bands when set
+
+	Transaction TransactionRequest `json:"transaction"`
+	Timestamp   *time.Time         `json:"timestamp,omitempty"` // transaction time; default now
+}
+
+// TestRuleResponse is the response for POST /rules/{id}/test.
+type TestRuleResponse struct {
+	RuleID  string  `json:"ruleId"`
+	Score   float64 `json:"score"`
+	Outcome string  `json:"outcome"` // the matched band's subRuleRef, .err on an evaluation error
+	Reason  string  `json:"reason,omitempty"`
+	Action  string  `json:"action,omitempty"`
+	Error   string  `json:"error,omitempty"` // CEL evaluation error
+}
+
+// TestRule evaluates one rule, stored or given in the body, against a sample
+// transaction and returns its score and matched band. Like a backtest it runs
+// on a sandboxed engine: nothing is stored, sampled or looked up.
+func (h *Handler) TestRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	ruleID := chi.URLParam(r, "id")
+
+	var req TestRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if !domain.ValidRuleLanguage(req.Language) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "language must be one of: cel, expr",
+		})
+		return
+	}
+
+	var rule domain.RuleConfig
+	if req.Expression != "" {
+		rule = domain.RuleConfig{
+			ID:         ruleID,
+			Name:       ruleID,
+			Version:    "1.0.0",
+			Language:   req.Language,
+			Expression: req.Expression,
+		}
+	} else {
+		stored, err := h.findRule(ctx, tenantID, ruleID)
+		if err != nil {
+			slog.Error("failed to get rule", "tenant_id", tenantID, "id", ruleID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to get rule",
+			})
+			return
+		}
+		if stored == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "rule not found",
+			})
+			return
+		}
+		rule = *stored
+	}
+	if req.Bands != nil {
+		rule.Bands = req.Bands
+	}
+	rule.TenantID = tenantID
+	rule.Enabled = true
+	rule.Shadow = false
+	rule.SampleRate = 0
+
+	sandbox := h.engine.Sandbox()
+	defer sandbox.Close()
+	if err := sandbox.LoadRule(&rule); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid CEL expression: " + err.Error(),
+		})
+		return
+	}
+
+	tx := req.Transaction
+	timestamp := time.Now().UTC()
+	if req.Timestamp != nil {
+		timestamp = *req.Timestamp
+	}
+	results, err := sandbox.EvaluateAll(ctx, &rules.EvaluateInput{
+		TenantID:          tenantID,
+		TxID:              "test",
+		Type:              tx.Type,
+		DebtorID:          tx.Debtor.ID,
+		CreditorID:        tx.Creditor.ID,
+		DebtorAccountID:   tx.Debtor.AccountID,
+		CreditorAccountID: tx.Creditor.AccountID,
+		DebtorName:        tx.Debtor.Name,
+		CreditorName:      tx.Creditor.Name,
+		DebtorCountry:     tx.Debtor.Country,
+		CreditorCountry:   tx.Creditor.Country,
+		Amount:            tx.Amount.Value,
+		Currency:          tx.Amount.Currency,
+		Timestamp:         timestamp,
+		Components:        tx.Amount.Components,
+		VelocityWindow:    tx.VelocityWindow,
+		ReversalOf:        tx.ReversalOf,
+		AdditionalData:    tx.Metadata,
+		Request:           GetRequestContext(ctx),
+	})
+	if err != nil || len(results) != 1 {
+		slog.Error("failed to test rule", "tenant_id", tenantID, "id", ruleID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to test rule",
+		})
+		return
+	}
+
+	result := results[0]
+	resp := TestRuleResponse{
+		RuleID:  result.RuleID,
+		Score:   result.Score,
+		Outcome: result.SubRuleRef,
+		Reason:  result.Reason,
+		Action:  result.Action,
+	}
+	if result.SubRuleRef == domain.RuleOutcomeError {
+		resp.Reason = ""
+		resp.Error = result.Reason
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// findRule returns the tenant's stored rule, which may not be loaded yet,
+// else the loaded rule that applies to the tenant, or nil.
+func (h *Handler) findRule(ctx context.Context, tenantID, ruleID string) (*domain.RuleConfig, error) {
+	if h.repo != nil {
+		rule, err := h.repo.GetRuleConfig(ctx, tenantID, ruleID)
+		if err == nil {
+			return rule, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	for _, rule := range h.engine.GetTenantRules(tenantID) {
+		if rule.ID == ruleID {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
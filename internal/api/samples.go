@@ -0,0 +1,53 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxListSamplesLimit caps the limit query parameter of GET /rules/{id}/samples.
+const maxListSamplesLimit = 500
+
+// ListRuleSamples returns the tenant's stored activation samples for a rule,
+// newest first. Query params: limit (default 50).
+func (h *Handler) ListRuleSamples(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	ruleID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListSamplesLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 500",
+			})
+			return
+		}
+		limit = n
+	}
+
+	samples, err := h.repo.ListActivationSamples(ctx, tenantID, ruleID, limit)
+	if err != nil {
+		slog.Error("failed to list activation samples", "rule_id", ruleID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list samples",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"samples": samples,
+		"count":   len(samples),
+	})
+}
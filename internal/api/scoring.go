@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/scoring"
+)
+
+// WithScoring sets the scoring service, so the API and the decision processor
+// share configured defaults and cached tenant configs.
+func WithScoring(svc *scoring.Service) Option {
+	return func(h *Handler) {
+		h.scoring = svc
+	}
+}
+
+// Scoring config sources.
+const (
+	ScoringSourceTenant  = "tenant"
+	ScoringSourceDefault = "default"
+)
+
+// ScoringConfigRequest is the request body for PUT /config/scoring.
+type ScoringConfigRequest struct {
+	AlertThreshold  float64 `json:"alertThreshold"`
+	WeightedScoring *bool   `json:"weightedScoring,omitempty"` // defaults to true
+	CriticalFail    string  `json:"criticalFail,omitempty"`    // "alert" (default) or "score"
+	MLWeight        float64 `json:"mlWeight,omitempty"`        // 0 (default) to 1
+	MLBlend         string  `json:"mlBlend,omitempty"`         // "weighted" (default) or "max"
+}
+
+// ScoringConfigResponse is the scoring config in effect for the tenant.
+type ScoringConfigResponse struct {
+	domain.ScoringConfig
+	Source string `json:"source"` // "tenant" or "default"
+}
+
+// GetScoringConfig returns the scoring config the tenant's evaluations are
+// decided with.
+func (h *Handler) GetScoringConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	cfg, err := h.scoring.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get scoring config", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get scoring config",
+		})
+		return
+	}
+
+	resp := ScoringConfigResponse{Source: ScoringSourceTenant}
+	if cfg == nil {
+		resp.ScoringConfig = h.scoring.Defaults()
+		resp.Source = ScoringSourceDefault
+	} else {
+		resp.ScoringConfig = *cfg
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PutScoringConfig stores the tenant's own scoring config. It applies to the
+// tenant's next evaluation on this instance, and on others once their cached
+// copy expires.
+func (h *Handler) PutScoringConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req ScoringConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if req.AlertThreshold <= 0 || req.AlertThreshold > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "alertThreshold must be greater than 0 and at most 1",
+		})
+		return
+	}
+	if req.CriticalFail == "" {
+		req.CriticalFail = domain.CriticalFailAlert
+	}
+	if !domain.ValidCriticalFail(req.CriticalFail) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "criticalFail must be 'alert' or 'score'",
+		})
+		return
+	}
+
+	if req.MLWeight < 0 || req.MLWeight > 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "mlWeight must be between 0 and 1",
+		})
+		return
+	}
+	if req.MLBlend == "" {
+		req.MLBlend = domain.MLBlendWeighted
+	}
+	if !domain.ValidMLBlend(req.MLBlend) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "mlBlend must be 'weighted' or 'max'",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.scoring.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get scoring config", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save scoring config",
+		})
+		return
+	}
+
+	cfg := &domain.ScoringConfig{
+		TenantID:        tenantID,
+		AlertThreshold:  req.AlertThreshold,
+		WeightedScoring: req.WeightedScoring == nil || *req.WeightedScoring,
+		CriticalFail:    req.CriticalFail,
+		MLWeight:        req.MLWeight,
+		MLBlend:         req.MLBlend,
+		UpdatedBy:       GetRequestContext(ctx).Principal,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	if err := h.scoring.Save(ctx, tenantID, cfg); err != nil {
+		slog.Error("failed to save scoring config", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save scoring config",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditScoringConfig, "", previous, cfg)
+	slog.Info("scoring config updated",
+		"tenant_id", tenantID,
+		"alert_threshold", cfg.AlertThreshold,
+		"weighted_scoring", cfg.WeightedScoring,
+		"critical_fail", cfg.CriticalFail,
+		"ml_weight", cfg.MLWeight,
+		"ml_blend", cfg.MLBlend,
+	)
+	writeJSON(w, http.StatusOK, ScoringConfigResponse{ScoringConfig: *cfg, Source: ScoringSourceTenant})
+}
+
+// DeleteScoringConfig removes the tenant's own scoring config, so the
+// defaults apply again.
+func (h *Handler) DeleteScoringConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	previous, err := h.scoring.Get(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to get scoring config", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete scoring config",
+		})
+		return
+	}
+
+	err = h.scoring.Delete(ctx, tenantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "tenant has no scoring config of its own",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete scoring config", "tenant_id", tenantID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete scoring config",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditScoringConfig, "", previous, nil)
+	slog.Info("scoring config deleted", "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Scoring config deleted; defaults apply.",
+	})
+}
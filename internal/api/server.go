@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/opensource-finance/osprey/internal/domain"
 	"github.com/opensource-finance/osprey/internal/rules"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/pkg/ospreypb"
+	"google.golang.org/grpc"
 )
 
 // Server represents the HTTP API server.
@@ -18,57 +22,250 @@ type Server struct {
 	router  *chi.Mux
 	handler *Handler
 	server  *http.Server
+	grpc    *grpc.Server
 	config  domain.ServerConfig
 }
 
 // NewServer creates a new API server.
-func NewServer(cfg domain.ServerConfig, repo domain.Repository, cache domain.Cache, bus domain.EventBus, engine *rules.Engine, typologyEngine *rules.TypologyEngine, processor *tadp.Processor, version string, mode domain.EvaluationMode) *Server {
-	handler := NewHandler(repo, cache, bus, engine, typologyEngine, processor, version, mode)
+func NewServer(cfg domain.ServerConfig, repo domain.Repository, cache domain.Cache, bus domain.EventBus, engine *rules.Engine, typologyEngine *rules.TypologyEngine, processor *tadp.Processor, version string, mode domain.EvaluationMode, opts ...Option) *Server {
+	handler := NewHandler(repo, cache, bus, engine, typologyEngine, processor, version, mode, opts...)
 	router := chi.NewRouter()
 
 	// Global middleware stack
-	router.Use(CORSMiddleware)         // CORS for browser clients
-	router.Use(RecoverMiddleware)      // Recover from panics
-	router.Use(TracingMiddleware)      // OpenTelemetry tracing
-	router.Use(LoggingMiddleware)      // Request logging
-	router.Use(middleware.RealIP)      // Extract real IP
-	router.Use(middleware.Compress(5)) // Gzip compression
-
-	// Health endpoints (no tenant required)
+	router.Use(handler.cors.Middleware) // CORS for browser clients
+	router.Use(handler.slo.Middleware)  // Count requests against the SLOs, panics included
+	router.Use(RecoverMiddleware)       // Recover from panics
+	router.Use(PeerAddrMiddleware)      // Keep the TCP peer for the admin network allowlist
+	router.Use(middleware.RealIP)       // Extract real IP (before the request context captures it)
+	router.Use(TracingMiddleware)       // OpenTelemetry tracing and request context
+	router.Use(LoggingMiddleware)       // Request logging
+	router.Use(middleware.Compress(5))  // Gzip compression
+
+	// Health and info endpoints (no tenant required)
 	router.Get("/health", handler.Health)
 	router.Get("/ready", handler.Ready)
+	router.Get("/info", handler.Info)
+	router.Get("/.well-known/jwks.json", handler.JWKS)
+	router.Get("/metrics", handler.Metrics)
+	router.Get("/openapi.json", handler.OpenAPI)
+
+	// preStop hook: fail readiness before the shutdown signal (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Post("/drain", handler.Drain)
+
+	// Error budgets and burn rates of the service level objectives (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/slo", handler.SLO)
+
+	// Heaviest tenants and entities by evaluations and alerts (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/stats/top", handler.TopStats)
+
+	// Operator summary across tenants (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/tenants/health", handler.TenantsHealth)
+
+	// Index advisor for the velocity and list queries (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/indexes", handler.AdviseIndexes)
+	router.With(handler.adminNetworks.Middleware).Post("/admin/indexes", handler.CreateIndexes)
+
+	// Tenant isolation audit and repair (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/isolation", handler.AuditIsolation)
+	router.With(handler.adminNetworks.Middleware).Post("/admin/isolation", handler.RepairIsolation)
+
+	// Tenant migration to another repository (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/migrations", handler.ListMigrations)
+	router.With(handler.adminNetworks.Middleware).Post("/admin/migrations", handler.StartMigration)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/migrations/{tenantId}", handler.GetMigration)
+	router.With(handler.adminNetworks.Middleware).Delete("/admin/migrations/{tenantId}", handler.ReleaseMigration)
+
+	// Retention runs across tenants (no tenant required)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/retention/runs", handler.ListRetentionRuns)
+	router.With(handler.adminNetworks.Middleware).Post("/admin/retention/runs", handler.StartRetentionRun)
+	router.With(handler.adminNetworks.Middleware).Get("/admin/retention/runs/{id}", handler.GetRetentionRun)
+
+	// Git push webhook (authenticated by signature, no tenant required)
+	router.Post("/gitsync/webhook", handler.GitWebhook)
 
 	// API routes (tenant required)
 	router.Route("/", func(r chi.Router) {
 		r.Use(TenantMiddleware)
+		r.Use(handler.MigrationFreeze)
+		r.Use(handler.GlobalTenantWrites)
+
+		// Management endpoints, limited to admin networks when configured
+		admin := r.With(handler.adminNetworks.Middleware)
 
 		// Transaction evaluation
 		r.Post("/evaluate", handler.Evaluate)
+		r.Get("/transaction-types", handler.ListTxTypes)
 
-		// Evaluation retrieval
+		// Evaluation retrieval and search
+		r.Get("/evaluations", handler.ListEvaluations)
 		r.Get("/evaluations/{id}", handler.GetEvaluation)
+		r.Get("/evaluations/{id}/explain", handler.ExplainEvaluation)
+		r.Get("/evaluations/{id}/outcomes", handler.ListEvaluationOutcomes)
+		r.Post("/evaluations/{id}/outcome", handler.ReportOutcome)
+
+		// Decision outcomes across evaluations
+		r.Get("/outcomes", handler.ListOutcomes)
+		r.Post("/outcomes/import", handler.ImportOutcomes)
+		r.Get("/outcomes/losses", handler.OutcomeLosses)
+
+		// Score statistics
+		r.Get("/stats/score-distribution", handler.ScoreDistribution)
+		r.Get("/stats/benchmark", handler.Benchmark)
 
 		// Transaction retrieval
 		r.Get("/transactions/{id}", handler.GetTransaction)
+		r.Get("/entities/{id}/transactions", handler.ListEntityTransactions)
+		r.Get("/entities/{id}/counterparties", handler.ListEntityCounterparties)
 
 		// Rule management
 		r.Get("/rules", handler.ListRules)
+		r.Get("/rules/docs", handler.RuleDocs)
 		r.Get("/rules/{id}", handler.GetRule)
-		r.Post("/rules", handler.CreateRule)
-		r.Post("/rules/reload", handler.ReloadRules)
+		r.Get("/rules/{id}/samples", handler.ListRuleSamples)
+		admin.Post("/rules", handler.CreateRule)
+		admin.Post("/rules/reload", handler.ReloadRules)
+		admin.Post("/rules/backtest", handler.BacktestRule)
+		admin.Post("/rules/{id}/test", handler.TestRule)
+		admin.Put("/rules/{id}", handler.UpdateRule)
+		admin.Delete("/rules/{id}", handler.DeleteRule)
 
 		// Typology management
 		r.Get("/typologies", handler.ListTypologies)
 		r.Get("/typologies/{id}", handler.GetTypology)
-		r.Post("/typologies", handler.CreateTypology)
-		r.Put("/typologies/{id}", handler.UpdateTypology)
-		r.Delete("/typologies/{id}", handler.DeleteTypology)
-		r.Post("/typologies/reload", handler.ReloadTypologies)
+		admin.Post("/typologies", handler.CreateTypology)
+		admin.Put("/typologies/{id}", handler.UpdateTypology)
+		admin.Delete("/typologies/{id}", handler.DeleteTypology)
+		admin.Post("/typologies/reload", handler.ReloadTypologies)
+
+		// Rule packs: bundles of rules and typologies
+		r.Get("/rulepacks", handler.ListRulePacks)
+		r.Get("/rulepacks/export", handler.ExportRulePack)
+		r.Get("/rulepacks/{name}", handler.GetRulePack)
+		admin.Post("/rulepacks/import", handler.ImportRulePack)
+		admin.Post("/rulepacks/install", handler.InstallRulePack)
+
+		// Party KYC profiles
+		r.Get("/parties/{id}", handler.GetParty)
+		r.Put("/parties/{id}", handler.UpsertParty)
+		r.Delete("/parties/{id}", handler.DeleteParty)
+
+		// Account balances, for drain detection
+		r.Get("/balances/{accountId}", handler.GetBalance)
+		r.Put("/balances/{accountId}", handler.SetBalance)
+
+		// Customer risk profiles (the party KYC profiles as a collection)
+		r.Get("/customers", handler.ListCustomers)
+		r.Post("/customers", handler.CreateCustomer)
+		r.Get("/customers/{id}", handler.GetParty)
+		r.Put("/customers/{id}", handler.UpsertParty)
+		r.Delete("/customers/{id}", handler.DeleteParty)
+
+		// Corridor risk reference data
+		r.Get("/refdata/corridors", handler.ListCorridors)
+		r.Get("/refdata/corridors/{origin}/{destination}", handler.GetCorridor)
+		admin.Put("/refdata/corridors/{origin}/{destination}", handler.UpsertCorridor)
+		admin.Delete("/refdata/corridors/{origin}/{destination}", handler.DeleteCorridor)
+		r.Get("/refdata/lists", handler.ListNamedLists)
+		r.Get("/refdata/lists/{name}", handler.GetNamedList)
+		admin.Put("/refdata/lists/{name}", handler.PutNamedList)
+		admin.Delete("/refdata/lists/{name}", handler.DeleteNamedList)
+
+		// Watchlists
+		r.Get("/lists", handler.ListWatchlist)
+		r.Get("/lists/{id}", handler.GetWatchlistEntry)
+		admin.Post("/lists", handler.CreateWatchlistEntry)
+		admin.Put("/lists/{id}", handler.UpdateWatchlistEntry)
+		admin.Delete("/lists/{id}", handler.DeleteWatchlistEntry)
+
+		// Background jobs
+		r.Get("/jobs", handler.ListJobs)
+		r.Post("/jobs/batch", handler.SubmitBatchJob)
+		r.Post("/jobs/reevaluate", handler.SubmitReevaluateJob)
+		r.Get("/jobs/{id}", handler.GetJob)
+		r.Get("/jobs/{id}/results", handler.GetJobResults)
+		r.Post("/jobs/{id}/pause", handler.PauseJob)
+		r.Post("/jobs/{id}/resume", handler.ResumeJob)
+		r.Post("/jobs/{id}/cancel", handler.CancelJob)
+
+		// Per-tenant scoring config
+		r.Get("/config/scoring", handler.GetScoringConfig)
+		admin.Put("/config/scoring", handler.PutScoringConfig)
+		admin.Delete("/config/scoring", handler.DeleteScoringConfig)
+		r.Get("/config/retention", handler.GetRetentionPolicy)
+		admin.Put("/config/retention", handler.PutRetentionPolicy)
+		admin.Delete("/config/retention", handler.DeleteRetentionPolicy)
+
+		// Feature flags
+		r.Get("/features", handler.ListFeatures)
+		admin.Put("/features/{name}", handler.SetFeature)
+		admin.Delete("/features/{name}", handler.DeleteFeature)
+
+		// Evaluation log verification and configuration audit log
+		r.Get("/audit/evaluations/verify", handler.VerifyEvaluationLog)
+		r.Get("/audit", handler.ListAuditEntries)
+		r.Get("/audit/verify", handler.VerifyAuditLog)
+		r.Get("/audit/{seq}", handler.GetAuditEntry)
+
+		// Alerts
+		r.Get("/alerts", handler.ListAlerts)
+		r.Get("/alerts/{id}", handler.GetAlert)
+		r.Patch("/alerts/{id}", handler.UpdateAlert)
+		r.Post("/alerts/{id}/ack", handler.AckAlert)
+		r.Get("/stream/alerts", handler.StreamAlerts)
+
+		// Review queue: held transactions, unacked alerts and open cases
+		r.Get("/review-queue", handler.ListReviewQueue)
+		r.Post("/review-queue/{id}/claim", handler.ClaimReviewItem)
+		r.Post("/review-queue/{id}/release", handler.ReleaseReviewItem)
+
+		// Declarative configuration
+		admin.Get("/state", handler.GetState)
+		admin.Put("/state", handler.PutState)
+
+		// Git sync
+		r.Get("/gitsync", handler.GetGitSyncStatus)
+		admin.Post("/gitsync/sync", handler.SyncGit)
+
+		// Rule directory
+		r.Get("/ruledir", handler.GetRuleDirStatus)
+		admin.Post("/ruledir/sync", handler.SyncRuleDir)
+
+		// Webhooks
+		r.Get("/webhooks", handler.ListWebhooks)
+		admin.Post("/webhooks", handler.CreateWebhook)
+		admin.Delete("/webhooks/{id}", handler.DeleteWebhook)
+		r.Get("/webhooks/{id}/deliveries", handler.ListWebhookDeliveries)
+
+		// Dead letters of the async worker
+		r.Get("/dlq", handler.ListDeadLetters)
+		r.Get("/dlq/{id}", handler.GetDeadLetter)
+		admin.Post("/dlq/{id}/replay", handler.ReplayDeadLetter)
+
+		// Maintenance windows
+		r.Get("/maintenance", handler.ListMaintenanceWindows)
+		admin.Post("/maintenance", handler.CreateMaintenanceWindow)
+		admin.Delete("/maintenance/{id}", handler.CancelMaintenanceWindow)
 	})
 
+	handler.readyFile = cfg.ReadyFile
+	handler.maxClockSkew = cfg.MaxClockSkew
+	handler.maxTxAge = cfg.MaxTransactionAge
+
+	// Describe the routes just registered
+	doc, err := buildOpenAPI(router, handler)
+	if err != nil {
+		slog.Error("failed to build OpenAPI document", "error", err)
+	}
+	handler.openAPI = doc
+
+	// Bidirectional evaluation stream, served by StartGRPC
+	grpcServer := grpc.NewServer()
+	ospreypb.RegisterEvaluationServer(grpcServer, NewStreamServer(handler))
+
 	return &Server{
 		router:  router,
 		handler: handler,
+		grpc:    grpcServer,
 		config:  cfg,
 	}
 }
@@ -85,11 +282,52 @@ func (s *Server) Start() error {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	return s.server.ListenAndServe()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.handler.markReady()
+	return s.server.Serve(ln)
+}
+
+// StartGRPC starts the gRPC evaluation stream on the configured gRPC port.
+// It returns grpc.ErrServerStopped once the server is shut down.
+func (s *Server) StartGRPC() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.config.Host, s.config.GRPCPort))
+	if err != nil {
+		return err
+	}
+	return s.grpc.Serve(lis)
+}
+
+// Drain fails readiness and removes the readiness file, so the instance is
+// taken out of rotation while it still serves requests.
+func (s *Server) Drain() {
+	s.handler.drain()
 }
 
-// Shutdown gracefully shuts down the server.
+// Shutdown gracefully shuts down the server and interrupts running background
+// jobs, tenant migrations and retention runs. Open evaluation streams are given until ctx is
+// done to finish.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.Drain()
+	defer s.handler.jobs.Stop()
+	defer s.handler.migrations.Stop()
+	defer s.handler.retention.Stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	defer func() {
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			s.grpc.Stop()
+		}
+	}()
+
 	if s.server == nil {
 		return nil
 	}
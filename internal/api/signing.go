@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/signing"
+)
+
+// WithSigner signs evaluation responses with a detached JWS in the
+// X-JWS-Signature header and publishes the key at /.well-known/jwks.json.
+func WithSigner(s *signing.Signer) Option {
+	return func(h *Handler) {
+		h.signer = s
+	}
+}
+
+// JWKS returns the public key that verifies response and webhook
+// signatures.
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	if h.signer == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "response signing not configured",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.signer.JWKS())
+}
+
+// writeSignedJSON writes data like writeJSON, with the detached JWS of the
+// exact body bytes when a signer is configured. A body that fails to sign is
+// sent unsigned.
+func (h *Handler) writeSignedJSON(w http.ResponseWriter, status int, data interface{}) {
+	if h.signer == nil {
+		writeJSON(w, status, data)
+		return
+	}
+
+	body, err := encodeJSON(data)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to encode response",
+		})
+		return
+	}
+	h.writeSignedBody(w, status, body)
+}
+
+// writeSignedBody writes an encoded JSON body, with its detached JWS when a
+// signer is configured.
+func (h *Handler) writeSignedBody(w http.ResponseWriter, status int, body []byte) {
+	if h.signer != nil {
+		if jws, err := h.signer.Sign(body); err != nil {
+			slog.Error("failed to sign response", "error", err)
+		} else {
+			w.Header().Set(signing.HeaderSignature, jws)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// encodeJSON encodes data exactly as writeJSON writes it.
+func encodeJSON(data interface{}) ([]byte, error) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(data); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
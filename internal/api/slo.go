@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/slo"
+)
+
+// WithSLO sets the tracker that counts requests against the endpoints'
+// service level objectives, reported by /slo and /metrics.
+func WithSLO(t *slo.Tracker) Option {
+	return func(h *Handler) {
+		h.slo = t
+	}
+}
+
+// SLO returns each objective's error budget and burn rates on this instance.
+func (h *Handler) SLO(w http.ResponseWriter, r *http.Request) {
+	if !h.slo.Enabled() {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "no service level objectives configured",
+		})
+		return
+	}
+
+	reports := h.slo.Reports()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"objectives": reports,
+		"count":      len(reports),
+		"window":     h.slo.Window().String(),
+		"since":      h.slo.Since().UTC(),
+	})
+}
+
+// writeSLOMetrics appends the objectives' counts, budgets and burn rates in
+// the Prometheus text format.
+func (h *Handler) writeSLOMetrics(b *strings.Builder) {
+	if !h.slo.Enabled() {
+		return
+	}
+
+	type sli struct {
+		route, name string
+		report      *slo.SLIReport
+	}
+	var slis []sli
+	reports := h.slo.Reports()
+	for i := range reports {
+		slis = append(slis, sli{reports[i].Route, "availability", &reports[i].Availability})
+		if reports[i].Latency != nil {
+			slis = append(slis, sli{reports[i].Route, "latency", reports[i].Latency})
+		}
+	}
+
+	write := func(name, kind, help string, value func(*slo.SLIReport) float64) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range slis {
+			fmt.Fprintf(b, "%s{route=\"%s\",sli=\"%s\"} %g\n", name, labelEscaper.Replace(s.route), s.name, value(s.report))
+		}
+	}
+	write("osprey_slo_target", "gauge", "Fraction of requests that must be good.",
+		func(r *slo.SLIReport) float64 { return r.Target })
+	write("osprey_slo_good_requests", "gauge", "Good requests within the SLO window.",
+		func(r *slo.SLIReport) float64 { return float64(r.Good) })
+	write("osprey_slo_bad_requests", "gauge", "Bad requests within the SLO window.",
+		func(r *slo.SLIReport) float64 { return float64(r.Bad) })
+	write("osprey_slo_error_budget_remaining", "gauge", "Fraction of the error budget left; negative once overspent.",
+		func(r *slo.SLIReport) float64 { return r.BudgetRemaining })
+
+	const burn = "osprey_slo_burn_rate"
+	fmt.Fprintf(b, "# HELP %s Rate the error budget is spent at; 1 spends it exactly over the SLO window.\n# TYPE %s gauge\n", burn, burn)
+	for _, s := range slis {
+		for _, w := range slo.BurnWindows {
+			if rate, ok := s.report.BurnRates[w.Name]; ok {
+				fmt.Fprintf(b, "%s{route=\"%s\",sli=\"%s\",window=\"%s\"} %g\n", burn, labelEscaper.Replace(s.route), s.name, w.Name, rate)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/cluster"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/state"
+)
+
+// WithState sets the state manager, so the API and Git sync serialize applies.
+func WithState(mgr *state.Manager) Option {
+	return func(h *Handler) {
+		h.state = mgr
+	}
+}
+
+// WithCluster sets the cluster coordinator, so POST /rules/reload and
+// POST /typologies/reload reload every replica.
+func WithCluster(c *cluster.Coordinator) Option {
+	return func(h *Handler) {
+		h.cluster = c
+	}
+}
+
+// GetState returns the stored rules and typologies in the PUT /state format.
+func (h *Handler) GetState(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	spec, err := h.state.Current(r.Context())
+	if err != nil {
+		slog.Error("failed to read state", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to read state",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, spec)
+}
+
+// PutState replaces all rules and typologies with the request body and
+// returns the plan. With ?dryRun=true the plan is returned without applying it.
+func (h *Handler) PutState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.rejectManaged(w) {
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	var spec state.Spec
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body: " + err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.state.Plan(ctx, &spec)
+	if errors.Is(err, state.ErrInvalid) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to plan state", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to plan state",
+		})
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	if dryRun || plan.Empty() {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"plan":    plan,
+			"applied": !dryRun,
+		})
+		return
+	}
+
+	if !h.checkGuardrails(w, r, state.GlobalTenantID, "PUT /state", plan.Mutation()) {
+		return
+	}
+
+	if err := h.state.Apply(ctx, plan); err != nil {
+		slog.Error("failed to apply state", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to apply state: " + err.Error(),
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditApply, domain.AuditState, "", nil, plan)
+	slog.Info("state applied",
+		"rule_changes", len(plan.Rules),
+		"typology_changes", len(plan.Typologies),
+		"unchanged", plan.Unchanged,
+	)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"plan":    plan,
+		"applied": true,
+	})
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/features"
+	"github.com/opensource-finance/osprey/internal/stats"
+)
+
+// WithTopTracker sets the tracker of the heaviest tenants and entities.
+// Without one, /stats/top answers 501.
+func WithTopTracker(t *stats.Tracker) Option {
+	return func(h *Handler) {
+		h.top = t
+	}
+}
+
+// WithAggregatePrivacy sets the protection of values shared across tenants
+// by /stats/top and /stats/benchmark. Without one, they are exact.
+// Operator views such as /admin/tenants/health are never protected.
+func WithAggregatePrivacy(p *stats.Privacy) Option {
+	return func(h *Handler) {
+		h.privacy = p
+	}
+}
+
+// TopStats returns the tenants and entities with the most evaluations and
+// alerts over the last one to two tracker windows, across tenants.
+// Query param: n, entries per list (default 10, at most 1000). With
+// aggregate privacy, entities are left out and tenant counts are protected.
+func (h *Handler) TopStats(w http.ResponseWriter, r *http.Request) {
+	if h.top == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{
+			"error": "top tracking not enabled",
+		})
+		return
+	}
+
+	n := stats.DefaultTopN
+	if v := r.URL.Query().Get("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > stats.TopCapacity {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "n must be between 1 and 1000",
+			})
+			return
+		}
+		n = parsed
+	}
+
+	writeJSON(w, http.StatusOK, h.privacy.Top(h.top.Top(n)))
+}
+
+// ScoreDistribution returns histograms of the tenant's final evaluation
+// scores and of each typology's scores, with percentile markers.
+// Query params: window (e.g. 1h, 24h, 7d; default 24h, at most 90d) or
+// since and until (RFC 3339), and buckets (a divisor of 100, default 20).
+func (h *Handler) ScoreDistribution(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	until := time.Now().UTC()
+	if v := query.Get("until"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "until must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		until = parsed
+	}
+	window := stats.DefaultWindow
+	if v := query.Get("window"); v != "" {
+		parsed, err := stats.ParseWindow(v)
+		if err != nil || parsed <= 0 || parsed > stats.MaxWindow {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "window must be a duration such as 1h, 24h or 7d, at most 90d",
+			})
+			return
+		}
+		window = parsed
+	}
+	since := until.Add(-window)
+	if v := query.Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		since = parsed
+	}
+	if !since.Before(until) || until.Sub(since) > stats.MaxWindow {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "since must be before until, at most 90 days apart",
+		})
+		return
+	}
+	buckets := stats.DefaultBuckets
+	if v := query.Get("buckets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || !stats.ValidBuckets(n) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "buckets must divide 100, e.g. 10, 20, 50 or 100",
+			})
+			return
+		}
+		buckets = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	report, err := h.stats.ScoreDistribution(ctx, tenantID, since, until, buckets)
+	if err != nil {
+		slog.Error("failed to report score distribution", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to report score distribution",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Benchmark compares the tenant's alert rate, precision and latency with
+// percentile bands of other tenants of similar volume. It is opt-in: only
+// tenants with the benchmarking feature flag are compared, and only they
+// may ask. Query param: window (e.g. 7d; default 30d, at most 90d).
+func (h *Handler) Benchmark(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	window := stats.DefaultBenchmarkWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := stats.ParseWindow(v)
+		if err != nil || parsed <= 0 || parsed > stats.MaxWindow {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "window must be a duration such as 24h or 7d, at most 90d",
+			})
+			return
+		}
+		window = parsed
+	}
+
+	if !h.features.Enabled(ctx, tenantID, features.Benchmarking) {
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"error": "benchmarking is opt-in; enable the benchmarking feature flag to compare with other tenants",
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	until := time.Now().UTC()
+	optedIn := func(peerID string) bool {
+		return h.features.Enabled(ctx, peerID, features.Benchmarking)
+	}
+	report, err := h.stats.Benchmark(ctx, tenantID, until.Add(-window), until, optedIn, h.privacy)
+	if err != nil {
+		slog.Error("failed to report benchmark", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to report benchmark",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/pkg/ospreypb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// TenantIDMetadata is the gRPC metadata key for tenant ID.
+	TenantIDMetadata = "x-tenant-id"
+
+	// PrincipalMetadata identifies the authenticated caller of a stream, like
+	// PrincipalHeader.
+	PrincipalMetadata = "x-principal"
+
+	// streamLanes is how many transactions of one stream are evaluated at
+	// once. Transactions of the same debtor account share a lane, so they are
+	// decided in the order they were sent.
+	streamLanes = 16
+
+	// streamLaneBuffer is how many transactions may wait in a lane before the
+	// stream stops reading from the client.
+	streamLaneBuffer = 64
+)
+
+// StreamServer serves the Evaluation gRPC service: a bidirectional stream of
+// transactions in and decisions out, matched by correlation ID.
+type StreamServer struct {
+	ospreypb.UnimplementedEvaluationServer
+	handler *Handler
+}
+
+// NewStreamServer creates the gRPC evaluation service on top of handler.
+func NewStreamServer(handler *Handler) *StreamServer {
+	return &StreamServer{handler: handler}
+}
+
+// Stream evaluates every transaction the client sends and answers each with
+// a decision carrying its correlation ID. Decisions of different accounts
+// may come back out of order; those of one debtor account never do. A
+// transaction that can't be evaluated gets a response with an error code
+// rather than ending the stream.
+func (s *StreamServer) Stream(stream ospreypb.Evaluation_StreamServer) error {
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	tenantID := firstMetadata(md, TenantIDMetadata)
+	if tenantID == "" {
+		return status.Error(codes.InvalidArgument, TenantIDMetadata+" metadata is required")
+	}
+	principal := firstMetadata(md, PrincipalMetadata)
+
+	responses := make(chan *ospreypb.EvaluateResponse, streamLanes)
+	sent := make(chan error, 1)
+	go func() {
+		// Keep draining after a failed send so the lanes never block
+		var sendErr error
+		for resp := range responses {
+			if sendErr == nil {
+				sendErr = stream.Send(resp)
+			}
+		}
+		sent <- sendErr
+	}()
+
+	var wg sync.WaitGroup
+	lanes := make([]chan *ospreypb.EvaluateRequest, streamLanes)
+	for i := range lanes {
+		lanes[i] = make(chan *ospreypb.EvaluateRequest, streamLaneBuffer)
+		wg.Add(1)
+		go func(lane <-chan *ospreypb.EvaluateRequest) {
+			defer wg.Done()
+			for req := range lane {
+				responses <- s.evaluate(ctx, tenantID, principal, req)
+			}
+		}(lanes[i])
+	}
+
+	var recvErr error
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				recvErr = err
+			}
+			break
+		}
+		lanes[streamLane(req)] <- req
+	}
+
+	for _, lane := range lanes {
+		close(lane)
+	}
+	wg.Wait()
+	close(responses)
+
+	if err := <-sent; err != nil {
+		return err
+	}
+	return recvErr
+}
+
+// evaluate decides one streamed transaction the way POST /evaluate does.
+func (s *StreamServer) evaluate(ctx context.Context, tenantID, principal string, msg *ospreypb.EvaluateRequest) *ospreypb.EvaluateResponse {
+	start := time.Now()
+	h := s.handler
+	resp := &ospreypb.EvaluateResponse{CorrelationId: msg.GetCorrelationId()}
+
+	fail := func(err error) *ospreypb.EvaluateResponse {
+		code := codes.Internal
+		var evalErr *evaluationError
+		if errors.As(err, &evalErr) {
+			switch evalErr.status {
+			case http.StatusBadRequest:
+				code = codes.InvalidArgument
+			case http.StatusConflict:
+				code = codes.AlreadyExists
+			case http.StatusServiceUnavailable:
+				code = codes.Unavailable
+			}
+		}
+		resp.ErrorCode = int32(code)
+		resp.Error = err.Error()
+		return resp
+	}
+
+	if h.migrations.Frozen(tenantID) {
+		return fail(&evaluationError{status: http.StatusServiceUnavailable, message: "tenant is frozen for migration cutover"})
+	}
+	if h.mode == domain.ModeCompliance && !h.hasLoadedTypologies() {
+		return fail(&evaluationError{status: http.StatusServiceUnavailable, message: "compliance mode requires typologies to be loaded"})
+	}
+
+	traceID := uuid.New().String()
+	ctx = context.WithValue(ctx, TenantIDKey, tenantID)
+	ctx = context.WithValue(ctx, TraceIDKey, traceID)
+	ctx = domain.WithRequestContext(ctx, &domain.RequestContext{
+		TenantID:  tenantID,
+		TraceID:   traceID,
+		RequestID: msg.GetCorrelationId(),
+		Principal: principal,
+	})
+
+	req, err := transactionRequest(msg)
+	if err != nil {
+		return fail(err)
+	}
+	unknownType, err := h.checkTransaction(ctx, tenantID, req)
+	if err != nil {
+		return fail(err)
+	}
+	tx, err := h.saveTransaction(ctx, tenantID, req)
+	if err != nil {
+		return fail(err)
+	}
+	evaluation, err := h.decide(ctx, tx, req, unknownType, traceID, start)
+	if err != nil {
+		return fail(err)
+	}
+
+	resp.EvaluationId = evaluation.ID
+	resp.TxId = tx.ID
+	resp.Status = evaluation.Status
+	resp.Score = evaluation.Score
+	resp.Reasons = tadp.GetReasons(evaluation)
+	resp.Actions = evaluation.Actions()
+	resp.TotalMs = time.Since(start).Milliseconds()
+	return resp
+}
+
+// transactionRequest converts a streamed transaction to its JSON API form.
+// The amount is read from its decimal string when set, as the JSON API
+// reads it, and from the double otherwise.
+func transactionRequest(msg *ospreypb.EvaluateRequest) (*TransactionRequest, error) {
+	value := domain.DecimalFromFloat(msg.GetAmount().GetValue())
+	if decimal := msg.GetAmount().GetDecimal(); decimal != "" {
+		parsed, err := domain.ParseDecimal(decimal)
+		if err != nil {
+			return nil, &evaluationError{status: http.StatusBadRequest, message: "invalid amount: " + err.Error()}
+		}
+		value = parsed
+	}
+	components, err := amountComponents(msg.GetAmount().GetComponents())
+	if err != nil {
+		return nil, err
+	}
+
+	req := &TransactionRequest{
+		Type: msg.GetType(),
+		Debtor: PartyInfo{
+			ID:        msg.GetDebtor().GetId(),
+			AccountID: msg.GetDebtor().GetAccountId(),
+			Name:      msg.GetDebtor().GetName(),
+			Country:   msg.GetDebtor().GetCountry(),
+		},
+		Creditor: PartyInfo{
+			ID:        msg.GetCreditor().GetId(),
+			AccountID: msg.GetCreditor().GetAccountId(),
+			Name:      msg.GetCreditor().GetName(),
+			Country:   msg.GetCreditor().GetCountry(),
+		},
+		Amount: AmountInfo{
+			Value:      value,
+			Currency:   msg.GetAmount().GetCurrency(),
+			Components: components,
+		},
+		TxID:           msg.GetTxId(),
+		VelocityWindow: int(msg.GetVelocityWindow()),
+		ReversalOf:     msg.GetReversalOf(),
+		PartOfBatch:    msg.GetPartOfBatch(),
+		RelatedTo:      msg.GetRelatedTo(),
+	}
+	if msg.GetMetadata() != nil {
+		req.Metadata = msg.GetMetadata().AsMap()
+	}
+	if msg.GetTimestamp() != nil {
+		timestamp := msg.GetTimestamp().AsTime()
+		req.Timestamp = &timestamp
+	}
+	return req, nil
+}
+
+// amountComponents converts streamed amount components, whose amounts are
+// decimal strings, to their JSON API form.
+func amountComponents(msg *ospreypb.AmountComponents) (*domain.AmountComponents, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	components := &domain.AmountComponents{
+		FXCurrency: msg.GetFxCurrency(),
+		FXRate:     msg.GetFxRate(),
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *domain.Decimal
+	}{
+		{"principal", msg.GetPrincipal(), &components.Principal},
+		{"fee", msg.GetFee(), &components.Fee},
+		{"fx_amount", msg.GetFxAmount(), &components.FXAmount},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := domain.ParseDecimal(field.value)
+		if err != nil {
+			return nil, &evaluationError{status: http.StatusBadRequest, message: "invalid amount.components." + field.name + ": " + err.Error()}
+		}
+		*field.dst = d
+	}
+	return components, nil
+}
+
+// streamLane picks the lane of a transaction by its debtor account, falling
+// back to the debtor when the account is not set.
+func streamLane(msg *ospreypb.EvaluateRequest) int {
+	key := msg.GetDebtor().GetAccountId()
+	if key == "" {
+		key = msg.GetDebtor().GetId()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % streamLanes)
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
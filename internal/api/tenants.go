@@ -0,0 +1,127 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/sandbox"
+	"github.com/opensource-finance/osprey/internal/worker"
+)
+
+// tenantHealthWindow is the period alert rates are reported over.
+const tenantHealthWindow = time.Hour
+
+// WithSandbox sets the purger that expires sandbox tenants' data. Sandbox
+// tenants are left out of the tenant health summary unless asked for.
+func WithSandbox(p *sandbox.Purger) Option {
+	return func(h *Handler) {
+		h.sandbox = p
+	}
+}
+
+// TenantHealth summarizes one tenant for hosted operators.
+type TenantHealth struct {
+	TenantID         string            `json:"tenantId"`
+	Rules            int               `json:"rules"`      // rules that apply, global included
+	Typologies       int               `json:"typologies"` // typologies that apply, global included
+	Evaluations      int               `json:"evaluationsLastHour"`
+	Alerts           int               `json:"alertsLastHour"`
+	AlertRate        float64           `json:"alertRate"` // alerts per evaluation over the last hour
+	LastEvaluationAt *time.Time        `json:"lastEvaluationAt,omitempty"`
+	Silent           bool              `json:"silent"` // evaluated before, but not in the last hour
+	Worker           string            `json:"worker"` // async worker subscription status
+	Queue            *worker.TenantLag `json:"queue,omitempty"`
+	Sandbox          bool              `json:"sandbox,omitempty"` // data expires; listed with ?sandbox=true
+}
+
+// TenantsHealth summarizes every known tenant: rule and typology counts, the
+// alert rate over the last hour, the last evaluation and the async worker's
+// subscription, so operators can spot a tenant whose integration stopped.
+// Tenants are known from their rules, typologies, evaluations or queue.
+// Sandbox tenants are only listed with ?sandbox=true. Counts are exact:
+// this is an operator view, so aggregate privacy doesn't apply.
+func (h *Handler) TenantsHealth(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	activity, err := h.repo.ListTenantActivity(r.Context(), now.Add(-tenantHealthWindow))
+	if err != nil {
+		slog.Error("failed to list tenant activity", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to load tenant activity",
+		})
+		return
+	}
+
+	tenants := make(map[string]*TenantHealth)
+	tenant := func(tenantID string) *TenantHealth {
+		t := tenants[tenantID]
+		if t == nil {
+			t = &TenantHealth{TenantID: tenantID}
+			tenants[tenantID] = t
+		}
+		return t
+	}
+
+	for _, a := range activity {
+		t := tenant(a.TenantID)
+		t.Evaluations = a.Evaluations
+		t.Alerts = a.Alerts
+		if t.Evaluations > 0 {
+			t.AlertRate = float64(t.Alerts) / float64(t.Evaluations)
+		}
+		last := a.LastEvaluationAt
+		t.LastEvaluationAt = &last
+		t.Silent = t.Evaluations == 0
+	}
+	if h.engine != nil {
+		for _, rule := range h.engine.GetLoadedRules() {
+			if rule.TenantID != "" && rule.TenantID != rules.GlobalTenantID {
+				tenant(rule.TenantID)
+			}
+		}
+	}
+	if h.typologyEngine != nil {
+		for _, typology := range h.typologyEngine.GetLoadedTypologies() {
+			if typology.TenantID != "" && typology.TenantID != rules.GlobalTenantID {
+				tenant(typology.TenantID)
+			}
+		}
+	}
+	for _, lag := range h.queue.QueueStatus().Tenants {
+		if lag.TenantID != worker.AllTenants {
+			tenant(lag.TenantID).Queue = &lag
+		}
+	}
+
+	includeSandbox := r.URL.Query().Get("sandbox") == "true"
+	out := make([]*TenantHealth, 0, len(tenants))
+	for _, t := range tenants {
+		t.Sandbox = h.sandbox.IsSandbox(t.TenantID)
+		if t.Sandbox && !includeSandbox {
+			continue
+		}
+		if h.engine != nil {
+			t.Rules = len(h.engine.GetTenantRules(t.TenantID))
+		}
+		if h.typologyEngine != nil {
+			t.Typologies = len(h.typologyEngine.GetTenantTypologies(t.TenantID))
+		}
+		t.Worker = h.queue.SubscriptionStatus(t.TenantID)
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TenantID < out[j].TenantID })
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tenants": out,
+		"count":   len(out),
+	})
+}
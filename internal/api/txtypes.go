@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/opensource-finance/osprey/internal/txtypes"
+)
+
+// WithTxTypes sets the policy that restricts the transaction types tenants
+// may evaluate.
+func WithTxTypes(p *txtypes.Policy) Option {
+	return func(h *Handler) {
+		h.txTypes = p
+	}
+}
+
+// ListTxTypes returns the tenant's allowed transaction types, what happens to
+// other types, and how often each unknown type was seen since startup.
+func (h *Handler) ListTxTypes(w http.ResponseWriter, r *http.Request) {
+	tenantID := GetTenantID(r.Context())
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"allowed": h.txTypes.Allowed(tenantID),
+		"unknown": h.txTypes.Unknown(tenantID),
+		"action":  h.txTypes.Action(),
+	})
+}
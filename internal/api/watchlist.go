@@ -0,0 +1,267 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/corridor"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Limits of the limit query parameter of GET /lists.
+const (
+	defaultListWatchlistLimit = 100
+	maxListWatchlistLimit     = 1000
+)
+
+// WatchlistEntryRequest is the request body for POST /lists and PUT /lists/{id}.
+type WatchlistEntryRequest struct {
+	ListType  string `json:"listType"`
+	PartyID   string `json:"partyId,omitempty"`
+	AccountID string `json:"accountId,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// validate normalizes the request and returns an error message, or "".
+func (req *WatchlistEntryRequest) validate() string {
+	req.PartyID = strings.TrimSpace(req.PartyID)
+	req.AccountID = strings.TrimSpace(req.AccountID)
+	req.Name = strings.TrimSpace(req.Name)
+	req.Country = corridor.NormalizeCountry(req.Country)
+
+	if !domain.ValidWatchlistType(req.ListType) {
+		return "listType must be one of: " + strings.Join(domain.WatchlistTypes, ", ")
+	}
+	if req.PartyID == "" && req.AccountID == "" && req.Name == "" {
+		return "partyId, accountId or name is required"
+	}
+	if req.Country != "" && (req.Country == domain.CorridorWildcard || !corridor.ValidCountry(req.Country)) {
+		return "country must be a 2-letter ISO country code"
+	}
+	return ""
+}
+
+// ListWatchlist returns the tenant's watchlist entries ordered by name.
+// Query params: type, limit (default 100), offset.
+func (h *Handler) ListWatchlist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	query := r.URL.Query()
+
+	listType := query.Get("type")
+	if listType != "" && !domain.ValidWatchlistType(listType) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "type must be one of: " + strings.Join(domain.WatchlistTypes, ", "),
+		})
+		return
+	}
+	limit := defaultListWatchlistLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListWatchlistLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 1000",
+			})
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "offset must be a non-negative integer",
+			})
+			return
+		}
+		offset = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	entries, err := h.repo.ListWatchlistEntries(ctx, tenantID, listType, offset, limit)
+	if err != nil {
+		slog.Error("failed to list watchlist entries", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list watchlist entries",
+		})
+		return
+	}
+	if entries == nil {
+		entries = []*domain.WatchlistEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// GetWatchlistEntry returns a watchlist entry.
+func (h *Handler) GetWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entryID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	entry, err := h.repo.GetWatchlistEntry(ctx, tenantID, entryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "watchlist entry not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get watchlist entry", "id", entryID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get watchlist entry",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// CreateWatchlistEntry adds a party to one of the tenant's watchlists. It
+// applies to the next evaluation.
+func (h *Handler) CreateWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	h.saveWatchlistEntry(w, r, uuid.New().String(), http.StatusCreated)
+}
+
+// UpdateWatchlistEntry replaces a watchlist entry.
+func (h *Handler) UpdateWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	h.saveWatchlistEntry(w, r, chi.URLParam(r, "id"), http.StatusOK)
+}
+
+// saveWatchlistEntry validates the request body and saves it as entryID.
+// Updates fail with 404 if the entry does not exist.
+func (h *Handler) saveWatchlistEntry(w http.ResponseWriter, r *http.Request, entryID string, status int) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req WatchlistEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	entry := &domain.WatchlistEntry{
+		ID:        entryID,
+		TenantID:  tenantID,
+		ListType:  req.ListType,
+		PartyID:   req.PartyID,
+		AccountID: req.AccountID,
+		Name:      req.Name,
+		Country:   req.Country,
+		Note:      req.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var previous *domain.WatchlistEntry
+	if status == http.StatusOK {
+		existing, err := h.repo.GetWatchlistEntry(ctx, tenantID, entryID)
+		if errors.Is(err, repository.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": "watchlist entry not found",
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("failed to get watchlist entry", "id", entryID, "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to save watchlist entry",
+			})
+			return
+		}
+		entry.CreatedAt = existing.CreatedAt
+		previous = existing
+	}
+
+	if err := h.repo.SaveWatchlistEntry(ctx, tenantID, entry); err != nil {
+		slog.Error("failed to save watchlist entry", "id", entryID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to save watchlist entry",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, auditSaveAction(previous != nil), domain.AuditWatchlistEntry, entryID, previous, entry)
+	slog.Info("watchlist entry saved", "id", entryID, "tenant_id", tenantID, "list_type", entry.ListType)
+	writeJSON(w, status, entry)
+}
+
+// DeleteWatchlistEntry removes a watchlist entry.
+func (h *Handler) DeleteWatchlistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	entryID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	existing, err := h.repo.GetWatchlistEntry(ctx, tenantID, entryID)
+	if err == nil {
+		err = h.repo.DeleteWatchlistEntry(ctx, tenantID, entryID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "watchlist entry not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete watchlist entry", "id", entryID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete watchlist entry",
+		})
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditWatchlistEntry, entryID, existing, nil)
+	slog.Info("watchlist entry deleted", "id", entryID, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Watchlist entry deleted.",
+	})
+}
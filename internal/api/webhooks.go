@@ -0,0 +1,251 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// maxListDeliveriesLimit caps the limit query parameter of GET /webhooks/{id}/deliveries.
+const maxListDeliveriesLimit = 500
+
+// CreateWebhookRequest is the request body for POST /webhooks.
+type CreateWebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"` // Generated when empty
+
+	// Feed is "alerts" (default) or "decisions" for every evaluation
+	Feed      string                `json:"feed,omitempty"`
+	Filter    *domain.WebhookFilter `json:"filter,omitempty"`
+	BatchSize int                   `json:"batchSize,omitempty"` // decisions per request; default 100
+}
+
+// ListWebhooks returns the tenant's webhooks. Secrets are never listed.
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	webhooks, err := h.repo.ListWebhooks(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to list webhooks", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list webhooks",
+		})
+		return
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// CreateWebhook registers a URL to receive the tenant's ALRT evaluations, or
+// with the decisions feed every evaluation, in batches. Either feed can be
+// narrowed by a filter on status, score range and typology. The response is
+// the only time the signing secret is returned.
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON request body",
+		})
+		return
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "url must be an absolute http or https URL",
+		})
+		return
+	}
+
+	if msg := validateWebhookFeed(&req); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": msg,
+		})
+		return
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			slog.Error("failed to generate webhook secret", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to create webhook",
+			})
+			return
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	webhook := &domain.Webhook{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		URL:       target.String(),
+		Secret:    secret,
+		Feed:      req.Feed,
+		Filter:    req.Filter,
+		BatchSize: req.BatchSize,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.repo.SaveWebhook(ctx, tenantID, webhook); err != nil {
+		slog.Error("failed to save webhook", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to create webhook",
+		})
+		return
+	}
+
+	audited := *webhook
+	audited.Secret = "" // the audit log must not disclose the signing secret
+	h.recordAudit(ctx, domain.AuditCreate, domain.AuditWebhook, webhook.ID, nil, &audited)
+	slog.Info("webhook created", "id", webhook.ID, "tenant_id", tenantID, "url", webhook.URL)
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+// validateWebhookFeed defaults the feed and checks the feed, filter and batch
+// size, returning an error message or "".
+func validateWebhookFeed(req *CreateWebhookRequest) string {
+	if req.Feed == "" {
+		req.Feed = domain.WebhookFeedAlerts
+	}
+	if req.Feed != domain.WebhookFeedAlerts && req.Feed != domain.WebhookFeedDecisions {
+		return "feed must be alerts or decisions"
+	}
+	if req.BatchSize < 0 || req.BatchSize > domain.MaxWebhookBatchSize {
+		return "batchSize must be between 0 and " + strconv.Itoa(domain.MaxWebhookBatchSize)
+	}
+	if f := req.Filter; f != nil {
+		for _, status := range f.Statuses {
+			if status != domain.StatusAlert && status != domain.StatusNoAlert {
+				return "filter statuses must be ALRT or NALT"
+			}
+		}
+		if f.MinScore != nil && f.MaxScore != nil && *f.MinScore > *f.MaxScore {
+			return "filter minScore must not exceed maxScore"
+		}
+	}
+	return ""
+}
+
+// DeleteWebhook removes a webhook. Its pending deliveries are marked failed
+// instead of being sent.
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	webhookID := chi.URLParam(r, "id")
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	existing, err := h.repo.GetWebhook(ctx, tenantID, webhookID)
+	if err == nil {
+		err = h.repo.DeleteWebhook(ctx, tenantID, webhookID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "webhook not found",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete webhook", "id", webhookID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to delete webhook",
+		})
+		return
+	}
+
+	existing.Secret = ""
+	h.recordAudit(ctx, domain.AuditDelete, domain.AuditWebhook, webhookID, existing, nil)
+	slog.Info("webhook deleted", "id", webhookID, "tenant_id", tenantID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Webhook deleted; pending deliveries will not be sent.",
+	})
+}
+
+// ListWebhookDeliveries returns a webhook's delivery log, newest first.
+// Query params: limit (default 100).
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := GetTenantID(ctx)
+	webhookID := chi.URLParam(r, "id")
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListDeliveriesLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and 500",
+			})
+			return
+		}
+		limit = n
+	}
+
+	if h.repo == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "repository not available",
+		})
+		return
+	}
+
+	if _, err := h.repo.GetWebhook(ctx, tenantID, webhookID); errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "webhook not found",
+		})
+		return
+	}
+
+	deliveries, err := h.repo.ListWebhookDeliveries(ctx, tenantID, webhookID, limit)
+	if err != nil {
+		slog.Error("failed to list webhook deliveries", "id", webhookID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to list webhook deliveries",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
@@ -0,0 +1,181 @@
+// Package auditlog maintains tamper-evident, hash-chained logs of evaluations
+// and of configuration changes.
+//
+// Each tenant has its own chain. Every record stores the hash of the record
+// before it, and its own hash covers its content plus that link, so editing,
+// reordering or deleting any record invalidates the rest of the chain.
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// GenesisHash is the PrevHash of the first record in every chain.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// appendRetries bounds retries when another instance appends the same sequence number.
+const appendRetries = 3
+
+// verifyPageSize is how many records are read per query during verification.
+const verifyPageSize = 1000
+
+// Log appends to and verifies tenant evaluation chains.
+type Log struct {
+	repo domain.Repository
+	mu   sync.Mutex // serializes appends within this process
+}
+
+// NewLog creates a new evaluation log.
+func NewLog(repo domain.Repository) *Log {
+	return &Log{repo: repo}
+}
+
+// Hash computes a record's hash from its sequence, content, timestamp and PrevHash.
+func Hash(rec *domain.EvaluationLogRecord) string {
+	h := sha256.New()
+	for _, part := range []string{
+		strconv.FormatInt(rec.Seq, 10),
+		rec.EvaluationID,
+		rec.TxID,
+		rec.CreatedAt.UTC().Format(time.RFC3339Nano),
+		rec.Payload,
+		rec.PrevHash,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0}) // separator so fields can't run into each other
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Append adds an evaluation to the end of the tenant's chain.
+func (l *Log) Append(ctx context.Context, tenantID string, eval *domain.Evaluation) (*domain.EvaluationLogRecord, error) {
+	payload, err := json.Marshal(eval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode evaluation: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < appendRetries; attempt++ {
+		seq, prevHash := int64(1), GenesisHash
+		last, err := l.repo.GetLastEvaluationLog(ctx, tenantID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to read evaluation log head: %w", err)
+		}
+		if last != nil {
+			seq, prevHash = last.Seq+1, last.Hash
+		}
+
+		rec := &domain.EvaluationLogRecord{
+			TenantID:     tenantID,
+			Seq:          seq,
+			EvaluationID: eval.ID,
+			TxID:         eval.TxID,
+			Payload:      string(payload),
+			PrevHash:     prevHash,
+			// Microsecond precision survives a round trip through every supported database
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		rec.Hash = Hash(rec)
+
+		// A conflict means another instance took this sequence number; re-read the head and retry
+		if lastErr = l.repo.AppendEvaluationLog(ctx, tenantID, rec); lastErr == nil {
+			return rec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to append evaluation log: %w", lastErr)
+}
+
+// VerifyResult reports the outcome of verifying a tenant's chain.
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Records  int64  `json:"records"`            // Records checked
+	HeadHash string `json:"headHash,omitempty"` // Hash of the last valid record
+	BrokenAt int64  `json:"brokenAt,omitempty"` // Sequence number of the first invalid record
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify walks the tenant's entire chain and checks every link and hash.
+func (l *Log) Verify(ctx context.Context, tenantID string) (*VerifyResult, error) {
+	result := &VerifyResult{Valid: true}
+	expectedPrev := GenesisHash
+	var afterSeq int64
+
+	for {
+		page, err := l.repo.ListEvaluationLog(ctx, tenantID, afterSeq, verifyPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read evaluation log: %w", err)
+		}
+
+		for _, rec := range page {
+			if reason := check(rec, afterSeq+1, expectedPrev); reason != "" {
+				result.Valid = false
+				result.BrokenAt = rec.Seq
+				result.Reason = reason
+				return result, nil
+			}
+			result.Records++
+			result.HeadHash = rec.Hash
+			expectedPrev = rec.Hash
+			afterSeq = rec.Seq
+		}
+
+		if len(page) < verifyPageSize {
+			return result, nil
+		}
+	}
+}
+
+// check validates one record against its expected position and predecessor.
+func check(rec *domain.EvaluationLogRecord, expectedSeq int64, expectedPrev string) string {
+	if rec.Seq != expectedSeq {
+		return fmt.Sprintf("sequence gap: expected %d, found %d", expectedSeq, rec.Seq)
+	}
+	if rec.PrevHash != expectedPrev {
+		return "previous hash does not match the preceding record"
+	}
+	if Hash(rec) != rec.Hash {
+		return "record hash does not match its content"
+	}
+	return ""
+}
+
+// Repository wraps a repository so every saved evaluation is also appended to
+// the tenant's hash-chained log.
+type Repository struct {
+	domain.Repository
+	log *Log
+}
+
+// Wrap returns repo with evaluation logging enabled.
+func Wrap(repo domain.Repository) *Repository {
+	return &Repository{Repository: repo, log: NewLog(repo)}
+}
+
+// Unwrap returns the repository whose evaluations are chained.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveEvaluation saves the evaluation and appends it to the evaluation log.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if err := r.Repository.SaveEvaluation(ctx, tenantID, eval); err != nil {
+		return err
+	}
+	_, err := r.log.Append(ctx, tenantID, eval)
+	return err
+}
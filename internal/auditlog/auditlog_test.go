@@ -0,0 +1,209 @@
+package auditlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+func TestEvaluationLog(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "auditlog-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	base, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer base.Close()
+
+	repo := Wrap(base)
+	log := NewLog(base)
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	for i := 0; i < 3; i++ {
+		err := repo.SaveEvaluation(ctx, tenantID, &domain.Evaluation{
+			ID:        fmt.Sprintf("eval-%d", i),
+			TxID:      fmt.Sprintf("tx-%d", i),
+			Status:    domain.StatusNoAlert,
+			Score:     0.1,
+			Timestamp: time.Now().UTC(),
+		})
+		if err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+
+	t.Run("ChainsRecords", func(t *testing.T) {
+		records, err := base.ListEvaluationLog(ctx, tenantID, 0, 10)
+		if err != nil {
+			t.Fatalf("ListEvaluationLog failed: %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("expected 3 records, got %d", len(records))
+		}
+		if records[0].PrevHash != GenesisHash {
+			t.Errorf("expected first record to link to genesis, got %s", records[0].PrevHash)
+		}
+		if records[2].PrevHash != records[1].Hash {
+			t.Error("expected each record to link to the previous hash")
+		}
+	})
+
+	t.Run("VerifyIntactChain", func(t *testing.T) {
+		result, err := log.Verify(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if !result.Valid || result.Records != 3 {
+			t.Errorf("expected valid chain of 3 records, got %+v", result)
+		}
+	})
+
+	t.Run("TenantsHaveSeparateChains", func(t *testing.T) {
+		result, _ := log.Verify(ctx, "tenant-002")
+		if !result.Valid || result.Records != 0 {
+			t.Errorf("expected empty valid chain for other tenant, got %+v", result)
+		}
+	})
+
+	t.Run("DetectsAlteredRecord", func(t *testing.T) {
+		db, err := sql.Open("sqlite", tmpPath)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		_, err = db.Exec(`UPDATE evaluation_log SET payload = replace(payload, 'NALT', 'ALRT') WHERE tenant_id = ? AND seq = 2`, tenantID)
+		if err != nil {
+			t.Fatalf("failed to tamper with record: %v", err)
+		}
+
+		result, err := log.Verify(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if result.Valid || result.BrokenAt != 2 {
+			t.Errorf("expected chain broken at record 2, got %+v", result)
+		}
+	})
+}
+
+func TestTrail(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "auditlog-trail-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	repo, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	trail := NewTrail(repo)
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	created := &domain.RuleConfig{ID: "rule-001", Name: "High value", Expression: "amount > 1000.0"}
+	updated := &domain.RuleConfig{ID: "rule-001", Name: "High value", Expression: "amount > 5000.0"}
+	changes := []struct {
+		action        string
+		before, after any
+	}{
+		{domain.AuditCreate, nil, created},
+		{domain.AuditUpdate, created, updated},
+		{domain.AuditDelete, updated, nil},
+	}
+	for _, c := range changes {
+		_, err := trail.Record(ctx, tenantID, &domain.AuditEntry{
+			Action:     c.action,
+			Resource:   domain.AuditRule,
+			ResourceID: "rule-001",
+			Actor:      "alice",
+		}, c.before, c.after)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	t.Run("ChainsEntries", func(t *testing.T) {
+		entries, err := repo.ListAuditEntries(ctx, tenantID, domain.AuditFilter{})
+		if err != nil {
+			t.Fatalf("ListAuditEntries failed: %v", err)
+		}
+		if len(entries) != 3 {
+			t.Fatalf("expected 3 entries, got %d", len(entries))
+		}
+		if entries[0].PrevHash != GenesisHash || entries[2].PrevHash != entries[1].Hash {
+			t.Error("expected entries to be chained from genesis")
+		}
+		if entries[0].Before != nil || string(entries[2].After) != "" {
+			t.Errorf("expected no state where the rule didn't exist, got %s and %s", entries[0].Before, entries[2].After)
+		}
+		if string(entries[1].Before) != string(entries[0].After) {
+			t.Errorf("expected the update's previous state to be the created rule, got %s", entries[1].Before)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		entries, err := repo.ListAuditEntries(ctx, tenantID, domain.AuditFilter{Action: domain.AuditUpdate})
+		if err != nil {
+			t.Fatalf("ListAuditEntries failed: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Seq != 2 {
+			t.Errorf("expected only the update, got %+v", entries)
+		}
+	})
+
+	t.Run("VerifyIntactChain", func(t *testing.T) {
+		result, err := trail.Verify(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if !result.Valid || result.Records != 3 {
+			t.Errorf("expected valid chain of 3 entries, got %+v", result)
+		}
+	})
+
+	t.Run("DetectsAlteredEntry", func(t *testing.T) {
+		db, err := sql.Open("sqlite", tmpPath)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		_, err = db.Exec(`UPDATE audit_log SET actor = 'mallory' WHERE tenant_id = ? AND seq = 2`, tenantID)
+		if err != nil {
+			t.Fatalf("failed to tamper with entry: %v", err)
+		}
+
+		result, err := trail.Verify(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		if result.Valid || result.BrokenAt != 2 {
+			t.Errorf("expected chain broken at entry 2, got %+v", result)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Trail appends to and verifies tenant configuration audit chains. It is
+// chained the same way as the evaluation log, in a separate table.
+type Trail struct {
+	repo domain.Repository
+	mu   sync.Mutex // serializes appends within this process
+}
+
+// NewTrail creates a new configuration audit trail.
+func NewTrail(repo domain.Repository) *Trail {
+	return &Trail{repo: repo}
+}
+
+// HashEntry computes an entry's hash from its sequence, content, timestamp
+// and PrevHash.
+func HashEntry(entry *domain.AuditEntry) string {
+	h := sha256.New()
+	for _, part := range []string{
+		strconv.FormatInt(entry.Seq, 10),
+		entry.Action,
+		entry.Resource,
+		entry.ResourceID,
+		entry.Actor,
+		entry.ClientIP,
+		entry.RequestID,
+		string(entry.Before),
+		string(entry.After),
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		entry.PrevHash,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0}) // separator so fields can't run into each other
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Record appends a change to the end of the tenant's chain. before and after
+// are encoded as JSON; pass nil for a side where the resource didn't exist.
+func (t *Trail) Record(ctx context.Context, tenantID string, change *domain.AuditEntry, before, after any) (*domain.AuditEntry, error) {
+	beforeJSON, err := encodeState(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode previous state: %w", err)
+	}
+	afterJSON, err := encodeState(after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new state: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < appendRetries; attempt++ {
+		seq, prevHash := int64(1), GenesisHash
+		last, err := t.repo.GetLastAuditEntry(ctx, tenantID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to read audit log head: %w", err)
+		}
+		if last != nil {
+			seq, prevHash = last.Seq+1, last.Hash
+		}
+
+		entry := *change
+		entry.TenantID = tenantID
+		entry.Seq = seq
+		entry.Before = beforeJSON
+		entry.After = afterJSON
+		entry.PrevHash = prevHash
+		// Microsecond precision survives a round trip through every supported database
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = HashEntry(&entry)
+
+		// A conflict means another instance took this sequence number; re-read the head and retry
+		if lastErr = t.repo.AppendAuditEntry(ctx, tenantID, &entry); lastErr == nil {
+			return &entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to append audit log: %w", lastErr)
+}
+
+// encodeState returns v as compact JSON, or nil for a nil v.
+func encodeState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" { // a typed nil pointer
+		return nil, nil
+	}
+	return data, nil
+}
+
+// Verify walks the tenant's entire audit chain and checks every link and hash.
+func (t *Trail) Verify(ctx context.Context, tenantID string) (*VerifyResult, error) {
+	result := &VerifyResult{Valid: true}
+	expectedPrev := GenesisHash
+	var afterSeq int64
+
+	for {
+		page, err := t.repo.ListAuditEntries(ctx, tenantID, domain.AuditFilter{AfterSeq: afterSeq, Limit: verifyPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		for _, entry := range page {
+			if reason := checkEntry(entry, afterSeq+1, expectedPrev); reason != "" {
+				result.Valid = false
+				result.BrokenAt = entry.Seq
+				result.Reason = reason
+				return result, nil
+			}
+			result.Records++
+			result.HeadHash = entry.Hash
+			expectedPrev = entry.Hash
+			afterSeq = entry.Seq
+		}
+
+		if len(page) < verifyPageSize {
+			return result, nil
+		}
+	}
+}
+
+// checkEntry validates one entry against its expected position and predecessor.
+func checkEntry(entry *domain.AuditEntry, expectedSeq int64, expectedPrev string) string {
+	if entry.Seq != expectedSeq {
+		return fmt.Sprintf("sequence gap: expected %d, found %d", expectedSeq, entry.Seq)
+	}
+	if entry.PrevHash != expectedPrev {
+		return "previous hash does not match the preceding entry"
+	}
+	if HashEntry(entry) != entry.Hash {
+		return "entry hash does not match its content"
+	}
+	return ""
+}
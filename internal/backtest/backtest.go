@@ -0,0 +1,140 @@
+// Package backtest replays candidate rules over stored transactions, so
+// thresholds can be tuned before a rule is enabled.
+//
+// Candidate rules run in a sandboxed engine: nothing is stored or sampled,
+// and live lookups are not replayed, so velocity_count and enricher
+// variables read as zero.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// MaxTransactions caps the transactions replayed by a backtest.
+const MaxTransactions = 100000
+
+// pageSize is how many transactions are read per query.
+const pageSize = 500
+
+// scoreBuckets is the number of buckets of the score distribution.
+const scoreBuckets = 10
+
+// Service replays candidate rules over a tenant's stored transactions.
+type Service struct {
+	repo   domain.Repository
+	engine *rules.Engine
+}
+
+// NewService creates a new backtest service. Candidate rules compile
+// against engine's variables, enricher variables included.
+func NewService(repo domain.Repository, engine *rules.Engine) *Service {
+	return &Service{repo: repo, engine: engine}
+}
+
+// Run replays the tenant's transactions with timestamps in [since, until)
+// through the candidate rule, oldest first, and reports its results.
+func (s *Service) Run(ctx context.Context, tenantID string, rule *domain.RuleConfig, since, until time.Time) (*domain.BacktestReport, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	if s.repo == nil || s.engine == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	candidate := *rule
+	candidate.TenantID = tenantID
+	candidate.Enabled = true
+	candidate.Shadow = false
+	candidate.SampleRate = 0
+
+	sandbox := s.engine.Sandbox()
+	defer sandbox.Close()
+	if err := sandbox.LoadRule(&candidate); err != nil {
+		return nil, err
+	}
+
+	report := &domain.BacktestReport{
+		RuleID:   candidate.ID,
+		Since:    since.UTC(),
+		Until:    until.UTC(),
+		Outcomes: make(map[string]int),
+		Scores:   make([]domain.ScoreBucket, scoreBuckets),
+	}
+	for i := range report.Scores {
+		report.Scores[i].Lower = float64(i) / scoreBuckets
+		report.Scores[i].Upper = float64(i+1) / scoreBuckets
+	}
+
+	var last time.Time
+	for offset := 0; offset < MaxTransactions; offset += pageSize {
+		page, err := s.repo.ListTransactions(ctx, tenantID, since, until, offset, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, tx := range page {
+			results, err := sandbox.EvaluateAll(ctx, &rules.EvaluateInput{
+				TenantID:          tenantID,
+				TxID:              tx.ID,
+				Type:              tx.Type,
+				DebtorID:          tx.DebtorID,
+				CreditorID:        tx.CreditorID,
+				DebtorAccountID:   tx.DebtorAccountID,
+				CreditorAccountID: tx.CreditorAcctID,
+				Amount:            tx.Amount,
+				Currency:          tx.Currency,
+				Timestamp:         tx.Timestamp,
+				Components:        tx.Components,
+				ReversalOf:        tx.ReversalOf,
+				AdditionalData:    tx.Metadata,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate transaction %s: %w", tx.ID, err)
+			}
+			for _, result := range results {
+				record(report, result)
+			}
+			last = tx.Timestamp
+		}
+
+		if len(page) < pageSize {
+			break
+		}
+		if offset+pageSize >= MaxTransactions {
+			report.Truncated = true
+			report.Until = last.UTC()
+		}
+	}
+
+	if report.Transactions > 0 {
+		report.AlertRate = float64(report.Alerts) / float64(report.Transactions)
+	}
+	if days := report.Until.Sub(report.Since).Hours() / 24; days > 0 {
+		report.AlertsPerDay = float64(report.Alerts) / days
+	}
+	return report, nil
+}
+
+// record adds a replayed result to the report.
+func record(report *domain.BacktestReport, result domain.RuleResult) {
+	report.Transactions++
+	report.Outcomes[result.SubRuleRef]++
+	if result.SubRuleRef == domain.RuleOutcomeError {
+		return
+	}
+	if result.Score > 0 {
+		report.Matched++
+	}
+	if result.SubRuleRef == domain.RuleOutcomeFail {
+		report.Alerts++
+	}
+
+	bucket := int(math.Floor(result.Score * scoreBuckets))
+	report.Scores[max(0, min(bucket, scoreBuckets-1))].Count++
+}
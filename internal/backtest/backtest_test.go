@@ -0,0 +1,82 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/watchlist"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestBacktest(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "tenant-001"
+	repo := ospreytest.NewRepository(nil)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		repo.SaveTransaction(ctx, tenantID, ospreytest.NewTransaction().ID(fmt.Sprintf("tx-%02d", i)).Tenant(tenantID).Amount(float64(1000*i), "USD").At(base.Add(time.Duration(i)*time.Hour)).Build())
+	}
+	repo.SaveTransaction(ctx, "tenant-002", ospreytest.NewTransaction().ID("tx-foreign").Tenant("tenant-002").Amount(9000, "USD").At(base).Build())
+
+	engine, _ := rules.NewEngine(func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+		return 100, nil
+	}, 5)
+	defer engine.Close()
+	if err := engine.RegisterEnricher(watchlist.NewService(repo).Enricher()); err != nil {
+		t.Fatalf("RegisterEnricher failed: %v", err)
+	}
+	svc := NewService(repo, engine)
+	bound := func(v float64) *float64 { return &v }
+
+	t.Run("Run", func(t *testing.T) {
+		report, err := svc.Run(ctx, tenantID, &domain.RuleConfig{
+			ID:         "large-amount",
+			Expression: `amount / 10000.0`,
+			Bands: []domain.RuleBand{
+				{UpperLimit: bound(0.5), SubRuleRef: domain.RuleOutcomePass},
+				{LowerLimit: bound(0.5), UpperLimit: bound(0.8), SubRuleRef: domain.RuleOutcomeReview},
+				{LowerLimit: bound(0.8), SubRuleRef: domain.RuleOutcomeFail},
+			},
+		}, base, base.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if report.Transactions != 10 || report.Matched != 9 || report.Alerts != 2 || report.Outcomes[domain.RuleOutcomeReview] != 3 {
+			t.Errorf("unexpected counts: %+v", report)
+		}
+		if report.AlertRate != 0.2 || report.AlertsPerDay != 2 {
+			t.Errorf("expected an alert rate of 0.2 and 2 alerts a day, got %.2f and %.2f", report.AlertRate, report.AlertsPerDay)
+		}
+		for i, bucket := range report.Scores {
+			if bucket.Count != 1 {
+				t.Errorf("bucket %d: expected one score, got %+v", i, bucket)
+			}
+		}
+	})
+
+	t.Run("LookupsReadAsZero", func(t *testing.T) {
+		report, err := svc.Run(ctx, tenantID, &domain.RuleConfig{
+			ID:         "lookups",
+			Expression: `velocity_count > 0 || size(debtor_watchlists) > 0`,
+		}, base, base.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if report.Transactions != 10 || report.Matched != 0 || report.Outcomes[domain.RuleOutcomeError] != 0 {
+			t.Errorf("expected no matches without lookups, got %+v", report)
+		}
+		if len(engine.GetLoadedRules()) != 0 {
+			t.Error("expected the candidate rule to stay out of the engine")
+		}
+	})
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		if _, err := svc.Run(ctx, tenantID, &domain.RuleConfig{ID: "bad", Expression: `amount >`}, base, base.Add(time.Hour)); err == nil {
+			t.Error("expected an error for an invalid expression")
+		}
+	})
+}
@@ -0,0 +1,315 @@
+// Package balances tracks account balances, so account drains are detected
+// without callers sending balances.
+//
+// An account's balance becomes known when the balance feed sets it, or when
+// a stored transaction reports its debtor's balance in the old_balance and
+// new_balance metadata keys. From then on each stored transaction debits
+// its debtor's account and credits its creditor's. Rules see old_balance
+// and new_balance, taken from the tracked balance when the caller didn't
+// send them, drain_ratio, new_balance / old_balance, and balance_known.
+package balances
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// Metadata keys, and CEL variables, of a debtor's balance before and after
+// a payment.
+const (
+	OldBalanceKey = "old_balance"
+	NewBalanceKey = "new_balance"
+)
+
+// Tracker keeps account balances up to date and reads them for evaluations.
+type Tracker struct {
+	repo domain.Repository
+
+	// locks serialize the updates of an account's balance on this instance
+	locks [64]sync.Mutex
+}
+
+// NewTracker creates a tracker storing balances in repo.
+func NewTracker(repo domain.Repository) *Tracker {
+	return &Tracker{repo: repo}
+}
+
+// AccountID returns the account one side of a transaction moves: its
+// account ID, or the party's ID when it has none.
+func AccountID(accountID, partyID string) string {
+	if accountID != "" {
+		return accountID
+	}
+	return partyID
+}
+
+// Get returns an account's tracked balance.
+func (t *Tracker) Get(ctx context.Context, tenantID, accountID string) (*domain.AccountBalance, error) {
+	return t.repo.GetAccountBalance(ctx, tenantID, accountID)
+}
+
+// Set sets an account's balance from the feed, replacing the tracked one.
+// Transactions stored afterwards move it from there.
+func (t *Tracker) Set(ctx context.Context, tenantID, accountID string, amount domain.Decimal, currency string) (*domain.AccountBalance, error) {
+	defer t.lock(tenantID, accountID)()
+
+	balance := &domain.AccountBalance{
+		TenantID:  tenantID,
+		AccountID: accountID,
+		Balance:   amount,
+		Currency:  currency,
+		Prior:     amount,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := t.repo.SaveAccountBalance(ctx, tenantID, balance); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// Apply moves the balances of a stored transaction's accounts. Reversals and
+// transfers within one account are left out, as are accounts whose balance
+// is unknown or in another currency.
+func (t *Tracker) Apply(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if tx.ReversalOf != "" {
+		return nil
+	}
+	debtor := AccountID(tx.DebtorAccountID, tx.DebtorID)
+	creditor := AccountID(tx.CreditorAcctID, tx.CreditorID)
+	if debtor == creditor {
+		return nil
+	}
+
+	var errs []error
+	if debtor != "" {
+		errs = append(errs, t.debit(ctx, tenantID, debtor, tx))
+	}
+	if creditor != "" {
+		errs = append(errs, t.credit(ctx, tenantID, creditor, tx))
+	}
+	return errors.Join(errs...)
+}
+
+// debit takes tx from its debtor's account, keeping the balance before it as
+// the prior. Balances the transaction reports replace the tracked ones.
+func (t *Tracker) debit(ctx context.Context, tenantID, accountID string, tx *domain.Transaction) error {
+	defer t.lock(tenantID, accountID)()
+
+	before, after, reported := Reported(tx.Metadata, tx.Amount)
+
+	balance, err := t.repo.GetAccountBalance(ctx, tenantID, accountID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		if !reported {
+			return nil
+		}
+		balance = &domain.AccountBalance{AccountID: accountID}
+	case err != nil:
+		return err
+	case balance.LastTxID == tx.ID:
+		// Saved again, e.g. on a retry: it was debited already
+		return nil
+	case !reported && !sameCurrency(balance.Currency, tx.Currency):
+		return nil
+	}
+
+	if reported {
+		balance.Prior, balance.Balance = before, after
+		balance.Currency = tx.Currency
+	} else {
+		balance.Prior = balance.Balance
+		balance.Balance = balance.Balance.Sub(tx.Amount)
+	}
+	balance.LastTxID = tx.ID
+	balance.UpdatedAt = time.Now().UTC()
+	return t.repo.SaveAccountBalance(ctx, tenantID, balance)
+}
+
+// credit adds tx to its creditor's account.
+func (t *Tracker) credit(ctx context.Context, tenantID, accountID string, tx *domain.Transaction) error {
+	defer t.lock(tenantID, accountID)()
+
+	balance, err := t.repo.GetAccountBalance(ctx, tenantID, accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if balance.LastCreditTxID == tx.ID || !sameCurrency(balance.Currency, tx.Currency) {
+		return nil
+	}
+
+	balance.Balance = balance.Balance.Add(tx.Amount)
+	balance.LastCreditTxID = tx.ID
+	balance.UpdatedAt = time.Now().UTC()
+	return t.repo.SaveAccountBalance(ctx, tenantID, balance)
+}
+
+// Balances returns the debtor account's balance before and after a payment,
+// and false when it is unknown. The payment may be stored already: an
+// account it debited last reports the balance before it.
+func (t *Tracker) Balances(ctx context.Context, input *rules.EvaluateInput) (before, after domain.Decimal, known bool, err error) {
+	accountID := AccountID(input.DebtorAccountID, input.DebtorID)
+	if input.TenantID == "" || accountID == "" {
+		return before, after, false, nil
+	}
+
+	balance, err := t.repo.GetAccountBalance(ctx, input.TenantID, accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return before, after, false, nil
+	}
+	if err != nil {
+		return before, after, false, fmt.Errorf("failed to get balance: %w", err)
+	}
+	if !sameCurrency(balance.Currency, input.Currency) {
+		return before, after, false, nil
+	}
+
+	before = balance.Balance
+	if input.TxID != "" && balance.LastTxID == input.TxID {
+		before = balance.Prior
+	}
+	return before, before.Sub(input.Amount), true, nil
+}
+
+// lock locks an account's balance and returns its unlock.
+func (t *Tracker) lock(tenantID, accountID string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(tenantID + "\x00" + accountID))
+	lock := &t.locks[h.Sum32()%uint32(len(t.locks))]
+	lock.Lock()
+	return lock.Unlock
+}
+
+// Reported returns the debtor's balance before and after a payment of
+// amount as its metadata reports it. One of the two is enough: the other is
+// derived from the amount.
+func Reported(metadata map[string]any, amount domain.Decimal) (before, after domain.Decimal, ok bool) {
+	before, hasBefore := number(metadata[OldBalanceKey])
+	after, hasAfter := number(metadata[NewBalanceKey])
+	switch {
+	case hasBefore && hasAfter:
+		return before, after, true
+	case hasBefore:
+		return before, before.Sub(amount), true
+	case hasAfter:
+		return after.Add(amount), after, true
+	}
+	return before, after, false
+}
+
+// number reads a metadata value as a decimal. JSON numbers are parsed
+// exactly; floats are taken at their shortest representation.
+func number(v any) (domain.Decimal, bool) {
+	switch n := v.(type) {
+	case float64:
+		return domain.DecimalFromFloat(n), true
+	case int:
+		return domain.Decimal{Units: int64(n)}, true
+	case int64:
+		return domain.Decimal{Units: n}, true
+	case json.Number:
+		d, err := domain.ParseDecimal(n.String())
+		return d, err == nil
+	}
+	return domain.Decimal{}, false
+}
+
+// sameCurrency reports whether a balance and a transaction are in the same
+// currency, taking an unset one as matching.
+func sameCurrency(balance, tx string) bool {
+	return balance == "" || tx == "" || balance == tx
+}
+
+// DrainRatio is new_balance / old_balance, or 1 when old_balance is not
+// positive and nothing can be drained.
+func DrainRatio(before, after float64) float64 {
+	if before <= 0 {
+		return 1
+	}
+	return after / before
+}
+
+// Repository moves account balances with every transaction it saves.
+type Repository struct {
+	domain.Repository
+	tracker *Tracker
+}
+
+// Wrap returns repo with account balances kept up to date by tracker.
+func Wrap(repo domain.Repository, tracker *Tracker) *Repository {
+	return &Repository{Repository: repo, tracker: tracker}
+}
+
+// Unwrap returns the repository whose transactions move balances.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveTransaction saves a transaction, then moves its accounts' balances.
+// A failure to update them is logged and doesn't fail the save.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if err := r.Repository.SaveTransaction(ctx, tenantID, tx); err != nil {
+		return err
+	}
+	if err := r.tracker.Apply(ctx, tenantID, tx); err != nil {
+		slog.Warn("failed to update account balances", "tenant_id", tenantID, "tx_id", tx.ID, "error", err)
+	}
+	return nil
+}
+
+// Enricher returns a rules.Enricher exposing drain_ratio and balance_known
+// to CEL. It also sets old_balance and new_balance from the tracked balance
+// when the transaction doesn't report them.
+func (t *Tracker) Enricher() rules.Enricher {
+	return &enricher{tracker: t}
+}
+
+type enricher struct {
+	tracker *Tracker
+}
+
+func (e *enricher) Name() string {
+	return "balances"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"drain_ratio":   cel.DoubleType,
+		"balance_known": cel.BoolType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["drain_ratio"] = 1.0
+	activation["balance_known"] = false
+
+	before, after, known := Reported(input.AdditionalData, input.Amount)
+	if !known && input.ReversalOf == "" {
+		var err error
+		if before, after, known, err = e.tracker.Balances(ctx, input); err != nil {
+			return err
+		}
+	}
+	if !known {
+		return nil
+	}
+
+	activation[OldBalanceKey] = before.Float64()
+	activation[NewBalanceKey] = after.Float64()
+	activation["drain_ratio"] = DrainRatio(before.Float64(), after.Float64())
+	activation["balance_known"] = true
+	return nil
+}
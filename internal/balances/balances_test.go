@@ -0,0 +1,124 @@
+package balances
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	tracker := NewTracker(base)
+	repo := Wrap(base, tracker)
+
+	save := func(tx *domain.Transaction) {
+		t.Helper()
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+	balance := func(accountID string) *domain.AccountBalance {
+		t.Helper()
+		b, err := tracker.Get(ctx, "tenant-001", accountID)
+		if err != nil {
+			t.Fatalf("Get %s failed: %v", accountID, err)
+		}
+		return b
+	}
+
+	// Nothing is tracked until a balance is known
+	save(ospreytest.NewTransaction().ID("a").From("alice").To("bob").Amount(100, "USD").Build())
+	if _, err := tracker.Get(ctx, "tenant-001", "alice-acct"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected no balance for alice, got %v", err)
+	}
+
+	// The feed sets alice's balance and a payment reports bob's
+	if _, err := tracker.Set(ctx, "tenant-001", "alice-acct", domain.MustDecimal("1000"), "USD"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	save(ospreytest.NewTransaction().ID("b").From("bob").To("carol").Amount(50, "USD").Metadata("old_balance", 500.0).Build())
+	if b := balance("bob-acct"); b.Balance.String() != "450" || b.Prior.String() != "500" {
+		t.Errorf("expected bob's reported 500 less 50, got %+v", b)
+	}
+
+	// Payments debit and credit known accounts, once each
+	tx := ospreytest.NewTransaction().ID("c").From("alice").To("bob").Amount(300, "USD").Build()
+	save(tx)
+	if err := tracker.Apply(ctx, "tenant-001", tx); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if b := balance("alice-acct"); b.Balance.String() != "700" || b.Prior.String() != "1000" || b.LastTxID != "c" {
+		t.Errorf("expected alice at 700 after 1000, got %+v", b)
+	}
+	if b := balance("bob-acct"); b.Balance.String() != "750" || b.Prior.String() != "500" {
+		t.Errorf("expected bob credited to 750, got %+v", b)
+	}
+
+	// Reversals and other currencies are left out
+	refund := ospreytest.NewTransaction().ID("d").From("alice").To("bob").Amount(300, "USD").Build()
+	refund.ReversalOf = "c"
+	save(refund)
+	save(ospreytest.NewTransaction().ID("e").From("alice").To("bob").Amount(10, "EUR").Build())
+	if b := balance("alice-acct"); b.Balance.String() != "700" {
+		t.Errorf("expected alice unchanged at 700, got %+v", b)
+	}
+	// Balances move exactly, without float drift
+	if _, err := tracker.Set(ctx, "tenant-001", "dave-acct", domain.MustDecimal("0.3"), "USD"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	save(ospreytest.NewTransaction().ID("f").From("dave").To("erin").Amount(0.1, "USD").Build())
+	if b := balance("dave-acct"); b.Balance.String() != "0.2" || b.Prior.String() != "0.3" {
+		t.Errorf("expected dave at exactly 0.2, got %+v", b)
+	}
+}
+
+func TestEnricher(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	tracker := NewTracker(base)
+	repo := Wrap(base, tracker)
+	enricher := tracker.Enricher()
+
+	input := func(id, amount string, metadata map[string]any) *rules.EvaluateInput {
+		return &rules.EvaluateInput{TenantID: "tenant-001", TxID: id, DebtorID: "alice", DebtorAccountID: "alice-acct", Amount: domain.MustDecimal(amount), Currency: "USD", AdditionalData: metadata}
+	}
+	enrich := func(in *rules.EvaluateInput) map[string]any {
+		t.Helper()
+		activation := map[string]any{}
+		if err := enricher.Enrich(ctx, in, activation); err != nil {
+			t.Fatalf("Enrich failed: %v", err)
+		}
+		return activation
+	}
+
+	if a := enrich(input("a", "100", nil)); a["balance_known"] != false || a["drain_ratio"] != 1.0 {
+		t.Errorf("expected an unknown balance, got %v", a)
+	}
+
+	// The caller's balances come first, the missing one derived
+	if a := enrich(input("a", "100", map[string]any{"old_balance": 400.0})); a["new_balance"] != 300.0 || a["drain_ratio"] != 0.75 {
+		t.Errorf("expected 400 to 300, got %v", a)
+	}
+
+	// Otherwise the tracked balance is used, before the payment even once it is stored
+	if _, err := tracker.Set(ctx, "tenant-001", "alice-acct", domain.MustDecimal("1000"), "USD"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	tx := ospreytest.NewTransaction().ID("b").From("alice").To("bob").Amount(950, "USD").Build()
+	if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+		t.Fatalf("SaveTransaction failed: %v", err)
+	}
+	a := enrich(input("b", "950", nil))
+	if a["balance_known"] != true || a["old_balance"] != 1000.0 || a["new_balance"] != 50.0 || a["drain_ratio"] != 0.05 {
+		t.Errorf("expected 1000 drained to 50, got %v", a)
+	}
+	if a := enrich(input("c", "50", nil)); a["old_balance"] != 50.0 || a["drain_ratio"] != 0.0 {
+		t.Errorf("expected the next payment to empty the account, got %v", a)
+	}
+}
@@ -0,0 +1,269 @@
+// Package baseline keeps each debtor's usual behaviour and exposes how far a
+// payment strays from it to rules.
+//
+// A baseline holds rolling statistics of the debtor's payments, updated as
+// transactions are saved: the mean and spread of its amounts, the hours of
+// the day it pays at and the creditors it pays. Rules see amount_zscore,
+// unusual_hour and new_counterparty, which compare a payment with the
+// payments before it, so behavioral anomalies need no external feature
+// store.
+package baseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// UnusualHourShare is the share of a debtor's payments below which an hour
+// of the day is unusual for it, about a quarter of an even spread.
+const UnusualHourShare = 0.01
+
+// Creditors a debtor pays are forgotten once their share of its payments
+// falls below MinCounterpartyShare, and only the MaxCounterparties most
+// paid are kept.
+const (
+	MinCounterpartyShare = 0.001
+	MaxCounterparties    = 100
+)
+
+// minStddevRatio floors the standard deviation of amount_zscore at a share
+// of the mean, so a debtor whose amounts never varied doesn't divide by 0.
+const minStddevRatio = 0.01
+
+// Add returns stats with a payment added. Each payment counts for 1/Count
+// until Count reaches span, then for 1/span. stats is not modified.
+func Add(stats domain.BaselineStats, amount float64, at time.Time, creditorID string, span int) domain.BaselineStats {
+	stats.Count++
+	weight := 1 / float64(min(stats.Count, int64(max(span, 1))))
+
+	delta := amount - stats.MeanAmount
+	stats.MeanAmount += weight * delta
+	stats.AmountVariance = (1 - weight) * (stats.AmountVariance + weight*delta*delta)
+
+	for hour := range stats.Hours {
+		stats.Hours[hour] *= 1 - weight
+	}
+	stats.Hours[at.UTC().Hour()] += weight
+
+	counterparties := make(map[string]float64, len(stats.Counterparties)+1)
+	for id, share := range stats.Counterparties {
+		if share *= 1 - weight; share >= MinCounterpartyShare {
+			counterparties[id] = share
+		}
+	}
+	if creditorID != "" {
+		counterparties[creditorID] += weight
+	}
+	for len(counterparties) > MaxCounterparties {
+		least := ""
+		for id, share := range counterparties {
+			if least == "" || share < counterparties[least] {
+				least = id
+			}
+		}
+		delete(counterparties, least)
+	}
+	stats.Counterparties = counterparties
+	return stats
+}
+
+// Scores are how far a payment strays from a debtor's baseline.
+type Scores struct {
+	// AmountZScore is the amount's distance from the mean in standard
+	// deviations, negative below it.
+	AmountZScore float64 `json:"amountZScore"`
+
+	// UnusualHour is true when the debtor rarely pays at the payment's UTC
+	// hour.
+	UnusualHour bool `json:"unusualHour"`
+
+	// NewCounterparty is true when the creditor is not among the debtor's
+	// usual counterparties.
+	NewCounterparty bool `json:"newCounterparty"`
+}
+
+// Score compares a payment with stats. Stats of fewer than minSamples
+// payments are not trusted and score zero.
+func Score(stats domain.BaselineStats, amount float64, at time.Time, creditorID string, minSamples int) Scores {
+	var scores Scores
+	if stats.Count == 0 || stats.Count < int64(minSamples) {
+		return scores
+	}
+
+	stddev := max(math.Sqrt(stats.AmountVariance), minStddevRatio*math.Abs(stats.MeanAmount))
+	if stddev > 0 {
+		scores.AmountZScore = (amount - stats.MeanAmount) / stddev
+	}
+	scores.UnusualHour = stats.Hours[at.UTC().Hour()] < UnusualHourShare
+	scores.NewCounterparty = creditorID != "" && stats.Counterparties[creditorID] == 0
+	return scores
+}
+
+// Repository adds every payment it saves to its debtor's baseline.
+type Repository struct {
+	domain.Repository
+	cfg domain.BaselineConfig
+
+	// locks serialize the updates of a debtor's baseline on this instance
+	locks [64]sync.Mutex
+}
+
+// Wrap returns repo with debtor baselines kept up to date. A zero span or
+// sample minimum uses the defaults.
+func Wrap(repo domain.Repository, cfg domain.BaselineConfig) *Repository {
+	return &Repository{Repository: repo, cfg: withDefaults(cfg)}
+}
+
+// Unwrap returns the repository whose payments feed the baselines.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveTransaction saves a transaction, then adds it to its debtor's
+// baseline. Reversals are left out. A failure to update the baseline is
+// logged and doesn't fail the save.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if err := r.Repository.SaveTransaction(ctx, tenantID, tx); err != nil {
+		return err
+	}
+	if tx.ReversalOf != "" || tx.DebtorID == "" {
+		return nil
+	}
+	if err := r.update(ctx, tenantID, tx); err != nil {
+		slog.Warn("failed to update baseline", "tenant_id", tenantID, "tx_id", tx.ID, "error", err)
+	}
+	return nil
+}
+
+// update adds tx to its debtor's baseline, keeping the stats before it as
+// the prior. Instances saving payments of the same debtor at once can lose
+// one of the updates, which only makes the baseline a little staler.
+func (r *Repository) update(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	h := fnv.New32a()
+	h.Write([]byte(tenantID + "\x00" + tx.DebtorID))
+	lock := &r.locks[h.Sum32()%uint32(len(r.locks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	baseline, err := r.Repository.GetEntityBaseline(ctx, tenantID, tx.DebtorID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		baseline = &domain.EntityBaseline{EntityID: tx.DebtorID}
+	case err != nil:
+		return err
+	case baseline.LastTxID == tx.ID:
+		// Saved again, e.g. on a retry: it was added already
+		return nil
+	}
+
+	creditorID := tx.CreditorID
+	if creditorID == tx.DebtorID {
+		creditorID = ""
+	}
+	baseline.Prior = baseline.Stats
+	baseline.Stats = Add(baseline.Stats, tx.Amount.Float64(), tx.Timestamp, creditorID, r.cfg.Span)
+	baseline.LastTxID = tx.ID
+	baseline.UpdatedAt = time.Now()
+	return r.Repository.SaveEntityBaseline(ctx, tenantID, baseline)
+}
+
+// Service scores payments against their debtor's baseline.
+type Service struct {
+	repo domain.Repository
+	cfg  domain.BaselineConfig
+}
+
+// NewService creates a baseline service. A zero span or sample minimum uses
+// the defaults.
+func NewService(repo domain.Repository, cfg domain.BaselineConfig) *Service {
+	return &Service{repo: repo, cfg: withDefaults(cfg)}
+}
+
+func withDefaults(cfg domain.BaselineConfig) domain.BaselineConfig {
+	defaults := domain.DefaultConfig().Baseline
+	if cfg.Span <= 0 {
+		cfg.Span = defaults.Span
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaults.MinSamples
+	}
+	return cfg
+}
+
+// Scores compares a payment with its debtor's baseline before it. The
+// payment may be stored already: a baseline it was last added to is
+// compared as it was before.
+func (s *Service) Scores(ctx context.Context, input *rules.EvaluateInput) (Scores, error) {
+	if input.TenantID == "" || input.DebtorID == "" {
+		return Scores{}, fmt.Errorf("tenantID and debtorID are required")
+	}
+
+	baseline, err := s.repo.GetEntityBaseline(ctx, input.TenantID, input.DebtorID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return Scores{}, nil
+	}
+	if err != nil {
+		return Scores{}, fmt.Errorf("failed to get baseline: %w", err)
+	}
+
+	stats := baseline.Stats
+	if input.TxID != "" && baseline.LastTxID == input.TxID {
+		stats = baseline.Prior
+	}
+	at := input.Timestamp
+	if at.IsZero() {
+		at = domain.EvaluationTime(ctx)
+	}
+	return Score(stats, input.Amount.Float64(), at, input.CreditorID, s.cfg.MinSamples), nil
+}
+
+// Enricher returns a rules.Enricher exposing amount_zscore, unusual_hour
+// and new_counterparty to CEL.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "baseline"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"amount_zscore":    cel.DoubleType,
+		"unusual_hour":     cel.BoolType,
+		"new_counterparty": cel.BoolType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["amount_zscore"] = 0.0
+	activation["unusual_hour"] = false
+	activation["new_counterparty"] = false
+	if input.DebtorID == "" || input.ReversalOf != "" {
+		return nil
+	}
+
+	scores, err := e.svc.Scores(ctx, input)
+	if err != nil {
+		return err
+	}
+	activation["amount_zscore"] = scores.AmountZScore
+	activation["unusual_hour"] = scores.UnusualHour
+	activation["new_counterparty"] = scores.NewCounterparty
+	return nil
+}
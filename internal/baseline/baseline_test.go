@@ -0,0 +1,106 @@
+package baseline
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestAdd(t *testing.T) {
+	at := time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC)
+	var stats domain.BaselineStats
+	for _, amount := range []float64{100, 200, 300} {
+		stats = Add(stats, amount, at, "merchant", 100)
+	}
+	// Below the span every payment counts equally
+	if stats.Count != 3 || stats.MeanAmount != 200 || math.Abs(stats.AmountVariance-20000.0/3) > 1e-9 {
+		t.Errorf("expected mean 200 and variance 6666.67 of 3 payments, got %+v", stats)
+	}
+	if stats.Hours[14] != 1 || stats.Counterparties["merchant"] != 1 {
+		t.Errorf("expected every payment at 14:00 to merchant, got %v, %v", stats.Hours, stats.Counterparties)
+	}
+
+	// Past the span a payment counts for 1/span and old creditors fade out
+	before := stats
+	for i := 0; i < 500; i++ {
+		stats = Add(stats, 50, at.Add(6*time.Hour), "grocer", 10)
+	}
+	if math.Abs(stats.MeanAmount-50) > 1e-6 || stats.Hours[20] < 0.99 {
+		t.Errorf("expected the baseline to follow the recent payments, got %+v", stats)
+	}
+	if _, ok := stats.Counterparties["merchant"]; ok {
+		t.Errorf("expected merchant to be forgotten, got %v", stats.Counterparties)
+	}
+	if before.Count != 3 || before.Counterparties["merchant"] != 1 {
+		t.Errorf("expected Add to leave its input unchanged, got %+v", before)
+	}
+}
+
+func TestScores(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	cfg := domain.BaselineConfig{MinSamples: 5}
+	repo := Wrap(base, cfg)
+	svc := NewService(repo, cfg)
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	save := func(id, creditorID string, amount float64, at time.Time, reversalOf string) {
+		t.Helper()
+		tx := ospreytest.NewTransaction().ID(id).From("alice").To(creditorID).Amount(amount, "USD").At(at).Build()
+		tx.ReversalOf = reversalOf
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+	input := func(id, creditorID string, amount string, at time.Time) *rules.EvaluateInput {
+		return &rules.EvaluateInput{TenantID: "tenant-001", TxID: id, DebtorID: "alice", CreditorID: creditorID, Amount: domain.MustDecimal(amount), Timestamp: at}
+	}
+
+	// Four payments are too few to trust
+	for i, amount := range []float64{90, 110, 100, 100} {
+		save(string(rune('a'+i)), "grocer", amount, day.Add(time.Duration(i)*24*time.Hour+10*time.Hour), "")
+	}
+	if scores, err := svc.Scores(ctx, input("new", "casino", "5000", day.Add(3*time.Hour))); err != nil || scores != (Scores{}) {
+		t.Errorf("expected no scores below the sample minimum, got %+v, %v", scores, err)
+	}
+
+	// A reversal doesn't count, nor does adding the last payment again
+	save("e", "grocer", 100, day.Add(4*24*time.Hour+10*time.Hour), "")
+	save("refund", "grocer", 100, day.Add(4*24*time.Hour+11*time.Hour), "a")
+	if err := repo.update(ctx, "tenant-001", ospreytest.NewTransaction().ID("e").From("alice").To("grocer").Amount(100, "USD").Build()); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	baseline, err := base.GetEntityBaseline(ctx, "tenant-001", "alice")
+	if err != nil || baseline.Stats.Count != 5 || baseline.LastTxID != "e" || baseline.Prior.Count != 4 {
+		t.Fatalf("expected 5 payments after e, got %+v, %v", baseline, err)
+	}
+
+	// A large payment to a new creditor at 03:00, not yet stored
+	scores, err := svc.Scores(ctx, input("f", "casino", "5000", day.Add(5*24*time.Hour+3*time.Hour)))
+	if err != nil {
+		t.Fatalf("Scores failed: %v", err)
+	}
+	if scores.AmountZScore < 100 || !scores.UnusualHour || !scores.NewCounterparty {
+		t.Errorf("expected an anomalous payment, got %+v", scores)
+	}
+
+	// A usual payment, stored first, is compared with the payments before it
+	save("g", "grocer", 100, day.Add(5*24*time.Hour+10*time.Hour), "")
+	scores, err = svc.Scores(ctx, input("g", "grocer", "100", day.Add(5*24*time.Hour+10*time.Hour)))
+	if err != nil || scores != (Scores{}) {
+		t.Errorf("expected a usual payment to score zero, got %+v, %v", scores, err)
+	}
+
+	activation := map[string]any{}
+	if err := svc.Enricher().Enrich(ctx, input("h", "casino", "100", day.Add(6*24*time.Hour+10*time.Hour)), activation); err != nil {
+		t.Fatalf("Enrich failed: %v", err)
+	}
+	if activation["new_counterparty"] != true || activation["unusual_hour"] != false {
+		t.Errorf("expected only a new counterparty, got %v", activation)
+	}
+}
@@ -0,0 +1,334 @@
+// Package breaker keeps evaluations flowing through repository outages.
+//
+// A circuit breaker guards the writes of the evaluation path, saving
+// transactions and evaluations. Each write gets a deadline, and after
+// Failures consecutive failures the breaker opens: writes no longer reach
+// the database, so requests don't wait on it. What happens to them depends
+// on the policy. With queue, they are appended to a local write-ahead log
+// and the evaluation goes on; with fail-fast, they fail at once with
+// ErrOpen, and the API answers 503. While it is open the evaluation path's
+// reads fail with ErrOpen as well. Every Cooldown an open breaker pings the
+// database and closes once it answers, then replays the log in order
+// through the wrapped repository, so the usual side effects of a save, such
+// as baselines and balances, catch up. Until the log is empty new writes
+// queue behind it, so they reach the database in order.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Policies for writes while the breaker is open.
+const (
+	PolicyQueue    = "queue"
+	PolicyFailFast = "fail-fast"
+)
+
+// Breaker states.
+const (
+	StateClosed = "closed"
+	StateOpen   = "open"
+)
+
+// ErrOpen is returned for writes the breaker rejects, and for reads while it
+// is open.
+var ErrOpen = errors.New("repository circuit breaker is open")
+
+// ValidPolicy reports whether policy is a known policy.
+func ValidPolicy(policy string) bool {
+	return policy == PolicyQueue || policy == PolicyFailFast
+}
+
+// Status describes the breaker for /health and /metrics.
+type Status struct {
+	Policy   string     `json:"policy"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"` // consecutive failed writes
+	OpenedAt *time.Time `json:"openedAt,omitempty"`
+	Opened   int64      `json:"opened"`   // times the breaker opened
+	Queued   int        `json:"queued"`   // writes in the log awaiting replay
+	Rejected int64      `json:"rejected"` // writes failed with ErrOpen
+	Replayed int64      `json:"replayed"` // writes replayed from the log
+	Dropped  int64      `json:"dropped"`  // logged writes the database refused on replay
+}
+
+// Repository guards the evaluation path's writes of the wrapped repository.
+type Repository struct {
+	domain.Repository
+	cfg domain.BreakerConfig
+	wal *wal // nil with fail-fast
+	now func() time.Time
+
+	mu       sync.Mutex
+	open     bool
+	failures int
+	openedAt time.Time
+	opened   int64
+	rejected int64
+	replayed int64
+	dropped  int64
+
+	replayMu sync.Mutex // held by the running replay
+}
+
+// Wrap returns repo guarded by a breaker with cfg's policy. With the queue
+// policy it opens the log at cfg.WALPath, where writes queued before a
+// restart wait to be replayed.
+func Wrap(repo domain.Repository, cfg domain.BreakerConfig) (*Repository, error) {
+	if !ValidPolicy(cfg.Policy) {
+		return nil, fmt.Errorf("unknown breaker policy %q", cfg.Policy)
+	}
+	defaults := domain.DefaultConfig().Breaker
+	if cfg.Failures <= 0 {
+		cfg.Failures = defaults.Failures
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+
+	r := &Repository{Repository: repo, cfg: cfg, now: time.Now}
+	if cfg.Policy == PolicyQueue {
+		w, err := openWAL(cfg.WALPath, cfg.MaxQueued)
+		if err != nil {
+			return nil, err
+		}
+		r.wal = w
+	}
+	return r, nil
+}
+
+// Unwrap returns the guarded repository.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// Status returns the breaker's current status. It is safe on a nil Repository.
+func (r *Repository) Status() Status {
+	if r == nil {
+		return Status{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := Status{
+		Policy:   r.cfg.Policy,
+		State:    StateClosed,
+		Failures: r.failures,
+		Opened:   r.opened,
+		Rejected: r.rejected,
+		Replayed: r.replayed,
+		Dropped:  r.dropped,
+	}
+	if r.open {
+		openedAt := r.openedAt.UTC()
+		s.State, s.OpenedAt = StateOpen, &openedAt
+	}
+	if r.wal != nil {
+		s.Queued = r.wal.pending()
+	}
+	return s
+}
+
+// Rejecting reports whether requests that must write should be refused: the
+// breaker is open with the fail-fast policy. It is safe on a nil Repository.
+func (r *Repository) Rejecting() bool {
+	if r == nil || r.cfg.Policy != PolicyFailFast {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.open
+}
+
+// SaveTransaction saves a transaction unless the breaker is open.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	return r.write(ctx, &entry{Op: opTransaction, TenantID: tenantID, Transaction: tx, OriginalMessage: tx.OriginalMessage}, func(ctx context.Context) error {
+		return r.Repository.SaveTransaction(ctx, tenantID, tx)
+	})
+}
+
+// SaveEvaluation saves an evaluation unless the breaker is open.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	return r.write(ctx, &entry{Op: opEvaluation, TenantID: tenantID, Evaluation: eval}, func(ctx context.Context) error {
+		return r.Repository.SaveEvaluation(ctx, tenantID, eval)
+	})
+}
+
+// write runs a guarded write. A write that fails with the breaker closed
+// counts towards opening it and, with the queue policy, is queued too. While
+// queued writes await replay new ones queue behind them, so writes reach the
+// database in order: an evaluation never lands before its transaction. Each
+// starts a replay unless one is running; a replay failing on an unreachable
+// database counts towards opening the breaker.
+func (r *Repository) write(ctx context.Context, e *entry, fn func(context.Context) error) error {
+	r.mu.Lock()
+	open := r.open
+	r.mu.Unlock()
+	if open {
+		return r.deferWrite(e)
+	}
+	if r.wal != nil && r.wal.pending() > 0 {
+		err := r.deferWrite(e)
+		if r.replayMu.TryLock() {
+			go func() {
+				defer r.replayMu.Unlock()
+				r.replayLocked(context.Background())
+			}()
+		}
+		return err
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	err := fn(writeCtx)
+	cancel()
+	if err == nil {
+		r.mu.Lock()
+		r.failures = 0
+		r.mu.Unlock()
+		return nil
+	}
+	// Invalid input, a duplicate, or the caller giving up says nothing about
+	// the database
+	if errors.Is(err, repository.ErrInvalidInput) || errors.Is(err, repository.ErrConflict) || ctx.Err() != nil {
+		return err
+	}
+
+	r.fail(err)
+	if r.wal == nil {
+		return err
+	}
+	if qerr := r.deferWrite(e); qerr != nil {
+		return errors.Join(err, qerr)
+	}
+	return nil
+}
+
+// fail counts a failed write, opening the breaker at the threshold.
+func (r *Repository) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	if r.open || r.failures < r.cfg.Failures {
+		return
+	}
+	r.open = true
+	r.openedAt = r.now()
+	r.opened++
+	slog.Error("repository circuit breaker opened", "policy", r.cfg.Policy, "failures", r.failures, "error", err)
+}
+
+// deferWrite queues a write with the queue policy, or rejects it.
+func (r *Repository) deferWrite(e *entry) error {
+	if r.wal != nil {
+		err := r.wal.append(e)
+		if err == nil {
+			return nil
+		}
+		slog.Error("failed to queue repository write", "op", e.Op, "error", err)
+	}
+	r.mu.Lock()
+	r.rejected++
+	r.mu.Unlock()
+	return ErrOpen
+}
+
+// Run probes the database every Cooldown until ctx is cancelled.
+func (r *Repository) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Cooldown)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.Probe(ctx)
+	}
+}
+
+// Probe closes an open breaker once Cooldown has passed and the database
+// answers a ping, then replays queued writes. Until they are replayed, new
+// writes are queued behind them.
+func (r *Repository) Probe(ctx context.Context) {
+	r.mu.Lock()
+	due := r.open && r.now().Sub(r.openedAt) >= r.cfg.Cooldown
+	r.mu.Unlock()
+
+	if due {
+		pingCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+		err := r.Repository.Ping(pingCtx)
+		cancel()
+		r.mu.Lock()
+		if err != nil {
+			r.openedAt = r.now()
+			r.mu.Unlock()
+			return
+		}
+		r.open, r.failures = false, 0
+		r.mu.Unlock()
+		slog.Info("repository circuit breaker closed")
+	}
+
+	if r.wal != nil && r.wal.pending() > 0 {
+		r.replay(ctx)
+	}
+}
+
+// replay applies the queued writes in order, including those queued while
+// it runs, until the log is empty. It stops at the first write that fails
+// while the database is unreachable, keeping it and the writes after it; a
+// write the reachable database refuses, such as one that was saved after
+// all, is dropped.
+func (r *Repository) replay(ctx context.Context) {
+	r.replayMu.Lock()
+	defer r.replayMu.Unlock()
+	r.replayLocked(ctx)
+}
+
+// replayLocked replays with replayMu held.
+func (r *Repository) replayLocked(ctx context.Context) {
+	var replayed, dropped int64
+	var err error
+	for err == nil && ctx.Err() == nil && r.wal.pending() > 0 {
+		err = r.wal.drain(func(e *entry) error {
+			writeCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+			defer cancel()
+			err := e.apply(writeCtx, r.Repository)
+			if err == nil {
+				replayed++
+				return nil
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			if pingErr := r.Repository.Ping(writeCtx); pingErr != nil {
+				r.fail(err)
+				return err
+			}
+			slog.Warn("dropping queued repository write", "op", e.Op, "tenant_id", e.TenantID, "error", err)
+			dropped++
+			return nil
+		})
+	}
+
+	r.mu.Lock()
+	r.replayed += replayed
+	r.dropped += dropped
+	r.mu.Unlock()
+	if err != nil {
+		slog.Error("repository write replay stopped", "replayed", replayed, "error", err)
+		return
+	}
+	slog.Info("repository writes replayed", "replayed", replayed, "dropped", dropped)
+}
@@ -0,0 +1,299 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	outage := errors.New("connection refused")
+
+	newBreaker := func(t *testing.T, policy string, base domain.Repository) (*Repository, *time.Time) {
+		t.Helper()
+		cfg := domain.DefaultConfig().Breaker
+		cfg.Policy, cfg.Failures, cfg.Cooldown = policy, 2, time.Minute
+		cfg.WALPath = filepath.Join(t.TempDir(), "wal.jsonl")
+		r, err := Wrap(base, cfg)
+		if err != nil {
+			t.Fatalf("Wrap failed: %v", err)
+		}
+		now := time.Now()
+		r.now = func() time.Time { return now }
+		return r, &now
+	}
+	tx := func(id string) *domain.Transaction {
+		return &domain.Transaction{ID: id, Type: "transfer", DebtorID: "alice", CreditorID: "bob", Amount: domain.MustDecimal("100"), Currency: "USD", OriginalMessage: []byte("<pacs.008/>")}
+	}
+
+	// Writes queued behind a failed one open the breaker through the
+	// replays they start
+	waitOpen := func(t *testing.T, r *Repository) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for r.Status().State != StateOpen && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if r.Status().State != StateOpen {
+			t.Fatalf("expected the breaker to open, got %+v", r.Status())
+		}
+	}
+
+	t.Run("QueueReplaysAfterOutage", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, now := newBreaker(t, PolicyQueue, base)
+
+		base.SetError(outage)
+		for _, id := range []string{"tx-1", "tx-2", "tx-3"} {
+			if err := r.SaveTransaction(ctx, "tenant-001", tx(id)); err != nil {
+				t.Fatalf("expected %s to be queued, got %v", id, err)
+			}
+		}
+		eval := &domain.Evaluation{ID: "eval-1", TenantID: "tenant-001", TxID: "tx-3", Status: domain.StatusNoAlert}
+		if err := r.SaveEvaluation(ctx, "tenant-001", eval); err != nil {
+			t.Fatalf("expected the evaluation to be queued, got %v", err)
+		}
+		waitOpen(t, r)
+		status := r.Status()
+		if status.Opened != 1 || status.Queued != 4 {
+			t.Fatalf("expected an open breaker with 4 queued writes, got %+v", status)
+		}
+		if _, err := r.GetTransaction(ctx, "tenant-001", "tx-1"); !errors.Is(err, ErrOpen) {
+			t.Errorf("expected reads to fail with ErrOpen, got %v", err)
+		}
+		if _, err := r.GetTransactionsByEntity(ctx, "tenant-001", "alice", time.Time{}); !errors.Is(err, ErrOpen) {
+			t.Errorf("expected velocity reads to fail with ErrOpen, got %v", err)
+		}
+		if _, err := r.GetEntityBaseline(ctx, "tenant-001", "alice"); !errors.Is(err, ErrOpen) {
+			t.Errorf("expected enricher reads to fail with ErrOpen, got %v", err)
+		}
+
+		// Before the cooldown, or while the database is down, it stays open
+		r.Probe(ctx)
+		*now = now.Add(time.Minute)
+		r.Probe(ctx)
+		if r.Status().State != StateOpen {
+			t.Fatal("expected the breaker to stay open while the database is down")
+		}
+
+		// Writes queue behind the log until it is replayed, so an evaluation
+		// never lands before its transaction
+		base.SetError(nil)
+		later := &domain.Evaluation{ID: "eval-2", TenantID: "tenant-001", TxID: "tx-2", Status: domain.StatusNoAlert}
+		if err := r.SaveEvaluation(ctx, "tenant-001", later); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+		if _, err := base.GetEvaluation(ctx, "tenant-001", "eval-2"); err == nil {
+			t.Fatal("expected eval-2 to wait for the queued writes")
+		}
+
+		*now = now.Add(time.Minute)
+		r.Probe(ctx)
+		status = r.Status()
+		if status.State != StateClosed || status.Queued != 0 || status.Replayed != 5 {
+			t.Fatalf("expected a closed breaker with every write replayed, got %+v", status)
+		}
+		saved, err := r.GetTransaction(ctx, "tenant-001", "tx-2")
+		if err != nil {
+			t.Fatalf("expected tx-2 to be saved: %v", err)
+		}
+		if saved.Amount.Float64() != 100 || saved.DebtorID != "alice" {
+			t.Errorf("unexpected replayed transaction: %+v", saved)
+		}
+		for _, id := range []string{"eval-1", "eval-2"} {
+			if _, err := base.GetEvaluation(ctx, "tenant-001", id); err != nil {
+				t.Errorf("expected %s to be saved: %v", id, err)
+			}
+		}
+
+		// With the log empty, writes go straight to the database again
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-4")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		if _, err := base.GetTransaction(ctx, "tenant-001", "tx-4"); err != nil {
+			t.Errorf("expected tx-4 to be saved directly: %v", err)
+		}
+	})
+
+	t.Run("QueueSurvivesRestart", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, _ := newBreaker(t, PolicyQueue, base)
+		base.SetError(outage)
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-1")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+
+		restarted, err := Wrap(base, r.cfg)
+		if err != nil {
+			t.Fatalf("Wrap failed: %v", err)
+		}
+		if n := restarted.Status().Queued; n != 1 {
+			t.Fatalf("expected the queued write to survive a restart, got %d", n)
+		}
+
+		// A closed breaker replays on its first probe
+		base.SetError(nil)
+		restarted.Probe(ctx)
+		if _, err := base.GetTransaction(ctx, "tenant-001", "tx-1"); err != nil {
+			t.Errorf("expected tx-1 to be replayed: %v", err)
+		}
+	})
+
+	t.Run("TruncatesWriteCutShort", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, _ := newBreaker(t, PolicyQueue, base)
+		base.SetError(outage)
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-1")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+
+		// A crash mid-append leaves half a line
+		f, err := os.OpenFile(r.cfg.WALPath, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatalf("OpenFile failed: %v", err)
+		}
+		f.WriteString(`{"op":"saveTransaction","tenantId":"ten`)
+		f.Close()
+
+		restarted, err := Wrap(base, r.cfg)
+		if err != nil {
+			t.Fatalf("Wrap failed: %v", err)
+		}
+		if err := restarted.SaveTransaction(ctx, "tenant-001", tx("tx-2")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		again, err := Wrap(base, r.cfg)
+		if err != nil {
+			t.Fatalf("Wrap failed: %v", err)
+		}
+		if n := again.Status().Queued; n != 2 {
+			t.Fatalf("expected both complete writes to be queued, got %d", n)
+		}
+
+		base.SetError(nil)
+		again.Probe(ctx)
+		for _, id := range []string{"tx-1", "tx-2"} {
+			if _, err := base.GetTransaction(ctx, "tenant-001", id); err != nil {
+				t.Errorf("expected %s to be replayed: %v", id, err)
+			}
+		}
+	})
+
+	t.Run("ReplayDropsRefusedWrites", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, now := newBreaker(t, PolicyQueue, base)
+		if err := base.SaveTransaction(ctx, "tenant-001", tx("tx-1")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+
+		// A duplicate queued during the outage is refused on replay
+		base.SetError(outage)
+		for _, id := range []string{"tx-1", "tx-2"} {
+			if err := r.SaveTransaction(ctx, "tenant-001", tx(id)); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+		}
+		base.SetError(nil)
+		*now = now.Add(time.Minute)
+		r.Probe(ctx)
+		status := r.Status()
+		if status.Queued != 0 || status.Replayed != 1 || status.Dropped != 1 {
+			t.Fatalf("expected one replayed and one dropped write, got %+v", status)
+		}
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, now := newBreaker(t, PolicyFailFast, base)
+		if r.Rejecting() {
+			t.Fatal("expected a closed breaker not to reject")
+		}
+
+		base.SetError(outage)
+		for range 2 {
+			if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-1")); !errors.Is(err, outage) {
+				t.Fatalf("expected the outage error, got %v", err)
+			}
+		}
+		if !r.Rejecting() {
+			t.Fatal("expected the breaker to open after 2 failures")
+		}
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-2")); !errors.Is(err, ErrOpen) {
+			t.Errorf("expected ErrOpen, got %v", err)
+		}
+		if status := r.Status(); status.Rejected != 1 || status.Queued != 0 {
+			t.Errorf("expected 1 rejected write, got %+v", status)
+		}
+
+		base.SetError(nil)
+		*now = now.Add(time.Minute)
+		r.Probe(ctx)
+		if r.Rejecting() {
+			t.Fatal("expected the breaker to close once the database answers")
+		}
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-2")); err != nil {
+			t.Errorf("SaveTransaction failed: %v", err)
+		}
+	})
+
+	t.Run("InvalidInputDoesNotTrip", func(t *testing.T) {
+		r, _ := newBreaker(t, PolicyFailFast, ospreytest.NewRepository(nil))
+		for range 3 {
+			if err := r.SaveTransaction(ctx, "", tx("tx-1")); err == nil {
+				t.Fatal("expected a missing tenant to fail")
+			}
+		}
+		if r.Rejecting() {
+			t.Error("expected invalid input not to open the breaker")
+		}
+	})
+
+	t.Run("CallerCancellationDoesNotTrip", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		r, _ := newBreaker(t, PolicyFailFast, base)
+		base.SetError(context.Canceled)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		for range 3 {
+			if err := r.SaveTransaction(cancelled, "tenant-001", tx("tx-1")); err == nil {
+				t.Fatal("expected a cancelled write to fail")
+			}
+		}
+		if status := r.Status(); status.State != StateClosed || status.Failures != 0 {
+			t.Errorf("expected cancelled writes not to count, got %+v", status)
+		}
+	})
+
+	t.Run("QueueFull", func(t *testing.T) {
+		base := ospreytest.NewRepository(nil)
+		cfg := domain.DefaultConfig().Breaker
+		cfg.Policy, cfg.Failures, cfg.MaxQueued = PolicyQueue, 1, 1
+		cfg.WALPath = filepath.Join(t.TempDir(), "wal.jsonl")
+		r, err := Wrap(base, cfg)
+		if err != nil {
+			t.Fatalf("Wrap failed: %v", err)
+		}
+
+		base.SetError(outage)
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-1")); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		if err := r.SaveTransaction(ctx, "tenant-001", tx("tx-2")); !errors.Is(err, ErrOpen) {
+			t.Errorf("expected a full log to reject with ErrOpen, got %v", err)
+		}
+	})
+
+	t.Run("UnknownPolicy", func(t *testing.T) {
+		if _, err := Wrap(ospreytest.NewRepository(nil), domain.BreakerConfig{Policy: "retry"}); err == nil {
+			t.Error("expected an unknown policy to fail")
+		}
+	})
+}
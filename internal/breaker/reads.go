@@ -0,0 +1,97 @@
+package breaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// The reads of the evaluation path, by the duplicate check, velocity and
+// the enrichers, fail with ErrOpen while the breaker is open rather than
+// each waiting on the database. Enrichers report the failure as a
+// degradation, and the evaluation goes on without them.
+
+// isOpen reports whether the breaker is open.
+func (r *Repository) isOpen() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.open
+}
+
+func (r *Repository) GetTransaction(ctx context.Context, tenantID string, txID string) (*domain.Transaction, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetTransaction(ctx, tenantID, txID)
+}
+
+func (r *Repository) GetTransactionsByEntity(ctx context.Context, tenantID string, entityID string, since time.Time) ([]*domain.Transaction, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetTransactionsByEntity(ctx, tenantID, entityID, since)
+}
+
+func (r *Repository) GetPartyKYC(ctx context.Context, tenantID string, entityID string) (*domain.PartyKYC, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetPartyKYC(ctx, tenantID, entityID)
+}
+
+func (r *Repository) ListCorridorRisks(ctx context.Context, tenantID string) ([]*domain.CorridorRisk, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.ListCorridorRisks(ctx, tenantID)
+}
+
+func (r *Repository) MatchWatchlist(ctx context.Context, tenantID string, subject domain.WatchlistSubject) ([]*domain.WatchlistEntry, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.MatchWatchlist(ctx, tenantID, subject)
+}
+
+func (r *Repository) ListOutcomes(ctx context.Context, tenantID string, filter domain.OutcomeFilter) ([]*domain.EvaluationOutcome, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.ListOutcomes(ctx, tenantID, filter)
+}
+
+func (r *Repository) GetScoringConfig(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetScoringConfig(ctx, tenantID)
+}
+
+func (r *Repository) GetCounterpartyEdge(ctx context.Context, tenantID string, debtorID string, creditorID string) (*domain.CounterpartyEdge, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetCounterpartyEdge(ctx, tenantID, debtorID, creditorID)
+}
+
+func (r *Repository) ListCounterpartyEdges(ctx context.Context, tenantID string, partyID string, since time.Time) ([]*domain.CounterpartyEdge, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.ListCounterpartyEdges(ctx, tenantID, partyID, since)
+}
+
+func (r *Repository) GetEntityBaseline(ctx context.Context, tenantID string, entityID string) (*domain.EntityBaseline, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetEntityBaseline(ctx, tenantID, entityID)
+}
+
+func (r *Repository) GetAccountBalance(ctx context.Context, tenantID string, accountID string) (*domain.AccountBalance, error) {
+	if r.isOpen() {
+		return nil, ErrOpen
+	}
+	return r.Repository.GetAccountBalance(ctx, tenantID, accountID)
+}
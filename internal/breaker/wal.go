@@ -0,0 +1,225 @@
+package breaker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Operations of a queued write.
+const (
+	opTransaction = "saveTransaction"
+	opEvaluation  = "saveEvaluation"
+)
+
+// errFull is returned when the log holds MaxQueued writes.
+var errFull = errors.New("write-ahead log is full")
+
+// entry is a queued write, one JSON line of the log.
+type entry struct {
+	Op          string              `json:"op"`
+	TenantID    string              `json:"tenantId"`
+	Transaction *domain.Transaction `json:"transaction,omitempty"`
+	Evaluation  *domain.Evaluation  `json:"evaluation,omitempty"`
+
+	// OriginalMessage is kept beside the transaction, which doesn't encode it
+	OriginalMessage []byte `json:"originalMessage,omitempty"`
+}
+
+// apply runs the write against repo.
+func (e *entry) apply(ctx context.Context, repo domain.Repository) error {
+	switch {
+	case e.Op == opTransaction && e.Transaction != nil:
+		e.Transaction.OriginalMessage = e.OriginalMessage
+		return repo.SaveTransaction(ctx, e.TenantID, e.Transaction)
+	case e.Op == opEvaluation && e.Evaluation != nil:
+		return repo.SaveEvaluation(ctx, e.TenantID, e.Evaluation)
+	}
+	return fmt.Errorf("unknown queued write %q", e.Op)
+}
+
+// wal is an append-only file of queued writes.
+type wal struct {
+	path string
+	max  int
+
+	mu    sync.Mutex
+	file  *os.File
+	count int
+}
+
+// openWAL opens the log at path, creating it if needed, and counts the
+// writes already in it. A write cut short by a crash is truncated away, so
+// the writes appended after it can be read.
+func openWAL(path string, max int) (*wal, error) {
+	if path == "" {
+		return nil, errors.New("breaker write-ahead log path is required with the queue policy")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	w := &wal{path: path, max: max, file: file}
+	entries, end, err := w.read()
+	if err == nil {
+		err = w.truncate(end)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w.count = len(entries)
+	return w, nil
+}
+
+// truncate cuts the log at end, the end of its last complete write, if
+// anything follows it. The caller holds mu.
+func (w *wal) truncate(end int64) error {
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == end {
+		return nil
+	}
+	slog.Warn("truncating incomplete write-ahead log entry", "path", w.path, "bytes", info.Size()-end)
+	if err := w.file.Truncate(end); err != nil {
+		return fmt.Errorf("failed to truncate write-ahead log: %w", err)
+	}
+	return w.file.Sync()
+}
+
+// pending returns how many writes await replay.
+func (w *wal) pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// append adds a write to the log and syncs it to disk.
+func (w *wal) append(e *entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode queued write: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.max > 0 && w.count >= w.max {
+		return errFull
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// drain passes the queued writes to fn in order and removes those it
+// handled. It stops at the first error, which it returns. Writes appended
+// meanwhile wait for the next drain.
+func (w *wal) drain(fn func(*entry) error) error {
+	w.mu.Lock()
+	entries, _, err := w.read()
+	if err == nil && len(entries) == 0 {
+		w.count = 0
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	done := 0
+	var fnErr error
+	for _, e := range entries {
+		if fnErr = fn(e); fnErr != nil {
+			break
+		}
+		done++
+	}
+	if done == 0 {
+		return fnErr
+	}
+
+	// Rewrite the log without the handled writes, keeping any appended
+	// since it was read
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	current, _, err := w.read()
+	if err != nil {
+		return err
+	}
+	if err := w.rewrite(current[done:]); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// read decodes every write in the log and returns the offset where the
+// last complete one ends. The caller holds mu.
+func (w *wal) read() ([]*entry, int64, error) {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	var entries []*entry
+	var end int64
+	reader := bufio.NewReader(w.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without its newline is a write cut short by a crash
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read write-ahead log: %w", err)
+		}
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			break
+		}
+		entries = append(entries, &e)
+		end += int64(len(line))
+	}
+	return entries, end, nil
+}
+
+// rewrite replaces the log with entries. The caller holds mu.
+func (w *wal) rewrite(entries []*entry) error {
+	tmp := w.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(file)
+	for _, e := range entries {
+		line, _ := json.Marshal(e)
+		out.Write(append(line, '\n'))
+	}
+	if err := errors.Join(out.Flush(), file.Sync(), file.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, w.path); err != nil {
+		return err
+	}
+
+	reopened, err := os.OpenFile(w.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	w.file.Close()
+	w.file = reopened
+	w.count = len(entries)
+	return nil
+}
@@ -12,6 +12,9 @@ import (
 func New(cfg domain.EventBusConfig) (domain.EventBus, error) {
 	switch cfg.Type {
 	case "channel":
+		if cfg.ChannelSynchronous {
+			return NewSyncChannelBus(), nil
+		}
 		return NewChannelBus(cfg.ChannelBufferSize), nil
 
 	case "nats":
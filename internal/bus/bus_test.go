@@ -2,6 +2,7 @@ package bus
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -34,9 +35,6 @@ func TestChannelBus(t *testing.T) {
 			t.Fatalf("subscribe failed: %v", err)
 		}
 
-		// Allow subscription to be active
-		time.Sleep(10 * time.Millisecond)
-
 		err = bus.Publish(ctx, tenantID, "test.topic", []byte("hello"))
 		if err != nil {
 			t.Fatalf("publish failed: %v", err)
@@ -85,11 +83,9 @@ func TestChannelBus(t *testing.T) {
 			return nil
 		})
 
-		time.Sleep(10 * time.Millisecond)
-
 		// Publish to tenant1
 		bus.Publish(ctx, tenant1, "isolation.topic", []byte("msg1"))
-		time.Sleep(50 * time.Millisecond)
+		flush(t, bus)
 
 		if received1.Load() != 1 {
 			t.Errorf("tenant1 should receive 1 message, got %d", received1.Load())
@@ -121,20 +117,17 @@ func TestChannelBus(t *testing.T) {
 			return nil
 		})
 
-		time.Sleep(10 * time.Millisecond)
-
 		bus.Publish(ctx, tenantID, "unsub.topic", []byte("msg1"))
-		time.Sleep(50 * time.Millisecond)
+		flush(t, bus)
 
 		if count.Load() != 1 {
 			t.Errorf("expected 1 message before unsubscribe, got %d", count.Load())
 		}
 
 		sub.Unsubscribe()
-		time.Sleep(10 * time.Millisecond)
 
 		bus.Publish(ctx, tenantID, "unsub.topic", []byte("msg2"))
-		time.Sleep(50 * time.Millisecond)
+		flush(t, bus)
 
 		// Should still be 1 after unsubscribe
 		if count.Load() != 1 {
@@ -155,10 +148,8 @@ func TestChannelBus(t *testing.T) {
 			return nil
 		})
 
-		time.Sleep(10 * time.Millisecond)
-
 		bus.Publish(ctx, tenantID, "multi.topic", []byte("broadcast"))
-		time.Sleep(50 * time.Millisecond)
+		flush(t, bus)
 
 		if count1.Load() != 1 || count2.Load() != 1 {
 			t.Errorf("expected both subscribers to receive, got %d and %d", count1.Load(), count2.Load())
@@ -256,8 +247,6 @@ func TestChannelBusHighLoad(t *testing.T) {
 		return nil
 	})
 
-	time.Sleep(10 * time.Millisecond)
-
 	// Publish many messages
 	for i := 0; i < messageCount; i++ {
 		bus.Publish(ctx, tenantID, "load.topic", []byte("msg"))
@@ -279,3 +268,172 @@ func TestChannelBusHighLoad(t *testing.T) {
 		t.Fatalf("timeout: received %d/%d messages", received.Load(), messageCount)
 	}
 }
+
+func TestChannelBusFlush(t *testing.T) {
+	bus := NewChannelBus(100)
+	defer bus.Close()
+
+	ctx := context.Background()
+	release := make(chan struct{})
+	var handled atomic.Int32
+
+	bus.Subscribe(ctx, "tenant-001", "slow.topic", func(ctx context.Context, msg *domain.Message) error {
+		<-release
+		handled.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(ctx, "tenant-001", "slow.topic", []byte("msg"))
+	}
+
+	t.Run("TimesOutWhileBusy", func(t *testing.T) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if err := bus.Flush(timeoutCtx); err == nil {
+			t.Error("expected flush to time out while handler is blocked")
+		}
+	})
+
+	t.Run("WaitsForHandlers", func(t *testing.T) {
+		close(release)
+		flush(t, bus)
+		if handled.Load() != 3 {
+			t.Errorf("expected 3 handled messages after flush, got %d", handled.Load())
+		}
+	})
+
+	t.Run("UnsubscribeReleasesQueued", func(t *testing.T) {
+		block := make(chan struct{})
+		sub, _ := bus.Subscribe(ctx, "tenant-001", "stuck.topic", func(ctx context.Context, msg *domain.Message) error {
+			<-block
+			return nil
+		})
+		bus.Publish(ctx, "tenant-001", "stuck.topic", []byte("a"))
+		bus.Publish(ctx, "tenant-001", "stuck.topic", []byte("b"))
+
+		sub.Unsubscribe()
+		close(block)
+		flush(t, bus)
+	})
+}
+
+func TestChannelBusDrain(t *testing.T) {
+	bus := NewChannelBus(100)
+	ctx := context.Background()
+
+	var handled atomic.Int32
+	bus.Subscribe(ctx, "tenant-001", "drain.topic", func(ctx context.Context, msg *domain.Message) error {
+		handled.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		bus.Publish(ctx, "tenant-001", "drain.topic", []byte("msg"))
+	}
+
+	if err := bus.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if handled.Load() != 10 {
+		t.Errorf("expected 10 handled messages, got %d", handled.Load())
+	}
+	if err := bus.Publish(ctx, "tenant-001", "drain.topic", []byte("late")); err == nil {
+		t.Error("expected publish to fail after drain")
+	}
+}
+
+func TestSyncChannelBus(t *testing.T) {
+	bus := NewSyncChannelBus()
+	defer bus.Close()
+
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	t.Run("DeliversBeforePublishReturns", func(t *testing.T) {
+		var received []string
+		bus.Subscribe(ctx, tenantID, "sync.topic", func(ctx context.Context, msg *domain.Message) error {
+			received = append(received, string(msg.Payload))
+			return nil
+		})
+
+		for _, payload := range []string{"a", "b", "c"} {
+			bus.Publish(ctx, tenantID, "sync.topic", []byte(payload))
+		}
+
+		if len(received) != 3 || received[0] != "a" || received[2] != "c" {
+			t.Errorf("expected ordered delivery of 3 messages, got %v", received)
+		}
+	})
+
+	t.Run("ReturnsHandlerErrors", func(t *testing.T) {
+		bus.Subscribe(ctx, tenantID, "failing.topic", func(ctx context.Context, msg *domain.Message) error {
+			return errors.New("handler failed")
+		})
+
+		if err := bus.Publish(ctx, tenantID, "failing.topic", nil); err == nil {
+			t.Error("expected handler error from publish")
+		}
+	})
+
+	t.Run("HandlerCanPublish", func(t *testing.T) {
+		var forwarded atomic.Bool
+		bus.Subscribe(ctx, tenantID, "first.topic", func(ctx context.Context, msg *domain.Message) error {
+			return bus.Publish(ctx, tenantID, "second.topic", msg.Payload)
+		})
+		bus.Subscribe(ctx, tenantID, "second.topic", func(ctx context.Context, msg *domain.Message) error {
+			forwarded.Store(true)
+			return nil
+		})
+
+		bus.Publish(ctx, tenantID, "first.topic", []byte("x"))
+		if !forwarded.Load() {
+			t.Error("expected nested publish to be delivered")
+		}
+	})
+
+	t.Run("FromConfig", func(t *testing.T) {
+		b, err := New(domain.EventBusConfig{Type: "channel", ChannelSynchronous: true})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		defer b.Close()
+
+		if !b.(*ChannelBus).Synchronous() {
+			t.Error("expected synchronous channel bus")
+		}
+	})
+}
+
+// flush waits for the bus to deliver everything published so far.
+func flush(t *testing.T, bus *ChannelBus) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+}
+
+func TestJetStreamSubjects(t *testing.T) {
+	for topic, durable := range map[string]bool{
+		domain.TopicTransactionIngested:             true,
+		domain.LaneTopic(domain.LaneBatch):          true,
+		domain.TopicDeadLetter:                      true,
+		domain.TopicDecision:                        false,
+		domain.TopicAlert:                           false,
+		domain.TopicTransactionIngested + "_legacy": false,
+	} {
+		if durableTopic(topic) != durable {
+			t.Errorf("%s: expected durable %v", topic, durable)
+		}
+	}
+
+	if name := consumerName("*", domain.LaneTopic(domain.LaneRealtime)); name != "osprey___osprey_transaction_ingested_realtime" {
+		t.Errorf("unexpected consumer name %q", name)
+	}
+	if tenantID, topic := splitSubject("osprey.tenant-a." + domain.TopicTransactionIngested); tenantID != "tenant-a" || topic != domain.TopicTransactionIngested {
+		t.Errorf("unexpected tenant %q and topic %q", tenantID, topic)
+	}
+}
@@ -3,8 +3,10 @@ package bus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,24 +15,39 @@ import (
 
 // ChannelBus implements EventBus using Go channels.
 // Used as the Community tier event bus.
+//
+// By default delivery is asynchronous: each subscription has a buffered
+// channel drained by its own goroutine, and messages are dropped when the
+// buffer is full. In synchronous mode Publish calls every handler in the
+// publisher's goroutine and returns their errors, so nothing is dropped and
+// results are visible as soon as Publish returns.
 type ChannelBus struct {
 	mu            sync.RWMutex
 	bufferSize    int
+	synchronous   bool
 	subscriptions map[string][]*channelSubscription
 	closed        bool
+	draining      bool
+
+	// In-flight tracking for Flush
+	pendingMu sync.Mutex
+	pending   int
+	idle      []chan struct{}
+	dropped   atomic.Int64
 }
 
 type channelSubscription struct {
+	bus      *ChannelBus
 	id       string
 	tenantID string
 	topic    string
 	handler  domain.MessageHandler
-	msgCh    chan *domain.Message
+	msgCh    chan *domain.Message // nil in synchronous mode
 	ctx      context.Context
 	cancel   context.CancelFunc
 }
 
-// NewChannelBus creates a new channel-based event bus.
+// NewChannelBus creates a new channel-based event bus with asynchronous delivery.
 func NewChannelBus(bufferSize int) *ChannelBus {
 	if bufferSize <= 0 {
 		bufferSize = 1000
@@ -41,18 +58,26 @@ func NewChannelBus(bufferSize int) *ChannelBus {
 	}
 }
 
+// NewSyncChannelBus creates a channel bus that delivers messages synchronously.
+// Use it in tests and low-throughput deployments where losing or reordering
+// messages matters more than publisher latency.
+func NewSyncChannelBus() *ChannelBus {
+	b := NewChannelBus(0)
+	b.synchronous = true
+	return b
+}
+
+// Synchronous reports whether the bus delivers messages in the publisher's goroutine.
+func (b *ChannelBus) Synchronous() bool {
+	return b.synchronous
+}
+
 // Publish sends a message to a topic.
 func (b *ChannelBus) Publish(ctx context.Context, tenantID string, topic string, payload []byte) error {
 	if tenantID == "" {
 		return fmt.Errorf("tenantID is required")
 	}
 
-	b.mu.RLock()
-	if b.closed {
-		b.mu.RUnlock()
-		return fmt.Errorf("bus is closed")
-	}
-
 	// Create message
 	msg := &domain.Message{
 		ID:        uuid.New().String(),
@@ -63,22 +88,52 @@ func (b *ChannelBus) Publish(ctx context.Context, tenantID string, topic string,
 		Timestamp: time.Now().UnixNano(),
 	}
 
+	// Hold the read lock while enqueueing so Close can't close a channel mid-send
+	b.mu.RLock()
+	if b.closed || b.draining {
+		b.mu.RUnlock()
+		return fmt.Errorf("bus is closed")
+	}
+
 	// Get subscriptions for this topic
 	subs := b.subscriptions[b.makeKey(tenantID, topic)]
-	b.mu.RUnlock()
+
+	if b.synchronous {
+		subs = append([]*channelSubscription(nil), subs...)
+		b.mu.RUnlock()
+		return b.deliver(subs, msg)
+	}
 
 	// Send to all matching subscribers (non-blocking)
 	for _, sub := range subs {
+		b.addPending(1)
 		select {
 		case sub.msgCh <- msg:
 		default:
 			// Channel full, skip this message for this subscriber
+			b.addPending(-1)
+			b.dropped.Add(1)
 		}
 	}
+	b.mu.RUnlock()
 
 	return nil
 }
 
+// deliver runs handlers in the caller's goroutine and joins their errors.
+func (b *ChannelBus) deliver(subs []*channelSubscription, msg *domain.Message) error {
+	var errs []error
+	for _, sub := range subs {
+		if sub.ctx.Err() != nil {
+			continue
+		}
+		if err := sub.handler(sub.ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Subscribe registers a handler for a topic.
 func (b *ChannelBus) Subscribe(ctx context.Context, tenantID string, topic string, handler domain.MessageHandler) (domain.Subscription, error) {
 	if tenantID == "" {
@@ -95,17 +150,20 @@ func (b *ChannelBus) Subscribe(ctx context.Context, tenantID string, topic strin
 	subCtx, cancel := context.WithCancel(ctx)
 
 	sub := &channelSubscription{
+		bus:      b,
 		id:       uuid.New().String(),
 		tenantID: tenantID,
 		topic:    topic,
 		handler:  handler,
-		msgCh:    make(chan *domain.Message, b.bufferSize),
 		ctx:      subCtx,
 		cancel:   cancel,
 	}
 
 	// Start message handler goroutine
-	go b.handleMessages(sub)
+	if !b.synchronous {
+		sub.msgCh = make(chan *domain.Message, b.bufferSize)
+		go b.handleMessages(sub)
+	}
 
 	key := b.makeKey(tenantID, topic)
 	b.subscriptions[key] = append(b.subscriptions[key], sub)
@@ -118,15 +176,108 @@ func (b *ChannelBus) handleMessages(sub *channelSubscription) {
 	for {
 		select {
 		case <-sub.ctx.Done():
-			return
+			// Stop new deliveries, then release anything still queued so Flush returns
+			b.removeSubscription(sub)
+			for {
+				select {
+				case msg, ok := <-sub.msgCh:
+					if !ok {
+						return
+					}
+					if msg != nil {
+						b.addPending(-1)
+					}
+				default:
+					return
+				}
+			}
 		case msg := <-sub.msgCh:
 			if msg != nil {
 				_ = sub.handler(sub.ctx, msg)
+				b.addPending(-1)
 			}
 		}
 	}
 }
 
+// Pending returns how many messages are queued for the subscription.
+func (s *channelSubscription) Pending() int {
+	return len(s.msgCh)
+}
+
+// removeSubscription detaches a subscription from its topic.
+func (b *ChannelBus) removeSubscription(sub *channelSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := b.makeKey(sub.tenantID, sub.topic)
+	subs := b.subscriptions[key]
+	for i, s := range subs {
+		if s == sub {
+			b.subscriptions[key] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(b.subscriptions[key]) == 0 {
+		delete(b.subscriptions, key)
+	}
+}
+
+// addPending adjusts the in-flight message count and wakes Flush callers at zero.
+func (b *ChannelBus) addPending(delta int) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	b.pending += delta
+	if b.pending == 0 {
+		for _, ch := range b.idle {
+			close(ch)
+		}
+		b.idle = nil
+	}
+}
+
+// Flush blocks until every message published so far has been handled, or ctx
+// is done. Messages published while Flush waits are included. In synchronous
+// mode it returns immediately.
+func (b *ChannelBus) Flush(ctx context.Context) error {
+	b.pendingMu.Lock()
+	if b.pending == 0 {
+		b.pendingMu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	b.idle = append(b.idle, idle)
+	b.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain stops accepting new messages, waits for in-flight messages to be
+// handled, then closes the bus. Use it for graceful shutdown.
+func (b *ChannelBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	b.draining = true
+	b.mu.Unlock()
+
+	err := b.Flush(ctx)
+	if closeErr := b.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Dropped returns how many deliveries were skipped because a subscriber's
+// buffer was full.
+func (b *ChannelBus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
 // Request implements request-reply pattern using channels.
 func (b *ChannelBus) Request(ctx context.Context, tenantID string, topic string, payload []byte) ([]byte, error) {
 	if tenantID == "" {
@@ -176,7 +327,8 @@ func (b *ChannelBus) Ping(ctx context.Context) error {
 	return nil
 }
 
-// Close closes the event bus.
+// Close closes the event bus. Messages still queued are discarded; use Drain
+// to deliver them first.
 func (b *ChannelBus) Close() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -191,7 +343,9 @@ func (b *ChannelBus) Close() error {
 	for _, subs := range b.subscriptions {
 		for _, sub := range subs {
 			sub.cancel()
-			close(sub.msgCh)
+			if sub.msgCh != nil {
+				close(sub.msgCh)
+			}
 		}
 	}
 
@@ -205,6 +359,7 @@ func (b *ChannelBus) makeKey(tenantID, topic string) string {
 
 // Unsubscribe stops receiving messages.
 func (s *channelSubscription) Unsubscribe() error {
+	s.bus.removeSubscription(s)
 	s.cancel()
 	return nil
 }
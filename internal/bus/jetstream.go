@@ -0,0 +1,263 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// JetStream defaults.
+const (
+	DefaultNATSStream     = "OSPREY"
+	DefaultNATSMaxDeliver = 5
+	DefaultNATSAckWait    = 30 * time.Second
+	DefaultNATSMaxAge     = 72 * time.Hour
+)
+
+// durableTopic reports whether a topic goes through the JetStream stream:
+// transaction ingest, its priority lanes and the dead letters. Other topics,
+// and every request-reply, stay on core NATS.
+func durableTopic(topic string) bool {
+	return topic == domain.TopicTransactionIngested ||
+		strings.HasPrefix(topic, domain.TopicTransactionIngested+".") ||
+		topic == domain.TopicDeadLetter
+}
+
+// streamSubjects are the subjects captured by the stream, for every tenant.
+func streamSubjects() []string {
+	return []string{
+		"osprey.*." + domain.TopicTransactionIngested,
+		"osprey.*." + domain.TopicTransactionIngested + ".>",
+		"osprey.*." + domain.TopicDeadLetter,
+	}
+}
+
+// consumerName returns the durable consumer name of a tenant's topic
+// subscription. Consumer names can't hold dots or wildcards.
+func consumerName(tenantID, topic string) string {
+	name := "osprey_" + tenantID + "_" + topic
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '/', '\\':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// splitSubject returns the tenant and topic of an osprey.<tenant>.<topic>
+// subject.
+func splitSubject(subject string) (tenantID, topic string) {
+	tokens := strings.SplitN(subject, ".", 3)
+	if len(tokens) < 3 {
+		return "_unknown", subject
+	}
+	return tokens[1], tokens[2]
+}
+
+// setupJetStream creates or updates the stream of the durable topics.
+func (b *NATSBus) setupJetStream(ctx context.Context) error {
+	js, err := jetstream.New(b.conn)
+	if err != nil {
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      b.config.NATSStream,
+		Subjects:  streamSubjects(),
+		Retention: jetstream.LimitsPolicy,
+		Storage:   jetstream.FileStorage,
+		MaxAge:    b.config.NATSMaxAge,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create stream %s: %w", b.config.NATSStream, err)
+	}
+	b.js = js
+
+	slog.Info("NATS JetStream enabled",
+		"stream", b.config.NATSStream,
+		"max_deliver", b.config.NATSMaxDeliver,
+		"ack_wait", b.config.NATSAckWait,
+	)
+	return nil
+}
+
+// publishDurable publishes to the stream and waits for it to store the
+// message. The message ID deduplicates retried publishes.
+func (b *NATSBus) publishDurable(ctx context.Context, subject string, msg *domain.Message, data []byte) error {
+	if _, err := b.js.Publish(ctx, subject, data, jetstream.WithMsgID(msg.ID)); err != nil {
+		return fmt.Errorf("failed to publish to stream: %w", err)
+	}
+	return nil
+}
+
+// subscribeDurable consumes a subject through a durable consumer, so
+// messages published while no worker runs are delivered when one starts.
+// A message is acknowledged when the handler succeeds and redelivered when
+// it fails, until its last delivery, which goes to the dead-letter subject.
+func (b *NATSBus) subscribeDurable(ctx context.Context, tenantID, topic, subject string, handler domain.MessageHandler) (domain.Subscription, error) {
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.config.NATSStream, jetstream.ConsumerConfig{
+		Durable:       consumerName(tenantID, topic),
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.config.NATSAckWait,
+		MaxDeliver:    b.config.NATSMaxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	cc, err := consumer.Consume(func(m jetstream.Msg) {
+		b.handleDurable(ctx, m, handler)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume: %w", err)
+	}
+
+	sub := &jetStreamSubscription{
+		id:       uuid.New().String(),
+		topic:    topic,
+		consumer: consumer,
+		cc:       cc,
+	}
+	b.mu.Lock()
+	b.durableSubs[sub.id] = sub
+	b.mu.Unlock()
+	return sub, nil
+}
+
+// handleDurable runs handler on a stream message and acknowledges it, or
+// leaves that to the handler when it returns domain.ErrAckDeferred.
+func (b *NATSBus) handleDurable(ctx context.Context, m jetstream.Msg, handler domain.MessageHandler) {
+	var msg domain.Message
+	if err := json.Unmarshal(m.Data(), &msg); err != nil {
+		// Redelivering can't fix a malformed message
+		slog.Error("failed to unmarshal NATS message", "subject", m.Subject(), "error", err)
+		b.deadLetter(ctx, m, nil, 1, err)
+		return
+	}
+
+	settle := func(err error) { b.settle(ctx, m, &msg, err) }
+	err := handler(domain.WithDeferredAck(ctx, settle), &msg)
+	if errors.Is(err, domain.ErrAckDeferred) {
+		return
+	}
+	settle(err)
+}
+
+// settle acknowledges a handled stream message, or has it redelivered or
+// dead-lettered after the handler failed.
+func (b *NATSBus) settle(ctx context.Context, m jetstream.Msg, msg *domain.Message, err error) {
+	if err == nil {
+		if err := m.Ack(); err != nil {
+			slog.Warn("failed to ack NATS message", "subject", m.Subject(), "message_id", msg.ID, "error", err)
+		}
+		return
+	}
+
+	var delivered uint64 = 1
+	if meta, metaErr := m.Metadata(); metaErr == nil {
+		delivered = meta.NumDelivered
+	}
+	if int(delivered) >= b.config.NATSMaxDeliver {
+		slog.Error("handler error, dead-lettering message",
+			"subject", m.Subject(),
+			"message_id", msg.ID,
+			"deliveries", delivered,
+			"error", err,
+		)
+		b.deadLetter(ctx, m, msg, delivered, err)
+		return
+	}
+
+	slog.Warn("handler error, message will be redelivered",
+		"subject", m.Subject(),
+		"message_id", msg.ID,
+		"deliveries", delivered,
+		"error", err,
+	)
+	// Back off linearly with the number of deliveries
+	if err := m.NakWithDelay(time.Duration(delivered) * time.Second); err != nil {
+		slog.Warn("failed to nak NATS message", "subject", m.Subject(), "message_id", msg.ID, "error", err)
+	}
+}
+
+// deadLetter publishes a message to its tenant's dead-letter topic as a
+// domain.DeadLetter, the record the worker publishes, then stops its
+// redelivery. msg is nil if the message couldn't be parsed. If the publish
+// fails, the message is left to be redelivered instead.
+func (b *NATSBus) deadLetter(ctx context.Context, m jetstream.Msg, msg *domain.Message, delivered uint64, cause error) {
+	tenantID, topic := splitSubject(m.Subject())
+	dead := domain.DeadLetter{
+		ID:       uuid.New().String(),
+		TenantID: tenantID,
+		Topic:    topic,
+		Payload:  string(m.Data()),
+		Error:    cause.Error(),
+		Attempts: int(delivered),
+		Poison:   msg == nil,
+		FailedAt: time.Now().UTC(),
+	}
+	if msg != nil {
+		dead.MessageID = msg.ID
+		dead.Payload = string(msg.Payload)
+	}
+	payload, _ := json.Marshal(dead)
+	data, _ := json.Marshal(&domain.Message{
+		ID:        dead.ID,
+		TenantID:  tenantID,
+		Topic:     domain.TopicDeadLetter,
+		Payload:   payload,
+		Metadata:  make(map[string]string),
+		Timestamp: time.Now().UnixNano(),
+	})
+
+	if _, err := b.js.Publish(ctx, b.makeSubject(tenantID, domain.TopicDeadLetter), data, jetstream.WithMsgID(dead.ID)); err != nil {
+		slog.Error("failed to dead-letter NATS message", "subject", m.Subject(), "error", err)
+		_ = m.Nak()
+		return
+	}
+	if err := m.Term(); err != nil {
+		slog.Warn("failed to terminate NATS message", "subject", m.Subject(), "error", err)
+	}
+}
+
+// jetStreamSubscription is a subscription through a durable consumer.
+type jetStreamSubscription struct {
+	id       string
+	topic    string
+	consumer jetstream.Consumer
+	cc       jetstream.ConsumeContext
+}
+
+// Unsubscribe stops consuming. The durable consumer is kept, so messages
+// published meanwhile are delivered to the next subscription.
+func (s *jetStreamSubscription) Unsubscribe() error {
+	s.cc.Stop()
+	return nil
+}
+
+// Topic returns the subscribed topic.
+func (s *jetStreamSubscription) Topic() string {
+	return s.topic
+}
+
+// Pending returns how many messages wait for the consumer: stored but not
+// yet delivered, or delivered but not yet acknowledged.
+func (s *jetStreamSubscription) Pending() int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	info, err := s.consumer.Info(ctx)
+	if err != nil {
+		return 0
+	}
+	return int(info.NumPending) + info.NumAckPending
+}
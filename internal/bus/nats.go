@@ -10,16 +10,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/opensource-finance/osprey/internal/domain"
 )
 
 // NATSBus implements EventBus using NATS.
-// Used as the Pro tier event bus with resilience.
+// Used as the Pro tier event bus with resilience. In JetStream mode,
+// transaction ingest is durable and delivered at least once.
 type NATSBus struct {
 	mu            sync.RWMutex
 	conn          *nats.Conn
 	subscriptions map[string]*natsSubscription
 	config        domain.EventBusConfig
+
+	// JetStream mode; js is nil on core NATS
+	js          jetstream.JetStream
+	durableSubs map[string]*jetStreamSubscription
 }
 
 type natsSubscription struct {
@@ -40,6 +46,18 @@ func NewNATSBus(cfg domain.EventBusConfig) (*NATSBus, error) {
 	if cfg.NATSReconnectWait == 0 {
 		cfg.NATSReconnectWait = 5
 	}
+	if cfg.NATSStream == "" {
+		cfg.NATSStream = DefaultNATSStream
+	}
+	if cfg.NATSMaxDeliver <= 0 {
+		cfg.NATSMaxDeliver = DefaultNATSMaxDeliver
+	}
+	if cfg.NATSAckWait <= 0 {
+		cfg.NATSAckWait = DefaultNATSAckWait
+	}
+	if cfg.NATSMaxAge <= 0 {
+		cfg.NATSMaxAge = DefaultNATSMaxAge
+	}
 
 	// Configure NATS connection with resilience
 	opts := []nats.Option{
@@ -97,11 +115,21 @@ func NewNATSBus(cfg domain.EventBusConfig) (*NATSBus, error) {
 		"server_id", conn.ConnectedServerId(),
 	)
 
-	return &NATSBus{
+	b := &NATSBus{
 		conn:          conn,
 		subscriptions: make(map[string]*natsSubscription),
 		config:        cfg,
-	}, nil
+		durableSubs:   make(map[string]*jetStreamSubscription),
+	}
+	if cfg.NATSJetStream {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := b.setupJetStream(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return b, nil
 }
 
 // Publish sends a message to a NATS subject.
@@ -126,6 +154,9 @@ func (b *NATSBus) Publish(ctx context.Context, tenantID string, topic string, pa
 	}
 
 	subject := b.makeSubject(tenantID, topic)
+	if b.js != nil && durableTopic(topic) {
+		return b.publishDurable(ctx, subject, msg, data)
+	}
 	return b.conn.Publish(subject, data)
 }
 
@@ -136,6 +167,9 @@ func (b *NATSBus) Subscribe(ctx context.Context, tenantID string, topic string,
 	}
 
 	subject := b.makeSubject(tenantID, topic)
+	if b.js != nil && durableTopic(topic) {
+		return b.subscribeDurable(ctx, tenantID, topic, subject, handler)
+	}
 
 	// Create NATS subscription
 	natsSub, err := b.conn.Subscribe(subject, func(m *nats.Msg) {
@@ -236,6 +270,10 @@ func (b *NATSBus) Close() error {
 		_ = sub.sub.Unsubscribe()
 	}
 	b.subscriptions = make(map[string]*natsSubscription)
+	for _, sub := range b.durableSubs {
+		sub.cc.Stop()
+	}
+	b.durableSubs = make(map[string]*jetStreamSubscription)
 
 	// Close connection
 	b.conn.Close()
@@ -261,3 +299,13 @@ func (s *natsSubscription) Unsubscribe() error {
 func (s *natsSubscription) Topic() string {
 	return s.topic
 }
+
+// Pending returns how many messages the client has received for the
+// subscription but not yet handled.
+func (s *natsSubscription) Pending() int {
+	msgs, _, err := s.sub.Pending()
+	if err != nil {
+		return 0
+	}
+	return msgs
+}
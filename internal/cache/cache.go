@@ -147,6 +147,16 @@ func (c *TwoPhaseCache) IncrementCounter(ctx context.Context, tenantID string, k
 	return c.remote.IncrementCounter(ctx, tenantID, key, window)
 }
 
+// IncrementCounterBy uses Redis for distributed atomic counters.
+func (c *TwoPhaseCache) IncrementCounterBy(ctx context.Context, tenantID string, key string, delta int64, window time.Duration) (int64, error) {
+	return c.remote.IncrementCounterBy(ctx, tenantID, key, delta, window)
+}
+
+// GetCounters reads the counters from Redis.
+func (c *TwoPhaseCache) GetCounters(ctx context.Context, tenantID string, keys []string) ([]int64, error) {
+	return c.remote.GetCounters(ctx, tenantID, keys)
+}
+
 // Ping checks both L1 and L2 health.
 func (c *TwoPhaseCache) Ping(ctx context.Context) error {
 	if err := c.local.Ping(ctx); err != nil {
@@ -152,6 +152,26 @@ func TestLRUCache(t *testing.T) {
 		}
 	})
 
+	t.Run("IncrementCounterBy", func(t *testing.T) {
+		if n, err := cache.IncrementCounterBy(ctx, tenantID, "bucket-1", 3, time.Minute); err != nil || n != 3 {
+			t.Fatalf("expected 3, got %d, %v", n, err)
+		}
+		if n, _ := cache.IncrementCounterBy(ctx, tenantID, "bucket-1", -1, time.Minute); n != 2 {
+			t.Errorf("expected 2 after a negative delta, got %d", n)
+		}
+
+		counts, err := cache.GetCounters(ctx, tenantID, []string{"bucket-1", "missing"})
+		if err != nil {
+			t.Fatalf("GetCounters failed: %v", err)
+		}
+		if len(counts) != 2 || counts[0] != 2 || counts[1] != 0 {
+			t.Errorf("expected [2 0], got %v", counts)
+		}
+		if counts, _ := cache.GetCounters(ctx, "other-tenant", []string{"bucket-1"}); counts[0] != 0 {
+			t.Errorf("expected another tenant not to see the counter, got %v", counts)
+		}
+	})
+
 	t.Run("TransactionCache", func(t *testing.T) {
 		data := &domain.DataCache{
 			DebtorID:   "debtor-001",
@@ -152,6 +152,11 @@ func (c *LRUCache) SetTransaction(ctx context.Context, tenantID string, txID str
 
 // IncrementCounter atomically increments a counter.
 func (c *LRUCache) IncrementCounter(ctx context.Context, tenantID string, key string, window time.Duration) (int64, error) {
+	return c.IncrementCounterBy(ctx, tenantID, key, 1, window)
+}
+
+// IncrementCounterBy atomically adds delta to a counter.
+func (c *LRUCache) IncrementCounterBy(ctx context.Context, tenantID string, key string, delta int64, window time.Duration) (int64, error) {
 	if tenantID == "" {
 		return 0, fmt.Errorf("tenantID is required")
 	}
@@ -165,18 +170,46 @@ func (c *LRUCache) IncrementCounter(ctx context.Context, tenantID string, key st
 	entry, ok := c.counters[fullKey]
 
 	if !ok || now.After(entry.expiresAt) {
+		// Drop expired counters before the map outgrows the cache
+		if len(c.counters) >= c.maxSize {
+			for k, e := range c.counters {
+				if now.After(e.expiresAt) {
+					delete(c.counters, k)
+				}
+			}
+		}
+
 		// Start new counter window
 		c.counters[fullKey] = &counterEntry{
-			count:     1,
+			count:     delta,
 			expiresAt: now.Add(window),
 		}
-		return 1, nil
+		return delta, nil
 	}
 
-	entry.count++
+	entry.count += delta
 	return entry.count, nil
 }
 
+// GetCounters returns the values of counters.
+func (c *LRUCache) GetCounters(ctx context.Context, tenantID string, keys []string) ([]int64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	values := make([]int64, len(keys))
+	for i, key := range keys {
+		if entry, ok := c.counters[c.makeKey(tenantID, "counter:"+key)]; ok && !now.After(entry.expiresAt) {
+			values[i] = entry.count
+		}
+	}
+	return values, nil
+}
+
 // Ping checks cache health.
 func (c *LRUCache) Ping(ctx context.Context) error {
 	return nil
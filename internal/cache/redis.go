@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/opensource-finance/osprey/internal/domain"
@@ -99,8 +100,24 @@ func (c *RedisCache) SetTransaction(ctx context.Context, tenantID string, txID s
 	return c.Set(ctx, tenantID, "tx:"+txID, bytes, ttl)
 }
 
+// incrementScript adds to a counter and sets its expiry when it has none,
+// so a new counter expires after the window.
+var incrementScript = redis.NewScript(`
+	local current = redis.call('INCRBY', KEYS[1], ARGV[1])
+	if redis.call('PTTL', KEYS[1]) == -1 then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	return current
+`)
+
 // IncrementCounter atomically increments a counter using Redis INCR with EXPIRE.
 func (c *RedisCache) IncrementCounter(ctx context.Context, tenantID string, key string, window time.Duration) (int64, error) {
+	return c.IncrementCounterBy(ctx, tenantID, key, 1, window)
+}
+
+// IncrementCounterBy atomically adds delta to a counter using Redis INCRBY
+// with EXPIRE.
+func (c *RedisCache) IncrementCounterBy(ctx context.Context, tenantID string, key string, delta int64, window time.Duration) (int64, error) {
 	if tenantID == "" {
 		return 0, fmt.Errorf("tenantID is required")
 	}
@@ -108,15 +125,7 @@ func (c *RedisCache) IncrementCounter(ctx context.Context, tenantID string, key
 	fullKey := c.makeKey(tenantID, "counter:"+key)
 
 	// Use Lua script for atomic increment with TTL
-	script := redis.NewScript(`
-		local current = redis.call('INCR', KEYS[1])
-		if current == 1 then
-			redis.call('PEXPIRE', KEYS[1], ARGV[1])
-		end
-		return current
-	`)
-
-	result, err := script.Run(ctx, c.client, []string{fullKey}, window.Milliseconds()).Int64()
+	result, err := incrementScript.Run(ctx, c.client, []string{fullKey}, delta, window.Milliseconds()).Int64()
 	if err != nil {
 		return 0, err
 	}
@@ -124,6 +133,37 @@ func (c *RedisCache) IncrementCounter(ctx context.Context, tenantID string, key
 	return result, nil
 }
 
+// GetCounters reads counters with a single MGET.
+func (c *RedisCache) GetCounters(ctx context.Context, tenantID string, keys []string) ([]int64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	if len(keys) == 0 {
+		return []int64{}, nil
+	}
+
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = c.makeKey(tenantID, "counter:"+key)
+	}
+	results, err := c.client.MGet(ctx, fullKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]int64, len(keys))
+	for i, result := range results {
+		s, ok := result.(string)
+		if !ok {
+			continue
+		}
+		if values[i], err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("counter %s is not an integer: %w", keys[i], err)
+		}
+	}
+	return values, nil
+}
+
 // Ping checks Redis connectivity.
 func (c *RedisCache) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
@@ -0,0 +1,152 @@
+// Package cluster keeps the engines of Osprey replicas in step.
+//
+// Each replica loads rules, named lists and typologies into memory, so a
+// change saved through one replica reaches the others only when they reload.
+// Whenever a replica reloads its own engines after a change it publishes a
+// notice on a control topic of the event bus; every replica subscribes to it
+// and reloads its engines from the database shortly after a notice from
+// another one. A change saved without a reload, which waits for POST
+// /rules/reload on its replica too, is announced by that reload, so replicas
+// keep evaluating the same configuration. Notices that arrive
+// together are coalesced into one reload, so applying a state of many rules
+// reloads each replica once.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Control topic and the tenant it is published under; bus subscriptions are
+// per tenant, and the notices concern every tenant.
+const (
+	TopicReload   = "osprey.control.reload"
+	ControlTenant = "_control"
+)
+
+// Notice is the payload of TopicReload.
+type Notice struct {
+	Node     string    `json:"node"`               // the replica that made the change
+	TenantID string    `json:"tenantId,omitempty"` // whose configuration changed, if one tenant's
+	At       time.Time `json:"at"`
+}
+
+// Coordinator publishes this replica's configuration changes and reloads
+// its engines on the changes of other replicas. A nil Coordinator does
+// nothing.
+type Coordinator struct {
+	bus   domain.EventBus
+	node  string
+	delay time.Duration
+
+	mu      sync.Mutex
+	reload  func(context.Context) error
+	ctx     context.Context
+	sub     domain.Subscription
+	pending *time.Timer
+}
+
+// NewCoordinator creates a coordinator publishing on bus. Reloads run delay
+// after the first notice of a burst. It does nothing until Start.
+func NewCoordinator(bus domain.EventBus, delay time.Duration) *Coordinator {
+	return &Coordinator{bus: bus, node: uuid.New().String(), delay: delay}
+}
+
+// Node returns the ID this replica's notices carry.
+func (c *Coordinator) Node() string {
+	return c.node
+}
+
+// Start subscribes to the control topic, so other replicas' changes run
+// reload until ctx is cancelled or Stop is called.
+func (c *Coordinator) Start(ctx context.Context, reload func(context.Context) error) error {
+	c.mu.Lock()
+	c.reload = reload
+	c.ctx = ctx
+	c.mu.Unlock()
+
+	sub, err := c.bus.Subscribe(ctx, ControlTenant, TopicReload, c.handle)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.sub = sub
+	c.mu.Unlock()
+	return nil
+}
+
+// Stop unsubscribes and cancels a pending reload.
+func (c *Coordinator) Stop() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending != nil {
+		c.pending.Stop()
+		c.pending = nil
+	}
+	if c.sub != nil {
+		c.sub.Unsubscribe()
+		c.sub = nil
+	}
+}
+
+// Notify tells the other replicas that the configuration of tenantID, or of
+// every tenant when empty, changed. The change is already saved, so a
+// failure is logged rather than returned.
+func (c *Coordinator) Notify(ctx context.Context, tenantID string) {
+	if c == nil {
+		return
+	}
+	payload, _ := json.Marshal(Notice{Node: c.node, TenantID: tenantID, At: time.Now().UTC()})
+	if err := c.bus.Publish(ctx, ControlTenant, TopicReload, payload); err != nil {
+		slog.Error("failed to publish reload notice", "tenant_id", tenantID, "error", err)
+	}
+}
+
+// handle schedules a reload for another replica's notice, unless one is
+// pending already.
+func (c *Coordinator) handle(_ context.Context, msg *domain.Message) error {
+	var notice Notice
+	if err := json.Unmarshal(msg.Payload, &notice); err != nil {
+		slog.Warn("ignoring malformed reload notice", "error", err)
+		return nil
+	}
+	if notice.Node == c.node {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reload == nil || c.pending != nil {
+		return nil
+	}
+	c.pending = time.AfterFunc(c.delay, c.run)
+	return nil
+}
+
+// run reloads the engines for the pending notices. Notices arriving during
+// the reload schedule another one, so the last change is always loaded.
+func (c *Coordinator) run() {
+	c.mu.Lock()
+	c.pending = nil
+	reload, ctx := c.reload, c.ctx
+	c.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err := reload(ctx); err != nil {
+		slog.Error("cluster reload failed", "error", err)
+		return
+	}
+	slog.Info("engines reloaded after a change on another replica")
+}
@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestCoordinator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := ospreytest.NewBus(nil)
+
+	// Two replicas sharing the database and the bus
+	var reloadsA, reloadsB atomic.Int32
+	reloaded := make(chan struct{}, 10)
+	a := NewCoordinator(bus, 20*time.Millisecond)
+	b := NewCoordinator(bus, 20*time.Millisecond)
+	if err := a.Start(ctx, func(context.Context) error { reloadsA.Add(1); return nil }); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := b.Start(ctx, func(context.Context) error { reloadsB.Add(1); reloaded <- struct{}{}; return nil }); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer a.Stop()
+	defer b.Stop()
+	// A burst of notices from a reloads b once, and not a itself
+	for range 3 {
+		a.Notify(ctx, "tenant-001")
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected b to reload")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if reloadsA.Load() != 0 || reloadsB.Load() != 1 {
+		t.Errorf("expected only b to reload, once, got a %d and b %d", reloadsA.Load(), reloadsB.Load())
+	}
+	if n := len(bus.Published(ControlTenant, TopicReload)); n != 3 {
+		t.Errorf("expected 3 notices, got %d", n)
+	}
+
+	// An explicit reload on b reaches a
+	b.Notify(ctx, "")
+	deadline := time.Now().Add(2 * time.Second)
+	for reloadsA.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if reloadsA.Load() != 1 {
+		t.Errorf("expected a to reload after b's notice, got %d", reloadsA.Load())
+	}
+
+	// A nil coordinator does nothing
+	var none *Coordinator
+	none.Notify(ctx, "tenant-001")
+	none.Stop()
+}
@@ -0,0 +1,260 @@
+// Package corridor scores origin→destination country corridors and exposes
+// the result to rules as corridor_risk.
+package corridor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// DefaultCacheTTL is how long a tenant's corridor overrides are cached.
+const DefaultCacheTTL = 5 * time.Minute
+
+// cacheKey holds the tenant's full override table; it is small and read on every evaluation.
+const cacheKey = "corridors"
+
+// Default risk scores applied when no tenant override matches.
+const (
+	BlackListRisk = 1.0
+	GreyListRisk  = 0.5
+)
+
+// Sources describing where an effective corridor score came from.
+const (
+	SourceOverride  = "override"
+	SourceBlackList = "fatf_blacklist"
+	SourceGreyList  = "fatf_greylist"
+	SourceDefault   = "default"
+)
+
+// BlackList is the FATF "High-Risk Jurisdictions subject to a Call for Action" list.
+// Snapshot of the June 2025 plenary; override per tenant via /refdata/corridors.
+var BlackList = map[string]bool{
+	"KP": true, // Democratic People's Republic of Korea
+	"IR": true, // Iran
+	"MM": true, // Myanmar
+}
+
+// GreyList is the FATF "Jurisdictions under Increased Monitoring" list.
+// Snapshot of the June 2025 plenary; override per tenant via /refdata/corridors.
+var GreyList = map[string]bool{
+	"DZ": true, // Algeria
+	"AO": true, // Angola
+	"BO": true, // Bolivia
+	"BG": true, // Bulgaria
+	"BF": true, // Burkina Faso
+	"CM": true, // Cameroon
+	"CI": true, // Côte d'Ivoire
+	"HR": true, // Croatia
+	"CD": true, // Democratic Republic of the Congo
+	"HT": true, // Haiti
+	"KE": true, // Kenya
+	"LA": true, // Lao PDR
+	"LB": true, // Lebanon
+	"MC": true, // Monaco
+	"MZ": true, // Mozambique
+	"NA": true, // Namibia
+	"NP": true, // Nepal
+	"NG": true, // Nigeria
+	"ZA": true, // South Africa
+	"SS": true, // South Sudan
+	"SY": true, // Syria
+	"VE": true, // Venezuela
+	"VN": true, // Vietnam
+	"VG": true, // British Virgin Islands
+	"YE": true, // Yemen
+}
+
+// Score is the effective risk for a corridor and where it came from.
+type Score struct {
+	Origin      string  `json:"origin"`
+	Destination string  `json:"destination"`
+	Risk        float64 `json:"risk"`
+	Source      string  `json:"source"`
+}
+
+// Service resolves corridor risk from tenant overrides and FATF defaults.
+type Service struct {
+	repo     domain.Repository
+	cache    domain.Cache
+	cacheTTL time.Duration
+}
+
+// NewService creates a new corridor risk service.
+func NewService(repo domain.Repository, cache domain.Cache) *Service {
+	return &Service{
+		repo:     repo,
+		cache:    cache,
+		cacheTTL: DefaultCacheTTL,
+	}
+}
+
+// NormalizeCountry upper-cases and trims a country code.
+func NormalizeCountry(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidCountry reports whether code is a 2-letter country code or the wildcard.
+func ValidCountry(code string) bool {
+	if code == domain.CorridorWildcard {
+		return true
+	}
+	if len(code) != 2 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Overrides returns the tenant's corridor overrides through the cache.
+func (s *Service) Overrides(ctx context.Context, tenantID string) ([]*domain.CorridorRisk, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, tenantID, cacheKey)
+		if err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		} else if data != nil {
+			var corridors []*domain.CorridorRisk
+			if err := json.Unmarshal(data, &corridors); err == nil {
+				return corridors, nil
+			}
+		}
+	}
+
+	if s.repo == nil {
+		return nil, nil
+	}
+
+	corridors, err := s.repo.ListCorridorRisks(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list corridor risks: %w", err)
+	}
+
+	if s.cache != nil {
+		if data, err := json.Marshal(corridors); err == nil {
+			_ = s.cache.Set(ctx, tenantID, cacheKey, data, s.cacheTTL)
+		}
+	}
+
+	return corridors, nil
+}
+
+// Invalidate drops the cached override table after it has been changed.
+func (s *Service) Invalidate(ctx context.Context, tenantID string) error {
+	if s.cache == nil {
+		return nil
+	}
+	return s.cache.Delete(ctx, tenantID, cacheKey)
+}
+
+// Lookup returns the effective risk for an origin→destination corridor.
+// Precedence: exact override, origin→*, *→destination, FATF black list,
+// FATF grey list, then 0.
+func (s *Service) Lookup(ctx context.Context, tenantID, origin, destination string) (*Score, error) {
+	origin = NormalizeCountry(origin)
+	destination = NormalizeCountry(destination)
+
+	overrides, err := s.Overrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Resolve(overrides, origin, destination), nil
+}
+
+// Resolve applies the lookup precedence to a set of overrides.
+func Resolve(overrides []*domain.CorridorRisk, origin, destination string) *Score {
+	score := &Score{Origin: origin, Destination: destination}
+
+	candidates := [][2]string{
+		{origin, destination},
+		{origin, domain.CorridorWildcard},
+		{domain.CorridorWildcard, destination},
+	}
+	for _, key := range candidates {
+		for _, o := range overrides {
+			if o.Origin == key[0] && o.Destination == key[1] {
+				score.Risk = o.Risk
+				score.Source = SourceOverride
+				return score
+			}
+		}
+	}
+
+	switch {
+	case BlackList[origin] || BlackList[destination]:
+		score.Risk = BlackListRisk
+		score.Source = SourceBlackList
+	case GreyList[origin] || GreyList[destination]:
+		score.Risk = GreyListRisk
+		score.Source = SourceGreyList
+	default:
+		score.Source = SourceDefault
+	}
+
+	return score
+}
+
+// CountryRisk returns the FATF default risk of a single country, as rules see
+// it through country_risk: 1.0 on the black list, 0.5 on the grey list, else
+// 0. Tenant overrides apply to corridors and are not consulted.
+func CountryRisk(code string) float64 {
+	code = NormalizeCountry(code)
+	switch {
+	case BlackList[code]:
+		return BlackListRisk
+	case GreyList[code]:
+		return GreyListRisk
+	}
+	return 0
+}
+
+// Enricher returns a rules.Enricher exposing corridor_risk to CEL.
+// corridor_risk is 0.0 unless both debtor and creditor countries are present.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "corridor"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"corridor_risk": cel.DoubleType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["corridor_risk"] = 0.0
+
+	if input.DebtorCountry == "" || input.CreditorCountry == "" {
+		return nil
+	}
+
+	score, err := e.svc.Lookup(ctx, input.TenantID, input.DebtorCountry, input.CreditorCountry)
+	if err != nil {
+		return err
+	}
+	activation["corridor_risk"] = score.Risk
+
+	return nil
+}
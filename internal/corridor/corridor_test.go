@@ -0,0 +1,136 @@
+package corridor
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+func TestResolve(t *testing.T) {
+	overrides := []*domain.CorridorRisk{
+		{Origin: "GB", Destination: "AE", Risk: 0.7},
+		{Origin: "GB", Destination: domain.CorridorWildcard, Risk: 0.2},
+		{Origin: domain.CorridorWildcard, Destination: "IR", Risk: 0.9},
+	}
+
+	tests := []struct {
+		name        string
+		origin      string
+		destination string
+		risk        float64
+		source      string
+	}{
+		{"ExactOverride", "GB", "AE", 0.7, SourceOverride},
+		{"OriginWildcard", "GB", "US", 0.2, SourceOverride},
+		{"OriginWildcardBeatsDestination", "GB", "IR", 0.2, SourceOverride},
+		{"DestinationWildcard", "US", "IR", 0.9, SourceOverride},
+		{"BlackList", "KP", "US", BlackListRisk, SourceBlackList},
+		{"GreyList", "US", "YE", GreyListRisk, SourceGreyList},
+		{"Default", "US", "FR", 0, SourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := Resolve(overrides, tt.origin, tt.destination)
+			if score.Risk != tt.risk || score.Source != tt.source {
+				t.Errorf("expected %.2f (%s), got %.2f (%s)", tt.risk, tt.source, score.Risk, score.Source)
+			}
+		})
+	}
+}
+
+func TestCorridorService(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "corridor-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	repo, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	lruCache := cache.NewLRUCache(100)
+	defer lruCache.Close()
+
+	svc := NewService(repo, lruCache)
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	t.Run("InvalidateAfterUpdate", func(t *testing.T) {
+		score, err := svc.Lookup(ctx, tenantID, "gb", "ae")
+		if err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+		if score.Source != SourceDefault {
+			t.Fatalf("expected default score, got %+v", score)
+		}
+
+		repo.SaveCorridorRisk(ctx, tenantID, &domain.CorridorRisk{Origin: "GB", Destination: "AE", Risk: 0.6})
+		if err := svc.Invalidate(ctx, tenantID); err != nil {
+			t.Fatalf("Invalidate failed: %v", err)
+		}
+
+		score, _ = svc.Lookup(ctx, tenantID, "GB", "AE")
+		if score.Risk != 0.6 {
+			t.Errorf("expected override risk 0.6 after invalidate, got %.2f", score.Risk)
+		}
+	})
+
+	t.Run("EnricherExposesCorridorRisk", func(t *testing.T) {
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "high-risk-corridor",
+			Expression: `corridor_risk >= 0.5`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		input := &rules.EvaluateInput{
+			TenantID:        tenantID,
+			TxID:            "tx-corridor-001",
+			Type:            "transfer",
+			DebtorID:        "debtor-001",
+			CreditorID:      "creditor-001",
+			DebtorCountry:   "GB",
+			CreditorCountry: "AE",
+			Amount:          domain.MustDecimal("500.0"),
+			Currency:        "USD",
+		}
+		results, _ := engine.EvaluateAll(ctx, input)
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match high-risk corridor, got score %.2f", results[0].Score)
+		}
+
+		// Without both countries the corridor is unknown and scores 0
+		input.CreditorCountry = ""
+		results, _ = engine.EvaluateAll(ctx, input)
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 0.0 {
+			t.Errorf("expected no match without creditor country, got score %.2f", results[0].Score)
+		}
+	})
+}
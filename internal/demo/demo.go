@@ -0,0 +1,271 @@
+// Package demo runs Osprey as a self-contained demo: it seeds demo rules
+// and typologies and feeds the API a stream of synthetic transactions with
+// injected fraud patterns, printing an alert feed as they are caught.
+package demo
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/api"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/state"
+)
+
+// TenantID is the tenant demo traffic is evaluated for.
+const TenantID = "demo"
+
+// DefaultRate is the number of normal transactions sent per second.
+const DefaultRate = 5
+
+// VelocityWindow is the velocity window of demo transactions in seconds,
+// short enough that normal customers stay under the velocity rule.
+const VelocityWindow = 60
+
+// Injected fraud patterns.
+const (
+	PatternVelocity     = "velocity_burst" // a debtor pays many mules in quick succession
+	PatternStructuring  = "structuring"    // a run of amounts just below the reporting threshold
+	PatternAccountDrain = "account_drain"  // a transfer empties the debtor's account
+	PatternHighValue    = "high_value"     // a very large transfer
+)
+
+// Patterns lists the injected fraud patterns.
+var Patterns = []string{PatternVelocity, PatternStructuring, PatternAccountDrain, PatternHighValue}
+
+//go:embed spec.json
+var specJSON []byte
+
+// Spec returns the demo rules and typologies.
+func Spec() (*state.Spec, error) {
+	var spec state.Spec
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse demo spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// Seed replaces the stored rules and typologies with the demo ones and
+// loads them into the engines.
+func Seed(ctx context.Context, m *state.Manager) error {
+	spec, err := Spec()
+	if err != nil {
+		return err
+	}
+	plan, err := m.Plan(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("failed to plan demo state: %w", err)
+	}
+	return m.Apply(ctx, plan)
+}
+
+// Config configures the traffic generator.
+type Config struct {
+	URL       string  // base URL of the Osprey API
+	Rate      int     // normal transactions per second; 0 means DefaultRate
+	FraudRate float64 // probability that a tick also injects a fraud pattern; 0 means 0.05
+	Seed      int64   // random seed; 0 seeds from the clock
+}
+
+// Stats counts the generator's traffic.
+type Stats struct {
+	Sent     int
+	Alerts   int
+	Failed   int
+	Injected map[string]int
+}
+
+// Generator sends synthetic transactions to the API and writes an alert
+// feed. It is not safe for concurrent use.
+type Generator struct {
+	cfg    Config
+	client *http.Client
+	rand   *rand.Rand
+	out    io.Writer
+	stats  Stats
+}
+
+// NewGenerator creates a generator writing its alert feed to out.
+func NewGenerator(cfg Config, out io.Writer) *Generator {
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultRate
+	}
+	if cfg.FraudRate <= 0 {
+		cfg.FraudRate = 0.05
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	return &Generator{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 5 * time.Second},
+		rand:   rand.New(rand.NewSource(cfg.Seed)),
+		out:    out,
+		stats:  Stats{Injected: make(map[string]int)},
+	}
+}
+
+// Stats returns the traffic sent so far.
+func (g *Generator) Stats() Stats {
+	return g.stats
+}
+
+// Run sends transactions until ctx is done, printing every alert and a
+// summary every 10 seconds.
+func (g *Generator) Run(ctx context.Context) error {
+	if err := g.waitReady(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(g.out, "  Demo traffic: %d transactions/s for tenant %q, about %.0f%% with injected fraud\n\n", g.cfg.Rate, TenantID, g.cfg.FraudRate*100)
+
+	ticker := time.NewTicker(time.Second / time.Duration(g.cfg.Rate))
+	defer ticker.Stop()
+	summary := time.NewTicker(10 * time.Second)
+	defer summary.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-summary.C:
+			fmt.Fprintf(g.out, "  -- %d evaluated, %d alerts, %d failed\n", g.stats.Sent, g.stats.Alerts, g.stats.Failed)
+		case <-ticker.C:
+			g.Tick(ctx)
+		}
+	}
+}
+
+// Tick sends one normal transaction and, at the fraud rate, one injected
+// fraud pattern.
+func (g *Generator) Tick(ctx context.Context) {
+	g.send(ctx, "", g.normal())
+	if g.rand.Float64() < g.cfg.FraudRate {
+		pattern := Patterns[g.rand.Intn(len(Patterns))]
+		g.Inject(ctx, pattern)
+	}
+}
+
+// Inject sends the transactions of a fraud pattern.
+func (g *Generator) Inject(ctx context.Context, pattern string) {
+	g.stats.Injected[pattern]++
+	debtor := fmt.Sprintf("cust-%04d", g.rand.Intn(10000))
+
+	switch pattern {
+	case PatternVelocity:
+		for i := 0; i < 8; i++ {
+			tx := g.transaction("transfer", debtor, fmt.Sprintf("mule-%03d", g.rand.Intn(100)), g.amount(200, 1500))
+			g.send(ctx, pattern, tx)
+		}
+	case PatternStructuring:
+		creditor := fmt.Sprintf("acct-%04d", g.rand.Intn(1000))
+		for i := 0; i < 6; i++ {
+			g.send(ctx, pattern, g.transaction("transfer", debtor, creditor, g.amount(9000, 9999)))
+		}
+	case PatternAccountDrain:
+		balance := g.amount(2000, 20000)
+		tx := g.transaction("transfer", debtor, fmt.Sprintf("mule-%03d", g.rand.Intn(100)), balance)
+		tx.Metadata = map[string]interface{}{"old_balance": balance, "new_balance": 0.0}
+		g.send(ctx, pattern, tx)
+	case PatternHighValue:
+		g.send(ctx, pattern, g.transaction("transfer", debtor, fmt.Sprintf("acct-%04d", g.rand.Intn(1000)), g.amount(50001, 250000)))
+	}
+}
+
+// normal returns a transaction of an ordinary customer.
+func (g *Generator) normal() *api.TransactionRequest {
+	debtor := fmt.Sprintf("cust-%04d", g.rand.Intn(10000))
+	if g.rand.Intn(4) == 0 {
+		return g.transaction("transfer", debtor, fmt.Sprintf("cust-%04d", g.rand.Intn(10000)), g.amount(10, 3000))
+	}
+	// Card payments cluster around small amounts
+	amount := math.Round(math.Exp(2+g.rand.NormFloat64())*100) / 100
+	return g.transaction("payment", debtor, fmt.Sprintf("shop-%03d", g.rand.Intn(500)), max(amount, 1))
+}
+
+func (g *Generator) transaction(txType, debtor, creditor string, amount float64) *api.TransactionRequest {
+	return &api.TransactionRequest{
+		Type:           txType,
+		Debtor:         api.PartyInfo{ID: debtor, AccountID: "acc-" + debtor},
+		Creditor:       api.PartyInfo{ID: creditor, AccountID: "acc-" + creditor},
+		Amount:         api.AmountInfo{Value: domain.DecimalFromFloat(amount), Currency: "USD"},
+		VelocityWindow: VelocityWindow,
+	}
+}
+
+// amount returns a random amount in [lo, hi) with cents.
+func (g *Generator) amount(lo, hi float64) float64 {
+	return math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+}
+
+// send evaluates a transaction and prints it if it alerted. pattern is the
+// injected pattern it belongs to, or empty for normal traffic.
+func (g *Generator) send(ctx context.Context, pattern string, tx *api.TransactionRequest) {
+	body, _ := json.Marshal(tx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.cfg.URL+"/evaluate", bytes.NewReader(body))
+	if err != nil {
+		g.stats.Failed++
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", TenantID)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		g.stats.Failed++
+		return
+	}
+	defer resp.Body.Close()
+
+	var eval api.EvaluateResponse
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&eval) != nil {
+		g.stats.Failed++
+		return
+	}
+	g.stats.Sent++
+	if eval.Status != "ALRT" {
+		return
+	}
+	g.stats.Alerts++
+
+	if pattern == "" {
+		pattern = "-"
+	}
+	fmt.Fprintf(g.out, "  %s  ALERT  %-14s  %s -> %s  %10.2f %s  score %.2f  %s\n",
+		time.Now().Format("15:04:05"), pattern, tx.Debtor.ID, tx.Creditor.ID,
+		tx.Amount.Value.Float64(), tx.Amount.Currency, eval.Score, strings.Join(eval.Reasons, "; "))
+}
+
+// waitReady waits up to 10 seconds for the API to answer /ready.
+func (g *Generator) waitReady(ctx context.Context) error {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.cfg.URL+"/ready", nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := g.client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("osprey API at %s is not ready", g.cfg.URL)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
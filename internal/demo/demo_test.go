@@ -0,0 +1,66 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/api"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/velocity"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestDemo(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(velocity.NewService(repo, nil).GetVelocityGetter(), 5)
+	defer engine.Close()
+	typologies := rules.NewTypologyEngine()
+
+	if err := Seed(ctx, state.NewManager(repo, engine, typologies)); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if engine.RulesCount() != 5 || typologies.TypologyCount() != 2 {
+		t.Fatalf("expected the demo rules and typologies to be loaded, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+	}
+
+	processor := tadp.NewProcessor()
+	processor.AlertThreshold = 0.7
+	server := httptest.NewServer(api.NewServer(domain.ServerConfig{}, repo, nil, nil, engine, typologies, processor, "test-v1", domain.ModeDetection).Router())
+	defer server.Close()
+
+	var feed bytes.Buffer
+	g := NewGenerator(Config{URL: server.URL, Seed: 1}, &feed)
+
+	t.Run("NormalTraffic", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			g.send(ctx, "", g.normal())
+		}
+		if stats := g.Stats(); stats.Sent != 50 || stats.Failed != 0 || stats.Alerts > 2 {
+			t.Errorf("expected normal traffic to pass, got %+v", stats)
+		}
+	})
+
+	t.Run("InjectedPatterns", func(t *testing.T) {
+		before := g.Stats().Alerts
+		for _, pattern := range Patterns {
+			g.Inject(ctx, pattern)
+		}
+		stats := g.Stats()
+		// The velocity burst alerts from its sixth transaction on
+		if alerts := stats.Alerts - before; alerts < 5 {
+			t.Errorf("expected the injected patterns to alert, got %d alerts", alerts)
+		}
+		for _, pattern := range []string{PatternVelocity, PatternAccountDrain, PatternHighValue} {
+			if !strings.Contains(feed.String(), pattern) {
+				t.Errorf("expected the alert feed to show %s, got:\n%s", pattern, feed.String())
+			}
+		}
+	})
+}
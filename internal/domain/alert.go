@@ -0,0 +1,139 @@
+package domain
+
+import "time"
+
+// Alert tracks acknowledgment, escalation and the analyst's disposition of an
+// ALRT evaluation. Its ID is the evaluation ID.
+type Alert struct {
+	ID              string     `json:"id"`
+	TenantID        string     `json:"tenantId"`
+	TxID            string     `json:"txId"`
+	Score           float64    `json:"score"`
+	Status          string     `json:"status"`
+	Assignee        string     `json:"assignee,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	AckedAt         *time.Time `json:"ackedAt,omitempty"`
+	AckedBy         string     `json:"ackedBy,omitempty"`
+	AckNote         string     `json:"ackNote,omitempty"`
+	EscalationLevel int        `json:"escalationLevel"` // Escalations sent so far; 0 = only the original notification
+	LastNotifiedAt  time.Time  `json:"lastNotifiedAt"`
+
+	// SuppressedByMaintenance is the ID of the maintenance window the alert
+	// was raised in. Suppressed alerts are never escalated.
+	SuppressedByMaintenance string `json:"suppressedByMaintenance,omitempty"`
+
+	// History is the alert's audit trail, oldest first. Only set on single-alert reads.
+	History []*AlertEvent `json:"history,omitempty"`
+}
+
+// Acked reports whether the alert has been acknowledged.
+func (a *Alert) Acked() bool {
+	return a.AckedAt != nil
+}
+
+// Alert statuses. New alerts are open; a closed alert can only be reopened.
+const (
+	AlertOpen                = "open"
+	AlertInvestigating       = "investigating"
+	AlertClosedFalsePositive = "closed-false-positive"
+	AlertClosedConfirmed     = "closed-confirmed"
+)
+
+// AlertStatuses lists the valid alert statuses.
+var AlertStatuses = []string{AlertOpen, AlertInvestigating, AlertClosedFalsePositive, AlertClosedConfirmed}
+
+// ValidAlertStatus reports whether s is a known alert status.
+func ValidAlertStatus(s string) bool {
+	for _, status := range AlertStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertClosed reports whether status is a closing disposition.
+func AlertClosed(status string) bool {
+	return status == AlertClosedFalsePositive || status == AlertClosedConfirmed
+}
+
+// AlertTransitionAllowed reports whether an alert may move from one status to
+// another: open and investigating alerts may move anywhere, closed alerts
+// only back to open.
+func AlertTransitionAllowed(from, to string) bool {
+	if from == to || !ValidAlertStatus(to) {
+		return false
+	}
+	return !AlertClosed(from) || to == AlertOpen
+}
+
+// Alert event actions recorded in an alert's history.
+const (
+	AlertEventAck      = "ack"
+	AlertEventEscalate = "escalate"
+	AlertEventStatus   = "status"
+	AlertEventAssign   = "assign"
+	AlertEventNote     = "note"
+
+	// AlertEventSuppressed records that a maintenance window, named by To,
+	// suppressed the alert's notifications
+	AlertEventSuppressed = "suppressed"
+)
+
+// AlertEvent is one entry in an alert's audit history. From and To carry the
+// old and new value of a status or assignee change.
+type AlertEvent struct {
+	ID      string    `json:"id"`
+	AlertID string    `json:"alertId"`
+	Action  string    `json:"action"`
+	Actor   string    `json:"actor,omitempty"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// AlertFilter narrows an alert listing.
+type AlertFilter struct {
+	Unacked  bool     // Only alerts not yet acknowledged
+	Statuses []string // Only alerts in one of these statuses
+	Assignee string   // Only alerts assigned to this analyst
+	TxID     string   // Only the alert for this transaction
+	MinScore float64  // Only alerts scoring at least this
+	Limit    int      // Max alerts returned, newest first; 0 = repository default
+
+	// Only alerts whose evaluation this rule fired in, and where this
+	// typology triggered or, with MinContribution, scored above it
+	RuleID          string
+	TypologyID      string
+	MinContribution *float64
+}
+
+// AlertConfig is the escalation policy for unacknowledged alerts.
+type AlertConfig struct {
+	// AckWindow is how long an alert may stay unacknowledged before each
+	// escalation. Zero disables escalation.
+	AckWindow time.Duration `json:"ackWindow"`
+
+	// MaxEscalations caps escalations per alert. Every escalation but the last
+	// re-notifies; the last escalates.
+	MaxEscalations int `json:"maxEscalations"`
+
+	// CheckInterval is how often unacknowledged alerts are checked.
+	CheckInterval time.Duration `json:"checkInterval"`
+}
+
+// Escalation actions carried by AlertEscalation events.
+const (
+	EscalationRenotify = "renotify"
+	EscalationEscalate = "escalate"
+)
+
+// AlertEscalation is published on TopicAlertEscalated when an alert misses
+// its acknowledgment window.
+type AlertEscalation struct {
+	Alert  *Alert `json:"alert"`
+	Level  int    `json:"level"`
+	Action string `json:"action"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit log actions.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditReload = "reload" // rules or typologies reloaded into the engine
+	AuditApply  = "apply"  // a PUT /state or rule pack plan applied
+)
+
+// Audited resources.
+const (
+	AuditRule              = "rule"
+	AuditRules             = "rules" // every tenant's rules, on reload
+	AuditTypology          = "typology"
+	AuditTypologies        = "typologies"
+	AuditState             = "state"
+	AuditRulePack          = "rule_pack"
+	AuditScoringConfig     = "scoring_config"
+	AuditRetentionPolicy   = "retention_policy"
+	AuditFeatureFlag       = "feature_flag"
+	AuditWebhook           = "webhook"
+	AuditNamedList         = "named_list"
+	AuditCorridor          = "corridor"
+	AuditWatchlistEntry    = "watchlist_entry"
+	AuditMaintenanceWindow = "maintenance_window"
+)
+
+// AuditEntry is one configuration change in a tenant's append-only,
+// hash-chained audit log. Before and After hold the resource as JSON on
+// either side of the change, and are empty where it didn't exist.
+type AuditEntry struct {
+	TenantID   string          `json:"tenantId"`
+	Seq        int64           `json:"seq"` // 1-based position in the tenant's chain
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resourceId,omitempty"`
+	Actor      string          `json:"actor,omitempty"` // Principal that made the change
+	ClientIP   string          `json:"clientIp,omitempty"`
+	RequestID  string          `json:"requestId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// AuditFilter narrows an audit log listing.
+type AuditFilter struct {
+	Resource   string // Only changes to this kind of resource
+	ResourceID string // Only changes to this resource
+	Action     string // Only this action
+	AfterSeq   int64  // Only entries after this sequence number, in chain order
+	Limit      int    // Max entries returned; 0 = repository default
+}
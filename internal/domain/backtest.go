@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// BacktestReport is the replay of a candidate rule over a tenant's stored
+// transactions.
+type BacktestReport struct {
+	RuleID string    `json:"ruleId"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"` // exclusive; the last replayed transaction when truncated
+
+	Transactions int `json:"transactions"` // replayed
+	Matched      int `json:"matched"`      // scored above 0
+
+	// Outcomes counts the replayed results by outcome, e.g. ".fail".
+	Outcomes map[string]int `json:"outcomes"`
+
+	// Alerts are the results that failed; each would have raised an alert
+	// on its own.
+	Alerts       int     `json:"alerts"`
+	AlertRate    float64 `json:"alertRate"`
+	AlertsPerDay float64 `json:"alertsPerDay"`
+
+	Scores    []ScoreBucket `json:"scores"`
+	Truncated bool          `json:"truncated,omitempty"` // more transactions than a backtest replays
+}
+
+// ScoreBucket counts the scores in [Lower, Upper). The first bucket also
+// counts scores below 0 and the last one scores of 1 and above.
+type ScoreBucket struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int     `json:"count"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// AccountBalance is an account's balance as Osprey knows it. It is set by the
+// balance feed or by a transaction that reports the account's balance, then
+// kept up to date by the account's stored transactions.
+type AccountBalance struct {
+	TenantID  string  `json:"tenantId"`
+	AccountID string  `json:"accountId"`
+	Balance   Decimal `json:"balance"`
+	Currency  string  `json:"currency,omitempty"`
+
+	// Prior is Balance before LastTxID debited it, so a transaction stored
+	// before it is evaluated sees the balance preceding it.
+	Prior    Decimal `json:"prior"`
+	LastTxID string  `json:"lastTxId,omitempty"`
+
+	// LastCreditTxID is the last transaction that credited the account, so
+	// a transaction saved again is not credited twice.
+	LastCreditTxID string `json:"lastCreditTxId,omitempty"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// EntityBaseline is an entity's usual behaviour as a debtor, kept up to date
+// as its payments are stored: rolling statistics of its amounts, the hours of
+// the day it pays at and the parties it pays.
+type EntityBaseline struct {
+	TenantID string        `json:"tenantId"`
+	EntityID string        `json:"entityId"`
+	Stats    BaselineStats `json:"stats"`
+
+	// Prior is Stats before LastTxID was added, so a transaction stored
+	// before it is evaluated is compared with the payments preceding it.
+	Prior    BaselineStats `json:"prior"`
+	LastTxID string        `json:"lastTxId,omitempty"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BaselineStats are exponentially weighted: each payment counts for
+// 1/Count of the statistics until Count reaches the baseline's span, then
+// for 1/span, so older behaviour fades out.
+type BaselineStats struct {
+	// Count is the number of payments added.
+	Count int64 `json:"count"`
+
+	// MeanAmount and AmountVariance describe the payment amounts.
+	MeanAmount     float64 `json:"meanAmount"`
+	AmountVariance float64 `json:"amountVariance"`
+
+	// Hours is the weighted share of payments made at each UTC hour; the
+	// shares sum to 1.
+	Hours [24]float64 `json:"hours"`
+
+	// Counterparties is the weighted share of payments to each creditor.
+	// Rarely paid creditors are dropped.
+	Counterparties map[string]float64 `json:"counterparties,omitempty"`
+}
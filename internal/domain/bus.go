@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 // EventBus defines the interface for event-driven communication.
@@ -28,6 +30,26 @@ type EventBus interface {
 // MessageHandler processes incoming messages.
 type MessageHandler func(ctx context.Context, msg *Message) error
 
+// ErrAckDeferred is returned by a MessageHandler that keeps handling a
+// message after it returns and reports the result through DeferredAck.
+var ErrAckDeferred = errors.New("message acknowledgement deferred")
+
+type deferredAckKey struct{}
+
+// WithDeferredAck lets handlers run under ctx defer a message's
+// acknowledgement: a handler returning ErrAckDeferred must later call settle
+// once with the result it would have returned. Buses that acknowledge
+// messages, such as JetStream, set it.
+func WithDeferredAck(ctx context.Context, settle func(error)) context.Context {
+	return context.WithValue(ctx, deferredAckKey{}, settle)
+}
+
+// DeferredAck returns the settle function set by WithDeferredAck, if any.
+func DeferredAck(ctx context.Context) (func(error), bool) {
+	settle, ok := ctx.Value(deferredAckKey{}).(func(error))
+	return settle, ok
+}
+
 // Message represents an event message.
 type Message struct {
 	ID        string            `json:"id"`
@@ -47,19 +69,34 @@ type Subscription interface {
 	Topic() string
 }
 
+// PendingCounter is implemented by subscriptions that can report how many
+// messages have been delivered to them but not yet handled.
+type PendingCounter interface {
+	Pending() int
+}
+
 // EventBusConfig holds configuration for event bus initialization.
 type EventBusConfig struct {
 	// Type is the bus type: "channel" or "nats"
 	Type string
 
 	// Channel settings (Community tier)
-	ChannelBufferSize int
+	ChannelBufferSize  int
+	ChannelSynchronous bool // Deliver in the publisher's goroutine; no drops, no background goroutines
 
 	// NATS settings (Pro tier)
 	NATSUrl           string
 	NATSToken         string
 	NATSMaxReconnects int
 	NATSReconnectWait int // seconds
+
+	// JetStream settings (Pro tier): transaction ingest goes through a
+	// durable stream with at-least-once delivery instead of core NATS
+	NATSJetStream  bool
+	NATSStream     string        // Stream name; default "OSPREY"
+	NATSMaxDeliver int           // Deliveries before a message is dead-lettered; default 5
+	NATSAckWait    time.Duration // How long a delivery may go unacknowledged before redelivery; default 30s
+	NATSMaxAge     time.Duration // How long the stream keeps messages; default 72h
 }
 
 // Standard topic names for the evaluation pipeline.
@@ -71,4 +108,19 @@ const (
 	TopicTypologyResult      = "osprey.typology.result"
 	TopicDecision            = "osprey.decision"
 	TopicAlert               = "osprey.alert"
+	TopicAlertEscalated      = "osprey.alert.escalated"
+	TopicDeadLetter          = "dlq" // Messages the worker gave up on
 )
+
+// Priority lanes of the async pipeline. Each lane has its own ingest topic
+// and worker capacity, so batch traffic can't delay real-time evaluations.
+const (
+	LaneRealtime = "realtime"
+	LaneBatch    = "batch"
+)
+
+// LaneTopic returns the ingest topic of a priority lane. Producers may
+// publish to it directly to skip classification.
+func LaneTopic(lane string) string {
+	return TopicTransactionIngested + "." + lane
+}
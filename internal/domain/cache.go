@@ -29,6 +29,13 @@ type Cache interface {
 	// Used for velocity checks (e.g., transaction count in time window).
 	IncrementCounter(ctx context.Context, tenantID string, key string, window time.Duration) (int64, error)
 
+	// IncrementCounterBy atomically adds delta to a counter and returns the
+	// new value. A new counter expires after window.
+	IncrementCounterBy(ctx context.Context, tenantID string, key string, delta int64, window time.Duration) (int64, error)
+
+	// GetCounters returns the values of counters, 0 for missing or expired ones.
+	GetCounters(ctx context.Context, tenantID string, keys []string) ([]int64, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 
@@ -63,4 +70,7 @@ type CacheConfig struct {
 
 	// Two-phase settings
 	EnableTwoPhase bool // If true, check local first, then Redis
+
+	// EvaluationTTL is how long an evaluation read by ID stays cached
+	EvaluationTTL time.Duration
 }
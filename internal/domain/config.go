@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"strings"
+	"time"
+)
+
 // Config holds the complete Osprey configuration.
 type Config struct {
 	// Server settings
@@ -21,6 +26,86 @@ type Config struct {
 	// Observability
 	Logging LoggingConfig `json:"logging"`
 	Tracing TracingConfig `json:"tracing"`
+
+	// Alerts sets the escalation policy for unacknowledged alerts
+	Alerts AlertConfig `json:"alerts"`
+
+	// Review sets the deadlines and claim lease of the review queue
+	Review ReviewConfig `json:"review"`
+
+	// Scoring sets the default decision policy; tenants override it via /config/scoring
+	Scoring ScoringConfig `json:"scoring"`
+
+	// Velocity sets the lookback window for velocity counts
+	Velocity VelocityConfig `json:"velocity"`
+
+	// Graph bounds the counterparty network searches behind graph signals
+	Graph GraphConfig `json:"graph"`
+
+	// ML calls an external model whose score is blended with the rules'
+	ML MLConfig `json:"ml"`
+
+	// Baseline sets how each debtor's behavioral baseline is kept
+	Baseline BaselineConfig `json:"baseline"`
+
+	// FX converts amounts to each tenant's base currency for rules
+	FX FXConfig `json:"fx"`
+
+	// Sandbox names the tenants whose data expires, for prospects to test against
+	Sandbox SandboxConfig `json:"sandbox"`
+
+	// Retention sets how long tenants keep their activity and where expired
+	// rows are archived
+	Retention RetentionConfig `json:"retention"`
+
+	// TxTypes restricts the transaction types each tenant may evaluate
+	TxTypes TxTypeConfig `json:"txTypes"`
+
+	// Guardrails caps how much one API call may change a tenant's rules and
+	// typologies without force=true
+	Guardrails GuardrailConfig `json:"guardrails"`
+
+	// Webhooks sets the delivery and retry policy for alert webhooks
+	Webhooks WebhookConfig `json:"webhooks"`
+
+	// Signing signs evaluation responses and webhook bodies with a JWS key
+	Signing SigningConfig `json:"signing"`
+
+	// Queue sets when async evaluation counts as falling behind
+	Queue QueueConfig `json:"queue"`
+
+	// SLO sets the availability and latency objectives of the API endpoints
+	SLO SLOConfig `json:"slo"`
+
+	// Stats sets the window of the heaviest tenants and entities
+	Stats StatsConfig `json:"stats"`
+
+	// Plugins configures enrichment plugins loaded at startup
+	Plugins PluginConfig `json:"plugins"`
+
+	// GitSync makes a Git repository the source of truth for rules and typologies
+	GitSync GitSyncConfig `json:"gitSync"`
+
+	// RuleDir makes a local directory the source of truth for rules and typologies
+	RuleDir RuleDirConfig `json:"ruleDir"`
+
+	// Cluster propagates configuration changes between replicas
+	Cluster ClusterConfig `json:"cluster"`
+
+	// Breaker guards the evaluation path's repository writes
+	Breaker BreakerConfig `json:"breaker"`
+
+	// Migration names the repository tenants are migrated to, e.g. on an
+	// upgrade from Community to Pro
+	Migration MigrationConfig `json:"migration"`
+
+	// Features sets install-wide feature flag defaults by name.
+	// Values stored via the /features API take precedence.
+	Features map[string]bool `json:"features"`
+
+	// Banner prints a plain-text startup summary to stdout.
+	// Automation should use GET /info instead.
+	Banner bool `json:"banner"`
 }
 
 // EvaluationMode determines the transaction evaluation strategy.
@@ -38,18 +123,437 @@ const (
 	ModeCompliance EvaluationMode = "compliance"
 )
 
+// DefaultVelocityWindow is the velocity lookback when none is configured.
+const DefaultVelocityWindow = time.Hour
+
+// MaxVelocityWindow bounds velocity windows from configuration and requests.
+const MaxVelocityWindow = 90 * 24 * time.Hour
+
+// DefaultVelocityReconcile is how often cached velocity aggregates are
+// recomputed from the database when none is configured.
+const DefaultVelocityReconcile = time.Minute
+
+// VelocityConfig sets the lookback window for velocity counts. A window sent
+// with a transaction takes precedence over both settings.
+type VelocityConfig struct {
+	// DefaultWindow applies to tenants without their own window.
+	DefaultWindow time.Duration `json:"defaultWindow"`
+
+	// TenantWindows overrides DefaultWindow per tenant.
+	TenantWindows map[string]time.Duration `json:"tenantWindows"`
+
+	// Reconcile is how long cached amount aggregates and velocity counters
+	// are updated in place before they are recomputed from the database.
+	Reconcile time.Duration `json:"reconcile"`
+
+	// WriteThrough counts each saved transaction in cache counters per
+	// entity and serves velocity_count from them instead of the database.
+	WriteThrough bool `json:"writeThrough"`
+}
+
+// WindowSeconds returns the velocity window for a tenant, in seconds.
+func (c VelocityConfig) WindowSeconds(tenantID string) int {
+	window := c.DefaultWindow
+	if w, ok := c.TenantWindows[tenantID]; ok && w > 0 {
+		window = w
+	}
+	if window <= 0 {
+		window = DefaultVelocityWindow
+	}
+	return int(window / time.Second)
+}
+
+// GraphConfig bounds the counterparty network searches made for each
+// evaluation.
+type GraphConfig struct {
+	// Window skips edges last seen longer ago.
+	Window time.Duration `json:"window"`
+
+	// MaxHops is the longest path from the creditor back to the debtor
+	// that counts as funds returning to origin.
+	MaxHops int `json:"maxHops"`
+}
+
+// ML model protocols.
+const (
+	MLProtocolHTTP = "http" // POST the request as JSON to MLConfig.URL
+	MLProtocolGRPC = "grpc" // Call MLConfig.Method at MLConfig.URL with google.protobuf.Struct messages
+)
+
+// MLConfig sets the external model detection mode decisions may consult.
+type MLConfig struct {
+	// URL is the model's HTTP endpoint, or its gRPC target as host:port.
+	// Empty disables the hook.
+	URL string `json:"url"`
+
+	// Protocol is MLProtocolHTTP or MLProtocolGRPC.
+	Protocol string `json:"protocol"`
+
+	// Method is the full gRPC method called.
+	Method string `json:"method"`
+
+	// Timeout bounds one call to the model.
+	Timeout time.Duration `json:"timeout"`
+
+	// Fallback is what a decision does when the model fails:
+	// MLFallbackRules or MLFallbackAlert.
+	Fallback string `json:"fallback"`
+}
+
+// BaselineConfig sets how each debtor's behavioral baseline is kept and when
+// it is trusted.
+type BaselineConfig struct {
+	// Span is the number of recent payments the statistics mostly reflect;
+	// each new payment counts for 1/Span of them.
+	Span int `json:"span"`
+
+	// MinSamples is the number of payments a baseline needs before the
+	// anomaly variables compare against it.
+	MinSamples int `json:"minSamples"`
+}
+
+// FX rate sources.
+const (
+	FXSourceStatic = "static" // FXConfig.Rates
+	FXSourceECB    = "ecb"    // The European Central Bank's daily reference rates
+	FXSourceAPI    = "api"    // A JSON endpoint at FXConfig.URL
+)
+
+// FXConfig sets where exchange rates come from and each tenant's base
+// currency. Rules see amounts converted to it as amount_base.
+type FXConfig struct {
+	// Source of the rates: FXSourceStatic, FXSourceECB or FXSourceAPI.
+	// Empty disables conversion.
+	Source string `json:"source"`
+
+	// URL replaces the ECB feed's address and is required by the API source.
+	URL string `json:"url,omitempty"`
+
+	// Rates are the static rates, in units of each currency per one
+	// BaseCurrency.
+	Rates map[string]float64 `json:"rates,omitempty"`
+
+	// Refresh is how often the ECB and API sources are fetched again.
+	Refresh time.Duration `json:"refresh"`
+
+	// BaseCurrency applies to tenants without their own.
+	BaseCurrency string `json:"baseCurrency"`
+
+	// TenantBaseCurrencies overrides BaseCurrency per tenant.
+	TenantBaseCurrencies map[string]string `json:"tenantBaseCurrencies,omitempty"`
+}
+
+// Base returns the base currency of a tenant.
+func (c FXConfig) Base(tenantID string) string {
+	if base, ok := c.TenantBaseCurrencies[tenantID]; ok && base != "" {
+		return base
+	}
+	return c.BaseCurrency
+}
+
+// SandboxConfig names the sandbox tenants. Their transactions, evaluations
+// and other activity are deleted once older than TTL, and they are left out
+// of the operator health summary, so prospects can integrate against a
+// hosted instance without polluting production data.
+type SandboxConfig struct {
+	// Tenants lists the sandbox tenant IDs. An entry ending in "*" matches
+	// every tenant ID with that prefix.
+	Tenants []string `json:"tenants"`
+
+	// TTL is how long a sandbox tenant's activity is kept.
+	TTL time.Duration `json:"ttl"`
+
+	// PurgeInterval is how often expired sandbox data is deleted.
+	PurgeInterval time.Duration `json:"purgeInterval"`
+}
+
+// RetentionConfig sets the retention policy of tenants without their own
+// and how expired activity is handled. Sandbox tenants are expired by their
+// TTL as well.
+type RetentionConfig struct {
+	// Defaults is the policy of tenants without their own. Zero days keep
+	// a class for ever.
+	Defaults RetentionPolicy `json:"defaults"`
+
+	// Interval is how often expired activity is deleted.
+	Interval time.Duration `json:"interval"`
+
+	// ArchiveDir is where policies with Archive write expired rows, as
+	// gzipped JSON lines. Without it, such policies expire nothing.
+	ArchiveDir string `json:"archiveDir"`
+
+	// Compact gives the space of deleted rows back to the file system after
+	// a run, for SQLite. It locks the database while it runs.
+	Compact bool `json:"compact"`
+}
+
+// IsSandbox reports whether tenantID is a sandbox tenant.
+func (c SandboxConfig) IsSandbox(tenantID string) bool {
+	for _, pattern := range c.Tenants {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(tenantID, prefix) {
+				return true
+			}
+		} else if tenantID == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// SLOObjective is the availability and latency objective of one endpoint.
+type SLOObjective struct {
+	// Route is the method and route pattern, e.g. "POST /evaluate" or
+	// "GET /evaluations/{id}".
+	Route string `json:"route"`
+
+	// Availability is the fraction of requests, e.g. 0.999, that must not
+	// fail with a 5xx status.
+	Availability float64 `json:"availability"`
+
+	// LatencyTarget is the fraction of requests, e.g. 0.99, that must
+	// complete within Latency. Zero sets no latency objective.
+	LatencyTarget float64       `json:"latencyTarget,omitempty"`
+	Latency       time.Duration `json:"latency,omitempty"`
+}
+
+// SLOConfig sets the service level objectives error budgets are tracked
+// against.
+type SLOConfig struct {
+	Objectives []SLOObjective `json:"objectives"`
+
+	// Window is the period each error budget covers.
+	Window time.Duration `json:"window"`
+}
+
+// StatsConfig sets how /stats/top tracks the heaviest tenants and entities,
+// and how counts shared across tenants are protected.
+type StatsConfig struct {
+	// TopWindow is how long counts are kept per window; /stats/top covers
+	// the window in progress and the one before it.
+	TopWindow time.Duration `json:"topWindow"`
+
+	// Epsilon adds Laplace noise of scale 1/Epsilon to counts shared across
+	// tenants. Zero adds none.
+	Epsilon float64 `json:"epsilon"`
+
+	// MinCohort withholds shared counts above 0 but below it. Zero withholds
+	// none.
+	MinCohort int `json:"minCohort"`
+}
+
+// Actions for transaction types missing from a tenant's allowed list.
+const (
+	// TxTypeReject refuses the transaction before it reaches the rules.
+	TxTypeReject = "reject"
+
+	// TxTypeFlag evaluates the transaction and marks the evaluation.
+	TxTypeFlag = "flag"
+)
+
+// TxTypeConfig restricts the transaction types tenants may evaluate, so
+// misspelled or garbage types don't flow into rules unnoticed.
+type TxTypeConfig struct {
+	// Allowed lists the transaction types per tenant. The "*" entry applies
+	// to tenants without their own list; no entry allows every type.
+	Allowed map[string][]string `json:"allowed"`
+
+	// Unknown is what happens to a type not on the list: "reject" or "flag".
+	Unknown string `json:"unknown"`
+}
+
+// GuardrailConfig limits drastic changes to production detection, so a
+// fat-fingered or compromised call can't switch most of it off at once.
+type GuardrailConfig struct {
+	// Caps per tenant. The "*" entry applies to tenants without their own;
+	// no entry leaves changes uncapped.
+	Caps map[string]MutationCaps `json:"caps"`
+}
+
+// MutationCaps bounds a single rule or typology change. Zero disables a cap.
+type MutationCaps struct {
+	// MaxDisabledFraction is the largest fraction of the tenant's enabled
+	// rules one call may disable or delete, e.g. 0.25
+	MaxDisabledFraction float64 `json:"maxDisabledFraction"`
+
+	// MaxThresholdChange is the most one call may move a typology's alert
+	// threshold, up or down
+	MaxThresholdChange float64 `json:"maxThresholdChange"`
+}
+
+// QueueConfig holds the thresholds at which async evaluation is reported as
+// lagging in /health.
+type QueueConfig struct {
+	MaxLag     time.Duration `json:"maxLag"`     // Age of the last processed message
+	MaxBacklog int           `json:"maxBacklog"` // Messages waiting per tenant
+	Lanes      LaneConfig    `json:"lanes"`
+
+	// Retries of a failed async evaluation before it is dead-lettered, and
+	// the delay before the first retry, doubled after each
+	Retries      int           `json:"retries"`
+	RetryBackoff time.Duration `json:"retryBackoff"`
+}
+
+// LaneConfig routes async transactions to priority lanes. Transactions go to
+// the realtime lane unless they ask for the batch lane or use a batch rail;
+// high-value amounts stay realtime unless explicitly marked batch.
+// Empty Capacity disables lanes.
+type LaneConfig struct {
+	Capacity        map[string]int `json:"capacity"`        // Concurrent evaluations per lane
+	HighValueAmount float64        `json:"highValueAmount"` // Amounts at or above this are realtime
+	BatchTypes      []string       `json:"batchTypes"`      // Transaction types of batch rails
+}
+
+// PluginConfig holds enrichment plugin settings.
+type PluginConfig struct {
+	// Dir is scanned for *.so enrichment plugins. Empty disables plugins.
+	Dir string `json:"dir"`
+
+	// Timeout bounds each plugin call per evaluation.
+	Timeout time.Duration `json:"timeout"`
+}
+
+// MigrationConfig holds tenant migration settings.
+type MigrationConfig struct {
+	// Target is the repository tenants are copied to. An empty driver
+	// disables migration.
+	Target RepositoryConfig `json:"-"`
+}
+
+// GitSyncConfig holds Git sync settings.
+type GitSyncConfig struct {
+	// Repo is the repository URL. Empty disables Git sync.
+	Repo string `json:"repo"`
+
+	// Branch to sync; empty uses the remote's default branch.
+	Branch string `json:"branch"`
+
+	// Path is the directory in the repository holding rules/ and typologies/.
+	Path string `json:"path"`
+
+	// Dir is the local checkout; empty uses a directory under the system temp dir.
+	Dir string `json:"dir"`
+
+	// Interval between polls. Zero disables polling; webhooks still trigger syncs.
+	Interval time.Duration `json:"interval"`
+
+	// WebhookSecret verifies push webhooks. Empty rejects all webhooks.
+	WebhookSecret string `json:"-"`
+}
+
+// RuleDirConfig holds rule directory watcher settings.
+type RuleDirConfig struct {
+	// Dir holds rules/ and typologies/. Empty disables the watcher.
+	Dir string `json:"dir"`
+
+	// Interval between checks of the directory for changes.
+	Interval time.Duration `json:"interval"`
+}
+
+// ClusterConfig holds settings for running several replicas.
+type ClusterConfig struct {
+	// ReloadDelay is how long a replica waits after another replica's change
+	// before reloading, so a burst of changes reloads it once.
+	ReloadDelay time.Duration `json:"reloadDelay"`
+}
+
+// BreakerConfig holds the repository circuit breaker settings.
+type BreakerConfig struct {
+	// Policy for writes while the breaker is open: queue appends them to the
+	// write-ahead log for replay, fail-fast rejects them. Empty disables the
+	// breaker.
+	Policy string `json:"policy"`
+
+	// Failures is how many consecutive failed writes open the breaker.
+	Failures int `json:"failures"`
+
+	// Cooldown is how long the breaker stays open before pinging the
+	// database, and how often it pings after.
+	Cooldown time.Duration `json:"cooldown"`
+
+	// Timeout bounds each guarded write; a write that takes longer fails.
+	Timeout time.Duration `json:"timeout"`
+
+	// WALPath is the write-ahead log of the queue policy.
+	WALPath string `json:"walPath"`
+
+	// MaxQueued caps the writes in the log; past it writes are rejected.
+	MaxQueued int `json:"maxQueued"`
+}
+
 // ServerConfig holds HTTP server settings.
 type ServerConfig struct {
 	Host         string `json:"host"`
 	Port         int    `json:"port"`
 	ReadTimeout  int    `json:"readTimeout"`  // seconds
 	WriteTimeout int    `json:"writeTimeout"` // seconds
+
+	// GRPCPort serves the bidirectional evaluation stream over gRPC. Zero
+	// disables it.
+	GRPCPort int `json:"grpcPort"`
+
+	// AdminNetworks limits rule, typology and other management mutations to
+	// these CIDRs (IPv4 or IPv6). Empty allows any network.
+	AdminNetworks []string `json:"adminNetworks"`
+
+	// TrustProxyHeaders checks the forwarded client IP instead of the TCP peer
+	// against AdminNetworks. Enable only behind a proxy that sets those headers.
+	TrustProxyHeaders bool `json:"trustProxyHeaders"`
+
+	// GlobalTenantWrites lets any network write for the global "*" tenant,
+	// whose rules and typologies apply to every tenant. When false, only
+	// AdminNetworks may, and only once they are set.
+	GlobalTenantWrites bool `json:"globalTenantWrites"`
+
+	// CORS controls which browser origins may call the API.
+	CORS CORSConfig `json:"cors"`
+
+	// ReadyFile is written once the server is listening and removed when it
+	// drains or shuts down, for file-based readiness probes. Empty disables it.
+	ReadyFile string `json:"readyFile"`
+
+	// DrainDelay is how long `osprey drain` waits after failing readiness, so
+	// load balancers stop routing before the shutdown signal arrives.
+	DrainDelay time.Duration `json:"drainDelay"`
+
+	// MaxClockSkew is how far past the server's clock a client-provided
+	// transaction timestamp may be. Zero allows any.
+	MaxClockSkew time.Duration `json:"maxClockSkew"`
+
+	// MaxTransactionAge is how far before the server's clock a
+	// client-provided transaction timestamp may be. Zero allows any, for
+	// historical replays.
+	MaxTransactionAge time.Duration `json:"maxTransactionAge"`
+}
+
+// SigningConfig enables detached JWS signatures on evaluation responses and
+// webhook deliveries.
+type SigningConfig struct {
+	// KeyFile is a PEM encoded PKCS #8 Ed25519 or ECDSA P-256 private key.
+	// Empty disables signing.
+	KeyFile string `json:"keyFile"`
+}
+
+// CORSConfig lists the browser origins allowed to call the API. The default
+// allows none; "*" allows any origin but never with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string            `json:"allowedOrigins"`   // Allowed for every tenant
+	TenantOrigins    map[string][]string `json:"tenantOrigins"`    // Additional origins per tenant
+	AllowCredentials bool                `json:"allowCredentials"` // Only sent for explicitly listed origins
 }
 
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
-	Level  string `json:"level"`  // debug, info, warn, error
-	Format string `json:"format"` // json, text
+	Level     string          `json:"level"`  // debug, info, warn, error
+	Format    string          `json:"format"` // json, text
+	Redaction RedactionConfig `json:"redaction"`
+}
+
+// RedactionConfig controls how sensitive log attributes are rewritten.
+type RedactionConfig struct {
+	Mode   string            `json:"mode"`   // off, standard, strict
+	Fields map[string]string `json:"fields"` // Per-field overrides: keep, hash, truncate, drop
+	Salt   string            `json:"-"`      // Key for hashed values; set per deployment
 }
 
 // TracingConfig holds OpenTelemetry settings.
@@ -83,6 +587,8 @@ func DefaultConfig() *Config {
 			Port:         8080,
 			ReadTimeout:  30,
 			WriteTimeout: 30,
+			DrainDelay:   5 * time.Second,
+			MaxClockSkew: 5 * time.Minute,
 		},
 		Tier:           TierCommunity,
 		EvaluationMode: ModeDetection, // Default: fast fraud detection
@@ -91,9 +597,10 @@ func DefaultConfig() *Config {
 			SQLitePath: "./osprey.db",
 		},
 		Cache: CacheConfig{
-			Type:         "memory",
-			LocalMaxSize: 10000,
-			LocalTTL:     300, // 5 minutes
+			Type:          "memory",
+			LocalMaxSize:  10000,
+			LocalTTL:      300, // 5 minutes
+			EvaluationTTL: 5 * time.Minute,
 		},
 		EventBus: EventBusConfig{
 			Type:              "channel",
@@ -102,11 +609,104 @@ func DefaultConfig() *Config {
 		Logging: LoggingConfig{
 			Level:  "info",
 			Format: "json",
+			Redaction: RedactionConfig{
+				Mode: "off",
+			},
 		},
 		Tracing: TracingConfig{
 			Enabled:     false,
 			ServiceName: "osprey",
 		},
+		Alerts: AlertConfig{
+			MaxEscalations: 3,
+			CheckInterval:  time.Minute,
+		},
+		Review: ReviewConfig{
+			HoldSLA:    time.Hour,
+			AlertSLA:   4 * time.Hour,
+			CaseSLA:    24 * time.Hour,
+			HoldWindow: 24 * time.Hour,
+			ClaimTTL:   30 * time.Minute,
+		},
+		Scoring: ScoringConfig{
+			AlertThreshold:  0.7,
+			WeightedScoring: true,
+			CriticalFail:    CriticalFailAlert,
+		},
+		Velocity: VelocityConfig{
+			DefaultWindow: DefaultVelocityWindow,
+		},
+		Graph: GraphConfig{
+			Window:  30 * 24 * time.Hour,
+			MaxHops: 3,
+		},
+		ML: MLConfig{
+			Protocol: MLProtocolHTTP,
+			Method:   "/osprey.ml.v1.Scorer/Score",
+			Timeout:  200 * time.Millisecond,
+			Fallback: MLFallbackRules,
+		},
+		Baseline: BaselineConfig{
+			Span:       100,
+			MinSamples: 10,
+		},
+		FX: FXConfig{
+			Refresh:      time.Hour,
+			BaseCurrency: "USD",
+		},
+		Sandbox: SandboxConfig{
+			TTL:           24 * time.Hour,
+			PurgeInterval: 10 * time.Minute,
+		},
+		Retention: RetentionConfig{
+			Interval: 24 * time.Hour,
+		},
+		TxTypes: TxTypeConfig{
+			Unknown: TxTypeReject,
+		},
+		SLO: SLOConfig{
+			Objectives: []SLOObjective{
+				{Route: "POST /evaluate", Availability: 0.999, LatencyTarget: 0.99, Latency: 250 * time.Millisecond},
+				{Route: "GET /evaluations/{id}", Availability: 0.999, LatencyTarget: 0.99, Latency: 100 * time.Millisecond},
+			},
+			Window: 30 * 24 * time.Hour,
+		},
+		Stats: StatsConfig{
+			TopWindow: 5 * time.Minute,
+		},
+		Webhooks: WebhookConfig{
+			MaxAttempts:    8,
+			InitialBackoff: 10 * time.Second,
+			MaxBackoff:     time.Hour,
+			Timeout:        10 * time.Second,
+			CheckInterval:  5 * time.Second,
+		},
+		Queue: QueueConfig{
+			MaxLag:       30 * time.Second,
+			MaxBacklog:   1000,
+			Retries:      3,
+			RetryBackoff: time.Second,
+		},
+		Plugins: PluginConfig{
+			Timeout: 50 * time.Millisecond,
+		},
+		GitSync: GitSyncConfig{
+			Interval: time.Minute,
+		},
+		RuleDir: RuleDirConfig{
+			Interval: 2 * time.Second,
+		},
+		Cluster: ClusterConfig{
+			ReloadDelay: 500 * time.Millisecond,
+		},
+		Breaker: BreakerConfig{
+			Failures:  5,
+			Cooldown:  10 * time.Second,
+			Timeout:   2 * time.Second,
+			WALPath:   "./osprey-wal.jsonl",
+			MaxQueued: 100000,
+		},
+		Banner: true,
 	}
 }
 
@@ -129,6 +729,7 @@ func ProConfig() *Config {
 		RedisAddr:      "localhost:6379",
 		EnableTwoPhase: true,
 		LocalMaxSize:   1000,
+		EvaluationTTL:  5 * time.Minute,
 	}
 	cfg.EventBus = EventBusConfig{
 		Type:              "nats",
@@ -136,6 +737,7 @@ func ProConfig() *Config {
 		NATSMaxReconnects: 10,
 		NATSReconnectWait: 5,
 	}
+	cfg.Velocity.WriteThrough = true
 	cfg.Tracing.Enabled = true
 	return cfg
 }
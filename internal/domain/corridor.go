@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CorridorWildcard matches any country on one side of a corridor.
+const CorridorWildcard = "*"
+
+// CorridorRisk is a tenant-configured risk score for payments from an origin
+// country to a destination country. Either side may be CorridorWildcard.
+type CorridorRisk struct {
+	TenantID    string    `json:"tenantId"`
+	Origin      string    `json:"origin"`      // ISO 3166-1 alpha-2 or "*"
+	Destination string    `json:"destination"` // ISO 3166-1 alpha-2 or "*"
+	Risk        float64   `json:"risk"`        // 0.0 (none) to 1.0 (prohibited)
+	Note        string    `json:"note,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// DeadLetter is an async message the worker gave up on: one it couldn't
+// parse, or one whose evaluation kept failing after its retries. It keeps
+// the original payload so the message can be inspected and replayed.
+type DeadLetter struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenantId"`
+	Topic      string     `json:"topic"`     // topic the message was consumed from
+	MessageID  string     `json:"messageId"` // ID of the failed message
+	Payload    string     `json:"payload"`
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	Poison     bool       `json:"poison,omitempty"` // failed permanently, without retries
+	FailedAt   time.Time  `json:"failedAt"`
+	ReplayedAt *time.Time `json:"replayedAt,omitempty"`
+}
+
+// DeadLetterFilter narrows a dead-letter listing; zero fields match everything.
+type DeadLetterFilter struct {
+	Pending bool // Only dead letters that haven't been replayed
+	Limit   int  // Max dead letters returned, latest first; 0 = repository default
+}
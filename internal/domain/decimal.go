@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is the most fractional digits a Decimal keeps.
+const MaxDecimalScale = 18
+
+// Decimal is an exact decimal number, Units × 10^-Scale, for amounts that
+// must not pick up binary floating-point error: 10000.005 stays 10000.005
+// instead of 10000.004999999999.
+//
+// It is written to JSON as a number and read from a number or a string, so
+// clients sending "amount": 10000.005 or "amount": "10000.005" both work.
+type Decimal struct {
+	Units int64
+	Scale int32
+}
+
+// ParseDecimal parses a decimal such as "10000.005", "-3" or "1.5e3".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	digits := whole + frac
+	sign := int64(1)
+	switch {
+	case strings.HasPrefix(digits, "-"):
+		sign, digits = -1, digits[1:]
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	}
+	if digits == "" || strings.ContainsAny(frac, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale := int64(len(frac)) - exponent
+	// Drop trailing zeros the scale doesn't need, then leading zeros
+	for scale > 0 && strings.HasSuffix(digits, "0") {
+		digits, scale = digits[:len(digits)-1], scale-1
+	}
+	if digits = strings.TrimLeft(digits, "0"); digits == "" {
+		return Decimal{}, nil
+	}
+	if scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+	for ; scale < 0; scale++ {
+		digits += "0"
+	}
+	if scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q has more than %d fractional digits", s, MaxDecimalScale)
+	}
+
+	units, err := strconv.ParseUint(digits, 10, 63)
+	if errors.Is(err, strconv.ErrRange) {
+		return Decimal{}, fmt.Errorf("decimal %q is out of range", s)
+	}
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{Units: sign * int64(units), Scale: int32(scale)}, nil
+}
+
+// MustDecimal parses s, panicking when it is invalid. For constants and tests.
+func MustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromFloat returns the shortest decimal that reads back as f:
+// 0.1 becomes 0.1, not 0.1000000000000000055511151231257827. Digits beyond
+// MaxDecimalScale are rounded off.
+func DecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}
+	}
+	if d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64)); err == nil {
+		return d
+	}
+	for prec := MaxDecimalScale; prec >= 0; prec-- {
+		if d, err := ParseDecimal(strconv.FormatFloat(f, 'f', prec, 64)); err == nil {
+			return d
+		}
+	}
+	return Decimal{}
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool {
+	return d.Units == 0
+}
+
+// Sign returns -1, 0 or 1.
+func (d Decimal) Sign() int {
+	switch {
+	case d.Units < 0:
+		return -1
+	case d.Units > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp compares d and o, returning -1, 0 or 1. Values too large to align
+// exactly are compared as floats.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, ok := align(d, o)
+	if !ok {
+		af, bf := d.Float64(), o.Float64()
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Add returns d + o. Sums too large to add exactly are added as floats.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, ok := align(d, o)
+	if !ok || (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return DecimalFromFloat(d.Float64() + o.Float64())
+	}
+	return Decimal{Units: a + b, Scale: max(d.Scale, o.Scale)}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	if o.Units == math.MinInt64 {
+		return DecimalFromFloat(d.Float64() - o.Float64())
+	}
+	return d.Add(Decimal{Units: -o.Units, Scale: o.Scale})
+}
+
+// align returns the units of d and o at the larger of their scales.
+func align(d, o Decimal) (int64, int64, bool) {
+	a, b := d.Units, o.Units
+	for s := d.Scale; s < o.Scale; s++ {
+		if a > math.MaxInt64/10 || a < math.MinInt64/10 {
+			return 0, 0, false
+		}
+		a *= 10
+	}
+	for s := o.Scale; s < d.Scale; s++ {
+		if b > math.MaxInt64/10 || b < math.MinInt64/10 {
+			return 0, 0, false
+		}
+		b *= 10
+	}
+	return a, b, true
+}
+
+// Round returns d rounded half away from zero to scale fractional digits.
+// Scales above d's own leave it unchanged.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.Scale {
+		return d
+	}
+	div := pow10(d.Scale - scale)
+	if div == 0 {
+		// More digits dropped than an int64 holds: well under half a unit
+		return Decimal{Scale: scale}
+	}
+	q, r := d.Units/div, absInt64(d.Units%div)
+	if r >= uint64(div)-r {
+		if d.Units < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Decimal{Units: q, Scale: scale}
+}
+
+// MinorUnits returns d in the minor units of a currency, e.g. cents for
+// USD and yen for JPY, rounded half away from zero.
+func (d Decimal) MinorUnits(currency string) int64 {
+	exp := CurrencyExponent(currency)
+	r := d.Round(exp)
+	units := r.Units
+	for s := r.Scale; s < exp; s++ {
+		units *= 10
+	}
+	return units
+}
+
+// pow10 returns 10^n, or 0 when it overflows int64.
+func pow10(n int32) int64 {
+	p := int64(1)
+	for i := int32(0); i < n; i++ {
+		if p > math.MaxInt64/10 {
+			return 0
+		}
+		p *= 10
+	}
+	return p
+}
+
+// String formats d without an exponent, e.g. "10000.005".
+func (d Decimal) String() string {
+	digits := strconv.FormatUint(uint64(absInt64(d.Units)), 10)
+	sign := ""
+	if d.Units < 0 {
+		sign = "-"
+	}
+	if d.Scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-d.Scale))
+	}
+	if pad := int(d.Scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.Scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+	return uint64(n)
+}
+
+// MarshalJSON writes d as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads d from a JSON number or string without going through
+// float64. null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	parsed, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit isn't a
+// hundredth.
+var currencyExponents = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of fractional digits of a currency's
+// minor unit: 2 for USD, 0 for JPY, 3 for KWD.
+func CurrencyExponent(currency string) int32 {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
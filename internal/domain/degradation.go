@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// Degradation statuses.
+const (
+	DegradationFailed  = "failed"  // The dependency returned an error; defaults were used
+	DegradationSkipped = "skipped" // The dependency wasn't consulted; defaults were used
+)
+
+// Degraded components reported by the evaluation pipeline. Enrichers report
+// as "enricher:<name>".
+const (
+	DegradedCache    = "cache"
+	DegradedVelocity = "velocity"
+	DegradedScoring  = "scoring"
+	DegradedML       = "ml"
+)
+
+// Degradation is an optional dependency that failed or was skipped during an
+// evaluation, so the decision was made on partial information.
+type Degradation struct {
+	Component string `json:"component"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Degradations collects the degradations of one evaluation. It is safe for
+// concurrent use.
+type Degradations struct {
+	mu   sync.Mutex
+	list []Degradation
+}
+
+type degradationsKey struct{}
+
+// WithDegradations returns a copy of ctx that collects degradations reported
+// during an evaluation.
+func WithDegradations(ctx context.Context) (context.Context, *Degradations) {
+	d := &Degradations{}
+	return context.WithValue(ctx, degradationsKey{}, d), d
+}
+
+// ReportDegradation records a degradation on the collector carried by ctx.
+// A component is reported once per status; without a collector it is a no-op.
+func ReportDegradation(ctx context.Context, component, status, reason string) {
+	d, _ := ctx.Value(degradationsKey{}).(*Degradations)
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, existing := range d.list {
+		if existing.Component == component && existing.Status == status {
+			return
+		}
+	}
+	d.list = append(d.list, Degradation{Component: component, Status: status, Reason: reason})
+}
+
+// List returns the degradations reported so far, or nil if there were none.
+func (d *Degradations) List() []Degradation {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.list) == 0 {
+		return nil
+	}
+	return append([]Degradation(nil), d.list...)
+}
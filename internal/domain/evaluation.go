@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
 	"time"
 )
 
@@ -33,6 +36,18 @@ type TypologyResult struct {
 	Rules        []RuleResult       `json:"rules"`
 	Contributions []RuleContribution `json:"contributions,omitempty"`
 	ProcessMs    int64              `json:"processMs,omitempty"`
+
+	// Contributions of the typologies a composite typology is made of
+	TypologyContributions []TypologyContribution `json:"typologyContributions,omitempty"`
+
+	// Rule coverage, checked against the typology's MinRulesFired and MinCoverage
+	RulesEvaluated int     `json:"rulesEvaluated"`
+	RulesFired     int     `json:"rulesFired"`
+	Coverage       float64 `json:"coverage"`
+
+	// SuppressedReason explains why a score at or above the threshold did not
+	// trigger, including a condition that didn't hold
+	SuppressedReason string `json:"suppressedReason,omitempty"`
 }
 
 // EvaluationMetadata contains processing information.
@@ -45,6 +60,30 @@ type EvaluationMetadata struct {
 	RulesEvaluated      int    `json:"rulesEvaluated"`
 	TypologiesEvaluated int    `json:"typologiesEvaluated"`
 	EngineVersion       string `json:"engineVersion"`
+
+	// Request details, when the evaluation came from an API call
+	RequestID string `json:"requestId,omitempty"`
+	Principal string `json:"principal,omitempty"`
+	ClientIP  string `json:"clientIp,omitempty"`
+
+	// Degradations lists optional dependencies that failed or were skipped,
+	// so the decision was made on partial information
+	Degradations []Degradation `json:"degradations,omitempty"`
+
+	// Velocity lists the velocity values the rules saw, as they were at
+	// decision time
+	Velocity []VelocitySnapshot `json:"velocity,omitempty"`
+
+	// UnknownTxType marks a transaction type missing from the tenant's
+	// allowed list, evaluated because the policy flags instead of rejecting
+	UnknownTxType bool `json:"unknownTxType,omitempty"`
+
+	// SuppressedByMaintenance is the ID of the maintenance window the
+	// evaluation fell in; its alert notified nobody
+	SuppressedByMaintenance string `json:"suppressedByMaintenance,omitempty"`
+
+	// ML records the ML model's part in the decision, when one was called
+	ML *MLDecision `json:"ml,omitempty"`
 }
 
 // EvaluationResponse is the API response for a transaction evaluation.
@@ -55,6 +94,7 @@ type EvaluationResponse struct {
 	Status       string             `json:"status"` // "PASS" or "ALERT"
 	Score        float64            `json:"score"`
 	Reasons      []string           `json:"reasons,omitempty"`
+	Actions      []string           `json:"actions,omitempty"` // recommended treatments, most restrictive first
 	Metadata     EvaluationMetadata `json:"metadata"`
 }
 
@@ -79,6 +119,9 @@ func (e *Evaluation) ToResponse() *EvaluationResponse {
 
 	var reasons []string
 	for _, r := range e.RuleResults {
+		if r.Shadow {
+			continue
+		}
 		if r.SubRuleRef == RuleOutcomeFail || r.SubRuleRef == RuleOutcomeReview {
 			reasons = append(reasons, r.Reason)
 		}
@@ -91,6 +134,125 @@ func (e *Evaluation) ToResponse() *EvaluationResponse {
 		Status:       status,
 		Score:        e.Score,
 		Reasons:      reasons,
+		Actions:      e.Actions(),
 		Metadata:     e.Metadata,
 	}
 }
+
+// Actions returns the distinct actions of the bands the rules matched, most
+// restrictive first. Shadow rules recommend nothing.
+func (e *Evaluation) Actions() []string {
+	matched := make(map[string]bool)
+	for _, r := range e.RuleResults {
+		if !r.Shadow && r.Action != "" {
+			matched[r.Action] = true
+		}
+	}
+
+	var actions []string
+	for _, action := range BandActions {
+		if matched[action] {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// EvaluationFilter narrows an evaluation listing; zero fields match everything.
+// Party filters match the stored transaction, so evaluations of transactions
+// that were not stored only match without them.
+type EvaluationFilter struct {
+	Status     string            // Only evaluations with this status, StatusAlert or StatusNoAlert
+	MinScore   *float64          // Only evaluations scoring at least this
+	MaxScore   *float64          // Only evaluations scoring at most this
+	Since      time.Time         // Only evaluations at or after this time
+	Until      time.Time         // Only evaluations before this time
+	DebtorID   string            // Only evaluations of this debtor's transactions
+	CreditorID string            // Only evaluations of this creditor's transactions
+	TxID       string            // Only evaluations of this transaction
+	TypologyID string            // Only evaluations where this typology triggered, or scored above MinContribution
+	After      *EvaluationCursor // Only evaluations listed after this one, for the next page
+	Limit      int               // Max evaluations returned, latest first; 0 = repository default
+
+	RuleID          string   // Only evaluations where this rule failed or asked for review
+	MinContribution *float64 // With TypologyID, the typology's score must exceed this, triggered or not
+}
+
+// EvaluationCursor is the position of an evaluation in a listing, which is
+// ordered by timestamp and then ID, latest first.
+type EvaluationCursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+// CursorOf returns the listing position of eval.
+func CursorOf(eval *Evaluation) *EvaluationCursor {
+	return &EvaluationCursor{Timestamp: eval.Timestamp, ID: eval.ID}
+}
+
+// Encode returns the cursor as an opaque, URL-safe token.
+func (c *EvaluationCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ErrInvalidCursor is returned when decoding a malformed cursor token.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DecodeEvaluationCursor parses a token returned by EvaluationCursor.Encode.
+func DecodeEvaluationCursor(token string) (*EvaluationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &EvaluationCursor{Timestamp: timestamp, ID: id}, nil
+}
+
+// TypologyTriggered reports whether the typology with the given ID triggered.
+func (e *Evaluation) TypologyTriggered(typologyID string) bool {
+	for _, t := range e.TypologyResults {
+		if t.Triggered && t.TypologyID == typologyID {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleFired reports whether the rule failed or asked for review. Shadow
+// results never count, as they can't cause an alert.
+func (e *Evaluation) RuleFired(ruleID string) bool {
+	for _, r := range e.RuleResults {
+		if r.RuleID == ruleID && !r.Shadow && (r.SubRuleRef == RuleOutcomeFail || r.SubRuleRef == RuleOutcomeReview) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contributed reports whether the evaluation matches a rule and typology
+// filter: ruleID, when set, fired, and typologyID, when set, triggered or,
+// with minContribution, scored above it.
+func (e *Evaluation) Contributed(ruleID, typologyID string, minContribution *float64) bool {
+	if ruleID != "" && !e.RuleFired(ruleID) {
+		return false
+	}
+	if typologyID == "" {
+		return true
+	}
+	if minContribution == nil {
+		return e.TypologyTriggered(typologyID)
+	}
+	for _, t := range e.TypologyResults {
+		if t.TypologyID == typologyID && t.Score > *minContribution {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// EvaluationLogRecord is one entry in a tenant's append-only, hash-chained
+// evaluation log. Each record's Hash covers its own content and PrevHash, so
+// altering or removing any record breaks every hash after it.
+type EvaluationLogRecord struct {
+	TenantID     string    `json:"tenantId"`
+	Seq          int64     `json:"seq"` // 1-based position in the tenant's chain
+	EvaluationID string    `json:"evaluationId"`
+	TxID         string    `json:"txId"`
+	Payload      string    `json:"payload"` // Evaluation as JSON, exactly as hashed
+	PrevHash     string    `json:"prevHash"`
+	Hash         string    `json:"hash"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
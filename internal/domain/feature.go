@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// FeatureFlag is a stored on/off override for a feature. TenantID "*" sets the
+// install-wide value; a tenant's own row takes precedence over it.
+type FeatureFlag struct {
+	TenantID  string    `json:"tenantId"`
+	Name      string    `json:"name"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
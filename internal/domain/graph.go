@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CounterpartyEdge sums the payments from one party to another. The edges
+// of a tenant form its counterparty network, built incrementally as
+// transactions are stored. Reversals don't add to it: a refund is not a new
+// relationship.
+type CounterpartyEdge struct {
+	TenantID   string    `json:"tenantId"`
+	DebtorID   string    `json:"debtorId"`
+	CreditorID string    `json:"creditorId"`
+	Count      int64     `json:"count"`
+	Amount     float64   `json:"amount"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
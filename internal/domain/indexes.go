@@ -0,0 +1,68 @@
+package domain
+
+import "context"
+
+// IndexAdvisor is implemented by repositories that can inspect their query
+// statistics and recommend indexes for the velocity and list queries.
+type IndexAdvisor interface {
+	// AdviseIndexes refreshes planner statistics and checks each candidate
+	// index against every tenant's entity cardinality and velocity window.
+	AdviseIndexes(ctx context.Context, velocity VelocityConfig) (*IndexReport, error)
+
+	// CreateIndex creates a candidate index by name.
+	CreateIndex(ctx context.Context, name string) error
+}
+
+// IndexReport is the outcome of an index advisor run.
+type IndexReport struct {
+	Driver  string              `json:"driver"`
+	Indexes []IndexAdvice       `json:"indexes"`
+	Tenants []TenantCardinality `json:"tenants"`
+
+	// Queries lists the slowest matching statements from pg_stat_statements;
+	// empty on SQLite or when the extension is not installed
+	Queries []QueryStat `json:"queries,omitempty"`
+
+	// Statistics lists the SQLite planner statistics gathered by ANALYZE
+	Statistics []IndexStat `json:"statistics,omitempty"`
+}
+
+// IndexAdvice is the advisor's verdict on one candidate index.
+type IndexAdvice struct {
+	Name        string   `json:"name"`
+	Table       string   `json:"table"`
+	Columns     []string `json:"columns"`
+	Query       string   `json:"query"` // the query the index serves
+	Exists      bool     `json:"exists"`
+	Recommended bool     `json:"recommended"`
+	Created     bool     `json:"created,omitempty"`
+	Tenants     []string `json:"tenants,omitempty"` // tenants whose workload calls for it
+	Reason      string   `json:"reason"`
+}
+
+// TenantCardinality describes a tenant's transactions for the index advisor.
+type TenantCardinality struct {
+	TenantID     string `json:"tenantId"`
+	Transactions int64  `json:"transactions"`
+	Debtors      int64  `json:"debtors"`
+	Creditors    int64  `json:"creditors"`
+	Alerts       int64  `json:"alerts"`
+	SpanSecs     int64  `json:"spanSecs"`   // oldest to newest transaction
+	WindowSecs   int    `json:"windowSecs"` // the tenant's velocity window
+}
+
+// QueryStat is a statement's execution statistics.
+type QueryStat struct {
+	Query  string  `json:"query"`
+	Calls  int64   `json:"calls"`
+	MeanMs float64 `json:"meanMs"`
+}
+
+// IndexStat is an index's planner statistics: its row count and the
+// average number of rows per distinct value of each column prefix.
+type IndexStat struct {
+	Table      string  `json:"table"`
+	Index      string  `json:"index"`
+	Rows       int64   `json:"rows"`
+	RowsPerKey []int64 `json:"rowsPerKey"`
+}
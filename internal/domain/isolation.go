@@ -0,0 +1,58 @@
+package domain
+
+import "context"
+
+// IsolationAuditor is implemented by repositories that can check the tenant
+// isolation invariants across tenants.
+type IsolationAuditor interface {
+	// AuditIsolation reports every record that references another tenant's data.
+	AuditIsolation(ctx context.Context) (*IsolationReport, error)
+
+	// RepairIsolation repairs the repairable violations and reports all of
+	// them, marking those it repaired.
+	RepairIsolation(ctx context.Context) (*IsolationReport, error)
+}
+
+// Isolation checks, named after the reference they inspect.
+const (
+	// IsolationTypologyRule: a typology references a rule that only exists
+	// for other tenants. Repaired by removing the reference.
+	IsolationTypologyRule = "typology_rule"
+
+	// IsolationEvaluationTransaction: an evaluation names a transaction that
+	// is stored under another tenant only.
+	IsolationEvaluationTransaction = "evaluation_transaction"
+
+	// IsolationAlertTransaction: an alert names a transaction that is stored
+	// under another tenant only.
+	IsolationAlertTransaction = "alert_transaction"
+
+	// IsolationOutcomeEvaluation: an outcome is linked to an evaluation of
+	// another tenant. Repaired by deleting the outcome, which copied that
+	// tenant's parties.
+	IsolationOutcomeEvaluation = "outcome_evaluation"
+)
+
+// IsolationReport is the outcome of a tenant isolation audit.
+type IsolationReport struct {
+	Driver     string               `json:"driver"`
+	Violations []IsolationViolation `json:"violations"`
+	Repaired   int                  `json:"repaired"`
+
+	// GlobalKeys lists the tables whose record IDs are unique across
+	// tenants rather than per tenant, so a tenant can't reuse an ID another
+	// tenant already stored.
+	GlobalKeys []string `json:"globalKeys"`
+}
+
+// IsolationViolation is a record that references another tenant's data.
+type IsolationViolation struct {
+	Check       string `json:"check"`
+	Table       string `json:"table"`
+	TenantID    string `json:"tenantId"`
+	ID          string `json:"id"`          // the offending record
+	Ref         string `json:"ref"`         // the ID it references
+	RefTenantID string `json:"refTenantId"` // the tenant the referenced record belongs to
+	Repairable  bool   `json:"repairable"`
+	Repaired    bool   `json:"repaired,omitempty"`
+}
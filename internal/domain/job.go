@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// Job types
+const (
+	JobTypeBatchFile  = "batch_file" // Evaluate every row of an uploaded transactions file
+	JobTypeReevaluate = "reevaluate" // Re-evaluate stored transactions under the current rule set
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusPaused    = "paused"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job file kinds
+const (
+	JobFileInput  = "input"
+	JobFileResult = "result"
+)
+
+// Job tracks a long-running background operation and its progress.
+type Job struct {
+	ID          string     `json:"id"`
+	TenantID    string     `json:"tenantId"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Params      *JobParams `json:"params,omitempty"`
+	Total       int        `json:"total"`     // Items to process
+	Processed   int        `json:"processed"` // Items processed so far, including failures
+	Failed      int        `json:"failed"`    // Items that could not be evaluated
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// JobParams configures a job. Fields apply only to the job types that use them.
+type JobParams struct {
+	Since         time.Time `json:"since,omitempty"`         // Reevaluate: start of the transaction window
+	Until         time.Time `json:"until,omitempty"`         // Reevaluate: end of the transaction window (exclusive)
+	RatePerSecond int       `json:"ratePerSecond,omitempty"` // Max items per second; 0 = unthrottled
+}
+
+// Done reports whether the job has reached a terminal status.
+func (j *Job) Done() bool {
+	switch j.Status {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return true
+	}
+	return false
+}
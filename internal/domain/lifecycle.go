@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Lifecycle topics carry a LifecycleEvent when a tenant's configuration or
+// case workflow changes, so external systems can react to more than
+// transaction decisions.
+const (
+	TopicRuleCreated      = "osprey.rule.created"
+	TopicRuleUpdated      = "osprey.rule.updated"
+	TopicRuleDeleted      = "osprey.rule.deleted"
+	TopicTypologyReloaded = "osprey.typology.reloaded"
+	TopicCaseClosed       = "osprey.case.closed"
+
+	// TopicGuardrailOverridden records a forced change beyond the guardrails
+	TopicGuardrailOverridden = "osprey.guardrail.overridden"
+)
+
+// LifecycleEvent is the payload of the lifecycle topics. Type repeats the
+// topic; the other fields are set as the event type describes.
+type LifecycleEvent struct {
+	Type     string    `json:"type"`
+	TenantID string    `json:"tenantId"`
+	Actor    string    `json:"actor,omitempty"` // the principal that made the change, if known
+	At       time.Time `json:"at"`
+
+	// Rule is the created or updated rule; RuleID names the deleted one
+	Rule   *RuleConfig `json:"rule,omitempty"`
+	RuleID string      `json:"ruleId,omitempty"`
+
+	// Typologies is how many typologies, across tenants, were reloaded
+	Typologies int `json:"typologies,omitempty"`
+
+	// Alert is the closed alert; its status is the disposition
+	Alert *Alert `json:"alert,omitempty"`
+
+	// Operation and Violations describe a forced change beyond the guardrails
+	Operation  string   `json:"operation,omitempty"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// NewLifecycleEvent returns an event of the given topic.
+func NewLifecycleEvent(topic, tenantID, actor string, at time.Time) *LifecycleEvent {
+	return &LifecycleEvent{Type: topic, TenantID: tenantID, Actor: actor, At: at.UTC()}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// MaintenanceWindow is a period during which a tenant's evaluations are
+// still performed and stored, but their alerts notify nobody: no alert
+// topic publish, no alert webhook and no escalation. It keeps planned
+// upstream reruns from paging the on-call. Windows are never deleted;
+// cancelling one keeps it, with who cancelled it and when, as an audit
+// record.
+type MaintenanceWindow struct {
+	ID          string     `json:"id"`
+	TenantID    string     `json:"tenantId"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      time.Time  `json:"endsAt"`
+	Reason      string     `json:"reason"`
+	CreatedBy   string     `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CancelledBy string     `json:"cancelledBy,omitempty"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+}
+
+// Active reports whether the window suppresses notifications at t.
+func (m *MaintenanceWindow) Active(t time.Time) bool {
+	return m.CancelledAt == nil && !t.Before(m.StartsAt) && t.Before(m.EndsAt)
+}
@@ -0,0 +1,52 @@
+package domain
+
+// MLRequest is what an external ML model is asked to score: the transaction
+// and how the rules scored it.
+type MLRequest struct {
+	TenantID    string         `json:"tenantId"`
+	TxID        string         `json:"txId"`
+	Transaction *Transaction   `json:"transaction,omitempty"`
+	RuleScore   float64        `json:"ruleScore"` // The aggregate rule score, 0 to 1
+	RuleResults []MLRuleResult `json:"ruleResults"`
+}
+
+// MLRuleResult is one rule's result as sent to a model.
+type MLRuleResult struct {
+	RuleID  string  `json:"ruleId"`
+	Score   float64 `json:"score"`
+	Outcome string  `json:"outcome"`
+	Weight  float64 `json:"weight"`
+}
+
+// MLScore is a model's answer: a score from 0 to 1 and, optionally, which
+// model gave it.
+type MLScore struct {
+	Score float64 `json:"score"`
+	Model string  `json:"model,omitempty"`
+}
+
+// What a decision does when the ML model fails or times out.
+const (
+	// MLFallbackRules decides on the rule score alone.
+	MLFallbackRules = "rules"
+	// MLFallbackAlert alerts, for tenants that would rather review than
+	// let an unscored transaction through.
+	MLFallbackAlert = "alert"
+)
+
+// ValidMLFallback reports whether s is a known fallback.
+func ValidMLFallback(s string) bool {
+	return s == MLFallbackRules || s == MLFallbackAlert
+}
+
+// MLDecision records how an ML model took part in an evaluation.
+type MLDecision struct {
+	Model     string  `json:"model,omitempty"`
+	Score     float64 `json:"score"`     // The model's score
+	RuleScore float64 `json:"ruleScore"` // The rule score it was blended with
+	Weight    float64 `json:"weight"`
+	Blend     string  `json:"blend"`
+
+	// Fallback is set when the model failed: the fallback the decision took
+	Fallback string `json:"fallback,omitempty"`
+}
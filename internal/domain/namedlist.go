@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Limits of a named list.
+const (
+	MaxNamedListNameLength = 64
+	MaxNamedListValues     = 100000
+)
+
+// NamedList is a tenant-managed set of strings for rule logic, e.g.
+// internal_accounts or high_risk_merchants. Rules read it with
+// list("name"). Unlike watchlists, the values have no meaning of their own:
+// they are whatever IDs or codes the rules compare them with.
+type NamedList struct {
+	TenantID    string    `json:"tenantId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Values      []string  `json:"values"`
+	UpdatedBy   string    `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ValidNamedListName reports whether name is 1 to 64 lower-case letters,
+// digits, underscores or hyphens.
+func ValidNamedListName(name string) bool {
+	if name == "" || len(name) > MaxNamedListNameLength {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
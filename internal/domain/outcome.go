@@ -0,0 +1,146 @@
+package domain
+
+import "time"
+
+// Outcome types integrators report after a decision.
+const (
+	// OutcomeChallengePassed: the customer passed the step-up challenge.
+	OutcomeChallengePassed = "challenge_passed"
+
+	// OutcomeChallengeFailed: the customer failed or abandoned the challenge.
+	OutcomeChallengeFailed = "challenge_failed"
+
+	// OutcomePaymentReturned: the payment was returned or reversed.
+	OutcomePaymentReturned = "payment_returned"
+
+	// OutcomeChargeback: the payment was charged back.
+	OutcomeChargeback = "chargeback"
+)
+
+// OutcomeTypes lists the valid outcome types.
+var OutcomeTypes = []string{OutcomeChallengePassed, OutcomeChallengeFailed, OutcomePaymentReturned, OutcomeChargeback}
+
+// LossOutcomes are the outcome types that cost the tenant money.
+var LossOutcomes = []string{OutcomePaymentReturned, OutcomeChargeback}
+
+// Labels set on loss outcomes from the decision of their evaluation, so
+// missed fraud can be pulled for calibration.
+const (
+	// OutcomeLabelCaught: the evaluation alerted before the loss.
+	OutcomeLabelCaught = "caught"
+
+	// OutcomeLabelMissed: the evaluation passed the transaction.
+	OutcomeLabelMissed = "missed"
+)
+
+// IsLossOutcome reports whether t is a loss outcome type.
+func IsLossOutcome(t string) bool {
+	for _, outcome := range LossOutcomes {
+		if t == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// OutcomeLabel labels an outcome of type t against the status of its
+// evaluation. Only loss outcomes are labelled.
+func OutcomeLabel(t string, status string) string {
+	if !IsLossOutcome(t) {
+		return ""
+	}
+	if status == StatusAlert {
+		return OutcomeLabelCaught
+	}
+	return OutcomeLabelMissed
+}
+
+// ValidOutcomeType reports whether t is a known outcome type.
+func ValidOutcomeType(t string) bool {
+	for _, outcome := range OutcomeTypes {
+		if t == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluationOutcome records what happened after an evaluation's decision.
+// The transaction and its parties are copied from the evaluation so outcomes
+// can be looked up per party without a join.
+type EvaluationOutcome struct {
+	ID           string    `json:"id"`
+	TenantID     string    `json:"tenantId"`
+	EvaluationID string    `json:"evaluationId"`
+	TxID         string    `json:"txId"`
+	DebtorID     string    `json:"debtorId"`
+	CreditorID   string    `json:"creditorId"`
+	Outcome      string    `json:"outcome"`
+	Reason       string    `json:"reason,omitempty"`
+	ReasonCode   string    `json:"reasonCode,omitempty"` // the network's chargeback or return code
+	Label        string    `json:"label,omitempty"`      // OutcomeLabelCaught or OutcomeLabelMissed on loss outcomes
+	Amount       Decimal   `json:"amount,omitzero"`      // returned or charged back amount
+	ReportedBy   string    `json:"reportedBy,omitempty"` // principal of the reporting request
+	OccurredAt   time.Time `json:"occurredAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// OutcomeFilter narrows an outcome listing; zero fields match everything.
+type OutcomeFilter struct {
+	EvaluationID string    // Only outcomes of this evaluation
+	PartyID      string    // Only outcomes where this party was debtor or creditor
+	Outcome      string    // Only outcomes of this type
+	Label        string    // Only outcomes with this label
+	Since        time.Time // Only outcomes that occurred at or after this time
+	Limit        int       // Max outcomes returned, latest first; 0 = repository default
+}
+
+// OutcomeEvent is a chargeback or return reported by a payment network or
+// processor, linked to the original transaction by its ID.
+type OutcomeEvent struct {
+	ID         string    `json:"id"` // the network's case or return reference
+	TxID       string    `json:"txId"`
+	Outcome    string    `json:"outcome"` // OutcomePaymentReturned or OutcomeChargeback
+	ReasonCode string    `json:"reasonCode,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Amount     Decimal   `json:"amount,omitzero"`
+	OccurredAt time.Time `json:"occurredAt,omitempty"` // defaults to the import time
+}
+
+// Statuses of an imported outcome event.
+const (
+	OutcomeImportImported = "imported"  // Stored, or already stored by an earlier import
+	OutcomeImportInvalid  = "invalid"   // The event failed validation
+	OutcomeImportNotFound = "not_found" // No evaluation of the transaction
+	OutcomeImportFailed   = "failed"    // The outcome could not be stored
+)
+
+// OutcomeImportResult is the result of importing one outcome event.
+type OutcomeImportResult struct {
+	ID           string `json:"id"`
+	TxID         string `json:"txId"`
+	Status       string `json:"status"`
+	EvaluationID string `json:"evaluationId,omitempty"`
+	Label        string `json:"label,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// LossReport aggregates loss outcomes by the rules that fired and the
+// typologies that triggered on their evaluations.
+type LossReport struct {
+	Since      time.Time       `json:"since"`
+	Losses     int             `json:"losses"`
+	Amount     Decimal         `json:"amount"`
+	Caught     int             `json:"caught"`
+	Missed     int             `json:"missed"`
+	Rules      []LossBreakdown `json:"rules"`
+	Typologies []LossBreakdown `json:"typologies"`
+	Truncated  bool            `json:"truncated,omitempty"` // more losses than the report reads
+}
+
+// LossBreakdown is the share of losses attributed to one rule or typology.
+type LossBreakdown struct {
+	ID     string  `json:"id"`
+	Losses int     `json:"losses"`
+	Amount Decimal `json:"amount"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// PartyKYC holds know-your-customer attributes for a transaction party.
+// Profiles are keyed by the same entity ID used as debtor/creditor ID on transactions.
+type PartyKYC struct {
+	EntityID         string    `json:"entityId"`
+	TenantID         string    `json:"tenantId"`
+	RiskRating       string    `json:"riskRating"`                 // "low", "medium", "high"
+	PEP              bool      `json:"pep"`                        // Politically exposed person
+	OnboardedAt      time.Time `json:"onboardedAt,omitempty"`      // Customer onboarding date
+	ResidenceCountry string    `json:"residenceCountry,omitempty"` // ISO 3166-1 alpha-2
+	Segment          string    `json:"segment,omitempty"`          // Customer segment, e.g. "retail" or "sme"
+	UpdatedAt        time.Time `json:"updatedAt,omitempty"`
+}
+
+// PartyFilter narrows a list of KYC profiles. Empty fields match every
+// profile.
+type PartyFilter struct {
+	RiskRating string
+	Segment    string
+}
+
+// KYC risk ratings
+const (
+	RiskRatingLow    = "low"
+	RiskRatingMedium = "medium"
+	RiskRatingHigh   = "high"
+)
+
+// ValidRiskRating reports whether rating is a recognised KYC risk rating.
+func ValidRiskRating(rating string) bool {
+	switch rating {
+	case RiskRatingLow, RiskRatingMedium, RiskRatingHigh:
+		return true
+	}
+	return false
+}
@@ -13,22 +13,199 @@ type Repository interface {
 	SaveTransaction(ctx context.Context, tenantID string, tx *Transaction) error
 	GetTransaction(ctx context.Context, tenantID string, txID string) (*Transaction, error)
 	GetTransactionsByEntity(ctx context.Context, tenantID string, entityID string, since time.Time) ([]*Transaction, error)
+	ListTransactions(ctx context.Context, tenantID string, since, until time.Time, offset, limit int) ([]*Transaction, error)
+	CountTransactions(ctx context.Context, tenantID string, since, until time.Time) (int, error)
 
 	// Rule configuration operations
 	SaveRuleConfig(ctx context.Context, tenantID string, rule *RuleConfig) error
 	GetRuleConfig(ctx context.Context, tenantID string, ruleID string) (*RuleConfig, error)
 	ListRuleConfigs(ctx context.Context, tenantID string) ([]*RuleConfig, error)
+	DeleteRuleConfig(ctx context.Context, tenantID string, ruleID string) error
+	// ListRuleVersions returns every stored version of the tenant's rules,
+	// disabled ones included, by rule ID and then oldest first.
+	ListRuleVersions(ctx context.Context, tenantID string) ([]*RuleVersion, error)
+	// ListAllRuleConfigs spans tenants; it feeds the rule engine loader only.
+	ListAllRuleConfigs(ctx context.Context) ([]*RuleConfig, error)
 
 	// Evaluation results
 	SaveEvaluation(ctx context.Context, tenantID string, eval *Evaluation) error
 	GetEvaluation(ctx context.Context, tenantID string, evalID string) (*Evaluation, error)
+	// GetEvaluationByTx returns the latest evaluation of a transaction.
+	GetEvaluationByTx(ctx context.Context, tenantID string, txID string) (*Evaluation, error)
+	ListEvaluations(ctx context.Context, tenantID string, filter EvaluationFilter) ([]*Evaluation, error)
+	// EvaluationScoreHistogram counts the scores of the evaluations dated
+	// from since until until in ScoreHistogramBuckets buckets.
+	EvaluationScoreHistogram(ctx context.Context, tenantID string, since, until time.Time) ([]int64, error)
+	// TypologyScoreHistograms counts the typology scores of the same
+	// evaluations, per typology ID.
+	TypologyScoreHistograms(ctx context.Context, tenantID string, since, until time.Time) (map[string][]int64, error)
+	// ListTenantActivity spans tenants; it feeds the operator health summary only.
+	ListTenantActivity(ctx context.Context, since time.Time) ([]*TenantActivity, error)
+	// ListTenantBenchmarks spans tenants; it feeds the benchmark report only.
+	ListTenantBenchmarks(ctx context.Context, since, until time.Time) ([]*TenantBenchmark, error)
+	// ListTenantIDs spans tenants; it feeds the sandbox purger only.
+	ListTenantIDs(ctx context.Context) ([]string, error)
+	// PurgeTenantData deletes a tenant's activity dated before the given
+	// time, keeping its configuration, and returns the rows deleted.
+	PurgeTenantData(ctx context.Context, tenantID string, before time.Time) (int64, error)
+	// ExpireTenantData deletes a tenant's activity of a retention class dated
+	// before the given time and returns the rows deleted. With an archive,
+	// every row is passed to it first and nothing is deleted unless it closes
+	// successfully.
+	ExpireTenantData(ctx context.Context, tenantID string, class string, before time.Time, archive RetentionArchive) (int64, error)
+
+	// Append-only evaluation log
+	AppendEvaluationLog(ctx context.Context, tenantID string, record *EvaluationLogRecord) error
+	GetLastEvaluationLog(ctx context.Context, tenantID string) (*EvaluationLogRecord, error)
+	ListEvaluationLog(ctx context.Context, tenantID string, afterSeq int64, limit int) ([]*EvaluationLogRecord, error)
+
+	// Append-only configuration audit log
+	AppendAuditEntry(ctx context.Context, tenantID string, entry *AuditEntry) error
+	GetLastAuditEntry(ctx context.Context, tenantID string) (*AuditEntry, error)
+	GetAuditEntry(ctx context.Context, tenantID string, seq int64) (*AuditEntry, error)
+	ListAuditEntries(ctx context.Context, tenantID string, filter AuditFilter) ([]*AuditEntry, error)
 
 	// Typology configuration operations
 	SaveTypology(ctx context.Context, tenantID string, typology *Typology) error
 	GetTypology(ctx context.Context, tenantID string, typologyID string) (*Typology, error)
 	ListTypologies(ctx context.Context, tenantID string) ([]*Typology, error)
+	// ListAllTypologies spans tenants; it feeds the typology engine loader only.
+	ListAllTypologies(ctx context.Context) ([]*Typology, error)
 	DeleteTypology(ctx context.Context, tenantID string, typologyID string) error
 
+	// Party KYC operations
+	SavePartyKYC(ctx context.Context, tenantID string, kyc *PartyKYC) error
+	GetPartyKYC(ctx context.Context, tenantID string, entityID string) (*PartyKYC, error)
+	// ListPartyKYC returns the tenant's profiles sorted by entity ID.
+	ListPartyKYC(ctx context.Context, tenantID string, filter PartyFilter) ([]*PartyKYC, error)
+	DeletePartyKYC(ctx context.Context, tenantID string, entityID string) error
+
+	// Corridor risk operations
+	SaveCorridorRisk(ctx context.Context, tenantID string, corridor *CorridorRisk) error
+	ListCorridorRisks(ctx context.Context, tenantID string) ([]*CorridorRisk, error)
+	DeleteCorridorRisk(ctx context.Context, tenantID string, origin string, destination string) error
+
+	// Watchlist operations
+	SaveWatchlistEntry(ctx context.Context, tenantID string, entry *WatchlistEntry) error
+	GetWatchlistEntry(ctx context.Context, tenantID string, entryID string) (*WatchlistEntry, error)
+	// ListWatchlistEntries lists entries by name; an empty listType lists every list.
+	ListWatchlistEntries(ctx context.Context, tenantID string, listType string, offset, limit int) ([]*WatchlistEntry, error)
+	DeleteWatchlistEntry(ctx context.Context, tenantID string, entryID string) error
+	// MatchWatchlist returns the entries naming the subject by party ID,
+	// account ID, or normalized name and country.
+	MatchWatchlist(ctx context.Context, tenantID string, subject WatchlistSubject) ([]*WatchlistEntry, error)
+
+	// Background job operations
+	SaveJob(ctx context.Context, tenantID string, job *Job) error
+	GetJob(ctx context.Context, tenantID string, jobID string) (*Job, error)
+	ListJobs(ctx context.Context, tenantID string, limit int) ([]*Job, error)
+	SaveJobFile(ctx context.Context, tenantID string, jobID string, kind string, data []byte) error
+	GetJobFile(ctx context.Context, tenantID string, jobID string, kind string) ([]byte, error)
+
+	// Feature flag operations
+	SaveFeatureFlag(ctx context.Context, tenantID string, flag *FeatureFlag) error
+	ListFeatureFlags(ctx context.Context, tenantID string) ([]*FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, tenantID string, name string) error
+
+	// Evaluation outcome operations
+	// SaveOutcome inserts an outcome; one with an existing ID is left unchanged.
+	SaveOutcome(ctx context.Context, tenantID string, outcome *EvaluationOutcome) error
+	ListOutcomes(ctx context.Context, tenantID string, filter OutcomeFilter) ([]*EvaluationOutcome, error)
+
+	// Activation sample operations
+	SaveActivationSample(ctx context.Context, tenantID string, sample *ActivationSample) error
+	ListActivationSamples(ctx context.Context, tenantID string, ruleID string, limit int) ([]*ActivationSample, error)
+
+	// Alert operations
+	SaveAlert(ctx context.Context, tenantID string, alert *Alert) error
+	GetAlert(ctx context.Context, tenantID string, alertID string) (*Alert, error)
+	ListAlerts(ctx context.Context, tenantID string, filter AlertFilter) ([]*Alert, error)
+	AckAlert(ctx context.Context, tenantID string, alertID string, by string, note string, at time.Time) error
+	EscalateAlert(ctx context.Context, tenantID string, alertID string, level int, at time.Time) error
+	// UpdateAlertCase sets status and assignee if the alert is still in fromStatus.
+	UpdateAlertCase(ctx context.Context, tenantID string, alertID string, fromStatus string, status string, assignee string, at time.Time) error
+	SaveAlertEvent(ctx context.Context, tenantID string, event *AlertEvent) error
+	ListAlertEvents(ctx context.Context, tenantID string, alertID string) ([]*AlertEvent, error)
+	// ListDueAlerts spans tenants; it feeds the background escalator only.
+	ListDueAlerts(ctx context.Context, notifiedBefore time.Time, maxLevel int, limit int) ([]*Alert, error)
+
+	// Webhook operations
+	SaveWebhook(ctx context.Context, tenantID string, webhook *Webhook) error
+	GetWebhook(ctx context.Context, tenantID string, webhookID string) (*Webhook, error)
+	ListWebhooks(ctx context.Context, tenantID string) ([]*Webhook, error)
+	DeleteWebhook(ctx context.Context, tenantID string, webhookID string) error
+	SaveWebhookDelivery(ctx context.Context, tenantID string, delivery *WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, tenantID string, webhookID string, limit int) ([]*WebhookDelivery, error)
+	// ClaimWebhookDelivery reserves a pending delivery for one attempt until
+	// leaseUntil. It fails with ErrNotFound if another instance claimed it.
+	ClaimWebhookDelivery(ctx context.Context, tenantID string, deliveryID string, attempts int, leaseUntil time.Time) error
+	// ListDueWebhookDeliveries spans tenants; it feeds the background dispatcher only.
+	ListDueWebhookDeliveries(ctx context.Context, before time.Time, limit int) ([]*WebhookDelivery, error)
+
+	// Dead letter operations
+	SaveDeadLetter(ctx context.Context, tenantID string, dead *DeadLetter) error
+	GetDeadLetter(ctx context.Context, tenantID string, deadLetterID string) (*DeadLetter, error)
+	ListDeadLetters(ctx context.Context, tenantID string, filter DeadLetterFilter) ([]*DeadLetter, error)
+	// MarkDeadLetterReplayed fails with ErrNotFound if it was already replayed.
+	MarkDeadLetterReplayed(ctx context.Context, tenantID string, deadLetterID string, at time.Time) error
+
+	// Maintenance window operations
+	SaveMaintenanceWindow(ctx context.Context, tenantID string, window *MaintenanceWindow) error
+	GetMaintenanceWindow(ctx context.Context, tenantID string, windowID string) (*MaintenanceWindow, error)
+	ListMaintenanceWindows(ctx context.Context, tenantID string) ([]*MaintenanceWindow, error)
+	// CancelMaintenanceWindow fails with ErrNotFound if it was already cancelled.
+	CancelMaintenanceWindow(ctx context.Context, tenantID string, windowID string, by string, at time.Time) error
+	// ActiveMaintenanceWindow returns a window active at t, or ErrNotFound.
+	ActiveMaintenanceWindow(ctx context.Context, tenantID string, t time.Time) (*MaintenanceWindow, error)
+
+	// Review queue claim operations
+	// ClaimReviewItem claims an item, or renews the claimant's own claim. It
+	// fails with ErrNotFound if another analyst's claim is still active.
+	ClaimReviewItem(ctx context.Context, tenantID string, claim *ReviewClaim) error
+	// ReleaseReviewItem fails with ErrNotFound if by holds no claim on the item.
+	ReleaseReviewItem(ctx context.Context, tenantID string, itemID string, by string) error
+	// ListReviewClaims returns the claims active at t.
+	ListReviewClaims(ctx context.Context, tenantID string, at time.Time) ([]*ReviewClaim, error)
+
+	// Scoring config operations
+	SaveScoringConfig(ctx context.Context, tenantID string, cfg *ScoringConfig) error
+	GetScoringConfig(ctx context.Context, tenantID string) (*ScoringConfig, error)
+	DeleteScoringConfig(ctx context.Context, tenantID string) error
+
+	// Retention policy operations
+	SaveRetentionPolicy(ctx context.Context, tenantID string, policy *RetentionPolicy) error
+	GetRetentionPolicy(ctx context.Context, tenantID string) (*RetentionPolicy, error)
+	DeleteRetentionPolicy(ctx context.Context, tenantID string) error
+
+	// Named list operations
+	SaveNamedList(ctx context.Context, tenantID string, list *NamedList) error
+	GetNamedList(ctx context.Context, tenantID string, name string) (*NamedList, error)
+	// ListNamedLists returns the tenant's lists by name.
+	ListNamedLists(ctx context.Context, tenantID string) ([]*NamedList, error)
+	DeleteNamedList(ctx context.Context, tenantID string, name string) error
+	// ListAllNamedLists returns every tenant's lists with their values, for
+	// loading into the rule engine.
+	ListAllNamedLists(ctx context.Context) ([]*NamedList, error)
+
+	// Counterparty network operations
+	// RecordCounterpartyEdge adds a transaction to the edge from its debtor
+	// to its creditor, creating the edge on their first transaction.
+	RecordCounterpartyEdge(ctx context.Context, tenantID string, tx *Transaction) error
+	GetCounterpartyEdge(ctx context.Context, tenantID string, debtorID string, creditorID string) (*CounterpartyEdge, error)
+	// ListCounterpartyEdges returns the edges from and to a party last seen
+	// at or after since, latest first.
+	ListCounterpartyEdges(ctx context.Context, tenantID string, partyID string, since time.Time) ([]*CounterpartyEdge, error)
+
+	// Behavioral baseline operations
+	// SaveEntityBaseline creates or replaces an entity's baseline.
+	SaveEntityBaseline(ctx context.Context, tenantID string, baseline *EntityBaseline) error
+	GetEntityBaseline(ctx context.Context, tenantID string, entityID string) (*EntityBaseline, error)
+
+	// Account balance operations
+	// SaveAccountBalance creates or replaces an account's balance.
+	SaveAccountBalance(ctx context.Context, tenantID string, balance *AccountBalance) error
+	GetAccountBalance(ctx context.Context, tenantID string, accountID string) (*AccountBalance, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 
@@ -36,9 +213,22 @@ type Repository interface {
 	Close() error
 }
 
+// BaseRepository returns the repository under any wrappers with an Unwrap
+// method. Wrappers only forward Repository, so optional interfaces such as
+// IndexAdvisor are checked on the base repository.
+func BaseRepository(repo Repository) Repository {
+	for {
+		wrapper, ok := repo.(interface{ Unwrap() Repository })
+		if !ok {
+			return repo
+		}
+		repo = wrapper.Unwrap()
+	}
+}
+
 // RepositoryConfig holds configuration for repository initialization.
 type RepositoryConfig struct {
-	// Driver is the database driver: "sqlite" or "postgres"
+	// Driver is the database driver: "sqlite", "postgres" or "memory"
 	Driver string
 
 	// SQLite specific
@@ -0,0 +1,48 @@
+package domain
+
+import "context"
+
+// RequestContext carries request-scoped identity and tracing details from the
+// API edge into the evaluation pipeline, so logs and results record who asked
+// for what. Fields are empty when unknown, e.g. for batch evaluations.
+type RequestContext struct {
+	TenantID  string `json:"tenantId,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Principal string `json:"principal,omitempty"` // Authenticated caller, as asserted by the auth layer
+	ClientIP  string `json:"clientIp,omitempty"`
+}
+
+type requestContextKey struct{}
+
+// WithRequestContext returns a copy of ctx carrying rc.
+func WithRequestContext(ctx context.Context, rc *RequestContext) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, rc)
+}
+
+// RequestContextFrom returns the RequestContext carried by ctx, or nil.
+func RequestContextFrom(ctx context.Context) *RequestContext {
+	rc, _ := ctx.Value(requestContextKey{}).(*RequestContext)
+	return rc
+}
+
+// LogAttrs returns the non-empty fields as slog key-value pairs.
+func (rc *RequestContext) LogAttrs() []any {
+	if rc == nil {
+		return nil
+	}
+
+	var attrs []any
+	for _, kv := range []struct{ key, value string }{
+		{"tenant_id", rc.TenantID},
+		{"trace_id", rc.TraceID},
+		{"request_id", rc.RequestID},
+		{"principal", rc.Principal},
+		{"client_ip", rc.ClientIP},
+	} {
+		if kv.value != "" {
+			attrs = append(attrs, kv.key, kv.value)
+		}
+	}
+	return attrs
+}
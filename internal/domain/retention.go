@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Retention classes: the kinds of tenant activity a retention policy keeps
+// for different periods.
+const (
+	RetentionTransactions = "transactions" // transactions and counterparty edges
+	RetentionEvaluations  = "evaluations"  // evaluations with their results, outcomes, samples and log
+	RetentionAlerts       = "alerts"       // alerts and their history
+)
+
+// RetentionClasses lists the retention classes in the order they are expired.
+var RetentionClasses = []string{RetentionTransactions, RetentionEvaluations, RetentionAlerts}
+
+// ComplianceMinRetentionDays is the fewest days compliance mode keeps each
+// class of activity: five years, the record keeping period of FATF
+// Recommendation 11.
+const ComplianceMinRetentionDays = 5 * 365
+
+// RetentionPolicy is how many days a tenant keeps each class of its
+// activity; zero keeps it forever. Configuration such as rules, typologies
+// and webhooks is never expired.
+type RetentionPolicy struct {
+	TenantID        string `json:"tenantId,omitempty"`
+	TransactionDays int    `json:"transactionDays"`
+	EvaluationDays  int    `json:"evaluationDays"`
+	AlertDays       int    `json:"alertDays"`
+
+	// Archive writes expired rows to the archive directory before they are
+	// deleted
+	Archive bool `json:"archive"`
+
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// Days returns how many days the policy keeps a retention class, 0 for ever.
+func (p *RetentionPolicy) Days(class string) int {
+	switch class {
+	case RetentionTransactions:
+		return p.TransactionDays
+	case RetentionEvaluations:
+		return p.EvaluationDays
+	case RetentionAlerts:
+		return p.AlertDays
+	}
+	return 0
+}
+
+// CheckCompliance returns an error when the policy expires a class sooner
+// than ComplianceMinRetentionDays. Keeping a class forever is allowed.
+func (p *RetentionPolicy) CheckCompliance() error {
+	for _, field := range []struct {
+		name string
+		days int
+	}{
+		{"transactionDays", p.TransactionDays},
+		{"evaluationDays", p.EvaluationDays},
+		{"alertDays", p.AlertDays},
+	} {
+		if field.days > 0 && field.days < ComplianceMinRetentionDays {
+			return fmt.Errorf("%s must be 0 or at least %d in compliance mode", field.name, ComplianceMinRetentionDays)
+		}
+	}
+	return nil
+}
+
+// RetentionArchive receives a tenant's expired rows before they are deleted.
+type RetentionArchive interface {
+	// ArchiveRow stores one row of a table, by column name.
+	ArchiveRow(table string, row map[string]any) error
+
+	// Close completes the archive. The rows are only deleted if it succeeds.
+	Close() error
+}
+
+// Compactor is implemented by repositories that can give the space of
+// deleted rows back to the file system, such as SQLite with VACUUM.
+type Compactor interface {
+	Compact(ctx context.Context) error
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// ReviewClaim marks a review queue item as being worked by one analyst, so
+// two analysts don't pick up the same held payment or case. A claim lapses
+// at ExpiresAt, returning an abandoned item to the queue.
+type ReviewClaim struct {
+	ItemID    string    `json:"itemId"` // the evaluation ID of the held transaction, case or alert
+	TenantID  string    `json:"tenantId"`
+	ClaimedBy string    `json:"claimedBy"`
+	ClaimedAt time.Time `json:"claimedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Active reports whether the claim still holds at t.
+func (c *ReviewClaim) Active(t time.Time) bool {
+	return t.Before(c.ExpiresAt)
+}
+
+// ReviewConfig sets the review queue's deadlines and claim lease.
+type ReviewConfig struct {
+	// HoldSLA is how long a held transaction may wait for a decision.
+	HoldSLA time.Duration `json:"holdSla"`
+
+	// AlertSLA is how long an alert may wait for an acknowledgment.
+	AlertSLA time.Duration `json:"alertSla"`
+
+	// CaseSLA is how long an acknowledged or investigated alert may stay
+	// open before its disposition.
+	CaseSLA time.Duration `json:"caseSla"`
+
+	// HoldWindow is how far back held transactions are listed. Older holds
+	// are assumed released by the payment system's own timeout.
+	HoldWindow time.Duration `json:"holdWindow"`
+
+	// ClaimTTL is how long a claim lasts unless renewed by claiming again.
+	ClaimTTL time.Duration `json:"claimTtl"`
+}
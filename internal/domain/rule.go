@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // RuleConfig defines a fraud detection rule configuration.
 type RuleConfig struct {
 	ID          string `json:"id"`
@@ -8,7 +10,17 @@ type RuleConfig struct {
 	Description string `json:"description"`
 	Version     string `json:"version"`
 
-	// CEL expression to evaluate
+	// Owner is the team or person accountable for the rule
+	Owner string `json:"owner,omitempty"`
+
+	// SourceFile is the file the rule was applied from by Git sync or the
+	// rule directory watcher, relative to the synced directory
+	SourceFile string `json:"sourceFile,omitempty"`
+
+	// Language of the expression; empty means RuleLanguageCEL
+	Language string `json:"language,omitempty"`
+
+	// Expression to evaluate
 	Expression string `json:"expression"`
 
 	// Outcome bands for score-to-decision mapping
@@ -19,6 +31,14 @@ type RuleConfig struct {
 
 	// Whether rule is active
 	Enabled bool `json:"enabled"`
+
+	// SampleRate is the fraction (0-1) of evaluations whose full activation
+	// is stored as an ActivationSample. 0 disables sampling.
+	SampleRate float64 `json:"sampleRate,omitempty"`
+
+	// Shadow rules are evaluated and their results recorded, but they are
+	// excluded from scores, typologies and alert decisions.
+	Shadow bool `json:"shadow,omitempty"`
 }
 
 // RuleBand maps a score range to an outcome.
@@ -27,6 +47,52 @@ type RuleBand struct {
 	UpperLimit *float64 `json:"upperLimit,omitempty"`
 	SubRuleRef string   `json:"subRuleRef"` // e.g., ".pass", ".fail", ".review"
 	Reason     string   `json:"reason"`
+	Action     string   `json:"action,omitempty"` // recommended treatment, e.g. BandActionStepUpAuth
+}
+
+// Band actions recommend how the integrator should treat a transaction.
+const (
+	BandActionHold       = "hold"         // Hold the payment for review
+	BandActionStepUpAuth = "step_up_auth" // Challenge the customer, e.g. 3DS
+	BandActionFlag       = "flag"         // Let it through, marked for follow-up
+	BandActionNotify     = "notify"       // Let it through and tell someone
+)
+
+// BandActions lists the valid band actions, most restrictive first.
+var BandActions = []string{BandActionHold, BandActionStepUpAuth, BandActionFlag, BandActionNotify}
+
+// ValidBandAction reports whether a is a known band action.
+func ValidBandAction(a string) bool {
+	for _, action := range BandActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule expression languages. Expr rules are translated to CEL when they are
+// compiled, easing migrations from engines built on expr-lang/expr.
+const (
+	RuleLanguageCEL  = "cel"
+	RuleLanguageExpr = "expr"
+)
+
+// RuleLanguages lists the valid rule expression languages.
+var RuleLanguages = []string{RuleLanguageCEL, RuleLanguageExpr}
+
+// ValidRuleLanguage reports whether l is a known rule language. Empty is
+// valid and means RuleLanguageCEL.
+func ValidRuleLanguage(l string) bool {
+	if l == "" {
+		return true
+	}
+	for _, language := range RuleLanguages {
+		if l == language {
+			return true
+		}
+	}
+	return false
 }
 
 // RuleResult is the output of a rule evaluation.
@@ -38,7 +104,9 @@ type RuleResult struct {
 	Score      float64 `json:"score"`      // The computed value
 	Reason     string  `json:"reason"`
 	Weight     float64 `json:"weight"`
-	ProcessMs  int64   `json:"processMs"` // Processing time in milliseconds
+	ProcessMs  int64   `json:"processMs"`        // Processing time in milliseconds
+	Shadow     bool    `json:"shadow,omitempty"` // Recorded only; excluded from scoring
+	Action     string  `json:"action,omitempty"` // The matched band's action
 }
 
 // Predefined rule outcomes
@@ -56,3 +124,15 @@ type VelocityRule struct {
 	Threshold   int    `json:"threshold"`   // Max transactions allowed
 	WindowSecs  int    `json:"windowSecs"`  // Time window in seconds
 }
+
+// RuleVersion records a stored version of a rule. Versions replaced through
+// declarative state are kept disabled, so a rule's versions are its history.
+type RuleVersion struct {
+	RuleID    string    `json:"ruleId"`
+	TenantID  string    `json:"tenantId"`
+	Version   string    `json:"version"`
+	Owner     string    `json:"owner,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// ActivationSample is a stored copy of every CEL variable a rule saw for one
+// evaluation, kept for debugging noisy rules. Values are redacted per the
+// logging redaction policy before storage.
+type ActivationSample struct {
+	ID          string         `json:"id"`
+	TenantID    string         `json:"tenantId"`
+	RuleID      string         `json:"ruleId"`
+	RuleVersion string         `json:"ruleVersion"`
+	TxID        string         `json:"txId"`
+	Outcome     string         `json:"outcome"` // The rule's SubRuleRef
+	Score       float64        `json:"score"`
+	Activation  map[string]any `json:"activation"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
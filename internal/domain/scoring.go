@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// What a rule's "fail" outcome does to a decision.
+const (
+	// CriticalFailAlert alerts whenever a rule fails, whatever the score.
+	CriticalFailAlert = "alert"
+	// CriticalFailScore lets a failed rule count through its score only.
+	CriticalFailScore = "score"
+)
+
+// How an ML model's score is blended with the rule score.
+const (
+	// MLBlendWeighted averages the two: (1 - MLWeight) × rules + MLWeight × model.
+	MLBlendWeighted = "weighted"
+	// MLBlendMax takes the higher of the two.
+	MLBlendMax = "max"
+)
+
+// ScoringConfig is how a tenant's rule results become a decision. Tenants
+// without their own use the deployment defaults.
+type ScoringConfig struct {
+	TenantID string `json:"tenantId,omitempty"`
+
+	// AlertThreshold is the aggregate score, 0 to 1, from which a detection
+	// mode evaluation alerts. Compliance mode uses each typology's own.
+	AlertThreshold float64 `json:"alertThreshold"`
+
+	// WeightedScoring averages rule scores by rule weight; otherwise every
+	// rule counts the same.
+	WeightedScoring bool `json:"weightedScoring"`
+
+	// CriticalFail is CriticalFailAlert or CriticalFailScore.
+	CriticalFail string `json:"criticalFail"`
+
+	// MLWeight is how much an ML model's score counts, 0 to 1. At 0 the
+	// model's score is recorded but doesn't change the decision.
+	MLWeight float64 `json:"mlWeight"`
+
+	// MLBlend is MLBlendWeighted (the default when empty) or MLBlendMax.
+	MLBlend string `json:"mlBlend,omitempty"`
+
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// ValidMLBlend reports whether s is a known blend of model and rule scores.
+func ValidMLBlend(s string) bool {
+	return s == MLBlendWeighted || s == MLBlendMax
+}
+
+// ValidCriticalFail reports whether s is a known critical fail behaviour.
+func ValidCriticalFail(s string) bool {
+	return s == CriticalFailAlert || s == CriticalFailScore
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// VelocitySnapshot is a velocity value a rule saw during an evaluation: the
+// CEL variable, the entity and window it was counted over, and its value.
+type VelocitySnapshot struct {
+	Variable      string  `json:"variable"`
+	EntityID      string  `json:"entityId"`
+	WindowSeconds int     `json:"windowSeconds"`
+	Value         float64 `json:"value"`
+}
+
+// VelocitySnapshots collects the velocity values of one evaluation. It is
+// safe for concurrent use.
+type VelocitySnapshots struct {
+	mu   sync.Mutex
+	list []VelocitySnapshot
+}
+
+type velocitySnapshotsKey struct{}
+
+// WithVelocitySnapshots returns a copy of ctx that collects velocity values
+// reported during an evaluation.
+func WithVelocitySnapshots(ctx context.Context) (context.Context, *VelocitySnapshots) {
+	s := &VelocitySnapshots{}
+	return context.WithValue(ctx, velocitySnapshotsKey{}, s), s
+}
+
+// ReportVelocity records a velocity value on the collector carried by ctx,
+// replacing an earlier value of the same variable, entity and window.
+// Without a collector it is a no-op.
+func ReportVelocity(ctx context.Context, snapshot VelocitySnapshot) {
+	s, _ := ctx.Value(velocitySnapshotsKey{}).(*VelocitySnapshots)
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.list {
+		if existing.Variable == snapshot.Variable && existing.EntityID == snapshot.EntityID && existing.WindowSeconds == snapshot.WindowSeconds {
+			s.list[i] = snapshot
+			return
+		}
+	}
+	s.list = append(s.list, snapshot)
+}
+
+// List returns the velocity values reported so far, or nil if there were none.
+func (s *VelocitySnapshots) List() []VelocitySnapshot {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.list) == 0 {
+		return nil
+	}
+	return append([]VelocitySnapshot(nil), s.list...)
+}
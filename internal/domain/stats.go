@@ -0,0 +1,19 @@
+package domain
+
+// ScoreHistogramBuckets is the number of buckets score histograms are
+// counted in. Bucket i counts scores from i/100 up to (i+1)/100; the last
+// bucket also counts scores of 1.
+const ScoreHistogramBuckets = 100
+
+// ScoreBucket returns the histogram bucket of a score, clamped to the
+// histogram.
+func ScoreHistogramBucket(score float64) int {
+	bucket := int(score*ScoreHistogramBuckets + 1e-9)
+	if bucket < 0 {
+		return 0
+	}
+	if bucket >= ScoreHistogramBuckets {
+		return ScoreHistogramBuckets - 1
+	}
+	return bucket
+}
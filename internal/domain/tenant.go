@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// TenantActivity summarizes a tenant's evaluations for the operator health
+// summary.
+type TenantActivity struct {
+	TenantID         string
+	Evaluations      int // since the requested time
+	Alerts           int // ALRT evaluations since the requested time
+	LastEvaluationAt time.Time
+}
+
+// TenantBenchmark holds a tenant's volume, dispositions and latency over a
+// window, for the cross-tenant benchmark report.
+type TenantBenchmark struct {
+	TenantID            string
+	Evaluations         int64
+	Alerts              int64   // ALRT evaluations
+	ClosedConfirmed     int64   // alerts raised in the window and closed as confirmed
+	ClosedFalsePositive int64   // alerts raised in the window and closed as false positives
+	MeanTotalMs         float64 // mean processing time of the evaluations
+}
@@ -1,9 +1,49 @@
 package domain
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
+// MaxTransactionIDLength caps the length of client-provided transaction IDs.
+const MaxTransactionIDLength = 128
+
+// ValidTransactionID reports whether a client-provided transaction ID is 1 to
+// MaxTransactionIDLength letters, digits, '.', '_', ':' or '-', so it can be
+// used in URL paths as is.
+func ValidTransactionID(id string) bool {
+	if id == "" || len(id) > MaxTransactionIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == ':', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type evaluationTimeKey struct{}
+
+// WithEvaluationTime returns a copy of ctx that evaluates as of t: velocity
+// windows end at t instead of now, so a replayed transaction sees the
+// history it had when it happened.
+func WithEvaluationTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, evaluationTimeKey{}, t)
+}
+
+// EvaluationTime returns the time ctx evaluates as of, or now.
+func EvaluationTime(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(evaluationTimeKey{}).(time.Time); ok {
+		return t
+	}
+	return time.Now()
+}
+
 // Transaction represents an incoming transaction to be evaluated.
 type Transaction struct {
 	// Core identifiers
@@ -20,9 +60,17 @@ type Transaction struct {
 	CreditorAcctID  string `json:"creditorAccountId"`
 
 	// Financial details
-	Amount   float64 `json:"amount"`
+	Amount   Decimal `json:"amount"`
 	Currency string  `json:"currency"`
 
+	// Optional breakdown of Amount into principal, fee, and FX legs
+	Components *AmountComponents `json:"components,omitempty"`
+
+	// Optional links to other transactions of the tenant
+	ReversalOf  string   `json:"reversalOf,omitempty"`  // the transaction this one reverses, e.g. a refund or chargeback
+	PartOfBatch string   `json:"partOfBatch,omitempty"` // batch ID shared by the transactions of one batch
+	RelatedTo   []string `json:"relatedTo,omitempty"`   // other transactions this one relates to
+
 	// Temporal
 	Timestamp time.Time `json:"timestamp"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -54,8 +102,45 @@ type Party struct {
 
 // Amount represents a monetary value.
 type Amount struct {
-	Value    float64 `json:"value" validate:"required,gt=0"`
-	Currency string  `json:"currency" validate:"required,len=3"`
+	Value      Decimal           `json:"value" validate:"required,gt=0"`
+	Currency   string            `json:"currency" validate:"required,len=3"`
+	Components *AmountComponents `json:"components,omitempty"`
+}
+
+// AmountComponents breaks a transaction amount into its constituent legs.
+// Principal + Fee must equal the transaction amount. The FX leg describes the
+// counter-amount delivered in another currency for cross-currency payments.
+type AmountComponents struct {
+	Principal  Decimal `json:"principal"`
+	Fee        Decimal `json:"fee,omitzero"`
+	FXAmount   Decimal `json:"fxAmount,omitzero"`
+	FXCurrency string  `json:"fxCurrency,omitempty"`
+	FXRate     float64 `json:"fxRate,omitempty"`
+}
+
+// Validate checks the components against the transaction total, which
+// Principal + Fee must equal exactly in the currency's minor units.
+func (c *AmountComponents) Validate(total Decimal, currency string) error {
+	if c.Principal.Sign() <= 0 {
+		return fmt.Errorf("components.principal must be positive")
+	}
+	if c.Fee.Sign() < 0 {
+		return fmt.Errorf("components.fee cannot be negative")
+	}
+	exp := CurrencyExponent(currency)
+	if c.Principal.Add(c.Fee).Round(exp).Cmp(total.Round(exp)) != 0 {
+		return fmt.Errorf("components.principal + components.fee must equal amount.value")
+	}
+	if c.FXAmount.Sign() < 0 || c.FXRate < 0 {
+		return fmt.Errorf("components FX values cannot be negative")
+	}
+	if !c.FXAmount.IsZero() && len(c.FXCurrency) != 3 {
+		return fmt.Errorf("components.fxCurrency must be a 3-letter currency code when fxAmount is set")
+	}
+	if c.FXCurrency != "" && c.FXAmount.IsZero() {
+		return fmt.Errorf("components.fxAmount is required when fxCurrency is set")
+	}
+	return nil
 }
 
 // ToTransaction converts a request to a Transaction domain object.
@@ -70,6 +155,7 @@ func (r *TransactionRequest) ToTransaction() *Transaction {
 		CreditorAcctID:  r.Creditor.AccountID,
 		Amount:          r.Amount.Value,
 		Currency:        r.Amount.Currency,
+		Components:      r.Amount.Components,
 		Timestamp:       now,
 		CreatedAt:       now,
 		Metadata:        r.Metadata,
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Typology defines a fraud detection typology configuration.
 // A typology groups multiple rules with weights to calculate composite risk scores.
@@ -18,6 +21,23 @@ type Typology struct {
 	// AlertThreshold is the minimum score to trigger an alert (0.0-1.0)
 	AlertThreshold float64 `json:"alertThreshold"`
 
+	// MinRulesFired is the number of the typology's rules that must score
+	// above zero before it can trigger. 0 disables the check.
+	MinRulesFired int `json:"minRulesFired,omitempty"`
+
+	// MinCoverage is the fraction of the typology's rules (0.0-1.0) that must
+	// have been evaluated without error before it can trigger, so it can't
+	// fire off one heavy rule while the rest were missing. 0 disables the check.
+	MinCoverage float64 `json:"minCoverage,omitempty"`
+
+	// Typologies weights other typologies' scores into this one's, making it
+	// a composite typology. Typologies must not refer to each other in a cycle.
+	Typologies []TypologyWeight `json:"typologies,omitempty"`
+
+	// Condition must also hold for the typology to trigger, so it can require
+	// combinations such as structuring and a new counterparty.
+	Condition *TypologyCondition `json:"condition,omitempty"`
+
 	// Whether typology is active
 	Enabled bool `json:"enabled"`
 
@@ -32,6 +52,54 @@ type TypologyRuleWeight struct {
 	Weight float64 `json:"weight"` // 0.0 to 1.0
 }
 
+// TypologyWeight defines another typology and its weight within a composite typology.
+type TypologyWeight struct {
+	TypologyID string  `json:"typologyId"`
+	Weight     float64 `json:"weight"` // 0.0 to 1.0
+}
+
+// TypologyCondition is a condition on an evaluation's rules and typologies.
+// Exactly one field is set: All holds if every condition in it does, Any if
+// at least one does, Rule if that rule scored above zero, and Typology if
+// that typology triggered.
+type TypologyCondition struct {
+	All      []TypologyCondition `json:"all,omitempty"`
+	Any      []TypologyCondition `json:"any,omitempty"`
+	Rule     string              `json:"rule,omitempty"`
+	Typology string              `json:"typology,omitempty"`
+}
+
+// String describes the condition, e.g. "typology structuring AND (rule a OR rule b)".
+func (c TypologyCondition) String() string {
+	var parts []string
+	op := " AND "
+	switch {
+	case c.Rule != "":
+		return "rule " + c.Rule
+	case c.Typology != "":
+		return "typology " + c.Typology
+	case len(c.Any) > 0:
+		op = " OR "
+		for _, nested := range c.Any {
+			parts = append(parts, nested.nested())
+		}
+	default:
+		for _, nested := range c.All {
+			parts = append(parts, nested.nested())
+		}
+	}
+	return strings.Join(parts, op)
+}
+
+// nested describes the condition as part of another, in parentheses if it
+// combines several.
+func (c TypologyCondition) nested() string {
+	if len(c.All) > 1 || len(c.Any) > 1 {
+		return "(" + c.String() + ")"
+	}
+	return c.String()
+}
+
 // RuleContribution shows how a single rule contributed to a typology score.
 type RuleContribution struct {
 	RuleID       string  `json:"ruleId"`
@@ -40,6 +108,15 @@ type RuleContribution struct {
 	Contribution float64 `json:"contribution"` // ruleScore * weight
 }
 
+// TypologyContribution shows how another typology contributed to a composite
+// typology's score.
+type TypologyContribution struct {
+	TypologyID    string  `json:"typologyId"`
+	TypologyScore float64 `json:"typologyScore"` // Score of the referenced typology
+	Weight        float64 `json:"weight"`        // Weight in the composite typology
+	Contribution  float64 `json:"contribution"`  // typologyScore * weight
+}
+
 // Predefined typology IDs for default typologies
 const (
 	TypologyAccountTakeover = "typology-account-takeover"
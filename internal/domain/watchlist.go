@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// WatchlistEntry is a party on one of a tenant's watchlists. An entry names
+// the party by ID, account ID or name; at least one is required. Country,
+// when set, narrows name matches to parties from that country.
+type WatchlistEntry struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenantId"`
+	ListType  string    `json:"listType"` // WatchlistSanctions, WatchlistBlocklist or WatchlistInternal
+	PartyID   string    `json:"partyId,omitempty"`
+	AccountID string    `json:"accountId,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Country   string    `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Watchlist types.
+const (
+	WatchlistSanctions = "sanctions" // Sanctions lists, e.g. OFAC SDN
+	WatchlistBlocklist = "blocklist" // Parties the tenant refuses to deal with
+	WatchlistInternal  = "watchlist" // Parties under internal monitoring
+)
+
+// WatchlistTypes lists the valid watchlist types.
+var WatchlistTypes = []string{WatchlistSanctions, WatchlistBlocklist, WatchlistInternal}
+
+// ValidWatchlistType reports whether t is a known watchlist type.
+func ValidWatchlistType(t string) bool {
+	for _, listType := range WatchlistTypes {
+		if t == listType {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchlistSubject is a transaction party checked against the watchlists.
+type WatchlistSubject struct {
+	PartyID   string
+	AccountID string
+	Name      string
+	Country   string
+}
+
+// NormalizeWatchlistName folds case and whitespace so names match however
+// they were typed.
+func NormalizeWatchlistName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
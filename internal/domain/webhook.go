@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook is a tenant-configured URL that receives ALRT evaluations, or
+// every decision when subscribed to the decisions feed.
+type Webhook struct {
+	ID        string         `json:"id"`
+	TenantID  string         `json:"tenantId"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"secret,omitempty"` // HMAC key; only returned when the webhook is created
+	Feed      string         `json:"feed"`             // WebhookFeedAlerts or WebhookFeedDecisions
+	Filter    *WebhookFilter `json:"filter,omitempty"`
+	BatchSize int            `json:"batchSize,omitempty"` // decisions per request on the decisions feed
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// Webhook feeds.
+const (
+	WebhookFeedAlerts    = "alerts"    // ALRT evaluations, one per request
+	WebhookFeedDecisions = "decisions" // every evaluation, batched
+)
+
+// DefaultWebhookBatchSize and MaxWebhookBatchSize bound the decisions sent
+// in one request on the decisions feed.
+const (
+	DefaultWebhookBatchSize = 100
+	MaxWebhookBatchSize     = 1000
+)
+
+// WebhookFilter narrows the evaluations a webhook receives. Empty fields
+// match everything.
+type WebhookFilter struct {
+	Statuses   []string `json:"statuses,omitempty"`   // e.g. ["NALT"]
+	MinScore   *float64 `json:"minScore,omitempty"`   // inclusive
+	MaxScore   *float64 `json:"maxScore,omitempty"`   // inclusive
+	Typologies []string `json:"typologies,omitempty"` // any of these typologies triggered
+}
+
+// Matches reports whether an evaluation passes the filter. A nil filter
+// matches every evaluation.
+func (f *WebhookFilter) Matches(eval *Evaluation) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, eval.Status) {
+		return false
+	}
+	if f.MinScore != nil && eval.Score < *f.MinScore {
+		return false
+	}
+	if f.MaxScore != nil && eval.Score > *f.MaxScore {
+		return false
+	}
+	if len(f.Typologies) > 0 {
+		for _, t := range eval.TypologyResults {
+			if t.Triggered && slices.Contains(f.Typologies, t.TypologyID) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"   // Waiting for its next attempt
+	DeliveryDelivered = "delivered" // The endpoint answered 2xx
+	DeliveryFailed    = "failed"    // Out of attempts, or the webhook was deleted
+)
+
+// Webhook event types.
+const (
+	WebhookEventAlert    = "evaluation.alert"
+	WebhookEventDecision = "evaluation.decision"
+	WebhookEventDigest   = "alert.digest"
+)
+
+// WebhookDigest is a tenant's digest policy: alerts it covers are not sent
+// one by one but summarized once per Interval.
+type WebhookDigest struct {
+	Interval   time.Duration `json:"interval"`
+	Typologies []string      `json:"typologies,omitempty"` // low-urgency typologies; empty covers every alert
+}
+
+// Covers reports whether an alert belongs in the digest: every typology it
+// triggered is a low-urgency one. Without typologies every alert is covered.
+func (d WebhookDigest) Covers(eval *Evaluation) bool {
+	if len(d.Typologies) == 0 {
+		return true
+	}
+	triggered := false
+	for _, t := range eval.TypologyResults {
+		if !t.Triggered {
+			continue
+		}
+		if !slices.Contains(d.Typologies, t.TypologyID) {
+			return false
+		}
+		triggered = true
+	}
+	return triggered
+}
+
+// DigestEntry is the part of an alert kept for its digest.
+type DigestEntry struct {
+	EvaluationID string   `json:"evaluationId"`
+	TxID         string   `json:"txId"`
+	Score        float64  `json:"score"`
+	Rules        []string `json:"rules,omitempty"`      // rules that failed or asked for review
+	Typologies   []string `json:"typologies,omitempty"` // typologies triggered
+	Entities     []string `json:"entities,omitempty"`   // debtor and creditor, when the transaction is stored
+}
+
+// WebhookDigestSummary is the body of an alert.digest delivery.
+type WebhookDigestSummary struct {
+	TenantID    string        `json:"tenantId"`
+	From        time.Time     `json:"from"` // first alert in the digest
+	To          time.Time     `json:"to"`   // last alert in the digest
+	Alerts      int           `json:"alerts"`
+	ByRule      []DigestCount `json:"byRule"`
+	ByTypology  []DigestCount `json:"byTypology"`
+	TopEntities []DigestCount `json:"topEntities"`
+	Evaluations []string      `json:"evaluations"`
+}
+
+// DigestCount is how many alerts in a digest share a rule, typology or
+// entity.
+type DigestCount struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// WebhookDelivery is one event sent to one webhook, including its retries.
+type WebhookDelivery struct {
+	ID             string     `json:"id"`
+	TenantID       string     `json:"tenantId"`
+	WebhookID      string     `json:"webhookId"`
+	EvaluationID   string     `json:"evaluationId"`
+	Event          string     `json:"event"`
+	Payload        []byte     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WebhookConfig is the delivery and retry policy for webhooks.
+type WebhookConfig struct {
+	// MaxAttempts is how many times a delivery is tried before it fails.
+	MaxAttempts int `json:"maxAttempts"`
+
+	// InitialBackoff is the wait before the first retry; each retry doubles
+	// it, up to MaxBackoff.
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"`
+
+	// Timeout bounds each HTTP request.
+	Timeout time.Duration `json:"timeout"`
+
+	// CheckInterval is how often due retries are looked for. New alerts are
+	// delivered right away.
+	CheckInterval time.Duration `json:"checkInterval"`
+
+	// Digests summarizes low-urgency alerts per tenant instead of sending
+	// them one by one. "*" applies to tenants without their own entry.
+	Digests map[string]WebhookDigest `json:"digests"`
+}
@@ -0,0 +1,81 @@
+// Package evalcache serves stored evaluations from the cache, so the repeated
+// reads of case management UIs don't reach the database.
+//
+// Evaluations are read through the cache under the tenant and evaluation ID.
+// Saving an evaluation drops its cached copy, so one stored again under the
+// same ID, as a re-evaluation may do, is never served stale. Purged
+// evaluations can still be served until their cached copy expires.
+package evalcache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// DefaultTTL is how long an evaluation is cached after it was read.
+const DefaultTTL = 5 * time.Minute
+
+// CacheKey returns the cache key of an evaluation.
+func CacheKey(evalID string) string {
+	return "evaluation:" + evalID
+}
+
+// Repository reads evaluations through the cache.
+type Repository struct {
+	domain.Repository
+	cache domain.Cache
+	ttl   time.Duration
+}
+
+// Wrap returns repo with evaluations read through cache. A zero TTL uses
+// DefaultTTL.
+func Wrap(repo domain.Repository, cache domain.Cache, ttl time.Duration) *Repository {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Repository{Repository: repo, cache: cache, ttl: ttl}
+}
+
+// Unwrap returns the repository read behind the cache.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// GetEvaluation returns the cached evaluation, or reads it from the wrapped
+// repository and caches it. A failing cache only costs the database read.
+func (r *Repository) GetEvaluation(ctx context.Context, tenantID string, evalID string) (*domain.Evaluation, error) {
+	data, err := r.cache.Get(ctx, tenantID, CacheKey(evalID))
+	if err != nil {
+		domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+	} else if data != nil {
+		var eval domain.Evaluation
+		if err := json.Unmarshal(data, &eval); err == nil {
+			return &eval, nil
+		}
+	}
+
+	eval, err := r.Repository.GetEvaluation(ctx, tenantID, evalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(eval); err == nil {
+		_ = r.cache.Set(ctx, tenantID, CacheKey(evalID), data, r.ttl)
+	}
+	return eval, nil
+}
+
+// SaveEvaluation saves the evaluation and drops its cached copy.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if err := r.Repository.SaveEvaluation(ctx, tenantID, eval); err != nil {
+		return err
+	}
+	if err := r.cache.Delete(ctx, tenantID, CacheKey(eval.ID)); err != nil {
+		slog.Warn("failed to invalidate cached evaluation", "tenant_id", tenantID, "evaluation_id", eval.ID, "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package evalcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	lruCache := cache.NewLRUCache(100)
+	defer lruCache.Close()
+	repo := Wrap(base, lruCache, 0)
+
+	if err := repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-1", TxID: "tx-1", Status: domain.StatusAlert, Score: 0.9}); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	if _, err := repo.GetEvaluation(ctx, "tenant-001", "eval-1"); err != nil {
+		t.Fatalf("GetEvaluation failed: %v", err)
+	}
+
+	// Served from the cache while the database is down
+	base.SetError(errors.New("database down"))
+	eval, err := repo.GetEvaluation(ctx, "tenant-001", "eval-1")
+	if err != nil {
+		t.Fatalf("expected the cached evaluation, got %v", err)
+	}
+	if eval.TxID != "tx-1" || eval.Status != domain.StatusAlert || eval.Score != 0.9 {
+		t.Errorf("unexpected cached evaluation: %+v", eval)
+	}
+	if _, err := repo.GetEvaluation(ctx, "tenant-002", "eval-1"); err == nil {
+		t.Error("expected another tenant not to read the cached evaluation")
+	}
+	base.SetError(nil)
+
+	if _, err := repo.GetEvaluation(ctx, "tenant-001", "eval-missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	t.Run("SaveInvalidates", func(t *testing.T) {
+		lruCache.Set(ctx, "tenant-001", CacheKey("eval-2"), []byte(`{"id":"eval-2","status":"NALT"}`), DefaultTTL)
+		if err := repo.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-2", Status: domain.StatusAlert}); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+		eval, err := repo.GetEvaluation(ctx, "tenant-001", "eval-2")
+		if err != nil {
+			t.Fatalf("GetEvaluation failed: %v", err)
+		}
+		if eval.Status != domain.StatusAlert {
+			t.Errorf("expected the stored evaluation, not the stale cached one, got %s", eval.Status)
+		}
+	})
+
+	t.Run("CacheFailureDegrades", func(t *testing.T) {
+		failing := Wrap(base, failingCache{lruCache}, 0)
+
+		dctx, degradations := domain.WithDegradations(ctx)
+		if _, err := failing.GetEvaluation(dctx, "tenant-001", "eval-1"); err != nil {
+			t.Fatalf("expected the evaluation from the repository, got %v", err)
+		}
+		got := degradations.List()
+		if len(got) != 1 || got[0].Component != domain.DegradedCache || got[0].Status != domain.DegradationFailed {
+			t.Errorf("expected failed cache degradation, got %v", got)
+		}
+	})
+}
+
+// failingCache is a cache whose reads always fail.
+type failingCache struct {
+	domain.Cache
+}
+
+func (failingCache) Get(ctx context.Context, tenantID string, key string) ([]byte, error) {
+	return nil, errors.New("cache unavailable")
+}
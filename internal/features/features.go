@@ -0,0 +1,236 @@
+// Package features gates experimental subsystems behind per-tenant feature flags.
+//
+// A flag's effective value for a tenant is resolved in order: the tenant's own
+// override, the install-wide override (tenant "*"), the configured default, and
+// finally the flag's built-in default.
+package features
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// GlobalTenantID holds install-wide overrides.
+const GlobalTenantID = "*"
+
+// cacheTTL bounds how long overrides are cached, so changes made through
+// another instance propagate without a restart.
+const cacheTTL = 30 * time.Second
+
+// Known feature flags.
+const (
+	MLHook           = "ml_hook"
+	GraphFeatures    = "graph_features"
+	CanaryRules      = "canary_rules"
+	Benchmarking     = "benchmarking"
+	StrictTypologies = "strict_typologies"
+)
+
+// Definition describes a known feature flag.
+type Definition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Registry lists every known flag. Unknown names are rejected so typos can't
+// silently create flags that nothing reads.
+var Registry = []Definition{
+	{Name: MLHook, Description: "Call an external ML model during evaluation"},
+	{Name: GraphFeatures, Description: "Expose transaction graph features to rules"},
+	{Name: CanaryRules, Description: "Evaluate canary rules alongside live rules"},
+	{Name: Benchmarking, Description: "Share anonymized metrics with, and compare against, tenants of similar volume"},
+	{Name: StrictTypologies, Description: "Reject typologies whose rules don't exist or whose weights don't sum to 1.0"},
+}
+
+// Lookup returns the definition for a flag name.
+func Lookup(name string) (Definition, bool) {
+	for _, def := range Registry {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Sources describing where a flag's effective value came from.
+const (
+	SourceDefault = "default"
+	SourceConfig  = "config"
+	SourceGlobal  = "global"
+	SourceTenant  = "tenant"
+)
+
+// State is a flag's effective value for a tenant.
+type State struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      string `json:"source"`
+}
+
+type cacheEntry struct {
+	flags  map[string]bool
+	loaded time.Time
+}
+
+// Service resolves feature flags from stored overrides and configured defaults.
+type Service struct {
+	repo     domain.Repository
+	defaults map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry // tenantID -> overrides
+}
+
+// NewService creates a feature flag service. defaults holds configured values by
+// flag name; unknown names are ignored with a warning.
+func NewService(repo domain.Repository, defaults map[string]bool) *Service {
+	known := make(map[string]bool, len(defaults))
+	for name, enabled := range defaults {
+		if _, ok := Lookup(name); !ok {
+			slog.Warn("ignoring unknown feature flag in config", "name", name)
+			continue
+		}
+		known[name] = enabled
+	}
+
+	return &Service{
+		repo:     repo,
+		defaults: known,
+		cache:    make(map[string]cacheEntry),
+	}
+}
+
+// ParseDefaults parses "name=true,name=false" into a defaults map.
+func ParseDefaults(s string) (map[string]bool, error) {
+	defaults := make(map[string]bool)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature flag %q (want name=true|false)", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for feature flag %q: %w", name, err)
+		}
+		defaults[strings.TrimSpace(name)] = enabled
+	}
+	return defaults, nil
+}
+
+// Enabled reports whether a flag is on for a tenant. Storage errors fall back
+// to the configured default so a database blip never flips features on.
+func (s *Service) Enabled(ctx context.Context, tenantID, name string) bool {
+	def, ok := Lookup(name)
+	if !ok {
+		return false
+	}
+	return s.resolve(ctx, tenantID, def).Enabled
+}
+
+// States returns the effective value of every known flag for a tenant.
+func (s *Service) States(ctx context.Context, tenantID string) []State {
+	states := make([]State, 0, len(Registry))
+	for _, def := range Registry {
+		states = append(states, s.resolve(ctx, tenantID, def))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Set stores an override for a tenant, or install-wide for GlobalTenantID.
+func (s *Service) Set(ctx context.Context, tenantID, name string, enabled bool) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("unknown feature flag %q", name)
+	}
+	if s.repo == nil {
+		return fmt.Errorf("repository not available")
+	}
+
+	if err := s.repo.SaveFeatureFlag(ctx, tenantID, &domain.FeatureFlag{Name: name, Enabled: enabled}); err != nil {
+		return err
+	}
+	s.invalidate(tenantID)
+	return nil
+}
+
+// Clear removes an override so the next source in the resolution order applies.
+func (s *Service) Clear(ctx context.Context, tenantID, name string) error {
+	if s.repo == nil {
+		return fmt.Errorf("repository not available")
+	}
+	if err := s.repo.DeleteFeatureFlag(ctx, tenantID, name); err != nil {
+		return err
+	}
+	s.invalidate(tenantID)
+	return nil
+}
+
+// resolve applies the resolution order for one flag.
+func (s *Service) resolve(ctx context.Context, tenantID string, def Definition) State {
+	state := State{Name: def.Name, Description: def.Description, Enabled: def.Default, Source: SourceDefault}
+
+	if enabled, ok := s.defaults[def.Name]; ok {
+		state.Enabled, state.Source = enabled, SourceConfig
+	}
+	if enabled, ok := s.overrides(ctx, GlobalTenantID)[def.Name]; ok {
+		state.Enabled, state.Source = enabled, SourceGlobal
+	}
+	if tenantID != GlobalTenantID {
+		if enabled, ok := s.overrides(ctx, tenantID)[def.Name]; ok {
+			state.Enabled, state.Source = enabled, SourceTenant
+		}
+	}
+
+	return state
+}
+
+// overrides returns a tenant's stored overrides through the cache.
+func (s *Service) overrides(ctx context.Context, tenantID string) map[string]bool {
+	if s.repo == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	entry, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && time.Since(entry.loaded) < cacheTTL {
+		return entry.flags
+	}
+
+	stored, err := s.repo.ListFeatureFlags(ctx, tenantID)
+	if err != nil {
+		slog.Warn("failed to load feature flags", "tenant_id", tenantID, "error", err)
+		return entry.flags // Keep serving the last known values
+	}
+
+	flags := make(map[string]bool, len(stored))
+	for _, flag := range stored {
+		flags[flag.Name] = flag.Enabled
+	}
+
+	s.mu.Lock()
+	s.cache[tenantID] = cacheEntry{flags: flags, loaded: time.Now()}
+	s.mu.Unlock()
+
+	return flags
+}
+
+func (s *Service) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.cache, tenantID)
+	s.mu.Unlock()
+}
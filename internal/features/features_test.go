@@ -0,0 +1,100 @@
+package features
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+func TestParseDefaults(t *testing.T) {
+	defaults, err := ParseDefaults("ml_hook=true, canary_rules=false")
+	if err != nil {
+		t.Fatalf("ParseDefaults failed: %v", err)
+	}
+	if !defaults[MLHook] || defaults[CanaryRules] {
+		t.Errorf("unexpected defaults: %v", defaults)
+	}
+
+	for _, bad := range []string{"ml_hook", "ml_hook=maybe"} {
+		if _, err := ParseDefaults(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestService(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "features-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	repo, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	svc := NewService(repo, map[string]bool{GraphFeatures: true, "unknown": true})
+	ctx := context.Background()
+
+	t.Run("Defaults", func(t *testing.T) {
+		if svc.Enabled(ctx, "tenant-001", MLHook) {
+			t.Error("expected ml_hook off by default")
+		}
+		if !svc.Enabled(ctx, "tenant-001", GraphFeatures) {
+			t.Error("expected graph_features on from config")
+		}
+		if svc.Enabled(ctx, "tenant-001", "unknown") {
+			t.Error("expected unknown flag to be off")
+		}
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		if err := svc.Set(ctx, GlobalTenantID, MLHook, true); err != nil {
+			t.Fatalf("Set global failed: %v", err)
+		}
+		if err := svc.Set(ctx, "tenant-001", MLHook, false); err != nil {
+			t.Fatalf("Set tenant failed: %v", err)
+		}
+
+		if svc.Enabled(ctx, "tenant-001", MLHook) {
+			t.Error("expected tenant override to win over global")
+		}
+		if !svc.Enabled(ctx, "tenant-002", MLHook) {
+			t.Error("expected global override for tenant without its own")
+		}
+
+		for _, state := range svc.States(ctx, "tenant-002") {
+			if state.Name == MLHook && state.Source != SourceGlobal {
+				t.Errorf("expected source %q, got %q", SourceGlobal, state.Source)
+			}
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		if err := svc.Clear(ctx, "tenant-001", MLHook); err != nil {
+			t.Fatalf("Clear failed: %v", err)
+		}
+		if !svc.Enabled(ctx, "tenant-001", MLHook) {
+			t.Error("expected global override after clearing tenant override")
+		}
+		if err := svc.Clear(ctx, "tenant-001", MLHook); err != repository.ErrNotFound {
+			t.Errorf("expected ErrNotFound on second clear, got: %v", err)
+		}
+	})
+
+	t.Run("UnknownFlag", func(t *testing.T) {
+		if err := svc.Set(ctx, "tenant-001", "ml-hook", true); err == nil {
+			t.Error("expected error for unknown flag")
+		}
+	})
+}
@@ -0,0 +1,208 @@
+// Package fx converts transaction amounts to each tenant's base currency and
+// exposes them to rules as amount_base and currency_base, so one threshold
+// holds for amounts in any currency: without it €50,000 and ¥50,000 trip the
+// same "amount > 10000" rule.
+package fx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// Rates quotes currencies against a base: Rates["JPY"] = 151 means one unit
+// of Base buys 151 yen.
+type Rates struct {
+	Base  string
+	Rates map[string]float64
+	AsOf  time.Time // Zero when the source doesn't say
+}
+
+// rate returns the units of currency per one Base.
+func (r *Rates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// Convert converts amount from one currency to another through the base. It
+// reports false when either currency has no rate.
+func (r *Rates) Convert(amount float64, from, to string) (float64, bool) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return amount, true
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, false
+	}
+	return amount / fromRate * toRate, true
+}
+
+// Normalize returns a currency code in the form rates are keyed by.
+func Normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// Service holds the latest rates of a source.
+type Service struct {
+	cfg    domain.FXConfig
+	source Source
+	rates  atomic.Pointer[Rates]
+}
+
+// NewService returns a service for the configured source, or nil when
+// conversion is disabled.
+func NewService(cfg domain.FXConfig) (*Service, error) {
+	source, err := NewSource(cfg)
+	if err != nil || source == nil {
+		return nil, err
+	}
+	return &Service{cfg: cfg, source: source}, nil
+}
+
+// Enabled reports whether the service converts amounts.
+func (s *Service) Enabled() bool {
+	return s != nil
+}
+
+// Refresh fetches the source's rates. On failure the previous rates stay.
+func (s *Service) Refresh(ctx context.Context) error {
+	rates, err := s.source.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s rates: %w", s.source.Name(), err)
+	}
+	s.rates.Store(rates)
+	return nil
+}
+
+// Run refreshes the rates every cfg.Refresh until ctx is cancelled. Static
+// rates never change, so it returns at once for them.
+func (s *Service) Run(ctx context.Context) {
+	if s.source.Name() == domain.FXSourceStatic || s.cfg.Refresh <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.Refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Refresh(ctx); err != nil {
+			slog.Error("FX rate refresh failed", "source", s.source.Name(), "error", err)
+		}
+	}
+}
+
+// Rates returns the latest rates, or nil before the first successful fetch.
+func (s *Service) Rates() *Rates {
+	return s.rates.Load()
+}
+
+// ToBase converts an amount to the tenant's base currency, returning the
+// converted amount and the base.
+func (s *Service) ToBase(tenantID string, amount float64, currency string) (float64, string, error) {
+	base := Normalize(s.cfg.Base(tenantID))
+	rates := s.rates.Load()
+	if rates == nil {
+		return 0, base, fmt.Errorf("no %s rates loaded", s.source.Name())
+	}
+	converted, ok := rates.Convert(amount, currency, base)
+	if !ok {
+		return 0, base, fmt.Errorf("no rate from %s to %s", Normalize(currency), base)
+	}
+	return converted, base, nil
+}
+
+// Enricher returns a rules.Enricher exposing amount_base and currency_base
+// to CEL. When the amount can't be converted they hold the transaction's own
+// amount and currency, and the evaluation is reported as degraded.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "fx"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"amount_base":   cel.DoubleType,
+		"currency_base": cel.StringType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["amount_base"] = input.Amount.Float64()
+	activation["currency_base"] = Normalize(input.Currency)
+
+	amount, base, err := e.svc.ToBase(input.TenantID, input.Amount.Float64(), input.Currency)
+	if err != nil {
+		return err
+	}
+	activation["amount_base"] = amount
+	activation["currency_base"] = base
+	return nil
+}
+
+// ParseRates parses rates of the form "EUR=0.92,JPY=151".
+func ParseRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		currency, value, ok := strings.Cut(entry, "=")
+		currency = Normalize(currency)
+		if !ok || currency == "" {
+			return nil, fmt.Errorf("invalid rate %q (want currency=rate)", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate for %s: %q", currency, value)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// ParseTenantBaseCurrencies parses base currencies of the form
+// "acme=EUR,globex=JPY".
+func ParseTenantBaseCurrencies(s string) (map[string]string, error) {
+	bases := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, currency, ok := strings.Cut(entry, "=")
+		tenantID, currency = strings.TrimSpace(tenantID), Normalize(currency)
+		if !ok || tenantID == "" || len(currency) != 3 {
+			return nil, fmt.Errorf("invalid tenant base currency %q (want tenant=currency)", entry)
+		}
+		bases[tenantID] = currency
+	}
+	return bases, nil
+}
@@ -0,0 +1,138 @@
+package fx
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+func TestToBase(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(domain.FXConfig{
+		Source:               domain.FXSourceStatic,
+		BaseCurrency:         "USD",
+		Rates:                map[string]float64{"eur": 0.8, "JPY": 160},
+		TenantBaseCurrencies: map[string]string{"tenant-eu": "EUR"},
+	})
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+	if _, _, err := svc.ToBase("tenant-001", 1, "EUR"); err == nil {
+		t.Error("expected an error before the rates are loaded")
+	}
+	if err := svc.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		tenantID string
+		amount   float64
+		currency string
+		want     float64
+		base     string
+	}{
+		{"tenant-001", 50000, "EUR", 62500, "USD"},
+		{"tenant-001", 50000, "jpy", 312.5, "USD"},
+		{"tenant-001", 100, "USD", 100, "USD"},
+		{"tenant-eu", 16000, "JPY", 80, "EUR"},
+		{"tenant-eu", 100, "USD", 80, "EUR"},
+	} {
+		got, base, err := svc.ToBase(tc.tenantID, tc.amount, tc.currency)
+		if err != nil || base != tc.base || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("ToBase(%s, %v %s) = %v %s, %v; want %v %s", tc.tenantID, tc.amount, tc.currency, got, base, err, tc.want, tc.base)
+		}
+	}
+
+	activation := map[string]any{}
+	err = svc.Enricher().Enrich(ctx, &rules.EvaluateInput{TenantID: "tenant-001", Amount: domain.MustDecimal("75"), Currency: "CHF"}, activation)
+	if err == nil || activation["amount_base"] != 75.0 || activation["currency_base"] != "CHF" {
+		t.Errorf("expected the unconverted amount and an error for a currency without a rate, got %v, %v", activation, err)
+	}
+}
+
+func TestSources(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ecb":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-01-02">
+			<Cube currency="USD" rate="1.25"/>
+			<Cube currency="JPY" rate="200"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`))
+		case "/api":
+			w.Write([]byte(`{"base": "usd", "date": "2026-01-02", "rates": {"EUR": 0.8}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ecb, err := NewSource(domain.FXConfig{Source: domain.FXSourceECB, URL: server.URL + "/ecb"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	rates, err := ecb.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("ECB Fetch failed: %v", err)
+	}
+	if rates.Base != "EUR" || rates.AsOf.Format("2006-01-02") != "2026-01-02" {
+		t.Errorf("expected euro rates of 2026-01-02, got %+v", rates)
+	}
+	if got, ok := rates.Convert(200, "JPY", "USD"); !ok || got != 1.25 {
+		t.Errorf("expected 200 JPY to be 1.25 USD, got %v %v", got, ok)
+	}
+
+	api, err := NewSource(domain.FXConfig{Source: domain.FXSourceAPI, URL: server.URL + "/api"})
+	if err != nil {
+		t.Fatalf("NewSource failed: %v", err)
+	}
+	rates, err = api.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("API Fetch failed: %v", err)
+	}
+	if got, ok := rates.Convert(8, "EUR", "USD"); !ok || got != 10 {
+		t.Errorf("expected 8 EUR to be 10 USD, got %v %v", got, ok)
+	}
+
+	failing, _ := NewSource(domain.FXConfig{Source: domain.FXSourceAPI, URL: server.URL + "/down"})
+	if _, err := failing.Fetch(ctx); err == nil {
+		t.Error("expected an error from a failing endpoint")
+	}
+	if _, err := NewSource(domain.FXConfig{Source: "oanda"}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+	if svc, err := NewService(domain.FXConfig{}); err != nil || svc.Enabled() {
+		t.Errorf("expected no service without a source, got %v, %v", svc, err)
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(" eur=0.92, JPY=151 ")
+	if err != nil || len(rates) != 2 || rates["EUR"] != 0.92 || rates["JPY"] != 151 {
+		t.Errorf("expected two rates, got %v, %v", rates, err)
+	}
+	for _, invalid := range []string{"EUR", "EUR=abc", "EUR=0", "=1"} {
+		if _, err := ParseRates(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+
+	bases, err := ParseTenantBaseCurrencies("acme=eur,globex=JPY")
+	if err != nil || bases["acme"] != "EUR" || bases["globex"] != "JPY" {
+		t.Errorf("expected two base currencies, got %v, %v", bases, err)
+	}
+	if _, err := ParseTenantBaseCurrencies("acme=euro"); err == nil {
+		t.Error("expected a malformed currency to be rejected")
+	}
+}
@@ -0,0 +1,181 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// ECBURL is the European Central Bank's feed of daily reference rates.
+const ECBURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// fetchTimeout bounds one request for rates.
+const fetchTimeout = 10 * time.Second
+
+// maxFeedBytes caps the size of a fetched rate feed.
+const maxFeedBytes = 1 << 20
+
+// Source supplies exchange rates.
+type Source interface {
+	// Name identifies the source in logs and status.
+	Name() string
+
+	// Fetch returns the current rates.
+	Fetch(ctx context.Context) (*Rates, error)
+}
+
+// NewSource returns the source cfg names, or nil when conversion is disabled.
+func NewSource(cfg domain.FXConfig) (Source, error) {
+	switch cfg.Source {
+	case "":
+		return nil, nil
+	case domain.FXSourceStatic:
+		if len(cfg.Rates) == 0 {
+			return nil, fmt.Errorf("the static FX source needs rates")
+		}
+		return NewStatic(cfg.BaseCurrency, cfg.Rates), nil
+	case domain.FXSourceECB:
+		url := cfg.URL
+		if url == "" {
+			url = ECBURL
+		}
+		return &ecbSource{url: url, client: &http.Client{Timeout: fetchTimeout}}, nil
+	case domain.FXSourceAPI:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("the api FX source needs a URL")
+		}
+		return &apiSource{url: cfg.URL, client: &http.Client{Timeout: fetchTimeout}}, nil
+	}
+	return nil, fmt.Errorf("unknown FX source %q (want %s, %s or %s)", cfg.Source, domain.FXSourceStatic, domain.FXSourceECB, domain.FXSourceAPI)
+}
+
+// Static is a fixed table of rates.
+type Static struct {
+	rates *Rates
+}
+
+// NewStatic returns a source of fixed rates, in units of each currency per
+// one base.
+func NewStatic(base string, rates map[string]float64) *Static {
+	table := &Rates{Base: Normalize(base), Rates: make(map[string]float64, len(rates))}
+	for currency, rate := range rates {
+		table.Rates[Normalize(currency)] = rate
+	}
+	return &Static{rates: table}
+}
+
+func (s *Static) Name() string {
+	return domain.FXSourceStatic
+}
+
+func (s *Static) Fetch(ctx context.Context) (*Rates, error) {
+	return s.rates, nil
+}
+
+// ecbSource reads the ECB's daily reference rates, quoted against the euro.
+type ecbSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *ecbSource) Name() string {
+	return domain.FXSourceECB
+}
+
+// ecbFeed is the part of the ECB feed holding the rates:
+// <Cube><Cube time="2026-01-02"><Cube currency="USD" rate="1.0876"/>...
+type ecbFeed struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func (s *ecbSource) Fetch(ctx context.Context) (*Rates, error) {
+	body, err := get(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed ecbFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("invalid ECB feed: %w", err)
+	}
+	if len(feed.Days) == 0 {
+		return nil, fmt.Errorf("ECB feed has no rates")
+	}
+
+	day := feed.Days[0]
+	rates := &Rates{Base: "EUR", Rates: make(map[string]float64, len(day.Rates))}
+	rates.AsOf, _ = time.Parse(time.DateOnly, day.Time)
+	for _, r := range day.Rates {
+		rate, err := strconv.ParseFloat(r.Rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB rate for %s: %q", r.Currency, r.Rate)
+		}
+		rates.Rates[Normalize(r.Currency)] = rate
+	}
+	return rates, nil
+}
+
+// apiSource reads rates from a JSON endpoint answering
+// {"base": "EUR", "date": "2026-01-02", "rates": {"USD": 1.0876, ...}}.
+type apiSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *apiSource) Name() string {
+	return domain.FXSourceAPI
+}
+
+func (s *apiSource) Fetch(ctx context.Context) (*Rates, error) {
+	body, err := get(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid FX API response: %w", err)
+	}
+	if resp.Base == "" || len(resp.Rates) == 0 {
+		return nil, fmt.Errorf("FX API response has no base or rates")
+	}
+
+	rates := NewStatic(resp.Base, resp.Rates).rates
+	rates.AsOf, _ = time.Parse(time.DateOnly, resp.Date)
+	return rates, nil
+}
+
+// get fetches a rate feed.
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+}
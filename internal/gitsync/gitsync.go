@@ -0,0 +1,246 @@
+// Package gitsync applies rules and typologies from a Git repository.
+//
+// When enabled, the repository is the source of truth: each sync fetches the
+// configured branch and applies every file under <path>/rules and
+// <path>/typologies as a state.Spec, so an invalid file rejects the whole
+// commit. A changed rule or typology is
+// saved with the commit SHA as semver build metadata on its version, e.g.
+// "1.2.0+3f2c9ab1e0d4", so every loaded version can be traced to a commit.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/state"
+)
+
+// Status describes the most recent sync.
+type Status struct {
+	Repo      string      `json:"repo"`
+	Branch    string      `json:"branch,omitempty"`
+	Commit    string      `json:"commit,omitempty"` // last applied commit
+	SyncedAt  *time.Time  `json:"syncedAt,omitempty"`
+	CheckedAt *time.Time  `json:"checkedAt,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Changes   *state.Plan `json:"changes,omitempty"` // applied from Commit
+}
+
+// Syncer polls a Git repository and applies its rules and typologies.
+type Syncer struct {
+	cfg     domain.GitSyncConfig
+	state   *state.Manager
+	trigger chan struct{}
+
+	syncMu sync.Mutex // serializes syncs
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewSyncer creates a syncer for cfg that applies commits through mgr.
+// It does nothing until Sync or Run.
+func NewSyncer(cfg domain.GitSyncConfig, mgr *state.Manager) *Syncer {
+	return &Syncer{
+		cfg:     cfg,
+		state:   mgr,
+		trigger: make(chan struct{}, 1),
+		status:  Status{Repo: cfg.Repo, Branch: cfg.Branch},
+	}
+}
+
+// Enabled reports whether Git sync is configured. It is safe on a nil Syncer.
+func (s *Syncer) Enabled() bool {
+	return s != nil && s.cfg.Repo != ""
+}
+
+// Status returns the result of the most recent sync.
+func (s *Syncer) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Trigger requests a sync from Run without waiting for it.
+func (s *Syncer) Trigger() {
+	select {
+	case s.trigger <- struct{}{}:
+	default: // a sync is already pending
+	}
+}
+
+// Run syncs every Interval, and whenever triggered, until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if s.cfg.Interval > 0 {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-s.trigger:
+		}
+		if _, err := s.Sync(ctx); err != nil {
+			slog.Error("git sync failed", "repo", s.cfg.Repo, "error", err)
+		}
+	}
+}
+
+// Sync fetches the branch and applies it if the commit hasn't been applied
+// yet. Invalid files leave the current configuration untouched.
+func (s *Syncer) Sync(ctx context.Context) (Status, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	commit, plan, err := s.sync(ctx)
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.CheckedAt = &now
+	s.status.Error = ""
+	if err != nil {
+		s.status.Error = err.Error()
+	} else if plan != nil {
+		s.status.Commit = commit
+		s.status.SyncedAt = &now
+		s.status.Changes = plan
+	}
+	return s.status, err
+}
+
+func (s *Syncer) sync(ctx context.Context) (string, *state.Plan, error) {
+	dir, err := s.checkout(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	commit, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.mu.RLock()
+	applied := s.status.Commit
+	s.mu.RUnlock()
+	if commit == applied {
+		return commit, nil, nil
+	}
+
+	spec, err := state.ReadDir(filepath.Join(dir, s.cfg.Path))
+	if err != nil {
+		return "", nil, err
+	}
+	plan, err := s.state.Plan(ctx, spec)
+	if err != nil {
+		return "", nil, fmt.Errorf("commit %s: %w", shortSHA(commit), err)
+	}
+	plan.SetBuildMetadata(shortSHA(commit))
+	if err := s.state.Apply(ctx, plan); err != nil {
+		return "", nil, err
+	}
+
+	slog.Info("git sync applied",
+		"commit", commit,
+		"rule_changes", len(plan.Rules),
+		"typology_changes", len(plan.Typologies),
+		"unchanged", plan.Unchanged,
+	)
+	return commit, plan, nil
+}
+
+// checkout clones the repository on first use and fetches it afterwards,
+// returning the working directory.
+func (s *Syncer) checkout(ctx context.Context) (string, error) {
+	dir := s.cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "osprey-gitsync")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if s.cfg.Branch != "" {
+			args = append(args, "--branch", s.cfg.Branch)
+		}
+		if _, err := git(ctx, "", append(args, "--", s.cfg.Repo, dir)...); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+
+	ref := s.cfg.Branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := git(ctx, dir, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// git runs a git command and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func shortSHA(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// VerifyWebhook checks a push webhook against the configured secret, using
+// the GitHub X-Hub-Signature-256 HMAC or the GitLab X-Gitlab-Token header.
+// Without a secret every webhook is rejected.
+func (s *Syncer) VerifyWebhook(header http.Header, body []byte) bool {
+	secret := s.cfg.WebhookSecret
+	if secret == "" {
+		return false
+	}
+
+	if sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		want, err := hex.DecodeString(sig)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(mac.Sum(nil), want)
+	}
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package gitsync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+// testRepo is a Git repository of rule and typology files.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "--quiet", "--initial-branch", "main")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes files (a nil value removes the file) and returns the commit SHA.
+func (r *testRepo) commit(files map[string]*string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, name)
+		if content == nil {
+			os.Remove(path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(*content), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git("add", "-A")
+	r.git("commit", "--quiet", "--allow-empty", "-m", "update")
+	return r.git("rev-parse", "HEAD")
+}
+
+func str(s string) *string { return &s }
+
+const highValueRule = `id: high-value
+name: High value
+expression: amount > 10000.0
+bands:
+  - subRuleRef: .pass
+    upperLimit: 1
+  - subRuleRef: .fail
+    lowerLimit: 1
+    reason: Large amount
+`
+
+const roundAmountRule = `id: round-amount
+name: Round amount
+expression: amount > 0.0 && int(amount) % 1000 == 0
+`
+
+const typology = `id: structuring
+name: Structuring
+alertThreshold: 0.5
+rules:
+  - ruleId: high-value
+    weight: 0.6
+  - ruleId: round-amount
+    weight: 0.4
+`
+
+func TestSyncer(t *testing.T) {
+	ctx := context.Background()
+	origin := newTestRepo(t)
+	repo := ospreytest.NewRepository(nil)
+	engine, err := rules.NewEngine(nil, 1)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	typologies := rules.NewTypologyEngine()
+
+	syncer := NewSyncer(domain.GitSyncConfig{
+		Repo:   origin.dir,
+		Branch: "main",
+		Path:   "osprey",
+		Dir:    filepath.Join(t.TempDir(), "checkout"),
+	}, state.NewManager(repo, engine, typologies))
+
+	loadedVersions := func() map[string]string {
+		versions := map[string]string{}
+		for _, rule := range engine.GetLoadedRules() {
+			versions[rule.ID] = rule.Version
+		}
+		return versions
+	}
+
+	first := origin.commit(map[string]*string{
+		"osprey/rules/high-value.yaml":       str(highValueRule),
+		"osprey/rules/round-amount.yml":      str(roundAmountRule),
+		"osprey/typologies/structuring.yaml": str(typology),
+		"README.md":                          str("not a rule"),
+	})
+
+	t.Run("applies a new commit", func(t *testing.T) {
+		status, err := syncer.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if status.Commit != first || status.Error != "" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+		if created := state.IDs(status.Changes.Rules, state.ActionCreate); !reflect.DeepEqual(created, []string{"high-value", "round-amount"}) {
+			t.Errorf("unexpected created rules: %v", created)
+		}
+
+		want := map[string]string{
+			"high-value":   "1.0.0+" + first[:12],
+			"round-amount": "1.0.0+" + first[:12],
+		}
+		if got := loadedVersions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected versions %v, got %v", want, got)
+		}
+		if typologies.TypologyCount() != 1 {
+			t.Errorf("expected 1 typology, got %d", typologies.TypologyCount())
+		}
+	})
+
+	t.Run("same commit is a no-op", func(t *testing.T) {
+		status, err := syncer.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if status.Commit != first || len(status.Changes.Rules) != 2 {
+			t.Errorf("expected status of the first sync, got %+v", status)
+		}
+	})
+
+	t.Run("only changed rules get a new version", func(t *testing.T) {
+		second := origin.commit(map[string]*string{
+			"osprey/rules/high-value.yaml": str(strings.Replace(highValueRule, "10000.0", "5000.0", 1)),
+			"osprey/CHANGELOG":             str("tuned"),
+		})
+		status, err := syncer.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if len(status.Changes.Rules) != 1 || !reflect.DeepEqual(state.IDs(status.Changes.Rules, state.ActionUpdate), []string{"high-value"}) {
+			t.Errorf("unexpected changes: %+v", status.Changes)
+		}
+
+		want := map[string]string{
+			"high-value":   "1.0.0+" + second[:12],
+			"round-amount": "1.0.0+" + first[:12],
+		}
+		if got := loadedVersions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected versions %v, got %v", want, got)
+		}
+		stored, _ := repo.ListRuleConfigs(ctx, state.GlobalTenantID)
+		if len(stored) != 2 {
+			t.Errorf("expected old version to be disabled, got %d enabled rules", len(stored))
+		}
+	})
+
+	t.Run("invalid commit is rejected as a whole", func(t *testing.T) {
+		before := loadedVersions()
+		origin.commit(map[string]*string{
+			"osprey/rules/high-value.yaml": str(strings.Replace(highValueRule, "amount > 10000.0", "amount >", 1)),
+			"osprey/rules/new.yaml":        str("id: new\nname: New\nexpression: amount > 1.0\n"),
+		})
+		status, err := syncer.Sync(ctx)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if status.Error == "" || !strings.Contains(status.Error, "high-value") {
+			t.Errorf("expected status error naming the rule, got %q", status.Error)
+		}
+		if got := loadedVersions(); !reflect.DeepEqual(got, before) {
+			t.Errorf("expected rules unchanged, got %v", got)
+		}
+	})
+
+	t.Run("removed files are disabled", func(t *testing.T) {
+		origin.commit(map[string]*string{
+			"osprey/rules/high-value.yaml":       nil,
+			"osprey/rules/new.yaml":              nil,
+			"osprey/typologies/structuring.yaml": nil,
+		})
+		status, err := syncer.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if !reflect.DeepEqual(state.IDs(status.Changes.Rules, state.ActionDelete), []string{"high-value"}) ||
+			!reflect.DeepEqual(state.IDs(status.Changes.Typologies, state.ActionDelete), []string{"structuring"}) {
+			t.Errorf("unexpected changes: %+v", status.Changes)
+		}
+		if engine.RulesCount() != 1 || typologies.TypologyCount() != 0 {
+			t.Errorf("expected 1 rule and no typologies, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+		}
+	})
+
+	t.Run("typology referencing a missing rule", func(t *testing.T) {
+		origin.commit(map[string]*string{
+			"osprey/typologies/structuring.yaml": str(typology),
+		})
+		if _, err := syncer.Sync(ctx); err == nil || !strings.Contains(err.Error(), "high-value") {
+			t.Errorf("expected missing rule error, got %v", err)
+		}
+	})
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	syncer := NewSyncer(domain.GitSyncConfig{Repo: "x", WebhookSecret: "s3cret"}, nil)
+	cases := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{"github signature", http.Header{"X-Hub-Signature-256": {signature}}, true},
+		{"wrong signature", http.Header{"X-Hub-Signature-256": {"sha256=00"}}, false},
+		{"gitlab token", http.Header{"X-Gitlab-Token": {"s3cret"}}, true},
+		{"wrong token", http.Header{"X-Gitlab-Token": {"nope"}}, false},
+		{"unsigned", http.Header{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := syncer.VerifyWebhook(tc.header, body); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("no secret", func(t *testing.T) {
+		s := NewSyncer(domain.GitSyncConfig{Repo: "x"}, nil)
+		if s.VerifyWebhook(http.Header{"X-Gitlab-Token": {""}}, body) {
+			t.Error("expected webhook to be rejected without a secret")
+		}
+	})
+}
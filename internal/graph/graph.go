@@ -0,0 +1,231 @@
+// Package graph builds each tenant's counterparty network and exposes
+// relationship signals to rules.
+//
+// The network is a store of debtor→creditor edges, updated as transactions
+// are saved. Signals read the edges last seen within the configured window:
+// whether the debtor pays the creditor for the first time, how many
+// counterparties the two share, and whether funds paid to the creditor can
+// flow back to the debtor within a few hops, as in round-tripping and
+// layering.
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// MaxVisitedParties bounds a return path search. A search that reaches it
+// stops and reports no path.
+const MaxVisitedParties = 500
+
+// Repository records the counterparty edge of every transaction it saves.
+type Repository struct {
+	domain.Repository
+}
+
+// Wrap returns repo with the counterparty network kept up to date.
+func Wrap(repo domain.Repository) *Repository {
+	return &Repository{Repository: repo}
+}
+
+// Unwrap returns the repository the edges are recorded alongside.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveTransaction saves a transaction, then adds it to the edge from its
+// debtor to its creditor. Reversals and transfers between a party's own
+// accounts are left out. A failure to record the edge is logged and doesn't
+// fail the save.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if err := r.Repository.SaveTransaction(ctx, tenantID, tx); err != nil {
+		return err
+	}
+	if tx.ReversalOf != "" || tx.DebtorID == "" || tx.CreditorID == "" || tx.DebtorID == tx.CreditorID {
+		return nil
+	}
+	if err := r.Repository.RecordCounterpartyEdge(ctx, tenantID, tx); err != nil {
+		slog.Warn("failed to record counterparty edge", "tenant_id", tenantID, "tx_id", tx.ID, "error", err)
+	}
+	return nil
+}
+
+// Signals are the counterparty network features of a transaction.
+type Signals struct {
+	// FirstSeen is true when this is the debtor's first payment to the
+	// creditor.
+	FirstSeen bool `json:"firstSeen"`
+
+	// SharedCounterparties counts the other parties both the debtor and the
+	// creditor transacted with, in either direction.
+	SharedCounterparties int `json:"sharedCounterparties"`
+
+	// ReturnHops is the number of payments on the shortest path from the
+	// creditor back to the debtor, 1 if the creditor paid the debtor
+	// directly, or 0 without a path within MaxHops.
+	ReturnHops int `json:"returnHops"`
+}
+
+// Service computes counterparty network signals.
+type Service struct {
+	repo domain.Repository
+	cfg  domain.GraphConfig
+	now  func() time.Time
+}
+
+// NewService creates a graph service. A zero window or hop limit uses the
+// defaults.
+func NewService(repo domain.Repository, cfg domain.GraphConfig) *Service {
+	defaults := domain.DefaultConfig().Graph
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.MaxHops <= 0 {
+		cfg.MaxHops = defaults.MaxHops
+	}
+	return &Service{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Signals computes the signals of a payment from debtorID to creditorID. The
+// transaction is expected to be stored already, so its own edge is counted.
+func (s *Service) Signals(ctx context.Context, tenantID, debtorID, creditorID string) (*Signals, error) {
+	if tenantID == "" || debtorID == "" || creditorID == "" {
+		return nil, fmt.Errorf("tenantID, debtorID and creditorID are required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	signals := &Signals{FirstSeen: true}
+	edge, err := s.repo.GetCounterpartyEdge(ctx, tenantID, debtorID, creditorID)
+	switch {
+	case err == nil:
+		signals.FirstSeen = edge.Count <= 1
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, fmt.Errorf("failed to get counterparty edge: %w", err)
+	}
+
+	since := s.now().Add(-s.cfg.Window)
+	debtorParties, err := s.counterparties(ctx, tenantID, debtorID, since)
+	if err != nil {
+		return nil, err
+	}
+	creditorParties, err := s.counterparties(ctx, tenantID, creditorID, since)
+	if err != nil {
+		return nil, err
+	}
+	for party := range debtorParties {
+		if party != creditorID && creditorParties[party] {
+			signals.SharedCounterparties++
+		}
+	}
+
+	signals.ReturnHops, err = s.returnHops(ctx, tenantID, debtorID, creditorID, since)
+	if err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+// counterparties returns the parties a party paid or was paid by since.
+func (s *Service) counterparties(ctx context.Context, tenantID, partyID string, since time.Time) (map[string]bool, error) {
+	edges, err := s.repo.ListCounterpartyEdges(ctx, tenantID, partyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list counterparty edges: %w", err)
+	}
+	parties := make(map[string]bool, len(edges))
+	for _, edge := range edges {
+		if edge.DebtorID == partyID {
+			parties[edge.CreditorID] = true
+		} else {
+			parties[edge.DebtorID] = true
+		}
+	}
+	return parties, nil
+}
+
+// returnHops searches the payments out of the creditor, breadth first, for
+// the shortest path back to the debtor.
+func (s *Service) returnHops(ctx context.Context, tenantID, debtorID, creditorID string, since time.Time) (int, error) {
+	visited := map[string]bool{creditorID: true}
+	frontier := []string{creditorID}
+	for hops := 1; hops <= s.cfg.MaxHops && len(frontier) > 0; hops++ {
+		var next []string
+		for _, party := range frontier {
+			edges, err := s.repo.ListCounterpartyEdges(ctx, tenantID, party, since)
+			if err != nil {
+				return 0, fmt.Errorf("failed to list counterparty edges: %w", err)
+			}
+			for _, edge := range edges {
+				if edge.DebtorID != party {
+					continue
+				}
+				if edge.CreditorID == debtorID {
+					return hops, nil
+				}
+				if visited[edge.CreditorID] {
+					continue
+				}
+				if len(visited) >= MaxVisitedParties {
+					return 0, nil
+				}
+				visited[edge.CreditorID] = true
+				next = append(next, edge.CreditorID)
+			}
+		}
+		frontier = next
+	}
+	return 0, nil
+}
+
+// Enricher returns a rules.Enricher exposing counterparty_first_seen,
+// shared_counterparties_count, funds_return_to_origin and
+// return_path_hops to CEL.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "graph"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"counterparty_first_seen":     cel.BoolType,
+		"shared_counterparties_count": cel.IntType,
+		"funds_return_to_origin":      cel.BoolType,
+		"return_path_hops":            cel.IntType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["counterparty_first_seen"] = false
+	activation["shared_counterparties_count"] = int64(0)
+	activation["funds_return_to_origin"] = false
+	activation["return_path_hops"] = int64(0)
+	if input.DebtorID == "" || input.CreditorID == "" || input.DebtorID == input.CreditorID {
+		return nil
+	}
+
+	signals, err := e.svc.Signals(ctx, input.TenantID, input.DebtorID, input.CreditorID)
+	if err != nil {
+		return err
+	}
+	activation["counterparty_first_seen"] = signals.FirstSeen
+	activation["shared_counterparties_count"] = int64(signals.SharedCounterparties)
+	activation["funds_return_to_origin"] = signals.ReturnHops > 0
+	activation["return_path_hops"] = int64(signals.ReturnHops)
+	return nil
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	repo := Wrap(base)
+
+	save := func(id, debtorID, creditorID string, amount float64, reversalOf string) {
+		t.Helper()
+		tx := ospreytest.NewTransaction().ID(id).From(debtorID).To(creditorID).Amount(amount, "USD").At(ospreytest.Epoch).Build()
+		tx.ReversalOf = reversalOf
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+
+	save("tx-1", "alice", "bob", 100, "")
+	save("tx-2", "alice", "bob", 50, "")
+	save("tx-3", "bob", "alice", 50, "tx-2")
+	save("tx-4", "alice", "alice", 10, "")
+
+	edge, err := base.GetCounterpartyEdge(ctx, "tenant-001", "alice", "bob")
+	if err != nil {
+		t.Fatalf("GetCounterpartyEdge failed: %v", err)
+	}
+	if edge.Count != 2 || edge.Amount != 150 || !edge.FirstSeen.Equal(ospreytest.Epoch) {
+		t.Errorf("expected 2 payments totalling 150, got %+v", edge)
+	}
+	if _, err := base.GetCounterpartyEdge(ctx, "tenant-001", "bob", "alice"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the reversal to add no edge, got %v", err)
+	}
+	if _, err := base.GetCounterpartyEdge(ctx, "tenant-001", "alice", "alice"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the own-account transfer to add no edge, got %v", err)
+	}
+}
+
+func TestSignals(t *testing.T) {
+	ctx := context.Background()
+	repo := Wrap(ospreytest.NewRepository(nil))
+	svc := NewService(repo, domain.GraphConfig{})
+	now := time.Now().UTC()
+
+	save := func(id, debtorID, creditorID string, ago time.Duration) {
+		t.Helper()
+		tx := ospreytest.NewTransaction().ID(id).From(debtorID).To(creditorID).Amount(100, "USD").At(now.Add(-ago)).Build()
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+
+	// alice → bob → carol → dave → alice, and carol also pays alice directly
+	// but too long ago to count
+	save("tx-1", "bob", "carol", time.Hour)
+	save("tx-2", "carol", "dave", time.Hour)
+	save("tx-3", "dave", "alice", time.Hour)
+	save("tx-4", "carol", "alice", 60*24*time.Hour)
+	save("tx-5", "alice", "carol", time.Hour)
+	save("tx-6", "alice", "bob", 0)
+
+	signals, err := svc.Signals(ctx, "tenant-001", "alice", "bob")
+	if err != nil {
+		t.Fatalf("Signals failed: %v", err)
+	}
+	want := Signals{FirstSeen: true, SharedCounterparties: 1, ReturnHops: 3}
+	if *signals != want {
+		t.Errorf("expected %+v, got %+v", want, *signals)
+	}
+
+	save("tx-7", "alice", "bob", 0)
+	short := NewService(repo, domain.GraphConfig{MaxHops: 2})
+	signals, err = short.Signals(ctx, "tenant-001", "alice", "bob")
+	if err != nil {
+		t.Fatalf("Signals failed: %v", err)
+	}
+	if signals.FirstSeen || signals.ReturnHops != 0 {
+		t.Errorf("expected a known counterparty and no path within 2 hops, got %+v", *signals)
+	}
+
+	t.Run("Enricher", func(t *testing.T) {
+		engine, err := rules.NewEngine(nil, 5)
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		if err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "round-trip-001",
+			Expression: "funds_return_to_origin && return_path_hops <= 3 && shared_counterparties_count >= 1 && !counterparty_first_seen ? 1.0 : 0.0",
+			Enabled:    true,
+		}); err != nil {
+			t.Fatalf("LoadRule failed: %v", err)
+		}
+
+		results, err := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:   "tenant-001",
+			TxID:       "tx-7",
+			DebtorID:   "alice",
+			CreditorID: "bob",
+			Amount:     domain.MustDecimal("100"),
+		})
+		if err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Score != 1 {
+			t.Errorf("expected the round trip to trigger the rule, got %+v", results)
+		}
+	})
+}
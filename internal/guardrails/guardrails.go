@@ -0,0 +1,136 @@
+// Package guardrails caps how much a single API call may change a tenant's
+// rules and typologies. A change beyond the caps is refused unless it is
+// forced, so disabling most rules or moving an alert threshold far takes a
+// deliberate, audited override rather than one mistyped or stolen request.
+package guardrails
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// AnyTenant is the caps entry for tenants without their own.
+const AnyTenant = "*"
+
+// Change describes what one call would do to a tenant's detection.
+type Change struct {
+	// EnabledRules is how many rules apply to the tenant before the change
+	EnabledRules int
+
+	// DisabledRules names the enabled rules the change disables or deletes
+	DisabledRules []string
+
+	// Thresholds lists the typology alert thresholds the change moves
+	Thresholds []ThresholdChange
+}
+
+// ThresholdChange is a typology's alert threshold before and after a change.
+type ThresholdChange struct {
+	TypologyID string
+	From, To   float64
+}
+
+// ExceededError lists the caps a change exceeds.
+type ExceededError struct {
+	Violations []string
+}
+
+func (e *ExceededError) Error() string {
+	return "change exceeds guardrails: " + strings.Join(e.Violations, "; ")
+}
+
+// Policy checks changes against the configured caps. A nil Policy, or one
+// without caps, allows every change.
+type Policy struct {
+	caps map[string]domain.MutationCaps
+}
+
+// NewPolicy creates a policy from configuration.
+func NewPolicy(cfg domain.GuardrailConfig) *Policy {
+	return &Policy{caps: cfg.Caps}
+}
+
+// Enabled reports whether any tenant has caps.
+func (p *Policy) Enabled() bool {
+	return p != nil && len(p.caps) > 0
+}
+
+// Check returns an *ExceededError when the change exceeds the tenant's caps,
+// falling back to AnyTenant, and nil otherwise.
+func (p *Policy) Check(tenantID string, change Change) error {
+	if !p.Enabled() {
+		return nil
+	}
+	caps, ok := p.caps[tenantID]
+	if !ok {
+		caps = p.caps[AnyTenant]
+	}
+
+	var violations []string
+	if caps.MaxDisabledFraction > 0 && len(change.DisabledRules) > 0 {
+		fraction := 1.0
+		if change.EnabledRules > 0 {
+			fraction = float64(len(change.DisabledRules)) / float64(change.EnabledRules)
+		}
+		if fraction > caps.MaxDisabledFraction {
+			violations = append(violations, fmt.Sprintf("disables %d of %d enabled rules (%s), above the cap of %s",
+				len(change.DisabledRules), change.EnabledRules, percent(fraction), percent(caps.MaxDisabledFraction)))
+		}
+	}
+	if caps.MaxThresholdChange > 0 {
+		for _, t := range change.Thresholds {
+			// Tolerate float noise so a change of exactly the cap passes
+			if math.Abs(t.To-t.From) > caps.MaxThresholdChange+1e-9 {
+				violations = append(violations, fmt.Sprintf("moves typology %s alert threshold from %g to %g, more than the cap of %g",
+					t.TypologyID, t.From, t.To, caps.MaxThresholdChange))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ExceededError{Violations: violations}
+	}
+	return nil
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.4g%%", f*100)
+}
+
+// ParseCaps parses per-tenant caps: "tenant=disable:0.25|threshold:0.1,*=disable:0.5".
+func ParseCaps(s string) (map[string]domain.MutationCaps, error) {
+	caps := make(map[string]domain.MutationCaps)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, value, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("invalid guardrail %q (want tenant=disable:fraction|threshold:change)", entry)
+		}
+		var c domain.MutationCaps
+		for _, limit := range strings.Split(value, "|") {
+			name, raw, ok := strings.Cut(strings.TrimSpace(limit), ":")
+			n, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if !ok || err != nil || n < 0 || n > 1 {
+				return nil, fmt.Errorf("tenant %s: invalid cap %q (want a name and a value between 0 and 1)", tenantID, limit)
+			}
+			switch strings.TrimSpace(name) {
+			case "disable":
+				c.MaxDisabledFraction = n
+			case "threshold":
+				c.MaxThresholdChange = n
+			default:
+				return nil, fmt.Errorf("tenant %s: unknown cap %q (want disable or threshold)", tenantID, name)
+			}
+		}
+		caps[tenantID] = c
+	}
+	return caps, nil
+}
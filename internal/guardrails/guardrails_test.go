@@ -0,0 +1,79 @@
+package guardrails
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy(domain.GuardrailConfig{Caps: map[string]domain.MutationCaps{
+		"tenant-a": {MaxDisabledFraction: 0.5},
+		AnyTenant:  {MaxDisabledFraction: 0.25, MaxThresholdChange: 0.1},
+	}})
+
+	t.Run("DisabledRules", func(t *testing.T) {
+		change := Change{EnabledRules: 4, DisabledRules: []string{"r1", "r2"}}
+		if err := policy.Check("tenant-a", change); err != nil {
+			t.Errorf("expected half within tenant-a's cap, got %v", err)
+		}
+		var exceeded *ExceededError
+		if err := policy.Check("tenant-b", change); !errors.As(err, &exceeded) || len(exceeded.Violations) != 1 {
+			t.Errorf("expected the default cap to refuse half, got %v", err)
+		}
+		if err := policy.Check("tenant-b", Change{EnabledRules: 4, DisabledRules: []string{"r1"}}); err != nil {
+			t.Errorf("expected a quarter within the cap, got %v", err)
+		}
+		// Nothing loaded, e.g. a rule not reloaded yet
+		if err := policy.Check("tenant-b", Change{DisabledRules: []string{"r1"}}); err == nil {
+			t.Error("expected disabling with no known enabled rules to be refused")
+		}
+	})
+
+	t.Run("Thresholds", func(t *testing.T) {
+		within := Change{Thresholds: []ThresholdChange{{TypologyID: "t1", From: 0.5, To: 0.6}, {TypologyID: "t2", From: 0.7, To: 0.6}}}
+		if err := policy.Check("tenant-b", within); err != nil {
+			t.Errorf("expected changes of the cap to pass, got %v", err)
+		}
+		beyond := Change{Thresholds: []ThresholdChange{{TypologyID: "t1", From: 0.5, To: 0.9}, {TypologyID: "t2", From: 0.7, To: 0.3}}}
+		var exceeded *ExceededError
+		if err := policy.Check("tenant-b", beyond); !errors.As(err, &exceeded) || len(exceeded.Violations) != 2 {
+			t.Errorf("expected both thresholds refused, got %v", err)
+		}
+		// tenant-a's own caps don't limit thresholds
+		if err := policy.Check("tenant-a", beyond); err != nil {
+			t.Errorf("expected no threshold cap for tenant-a, got %v", err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var nilPolicy *Policy
+		change := Change{EnabledRules: 1, DisabledRules: []string{"r1"}}
+		if nilPolicy.Enabled() || nilPolicy.Check("tenant-a", change) != nil {
+			t.Error("expected a nil policy to allow every change")
+		}
+		if err := NewPolicy(domain.GuardrailConfig{}).Check("tenant-a", change); err != nil {
+			t.Errorf("expected no caps to allow every change, got %v", err)
+		}
+	})
+}
+
+func TestParseCaps(t *testing.T) {
+	caps, err := ParseCaps("tenant-a=disable:0.25|threshold:0.1, *=disable:0.5,")
+	if err != nil {
+		t.Fatalf("ParseCaps failed: %v", err)
+	}
+	if len(caps) != 2 || caps["tenant-a"].MaxDisabledFraction != 0.25 || caps["tenant-a"].MaxThresholdChange != 0.1 {
+		t.Errorf("unexpected caps: %+v", caps)
+	}
+	if c := caps["*"]; c.MaxDisabledFraction != 0.5 || c.MaxThresholdChange != 0 {
+		t.Errorf("unexpected default caps: %+v", c)
+	}
+
+	for _, s := range []string{"tenant-a", "=disable:0.5", "tenant-a=disable", "tenant-a=disable:2", "tenant-a=weight:0.1", "tenant-a=disable:x"} {
+		if _, err := ParseCaps(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
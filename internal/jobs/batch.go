@@ -0,0 +1,193 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/tadp"
+)
+
+// MaxBatchRows caps the number of data rows accepted in one batch file.
+const MaxBatchRows = 100000
+
+// ErrInvalidFile is returned when a batch file cannot be parsed.
+var ErrInvalidFile = errors.New("invalid batch file")
+
+// Batch file columns. Header names are matched case-insensitively; unknown
+// columns are ignored for evaluation but carried through to the results file.
+const (
+	ColTxID              = "tx_id"
+	ColType              = "type"
+	ColDebtorID          = "debtor_id"
+	ColDebtorAccountID   = "debtor_account_id"
+	ColDebtorName        = "debtor_name"
+	ColDebtorCountry     = "debtor_country"
+	ColCreditorID        = "creditor_id"
+	ColCreditorAccountID = "creditor_account_id"
+	ColCreditorName      = "creditor_name"
+	ColCreditorCountry   = "creditor_country"
+	ColAmount            = "amount"
+	ColCurrency          = "currency"
+)
+
+// requiredColumns must appear in every batch file header.
+var requiredColumns = []string{ColType, ColDebtorID, ColCreditorID, ColAmount}
+
+// resultColumns are appended to the original columns in the results file.
+var resultColumns = []string{"status", "score", "reasons", "evaluation_id", "error"}
+
+// BatchFile is a parsed CSV transactions file.
+type BatchFile struct {
+	Header []string
+	Rows   [][]string
+	index  map[string]int
+}
+
+// ParseBatchFile parses and validates a CSV transactions file with a header row.
+func ParseBatchFile(data []byte) (*BatchFile, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%w: a header row and at least one data row are required", ErrInvalidFile)
+	}
+	if len(records)-1 > MaxBatchRows {
+		return nil, fmt.Errorf("%w: at most %d rows are allowed", ErrInvalidFile, MaxBatchRows)
+	}
+
+	file := &BatchFile{
+		Header: records[0],
+		Rows:   records[1:],
+		index:  make(map[string]int, len(records[0])),
+	}
+	for i, name := range file.Header {
+		file.index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, col := range requiredColumns {
+		if _, ok := file.index[col]; !ok {
+			return nil, fmt.Errorf("%w: missing required column %q", ErrInvalidFile, col)
+		}
+	}
+
+	return file, nil
+}
+
+// value returns a row's value for a column, or "" if the column is absent.
+func (f *BatchFile) value(row []string, col string) string {
+	i, ok := f.index[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// Transaction converts a row to a transaction record and rule input.
+func (f *BatchFile) Transaction(tenantID string, row []string) (*domain.Transaction, *rules.EvaluateInput, error) {
+	txType := f.value(row, ColType)
+	debtorID := f.value(row, ColDebtorID)
+	creditorID := f.value(row, ColCreditorID)
+
+	if txType == "" {
+		return nil, nil, fmt.Errorf("type is required")
+	}
+	if debtorID == "" || creditorID == "" {
+		return nil, nil, fmt.Errorf("debtor_id and creditor_id are required")
+	}
+
+	amount, err := domain.ParseDecimal(f.value(row, ColAmount))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("amount must be a positive number")
+	}
+
+	txID := f.value(row, ColTxID)
+	if txID == "" {
+		txID = uuid.New().String()
+	}
+
+	now := time.Now().UTC()
+	tx := &domain.Transaction{
+		ID:              txID,
+		TenantID:        tenantID,
+		Type:            txType,
+		DebtorID:        debtorID,
+		DebtorAccountID: f.value(row, ColDebtorAccountID),
+		CreditorID:      creditorID,
+		CreditorAcctID:  f.value(row, ColCreditorAccountID),
+		Amount:          amount,
+		Currency:        f.value(row, ColCurrency),
+		Timestamp:       now,
+		CreatedAt:       now,
+	}
+
+	input := &rules.EvaluateInput{
+		TenantID:          tenantID,
+		TxID:              tx.ID,
+		Type:              tx.Type,
+		DebtorID:          tx.DebtorID,
+		CreditorID:        tx.CreditorID,
+		DebtorAccountID:   tx.DebtorAccountID,
+		CreditorAccountID: tx.CreditorAcctID,
+		DebtorName:        f.value(row, ColDebtorName),
+		CreditorName:      f.value(row, ColCreditorName),
+		DebtorCountry:     f.value(row, ColDebtorCountry),
+		CreditorCountry:   f.value(row, ColCreditorCountry),
+		Amount:            tx.Amount,
+		Currency:          tx.Currency,
+		Timestamp:         tx.Timestamp,
+	}
+
+	return tx, input, nil
+}
+
+// ResultWriter builds a results file: the original columns plus the decision.
+type ResultWriter struct {
+	buf *bytes.Buffer
+	w   *csv.Writer
+}
+
+// NewResultWriter creates a results file with the original header plus result columns.
+func NewResultWriter(header []string) *ResultWriter {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	_ = w.Write(append(append([]string{}, header...), resultColumns...))
+	return &ResultWriter{buf: buf, w: w}
+}
+
+// Write appends a row with its evaluation outcome. A failed row has an empty
+// decision and the error message in the error column.
+func (rw *ResultWriter) Write(row []string, evaluation *domain.Evaluation, err error) {
+	out := append([]string{}, row...)
+	if err != nil {
+		out = append(out, "", "", "", "", err.Error())
+	} else {
+		out = append(out,
+			evaluation.Status,
+			strconv.FormatFloat(evaluation.Score, 'f', 4, 64),
+			strings.Join(tadp.GetReasons(evaluation), "; "),
+			evaluation.ID,
+			"",
+		)
+	}
+	_ = rw.w.Write(out)
+}
+
+// Bytes flushes and returns the results file.
+func (rw *ResultWriter) Bytes() ([]byte, error) {
+	rw.w.Flush()
+	if err := rw.w.Error(); err != nil {
+		return nil, err
+	}
+	return rw.buf.Bytes(), nil
+}
@@ -0,0 +1,473 @@
+// Package jobs runs long-running background operations such as batch file
+// evaluation and backfills, with progress tracking, rate limits, pause/resume
+// and cancellation.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/tadp"
+)
+
+// progressInterval is how many items are processed between progress saves.
+const progressInterval = 100
+
+// DefaultRatePerSecond throttles backfill jobs that don't set their own rate,
+// so a backfill never starves live traffic of database and CPU time.
+const DefaultRatePerSecond = 50
+
+var (
+	// ErrNoRepository is returned when jobs are submitted without a repository to track them.
+	ErrNoRepository = errors.New("repository not available")
+
+	// ErrJobNotActive is returned when controlling a job that is not running in this process.
+	ErrJobNotActive = errors.New("job is not active")
+
+	// errCancelled stops a job loop after a cancel request.
+	errCancelled = errors.New("cancelled")
+)
+
+// control lets API requests pause, resume and cancel a running job.
+type control struct {
+	tenantID string
+	cancel   context.CancelFunc
+
+	mu        sync.Mutex
+	paused    bool
+	resume    chan struct{}
+	cancelled bool
+
+	limiter *time.Ticker // nil when unthrottled
+}
+
+// Runner executes background jobs and records their progress.
+type Runner struct {
+	repo           domain.Repository
+	engine         *rules.Engine
+	typologyEngine *rules.TypologyEngine
+	processor      *tadp.Processor
+	mode           domain.EvaluationMode // detection or compliance
+
+	mu       sync.Mutex
+	controls map[string]*control // jobID -> control, for jobs running in this process
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRunner creates a new job runner.
+func NewRunner(repo domain.Repository, engine *rules.Engine, typologyEngine *rules.TypologyEngine, processor *tadp.Processor, mode domain.EvaluationMode) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		repo:           repo,
+		engine:         engine,
+		typologyEngine: typologyEngine,
+		processor:      processor,
+		mode:           mode,
+		controls:       make(map[string]*control),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// SubmitBatch validates a transactions file, stores it, and evaluates it in the background.
+// The returned job is pending; poll it with the repository's GetJob.
+func (r *Runner) SubmitBatch(ctx context.Context, tenantID string, data []byte) (*domain.Job, error) {
+	if r.repo == nil {
+		return nil, ErrNoRepository
+	}
+
+	file, err := ParseBatchFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	job := newJob(tenantID, domain.JobTypeBatchFile, len(file.Rows), nil)
+
+	if err := r.repo.SaveJobFile(ctx, tenantID, job.ID, domain.JobFileInput, data); err != nil {
+		return nil, fmt.Errorf("failed to save job input: %w", err)
+	}
+
+	return r.start(ctx, job, func(ctx context.Context, ctl *control) error {
+		return r.runBatch(ctx, ctl, job, file)
+	})
+}
+
+// SubmitReevaluate re-evaluates a tenant's stored transactions in [Since, Until)
+// under the currently loaded rules, throttled to RatePerSecond.
+func (r *Runner) SubmitReevaluate(ctx context.Context, tenantID string, params domain.JobParams) (*domain.Job, error) {
+	if r.repo == nil {
+		return nil, ErrNoRepository
+	}
+	if params.Until.IsZero() {
+		params.Until = time.Now().UTC()
+	}
+	if params.RatePerSecond == 0 {
+		params.RatePerSecond = DefaultRatePerSecond
+	}
+
+	total, err := r.repo.CountTransactions(ctx, tenantID, params.Since, params.Until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	job := newJob(tenantID, domain.JobTypeReevaluate, total, &params)
+
+	return r.start(ctx, job, func(ctx context.Context, ctl *control) error {
+		return r.runReevaluate(ctx, ctl, job)
+	})
+}
+
+// Pause suspends a running job after its current item.
+func (r *Runner) Pause(tenantID, jobID string) error {
+	ctl := r.control(tenantID, jobID)
+	if ctl == nil {
+		return ErrJobNotActive
+	}
+
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	if !ctl.paused {
+		ctl.paused = true
+		ctl.resume = make(chan struct{})
+	}
+	return nil
+}
+
+// Resume continues a paused job.
+func (r *Runner) Resume(tenantID, jobID string) error {
+	ctl := r.control(tenantID, jobID)
+	if ctl == nil {
+		return ErrJobNotActive
+	}
+
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	if ctl.paused {
+		ctl.paused = false
+		close(ctl.resume)
+	}
+	return nil
+}
+
+// Cancel stops a running or paused job. Work already done is kept.
+func (r *Runner) Cancel(tenantID, jobID string) error {
+	ctl := r.control(tenantID, jobID)
+	if ctl == nil {
+		return ErrJobNotActive
+	}
+
+	ctl.mu.Lock()
+	ctl.cancelled = true
+	ctl.mu.Unlock()
+	ctl.cancel()
+	return nil
+}
+
+// Stop interrupts running jobs and waits for them to record their final state.
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// newJob creates a pending job record.
+func newJob(tenantID, jobType string, total int, params *domain.JobParams) *domain.Job {
+	now := time.Now().UTC()
+	return &domain.Job{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Type:      jobType,
+		Status:    domain.JobStatusPending,
+		Params:    params,
+		Total:     total,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// start saves a pending job and runs it in the background.
+func (r *Runner) start(ctx context.Context, job *domain.Job, run func(ctx context.Context, ctl *control) error) (*domain.Job, error) {
+	if err := r.repo.SaveJob(ctx, job.TenantID, job); err != nil {
+		return nil, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	jobCtx, cancel := context.WithCancel(r.ctx)
+	ctl := &control{tenantID: job.TenantID, cancel: cancel}
+	if job.Params != nil && job.Params.RatePerSecond > 0 {
+		ctl.limiter = time.NewTicker(time.Second / time.Duration(job.Params.RatePerSecond))
+	}
+
+	r.mu.Lock()
+	r.controls[job.ID] = ctl
+	r.mu.Unlock()
+
+	slog.Info("job submitted", "job_id", job.ID, "type", job.Type, "tenant_id", job.TenantID, "total", job.Total)
+
+	snapshot := *job
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		started := time.Now().UTC()
+		job.Status = domain.JobStatusRunning
+		job.StartedAt = &started
+		r.saveProgress(job)
+
+		err := run(jobCtx, ctl)
+
+		// Deregister before recording the final status so a job reported as
+		// finished can no longer be controlled.
+		r.mu.Lock()
+		delete(r.controls, job.ID)
+		r.mu.Unlock()
+		cancel()
+		if ctl.limiter != nil {
+			ctl.limiter.Stop()
+		}
+
+		r.finish(job, err)
+	}()
+
+	return &snapshot, nil
+}
+
+// control returns the control for a job running in this process, if the tenant owns it.
+func (r *Runner) control(tenantID, jobID string) *control {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctl, ok := r.controls[jobID]
+	if !ok || ctl.tenantID != tenantID {
+		return nil
+	}
+	return ctl
+}
+
+// checkpoint is called before each item. It blocks while the job is paused,
+// applies the rate limit, and reports cancellation or shutdown.
+func (r *Runner) checkpoint(ctx context.Context, ctl *control, job *domain.Job) error {
+	ctl.mu.Lock()
+	paused, resume := ctl.paused, ctl.resume
+	ctl.mu.Unlock()
+
+	if paused {
+		job.Status = domain.JobStatusPaused
+		r.saveProgress(job)
+		slog.Info("job paused", "job_id", job.ID, "processed", job.Processed)
+
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return r.stopReason(ctl)
+		}
+
+		job.Status = domain.JobStatusRunning
+		r.saveProgress(job)
+		slog.Info("job resumed", "job_id", job.ID)
+	}
+
+	if ctl.limiter != nil {
+		select {
+		case <-ctl.limiter.C:
+		case <-ctx.Done():
+			return r.stopReason(ctl)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return r.stopReason(ctl)
+	}
+	return nil
+}
+
+// stopReason distinguishes a cancel request from a server shutdown.
+func (r *Runner) stopReason(ctl *control) error {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	if ctl.cancelled {
+		return errCancelled
+	}
+	return fmt.Errorf("interrupted by shutdown")
+}
+
+// advance records one processed item and periodically saves progress.
+func (r *Runner) advance(job *domain.Job, err error) {
+	job.Processed++
+	if err != nil {
+		job.Failed++
+	}
+	if job.Processed%progressInterval == 0 {
+		r.saveProgress(job)
+	}
+}
+
+// runBatch evaluates every row of a batch file and stores the results file.
+func (r *Runner) runBatch(ctx context.Context, ctl *control, job *domain.Job, file *BatchFile) error {
+	results := NewResultWriter(file.Header)
+
+	for i, row := range file.Rows {
+		if err := r.checkpoint(ctx, ctl, job); err != nil {
+			return err
+		}
+
+		var evaluation *domain.Evaluation
+		tx, input, err := file.Transaction(job.TenantID, row)
+		if err == nil {
+			if saveErr := r.repo.SaveTransaction(ctx, job.TenantID, tx); saveErr != nil {
+				slog.Error("failed to save transaction", "tx_id", tx.ID, "error", saveErr)
+			}
+			evaluation, err = r.evaluate(ctx, job.TenantID, tx, input)
+		}
+		if err != nil {
+			slog.Debug("batch row failed", "job_id", job.ID, "row", i+1, "error", err)
+		}
+
+		results.Write(row, evaluation, err)
+		r.advance(job, err)
+	}
+
+	return r.saveResults(job, results)
+}
+
+// reevaluateHeader is the results file header for reevaluate jobs.
+var reevaluateHeader = []string{"tx_id", "type", "debtor_id", "creditor_id", "amount", "currency", "timestamp"}
+
+// reevaluatePageSize is how many transactions are read per query.
+const reevaluatePageSize = 500
+
+// runReevaluate re-evaluates stored transactions page by page and stores a results file.
+func (r *Runner) runReevaluate(ctx context.Context, ctl *control, job *domain.Job) error {
+	results := NewResultWriter(reevaluateHeader)
+
+	for offset := 0; ; offset += reevaluatePageSize {
+		page, err := r.repo.ListTransactions(ctx, job.TenantID, job.Params.Since, job.Params.Until, offset, reevaluatePageSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return r.stopReason(ctl)
+			}
+			return fmt.Errorf("failed to list transactions: %w", err)
+		}
+
+		for _, tx := range page {
+			if err := r.checkpoint(ctx, ctl, job); err != nil {
+				return err
+			}
+
+			evaluation, err := r.evaluate(ctx, job.TenantID, tx, &rules.EvaluateInput{
+				TenantID:          job.TenantID,
+				TxID:              tx.ID,
+				Type:              tx.Type,
+				DebtorID:          tx.DebtorID,
+				CreditorID:        tx.CreditorID,
+				DebtorAccountID:   tx.DebtorAccountID,
+				CreditorAccountID: tx.CreditorAcctID,
+				Amount:            tx.Amount,
+				Currency:          tx.Currency,
+				Timestamp:         tx.Timestamp,
+				Components:        tx.Components,
+				ReversalOf:        tx.ReversalOf,
+				AdditionalData:    tx.Metadata,
+			})
+
+			row := []string{
+				tx.ID, tx.Type, tx.DebtorID, tx.CreditorID,
+				tx.Amount.String(), tx.Currency, tx.Timestamp.UTC().Format(time.RFC3339),
+			}
+			results.Write(row, evaluation, err)
+			r.advance(job, err)
+		}
+
+		if len(page) < reevaluatePageSize {
+			break
+		}
+	}
+
+	return r.saveResults(job, results)
+}
+
+// evaluate runs rule input through the rules, typologies and decision processor
+// and saves the evaluation.
+func (r *Runner) evaluate(ctx context.Context, tenantID string, tx *domain.Transaction, input *rules.EvaluateInput) (*domain.Evaluation, error) {
+	start := time.Now()
+
+	ctx, degradations := domain.WithDegradations(ctx)
+	ctx, velocity := domain.WithVelocitySnapshots(ctx)
+	ruleResults, err := r.engine.EvaluateAll(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("rule evaluation failed: %w", err)
+	}
+
+	var typologyResults []domain.TypologyResult
+	if r.mode == domain.ModeCompliance && r.typologyEngine != nil && r.typologyEngine.TypologyCount() > 0 {
+		typologyResults = r.typologyEngine.EvaluateTypologies(tenantID, ruleResults)
+	}
+
+	evaluation := r.processor.Process(ctx, &tadp.DecisionInput{
+		TenantID:        tenantID,
+		TxID:            input.TxID,
+		TraceID:         input.TxID,
+		RuleResults:     ruleResults,
+		TypologyResults: typologyResults,
+		StartTime:       start,
+		Degradations:    degradations.List(),
+		Velocity:        velocity.List(),
+		Transaction:     tx,
+	})
+
+	if err := r.repo.SaveEvaluation(ctx, tenantID, evaluation); err != nil {
+		slog.Error("failed to save evaluation", "tx_id", input.TxID, "error", err)
+	}
+
+	return evaluation, nil
+}
+
+// saveResults stores a job's results file.
+func (r *Runner) saveResults(job *domain.Job, results *ResultWriter) error {
+	data, err := results.Bytes()
+	if err != nil {
+		return err
+	}
+	// Use a fresh context so a shutdown right at the end doesn't lose the results
+	return r.repo.SaveJobFile(context.Background(), job.TenantID, job.ID, domain.JobFileResult, data)
+}
+
+// saveProgress persists the job's current status and counters.
+func (r *Runner) saveProgress(job *domain.Job) {
+	if err := r.repo.SaveJob(context.Background(), job.TenantID, job); err != nil {
+		slog.Error("failed to save job progress", "job_id", job.ID, "error", err)
+	}
+}
+
+// finish records the terminal state of a job.
+func (r *Runner) finish(job *domain.Job, err error) {
+	completed := time.Now().UTC()
+	job.CompletedAt = &completed
+
+	switch {
+	case err == nil:
+		job.Status = domain.JobStatusCompleted
+	case errors.Is(err, errCancelled):
+		job.Status = domain.JobStatusCancelled
+	default:
+		job.Status = domain.JobStatusFailed
+		job.Error = err.Error()
+	}
+	r.saveProgress(job)
+
+	slog.Info("job finished",
+		"job_id", job.ID,
+		"tenant_id", job.TenantID,
+		"status", job.Status,
+		"processed", job.Processed,
+		"failed", job.Failed,
+	)
+}
@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/tadp"
+)
+
+func TestParseBatchFile(t *testing.T) {
+	t.Run("MissingRequiredColumn", func(t *testing.T) {
+		_, err := ParseBatchFile([]byte("type,debtor_id,amount\ntransfer,d1,100\n"))
+		if !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("expected ErrInvalidFile, got %v", err)
+		}
+	})
+
+	t.Run("HeaderOnly", func(t *testing.T) {
+		_, err := ParseBatchFile([]byte("type,debtor_id,creditor_id,amount\n"))
+		if !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("expected ErrInvalidFile, got %v", err)
+		}
+	})
+
+	t.Run("CaseInsensitiveHeader", func(t *testing.T) {
+		file, err := ParseBatchFile([]byte("Type,Debtor_ID,Creditor_ID,Amount\ntransfer,d1,c1,100\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tx, _, err := file.Transaction("tenant-001", file.Rows[0])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tx.DebtorID != "d1" || tx.Amount != domain.MustDecimal("100") {
+			t.Errorf("unexpected transaction: %+v", tx)
+		}
+	})
+}
+
+// newTestRunner creates a runner over a temporary SQLite database with one high-value rule.
+func newTestRunner(t *testing.T) (*Runner, domain.Repository) {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "jobs-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpPath) })
+
+	repo, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	engine, _ := rules.NewEngine(nil, 5)
+	engine.LoadRule(&domain.RuleConfig{
+		ID:         "high-value",
+		Name:       "High Value",
+		Expression: "amount > 10000.0",
+		Weight:     1.0,
+		Enabled:    true,
+	})
+
+	runner := NewRunner(repo, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	t.Cleanup(func() {
+		runner.Stop()
+		engine.Close()
+		repo.Close()
+	})
+
+	return runner, repo
+}
+
+// waitForStatus polls a job until it reaches one of the given statuses.
+func waitForStatus(t *testing.T, repo domain.Repository, tenantID, jobID string, statuses ...string) *domain.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := repo.GetJob(context.Background(), tenantID, jobID)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		for _, status := range statuses {
+			if job.Status == status {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for job status %v, last status %s", statuses, job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBatchJob(t *testing.T) {
+	runner, repo := newTestRunner(t)
+
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	input := "tx_id,type,debtor_id,creditor_id,amount,currency,branch\n" +
+		"tx-1,transfer,d1,c1,500,USD,north\n" +
+		"tx-2,transfer,d1,c2,50000,USD,south\n" +
+		"tx-3,transfer,d2,c1,not-a-number,USD,east\n"
+
+	job, err := runner.SubmitBatch(ctx, tenantID, []byte(input))
+	if err != nil {
+		t.Fatalf("SubmitBatch failed: %v", err)
+	}
+	if job.Total != 3 {
+		t.Errorf("expected 3 rows, got %d", job.Total)
+	}
+
+	job = waitForStatus(t, repo, tenantID, job.ID, domain.JobStatusCompleted, domain.JobStatusFailed)
+	if job.Status != domain.JobStatusCompleted {
+		t.Fatalf("expected completed job, got %s (%s)", job.Status, job.Error)
+	}
+	if job.Processed != 3 || job.Failed != 1 {
+		t.Errorf("expected 3 processed and 1 failed, got %d and %d", job.Processed, job.Failed)
+	}
+
+	data, err := repo.GetJobFile(ctx, tenantID, job.ID, domain.JobFileResult)
+	if err != nil {
+		t.Fatalf("GetJobFile failed: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse results: %v", err)
+	}
+
+	header := strings.Join(records[0], ",")
+	if header != "tx_id,type,debtor_id,creditor_id,amount,currency,branch,status,score,reasons,evaluation_id,error" {
+		t.Errorf("unexpected results header: %s", header)
+	}
+	if records[1][6] != "north" {
+		t.Errorf("expected original columns to be preserved, got %v", records[1])
+	}
+	if records[1][7] != domain.StatusNoAlert || records[2][7] != domain.StatusAlert {
+		t.Errorf("unexpected statuses: %s, %s", records[1][7], records[2][7])
+	}
+	if records[3][11] == "" {
+		t.Error("expected error message for invalid row")
+	}
+
+	if _, err := repo.GetTransaction(ctx, tenantID, "tx-2"); err != nil {
+		t.Errorf("expected batch transaction to be saved: %v", err)
+	}
+}
+
+func TestReevaluateJob(t *testing.T) {
+	runner, repo := newTestRunner(t)
+
+	ctx := context.Background()
+	tenantID := "tenant-001"
+	now := time.Now().UTC()
+
+	for i := 0; i < 10; i++ {
+		repo.SaveTransaction(ctx, tenantID, &domain.Transaction{
+			ID:         fmt.Sprintf("tx-%02d", i),
+			Type:       "transfer",
+			DebtorID:   "d1",
+			CreditorID: "c1",
+			Amount:     domain.DecimalFromFloat(float64(i+1) * 2000),
+			Currency:   "USD",
+			Timestamp:  now.Add(-time.Duration(i+1) * time.Hour),
+			CreatedAt:  now,
+		})
+	}
+	// Outside the window
+	repo.SaveTransaction(ctx, tenantID, &domain.Transaction{
+		ID: "tx-old", Type: "transfer", DebtorID: "d1", CreditorID: "c1",
+		Amount: domain.MustDecimal("100"), Currency: "USD", Timestamp: now.Add(-72 * time.Hour), CreatedAt: now,
+	})
+
+	t.Run("ReevaluatesWindow", func(t *testing.T) {
+		job, err := runner.SubmitReevaluate(ctx, tenantID, domain.JobParams{
+			Since:         now.Add(-24 * time.Hour),
+			RatePerSecond: 1000,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReevaluate failed: %v", err)
+		}
+		if job.Total != 10 {
+			t.Errorf("expected 10 transactions in window, got %d", job.Total)
+		}
+
+		job = waitForStatus(t, repo, tenantID, job.ID, domain.JobStatusCompleted, domain.JobStatusFailed)
+		if job.Status != domain.JobStatusCompleted || job.Processed != 10 {
+			t.Fatalf("expected completed job with 10 processed, got %s with %d", job.Status, job.Processed)
+		}
+		if job.Params == nil || job.Params.RatePerSecond != 1000 {
+			t.Errorf("expected params to round-trip, got %+v", job.Params)
+		}
+
+		data, _ := repo.GetJobFile(ctx, tenantID, job.ID, domain.JobFileResult)
+		if alerts := strings.Count(string(data), domain.StatusAlert); alerts != 5 {
+			t.Errorf("expected 5 alerts under current rules, got %d", alerts)
+		}
+	})
+
+	t.Run("PauseAndResume", func(t *testing.T) {
+		job, err := runner.SubmitReevaluate(ctx, tenantID, domain.JobParams{
+			Since:         now.Add(-24 * time.Hour),
+			RatePerSecond: 20,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReevaluate failed: %v", err)
+		}
+
+		if err := runner.Pause(tenantID, job.ID); err != nil {
+			t.Fatalf("Pause failed: %v", err)
+		}
+		waitForStatus(t, repo, tenantID, job.ID, domain.JobStatusPaused)
+
+		if err := runner.Pause("other-tenant", job.ID); err != ErrJobNotActive {
+			t.Errorf("expected ErrJobNotActive for another tenant, got %v", err)
+		}
+
+		if err := runner.Resume(tenantID, job.ID); err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		job = waitForStatus(t, repo, tenantID, job.ID, domain.JobStatusCompleted)
+		if job.Processed != 10 {
+			t.Errorf("expected 10 processed after resume, got %d", job.Processed)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		job, err := runner.SubmitReevaluate(ctx, tenantID, domain.JobParams{
+			Since:         now.Add(-24 * time.Hour),
+			RatePerSecond: 2,
+		})
+		if err != nil {
+			t.Fatalf("SubmitReevaluate failed: %v", err)
+		}
+
+		if err := runner.Cancel(tenantID, job.ID); err != nil {
+			t.Fatalf("Cancel failed: %v", err)
+		}
+		job = waitForStatus(t, repo, tenantID, job.ID, domain.JobStatusCancelled)
+		if job.Processed >= 10 {
+			t.Errorf("expected cancelled job to stop early, processed %d", job.Processed)
+		}
+
+		if err := runner.Cancel(tenantID, job.ID); err != ErrJobNotActive {
+			t.Errorf("expected ErrJobNotActive after job ended, got %v", err)
+		}
+	})
+}
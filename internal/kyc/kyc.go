@@ -0,0 +1,174 @@
+// Package kyc provides cached access to party KYC profiles and exposes them to rules.
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// DefaultCacheTTL is how long a profile (or its absence) is cached.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Service loads party KYC profiles through the cache.
+type Service struct {
+	repo     domain.Repository
+	cache    domain.Cache
+	cacheTTL time.Duration
+}
+
+// NewService creates a new KYC service.
+func NewService(repo domain.Repository, cache domain.Cache) *Service {
+	return &Service{
+		repo:     repo,
+		cache:    cache,
+		cacheTTL: DefaultCacheTTL,
+	}
+}
+
+// CacheKey returns the cache key for a party's KYC profile.
+func CacheKey(entityID string) string {
+	return "kyc:" + entityID
+}
+
+// GetProfile returns the KYC profile for an entity, or nil if none is on file.
+func (s *Service) GetProfile(ctx context.Context, tenantID, entityID string) (*domain.PartyKYC, error) {
+	if tenantID == "" || entityID == "" {
+		return nil, fmt.Errorf("tenantID and entityID are required")
+	}
+
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, tenantID, CacheKey(entityID))
+		if err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		} else if data != nil {
+			var profile *domain.PartyKYC
+			if err := json.Unmarshal(data, &profile); err == nil {
+				return profile, nil
+			}
+		}
+	}
+
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	profile, err := s.repo.GetPartyKYC(ctx, tenantID, entityID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get party kyc: %w", err)
+	}
+
+	// Cache misses too ("null") so unknown parties don't hit the database every time
+	if s.cache != nil {
+		if data, err := json.Marshal(profile); err == nil {
+			_ = s.cache.Set(ctx, tenantID, CacheKey(entityID), data, s.cacheTTL)
+		}
+	}
+
+	return profile, nil
+}
+
+// Invalidate drops a cached profile after it has been updated.
+func (s *Service) Invalidate(ctx context.Context, tenantID, entityID string) error {
+	if s.cache == nil {
+		return nil
+	}
+	return s.cache.Delete(ctx, tenantID, CacheKey(entityID))
+}
+
+// Enricher returns a rules.Enricher exposing debtor_kyc and creditor_kyc to
+// CEL, with the most used attributes also as flat variables:
+// debtor_risk_rating, creditor_risk_rating, debtor_country,
+// creditor_country, debtor_segment, creditor_segment and account_age_days.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "kyc"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"debtor_kyc":   cel.MapType(cel.StringType, cel.DynType),
+		"creditor_kyc": cel.MapType(cel.StringType, cel.DynType),
+
+		"debtor_risk_rating":   cel.StringType,
+		"creditor_risk_rating": cel.StringType,
+		"debtor_country":       cel.StringType,
+		"creditor_country":     cel.StringType,
+		"debtor_segment":       cel.StringType,
+		"creditor_segment":     cel.StringType,
+		"account_age_days":     cel.IntType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	debtor, debtorErr := e.svc.GetProfile(ctx, input.TenantID, input.DebtorID)
+	creditor, creditorErr := e.svc.GetProfile(ctx, input.TenantID, input.CreditorID)
+
+	debtorKYC, creditorKYC := ToActivation(debtor), ToActivation(creditor)
+	activation["debtor_kyc"] = debtorKYC
+	activation["creditor_kyc"] = creditorKYC
+
+	// A party's country is its residence on file, else the country sent with
+	// the transaction
+	activation["debtor_risk_rating"] = debtorKYC["risk_rating"]
+	activation["creditor_risk_rating"] = creditorKYC["risk_rating"]
+	activation["debtor_country"] = partyCountry(debtor, input.DebtorCountry)
+	activation["creditor_country"] = partyCountry(creditor, input.CreditorCountry)
+	activation["debtor_segment"] = debtorKYC["segment"]
+	activation["creditor_segment"] = creditorKYC["segment"]
+	activation["account_age_days"] = debtorKYC["account_age_days"]
+
+	return errors.Join(debtorErr, creditorErr)
+}
+
+// ToActivation converts a profile to the map exposed to CEL.
+// A nil profile yields known=false and neutral defaults so rules never fail on missing keys.
+func ToActivation(profile *domain.PartyKYC) map[string]any {
+	if profile == nil {
+		return map[string]any{
+			"known":             false,
+			"risk_rating":       "",
+			"pep":               false,
+			"residence_country": "",
+			"segment":           "",
+			"account_age_days":  int64(-1),
+		}
+	}
+
+	ageDays := int64(-1)
+	if !profile.OnboardedAt.IsZero() {
+		ageDays = int64(time.Since(profile.OnboardedAt).Hours() / 24)
+	}
+
+	return map[string]any{
+		"known":             true,
+		"risk_rating":       profile.RiskRating,
+		"pep":               profile.PEP,
+		"residence_country": profile.ResidenceCountry,
+		"segment":           profile.Segment,
+		"account_age_days":  ageDays,
+	}
+}
+
+// partyCountry returns the party's residence country, or fallback without
+// one on file.
+func partyCountry(profile *domain.PartyKYC, fallback string) string {
+	if profile != nil && profile.ResidenceCountry != "" {
+		return profile.ResidenceCountry
+	}
+	return fallback
+}
@@ -0,0 +1,187 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+func TestKYCService(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "kyc-test-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	repo, err := repository.New(domain.RepositoryConfig{
+		Driver:     "sqlite",
+		SQLitePath: tmpPath,
+	})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	lruCache := cache.NewLRUCache(100)
+	defer lruCache.Close()
+
+	svc := NewService(repo, lruCache)
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	t.Run("UnknownParty", func(t *testing.T) {
+		profile, err := svc.GetProfile(ctx, tenantID, "nobody")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if profile != nil {
+			t.Errorf("expected nil profile for unknown party, got %+v", profile)
+		}
+	})
+
+	t.Run("InvalidateAfterUpdate", func(t *testing.T) {
+		entityID := "cust-001"
+		repo.SavePartyKYC(ctx, tenantID, &domain.PartyKYC{EntityID: entityID, RiskRating: domain.RiskRatingLow})
+
+		profile, _ := svc.GetProfile(ctx, tenantID, entityID)
+		if profile == nil || profile.RiskRating != domain.RiskRatingLow {
+			t.Fatalf("expected low risk profile, got %+v", profile)
+		}
+
+		// Cached value survives a write until invalidated
+		repo.SavePartyKYC(ctx, tenantID, &domain.PartyKYC{EntityID: entityID, RiskRating: domain.RiskRatingHigh})
+		profile, _ = svc.GetProfile(ctx, tenantID, entityID)
+		if profile.RiskRating != domain.RiskRatingLow {
+			t.Errorf("expected cached low risk profile, got %s", profile.RiskRating)
+		}
+
+		svc.Invalidate(ctx, tenantID, entityID)
+		profile, _ = svc.GetProfile(ctx, tenantID, entityID)
+		if profile.RiskRating != domain.RiskRatingHigh {
+			t.Errorf("expected high risk profile after invalidation, got %s", profile.RiskRating)
+		}
+	})
+
+	t.Run("CacheFailureDegrades", func(t *testing.T) {
+		repo.SavePartyKYC(ctx, tenantID, &domain.PartyKYC{EntityID: "cust-002", RiskRating: domain.RiskRatingMedium})
+		failing := NewService(repo, failingCache{lruCache})
+
+		dctx, degradations := domain.WithDegradations(ctx)
+		profile, err := failing.GetProfile(dctx, tenantID, "cust-002")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if profile == nil || profile.RiskRating != domain.RiskRatingMedium {
+			t.Errorf("expected profile from the repository, got %+v", profile)
+		}
+		got := degradations.List()
+		if len(got) != 1 || got[0].Component != domain.DegradedCache || got[0].Status != domain.DegradationFailed {
+			t.Errorf("expected failed cache degradation, got %v", got)
+		}
+	})
+
+	t.Run("EnricherExposesKYCToRules", func(t *testing.T) {
+		repo.SavePartyKYC(ctx, tenantID, &domain.PartyKYC{
+			EntityID:    "pep-001",
+			RiskRating:  domain.RiskRatingHigh,
+			PEP:         true,
+			OnboardedAt: time.Now().Add(-10 * 24 * time.Hour),
+		})
+
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "pep-new-account",
+			Expression: `debtor_kyc.pep && debtor_kyc.account_age_days < 30 && !creditor_kyc.known`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		results, _ := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:   tenantID,
+			TxID:       "tx-kyc-001",
+			Type:       "transfer",
+			DebtorID:   "pep-001",
+			CreditorID: "stranger-001",
+			Amount:     domain.MustDecimal("500.0"),
+			Currency:   "USD",
+		})
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match PEP debtor with new account, got score %.2f", results[0].Score)
+		}
+	})
+
+	t.Run("EnricherExposesFlatVariables", func(t *testing.T) {
+		repo.SavePartyKYC(ctx, tenantID, &domain.PartyKYC{
+			EntityID:    "risky-001",
+			RiskRating:  domain.RiskRatingHigh,
+			Segment:     "sme",
+			OnboardedAt: time.Now().Add(-400 * 24 * time.Hour),
+		})
+
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+
+		// The creditor has no profile, so its country comes from the transaction
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "high-risk-cross-border",
+			Expression: `debtor_risk_rating == "high" && debtor_segment == "sme" && creditor_country != "" && debtor_country != creditor_country && account_age_days > 365 && amount > 10000.0`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		results, _ := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:        tenantID,
+			TxID:            "tx-kyc-002",
+			Type:            "transfer",
+			DebtorID:        "risky-001",
+			CreditorID:      "stranger-002",
+			DebtorCountry:   "GB",
+			CreditorCountry: "AE",
+			Amount:          domain.MustDecimal("25000.0"),
+			Currency:        "USD",
+		})
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match a high-risk cross-border payment, got score %.2f", results[0].Score)
+		}
+	})
+}
+
+// failingCache is a cache whose reads always fail.
+type failingCache struct {
+	domain.Cache
+}
+
+func (failingCache) Get(ctx context.Context, tenantID string, key string) ([]byte, error) {
+	return nil, errors.New("cache unavailable")
+}
@@ -0,0 +1,279 @@
+// Package logging provides slog handlers for Osprey, including field redaction
+// so transaction identifiers don't leak into log aggregation systems.
+package logging
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Redaction policies applied to a log attribute.
+const (
+	PolicyKeep     = "keep"     // Log the value unchanged
+	PolicyHash     = "hash"     // Replace with a keyed hash; equal values still correlate
+	PolicyTruncate = "truncate" // Keep a short prefix
+	PolicyDrop     = "drop"     // Remove the attribute
+)
+
+// Redaction modes select a base set of policies.
+const (
+	ModeOff      = "off"      // No redaction
+	ModeStandard = "standard" // Hash party identifiers, drop names
+	ModeStrict   = "strict"   // Also hash tenant and transaction identifiers and drop scores and amounts
+)
+
+// truncateLen is how many characters PolicyTruncate keeps.
+const truncateLen = 4
+
+// standardPolicies cover party data and the alerts analysts work on.
+var standardPolicies = map[string]string{
+	"debtor_id":           PolicyHash,
+	"creditor_id":         PolicyHash,
+	"entity_id":           PolicyHash,
+	"account_id":          PolicyHash,
+	"alert_id":            PolicyHash,
+	"assignee":            PolicyHash,
+	"debtor_account_id":   PolicyHash,
+	"creditor_account_id": PolicyHash,
+	"debtor_name":         PolicyDrop,
+	"creditor_name":       PolicyDrop,
+	"client_ip":           PolicyHash,
+}
+
+// strictPolicies extend standardPolicies for production log pipelines.
+var strictPolicies = map[string]string{
+	"tenant_id":     PolicyHash,
+	"tx_id":         PolicyHash,
+	"evaluation_id": PolicyHash,
+	"message_id":    PolicyHash,
+	"id":            PolicyHash,
+	"path":          PolicyHash,
+	"trace_id":      PolicyTruncate,
+	"request_id":    PolicyTruncate,
+	"principal":     PolicyHash,
+	"score":         PolicyDrop,
+	"amount":        PolicyDrop,
+	"risk":          PolicyDrop,
+	"origin":        PolicyDrop,
+	"destination":   PolicyDrop,
+}
+
+// ValidPolicy reports whether p is a recognised redaction policy.
+func ValidPolicy(p string) bool {
+	switch p {
+	case PolicyKeep, PolicyHash, PolicyTruncate, PolicyDrop:
+		return true
+	}
+	return false
+}
+
+// Policies resolves the effective per-field policies for a redaction config.
+// Field overrides apply on top of the mode's defaults.
+func Policies(cfg domain.RedactionConfig) (map[string]string, error) {
+	policies := make(map[string]string)
+
+	switch cfg.Mode {
+	case "", ModeOff:
+	case ModeStandard:
+		merge(policies, standardPolicies)
+	case ModeStrict:
+		merge(policies, standardPolicies)
+		merge(policies, strictPolicies)
+	default:
+		return nil, fmt.Errorf("unknown redaction mode %q (want off, standard or strict)", cfg.Mode)
+	}
+
+	for field, policy := range cfg.Fields {
+		if !ValidPolicy(policy) {
+			return nil, fmt.Errorf("unknown redaction policy %q for field %q", policy, field)
+		}
+		policies[field] = policy
+	}
+
+	return policies, nil
+}
+
+func merge(dst, src map[string]string) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// ParseFields parses "field=policy,field=policy" into a map.
+func ParseFields(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		field, policy, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid redaction field %q (want field=policy)", pair)
+		}
+		fields[strings.TrimSpace(field)] = strings.TrimSpace(policy)
+	}
+	return fields, nil
+}
+
+// RedactingHandler applies redaction policies to attributes before passing
+// records to the next handler. Policies match attribute keys at any group depth.
+type RedactingHandler struct {
+	next     slog.Handler
+	policies map[string]string
+	salt     []byte
+}
+
+// NewRedactingHandler wraps next with the policies from cfg.
+// When cfg resolves to no policies, next is returned unwrapped.
+func NewRedactingHandler(next slog.Handler, cfg domain.RedactionConfig) (slog.Handler, error) {
+	policies, err := Policies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return next, nil
+	}
+	return &RedactingHandler{next: next, policies: policies, salt: []byte(cfg.Salt)}, nil
+}
+
+// Enabled reports whether the next handler handles records at level.
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record's attributes and passes it on.
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.redact(a); ok {
+			redacted.AddAttrs(a)
+		}
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts pre-bound attributes once, up front.
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	kept := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, ok := h.redact(a); ok {
+			kept = append(kept, a)
+		}
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(kept), policies: h.policies, salt: h.salt}
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name.
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), policies: h.policies, salt: h.salt}
+}
+
+// redact applies the policy for a's key. It returns false if a should be dropped.
+func (h *RedactingHandler) redact(a slog.Attr) (slog.Attr, bool) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		var kept []slog.Attr
+		for _, ga := range a.Value.Group() {
+			if ga, ok := h.redact(ga); ok {
+				kept = append(kept, ga)
+			}
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(kept...)}, true
+	}
+
+	switch h.policies[a.Key] {
+	case PolicyDrop:
+		return a, false
+	case PolicyHash:
+		return slog.String(a.Key, h.hash(a.Value.String())), true
+	case PolicyTruncate:
+		return slog.String(a.Key, truncate(a.Value.String())), true
+	}
+	return a, true
+}
+
+// hash returns a short keyed hash so equal values can still be correlated
+// across log lines without exposing them.
+func (h *RedactingHandler) hash(v string) string {
+	return keyedHash(h.salt, v)
+}
+
+func keyedHash(salt []byte, v string) string {
+	if v == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(v))
+	return "h:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Redactor applies the log redaction policies to structured data stored
+// outside the logs, such as activation samples, so stored copies of a value
+// hash the same way its log lines do.
+type Redactor struct {
+	policies map[string]string
+	salt     []byte
+}
+
+// NewRedactor creates a redactor with the policies from cfg.
+func NewRedactor(cfg domain.RedactionConfig) (*Redactor, error) {
+	policies, err := Policies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Redactor{policies: policies, salt: []byte(cfg.Salt)}, nil
+}
+
+// RedactMap returns a deep copy of m with policies applied to keys at any
+// depth. A nil Redactor only copies.
+func (r *Redactor) RedactMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		policy := ""
+		if r != nil {
+			policy = r.policies[k]
+		}
+		switch policy {
+		case PolicyDrop:
+			continue
+		case PolicyHash:
+			out[k] = keyedHash(r.salt, fmt.Sprint(v))
+		case PolicyTruncate:
+			out[k] = truncate(fmt.Sprint(v))
+		default:
+			out[k] = r.redactValue(v)
+		}
+	}
+	return out
+}
+
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return r.RedactMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = r.redactValue(e)
+		}
+		return out
+	}
+	return v
+}
+
+func truncate(v string) string {
+	if len(v) <= truncateLen {
+		return v
+	}
+	return v[:truncateLen] + "..."
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// logLine logs one record through a redacting JSON handler and decodes it.
+func logLine(t *testing.T, cfg domain.RedactionConfig, log func(*slog.Logger)) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	handler, err := NewRedactingHandler(slog.NewJSONHandler(&buf, nil), cfg)
+	if err != nil {
+		t.Fatalf("NewRedactingHandler failed: %v", err)
+	}
+	log(slog.New(handler))
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to decode log line %q: %v", buf.String(), err)
+	}
+	return line
+}
+
+func TestRedactingHandler(t *testing.T) {
+	t.Run("OffLogsUnchanged", func(t *testing.T) {
+		line := logLine(t, domain.RedactionConfig{Mode: ModeOff}, func(l *slog.Logger) {
+			l.Info("evaluated", "tenant_id", "tenant-001", "debtor_id", "user-123", "score", 0.8)
+		})
+		if line["debtor_id"] != "user-123" || line["score"] != 0.8 {
+			t.Errorf("expected unredacted fields, got %v", line)
+		}
+	})
+
+	t.Run("StandardHashesParties", func(t *testing.T) {
+		line := logLine(t, domain.RedactionConfig{Mode: ModeStandard, Salt: "s"}, func(l *slog.Logger) {
+			l.Info("evaluated", "tenant_id", "tenant-001", "debtor_id", "user-123", "account_id", "DE89370400440532013000", "debtor_name", "Jane Doe")
+		})
+		if line["tenant_id"] != "tenant-001" {
+			t.Errorf("expected tenant_id kept in standard mode, got %v", line["tenant_id"])
+		}
+		if d, _ := line["debtor_id"].(string); !strings.HasPrefix(d, "h:") || strings.Contains(d, "user-123") {
+			t.Errorf("expected hashed debtor_id, got %v", line["debtor_id"])
+		}
+		if a, _ := line["account_id"].(string); !strings.HasPrefix(a, "h:") {
+			t.Errorf("expected hashed account_id, got %v", line["account_id"])
+		}
+		if _, ok := line["debtor_name"]; ok {
+			t.Error("expected debtor_name to be dropped")
+		}
+	})
+
+	t.Run("AlertsHashedInEveryMode", func(t *testing.T) {
+		for _, mode := range []string{ModeStandard, ModeStrict} {
+			line := logLine(t, domain.RedactionConfig{Mode: mode, Salt: "s"}, func(l *slog.Logger) {
+				l.Info("alert updated", "alert_id", "eval-001", "assignee", "analyst@example.com")
+			})
+			for _, key := range []string{"alert_id", "assignee"} {
+				if v, _ := line[key].(string); !strings.HasPrefix(v, "h:") {
+					t.Errorf("expected %s hashed in %s mode, got %v", key, mode, line[key])
+				}
+			}
+		}
+	})
+
+	t.Run("StrictRedactsIdentifiersAndScores", func(t *testing.T) {
+		line := logLine(t, domain.RedactionConfig{Mode: ModeStrict, Salt: "s"}, func(l *slog.Logger) {
+			l.With("tenant_id", "tenant-001").Info("evaluated",
+				"tx_id", "tx-001",
+				"trace_id", "abcdef123456",
+				"score", 0.8,
+				"status", "ALRT",
+			)
+		})
+		if line["tenant_id"] == "tenant-001" || line["tx_id"] == "tx-001" {
+			t.Errorf("expected tenant_id and tx_id hashed, got %v", line)
+		}
+		if line["trace_id"] != "abcd..." {
+			t.Errorf("expected truncated trace_id, got %v", line["trace_id"])
+		}
+		if _, ok := line["score"]; ok {
+			t.Error("expected score to be dropped")
+		}
+		if line["status"] != "ALRT" {
+			t.Errorf("expected status kept, got %v", line["status"])
+		}
+	})
+
+	t.Run("HashesCorrelate", func(t *testing.T) {
+		cfg := domain.RedactionConfig{Mode: ModeStandard, Salt: "s"}
+		a := logLine(t, cfg, func(l *slog.Logger) { l.Info("a", "debtor_id", "user-123") })
+		b := logLine(t, cfg, func(l *slog.Logger) { l.Info("b", "creditor_id", "user-123") })
+		if a["debtor_id"] != b["creditor_id"] {
+			t.Error("expected equal values to hash identically")
+		}
+	})
+
+	t.Run("FieldOverridesAndGroups", func(t *testing.T) {
+		cfg := domain.RedactionConfig{Mode: ModeStrict, Fields: map[string]string{"score": PolicyKeep}}
+		line := logLine(t, cfg, func(l *slog.Logger) {
+			l.Info("evaluated", "score", 0.8, slog.Group("party", "debtor_id", "user-123"))
+		})
+		if line["score"] != 0.8 {
+			t.Errorf("expected override to keep score, got %v", line["score"])
+		}
+		party, _ := line["party"].(map[string]any)
+		if party["debtor_id"] == "user-123" {
+			t.Error("expected debtor_id inside group to be hashed")
+		}
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		if _, err := NewRedactingHandler(slog.Default().Handler(), domain.RedactionConfig{Mode: "paranoid"}); err == nil {
+			t.Error("expected error for unknown mode")
+		}
+		if _, err := NewRedactingHandler(slog.Default().Handler(), domain.RedactionConfig{Fields: map[string]string{"tx_id": "scramble"}}); err == nil {
+			t.Error("expected error for unknown policy")
+		}
+		if _, err := ParseFields("tx_id:hash"); err == nil {
+			t.Error("expected error for malformed field list")
+		}
+	})
+}
+
+func TestRedactor(t *testing.T) {
+	activation := map[string]any{
+		"debtor_id":   "user-123",
+		"debtor_name": "Jane Doe",
+		"amount":      5000.0,
+		"metadata": map[string]any{
+			"trace_id": "abcdef123456",
+			"parties":  []any{map[string]any{"creditor_id": "user-456"}},
+		},
+	}
+
+	t.Run("AppliesPoliciesAtAnyDepth", func(t *testing.T) {
+		r, err := NewRedactor(domain.RedactionConfig{Mode: ModeStandard, Salt: "s", Fields: map[string]string{"trace_id": PolicyTruncate}})
+		if err != nil {
+			t.Fatalf("NewRedactor failed: %v", err)
+		}
+		got := r.RedactMap(activation)
+
+		if d, _ := got["debtor_id"].(string); !strings.HasPrefix(d, "h:") {
+			t.Errorf("expected hashed debtor_id, got %v", got["debtor_id"])
+		}
+		if _, ok := got["debtor_name"]; ok {
+			t.Error("expected debtor_name to be dropped")
+		}
+		if got["amount"] != 5000.0 {
+			t.Errorf("expected amount kept in standard mode, got %v", got["amount"])
+		}
+		meta := got["metadata"].(map[string]any)
+		if meta["trace_id"] != "abcd..." {
+			t.Errorf("expected truncated trace_id, got %v", meta["trace_id"])
+		}
+		party := meta["parties"].([]any)[0].(map[string]any)
+		if c, _ := party["creditor_id"].(string); !strings.HasPrefix(c, "h:") {
+			t.Errorf("expected nested creditor_id hashed, got %v", party["creditor_id"])
+		}
+
+		if activation["debtor_id"] != "user-123" || activation["metadata"].(map[string]any)["trace_id"] != "abcdef123456" {
+			t.Error("expected the input to be left unchanged")
+		}
+	})
+
+	t.Run("NilRedactorCopies", func(t *testing.T) {
+		var r *Redactor
+		got := r.RedactMap(activation)
+		if got["debtor_name"] != "Jane Doe" {
+			t.Errorf("expected values unchanged, got %v", got)
+		}
+		got["metadata"].(map[string]any)["trace_id"] = "changed"
+		if activation["metadata"].(map[string]any)["trace_id"] != "abcdef123456" {
+			t.Error("expected a deep copy")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Package maintenance suppresses alert notifications during tenant
+// maintenance windows.
+//
+// Evaluations made while a window is active are still performed and stored,
+// but an ALRT evaluation is marked with the window's ID. The alert it
+// becomes is recorded as suppressed and never escalated, alert webhooks
+// skip it, and the worker doesn't publish it on the alert topic.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Repository marks ALRT evaluations saved during a maintenance window.
+type Repository struct {
+	domain.Repository
+	now func() time.Time
+}
+
+// Wrap returns repo with maintenance windows enforced. It must wrap the
+// alert and webhook repositories, so they see the mark.
+func Wrap(repo domain.Repository) *Repository {
+	return &Repository{Repository: repo, now: time.Now}
+}
+
+// Unwrap returns the repository the marked alerts are saved to.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveEvaluation marks an ALRT evaluation saved while one of the tenant's
+// maintenance windows is active, then saves it. If the windows can't be
+// read, the evaluation notifies as usual rather than risk a lost alert.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if eval.Status == domain.StatusAlert && eval.Metadata.SuppressedByMaintenance == "" {
+		window, err := r.Repository.ActiveMaintenanceWindow(ctx, tenantID, r.now())
+		switch {
+		case err == nil:
+			eval.Metadata.SuppressedByMaintenance = window.ID
+		case !errors.Is(err, repository.ErrNotFound):
+			slog.Warn("failed to check maintenance windows", "tenant_id", tenantID, "evaluation_id", eval.ID, "error", err)
+		}
+	}
+	return r.Repository.SaveEvaluation(ctx, tenantID, eval)
+}
@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/alerts"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/webhooks"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	clock := ospreytest.NewClock(ospreytest.Epoch)
+	base := ospreytest.NewRepository(clock)
+	repo := Wrap(webhooks.Wrap(alerts.Wrap(base), nil, nil))
+	repo.now = clock.Now
+
+	for _, webhook := range []*domain.Webhook{
+		{ID: "hook-alerts", URL: "https://example.com/alerts", Feed: domain.WebhookFeedAlerts},
+		{ID: "hook-decisions", URL: "https://example.com/decisions", Feed: domain.WebhookFeedDecisions},
+	} {
+		if err := base.SaveWebhook(ctx, "tenant-001", webhook); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+	}
+	window := &domain.MaintenanceWindow{
+		ID:        "window-1",
+		StartsAt:  clock.Now().Add(-time.Minute),
+		EndsAt:    clock.Now().Add(time.Hour),
+		Reason:    "upstream batch rerun",
+		CreatedAt: clock.Now(),
+	}
+	if err := base.SaveMaintenanceWindow(ctx, "tenant-001", window); err != nil {
+		t.Fatalf("SaveMaintenanceWindow failed: %v", err)
+	}
+
+	suppressed := &domain.Evaluation{ID: "eval-1", TxID: "tx-1", Status: domain.StatusAlert, Score: 0.9, Timestamp: clock.Now()}
+	if err := repo.SaveEvaluation(ctx, "tenant-001", suppressed); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	if suppressed.Metadata.SuppressedByMaintenance != "window-1" || tadp.ShouldAlert(suppressed) {
+		t.Errorf("expected the evaluation to be suppressed by window-1, got %q", suppressed.Metadata.SuppressedByMaintenance)
+	}
+	if stored, _ := base.GetEvaluation(ctx, "tenant-001", "eval-1"); stored == nil || stored.Metadata.SuppressedByMaintenance != "window-1" {
+		t.Errorf("expected the stored evaluation to be marked, got %+v", stored)
+	}
+
+	alert, err := alerts.NewService(base, nil, domain.AlertConfig{}).Get(ctx, "tenant-001", "eval-1")
+	if err != nil {
+		t.Fatalf("expected the alert to be recorded: %v", err)
+	}
+	if alert.SuppressedByMaintenance != "window-1" || len(alert.History) != 1 || alert.History[0].Action != domain.AlertEventSuppressed {
+		t.Errorf("expected a suppressed alert with its history, got %+v", alert)
+	}
+	due, _ := base.ListDueAlerts(ctx, clock.Now().Add(time.Hour), 3, 10)
+	if len(due) != 0 {
+		t.Errorf("expected suppressed alerts never to be due, got %+v", due)
+	}
+
+	if deliveries, _ := base.ListWebhookDeliveries(ctx, "tenant-001", "hook-alerts", 10); len(deliveries) != 0 {
+		t.Errorf("expected no alert webhook delivery, got %d", len(deliveries))
+	}
+	if deliveries, _ := base.ListWebhookDeliveries(ctx, "tenant-001", "hook-decisions", 10); len(deliveries) != 1 {
+		t.Errorf("expected the decisions webhook to get the evaluation, got %d", len(deliveries))
+	}
+
+	// Other tenants and cancelled windows notify as usual
+	other := &domain.Evaluation{ID: "eval-2", TxID: "tx-2", Status: domain.StatusAlert, Timestamp: clock.Now()}
+	if err := repo.SaveEvaluation(ctx, "tenant-002", other); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	if err := base.CancelMaintenanceWindow(ctx, "tenant-001", "window-1", "ops@example.com", clock.Now()); err != nil {
+		t.Fatalf("CancelMaintenanceWindow failed: %v", err)
+	}
+	after := &domain.Evaluation{ID: "eval-3", TxID: "tx-3", Status: domain.StatusAlert, Timestamp: clock.Now()}
+	if err := repo.SaveEvaluation(ctx, "tenant-001", after); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	for _, eval := range []*domain.Evaluation{other, after} {
+		if eval.Metadata.SuppressedByMaintenance != "" || !tadp.ShouldAlert(eval) {
+			t.Errorf("%s: expected no suppression, got %q", eval.ID, eval.Metadata.SuppressedByMaintenance)
+		}
+	}
+	if deliveries, _ := base.ListWebhookDeliveries(ctx, "tenant-001", "hook-alerts", 10); len(deliveries) != 1 {
+		t.Errorf("expected an alert delivery after the cancellation, got %d", len(deliveries))
+	}
+}
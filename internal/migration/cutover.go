@@ -0,0 +1,206 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Migration statuses.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed" // copied and consistent; the tenant was never frozen
+	StatusCutover   = "cutover"   // copied, consistent and frozen: move the tenant's traffic to the target
+	StatusFailed    = "failed"
+)
+
+// Coordinator errors.
+var (
+	ErrRunning  = errors.New("a migration of the tenant is running")
+	ErrNotFound = errors.New("no migration of the tenant")
+)
+
+// Migration is a tenant migration run by a Coordinator.
+type Migration struct {
+	TenantID   string     `json:"tenantId"`
+	Cutover    bool       `json:"cutover"`
+	Status     string     `json:"status"`
+	Frozen     bool       `json:"frozen"` // the tenant's writes are rejected
+	Error      string     `json:"error,omitempty"`
+	Report     *Report    `json:"report,omitempty"`
+	StartedBy  string     `json:"startedBy,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Coordinator runs tenant migrations in the background and tracks which
+// tenants are frozen for cutover. State is kept in memory, so a freeze only
+// holds on the instance that ran the migration.
+type Coordinator struct {
+	migrator *Migrator
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	migrations map[string]*Migration
+}
+
+// NewCoordinator creates a coordinator that migrates with m.
+func NewCoordinator(m *Migrator) *Coordinator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Coordinator{
+		migrator:   m,
+		ctx:        ctx,
+		cancel:     cancel,
+		migrations: make(map[string]*Migration),
+	}
+}
+
+// Start migrates a tenant in the background and returns the migration as
+// it starts. With cutover, the tenant is frozen for a second pass once the
+// first finishes, and stays frozen if the result is consistent.
+func (c *Coordinator) Start(tenantID string, cutover bool, by string) (*Migration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.migrations[tenantID]; ok && (existing.Status == StatusRunning || existing.Frozen) {
+		return nil, ErrRunning
+	}
+	migration := &Migration{
+		TenantID:  tenantID,
+		Cutover:   cutover,
+		Status:    StatusRunning,
+		StartedBy: by,
+		StartedAt: c.migrator.now().UTC(),
+	}
+	c.migrations[tenantID] = migration
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(tenantID, cutover)
+	}()
+	return copyMigration(migration), nil
+}
+
+// run copies the tenant and, for a cutover, copies again while it is frozen.
+func (c *Coordinator) run(tenantID string, cutover bool) {
+	report, err := c.migrator.Run(c.ctx, tenantID)
+	if err == nil && cutover {
+		c.update(tenantID, func(m *Migration) { m.Frozen = true })
+		slog.Info("tenant frozen for migration cutover", "tenant_id", tenantID)
+		// The second pass picks up what was written during the first
+		first := report
+		if report, err = c.migrator.Run(c.ctx, tenantID); err == nil {
+			mergeReports(first, report)
+		}
+	}
+
+	c.update(tenantID, func(m *Migration) {
+		finished := c.migrator.now().UTC()
+		m.FinishedAt = &finished
+		m.Report = report
+		switch {
+		case err != nil:
+			m.Status, m.Error, m.Frozen = StatusFailed, err.Error(), false
+		case !report.Consistent:
+			m.Status, m.Error, m.Frozen = StatusFailed, "record counts differ between source and target", false
+		case cutover:
+			m.Status = StatusCutover
+		default:
+			m.Status = StatusCompleted
+		}
+		slog.Info("tenant migration finished", "tenant_id", tenantID, "status", m.Status, "frozen", m.Frozen, "error", m.Error)
+	})
+}
+
+func (c *Coordinator) update(tenantID string, fn func(*Migration)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.migrations[tenantID]; ok {
+		fn(m)
+	}
+}
+
+// Get returns the tenant's latest migration.
+func (c *Coordinator) Get(tenantID string) (*Migration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.migrations[tenantID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyMigration(m), nil
+}
+
+// List returns each tenant's latest migration by tenant ID.
+func (c *Coordinator) List() []*Migration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]*Migration, 0, len(c.migrations))
+	for _, m := range c.migrations {
+		out = append(out, copyMigration(m))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TenantID < out[j].TenantID })
+	return out
+}
+
+// Release lifts the tenant's freeze and forgets its migration. A running
+// migration can't be released.
+func (c *Coordinator) Release(tenantID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.migrations[tenantID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.Status == StatusRunning {
+		return ErrRunning
+	}
+	delete(c.migrations, tenantID)
+	return nil
+}
+
+// Frozen reports whether the tenant's writes must be rejected.
+func (c *Coordinator) Frozen(tenantID string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.migrations[tenantID]
+	return ok && m.Frozen
+}
+
+// Stop interrupts running migrations and waits for them to return.
+func (c *Coordinator) Stop() {
+	if c == nil {
+		return
+	}
+	c.cancel()
+	c.wg.Wait()
+}
+
+// mergeReports makes the final pass of a cutover report both passes: what
+// either copied, and what was in the target before the first.
+func mergeReports(first, final *Report) {
+	final.StartedAt = first.StartedAt
+	for i := range final.Kinds {
+		final.Kinds[i].Copied += first.Kinds[i].Copied
+		final.Kinds[i].Skipped = first.Kinds[i].Skipped
+	}
+}
+
+func copyMigration(m *Migration) *Migration {
+	out := *m
+	if m.Report != nil {
+		report := *m.Report
+		report.Kinds = append([]KindReport(nil), m.Report.Kinds...)
+		out.Report = &report
+	}
+	return &out
+}
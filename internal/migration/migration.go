@@ -0,0 +1,243 @@
+// Package migration copies a tenant's data from one repository to another,
+// such as from the Community tier's SQLite database to the Pro tier's
+// PostgreSQL database.
+//
+// A copy takes the tenant's rules, typologies, transactions and
+// evaluations. Records already in the target are skipped, so a copy that
+// was interrupted can simply be run again. After copying, the tenant's
+// records are counted in both repositories; the migration is consistent
+// when every count matches.
+//
+// A cutover migration coordinates the switch: it copies while the tenant
+// stays live, then freezes the tenant's writes, copies what arrived
+// meanwhile and checks consistency. A consistent tenant stays frozen until
+// released, so nothing is written to the old store after its traffic moves.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// DefaultBatchSize is how many transactions or evaluations are read per query.
+const DefaultBatchSize = 500
+
+// Kinds of records copied, in copy order.
+const (
+	KindRules        = "rules"
+	KindTypologies   = "typologies"
+	KindTransactions = "transactions"
+	KindEvaluations  = "evaluations"
+)
+
+// endOfTime bounds transaction listings from above.
+var endOfTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// KindReport is the result of copying one kind of record.
+type KindReport struct {
+	Kind       string `json:"kind"`
+	Copied     int    `json:"copied"`
+	Skipped    int    `json:"skipped"` // already in the target
+	Source     int    `json:"source"`  // records in the source after copying
+	Target     int    `json:"target"`  // records in the target after copying
+	Consistent bool   `json:"consistent"`
+}
+
+// Report is the result of copying a tenant.
+type Report struct {
+	TenantID   string       `json:"tenantId"`
+	Kinds      []KindReport `json:"kinds"`
+	Consistent bool         `json:"consistent"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+}
+
+// Migrator copies tenants from a source to a target repository. The target
+// should be an unwrapped repository, so copied evaluations raise no alerts
+// or webhooks.
+type Migrator struct {
+	source    domain.Repository
+	target    domain.Repository
+	batchSize int
+	now       func() time.Time
+}
+
+// New creates a migrator from source to target.
+func New(source, target domain.Repository) *Migrator {
+	return &Migrator{
+		source:    source,
+		target:    target,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// Run copies the tenant's records to the target and checks that both
+// repositories hold the same number of each kind.
+func (m *Migrator) Run(ctx context.Context, tenantID string) (*Report, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", repository.ErrInvalidInput)
+	}
+
+	report := &Report{TenantID: tenantID, StartedAt: m.now().UTC()}
+	steps := []struct {
+		kind  string
+		copy  func(context.Context, string, *KindReport) error
+		count func(context.Context, domain.Repository, string) (int, error)
+	}{
+		{KindRules, m.copyRules, countRules},
+		{KindTypologies, m.copyTypologies, countTypologies},
+		{KindTransactions, m.copyTransactions, countTransactions},
+		{KindEvaluations, m.copyEvaluations, m.countEvaluations},
+	}
+
+	for _, step := range steps {
+		kind := KindReport{Kind: step.kind}
+		if err := step.copy(ctx, tenantID, &kind); err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", step.kind, err)
+		}
+		source, err := step.count(ctx, m.source, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count source %s: %w", step.kind, err)
+		}
+		target, err := step.count(ctx, m.target, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count target %s: %w", step.kind, err)
+		}
+		kind.Source, kind.Target = source, target
+		kind.Consistent = source == target
+		report.Kinds = append(report.Kinds, kind)
+	}
+
+	report.Consistent = true
+	for _, kind := range report.Kinds {
+		report.Consistent = report.Consistent && kind.Consistent
+	}
+	report.FinishedAt = m.now().UTC()
+	return report, nil
+}
+
+// copyRules upserts the tenant's active rules, every version.
+func (m *Migrator) copyRules(ctx context.Context, tenantID string, report *KindReport) error {
+	rules, err := m.source.ListRuleConfigs(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := m.target.SaveRuleConfig(ctx, tenantID, rule); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		report.Copied++
+	}
+	return nil
+}
+
+// copyTypologies upserts the tenant's active typologies.
+func (m *Migrator) copyTypologies(ctx context.Context, tenantID string, report *KindReport) error {
+	typologies, err := m.source.ListTypologies(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, typology := range typologies {
+		if err := m.target.SaveTypology(ctx, tenantID, typology); err != nil {
+			return fmt.Errorf("typology %s: %w", typology.ID, err)
+		}
+		report.Copied++
+	}
+	return nil
+}
+
+// copyTransactions inserts the tenant's transactions missing from the target.
+func (m *Migrator) copyTransactions(ctx context.Context, tenantID string, report *KindReport) error {
+	for offset := 0; ; offset += m.batchSize {
+		txs, err := m.source.ListTransactions(ctx, tenantID, time.Time{}, endOfTime, offset, m.batchSize)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			_, err := m.target.GetTransaction(ctx, tenantID, tx.ID)
+			switch {
+			case err == nil:
+				report.Skipped++
+				continue
+			case !errors.Is(err, repository.ErrNotFound):
+				return fmt.Errorf("transaction %s: %w", tx.ID, err)
+			}
+			if err := m.target.SaveTransaction(ctx, tenantID, tx); err != nil {
+				return fmt.Errorf("transaction %s: %w", tx.ID, err)
+			}
+			report.Copied++
+		}
+		if len(txs) < m.batchSize {
+			return nil
+		}
+	}
+}
+
+// copyEvaluations inserts the tenant's evaluations missing from the target.
+// Transactions are copied first, so each evaluation finds its transaction.
+func (m *Migrator) copyEvaluations(ctx context.Context, tenantID string, report *KindReport) error {
+	return m.eachEvaluation(ctx, m.source, tenantID, func(eval *domain.Evaluation) error {
+		_, err := m.target.GetEvaluation(ctx, tenantID, eval.ID)
+		switch {
+		case err == nil:
+			report.Skipped++
+			return nil
+		case !errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("evaluation %s: %w", eval.ID, err)
+		}
+		if err := m.target.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			return fmt.Errorf("evaluation %s: %w", eval.ID, err)
+		}
+		report.Copied++
+		return nil
+	})
+}
+
+// eachEvaluation calls fn with each of the tenant's evaluations, latest first.
+func (m *Migrator) eachEvaluation(ctx context.Context, repo domain.Repository, tenantID string, fn func(*domain.Evaluation) error) error {
+	filter := domain.EvaluationFilter{Limit: m.batchSize}
+	for {
+		evals, err := repo.ListEvaluations(ctx, tenantID, filter)
+		if err != nil {
+			return err
+		}
+		for _, eval := range evals {
+			if err := fn(eval); err != nil {
+				return err
+			}
+		}
+		if len(evals) < m.batchSize {
+			return nil
+		}
+		filter.After = domain.CursorOf(evals[len(evals)-1])
+	}
+}
+
+func countRules(ctx context.Context, repo domain.Repository, tenantID string) (int, error) {
+	rules, err := repo.ListRuleConfigs(ctx, tenantID)
+	return len(rules), err
+}
+
+func countTypologies(ctx context.Context, repo domain.Repository, tenantID string) (int, error) {
+	typologies, err := repo.ListTypologies(ctx, tenantID)
+	return len(typologies), err
+}
+
+func countTransactions(ctx context.Context, repo domain.Repository, tenantID string) (int, error) {
+	return repo.CountTransactions(ctx, tenantID, time.Time{}, endOfTime)
+}
+
+func (m *Migrator) countEvaluations(ctx context.Context, repo domain.Repository, tenantID string) (int, error) {
+	count := 0
+	err := m.eachEvaluation(ctx, repo, tenantID, func(*domain.Evaluation) error {
+		count++
+		return nil
+	})
+	return count, err
+}
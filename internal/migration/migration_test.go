@@ -0,0 +1,154 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func newRepo(t *testing.T) domain.Repository {
+	t.Helper()
+	repo, err := repository.New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// seed saves a rule, a typology and transactions first to last - 1 with
+// evaluations.
+func seed(t *testing.T, repo domain.Repository, tenantID string, first, last int) {
+	t.Helper()
+	ctx := context.Background()
+	if err := repo.SaveRuleConfig(ctx, tenantID, &domain.RuleConfig{ID: "high-value", Name: "High value", Version: "1.0.0", Expression: "amount > 10000", Enabled: true}); err != nil {
+		t.Fatalf("SaveRuleConfig failed: %v", err)
+	}
+	if err := repo.SaveTypology(ctx, tenantID, &domain.Typology{ID: "structuring", Name: "Structuring", Version: "1.0.0", Rules: []domain.TypologyRuleWeight{{RuleID: "high-value", Weight: 1}}, AlertThreshold: 0.5, Enabled: true}); err != nil {
+		t.Fatalf("SaveTypology failed: %v", err)
+	}
+	for i := first; i < last; i++ {
+		id := fmt.Sprintf("%s-tx-%03d", tenantID, i)
+		tx := ospreytest.NewTransaction().ID(id).Tenant(tenantID).At(ospreytest.Epoch.Add(time.Duration(i) * time.Minute)).Build()
+		if err := repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		eval := &domain.Evaluation{ID: id + "-eval", TxID: id, Status: domain.StatusNoAlert, Score: 0.1, Timestamp: tx.Timestamp}
+		if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	source, target := newRepo(t), newRepo(t)
+	seed(t, source, "tenant-001", 0, 7)
+	seed(t, source, "tenant-002", 0, 2)
+
+	m := New(source, target)
+	m.batchSize = 3
+
+	report, err := m.Run(ctx, "tenant-001")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !report.Consistent || len(report.Kinds) != 4 {
+		t.Fatalf("expected a consistent report of four kinds, got %+v", report)
+	}
+	for _, kind := range report.Kinds {
+		want := map[string]int{KindRules: 1, KindTypologies: 1, KindTransactions: 7, KindEvaluations: 7}[kind.Kind]
+		if kind.Copied != want || kind.Source != want || kind.Target != want {
+			t.Errorf("expected %d %s copied, got %+v", want, kind.Kind, kind)
+		}
+	}
+	if _, err := target.GetEvaluation(ctx, "tenant-001", "tenant-001-tx-006-eval"); err != nil {
+		t.Errorf("expected the evaluation in the target: %v", err)
+	}
+	if n, _ := target.CountTransactions(ctx, "tenant-002", time.Time{}, endOfTime); n != 0 {
+		t.Errorf("expected other tenants not to be copied, got %d transactions", n)
+	}
+
+	t.Run("RerunSkipsCopied", func(t *testing.T) {
+		seed(t, source, "tenant-001", 7, 9)
+		report, err := m.Run(ctx, "tenant-001")
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if tx := report.Kinds[2]; !report.Consistent || tx.Copied != 2 || tx.Skipped != 7 {
+			t.Errorf("expected two new transactions copied and seven skipped, got %+v", report)
+		}
+	})
+
+	t.Run("Inconsistent", func(t *testing.T) {
+		extra := ospreytest.NewTransaction().ID("stray").Tenant("tenant-001").Build()
+		target.SaveTransaction(ctx, "tenant-001", extra)
+		report, err := m.Run(ctx, "tenant-001")
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if report.Consistent || report.Kinds[2].Consistent || report.Kinds[2].Target != report.Kinds[2].Source+1 {
+			t.Errorf("expected a stray target transaction to break consistency, got %+v", report)
+		}
+	})
+
+	if _, err := m.Run(ctx, ""); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Errorf("expected ErrInvalidInput without a tenant, got %v", err)
+	}
+}
+
+func TestCoordinator(t *testing.T) {
+	source, target := newRepo(t), newRepo(t)
+	seed(t, source, "tenant-001", 0, 3)
+	c := NewCoordinator(New(source, target))
+	defer c.Stop()
+
+	wait := func(tenantID string) *Migration {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if m, err := c.Get(tenantID); err == nil && m.Status != StatusRunning {
+				return m
+			}
+		}
+		t.Fatalf("migration of %s did not finish", tenantID)
+		return nil
+	}
+
+	if _, err := c.Start("tenant-001", true, "ops"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	m := wait("tenant-001")
+	if m.Status != StatusCutover || !m.Frozen || !c.Frozen("tenant-001") || !m.Report.Consistent || m.StartedBy != "ops" {
+		t.Fatalf("expected a consistent, frozen cutover, got %+v", m)
+	}
+	if _, err := c.Start("tenant-001", false, ""); !errors.Is(err, ErrRunning) {
+		t.Errorf("expected ErrRunning while frozen, got %v", err)
+	}
+
+	if err := c.Release("tenant-001"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if c.Frozen("tenant-001") {
+		t.Error("expected the release to lift the freeze")
+	}
+	if err := c.Release("tenant-001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// A plain copy never freezes
+	if _, err := c.Start("tenant-001", false, ""); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if m := wait("tenant-001"); m.Status != StatusCompleted || m.Frozen {
+		t.Errorf("expected a completed, unfrozen migration, got %+v", m)
+	}
+	if list := c.List(); len(list) != 1 {
+		t.Errorf("expected one migration, got %d", len(list))
+	}
+}
@@ -0,0 +1,157 @@
+// Package ml calls an external model to score transactions for the decision
+// processor, so teams with trained models can blend them with their rules
+// instead of choosing one or the other.
+//
+// The model is sent the transaction and the rule results, and answers with
+// a score from 0 to 1: over HTTP as a JSON POST, or over gRPC as
+// google.protobuf.Struct messages of the same shape, as declared in
+// proto/osprey/ml/v1/scorer.proto.
+package ml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxResponseBytes caps the size of a model's answer.
+const maxResponseBytes = 1 << 16
+
+// Scorer scores transactions with an external model.
+type Scorer struct {
+	timeout time.Duration
+	call    func(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error)
+	closer  func() error
+}
+
+// New returns a scorer for the configured model, or nil when none is.
+func New(cfg domain.MLConfig) (*Scorer, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = domain.DefaultConfig().ML.Timeout
+	}
+
+	switch cfg.Protocol {
+	case domain.MLProtocolHTTP, "":
+		client := &http.Client{}
+		return &Scorer{
+			timeout: cfg.Timeout,
+			call: func(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error) {
+				return postJSON(ctx, client, cfg.URL, req)
+			},
+			closer: func() error { return nil },
+		}, nil
+	case domain.MLProtocolGRPC:
+		if cfg.Method == "" {
+			cfg.Method = domain.DefaultConfig().ML.Method
+		}
+		conn, err := grpc.NewClient(cfg.URL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ML gRPC target: %w", err)
+		}
+		return &Scorer{
+			timeout: cfg.Timeout,
+			call: func(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error) {
+				return invoke(ctx, conn, cfg.Method, req)
+			},
+			closer: conn.Close,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown ML protocol %q (want %s or %s)", cfg.Protocol, domain.MLProtocolHTTP, domain.MLProtocolGRPC)
+}
+
+// Score asks the model to score a transaction within the timeout. Scores
+// outside 0 to 1 are errors.
+func (s *Scorer) Score(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	score, err := s.call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(score.Score) || score.Score < 0 || score.Score > 1 {
+		return nil, fmt.Errorf("model score %v is outside 0 to 1", score.Score)
+	}
+	return score, nil
+}
+
+// Close releases the scorer's connection.
+func (s *Scorer) Close() error {
+	return s.closer()
+}
+
+// postJSON POSTs the request to an HTTP model.
+func postJSON(ctx context.Context, client *http.Client, url string, req *domain.MLRequest) (*domain.MLScore, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("model answered %d", resp.StatusCode)
+	}
+	var score domain.MLScore
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&score); err != nil {
+		return nil, fmt.Errorf("invalid model response: %w", err)
+	}
+	return &score, nil
+}
+
+// invoke calls a gRPC model with the request as a google.protobuf.Struct.
+func invoke(ctx context.Context, conn *grpc.ClientConn, method string, req *domain.MLRequest) (*domain.MLScore, error) {
+	in, err := toStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	out := &structpb.Struct{}
+	if err := conn.Invoke(ctx, method, in, out); err != nil {
+		return nil, err
+	}
+
+	data, err := out.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var score domain.MLScore
+	if err := json.Unmarshal(data, &score); err != nil {
+		return nil, fmt.Errorf("invalid model response: %w", err)
+	}
+	return &score, nil
+}
+
+// toStruct converts v to a google.protobuf.Struct through its JSON form.
+func toStruct(v any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
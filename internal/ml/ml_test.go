@@ -0,0 +1,125 @@
+package ml
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var request = &domain.MLRequest{
+	TenantID:    "tenant-001",
+	TxID:        "tx-001",
+	Transaction: &domain.Transaction{ID: "tx-001", Amount: domain.MustDecimal("9500.25"), Currency: "USD"},
+	RuleScore:   0.4,
+	RuleResults: []domain.MLRuleResult{{RuleID: "rule-1", Score: 0.4, Outcome: ".pass", Weight: 1}},
+}
+
+func TestHTTP(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req domain.MLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Transaction.Amount.String() != "9500.25" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/score":
+			w.Write([]byte(`{"score": 0.87, "model": "xgb-1"}`))
+		case "/invalid":
+			w.Write([]byte(`{"score": 1.5}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(`{"score": 0.1}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	scorer, err := New(domain.MLConfig{URL: server.URL + "/score", Protocol: domain.MLProtocolHTTP, Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	score, err := scorer.Score(ctx, request)
+	if err != nil || score.Score != 0.87 || score.Model != "xgb-1" {
+		t.Errorf("expected 0.87 from xgb-1, got %+v, %v", score, err)
+	}
+
+	for _, path := range []string{"/invalid", "/slow", "/down"} {
+		scorer, _ := New(domain.MLConfig{URL: server.URL + path, Timeout: 50 * time.Millisecond})
+		if _, err := scorer.Score(ctx, request); err == nil {
+			t.Errorf("expected an error from %s", path)
+		}
+	}
+
+	if scorer, err := New(domain.MLConfig{}); scorer != nil || err != nil {
+		t.Errorf("expected no scorer without a URL, got %v, %v", scorer, err)
+	}
+	if _, err := New(domain.MLConfig{URL: "model:9000", Protocol: "thrift"}); err == nil {
+		t.Error("expected an error for an unknown protocol")
+	}
+}
+
+// scorerServer is the Scorer service of proto/osprey/ml/v1/scorer.proto.
+type scorerServer interface {
+	Score(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error)
+}
+
+type modelServer struct{}
+
+func (modelServer) Score(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	score := 0.2
+	if in.Fields["ruleScore"].GetNumberValue() > 0.3 {
+		score = 0.95
+	}
+	return structpb.NewStruct(map[string]any{"score": score, "model": "grpc-model"})
+}
+
+func TestGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "osprey.ml.v1.Scorer",
+		HandlerType: (*scorerServer)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Score",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				in := &structpb.Struct{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(scorerServer).Score(ctx, in)
+			},
+		}},
+	}, modelServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	scorer, err := New(domain.MLConfig{URL: lis.Addr().String(), Protocol: domain.MLProtocolGRPC, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer scorer.Close()
+
+	score, err := scorer.Score(context.Background(), request)
+	if err != nil || score.Score != 0.95 || score.Model != "grpc-model" {
+		t.Errorf("expected 0.95 from grpc-model, got %+v, %v", score, err)
+	}
+
+	other, _ := New(domain.MLConfig{URL: lis.Addr().String(), Protocol: domain.MLProtocolGRPC, Method: "/osprey.ml.v1.Scorer/Missing", Timeout: time.Second})
+	defer other.Close()
+	if _, err := other.Score(context.Background(), request); err == nil {
+		t.Error("expected an error from an unknown method")
+	}
+}
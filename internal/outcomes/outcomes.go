@@ -0,0 +1,300 @@
+// Package outcomes ingests chargebacks and returns from downstream systems,
+// remembers each party's past losses for rules and reports losses by the
+// rules and typologies that fired on them.
+//
+// Imported events are linked to the latest evaluation of their transaction
+// and labelled caught or missed from its decision, so labelled losses can be
+// pulled for calibration with GET /outcomes?label=missed.
+package outcomes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// DefaultLookback is how far back the enricher counts a party's losses.
+const DefaultLookback = 180 * 24 * time.Hour
+
+// MaxReportLosses caps the losses of each type read by a loss report.
+const MaxReportLosses = 10000
+
+// maxPartyLosses caps the losses counted per party; more than this many
+// is as telling as exactly this many.
+const maxPartyLosses = 1000
+
+// Service imports outcome events and reads them back for rules and reports.
+type Service struct {
+	repo     domain.Repository
+	lookback time.Duration
+}
+
+// NewService creates a new outcome service. lookback bounds the losses the
+// enricher counts; 0 means DefaultLookback.
+func NewService(repo domain.Repository, lookback time.Duration) *Service {
+	if lookback <= 0 {
+		lookback = DefaultLookback
+	}
+	return &Service{repo: repo, lookback: lookback}
+}
+
+// Import stores chargeback and return events, each linked to the latest
+// evaluation of its transaction. Events are imported independently; the
+// results are in the order of events. An event whose ID was imported before
+// is reported as imported and left unchanged.
+func (s *Service) Import(ctx context.Context, tenantID string, principal string, events []domain.OutcomeEvent) ([]domain.OutcomeImportResult, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	now := time.Now().UTC()
+	results := make([]domain.OutcomeImportResult, len(events))
+	for i, event := range events {
+		results[i] = s.importEvent(ctx, tenantID, principal, event, now)
+	}
+	return results, nil
+}
+
+func (s *Service) importEvent(ctx context.Context, tenantID string, principal string, event domain.OutcomeEvent, now time.Time) domain.OutcomeImportResult {
+	result := domain.OutcomeImportResult{ID: event.ID, TxID: event.TxID}
+	if err := validateEvent(event, now); err != nil {
+		result.Status = domain.OutcomeImportInvalid
+		result.Error = err.Error()
+		return result
+	}
+
+	eval, err := s.repo.GetEvaluationByTx(ctx, tenantID, event.TxID)
+	if errors.Is(err, repository.ErrNotFound) {
+		result.Status = domain.OutcomeImportNotFound
+		result.Error = "no evaluation of the transaction"
+		return result
+	}
+	if err != nil {
+		result.Status = domain.OutcomeImportFailed
+		result.Error = "failed to get evaluation"
+		return result
+	}
+
+	outcome := &domain.EvaluationOutcome{
+		ID:           event.ID,
+		TenantID:     tenantID,
+		EvaluationID: eval.ID,
+		TxID:         eval.TxID,
+		Outcome:      event.Outcome,
+		Reason:       event.Reason,
+		ReasonCode:   event.ReasonCode,
+		Label:        domain.OutcomeLabel(event.Outcome, eval.Status),
+		Amount:       event.Amount,
+		ReportedBy:   principal,
+		OccurredAt:   event.OccurredAt.UTC(),
+		CreatedAt:    now,
+	}
+	if event.OccurredAt.IsZero() {
+		outcome.OccurredAt = now
+	}
+	if tx, err := s.repo.GetTransaction(ctx, tenantID, eval.TxID); err == nil {
+		outcome.DebtorID = tx.DebtorID
+		outcome.CreditorID = tx.CreditorID
+	}
+
+	if err := s.repo.SaveOutcome(ctx, tenantID, outcome); err != nil {
+		result.Status = domain.OutcomeImportFailed
+		result.Error = "failed to save outcome"
+		return result
+	}
+
+	result.Status = domain.OutcomeImportImported
+	result.EvaluationID = eval.ID
+	result.Label = outcome.Label
+	return result
+}
+
+// validateEvent checks an event before it is linked to an evaluation.
+func validateEvent(event domain.OutcomeEvent, now time.Time) error {
+	switch {
+	case event.ID == "":
+		return fmt.Errorf("id is required")
+	case event.TxID == "":
+		return fmt.Errorf("txId is required")
+	case !domain.IsLossOutcome(event.Outcome):
+		return fmt.Errorf("outcome must be %s or %s", domain.OutcomePaymentReturned, domain.OutcomeChargeback)
+	case event.Amount.Sign() < 0:
+		return fmt.Errorf("amount cannot be negative")
+	case event.OccurredAt.After(now):
+		return fmt.Errorf("occurredAt cannot be in the future")
+	}
+	return nil
+}
+
+// PartyLosses counts a party's chargebacks and returns, as debtor or
+// creditor, over the service's lookback.
+func (s *Service) PartyLosses(ctx context.Context, tenantID string, partyID string) (chargebacks, returns int, err error) {
+	if tenantID == "" {
+		return 0, 0, fmt.Errorf("tenantID is required")
+	}
+	if s.repo == nil {
+		return 0, 0, fmt.Errorf("no data source available")
+	}
+	if partyID == "" {
+		return 0, 0, nil
+	}
+
+	since := time.Now().UTC().Add(-s.lookback)
+	counts := make(map[string]int, len(domain.LossOutcomes))
+	for _, t := range domain.LossOutcomes {
+		losses, err := s.repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{
+			PartyID: partyID,
+			Outcome: t,
+			Since:   since,
+			Limit:   maxPartyLosses,
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list outcomes: %w", err)
+		}
+		counts[t] = len(losses)
+	}
+	return counts[domain.OutcomeChargeback], counts[domain.OutcomePaymentReturned], nil
+}
+
+// Losses reports the tenant's losses since the given time. Each loss counts
+// towards every rule that failed or flagged for review on its evaluation,
+// shadow rules included, and every typology that triggered.
+func (s *Service) Losses(ctx context.Context, tenantID string, since time.Time) (*domain.LossReport, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	report := &domain.LossReport{Since: since.UTC()}
+	var losses []*domain.EvaluationOutcome
+	for _, t := range domain.LossOutcomes {
+		outcomes, err := s.repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{
+			Outcome: t,
+			Since:   since,
+			Limit:   MaxReportLosses,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list outcomes: %w", err)
+		}
+		if len(outcomes) == MaxReportLosses {
+			report.Truncated = true
+		}
+		losses = append(losses, outcomes...)
+	}
+
+	byRule := make(map[string]*domain.LossBreakdown)
+	byTypology := make(map[string]*domain.LossBreakdown)
+	evaluations := make(map[string]*domain.Evaluation)
+	for _, loss := range losses {
+		report.Losses++
+		report.Amount = report.Amount.Add(loss.Amount)
+		switch loss.Label {
+		case domain.OutcomeLabelCaught:
+			report.Caught++
+		case domain.OutcomeLabelMissed:
+			report.Missed++
+		}
+
+		eval, ok := evaluations[loss.EvaluationID]
+		if !ok {
+			var err error
+			eval, err = s.repo.GetEvaluation(ctx, tenantID, loss.EvaluationID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("failed to get evaluation: %w", err)
+			}
+			evaluations[loss.EvaluationID] = eval
+		}
+		if eval == nil {
+			continue
+		}
+
+		for _, r := range eval.RuleResults {
+			if r.SubRuleRef == domain.RuleOutcomeFail || r.SubRuleRef == domain.RuleOutcomeReview {
+				addLoss(byRule, r.RuleID, loss.Amount)
+			}
+		}
+		for _, t := range eval.TypologyResults {
+			if t.Triggered {
+				addLoss(byTypology, t.TypologyID, loss.Amount)
+			}
+		}
+	}
+
+	report.Rules = sortedBreakdown(byRule)
+	report.Typologies = sortedBreakdown(byTypology)
+	return report, nil
+}
+
+func addLoss(breakdown map[string]*domain.LossBreakdown, id string, amount domain.Decimal) {
+	b, ok := breakdown[id]
+	if !ok {
+		b = &domain.LossBreakdown{ID: id}
+		breakdown[id] = b
+	}
+	b.Losses++
+	b.Amount = b.Amount.Add(amount)
+}
+
+// sortedBreakdown orders a breakdown by amount, largest first, then by ID.
+// It is never nil, so reports always list rules and typologies.
+func sortedBreakdown(breakdown map[string]*domain.LossBreakdown) []domain.LossBreakdown {
+	out := make([]domain.LossBreakdown, 0, len(breakdown))
+	for _, b := range breakdown {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := out[i].Amount.Cmp(out[j].Amount); c != 0 {
+			return c > 0
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Enricher returns a rules.Enricher exposing debtor_prior_chargebacks,
+// debtor_prior_returns, creditor_prior_chargebacks and
+// creditor_prior_returns to CEL.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "outcomes"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"debtor_prior_chargebacks":   cel.IntType,
+		"debtor_prior_returns":       cel.IntType,
+		"creditor_prior_chargebacks": cel.IntType,
+		"creditor_prior_returns":     cel.IntType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	debtorChargebacks, debtorReturns, debtorErr := e.svc.PartyLosses(ctx, input.TenantID, input.DebtorID)
+	creditorChargebacks, creditorReturns, creditorErr := e.svc.PartyLosses(ctx, input.TenantID, input.CreditorID)
+
+	activation["debtor_prior_chargebacks"] = int64(debtorChargebacks)
+	activation["debtor_prior_returns"] = int64(debtorReturns)
+	activation["creditor_prior_chargebacks"] = int64(creditorChargebacks)
+	activation["creditor_prior_returns"] = int64(creditorReturns)
+
+	return errors.Join(debtorErr, creditorErr)
+}
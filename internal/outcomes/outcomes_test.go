@@ -0,0 +1,157 @@
+package outcomes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestOutcomeService(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "tenant-001"
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo, 0)
+	now := time.Now().UTC()
+
+	repo.SaveTransaction(ctx, tenantID, ospreytest.NewTransaction().ID("tx-001").Tenant(tenantID).From("cust-001").To("shop-001").Build())
+	repo.SaveTransaction(ctx, tenantID, ospreytest.NewTransaction().ID("tx-002").Tenant(tenantID).From("cust-001").To("shop-002").Build())
+	for _, eval := range []*domain.Evaluation{
+		{ID: "eval-001", TxID: "tx-001", Status: domain.StatusAlert, Timestamp: now.Add(-2 * time.Hour), RuleResults: []domain.RuleResult{
+			{RuleID: "high-value", SubRuleRef: domain.RuleOutcomeFail},
+			{RuleID: "new-device", SubRuleRef: domain.RuleOutcomeReview, Shadow: true},
+			{RuleID: "velocity", SubRuleRef: domain.RuleOutcomePass},
+		}, TypologyResults: []domain.TypologyResult{
+			{TypologyID: "card-fraud", Triggered: true},
+			{TypologyID: "mule", Triggered: false},
+		}},
+		{ID: "eval-002", TxID: "tx-002", Status: domain.StatusNoAlert, Timestamp: now.Add(-time.Hour)},
+	} {
+		if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+
+	t.Run("Import", func(t *testing.T) {
+		results, err := svc.Import(ctx, tenantID, "ops@example.com", []domain.OutcomeEvent{
+			{ID: "cb-1", TxID: "tx-001", Outcome: domain.OutcomeChargeback, ReasonCode: "10.4", Amount: domain.MustDecimal("100.1")},
+			{ID: "rt-1", TxID: "tx-002", Outcome: domain.OutcomePaymentReturned, ReasonCode: "R10", Amount: domain.MustDecimal("39.2")},
+			{ID: "cb-2", TxID: "tx-999", Outcome: domain.OutcomeChargeback},
+			{ID: "cb-3", TxID: "tx-001", Outcome: domain.OutcomeChallengePassed},
+			{TxID: "tx-001", Outcome: domain.OutcomeChargeback},
+			{ID: "cb-4", TxID: "tx-001", Outcome: domain.OutcomeChargeback, OccurredAt: now.Add(time.Hour)},
+		})
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+
+		want := []struct{ status, label string }{
+			{domain.OutcomeImportImported, domain.OutcomeLabelCaught},
+			{domain.OutcomeImportImported, domain.OutcomeLabelMissed},
+			{domain.OutcomeImportNotFound, ""},
+			{domain.OutcomeImportInvalid, ""},
+			{domain.OutcomeImportInvalid, ""},
+			{domain.OutcomeImportInvalid, ""},
+		}
+		for i, w := range want {
+			if results[i].Status != w.status || results[i].Label != w.label {
+				t.Errorf("event %d: expected %s/%q, got %+v", i, w.status, w.label, results[i])
+			}
+		}
+
+		outcomes, _ := repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{EvaluationID: "eval-001"})
+		if len(outcomes) != 1 || outcomes[0].ID != "cb-1" || outcomes[0].ReasonCode != "10.4" || outcomes[0].DebtorID != "cust-001" || outcomes[0].ReportedBy != "ops@example.com" {
+			t.Fatalf("expected the chargeback linked to its evaluation, got %+v", outcomes)
+		}
+
+		// Re-importing the same file leaves the stored outcome unchanged
+		results, _ = svc.Import(ctx, tenantID, "ops@example.com", []domain.OutcomeEvent{
+			{ID: "cb-1", TxID: "tx-001", Outcome: domain.OutcomeChargeback, Amount: domain.MustDecimal("999")},
+		})
+		outcomes, _ = repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{EvaluationID: "eval-001"})
+		if results[0].Status != domain.OutcomeImportImported || len(outcomes) != 1 || outcomes[0].Amount.String() != "100.1" {
+			t.Errorf("expected a re-import to be ignored, got %+v and %+v", results[0], outcomes)
+		}
+	})
+
+	t.Run("Losses", func(t *testing.T) {
+		report, err := svc.Losses(ctx, tenantID, now.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatalf("Losses failed: %v", err)
+		}
+		if report.Losses != 2 || report.Amount.String() != "139.3" || report.Caught != 1 || report.Missed != 1 {
+			t.Errorf("unexpected totals: %+v", report)
+		}
+		if len(report.Rules) != 2 || report.Rules[0].ID != "high-value" || report.Rules[0].Amount.String() != "100.1" || report.Rules[1].ID != "new-device" {
+			t.Errorf("expected the failed and shadow review rules, got %+v", report.Rules)
+		}
+		if len(report.Typologies) != 1 || report.Typologies[0].ID != "card-fraud" || report.Typologies[0].Losses != 1 {
+			t.Errorf("expected the triggered typology only, got %+v", report.Typologies)
+		}
+
+		empty, _ := svc.Losses(ctx, "other-tenant", time.Time{})
+		if empty.Losses != 0 || empty.Rules == nil || empty.Typologies == nil {
+			t.Errorf("expected an empty report for another tenant, got %+v", empty)
+		}
+	})
+
+	t.Run("EnricherExposesPriorLossesToRules", func(t *testing.T) {
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "repeat-offender",
+			Expression: `debtor_prior_chargebacks >= 1 && debtor_prior_returns >= 1 && creditor_prior_chargebacks == 0`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		results, _ := engine.EvaluateAll(ctx, &rules.EvaluateInput{TenantID: tenantID, TxID: "tx-003", DebtorID: "cust-001", CreditorID: "shop-003"})
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match the repeat offender, got score %.2f", results[0].Score)
+		}
+
+		chargebacks, returns, _ := svc.PartyLosses(ctx, tenantID, "shop-002")
+		if chargebacks != 0 || returns != 1 {
+			t.Errorf("expected the creditor's return, got %d chargebacks and %d returns", chargebacks, returns)
+		}
+	})
+
+	t.Run("LookupFailureDegrades", func(t *testing.T) {
+		failing := ospreytest.NewRepository(nil)
+		failing.SetError(errors.New("database unavailable"))
+
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+		engine.RegisterEnricher(NewService(failing, 0).Enricher())
+		engine.LoadRule(&domain.RuleConfig{
+			ID:         "any-chargeback",
+			Expression: `debtor_prior_chargebacks > 0`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+
+		dctx, degradations := domain.WithDegradations(ctx)
+		results, _ := engine.EvaluateAll(dctx, &rules.EvaluateInput{TenantID: tenantID, TxID: "tx-004", DebtorID: "cust-001"})
+		if results[0].SubRuleRef == domain.RuleOutcomeError || results[0].Score != 0 {
+			t.Errorf("expected no prior losses on lookup failure, got %+v", results[0])
+		}
+		got := degradations.List()
+		if len(got) != 1 || got[0].Component != "enricher:outcomes" {
+			t.Errorf("expected an outcomes enricher degradation, got %v", got)
+		}
+	})
+}
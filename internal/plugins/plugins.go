@@ -0,0 +1,265 @@
+// Package plugins loads enrichment plugins and adapts them to the rule engine.
+//
+// Plugins are Go plugins implementing enrich.Plugin. They run in the Osprey
+// process, so they are trusted code: the adapter bounds each call with a
+// timeout, recovers panics and type-checks every returned variable, but a
+// plugin that ignores its context keeps running after the timeout.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/enrich"
+)
+
+// DefaultTimeout bounds a single Enrich call when no timeout is configured.
+const DefaultTimeout = 50 * time.Millisecond
+
+// variableName matches the identifiers plugins may declare.
+var variableName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Load opens every *.so file in dir, in name order, and returns its plugin.
+func Load(dir string) ([]enrich.Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".so") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	loaded := make([]enrich.Plugin, 0, len(names))
+	for _, name := range names {
+		p, err := open(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", name, err)
+		}
+		loaded = append(loaded, p)
+	}
+	return loaded, nil
+}
+
+// open loads one plugin file and looks up its exported Plugin variable.
+func open(path string) (enrich.Plugin, error) {
+	so, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sym, err := so.Lookup(enrich.SymbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p := sym.(type) {
+	case *enrich.Plugin:
+		if *p == nil {
+			return nil, fmt.Errorf("%s is nil", enrich.SymbolName)
+		}
+		return *p, nil
+	case enrich.Plugin:
+		return p, nil
+	}
+	return nil, fmt.Errorf("%s does not implement enrich.Plugin", enrich.SymbolName)
+}
+
+// Enricher adapts a plugin to the rule engine. Calls taking longer than
+// timeout are abandoned; zero uses DefaultTimeout.
+func Enricher(p enrich.Plugin, timeout time.Duration) (rules.Enricher, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	vars := p.Variables()
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("plugin %s declares no variables", p.Name())
+	}
+	celTypes := make(map[string]*cel.Type, len(vars))
+	for name, typ := range vars {
+		if !variableName.MatchString(name) {
+			return nil, fmt.Errorf("plugin %s: invalid variable name %q", p.Name(), name)
+		}
+		celType, ok := celTypeOf(typ)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s: variable %s has unsupported type %q", p.Name(), name, typ)
+		}
+		celTypes[name] = celType
+	}
+
+	return &enricher{plugin: p, vars: vars, celTypes: celTypes, timeout: timeout}, nil
+}
+
+type enricher struct {
+	plugin   enrich.Plugin
+	vars     map[string]enrich.Type
+	celTypes map[string]*cel.Type
+	timeout  time.Duration
+}
+
+func (e *enricher) Name() string {
+	return "plugin:" + e.plugin.Name()
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return e.celTypes
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	for name, typ := range e.vars {
+		activation[name] = zeroValue(typ)
+	}
+
+	values, err := e.call(ctx, transactionOf(input))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, typ := range e.vars {
+		raw, ok := values[name]
+		if !ok {
+			continue
+		}
+		v, ok := convert(typ, raw)
+		if !ok {
+			errs = append(errs, fmt.Errorf("variable %s: %T is not %s", name, raw, typ))
+			continue
+		}
+		activation[name] = v
+	}
+	return errors.Join(errs...)
+}
+
+// call runs Enrich under the timeout, turning a panic into an error.
+func (e *enricher) call(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	type result struct {
+		values map[string]any
+		err    error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("plugin panicked: %v", r)}
+			}
+		}()
+		values, err := e.plugin.Enrich(ctx, tx)
+		done <- result{values: values, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.values, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("plugin timed out after %s: %w", e.timeout, ctx.Err())
+	}
+}
+
+// transactionOf builds the plugin's view of a transaction. Metadata is copied
+// so a plugin can't modify the activation through it.
+func transactionOf(input *rules.EvaluateInput) enrich.Transaction {
+	metadata := make(map[string]any, len(input.AdditionalData))
+	for k, v := range input.AdditionalData {
+		metadata[k] = v
+	}
+	return enrich.Transaction{
+		TenantID:        input.TenantID,
+		TxID:            input.TxID,
+		Type:            input.Type,
+		DebtorID:        input.DebtorID,
+		CreditorID:      input.CreditorID,
+		DebtorCountry:   input.DebtorCountry,
+		CreditorCountry: input.CreditorCountry,
+		Amount:          input.Amount.Float64(),
+		Currency:        input.Currency,
+		Metadata:        metadata,
+	}
+}
+
+func celTypeOf(typ enrich.Type) (*cel.Type, bool) {
+	switch typ {
+	case enrich.Bool:
+		return cel.BoolType, true
+	case enrich.Int:
+		return cel.IntType, true
+	case enrich.Double:
+		return cel.DoubleType, true
+	case enrich.String:
+		return cel.StringType, true
+	case enrich.Map:
+		return cel.MapType(cel.StringType, cel.DynType), true
+	}
+	return nil, false
+}
+
+func zeroValue(typ enrich.Type) any {
+	switch typ {
+	case enrich.Bool:
+		return false
+	case enrich.Int:
+		return int64(0)
+	case enrich.Double:
+		return 0.0
+	case enrich.String:
+		return ""
+	}
+	return map[string]any{}
+}
+
+// convert normalizes a returned value to the Go type CEL expects for typ.
+func convert(typ enrich.Type, v any) (any, bool) {
+	switch typ {
+	case enrich.Bool:
+		b, ok := v.(bool)
+		return b, ok
+	case enrich.Int:
+		switch n := v.(type) {
+		case int:
+			return int64(n), true
+		case int32:
+			return int64(n), true
+		case int64:
+			return n, true
+		}
+	case enrich.Double:
+		switch n := v.(type) {
+		case float64:
+			return n, true
+		case float32:
+			return float64(n), true
+		case int:
+			return float64(n), true
+		case int32:
+			return float64(n), true
+		case int64:
+			return float64(n), true
+		}
+	case enrich.String:
+		s, ok := v.(string)
+		return s, ok
+	case enrich.Map:
+		m, ok := v.(map[string]any)
+		return m, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,218 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/enrich"
+)
+
+type fakePlugin struct {
+	name   string
+	vars   map[string]enrich.Type
+	enrich func(ctx context.Context, tx enrich.Transaction) (map[string]any, error)
+}
+
+func (p *fakePlugin) Name() string                      { return p.name }
+func (p *fakePlugin) Variables() map[string]enrich.Type { return p.vars }
+func (p *fakePlugin) Enrich(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+	return p.enrich(ctx, tx)
+}
+
+func newFake(fn func(ctx context.Context, tx enrich.Transaction) (map[string]any, error)) *fakePlugin {
+	return &fakePlugin{
+		name: "risk",
+		vars: map[string]enrich.Type{
+			"device_trusted": enrich.Bool,
+			"device_age":     enrich.Int,
+			"ip_risk":        enrich.Double,
+			"ip_country":     enrich.String,
+			"device":         enrich.Map,
+		},
+		enrich: fn,
+	}
+}
+
+func TestEnricher(t *testing.T) {
+	input := &rules.EvaluateInput{
+		TenantID:       "tenant-1",
+		TxID:           "tx-1",
+		DebtorID:       "debtor-1",
+		Amount:         domain.MustDecimal("250"),
+		AdditionalData: map[string]any{"channel": "mobile"},
+	}
+
+	t.Run("sets returned values", func(t *testing.T) {
+		p := newFake(func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+			if tx.DebtorID != "debtor-1" || tx.Metadata["channel"] != "mobile" {
+				t.Errorf("unexpected transaction: %+v", tx)
+			}
+			tx.Metadata["channel"] = "changed"
+			return map[string]any{
+				"device_trusted": true,
+				"device_age":     42,
+				"ip_risk":        float32(0.5),
+				"ip_country":     "NL",
+				"device":         map[string]any{"os": "ios"},
+				"undeclared":     "ignored",
+			}, nil
+		})
+		e, err := Enricher(p, time.Second)
+		if err != nil {
+			t.Fatalf("Enricher failed: %v", err)
+		}
+		if e.Name() != "plugin:risk" {
+			t.Errorf("expected name plugin:risk, got %s", e.Name())
+		}
+
+		activation := map[string]any{}
+		if err := e.Enrich(context.Background(), input, activation); err != nil {
+			t.Fatalf("Enrich failed: %v", err)
+		}
+		if activation["device_trusted"] != true || activation["device_age"] != int64(42) ||
+			activation["ip_risk"] != 0.5 || activation["ip_country"] != "NL" {
+			t.Errorf("unexpected activation: %v", activation)
+		}
+		if _, ok := activation["undeclared"]; ok {
+			t.Error("undeclared variable should be ignored")
+		}
+		if input.AdditionalData["channel"] != "mobile" {
+			t.Error("plugin should not modify the input metadata")
+		}
+	})
+
+	t.Run("wrong types fall back to zero values", func(t *testing.T) {
+		p := newFake(func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+			return map[string]any{"device_age": "old", "ip_risk": 0.9}, nil
+		})
+		e, _ := Enricher(p, time.Second)
+
+		activation := map[string]any{}
+		err := e.Enrich(context.Background(), input, activation)
+		if err == nil || !strings.Contains(err.Error(), "device_age") {
+			t.Errorf("expected type error for device_age, got %v", err)
+		}
+		if activation["device_age"] != int64(0) {
+			t.Errorf("expected zero device_age, got %v", activation["device_age"])
+		}
+		if activation["ip_risk"] != 0.9 {
+			t.Errorf("expected valid ip_risk to be kept, got %v", activation["ip_risk"])
+		}
+	})
+
+	t.Run("failures fall back to zero values", func(t *testing.T) {
+		cases := map[string]func(ctx context.Context, tx enrich.Transaction) (map[string]any, error){
+			"error": func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+				return nil, errors.New("lookup failed")
+			},
+			"panic": func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+				panic("boom")
+			},
+			"timeout": func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return map[string]any{"device_trusted": true}, nil
+			},
+		}
+		for name, fn := range cases {
+			e, _ := Enricher(newFake(fn), 20*time.Millisecond)
+			activation := map[string]any{}
+			if err := e.Enrich(context.Background(), input, activation); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+			if activation["device_trusted"] != false || activation["ip_country"] != "" {
+				t.Errorf("%s: expected zero values, got %v", name, activation)
+			}
+		}
+	})
+
+	t.Run("rejects invalid declarations", func(t *testing.T) {
+		bad := []map[string]enrich.Type{
+			nil,
+			{"Bad-Name": enrich.Bool},
+			{"score": "decimal"},
+		}
+		for _, vars := range bad {
+			p := &fakePlugin{name: "bad", vars: vars}
+			if _, err := Enricher(p, 0); err == nil {
+				t.Errorf("expected error for %v", vars)
+			}
+		}
+	})
+
+	t.Run("usable in rules", func(t *testing.T) {
+		p := newFake(func(ctx context.Context, tx enrich.Transaction) (map[string]any, error) {
+			return map[string]any{"ip_risk": 0.8}, nil
+		})
+		e, _ := Enricher(p, time.Second)
+
+		engine, err := rules.NewEngine(nil, 1)
+		if err != nil {
+			t.Fatalf("NewEngine failed: %v", err)
+		}
+		if err := engine.RegisterEnricher(e); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		if err := engine.RegisterEnricher(e); err == nil {
+			t.Error("expected duplicate variables to be rejected")
+		}
+
+		err = engine.LoadRule(&domain.RuleConfig{
+			ID:         "ip-risk",
+			Expression: "ip_risk > 0.5",
+			Bands: []domain.RuleBand{
+				{SubRuleRef: domain.RuleOutcomePass, UpperLimit: floatPtr(1)},
+				{SubRuleRef: domain.RuleOutcomeFail, LowerLimit: floatPtr(1)},
+			},
+			Enabled: true,
+		})
+		if err != nil {
+			t.Fatalf("LoadRule failed: %v", err)
+		}
+		results, err := engine.EvaluateAll(context.Background(), input)
+		if err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		if len(results) != 1 || results[0].SubRuleRef != domain.RuleOutcomeFail {
+			t.Errorf("expected rule to fail, got %+v", results)
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(dir)
+		if err != nil || len(loaded) != 0 {
+			t.Errorf("expected no plugins, got %v, %v", loaded, err)
+		}
+	})
+
+	t.Run("invalid plugin", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "bad.so"), []byte("garbage"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "bad.so") {
+			t.Errorf("expected error naming bad.so, got %v", err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func floatPtr(f float64) *float64 { return &f }
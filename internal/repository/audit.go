@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// defaultAuditLimit caps audit log listings that don't set a limit.
+const defaultAuditLimit = 100
+
+const auditColumns = `tenant_id, seq, action, resource, resource_id, actor, client_ip, request_id,
+	before_json, after_json, prev_hash, hash, created_at`
+
+// AppendAuditEntry inserts the next entry of a tenant's audit log.
+// Returns an error if an entry with the same sequence number already exists.
+func (r *SQLRepository) AppendAuditEntry(ctx context.Context, tenantID string, entry *domain.AuditEntry) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `INSERT INTO audit_log (` + auditColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, entry.Seq, entry.Action, entry.Resource, entry.ResourceID,
+		entry.Actor, entry.ClientIP, entry.RequestID,
+		string(entry.Before), string(entry.After),
+		entry.PrevHash, entry.Hash, entry.CreatedAt,
+	)
+	return err
+}
+
+// GetLastAuditEntry retrieves the newest entry of a tenant's audit log.
+func (r *SQLRepository) GetLastAuditEntry(ctx context.Context, tenantID string) (*domain.AuditEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE tenant_id = ? ORDER BY seq DESC LIMIT 1`
+	return scanAuditEntry(r.db.QueryRowContext(ctx, r.rebind(query), tenantID))
+}
+
+// GetAuditEntry retrieves one entry of a tenant's audit log.
+func (r *SQLRepository) GetAuditEntry(ctx context.Context, tenantID string, seq int64) (*domain.AuditEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE tenant_id = ? AND seq = ?`
+	return scanAuditEntry(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, seq))
+}
+
+// ListAuditEntries retrieves a tenant's audit log entries in chain order.
+func (r *SQLRepository) ListAuditEntries(ctx context.Context, tenantID string, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE tenant_id = ? AND seq > ?`
+	args := []any{tenantID, filter.AfterSeq}
+	if filter.Resource != "" {
+		query += ` AND resource = ?`
+		args = append(args, filter.Resource)
+	}
+	if filter.ResourceID != "" {
+		query += ` AND resource_id = ?`
+		args = append(args, filter.ResourceID)
+	}
+	if filter.Action != "" {
+		query += ` AND action = ?`
+		args = append(args, filter.Action)
+	}
+	query += ` ORDER BY seq LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// scanAuditEntry scans one audit_log row selected with auditColumns.
+func scanAuditEntry(row interface{ Scan(...any) error }) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var before, after string
+	err := row.Scan(
+		&entry.TenantID, &entry.Seq, &entry.Action, &entry.Resource, &entry.ResourceID,
+		&entry.Actor, &entry.ClientIP, &entry.RequestID,
+		&before, &after, &entry.PrevHash, &entry.Hash, &entry.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if before != "" {
+		entry.Before = json.RawMessage(before)
+	}
+	if after != "" {
+		entry.After = json.RawMessage(after)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// SaveAccountBalance creates or replaces an account's balance with tenant
+// isolation.
+func (r *SQLRepository) SaveAccountBalance(ctx context.Context, tenantID string, balance *domain.AccountBalance) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if balance.AccountID == "" {
+		return fmt.Errorf("%w: accountID is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO account_balances (
+			tenant_id, account_id, balance, currency, prior_balance,
+			balance_decimal, prior_balance_decimal,
+			last_tx_id, last_credit_tx_id, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, account_id) DO UPDATE SET
+			balance = excluded.balance,
+			currency = excluded.currency,
+			prior_balance = excluded.prior_balance,
+			balance_decimal = excluded.balance_decimal,
+			prior_balance_decimal = excluded.prior_balance_decimal,
+			last_tx_id = excluded.last_tx_id,
+			last_credit_tx_id = excluded.last_credit_tx_id,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := balance.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, balance.AccountID, balance.Balance.Float64(), balance.Currency, balance.Prior.Float64(),
+		balance.Balance.String(), balance.Prior.String(),
+		balance.LastTxID, balance.LastCreditTxID, updatedAt.UTC(),
+	)
+	return err
+}
+
+// GetAccountBalance retrieves an account's balance with tenant isolation.
+func (r *SQLRepository) GetAccountBalance(ctx context.Context, tenantID string, accountID string) (*domain.AccountBalance, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, account_id, balance, currency, prior_balance,
+			balance_decimal, prior_balance_decimal, last_tx_id, last_credit_tx_id, updated_at
+		FROM account_balances
+		WHERE tenant_id = ? AND account_id = ?
+	`
+
+	var balance domain.AccountBalance
+	var amount, prior float64
+	var currency, amountDecimal, priorDecimal, lastTxID, lastCreditTxID sql.NullString
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, accountID).Scan(
+		&balance.TenantID, &balance.AccountID, &amount, &currency, &prior,
+		&amountDecimal, &priorDecimal, &lastTxID, &lastCreditTxID, &balance.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	balance.Balance = decodeAmount(amount, amountDecimal)
+	balance.Prior = decodeAmount(prior, priorDecimal)
+	balance.Currency = currency.String
+	balance.LastTxID = lastTxID.String
+	balance.LastCreditTxID = lastCreditTxID.String
+	return &balance, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// SaveEntityBaseline creates or replaces an entity's baseline with tenant
+// isolation.
+func (r *SQLRepository) SaveEntityBaseline(ctx context.Context, tenantID string, baseline *domain.EntityBaseline) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if baseline.EntityID == "" {
+		return fmt.Errorf("%w: entityID is required", ErrInvalidInput)
+	}
+
+	stats, err := json.Marshal(baseline.Stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	prior, err := json.Marshal(baseline.Prior)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prior stats: %w", err)
+	}
+
+	query := `
+		INSERT INTO entity_baselines (tenant_id, entity_id, stats, prior_stats, last_tx_id, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, entity_id) DO UPDATE SET
+			stats = excluded.stats,
+			prior_stats = excluded.prior_stats,
+			last_tx_id = excluded.last_tx_id,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := baseline.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err = r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, baseline.EntityID, string(stats), string(prior), baseline.LastTxID, updatedAt.UTC(),
+	)
+	return err
+}
+
+// GetEntityBaseline retrieves an entity's baseline with tenant isolation.
+func (r *SQLRepository) GetEntityBaseline(ctx context.Context, tenantID string, entityID string) (*domain.EntityBaseline, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, entity_id, stats, prior_stats, last_tx_id, updated_at
+		FROM entity_baselines
+		WHERE tenant_id = ? AND entity_id = ?
+	`
+
+	var baseline domain.EntityBaseline
+	var stats, prior string
+	var lastTxID sql.NullString
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, entityID).Scan(
+		&baseline.TenantID, &baseline.EntityID, &stats, &prior, &lastTxID, &baseline.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(stats), &baseline.Stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stats of %s: %w", entityID, err)
+	}
+	if err := json.Unmarshal([]byte(prior), &baseline.Prior); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prior stats of %s: %w", entityID, err)
+	}
+	baseline.LastTxID = lastTxID.String
+	return &baseline, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// defaultDeadLetterLimit caps dead-letter listings that don't set a limit.
+const defaultDeadLetterLimit = 100
+
+// deadLetterColumns is the column list read by scanDeadLetter.
+const deadLetterColumns = `id, tenant_id, topic, message_id, payload, error, attempts, poison, failed_at, replayed_at`
+
+// SaveDeadLetter stores a dead letter with tenant isolation.
+func (r *SQLRepository) SaveDeadLetter(ctx context.Context, tenantID string, dead *domain.DeadLetter) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if dead.ID == "" || dead.Topic == "" {
+		return fmt.Errorf("%w: dead letter ID and topic are required", ErrInvalidInput)
+	}
+
+	poison := 0
+	if dead.Poison {
+		poison = 1
+	}
+
+	query := `
+		INSERT INTO dead_letters (` + deadLetterColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		dead.ID, tenantID, dead.Topic, dead.MessageID, dead.Payload, dead.Error, dead.Attempts, poison,
+		dead.FailedAt.UTC(), nullTime(dead.ReplayedAt),
+	)
+	return err
+}
+
+// GetDeadLetter retrieves a dead letter with tenant isolation.
+func (r *SQLRepository) GetDeadLetter(ctx context.Context, tenantID string, deadLetterID string) (*domain.DeadLetter, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letters WHERE tenant_id = ? AND id = ?`
+
+	dead, err := scanDeadLetter(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, deadLetterID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dead, nil
+}
+
+// ListDeadLetters retrieves a tenant's dead letters, latest first.
+func (r *SQLRepository) ListDeadLetters(ctx context.Context, tenantID string, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letters WHERE tenant_id = ?`
+	if filter.Pending {
+		query += ` AND replayed_at IS NULL`
+	}
+	query += ` ORDER BY failed_at DESC, id DESC LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dead []*domain.DeadLetter
+	for rows.Next() {
+		d, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		dead = append(dead, d)
+	}
+	return dead, rows.Err()
+}
+
+// MarkDeadLetterReplayed records that a dead letter was replayed. Returns
+// ErrNotFound if it doesn't exist or was already replayed, so concurrent
+// replays publish the message once.
+func (r *SQLRepository) MarkDeadLetterReplayed(ctx context.Context, tenantID string, deadLetterID string, at time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE dead_letters SET replayed_at = ?
+		WHERE tenant_id = ? AND id = ? AND replayed_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), at.UTC(), tenantID, deadLetterID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanDeadLetter reads a row selected with deadLetterColumns.
+func scanDeadLetter(row interface{ Scan(...any) error }) (*domain.DeadLetter, error) {
+	var dead domain.DeadLetter
+	var poison int
+	var replayedAt sql.NullTime
+
+	if err := row.Scan(
+		&dead.ID, &dead.TenantID, &dead.Topic, &dead.MessageID, &dead.Payload, &dead.Error,
+		&dead.Attempts, &poison, &dead.FailedAt, &replayedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	dead.Poison = poison != 0
+	if replayedAt.Valid {
+		t := replayedAt.Time
+		dead.ReplayedAt = &t
+	}
+	return &dead, nil
+}
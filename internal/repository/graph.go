@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// edgeColumns is the column list read by scanCounterpartyEdge.
+const edgeColumns = `tenant_id, debtor_id, creditor_id, tx_count, amount, first_seen, last_seen`
+
+// RecordCounterpartyEdge adds a transaction to the edge from its debtor to
+// its creditor with tenant isolation.
+func (r *SQLRepository) RecordCounterpartyEdge(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if tx.DebtorID == "" || tx.CreditorID == "" {
+		return fmt.Errorf("%w: debtorID and creditorID are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO counterparty_edges (` + edgeColumns + `)
+		VALUES (?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT(tenant_id, debtor_id, creditor_id) DO UPDATE SET
+			tx_count = counterparty_edges.tx_count + 1,
+			amount = counterparty_edges.amount + excluded.amount,
+			first_seen = CASE WHEN excluded.first_seen < counterparty_edges.first_seen
+				THEN excluded.first_seen ELSE counterparty_edges.first_seen END,
+			last_seen = CASE WHEN excluded.last_seen > counterparty_edges.last_seen
+				THEN excluded.last_seen ELSE counterparty_edges.last_seen END
+	`
+
+	at := tx.Timestamp.UTC()
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, tx.DebtorID, tx.CreditorID, tx.Amount.Float64(), at, at,
+	)
+	return err
+}
+
+// GetCounterpartyEdge retrieves the edge from a debtor to a creditor with
+// tenant isolation.
+func (r *SQLRepository) GetCounterpartyEdge(ctx context.Context, tenantID string, debtorID string, creditorID string) (*domain.CounterpartyEdge, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + edgeColumns + ` FROM counterparty_edges WHERE tenant_id = ? AND debtor_id = ? AND creditor_id = ?`
+
+	edge, err := scanCounterpartyEdge(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, debtorID, creditorID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return edge, nil
+}
+
+// ListCounterpartyEdges retrieves the edges from and to a party last seen at
+// or after since, latest first.
+func (r *SQLRepository) ListCounterpartyEdges(ctx context.Context, tenantID string, partyID string, since time.Time) ([]*domain.CounterpartyEdge, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + edgeColumns + ` FROM counterparty_edges
+		WHERE tenant_id = ? AND (debtor_id = ? OR creditor_id = ?) AND last_seen >= ?
+		ORDER BY last_seen DESC, debtor_id, creditor_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, partyID, partyID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []*domain.CounterpartyEdge
+	for rows.Next() {
+		edge, err := scanCounterpartyEdge(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
+
+// scanCounterpartyEdge reads a row selected with edgeColumns.
+func scanCounterpartyEdge(row interface{ Scan(...any) error }) (*domain.CounterpartyEdge, error) {
+	var edge domain.CounterpartyEdge
+	if err := row.Scan(
+		&edge.TenantID, &edge.DebtorID, &edge.CreditorID,
+		&edge.Count, &edge.Amount, &edge.FirstSeen, &edge.LastSeen,
+	); err != nil {
+		return nil, err
+	}
+	return &edge, nil
+}
+
+// backfillCounterpartyEdges builds the counterparty network from the stored
+// transactions while it is empty, which is once, on the first startup with
+// edges.
+func (r *SQLRepository) backfillCounterpartyEdges() error {
+	var edges int
+	query := `SELECT COUNT(*) FROM (SELECT 1 FROM counterparty_edges LIMIT 1) e`
+	if err := r.db.QueryRow(query).Scan(&edges); err != nil {
+		return fmt.Errorf("failed to inspect counterparty edges: %w", err)
+	}
+	if edges > 0 {
+		return nil
+	}
+
+	query = `
+		INSERT INTO counterparty_edges (` + edgeColumns + `)
+		SELECT tenant_id, debtor_id, creditor_id, COUNT(*), SUM(amount), MIN(timestamp), MAX(timestamp)
+		FROM transactions
+		WHERE (reversal_of IS NULL OR reversal_of = '') AND debtor_id <> creditor_id
+		GROUP BY tenant_id, debtor_id, creditor_id
+	`
+	if _, err := r.db.Exec(query); err != nil {
+		return fmt.Errorf("failed to backfill counterparty edges: %w", err)
+	}
+	return nil
+}
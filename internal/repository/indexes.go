@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Thresholds at which a tenant's workload calls for an index.
+const (
+	// velocityIndexMinRowsPerEntity is the average number of transactions per
+	// party above which scanning a party's whole history gets expensive.
+	velocityIndexMinRowsPerEntity = 20
+
+	// velocityIndexMaxWindowShare is the largest share of a tenant's history
+	// the velocity window may cover for a timestamp column to pay off.
+	velocityIndexMaxWindowShare = 0.25
+
+	// listIndexMinRows is the number of alerts above which filtered alert
+	// lists need their own index.
+	listIndexMinRows = 10000
+)
+
+// indexCandidate is an index the advisor may recommend.
+type indexCandidate struct {
+	name    string
+	table   string
+	columns []string
+	query   string
+
+	// need reports whether a tenant's workload calls for the index, and why.
+	need func(c domain.TenantCardinality) (bool, string)
+}
+
+// indexCandidates are the indexes for the velocity and list queries that the
+// base schema leaves out, because they only pay off on larger workloads.
+var indexCandidates = []indexCandidate{
+	{
+		name:    "idx_transactions_debtor_time",
+		table:   "transactions",
+		columns: []string{"tenant_id", "debtor_id", "timestamp"},
+		query:   "velocity count by debtor",
+		need: func(c domain.TenantCardinality) (bool, string) {
+			return velocityIndexNeed(c, c.Debtors, "debtor")
+		},
+	},
+	{
+		name:    "idx_transactions_creditor_time",
+		table:   "transactions",
+		columns: []string{"tenant_id", "creditor_id", "timestamp"},
+		query:   "velocity count by creditor",
+		need: func(c domain.TenantCardinality) (bool, string) {
+			return velocityIndexNeed(c, c.Creditors, "creditor")
+		},
+	},
+	{
+		name:    "idx_alerts_tenant_status",
+		table:   "alerts",
+		columns: []string{"tenant_id", "status", "created_at"},
+		query:   "alert list by status",
+		need: func(c domain.TenantCardinality) (bool, string) {
+			if c.Alerts < listIndexMinRows {
+				return false, ""
+			}
+			return true, fmt.Sprintf("%s: %d alerts", c.TenantID, c.Alerts)
+		},
+	},
+}
+
+// velocityIndexNeed reports whether velocity counts for a tenant's parties
+// scan enough history outside the window for a timestamp column to help.
+func velocityIndexNeed(c domain.TenantCardinality, entities int64, kind string) (bool, string) {
+	if entities == 0 || c.SpanSecs == 0 {
+		return false, ""
+	}
+	perEntity := c.Transactions / entities
+	share := float64(c.WindowSecs) / float64(c.SpanSecs)
+	if perEntity < velocityIndexMinRowsPerEntity || share > velocityIndexMaxWindowShare {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s: %d transactions per %s, %s window over %s of history",
+		c.TenantID, perEntity, kind,
+		time.Duration(c.WindowSecs)*time.Second, time.Duration(c.SpanSecs)*time.Second)
+}
+
+// AdviseIndexes refreshes planner statistics and checks each candidate index
+// against every tenant's entity cardinality and velocity window. It spans
+// tenants; it feeds the admin index advisor only.
+func (r *SQLRepository) AdviseIndexes(ctx context.Context, velocity domain.VelocityConfig) (*domain.IndexReport, error) {
+	if err := r.analyze(ctx); err != nil {
+		return nil, fmt.Errorf("failed to analyze tables: %w", err)
+	}
+
+	tenants, err := r.tenantCardinality(ctx, velocity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure tenant cardinality: %w", err)
+	}
+
+	report := &domain.IndexReport{
+		Driver:  r.driver,
+		Indexes: make([]domain.IndexAdvice, 0, len(indexCandidates)),
+		Tenants: tenants,
+	}
+
+	existing := make(map[string][][]string)
+	for _, candidate := range indexCandidates {
+		if _, ok := existing[candidate.table]; !ok {
+			cols, err := r.indexColumns(ctx, candidate.table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list indexes on %s: %w", candidate.table, err)
+			}
+			existing[candidate.table] = cols
+		}
+
+		advice := domain.IndexAdvice{
+			Name:    candidate.name,
+			Table:   candidate.table,
+			Columns: candidate.columns,
+			Query:   candidate.query,
+			Exists:  coveredBy(candidate.columns, existing[candidate.table]),
+		}
+		var reasons []string
+		for _, tenant := range tenants {
+			if need, reason := candidate.need(tenant); need {
+				advice.Tenants = append(advice.Tenants, tenant.TenantID)
+				reasons = append(reasons, reason)
+			}
+		}
+		switch {
+		case advice.Exists:
+			advice.Reason = "an index with these leading columns exists"
+		case len(reasons) > 0:
+			advice.Recommended = true
+			advice.Reason = strings.Join(reasons, "; ")
+		default:
+			advice.Reason = "no tenant's workload needs it yet"
+		}
+		report.Indexes = append(report.Indexes, advice)
+	}
+
+	// Statement and planner statistics are informational; not every
+	// installation has them
+	if r.driver == "postgres" {
+		report.Queries = r.queryStats(ctx)
+	} else {
+		report.Statistics = r.indexStats(ctx)
+	}
+
+	return report, nil
+}
+
+// CreateIndex creates a candidate index by name. PostgreSQL builds it
+// concurrently, so writes continue while it is built.
+func (r *SQLRepository) CreateIndex(ctx context.Context, name string) error {
+	for _, candidate := range indexCandidates {
+		if candidate.name != name {
+			continue
+		}
+		create := "CREATE INDEX IF NOT EXISTS"
+		if r.driver == "postgres" {
+			create = "CREATE INDEX CONCURRENTLY IF NOT EXISTS"
+		}
+		stmt := fmt.Sprintf("%s %s ON %s(%s)", create, candidate.name, candidate.table, strings.Join(candidate.columns, ", "))
+		_, err := r.db.ExecContext(ctx, stmt)
+		return err
+	}
+	return ErrNotFound
+}
+
+// analyze refreshes the planner statistics the advisor reports.
+func (r *SQLRepository) analyze(ctx context.Context) error {
+	if r.driver != "postgres" {
+		_, err := r.db.ExecContext(ctx, "ANALYZE")
+		return err
+	}
+	for _, table := range []string{"transactions", "alerts"} {
+		if _, err := r.db.ExecContext(ctx, "ANALYZE "+table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantCardinality measures every tenant's transactions and alerts.
+func (r *SQLRepository) tenantCardinality(ctx context.Context, velocity domain.VelocityConfig) ([]domain.TenantCardinality, error) {
+	byTenant := make(map[string]*domain.TenantCardinality)
+	tenant := func(tenantID string) *domain.TenantCardinality {
+		c := byTenant[tenantID]
+		if c == nil {
+			c = &domain.TenantCardinality{TenantID: tenantID, WindowSecs: velocity.WindowSeconds(tenantID)}
+			byTenant[tenantID] = c
+		}
+		return c
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tenant_id, COUNT(*), COUNT(DISTINCT debtor_id), COUNT(DISTINCT creditor_id)
+		FROM transactions
+		GROUP BY tenant_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var tenantID string
+		var txs, debtors, creditors int64
+		if err := rows.Scan(&tenantID, &txs, &debtors, &creditors); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c := tenant(tenantID)
+		c.Transactions, c.Debtors, c.Creditors = txs, debtors, creditors
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT tenant_id, COUNT(*) FROM alerts GROUP BY tenant_id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var tenantID string
+		var alerts int64
+		if err := rows.Scan(&tenantID, &alerts); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tenant(tenantID).Alerts = alerts
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.TenantCardinality, 0, len(byTenant))
+	for _, c := range byTenant {
+		if c.Transactions > 0 {
+			span, err := r.transactionSpan(ctx, c.TenantID)
+			if err != nil {
+				return nil, err
+			}
+			c.SpanSecs = int64(span / time.Second)
+		}
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TenantID < out[j].TenantID })
+	return out, nil
+}
+
+// transactionSpan returns the time between a tenant's oldest and newest
+// transaction. MIN and MAX lose the column type on SQLite, so the ends are
+// read with ordered lookups.
+func (r *SQLRepository) transactionSpan(ctx context.Context, tenantID string) (time.Duration, error) {
+	var oldest, newest time.Time
+	for _, end := range []struct {
+		order string
+		dest  *time.Time
+	}{{"ASC", &oldest}, {"DESC", &newest}} {
+		query := `SELECT timestamp FROM transactions WHERE tenant_id = ? ORDER BY timestamp ` + end.order + ` LIMIT 1`
+		if err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID).Scan(end.dest); err != nil {
+			return 0, err
+		}
+	}
+	return newest.Sub(oldest), nil
+}
+
+// indexColumns returns the column lists of a table's indexes.
+func (r *SQLRepository) indexColumns(ctx context.Context, table string) ([][]string, error) {
+	if r.driver == "postgres" {
+		return r.postgresIndexColumns(ctx, table)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT name FROM pragma_index_list(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var out [][]string
+	for _, name := range names {
+		rows, err := r.db.QueryContext(ctx, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, name)
+		if err != nil {
+			return nil, err
+		}
+		var cols []string
+		for rows.Next() {
+			var col sql.NullString
+			if err := rows.Scan(&col); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			cols = append(cols, col.String)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		out = append(out, cols)
+	}
+	return out, nil
+}
+
+// postgresIndexColumns reads index columns from their definitions, e.g.
+// "CREATE INDEX idx ON public.transactions USING btree (tenant_id, debtor_id)".
+func (r *SQLRepository) postgresIndexColumns(ctx context.Context, table string) ([][]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT indexdef FROM pg_indexes WHERE tablename = $1`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out [][]string
+	for rows.Next() {
+		var def string
+		if err := rows.Scan(&def); err != nil {
+			return nil, err
+		}
+		open, end := strings.Index(def, "("), strings.LastIndex(def, ")")
+		if open < 0 || end < open {
+			continue
+		}
+		var cols []string
+		for _, col := range strings.Split(def[open+1:end], ",") {
+			cols = append(cols, strings.Trim(strings.TrimSpace(col), `"`))
+		}
+		out = append(out, cols)
+	}
+	return out, rows.Err()
+}
+
+// coveredBy reports whether an existing index starts with the given columns.
+func coveredBy(columns []string, indexes [][]string) bool {
+	for _, cols := range indexes {
+		if len(cols) < len(columns) {
+			continue
+		}
+		match := true
+		for i, col := range columns {
+			if cols[i] != col {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// queryStats returns the slowest transaction and alert statements from
+// pg_stat_statements, or nil when the extension is not installed.
+func (r *SQLRepository) queryStats(ctx context.Context) []domain.QueryStat {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT query, calls, mean_exec_time
+		FROM pg_stat_statements
+		WHERE query LIKE '%FROM transactions%' OR query LIKE '%FROM alerts%'
+		ORDER BY mean_exec_time DESC
+		LIMIT 10
+	`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var stats []domain.QueryStat
+	for rows.Next() {
+		var s domain.QueryStat
+		if err := rows.Scan(&s.Query, &s.Calls, &s.MeanMs); err != nil {
+			return nil
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// indexStats returns the SQLite planner statistics for the advised tables,
+// or nil when ANALYZE has not produced any.
+func (r *SQLRepository) indexStats(ctx context.Context) []domain.IndexStat {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tbl, idx, stat FROM sqlite_stat1
+		WHERE tbl IN ('transactions', 'alerts') AND idx IS NOT NULL
+		ORDER BY tbl, idx
+	`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var stats []domain.IndexStat
+	for rows.Next() {
+		var s domain.IndexStat
+		var stat string
+		if err := rows.Scan(&s.Table, &s.Index, &stat); err != nil {
+			return nil
+		}
+		// "rows rowsPerKey..." with optional trailing flags such as "unordered"
+		for i, field := range strings.Fields(stat) {
+			n, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				break
+			}
+			if i == 0 {
+				s.Rows = n
+			} else {
+				s.RowsPerKey = append(s.RowsPerKey, n)
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+var _ domain.IndexAdvisor = (*SQLRepository)(nil)
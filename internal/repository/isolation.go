@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// globalTenantID scopes rules and typologies to every tenant, as in the
+// rules package.
+const globalTenantID = "*"
+
+// globalKeyTables are the tables keyed by record ID alone. Their IDs must be
+// unique across tenants, e.g. UUIDs.
+var globalKeyTables = []string{"evaluations", "jobs"}
+
+// crossTenantChecks are the isolation checks that compare a reference with
+// the tenant of the record it names. Each query returns the record's tenant
+// and ID, the reference, and the other tenant, skipping references that
+// also resolve within the record's own tenant.
+var crossTenantChecks = []struct {
+	check string
+	table string
+	query string
+}{
+	{
+		check: domain.IsolationEvaluationTransaction,
+		table: "evaluations",
+		query: `
+			SELECT e.tenant_id, e.id, e.tx_id, t.tenant_id
+			FROM evaluations e JOIN transactions t ON t.id = e.tx_id
+			WHERE t.tenant_id <> e.tenant_id
+			AND NOT EXISTS (SELECT 1 FROM transactions o WHERE o.tenant_id = e.tenant_id AND o.id = e.tx_id)
+			ORDER BY e.tenant_id, e.id
+		`,
+	},
+	{
+		check: domain.IsolationAlertTransaction,
+		table: "alerts",
+		query: `
+			SELECT a.tenant_id, a.id, a.tx_id, t.tenant_id
+			FROM alerts a JOIN transactions t ON t.id = a.tx_id
+			WHERE t.tenant_id <> a.tenant_id
+			AND NOT EXISTS (SELECT 1 FROM transactions o WHERE o.tenant_id = a.tenant_id AND o.id = a.tx_id)
+			ORDER BY a.tenant_id, a.id
+		`,
+	},
+	{
+		check: domain.IsolationOutcomeEvaluation,
+		table: "evaluation_outcomes",
+		query: `
+			SELECT oc.tenant_id, oc.id, oc.evaluation_id, e.tenant_id
+			FROM evaluation_outcomes oc JOIN evaluations e ON e.id = oc.evaluation_id
+			WHERE e.tenant_id <> oc.tenant_id
+			AND NOT EXISTS (SELECT 1 FROM evaluations o WHERE o.tenant_id = oc.tenant_id AND o.id = oc.evaluation_id)
+			ORDER BY oc.tenant_id, oc.id
+		`,
+	},
+}
+
+// AuditIsolation reports every record that references another tenant's
+// data. It spans tenants; it feeds the admin isolation audit only.
+func (r *SQLRepository) AuditIsolation(ctx context.Context) (*domain.IsolationReport, error) {
+	report := &domain.IsolationReport{
+		Driver:     r.driver,
+		Violations: []domain.IsolationViolation{},
+		GlobalKeys: globalKeyTables,
+	}
+
+	typologies, err := r.auditTypologyRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit typology rules: %w", err)
+	}
+	report.Violations = append(report.Violations, typologies...)
+
+	for _, c := range crossTenantChecks {
+		rows, err := r.db.QueryContext(ctx, c.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to audit %s: %w", c.check, err)
+		}
+		for rows.Next() {
+			v := domain.IsolationViolation{
+				Check:      c.check,
+				Table:      c.table,
+				Repairable: c.check == domain.IsolationOutcomeEvaluation,
+			}
+			if err := rows.Scan(&v.TenantID, &v.ID, &v.Ref, &v.RefTenantID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			report.Violations = append(report.Violations, v)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// RepairIsolation removes typology references to other tenants' rules and
+// deletes outcomes linked to other tenants' evaluations. Evaluations and
+// alerts naming other tenants' transactions are reported, not changed.
+func (r *SQLRepository) RepairIsolation(ctx context.Context) (*domain.IsolationReport, error) {
+	report, err := r.AuditIsolation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A typology may reference several foreign rules; rewrite it once
+	type typologyKey struct{ tenantID, id string }
+	foreign := make(map[typologyKey]map[string]bool)
+	for _, v := range report.Violations {
+		if v.Check == domain.IsolationTypologyRule {
+			key := typologyKey{v.TenantID, v.ID}
+			if foreign[key] == nil {
+				foreign[key] = make(map[string]bool)
+			}
+			foreign[key][v.Ref] = true
+		}
+	}
+	for key, refs := range foreign {
+		if err := r.removeTypologyRules(ctx, key.tenantID, key.id, refs); err != nil {
+			return nil, fmt.Errorf("failed to repair typology %s: %w", key.id, err)
+		}
+	}
+
+	deleteOutcome := `DELETE FROM evaluation_outcomes WHERE tenant_id = ? AND id = ?`
+	for i := range report.Violations {
+		v := &report.Violations[i]
+		if !v.Repairable {
+			continue
+		}
+		if v.Check == domain.IsolationOutcomeEvaluation {
+			if _, err := r.db.ExecContext(ctx, r.rebind(deleteOutcome), v.TenantID, v.ID); err != nil {
+				return nil, fmt.Errorf("failed to repair outcome %s: %w", v.ID, err)
+			}
+		}
+		v.Repaired = true
+		report.Repaired++
+	}
+	return report, nil
+}
+
+// auditTypologyRules reports typology rule references that resolve neither
+// in the typology's tenant nor globally, but do in another tenant. Global
+// typologies may only reference global rules.
+func (r *SQLRepository) auditTypologyRules(ctx context.Context) ([]domain.IsolationViolation, error) {
+	owners := make(map[string][]string) // rule ID -> tenants with the rule
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT id, tenant_id FROM rule_configs ORDER BY id, tenant_id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id, tenantID string
+		if err := rows.Scan(&id, &tenantID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		owners[id] = append(owners[id], tenantID)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT tenant_id, id, rules FROM typologies ORDER BY tenant_id, id, version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var violations []domain.IsolationViolation
+	type typologyRef struct{ tenantID, id, ruleID string }
+	seen := make(map[typologyRef]bool) // versions of a typology share their references
+	for rows.Next() {
+		var tenantID, id, rulesJSON string
+		if err := rows.Scan(&tenantID, &id, &rulesJSON); err != nil {
+			return nil, err
+		}
+		var refs []domain.TypologyRuleWeight
+		json.Unmarshal([]byte(rulesJSON), &refs)
+
+		for _, ref := range refs {
+			tenants := owners[ref.RuleID]
+			key := typologyRef{tenantID, id, ref.RuleID}
+			if len(tenants) == 0 || ruleApplies(tenants, tenantID) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			violations = append(violations, domain.IsolationViolation{
+				Check:       domain.IsolationTypologyRule,
+				Table:       "typologies",
+				TenantID:    tenantID,
+				ID:          id,
+				Ref:         ref.RuleID,
+				RefTenantID: tenants[0],
+				Repairable:  true,
+			})
+		}
+	}
+	return violations, rows.Err()
+}
+
+// ruleApplies reports whether a rule stored for the given tenants applies to
+// tenantID, either as its own rule or as a global one.
+func ruleApplies(tenants []string, tenantID string) bool {
+	for _, t := range tenants {
+		if t == tenantID || t == globalTenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTypologyRules drops the given rule references from every version of
+// a typology.
+func (r *SQLRepository) removeTypologyRules(ctx context.Context, tenantID string, typologyID string, ruleIDs map[string]bool) error {
+	rows, err := r.db.QueryContext(ctx, r.rebind(`SELECT version, rules FROM typologies WHERE tenant_id = ? AND id = ?`), tenantID, typologyID)
+	if err != nil {
+		return err
+	}
+	versions := make(map[string][]domain.TypologyRuleWeight)
+	for rows.Next() {
+		var version, rulesJSON string
+		if err := rows.Scan(&version, &rulesJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		var refs []domain.TypologyRuleWeight
+		json.Unmarshal([]byte(rulesJSON), &refs)
+		versions[version] = refs
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	update := `UPDATE typologies SET rules = ?, updated_at = ? WHERE tenant_id = ? AND id = ? AND version = ?`
+	now := time.Now().UTC()
+	for version, refs := range versions {
+		kept := make([]domain.TypologyRuleWeight, 0, len(refs))
+		for _, ref := range refs {
+			if !ruleIDs[ref.RuleID] {
+				kept = append(kept, ref)
+			}
+		}
+		if len(kept) == len(refs) {
+			continue
+		}
+		data, _ := json.Marshal(kept)
+		if _, err := r.db.ExecContext(ctx, r.rebind(update), string(data), now, tenantID, typologyID, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ domain.IsolationAuditor = (*SQLRepository)(nil)
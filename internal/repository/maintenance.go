@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// maintenanceColumns is the column list read by scanMaintenanceWindow.
+const maintenanceColumns = `id, tenant_id, starts_at, ends_at, reason, created_by, created_at, cancelled_by, cancelled_at`
+
+// SaveMaintenanceWindow creates a maintenance window with tenant isolation.
+func (r *SQLRepository) SaveMaintenanceWindow(ctx context.Context, tenantID string, window *domain.MaintenanceWindow) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if window.ID == "" || !window.EndsAt.After(window.StartsAt) {
+		return fmt.Errorf("%w: maintenance window ID and a positive duration are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO maintenance_windows (` + maintenanceColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		window.ID, tenantID, window.StartsAt.UTC(), window.EndsAt.UTC(), window.Reason,
+		window.CreatedBy, window.CreatedAt.UTC(), window.CancelledBy, nullTime(window.CancelledAt),
+	)
+	return err
+}
+
+// GetMaintenanceWindow retrieves a maintenance window with tenant isolation.
+func (r *SQLRepository) GetMaintenanceWindow(ctx context.Context, tenantID string, windowID string) (*domain.MaintenanceWindow, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE tenant_id = ? AND id = ?`
+
+	window, err := scanMaintenanceWindow(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, windowID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// ListMaintenanceWindows retrieves a tenant's maintenance windows, including
+// past and cancelled ones, latest start first.
+func (r *SQLRepository) ListMaintenanceWindows(ctx context.Context, tenantID string) ([]*domain.MaintenanceWindow, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE tenant_id = ? ORDER BY starts_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []*domain.MaintenanceWindow
+	for rows.Next() {
+		window, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, rows.Err()
+}
+
+// CancelMaintenanceWindow records who cancelled a window and when. Returns
+// ErrNotFound if it doesn't exist or was already cancelled.
+func (r *SQLRepository) CancelMaintenanceWindow(ctx context.Context, tenantID string, windowID string, by string, at time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE maintenance_windows SET cancelled_by = ?, cancelled_at = ?
+		WHERE tenant_id = ? AND id = ? AND cancelled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), by, at.UTC(), tenantID, windowID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ActiveMaintenanceWindow returns the uncancelled window active at t that
+// ends last, or ErrNotFound.
+func (r *SQLRepository) ActiveMaintenanceWindow(ctx context.Context, tenantID string, t time.Time) (*domain.MaintenanceWindow, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + maintenanceColumns + ` FROM maintenance_windows
+		WHERE tenant_id = ? AND cancelled_at IS NULL AND starts_at <= ? AND ends_at > ?
+		ORDER BY ends_at DESC
+		LIMIT 1
+	`
+
+	window, err := scanMaintenanceWindow(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, t.UTC(), t.UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// scanMaintenanceWindow reads a row selected with maintenanceColumns.
+func scanMaintenanceWindow(row interface{ Scan(...any) error }) (*domain.MaintenanceWindow, error) {
+	var window domain.MaintenanceWindow
+	var createdBy, cancelledBy sql.NullString
+	var cancelledAt sql.NullTime
+
+	if err := row.Scan(
+		&window.ID, &window.TenantID, &window.StartsAt, &window.EndsAt, &window.Reason,
+		&createdBy, &window.CreatedAt, &cancelledBy, &cancelledAt,
+	); err != nil {
+		return nil, err
+	}
+
+	window.CreatedBy = createdBy.String
+	window.CancelledBy = cancelledBy.String
+	if cancelledAt.Valid {
+		t := cancelledAt.Time
+		window.CancelledAt = &t
+	}
+	return &window, nil
+}
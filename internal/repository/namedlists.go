@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// SaveNamedList creates or replaces one of the tenant's named lists.
+func (r *SQLRepository) SaveNamedList(ctx context.Context, tenantID string, list *domain.NamedList) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if list.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	values := list.Values
+	if values == nil {
+		values = []string{}
+	}
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal values: %w", err)
+	}
+
+	query := `
+		INSERT INTO named_lists (tenant_id, name, description, list_values, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, name) DO UPDATE SET
+			description = excluded.description,
+			list_values = excluded.list_values,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := list.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err = r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, list.Name, list.Description, string(valuesJSON), list.UpdatedBy, updatedAt.UTC(),
+	)
+	return err
+}
+
+// GetNamedList retrieves one of the tenant's named lists.
+func (r *SQLRepository) GetNamedList(ctx context.Context, tenantID string, name string) (*domain.NamedList, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, name, description, list_values, updated_by, updated_at
+		FROM named_lists
+		WHERE tenant_id = ? AND name = ?
+	`
+
+	lists, err := r.queryNamedLists(ctx, query, tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, ErrNotFound
+	}
+	return lists[0], nil
+}
+
+// ListNamedLists returns the tenant's named lists by name.
+func (r *SQLRepository) ListNamedLists(ctx context.Context, tenantID string) ([]*domain.NamedList, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, name, description, list_values, updated_by, updated_at
+		FROM named_lists
+		WHERE tenant_id = ?
+		ORDER BY name
+	`
+
+	return r.queryNamedLists(ctx, query, tenantID)
+}
+
+// ListAllNamedLists returns every tenant's named lists, by tenant and name.
+func (r *SQLRepository) ListAllNamedLists(ctx context.Context) ([]*domain.NamedList, error) {
+	query := `
+		SELECT tenant_id, name, description, list_values, updated_by, updated_at
+		FROM named_lists
+		ORDER BY tenant_id, name
+	`
+
+	return r.queryNamedLists(ctx, query)
+}
+
+// DeleteNamedList deletes one of the tenant's named lists.
+func (r *SQLRepository) DeleteNamedList(ctx context.Context, tenantID string, name string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(`DELETE FROM named_lists WHERE tenant_id = ? AND name = ?`), tenantID, name)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// queryNamedLists runs a named list query and scans its rows.
+func (r *SQLRepository) queryNamedLists(ctx context.Context, query string, args ...any) ([]*domain.NamedList, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*domain.NamedList
+	for rows.Next() {
+		var list domain.NamedList
+		var description, updatedBy sql.NullString
+		var values string
+		if err := rows.Scan(&list.TenantID, &list.Name, &description, &values, &updatedBy, &list.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(values), &list.Values); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal values of list %s: %w", list.Name, err)
+		}
+		list.Description = description.String
+		list.UpdatedBy = updatedBy.String
+		lists = append(lists, &list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// defaultOutcomeLimit caps outcome listings that don't set a limit.
+const defaultOutcomeLimit = 100
+
+// outcomeColumns is the column list read by scanOutcome.
+const outcomeColumns = `id, tenant_id, evaluation_id, tx_id, debtor_id, creditor_id, outcome, reason, reason_code, label, amount, amount_decimal, reported_by, occurred_at, created_at`
+
+// SaveOutcome stores an evaluation outcome with tenant isolation. An outcome
+// with an existing ID is left unchanged, so re-imported events are ignored.
+func (r *SQLRepository) SaveOutcome(ctx context.Context, tenantID string, outcome *domain.EvaluationOutcome) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if outcome.ID == "" || outcome.EvaluationID == "" || outcome.Outcome == "" {
+		return fmt.Errorf("%w: id, evaluationId and outcome are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO evaluation_outcomes (` + outcomeColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		outcome.ID, tenantID, outcome.EvaluationID, outcome.TxID, outcome.DebtorID, outcome.CreditorID,
+		outcome.Outcome, outcome.Reason, outcome.ReasonCode, outcome.Label, outcome.Amount.Float64(), outcome.Amount.String(), outcome.ReportedBy,
+		outcome.OccurredAt.UTC(), outcome.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// ListOutcomes retrieves a tenant's outcomes, latest first.
+func (r *SQLRepository) ListOutcomes(ctx context.Context, tenantID string, filter domain.OutcomeFilter) ([]*domain.EvaluationOutcome, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultOutcomeLimit
+	}
+
+	query := `SELECT ` + outcomeColumns + ` FROM evaluation_outcomes WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.EvaluationID != "" {
+		query += ` AND evaluation_id = ?`
+		args = append(args, filter.EvaluationID)
+	}
+	if filter.PartyID != "" {
+		query += ` AND (debtor_id = ? OR creditor_id = ?)`
+		args = append(args, filter.PartyID, filter.PartyID)
+	}
+	if filter.Outcome != "" {
+		query += ` AND outcome = ?`
+		args = append(args, filter.Outcome)
+	}
+	if filter.Label != "" {
+		query += ` AND label = ?`
+		args = append(args, filter.Label)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND occurred_at >= ?`
+		args = append(args, filter.Since.UTC())
+	}
+	query += ` ORDER BY occurred_at DESC, created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outcomes []*domain.EvaluationOutcome
+	for rows.Next() {
+		outcome, err := scanOutcome(rows)
+		if err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, rows.Err()
+}
+
+// scanOutcome reads a row selected with outcomeColumns.
+func scanOutcome(row interface{ Scan(...any) error }) (*domain.EvaluationOutcome, error) {
+	var outcome domain.EvaluationOutcome
+	var amount float64
+	var reason, reasonCode, label, amountDecimal, reportedBy sql.NullString
+
+	if err := row.Scan(
+		&outcome.ID, &outcome.TenantID, &outcome.EvaluationID, &outcome.TxID,
+		&outcome.DebtorID, &outcome.CreditorID, &outcome.Outcome, &reason, &reasonCode, &label,
+		&amount, &amountDecimal, &reportedBy, &outcome.OccurredAt, &outcome.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	outcome.Amount = decodeAmount(amount, amountDecimal)
+	outcome.Reason = reason.String
+	outcome.ReasonCode = reasonCode.String
+	outcome.Label = label.String
+	outcome.ReportedBy = reportedBy.String
+	return &outcome, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// projectionBackfillPage is the number of evaluations projected per query
+// by backfillEvaluationProjections.
+const projectionBackfillPage = 500
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// saveEvaluationProjection writes an evaluation's rule and typology results
+// to the projection tables.
+func (r *SQLRepository) saveEvaluationProjection(ctx context.Context, db execer, tenantID string, eval *domain.Evaluation) error {
+	ruleQuery := r.rebind(`
+		INSERT INTO evaluation_rule_results (tenant_id, evaluation_id, rule_id, outcome, score, shadow, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	seen := make(map[string]bool)
+	for _, result := range eval.RuleResults {
+		if seen[result.RuleID] {
+			continue
+		}
+		seen[result.RuleID] = true
+
+		shadow := 0
+		if result.Shadow {
+			shadow = 1
+		}
+		if _, err := db.ExecContext(ctx, ruleQuery, tenantID, eval.ID, result.RuleID, result.SubRuleRef, result.Score, shadow, eval.Timestamp); err != nil {
+			return fmt.Errorf("failed to project rule result %s: %w", result.RuleID, err)
+		}
+	}
+
+	typologyQuery := r.rebind(`
+		INSERT INTO evaluation_typology_results (tenant_id, evaluation_id, typology_id, score, triggered, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	seen = make(map[string]bool)
+	for _, result := range eval.TypologyResults {
+		if seen[result.TypologyID] {
+			continue
+		}
+		seen[result.TypologyID] = true
+
+		triggered := 0
+		if result.Triggered {
+			triggered = 1
+		}
+		if _, err := db.ExecContext(ctx, typologyQuery, tenantID, eval.ID, result.TypologyID, result.Score, triggered, eval.Timestamp); err != nil {
+			return fmt.Errorf("failed to project typology result %s: %w", result.TypologyID, err)
+		}
+	}
+	return nil
+}
+
+// contributionFilter returns the SQL conditions restricting evaluations,
+// whose tenant and ID are the given columns, to those ruleID fired in and
+// where typologyID triggered or, with minContribution, scored above it.
+func contributionFilter(tenantColumn, idColumn, ruleID, typologyID string, minContribution *float64) (string, []any) {
+	var query string
+	var args []any
+	if ruleID != "" {
+		query += ` AND EXISTS (SELECT 1 FROM evaluation_rule_results p WHERE p.tenant_id = ` + tenantColumn + ` AND p.evaluation_id = ` + idColumn +
+			` AND p.rule_id = ? AND p.shadow = 0 AND p.outcome IN (?, ?))`
+		args = append(args, ruleID, domain.RuleOutcomeFail, domain.RuleOutcomeReview)
+	}
+	if typologyID != "" {
+		query += ` AND EXISTS (SELECT 1 FROM evaluation_typology_results p WHERE p.tenant_id = ` + tenantColumn + ` AND p.evaluation_id = ` + idColumn +
+			` AND p.typology_id = ?`
+		args = append(args, typologyID)
+		if minContribution != nil {
+			query += ` AND p.score > ?)`
+			args = append(args, *minContribution)
+		} else {
+			query += ` AND p.triggered = 1)`
+		}
+	}
+	return query, args
+}
+
+// backfillEvaluationProjections projects the evaluations stored before the
+// projection tables existed. It only runs while both projections are empty,
+// so it costs one query once they are in use.
+func (r *SQLRepository) backfillEvaluationProjections() error {
+	ctx := context.Background()
+
+	var projected int
+	query := `SELECT
+		(SELECT COUNT(*) FROM (SELECT 1 FROM evaluation_rule_results LIMIT 1) p) +
+		(SELECT COUNT(*) FROM (SELECT 1 FROM evaluation_typology_results LIMIT 1) q)`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&projected); err != nil {
+		return fmt.Errorf("failed to inspect evaluation projections: %w", err)
+	}
+	if projected > 0 {
+		return nil
+	}
+
+	page := r.rebind(`
+		SELECT id, tenant_id, timestamp, rule_results, typology_results FROM evaluations
+		WHERE id > ? ORDER BY id LIMIT ?
+	`)
+	after := ""
+	for {
+		// Read a page before writing it: the in-memory database has a
+		// single connection
+		rows, err := r.db.QueryContext(ctx, page, after, projectionBackfillPage)
+		if err != nil {
+			return fmt.Errorf("failed to read evaluations: %w", err)
+		}
+		var evals []*domain.Evaluation
+		for rows.Next() {
+			var eval domain.Evaluation
+			var ruleResults string
+			var typologyResults sql.NullString
+			if err := rows.Scan(&eval.ID, &eval.TenantID, &eval.Timestamp, &ruleResults, &typologyResults); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to read evaluations: %w", err)
+			}
+			json.Unmarshal([]byte(ruleResults), &eval.RuleResults)
+			if typologyResults.Valid {
+				json.Unmarshal([]byte(typologyResults.String), &eval.TypologyResults)
+			}
+			evals = append(evals, &eval)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read evaluations: %w", err)
+		}
+
+		for _, eval := range evals {
+			if err := r.saveEvaluationProjection(ctx, r.db, eval.TenantID, eval); err != nil {
+				return err
+			}
+			after = eval.ID
+		}
+		if len(evals) < projectionBackfillPage {
+			return nil
+		}
+	}
+}
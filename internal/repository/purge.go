@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// purgeTables lists the tables of a tenant's activity with the column that
+// dates each row. Configuration such as rules, typologies, webhooks and
+// watchlists is never purged.
+var purgeTables = []struct {
+	table  string
+	column string
+}{
+	{"transactions", "created_at"},
+	{"evaluations", "timestamp"},
+	{"evaluation_rule_results", "timestamp"},
+	{"evaluation_typology_results", "timestamp"},
+	{"alerts", "created_at"},
+	{"alert_events", "at"},
+	{"activation_samples", "created_at"},
+	{"evaluation_outcomes", "created_at"},
+	{"webhook_deliveries", "created_at"},
+	{"dead_letters", "failed_at"},
+	{"jobs", "created_at"},
+	{"job_files", "created_at"},
+	{"counterparty_edges", "last_seen"},
+	{"review_claims", "claimed_at"},
+	{"entity_baselines", "updated_at"},
+	{"account_balances", "updated_at"},
+}
+
+// ListTenantIDs returns every tenant with stored transactions or
+// evaluations, sorted by ID.
+func (r *SQLRepository) ListTenantIDs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT tenant_id FROM transactions
+		UNION
+		SELECT tenant_id FROM evaluations
+		ORDER BY tenant_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenantIDs []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	return tenantIDs, rows.Err()
+}
+
+// PurgeTenantData deletes the tenant's activity dated before the given time
+// and returns the number of rows deleted. The hash-chained evaluation log
+// can't lose its oldest records without breaking verification, so it is
+// deleted whole once its newest record is before the given time.
+func (r *SQLRepository) PurgeTenantData(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	before = before.UTC()
+	var purged int64
+	for _, t := range purgeTables {
+		query := `DELETE FROM ` + t.table + ` WHERE tenant_id = ? AND ` + t.column + ` < ?`
+		result, err := tx.ExecContext(ctx, r.rebind(query), tenantID, before)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", t.table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		purged += n
+	}
+
+	query := `
+		DELETE FROM evaluation_log
+		WHERE tenant_id = ? AND NOT EXISTS (
+			SELECT 1 FROM evaluation_log WHERE tenant_id = ? AND created_at >= ?
+		)
+	`
+	result, err := tx.ExecContext(ctx, r.rebind(query), tenantID, tenantID, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge evaluation_log: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	purged += n
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
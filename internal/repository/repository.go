@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/opensource-finance/osprey/internal/domain"
@@ -15,6 +16,7 @@ import (
 var (
 	ErrNotFound     = errors.New("record not found")
 	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("record already exists")
 )
 
 // SQLRepository implements domain.Repository using database/sql.
@@ -34,6 +36,8 @@ func New(cfg domain.RepositoryConfig) (domain.Repository, error) {
 		db, err = openSQLite(cfg)
 	case "postgres":
 		db, err = openPostgres(cfg)
+	case "memory":
+		db, err = openMemory()
 	default:
 		return nil, fmt.Errorf("unsupported driver: %s", cfg.Driver)
 	}
@@ -42,15 +46,18 @@ func New(cfg domain.RepositoryConfig) (domain.Repository, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Configure connection pool
-	if cfg.MaxOpenConns > 0 {
-		db.SetMaxOpenConns(cfg.MaxOpenConns)
-	}
-	if cfg.MaxIdleConns > 0 {
-		db.SetMaxIdleConns(cfg.MaxIdleConns)
-	}
-	if cfg.ConnMaxLifetime > 0 {
-		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	// Configure connection pool; the in-memory database lives on its one
+	// connection, so its pool is fixed
+	if cfg.Driver != "memory" {
+		if cfg.MaxOpenConns > 0 {
+			db.SetMaxOpenConns(cfg.MaxOpenConns)
+		}
+		if cfg.MaxIdleConns > 0 {
+			db.SetMaxIdleConns(cfg.MaxIdleConns)
+		}
+		if cfg.ConnMaxLifetime > 0 {
+			db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		}
 	}
 
 	repo := &SQLRepository{
@@ -73,10 +80,99 @@ func (r *SQLRepository) migrate() error {
 			return err
 		}
 	}
-	return nil
+
+	for _, m := range columnMigrations {
+		exists, err := r.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)
+		if _, err := r.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	if err := r.migrateTransactionKey(); err != nil {
+		return err
+	}
+	if err := r.backfillEvaluationProjections(); err != nil {
+		return err
+	}
+	return r.backfillCounterpartyEdges()
+}
+
+// transactionColumns lists every column of the transactions table, for
+// copying rows between its versions.
+const transactionColumns = `id, tenant_id, type, debtor_id, debtor_account_id,
+	creditor_id, creditor_account_id, amount, amount_decimal, currency,
+	timestamp, created_at, metadata, components,
+	reversal_of, part_of_batch, related_to, original_message`
+
+// migrateTransactionKey rekeys a transactions table created with id alone as
+// its primary key by (tenant_id, id), so tenants may reuse each other's
+// upstream transaction IDs. SQLite can't alter a primary key, so its table is
+// rebuilt.
+func (r *SQLRepository) migrateTransactionKey() error {
+	var query string
+	if r.driver == "postgres" {
+		query = `SELECT COUNT(*) FROM information_schema.key_column_usage WHERE table_name = 'transactions' AND constraint_name = 'transactions_pkey'`
+	} else {
+		query = `SELECT COUNT(*) FROM pragma_table_info('transactions') WHERE pk > 0`
+	}
+	var keyColumns int
+	if err := r.db.QueryRow(query).Scan(&keyColumns); err != nil {
+		return fmt.Errorf("failed to inspect transactions primary key: %w", err)
+	}
+	if keyColumns != 1 {
+		return nil
+	}
+
+	stmts := []string{`ALTER TABLE transactions DROP CONSTRAINT transactions_pkey, ADD PRIMARY KEY (tenant_id, id)`}
+	if r.driver != "postgres" {
+		// Indexes keep their names on the renamed table, so they are
+		// recreated once it is dropped
+		stmts = []string{
+			`ALTER TABLE transactions RENAME TO transactions_old`,
+			schemaTransactions,
+			`INSERT INTO transactions (` + transactionColumns + `) SELECT ` + transactionColumns + ` FROM transactions_old`,
+			`DROP TABLE transactions_old`,
+			schemaTransactions,
+		}
+	}
+
+	dbTx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := dbTx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to rekey transactions by tenant: %w", err)
+		}
+	}
+	return dbTx.Commit()
+}
+
+// columnExists reports whether a column is present on a table.
+func (r *SQLRepository) columnExists(table, column string) (bool, error) {
+	var query string
+	if r.driver == "postgres" {
+		query = `SELECT COUNT(*) FROM information_schema.columns WHERE table_name = ? AND column_name = ?`
+	} else {
+		query = `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	}
+
+	var count int
+	if err := r.db.QueryRow(r.rebind(query), table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to inspect column %s.%s: %w", table, column, err)
+	}
+	return count > 0, nil
 }
 
-// SaveTransaction stores a transaction with tenant isolation.
+// SaveTransaction stores a transaction with tenant isolation. An ID the
+// tenant already has is refused with ErrConflict.
 func (r *SQLRepository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
 	if tenantID == "" {
 		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
@@ -84,23 +180,51 @@ func (r *SQLRepository) SaveTransaction(ctx context.Context, tenantID string, tx
 
 	metadata, _ := json.Marshal(tx.Metadata)
 
+	var components sql.NullString
+	if tx.Components != nil {
+		data, err := json.Marshal(tx.Components)
+		if err != nil {
+			return fmt.Errorf("failed to encode amount components: %w", err)
+		}
+		components = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var relatedTo sql.NullString
+	if len(tx.RelatedTo) > 0 {
+		data, _ := json.Marshal(tx.RelatedTo)
+		relatedTo = sql.NullString{String: string(data), Valid: true}
+	}
+
 	query := `
 		INSERT INTO transactions (
 			id, tenant_id, type, debtor_id, debtor_account_id,
-			creditor_id, creditor_account_id, amount, currency,
-			timestamp, created_at, metadata, original_message
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			creditor_id, creditor_account_id, amount, amount_decimal, currency,
+			timestamp, created_at, metadata, components,
+			reversal_of, part_of_batch, related_to, original_message
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
 	`
 
-	_, err := r.db.ExecContext(ctx, r.rebind(query),
+	result, err := r.db.ExecContext(ctx, r.rebind(query),
 		tx.ID, tenantID, tx.Type,
 		tx.DebtorID, tx.DebtorAccountID,
 		tx.CreditorID, tx.CreditorAcctID,
-		tx.Amount, tx.Currency,
+		tx.Amount.Float64(), tx.Amount.String(), tx.Currency,
 		tx.Timestamp, tx.CreatedAt,
-		string(metadata), tx.OriginalMessage,
+		string(metadata), components,
+		tx.ReversalOf, tx.PartOfBatch, relatedTo, tx.OriginalMessage,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: transaction %s", ErrConflict, tx.ID)
+	}
+	return nil
 }
 
 // GetTransaction retrieves a transaction by ID with tenant isolation.
@@ -111,36 +235,27 @@ func (r *SQLRepository) GetTransaction(ctx context.Context, tenantID string, txI
 
 	query := `
 		SELECT id, tenant_id, type, debtor_id, debtor_account_id,
-			   creditor_id, creditor_account_id, amount, currency,
-			   timestamp, created_at, metadata
+			   creditor_id, creditor_account_id, amount, amount_decimal, currency,
+			   timestamp, created_at, metadata, components,
+			   reversal_of, part_of_batch, related_to
 		FROM transactions
 		WHERE tenant_id = ? AND id = ?
 	`
 
-	var tx domain.Transaction
-	var metadata string
-
-	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, txID).Scan(
-		&tx.ID, &tx.TenantID, &tx.Type,
-		&tx.DebtorID, &tx.DebtorAccountID,
-		&tx.CreditorID, &tx.CreditorAcctID,
-		&tx.Amount, &tx.Currency,
-		&tx.Timestamp, &tx.CreatedAt,
-		&metadata,
-	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, ErrNotFound
-	}
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, txID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if metadata != "" {
-		json.Unmarshal([]byte(metadata), &tx.Metadata)
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, err
 	}
-
-	return &tx, nil
+	if len(transactions) == 0 {
+		return nil, ErrNotFound
+	}
+	return transactions[0], nil
 }
 
 // GetTransactionsByEntity retrieves transactions for an entity with tenant isolation.
@@ -151,8 +266,9 @@ func (r *SQLRepository) GetTransactionsByEntity(ctx context.Context, tenantID st
 
 	query := `
 		SELECT id, tenant_id, type, debtor_id, debtor_account_id,
-			   creditor_id, creditor_account_id, amount, currency,
-			   timestamp, created_at, metadata
+			   creditor_id, creditor_account_id, amount, amount_decimal, currency,
+			   timestamp, created_at, metadata, components,
+			   reversal_of, part_of_batch, related_to
 		FROM transactions
 		WHERE tenant_id = ?
 		  AND (debtor_id = ? OR creditor_id = ?)
@@ -166,18 +282,71 @@ func (r *SQLRepository) GetTransactionsByEntity(ctx context.Context, tenantID st
 	}
 	defer rows.Close()
 
+	return scanTransactions(rows)
+}
+
+// ListTransactions retrieves a page of a tenant's transactions with timestamps
+// in [since, until), oldest first.
+func (r *SQLRepository) ListTransactions(ctx context.Context, tenantID string, since, until time.Time, offset, limit int) ([]*domain.Transaction, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT id, tenant_id, type, debtor_id, debtor_account_id,
+			   creditor_id, creditor_account_id, amount, amount_decimal, currency,
+			   timestamp, created_at, metadata, components,
+			   reversal_of, part_of_batch, related_to
+		FROM transactions
+		WHERE tenant_id = ?
+		  AND timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp, id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, since, until, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTransactions(rows)
+}
+
+// CountTransactions counts a tenant's transactions with timestamps in [since, until).
+func (r *SQLRepository) CountTransactions(ctx context.Context, tenantID string, since, until time.Time) (int, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ?
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, since, until).Scan(&count)
+	return count, err
+}
+
+// scanTransactions reads transaction rows selected with the standard column list.
+func scanTransactions(rows *sql.Rows) ([]*domain.Transaction, error) {
 	var transactions []*domain.Transaction
 	for rows.Next() {
 		var tx domain.Transaction
 		var metadata string
+		var amount float64
+		var amountDecimal, components, reversalOf, partOfBatch, relatedTo sql.NullString
 
 		if err := rows.Scan(
 			&tx.ID, &tx.TenantID, &tx.Type,
 			&tx.DebtorID, &tx.DebtorAccountID,
 			&tx.CreditorID, &tx.CreditorAcctID,
-			&tx.Amount, &tx.Currency,
+			&amount, &amountDecimal, &tx.Currency,
 			&tx.Timestamp, &tx.CreatedAt,
-			&metadata,
+			&metadata, &components,
+			&reversalOf, &partOfBatch, &relatedTo,
 		); err != nil {
 			return nil, err
 		}
@@ -185,6 +354,13 @@ func (r *SQLRepository) GetTransactionsByEntity(ctx context.Context, tenantID st
 		if metadata != "" {
 			json.Unmarshal([]byte(metadata), &tx.Metadata)
 		}
+		tx.Amount = decodeAmount(amount, amountDecimal)
+		tx.Components = decodeComponents(components)
+		tx.ReversalOf = reversalOf.String
+		tx.PartOfBatch = partOfBatch.String
+		if relatedTo.String != "" {
+			json.Unmarshal([]byte(relatedTo.String), &tx.RelatedTo)
+		}
 
 		transactions = append(transactions, &tx)
 	}
@@ -204,26 +380,35 @@ func (r *SQLRepository) SaveRuleConfig(ctx context.Context, tenantID string, rul
 	if rule.Enabled {
 		enabled = 1
 	}
+	shadow := 0
+	if rule.Shadow {
+		shadow = 1
+	}
 
 	now := time.Now().UTC()
 
 	query := `
 		INSERT INTO rule_configs (
-			id, tenant_id, name, description, version, expression, bands, weight, enabled, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			id, tenant_id, name, description, version, owner, source_file, language, expression, bands, weight, enabled, sample_rate, shadow, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id, tenant_id, version) DO UPDATE SET
 			name = excluded.name,
 			description = excluded.description,
+			owner = excluded.owner,
+			source_file = excluded.source_file,
+			language = excluded.language,
 			expression = excluded.expression,
 			bands = excluded.bands,
 			weight = excluded.weight,
 			enabled = excluded.enabled,
+			sample_rate = excluded.sample_rate,
+			shadow = excluded.shadow,
 			updated_at = excluded.updated_at
 	`
 
 	_, err := r.db.ExecContext(ctx, r.rebind(query),
 		rule.ID, tenantID, rule.Name, rule.Description,
-		rule.Version, rule.Expression, string(bands), rule.Weight, enabled,
+		rule.Version, rule.Owner, rule.SourceFile, rule.Language, rule.Expression, string(bands), rule.Weight, enabled, rule.SampleRate, shadow,
 		now, now,
 	)
 	return err
@@ -236,7 +421,7 @@ func (r *SQLRepository) GetRuleConfig(ctx context.Context, tenantID string, rule
 	}
 
 	query := `
-		SELECT id, tenant_id, name, description, version, expression, bands, weight, enabled
+		SELECT id, tenant_id, name, description, version, owner, source_file, language, expression, bands, weight, enabled, sample_rate, shadow
 		FROM rule_configs
 		WHERE tenant_id = ? AND id = ? AND enabled = 1
 		ORDER BY version DESC
@@ -245,11 +430,11 @@ func (r *SQLRepository) GetRuleConfig(ctx context.Context, tenantID string, rule
 
 	var cfg domain.RuleConfig
 	var bands string
-	var enabled int
+	var enabled, shadow int
 
 	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, ruleID).Scan(
 		&cfg.ID, &cfg.TenantID, &cfg.Name, &cfg.Description,
-		&cfg.Version, &cfg.Expression, &bands, &cfg.Weight, &enabled,
+		&cfg.Version, &cfg.Owner, &cfg.SourceFile, &cfg.Language, &cfg.Expression, &bands, &cfg.Weight, &enabled, &cfg.SampleRate, &shadow,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -260,6 +445,7 @@ func (r *SQLRepository) GetRuleConfig(ctx context.Context, tenantID string, rule
 	}
 
 	cfg.Enabled = enabled == 1
+	cfg.Shadow = shadow == 1
 	json.Unmarshal([]byte(bands), &cfg.Bands)
 
 	return &cfg, nil
@@ -272,32 +458,112 @@ func (r *SQLRepository) ListRuleConfigs(ctx context.Context, tenantID string) ([
 	}
 
 	query := `
-		SELECT id, tenant_id, name, description, version, expression, bands, weight, enabled
+		SELECT id, tenant_id, name, description, version, owner, source_file, language, expression, bands, weight, enabled, sample_rate, shadow
 		FROM rule_configs
 		WHERE tenant_id = ? AND enabled = 1
 		ORDER BY name
 	`
 
+	return r.queryRuleConfigs(ctx, query, tenantID)
+}
+
+// DeleteRuleConfig soft-deletes every version of a rule by setting enabled = 0.
+func (r *SQLRepository) DeleteRuleConfig(ctx context.Context, tenantID string, ruleID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE rule_configs
+		SET enabled = 0, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), time.Now().UTC(), tenantID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListRuleVersions returns every stored version of the tenant's rules,
+// disabled ones included, by rule ID and then oldest first.
+func (r *SQLRepository) ListRuleVersions(ctx context.Context, tenantID string) ([]*domain.RuleVersion, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT id, tenant_id, version, owner, enabled, created_at, updated_at
+		FROM rule_configs
+		WHERE tenant_id = ?
+		ORDER BY id, created_at, version
+	`
+
 	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var versions []*domain.RuleVersion
+	for rows.Next() {
+		var v domain.RuleVersion
+		var enabled int
+		if err := rows.Scan(&v.RuleID, &v.TenantID, &v.Version, &v.Owner, &enabled, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			return nil, err
+		}
+		v.Enabled = enabled == 1
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+// ListAllRuleConfigs retrieves the active rule configurations of every tenant,
+// global rules included. It feeds the rule engine loader only.
+func (r *SQLRepository) ListAllRuleConfigs(ctx context.Context) ([]*domain.RuleConfig, error) {
+	query := `
+		SELECT id, tenant_id, name, description, version, owner, source_file, language, expression, bands, weight, enabled, sample_rate, shadow
+		FROM rule_configs
+		WHERE enabled = 1
+		ORDER BY tenant_id, name
+	`
+
+	return r.queryRuleConfigs(ctx, query)
+}
+
+// queryRuleConfigs runs a rule configuration query and scans its rows.
+func (r *SQLRepository) queryRuleConfigs(ctx context.Context, query string, args ...any) ([]*domain.RuleConfig, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	var configs []*domain.RuleConfig
 	for rows.Next() {
 		var cfg domain.RuleConfig
 		var bands string
-		var enabled int
+		var enabled, shadow int
 
 		if err := rows.Scan(
 			&cfg.ID, &cfg.TenantID, &cfg.Name, &cfg.Description,
-			&cfg.Version, &cfg.Expression, &bands, &cfg.Weight, &enabled,
+			&cfg.Version, &cfg.Owner, &cfg.SourceFile, &cfg.Language, &cfg.Expression, &bands, &cfg.Weight, &enabled, &cfg.SampleRate, &shadow,
 		); err != nil {
 			return nil, err
 		}
 
 		cfg.Enabled = enabled == 1
+		cfg.Shadow = shadow == 1
 		json.Unmarshal([]byte(bands), &cfg.Bands)
 		configs = append(configs, &cfg)
 	}
@@ -322,137 +588,418 @@ func (r *SQLRepository) SaveEvaluation(ctx context.Context, tenantID string, eva
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, r.rebind(query),
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.rebind(query),
 		eval.ID, tenantID, eval.TxID, eval.Status, eval.Score, eval.Timestamp,
 		string(ruleResults), string(typologyResults), string(metadata),
-	)
-	return err
+	); err != nil {
+		return err
+	}
+	if err := r.saveEvaluationProjection(ctx, tx, tenantID, eval); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// evaluationColumns is the column list read by scanEvaluation.
+const evaluationColumns = `id, tenant_id, tx_id, status, score, timestamp, rule_results, typology_results, metadata`
+
 // GetEvaluation retrieves an evaluation by ID with tenant isolation.
 func (r *SQLRepository) GetEvaluation(ctx context.Context, tenantID string, evalID string) (*domain.Evaluation, error) {
 	if tenantID == "" {
 		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
 	}
 
-	query := `
-		SELECT id, tenant_id, tx_id, status, score, timestamp,
-			   rule_results, typology_results, metadata
-		FROM evaluations
-		WHERE tenant_id = ? AND id = ?
-	`
-
-	var eval domain.Evaluation
-	var ruleResults, typologyResults, metadata string
-
-	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, evalID).Scan(
-		&eval.ID, &eval.TenantID, &eval.TxID, &eval.Status, &eval.Score, &eval.Timestamp,
-		&ruleResults, &typologyResults, &metadata,
-	)
+	query := `SELECT ` + evaluationColumns + ` FROM evaluations WHERE tenant_id = ? AND id = ?`
 
+	eval, err := scanEvaluation(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, evalID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	json.Unmarshal([]byte(ruleResults), &eval.RuleResults)
-	json.Unmarshal([]byte(typologyResults), &eval.TypologyResults)
-	json.Unmarshal([]byte(metadata), &eval.Metadata)
-
-	return &eval, nil
+	return eval, err
 }
 
-// SaveTypology stores a typology configuration with tenant isolation.
-func (r *SQLRepository) SaveTypology(ctx context.Context, tenantID string, typology *domain.Typology) error {
+// GetEvaluationByTx retrieves a transaction's latest evaluation with tenant
+// isolation.
+func (r *SQLRepository) GetEvaluationByTx(ctx context.Context, tenantID string, txID string) (*domain.Evaluation, error) {
 	if tenantID == "" {
-		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
-	}
-
-	rules, _ := json.Marshal(typology.Rules)
-
-	enabled := 0
-	if typology.Enabled {
-		enabled = 1
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
 	}
 
-	now := time.Now().UTC()
-
 	query := `
-		INSERT INTO typologies (
-			id, tenant_id, name, description, version, rules, alert_threshold, enabled, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(id, tenant_id, version) DO UPDATE SET
-			name = excluded.name,
-			description = excluded.description,
-			rules = excluded.rules,
-			alert_threshold = excluded.alert_threshold,
-			enabled = excluded.enabled,
-			updated_at = excluded.updated_at
+		SELECT ` + evaluationColumns + ` FROM evaluations
+		WHERE tenant_id = ? AND tx_id = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
 	`
 
-	_, err := r.db.ExecContext(ctx, r.rebind(query),
-		typology.ID, tenantID, typology.Name, typology.Description,
-		typology.Version, string(rules), typology.AlertThreshold, enabled,
-		now, now,
-	)
-	return err
+	eval, err := scanEvaluation(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, txID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return eval, err
 }
 
-// GetTypology retrieves a typology configuration with tenant isolation.
-func (r *SQLRepository) GetTypology(ctx context.Context, tenantID string, typologyID string) (*domain.Typology, error) {
+// defaultEvaluationLimit caps evaluation listings that don't set a limit.
+const defaultEvaluationLimit = 100
+
+// ListEvaluations retrieves a tenant's evaluations, latest first, with tenant
+// isolation. Party filters read the stored transactions, and rule and
+// typology filters the evaluation projections.
+func (r *SQLRepository) ListEvaluations(ctx context.Context, tenantID string, filter domain.EvaluationFilter) ([]*domain.Evaluation, error) {
 	if tenantID == "" {
 		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
 	}
 
-	query := `
-		SELECT id, tenant_id, name, description, version, rules, alert_threshold, enabled, created_at, updated_at
-		FROM typologies
-		WHERE tenant_id = ? AND id = ? AND enabled = 1
-		ORDER BY version DESC
-		LIMIT 1
-	`
-
-	var t domain.Typology
-	var rules string
-	var enabled int
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultEvaluationLimit
+	}
 
-	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, typologyID).Scan(
-		&t.ID, &t.TenantID, &t.Name, &t.Description,
-		&t.Version, &rules, &t.AlertThreshold, &enabled,
-		&t.CreatedAt, &t.UpdatedAt,
-	)
+	query := `SELECT ` + evaluationColumns + ` FROM evaluations e WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.MinScore != nil {
+		query += ` AND score >= ?`
+		args = append(args, *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		query += ` AND score <= ?`
+		args = append(args, *filter.MaxScore)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND timestamp < ?`
+		args = append(args, filter.Until.UTC())
+	}
+	if filter.TxID != "" {
+		query += ` AND tx_id = ?`
+		args = append(args, filter.TxID)
+	}
+	if filter.DebtorID != "" {
+		query += ` AND EXISTS (SELECT 1 FROM transactions t WHERE t.tenant_id = e.tenant_id AND t.id = e.tx_id AND t.debtor_id = ?)`
+		args = append(args, filter.DebtorID)
+	}
+	if filter.CreditorID != "" {
+		query += ` AND EXISTS (SELECT 1 FROM transactions t WHERE t.tenant_id = e.tenant_id AND t.id = e.tx_id AND t.creditor_id = ?)`
+		args = append(args, filter.CreditorID)
+	}
+	contribution, contributionArgs := contributionFilter("e.tenant_id", "e.id", filter.RuleID, filter.TypologyID, filter.MinContribution)
+	query += contribution
+	args = append(args, contributionArgs...)
 
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, ErrNotFound
+	if after := filter.After; after != nil {
+		query += ` AND (timestamp < ? OR (timestamp = ? AND id < ?))`
+		args = append(args, after.Timestamp.UTC(), after.Timestamp.UTC(), after.ID)
 	}
+	query += ` ORDER BY timestamp DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	t.Enabled = enabled == 1
-	if err := json.Unmarshal([]byte(rules), &t.Rules); err != nil {
-		return nil, fmt.Errorf("failed to parse typology rules: %w", err)
+	var evals []*domain.Evaluation
+	for rows.Next() {
+		eval, err := scanEvaluation(rows)
+		if err != nil {
+			return nil, err
+		}
+		evals = append(evals, eval)
 	}
-
-	return &t, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return evals, nil
 }
 
-// ListTypologies retrieves all active typology configurations for a tenant.
-func (r *SQLRepository) ListTypologies(ctx context.Context, tenantID string) ([]*domain.Typology, error) {
-	if tenantID == "" {
+// scanEvaluation reads a row selected with evaluationColumns.
+func scanEvaluation(row interface{ Scan(...any) error }) (*domain.Evaluation, error) {
+	var eval domain.Evaluation
+	var ruleResults, typologyResults, metadata string
+
+	if err := row.Scan(
+		&eval.ID, &eval.TenantID, &eval.TxID, &eval.Status, &eval.Score, &eval.Timestamp,
+		&ruleResults, &typologyResults, &metadata,
+	); err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal([]byte(ruleResults), &eval.RuleResults)
+	json.Unmarshal([]byte(typologyResults), &eval.TypologyResults)
+	json.Unmarshal([]byte(metadata), &eval.Metadata)
+
+	return &eval, nil
+}
+
+// ListTenantActivity reports, for every tenant with evaluations, how many
+// evaluations and alerts it had since the given time and when it was last
+// evaluated. Tenants are sorted by ID.
+func (r *SQLRepository) ListTenantActivity(ctx context.Context, since time.Time) ([]*domain.TenantActivity, error) {
+	query := `
+		SELECT tenant_id,
+			SUM(CASE WHEN timestamp >= ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN timestamp >= ? AND status = ? THEN 1 ELSE 0 END)
+		FROM evaluations
+		GROUP BY tenant_id
+		ORDER BY tenant_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), since, since, domain.StatusAlert)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activity []*domain.TenantActivity
+	for rows.Next() {
+		var a domain.TenantActivity
+		if err := rows.Scan(&a.TenantID, &a.Evaluations, &a.Alerts); err != nil {
+			return nil, err
+		}
+		activity = append(activity, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Read the latest timestamp per tenant as a column so drivers return it typed
+	last := `
+		SELECT timestamp FROM evaluations
+		WHERE tenant_id = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`
+	for _, a := range activity {
+		if err := r.db.QueryRowContext(ctx, r.rebind(last), a.TenantID).Scan(&a.LastEvaluationAt); err != nil {
+			return nil, err
+		}
+	}
+	return activity, nil
+}
+
+// AppendEvaluationLog inserts the next record of a tenant's evaluation log.
+// Returns an error if a record with the same sequence number already exists.
+func (r *SQLRepository) AppendEvaluationLog(ctx context.Context, tenantID string, record *domain.EvaluationLogRecord) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO evaluation_log (
+			tenant_id, seq, evaluation_id, tx_id, payload, prev_hash, hash, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, record.Seq, record.EvaluationID, record.TxID,
+		record.Payload, record.PrevHash, record.Hash, record.CreatedAt,
+	)
+	return err
+}
+
+// GetLastEvaluationLog retrieves the newest record of a tenant's evaluation log.
+func (r *SQLRepository) GetLastEvaluationLog(ctx context.Context, tenantID string) (*domain.EvaluationLogRecord, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, seq, evaluation_id, tx_id, payload, prev_hash, hash, created_at
+		FROM evaluation_log
+		WHERE tenant_id = ?
+		ORDER BY seq DESC
+		LIMIT 1
+	`
+
+	var rec domain.EvaluationLogRecord
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID).Scan(
+		&rec.TenantID, &rec.Seq, &rec.EvaluationID, &rec.TxID,
+		&rec.Payload, &rec.PrevHash, &rec.Hash, &rec.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+// ListEvaluationLog retrieves up to limit records with seq > afterSeq, in chain order.
+func (r *SQLRepository) ListEvaluationLog(ctx context.Context, tenantID string, afterSeq int64, limit int) ([]*domain.EvaluationLogRecord, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, seq, evaluation_id, tx_id, payload, prev_hash, hash, created_at
+		FROM evaluation_log
+		WHERE tenant_id = ? AND seq > ?
+		ORDER BY seq
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*domain.EvaluationLogRecord
+	for rows.Next() {
+		var rec domain.EvaluationLogRecord
+		if err := rows.Scan(
+			&rec.TenantID, &rec.Seq, &rec.EvaluationID, &rec.TxID,
+			&rec.Payload, &rec.PrevHash, &rec.Hash, &rec.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+
+	return records, rows.Err()
+}
+
+// SaveTypology stores a typology configuration with tenant isolation.
+func (r *SQLRepository) SaveTypology(ctx context.Context, tenantID string, typology *domain.Typology) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	rules, _ := json.Marshal(typology.Rules)
+	weights, condition := encodeTypologyRefs(typology)
+
+	enabled := 0
+	if typology.Enabled {
+		enabled = 1
+	}
+
+	now := time.Now().UTC()
+
+	query := `
+		INSERT INTO typologies (
+			id, tenant_id, name, description, version, rules, alert_threshold,
+			min_rules_fired, min_coverage, typology_weights, trigger_condition,
+			enabled, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id, tenant_id, version) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			rules = excluded.rules,
+			alert_threshold = excluded.alert_threshold,
+			min_rules_fired = excluded.min_rules_fired,
+			min_coverage = excluded.min_coverage,
+			typology_weights = excluded.typology_weights,
+			trigger_condition = excluded.trigger_condition,
+			enabled = excluded.enabled,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		typology.ID, tenantID, typology.Name, typology.Description,
+		typology.Version, string(rules), typology.AlertThreshold,
+		typology.MinRulesFired, typology.MinCoverage, weights, condition,
+		enabled, now, now,
+	)
+	return err
+}
+
+// GetTypology retrieves a typology configuration with tenant isolation.
+func (r *SQLRepository) GetTypology(ctx context.Context, tenantID string, typologyID string) (*domain.Typology, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT id, tenant_id, name, description, version, rules, alert_threshold,
+			min_rules_fired, min_coverage, typology_weights, trigger_condition,
+			enabled, created_at, updated_at
+		FROM typologies
+		WHERE tenant_id = ? AND id = ? AND enabled = 1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	var t domain.Typology
+	var rules, weights, condition string
+	var enabled int
+
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, typologyID).Scan(
+		&t.ID, &t.TenantID, &t.Name, &t.Description,
+		&t.Version, &rules, &t.AlertThreshold,
+		&t.MinRulesFired, &t.MinCoverage, &weights, &condition, &enabled,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.Enabled = enabled == 1
+	if err := json.Unmarshal([]byte(rules), &t.Rules); err != nil {
+		return nil, fmt.Errorf("failed to parse typology rules: %w", err)
+	}
+	if err := decodeTypologyRefs(&t, weights, condition); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// ListTypologies retrieves all active typology configurations for a tenant.
+func (r *SQLRepository) ListTypologies(ctx context.Context, tenantID string) ([]*domain.Typology, error) {
+	if tenantID == "" {
 		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
 	}
 
 	query := `
-		SELECT id, tenant_id, name, description, version, rules, alert_threshold, enabled, created_at, updated_at
+		SELECT id, tenant_id, name, description, version, rules, alert_threshold,
+			min_rules_fired, min_coverage, typology_weights, trigger_condition,
+			enabled, created_at, updated_at
 		FROM typologies
 		WHERE tenant_id = ? AND enabled = 1
 		ORDER BY name
 	`
 
-	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
+	return r.queryTypologies(ctx, query, tenantID)
+}
+
+// ListAllTypologies retrieves the active typology configurations of every
+// tenant, global typologies included. It feeds the typology engine loader only.
+func (r *SQLRepository) ListAllTypologies(ctx context.Context) ([]*domain.Typology, error) {
+	query := `
+		SELECT id, tenant_id, name, description, version, rules, alert_threshold,
+			min_rules_fired, min_coverage, typology_weights, trigger_condition,
+			enabled, created_at, updated_at
+		FROM typologies
+		WHERE enabled = 1
+		ORDER BY tenant_id, name
+	`
+
+	return r.queryTypologies(ctx, query)
+}
+
+// queryTypologies runs a typology query and scans its rows.
+func (r *SQLRepository) queryTypologies(ctx context.Context, query string, args ...any) ([]*domain.Typology, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -461,12 +1008,13 @@ func (r *SQLRepository) ListTypologies(ctx context.Context, tenantID string) ([]
 	var typologies []*domain.Typology
 	for rows.Next() {
 		var t domain.Typology
-		var rules string
+		var rules, weights, condition string
 		var enabled int
 
 		if err := rows.Scan(
 			&t.ID, &t.TenantID, &t.Name, &t.Description,
-			&t.Version, &rules, &t.AlertThreshold, &enabled,
+			&t.Version, &rules, &t.AlertThreshold,
+			&t.MinRulesFired, &t.MinCoverage, &weights, &condition, &enabled,
 			&t.CreatedAt, &t.UpdatedAt,
 		); err != nil {
 			return nil, err
@@ -476,12 +1024,45 @@ func (r *SQLRepository) ListTypologies(ctx context.Context, tenantID string) ([]
 		if err := json.Unmarshal([]byte(rules), &t.Rules); err != nil {
 			return nil, fmt.Errorf("failed to parse typology rules for %s: %w", t.ID, err)
 		}
+		if err := decodeTypologyRefs(&t, weights, condition); err != nil {
+			return nil, err
+		}
 		typologies = append(typologies, &t)
 	}
 
 	return typologies, rows.Err()
 }
 
+// encodeTypologyRefs encodes the typologies a composite typology weights and
+// its condition, as empty strings when it has none.
+func encodeTypologyRefs(t *domain.Typology) (weights, condition string) {
+	if len(t.Typologies) > 0 {
+		data, _ := json.Marshal(t.Typologies)
+		weights = string(data)
+	}
+	if t.Condition != nil {
+		data, _ := json.Marshal(t.Condition)
+		condition = string(data)
+	}
+	return weights, condition
+}
+
+// decodeTypologyRefs reverses encodeTypologyRefs.
+func decodeTypologyRefs(t *domain.Typology, weights, condition string) error {
+	if weights != "" {
+		if err := json.Unmarshal([]byte(weights), &t.Typologies); err != nil {
+			return fmt.Errorf("failed to parse typology weights for %s: %w", t.ID, err)
+		}
+	}
+	if condition != "" {
+		t.Condition = &domain.TypologyCondition{}
+		if err := json.Unmarshal([]byte(condition), t.Condition); err != nil {
+			return fmt.Errorf("failed to parse typology condition for %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
 // DeleteTypology soft-deletes a typology by setting enabled = 0.
 func (r *SQLRepository) DeleteTypology(ctx context.Context, tenantID string, typologyID string) error {
 	if tenantID == "" {
@@ -510,14 +1091,1169 @@ func (r *SQLRepository) DeleteTypology(ctx context.Context, tenantID string, typ
 	return nil
 }
 
-// Ping checks database connectivity.
-func (r *SQLRepository) Ping(ctx context.Context) error {
-	return r.db.PingContext(ctx)
+// partyColumns is the column list read by scanPartyKYC.
+const partyColumns = `entity_id, tenant_id, risk_rating, pep, onboarded_at, residence_country, segment, updated_at`
+
+// SavePartyKYC upserts the KYC profile for a party with tenant isolation.
+func (r *SQLRepository) SavePartyKYC(ctx context.Context, tenantID string, kyc *domain.PartyKYC) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if kyc.EntityID == "" {
+		return fmt.Errorf("%w: entityID is required", ErrInvalidInput)
+	}
+
+	pep := 0
+	if kyc.PEP {
+		pep = 1
+	}
+
+	var onboardedAt sql.NullTime
+	if !kyc.OnboardedAt.IsZero() {
+		onboardedAt = sql.NullTime{Time: kyc.OnboardedAt.UTC(), Valid: true}
+	}
+
+	query := `
+		INSERT INTO party_kyc (` + partyColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, entity_id) DO UPDATE SET
+			risk_rating = excluded.risk_rating,
+			pep = excluded.pep,
+			onboarded_at = excluded.onboarded_at,
+			residence_country = excluded.residence_country,
+			segment = excluded.segment,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		kyc.EntityID, tenantID, kyc.RiskRating, pep,
+		onboardedAt, kyc.ResidenceCountry, kyc.Segment, time.Now().UTC(),
+	)
+	return err
 }
 
-// Close closes the database connection.
-func (r *SQLRepository) Close() error {
-	return r.db.Close()
+// GetPartyKYC retrieves the KYC profile for a party with tenant isolation.
+func (r *SQLRepository) GetPartyKYC(ctx context.Context, tenantID string, entityID string) (*domain.PartyKYC, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + partyColumns + ` FROM party_kyc WHERE tenant_id = ? AND entity_id = ?`
+
+	kyc, err := scanPartyKYC(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, entityID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return kyc, nil
+}
+
+// ListPartyKYC retrieves the tenant's KYC profiles matching the filter,
+// sorted by entity ID.
+func (r *SQLRepository) ListPartyKYC(ctx context.Context, tenantID string, filter domain.PartyFilter) ([]*domain.PartyKYC, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + partyColumns + ` FROM party_kyc WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.RiskRating != "" {
+		query += ` AND risk_rating = ?`
+		args = append(args, filter.RiskRating)
+	}
+	if filter.Segment != "" {
+		query += ` AND segment = ?`
+		args = append(args, filter.Segment)
+	}
+	query += ` ORDER BY entity_id`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*domain.PartyKYC
+	for rows.Next() {
+		kyc, err := scanPartyKYC(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, kyc)
+	}
+	return profiles, rows.Err()
+}
+
+// DeletePartyKYC deletes the KYC profile for a party with tenant isolation.
+func (r *SQLRepository) DeletePartyKYC(ctx context.Context, tenantID string, entityID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `DELETE FROM party_kyc WHERE tenant_id = ? AND entity_id = ?`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, entityID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanPartyKYC reads a profile selected with partyColumns.
+func scanPartyKYC(row interface{ Scan(...any) error }) (*domain.PartyKYC, error) {
+	var kyc domain.PartyKYC
+	var pep int
+	var onboardedAt sql.NullTime
+	var country, segment sql.NullString
+
+	if err := row.Scan(
+		&kyc.EntityID, &kyc.TenantID, &kyc.RiskRating, &pep,
+		&onboardedAt, &country, &segment, &kyc.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	kyc.PEP = pep == 1
+	if onboardedAt.Valid {
+		kyc.OnboardedAt = onboardedAt.Time
+	}
+	kyc.ResidenceCountry = country.String
+	kyc.Segment = segment.String
+
+	return &kyc, nil
+}
+
+// SaveCorridorRisk upserts a corridor risk override with tenant isolation.
+func (r *SQLRepository) SaveCorridorRisk(ctx context.Context, tenantID string, corridor *domain.CorridorRisk) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if corridor.Origin == "" || corridor.Destination == "" {
+		return fmt.Errorf("%w: origin and destination are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO corridor_risk (tenant_id, origin, destination, risk, note, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, origin, destination) DO UPDATE SET
+			risk = excluded.risk,
+			note = excluded.note,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, corridor.Origin, corridor.Destination, corridor.Risk,
+		corridor.Note, time.Now().UTC(),
+	)
+	return err
+}
+
+// ListCorridorRisks retrieves all corridor risk overrides for a tenant.
+func (r *SQLRepository) ListCorridorRisks(ctx context.Context, tenantID string) ([]*domain.CorridorRisk, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, origin, destination, risk, note, updated_at
+		FROM corridor_risk
+		WHERE tenant_id = ?
+		ORDER BY origin, destination
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var corridors []*domain.CorridorRisk
+	for rows.Next() {
+		var c domain.CorridorRisk
+		var note sql.NullString
+		if err := rows.Scan(&c.TenantID, &c.Origin, &c.Destination, &c.Risk, &note, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.Note = note.String
+		corridors = append(corridors, &c)
+	}
+
+	return corridors, rows.Err()
+}
+
+// DeleteCorridorRisk removes a corridor risk override with tenant isolation.
+func (r *SQLRepository) DeleteCorridorRisk(ctx context.Context, tenantID string, origin string, destination string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `DELETE FROM corridor_risk WHERE tenant_id = ? AND origin = ? AND destination = ?`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, origin, destination)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SaveJob upserts a background job with tenant isolation. A job ID already
+// used by another tenant is refused.
+func (r *SQLRepository) SaveJob(ctx context.Context, tenantID string, job *domain.Job) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if job.ID == "" {
+		return fmt.Errorf("%w: job id is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO jobs (
+			id, tenant_id, type, status, params, total, processed, failed, error,
+			created_at, started_at, completed_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status,
+			params = excluded.params,
+			total = excluded.total,
+			processed = excluded.processed,
+			failed = excluded.failed,
+			error = excluded.error,
+			started_at = excluded.started_at,
+			completed_at = excluded.completed_at,
+			updated_at = excluded.updated_at
+		WHERE jobs.tenant_id = excluded.tenant_id
+	`
+
+	var params sql.NullString
+	if job.Params != nil {
+		data, _ := json.Marshal(job.Params)
+		params = sql.NullString{String: string(data), Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query),
+		job.ID, tenantID, job.Type, job.Status, params, job.Total, job.Processed, job.Failed,
+		job.Error, job.CreatedAt, nullTime(job.StartedAt), nullTime(job.CompletedAt),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Job IDs are unique across tenants; another tenant's job is left alone
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: job id %s belongs to another tenant", ErrInvalidInput, job.ID)
+	}
+	return nil
+}
+
+// GetJob retrieves a background job by ID with tenant isolation.
+func (r *SQLRepository) GetJob(ctx context.Context, tenantID string, jobID string) (*domain.Job, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE tenant_id = ? AND id = ?
+	`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return job, err
+}
+
+// ListJobs retrieves a tenant's most recent background jobs, newest first.
+func (r *SQLRepository) ListJobs(ctx context.Context, tenantID string, limit int) ([]*domain.Job, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE tenant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// jobColumns is the column list read by scanJob.
+const jobColumns = `id, tenant_id, type, status, params, total, processed, failed, error,
+			created_at, started_at, completed_at, updated_at`
+
+// scanJob reads a job row selected with jobColumns.
+func scanJob(row interface{ Scan(...any) error }) (*domain.Job, error) {
+	var job domain.Job
+	var params, jobErr sql.NullString
+	var startedAt, completedAt sql.NullTime
+
+	if err := row.Scan(
+		&job.ID, &job.TenantID, &job.Type, &job.Status, &params,
+		&job.Total, &job.Processed, &job.Failed, &jobErr,
+		&job.CreatedAt, &startedAt, &completedAt, &job.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if params.Valid && params.String != "" {
+		job.Params = &domain.JobParams{}
+		json.Unmarshal([]byte(params.String), job.Params)
+	}
+	job.Error = jobErr.String
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return &job, nil
+}
+
+// SaveJobFile stores an input or result file for a job, replacing any previous content.
+func (r *SQLRepository) SaveJobFile(ctx context.Context, tenantID string, jobID string, kind string, data []byte) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO job_files (tenant_id, job_id, kind, data, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, job_id, kind) DO UPDATE SET
+			data = excluded.data,
+			created_at = excluded.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, jobID, kind, string(data), time.Now().UTC())
+	return err
+}
+
+// GetJobFile retrieves an input or result file for a job with tenant isolation.
+func (r *SQLRepository) GetJobFile(ctx context.Context, tenantID string, jobID string, kind string) ([]byte, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT data FROM job_files WHERE tenant_id = ? AND job_id = ? AND kind = ?`
+
+	var data string
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID, jobID, kind).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(data), nil
+}
+
+// SaveFeatureFlag upserts a feature flag override with tenant isolation.
+func (r *SQLRepository) SaveFeatureFlag(ctx context.Context, tenantID string, flag *domain.FeatureFlag) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if flag.Name == "" {
+		return fmt.Errorf("%w: flag name is required", ErrInvalidInput)
+	}
+
+	enabled := 0
+	if flag.Enabled {
+		enabled = 1
+	}
+
+	query := `
+		INSERT INTO feature_flags (tenant_id, name, enabled, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(tenant_id, name) DO UPDATE SET
+			enabled = excluded.enabled,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, flag.Name, enabled, time.Now().UTC())
+	return err
+}
+
+// ListFeatureFlags retrieves a tenant's feature flag overrides.
+func (r *SQLRepository) ListFeatureFlags(ctx context.Context, tenantID string) ([]*domain.FeatureFlag, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, name, enabled, updated_at
+		FROM feature_flags
+		WHERE tenant_id = ?
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []*domain.FeatureFlag
+	for rows.Next() {
+		var flag domain.FeatureFlag
+		var enabled int
+		if err := rows.Scan(&flag.TenantID, &flag.Name, &enabled, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		flag.Enabled = enabled == 1
+		flags = append(flags, &flag)
+	}
+
+	return flags, rows.Err()
+}
+
+// DeleteFeatureFlag removes a feature flag override with tenant isolation.
+func (r *SQLRepository) DeleteFeatureFlag(ctx context.Context, tenantID string, name string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(`DELETE FROM feature_flags WHERE tenant_id = ? AND name = ?`), tenantID, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// defaultAlertLimit caps alert listings that don't set a limit.
+const defaultAlertLimit = 100
+
+// SaveAlert inserts an alert with tenant isolation. An existing alert is left
+// unchanged, so saving an evaluation again never resets its acknowledgment.
+func (r *SQLRepository) SaveAlert(ctx context.Context, tenantID string, alert *domain.Alert) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if alert.ID == "" {
+		return fmt.Errorf("%w: alert ID is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO alerts (
+			id, tenant_id, tx_id, score, status, assignee, created_at, updated_at,
+			acked_at, acked_by, ack_note, escalation_level, last_notified_at, suppressed_by_maintenance
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, id) DO NOTHING
+	`
+
+	status := alert.Status
+	if status == "" {
+		status = domain.AlertOpen
+	}
+	updatedAt := alert.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = alert.CreatedAt
+	}
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		alert.ID, tenantID, alert.TxID, alert.Score, status, alert.Assignee,
+		alert.CreatedAt.UTC(), updatedAt.UTC(),
+		nullTime(alert.AckedAt), alert.AckedBy, alert.AckNote,
+		alert.EscalationLevel, alert.LastNotifiedAt.UTC(), alert.SuppressedByMaintenance,
+	)
+	return err
+}
+
+// GetAlert retrieves an alert with tenant isolation.
+func (r *SQLRepository) GetAlert(ctx context.Context, tenantID string, alertID string) (*domain.Alert, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE tenant_id = ? AND id = ?`
+
+	alert, err := scanAlert(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, alertID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return alert, err
+}
+
+// ListAlerts retrieves a tenant's alerts, newest first.
+func (r *SQLRepository) ListAlerts(ctx context.Context, tenantID string, filter domain.AlertFilter) ([]*domain.Alert, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAlertLimit
+	}
+
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if filter.Unacked {
+		query += ` AND acked_at IS NULL`
+	}
+	if len(filter.Statuses) > 0 {
+		query += ` AND status IN (?` + strings.Repeat(`, ?`, len(filter.Statuses)-1) + `)`
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+		}
+	}
+	if filter.Assignee != "" {
+		query += ` AND assignee = ?`
+		args = append(args, filter.Assignee)
+	}
+	if filter.TxID != "" {
+		query += ` AND tx_id = ?`
+		args = append(args, filter.TxID)
+	}
+	if filter.MinScore > 0 {
+		query += ` AND score >= ?`
+		args = append(args, filter.MinScore)
+	}
+	contribution, contributionArgs := contributionFilter("alerts.tenant_id", "alerts.id", filter.RuleID, filter.TypologyID, filter.MinContribution)
+	query += contribution
+	args = append(args, contributionArgs...)
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlerts(rows)
+}
+
+// AckAlert records an acknowledgment. Returns ErrNotFound if the alert doesn't
+// exist or is already acknowledged; the first acknowledgment is kept.
+func (r *SQLRepository) AckAlert(ctx context.Context, tenantID string, alertID string, by string, note string, at time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE alerts SET acked_at = ?, acked_by = ?, ack_note = ?
+		WHERE tenant_id = ? AND id = ? AND acked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), at.UTC(), by, note, tenantID, alertID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EscalateAlert moves an unacknowledged alert from level-1 to level. Returns
+// ErrNotFound if it was acknowledged or escalated by someone else meanwhile.
+func (r *SQLRepository) EscalateAlert(ctx context.Context, tenantID string, alertID string, level int, at time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE alerts SET escalation_level = ?, last_notified_at = ?
+		WHERE tenant_id = ? AND id = ? AND acked_at IS NULL AND escalation_level = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), level, at.UTC(), tenantID, alertID, level-1)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UpdateAlertCase sets an alert's status and assignee. Returns ErrNotFound if
+// the alert doesn't exist or is no longer in fromStatus.
+func (r *SQLRepository) UpdateAlertCase(ctx context.Context, tenantID string, alertID string, fromStatus string, status string, assignee string, at time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE alerts SET status = ?, assignee = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ? AND status = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), status, assignee, at.UTC(), tenantID, alertID, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SaveAlertEvent appends an entry to an alert's history with tenant isolation.
+func (r *SQLRepository) SaveAlertEvent(ctx context.Context, tenantID string, event *domain.AlertEvent) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if event.ID == "" || event.AlertID == "" {
+		return fmt.Errorf("%w: event and alert ID are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO alert_events (id, tenant_id, alert_id, action, actor, from_value, to_value, note, at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		event.ID, tenantID, event.AlertID, event.Action, event.Actor,
+		event.From, event.To, event.Note, event.At.UTC(),
+	)
+	return err
+}
+
+// ListAlertEvents retrieves an alert's history, oldest first.
+func (r *SQLRepository) ListAlertEvents(ctx context.Context, tenantID string, alertID string) ([]*domain.AlertEvent, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT id, alert_id, action, actor, from_value, to_value, note, at
+		FROM alert_events
+		WHERE tenant_id = ? AND alert_id = ?
+		ORDER BY at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.AlertEvent
+	for rows.Next() {
+		var event domain.AlertEvent
+		var actor, from, to, note sql.NullString
+		if err := rows.Scan(&event.ID, &event.AlertID, &event.Action, &actor, &from, &to, &note, &event.At); err != nil {
+			return nil, err
+		}
+		event.Actor = actor.String
+		event.From = from.String
+		event.To = to.String
+		event.Note = note.String
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// ListDueAlerts retrieves unacknowledged alerts across all tenants that were
+// last notified before notifiedBefore and are below maxLevel, oldest first.
+// Alerts suppressed by a maintenance window are never due.
+func (r *SQLRepository) ListDueAlerts(ctx context.Context, notifiedBefore time.Time, maxLevel int, limit int) ([]*domain.Alert, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM alerts
+		WHERE acked_at IS NULL AND last_notified_at < ? AND escalation_level < ?
+		  AND (suppressed_by_maintenance IS NULL OR suppressed_by_maintenance = '')
+		ORDER BY last_notified_at
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), notifiedBefore.UTC(), maxLevel, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAlerts(rows)
+}
+
+// alertColumns is the column list read by scanAlert.
+const alertColumns = `id, tenant_id, tx_id, score, status, assignee, created_at, updated_at,
+			acked_at, acked_by, ack_note, escalation_level, last_notified_at, suppressed_by_maintenance`
+
+// scanAlert reads an alert row selected with alertColumns.
+func scanAlert(row interface{ Scan(...any) error }) (*domain.Alert, error) {
+	var alert domain.Alert
+	var updatedAt, ackedAt sql.NullTime
+	var assignee, ackedBy, ackNote, suppressedBy sql.NullString
+
+	if err := row.Scan(
+		&alert.ID, &alert.TenantID, &alert.TxID, &alert.Score, &alert.Status, &assignee,
+		&alert.CreatedAt, &updatedAt, &ackedAt, &ackedBy, &ackNote,
+		&alert.EscalationLevel, &alert.LastNotifiedAt, &suppressedBy,
+	); err != nil {
+		return nil, err
+	}
+
+	alert.Assignee = assignee.String
+	// Alerts from before dispositions have no update time
+	alert.UpdatedAt = alert.CreatedAt
+	if updatedAt.Valid {
+		alert.UpdatedAt = updatedAt.Time
+	}
+	if ackedAt.Valid {
+		alert.AckedAt = &ackedAt.Time
+	}
+	alert.AckedBy = ackedBy.String
+	alert.AckNote = ackNote.String
+	alert.SuppressedByMaintenance = suppressedBy.String
+
+	return &alert, nil
+}
+
+// scanAlerts reads every row selected with alertColumns.
+func scanAlerts(rows *sql.Rows) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// defaultSampleLimit caps activation sample listings that don't set a limit.
+const defaultSampleLimit = 50
+
+// SaveActivationSample stores a sampled rule activation with tenant isolation.
+func (r *SQLRepository) SaveActivationSample(ctx context.Context, tenantID string, sample *domain.ActivationSample) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	activation, err := json.Marshal(sample.Activation)
+	if err != nil {
+		return fmt.Errorf("failed to encode activation: %w", err)
+	}
+
+	query := `
+		INSERT INTO activation_samples (
+			id, tenant_id, rule_id, rule_version, tx_id, outcome, score, activation, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, r.rebind(query),
+		sample.ID, tenantID, sample.RuleID, sample.RuleVersion, sample.TxID,
+		sample.Outcome, sample.Score, string(activation), sample.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// ListActivationSamples retrieves a rule's samples for a tenant, newest first.
+func (r *SQLRepository) ListActivationSamples(ctx context.Context, tenantID string, ruleID string, limit int) ([]*domain.ActivationSample, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if limit <= 0 {
+		limit = defaultSampleLimit
+	}
+
+	query := `
+		SELECT id, tenant_id, rule_id, rule_version, tx_id, outcome, score, activation, created_at
+		FROM activation_samples
+		WHERE tenant_id = ? AND rule_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, ruleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*domain.ActivationSample
+	for rows.Next() {
+		var sample domain.ActivationSample
+		var activation string
+		if err := rows.Scan(
+			&sample.ID, &sample.TenantID, &sample.RuleID, &sample.RuleVersion, &sample.TxID,
+			&sample.Outcome, &sample.Score, &activation, &sample.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(activation), &sample.Activation)
+		samples = append(samples, &sample)
+	}
+
+	return samples, rows.Err()
+}
+
+// SaveWebhook creates a webhook with tenant isolation.
+func (r *SQLRepository) SaveWebhook(ctx context.Context, tenantID string, webhook *domain.Webhook) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if webhook.ID == "" || webhook.URL == "" {
+		return fmt.Errorf("%w: webhook ID and URL are required", ErrInvalidInput)
+	}
+
+	var filter sql.NullString
+	if webhook.Filter != nil {
+		data, err := json.Marshal(webhook.Filter)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook filter: %w", err)
+		}
+		filter = sql.NullString{String: string(data), Valid: true}
+	}
+	feed := webhook.Feed
+	if feed == "" {
+		feed = domain.WebhookFeedAlerts
+	}
+
+	query := `
+		INSERT INTO webhooks (id, tenant_id, url, secret, feed, filter, batch_size, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		webhook.ID, tenantID, webhook.URL, webhook.Secret, feed, filter, webhook.BatchSize, webhook.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// GetWebhook retrieves a webhook, including its secret, with tenant isolation.
+func (r *SQLRepository) GetWebhook(ctx context.Context, tenantID string, webhookID string) (*domain.Webhook, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE tenant_id = ? AND id = ?`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves a tenant's webhooks, including their secrets.
+func (r *SQLRepository) ListWebhooks(ctx context.Context, tenantID string) ([]*domain.Webhook, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE tenant_id = ?
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// webhookColumns is the column list read by scanWebhook.
+const webhookColumns = `id, tenant_id, url, secret, feed, filter, batch_size, created_at`
+
+// scanWebhook reads a webhook selected with webhookColumns.
+func scanWebhook(row interface{ Scan(...any) error }) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var filter sql.NullString
+	if err := row.Scan(
+		&webhook.ID, &webhook.TenantID, &webhook.URL, &webhook.Secret,
+		&webhook.Feed, &filter, &webhook.BatchSize, &webhook.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if filter.Valid && filter.String != "" {
+		webhook.Filter = &domain.WebhookFilter{}
+		if err := json.Unmarshal([]byte(filter.String), webhook.Filter); err != nil {
+			return nil, fmt.Errorf("failed to decode webhook filter: %w", err)
+		}
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes a webhook with tenant isolation. Its delivery log is kept.
+func (r *SQLRepository) DeleteWebhook(ctx context.Context, tenantID string, webhookID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(`DELETE FROM webhooks WHERE tenant_id = ? AND id = ?`), tenantID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SaveWebhookDelivery upserts a webhook delivery with tenant isolation.
+func (r *SQLRepository) SaveWebhookDelivery(ctx context.Context, tenantID string, delivery *domain.WebhookDelivery) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if delivery.ID == "" {
+		return fmt.Errorf("%w: delivery ID is required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, tenant_id, webhook_id, evaluation_id, event, payload, status, attempts,
+			last_status_code, last_error, next_attempt_at, created_at, delivered_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, id) DO UPDATE SET
+			status = excluded.status,
+			attempts = excluded.attempts,
+			last_status_code = excluded.last_status_code,
+			last_error = excluded.last_error,
+			next_attempt_at = excluded.next_attempt_at,
+			delivered_at = excluded.delivered_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		delivery.ID, tenantID, delivery.WebhookID, delivery.EvaluationID, delivery.Event,
+		string(delivery.Payload), delivery.Status, delivery.Attempts,
+		delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt.UTC(),
+		delivery.CreatedAt.UTC(), nullTime(delivery.DeliveredAt),
+	)
+	return err
+}
+
+// defaultDeliveryLimit caps webhook delivery listings that don't set a limit.
+const defaultDeliveryLimit = 100
+
+// ListWebhookDeliveries retrieves a webhook's delivery log, newest first.
+func (r *SQLRepository) ListWebhookDeliveries(ctx context.Context, tenantID string, webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE tenant_id = ? AND webhook_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// ClaimWebhookDelivery counts an attempt on a pending delivery and pushes its
+// next attempt to leaseUntil, so other instances skip it while it is sent.
+// Returns ErrNotFound if the delivery isn't pending at the given attempt count.
+func (r *SQLRepository) ClaimWebhookDelivery(ctx context.Context, tenantID string, deliveryID string, attempts int, leaseUntil time.Time) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		UPDATE webhook_deliveries SET attempts = ?, next_attempt_at = ?
+		WHERE tenant_id = ? AND id = ? AND status = ? AND attempts = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query),
+		attempts+1, leaseUntil.UTC(), tenantID, deliveryID, domain.DeliveryPending, attempts,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDueWebhookDeliveries retrieves pending deliveries across all tenants
+// whose next attempt is before before, oldest first.
+func (r *SQLRepository) ListDueWebhookDeliveries(ctx context.Context, before time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), domain.DeliveryPending, before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// deliveryColumns is the column list read by scanDeliveries.
+const deliveryColumns = `id, tenant_id, webhook_id, evaluation_id, event, payload, status, attempts,
+			last_status_code, last_error, next_attempt_at, created_at, delivered_at`
+
+// scanDeliveries reads every row selected with deliveryColumns.
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		var payload string
+		var lastError sql.NullString
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(
+			&delivery.ID, &delivery.TenantID, &delivery.WebhookID, &delivery.EvaluationID, &delivery.Event,
+			&payload, &delivery.Status, &delivery.Attempts, &delivery.LastStatusCode, &lastError,
+			&delivery.NextAttemptAt, &delivery.CreatedAt, &deliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		delivery.Payload = []byte(payload)
+		delivery.LastError = lastError.String
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// Ping checks database connectivity.
+func (r *SQLRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close closes the database connection.
+func (r *SQLRepository) Close() error {
+	return r.db.Close()
+}
+
+// decodeAmount returns an exact amount stored beside its float, as for
+// transactions, outcomes and balances. Rows written before the decimal
+// column have it NULL and are read from the float.
+func decodeAmount(amount float64, exact sql.NullString) domain.Decimal {
+	if d, err := domain.ParseDecimal(exact.String); exact.Valid && err == nil {
+		return d
+	}
+	return domain.DecimalFromFloat(amount)
+}
+
+// decodeComponents parses the stored amount components JSON.
+// Rows written before components were introduced have a NULL column.
+func decodeComponents(raw sql.NullString) *domain.AmountComponents {
+	if !raw.Valid || raw.String == "" || raw.String == "null" {
+		return nil
+	}
+	var c domain.AmountComponents
+	if err := json.Unmarshal([]byte(raw.String), &c); err != nil {
+		return nil
+	}
+	return &c
+}
+
+// nullTime converts an optional timestamp to a nullable column value.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
 }
 
 // rebind converts ? placeholders to $1, $2, etc. for PostgreSQL.
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,6 +33,21 @@ func TestSQLiteRepository(t *testing.T) {
 	}
 	defer repo.Close()
 
+	testRepository(t, repo)
+}
+
+func TestMemoryRepository(t *testing.T) {
+	repo, err := New(domain.RepositoryConfig{Driver: "memory", MaxOpenConns: 10, ConnMaxLifetime: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	testRepository(t, repo)
+}
+
+// testRepository runs the repository contract against an open repository.
+func testRepository(t *testing.T, repo domain.Repository) {
 	ctx := context.Background()
 	tenantID := "tenant-001"
 
@@ -47,7 +65,7 @@ func TestSQLiteRepository(t *testing.T) {
 			DebtorAccountID: "acc-001",
 			CreditorID:      "creditor-001",
 			CreditorAcctID:  "acc-002",
-			Amount:          1000.00,
+			Amount:          domain.MustDecimal("1000.00"),
 			Currency:        "USD",
 			Timestamp:       time.Now().UTC(),
 			CreatedAt:       time.Now().UTC(),
@@ -67,13 +85,105 @@ func TestSQLiteRepository(t *testing.T) {
 			t.Errorf("expected ID %s, got %s", tx.ID, retrieved.ID)
 		}
 		if retrieved.Amount != tx.Amount {
-			t.Errorf("expected Amount %.2f, got %.2f", tx.Amount, retrieved.Amount)
+			t.Errorf("expected Amount %s, got %s", tx.Amount, retrieved.Amount)
 		}
 		if retrieved.TenantID != tenantID {
 			t.Errorf("expected TenantID %s, got %s", tenantID, retrieved.TenantID)
 		}
 	})
 
+	t.Run("TransactionIDsPerTenant", func(t *testing.T) {
+		tx := &domain.Transaction{ID: "tx-001", Type: "transfer", DebtorID: "debtor-b", CreditorID: "creditor-b",
+			Amount: domain.MustDecimal("5"), Currency: "EUR", Timestamp: time.Now().UTC(), CreatedAt: time.Now().UTC()}
+
+		// Another tenant may reuse tx-001; the tenant that has it may not
+		if err := repo.SaveTransaction(ctx, "tenant-reuse", tx); err != nil {
+			t.Fatalf("expected another tenant to reuse the ID, got %v", err)
+		}
+		if err := repo.SaveTransaction(ctx, tenantID, tx); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict for a duplicate ID, got %v", err)
+		}
+
+		for tenant, want := range map[string]string{tenantID: "1000", "tenant-reuse": "5"} {
+			retrieved, err := repo.GetTransaction(ctx, tenant, "tx-001")
+			if err != nil || retrieved.Amount.String() != want {
+				t.Errorf("expected %s's tx-001 to be %s, got %+v, %v", tenant, want, retrieved, err)
+			}
+		}
+	})
+
+	t.Run("AmountComponentsRoundTrip", func(t *testing.T) {
+		tx := &domain.Transaction{
+			ID:              "tx-fx-001",
+			Type:            "transfer",
+			DebtorID:        "debtor-fx",
+			DebtorAccountID: "acc-fx-1",
+			CreditorID:      "creditor-fx",
+			CreditorAcctID:  "acc-fx-2",
+			Amount:          domain.MustDecimal("102.50"),
+			Currency:        "USD",
+			Components: &domain.AmountComponents{
+				Principal:  domain.MustDecimal("100.00"),
+				Fee:        domain.MustDecimal("2.50"),
+				FXAmount:   domain.MustDecimal("92.10"),
+				FXCurrency: "EUR",
+				FXRate:     0.921,
+			},
+			Timestamp: time.Now().UTC(),
+			CreatedAt: time.Now().UTC(),
+		}
+
+		if err := repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+
+		retrieved, err := repo.GetTransaction(ctx, tenantID, tx.ID)
+		if err != nil {
+			t.Fatalf("GetTransaction failed: %v", err)
+		}
+		if retrieved.Components == nil {
+			t.Fatal("expected components to be persisted")
+		}
+		if *retrieved.Components != *tx.Components {
+			t.Errorf("expected components %+v, got %+v", *tx.Components, *retrieved.Components)
+		}
+	})
+
+	t.Run("LinksRoundTrip", func(t *testing.T) {
+		tx := &domain.Transaction{
+			ID:              "tx-refund-001",
+			Type:            "refund",
+			DebtorID:        "creditor-fx",
+			DebtorAccountID: "acc-fx-2",
+			CreditorID:      "debtor-fx",
+			CreditorAcctID:  "acc-fx-1",
+			Amount:          domain.MustDecimal("50"),
+			Currency:        "USD",
+			ReversalOf:      "tx-fx-001",
+			PartOfBatch:     "batch-7",
+			RelatedTo:       []string{"tx-001", "tx-fx-001"},
+			Timestamp:       time.Now().UTC(),
+			CreatedAt:       time.Now().UTC(),
+		}
+		if err := repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+
+		txs, err := repo.GetTransactionsByEntity(ctx, tenantID, "creditor-fx", time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("GetTransactionsByEntity failed: %v", err)
+		}
+		var retrieved *domain.Transaction
+		for _, candidate := range txs {
+			if candidate.ID == tx.ID {
+				retrieved = candidate
+			}
+		}
+		if retrieved == nil || retrieved.ReversalOf != "tx-fx-001" || retrieved.PartOfBatch != "batch-7" || strings.Join(retrieved.RelatedTo, ",") != "tx-001,tx-fx-001" {
+			t.Errorf("expected the links to be persisted, got %+v", retrieved)
+		}
+	})
+
 	t.Run("TenantIsolation", func(t *testing.T) {
 		otherTenant := "tenant-002"
 
@@ -107,7 +217,7 @@ func TestSQLiteRepository(t *testing.T) {
 			DebtorAccountID: "acc-001",
 			CreditorID:      "creditor-002",
 			CreditorAcctID:  "acc-003",
-			Amount:          500.00,
+			Amount:          domain.MustDecimal("500.00"),
 			Currency:        "USD",
 			Timestamp:       time.Now().UTC(),
 			CreatedAt:       time.Now().UTC(),
@@ -159,48 +269,1734 @@ func TestSQLiteRepository(t *testing.T) {
 		if retrieved.Status != eval.Status {
 			t.Errorf("expected Status %s, got %s", eval.Status, retrieved.Status)
 		}
+
+		rerun := &domain.Evaluation{ID: "eval-001b", TxID: "tx-001", Status: domain.StatusAlert, Timestamp: eval.Timestamp.Add(time.Minute)}
+		if err := repo.SaveEvaluation(ctx, tenantID, rerun); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+		latest, err := repo.GetEvaluationByTx(ctx, tenantID, "tx-001")
+		if err != nil || latest.ID != rerun.ID {
+			t.Errorf("expected the transaction's latest evaluation, got %+v, %v", latest, err)
+		}
+		if _, err := repo.GetEvaluationByTx(ctx, "other-tenant", "tx-001"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for another tenant, got %v", err)
+		}
 	})
 
-	t.Run("NotFound", func(t *testing.T) {
-		_, err := repo.GetTransaction(ctx, tenantID, "nonexistent")
-		if err != ErrNotFound {
-			t.Errorf("expected ErrNotFound, got: %v", err)
+	t.Run("ListEvaluations", func(t *testing.T) {
+		tenant := "tenant-search"
+		base := time.Now().UTC().Truncate(time.Second)
+		for _, tx := range []*domain.Transaction{
+			{ID: "tx-s1", TenantID: tenant, Type: "transfer", DebtorID: "cust-1", CreditorID: "shop-1", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: base},
+			{ID: "tx-s2", TenantID: tenant, Type: "transfer", DebtorID: "cust-2", CreditorID: "shop-1", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: base},
+		} {
+			if err := repo.SaveTransaction(ctx, tenant, tx); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+		}
+		for _, eval := range []*domain.Evaluation{
+			{ID: "eval-s1", TxID: "tx-s1", Status: domain.StatusAlert, Score: 0.9, Timestamp: base.Add(-3 * time.Hour),
+				RuleResults:     []domain.RuleResult{{RuleID: "high-value", SubRuleRef: domain.RuleOutcomeFail, Score: 1}},
+				TypologyResults: []domain.TypologyResult{{TypologyID: "mule_100%", Score: 0.9, Triggered: true}}},
+			{ID: "eval-s2", TxID: "tx-s1", Status: domain.StatusAlert, Score: 0.7, Timestamp: base.Add(-time.Hour),
+				TypologyResults: []domain.TypologyResult{{TypologyID: "mule_100%", Score: 0.6, Triggered: false}}},
+			{ID: "eval-s3", TxID: "tx-s2", Status: domain.StatusNoAlert, Score: 0.1, Timestamp: base.Add(-time.Hour),
+				RuleResults: []domain.RuleResult{{RuleID: "high-value", SubRuleRef: domain.RuleOutcomePass}}},
+			{ID: "eval-s4", TxID: "tx-unstored", Status: domain.StatusAlert, Score: 0.8, Timestamp: base,
+				RuleResults: []domain.RuleResult{{RuleID: "high-value", SubRuleRef: domain.RuleOutcomeFail, Shadow: true}, {RuleID: "velocity", SubRuleRef: domain.RuleOutcomeReview}}},
+		} {
+			if err := repo.SaveEvaluation(ctx, tenant, eval); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
 		}
 
-		_, err = repo.GetEvaluation(ctx, tenantID, "nonexistent")
-		if err != ErrNotFound {
-			t.Errorf("expected ErrNotFound, got: %v", err)
+		list := func(filter domain.EvaluationFilter) string {
+			t.Helper()
+			evals, err := repo.ListEvaluations(ctx, tenant, filter)
+			if err != nil {
+				t.Fatalf("ListEvaluations failed: %v", err)
+			}
+			ids := []string{}
+			for _, e := range evals {
+				ids = append(ids, e.ID)
+			}
+			return strings.Join(ids, ",")
+		}
+		min, max := 0.75, 0.85
+		contribution := 0.5
+		tests := []struct {
+			name   string
+			filter domain.EvaluationFilter
+			want   string
+		}{
+			{"all, latest first with ties by ID", domain.EvaluationFilter{}, "eval-s4,eval-s3,eval-s2,eval-s1"},
+			{"status", domain.EvaluationFilter{Status: domain.StatusNoAlert}, "eval-s3"},
+			{"score range", domain.EvaluationFilter{MinScore: &min, MaxScore: &max}, "eval-s4"},
+			{"time range", domain.EvaluationFilter{Since: base.Add(-2 * time.Hour), Until: base}, "eval-s3,eval-s2"},
+			{"debtor", domain.EvaluationFilter{DebtorID: "cust-1", Status: domain.StatusAlert}, "eval-s2,eval-s1"},
+			{"creditor", domain.EvaluationFilter{CreditorID: "shop-1"}, "eval-s3,eval-s2,eval-s1"},
+			{"transaction", domain.EvaluationFilter{TxID: "tx-unstored"}, "eval-s4"},
+			{"triggered typology", domain.EvaluationFilter{TypologyID: "mule_100%", Limit: 1}, "eval-s1"},
+			{"wildcards are literal", domain.EvaluationFilter{TypologyID: "mule_1%"}, ""},
+			{"fired rule", domain.EvaluationFilter{RuleID: "high-value"}, "eval-s1"},
+			{"rule asking for review", domain.EvaluationFilter{RuleID: "velocity"}, "eval-s4"},
+			{"typology contribution", domain.EvaluationFilter{TypologyID: "mule_100%", MinContribution: &contribution}, "eval-s2,eval-s1"},
+			{"after a cursor", domain.EvaluationFilter{After: &domain.EvaluationCursor{Timestamp: base.Add(-time.Hour), ID: "eval-s3"}, Limit: 1}, "eval-s2"},
+		}
+		for _, tt := range tests {
+			if got := list(tt.filter); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+			}
 		}
 	})
-}
 
-func TestUnsupportedDriver(t *testing.T) {
-	cfg := domain.RepositoryConfig{
-		Driver: "mysql",
-	}
+	t.Run("TenantActivity", func(t *testing.T) {
+		now := time.Now().UTC()
+		for i, eval := range []*domain.Evaluation{
+			{ID: "eval-act-1", TxID: "tx-act-1", Status: domain.StatusAlert, Timestamp: now.Add(-time.Minute)},
+			{ID: "eval-act-2", TxID: "tx-act-2", Status: domain.StatusNoAlert, Timestamp: now.Add(-2 * time.Hour)},
+		} {
+			tenant := "tenant-activity"
+			if i == 1 {
+				tenant = "tenant-idle"
+			}
+			if err := repo.SaveEvaluation(ctx, tenant, eval); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
+		}
 
-	_, err := New(cfg)
-	if err == nil {
-		t.Error("expected error for unsupported driver")
-	}
-}
+		activity, err := repo.ListTenantActivity(ctx, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("ListTenantActivity failed: %v", err)
+		}
+		byTenant := make(map[string]*domain.TenantActivity)
+		for _, a := range activity {
+			byTenant[a.TenantID] = a
+		}
 
-func TestRebind(t *testing.T) {
-	repo := &SQLRepository{driver: "postgres"}
+		active := byTenant["tenant-activity"]
+		if active == nil || active.Evaluations != 1 || active.Alerts != 1 {
+			t.Fatalf("unexpected activity: %+v", active)
+		}
+		if !active.LastEvaluationAt.Equal(now.Add(-time.Minute)) {
+			t.Errorf("expected last evaluation at %v, got %v", now.Add(-time.Minute), active.LastEvaluationAt)
+		}
+		idle := byTenant["tenant-idle"]
+		if idle == nil || idle.Evaluations != 0 || idle.LastEvaluationAt.IsZero() {
+			t.Errorf("expected an idle tenant with a last evaluation, got %+v", idle)
+		}
+	})
 
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"SELECT * FROM t WHERE id = ?", "SELECT * FROM t WHERE id = $1"},
-		{"INSERT INTO t (a, b) VALUES (?, ?)", "INSERT INTO t (a, b) VALUES ($1, $2)"},
-		{"SELECT * FROM t", "SELECT * FROM t"},
-	}
+	t.Run("TenantBenchmarks", func(t *testing.T) {
+		now := time.Now().UTC()
+		for _, eval := range []*domain.Evaluation{
+			{ID: "eval-bench-1", TxID: "tx-bench-1", Status: domain.StatusAlert, Timestamp: now.Add(-time.Minute),
+				Metadata: domain.EvaluationMetadata{TotalMs: 10}},
+			{ID: "eval-bench-2", TxID: "tx-bench-2", Status: domain.StatusNoAlert, Timestamp: now.Add(-time.Minute),
+				Metadata: domain.EvaluationMetadata{TotalMs: 20}},
+			{ID: "eval-bench-3", TxID: "tx-bench-3", Status: domain.StatusAlert, Timestamp: now.Add(-2 * time.Hour)},
+		} {
+			if err := repo.SaveEvaluation(ctx, "tenant-bench", eval); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
+		}
+		for _, alert := range []*domain.Alert{
+			{ID: "eval-bench-1", TxID: "tx-bench-1", Status: domain.AlertClosedFalsePositive, CreatedAt: now.Add(-time.Minute)},
+			{ID: "eval-bench-3", TxID: "tx-bench-3", Status: domain.AlertClosedConfirmed, CreatedAt: now.Add(-2 * time.Hour)},
+		} {
+			alert.LastNotifiedAt = alert.CreatedAt
+			alert.AckedAt = &alert.CreatedAt // Closed alerts were handled, so they aren't due
+			if err := repo.SaveAlert(ctx, "tenant-bench", alert); err != nil {
+				t.Fatalf("SaveAlert failed: %v", err)
+			}
+		}
 
-	for _, tt := range tests {
-		result := repo.rebind(tt.input)
-		if result != tt.expected {
-			t.Errorf("rebind(%q) = %q, want %q", tt.input, result, tt.expected)
+		benchmarks, err := repo.ListTenantBenchmarks(ctx, now.Add(-time.Hour), now)
+		if err != nil {
+			t.Fatalf("ListTenantBenchmarks failed: %v", err)
 		}
-	}
+		var bench *domain.TenantBenchmark
+		for _, b := range benchmarks {
+			if b.TenantID == "tenant-bench" {
+				bench = b
+			}
+		}
+		if bench == nil || bench.Evaluations != 2 || bench.Alerts != 1 || bench.MeanTotalMs != 15 {
+			t.Fatalf("expected the window's 2 evaluations, 1 alerting, 15 ms on average, got %+v", bench)
+		}
+		if bench.ClosedFalsePositive != 1 || bench.ClosedConfirmed != 0 {
+			t.Errorf("expected the window's false positive only, got %+v", bench)
+		}
+	})
+
+	t.Run("ScoreHistograms", func(t *testing.T) {
+		now := time.Now().UTC()
+		for _, eval := range []*domain.Evaluation{
+			{ID: "eval-hist-1", TxID: "tx-hist-1", Score: 0.29, Timestamp: now.Add(-time.Minute),
+				TypologyResults: []domain.TypologyResult{{TypologyID: "structuring", Score: 0.5}}},
+			{ID: "eval-hist-2", TxID: "tx-hist-2", Score: 1.0, Timestamp: now.Add(-time.Minute)},
+			{ID: "eval-hist-3", TxID: "tx-hist-3", Score: 0.5, Timestamp: now.Add(-2 * time.Hour)},
+		} {
+			eval.Status = domain.StatusNoAlert
+			if err := repo.SaveEvaluation(ctx, "tenant-hist", eval); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
+		}
+
+		counts, err := repo.EvaluationScoreHistogram(ctx, "tenant-hist", now.Add(-time.Hour), now)
+		if err != nil {
+			t.Fatalf("EvaluationScoreHistogram failed: %v", err)
+		}
+		if len(counts) != domain.ScoreHistogramBuckets || counts[29] != 1 || counts[99] != 1 || counts[50] != 0 {
+			t.Errorf("expected 0.29 and 1.0 in buckets 29 and 99, got %v", counts)
+		}
+
+		typologies, err := repo.TypologyScoreHistograms(ctx, "tenant-hist", now.Add(-time.Hour), now)
+		if err != nil {
+			t.Fatalf("TypologyScoreHistograms failed: %v", err)
+		}
+		if len(typologies) != 1 || typologies["structuring"][50] != 1 {
+			t.Errorf("expected structuring in bucket 50, got %v", typologies)
+		}
+
+		empty, err := repo.EvaluationScoreHistogram(ctx, "tenant-002", now.Add(-time.Hour), now)
+		if err != nil || len(empty) != domain.ScoreHistogramBuckets {
+			t.Errorf("expected empty buckets for another tenant, got %v, %v", empty, err)
+		}
+	})
+
+	t.Run("SaveAndGetPartyKYC", func(t *testing.T) {
+		onboarded := time.Now().UTC().Add(-90 * 24 * time.Hour).Truncate(time.Second)
+		kyc := &domain.PartyKYC{
+			EntityID:         "debtor-001",
+			RiskRating:       domain.RiskRatingHigh,
+			PEP:              true,
+			OnboardedAt:      onboarded,
+			ResidenceCountry: "GB",
+		}
+
+		if err := repo.SavePartyKYC(ctx, tenantID, kyc); err != nil {
+			t.Fatalf("SavePartyKYC failed: %v", err)
+		}
+
+		// Upsert replaces the existing profile
+		kyc.RiskRating = domain.RiskRatingMedium
+		if err := repo.SavePartyKYC(ctx, tenantID, kyc); err != nil {
+			t.Fatalf("SavePartyKYC upsert failed: %v", err)
+		}
+
+		retrieved, err := repo.GetPartyKYC(ctx, tenantID, "debtor-001")
+		if err != nil {
+			t.Fatalf("GetPartyKYC failed: %v", err)
+		}
+		if retrieved.RiskRating != domain.RiskRatingMedium {
+			t.Errorf("expected RiskRating medium, got %s", retrieved.RiskRating)
+		}
+		if !retrieved.PEP {
+			t.Error("expected PEP flag to be set")
+		}
+		if !retrieved.OnboardedAt.Equal(onboarded) {
+			t.Errorf("expected OnboardedAt %v, got %v", onboarded, retrieved.OnboardedAt)
+		}
+
+		if _, err := repo.GetPartyKYC(ctx, "tenant-002", "debtor-001"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for different tenant, got: %v", err)
+		}
+	})
+
+	t.Run("ListAndDeletePartyKYC", func(t *testing.T) {
+		for _, kyc := range []*domain.PartyKYC{
+			{EntityID: "cust-b", RiskRating: domain.RiskRatingHigh, Segment: "sme"},
+			{EntityID: "cust-a", RiskRating: domain.RiskRatingHigh, Segment: "retail"},
+			{EntityID: "cust-c", RiskRating: domain.RiskRatingLow, Segment: "sme"},
+		} {
+			if err := repo.SavePartyKYC(ctx, "tenant-customers", kyc); err != nil {
+				t.Fatalf("SavePartyKYC failed: %v", err)
+			}
+		}
+
+		list := func(filter domain.PartyFilter) string {
+			t.Helper()
+			profiles, err := repo.ListPartyKYC(ctx, "tenant-customers", filter)
+			if err != nil {
+				t.Fatalf("ListPartyKYC failed: %v", err)
+			}
+			var ids []string
+			for _, p := range profiles {
+				ids = append(ids, p.EntityID+"/"+p.Segment)
+			}
+			return strings.Join(ids, ",")
+		}
+		if got := list(domain.PartyFilter{}); got != "cust-a/retail,cust-b/sme,cust-c/sme" {
+			t.Errorf("expected every profile by entity ID, got %s", got)
+		}
+		if got := list(domain.PartyFilter{RiskRating: domain.RiskRatingHigh, Segment: "sme"}); got != "cust-b/sme" {
+			t.Errorf("expected the high-risk sme profile, got %s", got)
+		}
+
+		if err := repo.DeletePartyKYC(ctx, "tenant-customers", "cust-a"); err != nil {
+			t.Fatalf("DeletePartyKYC failed: %v", err)
+		}
+		if err := repo.DeletePartyKYC(ctx, "tenant-customers", "cust-a"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+		if err := repo.DeletePartyKYC(ctx, "tenant-002", "cust-b"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for different tenant, got %v", err)
+		}
+		if got := list(domain.PartyFilter{}); got != "cust-b/sme,cust-c/sme" {
+			t.Errorf("expected cust-a deleted, got %s", got)
+		}
+	})
+
+	t.Run("ScoringConfigCRUD", func(t *testing.T) {
+		if _, err := repo.GetScoringConfig(ctx, "tenant-scoring"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound before any config, got %v", err)
+		}
+
+		cfg := &domain.ScoringConfig{AlertThreshold: 0.6, WeightedScoring: true, CriticalFail: domain.CriticalFailAlert, UpdatedBy: "ops"}
+		if err := repo.SaveScoringConfig(ctx, "tenant-scoring", cfg); err != nil {
+			t.Fatalf("SaveScoringConfig failed: %v", err)
+		}
+
+		// Upsert replaces the existing config
+		cfg.AlertThreshold = 0.4
+		cfg.WeightedScoring = false
+		cfg.CriticalFail = domain.CriticalFailScore
+		cfg.MLWeight, cfg.MLBlend = 0.3, domain.MLBlendMax
+		if err := repo.SaveScoringConfig(ctx, "tenant-scoring", cfg); err != nil {
+			t.Fatalf("SaveScoringConfig failed: %v", err)
+		}
+
+		got, err := repo.GetScoringConfig(ctx, "tenant-scoring")
+		if err != nil {
+			t.Fatalf("GetScoringConfig failed: %v", err)
+		}
+		if got.TenantID != "tenant-scoring" || got.AlertThreshold != 0.4 || got.WeightedScoring || got.CriticalFail != domain.CriticalFailScore || got.MLWeight != 0.3 || got.MLBlend != domain.MLBlendMax || got.UpdatedBy != "ops" || got.UpdatedAt.IsZero() {
+			t.Errorf("unexpected scoring config: %+v", got)
+		}
+
+		if err := repo.DeleteScoringConfig(ctx, "tenant-002"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for different tenant, got %v", err)
+		}
+		if err := repo.DeleteScoringConfig(ctx, "tenant-scoring"); err != nil {
+			t.Fatalf("DeleteScoringConfig failed: %v", err)
+		}
+		if _, err := repo.GetScoringConfig(ctx, "tenant-scoring"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("NamedListCRUD", func(t *testing.T) {
+		if _, err := repo.GetNamedList(ctx, "tenant-lists", "internal_accounts"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound before any list, got %v", err)
+		}
+
+		list := &domain.NamedList{Name: "internal_accounts", Values: []string{"treasury"}, UpdatedBy: "ops"}
+		if err := repo.SaveNamedList(ctx, "tenant-lists", list); err != nil {
+			t.Fatalf("SaveNamedList failed: %v", err)
+		}
+		if err := repo.SaveNamedList(ctx, "tenant-lists", &domain.NamedList{Name: "high_risk_merchants"}); err != nil {
+			t.Fatalf("SaveNamedList failed: %v", err)
+		}
+
+		// Upsert replaces the existing list
+		list.Description = "Own accounts"
+		list.Values = []string{"treasury", "payroll"}
+		if err := repo.SaveNamedList(ctx, "tenant-lists", list); err != nil {
+			t.Fatalf("SaveNamedList failed: %v", err)
+		}
+
+		got, err := repo.GetNamedList(ctx, "tenant-lists", "internal_accounts")
+		if err != nil {
+			t.Fatalf("GetNamedList failed: %v", err)
+		}
+		if got.TenantID != "tenant-lists" || got.Description != "Own accounts" || len(got.Values) != 2 || got.Values[1] != "payroll" || got.UpdatedBy != "ops" || got.UpdatedAt.IsZero() {
+			t.Errorf("unexpected named list: %+v", got)
+		}
+
+		lists, err := repo.ListNamedLists(ctx, "tenant-lists")
+		if err != nil {
+			t.Fatalf("ListNamedLists failed: %v", err)
+		}
+		if len(lists) != 2 || lists[0].Name != "high_risk_merchants" || lists[0].Values == nil {
+			t.Errorf("expected 2 lists by name, got %+v", lists)
+		}
+		all, err := repo.ListAllNamedLists(ctx)
+		if err != nil {
+			t.Fatalf("ListAllNamedLists failed: %v", err)
+		}
+		if len(all) < 2 {
+			t.Errorf("expected every tenant's lists, got %d", len(all))
+		}
+
+		if err := repo.DeleteNamedList(ctx, "tenant-002", "internal_accounts"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for different tenant, got %v", err)
+		}
+		if err := repo.DeleteNamedList(ctx, "tenant-lists", "internal_accounts"); err != nil {
+			t.Fatalf("DeleteNamedList failed: %v", err)
+		}
+		if _, err := repo.GetNamedList(ctx, "tenant-lists", "internal_accounts"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("CorridorRiskCRUD", func(t *testing.T) {
+		c := &domain.CorridorRisk{Origin: "GB", Destination: "AE", Risk: 0.4, Note: "enhanced review"}
+		if err := repo.SaveCorridorRisk(ctx, tenantID, c); err != nil {
+			t.Fatalf("SaveCorridorRisk failed: %v", err)
+		}
+
+		// Upsert replaces the existing override
+		c.Risk = 0.7
+		if err := repo.SaveCorridorRisk(ctx, tenantID, c); err != nil {
+			t.Fatalf("SaveCorridorRisk upsert failed: %v", err)
+		}
+
+		corridors, err := repo.ListCorridorRisks(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("ListCorridorRisks failed: %v", err)
+		}
+		if len(corridors) != 1 || corridors[0].Risk != 0.7 {
+			t.Fatalf("expected one override with risk 0.7, got %+v", corridors)
+		}
+
+		others, _ := repo.ListCorridorRisks(ctx, "tenant-002")
+		if len(others) != 0 {
+			t.Errorf("expected no overrides for different tenant, got %d", len(others))
+		}
+
+		if err := repo.DeleteCorridorRisk(ctx, tenantID, "GB", "AE"); err != nil {
+			t.Fatalf("DeleteCorridorRisk failed: %v", err)
+		}
+		if err := repo.DeleteCorridorRisk(ctx, tenantID, "GB", "AE"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound on second delete, got: %v", err)
+		}
+	})
+
+	t.Run("FeatureFlagCRUD", func(t *testing.T) {
+		if err := repo.SaveFeatureFlag(ctx, tenantID, &domain.FeatureFlag{Name: "ml_hook", Enabled: true}); err != nil {
+			t.Fatalf("SaveFeatureFlag failed: %v", err)
+		}
+		if err := repo.SaveFeatureFlag(ctx, tenantID, &domain.FeatureFlag{Name: "ml_hook", Enabled: false}); err != nil {
+			t.Fatalf("SaveFeatureFlag upsert failed: %v", err)
+		}
+
+		flags, err := repo.ListFeatureFlags(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("ListFeatureFlags failed: %v", err)
+		}
+		if len(flags) != 1 || flags[0].Enabled {
+			t.Fatalf("expected one disabled flag, got %+v", flags)
+		}
+
+		others, _ := repo.ListFeatureFlags(ctx, "tenant-002")
+		if len(others) != 0 {
+			t.Errorf("expected no flags for different tenant, got %d", len(others))
+		}
+
+		if err := repo.DeleteFeatureFlag(ctx, tenantID, "ml_hook"); err != nil {
+			t.Fatalf("DeleteFeatureFlag failed: %v", err)
+		}
+		if err := repo.DeleteFeatureFlag(ctx, tenantID, "ml_hook"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound on second delete, got: %v", err)
+		}
+	})
+
+	t.Run("TypologyCoverageOptions", func(t *testing.T) {
+		typology := &domain.Typology{
+			ID:             "typology-mule",
+			Name:           "Mule Account",
+			Version:        "1.0.0",
+			Rules:          []domain.TypologyRuleWeight{{RuleID: "rule-1", Weight: 0.5}, {RuleID: "rule-2", Weight: 0.5}},
+			AlertThreshold: 0.5,
+			MinRulesFired:  2,
+			MinCoverage:    0.75,
+			Enabled:        true,
+		}
+		if err := repo.SaveTypology(ctx, tenantID, typology); err != nil {
+			t.Fatalf("SaveTypology failed: %v", err)
+		}
+
+		got, err := repo.GetTypology(ctx, tenantID, typology.ID)
+		if err != nil {
+			t.Fatalf("GetTypology failed: %v", err)
+		}
+		if got.MinRulesFired != 2 || got.MinCoverage != 0.75 {
+			t.Errorf("expected coverage options to round-trip, got minRulesFired=%d minCoverage=%v", got.MinRulesFired, got.MinCoverage)
+		}
+	})
+
+	t.Run("ListAcrossTenants", func(t *testing.T) {
+		for _, rule := range []*domain.RuleConfig{
+			{ID: "rule-global", Name: "Global", Version: "1.0.0", Expression: "true", Enabled: true},
+			{ID: "rule-own", Name: "Own", Version: "1.0.0", Expression: "true", Enabled: true},
+			{ID: "rule-off", Name: "Off", Version: "1.0.0", Expression: "true"},
+		} {
+			scope := tenantID
+			if rule.ID == "rule-global" {
+				scope = "*"
+			}
+			if err := repo.SaveRuleConfig(ctx, scope, rule); err != nil {
+				t.Fatalf("SaveRuleConfig failed: %v", err)
+			}
+		}
+		if err := repo.SaveTypology(ctx, "*", &domain.Typology{ID: "typology-global", Name: "Global", Version: "1.0.0", AlertThreshold: 0.5, Enabled: true}); err != nil {
+			t.Fatalf("SaveTypology failed: %v", err)
+		}
+
+		allRules, err := repo.ListAllRuleConfigs(ctx)
+		if err != nil {
+			t.Fatalf("ListAllRuleConfigs failed: %v", err)
+		}
+		tenants := make(map[string]string)
+		for _, rule := range allRules {
+			tenants[rule.ID] = rule.TenantID
+		}
+		if tenants["rule-global"] != "*" || tenants["rule-own"] != tenantID {
+			t.Errorf("expected rules of both scopes with their tenant, got %v", tenants)
+		}
+		if _, ok := tenants["rule-off"]; ok {
+			t.Error("expected disabled rules to be excluded")
+		}
+
+		allTypologies, err := repo.ListAllTypologies(ctx)
+		if err != nil {
+			t.Fatalf("ListAllTypologies failed: %v", err)
+		}
+		tenants = make(map[string]string)
+		for _, typology := range allTypologies {
+			tenants[typology.ID] = typology.TenantID
+		}
+		if tenants["typology-global"] != "*" || tenants["typology-mule"] != tenantID {
+			t.Errorf("expected typologies of both scopes with their tenant, got %v", tenants)
+		}
+	})
+
+	t.Run("DeleteRuleConfig", func(t *testing.T) {
+		for _, version := range []string{"1.0.0", "1.1.0"} {
+			if err := repo.SaveRuleConfig(ctx, tenantID, &domain.RuleConfig{ID: "rule-delete", Name: "Delete", Version: version, Expression: "true", Enabled: true}); err != nil {
+				t.Fatalf("SaveRuleConfig failed: %v", err)
+			}
+		}
+
+		if err := repo.DeleteRuleConfig(ctx, tenantID, "rule-delete"); err != nil {
+			t.Fatalf("DeleteRuleConfig failed: %v", err)
+		}
+		if _, err := repo.GetRuleConfig(ctx, tenantID, "rule-delete"); err != ErrNotFound {
+			t.Errorf("expected every version to be deleted, got %v", err)
+		}
+		if err := repo.DeleteRuleConfig(ctx, tenantID, "rule-unknown"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("RuleVersions", func(t *testing.T) {
+		tenant := "tenant-versions"
+		first := &domain.RuleConfig{ID: "rule-owned", Name: "Owned", Version: "1.0.0", Owner: "fraud-ops", Expression: "true", Enabled: true}
+		if err := repo.SaveRuleConfig(ctx, tenant, first); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+		second := *first
+		second.Version, second.Owner = "2.0.0", "payments-risk"
+		if err := repo.SaveRuleConfig(ctx, tenant, &second); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+		first.Enabled = false
+		if err := repo.SaveRuleConfig(ctx, tenant, first); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+
+		got, err := repo.GetRuleConfig(ctx, tenant, "rule-owned")
+		if err != nil || got.Owner != "payments-risk" {
+			t.Fatalf("expected the owner to round-trip, got %+v, %v", got, err)
+		}
+		versions, err := repo.ListRuleVersions(ctx, tenant)
+		if err != nil {
+			t.Fatalf("ListRuleVersions failed: %v", err)
+		}
+		if len(versions) != 2 || versions[0].Version != "1.0.0" || versions[0].Enabled || versions[0].Owner != "fraud-ops" {
+			t.Fatalf("expected the retired first version first, got %+v", versions)
+		}
+		if !versions[1].Enabled || versions[1].CreatedAt.IsZero() || versions[0].UpdatedAt.Before(versions[0].CreatedAt) {
+			t.Errorf("expected the active second version with timestamps, got %+v", versions[1])
+		}
+	})
+
+	t.Run("AlertLifecycle", func(t *testing.T) {
+		created := time.Now().UTC().Add(-time.Hour)
+		alert := &domain.Alert{ID: "eval-alert-001", TxID: "tx-001", Score: 0.9, CreatedAt: created, LastNotifiedAt: created}
+		if err := repo.SaveAlert(ctx, tenantID, alert); err != nil {
+			t.Fatalf("SaveAlert failed: %v", err)
+		}
+
+		due, err := repo.ListDueAlerts(ctx, time.Now(), 3, 10)
+		if err != nil {
+			t.Fatalf("ListDueAlerts failed: %v", err)
+		}
+		if len(due) != 1 || due[0].TenantID != tenantID {
+			t.Fatalf("expected one due alert, got %+v", due)
+		}
+
+		if err := repo.EscalateAlert(ctx, tenantID, alert.ID, 1, time.Now()); err != nil {
+			t.Fatalf("EscalateAlert failed: %v", err)
+		}
+		if err := repo.EscalateAlert(ctx, tenantID, alert.ID, 1, time.Now()); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound escalating to the same level twice, got: %v", err)
+		}
+
+		if err := repo.AckAlert(ctx, "tenant-002", alert.ID, "analyst", "", time.Now()); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound acknowledging another tenant's alert, got: %v", err)
+		}
+		if err := repo.AckAlert(ctx, tenantID, alert.ID, "analyst", "looked at it", time.Now()); err != nil {
+			t.Fatalf("AckAlert failed: %v", err)
+		}
+		if err := repo.AckAlert(ctx, tenantID, alert.ID, "someone-else", "", time.Now()); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound on second ack, got: %v", err)
+		}
+
+		// Saving again must not reset the acknowledgment
+		if err := repo.SaveAlert(ctx, tenantID, alert); err != nil {
+			t.Fatalf("SaveAlert again failed: %v", err)
+		}
+
+		got, err := repo.GetAlert(ctx, tenantID, alert.ID)
+		if err != nil {
+			t.Fatalf("GetAlert failed: %v", err)
+		}
+		if !got.Acked() || got.AckedBy != "analyst" || got.AckNote != "looked at it" || got.EscalationLevel != 1 {
+			t.Errorf("unexpected alert state: %+v", got)
+		}
+
+		unacked, _ := repo.ListAlerts(ctx, tenantID, domain.AlertFilter{Unacked: true})
+		if len(unacked) != 0 {
+			t.Errorf("expected no unacknowledged alerts, got %d", len(unacked))
+		}
+		all, _ := repo.ListAlerts(ctx, tenantID, domain.AlertFilter{})
+		if len(all) != 1 {
+			t.Errorf("expected one alert, got %d", len(all))
+		}
+		due, _ = repo.ListDueAlerts(ctx, time.Now().Add(time.Hour), 3, 10)
+		if len(due) != 0 {
+			t.Errorf("expected acknowledged alert not to be due, got %d", len(due))
+		}
+	})
+
+	t.Run("AlertCase", func(t *testing.T) {
+		// Older than the alert from AlertLifecycle, which is open and scores 0.9
+		created := time.Now().UTC().Add(-2 * time.Hour)
+		for i, score := range []float64{0.5, 0.97} {
+			alert := &domain.Alert{
+				ID:             fmt.Sprintf("eval-case-%d", i),
+				TxID:           fmt.Sprintf("tx-case-%d", i),
+				Score:          score,
+				CreatedAt:      created.Add(time.Duration(i) * time.Minute),
+				LastNotifiedAt: created,
+			}
+			if err := repo.SaveAlert(ctx, tenantID, alert); err != nil {
+				t.Fatalf("SaveAlert failed: %v", err)
+			}
+		}
+
+		got, err := repo.GetAlert(ctx, tenantID, "eval-case-0")
+		if err != nil {
+			t.Fatalf("GetAlert failed: %v", err)
+		}
+		if got.Status != domain.AlertOpen || !got.UpdatedAt.Equal(got.CreatedAt) {
+			t.Errorf("expected a new open alert, got %+v", got)
+		}
+
+		updated := time.Now().UTC()
+		if err := repo.UpdateAlertCase(ctx, tenantID, "eval-case-0", domain.AlertOpen, domain.AlertInvestigating, "analyst-1", updated); err != nil {
+			t.Fatalf("UpdateAlertCase failed: %v", err)
+		}
+		if err := repo.UpdateAlertCase(ctx, tenantID, "eval-case-0", domain.AlertOpen, domain.AlertClosedConfirmed, "", updated); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound updating from a stale status, got: %v", err)
+		}
+
+		for _, event := range []*domain.AlertEvent{
+			{ID: "event-2", AlertID: "eval-case-0", Action: domain.AlertEventAssign, Actor: "lead", To: "analyst-1", At: updated},
+			{ID: "event-1", AlertID: "eval-case-0", Action: domain.AlertEventStatus, Actor: "lead", From: domain.AlertOpen, To: domain.AlertInvestigating, At: updated.Add(-time.Second)},
+		} {
+			if err := repo.SaveAlertEvent(ctx, tenantID, event); err != nil {
+				t.Fatalf("SaveAlertEvent failed: %v", err)
+			}
+		}
+		events, err := repo.ListAlertEvents(ctx, tenantID, "eval-case-0")
+		if err != nil {
+			t.Fatalf("ListAlertEvents failed: %v", err)
+		}
+		if len(events) != 2 || events[0].ID != "event-1" || events[1].To != "analyst-1" {
+			t.Errorf("expected history oldest first, got %+v", events)
+		}
+		if events, _ := repo.ListAlertEvents(ctx, "tenant-002", "eval-case-0"); len(events) != 0 {
+			t.Errorf("expected no history for another tenant, got %d", len(events))
+		}
+
+		for name, tc := range map[string]struct {
+			filter domain.AlertFilter
+			want   []string
+		}{
+			"status":   {domain.AlertFilter{Statuses: []string{domain.AlertInvestigating}}, []string{"eval-case-0"}},
+			"statuses": {domain.AlertFilter{Statuses: []string{domain.AlertOpen, domain.AlertInvestigating}, MinScore: 0.4}, []string{"eval-alert-001", "eval-case-1", "eval-case-0"}},
+			"assignee": {domain.AlertFilter{Assignee: "analyst-1"}, []string{"eval-case-0"}},
+			"txId":     {domain.AlertFilter{TxID: "tx-case-1"}, []string{"eval-case-1"}},
+			"minScore": {domain.AlertFilter{MinScore: 0.95}, []string{"eval-case-1"}},
+		} {
+			list, err := repo.ListAlerts(ctx, tenantID, tc.filter)
+			if err != nil {
+				t.Fatalf("%s: ListAlerts failed: %v", name, err)
+			}
+			var ids []string
+			for _, alert := range list {
+				ids = append(ids, alert.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tc.want) {
+				t.Errorf("%s: got %v, want %v", name, ids, tc.want)
+			}
+		}
+	})
+
+	t.Run("ShadowRule", func(t *testing.T) {
+		rule := &domain.RuleConfig{
+			ID:         "shadow-rule",
+			TenantID:   tenantID,
+			Name:       "Shadow",
+			Expression: "amount > 1.0",
+			Version:    "1.0.0",
+			Enabled:    true,
+			Shadow:     true,
+		}
+		if err := repo.SaveRuleConfig(ctx, tenantID, rule); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+		got, err := repo.GetRuleConfig(ctx, tenantID, rule.ID)
+		if err != nil {
+			t.Fatalf("GetRuleConfig failed: %v", err)
+		}
+		if !got.Shadow {
+			t.Error("expected Shadow to round-trip")
+		}
+
+		rule.Shadow = false
+		if err := repo.SaveRuleConfig(ctx, tenantID, rule); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+		got, err = repo.GetRuleConfig(ctx, tenantID, rule.ID)
+		if err != nil {
+			t.Fatalf("GetRuleConfig failed: %v", err)
+		}
+		if got.Shadow {
+			t.Error("expected Shadow to be cleared on update")
+		}
+	})
+
+	t.Run("ActivationSamples", func(t *testing.T) {
+		rule := &domain.RuleConfig{
+			ID:         "sampled-rule",
+			TenantID:   tenantID,
+			Name:       "Sampled",
+			Expression: "amount > 1.0",
+			Version:    "1.0.0",
+			Enabled:    true,
+			SampleRate: 0.25,
+		}
+		if err := repo.SaveRuleConfig(ctx, tenantID, rule); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+		got, err := repo.GetRuleConfig(ctx, tenantID, rule.ID)
+		if err != nil {
+			t.Fatalf("GetRuleConfig failed: %v", err)
+		}
+		if got.SampleRate != 0.25 {
+			t.Errorf("expected SampleRate 0.25, got %v", got.SampleRate)
+		}
+
+		base := time.Now().UTC().Add(-time.Minute)
+		for i, ruleID := range []string{"sampled-rule", "sampled-rule", "other-rule"} {
+			sample := &domain.ActivationSample{
+				ID:          fmt.Sprintf("sample-%d", i),
+				RuleID:      ruleID,
+				RuleVersion: "1.0.0",
+				TxID:        fmt.Sprintf("tx-%d", i),
+				Outcome:     domain.RuleOutcomeFail,
+				Score:       1,
+				Activation:  map[string]any{"amount": 10.0 * float64(i+1)},
+				CreatedAt:   base.Add(time.Duration(i) * time.Second),
+			}
+			if err := repo.SaveActivationSample(ctx, tenantID, sample); err != nil {
+				t.Fatalf("SaveActivationSample failed: %v", err)
+			}
+		}
+
+		samples, err := repo.ListActivationSamples(ctx, tenantID, "sampled-rule", 10)
+		if err != nil {
+			t.Fatalf("ListActivationSamples failed: %v", err)
+		}
+		if len(samples) != 2 || samples[0].ID != "sample-1" || samples[1].ID != "sample-0" {
+			t.Fatalf("expected the rule's samples newest first, got %+v", samples)
+		}
+		if samples[0].TenantID != tenantID || samples[0].Activation["amount"] != 20.0 {
+			t.Errorf("unexpected sample: %+v", samples[0])
+		}
+
+		limited, _ := repo.ListActivationSamples(ctx, tenantID, "sampled-rule", 1)
+		if len(limited) != 1 {
+			t.Errorf("expected limit to apply, got %d samples", len(limited))
+		}
+		other, _ := repo.ListActivationSamples(ctx, "tenant-002", "sampled-rule", 10)
+		if len(other) != 0 {
+			t.Errorf("expected no samples for another tenant, got %d", len(other))
+		}
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		webhook := &domain.Webhook{
+			ID:        "webhook-1",
+			URL:       "https://example.com/hooks/osprey",
+			Secret:    "s3cret",
+			CreatedAt: now,
+		}
+		if err := repo.SaveWebhook(ctx, tenantID, webhook); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+		got, err := repo.GetWebhook(ctx, tenantID, webhook.ID)
+		if err != nil {
+			t.Fatalf("GetWebhook failed: %v", err)
+		}
+		if got.URL != webhook.URL || got.Secret != webhook.Secret {
+			t.Errorf("unexpected webhook: %+v", got)
+		}
+		if _, err := repo.GetWebhook(ctx, "other-tenant", webhook.ID); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for another tenant, got %v", err)
+		}
+		if got.Feed != domain.WebhookFeedAlerts || got.Filter != nil {
+			t.Errorf("expected the alerts feed without a filter by default, got %+v", got)
+		}
+
+		maxScore := 0.5
+		decisions := &domain.Webhook{
+			ID:        "webhook-decisions",
+			URL:       "https://example.com/hooks/decisions",
+			Secret:    "s3cret",
+			Feed:      domain.WebhookFeedDecisions,
+			Filter:    &domain.WebhookFilter{Statuses: []string{domain.StatusNoAlert}, MaxScore: &maxScore},
+			BatchSize: 50,
+			CreatedAt: now,
+		}
+		if err := repo.SaveWebhook(ctx, tenantID, decisions); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+		got, err = repo.GetWebhook(ctx, tenantID, decisions.ID)
+		if err != nil {
+			t.Fatalf("GetWebhook failed: %v", err)
+		}
+		if got.Feed != domain.WebhookFeedDecisions || got.BatchSize != 50 || got.Filter == nil ||
+			got.Filter.MaxScore == nil || *got.Filter.MaxScore != 0.5 || len(got.Filter.Statuses) != 1 {
+			t.Errorf("decisions webhook did not round-trip: %+v", got)
+		}
+		if err := repo.DeleteWebhook(ctx, tenantID, decisions.ID); err != nil {
+			t.Fatalf("DeleteWebhook failed: %v", err)
+		}
+
+		delivery := &domain.WebhookDelivery{
+			ID:            "delivery-1",
+			WebhookID:     webhook.ID,
+			EvaluationID:  "eval-1",
+			Event:         domain.WebhookEventAlert,
+			Payload:       []byte(`{"id":"eval-1"}`),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now.Add(-time.Second),
+			CreatedAt:     now,
+		}
+		if err := repo.SaveWebhookDelivery(ctx, tenantID, delivery); err != nil {
+			t.Fatalf("SaveWebhookDelivery failed: %v", err)
+		}
+
+		due, err := repo.ListDueWebhookDeliveries(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("ListDueWebhookDeliveries failed: %v", err)
+		}
+		if len(due) != 1 || due[0].TenantID != tenantID || string(due[0].Payload) != `{"id":"eval-1"}` {
+			t.Fatalf("unexpected due deliveries: %+v", due)
+		}
+
+		// Only one claim at a given attempt count succeeds
+		lease := now.Add(time.Minute)
+		if err := repo.ClaimWebhookDelivery(ctx, tenantID, delivery.ID, 0, lease); err != nil {
+			t.Fatalf("ClaimWebhookDelivery failed: %v", err)
+		}
+		if err := repo.ClaimWebhookDelivery(ctx, tenantID, delivery.ID, 0, lease); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for a second claim, got %v", err)
+		}
+		due, err = repo.ListDueWebhookDeliveries(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("ListDueWebhookDeliveries failed: %v", err)
+		}
+		if len(due) != 0 {
+			t.Errorf("expected claimed delivery to be leased, got %d due", len(due))
+		}
+
+		delivered := now.Add(time.Second)
+		delivery.Attempts = 1
+		delivery.Status = domain.DeliveryDelivered
+		delivery.LastStatusCode = 204
+		delivery.DeliveredAt = &delivered
+		if err := repo.SaveWebhookDelivery(ctx, tenantID, delivery); err != nil {
+			t.Fatalf("SaveWebhookDelivery failed: %v", err)
+		}
+		deliveries, err := repo.ListWebhookDeliveries(ctx, tenantID, webhook.ID, 10)
+		if err != nil {
+			t.Fatalf("ListWebhookDeliveries failed: %v", err)
+		}
+		if len(deliveries) != 1 {
+			t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+		}
+		if d := deliveries[0]; d.Status != domain.DeliveryDelivered || d.Attempts != 1 || d.LastStatusCode != 204 || d.DeliveredAt == nil {
+			t.Errorf("unexpected delivery: %+v", d)
+		}
+
+		if err := repo.DeleteWebhook(ctx, tenantID, webhook.ID); err != nil {
+			t.Fatalf("DeleteWebhook failed: %v", err)
+		}
+		if err := repo.DeleteWebhook(ctx, tenantID, webhook.ID); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("Watchlist", func(t *testing.T) {
+		for _, entry := range []*domain.WatchlistEntry{
+			{ID: "sdn-1", ListType: domain.WatchlistSanctions, Name: "Ivan  Petrov", Country: "RU", Note: "SDN"},
+			{ID: "block-1", ListType: domain.WatchlistBlocklist, AccountID: "acct-bad"},
+			{ID: "watch-1", ListType: domain.WatchlistInternal, PartyID: "cust-009", Name: "Alice Smith"},
+		} {
+			if err := repo.SaveWatchlistEntry(ctx, tenantID, entry); err != nil {
+				t.Fatalf("SaveWatchlistEntry failed: %v", err)
+			}
+		}
+		if err := repo.SaveWatchlistEntry(ctx, tenantID, &domain.WatchlistEntry{ID: "empty", ListType: domain.WatchlistInternal}); err == nil {
+			t.Error("expected an error for an entry without party, account or name")
+		}
+
+		got, err := repo.GetWatchlistEntry(ctx, tenantID, "sdn-1")
+		if err != nil {
+			t.Fatalf("GetWatchlistEntry failed: %v", err)
+		}
+		if got.Name != "Ivan  Petrov" || got.Country != "RU" || got.Note != "SDN" || got.CreatedAt.IsZero() {
+			t.Errorf("unexpected entry: %+v", got)
+		}
+		if _, err := repo.GetWatchlistEntry(ctx, "other-tenant", "sdn-1"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for another tenant, got %v", err)
+		}
+
+		all, err := repo.ListWatchlistEntries(ctx, tenantID, "", 0, 10)
+		if err != nil {
+			t.Fatalf("ListWatchlistEntries failed: %v", err)
+		}
+		if len(all) != 3 || all[0].ID != "block-1" || all[1].ID != "watch-1" || all[2].ID != "sdn-1" {
+			t.Errorf("expected entries ordered by name, got %v", all)
+		}
+		sanctions, _ := repo.ListWatchlistEntries(ctx, tenantID, domain.WatchlistSanctions, 0, 10)
+		if len(sanctions) != 1 {
+			t.Errorf("expected 1 sanctions entry, got %d", len(sanctions))
+		}
+		page, _ := repo.ListWatchlistEntries(ctx, tenantID, "", 2, 10)
+		if len(page) != 1 || page[0].ID != "sdn-1" {
+			t.Errorf("expected the last entry at offset 2, got %v", page)
+		}
+
+		match := func(subject domain.WatchlistSubject) []string {
+			t.Helper()
+			entries, err := repo.MatchWatchlist(ctx, tenantID, subject)
+			if err != nil {
+				t.Fatalf("MatchWatchlist failed: %v", err)
+			}
+			ids := []string{}
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			return ids
+		}
+		if ids := match(domain.WatchlistSubject{Name: "IVAN PETROV", Country: "RU"}); len(ids) != 1 || ids[0] != "sdn-1" {
+			t.Errorf("expected a normalized name match, got %v", ids)
+		}
+		if ids := match(domain.WatchlistSubject{Name: "Ivan Petrov", Country: "US"}); len(ids) != 0 {
+			t.Errorf("expected no match in another country, got %v", ids)
+		}
+		if ids := match(domain.WatchlistSubject{PartyID: "cust-009", AccountID: "acct-bad"}); len(ids) != 2 {
+			t.Errorf("expected party and account matches, got %v", ids)
+		}
+		if ids := match(domain.WatchlistSubject{}); len(ids) != 0 {
+			t.Errorf("expected no match for an empty subject, got %v", ids)
+		}
+
+		got.ListType = domain.WatchlistBlocklist
+		if err := repo.SaveWatchlistEntry(ctx, tenantID, got); err != nil {
+			t.Fatalf("SaveWatchlistEntry update failed: %v", err)
+		}
+		if updated, _ := repo.GetWatchlistEntry(ctx, tenantID, "sdn-1"); updated.ListType != domain.WatchlistBlocklist {
+			t.Errorf("expected the entry moved to the blocklist, got %s", updated.ListType)
+		}
+
+		if err := repo.DeleteWatchlistEntry(ctx, tenantID, "sdn-1"); err != nil {
+			t.Fatalf("DeleteWatchlistEntry failed: %v", err)
+		}
+		if err := repo.DeleteWatchlistEntry(ctx, tenantID, "sdn-1"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("Outcomes", func(t *testing.T) {
+		base := time.Now().UTC().Truncate(time.Second)
+		for _, outcome := range []*domain.EvaluationOutcome{
+			{ID: "out-1", EvaluationID: "eval-o1", TxID: "tx-o1", DebtorID: "cust-1", CreditorID: "shop-1", Outcome: domain.OutcomeChallengePassed, OccurredAt: base.Add(-2 * time.Hour)},
+			{ID: "out-2", EvaluationID: "eval-o1", TxID: "tx-o1", DebtorID: "cust-1", CreditorID: "shop-1", Outcome: domain.OutcomeChargeback, Reason: "fraud", ReasonCode: "10.4", Label: domain.OutcomeLabelMissed, Amount: domain.MustDecimal("42.5"), ReportedBy: "ops@example.com", OccurredAt: base},
+			{ID: "out-3", EvaluationID: "eval-o2", TxID: "tx-o2", DebtorID: "shop-1", CreditorID: "cust-2", Outcome: domain.OutcomePaymentReturned, OccurredAt: base.Add(-time.Hour)},
+		} {
+			outcome.CreatedAt = base
+			if err := repo.SaveOutcome(ctx, tenantID, outcome); err != nil {
+				t.Fatalf("SaveOutcome failed: %v", err)
+			}
+		}
+		reimported := &domain.EvaluationOutcome{ID: "out-2", EvaluationID: "eval-o1", Outcome: domain.OutcomeChargeback, Amount: domain.MustDecimal("1"), OccurredAt: base, CreatedAt: base}
+		if err := repo.SaveOutcome(ctx, tenantID, reimported); err != nil {
+			t.Fatalf("expected re-saving an outcome ID to be ignored, got %v", err)
+		}
+		if err := repo.SaveOutcome(ctx, tenantID, &domain.EvaluationOutcome{ID: "out-x", EvaluationID: "eval-o1"}); err == nil {
+			t.Error("expected an error for an outcome without a type")
+		}
+
+		list := func(filter domain.OutcomeFilter) []string {
+			t.Helper()
+			outcomes, err := repo.ListOutcomes(ctx, tenantID, filter)
+			if err != nil {
+				t.Fatalf("ListOutcomes failed: %v", err)
+			}
+			ids := []string{}
+			for _, o := range outcomes {
+				ids = append(ids, o.ID)
+			}
+			return ids
+		}
+		if ids := list(domain.OutcomeFilter{EvaluationID: "eval-o1"}); len(ids) != 2 || ids[0] != "out-2" || ids[1] != "out-1" {
+			t.Errorf("expected the evaluation's outcomes latest first, got %v", ids)
+		}
+		if ids := list(domain.OutcomeFilter{PartyID: "shop-1"}); len(ids) != 3 {
+			t.Errorf("expected the party's outcomes as debtor and creditor, got %v", ids)
+		}
+		if ids := list(domain.OutcomeFilter{Outcome: domain.OutcomePaymentReturned}); len(ids) != 1 || ids[0] != "out-3" {
+			t.Errorf("expected the returned payment, got %v", ids)
+		}
+		if ids := list(domain.OutcomeFilter{Since: base.Add(-90 * time.Minute), Limit: 1}); len(ids) != 1 || ids[0] != "out-2" {
+			t.Errorf("expected the latest outcome since the cutoff, got %v", ids)
+		}
+		if ids := list(domain.OutcomeFilter{Label: domain.OutcomeLabelMissed}); len(ids) != 1 || ids[0] != "out-2" {
+			t.Errorf("expected the missed chargeback, got %v", ids)
+		}
+
+		outcomes, _ := repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{EvaluationID: "eval-o1", Outcome: domain.OutcomeChargeback})
+		if len(outcomes) != 1 || outcomes[0].Reason != "fraud" || outcomes[0].ReasonCode != "10.4" || outcomes[0].Amount.String() != "42.5" || outcomes[0].ReportedBy != "ops@example.com" || !outcomes[0].OccurredAt.Equal(base) {
+			t.Errorf("unexpected outcome: %+v", outcomes)
+		}
+		if others, _ := repo.ListOutcomes(ctx, "other-tenant", domain.OutcomeFilter{}); len(others) != 0 {
+			t.Errorf("expected no outcomes for another tenant, got %d", len(others))
+		}
+	})
+
+	t.Run("DeadLetters", func(t *testing.T) {
+		base := time.Now().UTC().Truncate(time.Second)
+		for _, dead := range []*domain.DeadLetter{
+			{ID: "dl-1", Topic: domain.TopicTransactionIngested, MessageID: "msg-1", Payload: "not json", Error: "invalid character", Attempts: 1, Poison: true, FailedAt: base.Add(-time.Minute)},
+			{ID: "dl-2", Topic: domain.LaneTopic(domain.LaneBatch), MessageID: "msg-2", Payload: `{"txId":"tx-2"}`, Error: "rule evaluation failed", Attempts: 4, FailedAt: base},
+		} {
+			if err := repo.SaveDeadLetter(ctx, tenantID, dead); err != nil {
+				t.Fatalf("SaveDeadLetter failed: %v", err)
+			}
+		}
+
+		dead, err := repo.GetDeadLetter(ctx, tenantID, "dl-1")
+		if err != nil {
+			t.Fatalf("GetDeadLetter failed: %v", err)
+		}
+		if dead.Payload != "not json" || !dead.Poison || dead.Attempts != 1 || dead.MessageID != "msg-1" || dead.ReplayedAt != nil {
+			t.Errorf("unexpected dead letter: %+v", dead)
+		}
+		if _, err := repo.GetDeadLetter(ctx, "other-tenant", "dl-1"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for another tenant, got %v", err)
+		}
+
+		if err := repo.MarkDeadLetterReplayed(ctx, tenantID, "dl-2", base); err != nil {
+			t.Fatalf("MarkDeadLetterReplayed failed: %v", err)
+		}
+		if err := repo.MarkDeadLetterReplayed(ctx, tenantID, "dl-2", base); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound replaying twice, got %v", err)
+		}
+
+		all, err := repo.ListDeadLetters(ctx, tenantID, domain.DeadLetterFilter{})
+		if err != nil {
+			t.Fatalf("ListDeadLetters failed: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != "dl-2" || all[0].ReplayedAt == nil || !all[0].ReplayedAt.Equal(base) {
+			t.Errorf("expected dead letters latest first with the replay recorded, got %+v", all)
+		}
+		pending, _ := repo.ListDeadLetters(ctx, tenantID, domain.DeadLetterFilter{Pending: true})
+		if len(pending) != 1 || pending[0].ID != "dl-1" {
+			t.Errorf("expected only the pending dead letter, got %+v", pending)
+		}
+	})
+
+	t.Run("MaintenanceWindows", func(t *testing.T) {
+		base := time.Now().UTC().Truncate(time.Second)
+		for _, window := range []*domain.MaintenanceWindow{
+			{ID: "mw-past", StartsAt: base.Add(-3 * time.Hour), EndsAt: base.Add(-2 * time.Hour), Reason: "old rerun", CreatedAt: base},
+			{ID: "mw-now", StartsAt: base.Add(-time.Hour), EndsAt: base.Add(time.Hour), Reason: "batch rerun", CreatedBy: "ops@example.com", CreatedAt: base},
+		} {
+			if err := repo.SaveMaintenanceWindow(ctx, tenantID, window); err != nil {
+				t.Fatalf("SaveMaintenanceWindow failed: %v", err)
+			}
+		}
+		if err := repo.SaveMaintenanceWindow(ctx, tenantID, &domain.MaintenanceWindow{ID: "mw-bad", StartsAt: base, EndsAt: base}); err == nil {
+			t.Error("expected an error for an empty window")
+		}
+
+		active, err := repo.ActiveMaintenanceWindow(ctx, tenantID, base)
+		if err != nil || active.ID != "mw-now" || active.CreatedBy != "ops@example.com" {
+			t.Fatalf("expected the current window, got %+v, %v", active, err)
+		}
+		if _, err := repo.ActiveMaintenanceWindow(ctx, "other-tenant", base); err != ErrNotFound {
+			t.Errorf("expected no window for another tenant, got %v", err)
+		}
+
+		if err := repo.CancelMaintenanceWindow(ctx, tenantID, "mw-now", "lead@example.com", base); err != nil {
+			t.Fatalf("CancelMaintenanceWindow failed: %v", err)
+		}
+		if err := repo.CancelMaintenanceWindow(ctx, tenantID, "mw-now", "lead@example.com", base); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound cancelling twice, got %v", err)
+		}
+		if _, err := repo.ActiveMaintenanceWindow(ctx, tenantID, base); err != ErrNotFound {
+			t.Errorf("expected no active window after the cancellation, got %v", err)
+		}
+		cancelled, err := repo.GetMaintenanceWindow(ctx, tenantID, "mw-now")
+		if err != nil || cancelled.CancelledBy != "lead@example.com" || cancelled.CancelledAt == nil || !cancelled.CancelledAt.Equal(base) {
+			t.Errorf("expected the cancellation to be recorded, got %+v, %v", cancelled, err)
+		}
+
+		windows, err := repo.ListMaintenanceWindows(ctx, tenantID)
+		if err != nil || len(windows) != 2 || windows[0].ID != "mw-now" {
+			t.Errorf("expected both windows, latest start first, got %+v, %v", windows, err)
+		}
+
+		alert := &domain.Alert{ID: "alert-mw", TxID: "tx-mw", CreatedAt: base.Add(-time.Hour), LastNotifiedAt: base.Add(-time.Hour), SuppressedByMaintenance: "mw-now"}
+		if err := repo.SaveAlert(ctx, tenantID, alert); err != nil {
+			t.Fatalf("SaveAlert failed: %v", err)
+		}
+		if stored, err := repo.GetAlert(ctx, tenantID, "alert-mw"); err != nil || stored.SuppressedByMaintenance != "mw-now" {
+			t.Errorf("expected the suppression to be stored, got %+v, %v", stored, err)
+		}
+		due, err := repo.ListDueAlerts(ctx, base, 5, 100)
+		if err != nil {
+			t.Fatalf("ListDueAlerts failed: %v", err)
+		}
+		for _, a := range due {
+			if a.ID == "alert-mw" {
+				t.Error("expected a suppressed alert never to be due")
+			}
+		}
+	})
+
+	t.Run("CounterpartyEdges", func(t *testing.T) {
+		base := time.Now().UTC().Truncate(time.Second)
+		for i, tx := range []*domain.Transaction{
+			{DebtorID: "edge-a", CreditorID: "edge-b", Amount: domain.MustDecimal("100"), Timestamp: base.Add(-time.Hour)},
+			{DebtorID: "edge-a", CreditorID: "edge-b", Amount: domain.MustDecimal("50"), Timestamp: base.Add(-2 * time.Hour)},
+			{DebtorID: "edge-c", CreditorID: "edge-a", Amount: domain.MustDecimal("10"), Timestamp: base},
+			{DebtorID: "edge-a", CreditorID: "edge-d", Amount: domain.MustDecimal("5"), Timestamp: base.Add(-48 * time.Hour)},
+		} {
+			if err := repo.RecordCounterpartyEdge(ctx, tenantID, tx); err != nil {
+				t.Fatalf("RecordCounterpartyEdge %d failed: %v", i, err)
+			}
+		}
+
+		edge, err := repo.GetCounterpartyEdge(ctx, tenantID, "edge-a", "edge-b")
+		if err != nil {
+			t.Fatalf("GetCounterpartyEdge failed: %v", err)
+		}
+		if edge.Count != 2 || edge.Amount != 150 || !edge.FirstSeen.Equal(base.Add(-2*time.Hour)) || !edge.LastSeen.Equal(base.Add(-time.Hour)) {
+			t.Errorf("expected 2 payments totalling 150 over the last 2 hours, got %+v", edge)
+		}
+		if _, err := repo.GetCounterpartyEdge(ctx, tenantID, "edge-b", "edge-a"); err != ErrNotFound {
+			t.Errorf("expected edges to be directed, got %v", err)
+		}
+		if _, err := repo.GetCounterpartyEdge(ctx, "tenant-002", "edge-a", "edge-b"); err != ErrNotFound {
+			t.Errorf("expected no edge for another tenant, got %v", err)
+		}
+
+		edges, err := repo.ListCounterpartyEdges(ctx, tenantID, "edge-a", base.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatalf("ListCounterpartyEdges failed: %v", err)
+		}
+		if len(edges) != 2 || edges[0].DebtorID != "edge-c" || edges[1].CreditorID != "edge-b" {
+			t.Errorf("expected the edges in and out seen in the last day, latest first, got %+v", edges)
+		}
+	})
+
+	t.Run("EntityBaselines", func(t *testing.T) {
+		baseline := &domain.EntityBaseline{
+			EntityID: "baseline-a",
+			Stats:    domain.BaselineStats{Count: 2, MeanAmount: 150, AmountVariance: 2500, Counterparties: map[string]float64{"baseline-b": 1}},
+			Prior:    domain.BaselineStats{Count: 1, MeanAmount: 100},
+			LastTxID: "tx-2",
+		}
+		baseline.Stats.Hours[9] = 1
+		if err := repo.SaveEntityBaseline(ctx, tenantID, baseline); err != nil {
+			t.Fatalf("SaveEntityBaseline failed: %v", err)
+		}
+		baseline.Stats.Count = 3
+		if err := repo.SaveEntityBaseline(ctx, tenantID, baseline); err != nil {
+			t.Fatalf("SaveEntityBaseline failed: %v", err)
+		}
+
+		got, err := repo.GetEntityBaseline(ctx, tenantID, "baseline-a")
+		if err != nil {
+			t.Fatalf("GetEntityBaseline failed: %v", err)
+		}
+		if got.Stats.Count != 3 || got.Stats.Hours[9] != 1 || got.Stats.Counterparties["baseline-b"] != 1 || got.Prior.MeanAmount != 100 || got.LastTxID != "tx-2" {
+			t.Errorf("expected the replaced baseline, got %+v", got)
+		}
+		if _, err := repo.GetEntityBaseline(ctx, "tenant-002", "baseline-a"); err != ErrNotFound {
+			t.Errorf("expected no baseline for another tenant, got %v", err)
+		}
+	})
+
+	t.Run("ReviewClaims", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		claim := func(by string, at time.Time) error {
+			return repo.ClaimReviewItem(ctx, tenantID, &domain.ReviewClaim{ItemID: "eval-claim", ClaimedBy: by, ClaimedAt: at, ExpiresAt: at.Add(30 * time.Minute)})
+		}
+
+		if err := claim("alice", now); err != nil {
+			t.Fatalf("ClaimReviewItem failed: %v", err)
+		}
+		if err := claim("bob", now.Add(time.Minute)); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for an item claimed by another analyst, got %v", err)
+		}
+		if err := claim("alice", now.Add(time.Minute)); err != nil {
+			t.Errorf("expected the claimant to renew the claim, got %v", err)
+		}
+		claims, err := repo.ListReviewClaims(ctx, tenantID, now.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("ListReviewClaims failed: %v", err)
+		}
+		if len(claims) != 1 || claims[0].ClaimedBy != "alice" || !claims[0].ExpiresAt.Equal(now.Add(31*time.Minute)) {
+			t.Errorf("expected alice's renewed claim, got %+v", claims)
+		}
+		if claims, _ := repo.ListReviewClaims(ctx, "tenant-002", now); len(claims) != 0 {
+			t.Errorf("expected no claims for another tenant, got %+v", claims)
+		}
+
+		if err := claim("bob", now.Add(time.Hour)); err != nil {
+			t.Errorf("expected an expired claim to be taken over, got %v", err)
+		}
+		if err := repo.ReleaseReviewItem(ctx, tenantID, "eval-claim", "alice"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound releasing another analyst's claim, got %v", err)
+		}
+		if err := repo.ReleaseReviewItem(ctx, tenantID, "eval-claim", "bob"); err != nil {
+			t.Fatalf("ReleaseReviewItem failed: %v", err)
+		}
+		if claims, _ := repo.ListReviewClaims(ctx, tenantID, now.Add(time.Hour)); len(claims) != 0 {
+			t.Errorf("expected no claims after release, got %+v", claims)
+		}
+	})
+
+	t.Run("PurgeTenantData", func(t *testing.T) {
+		sandbox := "tenant-sandbox"
+		now := time.Now().UTC().Truncate(time.Second)
+		old := now.Add(-48 * time.Hour)
+		for _, tx := range []*domain.Transaction{
+			{ID: "purge-old", DebtorID: "purge-a", CreditorID: "purge-b", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: old, CreatedAt: old},
+			{ID: "purge-new", DebtorID: "purge-a", CreditorID: "purge-b", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: now, CreatedAt: now},
+		} {
+			if err := repo.SaveTransaction(ctx, sandbox, tx); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+			if err := repo.RecordCounterpartyEdge(ctx, sandbox, tx); err != nil {
+				t.Fatalf("RecordCounterpartyEdge failed: %v", err)
+			}
+		}
+		if err := repo.SaveEvaluation(ctx, sandbox, &domain.Evaluation{ID: "purge-eval", TxID: "purge-old", Status: domain.StatusNoAlert, Timestamp: old}); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+		if err := repo.AppendEvaluationLog(ctx, sandbox, &domain.EvaluationLogRecord{Seq: 1, EvaluationID: "purge-eval", TxID: "purge-old", Payload: "{}", CreatedAt: old}); err != nil {
+			t.Fatalf("AppendEvaluationLog failed: %v", err)
+		}
+
+		tenants, err := repo.ListTenantIDs(ctx)
+		if err != nil {
+			t.Fatalf("ListTenantIDs failed: %v", err)
+		}
+		if !strings.Contains(strings.Join(tenants, ","), sandbox) {
+			t.Errorf("expected %s among the tenants, got %v", sandbox, tenants)
+		}
+
+		purged, err := repo.PurgeTenantData(ctx, sandbox, now.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatalf("PurgeTenantData failed: %v", err)
+		}
+		// A transaction, an evaluation with its rule and typology rows
+		// (none here) and the whole evaluation log; the edge is still fresh
+		if purged != 3 {
+			t.Errorf("expected 3 rows purged, got %d", purged)
+		}
+		if _, err := repo.GetTransaction(ctx, sandbox, "purge-old"); err != ErrNotFound {
+			t.Errorf("expected the old transaction purged, got %v", err)
+		}
+		if _, err := repo.GetTransaction(ctx, sandbox, "purge-new"); err != nil {
+			t.Errorf("expected the new transaction kept, got %v", err)
+		}
+		if _, err := repo.GetEvaluation(ctx, sandbox, "purge-eval"); err != ErrNotFound {
+			t.Errorf("expected the old evaluation purged, got %v", err)
+		}
+		if _, err := repo.GetLastEvaluationLog(ctx, sandbox); err != ErrNotFound {
+			t.Errorf("expected the expired evaluation log purged, got %v", err)
+		}
+		if _, err := repo.GetCounterpartyEdge(ctx, sandbox, "purge-a", "purge-b"); err != nil {
+			t.Errorf("expected the edge seen today kept, got %v", err)
+		}
+		if _, err := repo.GetTransaction(ctx, tenantID, "tx-001"); err != nil {
+			t.Errorf("expected other tenants untouched, got %v", err)
+		}
+	})
+
+	t.Run("RetentionPolicies", func(t *testing.T) {
+		if _, err := repo.GetRetentionPolicy(ctx, tenantID); err != ErrNotFound {
+			t.Fatalf("expected ErrNotFound before a policy is saved, got %v", err)
+		}
+		policy := &domain.RetentionPolicy{TransactionDays: 396, EvaluationDays: 2555, Archive: true, UpdatedBy: "ops"}
+		if err := repo.SaveRetentionPolicy(ctx, tenantID, policy); err != nil {
+			t.Fatalf("SaveRetentionPolicy failed: %v", err)
+		}
+		policy.AlertDays = 730
+		if err := repo.SaveRetentionPolicy(ctx, tenantID, policy); err != nil {
+			t.Fatalf("SaveRetentionPolicy failed: %v", err)
+		}
+
+		got, err := repo.GetRetentionPolicy(ctx, tenantID)
+		if err != nil {
+			t.Fatalf("GetRetentionPolicy failed: %v", err)
+		}
+		if got.TenantID != tenantID || got.TransactionDays != 396 || got.EvaluationDays != 2555 || got.AlertDays != 730 || !got.Archive || got.UpdatedBy != "ops" {
+			t.Errorf("expected the replaced policy, got %+v", got)
+		}
+		if _, err := repo.GetRetentionPolicy(ctx, "tenant-002"); err != ErrNotFound {
+			t.Errorf("expected no policy for another tenant, got %v", err)
+		}
+
+		if err := repo.DeleteRetentionPolicy(ctx, tenantID); err != nil {
+			t.Fatalf("DeleteRetentionPolicy failed: %v", err)
+		}
+		if err := repo.DeleteRetentionPolicy(ctx, tenantID); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("ExpireTenantData", func(t *testing.T) {
+		tenant := "tenant-retention"
+		now := time.Now().UTC().Truncate(time.Second)
+		old := now.Add(-48 * time.Hour)
+		for _, tx := range []*domain.Transaction{
+			{ID: "expire-old", DebtorID: "expire-a", CreditorID: "expire-b", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: old, CreatedAt: old},
+			{ID: "expire-new", DebtorID: "expire-a", CreditorID: "expire-c", Amount: domain.MustDecimal("10"), Currency: "USD", Timestamp: now, CreatedAt: now},
+		} {
+			if err := repo.SaveTransaction(ctx, tenant, tx); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+		}
+		if err := repo.SaveEvaluation(ctx, tenant, &domain.Evaluation{ID: "expire-eval", TxID: "expire-old", Status: domain.StatusNoAlert, Timestamp: old}); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+		before := now.Add(-24 * time.Hour)
+
+		// Nothing is deleted when the archive can't be completed
+		failing := &recordingArchive{closeErr: errors.New("disk full")}
+		if _, err := repo.ExpireTenantData(ctx, tenant, domain.RetentionTransactions, before, failing); err == nil {
+			t.Fatal("expected the failed archive to fail the expiry")
+		}
+		if _, err := repo.GetTransaction(ctx, tenant, "expire-old"); err != nil {
+			t.Fatalf("expected the transaction kept after a failed archive, got %v", err)
+		}
+
+		archive := &recordingArchive{}
+		expired, err := repo.ExpireTenantData(ctx, tenant, domain.RetentionTransactions, before, archive)
+		if err != nil {
+			t.Fatalf("ExpireTenantData failed: %v", err)
+		}
+		if expired != 1 || len(archive.rows) != 1 || archive.rows[0].table != "transactions" || archive.rows[0].row["id"] != "expire-old" || !archive.closed {
+			t.Errorf("expected the old transaction archived and expired, got %d rows: %+v", expired, archive.rows)
+		}
+		if _, err := repo.GetTransaction(ctx, tenant, "expire-new"); err != nil {
+			t.Errorf("expected the new transaction kept, got %v", err)
+		}
+		if _, err := repo.GetEvaluation(ctx, tenant, "expire-eval"); err != nil {
+			t.Errorf("expected evaluations kept by the transactions class, got %v", err)
+		}
+
+		if _, err := repo.ExpireTenantData(ctx, tenant, domain.RetentionEvaluations, before, nil); err != nil {
+			t.Fatalf("ExpireTenantData failed: %v", err)
+		}
+		if _, err := repo.GetEvaluation(ctx, tenant, "expire-eval"); err != ErrNotFound {
+			t.Errorf("expected the old evaluation expired, got %v", err)
+		}
+		if _, err := repo.ExpireTenantData(ctx, tenant, "configuration", before, nil); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for an unknown class, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := repo.GetTransaction(ctx, tenantID, "nonexistent")
+		if err != ErrNotFound {
+			t.Errorf("expected ErrNotFound, got: %v", err)
+		}
+
+		_, err = repo.GetEvaluation(ctx, tenantID, "nonexistent")
+		if err != ErrNotFound {
+			t.Errorf("expected ErrNotFound, got: %v", err)
+		}
+	})
+
+	t.Run("IndexAdvisor", func(t *testing.T) {
+		advisor := repo.(domain.IndexAdvisor)
+
+		// One busy debtor paying daily for 40 days, against a 1h velocity window
+		base := time.Now().UTC().Add(-40 * 24 * time.Hour)
+		for i := 0; i < 40; i++ {
+			tx := &domain.Transaction{
+				ID:         fmt.Sprintf("busy-%d", i),
+				Type:       "transfer",
+				DebtorID:   "busy-debtor",
+				CreditorID: fmt.Sprintf("creditor-%d", i),
+				Amount:     domain.MustDecimal("10"),
+				Currency:   "USD",
+				Timestamp:  base.Add(time.Duration(i) * 24 * time.Hour),
+				CreatedAt:  base,
+			}
+			if err := repo.SaveTransaction(ctx, "busy-tenant", tx); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+		}
+
+		advice := func(report *domain.IndexReport, name string) domain.IndexAdvice {
+			for _, a := range report.Indexes {
+				if a.Name == name {
+					return a
+				}
+			}
+			t.Fatalf("no advice for %s", name)
+			return domain.IndexAdvice{}
+		}
+
+		report, err := advisor.AdviseIndexes(ctx, domain.VelocityConfig{DefaultWindow: time.Hour})
+		if err != nil {
+			t.Fatalf("AdviseIndexes failed: %v", err)
+		}
+		debtor := advice(report, "idx_transactions_debtor_time")
+		if !debtor.Recommended || debtor.Exists || len(debtor.Tenants) != 1 || debtor.Tenants[0] != "busy-tenant" {
+			t.Errorf("expected debtor index recommended for busy-tenant, got %+v", debtor)
+		}
+		if creditor := advice(report, "idx_transactions_creditor_time"); creditor.Recommended {
+			t.Errorf("expected no creditor index for one transaction per creditor, got %+v", creditor)
+		}
+		if len(report.Statistics) == 0 {
+			t.Error("expected SQLite planner statistics")
+		}
+
+		// A window covering most of the history gains nothing from the index
+		report, err = advisor.AdviseIndexes(ctx, domain.VelocityConfig{
+			DefaultWindow: time.Hour,
+			TenantWindows: map[string]time.Duration{"busy-tenant": 30 * 24 * time.Hour},
+		})
+		if err != nil {
+			t.Fatalf("AdviseIndexes failed: %v", err)
+		}
+		if advice(report, "idx_transactions_debtor_time").Recommended {
+			t.Error("expected no recommendation for a window covering most of the history")
+		}
+
+		if err := advisor.CreateIndex(ctx, "idx_transactions_debtor_time"); err != nil {
+			t.Fatalf("CreateIndex failed: %v", err)
+		}
+		report, err = advisor.AdviseIndexes(ctx, domain.VelocityConfig{DefaultWindow: time.Hour})
+		if err != nil {
+			t.Fatalf("AdviseIndexes failed: %v", err)
+		}
+		if debtor := advice(report, "idx_transactions_debtor_time"); !debtor.Exists || debtor.Recommended {
+			t.Errorf("expected created index to exist, got %+v", debtor)
+		}
+
+		if err := advisor.CreateIndex(ctx, "idx_everything"); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound for unknown index, got: %v", err)
+		}
+	})
+}
+
+func TestIsolationAudit(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+	now := time.Now().UTC()
+
+	for _, rule := range []struct{ tenantID, id string }{{"*", "global-rule"}, {"tenant-a", "a-rule"}, {"tenant-b", "b-rule"}} {
+		if err := repo.SaveRuleConfig(ctx, rule.tenantID, &domain.RuleConfig{ID: rule.id, Name: rule.id, Version: "1.0.0", Expression: "true", Enabled: true}); err != nil {
+			t.Fatalf("SaveRuleConfig failed: %v", err)
+		}
+	}
+	for _, typology := range []struct{ tenantID, version string }{{"tenant-a", "1.0.0"}, {"tenant-a", "2.0.0"}} {
+		if err := repo.SaveTypology(ctx, typology.tenantID, &domain.Typology{ID: "mixed", Name: "mixed", Version: typology.version, Enabled: true, Rules: []domain.TypologyRuleWeight{
+			{RuleID: "global-rule", Weight: 0.5}, {RuleID: "a-rule", Weight: 0.3}, {RuleID: "b-rule", Weight: 0.2}, {RuleID: "missing-rule", Weight: 0.1},
+		}}); err != nil {
+			t.Fatalf("SaveTypology failed: %v", err)
+		}
+	}
+	repo.SaveTransaction(ctx, "tenant-b", &domain.Transaction{ID: "tx-b", Type: "transfer", DebtorID: "cust-b", CreditorID: "shop-b", Amount: domain.MustDecimal("1"), Currency: "USD", Timestamp: now})
+	repo.SaveEvaluation(ctx, "tenant-b", &domain.Evaluation{ID: "eval-b", TxID: "tx-b", Status: domain.StatusNoAlert, Timestamp: now})
+	repo.SaveEvaluation(ctx, "tenant-a", &domain.Evaluation{ID: "eval-a", TxID: "tx-b", Status: domain.StatusNoAlert, Timestamp: now})
+	repo.SaveOutcome(ctx, "tenant-a", &domain.EvaluationOutcome{ID: "out-a", EvaluationID: "eval-b", TxID: "tx-b", Outcome: domain.OutcomeChargeback, OccurredAt: now, CreatedAt: now})
+
+	auditor := repo.(domain.IsolationAuditor)
+	report, err := auditor.AuditIsolation(ctx)
+	if err != nil {
+		t.Fatalf("AuditIsolation failed: %v", err)
+	}
+	got := make(map[string]domain.IsolationViolation)
+	for _, v := range report.Violations {
+		got[v.Check] = v
+	}
+	if len(report.Violations) != 3 {
+		t.Fatalf("expected 3 violations, got %+v", report.Violations)
+	}
+	if v := got[domain.IsolationTypologyRule]; v.ID != "mixed" || v.Ref != "b-rule" || v.RefTenantID != "tenant-b" || !v.Repairable {
+		t.Errorf("unexpected typology violation: %+v", v)
+	}
+	if v := got[domain.IsolationEvaluationTransaction]; v.TenantID != "tenant-a" || v.ID != "eval-a" || v.RefTenantID != "tenant-b" || v.Repairable {
+		t.Errorf("unexpected evaluation violation: %+v", v)
+	}
+	if v := got[domain.IsolationOutcomeEvaluation]; v.ID != "out-a" || v.Ref != "eval-b" {
+		t.Errorf("unexpected outcome violation: %+v", v)
+	}
+
+	report, err = auditor.RepairIsolation(ctx)
+	if err != nil {
+		t.Fatalf("RepairIsolation failed: %v", err)
+	}
+	if report.Repaired != 2 {
+		t.Errorf("expected the typology and outcome to be repaired, got %d", report.Repaired)
+	}
+	typologies, err := repo.ListAllTypologies(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTypologies failed: %v", err)
+	}
+	for _, typology := range typologies {
+		if len(typology.Rules) != 3 {
+			t.Errorf("version %s: expected the foreign rule to be removed, got %+v", typology.Version, typology.Rules)
+		}
+	}
+	report, _ = auditor.AuditIsolation(ctx)
+	if len(report.Violations) != 1 || report.Violations[0].Check != domain.IsolationEvaluationTransaction {
+		t.Errorf("expected only the evaluation violation to remain, got %+v", report.Violations)
+	}
+
+	// Job IDs are unique across tenants, so another tenant's job is left alone
+	if err := repo.SaveJob(ctx, "tenant-a", &domain.Job{ID: "job-1", Type: domain.JobTypeBatchFile, Status: domain.JobStatusPending, CreatedAt: now}); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+	if err := repo.SaveJob(ctx, "tenant-b", &domain.Job{ID: "job-1", Type: domain.JobTypeBatchFile, Status: domain.JobStatusFailed, CreatedAt: now}); err == nil {
+		t.Error("expected an error saving a job under another tenant's ID")
+	}
+	if job, _ := repo.GetJob(ctx, "tenant-a", "job-1"); job == nil || job.Status != domain.JobStatusPending {
+		t.Errorf("expected tenant-a's job to be unchanged, got %+v", job)
+	}
+}
+
+func TestEvaluationProjectionBackfill(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*SQLRepository)
+
+	now := time.Now().UTC()
+	for i := 0; i < projectionBackfillPage+1; i++ {
+		eval := &domain.Evaluation{ID: fmt.Sprintf("eval-%04d", i), TxID: "tx-1", Status: domain.StatusAlert, Timestamp: now,
+			RuleResults: []domain.RuleResult{{RuleID: "high-value", SubRuleRef: domain.RuleOutcomeFail}}}
+		if err := repo.SaveEvaluation(ctx, "tenant-a", eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+
+	// Evaluations stored before the projections existed
+	for _, table := range []string{"evaluation_rule_results", "evaluation_typology_results"} {
+		if _, err := r.db.Exec(`DELETE FROM ` + table); err != nil {
+			t.Fatalf("failed to clear %s: %v", table, err)
+		}
+	}
+	if err := r.backfillEvaluationProjections(); err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	evals, err := repo.ListEvaluations(ctx, "tenant-a", domain.EvaluationFilter{RuleID: "high-value", Limit: 1000})
+	if err != nil || len(evals) != projectionBackfillPage+1 {
+		t.Errorf("expected every evaluation to be projected, got %d, %v", len(evals), err)
+	}
+
+	// Once projected, it is a no-op
+	if err := r.backfillEvaluationProjections(); err != nil {
+		t.Errorf("second backfill failed: %v", err)
+	}
+}
+
+func TestCounterpartyEdgeBackfill(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*SQLRepository)
+
+	now := time.Now().UTC()
+	for _, tx := range []*domain.Transaction{
+		{ID: "tx-1", DebtorID: "alice", CreditorID: "bob", Amount: domain.MustDecimal("100"), Timestamp: now.Add(-time.Hour)},
+		{ID: "tx-2", DebtorID: "alice", CreditorID: "bob", Amount: domain.MustDecimal("40"), Timestamp: now},
+		{ID: "tx-3", DebtorID: "bob", CreditorID: "alice", Amount: domain.MustDecimal("40"), Timestamp: now, ReversalOf: "tx-2"},
+		{ID: "tx-4", DebtorID: "bob", CreditorID: "bob", Amount: domain.MustDecimal("5"), Timestamp: now},
+	} {
+		if err := repo.SaveTransaction(ctx, "tenant-a", tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+
+	// Transactions stored before the counterparty network existed
+	if err := r.backfillCounterpartyEdges(); err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	edges, err := repo.ListCounterpartyEdges(ctx, "tenant-a", "bob", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("ListCounterpartyEdges failed: %v", err)
+	}
+	if len(edges) != 1 || edges[0].DebtorID != "alice" || edges[0].Count != 2 || edges[0].Amount != 140 {
+		t.Errorf("expected one edge from alice to bob, got %+v", edges)
+	}
+
+	// Once built, it is a no-op
+	if err := r.backfillCounterpartyEdges(); err != nil {
+		t.Errorf("second backfill failed: %v", err)
+	}
+	if edge, err := repo.GetCounterpartyEdge(ctx, "tenant-a", "alice", "bob"); err != nil || edge.Count != 2 {
+		t.Errorf("expected the edge to be built once, got %+v, %v", edge, err)
+	}
+}
+
+func TestTransactionKeyMigration(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(domain.RepositoryConfig{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+	r := repo.(*SQLRepository)
+
+	// A transactions table from before IDs were scoped to tenants
+	for _, stmt := range []string{
+		`DROP TABLE transactions`,
+		`CREATE TABLE transactions (
+			id TEXT PRIMARY KEY, tenant_id TEXT NOT NULL, type TEXT NOT NULL,
+			debtor_id TEXT NOT NULL, debtor_account_id TEXT NOT NULL,
+			creditor_id TEXT NOT NULL, creditor_account_id TEXT NOT NULL,
+			amount REAL NOT NULL, currency TEXT NOT NULL,
+			timestamp TIMESTAMP NOT NULL, created_at TIMESTAMP NOT NULL,
+			metadata TEXT, original_message BLOB
+		)`,
+		`CREATE INDEX idx_transactions_tenant ON transactions(tenant_id)`,
+		`INSERT INTO transactions VALUES ('tx-1', 'tenant-a', 'transfer', 'alice', 'acc-1', 'bob', 'acc-2', 12.5, 'USD', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, '{}', NULL)`,
+	} {
+		if _, err := r.db.Exec(stmt); err != nil {
+			t.Fatalf("failed to set up the old table: %v", err)
+		}
+	}
+
+	if err := r.migrate(); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if tx, err := repo.GetTransaction(ctx, "tenant-a", "tx-1"); err != nil || tx.DebtorID != "alice" || tx.Amount.Float64() != 12.5 {
+		t.Errorf("expected the stored transaction to be kept, got %+v, %v", tx, err)
+	}
+	tx := &domain.Transaction{ID: "tx-1", Type: "transfer", Amount: domain.MustDecimal("1"), Timestamp: time.Now().UTC(), CreatedAt: time.Now().UTC()}
+	if err := repo.SaveTransaction(ctx, "tenant-b", tx); err != nil {
+		t.Errorf("expected another tenant to reuse the ID after the migration, got %v", err)
+	}
+	if err := repo.SaveTransaction(ctx, "tenant-a", tx); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for the tenant's own ID, got %v", err)
+	}
+
+	// Once rekeyed, it is a no-op
+	if err := r.migrate(); err != nil {
+		t.Errorf("second migrate failed: %v", err)
+	}
+}
+
+func TestUnsupportedDriver(t *testing.T) {
+	cfg := domain.RepositoryConfig{
+		Driver: "mysql",
+	}
+
+	_, err := New(cfg)
+	if err == nil {
+		t.Error("expected error for unsupported driver")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	repo := &SQLRepository{driver: "postgres"}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"SELECT * FROM t WHERE id = ?", "SELECT * FROM t WHERE id = $1"},
+		{"INSERT INTO t (a, b) VALUES (?, ?)", "INSERT INTO t (a, b) VALUES ($1, $2)"},
+		{"SELECT * FROM t", "SELECT * FROM t"},
+	}
+
+	for _, tt := range tests {
+		result := repo.rebind(tt.input)
+		if result != tt.expected {
+			t.Errorf("rebind(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// recordingArchive keeps archived rows in memory.
+type recordingArchive struct {
+	rows []struct {
+		table string
+		row   map[string]any
+	}
+	closed   bool
+	closeErr error
+}
+
+func (a *recordingArchive) ArchiveRow(table string, row map[string]any) error {
+	a.rows = append(a.rows, struct {
+		table string
+		row   map[string]any
+	}{table, row})
+	return nil
+}
+
+func (a *recordingArchive) Close() error {
+	a.closed = a.closeErr == nil
+	return a.closeErr
 }
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// retentionTables lists the tables of each retention class with the column
+// that dates each row.
+var retentionTables = map[string][]struct {
+	table  string
+	column string
+}{
+	domain.RetentionTransactions: {
+		{"transactions", "created_at"},
+		{"counterparty_edges", "last_seen"},
+	},
+	domain.RetentionEvaluations: {
+		{"evaluations", "timestamp"},
+		{"evaluation_rule_results", "timestamp"},
+		{"evaluation_typology_results", "timestamp"},
+		{"evaluation_outcomes", "created_at"},
+		{"activation_samples", "created_at"},
+	},
+	domain.RetentionAlerts: {
+		{"alerts", "created_at"},
+		{"alert_events", "at"},
+	},
+}
+
+// SaveRetentionPolicy upserts the tenant's retention policy.
+func (r *SQLRepository) SaveRetentionPolicy(ctx context.Context, tenantID string, policy *domain.RetentionPolicy) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	archive := 0
+	if policy.Archive {
+		archive = 1
+	}
+
+	query := `
+		INSERT INTO retention_policies (
+			tenant_id, transaction_days, evaluation_days, alert_days, archive, updated_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id) DO UPDATE SET
+			transaction_days = excluded.transaction_days,
+			evaluation_days = excluded.evaluation_days,
+			alert_days = excluded.alert_days,
+			archive = excluded.archive,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := policy.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, policy.TransactionDays, policy.EvaluationDays, policy.AlertDays, archive, policy.UpdatedBy, updatedAt.UTC(),
+	)
+	return err
+}
+
+// GetRetentionPolicy retrieves the tenant's retention policy, or ErrNotFound
+// if it uses the defaults.
+func (r *SQLRepository) GetRetentionPolicy(ctx context.Context, tenantID string) (*domain.RetentionPolicy, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, transaction_days, evaluation_days, alert_days, archive, updated_by, updated_at
+		FROM retention_policies
+		WHERE tenant_id = ?
+	`
+
+	var policy domain.RetentionPolicy
+	var archive int
+	var updatedBy sql.NullString
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID).Scan(
+		&policy.TenantID, &policy.TransactionDays, &policy.EvaluationDays, &policy.AlertDays, &archive, &updatedBy, &policy.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.Archive = archive == 1
+	policy.UpdatedBy = updatedBy.String
+	return &policy, nil
+}
+
+// DeleteRetentionPolicy deletes the tenant's retention policy, so it uses
+// the defaults again.
+func (r *SQLRepository) DeleteRetentionPolicy(ctx context.Context, tenantID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(`DELETE FROM retention_policies WHERE tenant_id = ?`), tenantID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ExpireTenantData deletes the tenant's rows of a retention class dated
+// before the given time, in one database transaction. The hash-chained
+// evaluation log expires with the evaluations, but only whole, once its
+// newest record is before the given time, as in PurgeTenantData.
+func (r *SQLRepository) ExpireTenantData(ctx context.Context, tenantID string, class string, before time.Time, archive domain.RetentionArchive) (int64, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	tables, ok := retentionTables[class]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown retention class %q", ErrInvalidInput, class)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	before = before.UTC()
+	var expired int64
+	expire := func(table, where string, args ...any) error {
+		if archive != nil {
+			if err := r.archiveRows(ctx, tx, table, where, archive, args...); err != nil {
+				return fmt.Errorf("failed to archive %s: %w", table, err)
+			}
+		}
+		result, err := tx.ExecContext(ctx, r.rebind(`DELETE FROM `+table+where), args...)
+		if err != nil {
+			return fmt.Errorf("failed to expire %s: %w", table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		expired += n
+		return nil
+	}
+
+	for _, t := range tables {
+		if err := expire(t.table, ` WHERE tenant_id = ? AND `+t.column+` < ?`, tenantID, before); err != nil {
+			return 0, err
+		}
+	}
+
+	if class == domain.RetentionEvaluations {
+		where := `
+			WHERE tenant_id = ? AND NOT EXISTS (
+				SELECT 1 FROM evaluation_log WHERE tenant_id = ? AND created_at >= ?
+			)
+		`
+		if err := expire("evaluation_log", where, tenantID, tenantID, before); err != nil {
+			return 0, err
+		}
+	}
+
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			return 0, fmt.Errorf("failed to close archive: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
+
+// archiveRows passes every row of the query to the archive, by column name.
+func (r *SQLRepository) archiveRows(ctx context.Context, tx *sql.Tx, table, where string, archive domain.RetentionArchive, args ...any) error {
+	rows, err := tx.QueryContext(ctx, r.rebind(`SELECT * FROM `+table+where), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		if err := archive.ArchiveRow(table, row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Compact rewrites a SQLite database without the space of deleted rows and
+// truncates its write-ahead log. PostgreSQL reuses the space through
+// autovacuum, so there it does nothing.
+func (r *SQLRepository) Compact(ctx context.Context) error {
+	if r.driver == "postgres" {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
+
+var _ domain.Compactor = (*SQLRepository)(nil)
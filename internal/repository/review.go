@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// ClaimReviewItem claims a review queue item for claim.ClaimedBy until
+// claim.ExpiresAt. The same analyst claiming again renews the lease.
+// Returns ErrNotFound if another analyst's claim is active at claim.ClaimedAt.
+func (r *SQLRepository) ClaimReviewItem(ctx context.Context, tenantID string, claim *domain.ReviewClaim) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if claim.ItemID == "" || claim.ClaimedBy == "" {
+		return fmt.Errorf("%w: item ID and claimant are required", ErrInvalidInput)
+	}
+
+	query := `
+		INSERT INTO review_claims (tenant_id, item_id, claimed_by, claimed_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, item_id) DO UPDATE SET
+			claimed_by = excluded.claimed_by,
+			claimed_at = excluded.claimed_at,
+			expires_at = excluded.expires_at
+		WHERE review_claims.claimed_by = excluded.claimed_by
+			OR review_claims.expires_at <= excluded.claimed_at
+	`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, claim.ItemID, claim.ClaimedBy, claim.ClaimedAt.UTC(), claim.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReleaseReviewItem drops by's claim on a review queue item.
+// Returns ErrNotFound if by holds no claim on it.
+func (r *SQLRepository) ReleaseReviewItem(ctx context.Context, tenantID string, itemID string, by string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `DELETE FROM review_claims WHERE tenant_id = ? AND item_id = ? AND claimed_by = ?`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, itemID, by)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListReviewClaims retrieves the tenant's review queue claims active at t.
+func (r *SQLRepository) ListReviewClaims(ctx context.Context, tenantID string, at time.Time) ([]*domain.ReviewClaim, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, item_id, claimed_by, claimed_at, expires_at
+		FROM review_claims WHERE tenant_id = ? AND expires_at > ?
+		ORDER BY item_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), tenantID, at.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []*domain.ReviewClaim
+	for rows.Next() {
+		var claim domain.ReviewClaim
+		if err := rows.Scan(&claim.TenantID, &claim.ItemID, &claim.ClaimedBy, &claim.ClaimedAt, &claim.ExpiresAt); err != nil {
+			return nil, err
+		}
+		claims = append(claims, &claim)
+	}
+	return claims, rows.Err()
+}
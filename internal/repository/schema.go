@@ -5,7 +5,7 @@ package repository
 
 const schemaTransactions = `
 CREATE TABLE IF NOT EXISTS transactions (
-    id TEXT PRIMARY KEY,
+    id TEXT NOT NULL,
     tenant_id TEXT NOT NULL,
     type TEXT NOT NULL,
     debtor_id TEXT NOT NULL,
@@ -13,11 +13,17 @@ CREATE TABLE IF NOT EXISTS transactions (
     creditor_id TEXT NOT NULL,
     creditor_account_id TEXT NOT NULL,
     amount REAL NOT NULL,
+    amount_decimal TEXT,
     currency TEXT NOT NULL,
     timestamp TIMESTAMP NOT NULL,
     created_at TIMESTAMP NOT NULL,
     metadata TEXT,
-    original_message BLOB
+    components TEXT,
+    reversal_of TEXT,
+    part_of_batch TEXT,
+    related_to TEXT,
+    original_message BLOB,
+    PRIMARY KEY (tenant_id, id)
 );
 
 CREATE INDEX IF NOT EXISTS idx_transactions_tenant ON transactions(tenant_id);
@@ -33,10 +39,15 @@ CREATE TABLE IF NOT EXISTS rule_configs (
     name TEXT NOT NULL,
     description TEXT,
     version TEXT NOT NULL,
+    owner TEXT NOT NULL DEFAULT '',
+    source_file TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
     expression TEXT NOT NULL,
     bands TEXT NOT NULL,
     weight REAL NOT NULL DEFAULT 1.0,
     enabled INTEGER NOT NULL DEFAULT 1,
+    sample_rate REAL NOT NULL DEFAULT 0,
+    shadow INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
     PRIMARY KEY (id, tenant_id, version)
@@ -65,6 +76,36 @@ CREATE INDEX IF NOT EXISTS idx_evaluations_status ON evaluations(tenant_id, stat
 CREATE INDEX IF NOT EXISTS idx_evaluations_timestamp ON evaluations(tenant_id, timestamp);
 `
 
+// schemaEvaluationProjections projects each evaluation's rule and typology
+// results into rows, so evaluations can be searched by what contributed to
+// them. They are written with the evaluation and never updated.
+const schemaEvaluationProjections = `
+CREATE TABLE IF NOT EXISTS evaluation_rule_results (
+    tenant_id TEXT NOT NULL,
+    evaluation_id TEXT NOT NULL,
+    rule_id TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    score REAL NOT NULL,
+    shadow INTEGER NOT NULL DEFAULT 0,
+    timestamp TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, evaluation_id, rule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_rule_results_rule ON evaluation_rule_results(tenant_id, rule_id, outcome, timestamp);
+
+CREATE TABLE IF NOT EXISTS evaluation_typology_results (
+    tenant_id TEXT NOT NULL,
+    evaluation_id TEXT NOT NULL,
+    typology_id TEXT NOT NULL,
+    score REAL NOT NULL,
+    triggered INTEGER NOT NULL DEFAULT 0,
+    timestamp TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, evaluation_id, typology_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_typology_results_typology ON evaluation_typology_results(tenant_id, typology_id, score);
+`
+
 // schemaTypologies defines the typologies table.
 // Typologies group multiple rules with weights to calculate composite risk scores.
 // Compatible with both SQLite and PostgreSQL.
@@ -77,6 +118,10 @@ CREATE TABLE IF NOT EXISTS typologies (
     version TEXT NOT NULL,
     rules TEXT NOT NULL,
     alert_threshold REAL NOT NULL DEFAULT 0.6,
+    min_rules_fired INTEGER NOT NULL DEFAULT 0,
+    min_coverage REAL NOT NULL DEFAULT 0,
+    typology_weights TEXT NOT NULL DEFAULT '',
+    trigger_condition TEXT NOT NULL DEFAULT '',
     enabled INTEGER NOT NULL DEFAULT 1,
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
@@ -88,12 +133,476 @@ CREATE INDEX IF NOT EXISTS idx_typologies_enabled ON typologies(tenant_id, enabl
 CREATE INDEX IF NOT EXISTS idx_typologies_name ON typologies(tenant_id, name);
 `
 
+// schemaPartyKYC stores KYC attributes per party, keyed by entity ID.
+const schemaPartyKYC = `
+CREATE TABLE IF NOT EXISTS party_kyc (
+    entity_id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    risk_rating TEXT NOT NULL,
+    pep INTEGER NOT NULL DEFAULT 0,
+    onboarded_at TIMESTAMP,
+    residence_country TEXT,
+    segment TEXT,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_party_kyc_risk ON party_kyc(tenant_id, risk_rating);
+`
+
+// schemaCorridorRisk stores per-tenant origin→destination country risk overrides.
+const schemaCorridorRisk = `
+CREATE TABLE IF NOT EXISTS corridor_risk (
+    tenant_id TEXT NOT NULL,
+    origin TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    risk REAL NOT NULL,
+    note TEXT,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, origin, destination)
+);
+`
+
+// schemaWatchlist stores the parties on each tenant's sanctions, block and
+// watch lists. name_key holds the normalized name for matching.
+const schemaWatchlist = `
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    list_type TEXT NOT NULL,
+    party_id TEXT NOT NULL DEFAULT '',
+    account_id TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    name_key TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    note TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_party ON watchlist_entries(tenant_id, party_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_account ON watchlist_entries(tenant_id, account_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_name ON watchlist_entries(tenant_id, name_key);
+`
+
+// schemaJobs tracks background jobs and their progress.
+const schemaJobs = `
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    params TEXT,
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant ON jobs(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+
+CREATE TABLE IF NOT EXISTS job_files (
+    tenant_id TEXT NOT NULL,
+    job_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, job_id, kind)
+);
+`
+
+// schemaEvaluationLog is the append-only, hash-chained evaluation log.
+// Rows are only ever inserted; the (tenant_id, seq) key rejects forks.
+const schemaEvaluationLog = `
+CREATE TABLE IF NOT EXISTS evaluation_log (
+    tenant_id TEXT NOT NULL,
+    seq INTEGER NOT NULL,
+    evaluation_id TEXT NOT NULL,
+    tx_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_log_eval ON evaluation_log(tenant_id, evaluation_id);
+`
+
+// schemaFeatureFlags stores feature flag overrides. tenant_id "*" is install-wide.
+const schemaFeatureFlags = `
+CREATE TABLE IF NOT EXISTS feature_flags (
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    enabled INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, name)
+);
+`
+
+// schemaAlerts tracks acknowledgment, escalation and disposition of ALRT
+// evaluations, with each alert's audit history in alert_events.
+// The alert ID is the evaluation ID.
+const schemaAlerts = `
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    tx_id TEXT NOT NULL,
+    score REAL NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    assignee TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP,
+    acked_at TIMESTAMP,
+    acked_by TEXT,
+    ack_note TEXT,
+    escalation_level INTEGER NOT NULL DEFAULT 0,
+    last_notified_at TIMESTAMP NOT NULL,
+    suppressed_by_maintenance TEXT,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_tenant_created ON alerts(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alerts_due ON alerts(acked_at, last_notified_at);
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    alert_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT,
+    from_value TEXT,
+    to_value TEXT,
+    note TEXT,
+    at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_alert ON alert_events(tenant_id, alert_id, at);
+`
+
+// schemaActivationSamples stores sampled rule activations for debugging.
+const schemaActivationSamples = `
+CREATE TABLE IF NOT EXISTS activation_samples (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    rule_id TEXT NOT NULL,
+    rule_version TEXT NOT NULL,
+    tx_id TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    score REAL NOT NULL,
+    activation TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activation_samples_rule ON activation_samples(tenant_id, rule_id, created_at);
+`
+
+// schemaOutcomes stores what happened after decisions, as reported by
+// integrators.
+const schemaOutcomes = `
+CREATE TABLE IF NOT EXISTS evaluation_outcomes (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    evaluation_id TEXT NOT NULL,
+    tx_id TEXT NOT NULL,
+    debtor_id TEXT NOT NULL,
+    creditor_id TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    reason TEXT,
+    reason_code TEXT,
+    label TEXT,
+    amount REAL NOT NULL DEFAULT 0,
+    amount_decimal TEXT,
+    reported_by TEXT,
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_outcomes_evaluation ON evaluation_outcomes(tenant_id, evaluation_id);
+CREATE INDEX IF NOT EXISTS idx_evaluation_outcomes_debtor ON evaluation_outcomes(tenant_id, debtor_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_evaluation_outcomes_creditor ON evaluation_outcomes(tenant_id, creditor_id, occurred_at);
+`
+
+// schemaWebhooks stores tenant webhook endpoints and the delivery log.
+// Pending deliveries double as the retry queue.
+const schemaWebhooks = `
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    feed TEXT NOT NULL DEFAULT 'alerts',
+    filter TEXT,
+    batch_size INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    webhook_id TEXT NOT NULL,
+    evaluation_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(tenant_id, webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+`
+
+// schemaDeadLetters stores async messages the worker gave up on, for
+// inspection and replay.
+const schemaDeadLetters = `
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    poison INTEGER NOT NULL DEFAULT 0,
+    failed_at TIMESTAMP NOT NULL,
+    replayed_at TIMESTAMP,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_failed ON dead_letters(tenant_id, failed_at);
+`
+
+// schemaMaintenanceWindows stores per-tenant maintenance windows, including
+// cancelled ones, which are kept as an audit record.
+const schemaMaintenanceWindows = `
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL,
+    cancelled_by TEXT,
+    cancelled_at TIMESTAMP,
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_ends ON maintenance_windows(tenant_id, ends_at);
+`
+
+// schemaCounterpartyEdges stores each tenant's counterparty network: one
+// row per debtor and creditor pair that transacted.
+const schemaCounterpartyEdges = `
+CREATE TABLE IF NOT EXISTS counterparty_edges (
+    tenant_id TEXT NOT NULL,
+    debtor_id TEXT NOT NULL,
+    creditor_id TEXT NOT NULL,
+    tx_count INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    first_seen TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, debtor_id, creditor_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_counterparty_edges_creditor ON counterparty_edges(tenant_id, creditor_id);
+`
+
+// schemaScoringConfigs stores the tenants' own decision policies.
+const schemaScoringConfigs = `
+CREATE TABLE IF NOT EXISTS scoring_configs (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    alert_threshold REAL NOT NULL,
+    weighted_scoring INTEGER NOT NULL,
+    critical_fail TEXT NOT NULL,
+    ml_weight REAL NOT NULL DEFAULT 0,
+    ml_blend TEXT NOT NULL DEFAULT '',
+    updated_by TEXT,
+    updated_at TIMESTAMP NOT NULL
+);
+`
+
+// schemaAuditLog is the append-only, hash-chained log of configuration
+// changes. Like the evaluation log, the (tenant_id, seq) key rejects forks.
+const schemaAuditLog = `
+CREATE TABLE IF NOT EXISTS audit_log (
+    tenant_id TEXT NOT NULL,
+    seq INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    resource TEXT NOT NULL,
+    resource_id TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    client_ip TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    before_json TEXT NOT NULL,
+    after_json TEXT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(tenant_id, resource, resource_id);
+`
+
+// schemaRetentionPolicies stores the tenants' own retention policies.
+const schemaRetentionPolicies = `
+CREATE TABLE IF NOT EXISTS retention_policies (
+    tenant_id TEXT NOT NULL PRIMARY KEY,
+    transaction_days INTEGER NOT NULL,
+    evaluation_days INTEGER NOT NULL,
+    alert_days INTEGER NOT NULL,
+    archive INTEGER NOT NULL,
+    updated_by TEXT,
+    updated_at TIMESTAMP NOT NULL
+);
+`
+
+// schemaNamedLists stores the tenants' named lists for rule logic. Values
+// are a JSON array; lists are read whole.
+const schemaNamedLists = `
+CREATE TABLE IF NOT EXISTS named_lists (
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    list_values TEXT NOT NULL,
+    updated_by TEXT,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, name)
+);
+`
+
+// schemaEntityBaselines stores each entity's behavioral baseline as a
+// debtor. The statistics are JSON; a baseline is read and written whole.
+const schemaEntityBaselines = `
+CREATE TABLE IF NOT EXISTS entity_baselines (
+    tenant_id TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    stats TEXT NOT NULL,
+    prior_stats TEXT NOT NULL,
+    last_tx_id TEXT,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, entity_id)
+);
+`
+
+// schemaAccountBalances stores each account's balance as last fed or
+// reported, moved by the account's stored transactions since.
+const schemaAccountBalances = `
+CREATE TABLE IF NOT EXISTS account_balances (
+    tenant_id TEXT NOT NULL,
+    account_id TEXT NOT NULL,
+    balance REAL NOT NULL,
+    currency TEXT,
+    prior_balance REAL NOT NULL,
+    balance_decimal TEXT,
+    prior_balance_decimal TEXT,
+    last_tx_id TEXT,
+    last_credit_tx_id TEXT,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, account_id)
+);
+`
+
+// schemaReviewClaims stores who is working which review queue item. A row
+// whose lease expired is free to be claimed again.
+const schemaReviewClaims = `
+CREATE TABLE IF NOT EXISTS review_claims (
+    tenant_id TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    claimed_by TEXT NOT NULL,
+    claimed_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, item_id)
+);
+`
+
+// columnMigration adds a column to a table created by an earlier release.
+// CREATE TABLE IF NOT EXISTS never alters existing tables, so columns added
+// after the initial schema must also be listed here.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists columns added after a table's first release, in order.
+var columnMigrations = []columnMigration{
+	{table: "transactions", column: "components", definition: "TEXT"},
+	{table: "jobs", column: "params", definition: "TEXT"},
+	{table: "typologies", column: "min_rules_fired", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "typologies", column: "min_coverage", definition: "REAL NOT NULL DEFAULT 0"},
+	{table: "rule_configs", column: "sample_rate", definition: "REAL NOT NULL DEFAULT 0"},
+	{table: "alerts", column: "status", definition: "TEXT NOT NULL DEFAULT 'open'"},
+	{table: "alerts", column: "assignee", definition: "TEXT"},
+	{table: "alerts", column: "updated_at", definition: "TIMESTAMP"},
+	{table: "rule_configs", column: "shadow", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "webhooks", column: "feed", definition: "TEXT NOT NULL DEFAULT 'alerts'"},
+	{table: "webhooks", column: "filter", definition: "TEXT"},
+	{table: "webhooks", column: "batch_size", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "evaluation_outcomes", column: "reason_code", definition: "TEXT"},
+	{table: "evaluation_outcomes", column: "label", definition: "TEXT"},
+	{table: "rule_configs", column: "language", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "alerts", column: "suppressed_by_maintenance", definition: "TEXT"},
+	{table: "transactions", column: "reversal_of", definition: "TEXT"},
+	{table: "transactions", column: "part_of_batch", definition: "TEXT"},
+	{table: "transactions", column: "related_to", definition: "TEXT"},
+	{table: "party_kyc", column: "segment", definition: "TEXT"},
+	{table: "rule_configs", column: "owner", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "transactions", column: "amount_decimal", definition: "TEXT"},
+	{table: "scoring_configs", column: "ml_weight", definition: "REAL NOT NULL DEFAULT 0"},
+	{table: "scoring_configs", column: "ml_blend", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "rule_configs", column: "source_file", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "typologies", column: "typology_weights", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "typologies", column: "trigger_condition", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "evaluation_outcomes", column: "amount_decimal", definition: "TEXT"},
+	{table: "account_balances", column: "balance_decimal", definition: "TEXT"},
+	{table: "account_balances", column: "prior_balance_decimal", definition: "TEXT"},
+}
+
 // AllSchemas returns all schema statements in order.
 func AllSchemas() []string {
 	return []string{
 		schemaTransactions,
 		schemaRuleConfigs,
 		schemaEvaluations,
+		schemaEvaluationProjections,
 		schemaTypologies,
+		schemaPartyKYC,
+		schemaCorridorRisk,
+		schemaWatchlist,
+		schemaJobs,
+		schemaEvaluationLog,
+		schemaFeatureFlags,
+		schemaAlerts,
+		schemaActivationSamples,
+		schemaOutcomes,
+		schemaWebhooks,
+		schemaDeadLetters,
+		schemaMaintenanceWindows,
+		schemaCounterpartyEdges,
+		schemaScoringConfigs,
+		schemaNamedLists,
+		schemaReviewClaims,
+		schemaEntityBaselines,
+		schemaAccountBalances,
+		schemaRetentionPolicies,
+		schemaAuditLog,
 	}
 }
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// SaveScoringConfig upserts the tenant's scoring config.
+func (r *SQLRepository) SaveScoringConfig(ctx context.Context, tenantID string, cfg *domain.ScoringConfig) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	weighted := 0
+	if cfg.WeightedScoring {
+		weighted = 1
+	}
+
+	query := `
+		INSERT INTO scoring_configs (
+			tenant_id, alert_threshold, weighted_scoring, critical_fail, ml_weight, ml_blend, updated_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id) DO UPDATE SET
+			alert_threshold = excluded.alert_threshold,
+			weighted_scoring = excluded.weighted_scoring,
+			critical_fail = excluded.critical_fail,
+			ml_weight = excluded.ml_weight,
+			ml_blend = excluded.ml_blend,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+
+	updatedAt := cfg.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		tenantID, cfg.AlertThreshold, weighted, cfg.CriticalFail, cfg.MLWeight, cfg.MLBlend, cfg.UpdatedBy, updatedAt.UTC(),
+	)
+	return err
+}
+
+// GetScoringConfig retrieves the tenant's scoring config, or ErrNotFound if
+// it uses the defaults.
+func (r *SQLRepository) GetScoringConfig(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT tenant_id, alert_threshold, weighted_scoring, critical_fail, ml_weight, ml_blend, updated_by, updated_at
+		FROM scoring_configs
+		WHERE tenant_id = ?
+	`
+
+	var cfg domain.ScoringConfig
+	var weighted int
+	var updatedBy sql.NullString
+	err := r.db.QueryRowContext(ctx, r.rebind(query), tenantID).Scan(
+		&cfg.TenantID, &cfg.AlertThreshold, &weighted, &cfg.CriticalFail, &cfg.MLWeight, &cfg.MLBlend, &updatedBy, &cfg.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg.WeightedScoring = weighted == 1
+	cfg.UpdatedBy = updatedBy.String
+	return &cfg, nil
+}
+
+// DeleteScoringConfig deletes the tenant's scoring config, so it uses the
+// defaults again.
+func (r *SQLRepository) DeleteScoringConfig(ctx context.Context, tenantID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.rebind(`DELETE FROM scoring_configs WHERE tenant_id = ?`), tenantID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
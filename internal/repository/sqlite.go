@@ -42,3 +42,26 @@ func openSQLite(cfg domain.RepositoryConfig) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// openMemory opens an in-memory SQLite database that never touches the
+// filesystem. Each connection to ":memory:" has its own database, so the pool
+// is held to a single connection that is never closed while the repository
+// is open. The data is lost when the repository is closed.
+func openMemory() (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(ON)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping in-memory database: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// scoreBucketExpr returns the SQL expression of a score's histogram bucket,
+// matching domain.ScoreHistogramBucket before clamping. SQLite truncates on CAST,
+// which is the floor of a non-negative score; Postgres rounds, so it floors
+// first.
+func (r *SQLRepository) scoreBucketExpr(column string) string {
+	scaled := column + ` * ` + strconv.Itoa(domain.ScoreHistogramBuckets) + ` + 0.000000001`
+	if r.driver == "postgres" {
+		return `CAST(FLOOR(` + scaled + `) AS INTEGER)`
+	}
+	return `CAST(` + scaled + ` AS INTEGER)`
+}
+
+// EvaluationScoreHistogram counts the tenant's evaluation scores from since
+// until until in domain.ScoreHistogramBuckets buckets.
+func (r *SQLRepository) EvaluationScoreHistogram(ctx context.Context, tenantID string, since, until time.Time) ([]int64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT '', ` + r.scoreBucketExpr("score") + ` AS bucket, COUNT(*)
+		FROM evaluations
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ?
+		GROUP BY bucket
+	`
+
+	histograms, err := r.queryScoreHistograms(ctx, query, tenantID, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	if counts, ok := histograms[""]; ok {
+		return counts, nil
+	}
+	return make([]int64, domain.ScoreHistogramBuckets), nil
+}
+
+// TypologyScoreHistograms counts the tenant's typology scores from since
+// until until in domain.ScoreHistogramBuckets buckets, per typology.
+func (r *SQLRepository) TypologyScoreHistograms(ctx context.Context, tenantID string, since, until time.Time) (map[string][]int64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `
+		SELECT typology_id, ` + r.scoreBucketExpr("score") + ` AS bucket, COUNT(*)
+		FROM evaluation_typology_results
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ?
+		GROUP BY typology_id, bucket
+	`
+
+	return r.queryScoreHistograms(ctx, query, tenantID, since.UTC(), until.UTC())
+}
+
+// queryScoreHistograms runs a query of (key, bucket, count) rows into
+// histograms by key.
+func (r *SQLRepository) queryScoreHistograms(ctx context.Context, query string, args ...any) (map[string][]int64, error) {
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histograms := make(map[string][]int64)
+	for rows.Next() {
+		var key string
+		var bucket int
+		var count int64
+		if err := rows.Scan(&key, &bucket, &count); err != nil {
+			return nil, err
+		}
+		counts, ok := histograms[key]
+		if !ok {
+			counts = make([]int64, domain.ScoreHistogramBuckets)
+			histograms[key] = counts
+		}
+		counts[min(max(bucket, 0), domain.ScoreHistogramBuckets-1)] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return histograms, nil
+}
+
+// totalMsExpr returns the SQL expression of an evaluation's processing time,
+// read from its JSON metadata.
+func (r *SQLRepository) totalMsExpr() string {
+	if r.driver == "postgres" {
+		return `CAST(CAST(metadata AS JSONB)->>'totalMs' AS DOUBLE PRECISION)`
+	}
+	return `CAST(json_extract(metadata, '$.totalMs') AS REAL)`
+}
+
+// ListTenantBenchmarks reports, for every tenant with evaluations from since
+// until until, how many it had and how many alerted, their mean processing
+// time, and how the alerts raised in the window were closed. Tenants are
+// sorted by ID.
+func (r *SQLRepository) ListTenantBenchmarks(ctx context.Context, since, until time.Time) ([]*domain.TenantBenchmark, error) {
+	query := `
+		SELECT tenant_id, COUNT(*),
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+			COALESCE(AVG(` + r.totalMsExpr() + `), 0)
+		FROM evaluations
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY tenant_id
+		ORDER BY tenant_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), domain.StatusAlert, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var benchmarks []*domain.TenantBenchmark
+	byTenant := make(map[string]*domain.TenantBenchmark)
+	for rows.Next() {
+		var b domain.TenantBenchmark
+		if err := rows.Scan(&b.TenantID, &b.Evaluations, &b.Alerts, &b.MeanTotalMs); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, &b)
+		byTenant[b.TenantID] = &b
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dispositions := `
+		SELECT tenant_id,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END)
+		FROM alerts
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY tenant_id
+	`
+	rows, err = r.db.QueryContext(ctx, r.rebind(dispositions),
+		domain.AlertClosedConfirmed, domain.AlertClosedFalsePositive, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tenantID string
+		var confirmed, falsePositive int64
+		if err := rows.Scan(&tenantID, &confirmed, &falsePositive); err != nil {
+			return nil, err
+		}
+		if b, ok := byTenant[tenantID]; ok {
+			b.ClosedConfirmed = confirmed
+			b.ClosedFalsePositive = falsePositive
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return benchmarks, nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// watchlistColumns is the column list read by scanWatchlistEntry.
+const watchlistColumns = `id, tenant_id, list_type, party_id, account_id, name, country, note, created_at, updated_at`
+
+// SaveWatchlistEntry upserts a watchlist entry with tenant isolation.
+func (r *SQLRepository) SaveWatchlistEntry(ctx context.Context, tenantID string, entry *domain.WatchlistEntry) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	if entry.ID == "" || entry.ListType == "" {
+		return fmt.Errorf("%w: id and listType are required", ErrInvalidInput)
+	}
+	if entry.PartyID == "" && entry.AccountID == "" && entry.Name == "" {
+		return fmt.Errorf("%w: partyId, accountId or name is required", ErrInvalidInput)
+	}
+
+	now := time.Now().UTC()
+	createdAt := entry.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	query := `
+		INSERT INTO watchlist_entries (
+			id, tenant_id, list_type, party_id, account_id, name, name_key,
+			country, note, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, id) DO UPDATE SET
+			list_type = excluded.list_type,
+			party_id = excluded.party_id,
+			account_id = excluded.account_id,
+			name = excluded.name,
+			name_key = excluded.name_key,
+			country = excluded.country,
+			note = excluded.note,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, r.rebind(query),
+		entry.ID, tenantID, entry.ListType, entry.PartyID, entry.AccountID,
+		entry.Name, domain.NormalizeWatchlistName(entry.Name),
+		entry.Country, entry.Note, createdAt, now,
+	)
+	return err
+}
+
+// GetWatchlistEntry retrieves a watchlist entry with tenant isolation.
+func (r *SQLRepository) GetWatchlistEntry(ctx context.Context, tenantID string, entryID string) (*domain.WatchlistEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + watchlistColumns + ` FROM watchlist_entries WHERE tenant_id = ? AND id = ?`
+
+	entry, err := scanWatchlistEntry(r.db.QueryRowContext(ctx, r.rebind(query), tenantID, entryID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return entry, err
+}
+
+// ListWatchlistEntries lists a tenant's watchlist entries by name, optionally
+// of one list type.
+func (r *SQLRepository) ListWatchlistEntries(ctx context.Context, tenantID string, listType string, offset, limit int) ([]*domain.WatchlistEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `SELECT ` + watchlistColumns + ` FROM watchlist_entries WHERE tenant_id = ?`
+	args := []any{tenantID}
+	if listType != "" {
+		query += ` AND list_type = ?`
+		args = append(args, listType)
+	}
+	query += ` ORDER BY name_key, party_id, account_id, id LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchlistEntries(rows)
+}
+
+// DeleteWatchlistEntry removes a watchlist entry with tenant isolation.
+func (r *SQLRepository) DeleteWatchlistEntry(ctx context.Context, tenantID string, entryID string) error {
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+
+	query := `DELETE FROM watchlist_entries WHERE tenant_id = ? AND id = ?`
+
+	result, err := r.db.ExecContext(ctx, r.rebind(query), tenantID, entryID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchWatchlist returns the tenant's entries naming the subject. Name
+// matches are on the normalized name; an entry's country must agree with
+// the subject's when both are known.
+func (r *SQLRepository) MatchWatchlist(ctx context.Context, tenantID string, subject domain.WatchlistSubject) ([]*domain.WatchlistEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("%w: tenantID is required", ErrInvalidInput)
+	}
+	var conditions []string
+	var args []any
+	if subject.PartyID != "" {
+		conditions = append(conditions, `party_id = ?`)
+		args = append(args, subject.PartyID)
+	}
+	if subject.AccountID != "" {
+		conditions = append(conditions, `account_id = ?`)
+		args = append(args, subject.AccountID)
+	}
+	if nameKey := domain.NormalizeWatchlistName(subject.Name); nameKey != "" {
+		if subject.Country != "" {
+			conditions = append(conditions, `(name_key = ? AND country IN ('', ?))`)
+			args = append(args, nameKey, subject.Country)
+		} else {
+			conditions = append(conditions, `name_key = ?`)
+			args = append(args, nameKey)
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + watchlistColumns + ` FROM watchlist_entries WHERE tenant_id = ? AND (` +
+		strings.Join(conditions, ` OR `) + `) ORDER BY list_type, id`
+
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), append([]any{tenantID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchlistEntries(rows)
+}
+
+// scanWatchlistEntry reads one row selected with watchlistColumns.
+func scanWatchlistEntry(row interface{ Scan(...any) error }) (*domain.WatchlistEntry, error) {
+	var entry domain.WatchlistEntry
+	var note sql.NullString
+	if err := row.Scan(
+		&entry.ID, &entry.TenantID, &entry.ListType, &entry.PartyID, &entry.AccountID,
+		&entry.Name, &entry.Country, &note, &entry.CreatedAt, &entry.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	entry.Note = note.String
+	return &entry, nil
+}
+
+// scanWatchlistEntries reads rows selected with watchlistColumns.
+func scanWatchlistEntries(rows *sql.Rows) ([]*domain.WatchlistEntry, error) {
+	var entries []*domain.WatchlistEntry
+	for rows.Next() {
+		entry, err := scanWatchlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
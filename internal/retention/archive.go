@@ -0,0 +1,96 @@
+package retention
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveLine is one line of an archive file.
+type archiveLine struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+// fileArchive writes the expired rows of one tenant's class as gzipped JSON
+// lines. The file is written under a temporary name and renamed on Close,
+// so a complete archive never has a partial one's name. Nothing is created
+// until the first row.
+type fileArchive struct {
+	path string
+	rows int64
+
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// newFileArchive names the archive of a tenant's class in a run:
+// <dir>/<tenant>/<run start>-<run ID>-<class>.jsonl.gz.
+func newFileArchive(dir, tenantID string, run *Run, class string) *fileArchive {
+	name := fmt.Sprintf("%s-%s-%s.jsonl.gz", run.StartedAt.Format("20060102T150405Z"), run.ID[:8], class)
+	return &fileArchive{path: filepath.Join(dir, tenantDir(tenantID), name)}
+}
+
+// ArchiveRow appends a row to the archive.
+func (a *fileArchive) ArchiveRow(table string, row map[string]any) error {
+	if a.file == nil {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0o750); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(a.path+".partial", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+		if err != nil {
+			return err
+		}
+		a.file = file
+		a.gz = gzip.NewWriter(file)
+		a.enc = json.NewEncoder(a.gz)
+	}
+	if err := a.enc.Encode(archiveLine{Table: table, Row: row}); err != nil {
+		return err
+	}
+	a.rows++
+	return nil
+}
+
+// Close flushes the archive to disk and gives it its final name.
+func (a *fileArchive) Close() error {
+	if a.file == nil {
+		return nil
+	}
+	if err := a.gz.Close(); err != nil {
+		return err
+	}
+	if err := a.file.Sync(); err != nil {
+		return err
+	}
+	if err := a.file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(a.path+".partial", a.path)
+}
+
+// remove deletes the archive after the rows it holds failed to expire.
+func (a *fileArchive) remove() {
+	if a.file == nil {
+		return
+	}
+	_ = a.file.Close()
+	_ = os.Remove(a.path + ".partial")
+	_ = os.Remove(a.path)
+	a.rows = 0
+}
+
+// tenantDir escapes a tenant ID into a single path element that can't
+// climb out of the archive directory.
+func tenantDir(tenantID string) string {
+	dir := url.PathEscape(tenantID)
+	if strings.HasPrefix(dir, ".") {
+		dir = "%2E" + dir[1:]
+	}
+	return dir
+}
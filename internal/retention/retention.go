@@ -0,0 +1,378 @@
+// Package retention expires tenants' activity by their retention policies.
+//
+// A policy keeps each retention class, transactions, evaluations and
+// alerts, for a number of days; tenants without their own use the
+// configured defaults, which keep everything. Runs are scheduled at the
+// configured interval and can be started by operators. With Archive, a
+// policy's expired rows are written to gzipped JSON lines files under the
+// archive directory before they are deleted, one file per tenant, class and
+// run; rows are only deleted once their file is complete. Runs are kept in
+// memory, so each instance only reports its own.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// maxRuns is how many runs are kept for GET /admin/retention/runs.
+const maxRuns = 20
+
+// Run triggers.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Run statuses.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed" // the run stopped early or some expirations failed
+)
+
+// Service errors.
+var (
+	ErrRunning  = errors.New("a retention run is in progress")
+	ErrNotFound = errors.New("no such retention run")
+)
+
+// Result is the expiry of one retention class of a tenant.
+type Result struct {
+	TenantID string    `json:"tenantId"`
+	Class    string    `json:"class,omitempty"`
+	Days     int       `json:"days,omitempty"`
+	Before   time.Time `json:"before,omitempty"` // rows dated before this were expired
+	Deleted  int64     `json:"deleted"`
+	Archive  string    `json:"archive,omitempty"` // file the rows were archived to
+	Error    string    `json:"error,omitempty"`
+}
+
+// Run is one pass over the tenants' retention policies.
+type Run struct {
+	ID         string     `json:"id"`
+	Trigger    string     `json:"trigger"`
+	TenantID   string     `json:"tenantId,omitempty"` // set when the run was limited to one tenant
+	Status     string     `json:"status"`
+	Deleted    int64      `json:"deleted"`
+	Results    []Result   `json:"results"` // classes with rows expired or an error
+	Compacted  bool       `json:"compacted,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedBy  string     `json:"startedBy,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Service applies retention policies and keeps the latest runs.
+type Service struct {
+	repo   domain.Repository
+	cfg    domain.RetentionConfig
+	now    func() time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	runs    []*Run // oldest first
+	running bool
+}
+
+// NewService creates a retention service. A zero interval uses the default.
+func NewService(repo domain.Repository, cfg domain.RetentionConfig) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = domain.DefaultConfig().Retention.Interval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{repo: repo, cfg: cfg, now: time.Now, ctx: ctx, cancel: cancel}
+}
+
+// Defaults returns the policy of tenants without their own.
+func (s *Service) Defaults() domain.RetentionPolicy {
+	return s.cfg.Defaults
+}
+
+// ArchiveEnabled reports whether an archive directory is configured.
+func (s *Service) ArchiveEnabled() bool {
+	return s.cfg.ArchiveDir != ""
+}
+
+// Get returns the tenant's own retention policy, or nil if it uses the
+// defaults.
+func (s *Service) Get(ctx context.Context, tenantID string) (*domain.RetentionPolicy, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+	policy, err := s.repo.GetRetentionPolicy(ctx, tenantID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+// Save stores the tenant's own retention policy. It applies from the next run.
+func (s *Service) Save(ctx context.Context, tenantID string, policy *domain.RetentionPolicy) error {
+	if s.repo == nil {
+		return fmt.Errorf("no data source available")
+	}
+	return s.repo.SaveRetentionPolicy(ctx, tenantID, policy)
+}
+
+// Delete removes the tenant's own retention policy, so the defaults apply.
+func (s *Service) Delete(ctx context.Context, tenantID string) error {
+	if s.repo == nil {
+		return fmt.Errorf("no data source available")
+	}
+	return s.repo.DeleteRetentionPolicy(ctx, tenantID)
+}
+
+// Start runs the policies in the background and returns the run as it
+// starts. An empty tenantID runs every tenant's policy.
+func (s *Service) Start(tenantID, by string) (*Run, error) {
+	run, err := s.begin(TriggerManual, tenantID, by)
+	if err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(s.ctx, run)
+	}()
+	return s.copyRun(run), nil
+}
+
+// Execute runs the policies and returns the finished run. An empty tenantID
+// runs every tenant's policy.
+func (s *Service) Execute(ctx context.Context, trigger, tenantID string) (*Run, error) {
+	run, err := s.begin(trigger, tenantID, "")
+	if err != nil {
+		return nil, err
+	}
+	s.execute(ctx, run)
+	return s.copyRun(run), nil
+}
+
+// Run applies the policies every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	if s.repo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Execute(ctx, TriggerSchedule, ""); err != nil {
+				slog.Info("scheduled retention run skipped", "error", err)
+			}
+		}
+	}
+}
+
+// GetRun returns a run by ID.
+func (s *Service) GetRun(id string) (*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return copyRun(run), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Runs returns the latest runs, newest first.
+func (s *Service) Runs() []*Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Run, 0, len(s.runs))
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		out = append(out, copyRun(s.runs[i]))
+	}
+	return out
+}
+
+// Stop interrupts a run started by Start and waits for it to return.
+func (s *Service) Stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// begin records a new run, unless one is in progress.
+func (s *Service) begin(trigger, tenantID, by string) (*Run, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return nil, ErrRunning
+	}
+	s.running = true
+
+	run := &Run{
+		ID:        uuid.New().String(),
+		Trigger:   trigger,
+		TenantID:  tenantID,
+		Status:    StatusRunning,
+		Results:   []Result{},
+		StartedBy: by,
+		StartedAt: s.now().UTC(),
+	}
+	s.runs = append(s.runs, run)
+	if len(s.runs) > maxRuns {
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+	}
+	return run, nil
+}
+
+// execute expires each tenant's classes by its policy, recording the
+// results on run as it goes.
+func (s *Service) execute(ctx context.Context, run *Run) {
+	err := s.expire(ctx, run)
+
+	var compacted bool
+	if err == nil && run.Deleted > 0 && s.cfg.Compact {
+		if compactor, ok := domain.BaseRepository(s.repo).(domain.Compactor); ok {
+			if err = compactor.Compact(ctx); err != nil {
+				err = fmt.Errorf("failed to compact: %w", err)
+			} else {
+				compacted = true
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	finished := s.now().UTC()
+	run.FinishedAt = &finished
+	run.Compacted = compacted
+	run.Status = StatusCompleted
+	failed := 0
+	for _, result := range run.Results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	switch {
+	case err != nil:
+		run.Status, run.Error = StatusFailed, err.Error()
+	case failed > 0:
+		run.Status, run.Error = StatusFailed, fmt.Sprintf("%d expirations failed", failed)
+	}
+	slog.Info("retention run finished", "run_id", run.ID, "trigger", run.Trigger, "status", run.Status, "deleted", run.Deleted, "error", run.Error)
+}
+
+func (s *Service) expire(ctx context.Context, run *Run) error {
+	tenantIDs := []string{run.TenantID}
+	if run.TenantID == "" {
+		var err error
+		if tenantIDs, err = s.repo.ListTenantIDs(ctx); err != nil {
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+	}
+
+	now := s.now().UTC()
+	for _, tenantID := range tenantIDs {
+		policy, err := s.Get(ctx, tenantID)
+		if err != nil {
+			s.record(run, Result{TenantID: tenantID, Error: err.Error()})
+			continue
+		}
+		if policy == nil {
+			defaults := s.Defaults()
+			policy = &defaults
+		}
+
+		for _, class := range domain.RetentionClasses {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			days := policy.Days(class)
+			if days <= 0 {
+				continue
+			}
+			result := Result{TenantID: tenantID, Class: class, Days: days, Before: now.AddDate(0, 0, -days)}
+			s.expireClass(ctx, run, policy, &result)
+			if result.Deleted > 0 || result.Error != "" {
+				s.record(run, result)
+			}
+		}
+	}
+	return nil
+}
+
+// expireClass expires one class of a tenant, archiving it first if the
+// policy says so. A failed archive is removed; its rows stay in place.
+func (s *Service) expireClass(ctx context.Context, run *Run, policy *domain.RetentionPolicy, result *Result) {
+	var archive *fileArchive
+	if policy.Archive {
+		if s.cfg.ArchiveDir == "" {
+			result.Error = "the policy archives, but no archive directory is configured"
+			return
+		}
+		archive = newFileArchive(s.cfg.ArchiveDir, result.TenantID, run, result.Class)
+	}
+
+	// A nil *fileArchive must not reach the repository as a non-nil interface
+	var n int64
+	var err error
+	if archive != nil {
+		n, err = s.repo.ExpireTenantData(ctx, result.TenantID, result.Class, result.Before, archive)
+	} else {
+		n, err = s.repo.ExpireTenantData(ctx, result.TenantID, result.Class, result.Before, nil)
+	}
+	if err != nil {
+		if archive != nil {
+			archive.remove()
+		}
+		slog.Error("failed to expire tenant data", "tenant_id", result.TenantID, "class", result.Class, "error", err)
+		result.Error = err.Error()
+		return
+	}
+	result.Deleted = n
+	if archive != nil && archive.rows > 0 {
+		result.Archive = archive.path
+	}
+	if n > 0 {
+		slog.Info("tenant data expired", "tenant_id", result.TenantID, "class", result.Class, "rows", n, "before", result.Before)
+	}
+}
+
+func (s *Service) record(run *Run, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.Results = append(run.Results, result)
+	run.Deleted += result.Deleted
+}
+
+func (s *Service) copyRun(run *Run) *Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyRun(run)
+}
+
+func copyRun(run *Run) *Run {
+	out := *run
+	out.Results = append([]Result{}, run.Results...)
+	return &out
+}
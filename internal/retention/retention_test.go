@@ -0,0 +1,168 @@
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestExecute(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	now := ospreytest.Epoch.Add(400 * 24 * time.Hour)
+
+	save := func(tenantID, id string, at time.Time) {
+		t.Helper()
+		if err := repo.SaveTransaction(ctx, tenantID, &domain.Transaction{ID: id, Timestamp: at, CreatedAt: at}); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		if err := repo.SaveEvaluation(ctx, tenantID, &domain.Evaluation{ID: "eval-" + id, TxID: id, Status: domain.StatusNoAlert, Timestamp: at}); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	save("bank-001", "old", now.AddDate(0, -14, 0))
+	save("bank-001", "new", now.AddDate(0, -1, 0))
+	save("bank-002", "other", now.AddDate(0, -14, 0))
+
+	// bank-001 keeps transactions 13 months and archives them; bank-002
+	// uses the defaults, which keep everything
+	if err := repo.SaveRetentionPolicy(ctx, "bank-001", &domain.RetentionPolicy{TransactionDays: 396, EvaluationDays: 2555, Archive: true}); err != nil {
+		t.Fatalf("SaveRetentionPolicy failed: %v", err)
+	}
+	dir := t.TempDir()
+	svc := NewService(repo, domain.RetentionConfig{ArchiveDir: dir})
+	svc.now = func() time.Time { return now }
+
+	run, err := svc.Execute(ctx, TriggerSchedule, "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if run.Status != StatusCompleted || run.Deleted != 1 || len(run.Results) != 1 {
+		t.Fatalf("expected one transaction expired, got %+v", run)
+	}
+	if _, err := repo.GetTransaction(ctx, "bank-001", "old"); err != repository.ErrNotFound {
+		t.Errorf("expected the 14-month-old transaction expired, got %v", err)
+	}
+	if _, err := repo.GetTransaction(ctx, "bank-001", "new"); err != nil {
+		t.Errorf("expected the recent transaction kept, got %v", err)
+	}
+	if _, err := repo.GetTransaction(ctx, "bank-002", "other"); err != nil {
+		t.Errorf("expected the default policy to keep everything, got %v", err)
+	}
+	if _, err := repo.GetEvaluation(ctx, "bank-001", "eval-old"); err != nil {
+		t.Errorf("expected evaluations kept for 7 years, got %v", err)
+	}
+
+	result := run.Results[0]
+	if filepath.Dir(result.Archive) != filepath.Join(dir, "bank-001") {
+		t.Fatalf("expected the archive under the tenant's directory, got %q", result.Archive)
+	}
+	lines := readArchive(t, result.Archive)
+	if len(lines) != 1 || lines[0].Table != "transactions" || lines[0].Row["id"] != "old" {
+		t.Errorf("expected the expired transaction archived, got %+v", lines)
+	}
+
+	runs := svc.Runs()
+	if len(runs) != 1 || runs[0].ID != run.ID || runs[0].Trigger != TriggerSchedule {
+		t.Errorf("expected the run listed, got %+v", runs)
+	}
+	if _, err := svc.GetRun("missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for an unknown run, got %v", err)
+	}
+}
+
+func TestExecuteWithoutArchiveDir(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	old := ospreytest.Epoch
+	if err := repo.SaveTransaction(ctx, "bank-001", &domain.Transaction{ID: "old", Timestamp: old, CreatedAt: old}); err != nil {
+		t.Fatalf("SaveTransaction failed: %v", err)
+	}
+	if err := repo.SaveRetentionPolicy(ctx, "bank-001", &domain.RetentionPolicy{TransactionDays: 30, Archive: true}); err != nil {
+		t.Fatalf("SaveRetentionPolicy failed: %v", err)
+	}
+
+	svc := NewService(repo, domain.RetentionConfig{})
+	svc.now = func() time.Time { return old.AddDate(1, 0, 0) }
+	run, err := svc.Execute(ctx, TriggerSchedule, "bank-001")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if run.Status != StatusFailed || len(run.Results) != 1 || run.Results[0].Error == "" {
+		t.Errorf("expected the archiving policy to fail without a directory, got %+v", run)
+	}
+	if _, err := repo.GetTransaction(ctx, "bank-001", "old"); err != nil {
+		t.Errorf("expected nothing deleted that couldn't be archived, got %v", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo, domain.RetentionConfig{Defaults: domain.RetentionPolicy{TransactionDays: 30}})
+
+	run, err := svc.Start("", "ops")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if run.Trigger != TriggerManual || run.StartedBy != "ops" {
+		t.Errorf("expected a manual run by ops, got %+v", run)
+	}
+	svc.Stop()
+
+	got, err := svc.GetRun(run.ID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if got.Status != StatusCompleted || got.FinishedAt == nil {
+		t.Errorf("expected the run completed, got %+v", got)
+	}
+}
+
+func TestTenantDir(t *testing.T) {
+	for tenantID, want := range map[string]string{
+		"bank-001": "bank-001",
+		"a/b":      "a%2Fb",
+		"..":       "%2E.",
+		".hidden":  "%2Ehidden",
+	} {
+		if got := tenantDir(tenantID); got != want {
+			t.Errorf("tenantDir(%q) = %q, want %q", tenantID, got, want)
+		}
+	}
+}
+
+func readArchive(t *testing.T, path string) []archiveLine {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	var lines []archiveLine
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var line archiveLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid archive line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	return lines
+}
@@ -0,0 +1,333 @@
+// Package review builds the analyst review queue: held transactions awaiting
+// a decision, unacknowledged alerts and open cases in one prioritized list,
+// with claims so two analysts don't work the same item.
+//
+// Every item is identified by its evaluation ID, which is also the ID of the
+// alert an ALRT evaluation raised, so an item keeps its ID as it moves from
+// alert to case. A held transaction that also alerted is listed once, as
+// its alert.
+package review
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Item kinds.
+const (
+	KindHold  = "hold"  // A transaction a rule held, with no outcome yet
+	KindAlert = "alert" // An open alert nobody acknowledged
+	KindCase  = "case"  // An acknowledged or investigated alert not yet closed
+)
+
+// Kinds lists the valid item kinds.
+var Kinds = []string{KindHold, KindAlert, KindCase}
+
+// ValidKind reports whether k is a known item kind.
+func ValidKind(k string) bool {
+	return slices.Contains(Kinds, k)
+}
+
+// StatusHeld is the status of a held transaction's item.
+const StatusHeld = "held"
+
+// maxSourceItems bounds how many holds and alerts are read per listing.
+const maxSourceItems = 1000
+
+var (
+	// ErrNotFound is returned when no evaluation has the item's ID.
+	ErrNotFound = errors.New("review item not found")
+
+	// ErrClaimed is returned when another analyst holds an active claim.
+	ErrClaimed = errors.New("review item is claimed by another analyst")
+
+	// ErrNotClaimed is returned when releasing an item one doesn't hold.
+	ErrNotClaimed = errors.New("review item is not claimed by this analyst")
+)
+
+// Item is one entry of the review queue.
+type Item struct {
+	ID       string    `json:"id"` // The evaluation ID, and alert ID for alerts and cases
+	Kind     string    `json:"kind"`
+	TxID     string    `json:"txId"`
+	Score    float64   `json:"score"`
+	Amount   float64   `json:"amount"`
+	Currency string    `json:"currency,omitempty"`
+	Status   string    `json:"status"` // The alert status, or held
+	Assignee string    `json:"assignee,omitempty"`
+	Since    time.Time `json:"since"` // When the item entered the queue
+
+	// DueAt is when the item's SLA runs out; SLARemainingSeconds turns
+	// negative once it has. Both are unset when the kind has no SLA.
+	DueAt               *time.Time `json:"dueAt,omitempty"`
+	SLARemainingSeconds *int64     `json:"slaRemainingSeconds,omitempty"`
+
+	ClaimedBy      string     `json:"claimedBy,omitempty"`
+	ClaimExpiresAt *time.Time `json:"claimExpiresAt,omitempty"`
+}
+
+// Filter narrows a queue listing.
+type Filter struct {
+	Kinds     []string // Only items of these kinds; empty lists all
+	Unclaimed bool     // Only items nobody holds an active claim on
+	Limit     int      // Max items returned; 0 = all
+}
+
+// Queue lists and claims review items.
+type Queue struct {
+	repo   domain.Repository
+	policy domain.ReviewConfig
+	now    func() time.Time
+}
+
+// NewQueue returns a review queue over repo with the given deadlines.
+func NewQueue(repo domain.Repository, policy domain.ReviewConfig) *Queue {
+	return &Queue{repo: repo, policy: policy, now: time.Now}
+}
+
+// List returns the tenant's review items, most urgent first: items past
+// their SLA by how overdue they are, then the rest by score, by amount,
+// and by SLA remaining.
+func (q *Queue) List(ctx context.Context, tenantID string, filter Filter) ([]*Item, error) {
+	now := q.now().UTC()
+	want := func(kind string) bool {
+		return len(filter.Kinds) == 0 || slices.Contains(filter.Kinds, kind)
+	}
+
+	var items []*Item
+	alerted := make(map[string]bool)
+	if want(KindAlert) || want(KindCase) || want(KindHold) {
+		// Holds need the alerts too, to skip the ones that alerted
+		list, err := q.repo.ListAlerts(ctx, tenantID, domain.AlertFilter{
+			Statuses: []string{domain.AlertOpen, domain.AlertInvestigating},
+			Limit:    maxSourceItems,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, alert := range list {
+			alerted[alert.ID] = true
+			if item := alertItem(alert); want(item.Kind) {
+				items = append(items, item)
+			}
+		}
+	}
+	if want(KindHold) {
+		holds, err := q.holds(ctx, tenantID, now, alerted)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, holds...)
+	}
+
+	claims, err := q.repo.ListReviewClaims(ctx, tenantID, now)
+	if err != nil {
+		return nil, err
+	}
+	claimed := make(map[string]*domain.ReviewClaim, len(claims))
+	for _, claim := range claims {
+		claimed[claim.ItemID] = claim
+	}
+
+	out := items[:0]
+	for _, item := range items {
+		if claim, ok := claimed[item.ID]; ok {
+			if filter.Unclaimed {
+				continue
+			}
+			item.ClaimedBy = claim.ClaimedBy
+			expires := claim.ExpiresAt
+			item.ClaimExpiresAt = &expires
+		}
+		if err := q.addAmount(ctx, tenantID, item); err != nil {
+			return nil, err
+		}
+		setSLA(item, q.sla(item.Kind), now)
+		out = append(out, item)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return before(out[i], out[j]) })
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+// alertItem returns the item of an open or investigated alert.
+func alertItem(alert *domain.Alert) *Item {
+	kind := KindCase
+	if alert.Status == domain.AlertOpen && !alert.Acked() {
+		kind = KindAlert
+	}
+	return &Item{
+		ID:       alert.ID,
+		Kind:     kind,
+		TxID:     alert.TxID,
+		Score:    alert.Score,
+		Status:   alert.Status,
+		Assignee: alert.Assignee,
+		Since:    alert.CreatedAt,
+	}
+}
+
+// holds returns the items of the transactions held within the hold window
+// that neither alerted nor have an outcome.
+func (q *Queue) holds(ctx context.Context, tenantID string, now time.Time, alerted map[string]bool) ([]*Item, error) {
+	since := now.Add(-q.policy.HoldWindow)
+	evals, err := q.repo.ListEvaluations(ctx, tenantID, domain.EvaluationFilter{
+		Status: domain.StatusNoAlert,
+		Since:  since,
+		Limit:  maxSourceItems,
+	})
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := q.repo.ListOutcomes(ctx, tenantID, domain.OutcomeFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	decided := make(map[string]bool, len(outcomes))
+	for _, outcome := range outcomes {
+		decided[outcome.EvaluationID] = true
+	}
+
+	var items []*Item
+	for _, eval := range evals {
+		if alerted[eval.ID] || decided[eval.ID] || !slices.Contains(eval.Actions(), domain.BandActionHold) {
+			continue
+		}
+		items = append(items, &Item{
+			ID:     eval.ID,
+			Kind:   KindHold,
+			TxID:   eval.TxID,
+			Score:  eval.Score,
+			Status: StatusHeld,
+			Since:  eval.Timestamp,
+		})
+	}
+	return items, nil
+}
+
+// addAmount copies the item's transaction amount, when it was stored.
+func (q *Queue) addAmount(ctx context.Context, tenantID string, item *Item) error {
+	tx, err := q.repo.GetTransaction(ctx, tenantID, item.TxID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	item.Amount = tx.Amount.Float64()
+	item.Currency = tx.Currency
+	return nil
+}
+
+// sla returns the deadline of a kind of item; zero sets none.
+func (q *Queue) sla(kind string) time.Duration {
+	switch kind {
+	case KindHold:
+		return q.policy.HoldSLA
+	case KindAlert:
+		return q.policy.AlertSLA
+	default:
+		return q.policy.CaseSLA
+	}
+}
+
+func setSLA(item *Item, sla time.Duration, now time.Time) {
+	if sla <= 0 {
+		return
+	}
+	due := item.Since.Add(sla)
+	remaining := int64(due.Sub(now) / time.Second)
+	item.DueAt = &due
+	item.SLARemainingSeconds = &remaining
+}
+
+// before reports whether a ranks ahead of b.
+func before(a, b *Item) bool {
+	aOverdue, bOverdue := overdue(a), overdue(b)
+	if aOverdue != bOverdue {
+		return aOverdue
+	}
+	if aOverdue && *a.SLARemainingSeconds != *b.SLARemainingSeconds {
+		return *a.SLARemainingSeconds < *b.SLARemainingSeconds
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Amount != b.Amount {
+		return a.Amount > b.Amount
+	}
+	if (a.DueAt == nil) != (b.DueAt == nil) {
+		return a.DueAt != nil
+	}
+	if a.DueAt != nil && !a.DueAt.Equal(*b.DueAt) {
+		return a.DueAt.Before(*b.DueAt)
+	}
+	return a.ID < b.ID
+}
+
+func overdue(item *Item) bool {
+	return item.SLARemainingSeconds != nil && *item.SLARemainingSeconds < 0
+}
+
+// Claim claims an item for by until the claim TTL passes; claiming an item
+// one already holds renews the claim. If another analyst holds it, Claim
+// returns their claim with ErrClaimed.
+func (q *Queue) Claim(ctx context.Context, tenantID, itemID, by string) (*domain.ReviewClaim, error) {
+	_, err := q.repo.GetEvaluation(ctx, tenantID, itemID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := q.now().UTC()
+	claim := &domain.ReviewClaim{
+		ItemID:    itemID,
+		TenantID:  tenantID,
+		ClaimedBy: by,
+		ClaimedAt: now,
+		ExpiresAt: now.Add(q.policy.ClaimTTL),
+	}
+	err = q.repo.ClaimReviewItem(ctx, tenantID, claim)
+	if errors.Is(err, repository.ErrNotFound) {
+		return q.activeClaim(ctx, tenantID, itemID, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// activeClaim returns the claim that blocked a claim, with ErrClaimed.
+func (q *Queue) activeClaim(ctx context.Context, tenantID, itemID string, now time.Time) (*domain.ReviewClaim, error) {
+	claims, err := q.repo.ListReviewClaims(ctx, tenantID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		if claim.ItemID == itemID {
+			return claim, ErrClaimed
+		}
+	}
+	return nil, ErrClaimed
+}
+
+// Release drops by's claim on an item. It fails with ErrNotClaimed if by
+// holds no claim on it.
+func (q *Queue) Release(ctx context.Context, tenantID, itemID, by string) error {
+	err := q.repo.ReleaseReviewItem(ctx, tenantID, itemID, by)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotClaimed
+	}
+	return err
+}
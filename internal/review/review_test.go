@@ -0,0 +1,128 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	clock := ospreytest.NewClock(time.Time{})
+	repo := ospreytest.NewRepository(clock)
+	now := clock.Now()
+
+	held := []domain.RuleResult{{RuleID: "large-transfer", Action: domain.BandActionHold}}
+	for _, eval := range []*domain.Evaluation{
+		{ID: "hold-overdue", TxID: "tx-1", Status: domain.StatusNoAlert, Score: 0.4, RuleResults: held, Timestamp: now.Add(-2 * time.Hour)},
+		{ID: "hold-small", TxID: "tx-2", Status: domain.StatusNoAlert, Score: 0.4, RuleResults: held, Timestamp: now.Add(-10 * time.Minute)},
+		{ID: "hold-large", TxID: "tx-3", Status: domain.StatusNoAlert, Score: 0.4, RuleResults: held, Timestamp: now.Add(-5 * time.Minute)},
+		{ID: "hold-decided", TxID: "tx-4", Status: domain.StatusNoAlert, Score: 0.4, RuleResults: held, Timestamp: now.Add(-5 * time.Minute)},
+		{ID: "hold-expired", TxID: "tx-5", Status: domain.StatusNoAlert, Score: 0.4, RuleResults: held, Timestamp: now.Add(-48 * time.Hour)},
+		{ID: "pass", TxID: "tx-6", Status: domain.StatusNoAlert, Score: 0.1, Timestamp: now},
+		{ID: "alert-1", TxID: "tx-7", Status: domain.StatusAlert, Score: 0.9, RuleResults: held, Timestamp: now.Add(-time.Hour)},
+		{ID: "case-1", TxID: "tx-8", Status: domain.StatusAlert, Score: 0.8, Timestamp: now.Add(-2 * time.Hour)},
+	} {
+		if err := repo.SaveEvaluation(ctx, "tenant-001", eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	for id, amount := range map[string]float64{"tx-1": 5000, "tx-2": 100, "tx-3": 900, "tx-7": 50} {
+		repo.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID(id).Tenant("tenant-001").Amount(amount, "USD").Build())
+	}
+	repo.SaveOutcome(ctx, "tenant-001", &domain.EvaluationOutcome{ID: "out-1", EvaluationID: "hold-decided", TxID: "tx-4", Outcome: "released", OccurredAt: now})
+	repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "alert-1", TxID: "tx-7", Score: 0.9, Status: domain.AlertOpen, CreatedAt: now.Add(-time.Hour)})
+	repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "case-1", TxID: "tx-8", Score: 0.8, Status: domain.AlertInvestigating, Assignee: "alice", CreatedAt: now.Add(-2 * time.Hour)})
+	repo.SaveAlert(ctx, "tenant-001", &domain.Alert{ID: "closed", TxID: "tx-9", Score: 1, Status: domain.AlertClosedConfirmed, CreatedAt: now})
+
+	q := NewQueue(repo, domain.DefaultConfig().Review)
+	q.now = clock.Now
+
+	ids := func(items []*Item) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.ID)
+		}
+		return out
+	}
+	list := func(filter Filter) []*Item {
+		t.Helper()
+		items, err := q.List(ctx, "tenant-001", filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		return items
+	}
+
+	t.Run("Ranking", func(t *testing.T) {
+		items := list(Filter{})
+		want := []string{"hold-overdue", "alert-1", "case-1", "hold-large", "hold-small"}
+		if got := ids(items); len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i, item := range items {
+			if item.ID != want[i] {
+				t.Fatalf("expected %v, got %v", want, ids(items))
+			}
+		}
+
+		overdue := items[0]
+		if overdue.Kind != KindHold || overdue.Status != StatusHeld || overdue.Amount != 5000 || overdue.Currency != "USD" {
+			t.Errorf("expected the overdue hold with its amount, got %+v", overdue)
+		}
+		if overdue.SLARemainingSeconds == nil || *overdue.SLARemainingSeconds != -3600 {
+			t.Errorf("expected the hold an hour past its SLA, got %+v", overdue.SLARemainingSeconds)
+		}
+		if items[1].Kind != KindAlert || items[2].Kind != KindCase || items[2].Assignee != "alice" {
+			t.Errorf("expected the unacked alert and the investigated case, got %+v, %+v", items[1], items[2])
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		if got := ids(list(Filter{Kinds: []string{KindCase}})); len(got) != 1 || got[0] != "case-1" {
+			t.Errorf("expected only the case, got %v", got)
+		}
+		if got := ids(list(Filter{Kinds: []string{KindHold}, Limit: 2})); len(got) != 2 || got[0] != "hold-overdue" {
+			t.Errorf("expected the first two holds, got %v", got)
+		}
+	})
+
+	t.Run("Claims", func(t *testing.T) {
+		claim, err := q.Claim(ctx, "tenant-001", "hold-overdue", "alice")
+		if err != nil {
+			t.Fatalf("Claim failed: %v", err)
+		}
+		if !claim.ExpiresAt.Equal(now.Add(30 * time.Minute)) {
+			t.Errorf("expected a 30 minute claim, got %+v", claim)
+		}
+		blocking, err := q.Claim(ctx, "tenant-001", "hold-overdue", "bob")
+		if !errors.Is(err, ErrClaimed) || blocking == nil || blocking.ClaimedBy != "alice" {
+			t.Errorf("expected ErrClaimed with alice's claim, got %+v, %v", blocking, err)
+		}
+		if _, err := q.Claim(ctx, "tenant-001", "missing", "bob"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown item, got %v", err)
+		}
+
+		if items := list(Filter{}); items[0].ClaimedBy != "alice" {
+			t.Errorf("expected the item to show its claim, got %+v", items[0])
+		}
+		if got := ids(list(Filter{Unclaimed: true})); len(got) != 4 || got[0] != "alert-1" {
+			t.Errorf("expected claimed items left out, got %v", got)
+		}
+
+		if err := q.Release(ctx, "tenant-001", "hold-overdue", "bob"); !errors.Is(err, ErrNotClaimed) {
+			t.Errorf("expected ErrNotClaimed releasing alice's claim, got %v", err)
+		}
+		clock.Advance(31 * time.Minute)
+		if _, err := q.Claim(ctx, "tenant-001", "hold-overdue", "bob"); err != nil {
+			t.Errorf("expected a lapsed claim to be taken over, got %v", err)
+		}
+		if err := q.Release(ctx, "tenant-001", "hold-overdue", "bob"); err != nil {
+			t.Errorf("Release failed: %v", err)
+		}
+	})
+}
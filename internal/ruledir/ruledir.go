@@ -0,0 +1,185 @@
+// Package ruledir applies rules and typologies from a local directory.
+//
+// When enabled, the directory is the source of truth, as with Git sync: it
+// holds rules/ and typologies/ with one YAML or JSON file per rule or
+// typology, and the whole directory is applied as a state.Spec whenever a
+// file is added, changed or removed. An invalid file rejects the change and
+// keeps the running configuration. Each rule records the file it came from.
+//
+// The directory is checked by polling its files' contents, which works the
+// same on every platform and on mounted volumes such as Kubernetes
+// ConfigMaps, where file events are unreliable.
+package ruledir
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/state"
+)
+
+// Status describes the most recent check of the directory.
+type Status struct {
+	Dir       string      `json:"dir"`
+	Digest    string      `json:"digest,omitempty"` // of the applied files
+	Files     int         `json:"files"`            // applied files
+	SyncedAt  *time.Time  `json:"syncedAt,omitempty"`
+	CheckedAt *time.Time  `json:"checkedAt,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Changes   *state.Plan `json:"changes,omitempty"` // of the last applied change
+}
+
+// Watcher applies the directory's rules and typologies when they change.
+type Watcher struct {
+	cfg   domain.RuleDirConfig
+	state *state.Manager
+
+	syncMu sync.Mutex // serializes syncs
+	failed string     // digest of the last rejected files, so each is reported once
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewWatcher creates a watcher for cfg that applies changes through mgr.
+// It does nothing until Sync or Run.
+func NewWatcher(cfg domain.RuleDirConfig, mgr *state.Manager) *Watcher {
+	return &Watcher{
+		cfg:    cfg,
+		state:  mgr,
+		status: Status{Dir: cfg.Dir},
+	}
+}
+
+// Enabled reports whether the watcher is configured. It is safe on a nil Watcher.
+func (w *Watcher) Enabled() bool {
+	return w != nil && w.cfg.Dir != ""
+}
+
+// Status returns the result of the most recent check.
+func (w *Watcher) Status() Status {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.status
+}
+
+// Run checks the directory every Interval until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := w.Sync(ctx); err != nil {
+			slog.Error("rule directory sync failed", "dir", w.cfg.Dir, "error", err)
+		}
+	}
+}
+
+// Sync applies the directory if its files changed since they were last
+// applied. Invalid files leave the current configuration untouched, and are
+// reported once rather than on every check.
+func (w *Watcher) Sync(ctx context.Context) (Status, error) {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+
+	digest, files, plan, err := w.sync(ctx)
+
+	now := time.Now().UTC()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.CheckedAt = &now
+	switch {
+	case err != nil:
+		w.status.Error = err.Error()
+	case plan != nil:
+		w.status.Error = ""
+		w.status.Digest = digest
+		w.status.Files = files
+		w.status.SyncedAt = &now
+		w.status.Changes = plan
+	}
+	return w.status, err
+}
+
+func (w *Watcher) sync(ctx context.Context) (string, int, *state.Plan, error) {
+	digest, files, err := digestDir(w.cfg.Dir)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	w.mu.RLock()
+	applied := w.status.Digest
+	w.mu.RUnlock()
+	if digest == applied || digest == w.failed {
+		return digest, files, nil, nil
+	}
+
+	// Files that don't read or validate are rejected until they change;
+	// other failures are retried on the next check
+	spec, err := state.ReadDir(w.cfg.Dir)
+	if err != nil {
+		w.failed = digest
+		return "", 0, nil, err
+	}
+	plan, err := w.state.Plan(ctx, spec)
+	if errors.Is(err, state.ErrInvalid) {
+		w.failed = digest
+	}
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if err := w.state.Apply(ctx, plan); err != nil {
+		return "", 0, nil, err
+	}
+	w.failed = ""
+
+	slog.Info("rule directory applied",
+		"dir", w.cfg.Dir,
+		"files", files,
+		"rule_changes", len(plan.Rules),
+		"typology_changes", len(plan.Typologies),
+		"unchanged", plan.Unchanged,
+	)
+	return digest, files, plan, nil
+}
+
+// digestDir hashes the names and contents of the directory's rule and
+// typology files, and counts them.
+func digestDir(dir string) (string, int, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return "", 0, err
+	}
+	paths, err := state.SpecFiles(dir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(dir, path)
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), len(paths), nil
+}
@@ -0,0 +1,104 @@
+package ruledir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := ospreytest.NewRepository(nil)
+	engine, err := rules.NewEngine(nil, 1)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	typologies := rules.NewTypologyEngine()
+	w := NewWatcher(domain.RuleDirConfig{Dir: dir}, state.NewManager(repo, engine, typologies))
+
+	write("rules/high-value.yaml", "id: high-value\nname: High value\nexpression: amount > 10000.0\nweight: 1\n")
+	write("typologies/big.yaml", "id: big\nname: Big\nalertThreshold: 0.5\nrules:\n  - ruleId: high-value\n    weight: 1\n")
+	write("README.md", "ignored")
+
+	t.Run("applies the directory", func(t *testing.T) {
+		status, err := w.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if status.Files != 2 || status.Changes == nil || len(status.Changes.Rules) != 1 {
+			t.Errorf("unexpected status: %+v", status)
+		}
+		if engine.RulesCount() != 1 || typologies.TypologyCount() != 1 {
+			t.Errorf("expected 1 rule and 1 typology loaded, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+		}
+		rule, err := repo.GetRuleConfig(ctx, state.GlobalTenantID, "high-value")
+		if err != nil {
+			t.Fatalf("GetRuleConfig failed: %v", err)
+		}
+		if rule.SourceFile != "rules/high-value.yaml" {
+			t.Errorf("expected the source file recorded, got %q", rule.SourceFile)
+		}
+	})
+
+	t.Run("does nothing while unchanged", func(t *testing.T) {
+		before := w.Status()
+		status, err := w.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if status.SyncedAt != before.SyncedAt {
+			t.Errorf("expected no sync, got %+v", status)
+		}
+	})
+
+	t.Run("keeps the configuration on invalid files", func(t *testing.T) {
+		write("rules/high-value.yaml", "id: high-value\nname: High value\nexpression: amount >\n")
+		if _, err := w.Sync(ctx); err == nil {
+			t.Fatal("expected an invalid expression to fail")
+		}
+		// Reported once, then kept in the status until the files change
+		status, err := w.Sync(ctx)
+		if err != nil || status.Error == "" {
+			t.Errorf("expected the error kept in the status only, got %v and %+v", err, status)
+		}
+		if rule, _ := repo.GetRuleConfig(ctx, state.GlobalTenantID, "high-value"); rule.Expression != "amount > 10000.0" {
+			t.Errorf("expected the stored rule unchanged, got %q", rule.Expression)
+		}
+	})
+
+	t.Run("applies fixes and removals", func(t *testing.T) {
+		write("rules/high-value.yaml", "id: high-value\nname: High value\nexpression: amount > 5000.0\nweight: 1\n")
+		write("rules/round.yaml", "id: round\nname: Round\nexpression: int(amount) % 1000 == 0\nweight: 1\n")
+		if err := os.Remove(filepath.Join(dir, "typologies/big.yaml")); err != nil {
+			t.Fatal(err)
+		}
+		status, err := w.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if status.Error != "" || status.Files != 2 {
+			t.Errorf("unexpected status: %+v", status)
+		}
+		if engine.RulesCount() != 2 || typologies.TypologyCount() != 0 {
+			t.Errorf("expected 2 rules and no typologies loaded, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+		}
+	})
+}
@@ -0,0 +1,310 @@
+// Package ruledocs renders a tenant's rule set as a human-readable document
+// for audit submissions: every loaded rule with its expression, bands,
+// weight, owner, typology memberships and version history, and every
+// loaded typology.
+//
+// Documents are built from the rules and typologies the engines are
+// running, not from stored drafts, so they describe what decided the
+// tenant's transactions when they were generated.
+package ruledocs
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Output formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Formats lists the valid output formats.
+var Formats = []string{FormatMarkdown, FormatHTML}
+
+// ValidFormat reports whether f is a known output format.
+func ValidFormat(f string) bool {
+	return slices.Contains(Formats, f)
+}
+
+// Rule scopes.
+const (
+	ScopeGlobal = "global" // A rule every tenant runs unless it overrides it
+	ScopeTenant = "tenant" // The tenant's own rule
+)
+
+// Document is a tenant's documented rule set.
+type Document struct {
+	TenantID      string
+	EngineVersion string
+	GeneratedAt   time.Time
+	Rules         []Rule
+	Typologies    []*domain.Typology
+}
+
+// Rule is a loaded rule with the typologies it feeds and its stored versions.
+type Rule struct {
+	*domain.RuleConfig
+	Scope      string
+	Typologies []Membership
+	History    []*domain.RuleVersion // Oldest first
+}
+
+// Membership is a rule's weight in a typology.
+type Membership struct {
+	TypologyID   string
+	TypologyName string
+	Weight       float64
+}
+
+// Build documents the rules and typologies loaded for a tenant. history
+// holds the stored versions of the tenant's and the global rules; each
+// rule is given the versions of its own scope.
+func Build(tenantID, engineVersion string, rules []*domain.RuleConfig, typologies []*domain.Typology, history []*domain.RuleVersion, now time.Time) *Document {
+	doc := &Document{
+		TenantID:      tenantID,
+		EngineVersion: engineVersion,
+		GeneratedAt:   now.UTC(),
+		Typologies:    slices.Clone(typologies),
+	}
+	sort.Slice(doc.Typologies, func(i, j int) bool { return doc.Typologies[i].ID < doc.Typologies[j].ID })
+
+	for _, cfg := range rules {
+		rule := Rule{RuleConfig: cfg, Scope: scope(cfg.TenantID)}
+		for _, t := range doc.Typologies {
+			for _, member := range t.Rules {
+				if member.RuleID == cfg.ID {
+					rule.Typologies = append(rule.Typologies, Membership{TypologyID: t.ID, TypologyName: t.Name, Weight: member.Weight})
+				}
+			}
+		}
+		for _, v := range history {
+			if v.RuleID == cfg.ID && scope(v.TenantID) == rule.Scope {
+				rule.History = append(rule.History, v)
+			}
+		}
+		doc.Rules = append(doc.Rules, rule)
+	}
+	sort.Slice(doc.Rules, func(i, j int) bool { return doc.Rules[i].ID < doc.Rules[j].ID })
+	return doc
+}
+
+func scope(tenantID string) string {
+	if tenantID == "" || tenantID == "*" {
+		return ScopeGlobal
+	}
+	return ScopeTenant
+}
+
+// Markdown renders the document as Markdown.
+func (d *Document) Markdown() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// HTML renders the document as a standalone HTML page.
+func (d *Document) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render renders the document in the given format.
+func (d *Document) Render(format string) ([]byte, error) {
+	if format == FormatHTML {
+		return d.HTML()
+	}
+	return d.Markdown()
+}
+
+var funcs = map[string]any{
+	"band":     bandRange,
+	"number":   number,
+	"percent":  func(f float64) string { return number(f*100) + "%" },
+	"time":     func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"language": language,
+	"cell":     cell,
+}
+
+// bandRange describes the scores a band matches: lower inclusive, upper
+// exclusive and unbounded when unset.
+func bandRange(b domain.RuleBand) string {
+	lower, upper := "0", "∞"
+	if b.LowerLimit != nil {
+		lower = number(*b.LowerLimit)
+	}
+	if b.UpperLimit != nil {
+		upper = number(*b.UpperLimit)
+	}
+	return "[" + lower + ", " + upper + ")"
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func language(l string) string {
+	if l == "" {
+		return domain.RuleLanguageCEL
+	}
+	return l
+}
+
+// cell escapes text for a Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(`# Rule set of {{.TenantID}}
+
+Generated {{time .GeneratedAt}} from the rules loaded in engine {{.EngineVersion}}. Rules: {{len .Rules}}; typologies: {{len .Typologies}}.
+{{range .Rules}}
+## {{.Name}} ({{.ID}})
+{{if .Description}}
+{{.Description}}
+{{end}}
+| | |
+|---|---|
+| Version | {{.Version}} |
+| Scope | {{.Scope}} |
+| Owner | {{if .Owner}}{{cell .Owner}}{{else}}unassigned{{end}} |
+| Weight | {{number .Weight}} |
+| Mode | {{if .Shadow}}shadow: recorded, excluded from scores and alerts{{else}}live{{end}} |
+{{- if .SampleRate}}
+| Activation sampling | {{percent .SampleRate}} |
+{{- end}}
+
+Expression ({{language .Language}}):
+
+` + "```" + `
+{{.Expression}}
+` + "```" + `
+{{if .Bands}}
+| Score | Outcome | Reason | Action |
+|---|---|---|---|
+{{- range .Bands}}
+| {{band .}} | {{.SubRuleRef}} | {{cell .Reason}} | {{.Action}} |
+{{- end}}
+{{else}}
+No bands: the rule passes or fails on its expression alone.
+{{end}}
+{{- if .Typologies}}
+Typologies: {{range $i, $m := .Typologies}}{{if $i}}, {{end}}{{$m.TypologyName}} ({{$m.TypologyID}}, weight {{number $m.Weight}}){{end}}.
+{{else}}
+Typologies: none.
+{{end}}
+{{- if .History}}
+Version history:
+
+| Version | Owner | Status | Created | Updated |
+|---|---|---|---|---|
+{{- range .History}}
+| {{.Version}} | {{cell .Owner}} | {{if .Enabled}}active{{else}}retired{{end}} | {{time .CreatedAt}} | {{time .UpdatedAt}} |
+{{- end}}
+{{end}}
+{{- end}}
+{{- if .Typologies}}
+# Typologies
+{{range .Typologies}}
+## {{.Name}} ({{.ID}})
+{{if .Description}}
+{{.Description}}
+{{end}}
+Version {{.Version}}, alert threshold {{number .AlertThreshold}}
+{{- if .MinRulesFired}}, at least {{.MinRulesFired}} rules fired{{end}}
+{{- if .MinCoverage}}, at least {{percent .MinCoverage}} of rules evaluated{{end}}.
+{{- if .Condition}} Triggers only if {{.Condition}}.{{end}}
+
+| Rule | Weight |
+|---|---|
+{{- range .Rules}}
+| {{.RuleID}} | {{number .Weight}} |
+{{- end}}
+{{- range .Typologies}}
+| typology {{.TypologyID}} | {{number .Weight}} |
+{{- end}}
+{{end}}
+{{- end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rule set of {{.TenantID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+pre { background: #f5f5f5; padding: 0.6em; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Rule set of {{.TenantID}}</h1>
+<p>Generated {{time .GeneratedAt}} from the rules loaded in engine {{.EngineVersion}}. Rules: {{len .Rules}}; typologies: {{len .Typologies}}.</p>
+{{range .Rules}}
+<section id="rule-{{.ID}}">
+<h2>{{.Name}} ({{.ID}})</h2>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<table>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Scope</th><td>{{.Scope}}</td></tr>
+<tr><th>Owner</th><td>{{if .Owner}}{{.Owner}}{{else}}unassigned{{end}}</td></tr>
+<tr><th>Weight</th><td>{{number .Weight}}</td></tr>
+<tr><th>Mode</th><td>{{if .Shadow}}shadow: recorded, excluded from scores and alerts{{else}}live{{end}}</td></tr>
+{{if .SampleRate}}<tr><th>Activation sampling</th><td>{{percent .SampleRate}}</td></tr>{{end}}
+</table>
+<p>Expression ({{language .Language}}):</p>
+<pre>{{.Expression}}</pre>
+{{if .Bands}}
+<table>
+<tr><th>Score</th><th>Outcome</th><th>Reason</th><th>Action</th></tr>
+{{range .Bands}}<tr><td>{{band .}}</td><td>{{.SubRuleRef}}</td><td>{{.Reason}}</td><td>{{.Action}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No bands: the rule passes or fails on its expression alone.</p>
+{{end}}
+<p>Typologies: {{if .Typologies}}{{range $i, $m := .Typologies}}{{if $i}}, {{end}}<a href="#typology-{{$m.TypologyID}}">{{$m.TypologyName}}</a> (weight {{number $m.Weight}}){{end}}{{else}}none{{end}}.</p>
+{{if .History}}
+<p>Version history:</p>
+<table>
+<tr><th>Version</th><th>Owner</th><th>Status</th><th>Created</th><th>Updated</th></tr>
+{{range .History}}<tr><td>{{.Version}}</td><td>{{.Owner}}</td><td>{{if .Enabled}}active{{else}}retired{{end}}</td><td>{{time .CreatedAt}}</td><td>{{time .UpdatedAt}}</td></tr>
+{{end}}</table>
+{{end}}
+</section>
+{{end}}
+{{if .Typologies}}
+<h1>Typologies</h1>
+{{range .Typologies}}
+<section id="typology-{{.ID}}">
+<h2>{{.Name}} ({{.ID}})</h2>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<p>Version {{.Version}}, alert threshold {{number .AlertThreshold}}
+{{- if .MinRulesFired}}, at least {{.MinRulesFired}} rules fired{{end}}
+{{- if .MinCoverage}}, at least {{percent .MinCoverage}} of rules evaluated{{end}}.
+{{- if .Condition}} Triggers only if {{.Condition}}.{{end}}</p>
+<table>
+<tr><th>Rule</th><th>Weight</th></tr>
+{{range .Rules}}<tr><td><a href="#rule-{{.RuleID}}">{{.RuleID}}</a></td><td>{{number .Weight}}</td></tr>
+{{end}}{{range .Typologies}}<tr><td>typology <a href="#typology-{{.TypologyID}}">{{.TypologyID}}</a></td><td>{{number .Weight}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
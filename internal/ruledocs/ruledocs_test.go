@@ -0,0 +1,73 @@
+package ruledocs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestBuild(t *testing.T) {
+	half := 0.5
+	now := ospreytest.Epoch
+	rules := []*domain.RuleConfig{
+		{ID: "rule-velocity", TenantID: "tenant-001", Name: "Velocity", Version: "2.0.0", Owner: "fraud-ops | EU",
+			Expression: "debtor_tx_count_1h > 10", Weight: 0.6, Shadow: true},
+		{ID: "rule-amount", TenantID: "*", Name: "High Amount", Description: "Large payments <b>", Version: "1.0.0",
+			Expression: "amount > 10000", Weight: 1,
+			Bands: []domain.RuleBand{
+				{UpperLimit: &half, SubRuleRef: domain.RuleOutcomePass, Reason: "small"},
+				{LowerLimit: &half, SubRuleRef: domain.RuleOutcomeFail, Reason: "large", Action: domain.BandActionHold},
+			}},
+	}
+	typologies := []*domain.Typology{
+		{ID: "typology-takeover", Name: "Account Takeover", Version: "1.0.0", AlertThreshold: 0.7, MinRulesFired: 2,
+			Rules: []domain.TypologyRuleWeight{{RuleID: "rule-amount", Weight: 0.4}, {RuleID: "rule-velocity", Weight: 0.6}}},
+	}
+	history := []*domain.RuleVersion{
+		{RuleID: "rule-velocity", TenantID: "tenant-001", Version: "1.0.0", Owner: "fraud-ops", CreatedAt: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-24 * time.Hour)},
+		{RuleID: "rule-velocity", TenantID: "tenant-001", Version: "2.0.0", Owner: "fraud-ops | EU", Enabled: true, CreatedAt: now.Add(-24 * time.Hour), UpdatedAt: now.Add(-24 * time.Hour)},
+		{RuleID: "rule-amount", TenantID: "tenant-001", Version: "0.1.0", CreatedAt: now, UpdatedAt: now}, // An override not loaded
+		{RuleID: "rule-amount", TenantID: "*", Version: "1.0.0", Enabled: true, CreatedAt: now, UpdatedAt: now},
+	}
+
+	doc := Build("tenant-001", "test-v1", rules, typologies, history, now)
+	if len(doc.Rules) != 2 || doc.Rules[0].ID != "rule-amount" || doc.Rules[0].Scope != ScopeGlobal || doc.Rules[1].Scope != ScopeTenant {
+		t.Fatalf("expected both rules by ID with their scopes, got %+v", doc.Rules)
+	}
+	if len(doc.Rules[0].History) != 1 || doc.Rules[0].History[0].Version != "1.0.0" {
+		t.Errorf("expected the global rule's own version only, got %+v", doc.Rules[0].History)
+	}
+	if len(doc.Rules[1].History) != 2 || len(doc.Rules[1].Typologies) != 1 || doc.Rules[1].Typologies[0].Weight != 0.6 {
+		t.Errorf("expected two versions and one typology membership, got %+v", doc.Rules[1])
+	}
+
+	md, err := doc.Markdown()
+	if err != nil {
+		t.Fatalf("Markdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Rule set of tenant-001",
+		"## High Amount (rule-amount)",
+		"| [0.5, ∞) | .fail | large | hold |",
+		"| Owner | fraud-ops \\| EU |",
+		"| Mode | shadow: recorded, excluded from scores and alerts |",
+		"Account Takeover (typology-takeover, weight 0.6)",
+		"| 1.0.0 | fraud-ops | retired | 2025-12-30T00:00:00Z | 2025-12-31T00:00:00Z |",
+		"Version 1.0.0, alert threshold 0.7, at least 2 rules fired.",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("expected the Markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+
+	page, err := doc.Render(FormatHTML)
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	if !strings.Contains(string(page), "Large payments &lt;b&gt;") || !strings.Contains(string(page), `<a href="#typology-typology-takeover">Account Takeover</a>`) {
+		t.Errorf("expected escaped text and typology links, got:\n%s", page)
+	}
+}
@@ -0,0 +1,163 @@
+// Package rulepacks reads and writes rule packs: versioned bundles of rules
+// and typologies that can be exported from one tenant and imported into
+// another. Built-in packs ship embedded in the binary.
+//
+// A pack is a state.Spec with a header, in JSON or YAML:
+//
+//	kind: osprey.rulepack/v1
+//	name: fatf-structuring
+//	version: 1.0.0
+//	rules:
+//	  - id: structuring-001
+//	    ...
+//	typologies:
+//	  - id: typology-structuring
+//	    ...
+package rulepacks
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/internal/yamlsubset"
+)
+
+// Kind identifies the rule pack format and its version.
+const Kind = "osprey.rulepack/v1"
+
+// Encodings of a pack.
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+var (
+	// ErrInvalid is returned when a pack can't be decoded.
+	ErrInvalid = errors.New("invalid rule pack")
+
+	// ErrNotFound is returned by Lookup for an unknown built-in pack.
+	ErrNotFound = errors.New("rule pack not found")
+)
+
+// Pack is a bundle of rules and typologies.
+type Pack struct {
+	Kind        string               `json:"kind"`
+	Name        string               `json:"name"`
+	Version     string               `json:"version,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Source      string               `json:"source,omitempty"` // where the rules come from, e.g. a paper or guidance
+	Rules       []state.RuleSpec     `json:"rules"`
+	Typologies  []state.TypologySpec `json:"typologies"`
+}
+
+// New returns a pack of spec's rules and typologies.
+func New(name, version string, spec *state.Spec) *Pack {
+	return &Pack{
+		Kind:       Kind,
+		Name:       name,
+		Version:    version,
+		Rules:      spec.Rules,
+		Typologies: spec.Typologies,
+	}
+}
+
+// Spec returns the pack's rules and typologies.
+func (p *Pack) Spec() *state.Spec {
+	return &state.Spec{Rules: p.Rules, Typologies: p.Typologies}
+}
+
+// Decode decodes a pack in the given encoding, rejecting unknown fields and
+// other kinds. Failures wrap ErrInvalid. The rules and typologies themselves
+// are validated when the pack is planned.
+func Decode(data []byte, encoding string) (*Pack, error) {
+	var p Pack
+	switch encoding {
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	case YAML:
+		if err := yamlsubset.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+
+	switch {
+	case p.Kind != Kind:
+		return nil, fmt.Errorf("%w: kind must be %s", ErrInvalid, Kind)
+	case p.Name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if p.Rules == nil {
+		p.Rules = []state.RuleSpec{}
+	}
+	if p.Typologies == nil {
+		p.Typologies = []state.TypologySpec{}
+	}
+	return &p, nil
+}
+
+// Encode encodes a pack in the given encoding.
+func Encode(p *Pack, encoding string) ([]byte, error) {
+	switch encoding {
+	case JSON:
+		return json.MarshalIndent(p, "", "  ")
+	case YAML:
+		return yamlsubset.Marshal(p)
+	}
+	return nil, fmt.Errorf("unknown encoding %q", encoding)
+}
+
+//go:embed packs/*.yaml
+var builtin embed.FS
+
+// Builtin returns the built-in packs, sorted by name.
+func Builtin() ([]*Pack, error) {
+	files, err := builtin.ReadDir("packs")
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]*Pack, 0, len(files))
+	for _, f := range files {
+		data, err := builtin.ReadFile(path.Join("packs", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		p, err := Decode(data, YAML)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		if want := strings.TrimSuffix(f.Name(), ".yaml"); p.Name != want {
+			return nil, fmt.Errorf("%s: pack is named %q", f.Name(), p.Name)
+		}
+		packs = append(packs, p)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	return packs, nil
+}
+
+// Lookup returns the built-in pack with the given name.
+func Lookup(name string) (*Pack, error) {
+	packs, err := Builtin()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packs {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
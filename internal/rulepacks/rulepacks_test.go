@@ -0,0 +1,92 @@
+package rulepacks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/internal/state"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestBuiltin(t *testing.T) {
+	packs, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin failed: %v", err)
+	}
+	if len(packs) < 2 {
+		t.Fatalf("expected the built-in packs, got %d", len(packs))
+	}
+
+	engine, err := rules.NewEngine(nil, 1)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	mgr := state.NewManager(ospreytest.NewRepository(nil), engine, nil)
+
+	for _, p := range packs {
+		t.Run(p.Name, func(t *testing.T) {
+			if len(p.Rules) == 0 || len(p.Typologies) == 0 || p.Version == "" {
+				t.Errorf("expected a versioned pack of rules and typologies, got %+v", p)
+			}
+			// Every expression compiles and every typology refers to the pack's rules
+			plan, err := mgr.PlanMerge(context.Background(), "tenant-1", p.Spec())
+			if err != nil {
+				t.Fatalf("PlanMerge failed: %v", err)
+			}
+			if len(plan.Rules) != len(p.Rules) || len(plan.Typologies) != len(p.Typologies) {
+				t.Errorf("expected every rule and typology created, got %+v", plan)
+			}
+
+			for _, encoding := range []string{JSON, YAML} {
+				data, err := Encode(p, encoding)
+				if err != nil {
+					t.Fatalf("Encode(%s) failed: %v", encoding, err)
+				}
+				got, err := Decode(data, encoding)
+				if err != nil {
+					t.Fatalf("Decode(%s) failed: %v\n%s", encoding, err, data)
+				}
+				if !reflect.DeepEqual(got, p) {
+					t.Errorf("%s round trip mismatch:\ngot  %+v\nwant %+v", encoding, got, p)
+				}
+			}
+		})
+	}
+
+	if _, err := Lookup("paysim-fraud"); err != nil {
+		t.Errorf("Lookup failed: %v", err)
+	}
+	if _, err := Lookup("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"wrong kind", "kind: osprey.rulepack/v2\nname: p\n"},
+		{"missing name", "kind: osprey.rulepack/v1\n"},
+		{"unknown field", "kind: osprey.rulepack/v1\nname: p\nrulez: []\n"},
+		{"malformed", "kind: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode([]byte(tt.doc), YAML); !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+
+	p, err := Decode([]byte(`{"kind": "osprey.rulepack/v1", "name": "empty"}`), JSON)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if p.Rules == nil || p.Typologies == nil {
+		t.Errorf("expected empty rule and typology lists, got %+v", p)
+	}
+}
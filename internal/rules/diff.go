@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// RuleDiff summarizes how a rule set changed.
+type RuleDiff struct {
+	Added     []RuleChange `json:"added"`
+	Removed   []RuleChange `json:"removed"`
+	Modified  []RuleChange `json:"modified"`
+	Unchanged int          `json:"unchanged"`
+}
+
+// RuleChange describes one added, removed or modified rule.
+type RuleChange struct {
+	RuleID          string        `json:"ruleId"`
+	TenantID        string        `json:"tenantId,omitempty"` // empty for global rules
+	Name            string        `json:"name"`
+	Version         string        `json:"version,omitempty"`
+	PreviousVersion string        `json:"previousVersion,omitempty"` // Modified only
+	VersionBumped   bool          `json:"versionBumped,omitempty"`   // Modified only
+	Fields          []FieldChange `json:"fields,omitempty"`          // Modified only
+}
+
+// FieldChange is a field whose value differs between two versions of a rule.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Empty reports whether nothing changed.
+func (d *RuleDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// ruleKey identifies a rule within its tenant.
+type ruleKey struct {
+	tenantID string
+	ruleID   string
+}
+
+// DiffRules compares two rule sets by tenant and rule ID. Changes are sorted
+// by tenant, then rule ID.
+func DiffRules(before, after []*domain.RuleConfig) *RuleDiff {
+	diff := &RuleDiff{
+		Added:    []RuleChange{},
+		Removed:  []RuleChange{},
+		Modified: []RuleChange{},
+	}
+
+	old := make(map[ruleKey]*domain.RuleConfig, len(before))
+	for _, cfg := range before {
+		old[ruleKey{scope(cfg.TenantID), cfg.ID}] = cfg
+	}
+	current := make(map[ruleKey]*domain.RuleConfig, len(after))
+	for _, cfg := range after {
+		current[ruleKey{scope(cfg.TenantID), cfg.ID}] = cfg
+	}
+
+	for key, cfg := range current {
+		prev, ok := old[key]
+		if !ok {
+			diff.Added = append(diff.Added, newRuleChange(key, cfg))
+			continue
+		}
+
+		fields := ruleFieldChanges(prev, cfg)
+		if len(fields) == 0 && prev.Version == cfg.Version {
+			diff.Unchanged++
+			continue
+		}
+		change := newRuleChange(key, cfg)
+		change.PreviousVersion = prev.Version
+		change.VersionBumped = prev.Version != cfg.Version
+		change.Fields = fields
+		diff.Modified = append(diff.Modified, change)
+	}
+	for key, cfg := range old {
+		if _, ok := current[key]; !ok {
+			diff.Removed = append(diff.Removed, newRuleChange(key, cfg))
+		}
+	}
+
+	for _, changes := range [][]RuleChange{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].TenantID != changes[j].TenantID {
+				return changes[i].TenantID < changes[j].TenantID
+			}
+			return changes[i].RuleID < changes[j].RuleID
+		})
+	}
+	return diff
+}
+
+// newRuleChange describes a rule; the tenant is omitted for global rules.
+func newRuleChange(key ruleKey, cfg *domain.RuleConfig) RuleChange {
+	change := RuleChange{RuleID: key.ruleID, Name: cfg.Name, Version: cfg.Version}
+	if key.tenantID != GlobalTenantID {
+		change.TenantID = key.tenantID
+	}
+	return change
+}
+
+// ruleFieldChanges lists the fields, other than the version, that differ.
+func ruleFieldChanges(prev, cfg *domain.RuleConfig) []FieldChange {
+	var fields []FieldChange
+	add := func(field string, old, new any) {
+		if !reflect.DeepEqual(old, new) {
+			fields = append(fields, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+
+	add("name", prev.Name, cfg.Name)
+	add("description", prev.Description, cfg.Description)
+	add("owner", prev.Owner, cfg.Owner)
+	add("language", prev.Language, cfg.Language)
+	add("expression", prev.Expression, cfg.Expression)
+	add("bands", prev.Bands, cfg.Bands)
+	add("weight", prev.Weight, cfg.Weight)
+	add("sampleRate", prev.SampleRate, cfg.SampleRate)
+	add("shadow", prev.Shadow, cfg.Shadow)
+	return fields
+}
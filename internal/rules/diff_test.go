@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestReloadRulesDiff(t *testing.T) {
+	engine, err := NewEngine(nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	rule := func(id, version, expr string, weight float64, enabled bool) *domain.RuleConfig {
+		return &domain.RuleConfig{ID: id, Name: "Rule " + id, Version: version, Expression: expr, Weight: weight, Enabled: enabled}
+	}
+
+	diff, err := engine.ReloadRules([]*domain.RuleConfig{
+		rule("rule-a", "1.0.0", "amount > 100.0 ? 1.0 : 0.0", 1, true),
+		rule("rule-b", "1.0.0", "amount > 200.0 ? 1.0 : 0.0", 1, true),
+		rule("rule-c", "1.0.0", "amount > 300.0 ? 1.0 : 0.0", 1, true),
+	})
+	if err != nil {
+		t.Fatalf("ReloadRules failed: %v", err)
+	}
+	if len(diff.Added) != 3 || len(diff.Removed) != 0 || len(diff.Modified) != 0 {
+		t.Fatalf("expected 3 added rules on first load, got %+v", diff)
+	}
+
+	diff, err = engine.ReloadRules([]*domain.RuleConfig{
+		rule("rule-a", "1.0.0", "amount > 100.0 ? 1.0 : 0.0", 1, true),
+		rule("rule-b", "1.1.0", "amount > 250.0 ? 1.0 : 0.0", 0.5, true),
+		rule("rule-c", "1.0.0", "amount > 300.0 ? 1.0 : 0.0", 1, false), // disabled counts as removed
+		rule("rule-d", "1.0.0", "amount > 400.0 ? 1.0 : 0.0", 1, true),
+	})
+	if err != nil {
+		t.Fatalf("ReloadRules failed: %v", err)
+	}
+
+	if diff.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged rule, got %d", diff.Unchanged)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].RuleID != "rule-d" {
+		t.Errorf("expected rule-d added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].RuleID != "rule-c" {
+		t.Errorf("expected rule-c removed, got %+v", diff.Removed)
+	}
+	if len(diff.Modified) != 1 {
+		t.Fatalf("expected 1 modified rule, got %+v", diff.Modified)
+	}
+
+	modified := diff.Modified[0]
+	if modified.RuleID != "rule-b" || !modified.VersionBumped || modified.PreviousVersion != "1.0.0" || modified.Version != "1.1.0" {
+		t.Errorf("unexpected modification: %+v", modified)
+	}
+	if len(modified.Fields) != 2 || modified.Fields[0].Field != "expression" || modified.Fields[1].Field != "weight" {
+		t.Errorf("expected expression and weight changes, got %+v", modified.Fields)
+	}
+
+	t.Run("ChangeWithoutVersionBump", func(t *testing.T) {
+		diff := DiffRules(
+			[]*domain.RuleConfig{rule("rule-a", "1.0.0", "amount > 1.0", 1, true)},
+			[]*domain.RuleConfig{rule("rule-a", "1.0.0", "amount > 2.0", 1, true)},
+		)
+		if len(diff.Modified) != 1 || diff.Modified[0].VersionBumped {
+			t.Errorf("expected modification without version bump, got %+v", diff.Modified)
+		}
+	})
+
+	t.Run("NoChanges", func(t *testing.T) {
+		rules := []*domain.RuleConfig{rule("rule-a", "1.0.0", "amount > 1.0", 1, true)}
+		if diff := DiffRules(rules, rules); !diff.Empty() || diff.Unchanged != 1 {
+			t.Errorf("expected empty diff, got %+v", diff)
+		}
+	})
+}
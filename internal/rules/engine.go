@@ -3,22 +3,57 @@ package rules
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
 	"github.com/opensource-finance/osprey/internal/domain"
 )
 
+// GlobalTenantID scopes rules and typologies to every tenant. Rules and
+// typologies without a tenant ID are global.
+const GlobalTenantID = "*"
+
+// metadataVariables are the metadata keys also set as top-level variables,
+// the debtor's balances before and after the payment.
+var metadataVariables = []string{"old_balance", "new_balance"}
+
+// metadataNumber reads a metadata value as a double.
+func metadataNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
 // Engine is the CEL-based rule evaluation engine.
 type Engine struct {
 	mu             sync.RWMutex
 	env            *cel.Env
-	compiledRules  map[string]*CompiledRule
+	compiledRules  map[string]map[string]*CompiledRule // tenant ID -> rule ID
 	velocityGetter VelocityGetter
+	velocity       domain.VelocityConfig
+	enrichers      []Enricher
+	sampler        Sampler
+	lists          map[string]map[string][]string // tenant ID -> list name -> values
+	windowSource   WindowSource
+	countryRisk    atomic.Pointer[CountryRiskFunc]
 	maxWorkers     int
 }
 
@@ -26,6 +61,10 @@ type Engine struct {
 type CompiledRule struct {
 	Config  *domain.RuleConfig
 	Program cel.Program
+
+	// lookback is the longest window the rule passes to the window
+	// functions; 0 when it calls none
+	lookback time.Duration
 }
 
 // VelocityGetter is a function that returns the transaction count for an entity in a time window.
@@ -37,29 +76,65 @@ func NewEngine(velocityGetter VelocityGetter, maxWorkers int) (*Engine, error) {
 		maxWorkers = 10
 	}
 
-	// Create CEL environment with transaction variables
-	env, err := cel.NewEnv(
+	e := &Engine{
+		compiledRules:  make(map[string]map[string]*CompiledRule),
+		velocityGetter: velocityGetter,
+		maxWorkers:     maxWorkers,
+	}
+
+	// Create CEL environment with transaction variables and helper functions
+	opts := []cel.EnvOption{
 		cel.Variable("tx", cel.MapType(cel.StringType, cel.DynType)),
 		cel.Variable("velocity_count", cel.IntType),
 		cel.Variable("amount", cel.DoubleType),
+		// The exact amount in the currency's minor units, e.g. cents, for
+		// thresholds that float rounding mustn't move
+		cel.Variable("amount_minor", cel.IntType),
 		cel.Variable("currency", cel.StringType),
 		cel.Variable("debtor_id", cel.StringType),
 		cel.Variable("creditor_id", cel.StringType),
 		cel.Variable("tx_type", cel.StringType),
+		cel.Variable("debtor_account_id", cel.StringType),
+		cel.Variable("creditor_account_id", cel.StringType),
+		// Transaction time; timestamp is an alias of tx_timestamp
+		cel.Variable("tx_timestamp", cel.TimestampType),
+		cel.Variable("timestamp", cel.TimestampType),
+		cel.Variable("day_of_week", cel.IntType),
+		// Transaction metadata, e.g. metadata.channel
+		cel.Variable("metadata", cel.MapType(cel.StringType, cel.DynType)),
 		// Balance variables for account drain detection (PaySim pattern)
 		cel.Variable("old_balance", cel.DoubleType),
 		cel.Variable("new_balance", cel.DoubleType),
-	)
+		// Amount components (principal, fee, FX leg)
+		cel.Variable("principal", cel.DoubleType),
+		cel.Variable("fee", cel.DoubleType),
+		cel.Variable("fx_amount", cel.DoubleType),
+		cel.Variable("fx_currency", cel.StringType),
+		cel.Variable("fx_rate", cel.DoubleType),
+	}
+	opts = append(opts, e.functions()...)
+	opts = append(opts, listFunctions()...)
+	env, err := cel.NewEnv(append(opts, windowFunctions()...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
 	}
 
-	return &Engine{
-		env:            env,
-		compiledRules:  make(map[string]*CompiledRule),
-		velocityGetter: velocityGetter,
-		maxWorkers:     maxWorkers,
-	}, nil
+	e.env = env
+	return e, nil
+}
+
+// SetVelocityWindows sets the velocity window used for inputs without one.
+func (e *Engine) SetVelocityWindows(cfg domain.VelocityConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.velocity = cfg
+}
+
+// VelocityWindows returns the velocity window configuration.
+func (e *Engine) VelocityWindows() domain.VelocityConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.velocity
 }
 
 // ValidateRule compiles and validates a rule without mutating loaded engine rules.
@@ -75,7 +150,7 @@ func (e *Engine) ValidateRule(cfg *domain.RuleConfig) error {
 	return err
 }
 
-// LoadRule compiles and loads a rule into the engine.
+// LoadRule compiles and loads a rule into the engine, scoped to its tenant.
 func (e *Engine) LoadRule(cfg *domain.RuleConfig) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -85,7 +160,11 @@ func (e *Engine) LoadRule(cfg *domain.RuleConfig) error {
 		return err
 	}
 
-	e.compiledRules[cfg.ID] = compiled
+	tenantID := scope(cfg.TenantID)
+	if e.compiledRules[tenantID] == nil {
+		e.compiledRules[tenantID] = make(map[string]*CompiledRule)
+	}
+	e.compiledRules[tenantID][cfg.ID] = compiled
 
 	return nil
 }
@@ -104,23 +183,43 @@ func (e *Engine) LoadRules(configs []*domain.RuleConfig) error {
 
 // EvaluateInput holds the transaction data for rule evaluation.
 type EvaluateInput struct {
-	TenantID       string
-	TxID           string
-	Type           string
-	DebtorID       string
-	CreditorID     string
-	Amount         float64
-	Currency       string
-	VelocityWindow int // seconds
-	AdditionalData map[string]any
+	TenantID          string
+	TxID              string
+	Type              string
+	DebtorID          string
+	CreditorID        string
+	DebtorAccountID   string
+	CreditorAccountID string
+	DebtorName        string
+	CreditorName      string
+	DebtorCountry     string
+	CreditorCountry   string
+	Amount            domain.Decimal
+	Currency          string
+	Timestamp         time.Time                // velocity windows end here; zero evaluates at the current time
+	Components        *domain.AmountComponents // nil when the amount has no breakdown
+	VelocityWindow    int                      // seconds; 0 uses the tenant's configured window
+	ReversalOf        string                   // the transaction this one reverses, if any
+	AdditionalData    map[string]any
+	Request           *domain.RequestContext // nil for batch evaluations and queued messages without one
 }
 
-// EvaluateAll evaluates all loaded rules in parallel.
+// EvaluateAll evaluates the input tenant's rules in parallel: its own rules
+// plus the global rules it doesn't override.
 func (e *Engine) EvaluateAll(ctx context.Context, input *EvaluateInput) ([]domain.RuleResult, error) {
+	if !input.Timestamp.IsZero() {
+		ctx = domain.WithEvaluationTime(ctx, input.Timestamp)
+	}
+
 	e.mu.RLock()
-	rules := make([]*CompiledRule, 0, len(e.compiledRules))
-	for _, rule := range e.compiledRules {
-		rules = append(rules, rule)
+	rules := e.tenantRules(input.TenantID)
+	enrichers := e.enrichers
+	sampler := e.sampler
+	lists := e.lists[input.TenantID]
+	windowSource := e.windowSource
+	velocityWindow := input.VelocityWindow
+	if velocityWindow <= 0 {
+		velocityWindow = e.velocity.WindowSeconds(input.TenantID)
 	}
 	e.mu.RUnlock()
 
@@ -128,40 +227,117 @@ func (e *Engine) EvaluateAll(ctx context.Context, input *EvaluateInput) ([]domai
 		return nil, nil
 	}
 
-	// Get velocity count if getter is available
+	// Get velocity count if getter is available; without one it stays 0
 	var velocityCount int64
-	if e.velocityGetter != nil && input.VelocityWindow > 0 {
-		count, err := e.velocityGetter(ctx, input.TenantID, input.DebtorID, input.VelocityWindow)
-		if err == nil {
+	if e.velocityGetter != nil {
+		if input.DebtorID == "" {
+			domain.ReportDegradation(ctx, domain.DegradedVelocity, domain.DegradationSkipped, "no debtor id")
+		} else if count, err := e.velocityGetter(ctx, input.TenantID, input.DebtorID, velocityWindow); err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedVelocity, domain.DegradationFailed, err.Error())
+		} else {
 			velocityCount = count
+			domain.ReportVelocity(ctx, domain.VelocitySnapshot{
+				Variable:      "velocity_count",
+				EntityID:      input.DebtorID,
+				WindowSeconds: velocityWindow,
+				Value:         float64(count),
+			})
 		}
 	}
 
+	// Without a breakdown the whole amount is principal
+	amount := input.Amount.Float64()
+	components := domain.AmountComponents{Principal: input.Amount}
+	if input.Components != nil {
+		components = *input.Components
+	}
+
+	amountMinor := input.Amount.MinorUnits(input.Currency)
+
+	timestamp := evaluationTime(input)
+	metadata := input.AdditionalData
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
 	// Prepare CEL activation variables
 	activation := map[string]any{
 		"tx": map[string]any{
-			"id":          input.TxID,
-			"type":        input.Type,
-			"debtor_id":   input.DebtorID,
-			"creditor_id": input.CreditorID,
-			"amount":      input.Amount,
-			"currency":    input.Currency,
+			"id":           input.TxID,
+			"type":         input.Type,
+			"debtor_id":    input.DebtorID,
+			"creditor_id":  input.CreditorID,
+			"amount":       amount,
+			"amount_minor": amountMinor,
+			"currency":     input.Currency,
+			"timestamp":    timestamp,
+			"principal":    components.Principal.Float64(),
+			"fee":          components.Fee.Float64(),
+			"fx_amount":    components.FXAmount.Float64(),
+			"fx_currency":  components.FXCurrency,
 		},
-		"velocity_count": velocityCount,
-		"amount":         input.Amount,
-		"currency":       input.Currency,
-		"debtor_id":      input.DebtorID,
-		"creditor_id":    input.CreditorID,
-		"tx_type":        input.Type,
+		"velocity_count":      velocityCount,
+		"amount":              amount,
+		"amount_minor":        amountMinor,
+		"currency":            input.Currency,
+		"debtor_id":           input.DebtorID,
+		"creditor_id":         input.CreditorID,
+		"tx_type":             input.Type,
+		"debtor_account_id":   input.DebtorAccountID,
+		"creditor_account_id": input.CreditorAccountID,
+		"tx_timestamp":        timestamp,
+		"timestamp":           timestamp,
+		"day_of_week":         int64(timestamp.Weekday()),
+		"metadata":            metadata,
 		// Balance variables for account drain detection (default to 0 if not provided)
 		"old_balance": 0.0,
 		"new_balance": 0.0,
+		"principal":   components.Principal.Float64(),
+		"fee":         components.Fee.Float64(),
+		"fx_amount":   components.FXAmount.Float64(),
+		"fx_currency": components.FXCurrency,
+		"fx_rate":     components.FXRate,
+	}
+
+	// A few metadata keys are top-level variables too, so callers can send
+	// balances; other keys are only reachable under metadata, and never
+	// shadow the engine's own variables
+	for _, key := range metadataVariables {
+		if v, ok := metadataNumber(metadata[key]); ok {
+			activation[key] = v
+		}
+	}
+
+	// Run enrichers; each falls back to defaults on failure
+	for _, enricher := range enrichers {
+		if err := enricher.Enrich(ctx, input, activation); err != nil {
+			attrs := []any{"enricher", enricher.Name(), "tx_id", input.TxID, "error", err}
+			slog.Warn("enricher failed", append(attrs, input.Request.LogAttrs()...)...)
+			domain.ReportDegradation(ctx, "enricher:"+enricher.Name(), domain.DegradationFailed, err.Error())
+		}
+	}
+
+	// Named lists sit beside the activation so samples leave them out
+	if lists == nil {
+		lists = map[string][]string{}
 	}
 
-	// Merge additional data
-	for k, v := range input.AdditionalData {
-		activation[k] = v
+	// So does the debtor's window, read only when a rule calls the window functions
+	var lookback time.Duration
+	for _, rule := range rules {
+		lookback = max(lookback, rule.lookback)
 	}
+	window := debtorWindowOf(ctx, windowSource, input, lookback, timestamp)
+
+	vars, err := interpreter.NewActivation(activation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build activation: %w", err)
+	}
+	listVars, err := interpreter.NewActivation(map[string]any{namedListsVariable: lists, debtorWindowVariable: window})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build activation: %w", err)
+	}
+	vars = interpreter.NewHierarchicalActivation(vars, listVars)
 
 	// Parallel evaluation using worker pool pattern
 	results := make([]domain.RuleResult, len(rules))
@@ -178,7 +354,8 @@ func (e *Engine) EvaluateAll(ctx context.Context, input *EvaluateInput) ([]domai
 			sem <- struct{}{}        // Acquire
 			defer func() { <-sem }() // Release
 
-			result := e.evaluateRule(ctx, r, activation, input)
+			result := e.evaluateRule(ctx, r, vars, input)
+			sample(ctx, sampler, r, activation, result)
 			results[idx] = result
 		}(i, rule)
 	}
@@ -189,7 +366,7 @@ func (e *Engine) EvaluateAll(ctx context.Context, input *EvaluateInput) ([]domai
 }
 
 // evaluateRule evaluates a single rule and returns the result.
-func (e *Engine) evaluateRule(ctx context.Context, rule *CompiledRule, activation map[string]any, input *EvaluateInput) domain.RuleResult {
+func (e *Engine) evaluateRule(ctx context.Context, rule *CompiledRule, vars interpreter.Activation, input *EvaluateInput) domain.RuleResult {
 	start := time.Now()
 
 	result := domain.RuleResult{
@@ -197,10 +374,11 @@ func (e *Engine) evaluateRule(ctx context.Context, rule *CompiledRule, activatio
 		TenantID: input.TenantID,
 		TxID:     input.TxID,
 		Weight:   rule.Config.Weight,
+		Shadow:   rule.Config.Shadow,
 	}
 
 	// Evaluate CEL expression
-	out, _, err := rule.Program.Eval(activation)
+	out, _, err := rule.Program.Eval(vars)
 	if err != nil {
 		result.SubRuleRef = domain.RuleOutcomeError
 		result.Reason = fmt.Sprintf("evaluation error: %v", err)
@@ -213,7 +391,7 @@ func (e *Engine) evaluateRule(ctx context.Context, rule *CompiledRule, activatio
 	result.Score = score
 
 	// Determine outcome based on bands
-	result.SubRuleRef, result.Reason = matchBand(score, rule.Config.Bands)
+	result.SubRuleRef, result.Reason, result.Action = matchBand(score, rule.Config.Bands)
 	result.ProcessMs = time.Since(start).Milliseconds()
 
 	return result
@@ -239,7 +417,7 @@ func toScore(val ref.Val) float64 {
 // matchBand finds the matching band for a score.
 // Bands are evaluated in order. Use lower inclusive, upper exclusive,
 // except when upper is nil (meaning infinity).
-func matchBand(score float64, bands []domain.RuleBand) (string, string) {
+func matchBand(score float64, bands []domain.RuleBand) (string, string, string) {
 	for _, band := range bands {
 		lower := 0.0
 		hasUpper := band.UpperLimit != nil
@@ -255,7 +433,7 @@ func matchBand(score float64, bands []domain.RuleBand) (string, string) {
 		// Match: lower <= score < upper (or lower <= score if no upper bound)
 		if score >= lower {
 			if !hasUpper || score < upper {
-				return band.SubRuleRef, band.Reason
+				return band.SubRuleRef, band.Reason, band.Action
 			}
 			// Special case: if score equals upper and this is the last band, match it
 			if score == upper && band.UpperLimit != nil {
@@ -266,23 +444,75 @@ func matchBand(score float64, bands []domain.RuleBand) (string, string) {
 	}
 
 	// Default to pass if no band matches
-	return domain.RuleOutcomePass, "no matching band"
+	return domain.RuleOutcomePass, "no matching band", ""
+}
+
+// tenantRules returns the rules that apply to a tenant: its own rules and the
+// global rules with IDs it doesn't use. Callers must hold e.mu.
+func (e *Engine) tenantRules(tenantID string) []*CompiledRule {
+	global := e.compiledRules[GlobalTenantID]
+	var own map[string]*CompiledRule
+	if tenantID = scope(tenantID); tenantID != GlobalTenantID {
+		own = e.compiledRules[tenantID]
+	}
+
+	rules := make([]*CompiledRule, 0, len(global)+len(own))
+	for id, rule := range global {
+		if _, overridden := own[id]; !overridden {
+			rules = append(rules, rule)
+		}
+	}
+	for _, rule := range own {
+		rules = append(rules, rule)
+	}
+	return rules
 }
 
-// RulesCount returns the number of loaded rules.
+// scope returns the tenant a rule or typology applies to.
+func scope(tenantID string) string {
+	if tenantID == "" {
+		return GlobalTenantID
+	}
+	return tenantID
+}
+
+// RulesCount returns the number of loaded rules across all tenants.
 func (e *Engine) RulesCount() int {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return len(e.compiledRules)
+
+	count := 0
+	for _, rules := range e.compiledRules {
+		count += len(rules)
+	}
+	return count
+}
+
+// ActiveRulesCount returns the number of loaded rules across all tenants
+// that score transactions: enabled and not in shadow mode.
+func (e *Engine) ActiveRulesCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	count := 0
+	for _, rules := range e.compiledRules {
+		for _, rule := range rules {
+			if rule.Config.Enabled && !rule.Config.Shadow {
+				count++
+			}
+		}
+	}
+	return count
 }
 
-// ReloadRules clears all existing rules and loads new ones.
-// This enables hot-reloading of rules from the database.
-func (e *Engine) ReloadRules(configs []*domain.RuleConfig) error {
+// ReloadRules clears all existing rules and loads new ones, for every tenant.
+// This enables hot-reloading of rules from the database. The returned diff
+// compares the enabled rules loaded before and after.
+func (e *Engine) ReloadRules(configs []*domain.RuleConfig) (*RuleDiff, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	newRules := make(map[string]*CompiledRule)
+	newRules := make(map[string]map[string]*CompiledRule)
 
 	// Load new rules
 	for _, cfg := range configs {
@@ -292,24 +522,53 @@ func (e *Engine) ReloadRules(configs []*domain.RuleConfig) error {
 
 		compiled, err := e.compileRule(cfg)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		newRules[cfg.ID] = compiled
+		tenantID := scope(cfg.TenantID)
+		if newRules[tenantID] == nil {
+			newRules[tenantID] = make(map[string]*CompiledRule)
+		}
+		newRules[tenantID][cfg.ID] = compiled
 	}
 
+	diff := DiffRules(ruleConfigs(e.compiledRules), ruleConfigs(newRules))
 	e.compiledRules = newRules
 
-	return nil
+	return diff, nil
 }
 
-// GetLoadedRules returns the currently loaded rule configurations.
+// ruleConfigs returns the configurations of compiled rules.
+func ruleConfigs(compiled map[string]map[string]*CompiledRule) []*domain.RuleConfig {
+	var configs []*domain.RuleConfig
+	for _, rules := range compiled {
+		for _, rule := range rules {
+			configs = append(configs, rule.Config)
+		}
+	}
+	return configs
+}
+
+// GetLoadedRules returns the currently loaded rule configurations of every tenant.
 func (e *Engine) GetLoadedRules() []*domain.RuleConfig {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	rules := make([]*domain.RuleConfig, 0, len(e.compiledRules))
-	for _, compiled := range e.compiledRules {
-		rules = append(rules, compiled.Config)
+	rules := ruleConfigs(e.compiledRules)
+	if rules == nil {
+		rules = []*domain.RuleConfig{}
+	}
+	return rules
+}
+
+// GetTenantRules returns the rule configurations that apply to a tenant.
+func (e *Engine) GetTenantRules(tenantID string) []*domain.RuleConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	compiled := e.tenantRules(tenantID)
+	rules := make([]*domain.RuleConfig, 0, len(compiled))
+	for _, rule := range compiled {
+		rules = append(rules, rule.Config)
 	}
 	return rules
 }
@@ -318,12 +577,31 @@ func (e *Engine) GetLoadedRules() []*domain.RuleConfig {
 func (e *Engine) Close() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.compiledRules = make(map[string]*CompiledRule)
+	e.compiledRules = make(map[string]map[string]*CompiledRule)
 	return nil
 }
 
 func (e *Engine) compileRule(cfg *domain.RuleConfig) (*CompiledRule, error) {
-	ast, issues := e.env.Compile(cfg.Expression)
+	for i, band := range cfg.Bands {
+		if band.Action != "" && !domain.ValidBandAction(band.Action) {
+			return nil, fmt.Errorf("rule %s: band %d: action must be one of: %s", cfg.ID, i, strings.Join(domain.BandActions, ", "))
+		}
+	}
+
+	expression := cfg.Expression
+	switch cfg.Language {
+	case "", domain.RuleLanguageCEL:
+	case domain.RuleLanguageExpr:
+		translated, err := translateExpr(cfg.Expression, exprVariables(e.env))
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate expr rule %s: %w", cfg.ID, err)
+		}
+		expression = translated
+	default:
+		return nil, fmt.Errorf("rule %s: language must be one of: %s", cfg.ID, strings.Join(domain.RuleLanguages, ", "))
+	}
+
+	ast, issues := e.env.Compile(expression)
 	if issues != nil && issues.Err() != nil {
 		return nil, fmt.Errorf("failed to compile rule %s: %w", cfg.ID, issues.Err())
 	}
@@ -333,13 +611,19 @@ func (e *Engine) compileRule(cfg *domain.RuleConfig) (*CompiledRule, error) {
 		return nil, fmt.Errorf("rule %s: expression must return bool, int, or double, got %s", cfg.ID, outputType)
 	}
 
+	lookback, err := windowLookback(ast)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", cfg.ID, err)
+	}
+
 	program, err := e.env.Program(ast)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create program for rule %s: %w", cfg.ID, err)
 	}
 
 	return &CompiledRule{
-		Config:  cfg,
-		Program: program,
+		Config:   cfg,
+		Program:  program,
+		lookback: lookback,
 	}, nil
 }
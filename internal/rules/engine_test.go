@@ -3,10 +3,12 @@ package rules
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/cel-go/cel"
 	"github.com/opensource-finance/osprey/internal/domain"
 )
 
@@ -43,6 +45,15 @@ func TestLoadRule(t *testing.T) {
 	if engine.RulesCount() != 1 {
 		t.Errorf("expected 1 rule, got %d", engine.RulesCount())
 	}
+
+	shadow := *rule
+	shadow.ID, shadow.Shadow = "test-rule-002", true
+	if err := engine.LoadRule(&shadow); err != nil {
+		t.Fatalf("failed to load shadow rule: %v", err)
+	}
+	if engine.RulesCount() != 2 || engine.ActiveRulesCount() != 1 {
+		t.Errorf("expected 2 rules of which 1 active, got %d and %d", engine.RulesCount(), engine.ActiveRulesCount())
+	}
 }
 
 func TestLoadInvalidRule(t *testing.T) {
@@ -89,7 +100,7 @@ func TestEvaluateSimpleRule(t *testing.T) {
 	input := &EvaluateInput{
 		TenantID: "tenant-001",
 		TxID:     "tx-001",
-		Amount:   500.0,
+		Amount:   domain.MustDecimal("500.0"),
 		Currency: "USD",
 	}
 
@@ -110,7 +121,7 @@ func TestEvaluateSimpleRule(t *testing.T) {
 	}
 
 	// Test with high amount
-	input.Amount = 5000.0
+	input.Amount = domain.MustDecimal("5000.0")
 	results, _ = engine.EvaluateAll(ctx, input)
 
 	if results[0].Score != 1.0 {
@@ -197,6 +208,7 @@ func TestVelocityRule(t *testing.T) {
 		VelocityWindow: 3600, // 1 hour
 	}
 
+	ctx, velocity := domain.WithVelocitySnapshots(ctx)
 	results, _ := engine.EvaluateAll(ctx, input)
 
 	// With 15 transactions (> 10), should return 1.0 (fail)
@@ -206,6 +218,12 @@ func TestVelocityRule(t *testing.T) {
 	if results[0].SubRuleRef != domain.RuleOutcomeFail {
 		t.Errorf("expected FAIL for high velocity, got %s", results[0].SubRuleRef)
 	}
+
+	// The count the rule saw is recorded for the evaluation
+	want := domain.VelocitySnapshot{Variable: "velocity_count", EntityID: "user-001", WindowSeconds: 3600, Value: 15}
+	if snapshots := velocity.List(); len(snapshots) != 1 || snapshots[0] != want {
+		t.Errorf("expected snapshot %+v, got %+v", want, snapshots)
+	}
 }
 
 func TestParallelExecution(t *testing.T) {
@@ -232,7 +250,7 @@ func TestParallelExecution(t *testing.T) {
 	input := &EvaluateInput{
 		TenantID: "tenant-001",
 		TxID:     "tx-001",
-		Amount:   100.0,
+		Amount:   domain.MustDecimal("100.0"),
 	}
 
 	results, err := engine.EvaluateAll(ctx, input)
@@ -327,14 +345,14 @@ func TestHighValueTransferRule(t *testing.T) {
 	ctx := context.Background()
 
 	// Low value
-	input := &EvaluateInput{TenantID: "t1", TxID: "tx1", Amount: 500.0}
+	input := &EvaluateInput{TenantID: "t1", TxID: "tx1", Amount: domain.MustDecimal("500.0")}
 	results, _ := engine.EvaluateAll(ctx, input)
 	if results[0].SubRuleRef != domain.RuleOutcomePass {
 		t.Errorf("expected PASS for low value, got %s", results[0].SubRuleRef)
 	}
 
 	// High value
-	input.Amount = 15000.0
+	input.Amount = domain.MustDecimal("15000.0")
 	results, _ = engine.EvaluateAll(ctx, input)
 	if results[0].SubRuleRef != domain.RuleOutcomeReview {
 		t.Errorf("expected REVIEW for high value, got %s", results[0].SubRuleRef)
@@ -390,6 +408,7 @@ func TestRuleResultMetadata(t *testing.T) {
 		Expression: "amount > 0.0",
 		Weight:     0.75,
 		Enabled:    true,
+		Shadow:     true,
 	}
 	engine.LoadRule(rule)
 
@@ -397,7 +416,7 @@ func TestRuleResultMetadata(t *testing.T) {
 	input := &EvaluateInput{
 		TenantID: "tenant-123",
 		TxID:     "tx-456",
-		Amount:   100.0,
+		Amount:   domain.MustDecimal("100.0"),
 	}
 
 	results, _ := engine.EvaluateAll(ctx, input)
@@ -417,5 +436,500 @@ func TestRuleResultMetadata(t *testing.T) {
 	if results[0].ProcessMs < 0 {
 		t.Error("ProcessMs should be non-negative")
 	}
+	if !results[0].Shadow {
+		t.Error("expected Shadow to be carried from the rule config")
+	}
+}
+
+func TestBandActions(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+
+	zero := 0.0
+	one := 1.0
+
+	rule := &domain.RuleConfig{
+		ID:         "card-not-present",
+		Expression: "amount > 1000.0 ? 1.0 : 0.0",
+		Bands: []domain.RuleBand{
+			{LowerLimit: &zero, UpperLimit: &one, SubRuleRef: domain.RuleOutcomePass, Reason: "Low amount"},
+			{LowerLimit: &one, SubRuleRef: domain.RuleOutcomeReview, Reason: "High amount", Action: domain.BandActionStepUpAuth},
+		},
+		Weight:  1.0,
+		Enabled: true,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	results, _ := engine.EvaluateAll(ctx, &EvaluateInput{TenantID: "tenant-001", TxID: "tx-001", Amount: domain.MustDecimal("5000.0")})
+	if results[0].Action != domain.BandActionStepUpAuth {
+		t.Errorf("expected the matched band's action, got %q", results[0].Action)
+	}
+	results, _ = engine.EvaluateAll(ctx, &EvaluateInput{TenantID: "tenant-001", TxID: "tx-002", Amount: domain.MustDecimal("50.0")})
+	if results[0].Action != "" {
+		t.Errorf("expected no action for a band without one, got %q", results[0].Action)
+	}
+
+	invalid := *rule
+	invalid.Bands = []domain.RuleBand{{SubRuleRef: domain.RuleOutcomeFail, Action: "block_forever"}}
+	if err := engine.ValidateRule(&invalid); err == nil {
+		t.Error("expected an unknown action to be rejected")
+	}
+}
+
+func TestAmountComponentVariables(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+
+	rule := &domain.RuleConfig{
+		ID:         "fee-ratio",
+		Expression: "fee / principal > 0.1",
+		Weight:     1.0,
+		Enabled:    true,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+
+	t.Run("DisproportionateFee", func(t *testing.T) {
+		input := &EvaluateInput{
+			TenantID: "tenant-001",
+			TxID:     "tx-fee",
+			Amount:   domain.MustDecimal("120.0"),
+			Components: &domain.AmountComponents{
+				Principal: domain.MustDecimal("100.0"),
+				Fee:       domain.MustDecimal("20.0"),
+			},
+		}
+		results, _ := engine.EvaluateAll(ctx, input)
+		if results[0].Score != 1.0 {
+			t.Errorf("expected score 1.0 for 20%% fee, got %.2f", results[0].Score)
+		}
+	})
+
+	t.Run("DefaultsWithoutComponents", func(t *testing.T) {
+		input := &EvaluateInput{
+			TenantID: "tenant-001",
+			TxID:     "tx-plain",
+			Amount:   domain.MustDecimal("100.0"),
+		}
+		results, _ := engine.EvaluateAll(ctx, input)
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 0.0 {
+			t.Errorf("expected score 0.0 when principal defaults to amount, got %.2f", results[0].Score)
+		}
+	})
+}
+
+func TestAmountMinorUnits(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+
+	rule := &domain.RuleConfig{
+		ID:         "amount-minor",
+		Expression: "amount_minor",
+		Weight:     1.0,
+		Enabled:    true,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		want     float64
+	}{
+		{"10000.005", "USD", 1000001}, // 10000.004999999999 as a float
+		{"10000.004999", "USD", 1000000},
+		{"-0.5", "USD", -50},
+		{"1.5e3", "EUR", 150000},
+		{"50000", "JPY", 50000},
+		{"49999.5", "JPY", 50000},
+		{"1.2345", "KWD", 1235},
+	} {
+		results, _ := engine.EvaluateAll(ctx, &EvaluateInput{
+			TenantID: "tenant-001",
+			TxID:     "tx-minor",
+			Amount:   domain.MustDecimal(tc.amount),
+			Currency: tc.currency,
+		})
+		if results[0].Score != tc.want {
+			t.Errorf("expected amount_minor %v for %s %s, got %v", tc.want, tc.amount, tc.currency, results[0].Score)
+		}
+	}
+}
+
+// recordingSampler collects samples for tests.
+type recordingSampler struct {
+	mu      sync.Mutex
+	samples []*domain.ActivationSample
+}
+
+func (s *recordingSampler) Sample(ctx context.Context, sample *domain.ActivationSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples = append(s.samples, sample)
+}
+
+func TestRuleSampling(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+
+	sampler := &recordingSampler{}
+	engine.SetSampler(sampler)
+
+	for _, rule := range []*domain.RuleConfig{
+		{ID: "sampled", Name: "Sampled", Expression: "amount > 1000.0", Version: "1.0.0", Enabled: true, SampleRate: 1},
+		{ID: "unsampled", Name: "Unsampled", Expression: "amount > 1000.0", Version: "1.0.0", Enabled: true},
+	} {
+		if err := engine.LoadRule(rule); err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+	}
+
+	input := &EvaluateInput{TenantID: "tenant-001", TxID: "tx-001", Amount: domain.MustDecimal("5000.0"), Currency: "USD", DebtorID: "user-123"}
+	if _, err := engine.EvaluateAll(context.Background(), input); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if len(sampler.samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(sampler.samples))
+	}
+	got := sampler.samples[0]
+	if got.RuleID != "sampled" || got.TenantID != "tenant-001" || got.TxID != "tx-001" || got.RuleVersion != "1.0.0" {
+		t.Errorf("unexpected sample: %+v", got)
+	}
+	if got.Activation["amount"] != 5000.0 || got.Activation["debtor_id"] != "user-123" {
+		t.Errorf("expected the rule's activation, got %v", got.Activation)
+	}
+}
+
+func TestVelocityWindows(t *testing.T) {
+	var windows []int
+	getter := func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+		windows = append(windows, windowSecs)
+		return 1, nil
+	}
+	engine, _ := NewEngine(getter, 1)
+	defer engine.Close()
+	engine.LoadRule(&domain.RuleConfig{ID: "velocity", Expression: "velocity_count > 0 ? 1.0 : 0.0", Enabled: true})
+
+	evaluate := func(tenantID string, window int) int {
+		t.Helper()
+		windows = nil
+		if _, err := engine.EvaluateAll(context.Background(), &EvaluateInput{TenantID: tenantID, TxID: "tx-001", DebtorID: "user-001", VelocityWindow: window}); err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		if len(windows) != 1 {
+			t.Fatalf("expected one velocity lookup, got %d", len(windows))
+		}
+		return windows[0]
+	}
+
+	if got := evaluate("tenant-001", 0); got != 3600 {
+		t.Errorf("expected the 1h default without configuration, got %d", got)
+	}
+
+	engine.SetVelocityWindows(domain.VelocityConfig{
+		DefaultWindow: 30 * time.Minute,
+		TenantWindows: map[string]time.Duration{"tenant-002": 24 * time.Hour},
+	})
+	if got := evaluate("tenant-001", 0); got != 1800 {
+		t.Errorf("expected the configured default, got %d", got)
+	}
+	if got := evaluate("tenant-002", 0); got != 86400 {
+		t.Errorf("expected the tenant's window, got %d", got)
+	}
+	if got := evaluate("tenant-002", 600); got != 600 {
+		t.Errorf("expected the input's window to win, got %d", got)
+	}
+}
+
+type failingEnricher struct{}
+
+func (failingEnricher) Name() string                    { return "failing" }
+func (failingEnricher) Variables() map[string]*cel.Type { return nil }
+func (failingEnricher) Enrich(ctx context.Context, input *EvaluateInput, activation map[string]any) error {
+	return fmt.Errorf("lookup timed out")
 }
 
+func TestDegradations(t *testing.T) {
+	getter := func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+		return 0, fmt.Errorf("database unavailable")
+	}
+	engine, _ := NewEngine(getter, 2)
+	defer engine.Close()
+	engine.LoadRule(&domain.RuleConfig{ID: "velocity", Expression: "velocity_count > 5 ? 1.0 : 0.0", Enabled: true})
+
+	evaluate := func(input *EvaluateInput) []domain.Degradation {
+		t.Helper()
+		ctx, degradations := domain.WithDegradations(context.Background())
+		if _, err := engine.EvaluateAll(ctx, input); err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		return degradations.List()
+	}
+
+	t.Run("VelocityFailed", func(t *testing.T) {
+		got := evaluate(&EvaluateInput{TenantID: "tenant-001", TxID: "tx-001", DebtorID: "user-001"})
+		want := []domain.Degradation{{Component: domain.DegradedVelocity, Status: domain.DegradationFailed, Reason: "database unavailable"}}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("VelocitySkipped", func(t *testing.T) {
+		got := evaluate(&EvaluateInput{TenantID: "tenant-001", TxID: "tx-002"})
+		if len(got) != 1 || got[0].Component != domain.DegradedVelocity || got[0].Status != domain.DegradationSkipped {
+			t.Errorf("expected skipped velocity, got %v", got)
+		}
+	})
+
+	t.Run("EnricherFailed", func(t *testing.T) {
+		if err := engine.RegisterEnricher(failingEnricher{}); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		got := evaluate(&EvaluateInput{TenantID: "tenant-001", TxID: "tx-003"})
+		found := false
+		for _, d := range got {
+			if d.Component == "enricher:failing" && d.Status == domain.DegradationFailed {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected failed enricher, got %v", got)
+		}
+	})
+
+	t.Run("NoCollector", func(t *testing.T) {
+		if _, err := engine.EvaluateAll(context.Background(), &EvaluateInput{TenantID: "tenant-001", TxID: "tx-004"}); err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+	})
+}
+
+func TestTenantRules(t *testing.T) {
+	engine, _ := NewEngine(nil, 2)
+	defer engine.Close()
+
+	engine.LoadRules([]*domain.RuleConfig{
+		{ID: "high-value", Expression: "amount > 1000.0", Weight: 1.0, Enabled: true},
+		{ID: "round-amount", TenantID: GlobalTenantID, Expression: "amount == 5000.0", Weight: 1.0, Enabled: true},
+		{ID: "high-value", TenantID: "tenant-a", Expression: "amount > 10000.0", Weight: 1.0, Enabled: true},
+		{ID: "usd-only", TenantID: "tenant-a", Expression: "currency != 'USD'", Weight: 1.0, Enabled: true},
+	})
+
+	scores := func(tenantID string) map[string]float64 {
+		t.Helper()
+		results, err := engine.EvaluateAll(context.Background(), &EvaluateInput{TenantID: tenantID, TxID: "tx-001", Amount: domain.MustDecimal("5000"), Currency: "USD"})
+		if err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		out := make(map[string]float64, len(results))
+		for _, r := range results {
+			out[r.RuleID] = r.Score
+		}
+		return out
+	}
+
+	t.Run("GlobalFallback", func(t *testing.T) {
+		got := scores("tenant-b")
+		if len(got) != 2 || got["high-value"] != 1.0 || got["round-amount"] != 1.0 {
+			t.Errorf("expected the global rules, got %v", got)
+		}
+	})
+
+	t.Run("TenantOverride", func(t *testing.T) {
+		got := scores("tenant-a")
+		if len(got) != 3 {
+			t.Fatalf("expected global and tenant rules, got %v", got)
+		}
+		if got["high-value"] != 0.0 {
+			t.Errorf("expected the tenant's high-value rule to override the global one, got %v", got["high-value"])
+		}
+		if got["round-amount"] != 1.0 {
+			t.Errorf("expected the global round-amount rule, got %v", got["round-amount"])
+		}
+	})
+
+	t.Run("Listing", func(t *testing.T) {
+		if engine.RulesCount() != 4 {
+			t.Errorf("expected 4 loaded rules, got %d", engine.RulesCount())
+		}
+		if got := len(engine.GetTenantRules("tenant-a")); got != 3 {
+			t.Errorf("expected 3 rules for tenant-a, got %d", got)
+		}
+		if got := len(engine.GetTenantRules("tenant-b")); got != 2 {
+			t.Errorf("expected 2 rules for tenant-b, got %d", got)
+		}
+	})
+
+	t.Run("ReloadDiff", func(t *testing.T) {
+		diff, err := engine.ReloadRules([]*domain.RuleConfig{
+			{ID: "high-value", TenantID: GlobalTenantID, Expression: "amount > 1000.0", Weight: 1.0, Enabled: true},
+			{ID: "round-amount", TenantID: GlobalTenantID, Expression: "amount == 5000.0", Weight: 1.0, Enabled: true},
+			{ID: "high-value", TenantID: "tenant-a", Expression: "amount > 10000.0", Weight: 1.0, Enabled: true},
+		})
+		if err != nil {
+			t.Fatalf("ReloadRules failed: %v", err)
+		}
+		if len(diff.Removed) != 1 || diff.Removed[0].RuleID != "usd-only" || diff.Removed[0].TenantID != "tenant-a" {
+			t.Errorf("expected tenant-a's usd-only rule removed, got %+v", diff.Removed)
+		}
+		if diff.Unchanged != 3 {
+			t.Errorf("expected 3 unchanged rules, got %d", diff.Unchanged)
+		}
+	})
+}
+
+func TestTimestampAndMetadataVariables(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+
+	// Type-checked at load time rather than failing at runtime
+	if err := engine.LoadRule(&domain.RuleConfig{ID: "bad-day", Expression: `day_of_week == "monday"`, Enabled: true}); err == nil {
+		t.Error("expected comparing day_of_week with a string to fail to compile")
+	}
+
+	rule := &domain.RuleConfig{
+		ID:         "night-web-transfer",
+		Expression: `tx_timestamp.getHours("Europe/Paris") >= 1 && tx_timestamp.getHours("Europe/Paris") < 5 && day_of_week == 6 && metadata.channel == "web" && tx.timestamp == timestamp`,
+		Enabled:    true,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	evaluate := func(at time.Time, metadata map[string]any) domain.RuleResult {
+		t.Helper()
+		results, err := engine.EvaluateAll(ctx, &EvaluateInput{
+			TenantID:       "tenant-001",
+			TxID:           "tx-001",
+			Amount:         domain.MustDecimal("100"),
+			Timestamp:      at,
+			AdditionalData: metadata,
+		})
+		if err != nil || len(results) != 1 {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		return results[0]
+	}
+
+	// Saturday 01:30 UTC is 02:30 in Paris
+	saturday := time.Date(2026, 3, 14, 1, 30, 0, 0, time.UTC)
+	if result := evaluate(saturday, map[string]any{"channel": "web"}); result.Score != 1 {
+		t.Errorf("expected a night-time web transfer to match, got %+v", result)
+	}
+	if result := evaluate(saturday.Add(6*time.Hour), map[string]any{"channel": "web"}); result.Score != 0 {
+		t.Errorf("expected a morning transfer not to match, got %+v", result)
+	}
+	// A key the transaction doesn't carry is an error unless guarded with has()
+	if result := evaluate(saturday, nil); result.SubRuleRef != domain.RuleOutcomeError {
+		t.Errorf("expected a missing metadata key to be an evaluation error, got %+v", result)
+	}
+
+	// Metadata never shadows the engine's variables; only the balances are
+	// also top-level
+	if err := engine.LoadRule(&domain.RuleConfig{
+		ID:         "core-variables",
+		Expression: `amount == 100.0 && velocity_count == 0 && tx_timestamp == timestamp && old_balance == 500.0 && metadata.amount == 1.0`,
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+	results, err := engine.EvaluateAll(ctx, &EvaluateInput{
+		TenantID:  "tenant-001",
+		TxID:      "tx-002",
+		Amount:    domain.MustDecimal("100"),
+		Timestamp: saturday,
+		AdditionalData: map[string]any{
+			"amount":         1.0,
+			"velocity_count": int64(99),
+			"tx_timestamp":   "not a timestamp",
+			"old_balance":    500,
+		},
+	})
+	if err != nil {
+		t.Fatalf("EvaluateAll failed: %v", err)
+	}
+	matched := false
+	for _, result := range results {
+		if result.RuleID == "core-variables" {
+			matched = result.Score == 1
+		}
+	}
+	if !matched {
+		t.Errorf("expected metadata not to shadow core variables, got %+v", results)
+	}
+}
+
+func TestWindowFunctions(t *testing.T) {
+	engine, _ := NewEngine(nil, 2)
+	defer engine.Close()
+
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	var since time.Time
+	engine.SetWindowSource(func(ctx context.Context, tenantID, debtorID string, from time.Time) ([]*domain.Transaction, error) {
+		since = from
+		payment := func(id string, amount string, age time.Duration) *domain.Transaction {
+			return &domain.Transaction{ID: id, DebtorID: debtorID, Amount: domain.MustDecimal(amount), Timestamp: now.Add(-age)}
+		}
+		return []*domain.Transaction{
+			payment("tx-1", "2900", 2*time.Hour),
+			payment("tx-2", "2950", 5*time.Hour),
+			payment("tx-3", "9000", 6*time.Hour), // not below the threshold
+			payment("tx-4", "2800", 20*time.Hour),
+			payment("tx-5", "2500", 30*time.Hour), // outside 24h
+			payment("tx-now", "2700", 0),          // the evaluated transaction, stored first
+		}, nil
+	})
+
+	if err := engine.LoadRule(&domain.RuleConfig{
+		ID:         "structuring",
+		Expression: "window_sum_below(3000, 86400) >= 10000.0 && window_count_below(3000.0, 86400) >= 4",
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	evaluate := func(amount string) float64 {
+		t.Helper()
+		results, err := engine.EvaluateAll(context.Background(), &EvaluateInput{
+			TenantID: "tenant-001", TxID: "tx-now", DebtorID: "user-001", Amount: domain.MustDecimal(amount), Timestamp: now,
+		})
+		if err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		return results[0].Score
+	}
+
+	// 2900 + 2950 + 2800 + 2700 = 11,350 over four payments
+	if got := evaluate("2700"); got != 1.0 {
+		t.Errorf("expected four payments under 3000 summing past 10000 to fire, got %v", got)
+	}
+	if !since.Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("expected payments to be read over the rule's 24h window, got since %v", since)
+	}
+	if got := evaluate("3100"); got != 0.0 {
+		t.Errorf("expected three payments under 3000 not to fire, got %v", got)
+	}
+
+	for _, expression := range []string{
+		"window_sum_below(3000.0, velocity_count) > 0.0",
+		"window_count_below(3000.0, 0) > 0",
+		"window_count_below(3000.0, 31536000) > 0",
+	} {
+		if err := engine.ValidateRule(&domain.RuleConfig{ID: "invalid", Expression: expression}); err == nil {
+			t.Errorf("expected %q to be rejected", expression)
+		}
+	}
+}
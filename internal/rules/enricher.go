@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Enricher adds derived variables to the CEL activation before rules are evaluated.
+// Variables declares the CEL type of every key the enricher sets so rules that
+// reference them type-check at compile time. Enrich must populate every declared
+// variable, falling back to neutral defaults when its data source is unavailable.
+type Enricher interface {
+	// Name identifies the enricher in logs.
+	Name() string
+
+	// Variables returns the CEL variable declarations provided by this enricher.
+	Variables() map[string]*cel.Type
+
+	// Enrich sets the declared variables on the activation for one transaction.
+	Enrich(ctx context.Context, input *EvaluateInput, activation map[string]any) error
+}
+
+// RegisterEnricher declares an enricher's variables in the CEL environment and
+// runs it on every subsequent evaluation. Register enrichers before loading rules
+// that reference their variables. Variables may not redeclare built-in or
+// another enricher's variables.
+func (e *Engine) RegisterEnricher(enricher Enricher) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	declared := make(map[string]bool)
+	for _, v := range e.env.Variables() {
+		declared[v.Name()] = true
+	}
+
+	var opts []cel.EnvOption
+	for name, typ := range enricher.Variables() {
+		if declared[name] {
+			return fmt.Errorf("failed to register enricher %s: variable %q is already declared", enricher.Name(), name)
+		}
+		opts = append(opts, cel.Variable(name, typ))
+	}
+
+	env, err := e.env.Extend(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to register enricher %s: %w", enricher.Name(), err)
+	}
+
+	e.env = env
+	e.enrichers = append(e.enrichers, enricher)
+	return nil
+}
+
+// Enrichers returns the names of registered enrichers in registration order.
+func (e *Engine) Enrichers() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make([]string, len(e.enrichers))
+	for i, enricher := range e.enrichers {
+		names[i] = enricher.Name()
+	}
+	return names
+}
@@ -0,0 +1,439 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// translateExpr translates an expr-lang/expr expression to CEL, so rules
+// migrated from engines built on Expr run on the CEL engine unchanged.
+//
+// It covers the common subset: literals, variables, member and index access,
+// arithmetic, comparisons, and/or/not, the ternary operator, in and not in,
+// matches, contains, startsWith, endsWith, and the len, abs, int, float and
+// string functions. Numbers are doubles, as Expr mixes ints and floats
+// freely: integer variables are converted and integer literals are written
+// as doubles. Anything else, e.g. closures, pipes or ranges, is an error.
+func translateExpr(expression string, variables map[string]*cel.Type) (string, error) {
+	tokens, err := tokenizeExpr(expression)
+	if err != nil {
+		return "", err
+	}
+	p := &exprParser{tokens: tokens, variables: variables}
+	node, err := p.parseTernary()
+	if err != nil {
+		return "", err
+	}
+	if tok := p.peek(); tok.kind != exprEOF {
+		return "", fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return node.code, nil
+}
+
+// exprVariables returns the CEL types of the environment's variables.
+func exprVariables(env *cel.Env) map[string]*cel.Type {
+	variables := make(map[string]*cel.Type)
+	for _, v := range env.Variables() {
+		variables[v.Name()] = v.Type()
+	}
+	return variables
+}
+
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprNumber
+	exprString
+	exprIdent
+	exprOperator
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string // the literal value for strings
+	pos  int
+}
+
+// exprOperators are the operators and punctuation, longest first.
+var exprOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "**", "??", "..", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ",", ".", "?", ":", "|", "#", "{", "}"}
+
+func tokenizeExpr(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '_' ||
+				s[i] == '.' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])) ||
+				(s[i] == 'e' || s[i] == 'E') && i+1 < len(s) && (unicode.IsDigit(rune(s[i+1])) || s[i+1] == '-' || s[i+1] == '+') ||
+				(s[i] == '-' || s[i] == '+') && (s[i-1] == 'e' || s[i-1] == 'E')) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: exprNumber, text: strings.ReplaceAll(s[start:i], "_", ""), pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(s) && rune(s[i]) != c {
+				if s[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			raw := s[start:i]
+			if c == '\'' {
+				raw = `"` + strings.ReplaceAll(strings.ReplaceAll(raw[1:len(raw)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d", start)
+			}
+			tokens = append(tokens, exprToken{kind: exprString, text: value, pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(s) && (s[i] == '_' || unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i]))) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: exprIdent, text: s[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, exprToken{kind: exprOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, exprToken{kind: exprEOF, pos: len(s)}), nil
+}
+
+// exprNode is a translated subexpression.
+type exprNode struct {
+	code string
+	int  bool // an integer literal, kept as an int where CEL needs one
+}
+
+type exprParser struct {
+	tokens    []exprToken
+	pos       int
+	variables map[string]*cel.Type
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != exprEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// is reports whether the next token is the operator or keyword text.
+func (p *exprParser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == exprOperator || tok.kind == exprIdent) && tok.text == text
+}
+
+func (p *exprParser) expect(text string) error {
+	if !p.is(text) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at position %d", text, tok.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseTernary() (exprNode, error) {
+	cond, err := p.parseOr()
+	if err != nil || !p.is("?") {
+		return cond, err
+	}
+	p.next()
+	then, err := p.parseTernary()
+	if err != nil {
+		return cond, err
+	}
+	if err := p.expect(":"); err != nil {
+		return cond, err
+	}
+	otherwise, err := p.parseTernary()
+	if err != nil {
+		return cond, err
+	}
+	return exprNode{code: fmt.Sprintf("(%s ? %s : %s)", cond.code, then.code, otherwise.code)}, nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && (p.is("or") || p.is("||")) {
+		p.next()
+		var right exprNode
+		if right, err = p.parseAnd(); err == nil {
+			left = exprNode{code: fmt.Sprintf("(%s || %s)", left.code, right.code)}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseComparison()
+	for err == nil && (p.is("and") || p.is("&&")) {
+		p.next()
+		var right exprNode
+		if right, err = p.parseComparison(); err == nil {
+			left = exprNode{code: fmt.Sprintf("(%s && %s)", left.code, right.code)}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	for err == nil {
+		negate := false
+		if p.is("not") && p.tokens[p.pos+1].kind == exprIdent && p.tokens[p.pos+1].text == "in" {
+			p.next()
+			negate = true
+		}
+
+		tok := p.peek()
+		var format string
+		switch tok.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			format = "(%s " + tok.text + " %s)"
+		case "in":
+			format = "(%s in %s)"
+		case "matches", "contains", "startsWith", "endsWith":
+			if tok.kind == exprIdent {
+				format = "%s." + tok.text + "(%s)"
+			}
+		}
+		if format == "" {
+			return left, nil
+		}
+		p.next()
+
+		var right exprNode
+		if right, err = p.parseAdditive(); err == nil {
+			code := fmt.Sprintf(format, left.code, right.code)
+			if negate {
+				code = "!" + code
+			}
+			left = exprNode{code: code}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	for err == nil && (p.is("+") || p.is("-")) {
+		op := p.next().text
+		var right exprNode
+		if right, err = p.parseMultiplicative(); err == nil {
+			left = exprNode{code: fmt.Sprintf("(%s %s %s)", left.code, op, right.code)}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && (p.is("*") || p.is("/") || p.is("%")) {
+		op := p.next().text
+		var right exprNode
+		if right, err = p.parseUnary(); err == nil {
+			code := fmt.Sprintf("(%s %s %s)", left.code, op, right.code)
+			if op == "%" {
+				// CEL has no double modulo; Expr's applies to integers only
+				code = fmt.Sprintf("double(int(%s) %% int(%s))", left.code, right.code)
+			}
+			left = exprNode{code: code}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch {
+	case p.is("not") || p.is("!"):
+		p.next()
+		operand, err := p.parseUnary()
+		return exprNode{code: "!" + operand.code}, err
+	case p.is("-"):
+		p.next()
+		operand, err := p.parseUnary()
+		return exprNode{code: "-" + operand.code}, err
+	case p.is("+"):
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	node, err := p.parsePrimary()
+	for err == nil {
+		switch {
+		case p.is("."):
+			p.next()
+			field := p.next()
+			if field.kind != exprIdent {
+				return node, fmt.Errorf("expected a field name at position %d", field.pos)
+			}
+			node = exprNode{code: node.code + "." + field.text}
+		case p.is("["):
+			p.next()
+			var index exprNode
+			if index, err = p.parseTernary(); err != nil {
+				return node, err
+			}
+			if err = p.expect("]"); err != nil {
+				return node, err
+			}
+			node = exprNode{code: fmt.Sprintf("%s[%s]", node.code, intLiteral(index))}
+		default:
+			return node, nil
+		}
+	}
+	return node, err
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case exprNumber:
+		if _, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return exprNode{code: tok.text + ".0", int: true}, nil
+		}
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			return exprNode{}, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return exprNode{code: tok.text}, nil
+	case exprString:
+		return exprNode{code: strconv.Quote(tok.text)}, nil
+	case exprIdent:
+		switch tok.text {
+		case "true", "false":
+			return exprNode{code: tok.text}, nil
+		case "nil":
+			return exprNode{code: "null"}, nil
+		}
+		if p.is("(") {
+			return p.parseCall(tok)
+		}
+		return p.variable(tok)
+	case exprOperator:
+		switch tok.text {
+		case "(":
+			node, err := p.parseTernary()
+			if err != nil {
+				return node, err
+			}
+			return node, p.expect(")")
+		case "[":
+			var items []string
+			for !p.is("]") {
+				item, err := p.parseTernary()
+				if err != nil {
+					return item, err
+				}
+				items = append(items, item.code)
+				if !p.is(",") {
+					break
+				}
+				p.next()
+			}
+			return exprNode{code: "[" + strings.Join(items, ", ") + "]"}, p.expect("]")
+		}
+	case exprEOF:
+		return exprNode{}, fmt.Errorf("unexpected end of expression")
+	}
+	return exprNode{}, fmt.Errorf("unsupported %q at position %d", tok.text, tok.pos)
+}
+
+// variable translates a variable reference, converting integers to doubles.
+func (p *exprParser) variable(tok exprToken) (exprNode, error) {
+	typ, ok := p.variables[tok.text]
+	if !ok {
+		return exprNode{}, fmt.Errorf("undeclared variable %q at position %d", tok.text, tok.pos)
+	}
+	switch typ.Kind() {
+	case types.IntKind, types.UintKind:
+		return exprNode{code: "double(" + tok.text + ")"}, nil
+	case types.DoubleKind:
+		return exprNode{code: tok.text}, nil
+	case types.StringKind:
+		return exprNode{code: tok.text}, nil
+	case types.BoolKind:
+		return exprNode{code: tok.text}, nil
+	}
+	return exprNode{code: tok.text}, nil
+}
+
+// parseCall translates a call of one of the supported Expr functions.
+func (p *exprParser) parseCall(fn exprToken) (exprNode, error) {
+	p.next() // (
+	var args []exprNode
+	for !p.is(")") {
+		arg, err := p.parseTernary()
+		if err != nil {
+			return arg, err
+		}
+		args = append(args, arg)
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(")"); err != nil {
+		return exprNode{}, err
+	}
+	if len(args) != 1 {
+		return exprNode{}, fmt.Errorf("%s takes one argument at position %d", fn.text, fn.pos)
+	}
+
+	arg := args[0].code
+	switch fn.text {
+	case "len":
+		return exprNode{code: "double(size(" + arg + "))"}, nil
+	case "abs":
+		return exprNode{code: fmt.Sprintf("(%s < 0.0 ? -%s : %s)", arg, arg, arg)}, nil
+	case "int":
+		return exprNode{code: "double(int(" + arg + "))"}, nil
+	case "float":
+		return exprNode{code: "double(" + arg + ")"}, nil
+	case "string":
+		return exprNode{code: "string(" + arg + ")"}, nil
+	}
+	return exprNode{}, fmt.Errorf("unsupported function %q at position %d", fn.text, fn.pos)
+}
+
+// intLiteral writes an integer literal as an int, e.g. for list indexes.
+func intLiteral(n exprNode) string {
+	if n.int {
+		return strings.TrimSuffix(n.code, ".0")
+	}
+	return n.code
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestTranslateExpr(t *testing.T) {
+	engine, _ := NewEngine(nil, 5)
+	defer engine.Close()
+	variables := exprVariables(engine.env)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`amount > 1000 and velocity_count >= 2`, `((amount > 1000.0) && (double(velocity_count) >= 2.0))`},
+		{`tx_type in ["transfer", "payment"]`, `(tx_type in ["transfer", "payment"])`},
+		{`not (debtor_id startsWith "mule")`, `!debtor_id.startsWith("mule")`},
+		{`len(debtor_id) > 3 ? 1 : 0.5`, `((double(size(debtor_id)) > 3.0) ? 1.0 : 0.5)`},
+		{`creditor_id != nil || fee % 2 == 1`, `((creditor_id != null) || (double(int(fee) % int(2.0)) == 1.0))`},
+	}
+	for _, tt := range tests {
+		got, err := translateExpr(tt.expr, variables)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{`amount >`, `filter(tx, .amount > 1)`, `1..10`, `amount | abs()`} {
+		if _, err := translateExpr(expr, variables); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
+
+func TestExprRule(t *testing.T) {
+	engine, _ := NewEngine(func(ctx context.Context, tenantID, entityID string, windowSecs int) (int64, error) {
+		return 3, nil
+	}, 5)
+	defer engine.Close()
+
+	rule := &domain.RuleConfig{
+		ID:         "expr-velocity",
+		Language:   domain.RuleLanguageExpr,
+		Expression: `amount > 1000 and velocity_count >= 2 and tx_type in ["transfer"]`,
+		Weight:     1.0,
+		Enabled:    true,
+	}
+	if err := engine.LoadRule(rule); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+
+	ctx := context.Background()
+	results, _ := engine.EvaluateAll(ctx, &EvaluateInput{TenantID: "tenant-001", TxID: "tx-001", Type: "transfer", DebtorID: "d-1", Amount: domain.MustDecimal("5000.0")})
+	if results[0].Score != 1.0 {
+		t.Errorf("expected the expr rule to match, got %+v", results[0])
+	}
+	results, _ = engine.EvaluateAll(ctx, &EvaluateInput{TenantID: "tenant-001", TxID: "tx-002", Type: "payment", DebtorID: "d-1", Amount: domain.MustDecimal("5000.0")})
+	if results[0].Score != 0.0 {
+		t.Errorf("expected the expr rule not to match a payment, got %+v", results[0])
+	}
+
+	unknown := *rule
+	unknown.Language = "lua"
+	if err := engine.ValidateRule(&unknown); err == nil {
+		t.Error("expected an unknown language to be rejected")
+	}
+	cel := *rule
+	cel.Language = domain.RuleLanguageCEL
+	if err := engine.ValidateRule(&cel); err == nil {
+		t.Error("expected Expr syntax to be rejected as CEL")
+	}
+}
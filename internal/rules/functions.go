@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// RoundAmountUnit is the unit isRoundAmount measures roundness in when none
+// is given, matching the starter kit's round-amount-001 rule.
+const RoundAmountUnit = 1000.0
+
+// CountryRiskFunc returns the risk of a country code, 0.0 to 1.0.
+type CountryRiskFunc func(code string) float64
+
+// SetCountryRisk sets what country_risk looks up. Without one, every
+// country scores 0.0.
+func (e *Engine) SetCountryRisk(fn CountryRiskFunc) {
+	e.countryRisk.Store(&fn)
+}
+
+// functions declares the financial helper functions available to rules:
+//
+//	isRoundAmount(amount, tolerance)         amount within tolerance of a multiple of 1000
+//	isRoundAmount(amount, unit, tolerance)   amount within tolerance of a multiple of unit
+//	isJustBelow(amount, threshold, margin)   threshold - margin <= amount < threshold
+//	hour_of_day(timestamp)                   the hour, 0 to 23, in UTC
+//	country_risk(code)                       the country's risk, 0.0 to 1.0
+//	account_prefix(account, n)               the first n characters of a normalized account ID
+//	has_account_prefix(account, prefixes)    whether a normalized account ID starts with any prefix
+//
+// Account IDs are normalized by removing spaces and upper-casing, so IBANs
+// match however they were formatted.
+func (e *Engine) functions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("isRoundAmount",
+			cel.Overload("isRoundAmount_double_double",
+				[]*cel.Type{cel.DoubleType, cel.DoubleType}, cel.BoolType,
+				cel.BinaryBinding(func(amount, tolerance ref.Val) ref.Val {
+					return types.Bool(isRoundAmount(float64(amount.(types.Double)), RoundAmountUnit, float64(tolerance.(types.Double))))
+				})),
+			cel.Overload("isRoundAmount_double_double_double",
+				[]*cel.Type{cel.DoubleType, cel.DoubleType, cel.DoubleType}, cel.BoolType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					return types.Bool(isRoundAmount(float64(args[0].(types.Double)), float64(args[1].(types.Double)), float64(args[2].(types.Double))))
+				})),
+		),
+		cel.Function("isJustBelow",
+			cel.Overload("isJustBelow_double_double_double",
+				[]*cel.Type{cel.DoubleType, cel.DoubleType, cel.DoubleType}, cel.BoolType,
+				cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					amount := float64(args[0].(types.Double))
+					threshold := float64(args[1].(types.Double))
+					margin := float64(args[2].(types.Double))
+					return types.Bool(amount >= threshold-margin && amount < threshold)
+				})),
+		),
+		cel.Function("hour_of_day",
+			cel.Overload("hour_of_day_timestamp",
+				[]*cel.Type{cel.TimestampType}, cel.IntType,
+				cel.UnaryBinding(func(ts ref.Val) ref.Val {
+					return types.Int(ts.(types.Timestamp).Time.UTC().Hour())
+				})),
+		),
+		cel.Function("country_risk",
+			cel.Overload("country_risk_string",
+				[]*cel.Type{cel.StringType}, cel.DoubleType,
+				cel.UnaryBinding(func(code ref.Val) ref.Val {
+					fn := e.countryRisk.Load()
+					if fn == nil || *fn == nil {
+						return types.Double(0)
+					}
+					return types.Double((*fn)(strings.ToUpper(strings.TrimSpace(string(code.(types.String))))))
+				})),
+		),
+		cel.Function("account_prefix",
+			cel.Overload("account_prefix_string_int",
+				[]*cel.Type{cel.StringType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(func(account, n ref.Val) ref.Val {
+					normalized := normalizeAccount(string(account.(types.String)))
+					length := int(n.(types.Int))
+					if length < 0 {
+						length = 0
+					}
+					if length > len(normalized) {
+						length = len(normalized)
+					}
+					return types.String(normalized[:length])
+				})),
+		),
+		cel.Function("has_account_prefix",
+			cel.Overload("has_account_prefix_string_list",
+				[]*cel.Type{cel.StringType, cel.ListType(cel.StringType)}, cel.BoolType,
+				cel.BinaryBinding(func(account, prefixes ref.Val) ref.Val {
+					normalized := normalizeAccount(string(account.(types.String)))
+					if normalized == "" {
+						return types.False
+					}
+					it := prefixes.(traits.Lister).Iterator()
+					for it.HasNext() == types.True {
+						prefix, ok := it.Next().(types.String)
+						if !ok {
+							return types.NewErr("has_account_prefix: prefixes must be strings")
+						}
+						if p := normalizeAccount(string(prefix)); p != "" && strings.HasPrefix(normalized, p) {
+							return types.True
+						}
+					}
+					return types.False
+				})),
+		),
+	}
+}
+
+// isRoundAmount reports whether a positive amount is within tolerance of a
+// whole multiple of unit.
+func isRoundAmount(amount, unit, tolerance float64) bool {
+	if amount <= 0 || unit <= 0 {
+		return false
+	}
+	multiple := math.Round(amount/unit) * unit
+	// The epsilon absorbs binary rounding, e.g. 5000 - 4999.99 > 0.01
+	return multiple > 0 && math.Abs(amount-multiple) <= tolerance+1e-9
+}
+
+// normalizeAccount removes whitespace from an account ID and upper-cases it.
+func normalizeAccount(account string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(account), ""))
+}
+
+// evaluationTime returns the time rules see as timestamp: the transaction's
+// own time, else now.
+func evaluationTime(input *EvaluateInput) time.Time {
+	if input.Timestamp.IsZero() {
+		return time.Now().UTC()
+	}
+	return input.Timestamp.UTC()
+}
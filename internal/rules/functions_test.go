@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestFunctions(t *testing.T) {
+	engine, err := NewEngine(nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+	engine.SetCountryRisk(func(code string) float64 {
+		if code == "IR" {
+			return 1.0
+		}
+		return 0
+	})
+
+	night := time.Date(2026, 3, 14, 2, 30, 0, 0, time.FixedZone("CET", 3600))
+	input := &EvaluateInput{
+		TenantID:          "tenant-001",
+		TxID:              "tx-001",
+		Amount:            domain.MustDecimal("9500.0"),
+		Timestamp:         night,
+		DebtorAccountID:   "gb29 nwbk 6016 1331 9268 19",
+		CreditorAccountID: "DE89370400440532013000",
+		CreditorCountry:   "ir",
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{"RoundAmount", "isRoundAmount(5000.0, 0.0)", true},
+		{"RoundAmountWithinTolerance", "isRoundAmount(4999.99, 0.01)", true},
+		{"NotRoundAmount", "isRoundAmount(amount, 0.01)", false},
+		{"RoundAmountBelowUnit", "isRoundAmount(0.5, 0.5)", false},
+		{"RoundAmountCustomUnit", "isRoundAmount(amount, 500.0, 0.0)", true},
+		{"JustBelow", "isJustBelow(amount, 10000.0, 1000.0)", true},
+		{"AtThreshold", "isJustBelow(10000.0, 10000.0, 1000.0)", false},
+		{"BelowMargin", "isJustBelow(amount, 10000.0, 100.0)", false},
+		{"HourOfDayUTC", "hour_of_day(timestamp) == 1", true},
+		{"TimestampMethods", "timestamp.getDayOfWeek() == 6", true},
+		{"CountryRisk", `country_risk("ir") == 1.0 && country_risk("FR") == 0.0`, true},
+		{"AccountPrefix", `account_prefix(debtor_account_id, 8) == "GB29NWBK"`, true},
+		{"AccountPrefixTooLong", `account_prefix("GB29", 10) == "GB29"`, true},
+		{"HasAccountPrefix", `has_account_prefix(creditor_account_id, ["gb", "de 89"])`, true},
+		{"NoAccountPrefix", `has_account_prefix(creditor_account_id, ["FR", ""])`, false},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &domain.RuleConfig{ID: "fn-" + tt.name, TenantID: tt.name, Expression: tt.expression, Enabled: true}
+			if err := engine.LoadRule(rule); err != nil {
+				t.Fatalf("failed to load rule: %v", err)
+			}
+			in := *input
+			in.TenantID = tt.name
+			results, err := engine.EvaluateAll(ctx, &in)
+			if err != nil {
+				t.Fatalf("EvaluateAll failed: %v", err)
+			}
+			if len(results) != 1 || results[0].SubRuleRef == domain.RuleOutcomeError {
+				t.Fatalf("unexpected results: %+v", results)
+			}
+			if got := results[0].Score == 1.0; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("DefaultsToNow", func(t *testing.T) {
+		rule := &domain.RuleConfig{ID: "fn-now", TenantID: "now", Expression: "timestamp > timestamp('2026-01-01T00:00:00Z') && country_risk('ir') == 1.0", Enabled: true}
+		if err := engine.LoadRule(rule); err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+		results, _ := engine.EvaluateAll(ctx, &EvaluateInput{TenantID: "now", TxID: "tx-now"})
+		if len(results) != 1 || results[0].Score != 1.0 {
+			t.Errorf("expected a zero timestamp to evaluate at the current time, got %+v", results)
+		}
+	})
+
+	t.Run("NoCountryRisk", func(t *testing.T) {
+		plain, _ := NewEngine(nil, 5)
+		defer plain.Close()
+		if err := plain.LoadRule(&domain.RuleConfig{ID: "fn-risk", Expression: `country_risk("IR")`, Enabled: true}); err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+		results, _ := plain.EvaluateAll(ctx, &EvaluateInput{TenantID: "tenant-001", TxID: "tx-001"})
+		if len(results) != 1 || results[0].Score != 0 || results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Errorf("expected 0.0 without a country risk source, got %+v", results)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// namedListsVariable holds the evaluating tenant's named lists. It is set
+// beside the activation rather than in it, so samples don't carry the lists.
+const namedListsVariable = "named_lists"
+
+// ReloadLists replaces every tenant's named lists. Rules read them with
+// list("name"); a list the tenant doesn't have is empty.
+func (e *Engine) ReloadLists(lists []*domain.NamedList) {
+	loaded := make(map[string]map[string][]string)
+	for _, list := range lists {
+		if loaded[list.TenantID] == nil {
+			loaded[list.TenantID] = make(map[string][]string)
+		}
+		values := list.Values
+		if values == nil {
+			values = []string{}
+		}
+		loaded[list.TenantID][list.Name] = values
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lists = loaded
+}
+
+// listFunctions declares list(name), which expands to
+// named_list(named_lists, name) so the lookup is scoped to the evaluating
+// tenant.
+func listFunctions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Variable(namedListsVariable, cel.MapType(cel.StringType, cel.ListType(cel.StringType))),
+		cel.Macros(cel.GlobalMacro("list", 1,
+			func(eh cel.MacroExprFactory, target ast.Expr, args []ast.Expr) (ast.Expr, *cel.Error) {
+				return eh.NewCall("named_list", eh.NewIdent(namedListsVariable), args[0]), nil
+			})),
+		cel.Function("named_list",
+			cel.Overload("named_list_map_string",
+				[]*cel.Type{cel.MapType(cel.StringType, cel.ListType(cel.StringType)), cel.StringType}, cel.ListType(cel.StringType),
+				cel.BinaryBinding(func(lists, name ref.Val) ref.Val {
+					if list, found := lists.(traits.Mapper).Find(name); found {
+						return list
+					}
+					return types.NewStringList(types.DefaultTypeAdapter, nil)
+				})),
+		),
+	}
+}
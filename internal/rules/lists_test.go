@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestNamedLists(t *testing.T) {
+	engine, err := NewEngine(nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+	sampler := &recordingSampler{}
+	engine.SetSampler(sampler)
+
+	if err := engine.LoadRule(&domain.RuleConfig{
+		ID:         "internal-transfer",
+		Expression: `creditor_id in list("internal_accounts") && !(debtor_id in list("missing"))`,
+		Enabled:    true,
+		SampleRate: 1,
+	}); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+	engine.ReloadLists([]*domain.NamedList{
+		{TenantID: "tenant-001", Name: "internal_accounts", Values: []string{"treasury"}},
+		{TenantID: "tenant-002", Name: "internal_accounts", Values: []string{"payroll"}},
+	})
+
+	ctx := context.Background()
+	score := func(e *Engine, tenantID string) float64 {
+		t.Helper()
+		results, err := e.EvaluateAll(ctx, &EvaluateInput{TenantID: tenantID, TxID: "tx-001", DebtorID: "alice", CreditorID: "treasury"})
+		if err != nil {
+			t.Fatalf("EvaluateAll failed: %v", err)
+		}
+		if len(results) != 1 || results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected results: %+v", results)
+		}
+		return results[0].Score
+	}
+
+	if s := score(engine, "tenant-001"); s != 1 {
+		t.Errorf("expected the tenant's list to match, got %.2f", s)
+	}
+	if s := score(engine, "tenant-002"); s != 0 {
+		t.Errorf("expected another tenant's list not to match, got %.2f", s)
+	}
+	if s := score(engine, "tenant-003"); s != 0 {
+		t.Errorf("expected a tenant without lists to see empty lists, got %.2f", s)
+	}
+	if _, ok := sampler.samples[0].Activation[namedListsVariable]; ok {
+		t.Error("expected samples to leave the named lists out")
+	}
+
+	sandbox := engine.Sandbox()
+	if err := sandbox.LoadRule(&domain.RuleConfig{ID: "candidate", Expression: `creditor_id in list("internal_accounts")`, Enabled: true}); err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+	if s := score(sandbox, "tenant-001"); s != 1 {
+		t.Errorf("expected the sandbox to see the loaded lists, got %.2f", s)
+	}
+
+	engine.ReloadLists(nil)
+	if s := score(engine, "tenant-001"); s != 0 {
+		t.Errorf("expected the reload to drop the list, got %.2f", s)
+	}
+}
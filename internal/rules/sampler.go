@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Sampler receives the activation of a sampled rule evaluation. The sample's
+// Activation is shared with concurrently running rules: Sample must copy it
+// before returning and must not modify it.
+type Sampler interface {
+	Sample(ctx context.Context, sample *domain.ActivationSample)
+}
+
+// SetSampler sets where evaluations of rules with a SampleRate are sent.
+// nil disables sampling.
+func (e *Engine) SetSampler(sampler Sampler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sampler = sampler
+}
+
+// sample sends the evaluation to the sampler with the rule's SampleRate.
+func sample(ctx context.Context, sampler Sampler, rule *CompiledRule, activation map[string]any, result domain.RuleResult) {
+	rate := rule.Config.SampleRate
+	if sampler == nil || rate <= 0 || (rate < 1 && rand.Float64() >= rate) {
+		return
+	}
+
+	sampler.Sample(ctx, &domain.ActivationSample{
+		TenantID:    result.TenantID,
+		RuleID:      rule.Config.ID,
+		RuleVersion: rule.Config.Version,
+		TxID:        result.TxID,
+		Outcome:     result.SubRuleRef,
+		Score:       result.Score,
+		Activation:  activation,
+	})
+}
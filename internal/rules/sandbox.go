@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// Sandbox returns an empty engine that compiles rules like this one, enricher
+// variables included, but looks nothing up: velocity_count is 0, enricher
+// variables take their zero values and no activations are sampled. Named
+// lists are those loaded when it was created. It replays candidate rules
+// without touching live state.
+func (e *Engine) Sandbox() *Engine {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	defaults := &zeroEnricher{variables: make(map[string]*cel.Type)}
+	for _, enricher := range e.enrichers {
+		for name, typ := range enricher.Variables() {
+			defaults.variables[name] = typ
+		}
+	}
+
+	return &Engine{
+		env:           e.env,
+		compiledRules: make(map[string]map[string]*CompiledRule),
+		velocity:      e.velocity,
+		lists:         e.lists,
+		enrichers:     []Enricher{defaults},
+		maxWorkers:    e.maxWorkers,
+	}
+}
+
+// zeroEnricher sets the variables of a sandboxed engine's enrichers to their
+// zero values.
+type zeroEnricher struct {
+	variables map[string]*cel.Type
+}
+
+func (z *zeroEnricher) Name() string {
+	return "sandbox"
+}
+
+func (z *zeroEnricher) Variables() map[string]*cel.Type {
+	return z.variables
+}
+
+func (z *zeroEnricher) Enrich(ctx context.Context, input *EvaluateInput, activation map[string]any) error {
+	for name, typ := range z.variables {
+		activation[name] = zeroValue(typ)
+	}
+	return nil
+}
+
+// zeroValue returns the zero value of a CEL type, or nil for types without
+// one.
+func zeroValue(typ *cel.Type) any {
+	switch typ.Kind() {
+	case types.BoolKind:
+		return false
+	case types.IntKind:
+		return int64(0)
+	case types.UintKind:
+		return uint64(0)
+	case types.DoubleKind:
+		return 0.0
+	case types.StringKind:
+		return ""
+	case types.ListKind:
+		return []any{}
+	case types.MapKind:
+		return map[string]any{}
+	}
+	return nil
+}
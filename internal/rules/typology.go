@@ -1,6 +1,9 @@
 package rules
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,26 +14,31 @@ import (
 // It calculates weighted scores from individual rule results.
 type TypologyEngine struct {
 	mu         sync.RWMutex
-	typologies map[string]*domain.Typology // key: typologyID
+	typologies map[string]map[string]*domain.Typology // key: tenantID -> typologyID
 }
 
 // NewTypologyEngine creates a new typology evaluation engine.
 func NewTypologyEngine() *TypologyEngine {
 	return &TypologyEngine{
-		typologies: make(map[string]*domain.Typology),
+		typologies: make(map[string]map[string]*domain.Typology),
 	}
 }
 
-// LoadTypologies loads typology configurations into the engine.
+// LoadTypologies loads typology configurations of every tenant into the engine.
 func (e *TypologyEngine) LoadTypologies(typologies []*domain.Typology) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	e.typologies = make(map[string]*domain.Typology)
+	e.typologies = make(map[string]map[string]*domain.Typology)
 	for _, t := range typologies {
-		if t.Enabled {
-			e.typologies[t.ID] = t
+		if !t.Enabled {
+			continue
+		}
+		tenantID := scope(t.TenantID)
+		if e.typologies[tenantID] == nil {
+			e.typologies[tenantID] = make(map[string]*domain.Typology)
 		}
+		e.typologies[tenantID][t.ID] = t
 	}
 }
 
@@ -39,54 +47,89 @@ func (e *TypologyEngine) ReloadTypologies(typologies []*domain.Typology) {
 	e.LoadTypologies(typologies)
 }
 
-// GetLoadedTypologies returns currently loaded typologies.
+// GetLoadedTypologies returns currently loaded typologies of every tenant.
 func (e *TypologyEngine) GetLoadedTypologies() []*domain.Typology {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	result := make([]*domain.Typology, 0, len(e.typologies))
-	for _, t := range e.typologies {
+	result := make([]*domain.Typology, 0)
+	for _, typologies := range e.typologies {
+		for _, t := range typologies {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// GetTenantTypologies returns the typologies that apply to a tenant.
+func (e *TypologyEngine) GetTenantTypologies(tenantID string) []*domain.Typology {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tenantTypologies(tenantID)
+}
+
+// tenantTypologies returns a tenant's own typologies and the global typologies
+// with IDs it doesn't use. Callers must hold e.mu.
+func (e *TypologyEngine) tenantTypologies(tenantID string) []*domain.Typology {
+	global := e.typologies[GlobalTenantID]
+	var own map[string]*domain.Typology
+	if tenantID = scope(tenantID); tenantID != GlobalTenantID {
+		own = e.typologies[tenantID]
+	}
+
+	result := make([]*domain.Typology, 0, len(global)+len(own))
+	for id, t := range global {
+		if _, overridden := own[id]; !overridden {
+			result = append(result, t)
+		}
+	}
+	for _, t := range own {
 		result = append(result, t)
 	}
 	return result
 }
 
-// TypologyCount returns the number of loaded typologies.
+// TypologyCount returns the number of loaded typologies across all tenants.
 func (e *TypologyEngine) TypologyCount() int {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return len(e.typologies)
+
+	count := 0
+	for _, typologies := range e.typologies {
+		count += len(typologies)
+	}
+	return count
 }
 
-// EvaluateTypologies calculates typology scores from rule results.
+// EvaluateTypologies calculates a tenant's typology scores from rule results.
 // For each typology, it calculates a weighted sum of the rule scores
 // and determines if the threshold is exceeded.
 //
 // Algorithm:
 // 1. Build a map of ruleID -> score from rule results
-// 2. For each typology, sum (rule_score * weight) for matching rules
+// 2. For each typology, sum (rule_score * weight) for matching rules, and
+//    (typology_score * weight) for the typologies a composite refers to,
+//    evaluating those first
 // 3. Compare against alert threshold
-// 4. Return triggered typologies
-func (e *TypologyEngine) EvaluateTypologies(ruleResults []domain.RuleResult) []domain.TypologyResult {
+// 4. Suppress the trigger if too few rules fired or were evaluated, or the
+//    typology's condition doesn't hold
+// 5. Return triggered typologies
+func (e *TypologyEngine) EvaluateTypologies(tenantID string, ruleResults []domain.RuleResult) []domain.TypologyResult {
 	start := time.Now()
 
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	if len(e.typologies) == 0 {
+	typologies := e.tenantTypologies(tenantID)
+	if len(typologies) == 0 {
 		return nil
 	}
 
-	// Build rule score map for O(1) lookups
-	ruleScores := make(map[string]float64, len(ruleResults))
-	for _, r := range ruleResults {
-		ruleScores[r.RuleID] = r.Score
-	}
-
-	results := make([]domain.TypologyResult, 0, len(e.typologies))
+	ev := newTypologyEvaluation(typologies, ruleResults)
+	results := make([]domain.TypologyResult, 0, len(typologies))
 
-	for _, typology := range e.typologies {
-		result := e.evaluateTypology(typology, ruleScores)
+	for _, typology := range typologies {
+		result := *ev.result(typology.ID)
 		result.ProcessMs = time.Since(start).Milliseconds()
 		results = append(results, result)
 	}
@@ -94,66 +137,182 @@ func (e *TypologyEngine) EvaluateTypologies(ruleResults []domain.RuleResult) []d
 	return results
 }
 
-// evaluateTypology calculates the score for a single typology.
-func (e *TypologyEngine) evaluateTypology(typology *domain.Typology, ruleScores map[string]float64) domain.TypologyResult {
+// indexRuleResults maps rule results by rule ID.
+func indexRuleResults(ruleResults []domain.RuleResult) map[string]domain.RuleResult {
+	index := make(map[string]domain.RuleResult, len(ruleResults))
+	for _, r := range ruleResults {
+		if r.Shadow {
+			// Shadow rules never feed typologies.
+			continue
+		}
+		index[r.RuleID] = r
+	}
+	return index
+}
+
+// typologyEvaluation evaluates a tenant's typologies against one set of rule
+// results. Each typology is evaluated once, so composite typologies share the
+// results of the typologies they refer to, which form a DAG.
+type typologyEvaluation struct {
+	typologies  map[string]*domain.Typology
+	ruleResults map[string]domain.RuleResult
+	results     map[string]*domain.TypologyResult
+	visiting    map[string]bool
+}
+
+func newTypologyEvaluation(typologies []*domain.Typology, ruleResults []domain.RuleResult) *typologyEvaluation {
+	ev := &typologyEvaluation{
+		typologies:  make(map[string]*domain.Typology, len(typologies)),
+		ruleResults: indexRuleResults(ruleResults),
+		results:     make(map[string]*domain.TypologyResult, len(typologies)),
+		visiting:    make(map[string]bool),
+	}
+	for _, t := range typologies {
+		ev.typologies[t.ID] = t
+	}
+	return ev
+}
+
+// result returns the result of a typology, evaluating it on first use. It
+// returns nil for a typology the tenant doesn't have, and for a reference
+// that closes a cycle, which validation rejects but stored data may hold.
+func (ev *typologyEvaluation) result(typologyID string) *domain.TypologyResult {
+	if result, ok := ev.results[typologyID]; ok {
+		return result
+	}
+	typology, ok := ev.typologies[typologyID]
+	if !ok || ev.visiting[typologyID] {
+		return nil
+	}
+
+	ev.visiting[typologyID] = true
+	result := ev.evaluate(typology)
+	delete(ev.visiting, typologyID)
+
+	ev.results[typologyID] = &result
+	return &result
+}
+
+// evaluate calculates the score for a single typology.
+func (ev *typologyEvaluation) evaluate(typology *domain.Typology) domain.TypologyResult {
 	result := domain.TypologyResult{
-		TypologyID:   typology.ID,
-		TypologyName: typology.Name,
-		Threshold:    typology.AlertThreshold,
+		TypologyID:    typology.ID,
+		TypologyName:  typology.Name,
+		Threshold:     typology.AlertThreshold,
 		Contributions: make([]domain.RuleContribution, 0, len(typology.Rules)),
 	}
 
 	var totalScore float64
 
 	for _, ruleWeight := range typology.Rules {
-		ruleScore, exists := ruleScores[ruleWeight.RuleID]
+		ruleResult, exists := ev.ruleResults[ruleWeight.RuleID]
 		if !exists {
 			// Rule not evaluated - skip
 			continue
 		}
 
-		contribution := ruleScore * ruleWeight.Weight
+		// Errored rules still contribute their score but don't count as coverage
+		if ruleResult.SubRuleRef != domain.RuleOutcomeError {
+			result.RulesEvaluated++
+			if ruleResult.Score > 0 {
+				result.RulesFired++
+			}
+		}
+
+		contribution := ruleResult.Score * ruleWeight.Weight
 		totalScore += contribution
 
 		result.Contributions = append(result.Contributions, domain.RuleContribution{
 			RuleID:       ruleWeight.RuleID,
-			RuleScore:    ruleScore,
+			RuleScore:    ruleResult.Score,
 			Weight:       ruleWeight.Weight,
 			Contribution: contribution,
 		})
 	}
 
+	for _, typologyWeight := range typology.Typologies {
+		referenced := ev.result(typologyWeight.TypologyID)
+		if referenced == nil {
+			continue
+		}
+
+		contribution := referenced.Score * typologyWeight.Weight
+		totalScore += contribution
+
+		result.TypologyContributions = append(result.TypologyContributions, domain.TypologyContribution{
+			TypologyID:    typologyWeight.TypologyID,
+			TypologyScore: referenced.Score,
+			Weight:        typologyWeight.Weight,
+			Contribution:  contribution,
+		})
+	}
+
+	if len(typology.Rules) > 0 {
+		result.Coverage = float64(result.RulesEvaluated) / float64(len(typology.Rules))
+	}
+
 	result.Score = totalScore
 	result.Triggered = totalScore >= typology.AlertThreshold
 
+	if result.Triggered {
+		switch {
+		case result.RulesFired < typology.MinRulesFired:
+			result.SuppressedReason = fmt.Sprintf("%d of %d required rules fired", result.RulesFired, typology.MinRulesFired)
+		case result.Coverage < typology.MinCoverage:
+			result.SuppressedReason = fmt.Sprintf("rule coverage %.2f below required %.2f", result.Coverage, typology.MinCoverage)
+		case typology.Condition != nil && !ev.holds(typology.Condition):
+			result.SuppressedReason = "condition not met"
+		}
+		result.Triggered = result.SuppressedReason == ""
+	}
+
 	return result
 }
 
-// EvaluateTypology evaluates a single typology by ID.
-func (e *TypologyEngine) EvaluateTypology(typologyID string, ruleResults []domain.RuleResult) (*domain.TypologyResult, bool) {
-	e.mu.RLock()
-	typology, exists := e.typologies[typologyID]
-	if !exists {
-		e.mu.RUnlock()
-		return nil, false
-	}
-
-	// Build rule score map while holding lock
-	ruleScores := make(map[string]float64, len(ruleResults))
-	for _, r := range ruleResults {
-		ruleScores[r.RuleID] = r.Score
+// holds reports whether a typology condition holds. A rule counts once it
+// scored above zero without error.
+func (ev *typologyEvaluation) holds(c *domain.TypologyCondition) bool {
+	switch {
+	case len(c.All) > 0:
+		for i := range c.All {
+			if !ev.holds(&c.All[i]) {
+				return false
+			}
+		}
+		return true
+	case len(c.Any) > 0:
+		for i := range c.Any {
+			if ev.holds(&c.Any[i]) {
+				return true
+			}
+		}
+		return false
+	case c.Rule != "":
+		result, ok := ev.ruleResults[c.Rule]
+		return ok && result.SubRuleRef != domain.RuleOutcomeError && result.Score > 0
+	case c.Typology != "":
+		result := ev.result(c.Typology)
+		return result != nil && result.Triggered
 	}
+	return false
+}
 
-	// Evaluate while holding lock to prevent data race on typology pointer
-	result := e.evaluateTypology(typology, ruleScores)
-	e.mu.RUnlock()
+// EvaluateTypology evaluates a single typology of a tenant by ID.
+func (e *TypologyEngine) EvaluateTypology(tenantID, typologyID string, ruleResults []domain.RuleResult) (*domain.TypologyResult, bool) {
+	// Evaluate while holding lock to prevent data race on typology pointers
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	return &result, true
+	result := newTypologyEvaluation(e.tenantTypologies(tenantID), ruleResults).result(typologyID)
+	if result == nil {
+		return nil, false
+	}
+	return result, true
 }
 
-// GetTriggeredTypologies returns only typologies that exceeded their threshold.
-func (e *TypologyEngine) GetTriggeredTypologies(ruleResults []domain.RuleResult) []domain.TypologyResult {
-	all := e.EvaluateTypologies(ruleResults)
+// GetTriggeredTypologies returns only a tenant's typologies that exceeded their threshold.
+func (e *TypologyEngine) GetTriggeredTypologies(tenantID string, ruleResults []domain.RuleResult) []domain.TypologyResult {
+	all := e.EvaluateTypologies(tenantID, ruleResults)
 	triggered := make([]domain.TypologyResult, 0)
 	for _, t := range all {
 		if t.Triggered {
@@ -163,10 +322,124 @@ func (e *TypologyEngine) GetTriggeredTypologies(ruleResults []domain.RuleResult)
 	return triggered
 }
 
+// ValidateCondition checks that every part of a typology condition sets
+// exactly one of all, any, rule and typology.
+func ValidateCondition(c *domain.TypologyCondition) error {
+	set := 0
+	for _, ok := range []bool{len(c.All) > 0, len(c.Any) > 0, c.Rule != "", c.Typology != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("each condition must set exactly one of all, any, rule and typology")
+	}
+	for _, nested := range [][]domain.TypologyCondition{c.All, c.Any} {
+		for i := range nested {
+			if err := ValidateCondition(&nested[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ConditionRefs returns the rules and typologies a condition names.
+func ConditionRefs(c *domain.TypologyCondition) (ruleIDs, typologyIDs []string) {
+	if c == nil {
+		return nil, nil
+	}
+	switch {
+	case c.Rule != "":
+		ruleIDs = append(ruleIDs, c.Rule)
+	case c.Typology != "":
+		typologyIDs = append(typologyIDs, c.Typology)
+	}
+	for _, nested := range [][]domain.TypologyCondition{c.All, c.Any} {
+		for i := range nested {
+			rules, typologies := ConditionRefs(&nested[i])
+			ruleIDs = append(ruleIDs, rules...)
+			typologyIDs = append(typologyIDs, typologies...)
+		}
+	}
+	return ruleIDs, typologyIDs
+}
+
+// typologyRefs returns the typologies a typology weights or names in its condition.
+func typologyRefs(t *domain.Typology) []string {
+	_, refs := ConditionRefs(t.Condition)
+	for _, tw := range t.Typologies {
+		refs = append(refs, tw.TypologyID)
+	}
+	return refs
+}
+
+// ValidateTypologyGraph checks the references between a tenant's typologies:
+// every typology another weights or names in its condition must be in the
+// set, and no typology may refer back to itself, directly or through others.
+func ValidateTypologyGraph(typologies []*domain.Typology) error {
+	byID := make(map[string]*domain.Typology, len(typologies))
+	for _, t := range typologies {
+		byID[t.ID] = t
+	}
+
+	var errs []error
+	for _, t := range typologies {
+		for _, ref := range typologyRefs(t) {
+			if byID[ref] == nil {
+				errs = append(errs, fmt.Errorf("typology %s: typology %q does not exist", t.ID, ref))
+			}
+		}
+	}
+
+	// Depth-first search, where reaching a typology still on the path is a cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(typologies))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch marks[id] {
+		case visiting:
+			for i := range path {
+				if path[i] == id {
+					return fmt.Errorf("typologies refer to each other in a cycle: %s", strings.Join(append(path[i:], id), " -> "))
+				}
+			}
+		case visited:
+			return nil
+		}
+		t := byID[id]
+		if t == nil {
+			return nil
+		}
+		marks[id] = visiting
+		path = append(path, id)
+		for _, ref := range typologyRefs(t) {
+			if err := visit(ref); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[id] = visited
+		return nil
+	}
+	for _, t := range typologies {
+		if err := visit(t.ID); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // Close cleans up the engine.
 func (e *TypologyEngine) Close() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.typologies = make(map[string]*domain.Typology)
+	e.typologies = make(map[string]map[string]*domain.Typology)
 	return nil
 }
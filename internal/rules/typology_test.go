@@ -124,7 +124,7 @@ func TestTypologyEngine_EvaluateTypologies(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results := engine.EvaluateTypologies(tt.ruleResults)
+			results := engine.EvaluateTypologies("tenant-001", tt.ruleResults)
 
 			var accountTakeoverTriggered, structuringTriggered bool
 			for _, r := range results {
@@ -176,7 +176,7 @@ func TestTypologyEngine_GetTriggeredTypologies(t *testing.T) {
 		{RuleID: "rule-1", Score: 0.6},
 	}
 
-	triggered := engine.GetTriggeredTypologies(ruleResults)
+	triggered := engine.GetTriggeredTypologies("tenant-001", ruleResults)
 
 	if len(triggered) != 1 {
 		t.Fatalf("Expected 1 triggered typology, got %d", len(triggered))
@@ -212,7 +212,7 @@ func TestTypologyEngine_RuleContributions(t *testing.T) {
 		{RuleID: "rule-3", Score: 0.5},
 	}
 
-	results := engine.EvaluateTypologies(ruleResults)
+	results := engine.EvaluateTypologies("tenant-001", ruleResults)
 
 	if len(results) != 1 {
 		t.Fatalf("Expected 1 result, got %d", len(results))
@@ -311,8 +311,260 @@ func TestTypologyEngine_ReloadTypologies(t *testing.T) {
 	}
 
 	// Verify old typology is gone
-	_, exists := engine.EvaluateTypology("typology-1", nil)
+	_, exists := engine.EvaluateTypology("tenant-001", "typology-1", nil)
 	if exists {
 		t.Error("typology-1 should not exist after reload")
 	}
 }
+
+func TestTypologyEngine_MinimumCoverage(t *testing.T) {
+	engine := NewTypologyEngine()
+	engine.LoadTypologies([]*domain.Typology{
+		{
+			ID:             "mule-account",
+			Name:           "Mule Account",
+			AlertThreshold: 0.5,
+			MinRulesFired:  2,
+			MinCoverage:    0.75,
+			Enabled:        true,
+			Rules: []domain.TypologyRuleWeight{
+				{RuleID: "high-value-001", Weight: 0.6},
+				{RuleID: "velocity-001", Weight: 0.2},
+				{RuleID: "partial-drain-001", Weight: 0.1},
+				{RuleID: "risk-type-001", Weight: 0.1},
+			},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		results    []domain.RuleResult
+		triggered  bool
+		suppressed bool
+		coverage   float64
+	}{
+		{
+			name: "SingleHeavyRule",
+			results: []domain.RuleResult{
+				{RuleID: "high-value-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+				{RuleID: "velocity-001", Score: 0, SubRuleRef: domain.RuleOutcomePass},
+				{RuleID: "partial-drain-001", Score: 0, SubRuleRef: domain.RuleOutcomePass},
+				{RuleID: "risk-type-001", Score: 0, SubRuleRef: domain.RuleOutcomePass},
+			},
+			suppressed: true,
+			coverage:   1.0,
+		},
+		{
+			name: "MissingRules",
+			results: []domain.RuleResult{
+				{RuleID: "high-value-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+				{RuleID: "velocity-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+				{RuleID: "partial-drain-001", Score: 0, SubRuleRef: domain.RuleOutcomeError},
+			},
+			suppressed: true,
+			coverage:   0.5,
+		},
+		{
+			name: "FullActivation",
+			results: []domain.RuleResult{
+				{RuleID: "high-value-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+				{RuleID: "velocity-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+				{RuleID: "partial-drain-001", Score: 0, SubRuleRef: domain.RuleOutcomePass},
+			},
+			triggered: true,
+			coverage:  0.75,
+		},
+		{
+			name: "BelowThreshold",
+			results: []domain.RuleResult{
+				{RuleID: "velocity-001", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail},
+			},
+			coverage: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := engine.EvaluateTypology("tenant-001", "mule-account", tt.results)
+			if result.Triggered != tt.triggered {
+				t.Errorf("Triggered = %v, want %v (reason %q)", result.Triggered, tt.triggered, result.SuppressedReason)
+			}
+			if (result.SuppressedReason != "") != tt.suppressed {
+				t.Errorf("unexpected SuppressedReason %q", result.SuppressedReason)
+			}
+			if result.Coverage != tt.coverage {
+				t.Errorf("Coverage = %v, want %v", result.Coverage, tt.coverage)
+			}
+		})
+	}
+}
+
+func TestTypologyEngine_TenantTypologies(t *testing.T) {
+	engine := NewTypologyEngine()
+
+	engine.LoadTypologies([]*domain.Typology{
+		{
+			ID:             "typology-a",
+			TenantID:       GlobalTenantID,
+			AlertThreshold: 0.5,
+			Enabled:        true,
+			Rules:          []domain.TypologyRuleWeight{{RuleID: "rule-1", Weight: 1.0}},
+		},
+		{
+			ID:             "typology-a",
+			TenantID:       "tenant-a",
+			AlertThreshold: 0.9,
+			Enabled:        true,
+			Rules:          []domain.TypologyRuleWeight{{RuleID: "rule-1", Weight: 1.0}},
+		},
+		{
+			ID:             "typology-b",
+			TenantID:       "tenant-a",
+			AlertThreshold: 0.5,
+			Enabled:        true,
+			Rules:          []domain.TypologyRuleWeight{{RuleID: "rule-1", Weight: 1.0}},
+		},
+	})
+
+	ruleResults := []domain.RuleResult{{RuleID: "rule-1", Score: 0.6}}
+
+	if got := engine.GetTriggeredTypologies("tenant-b", ruleResults); len(got) != 1 || got[0].TypologyID != "typology-a" {
+		t.Errorf("Expected tenant-b to use the global typology-a, got %+v", got)
+	}
+
+	got := engine.GetTriggeredTypologies("tenant-a", ruleResults)
+	if len(got) != 1 || got[0].TypologyID != "typology-b" {
+		t.Errorf("Expected tenant-a's typology-a to override the global one, got %+v", got)
+	}
+
+	if result, ok := engine.EvaluateTypology("tenant-a", "typology-a", ruleResults); !ok || result.Triggered {
+		t.Errorf("Expected tenant-a's typology-a not to trigger, got %+v", result)
+	}
+	if engine.TypologyCount() != 3 {
+		t.Errorf("Expected 3 loaded typologies, got %d", engine.TypologyCount())
+	}
+}
+
+func TestTypologyEngine_CompositeTypologies(t *testing.T) {
+	engine := NewTypologyEngine()
+	engine.LoadTypologies([]*domain.Typology{
+		{
+			ID: "rapid-movement", Name: "Rapid Movement", AlertThreshold: 0.5, Enabled: true,
+			Rules: []domain.TypologyRuleWeight{{RuleID: "rapid-movement-001", Weight: 1}},
+		},
+		{
+			ID: "account-drain", Name: "Account Drain", AlertThreshold: 0.5, Enabled: true,
+			Rules: []domain.TypologyRuleWeight{{RuleID: "account-drain-001", Weight: 1}},
+		},
+		{
+			ID: "drain-and-run", Name: "Drain and Run", AlertThreshold: 0.5, Enabled: true,
+			Typologies: []domain.TypologyWeight{
+				{TypologyID: "rapid-movement", Weight: 0.5},
+				{TypologyID: "account-drain", Weight: 0.5},
+			},
+			Condition: &domain.TypologyCondition{All: []domain.TypologyCondition{
+				{Typology: "rapid-movement"},
+				{Any: []domain.TypologyCondition{{Typology: "account-drain"}, {Rule: "new-counterparty-001"}}},
+			}},
+		},
+	})
+
+	tests := []struct {
+		name          string
+		ruleResults   []domain.RuleResult
+		wantScore     float64
+		wantTriggered bool
+		wantReason    string
+	}{
+		{
+			name: "both typologies trigger",
+			ruleResults: []domain.RuleResult{
+				{RuleID: "rapid-movement-001", Score: 1},
+				{RuleID: "account-drain-001", Score: 1},
+			},
+			wantScore:     1,
+			wantTriggered: true,
+		},
+		{
+			name: "rapid movement and a new counterparty",
+			ruleResults: []domain.RuleResult{
+				{RuleID: "rapid-movement-001", Score: 1},
+				{RuleID: "new-counterparty-001", Score: 1},
+			},
+			wantScore:     0.5,
+			wantTriggered: true,
+		},
+		{
+			name: "score reached without the condition",
+			ruleResults: []domain.RuleResult{
+				{RuleID: "account-drain-001", Score: 1},
+			},
+			wantScore:  0.5,
+			wantReason: "condition not met",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := engine.EvaluateTypology("", "drain-and-run", tt.ruleResults)
+			if !ok {
+				t.Fatal("expected the composite typology to be found")
+			}
+			if result.Score != tt.wantScore || result.Triggered != tt.wantTriggered || result.SuppressedReason != tt.wantReason {
+				t.Errorf("got score %.2f, triggered %v, reason %q", result.Score, result.Triggered, result.SuppressedReason)
+			}
+			if len(result.TypologyContributions) != 2 {
+				t.Errorf("expected 2 typology contributions, got %d", len(result.TypologyContributions))
+			}
+		})
+	}
+
+	t.Run("a cycle in stored typologies is ignored", func(t *testing.T) {
+		engine := NewTypologyEngine()
+		engine.LoadTypologies([]*domain.Typology{
+			{ID: "a", AlertThreshold: 0.5, Enabled: true, Typologies: []domain.TypologyWeight{{TypologyID: "b", Weight: 1}}},
+			{ID: "b", AlertThreshold: 0.5, Enabled: true, Typologies: []domain.TypologyWeight{{TypologyID: "a", Weight: 1}}},
+		})
+		if results := engine.EvaluateTypologies("", nil); len(results) != 2 {
+			t.Errorf("expected 2 results, got %d", len(results))
+		}
+	})
+}
+
+func TestValidateTypologyGraph(t *testing.T) {
+	weights := func(ids ...string) []domain.TypologyWeight {
+		var w []domain.TypologyWeight
+		for _, id := range ids {
+			w = append(w, domain.TypologyWeight{TypologyID: id, Weight: 1})
+		}
+		return w
+	}
+
+	valid := []*domain.Typology{
+		{ID: "a", Typologies: weights("b", "c")},
+		{ID: "b", Typologies: weights("c")},
+		{ID: "c", Condition: &domain.TypologyCondition{Rule: "rule-1"}},
+	}
+	if err := ValidateTypologyGraph(valid); err != nil {
+		t.Errorf("expected a DAG to be valid, got %v", err)
+	}
+
+	err := ValidateTypologyGraph([]*domain.Typology{
+		{ID: "a", Typologies: weights("b")},
+		{ID: "b", Condition: &domain.TypologyCondition{Typology: "a"}},
+	})
+	if err == nil || err.Error() != "typologies refer to each other in a cycle: a -> b -> a" {
+		t.Errorf("expected the cycle, got %v", err)
+	}
+
+	if err := ValidateTypologyGraph([]*domain.Typology{{ID: "a", Typologies: weights("missing")}}); err == nil {
+		t.Error("expected a missing typology to be rejected")
+	}
+
+	if err := ValidateCondition(&domain.TypologyCondition{Rule: "r", Typology: "t"}); err == nil {
+		t.Error("expected a condition with two fields to be rejected")
+	}
+	if err := ValidateCondition(&domain.TypologyCondition{All: []domain.TypologyCondition{{}}}); err == nil {
+		t.Error("expected an empty nested condition to be rejected")
+	}
+}
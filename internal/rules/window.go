@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// debtorWindowVariable holds the debtor's recent payments for the window
+// functions. Like named lists it is set beside the activation, so samples
+// don't carry it.
+const debtorWindowVariable = "debtor_window"
+
+// Window functions.
+const (
+	windowSumBelow   = "window_sum_below"
+	windowCountBelow = "window_count_below"
+)
+
+// WindowSource returns the payments an entity sent as debtor since a time,
+// reversals excluded.
+type WindowSource func(ctx context.Context, tenantID, debtorID string, since time.Time) ([]*domain.Transaction, error)
+
+// SetWindowSource sets where the window functions read a debtor's payments
+// from. Without one they only see the transaction being evaluated.
+func (e *Engine) SetWindowSource(fn WindowSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.windowSource = fn
+}
+
+// windowType is the CEL type of debtorWindowVariable.
+var windowType = cel.OpaqueType("osprey.DebtorWindow")
+
+// debtorWindow is a debtor's payments, the evaluated one included, as
+// amounts and their age at the evaluation time.
+type debtorWindow struct {
+	payments []windowPayment
+}
+
+type windowPayment struct {
+	amount float64
+	age    time.Duration
+}
+
+func (w *debtorWindow) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	return nil, fmt.Errorf("unsupported conversion of %s", windowType)
+}
+
+func (w *debtorWindow) ConvertToType(typeVal ref.Type) ref.Val {
+	if typeVal == types.TypeType {
+		return windowType
+	}
+	return types.NewErr("unsupported conversion of %s to %s", windowType, typeVal)
+}
+
+func (w *debtorWindow) Equal(other ref.Val) ref.Val {
+	return types.Bool(w == other)
+}
+
+func (w *debtorWindow) Type() ref.Type {
+	return windowType
+}
+
+func (w *debtorWindow) Value() any {
+	return w.payments
+}
+
+// below returns the count and sum of the payments younger than window that
+// are each below threshold.
+func (w *debtorWindow) below(threshold float64, window time.Duration) (int64, float64) {
+	var count int64
+	var sum float64
+	for _, p := range w.payments {
+		if p.age < window && p.amount < threshold {
+			count++
+			sum += p.amount
+		}
+	}
+	return count, sum
+}
+
+// windowFunctions declares the structuring functions:
+//
+//	window_sum_below(threshold, seconds)     sum of the debtor's payments each below threshold in the last seconds
+//	window_count_below(threshold, seconds)   how many there were
+//
+// Both include the transaction being evaluated and expand to calls on
+// debtor_window, so the lookup is scoped to the evaluated debtor. seconds
+// must be a constant, which bounds how far back payments are read.
+func windowFunctions() []cel.EnvOption {
+	opts := []cel.EnvOption{cel.Variable(debtorWindowVariable, windowType)}
+	for _, fn := range []struct {
+		name   string
+		result *cel.Type
+		value  func(count int64, sum float64) ref.Val
+	}{
+		{windowSumBelow, cel.DoubleType, func(count int64, sum float64) ref.Val { return types.Double(sum) }},
+		{windowCountBelow, cel.IntType, func(count int64, sum float64) ref.Val { return types.Int(count) }},
+	} {
+		opts = append(opts,
+			cel.Macros(cel.GlobalMacro(fn.name, 2,
+				func(eh cel.MacroExprFactory, target ast.Expr, args []ast.Expr) (ast.Expr, *cel.Error) {
+					return eh.NewCall(fn.name, eh.NewIdent(debtorWindowVariable), args[0], args[1]), nil
+				})),
+			cel.Function(fn.name,
+				cel.Overload(fn.name+"_window_double_int",
+					[]*cel.Type{windowType, cel.DoubleType, cel.IntType}, fn.result,
+					cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+						return fn.value(args[0].(*debtorWindow).below(float64(args[1].(types.Double)), time.Duration(args[2].(types.Int))*time.Second))
+					})),
+				cel.Overload(fn.name+"_window_int_int",
+					[]*cel.Type{windowType, cel.IntType, cel.IntType}, fn.result,
+					cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+						return fn.value(args[0].(*debtorWindow).below(float64(args[1].(types.Int)), time.Duration(args[2].(types.Int))*time.Second))
+					})),
+			),
+		)
+	}
+	return opts
+}
+
+// windowLookback returns the longest window a compiled expression passes to
+// the window functions, 0 when it calls none. Windows must be constants of
+// one second to domain.MaxVelocityWindow.
+func windowLookback(checked *cel.Ast) (time.Duration, error) {
+	var lookback time.Duration
+	root := ast.NavigateAST(checked.NativeRep())
+	for _, name := range []string{windowSumBelow, windowCountBelow} {
+		for _, call := range ast.MatchDescendants(root, ast.FunctionMatcher(name)) {
+			args := call.AsCall().Args()
+			last := args[len(args)-1]
+			if last.Kind() != ast.LiteralKind {
+				return 0, fmt.Errorf("%s: seconds must be a constant", name)
+			}
+			seconds, ok := last.AsLiteral().(types.Int)
+			if !ok {
+				return 0, fmt.Errorf("%s: seconds must be a constant", name)
+			}
+			window := time.Duration(seconds) * time.Second
+			if window < time.Second || window > domain.MaxVelocityWindow {
+				return 0, fmt.Errorf("%s: seconds must be between 1 and %d", name, int(domain.MaxVelocityWindow/time.Second))
+			}
+			lookback = max(lookback, window)
+		}
+	}
+	return lookback, nil
+}
+
+// debtorWindowOf reads the debtor's payments over lookback for the window
+// functions. The evaluated transaction is added from the input, whether or
+// not it was stored first. Without a source or debtor only it is seen.
+func debtorWindowOf(ctx context.Context, source WindowSource, input *EvaluateInput, lookback time.Duration, now time.Time) *debtorWindow {
+	w := &debtorWindow{}
+	if input.ReversalOf == "" {
+		w.payments = append(w.payments, windowPayment{amount: input.Amount.Float64()})
+	}
+	if source == nil || lookback == 0 || input.DebtorID == "" {
+		return w
+	}
+
+	txs, err := source(ctx, input.TenantID, input.DebtorID, now.Add(-lookback))
+	if err != nil {
+		domain.ReportDegradation(ctx, domain.DegradedVelocity, domain.DegradationFailed, err.Error())
+		return w
+	}
+	for _, tx := range txs {
+		age := now.Sub(tx.Timestamp)
+		if tx.ID == input.TxID || age < 0 || tx.ReversalOf != "" {
+			continue
+		}
+		w.payments = append(w.payments, windowPayment{amount: tx.Amount.Float64(), age: age})
+	}
+	return w
+}
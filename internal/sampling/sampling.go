@@ -0,0 +1,39 @@
+// Package sampling stores activation samples of rules with a sample rate,
+// redacted with the log redaction policy.
+package sampling
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/logging"
+)
+
+// Recorder saves activation samples to the repository. It implements
+// rules.Sampler.
+type Recorder struct {
+	repo     domain.Repository
+	redactor *logging.Redactor
+	now      func() time.Time
+}
+
+// NewRecorder creates a recorder. A nil redactor stores values unredacted.
+func NewRecorder(repo domain.Repository, redactor *logging.Redactor) *Recorder {
+	return &Recorder{repo: repo, redactor: redactor, now: time.Now}
+}
+
+// Sample redacts and stores a sample. Storage failures are logged; they never
+// fail the evaluation.
+func (r *Recorder) Sample(ctx context.Context, sample *domain.ActivationSample) {
+	stored := *sample
+	stored.ID = uuid.New().String()
+	stored.Activation = r.redactor.RedactMap(sample.Activation)
+	stored.CreatedAt = r.now().UTC()
+
+	if err := r.repo.SaveActivationSample(context.WithoutCancel(ctx), stored.TenantID, &stored); err != nil {
+		slog.Warn("failed to save activation sample", "rule_id", stored.RuleID, "tx_id", stored.TxID, "error", err)
+	}
+}
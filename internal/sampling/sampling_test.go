@@ -0,0 +1,58 @@
+package sampling
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/logging"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	redactor, err := logging.NewRedactor(domain.RedactionConfig{Mode: logging.ModeStandard, Salt: "s"})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	recorder := NewRecorder(repo, redactor)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	activation := map[string]any{"debtor_id": "user-123", "debtor_name": "Jane Doe", "amount": 500.0}
+	recorder.Sample(ctx, &domain.ActivationSample{
+		TenantID:    "tenant-001",
+		RuleID:      "high-value",
+		RuleVersion: "1.0.0",
+		TxID:        "tx-001",
+		Outcome:     domain.RuleOutcomeFail,
+		Score:       1,
+		Activation:  activation,
+	})
+
+	samples, err := repo.ListActivationSamples(ctx, "tenant-001", "high-value", 10)
+	if err != nil {
+		t.Fatalf("ListActivationSamples failed: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(samples))
+	}
+
+	got := samples[0]
+	if got.ID == "" || !got.CreatedAt.Equal(now) || got.TxID != "tx-001" {
+		t.Errorf("unexpected sample: %+v", got)
+	}
+	if d, _ := got.Activation["debtor_id"].(string); !strings.HasPrefix(d, "h:") {
+		t.Errorf("expected hashed debtor_id, got %v", got.Activation["debtor_id"])
+	}
+	if _, ok := got.Activation["debtor_name"]; ok {
+		t.Error("expected debtor_name to be dropped")
+	}
+	if activation["debtor_id"] != "user-123" {
+		t.Error("expected the engine's activation to be left unchanged")
+	}
+}
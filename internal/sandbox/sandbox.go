@@ -0,0 +1,103 @@
+// Package sandbox expires the data of sandbox tenants.
+//
+// A sandbox tenant is an ordinary tenant named in the sandbox configuration.
+// It evaluates like any other, but its transactions, evaluations, alerts and
+// other activity are deleted once older than the TTL, 24 hours by default.
+// Its rules, typologies, webhooks and other configuration are kept, so a
+// prospect's integration keeps working from one day to the next.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Purger deletes expired sandbox data.
+type Purger struct {
+	repo domain.Repository
+	cfg  domain.SandboxConfig
+	now  func() time.Time
+}
+
+// NewPurger creates a purger. A zero TTL or purge interval uses the defaults.
+func NewPurger(repo domain.Repository, cfg domain.SandboxConfig) *Purger {
+	defaults := domain.DefaultConfig().Sandbox
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaults.TTL
+	}
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = defaults.PurgeInterval
+	}
+	return &Purger{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Enabled reports whether any sandbox tenant is configured.
+func (p *Purger) Enabled() bool {
+	return p != nil && p.repo != nil && len(p.cfg.Tenants) > 0
+}
+
+// IsSandbox reports whether tenantID is a sandbox tenant.
+func (p *Purger) IsSandbox(tenantID string) bool {
+	return p != nil && p.cfg.IsSandbox(tenantID)
+}
+
+// TTL returns how long sandbox data is kept.
+func (p *Purger) TTL() time.Duration {
+	return p.cfg.TTL
+}
+
+// Purge deletes the sandbox tenants' activity older than the TTL and returns
+// how many rows were deleted. A tenant that fails is logged and skipped.
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	if !p.Enabled() {
+		return 0, nil
+	}
+
+	tenantIDs, err := p.repo.ListTenantIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	before := p.now().Add(-p.cfg.TTL)
+	var purged int64
+	for _, tenantID := range tenantIDs {
+		if !p.cfg.IsSandbox(tenantID) {
+			continue
+		}
+		n, err := p.repo.PurgeTenantData(ctx, tenantID, before)
+		if err != nil {
+			slog.Error("failed to purge sandbox tenant", "tenant_id", tenantID, "error", err)
+			continue
+		}
+		if n > 0 {
+			slog.Info("sandbox data expired", "tenant_id", tenantID, "rows", n, "before", before)
+		}
+		purged += n
+	}
+	return purged, nil
+}
+
+// Run purges expired sandbox data every PurgeInterval until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	if !p.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(p.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Purge(ctx); err != nil {
+				slog.Error("sandbox purge failed", "error", err)
+			}
+		}
+	}
+}
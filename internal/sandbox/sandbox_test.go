@@ -0,0 +1,77 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestIsSandbox(t *testing.T) {
+	cfg := domain.SandboxConfig{Tenants: []string{"demo", "sandbox-*"}}
+	for tenantID, want := range map[string]bool{
+		"demo":         true,
+		"demo-2":       false,
+		"sandbox-acme": true,
+		"sandbox-":     true,
+		"acme":         false,
+	} {
+		if got := cfg.IsSandbox(tenantID); got != want {
+			t.Errorf("IsSandbox(%q) = %v, want %v", tenantID, got, want)
+		}
+	}
+
+	var purger *Purger
+	if purger.IsSandbox("demo") || purger.Enabled() {
+		t.Error("expected a nil purger to know no sandbox tenants")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	now := ospreytest.Epoch.Add(72 * time.Hour)
+
+	save := func(tenantID, id string, at time.Time) {
+		t.Helper()
+		tx := ospreytest.NewTransaction().ID(id).Tenant(tenantID).At(at).Build()
+		if err := repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+		if err := repo.SaveEvaluation(ctx, tenantID, &domain.Evaluation{ID: "eval-" + id, TxID: id, Status: domain.StatusNoAlert, Timestamp: at}); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	save("sandbox-acme", "tx-old", now.Add(-25*time.Hour))
+	save("sandbox-acme", "tx-new", now.Add(-time.Hour))
+	save("tenant-001", "tx-prod", now.Add(-25*time.Hour))
+
+	purger := NewPurger(repo, domain.SandboxConfig{Tenants: []string{"sandbox-*"}})
+	purger.now = func() time.Time { return now }
+
+	purged, err := purger.Purge(ctx)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("expected the old transaction and its evaluation purged, got %d rows", purged)
+	}
+	if _, err := repo.GetTransaction(ctx, "sandbox-acme", "tx-old"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected tx-old purged, got %v", err)
+	}
+	if _, err := repo.GetTransaction(ctx, "sandbox-acme", "tx-new"); err != nil {
+		t.Errorf("expected tx-new kept, got %v", err)
+	}
+	if _, err := repo.GetTransaction(ctx, "tenant-001", "tx-prod"); err != nil {
+		t.Errorf("expected the production tenant untouched, got %v", err)
+	}
+
+	repo.SetError(errors.New("database down"))
+	if _, err := purger.Purge(ctx); err == nil {
+		t.Error("expected a listing failure to fail the purge")
+	}
+}
@@ -0,0 +1,130 @@
+// Package scoring resolves the scoring config each tenant's evaluations are
+// decided with: its own, saved through the API, or the deployment defaults.
+package scoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// DefaultCacheTTL is how long a tenant's config (or its absence) is cached.
+// Another instance's change applies within this time; this instance's at once.
+const DefaultCacheTTL = 30 * time.Second
+
+// CacheKey is the cache key of a tenant's scoring config.
+const CacheKey = "scoring"
+
+// Service loads tenants' scoring configs through the cache.
+type Service struct {
+	repo     domain.Repository
+	cache    domain.Cache
+	defaults domain.ScoringConfig
+	cacheTTL time.Duration
+}
+
+// NewService creates a scoring service. An empty CriticalFail in defaults
+// means domain.CriticalFailAlert.
+func NewService(repo domain.Repository, cache domain.Cache, defaults domain.ScoringConfig) *Service {
+	if defaults.CriticalFail == "" {
+		defaults.CriticalFail = domain.CriticalFailAlert
+	}
+	return &Service{
+		repo:     repo,
+		cache:    cache,
+		defaults: defaults,
+		cacheTTL: DefaultCacheTTL,
+	}
+}
+
+// Defaults returns the config of tenants without their own.
+func (s *Service) Defaults() domain.ScoringConfig {
+	return s.defaults
+}
+
+// Get returns the tenant's own scoring config, or nil if it uses the
+// defaults.
+func (s *Service) Get(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, tenantID, CacheKey)
+		if err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		} else if data != nil {
+			var cfg *domain.ScoringConfig
+			if err := json.Unmarshal(data, &cfg); err == nil {
+				return cfg, nil
+			}
+		}
+	}
+
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	cfg, err := s.repo.GetScoringConfig(ctx, tenantID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get scoring config: %w", err)
+	}
+
+	// Cache misses too ("null"): most tenants use the defaults
+	if s.cache != nil {
+		if data, err := json.Marshal(cfg); err == nil {
+			_ = s.cache.Set(ctx, tenantID, CacheKey, data, s.cacheTTL)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Effective returns the scoring config the tenant's evaluations are decided
+// with.
+func (s *Service) Effective(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	cfg, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		defaults := s.defaults
+		return &defaults, nil
+	}
+	return cfg, nil
+}
+
+// Save stores the tenant's own scoring config.
+func (s *Service) Save(ctx context.Context, tenantID string, cfg *domain.ScoringConfig) error {
+	if s.repo == nil {
+		return fmt.Errorf("no data source available")
+	}
+	if err := s.repo.SaveScoringConfig(ctx, tenantID, cfg); err != nil {
+		return err
+	}
+	return s.invalidate(ctx, tenantID)
+}
+
+// Delete removes the tenant's own scoring config, so it uses the defaults.
+// It returns repository.ErrNotFound if the tenant had none.
+func (s *Service) Delete(ctx context.Context, tenantID string) error {
+	if s.repo == nil {
+		return fmt.Errorf("no data source available")
+	}
+	if err := s.repo.DeleteScoringConfig(ctx, tenantID); err != nil {
+		return err
+	}
+	return s.invalidate(ctx, tenantID)
+}
+
+func (s *Service) invalidate(ctx context.Context, tenantID string) error {
+	if s.cache == nil {
+		return nil
+	}
+	return s.cache.Delete(ctx, tenantID, CacheKey)
+}
@@ -0,0 +1,64 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	lruCache := cache.NewLRUCache(100)
+	defer lruCache.Close()
+
+	svc := NewService(repo, lruCache, domain.ScoringConfig{AlertThreshold: 0.7, WeightedScoring: true})
+	if svc.Defaults().CriticalFail != domain.CriticalFailAlert {
+		t.Errorf("expected critical failures to alert by default, got %q", svc.Defaults().CriticalFail)
+	}
+
+	cfg, err := svc.Effective(ctx, "tenant-001")
+	if err != nil {
+		t.Fatalf("Effective failed: %v", err)
+	}
+	if cfg.AlertThreshold != 0.7 || !cfg.WeightedScoring {
+		t.Errorf("expected the defaults, got %+v", cfg)
+	}
+
+	// Writes bypassing the service stay hidden until the cache entry expires
+	repo.SaveScoringConfig(ctx, "tenant-001", &domain.ScoringConfig{AlertThreshold: 0.2, CriticalFail: domain.CriticalFailScore})
+	if cfg, _ := svc.Effective(ctx, "tenant-001"); cfg.AlertThreshold != 0.7 {
+		t.Errorf("expected the cached defaults, got %+v", cfg)
+	}
+
+	if err := svc.Save(ctx, "tenant-001", &domain.ScoringConfig{AlertThreshold: 0.4, CriticalFail: domain.CriticalFailScore}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	cfg, _ = svc.Effective(ctx, "tenant-001")
+	if cfg.AlertThreshold != 0.4 || cfg.WeightedScoring || cfg.CriticalFail != domain.CriticalFailScore {
+		t.Errorf("expected the tenant's config, got %+v", cfg)
+	}
+	if cfg, _ := svc.Effective(ctx, "tenant-002"); cfg.AlertThreshold != 0.7 {
+		t.Errorf("expected other tenants to keep the defaults, got %+v", cfg)
+	}
+
+	if err := svc.Delete(ctx, "tenant-001"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if cfg, _ := svc.Effective(ctx, "tenant-001"); cfg.AlertThreshold != 0.7 {
+		t.Errorf("expected the defaults after delete, got %+v", cfg)
+	}
+	if err := svc.Delete(ctx, "tenant-001"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	repo.SetError(errors.New("database down"))
+	if _, err := NewService(repo, nil, domain.ScoringConfig{}).Effective(ctx, "tenant-001"); err == nil {
+		t.Error("expected a failed lookup to return an error")
+	}
+}
@@ -0,0 +1,171 @@
+// Package screening provides PEP and adverse-media checks for transaction parties.
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// DefaultCacheTTL is how long a screening result is cached per party.
+const DefaultCacheTTL = 24 * time.Hour
+
+// Subject identifies the party being screened.
+type Subject struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Result is the outcome of screening a party.
+type Result struct {
+	IsPEP             bool    `json:"isPep"`
+	AdverseMediaScore float64 `json:"adverseMediaScore"` // 0.0 (none) to 1.0 (severe)
+	Source            string  `json:"source"`
+}
+
+// Provider performs PEP and adverse-media checks against an external source.
+type Provider interface {
+	// Name identifies the provider in logs and results.
+	Name() string
+
+	// Screen checks a single party.
+	Screen(ctx context.Context, tenantID string, subject Subject) (*Result, error)
+}
+
+// NullProvider is the offline default. It reports every party as clear.
+type NullProvider struct{}
+
+// Name returns the provider name.
+func (NullProvider) Name() string {
+	return "null"
+}
+
+// Screen always returns a clear result.
+func (NullProvider) Screen(ctx context.Context, tenantID string, subject Subject) (*Result, error) {
+	return &Result{Source: "null"}, nil
+}
+
+// Service screens parties through a provider, caching results per tenant.
+type Service struct {
+	provider Provider
+	cache    domain.Cache
+	cacheTTL time.Duration
+}
+
+// NewService creates a screening service. A nil provider falls back to NullProvider
+// and a zero ttl to DefaultCacheTTL.
+func NewService(provider Provider, cache domain.Cache, ttl time.Duration) *Service {
+	if provider == nil {
+		provider = NullProvider{}
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Service{
+		provider: provider,
+		cache:    cache,
+		cacheTTL: ttl,
+	}
+}
+
+// Screen returns the cached result for a subject or queries the provider.
+func (s *Service) Screen(ctx context.Context, tenantID string, subject Subject) (*Result, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	key := cacheKey(subject)
+	if key == "" {
+		return &Result{Source: s.provider.Name()}, nil
+	}
+
+	if s.cache != nil {
+		data, err := s.cache.Get(ctx, tenantID, key)
+		if err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		} else if data != nil {
+			var cached Result
+			if err := json.Unmarshal(data, &cached); err == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	result, err := s.provider.Screen(ctx, tenantID, subject)
+	if err != nil {
+		return nil, fmt.Errorf("%s screening failed: %w", s.provider.Name(), err)
+	}
+
+	if s.cache != nil {
+		if data, err := json.Marshal(result); err == nil {
+			_ = s.cache.Set(ctx, tenantID, key, data, s.cacheTTL)
+		}
+	}
+
+	return result, nil
+}
+
+// cacheKey keys on party ID when present, otherwise on the normalised name.
+func cacheKey(subject Subject) string {
+	if subject.ID != "" {
+		return "screening:id:" + subject.ID
+	}
+	name := strings.ToLower(strings.Join(strings.Fields(subject.Name), " "))
+	if name == "" {
+		return ""
+	}
+	return "screening:name:" + name
+}
+
+// Enricher returns a rules.Enricher exposing is_pep and adverse_media_score to CEL.
+// Both parties are screened; is_pep is true if either is a PEP and
+// adverse_media_score is the higher of the two scores.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "screening"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"is_pep":              cel.BoolType,
+		"adverse_media_score": cel.DoubleType,
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["is_pep"] = false
+	activation["adverse_media_score"] = 0.0
+
+	var errs []error
+	for _, subject := range []Subject{
+		{ID: input.DebtorID, Name: input.DebtorName},
+		{ID: input.CreditorID, Name: input.CreditorName},
+	} {
+		result, err := e.svc.Screen(ctx, input.TenantID, subject)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if result.IsPEP {
+			activation["is_pep"] = true
+		}
+		if result.AdverseMediaScore > activation["adverse_media_score"].(float64) {
+			activation["adverse_media_score"] = result.AdverseMediaScore
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/cache"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// stubProvider returns fixed results per party ID and counts calls.
+type stubProvider struct {
+	results map[string]*Result
+	calls   int
+	err     error
+}
+
+func (p *stubProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubProvider) Screen(ctx context.Context, tenantID string, subject Subject) (*Result, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if r, ok := p.results[subject.ID]; ok {
+		return r, nil
+	}
+	return &Result{Source: "stub"}, nil
+}
+
+func TestScreeningService(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "tenant-001"
+
+	t.Run("NullProviderIsClear", func(t *testing.T) {
+		svc := NewService(nil, nil, 0)
+		result, err := svc.Screen(ctx, tenantID, Subject{ID: "user-001", Name: "Jane Doe"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsPEP || result.AdverseMediaScore != 0 {
+			t.Errorf("expected clear result, got %+v", result)
+		}
+	})
+
+	t.Run("RequiresTenantID", func(t *testing.T) {
+		svc := NewService(nil, nil, 0)
+		if _, err := svc.Screen(ctx, "", Subject{ID: "user-001"}); err == nil {
+			t.Error("expected error for empty tenantID")
+		}
+	})
+
+	t.Run("CachesResults", func(t *testing.T) {
+		lruCache := cache.NewLRUCache(100)
+		defer lruCache.Close()
+
+		provider := &stubProvider{results: map[string]*Result{
+			"user-pep": {IsPEP: true, AdverseMediaScore: 0.4, Source: "stub"},
+		}}
+		svc := NewService(provider, lruCache, 0)
+
+		for i := 0; i < 3; i++ {
+			result, err := svc.Screen(ctx, tenantID, Subject{ID: "user-pep"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsPEP {
+				t.Error("expected PEP result")
+			}
+		}
+		if provider.calls != 1 {
+			t.Errorf("expected 1 provider call, got %d", provider.calls)
+		}
+	})
+
+	t.Run("EnricherExposesScreeningToRules", func(t *testing.T) {
+		provider := &stubProvider{results: map[string]*Result{
+			"creditor-pep": {IsPEP: true, AdverseMediaScore: 0.8, Source: "stub"},
+			"debtor-media": {AdverseMediaScore: 0.3, Source: "stub"},
+		}}
+		svc := NewService(provider, nil, 0)
+
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "pep-adverse-media",
+			Expression: `is_pep && adverse_media_score > 0.5`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		results, _ := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:   tenantID,
+			TxID:       "tx-screen-001",
+			Type:       "transfer",
+			DebtorID:   "debtor-media",
+			CreditorID: "creditor-pep",
+			Amount:     domain.MustDecimal("500.0"),
+			Currency:   "USD",
+		})
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match PEP creditor with adverse media, got score %.2f", results[0].Score)
+		}
+	})
+
+	t.Run("ProviderFailureLeavesDefaults", func(t *testing.T) {
+		svc := NewService(&stubProvider{err: errors.New("offline")}, nil, 0)
+		activation := map[string]any{}
+		input := &rules.EvaluateInput{TenantID: tenantID, DebtorID: "a", CreditorID: "b"}
+		if err := svc.Enricher().Enrich(ctx, input, activation); err == nil {
+			t.Error("expected provider error to be reported")
+		}
+		if activation["is_pep"] != false || activation["adverse_media_score"] != 0.0 {
+			t.Errorf("expected defaults, got %v", activation)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+// Package signing signs response and webhook bodies with detached JWS
+// (RFC 7515, Appendix F), so downstream systems can verify a decision came
+// from this deployment even after it passed through intermediaries.
+//
+// A signature is a compact JWS whose payload part is left empty:
+// "<header>..<signature>". To verify it, a receiver puts the base64url
+// encoded body back between the dots and checks the result against the key
+// in the deployment's JWKS, found by the header's kid. Ed25519 (EdDSA) and
+// ECDSA P-256 (ES256) keys are supported.
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// HeaderSignature carries the detached JWS of a response or webhook body.
+const HeaderSignature = "X-JWS-Signature"
+
+// Signature algorithms.
+const (
+	AlgEdDSA = "EdDSA"
+	AlgES256 = "ES256"
+)
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Header is the protected header of a signature.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Iat int64  `json:"iat"` // signing time, unix seconds
+}
+
+// Signer signs payloads with the deployment's private key.
+type Signer struct {
+	key crypto.Signer
+	jwk JWK
+	now func() time.Time
+}
+
+// LoadKey reads a PEM encoded PKCS #8 private key, as written by
+// "openssl genpkey -algorithm ed25519", and returns a signer for it.
+func LoadKey(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	return NewSigner(signer)
+}
+
+// NewSigner returns a signer for an Ed25519 or ECDSA P-256 private key. The
+// key ID is the key's RFC 7638 thumbprint.
+func NewSigner(key crypto.Signer) (*Signer, error) {
+	var jwk JWK
+	switch pub := key.Public().(type) {
+	case ed25519.PublicKey:
+		jwk = JWK{Kty: "OKP", Crv: "Ed25519", X: encode(pub), Alg: AlgEdDSA}
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 ECDSA signing keys are supported")
+		}
+		jwk = JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   encode(pub.X.FillBytes(make([]byte, 32))),
+			Y:   encode(pub.Y.FillBytes(make([]byte, 32))),
+			Alg: AlgES256,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", pub)
+	}
+	jwk.Use = "sig"
+	jwk.Kid = thumbprint(jwk)
+	return &Signer{key: key, jwk: jwk, now: time.Now}, nil
+}
+
+// thumbprint returns the RFC 7638 thumbprint of a public key.
+func thumbprint(jwk JWK) string {
+	// The required members in lexicographic order, without whitespace
+	var members string
+	if jwk.Kty == "OKP" {
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Crv, jwk.Kty, jwk.X)
+	} else {
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return encode(sum[:])
+}
+
+// KeyID returns the kid of the signing key.
+func (s *Signer) KeyID() string {
+	return s.jwk.Kid
+}
+
+// JWKS returns the key set that verifies this signer's signatures.
+func (s *Signer) JWKS() JWKS {
+	return JWKS{Keys: []JWK{s.jwk}}
+}
+
+// Public returns the public key.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Sign returns the detached JWS of payload.
+func (s *Signer) Sign(payload []byte) (string, error) {
+	header, err := json.Marshal(Header{Alg: s.jwk.Alg, Kid: s.jwk.Kid, Iat: s.now().Unix()})
+	if err != nil {
+		return "", err
+	}
+	protected := encode(header)
+	input := []byte(protected + "." + encode(payload))
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		r, sv, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", err
+		}
+		// JWS encodes ES256 signatures as R || S, 32 bytes each
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sv.FillBytes(signature[32:])
+	default:
+		if signature, err = s.key.Sign(rand.Reader, input, crypto.Hash(0)); err != nil {
+			return "", err
+		}
+	}
+	return protected + ".." + encode(signature), nil
+}
+
+// Verify checks a detached JWS of payload against a public key and returns
+// its header.
+func Verify(pub crypto.PublicKey, jws string, payload []byte) (*Header, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return nil, errors.New("not a detached JWS")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid header encoding: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	input := []byte(parts[0] + "." + encode(payload))
+
+	valid := false
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		valid = header.Alg == AlgEdDSA && ed25519.Verify(key, input, signature)
+	case *ecdsa.PublicKey:
+		if header.Alg == AlgES256 && len(signature) == 64 {
+			digest := sha256.Sum256(input)
+			r := new(big.Int).SetBytes(signature[:32])
+			sv := new(big.Int).SetBytes(signature[32:])
+			valid = ecdsa.Verify(key, digest[:], r, sv)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if !valid {
+		return nil, errors.New("signature does not match")
+	}
+	return &header, nil
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
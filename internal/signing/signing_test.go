@@ -0,0 +1,110 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThumbprint(t *testing.T) {
+	// RFC 8037, Appendix A.3
+	seed, _ := base64.RawURLEncoding.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	signer, err := NewSigner(ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	if got := signer.KeyID(); got != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Errorf("kid = %q, want the RFC 8037 thumbprint", got)
+	}
+	if jwk := signer.JWKS().Keys[0]; jwk.X != "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" || jwk.Alg != AlgEdDSA {
+		t.Errorf("unexpected JWK %+v", jwk)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	payload := []byte(`{"evaluationId":"eval-001","status":"ALRT"}` + "\n")
+
+	for name, key := range map[string]crypto.Signer{
+		AlgEdDSA: edKey,
+		AlgES256: ecKey,
+	} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewSigner(key)
+			if err != nil {
+				t.Fatalf("NewSigner failed: %v", err)
+			}
+			signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+			jws, err := signer.Sign(payload)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if parts := strings.Split(jws, "."); len(parts) != 3 || parts[1] != "" {
+				t.Fatalf("expected a detached JWS, got %q", jws)
+			}
+
+			header, err := Verify(signer.Public(), jws, payload)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if header.Alg != name || header.Kid != signer.KeyID() || header.Iat != 1700000000 {
+				t.Errorf("unexpected header %+v", header)
+			}
+
+			tampered := []byte(strings.Replace(string(payload), "ALRT", "NALT", 1))
+			if _, err := Verify(signer.Public(), jws, tampered); err == nil {
+				t.Error("expected a tampered payload to fail verification")
+			}
+		})
+	}
+
+	if _, err := NewSigner(mustECKey(t, elliptic.P384())); err == nil {
+		t.Error("expected a P-384 key to be refused")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	signer, err := LoadKey(path)
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	if !key.Public().(ed25519.PublicKey).Equal(signer.Public()) {
+		t.Error("expected the loaded key")
+	}
+
+	os.WriteFile(path, []byte("not a key"), 0o600)
+	if _, err := LoadKey(path); err == nil {
+		t.Error("expected a non-PEM file to be refused")
+	}
+}
+
+func mustECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return key
+}
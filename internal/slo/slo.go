@@ -0,0 +1,319 @@
+// Package slo tracks the API endpoints' availability and latency against
+// their service level objectives, so operators can manage error budgets.
+//
+// Each objective's requests are counted in one-minute buckets covering the
+// SLO window. A request fails the availability objective with a 5xx status
+// and the latency objective when slower than the objective's latency. The
+// error budget is the fraction of requests allowed to fail, 1 - target; a
+// burn rate of 1 spends it exactly over the window, 10 spends it in a tenth
+// of the window. Counts are kept in memory per instance and start over on
+// restart.
+package slo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// bucketSize is the resolution of the request counts.
+const bucketSize = time.Minute
+
+// BurnWindows are the windows burn rates are reported over, the short and
+// long windows of the usual multiwindow burn rate alerts. Windows longer than
+// the SLO window are left out.
+var BurnWindows = []struct {
+	Name   string
+	Window time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"3d", 3 * 24 * time.Hour},
+}
+
+// bucket counts the requests of one minute.
+type bucket struct {
+	minute int64 // unix minute the counts belong to
+	total  int64
+	errors int64
+	slow   int64
+}
+
+// objective is an objective with its request counts.
+type objective struct {
+	domain.SLOObjective
+	buckets []bucket
+}
+
+// Tracker counts requests against the configured objectives.
+type Tracker struct {
+	mu         sync.Mutex
+	objectives []*objective
+	byRoute    map[string]*objective
+	window     time.Duration
+	since      time.Time
+	now        func() time.Time
+}
+
+// NewTracker creates a tracker. A zero window uses the default.
+func NewTracker(cfg domain.SLOConfig) *Tracker {
+	if cfg.Window <= 0 {
+		cfg.Window = domain.DefaultConfig().SLO.Window
+	}
+	t := &Tracker{
+		byRoute: make(map[string]*objective),
+		window:  cfg.Window,
+		since:   time.Now(),
+		now:     time.Now,
+	}
+	size := int(cfg.Window / bucketSize)
+	for _, o := range cfg.Objectives {
+		obj := &objective{SLOObjective: o, buckets: make([]bucket, size)}
+		t.objectives = append(t.objectives, obj)
+		t.byRoute[o.Route] = obj
+	}
+	return t
+}
+
+// Enabled reports whether any objective is configured.
+func (t *Tracker) Enabled() bool {
+	return t != nil && len(t.objectives) > 0
+}
+
+// Record counts a request to route, a method and route pattern such as
+// "GET /evaluations/{id}". Requests to routes without an objective are
+// ignored.
+func (t *Tracker) Record(route string, status int, duration time.Duration) {
+	if !t.Enabled() {
+		return
+	}
+	obj, ok := t.byRoute[route]
+	if !ok {
+		return
+	}
+
+	minute := t.now().Unix() / int64(bucketSize/time.Second)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &obj.buckets[minute%int64(len(obj.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if status >= http.StatusInternalServerError {
+		b.errors++
+	}
+	if obj.LatencyTarget > 0 && duration > obj.Latency {
+		b.slow++
+	}
+}
+
+// Middleware records every request against the objective of its route.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	if !t.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			t.Record(r.Method+" "+rctx.RoutePattern(), rw.status, time.Since(start))
+		}
+	})
+}
+
+// statusWriter captures the response status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush passes flushes of streamed responses through.
+func (w *statusWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Report is an objective's standing over the SLO window.
+type Report struct {
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+
+	Availability SLIReport  `json:"availability"`
+	Latency      *SLIReport `json:"latency,omitempty"` // nil without a latency objective
+}
+
+// SLIReport is how one service level indicator stands against its target.
+type SLIReport struct {
+	Target    float64 `json:"target"`
+	Threshold string  `json:"threshold,omitempty"` // the latency requests must beat
+	Good      int64   `json:"good"`
+	Bad       int64   `json:"bad"`
+
+	// Actual is the fraction of good requests, 1 without requests
+	Actual float64 `json:"actual"`
+
+	// BudgetRemaining is the fraction of the error budget left; negative
+	// once it is overspent
+	BudgetRemaining float64 `json:"budgetRemaining"`
+
+	// BurnRates is the rate the budget is spent at over each burn window
+	BurnRates map[string]float64 `json:"burnRates"`
+}
+
+// Window returns the period each error budget covers.
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
+
+// Since returns when counting started.
+func (t *Tracker) Since() time.Time {
+	return t.since
+}
+
+// Reports returns every objective's standing, in configuration order.
+func (t *Tracker) Reports() []Report {
+	if !t.Enabled() {
+		return []Report{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	reports := make([]Report, 0, len(t.objectives))
+	for _, obj := range t.objectives {
+		total, errors, slow := obj.count(now, t.window)
+		report := Report{
+			Route:        obj.Route,
+			Requests:     total,
+			Availability: sliReport(obj.Availability, total, errors),
+		}
+		if obj.LatencyTarget > 0 {
+			latency := sliReport(obj.LatencyTarget, total, slow)
+			latency.Threshold = obj.Latency.String()
+			report.Latency = &latency
+		}
+
+		for _, w := range BurnWindows {
+			if w.Window > t.window {
+				continue
+			}
+			total, errors, slow := obj.count(now, w.Window)
+			report.Availability.BurnRates[w.Name] = burnRate(obj.Availability, total, errors)
+			if report.Latency != nil {
+				report.Latency.BurnRates[w.Name] = burnRate(obj.LatencyTarget, total, slow)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// count sums the requests of the last window.
+func (o *objective) count(now time.Time, window time.Duration) (total, errors, slow int64) {
+	current := now.Unix() / int64(bucketSize/time.Second)
+	first := current - int64(window/bucketSize) + 1
+	for _, b := range o.buckets {
+		if b.minute >= first && b.minute <= current {
+			total += b.total
+			errors += b.errors
+			slow += b.slow
+		}
+	}
+	return total, errors, slow
+}
+
+// sliReport reports bad of total requests against target.
+func sliReport(target float64, total, bad int64) SLIReport {
+	report := SLIReport{
+		Target:          target,
+		Good:            total - bad,
+		Bad:             bad,
+		Actual:          1,
+		BudgetRemaining: 1,
+		BurnRates:       make(map[string]float64),
+	}
+	if total > 0 {
+		report.Actual = float64(total-bad) / float64(total)
+		report.BudgetRemaining = 1 - burnRate(target, total, bad)
+	}
+	return report
+}
+
+// burnRate is the fraction of failed requests relative to the error budget.
+func burnRate(target float64, total, bad int64) float64 {
+	if total == 0 || target >= 1 {
+		return 0
+	}
+	return float64(bad) / float64(total) / (1 - target)
+}
+
+// ParseObjectives parses objectives separated by semicolons, each
+// "route=availability" or "route=availability,latencyTarget@latency", e.g.
+// "POST /evaluate=0.999,0.99@250ms;GET /evaluations/{id}=0.999".
+func ParseObjectives(s string) ([]domain.SLOObjective, error) {
+	var objectives []domain.SLOObjective
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		route = strings.TrimSpace(route)
+		if !ok || !strings.Contains(route, " /") {
+			return nil, fmt.Errorf("invalid objective %q (want \"METHOD /route=availability\")", entry)
+		}
+
+		o := domain.SLOObjective{Route: route}
+		availability, latency, hasLatency := strings.Cut(value, ",")
+		var err error
+		if o.Availability, err = parseTarget(availability); err != nil {
+			return nil, fmt.Errorf("%s: availability: %w", route, err)
+		}
+		if hasLatency {
+			target, threshold, ok := strings.Cut(latency, "@")
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid latency objective %q (want target@duration)", route, latency)
+			}
+			if o.LatencyTarget, err = parseTarget(target); err != nil {
+				return nil, fmt.Errorf("%s: latency: %w", route, err)
+			}
+			if o.Latency, err = time.ParseDuration(strings.TrimSpace(threshold)); err != nil || o.Latency <= 0 {
+				return nil, fmt.Errorf("%s: invalid latency %q", route, threshold)
+			}
+		}
+		objectives = append(objectives, o)
+	}
+	return objectives, nil
+}
+
+// parseTarget parses a target fraction between 0 and 1, exclusive.
+func parseTarget(s string) (float64, error) {
+	target, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	if target <= 0 || target >= 1 {
+		return 0, fmt.Errorf("target %v must be between 0 and 1", target)
+	}
+	return target, nil
+}
@@ -0,0 +1,131 @@
+package slo
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(domain.SLOConfig{
+		Objectives: []domain.SLOObjective{
+			{Route: "POST /evaluate", Availability: 0.99, LatencyTarget: 0.9, Latency: 100 * time.Millisecond},
+			{Route: "GET /evaluations/{id}", Availability: 0.999},
+		},
+		Window: 24 * time.Hour,
+	})
+	tracker.now = func() time.Time { return now }
+
+	// Two hours ago: 100 good requests
+	now = now.Add(-2 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.Record("POST /evaluate", http.StatusOK, 10*time.Millisecond)
+	}
+	// Now: 98 good, 2 failed, 5 slow
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 98; i++ {
+		duration := 10 * time.Millisecond
+		if i < 5 {
+			duration = time.Second
+		}
+		tracker.Record("POST /evaluate", http.StatusOK, duration)
+	}
+	tracker.Record("POST /evaluate", http.StatusInternalServerError, 10*time.Millisecond)
+	tracker.Record("POST /evaluate", http.StatusServiceUnavailable, 10*time.Millisecond)
+	tracker.Record("POST /evaluate", http.StatusBadRequest, 10*time.Millisecond)
+	tracker.Record("GET /rules", http.StatusInternalServerError, 0)
+
+	reports := tracker.Reports()
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	evaluate := reports[0]
+	if evaluate.Requests != 201 || evaluate.Availability.Bad != 2 || evaluate.Availability.Good != 199 {
+		t.Errorf("unexpected availability counts: %+v", evaluate)
+	}
+	// 2 bad of 201 against a 1% budget
+	if want := 1 - (2.0/201)/0.01; !near(evaluate.Availability.BudgetRemaining, want) {
+		t.Errorf("expected budget remaining %v, got %v", want, evaluate.Availability.BudgetRemaining)
+	}
+	// The last hour only saw the 101 recent requests
+	if want := (2.0 / 101) / 0.01; !near(evaluate.Availability.BurnRates["1h"], want) {
+		t.Errorf("expected 1h burn rate %v, got %v", want, evaluate.Availability.BurnRates["1h"])
+	}
+	if _, ok := evaluate.Availability.BurnRates["3d"]; ok {
+		t.Error("expected no burn rate over a window longer than the SLO window")
+	}
+	if evaluate.Latency == nil || evaluate.Latency.Bad != 5 || evaluate.Latency.Threshold != "100ms" {
+		t.Errorf("unexpected latency report: %+v", evaluate.Latency)
+	}
+
+	idle := reports[1]
+	if idle.Requests != 0 || idle.Availability.Actual != 1 || idle.Availability.BudgetRemaining != 1 || idle.Latency != nil {
+		t.Errorf("expected an untouched budget without requests, got %+v", idle)
+	}
+
+	// Requests older than the window drop out
+	now = now.Add(23 * time.Hour)
+	if reports := tracker.Reports(); reports[0].Requests != 101 {
+		t.Errorf("expected only the requests within 24h, got %d", reports[0].Requests)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tracker := NewTracker(domain.SLOConfig{Objectives: []domain.SLOObjective{{Route: "GET /evaluations/{id}", Availability: 0.999}}})
+
+	router := chi.NewRouter()
+	router.Use(tracker.Middleware)
+	router.Get("/evaluations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	for _, path := range []string{"/evaluations/a", "/evaluations/b", "/evaluations/broken", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	report := tracker.Reports()[0]
+	if report.Requests != 3 || report.Availability.Bad != 1 {
+		t.Errorf("expected 3 requests with 1 bad counted by route pattern, got %+v", report)
+	}
+
+	var disabled *Tracker
+	if disabled.Enabled() || len(disabled.Reports()) != 0 {
+		t.Error("expected a nil tracker to track nothing")
+	}
+}
+
+func TestParseObjectives(t *testing.T) {
+	objectives, err := ParseObjectives("POST /evaluate=0.999,0.99@250ms; GET /evaluations/{id}=0.995")
+	if err != nil {
+		t.Fatalf("ParseObjectives failed: %v", err)
+	}
+	want := []domain.SLOObjective{
+		{Route: "POST /evaluate", Availability: 0.999, LatencyTarget: 0.99, Latency: 250 * time.Millisecond},
+		{Route: "GET /evaluations/{id}", Availability: 0.995},
+	}
+	if len(objectives) != len(want) || objectives[0] != want[0] || objectives[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, objectives)
+	}
+
+	for _, invalid := range []string{
+		"/evaluate=0.999",
+		"POST /evaluate",
+		"POST /evaluate=1",
+		"POST /evaluate=0.999,0.99",
+		"POST /evaluate=0.999,0.99@soon",
+	} {
+		if _, err := ParseObjectives(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opensource-finance/osprey/internal/yamlsubset"
+)
+
+// ReadDir reads a Spec from the rule and typology files under root: each
+// .yaml, .yml or .json file in root/rules holds one RuleSpec and each in
+// root/typologies one TypologySpec. Rules record their file, relative to
+// root, as their source. Every invalid file is reported.
+func ReadDir(root string) (*Spec, error) {
+	spec := &Spec{}
+	err := readFiles(filepath.Join(root, "rules"), func(name string, data []byte) error {
+		var r RuleSpec
+		if err := decodeFile(name, data, &r); err != nil {
+			return err
+		}
+		r.SourceFile = filepath.ToSlash(name)
+		spec.Rules = append(spec.Rules, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFiles(filepath.Join(root, "typologies"), func(name string, data []byte) error {
+		var t TypologySpec
+		if err := decodeFile(name, data, &t); err != nil {
+			return err
+		}
+		spec.Typologies = append(spec.Typologies, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// SpecFiles returns the paths of the files ReadDir reads under root, in the
+// order it reads them.
+func SpecFiles(root string) ([]string, error) {
+	var paths []string
+	for _, dir := range []string{"rules", "typologies"} {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if specFile(entry) {
+				paths = append(paths, filepath.Join(root, dir, entry.Name()))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// readFiles calls fn for each .yaml, .yml and .json file in dir, in name
+// order. A missing directory has no files.
+func readFiles(dir string, fn func(name string, data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if !specFile(entry) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			err = fn(filepath.Join(filepath.Base(dir), entry.Name()), data)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// specFile reports whether a directory entry is a rule or typology file.
+func specFile(entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return false
+	}
+	switch filepath.Ext(entry.Name()) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// decodeFile decodes a YAML or JSON file into v, rejecting unknown fields.
+func decodeFile(name string, data []byte, v any) error {
+	if filepath.Ext(name) != ".json" {
+		doc, err := yamlsubset.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
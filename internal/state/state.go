@@ -0,0 +1,639 @@
+// Package state manages rules and typologies declaratively.
+//
+// A Spec is the complete desired configuration. Plan compares it with the
+// stored global configuration and Apply saves the difference and reloads the
+// engines, so applying the same Spec twice changes nothing. Rules and
+// typologies missing from the Spec are disabled and removed respectively.
+// PlanMerge instead only adds and updates, for importing into a tenant.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// GlobalTenantID is the tenant managed rules and typologies are saved under.
+const GlobalTenantID = "*"
+
+// DefaultVersion is used for specs that don't set a version.
+const DefaultVersion = "1.0.0"
+
+// ErrInvalid is returned by Plan when the Spec fails validation.
+var ErrInvalid = errors.New("invalid state")
+
+// Change actions.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Spec is the desired set of rules and typologies.
+type Spec struct {
+	Rules      []RuleSpec     `json:"rules"`
+	Typologies []TypologySpec `json:"typologies"`
+}
+
+// RuleSpec declares a rule. Fields match POST /rules; Enabled defaults to true.
+type RuleSpec struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Language    string            `json:"language,omitempty"`
+	Expression  string            `json:"expression"`
+	Bands       []domain.RuleBand `json:"bands,omitempty"`
+	Weight      float64           `json:"weight"`
+	Enabled     *bool             `json:"enabled,omitempty"`
+	SampleRate  float64           `json:"sampleRate,omitempty"`
+	Shadow      bool              `json:"shadow,omitempty"`
+
+	// SourceFile is set by ReadDir, not in the file
+	SourceFile string `json:"-"`
+}
+
+// TypologySpec declares a typology. Fields match POST /typologies; Enabled
+// defaults to true.
+type TypologySpec struct {
+	ID             string                      `json:"id"`
+	Name           string                      `json:"name"`
+	Description    string                      `json:"description,omitempty"`
+	Version        string                      `json:"version,omitempty"`
+	Rules          []domain.TypologyRuleWeight `json:"rules"`
+	AlertThreshold float64                     `json:"alertThreshold"`
+	MinRulesFired  int                         `json:"minRulesFired,omitempty"`
+	MinCoverage    float64                     `json:"minCoverage,omitempty"`
+	Typologies     []domain.TypologyWeight     `json:"typologies,omitempty"`
+	Condition      *domain.TypologyCondition   `json:"condition,omitempty"`
+	Enabled        *bool                       `json:"enabled,omitempty"`
+}
+
+func (s *RuleSpec) config(tenantID string) *domain.RuleConfig {
+	return &domain.RuleConfig{
+		ID:          s.ID,
+		TenantID:    tenantID,
+		Name:        s.Name,
+		Description: s.Description,
+		Version:     versionOrDefault(s.Version),
+		Owner:       s.Owner,
+		SourceFile:  s.SourceFile,
+		Language:    s.Language,
+		Expression:  s.Expression,
+		Bands:       s.Bands,
+		Weight:      s.Weight,
+		Enabled:     s.Enabled == nil || *s.Enabled,
+		SampleRate:  s.SampleRate,
+		Shadow:      s.Shadow,
+	}
+}
+
+func (s *TypologySpec) typology(tenantID string) *domain.Typology {
+	return &domain.Typology{
+		ID:             s.ID,
+		TenantID:       tenantID,
+		Name:           s.Name,
+		Description:    s.Description,
+		Version:        versionOrDefault(s.Version),
+		Rules:          s.Rules,
+		AlertThreshold: s.AlertThreshold,
+		MinRulesFired:  s.MinRulesFired,
+		MinCoverage:    s.MinCoverage,
+		Typologies:     s.Typologies,
+		Condition:      s.Condition,
+		Enabled:        s.Enabled == nil || *s.Enabled,
+	}
+}
+
+func versionOrDefault(v string) string {
+	if v == "" {
+		return DefaultVersion
+	}
+	return v
+}
+
+// Change is one planned change to a rule or typology.
+type Change struct {
+	ID              string `json:"id"`
+	Action          string `json:"action"`
+	Version         string `json:"version,omitempty"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+}
+
+// Plan lists the changes needed to reach a Spec, sorted by ID.
+type Plan struct {
+	Rules      []Change `json:"rules"`
+	Typologies []Change `json:"typologies"`
+	Unchanged  int      `json:"unchanged"`
+
+	tenantID         string
+	saveRules        map[string]*domain.RuleConfig
+	disableRules     []*domain.RuleConfig
+	saveTypologies   map[string]*domain.Typology
+	removeTypologies []string
+
+	enabledRules int                          // stored enabled rules
+	thresholds   []guardrails.ThresholdChange // of updated typologies
+}
+
+// Empty reports whether the plan changes nothing.
+func (p *Plan) Empty() bool {
+	return len(p.Rules) == 0 && len(p.Typologies) == 0
+}
+
+// Mutation describes the plan for the guardrails: the rules it deletes and
+// the alert thresholds it moves.
+func (p *Plan) Mutation() guardrails.Change {
+	return guardrails.Change{
+		EnabledRules:  p.enabledRules,
+		DisabledRules: IDs(p.Rules, ActionDelete),
+		Thresholds:    p.thresholds,
+	}
+}
+
+// IDs returns the IDs of changes with the given action.
+func IDs(changes []Change, action string) []string {
+	ids := []string{}
+	for _, c := range changes {
+		if c.Action == action {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
+
+// SetBuildMetadata appends "+meta" to the version of every rule and typology
+// the plan creates or updates, e.g. to record the commit they came from.
+func (p *Plan) SetBuildMetadata(meta string) {
+	for i, c := range p.Rules {
+		if rule, ok := p.saveRules[c.ID]; ok {
+			rule.Version = baseVersion(rule.Version) + "+" + meta
+			p.Rules[i].Version = rule.Version
+		}
+	}
+	for i, c := range p.Typologies {
+		if t, ok := p.saveTypologies[c.ID]; ok {
+			t.Version = baseVersion(t.Version) + "+" + meta
+			p.Typologies[i].Version = t.Version
+		}
+	}
+}
+
+// Manager plans and applies Specs against the stored configuration.
+type Manager struct {
+	repo       domain.Repository
+	engine     *rules.Engine
+	typologies *rules.TypologyEngine
+	mu         sync.Mutex // serializes applies
+	applied    func(ctx context.Context, tenantID string)
+}
+
+// NewManager creates a manager. typologies may be nil.
+func NewManager(repo domain.Repository, engine *rules.Engine, typologies *rules.TypologyEngine) *Manager {
+	return &Manager{repo: repo, engine: engine, typologies: typologies}
+}
+
+// OnApply sets fn to run after each plan is applied and loaded into the
+// engines, e.g. to tell other replicas to reload.
+func (m *Manager) OnApply(fn func(ctx context.Context, tenantID string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.applied = fn
+}
+
+// Current returns the stored enabled rules and typologies as a Spec.
+func (m *Manager) Current(ctx context.Context) (*Spec, error) {
+	return m.CurrentOf(ctx, GlobalTenantID)
+}
+
+// CurrentOf returns a tenant's own stored enabled rules and typologies as a
+// Spec, without the global ones that also apply to it.
+func (m *Manager) CurrentOf(ctx context.Context, tenantID string) (*Spec, error) {
+	storedRules, err := m.repo.ListRuleConfigs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	storedTypologies, err := m.repo.ListTypologies(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list typologies: %w", err)
+	}
+
+	spec := &Spec{Rules: []RuleSpec{}, Typologies: []TypologySpec{}}
+	for _, r := range storedRules {
+		spec.Rules = append(spec.Rules, RuleSpec{
+			ID:          r.ID,
+			Name:        r.Name,
+			Description: r.Description,
+			Version:     r.Version,
+			Owner:       r.Owner,
+			Language:    r.Language,
+			Expression:  r.Expression,
+			Bands:       r.Bands,
+			Weight:      r.Weight,
+			SampleRate:  r.SampleRate,
+			Shadow:      r.Shadow,
+		})
+	}
+	for _, t := range storedTypologies {
+		spec.Typologies = append(spec.Typologies, TypologySpec{
+			ID:             t.ID,
+			Name:           t.Name,
+			Description:    t.Description,
+			Version:        t.Version,
+			Rules:          t.Rules,
+			AlertThreshold: t.AlertThreshold,
+			MinRulesFired:  t.MinRulesFired,
+			MinCoverage:    t.MinCoverage,
+			Typologies:     t.Typologies,
+			Condition:      t.Condition,
+		})
+	}
+	sort.Slice(spec.Rules, func(i, j int) bool { return spec.Rules[i].ID < spec.Rules[j].ID })
+	sort.Slice(spec.Typologies, func(i, j int) bool { return spec.Typologies[i].ID < spec.Typologies[j].ID })
+	return spec, nil
+}
+
+// Plan validates spec and compares it with the stored global configuration.
+// Validation failures wrap ErrInvalid.
+func (m *Manager) Plan(ctx context.Context, spec *Spec) (*Plan, error) {
+	return m.plan(ctx, GlobalTenantID, spec, false)
+}
+
+// PlanMerge validates spec and compares it with a tenant's stored
+// configuration, like Plan, but leaves rules and typologies missing from the
+// spec in place. Typologies may also refer to the enabled rules already
+// stored for the tenant or globally.
+func (m *Manager) PlanMerge(ctx context.Context, tenantID string, spec *Spec) (*Plan, error) {
+	return m.plan(ctx, tenantID, spec, true)
+}
+
+func (m *Manager) plan(ctx context.Context, tenantID string, spec *Spec, merge bool) (*Plan, error) {
+	storedRules, err := m.repo.ListRuleConfigs(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	storedTypologies, err := m.repo.ListTypologies(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list typologies: %w", err)
+	}
+
+	desiredRules := make([]*domain.RuleConfig, 0, len(spec.Rules))
+	for i := range spec.Rules {
+		desiredRules = append(desiredRules, spec.Rules[i].config(tenantID))
+	}
+	desiredTypologies := make([]*domain.Typology, 0, len(spec.Typologies))
+	for i := range spec.Typologies {
+		desiredTypologies = append(desiredTypologies, spec.Typologies[i].typology(tenantID))
+	}
+
+	// A merge keeps the stored rules and typologies, so typologies may refer
+	// to them
+	known := make(map[string]bool)
+	var knownTypologies []*domain.Typology
+	if merge {
+		for _, rule := range storedRules {
+			known[rule.ID] = true
+		}
+		knownTypologies = storedTypologies
+		if tenantID != GlobalTenantID {
+			globalRules, err := m.repo.ListRuleConfigs(ctx, GlobalTenantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list rules: %w", err)
+			}
+			for _, rule := range globalRules {
+				known[rule.ID] = true
+			}
+			globalTypologies, err := m.repo.ListTypologies(ctx, GlobalTenantID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list typologies: %w", err)
+			}
+			knownTypologies = append(globalTypologies, knownTypologies...)
+		}
+	}
+	if err := m.validate(desiredRules, desiredTypologies, known, knownTypologies); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	plan := &Plan{
+		Rules:          []Change{},
+		Typologies:     []Change{},
+		tenantID:       tenantID,
+		saveRules:      make(map[string]*domain.RuleConfig),
+		saveTypologies: make(map[string]*domain.Typology),
+	}
+	plan.planRules(desiredRules, storedRules, merge)
+	plan.planTypologies(desiredTypologies, storedTypologies, merge)
+	return plan, nil
+}
+
+// planRules plans the changes from storedRules to desired. Unless merging,
+// stored rules missing from desired are deleted.
+func (p *Plan) planRules(desired, storedRules []*domain.RuleConfig, merge bool) {
+	stored := make(map[string][]*domain.RuleConfig)
+	for _, rule := range storedRules {
+		stored[rule.ID] = append(stored[rule.ID], rule)
+	}
+	p.enabledRules = len(stored)
+
+	for _, rule := range desired {
+		current := stored[rule.ID]
+		delete(stored, rule.ID)
+
+		change := Change{ID: rule.ID, Version: rule.Version}
+		if len(current) > 0 {
+			change.PreviousVersion = current[0].Version
+		}
+		switch {
+		case !rule.Enabled && len(current) == 0:
+			continue
+		case !rule.Enabled:
+			change.Action, change.Version = ActionDelete, ""
+		case len(current) == 1 && sameRule(current[0], rule):
+			p.Unchanged++
+			continue
+		case len(current) == 0:
+			change.Action = ActionCreate
+			p.saveRules[rule.ID] = rule
+		default:
+			change.Action = ActionUpdate
+			p.saveRules[rule.ID] = rule
+		}
+		p.disableRules = append(p.disableRules, current...)
+		p.Rules = append(p.Rules, change)
+	}
+	if !merge {
+		for id, current := range stored {
+			p.disableRules = append(p.disableRules, current...)
+			p.Rules = append(p.Rules, Change{ID: id, Action: ActionDelete, PreviousVersion: current[0].Version})
+		}
+	}
+	sort.Slice(p.Rules, func(i, j int) bool { return p.Rules[i].ID < p.Rules[j].ID })
+}
+
+// planTypologies plans the changes from storedTypologies to desired. Unless
+// merging, stored typologies missing from desired are removed.
+func (p *Plan) planTypologies(desired, storedTypologies []*domain.Typology, merge bool) {
+	stored := make(map[string][]*domain.Typology)
+	for _, t := range storedTypologies {
+		stored[t.ID] = append(stored[t.ID], t)
+	}
+
+	for _, t := range desired {
+		current := stored[t.ID]
+		delete(stored, t.ID)
+
+		change := Change{ID: t.ID, Version: t.Version}
+		if len(current) > 0 {
+			change.PreviousVersion = current[0].Version
+		}
+		switch {
+		case !t.Enabled && len(current) == 0:
+			continue
+		case !t.Enabled:
+			change.Action, change.Version = ActionDelete, ""
+		case len(current) == 1 && sameTypology(current[0], t):
+			p.Unchanged++
+			continue
+		case len(current) == 0:
+			change.Action = ActionCreate
+			p.saveTypologies[t.ID] = t
+		default:
+			change.Action = ActionUpdate
+			p.saveTypologies[t.ID] = t
+			if from := current[0].AlertThreshold; from != t.AlertThreshold {
+				p.thresholds = append(p.thresholds, guardrails.ThresholdChange{TypologyID: t.ID, From: from, To: t.AlertThreshold})
+			}
+		}
+		if len(current) > 0 {
+			p.removeTypologies = append(p.removeTypologies, t.ID)
+		}
+		p.Typologies = append(p.Typologies, change)
+	}
+	if !merge {
+		for id, current := range stored {
+			p.removeTypologies = append(p.removeTypologies, id)
+			p.Typologies = append(p.Typologies, Change{ID: id, Action: ActionDelete, PreviousVersion: current[0].Version})
+		}
+	}
+	sort.Slice(p.Typologies, func(i, j int) bool { return p.Typologies[i].ID < p.Typologies[j].ID })
+}
+
+// Apply saves a plan and reloads the engines. Replaced rule versions are
+// disabled before the new version is saved.
+func (m *Manager) Apply(ctx context.Context, plan *Plan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, old := range plan.disableRules {
+		disabled := *old
+		disabled.Enabled = false
+		if err := m.repo.SaveRuleConfig(ctx, plan.tenantID, &disabled); err != nil {
+			return fmt.Errorf("failed to disable rule %s: %w", old.ID, err)
+		}
+	}
+	for _, c := range plan.Rules {
+		if rule, ok := plan.saveRules[c.ID]; ok {
+			if err := m.repo.SaveRuleConfig(ctx, plan.tenantID, rule); err != nil {
+				return fmt.Errorf("failed to save rule %s: %w", rule.ID, err)
+			}
+		}
+	}
+
+	for _, id := range plan.removeTypologies {
+		if err := m.repo.DeleteTypology(ctx, plan.tenantID, id); err != nil {
+			return fmt.Errorf("failed to remove typology %s: %w", id, err)
+		}
+	}
+	for _, c := range plan.Typologies {
+		if t, ok := plan.saveTypologies[c.ID]; ok {
+			if err := m.repo.SaveTypology(ctx, plan.tenantID, t); err != nil {
+				return fmt.Errorf("failed to save typology %s: %w", t.ID, err)
+			}
+		}
+	}
+
+	if err := m.reload(ctx); err != nil {
+		return err
+	}
+	if m.applied != nil {
+		m.applied(ctx, plan.tenantID)
+	}
+	return nil
+}
+
+// Reload loads the saved configuration into the engines, e.g. after another
+// replica changed it.
+func (m *Manager) Reload(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reload(ctx)
+}
+
+// reload loads the saved configuration into the engines.
+func (m *Manager) reload(ctx context.Context) error {
+	dbRules, err := m.repo.ListAllRuleConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list rules: %w", err)
+	}
+	if _, err := m.engine.ReloadRules(dbRules); err != nil {
+		return fmt.Errorf("failed to reload rules: %w", err)
+	}
+
+	lists, err := m.repo.ListAllNamedLists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list named lists: %w", err)
+	}
+	m.engine.ReloadLists(lists)
+
+	if m.typologies != nil {
+		dbTypologies, err := m.repo.ListAllTypologies(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list typologies: %w", err)
+		}
+		m.typologies.ReloadTypologies(dbTypologies)
+	}
+	return nil
+}
+
+// validate applies the same checks as the rule and typology APIs across the
+// whole spec, so an invalid spec is rejected as a whole. Typologies may refer
+// to the spec's enabled rules and to known, and to the spec's typologies and
+// knownTypologies, which the spec's replace.
+func (m *Manager) validate(ruleConfigs []*domain.RuleConfig, typologies []*domain.Typology, known map[string]bool, knownTypologies []*domain.Typology) error {
+	var errs []error
+
+	enabledRules := make(map[string]bool)
+	for id := range known {
+		enabledRules[id] = true
+	}
+	seen := make(map[string]bool)
+	for _, rule := range ruleConfigs {
+		switch {
+		case rule.ID == "" || rule.Name == "" || rule.Expression == "":
+			errs = append(errs, fmt.Errorf("rule %q: id, name, and expression are required", rule.ID))
+			continue
+		case seen[rule.ID]:
+			errs = append(errs, fmt.Errorf("rule %s: defined more than once", rule.ID))
+			continue
+		case strings.Contains(rule.Version, "+"):
+			errs = append(errs, fmt.Errorf("rule %s: version must not contain build metadata", rule.ID))
+			continue
+		case rule.SampleRate < 0 || rule.SampleRate > 1:
+			errs = append(errs, fmt.Errorf("rule %s: sampleRate must be between 0 and 1", rule.ID))
+			continue
+		}
+		seen[rule.ID] = true
+		if err := m.engine.ValidateRule(rule); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rule.Enabled {
+			enabledRules[rule.ID] = true
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, t := range typologies {
+		switch {
+		case t.ID == "" || t.Name == "":
+			errs = append(errs, fmt.Errorf("typology %q: id and name are required", t.ID))
+			continue
+		case seen[t.ID]:
+			errs = append(errs, fmt.Errorf("typology %s: defined more than once", t.ID))
+			continue
+		case strings.Contains(t.Version, "+"):
+			errs = append(errs, fmt.Errorf("typology %s: version must not contain build metadata", t.ID))
+			continue
+		case len(t.Rules) == 0 && len(t.Typologies) == 0:
+			errs = append(errs, fmt.Errorf("typology %s: at least one rule or typology is required", t.ID))
+			continue
+		}
+		seen[t.ID] = true
+		for _, rw := range t.Rules {
+			if !enabledRules[rw.RuleID] {
+				errs = append(errs, fmt.Errorf("typology %s: rule %q is not an enabled rule", t.ID, rw.RuleID))
+			}
+			if rw.Weight < 0 || rw.Weight > 1 {
+				errs = append(errs, fmt.Errorf("typology %s: rule weight must be between 0 and 1", t.ID))
+			}
+		}
+		for _, tw := range t.Typologies {
+			if tw.Weight < 0 || tw.Weight > 1 {
+				errs = append(errs, fmt.Errorf("typology %s: typology weight must be between 0 and 1", t.ID))
+			}
+		}
+		if t.Condition != nil {
+			if err := rules.ValidateCondition(t.Condition); err != nil {
+				errs = append(errs, fmt.Errorf("typology %s: %w", t.ID, err))
+			}
+			ruleIDs, _ := rules.ConditionRefs(t.Condition)
+			for _, ruleID := range ruleIDs {
+				if !enabledRules[ruleID] {
+					errs = append(errs, fmt.Errorf("typology %s: condition rule %q is not an enabled rule", t.ID, ruleID))
+				}
+			}
+		}
+		if t.AlertThreshold <= 0 || t.AlertThreshold > 1 {
+			errs = append(errs, fmt.Errorf("typology %s: alertThreshold must be between 0 (exclusive) and 1", t.ID))
+		}
+		if t.MinRulesFired < 0 || t.MinRulesFired > len(t.Rules) {
+			errs = append(errs, fmt.Errorf("typology %s: minRulesFired must be between 0 and the number of rules", t.ID))
+		}
+		if t.MinCoverage < 0 || t.MinCoverage > 1 {
+			errs = append(errs, fmt.Errorf("typology %s: minCoverage must be between 0 and 1", t.ID))
+		}
+	}
+
+	graph := make([]*domain.Typology, 0, len(knownTypologies)+len(typologies))
+	for _, t := range knownTypologies {
+		if !seen[t.ID] {
+			graph = append(graph, t)
+		}
+	}
+	for _, t := range typologies {
+		if seen[t.ID] && t.Enabled {
+			graph = append(graph, t)
+		}
+	}
+	if err := rules.ValidateTypologyGraph(graph); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// baseVersion strips build metadata from a version.
+func baseVersion(v string) string {
+	base, _, _ := strings.Cut(v, "+")
+	return base
+}
+
+// sameRule reports whether a stored rule matches a desired one, ignoring
+// build metadata on the stored version.
+func sameRule(stored, rule *domain.RuleConfig) bool {
+	prev := *stored
+	prev.Version = baseVersion(prev.Version)
+	return prev.SourceFile == rule.SourceFile &&
+		rules.DiffRules([]*domain.RuleConfig{&prev}, []*domain.RuleConfig{rule}).Empty()
+}
+
+// sameTypology reports whether a stored typology matches a desired one,
+// ignoring build metadata and audit timestamps.
+func sameTypology(stored, t *domain.Typology) bool {
+	a, b := *stored, *t
+	a.Version = baseVersion(a.Version)
+	a.TenantID, b.TenantID = "", ""
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
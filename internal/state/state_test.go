@@ -0,0 +1,248 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/guardrails"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	engine, err := rules.NewEngine(nil, 1)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	typologies := rules.NewTypologyEngine()
+	mgr := NewManager(repo, engine, typologies)
+
+	spec := &Spec{
+		Rules: []RuleSpec{
+			{ID: "high-value", Name: "High value", Expression: "amount > 10000.0", Weight: 1},
+			{ID: "round-amount", Name: "Round amount", Expression: "int(amount) % 1000 == 0", Weight: 1},
+		},
+		Typologies: []TypologySpec{{
+			ID:             "structuring",
+			Name:           "Structuring",
+			AlertThreshold: 0.5,
+			Rules: []domain.TypologyRuleWeight{
+				{RuleID: "high-value", Weight: 0.5},
+				{RuleID: "round-amount", Weight: 0.5},
+			},
+		}},
+	}
+
+	apply := func(spec *Spec) *Plan {
+		t.Helper()
+		plan, err := mgr.Plan(ctx, spec)
+		if err != nil {
+			t.Fatalf("Plan failed: %v", err)
+		}
+		if err := mgr.Apply(ctx, plan); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		return plan
+	}
+
+	t.Run("creates", func(t *testing.T) {
+		plan := apply(spec)
+		want := []Change{
+			{ID: "high-value", Action: ActionCreate, Version: DefaultVersion},
+			{ID: "round-amount", Action: ActionCreate, Version: DefaultVersion},
+		}
+		if !reflect.DeepEqual(plan.Rules, want) {
+			t.Errorf("unexpected rule changes: %+v", plan.Rules)
+		}
+		if engine.RulesCount() != 2 || typologies.TypologyCount() != 1 {
+			t.Errorf("expected 2 rules and 1 typology loaded, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+		}
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		plan, err := mgr.Plan(ctx, spec)
+		if err != nil {
+			t.Fatalf("Plan failed: %v", err)
+		}
+		if !plan.Empty() || plan.Unchanged != 3 {
+			t.Errorf("expected empty plan with 3 unchanged, got %+v", plan)
+		}
+	})
+
+	t.Run("records threshold changes", func(t *testing.T) {
+		moved := *spec
+		moved.Typologies = []TypologySpec{spec.Typologies[0]}
+		moved.Typologies[0].AlertThreshold = 0.8
+		plan, err := mgr.Plan(ctx, &moved)
+		if err != nil {
+			t.Fatalf("Plan failed: %v", err)
+		}
+		want := []guardrails.ThresholdChange{{TypologyID: "structuring", From: 0.5, To: 0.8}}
+		if m := plan.Mutation(); !reflect.DeepEqual(m.Thresholds, want) || len(m.DisabledRules) != 0 {
+			t.Errorf("unexpected mutation: %+v", m)
+		}
+	})
+
+	t.Run("updates with build metadata", func(t *testing.T) {
+		spec.Rules[0].Expression = "amount > 5000.0"
+		spec.Rules[0].Version = "1.1.0"
+		plan, err := mgr.Plan(ctx, spec)
+		if err != nil {
+			t.Fatalf("Plan failed: %v", err)
+		}
+		plan.SetBuildMetadata("abc123")
+		want := []Change{{ID: "high-value", Action: ActionUpdate, Version: "1.1.0+abc123", PreviousVersion: DefaultVersion}}
+		if !reflect.DeepEqual(plan.Rules, want) {
+			t.Errorf("unexpected rule changes: %+v", plan.Rules)
+		}
+		if err := mgr.Apply(ctx, plan); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+
+		stored, _ := repo.ListRuleConfigs(ctx, GlobalTenantID)
+		if len(stored) != 2 {
+			t.Errorf("expected the replaced version to be disabled, got %d enabled rules", len(stored))
+		}
+		// Build metadata is ignored when comparing
+		if plan, _ := mgr.Plan(ctx, spec); !plan.Empty() {
+			t.Errorf("expected empty plan, got %+v", plan)
+		}
+	})
+
+	t.Run("deletes disabled and missing entries", func(t *testing.T) {
+		plan := apply(&Spec{
+			Rules: []RuleSpec{
+				{ID: "high-value", Name: "High value", Version: "1.1.0", Expression: "amount > 5000.0", Weight: 1},
+				{ID: "round-amount", Name: "Round amount", Expression: "int(amount) % 1000 == 0", Weight: 1, Enabled: boolPtr(false)},
+			},
+		})
+		if got := IDs(plan.Rules, ActionDelete); !reflect.DeepEqual(got, []string{"round-amount"}) {
+			t.Errorf("expected round-amount deleted, got %v", got)
+		}
+		if m := plan.Mutation(); m.EnabledRules != 2 || len(m.DisabledRules) != 1 {
+			t.Errorf("expected 1 of 2 enabled rules disabled, got %+v", m)
+		}
+		if got := IDs(plan.Typologies, ActionDelete); !reflect.DeepEqual(got, []string{"structuring"}) {
+			t.Errorf("expected structuring deleted, got %v", got)
+		}
+		if engine.RulesCount() != 1 || typologies.TypologyCount() != 0 {
+			t.Errorf("expected 1 rule and no typologies loaded, got %d and %d", engine.RulesCount(), typologies.TypologyCount())
+		}
+
+		current, err := mgr.Current(ctx)
+		if err != nil {
+			t.Fatalf("Current failed: %v", err)
+		}
+		if len(current.Rules) != 1 || current.Rules[0].Version != "1.1.0+abc123" {
+			t.Errorf("unexpected current state: %+v", current)
+		}
+	})
+
+	t.Run("merges into a tenant", func(t *testing.T) {
+		merge := func(spec *Spec) *Plan {
+			t.Helper()
+			plan, err := mgr.PlanMerge(ctx, "acme", spec)
+			if err != nil {
+				t.Fatalf("PlanMerge failed: %v", err)
+			}
+			if err := mgr.Apply(ctx, plan); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			return plan
+		}
+
+		// Typologies may refer to stored global rules
+		merge(&Spec{
+			Rules: []RuleSpec{{ID: "cash-out", Name: "Cash out", Expression: "amount > 100.0", Weight: 1}},
+			Typologies: []TypologySpec{{
+				ID:             "drain",
+				Name:           "Drain",
+				AlertThreshold: 0.5,
+				Rules: []domain.TypologyRuleWeight{
+					{RuleID: "cash-out", Weight: 0.5},
+					{RuleID: "high-value", Weight: 0.5},
+				},
+			}},
+		})
+		plan := merge(&Spec{
+			Rules: []RuleSpec{{ID: "night", Name: "Night", Expression: "amount > 1.0", Weight: 1}},
+		})
+		if len(IDs(plan.Rules, ActionDelete)) != 0 || len(plan.Typologies) != 0 {
+			t.Errorf("expected a merge to delete nothing, got %+v", plan)
+		}
+
+		current, err := mgr.CurrentOf(ctx, "acme")
+		if err != nil {
+			t.Fatalf("CurrentOf failed: %v", err)
+		}
+		if len(current.Rules) != 2 || len(current.Typologies) != 1 {
+			t.Errorf("expected 2 rules and 1 typology for the tenant, got %+v", current)
+		}
+		if global, _ := mgr.Current(ctx); len(global.Rules) != 1 {
+			t.Errorf("expected global rules to be unchanged, got %+v", global.Rules)
+		}
+	})
+
+	t.Run("merges composite typologies", func(t *testing.T) {
+		// A composite may refer to the tenant's stored typologies
+		composite := &Spec{Typologies: []TypologySpec{{
+			ID:             "drain-at-night",
+			Name:           "Drain at night",
+			AlertThreshold: 0.5,
+			Typologies:     []domain.TypologyWeight{{TypologyID: "drain", Weight: 1}},
+			Condition: &domain.TypologyCondition{All: []domain.TypologyCondition{
+				{Typology: "drain"}, {Rule: "night"},
+			}},
+		}}}
+		for i, want := range []int{1, 0} {
+			plan, err := mgr.PlanMerge(ctx, "acme", composite)
+			if err != nil {
+				t.Fatalf("PlanMerge failed: %v", err)
+			}
+			if len(plan.Typologies) != want {
+				t.Fatalf("merge %d: expected %d typology changes, got %+v", i+1, want, plan.Typologies)
+			}
+			if err := mgr.Apply(ctx, plan); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+		}
+
+		cycle := &Spec{Typologies: []TypologySpec{{
+			ID:             "drain",
+			Name:           "Drain",
+			AlertThreshold: 0.5,
+			Typologies:     []domain.TypologyWeight{{TypologyID: "drain-at-night", Weight: 1}},
+		}}}
+		if _, err := mgr.PlanMerge(ctx, "acme", cycle); !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "cycle") {
+			t.Errorf("expected the cycle to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid specs as a whole", func(t *testing.T) {
+		invalid := &Spec{
+			Rules: []RuleSpec{
+				{ID: "ok", Name: "OK", Expression: "amount > 1.0"},
+				{ID: "bad", Name: "Bad", Expression: "amount >"},
+				{ID: "ok", Name: "Duplicate", Expression: "amount > 2.0"},
+			},
+			Typologies: []TypologySpec{
+				{ID: "t", Name: "T", AlertThreshold: 0.5, Rules: []domain.TypologyRuleWeight{{RuleID: "missing", Weight: 1}}},
+			},
+		}
+		_, err := mgr.Plan(ctx, invalid)
+		if !errors.Is(err, ErrInvalid) {
+			t.Fatalf("expected ErrInvalid, got %v", err)
+		}
+		if engine.RulesCount() != 3 {
+			t.Errorf("expected loaded rules to be unchanged, got %d", engine.RulesCount())
+		}
+	})
+}
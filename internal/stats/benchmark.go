@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Limits of a benchmark report.
+const (
+	DefaultBenchmarkWindow = 30 * 24 * time.Hour
+
+	// BenchmarkVolumeFactor bounds how much more or less volume a peer may
+	// have: a tenant with 10,000 evaluations is compared with tenants that
+	// had 2,500 to 40,000.
+	BenchmarkVolumeFactor = 4
+
+	// MinBenchmarkPeers is the fewest peers a metric's bands are computed
+	// from, so no single peer's value can be read off them. A larger
+	// minimum cohort raises it.
+	MinBenchmarkPeers = 5
+)
+
+// BenchmarkPercentiles are the bands of every benchmark metric.
+var BenchmarkPercentiles = []int{25, 50, 75, 90}
+
+// Benchmark metrics.
+const (
+	MetricAlertRate = "alertRate" // alerts per evaluation
+	MetricPrecision = "precision" // confirmed alerts per closed alert
+	MetricLatencyMs = "latencyMs" // mean processing time of an evaluation
+)
+
+// BenchmarkMetric compares one of the tenant's metrics with its peers'.
+type BenchmarkMetric struct {
+	Name  string   `json:"name"`
+	Value *float64 `json:"value"` // The tenant's; nil when it has none, e.g. no closed alerts
+	Peers int      `json:"peers"` // Peers with a value
+
+	// Bands are the peers' percentiles, e.g. "p75"; Rank is the share of
+	// peers below the tenant, 0 to 100. Both are omitted with fewer peers
+	// than Privacy.Peers allows, and Rank without a value.
+	Bands map[string]float64 `json:"bands,omitempty"`
+	Rank  *float64           `json:"rank,omitempty"`
+}
+
+// BenchmarkReport compares a tenant with opted-in tenants of similar
+// volume. It names no other tenant.
+type BenchmarkReport struct {
+	Since       time.Time         `json:"since"`
+	Until       time.Time         `json:"until"` // exclusive
+	Evaluations int64             `json:"evaluations"`
+	Peers       int               `json:"peers"` // Tenants of similar volume compared with
+	Metrics     []BenchmarkMetric `json:"metrics"`
+}
+
+// Benchmark compares the tenant's alert rate, precision and latency from
+// since until until with those of the tenants for which optedIn reports
+// true and whose evaluation count is within BenchmarkVolumeFactor of the
+// tenant's. Precision is read from alert dispositions, so only peers that
+// closed alerts count towards it. Bands and ranks are protected by privacy,
+// which may be nil.
+func (s *Service) Benchmark(ctx context.Context, tenantID string, since, until time.Time, optedIn func(tenantID string) bool, privacy *Privacy) (*BenchmarkReport, error) {
+	all, err := s.repo.ListTenantBenchmarks(ctx, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant benchmarks: %w", err)
+	}
+
+	var own benchmarkValues
+	var peers []benchmarkValues
+	for _, b := range all {
+		if b.TenantID == tenantID {
+			own = valuesOf(b)
+		}
+	}
+	for _, b := range all {
+		if b.TenantID == tenantID || !similarVolume(own.evaluations, b.Evaluations) || !optedIn(b.TenantID) {
+			continue
+		}
+		peers = append(peers, valuesOf(b))
+	}
+
+	report := &BenchmarkReport{
+		Since:       since.UTC(),
+		Until:       until.UTC(),
+		Evaluations: own.evaluations,
+		Peers:       len(peers),
+	}
+	// Noise is fixed per tenant, window and day, like that of other
+	// shared values
+	key := fmt.Sprintf("benchmark/%s/%s/%s", tenantID, until.Sub(since), until.UTC().Format(time.DateOnly))
+	for _, metric := range []struct {
+		name  string
+		value func(benchmarkValues) *float64
+	}{
+		{MetricAlertRate, func(v benchmarkValues) *float64 { return v.alertRate }},
+		{MetricPrecision, func(v benchmarkValues) *float64 { return v.precision }},
+		{MetricLatencyMs, func(v benchmarkValues) *float64 { return v.latencyMs }},
+	} {
+		var values []float64
+		for _, peer := range peers {
+			if v := metric.value(peer); v != nil {
+				values = append(values, *v)
+			}
+		}
+		report.Metrics = append(report.Metrics, compare(metric.name, metric.value(own), values, privacy, key+"/"+metric.name))
+	}
+	return report, nil
+}
+
+// benchmarkValues are one tenant's metrics; nil when undefined.
+type benchmarkValues struct {
+	evaluations int64
+	alertRate   *float64
+	precision   *float64
+	latencyMs   *float64
+}
+
+func valuesOf(b *domain.TenantBenchmark) benchmarkValues {
+	latency := b.MeanTotalMs
+	return benchmarkValues{
+		evaluations: b.Evaluations,
+		alertRate:   ratio(b.Alerts, b.Evaluations),
+		precision:   ratio(b.ClosedConfirmed, b.ClosedConfirmed+b.ClosedFalsePositive),
+		latencyMs:   &latency,
+	}
+}
+
+func ratio(n, of int64) *float64 {
+	if of == 0 {
+		return nil
+	}
+	r := float64(n) / float64(of)
+	return &r
+}
+
+// similarVolume reports whether a tenant with peer evaluations is within
+// BenchmarkVolumeFactor of one with own.
+func similarVolume(own, peer int64) bool {
+	return own > 0 && peer*BenchmarkVolumeFactor >= own && peer <= own*BenchmarkVolumeFactor
+}
+
+// compare places value among the peers' values, protecting the bands and
+// rank published under key.
+func compare(name string, value *float64, peers []float64, privacy *Privacy, key string) BenchmarkMetric {
+	metric := BenchmarkMetric{Name: name, Value: value, Peers: len(peers)}
+	if !privacy.Peers(len(peers)) {
+		return metric
+	}
+
+	sort.Float64s(peers)
+	lowest, highest := peers[0], peers[len(peers)-1]
+	// The noise of a band is scaled to the mean gap between two peers
+	gap := (highest - lowest) / float64(len(peers))
+	bands := make([]float64, len(BenchmarkPercentiles))
+	for i, p := range BenchmarkPercentiles {
+		// Nearest rank, so without noise every band is one of the peers' values
+		r := int(math.Ceil(float64(p)/100*float64(len(peers)))) - 1
+		bands[i] = privacy.Value(fmt.Sprintf("%s/p%d", key, p), peers[max(r, 0)], gap)
+	}
+	// Noise may reorder the bands; they stay ascending and within the peers' range
+	sort.Float64s(bands)
+	metric.Bands = make(map[string]float64, len(BenchmarkPercentiles))
+	for i, p := range BenchmarkPercentiles {
+		metric.Bands[fmt.Sprintf("p%d", p)] = min(max(bands[i], lowest), highest)
+	}
+	if value != nil {
+		below := sort.SearchFloat64s(peers, *value)
+		rank := privacy.Value(key+"/rank", 100*float64(below)/float64(len(peers)), 100/float64(len(peers)))
+		rank = min(max(rank, 0), 100)
+		metric.Rank = &rank
+	}
+	return metric
+}
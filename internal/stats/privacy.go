@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	mrand "math/rand/v2"
+	"sort"
+	"time"
+)
+
+// Privacy protects counts shared across tenants. A count above 0 but below
+// the minimum cohort size is suppressed, so no small group of customers can be singled
+// out, and the others get Laplace noise of scale 1/epsilon, so no single
+// transaction can be inferred from them. A nil Privacy publishes every count
+// as is.
+//
+// The noise of a count is drawn once per key, typically a tenant, metric
+// and window, so repeating a query returns the same noisy value instead of
+// fresh samples that would average out.
+type Privacy struct {
+	epsilon   float64
+	minCohort int64
+	salt      uint64
+}
+
+// NewPrivacy returns the protection of shared counts, or nil when epsilon
+// and minCohort are both 0.
+func NewPrivacy(epsilon float64, minCohort int) *Privacy {
+	if epsilon <= 0 && minCohort <= 0 {
+		return nil
+	}
+	var salt [8]byte
+	rand.Read(salt[:])
+	return &Privacy{
+		epsilon:   epsilon,
+		minCohort: int64(minCohort),
+		salt:      binary.LittleEndian.Uint64(salt[:]),
+	}
+}
+
+// Count returns the count to publish under key, and false when it must be
+// suppressed.
+func (p *Privacy) Count(key string, n int64) (int64, bool) {
+	if p == nil {
+		return n, true
+	}
+	if n > 0 && n < p.minCohort {
+		return 0, false
+	}
+	if p.epsilon > 0 {
+		n = max(0, int64(math.Round(float64(n)+p.noise(key))))
+	}
+	return n, true
+}
+
+// Peers reports whether a value computed from n tenants, such as a
+// benchmark band, may be published: n must reach both MinBenchmarkPeers and
+// the minimum cohort.
+func (p *Privacy) Peers(n int) bool {
+	if p == nil {
+		return n >= MinBenchmarkPeers
+	}
+	return int64(n) >= max(MinBenchmarkPeers, p.minCohort)
+}
+
+// Value returns v to publish under key, with Laplace noise of scale
+// sensitivity/epsilon, where sensitivity is how far one tenant can move v.
+func (p *Privacy) Value(key string, v, sensitivity float64) float64 {
+	if p == nil || p.epsilon <= 0 {
+		return v
+	}
+	return v + sensitivity*p.noise(key)
+}
+
+// noise draws the Laplace noise of key.
+func (p *Privacy) noise(key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	u := mrand.New(mrand.NewPCG(p.salt, h.Sum64())).Float64() - 0.5
+	if u == -0.5 {
+		return 0
+	}
+	return -math.Copysign(1/p.epsilon, u) * math.Log(1-2*math.Abs(u))
+}
+
+// Top protects a top report. Entity lists are left out, since every entry
+// names one customer; tenant entries are noised, those below the minimum
+// cohort dropped, and the lists sorted again.
+func (p *Privacy) Top(report *TopReport) *TopReport {
+	if p == nil {
+		return report
+	}
+	window := report.Since.UTC().Format(time.RFC3339)
+	return &TopReport{
+		Since: report.Since,
+		Tenants: TopList{
+			Evaluations: p.topEntries("top/evaluations/"+window, report.Tenants.Evaluations),
+			Alerts:      p.topEntries("top/alerts/"+window, report.Tenants.Alerts),
+		},
+		Entities: TopList{
+			Evaluations: []TopEntry{},
+			Alerts:      []TopEntry{},
+		},
+	}
+}
+
+func (p *Privacy) topEntries(key string, entries []TopEntry) []TopEntry {
+	out := make([]TopEntry, 0, len(entries))
+	for _, entry := range entries {
+		count, ok := p.Count(key+"/"+entry.TenantID, entry.Count)
+		if !ok {
+			continue
+		}
+		// The sketch error is another count of the tenant, so it is left out
+		out = append(out, TopEntry{TenantID: entry.TenantID, Count: count})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Count > out[j].Count })
+	return out
+}
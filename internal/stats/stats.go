@@ -0,0 +1,151 @@
+// Package stats summarizes a tenant's decision scores, so alert thresholds
+// can be tuned from production data instead of guesswork.
+//
+// Scores are counted in the repository in 100 buckets 0.01 wide and merged
+// here into the histogram asked for. Percentiles are read from the fine
+// buckets, so each is the upper edge of a bucket: p90 = 0.43 means 90% of
+// scores are below 0.43, to within 0.01.
+//
+// A Tracker also keeps the tenants and entities with the most evaluations
+// and alerts in the last few minutes, to find the source of a spike.
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Limits of a score distribution.
+const (
+	DefaultWindow  = 24 * time.Hour
+	MaxWindow      = 90 * 24 * time.Hour
+	DefaultBuckets = 20
+)
+
+// Percentiles are the markers of every distribution.
+var Percentiles = []int{50, 75, 90, 95, 99}
+
+// Distribution is a histogram of scores with percentile markers.
+type Distribution struct {
+	Count       int64                `json:"count"`
+	Histogram   []domain.ScoreBucket `json:"histogram"`
+	Percentiles map[string]float64   `json:"percentiles,omitempty"` // e.g. "p90"; omitted without scores
+}
+
+// TypologyDistribution is the distribution of one typology's scores.
+type TypologyDistribution struct {
+	TypologyID string `json:"typologyId"`
+	Distribution
+}
+
+// Report is the score distribution of a tenant's evaluations over a window.
+type Report struct {
+	Since       time.Time              `json:"since"`
+	Until       time.Time              `json:"until"` // exclusive
+	Buckets     int                    `json:"buckets"`
+	Evaluations Distribution           `json:"evaluations"`
+	Typologies  []TypologyDistribution `json:"typologies"`
+}
+
+// Service reports score distributions.
+type Service struct {
+	repo domain.Repository
+}
+
+// NewService creates a new stats service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ValidBuckets reports whether a histogram can have n buckets: n must
+// divide the 100 buckets scores are counted in.
+func ValidBuckets(n int) bool {
+	return n > 0 && n <= domain.ScoreHistogramBuckets && domain.ScoreHistogramBuckets%n == 0
+}
+
+// ParseWindow parses a window such as "1h", "24h" or "7d".
+func ParseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// ScoreDistribution reports the distribution of the final scores of the
+// tenant's evaluations dated from since until until, and of each
+// typology's scores, in the given number of buckets.
+func (s *Service) ScoreDistribution(ctx context.Context, tenantID string, since, until time.Time, buckets int) (*Report, error) {
+	if !ValidBuckets(buckets) {
+		return nil, fmt.Errorf("buckets must divide %d", domain.ScoreHistogramBuckets)
+	}
+
+	counts, err := s.repo.EvaluationScoreHistogram(ctx, tenantID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count evaluation scores: %w", err)
+	}
+	typologies, err := s.repo.TypologyScoreHistograms(ctx, tenantID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count typology scores: %w", err)
+	}
+
+	report := &Report{
+		Since:       since.UTC(),
+		Until:       until.UTC(),
+		Buckets:     buckets,
+		Evaluations: Summarize(counts, buckets),
+		Typologies:  make([]TypologyDistribution, 0, len(typologies)),
+	}
+	for typologyID, counts := range typologies {
+		report.Typologies = append(report.Typologies, TypologyDistribution{
+			TypologyID:   typologyID,
+			Distribution: Summarize(counts, buckets),
+		})
+	}
+	sort.Slice(report.Typologies, func(i, j int) bool {
+		return report.Typologies[i].TypologyID < report.Typologies[j].TypologyID
+	})
+	return report, nil
+}
+
+// Summarize merges counts in domain.ScoreHistogramBuckets buckets into a
+// histogram of the given number of buckets and reads the percentiles.
+func Summarize(counts []int64, buckets int) Distribution {
+	width := domain.ScoreHistogramBuckets / buckets
+	dist := Distribution{Histogram: make([]domain.ScoreBucket, buckets)}
+	for i := range dist.Histogram {
+		dist.Histogram[i].Lower = float64(i*width) / domain.ScoreHistogramBuckets
+		dist.Histogram[i].Upper = float64((i+1)*width) / domain.ScoreHistogramBuckets
+	}
+	for i, n := range counts {
+		dist.Histogram[min(i/width, buckets-1)].Count += int(n)
+		dist.Count += n
+	}
+	if dist.Count == 0 {
+		return dist
+	}
+
+	dist.Percentiles = make(map[string]float64, len(Percentiles))
+	for _, p := range Percentiles {
+		// The smallest score at least p% of scores are at or below
+		rank := (dist.Count*int64(p) + 99) / 100
+		var cumulative int64
+		for i, n := range counts {
+			cumulative += n
+			if cumulative >= rank {
+				dist.Percentiles["p"+strconv.Itoa(p)] = float64(i+1) / domain.ScoreHistogramBuckets
+				break
+			}
+		}
+	}
+	return dist
+}
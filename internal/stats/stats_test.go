@@ -0,0 +1,325 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestSummarize(t *testing.T) {
+	counts := make([]int64, domain.ScoreHistogramBuckets)
+	// 90 scores of 0.1, 9 of 0.55 and 1 of 1.0
+	counts[domain.ScoreHistogramBucket(0.1)] = 90
+	counts[domain.ScoreHistogramBucket(0.55)] = 9
+	counts[domain.ScoreHistogramBucket(1.0)] = 1
+
+	dist := Summarize(counts, 10)
+	if dist.Count != 100 || len(dist.Histogram) != 10 {
+		t.Fatalf("expected 100 scores in 10 buckets, got %+v", dist)
+	}
+	if b := dist.Histogram[1]; b.Lower != 0.1 || b.Upper != 0.2 || b.Count != 90 {
+		t.Errorf("expected 90 scores in [0.1, 0.2), got %+v", b)
+	}
+	if dist.Histogram[5].Count != 9 || dist.Histogram[9].Count != 1 {
+		t.Errorf("expected 0.55 and 1.0 in the 6th and last buckets, got %+v", dist.Histogram)
+	}
+
+	want := map[string]float64{"p50": 0.11, "p75": 0.11, "p90": 0.11, "p95": 0.56, "p99": 0.56}
+	for key, value := range want {
+		if dist.Percentiles[key] != value {
+			t.Errorf("expected %s = %.2f, got %.2f", key, value, dist.Percentiles[key])
+		}
+	}
+
+	if empty := Summarize(make([]int64, domain.ScoreHistogramBuckets), 20); empty.Count != 0 || empty.Percentiles != nil || len(empty.Histogram) != 20 {
+		t.Errorf("expected empty buckets and no percentiles, got %+v", empty)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	for s, want := range map[string]time.Duration{"1h": time.Hour, "24h": 24 * time.Hour, "7d": 7 * 24 * time.Hour} {
+		if got, err := ParseWindow(s); err != nil || got != want {
+			t.Errorf("ParseWindow(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseWindow("xd"); err == nil {
+		t.Error("expected an invalid day count to fail")
+	}
+}
+
+func TestScoreDistribution(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo)
+
+	save := func(id string, score float64, at time.Time, typologies ...domain.TypologyResult) {
+		t.Helper()
+		eval := &domain.Evaluation{ID: id, TxID: "tx-" + id, Score: score, Status: domain.StatusNoAlert, Timestamp: at, TypologyResults: typologies}
+		if err := repo.SaveEvaluation(ctx, "tenant-001", eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	now := ospreytest.Epoch
+	save("eval-1", 0.29, now.Add(-time.Hour), domain.TypologyResult{TypologyID: "structuring", Score: 0.8})
+	save("eval-2", 0.71, now.Add(-time.Hour), domain.TypologyResult{TypologyID: "structuring", Score: 0.2}, domain.TypologyResult{TypologyID: "mule", Score: 0.5})
+	save("eval-3", 0.9, now.Add(-48*time.Hour))
+
+	report, err := svc.ScoreDistribution(ctx, "tenant-001", now.Add(-24*time.Hour), now, 10)
+	if err != nil {
+		t.Fatalf("ScoreDistribution failed: %v", err)
+	}
+	if report.Evaluations.Count != 2 || report.Evaluations.Histogram[2].Count != 1 || report.Evaluations.Histogram[7].Count != 1 {
+		t.Errorf("expected the two evaluations in the window, got %+v", report.Evaluations)
+	}
+	if report.Evaluations.Percentiles["p50"] != 0.3 {
+		t.Errorf("expected p50 = 0.30, got %.2f", report.Evaluations.Percentiles["p50"])
+	}
+	if len(report.Typologies) != 2 || report.Typologies[0].TypologyID != "mule" || report.Typologies[1].Count != 2 {
+		t.Errorf("expected both typologies by ID, got %+v", report.Typologies)
+	}
+
+	if _, err := svc.ScoreDistribution(ctx, "tenant-001", now.Add(-time.Hour), now, 30); err == nil {
+		t.Error("expected buckets not dividing 100 to fail")
+	}
+	repo.SetError(errors.New("database down"))
+	if _, err := svc.ScoreDistribution(ctx, "tenant-001", now.Add(-time.Hour), now, 10); err == nil {
+		t.Error("expected a repository failure to fail the report")
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo)
+	now := ospreytest.Epoch
+
+	// save gives a tenant n evaluations, alerts of them alerting, each taking ms
+	save := func(tenantID string, n, alerts int, ms int64) {
+		t.Helper()
+		for i := range n {
+			status := domain.StatusNoAlert
+			if i < alerts {
+				status = domain.StatusAlert
+			}
+			id := tenantID + "-" + strconv.Itoa(i)
+			eval := &domain.Evaluation{ID: id, TxID: "tx-" + id, Status: status, Timestamp: now.Add(-time.Hour),
+				Metadata: domain.EvaluationMetadata{TotalMs: ms}}
+			if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+				t.Fatalf("SaveEvaluation failed: %v", err)
+			}
+		}
+	}
+	save("tenant-own", 20, 4, 10)
+	for i := range 5 {
+		save("tenant-peer-"+strconv.Itoa(i), 10+i, i, int64(5*(i+1)))
+	}
+	save("tenant-large", 200, 0, 1)
+	save("tenant-private", 20, 20, 1)
+	confirmed := &domain.Alert{ID: "tenant-own-0", TxID: "tx-tenant-own-0", Status: domain.AlertClosedConfirmed, CreatedAt: now.Add(-time.Hour)}
+	if err := repo.SaveAlert(ctx, "tenant-own", confirmed); err != nil {
+		t.Fatalf("SaveAlert failed: %v", err)
+	}
+
+	optedIn := func(tenantID string) bool { return tenantID != "tenant-private" }
+	report, err := svc.Benchmark(ctx, "tenant-own", now.Add(-24*time.Hour), now, optedIn, nil)
+	if err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+	if report.Evaluations != 20 || report.Peers != 5 {
+		t.Fatalf("expected 20 evaluations against the 5 similar opted-in peers, got %+v", report)
+	}
+
+	metrics := make(map[string]BenchmarkMetric)
+	for _, m := range report.Metrics {
+		metrics[m.Name] = m
+	}
+	alertRate := metrics[MetricAlertRate]
+	if alertRate.Value == nil || *alertRate.Value != 0.2 || alertRate.Bands["p90"] != 4.0/14 || *alertRate.Rank != 60 {
+		t.Errorf("expected an alert rate of 0.2 above 3 of 5 peers, got %+v", alertRate)
+	}
+	latency := metrics[MetricLatencyMs]
+	if latency.Bands["p25"] != 10 || latency.Bands["p50"] != 15 || *latency.Rank != 20 {
+		t.Errorf("expected latency bands from 5 to 25 ms, got %+v", latency)
+	}
+	precision := metrics[MetricPrecision]
+	if precision.Value == nil || *precision.Value != 1 || precision.Peers != 0 || precision.Bands != nil || precision.Rank != nil {
+		t.Errorf("expected a precision of 1 without bands, since no peer closed alerts, got %+v", precision)
+	}
+
+	report, err = svc.Benchmark(ctx, "tenant-own", now.Add(-24*time.Hour), now, func(string) bool { return false }, nil)
+	if err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+	if report.Peers != 0 || report.Metrics[0].Bands != nil {
+		t.Errorf("expected no bands without opted-in peers, got %+v", report)
+	}
+	t.Run("Privacy", func(t *testing.T) {
+		report, _ := svc.Benchmark(ctx, "tenant-own", now.Add(-24*time.Hour), now, optedIn, NewPrivacy(0, 6))
+		if report.Peers != 5 || report.Metrics[0].Bands != nil || report.Metrics[0].Rank != nil {
+			t.Errorf("expected bands withheld below the minimum cohort, got %+v", report.Metrics[0])
+		}
+
+		privacy := NewPrivacy(0.5, 0)
+		report, _ = svc.Benchmark(ctx, "tenant-own", now.Add(-24*time.Hour), now, optedIn, privacy)
+		again, _ := svc.Benchmark(ctx, "tenant-own", now.Add(-24*time.Hour), now, optedIn, privacy)
+		latency := report.Metrics[2]
+		if latency.Bands["p25"] > latency.Bands["p50"] || latency.Bands["p25"] < 5 || latency.Bands["p90"] > 25 {
+			t.Errorf("expected noisy bands ascending within the peers' range, got %+v", latency.Bands)
+		}
+		if *latency.Rank < 0 || *latency.Rank > 100 {
+			t.Errorf("expected a rank from 0 to 100, got %v", *latency.Rank)
+		}
+		if again.Metrics[2].Bands["p50"] != latency.Bands["p50"] || *again.Metrics[2].Rank != *latency.Rank {
+			t.Errorf("expected the same noise on a repeated query, got %+v and %+v", latency, again.Metrics[2])
+		}
+	})
+}
+
+func TestTracker(t *testing.T) {
+	clock := ospreytest.NewClock(ospreytest.Epoch)
+	tracker := NewTracker(5 * time.Minute)
+	tracker.now = clock.Now
+	tracker.started = clock.Now()
+
+	for i := 0; i < 5; i++ {
+		tracker.Evaluated("tenant-001", "noisy")
+	}
+	tracker.Evaluated("tenant-001", "quiet")
+	tracker.Evaluated("tenant-002", "")
+	tracker.Alerted("tenant-001", "noisy")
+
+	report := tracker.Top(2)
+	if got := report.Tenants.Evaluations; len(got) != 2 || got[0].TenantID != "tenant-001" || got[0].Count != 6 || got[1].Count != 1 {
+		t.Errorf("unexpected top tenants: %+v", got)
+	}
+	if got := report.Entities.Evaluations; len(got) != 2 || got[0].EntityID != "noisy" || got[0].Count != 5 || got[1].EntityID != "quiet" {
+		t.Errorf("unexpected top entities: %+v", got)
+	}
+	if got := report.Entities.Alerts; len(got) != 1 || got[0].EntityID != "noisy" || got[0].Count != 1 {
+		t.Errorf("unexpected top alerted entities: %+v", got)
+	}
+
+	// The previous window still counts; two windows later nothing does
+	clock.Advance(6 * time.Minute)
+	tracker.Evaluated("tenant-001", "noisy")
+	report = tracker.Top(DefaultTopN)
+	if got := report.Entities.Evaluations[0]; got.Count != 6 || !report.Since.Equal(ospreytest.Epoch) {
+		t.Errorf("expected both windows since the first, got %+v since %v", got, report.Since)
+	}
+	clock.Advance(10 * time.Minute)
+	if report = tracker.Top(DefaultTopN); len(report.Tenants.Evaluations) != 0 {
+		t.Errorf("expected old windows to be forgotten, got %+v", report.Tenants.Evaluations)
+	}
+
+	t.Run("Overflow", func(t *testing.T) {
+		tracker := NewTracker(time.Hour)
+		for i := 0; i < 3; i++ {
+			tracker.Evaluated("tenant-001", "heavy")
+		}
+		for i := 0; i < TopCapacity+10; i++ {
+			tracker.Evaluated("tenant-001", strconv.Itoa(i))
+		}
+		top := tracker.Top(1).Entities.Evaluations
+		if len(top) != 1 || top[0].EntityID != "heavy" || top[0].Count != 3 || top[0].Error != 0 {
+			t.Errorf("expected the heavy hitter to survive a full sketch, got %+v", top)
+		}
+		if n := len(tracker.Top(TopCapacity * 2).Entities.Evaluations); n != TopCapacity {
+			t.Errorf("expected at most %d entities, got %d", TopCapacity, n)
+		}
+	})
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	tracker := NewTracker(time.Hour)
+	wrapped := Wrap(repo, tracker)
+
+	tx := ospreytest.NewTransaction().ID("tx-1").Tenant("tenant-001").From("debtor-001").Build()
+	if err := wrapped.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+		t.Fatalf("SaveTransaction failed: %v", err)
+	}
+	if err := wrapped.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-1", TxID: "tx-1", Status: domain.StatusAlert}); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+	if err := wrapped.SaveEvaluation(ctx, "tenant-001", &domain.Evaluation{ID: "eval-2", TxID: "tx-1", Status: domain.StatusNoAlert}); err != nil {
+		t.Fatalf("SaveEvaluation failed: %v", err)
+	}
+
+	report := tracker.Top(DefaultTopN)
+	if got := report.Entities.Evaluations; len(got) != 1 || got[0].EntityID != "debtor-001" || got[0].Count != 1 {
+		t.Errorf("expected the debtor's evaluation, got %+v", got)
+	}
+	if got := report.Entities.Alerts; len(got) != 1 || got[0].EntityID != "debtor-001" || got[0].Count != 1 {
+		t.Errorf("expected one alert on the debtor, got %+v", got)
+	}
+}
+
+func TestPrivacy(t *testing.T) {
+	if p := NewPrivacy(0, 0); p != nil {
+		t.Fatal("expected no protection without epsilon or cohort")
+	}
+	var none *Privacy
+	if count, ok := none.Count("k", 3); !ok || count != 3 {
+		t.Errorf("expected an exact count without protection, got %d %v", count, ok)
+	}
+
+	t.Run("Minimum cohort", func(t *testing.T) {
+		p := NewPrivacy(0, 5)
+		if _, ok := p.Count("k", 4); ok {
+			t.Error("expected a count below the cohort to be suppressed")
+		}
+		if count, ok := p.Count("k", 0); !ok || count != 0 {
+			t.Errorf("expected 0 to be published, got %d %v", count, ok)
+		}
+		if count, ok := p.Count("k", 5); !ok || count != 5 {
+			t.Errorf("expected 5 to be published exactly, got %d %v", count, ok)
+		}
+	})
+
+	t.Run("Noise", func(t *testing.T) {
+		p := NewPrivacy(0.5, 0)
+		first, _ := p.Count("tenant-001", 1000)
+		if again, _ := p.Count("tenant-001", 1000); again != first {
+			t.Errorf("expected the same noise for a key, got %d and %d", first, again)
+		}
+
+		var total, moved float64
+		for i := range 1000 {
+			count, _ := p.Count("tenant-"+strconv.Itoa(i), 1000)
+			total += float64(count)
+			if count != 1000 {
+				moved++
+			}
+		}
+		if mean := total / 1000; math.Abs(mean-1000) > 0.5 {
+			t.Errorf("expected noise centered on the count, got a mean of %.2f", mean)
+		}
+		if moved < 500 {
+			t.Errorf("expected most counts noised, got %v of 1000", moved)
+		}
+	})
+
+	t.Run("Top", func(t *testing.T) {
+		p := NewPrivacy(0, 10)
+		report := p.Top(&TopReport{
+			Tenants: TopList{Evaluations: []TopEntry{
+				{TenantID: "big", Count: 50, Error: 2},
+				{TenantID: "small", Count: 3},
+			}},
+			Entities: TopList{Evaluations: []TopEntry{{TenantID: "big", EntityID: "debtor-001", Count: 40}}},
+		})
+		if got := report.Tenants.Evaluations; len(got) != 1 || got[0].TenantID != "big" || got[0].Error != 0 {
+			t.Errorf("unexpected tenants: %+v", got)
+		}
+		if len(report.Entities.Evaluations) != 0 {
+			t.Errorf("expected no entities, got %+v", report.Entities.Evaluations)
+		}
+	})
+}
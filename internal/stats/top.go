@@ -0,0 +1,270 @@
+package stats
+
+import (
+	"container/heap"
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Limits of the top tracker.
+const (
+	// TopCapacity is how many tenants or entities each sketch counts.
+	// Anything outside the top TopCapacity is approximate or forgotten.
+	TopCapacity = 1000
+
+	DefaultTopN = 10
+)
+
+// TopEntry is a tenant's, or a tenant's entity's, count. Count may
+// overstate the true count by up to Error, when the entry replaced another
+// in a full sketch.
+type TopEntry struct {
+	TenantID string `json:"tenantId"`
+	EntityID string `json:"entityId,omitempty"`
+	Count    int64  `json:"count"`
+	Error    int64  `json:"error,omitempty"`
+}
+
+// TopList is the top tenants or entities by evaluations and by alerts.
+type TopList struct {
+	Evaluations []TopEntry `json:"evaluations"`
+	Alerts      []TopEntry `json:"alerts"`
+}
+
+// TopReport is the heaviest tenants and entities since Since.
+type TopReport struct {
+	Since    time.Time `json:"since"`
+	Tenants  TopList   `json:"tenants"`
+	Entities TopList   `json:"entities"`
+}
+
+// Tracker keeps approximate top counts of evaluations and alerts per tenant
+// and per debtor with the Space-Saving algorithm, in bounded memory. Counts
+// are kept per window: a report covers the window in progress and the one
+// before it, so a spike shows for one to two windows.
+type Tracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	started  time.Time // start of the current window
+	current  *topSketches
+	previous *topSketches
+}
+
+// topSketches are the four sketches of one window.
+type topSketches struct {
+	tenantEvaluations, tenantAlerts, entityEvaluations, entityAlerts *sketch
+}
+
+func newTopSketches() *topSketches {
+	return &topSketches{newSketch(), newSketch(), newSketch(), newSketch()}
+}
+
+// NewTracker creates a tracker counting over windows of the given length.
+func NewTracker(window time.Duration) *Tracker {
+	t := &Tracker{window: window, now: time.Now}
+	t.started = t.now()
+	t.current, t.previous = newTopSketches(), newTopSketches()
+	return t
+}
+
+// rotate starts a new window when the current one is over. A gap of more
+// than a window leaves nothing from before it. The caller holds t.mu.
+func (t *Tracker) rotate(now time.Time) {
+	elapsed := now.Sub(t.started)
+	if elapsed < t.window {
+		return
+	}
+	t.previous, t.current = t.current, newTopSketches()
+	if elapsed >= 2*t.window {
+		t.previous = newTopSketches()
+	}
+	t.started = now.Add(-elapsed % t.window)
+}
+
+// Evaluated counts an evaluation of the tenant's entity.
+func (t *Tracker) Evaluated(tenantID, entityID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(t.now())
+	t.current.tenantEvaluations.add(topKey{tenantID: tenantID})
+	if entityID != "" {
+		t.current.entityEvaluations.add(topKey{tenantID: tenantID, entityID: entityID})
+	}
+}
+
+// Alerted counts an alert on the tenant's entity.
+func (t *Tracker) Alerted(tenantID, entityID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(t.now())
+	t.current.tenantAlerts.add(topKey{tenantID: tenantID})
+	if entityID != "" {
+		t.current.entityAlerts.add(topKey{tenantID: tenantID, entityID: entityID})
+	}
+}
+
+// Top reports the n heaviest tenants and entities, heaviest first.
+func (t *Tracker) Top(n int) *TopReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(t.now())
+	return &TopReport{
+		Since: t.started.Add(-t.window).UTC(),
+		Tenants: TopList{
+			Evaluations: top(n, t.previous.tenantEvaluations, t.current.tenantEvaluations),
+			Alerts:      top(n, t.previous.tenantAlerts, t.current.tenantAlerts),
+		},
+		Entities: TopList{
+			Evaluations: top(n, t.previous.entityEvaluations, t.current.entityEvaluations),
+			Alerts:      top(n, t.previous.entityAlerts, t.current.entityAlerts),
+		},
+	}
+}
+
+// top merges the sketches and returns their n largest counts.
+func top(n int, sketches ...*sketch) []TopEntry {
+	merged := make(map[topKey]*TopEntry)
+	for _, s := range sketches {
+		for _, c := range s.counters {
+			entry, ok := merged[c.key]
+			if !ok {
+				entry = &TopEntry{TenantID: c.key.tenantID, EntityID: c.key.entityID}
+				merged[c.key] = entry
+			}
+			entry.Count += c.count
+			entry.Error += c.error
+		}
+	}
+	out := make([]TopEntry, 0, len(merged))
+	for _, entry := range merged {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		return out[i].EntityID < out[j].EntityID
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+type topKey struct {
+	tenantID, entityID string
+}
+
+type counter struct {
+	key   topKey
+	count int64
+	error int64
+	index int // position in the heap
+}
+
+// sketch is a Space-Saving sketch: TopCapacity counters in a min-heap. A
+// new key takes over the smallest counter when the sketch is full.
+type sketch struct {
+	counters []*counter
+	byKey    map[topKey]*counter
+}
+
+func newSketch() *sketch {
+	return &sketch{byKey: make(map[topKey]*counter)}
+}
+
+func (s *sketch) add(key topKey) {
+	if c, ok := s.byKey[key]; ok {
+		c.count++
+		heap.Fix(s, c.index)
+		return
+	}
+	if len(s.counters) < TopCapacity {
+		heap.Push(s, &counter{key: key, count: 1})
+		return
+	}
+	smallest := s.counters[0]
+	delete(s.byKey, smallest.key)
+	smallest.key, smallest.error = key, smallest.count
+	smallest.count++
+	s.byKey[key] = smallest
+	heap.Fix(s, 0)
+}
+
+// heap.Interface, smallest count first.
+
+func (s *sketch) Len() int           { return len(s.counters) }
+func (s *sketch) Less(i, j int) bool { return s.counters[i].count < s.counters[j].count }
+func (s *sketch) Swap(i, j int) {
+	s.counters[i], s.counters[j] = s.counters[j], s.counters[i]
+	s.counters[i].index, s.counters[j].index = i, j
+}
+
+func (s *sketch) Push(x any) {
+	c := x.(*counter)
+	c.index = len(s.counters)
+	s.counters = append(s.counters, c)
+	s.byKey[c.key] = c
+}
+
+func (s *sketch) Pop() any {
+	last := s.counters[len(s.counters)-1]
+	s.counters = s.counters[:len(s.counters)-1]
+	delete(s.byKey, last.key)
+	return last
+}
+
+// Repository counts every transaction it saves as an evaluation of its
+// tenant and debtor, and every ALRT evaluation as an alert.
+type Repository struct {
+	domain.Repository
+	tracker *Tracker
+}
+
+// Wrap returns repo with evaluation and alert volume counted in tracker.
+func Wrap(repo domain.Repository, tracker *Tracker) *Repository {
+	return &Repository{Repository: repo, tracker: tracker}
+}
+
+// Unwrap returns the repository whose saves are counted.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveTransaction saves the transaction and counts it.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if err := r.Repository.SaveTransaction(ctx, tenantID, tx); err != nil {
+		return err
+	}
+	r.tracker.Evaluated(tenantID, tx.DebtorID)
+	return nil
+}
+
+// SaveEvaluation saves the evaluation and, if it alerted, counts the alert
+// against the transaction's debtor.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if err := r.Repository.SaveEvaluation(ctx, tenantID, eval); err != nil {
+		return err
+	}
+	if eval.Status != domain.StatusAlert {
+		return nil
+	}
+	var debtorID string
+	if tx, err := r.Repository.GetTransaction(ctx, tenantID, eval.TxID); err == nil {
+		debtorID = tx.DebtorID
+	} else {
+		slog.Debug("alert counted without its debtor", "tenant_id", tenantID, "tx_id", eval.TxID, "error", err)
+	}
+	r.tracker.Alerted(tenantID, debtorID)
+	return nil
+}
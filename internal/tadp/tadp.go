@@ -18,10 +18,45 @@ type Processor struct {
 	// Weight configuration for rule aggregation
 	UseWeightedScoring bool
 
+	// What a failed rule does: domain.CriticalFailAlert (the default when
+	// empty) or domain.CriticalFailScore
+	CriticalFail string
+
 	// Mode determines evaluation strategy:
 	// - "detection": Rules → Weighted Score → Alert (fast, no typologies)
 	// - "compliance": Rules → Typologies → FATF patterns (requires typologies)
 	Mode string
+
+	// How much an ML model's score counts and how it is blended, when the
+	// tenant's scoring config can't be loaded
+	MLWeight float64
+	MLBlend  string
+
+	// Scoring resolves each tenant's own scoring config. When nil, or when the
+	// lookup fails, the fields above apply to every tenant.
+	Scoring ScoringSource
+
+	// ML scores detection mode transactions with an external model, blended
+	// with the rule score as the tenant's scoring config says. nil disables it.
+	ML MLScorer
+
+	// MLEnabled reports whether a tenant's evaluations call ML. When nil,
+	// every tenant's do.
+	MLEnabled func(ctx context.Context, tenantID string) bool
+
+	// What a decision does when ML fails: domain.MLFallbackRules (the
+	// default when empty) or domain.MLFallbackAlert
+	MLFallback string
+}
+
+// ScoringSource resolves the scoring config in effect for a tenant.
+type ScoringSource interface {
+	Effective(ctx context.Context, tenantID string) (*domain.ScoringConfig, error)
+}
+
+// MLScorer scores a transaction with an external model.
+type MLScorer interface {
+	Score(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error)
 }
 
 // NewProcessor creates a new TADP processor with default settings.
@@ -52,6 +87,11 @@ type DecisionInput struct {
 	RuleResults     []domain.RuleResult
 	TypologyResults []domain.TypologyResult // From TypologyEngine evaluation
 	StartTime       time.Time
+	Request         *domain.RequestContext    // nil for batch evaluations and queued messages without one
+	Degradations    []domain.Degradation      // Dependencies that failed or were skipped
+	Velocity        []domain.VelocitySnapshot // Velocity values the rules saw
+	UnknownTxType   bool                      // Type missing from the tenant's allowed list
+	Transaction     *domain.Transaction       // Sent to the ML model; nil sends only the IDs
 }
 
 // Process evaluates rule results and produces a final decision.
@@ -66,8 +106,12 @@ func (p *Processor) Process(ctx context.Context, input *DecisionInput) *domain.E
 		RuleResults: input.RuleResults,
 	}
 
+	scoring, degradations := p.scoring(ctx, input)
+	var mlDecision *domain.MLDecision
+
 	// Aggregate rule results
-	aggResult := p.aggregate(input.RuleResults)
+	aggResult := p.aggregate(input.RuleResults, scoring.WeightedScoring)
+	criticalAlert := aggResult.HasCriticalFailure && scoring.CriticalFail != domain.CriticalFailScore
 
 	// Compliance Mode: Use typology results for FATF-aligned evaluation
 	if p.Mode == "compliance" && len(input.TypologyResults) > 0 {
@@ -86,7 +130,7 @@ func (p *Processor) Process(ctx context.Context, input *DecisionInput) *domain.E
 		}
 
 		// Decision based on typology results
-		if anyTypologyTriggered || aggResult.HasCriticalFailure {
+		if anyTypologyTriggered || criticalAlert {
 			eval.Status = domain.StatusAlert
 		} else {
 			eval.Status = domain.StatusNoAlert
@@ -97,16 +141,31 @@ func (p *Processor) Process(ctx context.Context, input *DecisionInput) *domain.E
 	} else {
 		// Detection Mode: Fast, weighted rule aggregation (default)
 		// No typologies required - direct score-to-alert decision
-		if aggResult.HasCriticalFailure || aggResult.AggregateScore >= p.AlertThreshold {
+		score := aggResult.AggregateScore
+		mlAlert := false
+		if p.ML != nil && (p.MLEnabled == nil || p.MLEnabled(ctx, input.TenantID)) {
+			var err error
+			score, mlDecision, err = p.scoreML(ctx, input, score, scoring)
+			if err != nil {
+				degradations = append(degradations, domain.Degradation{
+					Component: domain.DegradedML,
+					Status:    domain.DegradationFailed,
+					Reason:    err.Error(),
+				})
+				mlAlert = mlDecision.Fallback == domain.MLFallbackAlert
+			}
+		}
+
+		if criticalAlert || mlAlert || score >= scoring.AlertThreshold {
 			eval.Status = domain.StatusAlert
 		} else {
 			eval.Status = domain.StatusNoAlert
 		}
 
-		eval.Score = aggResult.AggregateScore
+		eval.Score = score
 
 		// Build detection summary (optional typology-like grouping for reporting)
-		eval.TypologyResults = p.buildDetectionSummary(input.RuleResults, aggResult)
+		eval.TypologyResults = buildDetectionSummary(input.RuleResults, aggResult, scoring.AlertThreshold, criticalAlert, scoring.WeightedScoring)
 	}
 
 	// Populate metadata
@@ -120,11 +179,92 @@ func (p *Processor) Process(ctx context.Context, input *DecisionInput) *domain.E
 		DecisionMs:          decisionMs,
 		TotalMs:             totalMs,
 		EngineVersion:       "osprey-1.0",
+		Degradations:        degradations,
+		Velocity:            input.Velocity,
+		UnknownTxType:       input.UnknownTxType,
+		ML:                  mlDecision,
+	}
+	if rc := input.Request; rc != nil {
+		eval.Metadata.RequestID = rc.RequestID
+		eval.Metadata.Principal = rc.Principal
+		eval.Metadata.ClientIP = rc.ClientIP
 	}
 
 	return eval
 }
 
+// scoring returns the scoring config for the input's tenant and the
+// evaluation's degradations, including a failed lookup of that config.
+func (p *Processor) scoring(ctx context.Context, input *DecisionInput) (*domain.ScoringConfig, []domain.Degradation) {
+	degradations := input.Degradations
+	if p.Scoring != nil {
+		cfg, err := p.Scoring.Effective(ctx, input.TenantID)
+		if err == nil {
+			return cfg, degradations
+		}
+		degradations = append(degradations, domain.Degradation{
+			Component: domain.DegradedScoring,
+			Status:    domain.DegradationFailed,
+			Reason:    err.Error(),
+		})
+	}
+	return &domain.ScoringConfig{
+		AlertThreshold:  p.AlertThreshold,
+		WeightedScoring: p.UseWeightedScoring,
+		CriticalFail:    p.CriticalFail,
+		MLWeight:        p.MLWeight,
+		MLBlend:         p.MLBlend,
+	}, degradations
+}
+
+// scoreML asks the ML model to score the input and blends its score with
+// the rule score. When the model fails it returns the rule score, the error
+// and the fallback taken.
+func (p *Processor) scoreML(ctx context.Context, input *DecisionInput, ruleScore float64, scoring *domain.ScoringConfig) (float64, *domain.MLDecision, error) {
+	decision := &domain.MLDecision{
+		RuleScore: ruleScore,
+		Weight:    scoring.MLWeight,
+		Blend:     scoring.MLBlend,
+	}
+	if decision.Blend == "" {
+		decision.Blend = domain.MLBlendWeighted
+	}
+
+	req := &domain.MLRequest{
+		TenantID:    input.TenantID,
+		TxID:        input.TxID,
+		Transaction: input.Transaction,
+		RuleScore:   ruleScore,
+		RuleResults: []domain.MLRuleResult{},
+	}
+	for _, r := range input.RuleResults {
+		if r.Shadow {
+			continue
+		}
+		req.RuleResults = append(req.RuleResults, domain.MLRuleResult{RuleID: r.RuleID, Score: r.Score, Outcome: r.SubRuleRef, Weight: r.Weight})
+	}
+
+	result, err := p.ML.Score(ctx, req)
+	if err != nil {
+		decision.Fallback = p.MLFallback
+		if decision.Fallback == "" {
+			decision.Fallback = domain.MLFallbackRules
+		}
+		return ruleScore, decision, err
+	}
+	decision.Model = result.Model
+	decision.Score = result.Score
+
+	weight := min(max(decision.Weight, 0), 1)
+	switch {
+	case weight == 0:
+		return ruleScore, decision, nil
+	case decision.Blend == domain.MLBlendMax:
+		return max(ruleScore, result.Score), decision, nil
+	}
+	return (1-weight)*ruleScore + weight*result.Score, decision, nil
+}
+
 // AggregateResult holds the aggregated scoring results.
 type AggregateResult struct {
 	AggregateScore     float64
@@ -134,7 +274,8 @@ type AggregateResult struct {
 }
 
 // aggregate computes the weighted aggregate score from rule results.
-func (p *Processor) aggregate(results []domain.RuleResult) *AggregateResult {
+// Shadow results are skipped: they are recorded but never affect the decision.
+func (p *Processor) aggregate(results []domain.RuleResult, weighted bool) *AggregateResult {
 	if len(results) == 0 {
 		return &AggregateResult{}
 	}
@@ -142,6 +283,9 @@ func (p *Processor) aggregate(results []domain.RuleResult) *AggregateResult {
 	agg := &AggregateResult{}
 
 	for _, r := range results {
+		if r.Shadow {
+			continue
+		}
 		weight := r.Weight
 		if weight <= 0 {
 			weight = 1.0
@@ -155,7 +299,7 @@ func (p *Processor) aggregate(results []domain.RuleResult) *AggregateResult {
 			agg.RulesTriggered++
 		}
 
-		if p.UseWeightedScoring {
+		if weighted {
 			agg.AggregateScore += r.Score * weight
 			agg.TotalWeight += weight
 		} else {
@@ -172,34 +316,59 @@ func (p *Processor) aggregate(results []domain.RuleResult) *AggregateResult {
 	return agg
 }
 
+// DetectionSummaryID is the typology ID of the detection mode summary.
+const DetectionSummaryID = "detection-summary"
+
 // buildDetectionSummary creates a summary for Detection mode.
 // Groups all rules into a single "detection" result for consistent API response.
-func (p *Processor) buildDetectionSummary(rules []domain.RuleResult, agg *AggregateResult) []domain.TypologyResult {
+// Its contributions add up to the aggregate score.
+func buildDetectionSummary(rules []domain.RuleResult, agg *AggregateResult, threshold float64, criticalAlert bool, weighted bool) []domain.TypologyResult {
 	if len(rules) == 0 {
 		return nil
 	}
 
+	var contributions []domain.RuleContribution
+	for _, r := range rules {
+		if r.Shadow {
+			continue
+		}
+		weight := 1.0
+		if weighted && r.Weight > 0 {
+			weight = r.Weight
+		}
+		contribution := domain.RuleContribution{RuleID: r.RuleID, RuleScore: r.Score, Weight: weight}
+		if agg.TotalWeight > 0 {
+			contribution.Contribution = r.Score * weight / agg.TotalWeight
+		}
+		contributions = append(contributions, contribution)
+	}
+
 	return []domain.TypologyResult{
 		{
-			TypologyID:   "detection-summary",
-			TypologyName: "Detection Mode Summary",
-			Score:        agg.AggregateScore,
-			Threshold:    p.AlertThreshold,
-			Triggered:    agg.AggregateScore >= p.AlertThreshold || agg.HasCriticalFailure,
-			Rules:        rules,
+			TypologyID:    DetectionSummaryID,
+			TypologyName:  "Detection Mode Summary",
+			Score:         agg.AggregateScore,
+			Threshold:     threshold,
+			Triggered:     agg.AggregateScore >= threshold || criticalAlert,
+			Rules:         rules,
+			Contributions: contributions,
 		},
 	}
 }
 
-// ShouldAlert returns true if the evaluation should trigger an alert.
+// ShouldAlert returns true if the evaluation should trigger an alert
+// notification: it alerted outside any maintenance window.
 func ShouldAlert(eval *domain.Evaluation) bool {
-	return eval.Status == domain.StatusAlert
+	return eval.Status == domain.StatusAlert && eval.Metadata.SuppressedByMaintenance == ""
 }
 
 // GetReasons extracts human-readable reasons from an evaluation.
 func GetReasons(eval *domain.Evaluation) []string {
 	var reasons []string
 	for _, r := range eval.RuleResults {
+		if r.Shadow {
+			continue
+		}
 		if r.SubRuleRef == domain.RuleOutcomeFail || r.SubRuleRef == domain.RuleOutcomeReview {
 			if r.Reason != "" {
 				reasons = append(reasons, r.Reason)
@@ -2,6 +2,7 @@ package tadp
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -41,6 +42,23 @@ func TestProcessor(t *testing.T) {
 		}
 	})
 
+	t.Run("RequestContextInMetadata", func(t *testing.T) {
+		input := &DecisionInput{
+			TenantID:    "tenant-001",
+			TxID:        "tx-rc",
+			TraceID:     "trace-rc",
+			StartTime:   time.Now(),
+			RuleResults: []domain.RuleResult{{RuleID: "rule-1", Score: 0.1, SubRuleRef: domain.RuleOutcomePass, Weight: 1.0}},
+			Request:     &domain.RequestContext{RequestID: "req-001", Principal: "svc-payments", ClientIP: "10.0.0.5"},
+		}
+
+		eval := proc.Process(ctx, input)
+
+		if eval.Metadata.RequestID != "req-001" || eval.Metadata.Principal != "svc-payments" || eval.Metadata.ClientIP != "10.0.0.5" {
+			t.Errorf("expected request details in metadata, got %+v", eval.Metadata)
+		}
+	})
+
 	t.Run("CriticalFailure", func(t *testing.T) {
 		input := &DecisionInput{
 			TenantID:  "tenant-001",
@@ -215,6 +233,36 @@ func TestGetReasons(t *testing.T) {
 	}
 }
 
+func TestShadowRules(t *testing.T) {
+	proc := NewProcessor()
+
+	input := &DecisionInput{
+		TenantID:  "tenant-001",
+		TxID:      "tx-001",
+		TraceID:   "trace-001",
+		StartTime: time.Now(),
+		RuleResults: []domain.RuleResult{
+			{RuleID: "live", Score: 0.1, SubRuleRef: domain.RuleOutcomePass, Weight: 1.0},
+			{RuleID: "shadow", Score: 1.0, SubRuleRef: domain.RuleOutcomeFail, Reason: "Shadow fired", Weight: 1.0, Shadow: true},
+		},
+	}
+
+	eval := proc.Process(context.Background(), input)
+
+	if eval.Status != domain.StatusNoAlert {
+		t.Errorf("expected shadow failure not to alert, got %s", eval.Status)
+	}
+	if eval.Score != 0.1 {
+		t.Errorf("expected score 0.1 from the live rule only, got %v", eval.Score)
+	}
+	if len(eval.RuleResults) != 2 {
+		t.Errorf("expected shadow result to be recorded, got %d results", len(eval.RuleResults))
+	}
+	if reasons := GetReasons(eval); len(reasons) != 0 {
+		t.Errorf("expected no reasons from shadow rules, got %v", reasons)
+	}
+}
+
 func TestCustomThreshold(t *testing.T) {
 	proc := &Processor{
 		AlertThreshold:     0.5, // Lower threshold
@@ -266,6 +314,177 @@ func TestUnweightedScoring(t *testing.T) {
 	}
 }
 
+// scoringSource serves one scoring config to every tenant.
+type scoringSource struct {
+	cfg *domain.ScoringConfig
+	err error
+}
+
+func (s scoringSource) Effective(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	return s.cfg, s.err
+}
+
+func TestTenantScoring(t *testing.T) {
+	ctx := context.Background()
+	input := func() *DecisionInput {
+		return &DecisionInput{
+			TenantID:  "tenant-001",
+			TxID:      "tx-001",
+			StartTime: time.Now(),
+			RuleResults: []domain.RuleResult{
+				{RuleID: "rule-1", Score: 0.2, SubRuleRef: domain.RuleOutcomeFail, Weight: 1.0},
+				{RuleID: "rule-2", Score: 0.5, SubRuleRef: domain.RuleOutcomePass, Weight: 3.0},
+			},
+		}
+	}
+
+	t.Run("ThresholdAndWeighting", func(t *testing.T) {
+		proc := NewProcessor()
+		proc.Scoring = scoringSource{cfg: &domain.ScoringConfig{AlertThreshold: 0.35, WeightedScoring: false, CriticalFail: domain.CriticalFailScore}}
+
+		eval := proc.Process(ctx, input())
+
+		// Unweighted: (0.2 + 0.5) / 2 = 0.35
+		if eval.Score < 0.349 || eval.Score > 0.351 {
+			t.Errorf("expected unweighted score 0.35, got %.3f", eval.Score)
+		}
+		if eval.Status != domain.StatusAlert {
+			t.Errorf("expected ALRT at the tenant's 0.35 threshold, got %s", eval.Status)
+		}
+		if eval.TypologyResults[0].Threshold != 0.35 {
+			t.Errorf("expected the summary to show the tenant's threshold, got %v", eval.TypologyResults[0].Threshold)
+		}
+	})
+
+	t.Run("CriticalFailScore", func(t *testing.T) {
+		proc := NewProcessor()
+		proc.Scoring = scoringSource{cfg: &domain.ScoringConfig{AlertThreshold: 0.7, WeightedScoring: true, CriticalFail: domain.CriticalFailScore}}
+
+		eval := proc.Process(ctx, input())
+
+		if eval.Status != domain.StatusNoAlert {
+			t.Errorf("expected the failed rule to count through its score only, got %s", eval.Status)
+		}
+		if eval.TypologyResults[0].Triggered {
+			t.Error("expected the detection summary not to trigger")
+		}
+
+		proc.Scoring = scoringSource{cfg: &domain.ScoringConfig{AlertThreshold: 0.7, WeightedScoring: true, CriticalFail: domain.CriticalFailAlert}}
+		if eval := proc.Process(ctx, input()); eval.Status != domain.StatusAlert {
+			t.Errorf("expected the failed rule to force an alert, got %s", eval.Status)
+		}
+	})
+
+	t.Run("LookupFailureUsesDefaults", func(t *testing.T) {
+		proc := NewProcessor()
+		proc.Scoring = scoringSource{err: errors.New("database down")}
+
+		eval := proc.Process(ctx, input())
+
+		if eval.Status != domain.StatusAlert {
+			t.Errorf("expected the default critical fail behaviour, got %s", eval.Status)
+		}
+		got := eval.Metadata.Degradations
+		if len(got) != 1 || got[0].Component != domain.DegradedScoring || got[0].Status != domain.DegradationFailed {
+			t.Errorf("expected a failed scoring degradation, got %v", got)
+		}
+	})
+}
+
+// mlScorer answers with a fixed score or error and records its requests.
+type mlScorer struct {
+	score    float64
+	err      error
+	requests []*domain.MLRequest
+}
+
+func (s *mlScorer) Score(ctx context.Context, req *domain.MLRequest) (*domain.MLScore, error) {
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.MLScore{Score: s.score, Model: "xgb-1"}, nil
+}
+
+func TestMLScoring(t *testing.T) {
+	ctx := context.Background()
+	input := &DecisionInput{
+		TenantID:    "tenant-001",
+		TxID:        "tx-001",
+		StartTime:   time.Now(),
+		Transaction: &domain.Transaction{ID: "tx-001", Amount: domain.MustDecimal("9500")},
+		RuleResults: []domain.RuleResult{
+			{RuleID: "rule-1", Score: 0.4, SubRuleRef: domain.RuleOutcomePass, Weight: 1.0},
+			{RuleID: "shadow", Score: 1, SubRuleRef: domain.RuleOutcomeFail, Weight: 1.0, Shadow: true},
+		},
+	}
+	processor := func(scorer *mlScorer, cfg domain.ScoringConfig) *Processor {
+		proc := NewProcessor()
+		proc.ML = scorer
+		cfg.AlertThreshold = 0.7
+		proc.Scoring = scoringSource{cfg: &cfg}
+		return proc
+	}
+
+	t.Run("Weighted", func(t *testing.T) {
+		scorer := &mlScorer{score: 0.9}
+		eval := processor(scorer, domain.ScoringConfig{MLWeight: 0.5}).Process(ctx, input)
+
+		// 0.5 × 0.4 + 0.5 × 0.9 = 0.65
+		if eval.Score < 0.649 || eval.Score > 0.651 || eval.Status != domain.StatusNoAlert {
+			t.Errorf("expected a blended score of 0.65 below the threshold, got %.3f %s", eval.Score, eval.Status)
+		}
+		ml := eval.Metadata.ML
+		if ml == nil || ml.Score != 0.9 || ml.RuleScore != 0.4 || ml.Model != "xgb-1" || ml.Blend != domain.MLBlendWeighted {
+			t.Errorf("expected the model's part to be recorded, got %+v", ml)
+		}
+		req := scorer.requests[0]
+		if req.TxID != "tx-001" || req.Transaction == nil || req.RuleScore != 0.4 || len(req.RuleResults) != 1 {
+			t.Errorf("expected the transaction and the live rule results to be sent, got %+v", req)
+		}
+	})
+
+	t.Run("Max", func(t *testing.T) {
+		eval := processor(&mlScorer{score: 0.9}, domain.ScoringConfig{MLWeight: 1, MLBlend: domain.MLBlendMax}).Process(ctx, input)
+		if eval.Score != 0.9 || eval.Status != domain.StatusAlert {
+			t.Errorf("expected the model's higher score to alert, got %.3f %s", eval.Score, eval.Status)
+		}
+	})
+
+	t.Run("RecordOnly", func(t *testing.T) {
+		eval := processor(&mlScorer{score: 0.9}, domain.ScoringConfig{}).Process(ctx, input)
+		if eval.Score != 0.4 || eval.Metadata.ML == nil || eval.Metadata.ML.Score != 0.9 {
+			t.Errorf("expected the model's score to be recorded only, got %.3f %+v", eval.Score, eval.Metadata.ML)
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		proc := processor(&mlScorer{err: errors.New("deadline exceeded")}, domain.ScoringConfig{MLWeight: 0.5})
+		eval := proc.Process(ctx, input)
+		if eval.Score != 0.4 || eval.Status != domain.StatusNoAlert || eval.Metadata.ML.Fallback != domain.MLFallbackRules {
+			t.Errorf("expected the rules to decide alone, got %.3f %s %+v", eval.Score, eval.Status, eval.Metadata.ML)
+		}
+		got := eval.Metadata.Degradations
+		if len(got) != 1 || got[0].Component != domain.DegradedML {
+			t.Errorf("expected an ml degradation, got %v", got)
+		}
+
+		proc.MLFallback = domain.MLFallbackAlert
+		if eval := proc.Process(ctx, input); eval.Status != domain.StatusAlert {
+			t.Errorf("expected the alert fallback to alert, got %s", eval.Status)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		scorer := &mlScorer{score: 0.9}
+		proc := processor(scorer, domain.ScoringConfig{MLWeight: 0.5})
+		proc.MLEnabled = func(ctx context.Context, tenantID string) bool { return false }
+		if eval := proc.Process(ctx, input); eval.Score != 0.4 || eval.Metadata.ML != nil || len(scorer.requests) != 0 {
+			t.Errorf("expected no model call for a tenant without the hook, got %.3f %+v", eval.Score, eval.Metadata.ML)
+		}
+	})
+}
+
 // ============================================================================
 // COMPLIANCE MODE TESTS
 // ============================================================================
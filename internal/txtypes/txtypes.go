@@ -0,0 +1,180 @@
+// Package txtypes enforces per-tenant allowed transaction types and counts
+// the types that were not on a tenant's list.
+package txtypes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// AnyTenant is the allowed-list entry for tenants without their own list.
+const AnyTenant = "*"
+
+// UnknownType counts a transaction type missing from a tenant's allowed list.
+type UnknownType struct {
+	Type       string    `json:"type"`
+	Count      int64     `json:"count"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// Policy checks transaction types against the configured allowed lists. A nil
+// Policy, or one without lists, allows every type.
+type Policy struct {
+	allowed map[string]map[string]bool
+	action  string
+	now     func() time.Time
+
+	mu      sync.Mutex
+	unknown map[string]map[string]*UnknownType // tenant -> type
+}
+
+// NewPolicy creates a policy from configuration. An empty Unknown action
+// rejects unknown types.
+func NewPolicy(cfg domain.TxTypeConfig) *Policy {
+	p := &Policy{
+		allowed: make(map[string]map[string]bool, len(cfg.Allowed)),
+		action:  cfg.Unknown,
+		now:     time.Now,
+		unknown: make(map[string]map[string]*UnknownType),
+	}
+	if p.action == "" {
+		p.action = domain.TxTypeReject
+	}
+	for tenantID, types := range cfg.Allowed {
+		set := make(map[string]bool, len(types))
+		for _, t := range types {
+			set[t] = true
+		}
+		p.allowed[tenantID] = set
+	}
+	return p
+}
+
+// Enabled reports whether any tenant has an allowed list.
+func (p *Policy) Enabled() bool {
+	return p != nil && len(p.allowed) > 0
+}
+
+// Action returns what happens to unknown types: domain.TxTypeReject or
+// domain.TxTypeFlag.
+func (p *Policy) Action() string {
+	if p == nil {
+		return domain.TxTypeReject
+	}
+	return p.action
+}
+
+// Allowed returns the tenant's allowed types, sorted, or nil when every type
+// is allowed.
+func (p *Policy) Allowed(tenantID string) []string {
+	set := p.tenantSet(tenantID)
+	if set == nil {
+		return nil
+	}
+	types := make([]string, 0, len(set))
+	for t := range set {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Check reports whether the tenant allows the transaction type. Types that
+// are not allowed are counted for Unknown.
+func (p *Policy) Check(tenantID, txType string) bool {
+	set := p.tenantSet(tenantID)
+	if set == nil || set[txType] {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	types := p.unknown[tenantID]
+	if types == nil {
+		types = make(map[string]*UnknownType)
+		p.unknown[tenantID] = types
+	}
+	u := types[txType]
+	if u == nil {
+		u = &UnknownType{Type: txType}
+		types[txType] = u
+	}
+	u.Count++
+	u.LastSeenAt = p.now().UTC()
+	return false
+}
+
+// Unknown returns the unknown types seen for a tenant since startup, most
+// frequent first.
+func (p *Policy) Unknown(tenantID string) []UnknownType {
+	if p == nil {
+		return []UnknownType{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]UnknownType, 0, len(p.unknown[tenantID]))
+	for _, u := range p.unknown[tenantID] {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// tenantSet returns the tenant's allowed set, falling back to AnyTenant.
+func (p *Policy) tenantSet(tenantID string) map[string]bool {
+	if p == nil {
+		return nil
+	}
+	if set, ok := p.allowed[tenantID]; ok {
+		return set
+	}
+	return p.allowed[AnyTenant]
+}
+
+// ParseAllowed parses per-tenant allowed types: "tenant=transfer|payment,*=transfer".
+func ParseAllowed(s string) (map[string][]string, error) {
+	allowed := make(map[string][]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, value, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("invalid allowed types %q (want tenant=type|type)", entry)
+		}
+		var types []string
+		for _, t := range strings.Split(value, "|") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			return nil, fmt.Errorf("tenant %s: no transaction types", tenantID)
+		}
+		allowed[tenantID] = append(allowed[tenantID], types...)
+	}
+	return allowed, nil
+}
+
+// ParseAction validates an unknown-type action.
+func ParseAction(s string) (string, error) {
+	switch s {
+	case domain.TxTypeReject, domain.TxTypeFlag:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown transaction type action %q (want %s or %s)", s, domain.TxTypeReject, domain.TxTypeFlag)
+	}
+}
@@ -0,0 +1,72 @@
+package txtypes
+
+import (
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy(domain.TxTypeConfig{Allowed: map[string][]string{
+		"tenant-a": {"transfer", "payment"},
+		AnyTenant:  {"transfer"},
+	}})
+
+	t.Run("Allowed", func(t *testing.T) {
+		if !p.Check("tenant-a", "payment") {
+			t.Error("expected payment to be allowed for tenant-a")
+		}
+		if !p.Check("tenant-b", "transfer") {
+			t.Error("expected the * list to apply to tenant-b")
+		}
+		if got := p.Allowed("tenant-a"); len(got) != 2 || got[0] != "payment" {
+			t.Errorf("expected sorted allowed types, got %v", got)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		for _, txType := range []string{"refund", "garbage", "refund"} {
+			if p.Check("tenant-b", txType) {
+				t.Errorf("expected %s to be unknown for tenant-b", txType)
+			}
+		}
+		unknown := p.Unknown("tenant-b")
+		if len(unknown) != 2 || unknown[0].Type != "refund" || unknown[0].Count != 2 || unknown[1].Count != 1 {
+			t.Errorf("unexpected unknown types: %+v", unknown)
+		}
+		if len(p.Unknown("tenant-a")) != 0 {
+			t.Error("expected unknown types to be counted per tenant")
+		}
+		if p.Action() != domain.TxTypeReject {
+			t.Errorf("expected reject by default, got %s", p.Action())
+		}
+	})
+
+	t.Run("NoLists", func(t *testing.T) {
+		var nilPolicy *Policy
+		for _, p := range []*Policy{nilPolicy, NewPolicy(domain.TxTypeConfig{})} {
+			if p.Enabled() || !p.Check("tenant-a", "anything") || p.Allowed("tenant-a") != nil {
+				t.Error("expected a policy without lists to allow every type")
+			}
+		}
+	})
+}
+
+func TestParseAllowed(t *testing.T) {
+	allowed, err := ParseAllowed("tenant-a=transfer|payment, *=transfer,")
+	if err != nil {
+		t.Fatalf("ParseAllowed failed: %v", err)
+	}
+	if len(allowed) != 2 || len(allowed["tenant-a"]) != 2 || allowed["*"][0] != "transfer" {
+		t.Errorf("unexpected allowed types: %v", allowed)
+	}
+
+	for _, s := range []string{"tenant-a", "=transfer", "tenant-a=", "tenant-a=|"} {
+		if _, err := ParseAllowed(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+	if _, err := ParseAction("drop"); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
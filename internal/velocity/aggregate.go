@@ -0,0 +1,224 @@
+package velocity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// Aggregate summarizes an entity's transactions over a velocity window.
+// Reversals are netted out of Sum rather than added to it, so Sum is
+// negative when the window holds reversals of transactions older than it.
+type Aggregate struct {
+	Count          int64     `json:"count"`
+	Sum            float64   `json:"sum"`
+	Max            float64   `json:"max"`            // largest amount, reversals excluded
+	Counterparties []string  `json:"counterparties"` // sorted, distinct
+	NetFlow        float64   `json:"netFlow"`        // amounts received minus amounts sent
+	Reversals      int64     `json:"reversals"`
+	ReconciledAt   time.Time `json:"reconciledAt"`
+}
+
+// add folds one transaction of the entity into the aggregate.
+func (a *Aggregate) add(entityID string, tx *domain.Transaction) {
+	amount := tx.Amount.Float64()
+	a.Count++
+	if tx.ReversalOf != "" {
+		a.Reversals++
+		a.Sum -= amount
+	} else {
+		a.Sum += amount
+		a.Max = max(a.Max, amount)
+	}
+	switch entityID {
+	case tx.DebtorID:
+		if tx.CreditorID != entityID {
+			a.NetFlow -= amount
+		}
+	case tx.CreditorID:
+		a.NetFlow += amount
+	}
+
+	counterparty := tx.CreditorID
+	if counterparty == entityID {
+		counterparty = tx.DebtorID
+	}
+	if counterparty == "" || counterparty == entityID {
+		return
+	}
+	if i, found := slices.BinarySearch(a.Counterparties, counterparty); !found {
+		a.Counterparties = slices.Insert(a.Counterparties, i, counterparty)
+	}
+}
+
+// aggregateKey caches an entity's aggregate for one window; the cache scopes
+// it to the tenant.
+func aggregateKey(entityID string, windowSecs int) string {
+	return "velocity:agg:" + entityID + ":" + strconv.Itoa(windowSecs)
+}
+
+// Aggregate returns the sum, maximum and distinct counterparties of an
+// entity's transactions over the window, as a debtor or creditor. A cached
+// aggregate is served until it is older than reconcile and then recomputed
+// from the database; reconcile <= 0 always reads the database.
+func (s *Service) Aggregate(ctx context.Context, tenantID, entityID string, windowSecs int, reconcile time.Duration) (*Aggregate, error) {
+	agg, _, err := s.aggregate(ctx, tenantID, entityID, windowSecs, reconcile)
+	return agg, err
+}
+
+// aggregate is Aggregate, also reporting whether the aggregate came from the
+// cache rather than the database.
+func (s *Service) aggregate(ctx context.Context, tenantID, entityID string, windowSecs int, reconcile time.Duration) (*Aggregate, bool, error) {
+	if tenantID == "" || entityID == "" {
+		return nil, false, fmt.Errorf("tenantID and entityID are required")
+	}
+
+	until := domain.EvaluationTime(ctx)
+	if !live(until) {
+		// Cached aggregates cover the window ending now
+		agg, err := s.reconcile(ctx, tenantID, entityID, windowSecs, until)
+		return agg, false, err
+	}
+
+	if s.cache != nil && reconcile > 0 {
+		data, err := s.cache.Get(ctx, tenantID, aggregateKey(entityID, windowSecs))
+		if err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		} else if data != nil {
+			var agg Aggregate
+			if err := json.Unmarshal(data, &agg); err == nil && time.Since(agg.ReconciledAt) < reconcile {
+				return &agg, true, nil
+			}
+		}
+	}
+
+	agg, err := s.reconcile(ctx, tenantID, entityID, windowSecs, until)
+	if err != nil {
+		return nil, false, err
+	}
+	s.storeAggregate(ctx, tenantID, entityID, windowSecs, agg, reconcile)
+	return agg, false, nil
+}
+
+// reconcile computes an entity's aggregate over the window ending at until
+// from the database.
+func (s *Service) reconcile(ctx context.Context, tenantID, entityID string, windowSecs int, until time.Time) (*Aggregate, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	txs, err := s.repo.GetTransactionsByEntity(ctx, tenantID, entityID, until.Add(-time.Duration(windowSecs)*time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	agg := &Aggregate{Counterparties: []string{}, ReconciledAt: time.Now().UTC()}
+	for _, tx := range txs {
+		if !tx.Timestamp.After(until) {
+			agg.add(entityID, tx)
+		}
+	}
+	return agg, nil
+}
+
+// storeAggregate caches an aggregate until it is due for reconciliation.
+// Concurrent updates of the same entity may overwrite each other; the next
+// reconciliation corrects them, as it drops transactions that have left the
+// window.
+func (s *Service) storeAggregate(ctx context.Context, tenantID, entityID string, windowSecs int, agg *Aggregate, reconcile time.Duration) {
+	if s.cache == nil || reconcile <= 0 {
+		return
+	}
+	ttl := reconcile - time.Since(agg.ReconciledAt)
+	if ttl <= 0 {
+		return
+	}
+	if data, err := json.Marshal(agg); err == nil {
+		_ = s.cache.Set(ctx, tenantID, aggregateKey(entityID, windowSecs), data, ttl)
+	}
+}
+
+// AggregateEnricher exposes the debtor's transaction amounts over the
+// tenant's velocity window, or the transaction's own, as velocity_sum,
+// velocity_max_amount, distinct_counterparties, net_flow and
+// has_recent_reversal. Like velocity_count they include the transaction
+// being evaluated.
+func (s *Service) AggregateEnricher(cfg domain.VelocityConfig) rules.Enricher {
+	if cfg.Reconcile <= 0 {
+		cfg.Reconcile = domain.DefaultVelocityReconcile
+	}
+	return &aggregateEnricher{svc: s, cfg: cfg}
+}
+
+type aggregateEnricher struct {
+	svc *Service
+	cfg domain.VelocityConfig
+}
+
+func (e *aggregateEnricher) Name() string {
+	return "velocity_aggregates"
+}
+
+func (e *aggregateEnricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"velocity_sum":            cel.DoubleType,
+		"velocity_max_amount":     cel.DoubleType,
+		"distinct_counterparties": cel.IntType,
+		"net_flow":                cel.DoubleType,
+		"has_recent_reversal":     cel.BoolType,
+	}
+}
+
+func (e *aggregateEnricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	activation["velocity_sum"] = 0.0
+	activation["velocity_max_amount"] = 0.0
+	activation["distinct_counterparties"] = int64(0)
+	activation["net_flow"] = 0.0
+	activation["has_recent_reversal"] = false
+	if input.DebtorID == "" {
+		return nil
+	}
+
+	windowSecs := input.VelocityWindow
+	if windowSecs <= 0 {
+		windowSecs = e.cfg.WindowSeconds(input.TenantID)
+	}
+
+	// The transaction is stored before it is evaluated, so the database
+	// includes it but a cached aggregate doesn't yet: fold it in
+	agg, cached, err := e.svc.aggregate(ctx, input.TenantID, input.DebtorID, windowSecs, e.cfg.Reconcile)
+	if err != nil {
+		return err
+	}
+	if cached {
+		agg.add(input.DebtorID, &domain.Transaction{DebtorID: input.DebtorID, CreditorID: input.CreditorID, Amount: input.Amount, ReversalOf: input.ReversalOf})
+		e.svc.storeAggregate(ctx, input.TenantID, input.DebtorID, windowSecs, agg, e.cfg.Reconcile)
+	}
+
+	// A refund-heavy window nets to zero volume, not below
+	sum := max(agg.Sum, 0)
+	activation["velocity_sum"] = sum
+	activation["velocity_max_amount"] = agg.Max
+	activation["distinct_counterparties"] = int64(len(agg.Counterparties))
+	activation["net_flow"] = agg.NetFlow
+	activation["has_recent_reversal"] = agg.Reversals > 0
+	for _, v := range []struct {
+		variable string
+		value    float64
+	}{
+		{"velocity_sum", sum},
+		{"velocity_max_amount", agg.Max},
+		{"distinct_counterparties", float64(len(agg.Counterparties))},
+		{"net_flow", agg.NetFlow},
+	} {
+		domain.ReportVelocity(ctx, domain.VelocitySnapshot{Variable: v.variable, EntityID: input.DebtorID, WindowSeconds: windowSecs, Value: v.value})
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package velocity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// Default burst windows: the last 10 minutes against the hourly average.
+const (
+	DefaultBurstShortWindow = 10 * time.Minute
+	DefaultBurstLongWindow  = time.Hour
+)
+
+// BurstRatio compares an entity's transaction rate over the short window with
+// its average rate over the long window, which includes the short one. 1.0 is
+// a steady rate; the maximum, long/short, means every transaction in the long
+// window arrived within the short one. Returns 0 without history.
+func (s *Service) BurstRatio(ctx context.Context, tenantID, entityID string, short, long time.Duration) (float64, error) {
+	if tenantID == "" || entityID == "" {
+		return 0, fmt.Errorf("tenantID and entityID are required")
+	}
+	if short <= 0 || long <= short {
+		return 0, fmt.Errorf("burst windows must satisfy 0 < short < long")
+	}
+
+	now := domain.EvaluationTime(ctx)
+	shortCount, longCount, err := s.windowCounts(ctx, tenantID, entityID, now.Add(-short), now.Add(-long), now)
+	if err != nil {
+		return 0, err
+	}
+	if longCount == 0 {
+		return 0, nil
+	}
+
+	expected := float64(longCount) * short.Seconds() / long.Seconds()
+	return float64(shortCount) / expected, nil
+}
+
+// windowCounts counts an entity's transactions since each cutoff and until
+// until with a single read of the long window.
+func (s *Service) windowCounts(ctx context.Context, tenantID, entityID string, shortSince, longSince, until time.Time) (int64, int64, error) {
+	if s.db != nil {
+		query := `
+			SELECT
+				COALESCE(SUM(CASE WHEN timestamp >= ? THEN 1 ELSE 0 END), 0),
+				COUNT(*)
+			FROM transactions
+			WHERE tenant_id = ?
+			AND (debtor_id = ? OR creditor_id = ?)
+			AND timestamp >= ? AND timestamp <= ?
+		`
+
+		var shortCount, longCount int64
+		err := s.db.QueryRowContext(ctx, query, shortSince, tenantID, entityID, entityID, longSince, until).Scan(&shortCount, &longCount)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to count transactions: %w", err)
+		}
+		return shortCount, longCount, nil
+	}
+
+	if s.repo == nil {
+		return 0, 0, fmt.Errorf("no data source available")
+	}
+
+	txs, err := s.repo.GetTransactionsByEntity(ctx, tenantID, entityID, longSince)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	var shortCount, longCount int64
+	for _, tx := range txs {
+		if tx.Timestamp.After(until) {
+			continue
+		}
+		longCount++
+		if !tx.Timestamp.Before(shortSince) {
+			shortCount++
+		}
+	}
+	return shortCount, longCount, nil
+}
+
+// BurstEnricher exposes the debtor's burst ratio to rules as
+// velocity_burst_ratio. Zero windows use the defaults.
+func (s *Service) BurstEnricher(short, long time.Duration) rules.Enricher {
+	if short <= 0 {
+		short = DefaultBurstShortWindow
+	}
+	if long <= 0 {
+		long = DefaultBurstLongWindow
+	}
+	return &burstEnricher{svc: s, short: short, long: long}
+}
+
+type burstEnricher struct {
+	svc   *Service
+	short time.Duration
+	long  time.Duration
+}
+
+func (e *burstEnricher) Name() string {
+	return "velocity_burst"
+}
+
+func (e *burstEnricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"velocity_burst_ratio": cel.DoubleType,
+	}
+}
+
+func (e *burstEnricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	ratio, err := e.svc.BurstRatio(ctx, input.TenantID, input.DebtorID, e.short, e.long)
+	activation["velocity_burst_ratio"] = ratio
+	return err
+}
@@ -0,0 +1,180 @@
+package velocity
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// counterBuckets is how many buckets a window's count is kept in. A count
+// read from the buckets includes the whole of the oldest one, so it may
+// include transactions up to a 60th of the window older than the window.
+const counterBuckets = 60
+
+// Repository counts every transaction it saves in the velocity counters.
+type Repository struct {
+	domain.Repository
+	svc *Service
+}
+
+// Wrap returns repo with the velocity counters of svc written through on
+// every saved transaction.
+func Wrap(repo domain.Repository, svc *Service) *Repository {
+	return &Repository{Repository: repo, svc: svc}
+}
+
+// Unwrap returns the repository whose transactions are counted.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveTransaction saves a transaction, then counts it for its debtor and
+// creditor. A failure to count it is logged and doesn't fail the save.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if err := r.Repository.SaveTransaction(ctx, tenantID, tx); err != nil {
+		return err
+	}
+	if err := r.svc.Record(ctx, tenantID, tx); err != nil {
+		slog.Warn("failed to count transaction velocity", "tenant_id", tenantID, "tx_id", tx.ID, "error", err)
+	}
+	return nil
+}
+
+// EnableCounters makes GetTransactionCount serve each tenant's velocity
+// window from per-entity counters in the cache, which Record increments as
+// transactions are saved. An entity's count is read from the database on
+// its first read, which corrects the counters, and again every
+// cfg.Reconcile; other windows are always read from the database.
+func (s *Service) EnableCounters(cfg domain.VelocityConfig) {
+	if cfg.Reconcile <= 0 {
+		cfg.Reconcile = domain.DefaultVelocityReconcile
+	}
+	s.counters = &cfg
+}
+
+// counterBucketSecs returns the width of a window's buckets, at least a second.
+func counterBucketSecs(windowSecs int) int64 {
+	return max(int64(windowSecs/counterBuckets), 1)
+}
+
+// counterKey names an entity's count in one bucket of a window; the cache
+// scopes it to the tenant.
+func counterKey(entityID string, windowSecs int, bucket int64) string {
+	return "velocity:count:" + entityID + ":" + strconv.Itoa(windowSecs) + ":" + strconv.FormatInt(bucket, 10)
+}
+
+// reconciledKey marks an entity's window counters as reconciled with the
+// database until it expires.
+func reconciledKey(entityID string, windowSecs int) string {
+	return "velocity:reconciled:" + entityID + ":" + strconv.Itoa(windowSecs)
+}
+
+// counterTTL returns how long a bucket's counter must live: until the
+// bucket has left the window.
+func counterTTL(bucket, bucketSecs int64, windowSecs int, now time.Time) time.Duration {
+	expires := time.Unix((bucket+1)*bucketSecs, 0).Add(time.Duration(windowSecs) * time.Second)
+	return expires.Sub(now)
+}
+
+// Record counts a saved transaction for its debtor and creditor in the
+// tenant's velocity window. A transaction dated before the window is not
+// counted. If a counter can't be incremented, the entity is reconciled on
+// its next read.
+func (s *Service) Record(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	if s.counters == nil || s.cache == nil {
+		return nil
+	}
+
+	windowSecs := s.counters.WindowSeconds(tenantID)
+	bucketSecs := counterBucketSecs(windowSecs)
+	bucket := tx.Timestamp.Unix() / bucketSecs
+	ttl := counterTTL(bucket, bucketSecs, windowSecs, time.Now())
+	if ttl <= 0 {
+		return nil
+	}
+
+	entities := []string{tx.DebtorID}
+	if tx.CreditorID != tx.DebtorID {
+		entities = append(entities, tx.CreditorID)
+	}
+	for _, entityID := range entities {
+		if entityID == "" {
+			continue
+		}
+		if _, err := s.cache.IncrementCounterBy(ctx, tenantID, counterKey(entityID, windowSecs, bucket), 1, ttl); err != nil {
+			_ = s.cache.Delete(ctx, tenantID, reconciledKey(entityID, windowSecs))
+			return err
+		}
+	}
+	return nil
+}
+
+// countFromCounters returns an entity's count over the tenant's velocity
+// window from the counters, reconciling them first when due. ok is false
+// when the counters can't serve the window, so the caller reads the
+// database instead.
+func (s *Service) countFromCounters(ctx context.Context, tenantID, entityID string, windowSecs int) (count int64, ok bool, err error) {
+	if s.counters == nil || s.cache == nil || windowSecs != s.counters.WindowSeconds(tenantID) {
+		return 0, false, nil
+	}
+
+	now := time.Now()
+	since := now.Add(-time.Duration(windowSecs) * time.Second)
+	bucketSecs := counterBucketSecs(windowSecs)
+	first, last := since.Unix()/bucketSecs, now.Unix()/bucketSecs
+	keys := make([]string, 0, last-first+1)
+	for bucket := first; bucket <= last; bucket++ {
+		keys = append(keys, counterKey(entityID, windowSecs, bucket))
+	}
+
+	reconciled, err := s.cache.Get(ctx, tenantID, reconciledKey(entityID, windowSecs))
+	if err != nil {
+		domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		return 0, false, nil
+	}
+	counts, err := s.cache.GetCounters(ctx, tenantID, keys)
+	if err != nil {
+		domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+		return 0, false, nil
+	}
+	if reconciled != nil {
+		for _, n := range counts {
+			count += n
+		}
+		return count, true, nil
+	}
+
+	// Cold or due: count from the database and correct each bucket
+	if s.repo == nil {
+		return 0, false, nil
+	}
+	txs, err := s.repo.GetTransactionsByEntity(ctx, tenantID, entityID, time.Unix(first*bucketSecs, 0))
+	if err != nil {
+		return 0, true, err
+	}
+	stored := make([]int64, len(keys))
+	for _, tx := range txs {
+		if bucket := tx.Timestamp.Unix() / bucketSecs; bucket >= first && bucket <= last {
+			stored[bucket-first]++
+		}
+		if !tx.Timestamp.Before(since) {
+			count++
+		}
+	}
+	for i, key := range keys {
+		delta := stored[i] - counts[i]
+		if delta == 0 {
+			continue
+		}
+		ttl := counterTTL(first+int64(i), bucketSecs, windowSecs, now)
+		if _, err := s.cache.IncrementCounterBy(ctx, tenantID, key, delta, ttl); err != nil {
+			domain.ReportDegradation(ctx, domain.DegradedCache, domain.DegradationFailed, err.Error())
+			return count, true, nil
+		}
+	}
+	_ = s.cache.Set(ctx, tenantID, reconciledKey(entityID, windowSecs), []byte(now.UTC().Format(time.RFC3339)), s.counters.Reconcile)
+	return count, true, nil
+}
@@ -0,0 +1,32 @@
+package velocity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Payments returns the payments a debtor sent since a time, reversals and
+// transactions received excluded. It is the rules.WindowSource behind
+// window_sum_below and window_count_below, which detect structuring: many
+// payments each under a reporting threshold that together cross it.
+func (s *Service) Payments(ctx context.Context, tenantID, debtorID string, since time.Time) ([]*domain.Transaction, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	txs, err := s.repo.GetTransactionsByEntity(ctx, tenantID, debtorID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	payments := txs[:0]
+	for _, tx := range txs {
+		if tx.DebtorID == debtorID && tx.ReversalOf == "" {
+			payments = append(payments, tx)
+		}
+	}
+	return payments, nil
+}
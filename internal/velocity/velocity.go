@@ -15,6 +15,10 @@ type Service struct {
 	repo  domain.Repository
 	cache domain.Cache
 	db    *sql.DB // Direct DB access for custom queries
+
+	// counters, when set, serves the tenants' velocity windows from cache
+	// counters written through on save
+	counters *domain.VelocityConfig
 }
 
 // NewService creates a new velocity service.
@@ -33,31 +37,49 @@ func (s *Service) GetTransactionCount(ctx context.Context, tenantID, entityID st
 		return 0, fmt.Errorf("tenantID and entityID are required")
 	}
 
+	until := domain.EvaluationTime(ctx)
+	if live(until) {
+		if count, ok, err := s.countFromCounters(ctx, tenantID, entityID, windowSecs); ok {
+			return count, err
+		}
+	}
+
 	// Query database for actual count (caching would require careful TTL management)
-	since := time.Now().Add(-time.Duration(windowSecs) * time.Second)
+	since := until.Add(-time.Duration(windowSecs) * time.Second)
 
 	if s.db != nil {
-		return s.countFromDB(ctx, tenantID, entityID, since)
+		return s.countFromDB(ctx, tenantID, entityID, since, until)
 	}
 
 	if s.repo != nil {
-		return s.countFromRepo(ctx, tenantID, entityID, since)
+		return s.countFromRepo(ctx, tenantID, entityID, since, until)
 	}
 
 	return 0, fmt.Errorf("no data source available")
 }
 
+// liveTolerance is how far from now an evaluation time may be for the
+// velocity counters and cached aggregates, which cover windows ending now,
+// to serve it.
+const liveTolerance = time.Minute
+
+// live reports whether a window ending at until can be served as ending now.
+func live(until time.Time) bool {
+	d := time.Since(until)
+	return d <= liveTolerance && d >= -liveTolerance
+}
+
 // countFromDB queries the database directly for transaction count.
-func (s *Service) countFromDB(ctx context.Context, tenantID, entityID string, since time.Time) (int64, error) {
+func (s *Service) countFromDB(ctx context.Context, tenantID, entityID string, since, until time.Time) (int64, error) {
 	query := `
 		SELECT COUNT(*) FROM transactions
 		WHERE tenant_id = ?
 		AND (debtor_id = ? OR creditor_id = ?)
-		AND timestamp >= ?
+		AND timestamp >= ? AND timestamp <= ?
 	`
 
 	var count int64
-	err := s.db.QueryRowContext(ctx, query, tenantID, entityID, entityID, since).Scan(&count)
+	err := s.db.QueryRowContext(ctx, query, tenantID, entityID, entityID, since, until).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count transactions: %w", err)
 	}
@@ -66,12 +88,18 @@ func (s *Service) countFromDB(ctx context.Context, tenantID, entityID string, si
 }
 
 // countFromRepo uses the repository to get transactions and count them.
-func (s *Service) countFromRepo(ctx context.Context, tenantID, entityID string, since time.Time) (int64, error) {
+func (s *Service) countFromRepo(ctx context.Context, tenantID, entityID string, since, until time.Time) (int64, error) {
 	txs, err := s.repo.GetTransactionsByEntity(ctx, tenantID, entityID, since)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get transactions: %w", err)
 	}
-	return int64(len(txs)), nil
+	var count int64
+	for _, tx := range txs {
+		if !tx.Timestamp.After(until) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // GetVelocityGetter returns a VelocityGetter function for the rule engine.
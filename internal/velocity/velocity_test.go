@@ -2,7 +2,9 @@ package velocity
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -10,6 +12,8 @@ import (
 	"github.com/opensource-finance/osprey/internal/cache"
 	"github.com/opensource-finance/osprey/internal/domain"
 	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
 )
 
 func TestVelocityService(t *testing.T) {
@@ -62,7 +66,7 @@ func TestVelocityService(t *testing.T) {
 				DebtorAccountID: "acc-001",
 				CreditorID:      "user-002",
 				CreditorAcctID:  "acc-002",
-				Amount:          100.0,
+				Amount:          domain.MustDecimal("100.0"),
 				Currency:        "USD",
 				Timestamp:       time.Now().UTC(),
 				CreatedAt:       time.Now().UTC(),
@@ -150,3 +154,438 @@ func TestNoDataSource(t *testing.T) {
 		t.Error("expected error with no data source")
 	}
 }
+
+func TestBurstRatio(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo, nil)
+	now := time.Now().UTC()
+
+	save := func(debtorID string, ago ...time.Duration) {
+		for i, d := range ago {
+			tx := ospreytest.NewTransaction().
+				ID(fmt.Sprintf("%s-%d", debtorID, i)).
+				Tenant("tenant-001").
+				From(debtorID).
+				At(now.Add(-d)).
+				Build()
+			if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+				t.Fatalf("failed to save transaction: %v", err)
+			}
+		}
+	}
+
+	// One transaction every 10 minutes over the last hour
+	save("steady", 5*time.Minute, 15*time.Minute, 25*time.Minute, 35*time.Minute, 45*time.Minute, 55*time.Minute)
+	// Half of the hour's transactions in the last 10 minutes
+	save("bursty", 1*time.Minute, 2*time.Minute, 3*time.Minute, 4*time.Minute, 5*time.Minute, 6*time.Minute,
+		20*time.Minute, 25*time.Minute, 30*time.Minute, 35*time.Minute, 40*time.Minute, 45*time.Minute)
+	// Activity only outside the long window
+	save("dormant", 2*time.Hour)
+
+	tests := []struct {
+		entityID string
+		want     float64
+	}{
+		{"steady", 1.0},
+		{"bursty", 3.0},
+		{"dormant", 0},
+		{"unknown", 0},
+	}
+	for _, tt := range tests {
+		got, err := svc.BurstRatio(ctx, "tenant-001", tt.entityID, DefaultBurstShortWindow, DefaultBurstLongWindow)
+		if err != nil {
+			t.Fatalf("BurstRatio(%s) failed: %v", tt.entityID, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("BurstRatio(%s) = %v, want %v", tt.entityID, got, tt.want)
+		}
+	}
+
+	t.Run("InvalidWindows", func(t *testing.T) {
+		if _, err := svc.BurstRatio(ctx, "tenant-001", "steady", time.Hour, 10*time.Minute); err == nil {
+			t.Error("expected error when the short window isn't shorter than the long one")
+		}
+	})
+
+	t.Run("Enricher", func(t *testing.T) {
+		engine, err := rules.NewEngine(nil, 5)
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+		if err := engine.RegisterEnricher(svc.BurstEnricher(0, 0)); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		if err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "burst-001",
+			Expression: "velocity_burst_ratio >= 2.0 ? 1.0 : 0.0",
+			Enabled:    true,
+		}); err != nil {
+			t.Fatalf("LoadRule failed: %v", err)
+		}
+
+		for entityID, want := range map[string]float64{"bursty": 1.0, "steady": 0.0} {
+			results, err := engine.EvaluateAll(ctx, &rules.EvaluateInput{TenantID: "tenant-001", TxID: "tx", DebtorID: entityID})
+			if err != nil {
+				t.Fatalf("EvaluateAll failed: %v", err)
+			}
+			if len(results) != 1 || results[0].Score != want {
+				t.Errorf("%s: expected score %v, got %+v", entityID, want, results)
+			}
+		}
+	})
+}
+
+func TestAggregate(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	lruCache := cache.NewLRUCache(100)
+	defer lruCache.Close()
+	svc := NewService(repo, lruCache)
+	now := time.Now().UTC()
+
+	save := func(id, debtorID, creditorID string, amount float64, ago time.Duration) {
+		tx := ospreytest.NewTransaction().
+			ID(id).
+			Tenant("tenant-001").
+			From(debtorID).
+			To(creditorID).
+			Amount(amount, "USD").
+			At(now.Add(-ago)).
+			Build()
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+	}
+
+	save("tx-1", "user-001", "shop-a", 100, 5*time.Minute)
+	save("tx-2", "user-001", "shop-b", 250, 10*time.Minute)
+	save("tx-3", "user-001", "shop-a", 50, 20*time.Minute)
+	save("tx-4", "friend", "user-001", 40, 30*time.Minute)
+	save("tx-5", "user-001", "shop-c", 9000, 2*time.Hour) // outside the window
+
+	t.Run("FromDatabase", func(t *testing.T) {
+		agg, err := svc.Aggregate(ctx, "tenant-001", "user-001", 3600, 0)
+		if err != nil {
+			t.Fatalf("Aggregate failed: %v", err)
+		}
+		if agg.Count != 4 || agg.Sum != 440 || agg.Max != 250 {
+			t.Errorf("expected 4 transactions summing to 440 with max 250, got %+v", agg)
+		}
+		if len(agg.Counterparties) != 3 {
+			t.Errorf("expected 3 distinct counterparties, got %v", agg.Counterparties)
+		}
+		if agg.NetFlow != -360 {
+			t.Errorf("expected a net flow of -360, got %v", agg.NetFlow)
+		}
+
+		if _, err := svc.Aggregate(ctx, "", "user-001", 3600, 0); err == nil {
+			t.Error("expected error for empty tenantID")
+		}
+	})
+
+	t.Run("AsOfTransactionTime", func(t *testing.T) {
+		replay := domain.WithEvaluationTime(ctx, now.Add(-90*time.Minute))
+		agg, err := svc.Aggregate(replay, "tenant-001", "user-001", 3600, time.Minute)
+		if err != nil {
+			t.Fatalf("Aggregate failed: %v", err)
+		}
+		if agg.Count != 1 || agg.Sum != 9000 {
+			t.Errorf("expected only the transaction in the hour before the replayed one, got %+v", agg)
+		}
+		count, err := svc.GetTransactionCount(replay, "tenant-001", "user-001", 3600)
+		if err != nil {
+			t.Fatalf("GetTransactionCount failed: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected a count of 1 as of the replayed transaction, got %d", count)
+		}
+	})
+
+	t.Run("CachedUntilReconciled", func(t *testing.T) {
+		if _, err := svc.Aggregate(ctx, "tenant-001", "user-001", 3600, time.Minute); err != nil {
+			t.Fatalf("Aggregate failed: %v", err)
+		}
+		save("tx-6", "user-001", "shop-d", 500, time.Minute)
+
+		agg, err := svc.Aggregate(ctx, "tenant-001", "user-001", 3600, time.Minute)
+		if err != nil {
+			t.Fatalf("Aggregate failed: %v", err)
+		}
+		if agg.Sum != 440 {
+			t.Errorf("expected the cached sum 440 before reconciliation, got %v", agg.Sum)
+		}
+
+		agg, err = svc.Aggregate(ctx, "tenant-001", "user-001", 3600, 0)
+		if err != nil {
+			t.Fatalf("Aggregate failed: %v", err)
+		}
+		if agg.Sum != 940 || agg.Max != 500 || len(agg.Counterparties) != 4 {
+			t.Errorf("expected the reconciled aggregate to include tx-6, got %+v", agg)
+		}
+	})
+
+	t.Run("Enricher", func(t *testing.T) {
+		engine, err := rules.NewEngine(nil, 5)
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+		if err := engine.RegisterEnricher(svc.AggregateEnricher(domain.VelocityConfig{})); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		if err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "aggregate-001",
+			Expression: "velocity_sum > 1000.0 && velocity_max_amount >= 500.0 && distinct_counterparties >= 5 ? 1.0 : 0.0",
+			Enabled:    true,
+		}); err != nil {
+			t.Fatalf("LoadRule failed: %v", err)
+		}
+
+		evaluate := func(id, creditorID string, amount float64) float64 {
+			save(id, "user-002", creditorID, amount, 0)
+			results, err := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+				TenantID:   "tenant-001",
+				TxID:       id,
+				DebtorID:   "user-002",
+				CreditorID: creditorID,
+				Amount:     domain.DecimalFromFloat(amount),
+			})
+			if err != nil {
+				t.Fatalf("EvaluateAll failed: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			return results[0].Score
+		}
+
+		// The first evaluation reconciles; the rest are added to the cached aggregate
+		for i, amount := range []float64{100, 200, 300, 500} {
+			if score := evaluate(fmt.Sprintf("agg-%d", i), fmt.Sprintf("shop-%d", i), amount); score != 0 {
+				t.Errorf("transaction %d: expected score 0, got %v", i, score)
+			}
+		}
+		if score := evaluate("agg-4", "shop-4", 50); score != 1 {
+			t.Errorf("expected the fifth counterparty to trigger the rule, got %v", score)
+		}
+
+		for _, reconcile := range []time.Duration{time.Minute, 0} {
+			agg, err := svc.Aggregate(ctx, "tenant-001", "user-002", 3600, reconcile)
+			if err != nil {
+				t.Fatalf("Aggregate failed: %v", err)
+			}
+			if agg.Count != 5 || agg.Sum != 1150 || len(agg.Counterparties) != 5 {
+				t.Errorf("reconcile %s: expected 5 transactions summing to 1150, got %+v", reconcile, agg)
+			}
+		}
+	})
+}
+
+func TestAggregateReversals(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo, nil)
+	now := time.Now().UTC()
+
+	save := func(id, debtorID, creditorID string, amount float64, reversalOf string) {
+		tx := ospreytest.NewTransaction().
+			ID(id).
+			Tenant("tenant-001").
+			From(debtorID).
+			To(creditorID).
+			Amount(amount, "USD").
+			At(now.Add(-time.Minute)).
+			Build()
+		tx.ReversalOf = reversalOf
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+	}
+
+	save("sale-1", "cust-1", "merchant", 300, "")
+	save("sale-2", "cust-2", "merchant", 200, "")
+
+	engine, err := rules.NewEngine(nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if err := engine.RegisterEnricher(svc.AggregateEnricher(domain.VelocityConfig{})); err != nil {
+		t.Fatalf("RegisterEnricher failed: %v", err)
+	}
+	if err := engine.LoadRule(&domain.RuleConfig{
+		ID:         "refunds-001",
+		Expression: "has_recent_reversal && velocity_sum == 200.0 && net_flow == 200.0 ? 1.0 : 0.0",
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+
+	// The merchant refunds the first sale in full
+	save("refund-1", "merchant", "cust-1", 300, "sale-1")
+	results, err := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+		TenantID:   "tenant-001",
+		TxID:       "refund-1",
+		DebtorID:   "merchant",
+		CreditorID: "cust-1",
+		Amount:     domain.MustDecimal("300"),
+		ReversalOf: "sale-1",
+	})
+	if err != nil {
+		t.Fatalf("EvaluateAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Score != 1 {
+		t.Errorf("expected the refund to net out of velocity_sum, got %+v", results)
+	}
+
+	agg, err := svc.Aggregate(ctx, "tenant-001", "merchant", 3600, 0)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if agg.Count != 3 || agg.Sum != 200 || agg.Max != 300 || agg.Reversals != 1 {
+		t.Errorf("expected 3 transactions netting to 200 with 1 reversal, got %+v", agg)
+	}
+
+	// The customer's refund lowers their volume as well
+	agg, err = svc.Aggregate(ctx, "tenant-001", "cust-1", 3600, 0)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if agg.Sum != 0 || agg.NetFlow != 0 {
+		t.Errorf("expected the refunded customer to net to zero, got %+v", agg)
+	}
+}
+
+func TestPayments(t *testing.T) {
+	ctx := context.Background()
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo, nil)
+	now := time.Now().UTC()
+
+	save := func(id, debtorID, creditorID string, amount float64, age time.Duration, reversalOf string) {
+		tx := ospreytest.NewTransaction().ID(id).Tenant("tenant-001").From(debtorID).To(creditorID).Amount(amount, "USD").At(now.Add(-age)).Build()
+		tx.ReversalOf = reversalOf
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+	}
+
+	// Three deposits under $3,000 in a day, a large payment received and a refund
+	save("smurf-1", "mule", "bank", 2900, 3*time.Hour, "")
+	save("smurf-2", "mule", "bank", 2950, 2*time.Hour, "")
+	save("smurf-3", "mule", "bank", 2800, time.Hour, "")
+	save("salary", "employer", "mule", 2500, time.Hour, "")
+	save("refund", "mule", "shop", 100, time.Hour, "smurf-1")
+	save("old", "mule", "bank", 2990, 48*time.Hour, "")
+
+	payments, err := svc.Payments(ctx, "tenant-001", "mule", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Payments failed: %v", err)
+	}
+	if len(payments) != 3 {
+		t.Errorf("expected the 3 payments sent in the last day, got %d", len(payments))
+	}
+
+	engine, err := rules.NewEngine(nil, 5)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	engine.SetWindowSource(svc.Payments)
+	if err := engine.LoadRule(&domain.RuleConfig{
+		ID:         "structuring-001",
+		Expression: "window_sum_below(3000, 86400) > 10000.0 && window_count_below(3000, 86400) >= 4",
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("LoadRule failed: %v", err)
+	}
+
+	// The fourth deposit takes the day past $10,000
+	save("smurf-4", "mule", "bank", 2700, 0, "")
+	results, err := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+		TenantID:   "tenant-001",
+		TxID:       "smurf-4",
+		DebtorID:   "mule",
+		CreditorID: "bank",
+		Amount:     domain.MustDecimal("2700"),
+		Timestamp:  now,
+	})
+	if err != nil {
+		t.Fatalf("EvaluateAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Score != 1 {
+		t.Errorf("expected structuring to be detected, got %+v", results)
+	}
+}
+
+func TestParseTenantWindows(t *testing.T) {
+	windows, err := ParseTenantWindows("tenant-a=24h, tenant-b=15m,")
+	if err != nil {
+		t.Fatalf("ParseTenantWindows failed: %v", err)
+	}
+	if len(windows) != 2 || windows["tenant-a"] != 24*time.Hour || windows["tenant-b"] != 15*time.Minute {
+		t.Errorf("unexpected windows: %v", windows)
+	}
+
+	for _, s := range []string{"tenant-a", "=1h", "tenant-a=soon", "tenant-a=0s", "tenant-a=2400h"} {
+		if _, err := ParseTenantWindows(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestCounters(t *testing.T) {
+	ctx := context.Background()
+	base := ospreytest.NewRepository(nil)
+	lruCache := cache.NewLRUCache(1000)
+	defer lruCache.Close()
+	svc := NewService(base, lruCache)
+	svc.EnableCounters(domain.VelocityConfig{DefaultWindow: time.Hour, Reconcile: time.Hour})
+	repo := Wrap(base, svc)
+	now := time.Now().UTC()
+
+	save := func(id, debtorID, creditorID string, ago time.Duration) {
+		t.Helper()
+		tx := ospreytest.NewTransaction().ID(id).Tenant("tenant-001").From(debtorID).To(creditorID).At(now.Add(-ago)).Build()
+		if err := repo.SaveTransaction(ctx, "tenant-001", tx); err != nil {
+			t.Fatalf("failed to save transaction: %v", err)
+		}
+	}
+
+	// Saved behind the wrapper's back, so only reconciliation sees it
+	base.SaveTransaction(ctx, "tenant-001", ospreytest.NewTransaction().ID("tx-cold").Tenant("tenant-001").From("user-001").To("shop-a").At(now.Add(-30*time.Minute)).Build())
+	save("tx-1", "user-001", "shop-a", 10*time.Minute)
+	save("tx-old", "user-001", "shop-a", 2*time.Hour) // before the window
+
+	count, err := svc.GetTransactionCount(ctx, "tenant-001", "user-001", 3600)
+	if err != nil || count != 2 {
+		t.Fatalf("expected the cold read to count 2 from the database, got %d, %v", count, err)
+	}
+	svc.GetTransactionCount(ctx, "tenant-001", "shop-b", 3600)
+
+	// Later saves are served from the counters without the database
+	save("tx-2", "user-001", "shop-b", time.Minute)
+	save("tx-3", "shop-b", "user-001", 0)
+	base.SetError(errors.New("database down"))
+	count, err = svc.GetTransactionCount(ctx, "tenant-001", "user-001", 3600)
+	if err != nil || count != 4 {
+		t.Errorf("expected 4 from the counters, got %d, %v", count, err)
+	}
+	if count, _ := svc.GetTransactionCount(ctx, "tenant-001", "shop-b", 3600); count != 2 {
+		t.Errorf("expected the creditor to be counted too, got %d", count)
+	}
+
+	// Other windows still read the database
+	if _, err := svc.GetTransactionCount(ctx, "tenant-001", "user-001", 60); err == nil {
+		t.Error("expected a window without counters to read the database")
+	}
+	base.SetError(nil)
+
+	// Reconciliation corrects counters that drifted
+	lruCache.Delete(ctx, "tenant-001", reconciledKey("user-001", 3600))
+	lruCache.IncrementCounterBy(ctx, "tenant-001", counterKey("user-001", 3600, now.Unix()/counterBucketSecs(3600)), 5, time.Hour)
+	if count, _ := svc.GetTransactionCount(ctx, "tenant-001", "user-001", 3600); count != 4 {
+		t.Errorf("expected the reconciled count 4, got %d", count)
+	}
+	if count, _ := svc.GetTransactionCount(ctx, "tenant-001", "user-001", 3600); count != 4 {
+		t.Errorf("expected the corrected counters to count 4, got %d", count)
+	}
+}
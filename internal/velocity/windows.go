@@ -0,0 +1,44 @@
+package velocity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// ParseTenantWindows parses per-tenant velocity windows: "tenant=24h,tenant=15m".
+func ParseTenantWindows(s string) (map[string]time.Duration, error) {
+	windows := make(map[string]time.Duration)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, value, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("invalid tenant window %q (want tenant=duration)", entry)
+		}
+		window, err := ParseWindow(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+		windows[tenantID] = window
+	}
+	return windows, nil
+}
+
+// ParseWindow parses a velocity window duration, at least one second and at
+// most domain.MaxVelocityWindow.
+func ParseWindow(s string) (time.Duration, error) {
+	window, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if window < time.Second || window > domain.MaxVelocityWindow {
+		return 0, fmt.Errorf("velocity window %s must be between 1s and %s", window, domain.MaxVelocityWindow)
+	}
+	return window, nil
+}
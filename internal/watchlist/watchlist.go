@@ -0,0 +1,103 @@
+// Package watchlist checks transaction parties against each tenant's
+// sanctions, block and watch lists and exposes the result to rules.
+//
+// Lookups go to the repository on every evaluation rather than through the
+// cache, so an entry takes effect on the next transaction after it is saved.
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+)
+
+// Service matches parties against the watchlists.
+type Service struct {
+	repo domain.Repository
+}
+
+// NewService creates a new watchlist service.
+func NewService(repo domain.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Match returns the tenant's entries naming the subject.
+func (s *Service) Match(ctx context.Context, tenantID string, subject domain.WatchlistSubject) ([]*domain.WatchlistEntry, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("no data source available")
+	}
+
+	entries, err := s.repo.MatchWatchlist(ctx, tenantID, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match watchlist: %w", err)
+	}
+	return entries, nil
+}
+
+// Enricher returns a rules.Enricher exposing debtor_on_watchlist,
+// creditor_on_watchlist, debtor_watchlists and creditor_watchlists to CEL.
+func (s *Service) Enricher() rules.Enricher {
+	return &enricher{svc: s}
+}
+
+type enricher struct {
+	svc *Service
+}
+
+func (e *enricher) Name() string {
+	return "watchlist"
+}
+
+func (e *enricher) Variables() map[string]*cel.Type {
+	return map[string]*cel.Type{
+		"debtor_on_watchlist":   cel.BoolType,
+		"creditor_on_watchlist": cel.BoolType,
+		"debtor_watchlists":     cel.ListType(cel.StringType),
+		"creditor_watchlists":   cel.ListType(cel.StringType),
+	}
+}
+
+func (e *enricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	debtor, debtorErr := e.svc.Match(ctx, input.TenantID, domain.WatchlistSubject{
+		PartyID:   input.DebtorID,
+		AccountID: input.DebtorAccountID,
+		Name:      input.DebtorName,
+		Country:   input.DebtorCountry,
+	})
+	creditor, creditorErr := e.svc.Match(ctx, input.TenantID, domain.WatchlistSubject{
+		PartyID:   input.CreditorID,
+		AccountID: input.CreditorAccountID,
+		Name:      input.CreditorName,
+		Country:   input.CreditorCountry,
+	})
+
+	activation["debtor_on_watchlist"] = len(debtor) > 0
+	activation["creditor_on_watchlist"] = len(creditor) > 0
+	activation["debtor_watchlists"] = ListTypes(debtor)
+	activation["creditor_watchlists"] = ListTypes(creditor)
+
+	return errors.Join(debtorErr, creditorErr)
+}
+
+// ListTypes returns the distinct list types of entries, sorted. It is never
+// nil, so rules can test membership without checking for a value.
+func ListTypes(entries []*domain.WatchlistEntry) []string {
+	seen := make(map[string]bool, len(entries))
+	types := []string{}
+	for _, entry := range entries {
+		if !seen[entry.ListType] {
+			seen[entry.ListType] = true
+			types = append(types, entry.ListType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,125 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/rules"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+func TestWatchlistService(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "tenant-001"
+	repo := ospreytest.NewRepository(nil)
+	svc := NewService(repo)
+
+	for _, entry := range []*domain.WatchlistEntry{
+		{ID: "sdn-1", ListType: domain.WatchlistSanctions, Name: "Ivan  PETROV", Country: "RU"},
+		{ID: "block-1", ListType: domain.WatchlistBlocklist, AccountID: "acct-bad"},
+		{ID: "watch-1", ListType: domain.WatchlistInternal, PartyID: "cust-009"},
+	} {
+		if err := repo.SaveWatchlistEntry(ctx, tenantID, entry); err != nil {
+			t.Fatalf("SaveWatchlistEntry failed: %v", err)
+		}
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			subject domain.WatchlistSubject
+			want    []string
+		}{
+			{"name ignores case and spacing", domain.WatchlistSubject{Name: "ivan petrov", Country: "RU"}, []string{domain.WatchlistSanctions}},
+			{"name without country", domain.WatchlistSubject{Name: "Ivan Petrov"}, []string{domain.WatchlistSanctions}},
+			{"name in another country", domain.WatchlistSubject{Name: "Ivan Petrov", Country: "US"}, []string{}},
+			{"account", domain.WatchlistSubject{PartyID: "cust-001", AccountID: "acct-bad"}, []string{domain.WatchlistBlocklist}},
+			{"party and name", domain.WatchlistSubject{PartyID: "cust-009", Name: "Ivan Petrov"}, []string{domain.WatchlistSanctions, domain.WatchlistInternal}},
+			{"no match", domain.WatchlistSubject{PartyID: "cust-001", AccountID: "acct-001", Name: "Jane Doe"}, []string{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				entries, err := svc.Match(ctx, tenantID, tt.subject)
+				if err != nil {
+					t.Fatalf("Match failed: %v", err)
+				}
+				got := ListTypes(entries)
+				if len(got) != len(tt.want) {
+					t.Fatalf("got lists %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Errorf("got lists %v, want %v", got, tt.want)
+					}
+				}
+			})
+		}
+
+		if entries, _ := svc.Match(ctx, "other-tenant", domain.WatchlistSubject{PartyID: "cust-009"}); len(entries) != 0 {
+			t.Errorf("expected no matches for another tenant, got %d", len(entries))
+		}
+	})
+
+	t.Run("EnricherExposesMatchesToRules", func(t *testing.T) {
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+
+		if err := engine.RegisterEnricher(svc.Enricher()); err != nil {
+			t.Fatalf("RegisterEnricher failed: %v", err)
+		}
+		err := engine.LoadRule(&domain.RuleConfig{
+			ID:         "sanctioned-creditor",
+			Expression: `"sanctions" in creditor_watchlists && !debtor_on_watchlist`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to load rule: %v", err)
+		}
+
+		results, _ := engine.EvaluateAll(ctx, &rules.EvaluateInput{
+			TenantID:        tenantID,
+			TxID:            "tx-watch-001",
+			Type:            "transfer",
+			DebtorID:        "cust-001",
+			CreditorID:      "cust-777",
+			CreditorName:    "IVAN PETROV",
+			CreditorCountry: "RU",
+			Amount:          domain.MustDecimal("500.0"),
+			Currency:        "USD",
+		})
+		if results[0].SubRuleRef == domain.RuleOutcomeError {
+			t.Fatalf("unexpected evaluation error: %s", results[0].Reason)
+		}
+		if results[0].Score != 1.0 {
+			t.Errorf("expected rule to match the sanctioned creditor, got score %.2f", results[0].Score)
+		}
+	})
+
+	t.Run("LookupFailureDegrades", func(t *testing.T) {
+		failing := ospreytest.NewRepository(nil)
+		failing.SetError(errors.New("database unavailable"))
+
+		engine, _ := rules.NewEngine(nil, 5)
+		defer engine.Close()
+		engine.RegisterEnricher(NewService(failing).Enricher())
+		engine.LoadRule(&domain.RuleConfig{
+			ID:         "any-watchlist",
+			Expression: `debtor_on_watchlist || size(creditor_watchlists) > 0`,
+			Weight:     1.0,
+			Enabled:    true,
+		})
+
+		dctx, degradations := domain.WithDegradations(ctx)
+		results, _ := engine.EvaluateAll(dctx, &rules.EvaluateInput{TenantID: tenantID, TxID: "tx-watch-002", DebtorID: "cust-009"})
+		if results[0].SubRuleRef == domain.RuleOutcomeError || results[0].Score != 0 {
+			t.Errorf("expected no match on lookup failure, got %+v", results[0])
+		}
+		got := degradations.List()
+		if len(got) != 1 || got[0].Component != "enricher:watchlist" {
+			t.Errorf("expected a watchlist enricher degradation, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// digestTopEntities bounds the entities listed in a digest.
+const digestTopEntities = 10
+
+// AnyTenant is the digest entry for tenants without their own.
+const AnyTenant = "*"
+
+// digest returns the tenant's digest policy, falling back to AnyTenant.
+func (r *Repository) digest(tenantID string) (domain.WebhookDigest, bool) {
+	if d, ok := r.digests[tenantID]; ok {
+		return d, d.Interval > 0
+	}
+	d, ok := r.digests[AnyTenant]
+	return d, ok && d.Interval > 0
+}
+
+// digestEntry keeps what a digest reports about an alert. The entities are
+// looked up from the stored transaction, if there is one.
+func (r *Repository) digestEntry(ctx context.Context, tenantID string, eval *domain.Evaluation) *domain.DigestEntry {
+	entry := &domain.DigestEntry{EvaluationID: eval.ID, TxID: eval.TxID, Score: eval.Score}
+	for _, result := range eval.RuleResults {
+		if !result.Shadow && (result.SubRuleRef == domain.RuleOutcomeFail || result.SubRuleRef == domain.RuleOutcomeReview) {
+			entry.Rules = append(entry.Rules, result.RuleID)
+		}
+	}
+	for _, result := range eval.TypologyResults {
+		if result.Triggered {
+			entry.Typologies = append(entry.Typologies, result.TypologyID)
+		}
+	}
+	if tx, err := r.Repository.GetTransaction(ctx, tenantID, eval.TxID); err == nil {
+		for _, id := range []string{tx.DebtorID, tx.CreditorID} {
+			if id != "" && !slices.Contains(entry.Entities, id) {
+				entry.Entities = append(entry.Entities, id)
+			}
+		}
+	}
+	return entry
+}
+
+// summarize counts a digest's alerts by rule, typology and entity.
+func summarize(batch []*domain.WebhookDelivery) *domain.WebhookDigestSummary {
+	summary := &domain.WebhookDigestSummary{
+		TenantID:    batch[0].TenantID,
+		From:        batch[0].CreatedAt,
+		To:          batch[0].CreatedAt,
+		Evaluations: make([]string, 0, len(batch)),
+	}
+	rules, typologies, entities := make(map[string]int), make(map[string]int), make(map[string]int)
+	for _, delivery := range batch {
+		var entry domain.DigestEntry
+		if err := json.Unmarshal(delivery.Payload, &entry); err != nil {
+			slog.Error("invalid digest entry", "tenant_id", delivery.TenantID, "delivery_id", delivery.ID, "error", err)
+			continue
+		}
+		summary.Alerts++
+		summary.Evaluations = append(summary.Evaluations, entry.EvaluationID)
+		if delivery.CreatedAt.Before(summary.From) {
+			summary.From = delivery.CreatedAt
+		}
+		if delivery.CreatedAt.After(summary.To) {
+			summary.To = delivery.CreatedAt
+		}
+		for _, id := range entry.Rules {
+			rules[id]++
+		}
+		for _, id := range entry.Typologies {
+			typologies[id]++
+		}
+		for _, id := range entry.Entities {
+			entities[id]++
+		}
+	}
+	summary.ByRule = digestCounts(rules, 0)
+	summary.ByTypology = digestCounts(typologies, 0)
+	summary.TopEntities = digestCounts(entities, digestTopEntities)
+	return summary
+}
+
+// digestCounts returns counts, most frequent first, keeping at most limit
+// (0 keeps all).
+func digestCounts(counts map[string]int, limit int) []domain.DigestCount {
+	out := make([]domain.DigestCount, 0, len(counts))
+	for id, n := range counts {
+		out = append(out, domain.DigestCount{ID: id, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].ID < out[j].ID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// ParseDigests parses per-tenant digest policies:
+// "tenant=1h:typology-001|typology-002,*=24h". Without typologies every
+// alert of the tenant is digested.
+func ParseDigests(s string) (map[string]domain.WebhookDigest, error) {
+	digests := make(map[string]domain.WebhookDigest)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenantID, value, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("invalid digest %q (want tenant=interval:typology|typology)", entry)
+		}
+		interval, types, _ := strings.Cut(value, ":")
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("tenant %s: invalid digest interval %q", tenantID, interval)
+		}
+		digest := domain.WebhookDigest{Interval: d}
+		for _, t := range strings.Split(types, "|") {
+			if t = strings.TrimSpace(t); t != "" {
+				digest.Typologies = append(digest.Typologies, t)
+			}
+		}
+		digests[tenantID] = digest
+	}
+	return digests, nil
+}
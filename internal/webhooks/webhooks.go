@@ -0,0 +1,406 @@
+// Package webhooks delivers evaluations to tenant-configured URLs.
+//
+// Every saved ALRT evaluation is queued as one delivery per webhook of its
+// tenant; webhooks on the decisions feed get every evaluation that passes
+// their filter. A tenant's digest policy holds back low-urgency alerts and
+// sends them as one summary per interval instead. The dispatcher POSTs the evaluation JSON, signed with the
+// webhook's secret and, when a signing key is configured, the deployment's
+// JWS key, and retries failures with exponential backoff up to
+// MaxAttempts. Decisions due together are sent in batches. Deliveries are
+// stored, so retries survive restarts and every attempt's outcome is kept in
+// the delivery log.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+	"github.com/opensource-finance/osprey/internal/signing"
+)
+
+// dispatchBatch bounds how many due deliveries are sent per check.
+const dispatchBatch = 100
+
+// Request headers sent with every delivery.
+const (
+	HeaderSignature = "X-Osprey-Signature"
+	HeaderEvent     = "X-Osprey-Event"
+	HeaderDelivery  = "X-Osprey-Delivery"
+)
+
+// Sign returns the X-Osprey-Signature value for a request body sent at
+// timestamp: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+// Receivers recompute it with the webhook secret and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Repository queues webhook deliveries for the evaluations it saves.
+type Repository struct {
+	domain.Repository
+	notify  func()
+	digests map[string]domain.WebhookDigest
+}
+
+// Wrap returns repo with webhook queueing enabled. notify, if set, is called
+// after deliveries are queued so they can be sent right away. digests holds
+// each tenant's digest policy, if any.
+func Wrap(repo domain.Repository, notify func(), digests map[string]domain.WebhookDigest) *Repository {
+	return &Repository{Repository: repo, notify: notify, digests: digests}
+}
+
+// Unwrap returns the repository whose evaluations queue deliveries.
+func (r *Repository) Unwrap() domain.Repository {
+	return r.Repository
+}
+
+// SaveEvaluation saves the evaluation and queues a delivery for each of the
+// tenant's webhooks that wants it: alert webhooks get ALRT evaluations,
+// decisions webhooks get every evaluation, both subject to their filter.
+// Alerts suppressed by a maintenance window only go to decisions webhooks.
+// Alerts are sent right away, unless the tenant's digest covers them: those
+// wait for the end of the digest interval. Decisions wait for the next
+// dispatch, so the ones due together go out in one request.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	if err := r.Repository.SaveEvaluation(ctx, tenantID, eval); err != nil {
+		return err
+	}
+
+	webhooks, err := r.Repository.ListWebhooks(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var payload, entry []byte
+	alerted := false
+	now := time.Now().UTC()
+	digest, digested := r.digest(tenantID)
+	digested = digested && eval.Status == domain.StatusAlert && digest.Covers(eval)
+	for _, webhook := range webhooks {
+		event := domain.WebhookEventAlert
+		if webhook.Feed == domain.WebhookFeedDecisions {
+			event = domain.WebhookEventDecision
+		} else if eval.Status != domain.StatusAlert || eval.Metadata.SuppressedByMaintenance != "" {
+			continue
+		}
+		if !webhook.Filter.Matches(eval) {
+			continue
+		}
+
+		body, due := &payload, now
+		if event == domain.WebhookEventAlert && digested {
+			event, body, due = domain.WebhookEventDigest, &entry, now.Truncate(digest.Interval).Add(digest.Interval)
+		}
+		if *body == nil {
+			var v any = eval
+			if event == domain.WebhookEventDigest {
+				v = r.digestEntry(ctx, tenantID, eval)
+			}
+			if *body, err = json.Marshal(v); err != nil {
+				return fmt.Errorf("failed to encode webhook payload: %w", err)
+			}
+		}
+		delivery := &domain.WebhookDelivery{
+			ID:            uuid.New().String(),
+			WebhookID:     webhook.ID,
+			EvaluationID:  eval.ID,
+			Event:         event,
+			Payload:       *body,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: due,
+			CreatedAt:     now,
+		}
+		if err := r.Repository.SaveWebhookDelivery(ctx, tenantID, delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+		alerted = alerted || event == domain.WebhookEventAlert
+	}
+
+	if alerted && r.notify != nil {
+		r.notify()
+	}
+	return nil
+}
+
+// Dispatcher sends queued deliveries and schedules retries.
+type Dispatcher struct {
+	repo    domain.Repository
+	policy  domain.WebhookConfig
+	client  *http.Client
+	trigger chan struct{}
+	signer  *signing.Signer
+	now     func() time.Time
+}
+
+// NewDispatcher creates a dispatcher with the given retry policy.
+func NewDispatcher(repo domain.Repository, policy domain.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		repo:    repo,
+		policy:  policy,
+		client:  &http.Client{Timeout: policy.Timeout},
+		trigger: make(chan struct{}, 1),
+		now:     time.Now,
+	}
+}
+
+// SetSigner adds a detached JWS of every request body, verifiable with the
+// deployment's JWKS, next to the per-webhook HMAC signature.
+func (d *Dispatcher) SetSigner(s *signing.Signer) {
+	d.signer = s
+}
+
+// Trigger requests a dispatch from Run without waiting for it.
+func (d *Dispatcher) Trigger() {
+	select {
+	case d.trigger <- struct{}{}:
+	default: // a dispatch is already pending
+	}
+}
+
+// Run sends due deliveries every CheckInterval, and whenever triggered,
+// until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	interval := d.policy.CheckInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.trigger:
+		}
+		// Keep going while full pages come back, so a busy decisions feed
+		// doesn't fall behind by a page per interval
+		for {
+			n, err := d.Dispatch(ctx)
+			if err != nil {
+				slog.Error("webhook dispatch failed", "error", err)
+			}
+			if err != nil || n < dispatchBatch || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// Dispatch sends every due delivery once and returns how many were attempted.
+// A webhook's due decisions are sent together, up to its batch size per
+// request.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	due, err := d.repo.ListDueWebhookDeliveries(ctx, d.now(), dispatchBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due deliveries: %w", err)
+	}
+
+	attempted := 0
+	for _, group := range groupDeliveries(due) {
+		webhook, lookupErr := d.repo.GetWebhook(ctx, group[0].TenantID, group[0].WebhookID)
+		size := 1
+		if lookupErr == nil {
+			switch group[0].Event {
+			case domain.WebhookEventDecision:
+				size = batchSize(webhook)
+			case domain.WebhookEventDigest:
+				size = len(group)
+			}
+		}
+
+		for start := 0; start < len(group); start += size {
+			batch := d.claim(ctx, group[start:min(start+size, len(group))])
+			if len(batch) == 0 {
+				continue
+			}
+			attempted += len(batch)
+
+			d.attempt(ctx, webhook, lookupErr, batch)
+			for _, delivery := range batch {
+				if err := d.repo.SaveWebhookDelivery(ctx, delivery.TenantID, delivery); err != nil {
+					slog.Error("failed to record webhook delivery", "tenant_id", delivery.TenantID, "delivery_id", delivery.ID, "error", err)
+				}
+			}
+		}
+	}
+	return attempted, nil
+}
+
+// groupDeliveries groups due decisions and digest entries by webhook, in
+// order of their first delivery. Every alert is its own group.
+func groupDeliveries(due []*domain.WebhookDelivery) [][]*domain.WebhookDelivery {
+	type key struct{ tenantID, webhookID, event string }
+	var groups [][]*domain.WebhookDelivery
+	batched := make(map[key]int)
+	for _, delivery := range due {
+		if delivery.Event == domain.WebhookEventAlert {
+			groups = append(groups, []*domain.WebhookDelivery{delivery})
+			continue
+		}
+		k := key{delivery.TenantID, delivery.WebhookID, delivery.Event}
+		if i, ok := batched[k]; ok {
+			groups[i] = append(groups[i], delivery)
+			continue
+		}
+		batched[k] = len(groups)
+		groups = append(groups, []*domain.WebhookDelivery{delivery})
+	}
+	return groups
+}
+
+// batchSize returns how many decisions a webhook takes per request.
+func batchSize(webhook *domain.Webhook) int {
+	if webhook.BatchSize <= 0 {
+		return domain.DefaultWebhookBatchSize
+	}
+	return min(webhook.BatchSize, domain.MaxWebhookBatchSize)
+}
+
+// claim holds deliveries while they are sent and returns those it got;
+// another instance may have some already.
+func (d *Dispatcher) claim(ctx context.Context, deliveries []*domain.WebhookDelivery) []*domain.WebhookDelivery {
+	lease := d.now().Add(d.policy.Timeout + time.Minute)
+	var claimed []*domain.WebhookDelivery
+	for _, delivery := range deliveries {
+		if err := d.repo.ClaimWebhookDelivery(ctx, delivery.TenantID, delivery.ID, delivery.Attempts, lease); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				slog.Error("failed to claim webhook delivery", "tenant_id", delivery.TenantID, "delivery_id", delivery.ID, "error", err)
+			}
+			continue
+		}
+		delivery.Attempts++
+		claimed = append(claimed, delivery)
+	}
+	return claimed
+}
+
+// attempt sends claimed deliveries to their webhook in one request and
+// updates their status for the next save. lookupErr is the error from
+// loading the webhook.
+func (d *Dispatcher) attempt(ctx context.Context, webhook *domain.Webhook, lookupErr error, batch []*domain.WebhookDelivery) {
+	if errors.Is(lookupErr, repository.ErrNotFound) {
+		for _, delivery := range batch {
+			delivery.Status = domain.DeliveryFailed
+			delivery.LastError = "webhook deleted"
+		}
+		return
+	}
+	err := lookupErr
+	statusCode := 0
+	if err == nil {
+		statusCode, err = d.send(ctx, webhook, batch)
+	}
+
+	now := d.now().UTC()
+	for _, delivery := range batch {
+		delivery.LastStatusCode = statusCode
+		if err == nil {
+			delivery.Status = domain.DeliveryDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			continue
+		}
+
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.policy.MaxAttempts {
+			delivery.Status = domain.DeliveryFailed
+			slog.Warn("webhook delivery failed",
+				"tenant_id", delivery.TenantID,
+				"webhook_id", delivery.WebhookID,
+				"delivery_id", delivery.ID,
+				"attempts", delivery.Attempts,
+				"error", err,
+			)
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	}
+}
+
+// send POSTs the deliveries and returns the response status code. An alert
+// is sent as the evaluation JSON; decisions are sent as
+// {"decisions": [evaluation, ...]} and digest entries as
+// {"digest": summary}, with every delivery ID in the delivery header.
+func (d *Dispatcher) send(ctx context.Context, webhook *domain.Webhook, batch []*domain.WebhookDelivery) (int, error) {
+	body := batch[0].Payload
+	ids := make([]string, len(batch))
+	for i, delivery := range batch {
+		ids[i] = delivery.ID
+	}
+	var err error
+	switch batch[0].Event {
+	case domain.WebhookEventDecision:
+		decisions := make([]json.RawMessage, len(batch))
+		for i, delivery := range batch {
+			decisions[i] = delivery.Payload
+		}
+		body, err = json.Marshal(map[string]any{"decisions": decisions})
+	case domain.WebhookEventDigest:
+		body, err = json.Marshal(map[string]any{"digest": summarize(batch)})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, batch[0].Event)
+	req.Header.Set(HeaderDelivery, strings.Join(ids, ","))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, d.now().Unix(), body))
+	if d.signer != nil {
+		jws, err := d.signer.Sign(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to sign webhook body: %w", err)
+		}
+		req.Header.Set(signing.HeaderSignature, jws)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait after the given number of failed attempts:
+// InitialBackoff doubled for each attempt after the first, capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.policy.InitialBackoff
+	for i := 1; i < attempts && (d.policy.MaxBackoff <= 0 || wait < d.policy.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if d.policy.MaxBackoff > 0 && wait > d.policy.MaxBackoff {
+		wait = d.policy.MaxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,417 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/signing"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
+)
+
+const tenantID = "tenant-001"
+
+// receiver is a webhook endpoint that answers with the queued status codes,
+// then 200, and records what it received.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, string(body))
+	rc.headers = append(rc.headers, r.Header.Clone())
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) calls() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.bodies)
+}
+
+// setup registers a webhook for rc and saves one ALRT and one NALT evaluation.
+func setup(t *testing.T, rc *receiver, policy domain.WebhookConfig) (*Dispatcher, *ospreytest.Clock, domain.Repository) {
+	t.Helper()
+	ctx := context.Background()
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	base := ospreytest.NewRepository(nil)
+	if err := base.SaveWebhook(ctx, tenantID, &domain.Webhook{
+		ID:     "webhook-1",
+		URL:    server.URL,
+		Secret: "s3cret",
+	}); err != nil {
+		t.Fatalf("SaveWebhook failed: %v", err)
+	}
+
+	notified := 0
+	repo := Wrap(base, func() { notified++ }, nil)
+	for _, eval := range []*domain.Evaluation{
+		{ID: "eval-alert", TxID: "tx-1", Status: domain.StatusAlert, Score: 0.9},
+		{ID: "eval-pass", TxID: "tx-2", Status: domain.StatusNoAlert, Score: 0.1},
+	} {
+		if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	if notified != 1 {
+		t.Fatalf("expected one notification, got %d", notified)
+	}
+
+	clock := ospreytest.NewClock(time.Now())
+	d := NewDispatcher(repo, policy)
+	d.now = clock.Now
+	return d, clock, repo
+}
+
+func deliveries(t *testing.T, repo domain.Repository) []*domain.WebhookDelivery {
+	t.Helper()
+	list, err := repo.ListWebhookDeliveries(context.Background(), tenantID, "webhook-1", 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries failed: %v", err)
+	}
+	return list
+}
+
+func TestDispatch(t *testing.T) {
+	ctx := context.Background()
+	policy := domain.WebhookConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     15 * time.Second,
+		Timeout:        5 * time.Second,
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		rc := &receiver{}
+		d, clock, repo := setup(t, rc, policy)
+
+		if n, err := d.Dispatch(ctx); err != nil || n != 1 {
+			t.Fatalf("Dispatch = %d, %v; want 1 attempt", n, err)
+		}
+		if rc.calls() != 1 {
+			t.Fatalf("expected 1 request, got %d", rc.calls())
+		}
+		body, header := rc.bodies[0], rc.headers[0]
+		if !strings.Contains(body, `"eval-alert"`) {
+			t.Errorf("expected ALRT evaluation in payload, got %s", body)
+		}
+		if header.Get(HeaderEvent) != domain.WebhookEventAlert {
+			t.Errorf("unexpected event header %q", header.Get(HeaderEvent))
+		}
+		want := Sign("s3cret", clock.Now().Unix(), []byte(body))
+		if header.Get(HeaderSignature) != want {
+			t.Errorf("signature = %q, want %q", header.Get(HeaderSignature), want)
+		}
+		if !strings.HasPrefix(want, "t="+strconv.FormatInt(clock.Now().Unix(), 10)+",v1=") {
+			t.Errorf("unexpected signature format %q", want)
+		}
+
+		list := deliveries(t, repo)
+		if len(list) != 1 || list[0].Status != domain.DeliveryDelivered || list[0].DeliveredAt == nil {
+			t.Fatalf("expected a delivered delivery, got %+v", list)
+		}
+		if header.Get(HeaderDelivery) != list[0].ID {
+			t.Errorf("delivery header = %q, want %q", header.Get(HeaderDelivery), list[0].ID)
+		}
+
+		// Nothing is sent twice
+		if n, _ := d.Dispatch(ctx); n != 0 {
+			t.Errorf("expected no attempts after delivery, got %d", n)
+		}
+		if header.Get(signing.HeaderSignature) != "" {
+			t.Errorf("expected no JWS without a signing key, got %q", header.Get(signing.HeaderSignature))
+		}
+	})
+
+	t.Run("JWS", func(t *testing.T) {
+		_, key, _ := ed25519.GenerateKey(nil)
+		signer, err := signing.NewSigner(key)
+		if err != nil {
+			t.Fatalf("NewSigner failed: %v", err)
+		}
+		rc := &receiver{}
+		d, _, _ := setup(t, rc, policy)
+		d.SetSigner(signer)
+
+		if n, err := d.Dispatch(ctx); err != nil || n != 1 {
+			t.Fatalf("Dispatch = %d, %v; want 1 attempt", n, err)
+		}
+		header, err := signing.Verify(signer.Public(), rc.headers[0].Get(signing.HeaderSignature), []byte(rc.bodies[0]))
+		if err != nil {
+			t.Fatalf("expected a JWS of the body, got %v", err)
+		}
+		if header.Kid != signer.KeyID() {
+			t.Errorf("kid = %q, want %q", header.Kid, signer.KeyID())
+		}
+	})
+
+	t.Run("RetriesWithBackoff", func(t *testing.T) {
+		rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+		d, clock, repo := setup(t, rc, policy)
+
+		d.Dispatch(ctx)
+		list := deliveries(t, repo)
+		if list[0].Status != domain.DeliveryPending || list[0].Attempts != 1 || list[0].LastStatusCode != 500 {
+			t.Fatalf("expected a pending retry after a 500, got %+v", list[0])
+		}
+		if got := list[0].NextAttemptAt.Sub(clock.Now()); got != 10*time.Second {
+			t.Errorf("first backoff = %v, want 10s", got)
+		}
+
+		// Not due until the backoff has passed
+		if n, _ := d.Dispatch(ctx); n != 0 {
+			t.Errorf("expected no attempts before the backoff, got %d", n)
+		}
+		clock.Advance(10 * time.Second)
+		d.Dispatch(ctx)
+		list = deliveries(t, repo)
+		if got := list[0].NextAttemptAt.Sub(clock.Now()); got != 15*time.Second {
+			t.Errorf("second backoff = %v, want the 15s cap", got)
+		}
+
+		clock.Advance(15 * time.Second)
+		d.Dispatch(ctx)
+		list = deliveries(t, repo)
+		if list[0].Status != domain.DeliveryDelivered || list[0].Attempts != 3 || list[0].LastError != "" {
+			t.Errorf("expected delivery on the third attempt, got %+v", list[0])
+		}
+	})
+
+	t.Run("FailsAfterMaxAttempts", func(t *testing.T) {
+		rc := &receiver{statuses: []int{500, 500, 500, 500}}
+		d, clock, repo := setup(t, rc, policy)
+
+		for i := 0; i < 5; i++ {
+			d.Dispatch(ctx)
+			clock.Advance(time.Minute)
+		}
+		if rc.calls() != 3 {
+			t.Errorf("expected 3 attempts, got %d", rc.calls())
+		}
+		list := deliveries(t, repo)
+		if list[0].Status != domain.DeliveryFailed || list[0].LastError == "" {
+			t.Errorf("expected a failed delivery, got %+v", list[0])
+		}
+	})
+
+	t.Run("DeletedWebhook", func(t *testing.T) {
+		rc := &receiver{}
+		d, _, repo := setup(t, rc, policy)
+		if err := repo.DeleteWebhook(ctx, tenantID, "webhook-1"); err != nil {
+			t.Fatalf("DeleteWebhook failed: %v", err)
+		}
+
+		d.Dispatch(ctx)
+		if rc.calls() != 0 {
+			t.Errorf("expected no requests to a deleted webhook, got %d", rc.calls())
+		}
+		list := deliveries(t, repo)
+		if list[0].Status != domain.DeliveryFailed || list[0].LastError != "webhook deleted" {
+			t.Errorf("expected a failed delivery, got %+v", list[0])
+		}
+	})
+}
+
+func TestDecisionsFeed(t *testing.T) {
+	ctx := context.Background()
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	base := ospreytest.NewRepository(nil)
+	minScore := 0.2
+	for _, webhook := range []*domain.Webhook{
+		{ID: "decisions", URL: server.URL, Secret: "s3cret", Feed: domain.WebhookFeedDecisions, BatchSize: 2},
+		{ID: "filtered", URL: server.URL, Secret: "s3cret", Feed: domain.WebhookFeedDecisions,
+			Filter: &domain.WebhookFilter{Statuses: []string{domain.StatusNoAlert}, MinScore: &minScore}},
+	} {
+		if err := base.SaveWebhook(ctx, tenantID, webhook); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+	}
+
+	notified := 0
+	repo := Wrap(base, func() { notified++ }, nil)
+	for i, score := range []float64{0.1, 0.3, 0.9} {
+		status := domain.StatusNoAlert
+		if score > 0.5 {
+			status = domain.StatusAlert
+		}
+		eval := &domain.Evaluation{ID: "eval-" + strconv.Itoa(i), TxID: "tx", Status: status, Score: score}
+		if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	if notified != 0 {
+		t.Errorf("decisions should wait for the next dispatch, got %d notifications", notified)
+	}
+
+	d := NewDispatcher(repo, domain.WebhookConfig{MaxAttempts: 3, Timeout: 5 * time.Second})
+	if n, err := d.Dispatch(ctx); err != nil || n != 4 {
+		t.Fatalf("Dispatch = %d, %v; want 4 attempts", n, err)
+	}
+
+	// Three decisions in batches of two, plus the one NALT above 0.2
+	if rc.calls() != 3 {
+		t.Fatalf("expected 3 requests, got %d", rc.calls())
+	}
+	var filtered []string
+	for i, body := range rc.bodies {
+		if rc.headers[i].Get(HeaderEvent) != domain.WebhookEventDecision {
+			t.Errorf("unexpected event header %q", rc.headers[i].Get(HeaderEvent))
+		}
+		if !strings.HasPrefix(body, `{"decisions":[`) {
+			t.Errorf("expected a decisions batch, got %s", body)
+		}
+		if ids := strings.Split(rc.headers[i].Get(HeaderDelivery), ","); len(ids) == 1 {
+			filtered = append(filtered, body)
+		}
+	}
+	if len(filtered) != 2 {
+		t.Fatalf("expected a batch of one for the filtered webhook and the last batch, got %d", len(filtered))
+	}
+
+	list, err := repo.ListWebhookDeliveries(ctx, tenantID, "filtered", 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries failed: %v", err)
+	}
+	if len(list) != 1 || list[0].EvaluationID != "eval-1" || list[0].Status != domain.DeliveryDelivered {
+		t.Errorf("expected only eval-1 delivered to the filtered webhook, got %+v", list)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, domain.WebhookConfig{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		5:  10 * time.Second,
+		50: 10 * time.Second,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestDigest(t *testing.T) {
+	ctx := context.Background()
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	base := ospreytest.NewRepository(nil)
+	if err := base.SaveWebhook(ctx, tenantID, &domain.Webhook{ID: "webhook-1", URL: server.URL, Secret: "s3cret"}); err != nil {
+		t.Fatalf("SaveWebhook failed: %v", err)
+	}
+	for _, tx := range []*domain.Transaction{
+		{ID: "tx-1", DebtorID: "debtor-a", CreditorID: "creditor-x"},
+		{ID: "tx-2", DebtorID: "debtor-a", CreditorID: "creditor-y"},
+	} {
+		if err := base.SaveTransaction(ctx, tenantID, tx); err != nil {
+			t.Fatalf("SaveTransaction failed: %v", err)
+		}
+	}
+
+	notified := 0
+	digests := map[string]domain.WebhookDigest{tenantID: {Interval: time.Hour, Typologies: []string{"low"}}}
+	repo := Wrap(base, func() { notified++ }, digests)
+	alert := func(id, txID string, typologies ...string) *domain.Evaluation {
+		eval := &domain.Evaluation{ID: id, TxID: txID, Status: domain.StatusAlert, Score: 0.9,
+			RuleResults: []domain.RuleResult{{RuleID: "rule-001", SubRuleRef: domain.RuleOutcomeFail}}}
+		for _, typology := range typologies {
+			eval.TypologyResults = append(eval.TypologyResults, domain.TypologyResult{TypologyID: typology, Triggered: true})
+		}
+		return eval
+	}
+	for _, eval := range []*domain.Evaluation{
+		alert("eval-1", "tx-1", "low"),
+		alert("eval-2", "tx-2", "low"),
+		alert("eval-3", "tx-3", "low", "high"),
+	} {
+		if err := repo.SaveEvaluation(ctx, tenantID, eval); err != nil {
+			t.Fatalf("SaveEvaluation failed: %v", err)
+		}
+	}
+	if notified != 1 {
+		t.Errorf("expected a notification for the urgent alert only, got %d", notified)
+	}
+
+	clock := ospreytest.NewClock(time.Now())
+	d := NewDispatcher(repo, domain.WebhookConfig{MaxAttempts: 3, Timeout: 5 * time.Second})
+	d.now = clock.Now
+	if n, _ := d.Dispatch(ctx); n != 1 || rc.calls() != 1 {
+		t.Fatalf("expected only the urgent alert before the digest is due, got %d attempts", n)
+	}
+	if rc.headers[0].Get(HeaderEvent) != domain.WebhookEventAlert || !strings.Contains(rc.bodies[0], `"eval-3"`) {
+		t.Errorf("expected eval-3 sent right away, got %s", rc.bodies[0])
+	}
+
+	clock.Advance(time.Hour)
+	if n, _ := d.Dispatch(ctx); n != 2 || rc.calls() != 2 {
+		t.Fatalf("expected one digest of 2 alerts, got %d attempts and %d requests", n, rc.calls())
+	}
+	if rc.headers[1].Get(HeaderEvent) != domain.WebhookEventDigest {
+		t.Errorf("unexpected event header %q", rc.headers[1].Get(HeaderEvent))
+	}
+	var body struct {
+		Digest domain.WebhookDigestSummary `json:"digest"`
+	}
+	if err := json.Unmarshal([]byte(rc.bodies[1]), &body); err != nil {
+		t.Fatalf("invalid digest body: %v", err)
+	}
+	summary := body.Digest
+	if summary.Alerts != 2 || len(summary.Evaluations) != 2 || summary.TenantID != tenantID {
+		t.Errorf("unexpected digest: %+v", summary)
+	}
+	if len(summary.ByRule) != 1 || summary.ByRule[0] != (domain.DigestCount{ID: "rule-001", Count: 2}) {
+		t.Errorf("unexpected rule counts: %+v", summary.ByRule)
+	}
+	if len(summary.ByTypology) != 1 || summary.ByTypology[0].Count != 2 {
+		t.Errorf("unexpected typology counts: %+v", summary.ByTypology)
+	}
+	if len(summary.TopEntities) != 3 || summary.TopEntities[0] != (domain.DigestCount{ID: "debtor-a", Count: 2}) {
+		t.Errorf("unexpected top entities: %+v", summary.TopEntities)
+	}
+}
+
+func TestParseDigests(t *testing.T) {
+	digests, err := ParseDigests("acme=1h:low|medium, *=24h")
+	if err != nil {
+		t.Fatalf("ParseDigests failed: %v", err)
+	}
+	if d := digests["acme"]; d.Interval != time.Hour || len(d.Typologies) != 2 || d.Typologies[1] != "medium" {
+		t.Errorf("unexpected acme digest: %+v", d)
+	}
+	if d := digests[AnyTenant]; d.Interval != 24*time.Hour || d.Typologies != nil {
+		t.Errorf("unexpected default digest: %+v", d)
+	}
+
+	for _, s := range []string{"acme", "=1h", "acme=soon", "acme=0s"} {
+		if _, err := ParseDigests(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
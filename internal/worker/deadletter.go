@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// poisonError marks a failure retrying can't fix, such as a malformed
+// payload. Poison messages are dead-lettered on their first attempt.
+type poisonError struct {
+	err error
+}
+
+func (e *poisonError) Error() string { return e.err.Error() }
+func (e *poisonError) Unwrap() error { return e.err }
+
+// isPoison reports whether err is a poisonError.
+func isPoison(err error) bool {
+	var p *poisonError
+	return errors.As(err, &p)
+}
+
+// handle processes a message, retrying and then dead-lettering it if it
+// keeps failing. It returns an error only if the message couldn't be
+// dead-lettered either, so the bus may redeliver it.
+func (w *Worker) handle(ctx context.Context, tenantID string, msg *domain.Message) error {
+	attempts, err := w.process(ctx, tenantID, msg)
+	w.record(tenantID, msg, err)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the message to the bus
+		return err
+	}
+	return w.deadLetter(ctx, tenantID, msg, attempts, err)
+}
+
+// process runs processTransaction until it succeeds, fails with a poison
+// error or has been retried Retries times. The delay between attempts
+// starts at RetryBackoff and doubles after each retry.
+func (w *Worker) process(ctx context.Context, tenantID string, msg *domain.Message) (int, error) {
+	backoff := w.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := w.processTransaction(ctx, tenantID, msg)
+		if err == nil || isPoison(err) || attempt > w.retries {
+			return attempt, err
+		}
+
+		slog.Warn("transaction failed, retrying",
+			"message_id", msg.ID,
+			"tenant_id", tenantID,
+			"attempt", attempt,
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		backoff *= 2
+	}
+}
+
+// deadLetter stores a message the worker gave up on and publishes it to the
+// tenant's dead-letter topic.
+func (w *Worker) deadLetter(ctx context.Context, tenantID string, msg *domain.Message, attempts int, cause error) error {
+	if tenantID == AllTenants || tenantID == "" {
+		tenantID = msg.TenantID
+	}
+	topic := msg.Topic
+	if topic == "" {
+		topic = domain.TopicTransactionIngested
+	}
+
+	dead := &domain.DeadLetter{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Topic:     topic,
+		MessageID: msg.ID,
+		Payload:   string(msg.Payload),
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		Poison:    isPoison(cause),
+		FailedAt:  w.now().UTC(),
+	}
+
+	if w.repo != nil {
+		if err := w.repo.SaveDeadLetter(ctx, tenantID, dead); err != nil {
+			slog.Error("failed to store dead letter",
+				"message_id", msg.ID,
+				"tenant_id", tenantID,
+				"error", err,
+			)
+			return cause
+		}
+	}
+
+	data, _ := json.Marshal(dead)
+	if err := w.bus.Publish(ctx, tenantID, domain.TopicDeadLetter, data); err != nil {
+		slog.Error("failed to publish dead letter",
+			"message_id", msg.ID,
+			"tenant_id", tenantID,
+			"error", err,
+		)
+	}
+
+	slog.Error("transaction dead-lettered",
+		"message_id", msg.ID,
+		"tenant_id", tenantID,
+		"dead_letter_id", dead.ID,
+		"attempts", attempts,
+		"poison", dead.Poison,
+		"error", cause,
+	)
+	return nil
+}
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// TenantLag reports how far async evaluation is behind for one tenant.
+type TenantLag struct {
+	TenantID        string     `json:"tenantId"`
+	Processed       int64      `json:"processed"`
+	Failed          int64      `json:"failed"`
+	Backlog         int        `json:"backlog"`  // messages received but not yet handled
+	LagMs           int64      `json:"lagMs"`    // age of the last message when it was handled
+	MaxLagMs        int64      `json:"maxLagMs"` // highest LagMs since start
+	LastProcessedAt *time.Time `json:"lastProcessedAt,omitempty"`
+	Lagging         bool       `json:"lagging"`
+}
+
+// QueueStatus reports ingested-vs-processed lag for every tenant the worker
+// has seen or subscribed to.
+type QueueStatus struct {
+	Lagging bool         `json:"lagging"`
+	Tenants []TenantLag  `json:"tenants"`
+	Lanes   []LaneStatus `json:"lanes,omitempty"`
+}
+
+// Subscription statuses reported by SubscriptionStatus.
+const (
+	SubscriptionTenant = "subscribed"   // the tenant has its own subscription
+	SubscriptionGlobal = "global"       // the global subscription covers every tenant
+	SubscriptionNone   = "unsubscribed" // the worker doesn't receive the tenant's messages
+)
+
+// SubscriptionStatus reports whether the worker receives a tenant's ingested
+// transactions. It is safe on a nil Worker.
+func (w *Worker) SubscriptionStatus(tenantID string) string {
+	if w == nil {
+		return SubscriptionNone
+	}
+
+	w.lagMu.Lock()
+	defer w.lagMu.Unlock()
+	switch {
+	case len(w.tenantSubs[tenantID]) > 0:
+		return SubscriptionTenant
+	case len(w.tenantSubs[AllTenants]) > 0:
+		return SubscriptionGlobal
+	default:
+		return SubscriptionNone
+	}
+}
+
+// Subscriptions returns how many bus subscriptions the worker holds, 0
+// before Start and after Stop. It is safe on a nil Worker.
+func (w *Worker) Subscriptions() int {
+	if w == nil {
+		return 0
+	}
+
+	w.lagMu.Lock()
+	defer w.lagMu.Unlock()
+	n := 0
+	for _, subs := range w.tenantSubs {
+		n += len(subs)
+	}
+	return n
+}
+
+// record updates the tenant's lag after a message has been handled. The lag
+// is measured from the message's publish timestamp.
+func (w *Worker) record(tenantID string, msg *domain.Message, err error) {
+	now := w.now()
+
+	w.lagMu.Lock()
+	defer w.lagMu.Unlock()
+
+	lag := w.lag[tenantID]
+	if lag == nil {
+		lag = &TenantLag{TenantID: tenantID}
+		w.lag[tenantID] = lag
+	}
+	if err != nil {
+		lag.Failed++
+	} else {
+		lag.Processed++
+	}
+	if msg.Timestamp > 0 {
+		lag.LagMs = max(now.Sub(time.Unix(0, msg.Timestamp)).Milliseconds(), 0)
+		lag.MaxLagMs = max(lag.MaxLagMs, lag.LagMs)
+	}
+	lag.LastProcessedAt = &now
+}
+
+// QueueStatus returns the current lag per tenant, sorted by tenant ID. A
+// tenant is lagging when its backlog reaches MaxBacklog, or when its last
+// message was older than MaxLag and the queue hasn't been idle since. It is
+// safe on a nil Worker.
+func (w *Worker) QueueStatus() QueueStatus {
+	status := QueueStatus{Tenants: []TenantLag{}}
+	if w == nil {
+		return status
+	}
+	now := w.now()
+
+	w.lagMu.Lock()
+	defer w.lagMu.Unlock()
+
+	tenants := map[string]TenantLag{}
+	for tenantID, lag := range w.lag {
+		tenants[tenantID] = *lag
+	}
+	for tenantID, subs := range w.tenantSubs {
+		lag := tenants[tenantID]
+		lag.TenantID = tenantID
+		for _, sub := range subs {
+			if counter, ok := sub.(domain.PendingCounter); ok {
+				lag.Backlog += counter.Pending()
+			}
+		}
+		tenants[tenantID] = lag
+	}
+
+	for _, lag := range tenants {
+		if w.maxBacklog > 0 && lag.Backlog >= w.maxBacklog {
+			lag.Lagging = true
+		}
+		if w.maxLag > 0 && lag.LagMs > w.maxLag.Milliseconds() {
+			recent := lag.LastProcessedAt != nil && now.Sub(*lag.LastProcessedAt) < w.maxLag
+			lag.Lagging = lag.Lagging || lag.Backlog > 0 || recent
+		}
+		status.Lagging = status.Lagging || lag.Lagging
+		status.Tenants = append(status.Tenants, lag)
+	}
+	sort.Slice(status.Tenants, func(i, j int) bool {
+		return status.Tenants[i].TenantID < status.Tenants[j].TenantID
+	})
+	status.Lanes = w.laneStatus()
+	return status
+}
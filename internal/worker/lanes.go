@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Lanes lists the priority lanes in the order they are reported.
+var Lanes = []string{domain.LaneRealtime, domain.LaneBatch}
+
+// LaneStatus reports a priority lane's capacity and load.
+type LaneStatus struct {
+	Lane     string `json:"lane"`
+	Capacity int    `json:"capacity"`
+	Busy     int64  `json:"busy"`    // evaluations in progress
+	Backlog  int    `json:"backlog"` // messages waiting on the lane topic
+	Routed   int64  `json:"routed"`  // messages classified into the lane
+}
+
+// lane is a priority lane with its own topic subscriptions and worker
+// capacity.
+type lane struct {
+	name     string
+	capacity int
+	slots    chan struct{} // nil with capacity 1: messages are handled inline
+	busy     atomic.Int64
+	routed   atomic.Int64
+}
+
+func newLanes(cfg domain.LaneConfig) map[string]*lane {
+	lanes := make(map[string]*lane, len(Lanes))
+	for _, name := range Lanes {
+		l := &lane{name: name, capacity: max(cfg.Capacity[name], 1)}
+		if l.capacity > 1 {
+			l.slots = make(chan struct{}, l.capacity)
+		}
+		lanes[name] = l
+	}
+	return lanes
+}
+
+// ParseLanes parses lane capacities such as "realtime=8,batch=2".
+func ParseLanes(s string) (map[string]int, error) {
+	capacity := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid lane %q (want lane=capacity)", pair)
+		}
+		name = strings.TrimSpace(name)
+		if !slices.Contains(Lanes, name) {
+			return nil, fmt.Errorf("unknown lane %q (want %s)", name, strings.Join(Lanes, " or "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid capacity for lane %q: must be a positive integer", name)
+		}
+		capacity[name] = n
+	}
+	return capacity, nil
+}
+
+// Classify returns the lane for a transaction. An explicit priority wins;
+// otherwise high-value amounts are realtime, batch rail types are batch and
+// everything else is realtime.
+func Classify(cfg domain.LaneConfig, msg *TransactionMessage) string {
+	if slices.Contains(Lanes, msg.Priority) {
+		return msg.Priority
+	}
+	if cfg.HighValueAmount > 0 && msg.Amount.Cmp(domain.DecimalFromFloat(cfg.HighValueAmount)) >= 0 {
+		return domain.LaneRealtime
+	}
+	if slices.Contains(cfg.BatchTypes, msg.Type) {
+		return domain.LaneBatch
+	}
+	return domain.LaneRealtime
+}
+
+// route classifies ingested messages and republishes them to their lane
+// topic under the same tenant.
+func (w *Worker) route(tenantID string) domain.MessageHandler {
+	return func(ctx context.Context, msg *domain.Message) error {
+		var txMsg TransactionMessage
+		if err := json.Unmarshal(msg.Payload, &txMsg); err != nil {
+			err = &poisonError{err}
+			w.record(tenantID, msg, err)
+			return w.deadLetter(ctx, tenantID, msg, 1, err)
+		}
+		l := w.lanes[Classify(w.lanesCfg, &txMsg)]
+		l.routed.Add(1)
+		return w.bus.Publish(ctx, tenantID, domain.LaneTopic(l.name), msg.Payload)
+	}
+}
+
+// handle runs handler within the lane's capacity. With capacity 1 the
+// message is handled inline; otherwise it waits for a free slot and is
+// handled in its own goroutine, so only this lane's subscription backs up
+// when the lane is saturated. A bus that acknowledges messages is told the
+// result once the goroutine finishes, so a message isn't acknowledged
+// before it is evaluated.
+func (l *lane) handle(w *Worker, handler domain.MessageHandler) domain.MessageHandler {
+	return func(ctx context.Context, msg *domain.Message) error {
+		if l.slots == nil {
+			l.busy.Add(1)
+			defer l.busy.Add(-1)
+			return handler(ctx, msg)
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		settle, deferred := domain.DeferredAck(ctx)
+		l.busy.Add(1)
+		w.wg.Add(1)
+		go func() {
+			defer func() {
+				l.busy.Add(-1)
+				<-l.slots
+				w.wg.Done()
+			}()
+			// Failures are logged and recorded by the handler
+			err := handler(ctx, msg)
+			if deferred {
+				settle(err)
+			}
+		}()
+		if deferred {
+			return domain.ErrAckDeferred
+		}
+		return nil
+	}
+}
+
+// laneStatus reports every lane; callers hold lagMu.
+func (w *Worker) laneStatus() []LaneStatus {
+	if w.lanes == nil {
+		return nil
+	}
+	backlog := map[string]int{}
+	for _, subs := range w.tenantSubs {
+		for _, sub := range subs {
+			counter, ok := sub.(domain.PendingCounter)
+			if !ok {
+				continue
+			}
+			for name := range w.lanes {
+				if sub.Topic() == domain.LaneTopic(name) {
+					backlog[name] += counter.Pending()
+				}
+			}
+		}
+	}
+
+	status := make([]LaneStatus, 0, len(w.lanes))
+	for _, l := range w.lanes {
+		status = append(status, LaneStatus{
+			Lane:     l.name,
+			Capacity: l.capacity,
+			Busy:     l.busy.Load(),
+			Backlog:  backlog[l.name],
+			Routed:   l.routed.Load(),
+		})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return slices.Index(Lanes, status[i].Lane) < slices.Index(Lanes, status[j].Lane)
+	})
+	return status
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/opensource-finance/osprey/internal/domain"
 	"github.com/opensource-finance/osprey/internal/rules"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/internal/txtypes"
 )
 
 // Worker processes transactions asynchronously from the EventBus.
@@ -22,11 +23,29 @@ type Worker struct {
 	typologyEngine *rules.TypologyEngine
 	processor      *tadp.Processor
 	mode           domain.EvaluationMode // detection or compliance
+	txTypes        *txtypes.Policy
 
 	subscriptions []domain.Subscription
 	wg            sync.WaitGroup
-	ctx           context.Context
-	cancel        context.CancelFunc
+
+	// Lag tracking, reported by QueueStatus
+	lagMu      sync.Mutex
+	lag        map[string]*TenantLag
+	tenantSubs map[string][]domain.Subscription
+	maxLag     time.Duration
+	maxBacklog int
+	now        func() time.Time
+
+	// Priority lanes; nil when lanes are disabled
+	lanes    map[string]*lane
+	lanesCfg domain.LaneConfig
+
+	// Retries of failed evaluations before they are dead-lettered
+	retries      int
+	retryBackoff time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // Config holds worker configuration.
@@ -36,6 +55,24 @@ type Config struct {
 
 	// WorkerCount is the number of concurrent workers per tenant
 	WorkerCount int
+
+	// MaxLag and MaxBacklog are the thresholds at which QueueStatus reports
+	// a tenant as lagging (zero disables the check)
+	MaxLag     time.Duration
+	MaxBacklog int
+
+	// Lanes routes transactions to priority lanes with their own capacity
+	Lanes domain.LaneConfig
+
+	// TxTypes restricts the transaction types tenants may evaluate (nil
+	// allows every type)
+	TxTypes *txtypes.Policy
+
+	// Retries is how many times a failed evaluation is retried before the
+	// message is dead-lettered; RetryBackoff is the delay before the first
+	// retry, doubled after each
+	Retries      int
+	RetryBackoff time.Duration
 }
 
 // NewWorker creates a new async worker.
@@ -48,6 +85,9 @@ func NewWorker(bus domain.EventBus, repo domain.Repository, engine *rules.Engine
 		typologyEngine: typologyEngine,
 		processor:      processor,
 		mode:           mode,
+		lag:            make(map[string]*TenantLag),
+		tenantSubs:     make(map[string][]domain.Subscription),
+		now:            time.Now,
 		ctx:            ctx,
 		cancel:         cancel,
 	}
@@ -55,6 +95,25 @@ func NewWorker(bus domain.EventBus, repo domain.Repository, engine *rules.Engine
 
 // Start begins processing messages for the given tenants.
 func (w *Worker) Start(cfg Config) error {
+	w.txTypes = cfg.TxTypes
+	w.retries = cfg.Retries
+	w.retryBackoff = cfg.RetryBackoff
+
+	w.lagMu.Lock()
+	w.maxLag = cfg.MaxLag
+	w.maxBacklog = cfg.MaxBacklog
+	if len(cfg.Lanes.Capacity) > 0 {
+		w.lanes = newLanes(cfg.Lanes)
+		w.lanesCfg = cfg.Lanes
+	}
+	w.lagMu.Unlock()
+	if w.lanes != nil {
+		slog.Info("priority lanes enabled",
+			"realtime_capacity", w.lanes[domain.LaneRealtime].capacity,
+			"batch_capacity", w.lanes[domain.LaneBatch].capacity,
+		)
+	}
+
 	if len(cfg.TenantIDs) == 0 {
 		return w.startGlobalWorker()
 	}
@@ -76,15 +135,17 @@ func (w *Worker) Start(cfg Config) error {
 	return nil
 }
 
+// AllTenants is the subscription tenant of the worker that processes every
+// tenant; its queue status is reported under this ID.
+const AllTenants = "_global"
+
 // startGlobalWorker starts a worker that processes all tenants (for testing/dev).
 func (w *Worker) startGlobalWorker() error {
 	// Subscribe using a special "global" tenant ID
 	// In production, you'd want to subscribe with wildcards or JetStream
-	sub, err := w.bus.Subscribe(w.ctx, "_global", domain.TopicTransactionIngested, w.handleMessage)
-	if err != nil {
+	if err := w.subscribe(AllTenants, w.handleMessage); err != nil {
 		return err
 	}
-	w.subscriptions = append(w.subscriptions, sub)
 
 	slog.Info("global worker started")
 	return nil
@@ -93,13 +154,12 @@ func (w *Worker) startGlobalWorker() error {
 // startTenantWorker starts workers for a specific tenant.
 func (w *Worker) startTenantWorker(tenantID string) error {
 	// Subscribe to transaction ingested topic
-	sub, err := w.bus.Subscribe(w.ctx, tenantID, domain.TopicTransactionIngested, func(ctx context.Context, msg *domain.Message) error {
-		return w.processTransaction(ctx, tenantID, msg)
+	err := w.subscribe(tenantID, func(ctx context.Context, msg *domain.Message) error {
+		return w.handle(ctx, tenantID, msg)
 	})
 	if err != nil {
 		return err
 	}
-	w.subscriptions = append(w.subscriptions, sub)
 
 	slog.Info("tenant worker started",
 		"tenant_id", tenantID,
@@ -111,42 +171,110 @@ func (w *Worker) startTenantWorker(tenantID string) error {
 
 // handleMessage handles messages from global subscription.
 func (w *Worker) handleMessage(ctx context.Context, msg *domain.Message) error {
-	return w.processTransaction(ctx, msg.TenantID, msg)
+	return w.handle(ctx, msg.TenantID, msg)
+}
+
+// subscribe subscribes handler to the tenant's ingest topic. With lanes, the
+// ingest topic is routed to the lane topics and handler runs on each lane
+// within its capacity.
+func (w *Worker) subscribe(tenantID string, handler domain.MessageHandler) error {
+	if w.lanes == nil {
+		return w.subscribeTopic(tenantID, domain.TopicTransactionIngested, handler)
+	}
+
+	for _, name := range Lanes {
+		l := w.lanes[name]
+		if err := w.subscribeTopic(tenantID, domain.LaneTopic(name), l.handle(w, handler)); err != nil {
+			return err
+		}
+	}
+	return w.subscribeTopic(tenantID, domain.TopicTransactionIngested, w.route(tenantID))
+}
+
+func (w *Worker) subscribeTopic(tenantID, topic string, handler domain.MessageHandler) error {
+	sub, err := w.bus.Subscribe(w.ctx, tenantID, topic, handler)
+	if err != nil {
+		return err
+	}
+	w.subscriptions = append(w.subscriptions, sub)
+	w.trackSubscription(tenantID, sub)
+	return nil
+}
+
+// trackSubscription reports the subscription's backlog under tenantID.
+func (w *Worker) trackSubscription(tenantID string, sub domain.Subscription) {
+	w.lagMu.Lock()
+	defer w.lagMu.Unlock()
+	w.tenantSubs[tenantID] = append(w.tenantSubs[tenantID], sub)
 }
 
 // TransactionMessage is the message payload for transaction processing.
 type TransactionMessage struct {
-	TxID           string         `json:"txId"`
-	TenantID       string         `json:"tenantId"`
-	TraceID        string         `json:"traceId"`
-	Type           string         `json:"type"`
-	DebtorID       string         `json:"debtorId"`
-	CreditorID     string         `json:"creditorId"`
-	Amount         float64        `json:"amount"`
-	Currency       string         `json:"currency"`
-	VelocityWindow int            `json:"velocityWindow,omitempty"`
-	AdditionalData map[string]any `json:"additionalData,omitempty"`
+	TxID              string                   `json:"txId"`
+	TenantID          string                   `json:"tenantId"`
+	TraceID           string                   `json:"traceId"`
+	Type              string                   `json:"type"`
+	DebtorID          string                   `json:"debtorId"`
+	CreditorID        string                   `json:"creditorId"`
+	DebtorAccountID   string                   `json:"debtorAccountId,omitempty"`
+	CreditorAccountID string                   `json:"creditorAccountId,omitempty"`
+	DebtorName        string                   `json:"debtorName,omitempty"`
+	CreditorName      string                   `json:"creditorName,omitempty"`
+	DebtorCountry     string                   `json:"debtorCountry,omitempty"`
+	CreditorCountry   string                   `json:"creditorCountry,omitempty"`
+	Amount            domain.Decimal           `json:"amount"`
+	Currency          string                   `json:"currency"`
+	Timestamp         time.Time                `json:"timestamp,omitempty"` // zero on messages queued before it was sent
+	Components        *domain.AmountComponents `json:"components,omitempty"`
+	VelocityWindow    int                      `json:"velocityWindow,omitempty"`
+	ReversalOf        string                   `json:"reversalOf,omitempty"`
+	PartOfBatch       string                   `json:"partOfBatch,omitempty"`
+	RelatedTo         []string                 `json:"relatedTo,omitempty"`
+	AdditionalData    map[string]any           `json:"additionalData,omitempty"`
+	Priority          string                   `json:"priority,omitempty"` // realtime or batch lane
+	Request           *domain.RequestContext   `json:"request,omitempty"`  // the API request that queued it, if any
+}
+
+// Transaction returns the message as the transaction it carries.
+func (m *TransactionMessage) Transaction(tenantID string) *domain.Transaction {
+	return &domain.Transaction{
+		ID:              m.TxID,
+		TenantID:        tenantID,
+		Type:            m.Type,
+		DebtorID:        m.DebtorID,
+		DebtorAccountID: m.DebtorAccountID,
+		CreditorID:      m.CreditorID,
+		CreditorAcctID:  m.CreditorAccountID,
+		Amount:          m.Amount,
+		Currency:        m.Currency,
+		Components:      m.Components,
+		ReversalOf:      m.ReversalOf,
+		PartOfBatch:     m.PartOfBatch,
+		RelatedTo:       m.RelatedTo,
+		Timestamp:       m.Timestamp,
+		Metadata:        m.AdditionalData,
+	}
 }
 
 // processTransaction evaluates a transaction through the pipeline.
 func (w *Worker) processTransaction(ctx context.Context, tenantID string, msg *domain.Message) error {
 	start := time.Now()
 
-	if w.mode == domain.ModeCompliance && (w.typologyEngine == nil || w.typologyEngine.TypologyCount() == 0) {
-		err := fmt.Errorf("compliance mode requires typologies to be loaded")
-		slog.Error("skipping transaction in compliance mode",
+	// Parse message
+	var txMsg TransactionMessage
+	if err := json.Unmarshal(msg.Payload, &txMsg); err != nil {
+		slog.Error("failed to parse transaction message",
 			"message_id", msg.ID,
-			"tenant_id", tenantID,
 			"error", err,
 		)
-		return err
+		return &poisonError{err}
 	}
 
-	// Parse message
-	var txMsg TransactionMessage
-	if err := json.Unmarshal(msg.Payload, &txMsg); err != nil {
-		slog.Error("failed to parse transaction message",
+	if w.mode == domain.ModeCompliance && (w.typologyEngine == nil || w.typologyEngine.TypologyCount() == 0) {
+		err := fmt.Errorf("compliance mode requires typologies to be loaded")
+		slog.Error("skipping transaction in compliance mode",
 			"message_id", msg.ID,
+			"tenant_id", tenantID,
 			"error", err,
 		)
 		return err
@@ -162,6 +290,17 @@ func (w *Worker) processTransaction(ctx context.Context, tenantID string, msg *d
 		traceID = msg.ID
 	}
 
+	unknownType := !w.txTypes.Check(tenantID, txMsg.Type)
+	if unknownType && w.txTypes.Action() == domain.TxTypeReject {
+		err := fmt.Errorf("transaction type %q is not allowed", txMsg.Type)
+		slog.Warn("rejecting transaction",
+			"tx_id", txMsg.TxID,
+			"tenant_id", tenantID,
+			"error", err,
+		)
+		return &poisonError{err}
+	}
+
 	slog.Debug("processing transaction",
 		"tx_id", txMsg.TxID,
 		"tenant_id", tenantID,
@@ -170,21 +309,29 @@ func (w *Worker) processTransaction(ctx context.Context, tenantID string, msg *d
 
 	// 1. Evaluate rules
 	evalInput := &rules.EvaluateInput{
-		TenantID:       tenantID,
-		TxID:           txMsg.TxID,
-		Type:           txMsg.Type,
-		DebtorID:       txMsg.DebtorID,
-		CreditorID:     txMsg.CreditorID,
-		Amount:         txMsg.Amount,
-		Currency:       txMsg.Currency,
-		VelocityWindow: txMsg.VelocityWindow,
-		AdditionalData: txMsg.AdditionalData,
-	}
-
-	if evalInput.VelocityWindow == 0 {
-		evalInput.VelocityWindow = 3600 // Default 1 hour
+		TenantID:          tenantID,
+		TxID:              txMsg.TxID,
+		Type:              txMsg.Type,
+		DebtorID:          txMsg.DebtorID,
+		CreditorID:        txMsg.CreditorID,
+		DebtorAccountID:   txMsg.DebtorAccountID,
+		CreditorAccountID: txMsg.CreditorAccountID,
+		DebtorName:        txMsg.DebtorName,
+		CreditorName:      txMsg.CreditorName,
+		DebtorCountry:     txMsg.DebtorCountry,
+		CreditorCountry:   txMsg.CreditorCountry,
+		Amount:            txMsg.Amount,
+		Currency:          txMsg.Currency,
+		Timestamp:         txMsg.Timestamp,
+		Components:        txMsg.Components,
+		VelocityWindow:    txMsg.VelocityWindow,
+		ReversalOf:        txMsg.ReversalOf,
+		AdditionalData:    txMsg.AdditionalData,
+		Request:           txMsg.Request,
 	}
 
+	ctx, degradations := domain.WithDegradations(ctx)
+	ctx, velocity := domain.WithVelocitySnapshots(ctx)
 	ruleResults, err := w.engine.EvaluateAll(ctx, evalInput)
 	if err != nil {
 		slog.Error("rule evaluation failed",
@@ -197,7 +344,7 @@ func (w *Worker) processTransaction(ctx context.Context, tenantID string, msg *d
 	// 2. Evaluate typologies ONLY in Compliance mode
 	var typologyResults []domain.TypologyResult
 	if w.mode == domain.ModeCompliance && w.typologyEngine != nil && w.typologyEngine.TypologyCount() > 0 {
-		typologyResults = w.typologyEngine.EvaluateTypologies(ruleResults)
+		typologyResults = w.typologyEngine.EvaluateTypologies(tenantID, ruleResults)
 	}
 
 	// 3. Process decision
@@ -208,6 +355,11 @@ func (w *Worker) processTransaction(ctx context.Context, tenantID string, msg *d
 		RuleResults:     ruleResults,
 		TypologyResults: typologyResults,
 		StartTime:       start,
+		Request:         txMsg.Request,
+		Degradations:    degradations.List(),
+		Velocity:        velocity.List(),
+		UnknownTxType:   unknownType,
+		Transaction:     txMsg.Transaction(tenantID),
 	}
 
 	evaluation := w.processor.Process(ctx, decisionInput)
@@ -267,6 +419,10 @@ func (w *Worker) Stop() error {
 	}
 	w.subscriptions = nil
 
+	w.lagMu.Lock()
+	clear(w.tenantSubs)
+	w.lagMu.Unlock()
+
 	w.wg.Wait()
 
 	slog.Info("workers stopped")
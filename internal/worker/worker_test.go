@@ -3,14 +3,18 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/cel-go/cel"
 	"github.com/opensource-finance/osprey/internal/bus"
 	"github.com/opensource-finance/osprey/internal/domain"
 	"github.com/opensource-finance/osprey/internal/rules"
 	"github.com/opensource-finance/osprey/internal/tadp"
+	"github.com/opensource-finance/osprey/pkg/ospreytest"
 )
 
 func TestWorker(t *testing.T) {
@@ -96,9 +100,6 @@ func TestWorker(t *testing.T) {
 			return nil
 		})
 
-		// Allow subscriptions to be active
-		time.Sleep(50 * time.Millisecond)
-
 		// Publish a transaction
 		txMsg := TransactionMessage{
 			TxID:       "tx-001",
@@ -107,7 +108,7 @@ func TestWorker(t *testing.T) {
 			Type:       "transfer",
 			DebtorID:   "debtor-001",
 			CreditorID: "creditor-001",
-			Amount:     500.0,
+			Amount:     domain.MustDecimal("500.0"),
 			Currency:   "USD",
 		}
 
@@ -117,8 +118,8 @@ func TestWorker(t *testing.T) {
 			t.Fatalf("Publish failed: %v", err)
 		}
 
-		// Wait for processing
-		time.Sleep(100 * time.Millisecond)
+		// Wait for processing, including the decision it publishes
+		flushBus(t, eventBus)
 
 		if !decisionReceived.Load() {
 			t.Error("expected decision to be published")
@@ -165,8 +166,6 @@ func TestWorker(t *testing.T) {
 			return nil
 		})
 
-		time.Sleep(50 * time.Millisecond)
-
 		// Publish a high-risk transaction (same debtor/creditor triggers rule)
 		txMsg := TransactionMessage{
 			TxID:       "tx-alert",
@@ -174,14 +173,13 @@ func TestWorker(t *testing.T) {
 			Type:       "transfer",
 			DebtorID:   "same-user", // Same as creditor
 			CreditorID: "same-user",
-			Amount:     100.0,
+			Amount:     domain.MustDecimal("100.0"),
 			Currency:   "USD",
 		}
 
 		payload, _ := json.Marshal(txMsg)
 		eventBus.Publish(context.Background(), "tenant-alert", domain.TopicTransactionIngested, payload)
-
-		time.Sleep(100 * time.Millisecond)
+		flushBus(t, eventBus)
 
 		if !alertReceived.Load() {
 			t.Error("expected alert to be published for high-risk transaction")
@@ -212,7 +210,7 @@ func TestTransactionMessageParsing(t *testing.T) {
 		Type:           "transfer",
 		DebtorID:       "debtor-001",
 		CreditorID:     "creditor-001",
-		Amount:         1234.56,
+		Amount:         domain.MustDecimal("1234.56"),
 		Currency:       "USD",
 		VelocityWindow: 7200,
 		AdditionalData: map[string]any{"key": "value"},
@@ -233,7 +231,7 @@ func TestTransactionMessageParsing(t *testing.T) {
 		t.Errorf("expected TxID '%s', got '%s'", msg.TxID, parsed.TxID)
 	}
 	if parsed.Amount != msg.Amount {
-		t.Errorf("expected Amount %.2f, got %.2f", msg.Amount, parsed.Amount)
+		t.Errorf("expected Amount %s, got %s", msg.Amount, parsed.Amount)
 	}
 	if parsed.VelocityWindow != msg.VelocityWindow {
 		t.Errorf("expected VelocityWindow %d, got %d", msg.VelocityWindow, parsed.VelocityWindow)
@@ -266,7 +264,7 @@ func TestProcessTransaction_ComplianceModeRequiresTypologies(t *testing.T) {
 		Type:       "transfer",
 		DebtorID:   "debtor-001",
 		CreditorID: "creditor-001",
-		Amount:     100,
+		Amount:     domain.MustDecimal("100"),
 		Currency:   "USD",
 	})
 
@@ -280,3 +278,368 @@ func TestProcessTransaction_ComplianceModeRequiresTypologies(t *testing.T) {
 		t.Fatal("expected error when compliance mode has no typologies")
 	}
 }
+
+func TestWorkerWithFakes(t *testing.T) {
+	eventBus := ospreytest.NewBus(nil)
+	repo := ospreytest.NewRepository(nil)
+
+	engine, _ := rules.NewEngine(nil, 2)
+	engine.LoadRules([]*domain.RuleConfig{
+		{
+			ID:         "same-party-check",
+			Name:       "Same Party Check",
+			Expression: "debtor_id == creditor_id",
+			Weight:     1.0,
+			Enabled:    true,
+		},
+	})
+
+	w := NewWorker(eventBus, repo, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	if err := w.Start(Config{TenantIDs: []string{"tenant-001"}}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	tx := ospreytest.NewTransaction().Tenant("tenant-001").From("same-user").To("same-user")
+	payload, _ := json.Marshal(tx.Message())
+
+	// The fake bus delivers synchronously, so results are visible on return
+	if err := eventBus.Publish(context.Background(), "tenant-001", domain.TopicTransactionIngested, payload); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if got := len(eventBus.Published("tenant-001", domain.TopicDecision)); got != 1 {
+		t.Errorf("expected 1 decision, got %d", got)
+	}
+
+	evaluations := repo.Evaluations("tenant-001")
+	if len(evaluations) != 1 || evaluations[0].TxID != tx.Build().ID {
+		t.Fatalf("expected saved evaluation for %s, got %+v", tx.Build().ID, evaluations)
+	}
+}
+
+// flushBus waits for the channel bus to deliver everything published so far.
+func flushBus(t *testing.T, eventBus *bus.ChannelBus) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := eventBus.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+}
+
+// pendingSubscription is a subscription with a fixed backlog.
+type pendingSubscription struct {
+	domain.Subscription
+	pending int
+}
+
+func (s pendingSubscription) Pending() int { return s.pending }
+
+func TestQueueStatus(t *testing.T) {
+	clock := ospreytest.NewClock(ospreytest.Epoch)
+	eventBus := ospreytest.NewBus(clock)
+	engine, _ := rules.NewEngine(nil, 2)
+
+	w := NewWorker(eventBus, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	w.now = clock.Now
+	if err := w.Start(Config{TenantIDs: []string{"tenant-001"}, MaxLag: time.Second, MaxBacklog: 10}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	payload, _ := json.Marshal(ospreytest.NewTransaction().Tenant("tenant-001").Message())
+	if err := eventBus.Publish(context.Background(), "tenant-001", domain.TopicTransactionIngested, payload); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	status := w.QueueStatus()
+	if status.Lagging || len(status.Tenants) != 1 || status.Tenants[0].Processed != 1 {
+		t.Fatalf("expected one processed message and no lag, got %+v", status)
+	}
+
+	t.Run("old message is lagging", func(t *testing.T) {
+		w.record("tenant-001", &domain.Message{Timestamp: clock.Now().Add(-5 * time.Second).UnixNano()}, nil)
+		status := w.QueueStatus()
+		lag := status.Tenants[0]
+		if !status.Lagging || !lag.Lagging || lag.LagMs != 5000 || lag.MaxLagMs != 5000 || lag.Processed != 2 {
+			t.Errorf("expected tenant to be lagging by 5s, got %+v", lag)
+		}
+	})
+
+	t.Run("idle queue is not lagging", func(t *testing.T) {
+		clock.Advance(time.Minute)
+		if status := w.QueueStatus(); status.Lagging {
+			t.Errorf("expected idle queue not to be lagging, got %+v", status)
+		}
+	})
+
+	t.Run("backlog", func(t *testing.T) {
+		w.trackSubscription("tenant-002", pendingSubscription{pending: 10})
+		status := w.QueueStatus()
+		if len(status.Tenants) != 2 {
+			t.Fatalf("expected 2 tenants, got %+v", status.Tenants)
+		}
+		lag := status.Tenants[1]
+		if lag.TenantID != "tenant-002" || lag.Backlog != 10 || !lag.Lagging || !status.Lagging {
+			t.Errorf("expected tenant-002 to be lagging on backlog, got %+v", lag)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		w.record("tenant-001", &domain.Message{}, context.Canceled)
+		if lag := w.QueueStatus().Tenants[0]; lag.Failed != 1 || lag.Processed != 2 {
+			t.Errorf("expected one failure, got %+v", lag)
+		}
+	})
+}
+
+func TestSubscriptionStatus(t *testing.T) {
+	eventBus := ospreytest.NewBus(nil)
+	engine, _ := rules.NewEngine(nil, 2)
+
+	var nilWorker *Worker
+	if got := nilWorker.SubscriptionStatus("tenant-001"); got != SubscriptionNone {
+		t.Errorf("expected %q without a worker, got %q", SubscriptionNone, got)
+	}
+
+	w := NewWorker(eventBus, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	if err := w.Start(Config{TenantIDs: []string{"tenant-001"}}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if got := w.SubscriptionStatus("tenant-001"); got != SubscriptionTenant {
+		t.Errorf("expected %q, got %q", SubscriptionTenant, got)
+	}
+	if got := w.SubscriptionStatus("tenant-002"); got != SubscriptionNone {
+		t.Errorf("expected %q, got %q", SubscriptionNone, got)
+	}
+	w.Stop()
+	if got := w.SubscriptionStatus("tenant-001"); got != SubscriptionNone {
+		t.Errorf("expected %q after Stop, got %q", SubscriptionNone, got)
+	}
+
+	global := NewWorker(eventBus, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	if err := global.Start(Config{}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer global.Stop()
+	if got := global.SubscriptionStatus("tenant-002"); got != SubscriptionGlobal {
+		t.Errorf("expected %q, got %q", SubscriptionGlobal, got)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cfg := domain.LaneConfig{HighValueAmount: 10000, BatchTypes: []string{"ach", "backfill"}}
+	cases := []struct {
+		name string
+		msg  TransactionMessage
+		want string
+	}{
+		{"default", TransactionMessage{Type: "transfer", Amount: domain.MustDecimal("100")}, domain.LaneRealtime},
+		{"batch rail", TransactionMessage{Type: "ach", Amount: domain.MustDecimal("100")}, domain.LaneBatch},
+		{"high-value batch rail", TransactionMessage{Type: "ach", Amount: domain.MustDecimal("10000")}, domain.LaneRealtime},
+		{"explicit batch", TransactionMessage{Type: "transfer", Amount: domain.MustDecimal("50000"), Priority: "batch"}, domain.LaneBatch},
+		{"explicit realtime", TransactionMessage{Type: "backfill", Priority: "realtime"}, domain.LaneRealtime},
+		{"unknown priority", TransactionMessage{Type: "backfill", Priority: "urgent"}, domain.LaneBatch},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(cfg, &tc.msg); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseLanes(t *testing.T) {
+	got, err := ParseLanes(" realtime=8, batch=2 ")
+	if err != nil {
+		t.Fatalf("ParseLanes failed: %v", err)
+	}
+	if got[domain.LaneRealtime] != 8 || got[domain.LaneBatch] != 2 {
+		t.Errorf("unexpected capacities: %v", got)
+	}
+
+	for _, bad := range []string{"realtime", "urgent=2", "batch=0", "batch=x"} {
+		if _, err := ParseLanes(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+// blockingEnricher holds evaluations of one transaction type until released.
+type blockingEnricher struct {
+	txType  string
+	release chan struct{}
+}
+
+func (e *blockingEnricher) Name() string                   { return "blocking" }
+func (e *blockingEnricher) Variables() map[string]*cel.Type { return nil }
+func (e *blockingEnricher) Enrich(ctx context.Context, input *rules.EvaluateInput, activation map[string]any) error {
+	if input.Type == e.txType {
+		<-e.release
+	}
+	return nil
+}
+
+func TestPriorityLanes(t *testing.T) {
+	eventBus := bus.NewChannelBus(100)
+	defer eventBus.Close()
+
+	engine, _ := rules.NewEngine(nil, 2)
+	blocker := &blockingEnricher{txType: "backfill", release: make(chan struct{})}
+	engine.RegisterEnricher(blocker)
+	engine.LoadRules([]*domain.RuleConfig{
+		{ID: "test-rule-001", Name: "Test Rule", Expression: "amount > 0.0", Weight: 1.0, Enabled: true},
+	})
+
+	w := NewWorker(eventBus, nil, engine, rules.NewTypologyEngine(), tadp.NewProcessor(), domain.ModeDetection)
+	err := w.Start(Config{
+		TenantIDs: []string{"tenant-001"},
+		Lanes: domain.LaneConfig{
+			Capacity:   map[string]int{domain.LaneRealtime: 2},
+			BatchTypes: []string{"backfill"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	decisions := make(chan string, 10)
+	eventBus.Subscribe(context.Background(), "tenant-001", domain.TopicDecision, func(ctx context.Context, msg *domain.Message) error {
+		var evaluation domain.Evaluation
+		json.Unmarshal(msg.Payload, &evaluation)
+		decisions <- evaluation.TxID
+		return nil
+	})
+
+	publish := func(id, txType string) {
+		t.Helper()
+		payload, _ := json.Marshal(ospreytest.NewTransaction().ID(id).Tenant("tenant-001").Type(txType).Message())
+		if err := eventBus.Publish(context.Background(), "tenant-001", domain.TopicTransactionIngested, payload); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	// The batch lane (capacity 1) is stuck on its first backfill
+	for i := range 3 {
+		publish(fmt.Sprintf("backfill-%d", i), "backfill")
+	}
+	publish("payment-001", "transfer")
+
+	select {
+	case txID := <-decisions:
+		if txID != "payment-001" {
+			t.Fatalf("expected the realtime payment first, got %s", txID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("realtime payment was delayed by the batch lane")
+	}
+
+	lanes := w.QueueStatus().Lanes
+	if len(lanes) != 2 || lanes[0].Lane != domain.LaneRealtime || lanes[0].Routed != 1 || lanes[0].Capacity != 2 ||
+		lanes[1].Lane != domain.LaneBatch || lanes[1].Routed != 3 || lanes[1].Capacity != 1 {
+		t.Errorf("unexpected lane status: %+v", lanes)
+	}
+
+	close(blocker.release)
+	flushBus(t, eventBus)
+	for range 3 {
+		select {
+		case <-decisions:
+		case <-time.After(5 * time.Second):
+			t.Fatal("batch transactions were not evaluated after release")
+		}
+	}
+}
+
+func TestLaneDeferredAck(t *testing.T) {
+	w := &Worker{}
+	l := newLanes(domain.LaneConfig{Capacity: map[string]int{domain.LaneRealtime: 2}})[domain.LaneRealtime]
+	release := make(chan struct{})
+	failed := errors.New("evaluation failed")
+	handle := l.handle(w, func(ctx context.Context, msg *domain.Message) error {
+		<-release
+		return failed
+	})
+
+	// Without an acknowledging bus, the message is handed off at once
+	if err := handle(context.Background(), &domain.Message{ID: "msg-1"}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// An acknowledging bus hears the result only once it is evaluated
+	settled := make(chan error, 1)
+	ctx := domain.WithDeferredAck(context.Background(), func(err error) { settled <- err })
+	if err := handle(ctx, &domain.Message{ID: "msg-2"}); !errors.Is(err, domain.ErrAckDeferred) {
+		t.Fatalf("expected ErrAckDeferred, got %v", err)
+	}
+	select {
+	case err := <-settled:
+		t.Fatalf("settled before the evaluation finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case err := <-settled:
+		if !errors.Is(err, failed) {
+			t.Errorf("expected the handler's error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was never settled")
+	}
+	w.wg.Wait()
+}
+
+func TestDeadLetters(t *testing.T) {
+	eventBus := ospreytest.NewBus(nil)
+	repo := ospreytest.NewRepository(nil)
+	engine, _ := rules.NewEngine(nil, 2)
+
+	// Compliance mode without typologies fails every evaluation
+	w := NewWorker(eventBus, repo, engine, rules.NewTypologyEngine(), tadp.NewComplianceProcessor(), domain.ModeCompliance)
+	if err := w.Start(Config{TenantIDs: []string{"tenant-001"}, Retries: 2, RetryBackoff: time.Millisecond}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	tx := ospreytest.NewTransaction().Tenant("tenant-001")
+	payload, _ := json.Marshal(tx.Message())
+	ctx := context.Background()
+	if err := eventBus.Publish(ctx, "tenant-001", domain.TopicTransactionIngested, payload); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := eventBus.Publish(ctx, "tenant-001", domain.TopicTransactionIngested, []byte("not json")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	dead, err := repo.ListDeadLetters(ctx, "tenant-001", domain.DeadLetterFilter{})
+	if err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+	if len(dead) != 2 {
+		t.Fatalf("expected 2 dead letters, got %d", len(dead))
+	}
+	byPayload := map[string]*domain.DeadLetter{}
+	for _, d := range dead {
+		byPayload[d.Payload] = d
+	}
+
+	failed := byPayload[string(payload)]
+	if failed == nil || failed.Attempts != 3 || failed.Poison || failed.Topic != domain.TopicTransactionIngested || failed.Error == "" {
+		t.Errorf("unexpected dead letter for the failing evaluation: %+v", failed)
+	}
+	poison := byPayload["not json"]
+	if poison == nil || poison.Attempts != 1 || !poison.Poison {
+		t.Errorf("unexpected dead letter for the poison message: %+v", poison)
+	}
+
+	if got := len(eventBus.Published("tenant-001", domain.TopicDeadLetter)); got != 2 {
+		t.Errorf("expected 2 published dead letters, got %d", got)
+	}
+	if status := w.QueueStatus(); len(status.Tenants) != 1 || status.Tenants[0].Failed != 2 {
+		t.Errorf("expected 2 failures, got %+v", status.Tenants)
+	}
+}
@@ -0,0 +1,467 @@
+// Package yamlsubset reads and writes the subset of YAML used by rule,
+// typology and rule pack files, without a YAML dependency.
+package yamlsubset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal decodes a YAML document into v through its JSON encoding, so v's
+// json tags apply. Unknown fields are rejected.
+func Unmarshal(data []byte, v any) error {
+	doc, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Decode parses a YAML document into map[string]any, []any and scalar
+// values. It supports block mappings and sequences, plain and quoted
+// scalars, literal (|) and folded (>) block scalars, single-line flow
+// collections and comments. Anchors, tags and multiple documents are not
+// supported.
+func Decode(data []byte) (any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw[indent:], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		text := strings.TrimRight(stripComment(raw[indent:]), " \t")
+		if i == 0 && text == "---" {
+			text = ""
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: indent, text: text, raw: raw})
+	}
+
+	if !p.next() {
+		return nil, nil
+	}
+	v, err := p.parseNode(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.next() {
+		l := p.lines[p.pos]
+		return nil, fmt.Errorf("line %d: unexpected content %q", l.num, l.text)
+	}
+	return v, nil
+}
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string // without indentation or comment
+	raw    string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// next skips blank lines and reports whether content remains.
+func (p *yamlParser) next() bool {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+	return p.pos < len(p.lines)
+}
+
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	l := p.lines[p.pos]
+	switch {
+	case isSeqItem(l.text):
+		return p.parseSeq(indent)
+	case mappingColon(l.text) >= 0:
+		return p.parseMap(indent)
+	}
+	p.pos++
+	return parseInline(l.text, l.num)
+}
+
+func (p *yamlParser) parseMap(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for p.next() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent || isSeqItem(l.text) {
+			return nil, fmt.Errorf("line %d: bad indentation", l.num)
+		}
+		colon := mappingColon(l.text)
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected key: value", l.num)
+		}
+		key, err := parseKey(l.text[:colon], l.num)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+
+		v, err := p.parseValue(strings.TrimSpace(l.text[colon+1:]), indent, l.num, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func (p *yamlParser) parseSeq(indent int) ([]any, error) {
+	seq := []any{}
+	for p.next() {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !isSeqItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: bad indentation", l.num)
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if mappingColon(rest) >= 0 && !strings.HasPrefix(rest, "{") {
+			// "- key: value" starts a mapping indented to its first key
+			p.lines[p.pos].indent = indent + len(l.text) - len(rest)
+			p.lines[p.pos].text = rest
+			v, err := p.parseMap(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		p.pos++
+		v, err := p.parseValue(rest, indent, l.num, false)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+	}
+	return seq, nil
+}
+
+// parseValue parses the value after "key:" or "-". An empty value is
+// followed by a nested block; a mapping value may also be followed by a
+// sequence at the key's own indentation.
+func (p *yamlParser) parseValue(rest string, indent, num int, inMap bool) (any, error) {
+	if strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">") {
+		return p.parseBlockScalar(rest, indent, num)
+	}
+	if rest != "" {
+		return parseInline(rest, num)
+	}
+	if !p.next() {
+		return nil, nil
+	}
+	l := p.lines[p.pos]
+	if l.indent > indent || (inMap && l.indent == indent && isSeqItem(l.text)) {
+		return p.parseNode(l.indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseBlockScalar(header string, indent, num int) (string, error) {
+	folded := header[0] == '>'
+	chomp := header[1:]
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return "", fmt.Errorf("line %d: unsupported block scalar header %q", num, header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		if l.indent < blockIndent {
+			return "", fmt.Errorf("line %d: bad indentation in block scalar", l.num)
+		}
+		lines = append(lines, l.raw[blockIndent:])
+		p.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "" || lines[i-1] == "":
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+	if chomp != "-" && text != "" {
+		text += "\n"
+	}
+	return text, nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// mappingColon returns the index of the colon separating a key from its
+// value, or -1 if text is not a mapping entry.
+func mappingColon(text string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ':' && depth == 0 && (i == len(text)-1 || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing comment outside quotes.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || text[i-1] == ' ' || strings.ContainsRune("[{,:", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func parseKey(s string, num int) (string, error) {
+	v, err := parseScalar(strings.TrimSpace(s), num)
+	if err != nil {
+		return "", err
+	}
+	if v == nil {
+		return "", fmt.Errorf("line %d: empty key", num)
+	}
+	return fmt.Sprint(v), nil
+}
+
+// parseInline parses a single-line scalar or flow collection.
+func parseInline(s string, num int) (any, error) {
+	if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") {
+		f := &flowParser{s: s, num: num}
+		v, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		f.skipSpace()
+		if f.pos != len(f.s) {
+			return nil, fmt.Errorf("line %d: unexpected %q after flow collection", num, f.s[f.pos:])
+		}
+		return v, nil
+	}
+	return parseScalar(s, num)
+}
+
+func parseScalar(s string, num int) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double-quoted string %s", num, s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("line %d: unterminated single-quoted string", num)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
+
+// flowParser parses flow collections such as [a, b] and {k: v}.
+type flowParser struct {
+	s   string
+	pos int
+	num int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flowParser) parse() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.s) {
+		return nil, fmt.Errorf("line %d: unterminated flow collection", f.num)
+	}
+	switch f.s[f.pos] {
+	case '[':
+		return f.parseSeq()
+	case '{':
+		return f.parseMap()
+	}
+	return parseScalar(f.scalar(), f.num)
+}
+
+// scalar returns the next scalar token, stopping at an unquoted , : ] or }.
+func (f *flowParser) scalar() string {
+	start := f.pos
+	var quote byte
+	for ; f.pos < len(f.s); f.pos++ {
+		c := f.s[f.pos]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				f.pos++
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		if c == ',' || c == ']' || c == '}' || (c == ':' && (f.pos+1 == len(f.s) || f.s[f.pos+1] == ' ')) {
+			break
+		}
+	}
+	return strings.TrimSpace(f.s[start:f.pos])
+}
+
+func (f *flowParser) expect(c byte) error {
+	f.skipSpace()
+	if f.pos >= len(f.s) || f.s[f.pos] != c {
+		return fmt.Errorf("line %d: expected %q in flow collection", f.num, c)
+	}
+	f.pos++
+	return nil
+}
+
+func (f *flowParser) parseSeq() ([]any, error) {
+	f.pos++ // [
+	seq := []any{}
+	for {
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == ']' {
+			f.pos++
+			return seq, nil
+		}
+		v, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == ',' {
+			f.pos++
+			continue
+		}
+		if err := f.expect(']'); err != nil {
+			return nil, err
+		}
+		return seq, nil
+	}
+}
+
+func (f *flowParser) parseMap() (map[string]any, error) {
+	f.pos++ // {
+	m := map[string]any{}
+	for {
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == '}' {
+			f.pos++
+			return m, nil
+		}
+		key, err := parseKey(f.scalar(), f.num)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.expect(':'); err != nil {
+			return nil, err
+		}
+		v, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+		f.skipSpace()
+		if f.pos < len(f.s) && f.s[f.pos] == ',' {
+			f.pos++
+			continue
+		}
+		if err := f.expect('}'); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
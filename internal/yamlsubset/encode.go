@@ -0,0 +1,232 @@
+package yamlsubset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Marshal encodes v as a YAML document through its JSON encoding, so v's
+// json tags and field order apply. Strings are double-quoted unless they read
+// the same unquoted, and multi-line strings become literal block scalars.
+// Decode reads the result back to the same values.
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := readNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	switch n := node.(type) {
+	case object:
+		if len(n) == 0 {
+			b.WriteString("{}\n")
+		}
+		writeObject(&b, n, 0)
+	case []any:
+		if len(n) == 0 {
+			b.WriteString("[]\n")
+		}
+		writeSeq(&b, n, 0)
+	default:
+		b.WriteString(scalar(n) + "\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// object is a JSON object with its keys in document order.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+// readNode reads one JSON value, keeping object keys in order.
+func readNode(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err = dec.Token() // closing brace
+		return obj, err
+	case '[':
+		seq := []any{}
+		for dec.More() {
+			value, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, value)
+		}
+		_, err = dec.Token() // closing bracket
+		return seq, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+func writeObject(b *strings.Builder, obj object, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, m := range obj {
+		b.WriteString(pad + scalar(m.key) + ":")
+		writeValue(b, m.value, indent)
+	}
+}
+
+func writeSeq(b *strings.Builder, seq []any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range seq {
+		switch v := item.(type) {
+		case object:
+			if len(v) == 0 {
+				b.WriteString(pad + "- {}\n")
+				continue
+			}
+			// "- key: value" with the rest of the mapping indented to its first key
+			var nested strings.Builder
+			writeObject(&nested, v, indent+2)
+			b.WriteString(pad + "- " + nested.String()[indent+2:])
+		case []any:
+			if len(v) == 0 {
+				b.WriteString(pad + "- []\n")
+				continue
+			}
+			b.WriteString(pad + "-\n")
+			writeSeq(b, v, indent+2)
+		default:
+			b.WriteString(pad + "-")
+			writeValue(b, v, indent)
+		}
+	}
+}
+
+// writeValue writes the value after "key:" or "-" at indent, ending the line.
+func writeValue(b *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeObject(b, v, indent+2)
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeSeq(b, v, indent+2)
+	case string:
+		if !literalBlock(v) {
+			b.WriteString(" " + scalar(v) + "\n")
+			return
+		}
+		header, text := " |-", v
+		if strings.HasSuffix(v, "\n") {
+			header, text = " |", strings.TrimSuffix(v, "\n")
+		}
+		b.WriteString(header + "\n")
+		pad := strings.Repeat(" ", indent+2)
+		for _, line := range strings.Split(text, "\n") {
+			if line != "" {
+				b.WriteString(pad + line)
+			}
+			b.WriteString("\n")
+		}
+	default:
+		b.WriteString(" " + scalar(v) + "\n")
+	}
+}
+
+// literalBlock reports whether a string can be written as a literal block
+// scalar and read back unchanged.
+func literalBlock(s string) bool {
+	if !strings.Contains(s, "\n") || strings.ContainsAny(s, "\r\t") {
+		return false
+	}
+	text := strings.TrimSuffix(s, "\n")
+	if strings.HasSuffix(text, "\n") {
+		return false // trailing blank lines are not kept
+	}
+	first := true
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "" || (first && line[0] == ' ') {
+			return false // the first line sets the block's indentation
+		}
+		first = false
+	}
+	return !first
+}
+
+// scalar formats a JSON scalar or key.
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if plain(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// plain reports whether a string reads as itself unquoted: it starts with a
+// letter, holds only letters, digits, spaces and _.-/, and is not a keyword
+// or number in any YAML version.
+func plain(s string) bool {
+	if s == "" || strings.HasSuffix(s, " ") {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || strings.ContainsRune(" _.-/", c)):
+		default:
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err != nil
+}
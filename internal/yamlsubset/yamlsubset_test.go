@@ -0,0 +1,129 @@
+package yamlsubset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	doc := `---
+# rule file
+id: high-value
+name: "High value: single"
+description: Debtor's large transfer   # trailing comment
+weight: 0.5
+enabled: true
+tags: [aml, 'fraud', 3]
+limits: {min: 1, max: 2.5}
+empty:
+expression: |
+  amount > 10000.0 &&
+    currency == "USD"
+folded: >-
+  one
+  two
+bands:
+  - subRuleRef: .pass
+    upperLimit: 1
+  - subRuleRef: .fail
+    lowerLimit: 1
+    reason: "Amount #1"
+nested:
+- a
+-
+  - b
+`
+	got, err := Decode([]byte(doc))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := map[string]any{
+		"id":          "high-value",
+		"name":        "High value: single",
+		"description": "Debtor's large transfer",
+		"weight":      0.5,
+		"enabled":     true,
+		"tags":        []any{"aml", "fraud", int64(3)},
+		"limits":      map[string]any{"min": int64(1), "max": 2.5},
+		"empty":       nil,
+		"expression":  "amount > 10000.0 &&\n  currency == \"USD\"\n",
+		"folded":      "one two",
+		"bands": []any{
+			map[string]any{"subRuleRef": ".pass", "upperLimit": int64(1)},
+			map[string]any{"subRuleRef": ".fail", "lowerLimit": int64(1), "reason": "Amount #1"},
+		},
+		"nested": []any{"a", []any{"b"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected result:\n got: %#v\nwant: %#v", got, want)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		bad := []string{
+			"a: 1\na: 2",
+			"a:\n\tb: 1",
+			"a: 1\n  b: 2",
+			"a: [1, 2",
+			"a: \"unterminated",
+		}
+		for _, doc := range bad {
+			if _, err := Decode([]byte(doc)); err == nil {
+				t.Errorf("expected error for %q", doc)
+			}
+		}
+	})
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	type band struct {
+		SubRuleRef string   `json:"subRuleRef"`
+		LowerLimit *float64 `json:"lowerLimit,omitempty"`
+	}
+	type doc struct {
+		ID         string         `json:"id"`
+		Name       string         `json:"name"`
+		Expression string         `json:"expression"`
+		Trailing   string         `json:"trailing"`
+		Indented   string         `json:"indented"`
+		Keyword    string         `json:"keyword"`
+		Number     string         `json:"number"`
+		Weight     float64        `json:"weight"`
+		Enabled    bool           `json:"enabled"`
+		Tags       []string       `json:"tags"`
+		Empty      []string       `json:"empty"`
+		Params     map[string]any `json:"params"`
+		Bands      []band         `json:"bands"`
+		Matrix     [][]int        `json:"matrix"`
+	}
+
+	limit := 1.5
+	in := doc{
+		ID:         "high-value",
+		Name:       "High value: single # one",
+		Expression: "amount > 10000.0 &&\n  currency == \"USD\"",
+		Trailing:   "line one\n\nline three\n",
+		Indented:   "  leading\nspace",
+		Keyword:    "yes",
+		Number:     "1e3",
+		Weight:     0.5,
+		Enabled:    true,
+		Tags:       []string{"aml", "fraud"},
+		Empty:      []string{},
+		Params:     map[string]any{"threshold": 10000.0, "list": "sanctions"},
+		Bands:      []band{{SubRuleRef: ".pass"}, {SubRuleRef: ".fail", LowerLimit: &limit}},
+		Matrix:     [][]int{{1, 2}, {}},
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var out doc
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n%s\ngot  %#v\nwant %#v", data, out, in)
+	}
+}
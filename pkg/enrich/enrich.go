@@ -0,0 +1,67 @@
+// Package enrich is the contract for Osprey enrichment plugins.
+//
+// A plugin adds variables to the CEL activation of every transaction, so rules
+// can use proprietary features without forking the engine. Build it as a Go
+// plugin that exports a package-level variable named Plugin:
+//
+//	package main
+//
+//	import "github.com/opensource-finance/osprey/pkg/enrich"
+//
+//	var Plugin enrich.Plugin = deviceRisk{}
+//
+// and compile it with the same Go version and dependency versions as the Osprey
+// binary:
+//
+//	go build -buildmode=plugin -o plugins/device_risk.so ./device_risk
+//
+// Osprey loads every *.so file in OSPREY_PLUGIN_DIR at startup.
+package enrich
+
+import "context"
+
+// SymbolName is the exported variable Osprey looks up in a plugin.
+const SymbolName = "Plugin"
+
+// Type is the CEL type of a plugin variable.
+type Type string
+
+// Supported variable types and the Go values Enrich returns for them.
+const (
+	Bool   Type = "bool"   // bool
+	Int    Type = "int"    // int, int32 or int64
+	Double Type = "double" // float64, float32 or any integer
+	String Type = "string" // string
+	Map    Type = "map"    // map[string]any
+)
+
+// Transaction is the read-only view of a transaction passed to plugins.
+type Transaction struct {
+	TenantID        string
+	TxID            string
+	Type            string
+	DebtorID        string
+	CreditorID      string
+	DebtorCountry   string
+	CreditorCountry string
+	Amount          float64
+	Currency        string
+	Metadata        map[string]any
+}
+
+// Plugin adds variables to rule evaluation.
+//
+// Enrich runs once per transaction, concurrently across transactions, under a
+// deadline set by OSPREY_PLUGIN_TIMEOUT. A variable that is missing from the
+// result, has the wrong type, or belongs to a call that failed, panicked or
+// timed out gets its zero value, so rules never fail on plugin errors.
+type Plugin interface {
+	// Name identifies the plugin in logs.
+	Name() string
+
+	// Variables declares every variable the plugin sets and its type.
+	Variables() map[string]Type
+
+	// Enrich computes the declared variables for one transaction.
+	Enrich(ctx context.Context, tx Transaction) (map[string]any, error)
+}
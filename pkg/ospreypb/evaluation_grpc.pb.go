@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: osprey/v1/evaluation.proto
+
+package ospreypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Evaluation_Stream_FullMethodName = "/osprey.v1.Evaluation/Stream"
+)
+
+// EvaluationClient is the client API for Evaluation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Evaluation evaluates transactions over a long-lived gRPC stream, for
+// payment switches that would otherwise open a request per transaction.
+//
+// The tenant is set once per stream with the x-tenant-id metadata key, and
+// the caller may be identified with x-principal.
+type EvaluationClient interface {
+	// Stream evaluates every transaction the client sends and sends back one
+	// decision per transaction, matched by correlation_id. Transactions of
+	// the same debtor account are decided in the order they were sent;
+	// others are decided concurrently, so decisions may arrive out of order.
+	Stream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EvaluateRequest, EvaluateResponse], error)
+}
+
+type evaluationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEvaluationClient(cc grpc.ClientConnInterface) EvaluationClient {
+	return &evaluationClient{cc}
+}
+
+func (c *evaluationClient) Stream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EvaluateRequest, EvaluateResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Evaluation_ServiceDesc.Streams[0], Evaluation_Stream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EvaluateRequest, EvaluateResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Evaluation_StreamClient = grpc.BidiStreamingClient[EvaluateRequest, EvaluateResponse]
+
+// EvaluationServer is the server API for Evaluation service.
+// All implementations must embed UnimplementedEvaluationServer
+// for forward compatibility.
+//
+// Evaluation evaluates transactions over a long-lived gRPC stream, for
+// payment switches that would otherwise open a request per transaction.
+//
+// The tenant is set once per stream with the x-tenant-id metadata key, and
+// the caller may be identified with x-principal.
+type EvaluationServer interface {
+	// Stream evaluates every transaction the client sends and sends back one
+	// decision per transaction, matched by correlation_id. Transactions of
+	// the same debtor account are decided in the order they were sent;
+	// others are decided concurrently, so decisions may arrive out of order.
+	Stream(grpc.BidiStreamingServer[EvaluateRequest, EvaluateResponse]) error
+	mustEmbedUnimplementedEvaluationServer()
+}
+
+// UnimplementedEvaluationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEvaluationServer struct{}
+
+func (UnimplementedEvaluationServer) Stream(grpc.BidiStreamingServer[EvaluateRequest, EvaluateResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedEvaluationServer) mustEmbedUnimplementedEvaluationServer() {}
+func (UnimplementedEvaluationServer) testEmbeddedByValue()                    {}
+
+// UnsafeEvaluationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EvaluationServer will
+// result in compilation errors.
+type UnsafeEvaluationServer interface {
+	mustEmbedUnimplementedEvaluationServer()
+}
+
+func RegisterEvaluationServer(s grpc.ServiceRegistrar, srv EvaluationServer) {
+	// If the following call pancis, it indicates UnimplementedEvaluationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Evaluation_ServiceDesc, srv)
+}
+
+func _Evaluation_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EvaluationServer).Stream(&grpc.GenericServerStream[EvaluateRequest, EvaluateResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Evaluation_StreamServer = grpc.BidiStreamingServer[EvaluateRequest, EvaluateResponse]
+
+// Evaluation_ServiceDesc is the grpc.ServiceDesc for Evaluation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Evaluation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "osprey.v1.Evaluation",
+	HandlerType: (*EvaluationServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Evaluation_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "osprey/v1/evaluation.proto",
+}
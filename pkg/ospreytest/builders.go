@@ -0,0 +1,190 @@
+package ospreytest
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+var txSeq atomic.Int64
+
+// TransactionBuilder builds transactions with sensible defaults: a 100.00 USD
+// transfer between two distinct parties at Epoch.
+type TransactionBuilder struct {
+	tx              domain.Transaction
+	debtorName      string
+	creditorName    string
+	debtorCountry   string
+	creditorCountry string
+}
+
+// NewTransaction starts a transaction with a unique ID.
+func NewTransaction() *TransactionBuilder {
+	n := txSeq.Add(1)
+	return &TransactionBuilder{
+		tx: domain.Transaction{
+			ID:              fmt.Sprintf("tx-%06d", n),
+			Type:            "transfer",
+			DebtorID:        "debtor-001",
+			DebtorAccountID: "debtor-001-acct",
+			CreditorID:      "creditor-001",
+			CreditorAcctID:  "creditor-001-acct",
+			Amount:          domain.Decimal{Units: 100},
+			Currency:        "USD",
+			Timestamp:       Epoch,
+			CreatedAt:       Epoch,
+		},
+	}
+}
+
+// ID sets the transaction ID.
+func (b *TransactionBuilder) ID(id string) *TransactionBuilder {
+	b.tx.ID = id
+	return b
+}
+
+// Tenant sets the tenant ID.
+func (b *TransactionBuilder) Tenant(tenantID string) *TransactionBuilder {
+	b.tx.TenantID = tenantID
+	return b
+}
+
+// Type sets the transaction type.
+func (b *TransactionBuilder) Type(txType string) *TransactionBuilder {
+	b.tx.Type = txType
+	return b
+}
+
+// From sets the debtor and derives its account ID.
+func (b *TransactionBuilder) From(debtorID string) *TransactionBuilder {
+	b.tx.DebtorID = debtorID
+	b.tx.DebtorAccountID = debtorID + "-acct"
+	return b
+}
+
+// To sets the creditor and derives its account ID.
+func (b *TransactionBuilder) To(creditorID string) *TransactionBuilder {
+	b.tx.CreditorID = creditorID
+	b.tx.CreditorAcctID = creditorID + "-acct"
+	return b
+}
+
+// DebtorDetails sets the debtor's name and country for screening and corridor rules.
+func (b *TransactionBuilder) DebtorDetails(name, country string) *TransactionBuilder {
+	b.debtorName, b.debtorCountry = name, country
+	return b
+}
+
+// CreditorDetails sets the creditor's name and country for screening and corridor rules.
+func (b *TransactionBuilder) CreditorDetails(name, country string) *TransactionBuilder {
+	b.creditorName, b.creditorCountry = name, country
+	return b
+}
+
+// Amount sets the amount and currency.
+func (b *TransactionBuilder) Amount(value float64, currency string) *TransactionBuilder {
+	b.tx.Amount = domain.DecimalFromFloat(value)
+	b.tx.Currency = currency
+	return b
+}
+
+// Components sets the amount breakdown.
+func (b *TransactionBuilder) Components(c *domain.AmountComponents) *TransactionBuilder {
+	b.tx.Components = c
+	return b
+}
+
+// At sets the transaction and creation timestamps.
+func (b *TransactionBuilder) At(ts time.Time) *TransactionBuilder {
+	b.tx.Timestamp = ts
+	b.tx.CreatedAt = ts
+	return b
+}
+
+// Metadata sets a metadata value.
+func (b *TransactionBuilder) Metadata(key string, value any) *TransactionBuilder {
+	if b.tx.Metadata == nil {
+		b.tx.Metadata = make(map[string]any)
+	}
+	b.tx.Metadata[key] = value
+	return b
+}
+
+// Build returns the transaction.
+func (b *TransactionBuilder) Build() *domain.Transaction {
+	tx := b.tx
+	if b.tx.Metadata != nil {
+		tx.Metadata = make(map[string]any, len(b.tx.Metadata))
+		for k, v := range b.tx.Metadata {
+			tx.Metadata[k] = v
+		}
+	}
+	return &tx
+}
+
+// Request returns the transaction as a POST /evaluate request body.
+func (b *TransactionBuilder) Request() map[string]any {
+	req := map[string]any{
+		"type":     b.tx.Type,
+		"debtor":   party(b.tx.DebtorID, b.tx.DebtorAccountID, b.debtorName, b.debtorCountry),
+		"creditor": party(b.tx.CreditorID, b.tx.CreditorAcctID, b.creditorName, b.creditorCountry),
+		"amount":   amount(b.tx.Amount, b.tx.Currency, b.tx.Components),
+	}
+	if b.tx.Metadata != nil {
+		req["metadata"] = b.tx.Metadata
+	}
+	return req
+}
+
+// Message returns the transaction as an async worker message payload for
+// domain.TopicTransactionIngested.
+func (b *TransactionBuilder) Message() map[string]any {
+	msg := map[string]any{
+		"txId":       b.tx.ID,
+		"tenantId":   b.tx.TenantID,
+		"type":       b.tx.Type,
+		"debtorId":   b.tx.DebtorID,
+		"creditorId": b.tx.CreditorID,
+		"amount":     b.tx.Amount,
+		"currency":   b.tx.Currency,
+	}
+	optional := map[string]string{
+		"debtorName":      b.debtorName,
+		"creditorName":    b.creditorName,
+		"debtorCountry":   b.debtorCountry,
+		"creditorCountry": b.creditorCountry,
+	}
+	for k, v := range optional {
+		if v != "" {
+			msg[k] = v
+		}
+	}
+	if b.tx.Components != nil {
+		msg["components"] = b.tx.Components
+	}
+	if b.tx.Metadata != nil {
+		msg["additionalData"] = b.tx.Metadata
+	}
+	return msg
+}
+
+func party(id, accountID, name, country string) map[string]any {
+	p := map[string]any{"id": id, "accountId": accountID}
+	if name != "" {
+		p["name"] = name
+	}
+	if country != "" {
+		p["country"] = country
+	}
+	return p
+}
+
+func amount(value domain.Decimal, currency string, components *domain.AmountComponents) map[string]any {
+	a := map[string]any{"value": value, "currency": currency}
+	if components != nil {
+		a["components"] = components
+	}
+	return a
+}
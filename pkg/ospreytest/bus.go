@@ -0,0 +1,204 @@
+package ospreytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Bus is an in-memory domain.EventBus that delivers every message
+// synchronously, in the publisher's goroutine, before Publish returns. Tests
+// can assert on the results of a publish without sleeping.
+type Bus struct {
+	mu         sync.Mutex
+	clock      *Clock
+	seq        int
+	subs       []*busSubscription
+	responders map[string]Responder
+	published  []*domain.Message
+	closed     bool
+}
+
+// Responder answers a Request on a topic.
+type Responder func(ctx context.Context, msg *domain.Message) ([]byte, error)
+
+type busSubscription struct {
+	bus      *Bus
+	tenantID string
+	topic    string
+	handler  domain.MessageHandler
+	active   bool
+}
+
+// NewBus creates an empty bus. A nil clock uses a clock starting at Epoch.
+func NewBus(clock *Clock) *Bus {
+	if clock == nil {
+		clock = NewClock(time.Time{})
+	}
+	return &Bus{
+		clock:      clock,
+		responders: make(map[string]Responder),
+	}
+}
+
+// Publish records the message and delivers it to matching subscribers.
+// Handler errors are returned, joined, so tests see failures directly.
+func (b *Bus) Publish(ctx context.Context, tenantID string, topic string, payload []byte) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenantID is required")
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return fmt.Errorf("bus is closed")
+	}
+	msg := b.newMessage(tenantID, topic, payload)
+	b.published = append(b.published, msg)
+
+	var handlers []domain.MessageHandler
+	for _, sub := range b.subs {
+		if sub.active && sub.tenantID == tenantID && sub.topic == topic {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.Unlock()
+
+	// Deliver outside the lock so handlers can publish in turn
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscribe registers a handler for a tenant's topic.
+func (b *Bus) Subscribe(ctx context.Context, tenantID string, topic string, handler domain.MessageHandler) (domain.Subscription, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, fmt.Errorf("bus is closed")
+	}
+
+	sub := &busSubscription{bus: b, tenantID: tenantID, topic: topic, handler: handler, active: true}
+	b.subs = append(b.subs, sub)
+	return sub, nil
+}
+
+// Respond registers the responder used by Request for a topic.
+func (b *Bus) Respond(topic string, responder Responder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.responders[topic] = responder
+}
+
+// Request calls the topic's responder directly. It fails if none is registered.
+func (b *Bus) Request(ctx context.Context, tenantID string, topic string, payload []byte) ([]byte, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("bus is closed")
+	}
+	responder, ok := b.responders[topic]
+	msg := b.newMessage(tenantID, topic, payload)
+	b.published = append(b.published, msg)
+	b.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no responder for topic %s", topic)
+	}
+	return responder(ctx, msg)
+}
+
+// Published returns the messages published to a tenant's topic, oldest first.
+// An empty topic matches every topic.
+func (b *Bus) Published(tenantID, topic string) []*domain.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []*domain.Message
+	for _, msg := range b.published {
+		if msg.TenantID == tenantID && (topic == "" || msg.Topic == topic) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Reset forgets published messages but keeps subscriptions.
+func (b *Bus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = nil
+}
+
+// Ping reports whether the bus is open.
+func (b *Bus) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return fmt.Errorf("bus is closed")
+	}
+	return nil
+}
+
+// Close stops delivery to all subscriptions.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, sub := range b.subs {
+		sub.active = false
+	}
+	b.subs = nil
+	return nil
+}
+
+// newMessage builds a message with a sequential ID. Callers must hold b.mu.
+func (b *Bus) newMessage(tenantID, topic string, payload []byte) *domain.Message {
+	b.seq++
+	return &domain.Message{
+		ID:        fmt.Sprintf("msg-%06d", b.seq),
+		TenantID:  tenantID,
+		Topic:     topic,
+		Payload:   payload,
+		Metadata:  make(map[string]string),
+		Timestamp: b.clock.Now().UnixNano(),
+	}
+}
+
+// Unsubscribe stops delivery to the subscription.
+func (s *busSubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.active = false
+	for i, sub := range s.bus.subs {
+		if sub == s {
+			s.bus.subs = append(s.bus.subs[:i], s.bus.subs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Topic returns the subscribed topic.
+func (s *busSubscription) Topic() string {
+	return s.topic
+}
+
+var _ domain.EventBus = (*Bus)(nil)
@@ -0,0 +1,174 @@
+package ospreytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+)
+
+// Cache is an in-memory domain.Cache whose expiry follows a Clock, so TTLs and
+// velocity windows can be tested by advancing time instead of sleeping.
+type Cache struct {
+	mu       sync.Mutex
+	clock    *Clock
+	items    map[string]cacheItem
+	counters map[string]cacheCounter
+}
+
+type cacheItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type cacheCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewCache creates an empty cache. A nil clock uses a clock starting at Epoch.
+func NewCache(clock *Clock) *Cache {
+	if clock == nil {
+		clock = NewClock(time.Time{})
+	}
+	return &Cache{
+		clock:    clock,
+		items:    make(map[string]cacheItem),
+		counters: make(map[string]cacheCounter),
+	}
+}
+
+// Get retrieves a value. Returns nil, nil if the key is missing or expired.
+func (c *Cache) Get(ctx context.Context, tenantID string, key string) ([]byte, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fullKey := tenantID + ":" + key
+	item, ok := c.items[fullKey]
+	if !ok {
+		return nil, nil
+	}
+	if !c.clock.Now().Before(item.expiresAt) {
+		delete(c.items, fullKey)
+		return nil, nil
+	}
+	return item.value, nil
+}
+
+// Set stores a value with a TTL.
+func (c *Cache) Set(ctx context.Context, tenantID string, key string, value []byte, ttl time.Duration) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[tenantID+":"+key] = cacheItem{value: value, expiresAt: c.clock.Now().Add(ttl)}
+	return nil
+}
+
+// Delete removes a value.
+func (c *Cache) Delete(ctx context.Context, tenantID string, key string) error {
+	if tenantID == "" {
+		return fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, tenantID+":"+key)
+	return nil
+}
+
+// GetTransaction retrieves cached transaction data.
+func (c *Cache) GetTransaction(ctx context.Context, tenantID string, txID string) (*domain.DataCache, error) {
+	data, err := c.Get(ctx, tenantID, "tx:"+txID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var dc domain.DataCache
+	if err := json.Unmarshal(data, &dc); err != nil {
+		return nil, err
+	}
+	return &dc, nil
+}
+
+// SetTransaction caches transaction data.
+func (c *Cache) SetTransaction(ctx context.Context, tenantID string, txID string, data *domain.DataCache, ttl time.Duration) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, tenantID, "tx:"+txID, bytes, ttl)
+}
+
+// IncrementCounter increments a counter, starting a new window once the
+// previous one has elapsed.
+func (c *Cache) IncrementCounter(ctx context.Context, tenantID string, key string, window time.Duration) (int64, error) {
+	return c.IncrementCounterBy(ctx, tenantID, key, 1, window)
+}
+
+// IncrementCounterBy adds delta to a counter, starting a new window once
+// the previous one has elapsed.
+func (c *Cache) IncrementCounterBy(ctx context.Context, tenantID string, key string, delta int64, window time.Duration) (int64, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fullKey := tenantID + ":counter:" + key
+	now := c.clock.Now()
+	counter, ok := c.counters[fullKey]
+	if !ok || !now.Before(counter.expiresAt) {
+		counter = cacheCounter{expiresAt: now.Add(window)}
+	}
+	counter.count += delta
+	c.counters[fullKey] = counter
+	return counter.count, nil
+}
+
+// GetCounters returns the values of counters, 0 for missing or expired ones.
+func (c *Cache) GetCounters(ctx context.Context, tenantID string, keys []string) ([]int64, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("tenantID is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	values := make([]int64, len(keys))
+	for i, key := range keys {
+		if counter, ok := c.counters[tenantID+":counter:"+key]; ok && now.Before(counter.expiresAt) {
+			values[i] = counter.count
+		}
+	}
+	return values, nil
+}
+
+// Ping always succeeds.
+func (c *Cache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close clears the cache.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]cacheItem)
+	c.counters = make(map[string]cacheCounter)
+	return nil
+}
+
+var _ domain.Cache = (*Cache)(nil)
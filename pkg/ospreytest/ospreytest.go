@@ -0,0 +1,44 @@
+// Package ospreytest provides deterministic in-memory fakes of the Osprey
+// Repository, Cache and EventBus, plus transaction builders, for testing code
+// that integrates with Osprey without SQLite files, network services or sleeps.
+//
+// The fakes mirror the behaviour of the real implementations closely enough
+// for handler and pipeline tests: tenant isolation, upsert semantics, sort
+// order and not-found errors match the SQL repository.
+package ospreytest
+
+import (
+	"sync"
+	"time"
+)
+
+// Epoch is the default start time of a Clock.
+var Epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a manually advanced clock shared by the fakes.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock set to start. A zero start uses Epoch.
+func NewClock(start time.Time) *Clock {
+	if start.IsZero() {
+		start = Epoch
+	}
+	return &Clock{now: start}
+}
+
+// Now returns the current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,141 @@
+package ospreytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	clock := NewClock(time.Time{})
+	repo := NewRepository(clock)
+	tenantID := "tenant-001"
+
+	t.Run("TransactionsByEntityAndWindow", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			tx := NewTransaction().From("alice").At(Epoch.Add(time.Duration(i) * time.Hour)).Build()
+			if err := repo.SaveTransaction(ctx, tenantID, tx); err != nil {
+				t.Fatalf("SaveTransaction failed: %v", err)
+			}
+		}
+
+		txs, _ := repo.GetTransactionsByEntity(ctx, tenantID, "alice", Epoch.Add(time.Hour))
+		if len(txs) != 2 || !txs[0].Timestamp.After(txs[1].Timestamp) {
+			t.Errorf("expected 2 transactions newest first, got %d", len(txs))
+		}
+
+		page, _ := repo.ListTransactions(ctx, tenantID, Epoch, Epoch.Add(2*time.Hour), 1, 10)
+		if len(page) != 1 || !page[0].Timestamp.Equal(Epoch.Add(time.Hour)) {
+			t.Errorf("expected second transaction in page, got %+v", page)
+		}
+
+		others, _ := repo.GetTransactionsByEntity(ctx, "tenant-002", "alice", Epoch)
+		if len(others) != 0 {
+			t.Errorf("expected tenant isolation, got %d transactions", len(others))
+		}
+	})
+
+	t.Run("NotFoundAndInvalidInput", func(t *testing.T) {
+		if _, err := repo.GetEvaluation(ctx, tenantID, "missing"); err != repository.ErrNotFound {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetTransaction(ctx, "", "tx"); !errors.Is(err, repository.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("TypologyVersions", func(t *testing.T) {
+		repo.SaveTypology(ctx, tenantID, &domain.Typology{ID: "typ-1", Name: "Mule", Version: "1.0.0", Enabled: true})
+		repo.SaveTypology(ctx, tenantID, &domain.Typology{ID: "typ-1", Name: "Mule", Version: "1.1.0", Enabled: true})
+
+		typ, err := repo.GetTypology(ctx, tenantID, "typ-1")
+		if err != nil || typ.Version != "1.1.0" {
+			t.Fatalf("expected latest version, got %+v (%v)", typ, err)
+		}
+
+		if err := repo.DeleteTypology(ctx, tenantID, "typ-1"); err != nil {
+			t.Fatalf("DeleteTypology failed: %v", err)
+		}
+		if _, err := repo.GetTypology(ctx, tenantID, "typ-1"); err != repository.ErrNotFound {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("InjectedError", func(t *testing.T) {
+		boom := errors.New("boom")
+		repo.SetError(boom)
+		defer repo.SetError(nil)
+
+		if err := repo.Ping(ctx); err != boom {
+			t.Errorf("expected injected error, got %v", err)
+		}
+	})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	clock := NewClock(time.Time{})
+	c := NewCache(clock)
+
+	c.Set(ctx, "tenant-001", "key", []byte("value"), time.Minute)
+	if v, _ := c.Get(ctx, "tenant-002", "key"); v != nil {
+		t.Error("expected tenant isolation")
+	}
+
+	clock.Advance(time.Minute)
+	if v, _ := c.Get(ctx, "tenant-001", "key"); v != nil {
+		t.Error("expected value to expire")
+	}
+
+	c.IncrementCounter(ctx, "tenant-001", "velocity", time.Hour)
+	if n, _ := c.IncrementCounter(ctx, "tenant-001", "velocity", time.Hour); n != 2 {
+		t.Errorf("expected count 2, got %d", n)
+	}
+	clock.Advance(time.Hour)
+	if n, _ := c.IncrementCounter(ctx, "tenant-001", "velocity", time.Hour); n != 1 {
+		t.Errorf("expected new window, got %d", n)
+	}
+}
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(nil)
+
+	var received []string
+	sub, _ := bus.Subscribe(ctx, "tenant-001", domain.TopicDecision, func(ctx context.Context, msg *domain.Message) error {
+		received = append(received, string(msg.Payload))
+		return nil
+	})
+
+	bus.Publish(ctx, "tenant-001", domain.TopicDecision, []byte("a"))
+	bus.Publish(ctx, "tenant-002", domain.TopicDecision, []byte("b"))
+
+	// Delivery is synchronous: no waiting needed
+	if len(received) != 1 || received[0] != "a" {
+		t.Errorf("expected one delivery, got %v", received)
+	}
+	if len(bus.Published("tenant-002", "")) != 1 {
+		t.Error("expected published message to be recorded")
+	}
+
+	sub.Unsubscribe()
+	bus.Publish(ctx, "tenant-001", domain.TopicDecision, []byte("c"))
+	if len(received) != 1 {
+		t.Error("expected no delivery after unsubscribe")
+	}
+
+	if _, err := bus.Request(ctx, "tenant-001", "ping", nil); err == nil {
+		t.Error("expected error without responder")
+	}
+	bus.Respond("ping", func(ctx context.Context, msg *domain.Message) ([]byte, error) {
+		return []byte("pong"), nil
+	})
+	if reply, _ := bus.Request(ctx, "tenant-001", "ping", nil); string(reply) != "pong" {
+		t.Errorf("expected pong, got %q", reply)
+	}
+}
@@ -0,0 +1,2663 @@
+package ospreytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensource-finance/osprey/internal/domain"
+	"github.com/opensource-finance/osprey/internal/repository"
+)
+
+// Repository is an in-memory domain.Repository. Lookups of missing records
+// return repository.ErrNotFound, like the SQL repository.
+type Repository struct {
+	mu    sync.Mutex
+	clock *Clock
+	err   error
+
+	transactions map[tenantKey]*domain.Transaction
+	rules        map[versionKey]*domain.RuleConfig
+	ruleVersions map[versionKey]*domain.RuleVersion
+	evaluations  map[tenantKey]*domain.Evaluation
+	evalLog      map[string][]*domain.EvaluationLogRecord // tenant -> records in seq order
+	auditLog     map[string][]*domain.AuditEntry          // tenant -> entries in seq order
+	typologies   map[versionKey]*domain.Typology
+	parties      map[tenantKey]*domain.PartyKYC
+	corridors    map[tenantKey]*domain.CorridorRisk
+	watchlist    map[tenantKey]*domain.WatchlistEntry
+	jobs         map[string]*domain.Job // id -> job (ids are unique across tenants)
+	jobFiles     map[tenantKey][]byte
+	flags        map[tenantKey]*domain.FeatureFlag
+	alerts       map[tenantKey]*domain.Alert
+	alertEvents  map[tenantKey][]*domain.AlertEvent
+	samples      map[string][]*domain.ActivationSample  // tenant -> samples in save order
+	outcomes     map[string][]*domain.EvaluationOutcome // tenant -> outcomes in save order
+	webhooks     map[tenantKey]*domain.Webhook
+	deliveries   map[tenantKey]*domain.WebhookDelivery
+	deadLetters  map[string][]*domain.DeadLetter // tenant -> dead letters in save order
+	maintenance  map[tenantKey]*domain.MaintenanceWindow
+	edges        map[tenantKey]*domain.CounterpartyEdge
+	scoring      map[string]*domain.ScoringConfig // tenant -> config
+	namedLists   map[tenantKey]*domain.NamedList
+	claims       map[tenantKey]*domain.ReviewClaim
+	baselines    map[tenantKey]*domain.EntityBaseline
+	balances     map[tenantKey]*domain.AccountBalance
+	retention    map[string]*domain.RetentionPolicy // tenant -> policy
+}
+
+type tenantKey struct {
+	tenantID string
+	id       string
+}
+
+type versionKey struct {
+	tenantID string
+	id       string
+	version  string
+}
+
+// NewRepository creates an empty in-memory repository. A nil clock uses a
+// clock starting at Epoch.
+func NewRepository(clock *Clock) *Repository {
+	if clock == nil {
+		clock = NewClock(time.Time{})
+	}
+	return &Repository{
+		clock:        clock,
+		transactions: make(map[tenantKey]*domain.Transaction),
+		rules:        make(map[versionKey]*domain.RuleConfig),
+		ruleVersions: make(map[versionKey]*domain.RuleVersion),
+		evaluations:  make(map[tenantKey]*domain.Evaluation),
+		evalLog:      make(map[string][]*domain.EvaluationLogRecord),
+		auditLog:     make(map[string][]*domain.AuditEntry),
+		typologies:   make(map[versionKey]*domain.Typology),
+		parties:      make(map[tenantKey]*domain.PartyKYC),
+		corridors:    make(map[tenantKey]*domain.CorridorRisk),
+		watchlist:    make(map[tenantKey]*domain.WatchlistEntry),
+		jobs:         make(map[string]*domain.Job),
+		jobFiles:     make(map[tenantKey][]byte),
+		flags:        make(map[tenantKey]*domain.FeatureFlag),
+		alerts:       make(map[tenantKey]*domain.Alert),
+		alertEvents:  make(map[tenantKey][]*domain.AlertEvent),
+		samples:      make(map[string][]*domain.ActivationSample),
+		outcomes:     make(map[string][]*domain.EvaluationOutcome),
+		webhooks:     make(map[tenantKey]*domain.Webhook),
+		deliveries:   make(map[tenantKey]*domain.WebhookDelivery),
+		deadLetters:  make(map[string][]*domain.DeadLetter),
+		maintenance:  make(map[tenantKey]*domain.MaintenanceWindow),
+		edges:        make(map[tenantKey]*domain.CounterpartyEdge),
+		scoring:      make(map[string]*domain.ScoringConfig),
+		namedLists:   make(map[tenantKey]*domain.NamedList),
+		claims:       make(map[tenantKey]*domain.ReviewClaim),
+		baselines:    make(map[tenantKey]*domain.EntityBaseline),
+		balances:     make(map[tenantKey]*domain.AccountBalance),
+		retention:    make(map[string]*domain.RetentionPolicy),
+	}
+}
+
+// SetError makes every subsequent call fail with err, for testing storage
+// failures. Pass nil to restore normal behaviour.
+func (r *Repository) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// check validates the tenant and returns the injected error, if any.
+// Callers must hold r.mu.
+func (r *Repository) check(tenantID string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if tenantID == "" {
+		return fmt.Errorf("%w: tenantID is required", repository.ErrInvalidInput)
+	}
+	return nil
+}
+
+// SaveTransaction stores a transaction. An ID the tenant already has is
+// rejected with repository.ErrConflict.
+func (r *Repository) SaveTransaction(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	key := tenantKey{tenantID, tx.ID}
+	if _, ok := r.transactions[key]; ok {
+		return fmt.Errorf("%w: transaction %s", repository.ErrConflict, tx.ID)
+	}
+
+	stored := *tx
+	stored.TenantID = tenantID
+	stored.OriginalMessage = nil
+	r.transactions[key] = &stored
+	return nil
+}
+
+// GetTransaction retrieves a transaction by ID.
+func (r *Repository) GetTransaction(ctx context.Context, tenantID string, txID string) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	tx, ok := r.transactions[tenantKey{tenantID, txID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *tx
+	return &out, nil
+}
+
+// GetTransactionsByEntity retrieves an entity's transactions since a time, newest first.
+func (r *Repository) GetTransactionsByEntity(ctx context.Context, tenantID string, entityID string, since time.Time) ([]*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.Transaction
+	for _, tx := range r.transactions {
+		if tx.TenantID != tenantID || (tx.DebtorID != entityID && tx.CreditorID != entityID) {
+			continue
+		}
+		if tx.Timestamp.Before(since) {
+			continue
+		}
+		copied := *tx
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.After(out[j].Timestamp) })
+	return out, nil
+}
+
+// ListTransactions retrieves a page of transactions in [since, until), oldest first.
+func (r *Repository) ListTransactions(ctx context.Context, tenantID string, since, until time.Time, offset, limit int) ([]*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	matched := r.transactionsBetween(tenantID, since, until)
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].Timestamp.Equal(matched[j].Timestamp) {
+			return matched[i].Timestamp.Before(matched[j].Timestamp)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// CountTransactions counts transactions in [since, until).
+func (r *Repository) CountTransactions(ctx context.Context, tenantID string, since, until time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return 0, err
+	}
+	return len(r.transactionsBetween(tenantID, since, until)), nil
+}
+
+// transactionsBetween copies a tenant's transactions in [since, until).
+// Callers must hold r.mu.
+func (r *Repository) transactionsBetween(tenantID string, since, until time.Time) []*domain.Transaction {
+	var out []*domain.Transaction
+	for _, tx := range r.transactions {
+		if tx.TenantID != tenantID || tx.Timestamp.Before(since) || !tx.Timestamp.Before(until) {
+			continue
+		}
+		copied := *tx
+		out = append(out, &copied)
+	}
+	return out
+}
+
+// SaveRuleConfig upserts a rule configuration version.
+func (r *Repository) SaveRuleConfig(ctx context.Context, tenantID string, rule *domain.RuleConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	stored := *rule
+	stored.TenantID = tenantID
+	key := versionKey{tenantID, rule.ID, rule.Version}
+	r.rules[key] = &stored
+
+	now := r.clock.Now()
+	version := r.ruleVersions[key]
+	if version == nil {
+		version = &domain.RuleVersion{RuleID: rule.ID, TenantID: tenantID, Version: rule.Version, CreatedAt: now}
+		r.ruleVersions[key] = version
+	}
+	version.Owner = rule.Owner
+	version.Enabled = rule.Enabled
+	version.UpdatedAt = now
+	return nil
+}
+
+// GetRuleConfig retrieves the latest enabled version of a rule.
+func (r *Repository) GetRuleConfig(ctx context.Context, tenantID string, ruleID string) (*domain.RuleConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var latest *domain.RuleConfig
+	for key, rule := range r.rules {
+		if key.tenantID != tenantID || key.id != ruleID || !rule.Enabled {
+			continue
+		}
+		if latest == nil || rule.Version > latest.Version {
+			latest = rule
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrNotFound
+	}
+	out := *latest
+	return &out, nil
+}
+
+// ListRuleConfigs retrieves all enabled rule configurations, ordered by name.
+func (r *Repository) ListRuleConfigs(ctx context.Context, tenantID string) ([]*domain.RuleConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.RuleConfig
+	for key, rule := range r.rules {
+		if key.tenantID != tenantID || !rule.Enabled {
+			continue
+		}
+		copied := *rule
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// DeleteRuleConfig soft-deletes every version of a rule.
+func (r *Repository) DeleteRuleConfig(ctx context.Context, tenantID string, ruleID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	found := false
+	for key, rule := range r.rules {
+		if key.tenantID == tenantID && key.id == ruleID {
+			rule.Enabled = false
+			r.ruleVersions[key].Enabled = false
+			r.ruleVersions[key].UpdatedAt = r.clock.Now()
+			found = true
+		}
+	}
+	if !found {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ListRuleVersions returns every stored version of the tenant's rules, by
+// rule ID and then oldest first.
+func (r *Repository) ListRuleVersions(ctx context.Context, tenantID string) ([]*domain.RuleVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.RuleVersion
+	for key, version := range r.ruleVersions {
+		if key.tenantID == tenantID {
+			copied := *version
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].RuleID != out[j].RuleID {
+			return out[i].RuleID < out[j].RuleID
+		}
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// ListAllRuleConfigs returns every tenant's enabled rule configurations.
+func (r *Repository) ListAllRuleConfigs(ctx context.Context) ([]*domain.RuleConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var out []*domain.RuleConfig
+	for _, rule := range r.rules {
+		if !rule.Enabled {
+			continue
+		}
+		copied := *rule
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// SaveEvaluation stores an evaluation. Duplicate IDs are rejected.
+func (r *Repository) SaveEvaluation(ctx context.Context, tenantID string, eval *domain.Evaluation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, eval.ID}
+	if _, ok := r.evaluations[key]; ok {
+		return fmt.Errorf("evaluation %s already exists", eval.ID)
+	}
+	stored := *eval
+	stored.TenantID = tenantID
+	r.evaluations[key] = &stored
+	return nil
+}
+
+// GetEvaluation retrieves an evaluation by ID.
+func (r *Repository) GetEvaluation(ctx context.Context, tenantID string, evalID string) (*domain.Evaluation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	eval, ok := r.evaluations[tenantKey{tenantID, evalID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *eval
+	return &out, nil
+}
+
+// GetEvaluationByTx returns a transaction's latest evaluation.
+func (r *Repository) GetEvaluationByTx(ctx context.Context, tenantID string, txID string) (*domain.Evaluation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var latest *domain.Evaluation
+	for key, eval := range r.evaluations {
+		if key.tenantID == tenantID && eval.TxID == txID && (latest == nil || eval.Timestamp.After(latest.Timestamp)) {
+			latest = eval
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrNotFound
+	}
+	out := *latest
+	return &out, nil
+}
+
+// ListEvaluations retrieves a tenant's evaluations, latest first.
+func (r *Repository) ListEvaluations(ctx context.Context, tenantID string, filter domain.EvaluationFilter) ([]*domain.Evaluation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var out []*domain.Evaluation
+	for key, eval := range r.evaluations {
+		if key.tenantID != tenantID {
+			continue
+		}
+		if filter.Status != "" && eval.Status != filter.Status {
+			continue
+		}
+		if (filter.MinScore != nil && eval.Score < *filter.MinScore) || (filter.MaxScore != nil && eval.Score > *filter.MaxScore) {
+			continue
+		}
+		if filter.TxID != "" && eval.TxID != filter.TxID {
+			continue
+		}
+		if (!filter.Since.IsZero() && eval.Timestamp.Before(filter.Since)) || (!filter.Until.IsZero() && !eval.Timestamp.Before(filter.Until)) {
+			continue
+		}
+		if filter.DebtorID != "" || filter.CreditorID != "" {
+			tx, ok := r.transactions[tenantKey{tenantID, eval.TxID}]
+			if !ok ||
+				(filter.DebtorID != "" && tx.DebtorID != filter.DebtorID) ||
+				(filter.CreditorID != "" && tx.CreditorID != filter.CreditorID) {
+				continue
+			}
+		}
+		if !eval.Contributed(filter.RuleID, filter.TypologyID, filter.MinContribution) {
+			continue
+		}
+		if after := filter.After; after != nil {
+			if eval.Timestamp.After(after.Timestamp) || (eval.Timestamp.Equal(after.Timestamp) && eval.ID >= after.ID) {
+				continue
+			}
+		}
+		copied := *eval
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Timestamp.Equal(out[j].Timestamp) {
+			return out[i].Timestamp.After(out[j].Timestamp)
+		}
+		return out[i].ID > out[j].ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// EvaluationScoreHistogram counts the tenant's evaluation scores from since
+// until until.
+func (r *Repository) EvaluationScoreHistogram(ctx context.Context, tenantID string, since, until time.Time) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, domain.ScoreHistogramBuckets)
+	for key, eval := range r.evaluations {
+		if key.tenantID == tenantID && !eval.Timestamp.Before(since) && eval.Timestamp.Before(until) {
+			counts[domain.ScoreHistogramBucket(eval.Score)]++
+		}
+	}
+	return counts, nil
+}
+
+// TypologyScoreHistograms counts the tenant's typology scores from since
+// until until, per typology.
+func (r *Repository) TypologyScoreHistograms(ctx context.Context, tenantID string, since, until time.Time) (map[string][]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	histograms := make(map[string][]int64)
+	for key, eval := range r.evaluations {
+		if key.tenantID != tenantID || eval.Timestamp.Before(since) || !eval.Timestamp.Before(until) {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, result := range eval.TypologyResults {
+			if seen[result.TypologyID] {
+				continue
+			}
+			seen[result.TypologyID] = true
+			if histograms[result.TypologyID] == nil {
+				histograms[result.TypologyID] = make([]int64, domain.ScoreHistogramBuckets)
+			}
+			histograms[result.TypologyID][domain.ScoreHistogramBucket(result.Score)]++
+		}
+	}
+	return histograms, nil
+}
+
+// ListTenantActivity summarizes the evaluations of every tenant, sorted by tenant ID.
+func (r *Repository) ListTenantActivity(ctx context.Context, since time.Time) ([]*domain.TenantActivity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	byTenant := make(map[string]*domain.TenantActivity)
+	for key, eval := range r.evaluations {
+		a := byTenant[key.tenantID]
+		if a == nil {
+			a = &domain.TenantActivity{TenantID: key.tenantID}
+			byTenant[key.tenantID] = a
+		}
+		if !eval.Timestamp.Before(since) {
+			a.Evaluations++
+			if eval.Status == domain.StatusAlert {
+				a.Alerts++
+			}
+		}
+		if eval.Timestamp.After(a.LastEvaluationAt) {
+			a.LastEvaluationAt = eval.Timestamp
+		}
+	}
+
+	out := make([]*domain.TenantActivity, 0, len(byTenant))
+	for _, a := range byTenant {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TenantID < out[j].TenantID })
+	return out, nil
+}
+
+// ListTenantBenchmarks summarizes the evaluations and alert dispositions of
+// every tenant in the window, sorted by tenant ID.
+func (r *Repository) ListTenantBenchmarks(ctx context.Context, since, until time.Time) ([]*domain.TenantBenchmark, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	inWindow := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+	byTenant := make(map[string]*domain.TenantBenchmark)
+	totalMs := make(map[string]int64)
+	for key, eval := range r.evaluations {
+		if !inWindow(eval.Timestamp) {
+			continue
+		}
+		b := byTenant[key.tenantID]
+		if b == nil {
+			b = &domain.TenantBenchmark{TenantID: key.tenantID}
+			byTenant[key.tenantID] = b
+		}
+		b.Evaluations++
+		if eval.Status == domain.StatusAlert {
+			b.Alerts++
+		}
+		totalMs[key.tenantID] += eval.Metadata.TotalMs
+	}
+	for key, alert := range r.alerts {
+		b := byTenant[key.tenantID]
+		if b == nil || !inWindow(alert.CreatedAt) {
+			continue
+		}
+		switch alert.Status {
+		case domain.AlertClosedConfirmed:
+			b.ClosedConfirmed++
+		case domain.AlertClosedFalsePositive:
+			b.ClosedFalsePositive++
+		}
+	}
+
+	out := make([]*domain.TenantBenchmark, 0, len(byTenant))
+	for tenantID, b := range byTenant {
+		b.MeanTotalMs = float64(totalMs[tenantID]) / float64(b.Evaluations)
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TenantID < out[j].TenantID })
+	return out, nil
+}
+
+// ListTenantIDs returns every tenant with stored transactions or
+// evaluations, sorted by ID.
+func (r *Repository) ListTenantIDs(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	seen := make(map[string]bool)
+	for _, tx := range r.transactions {
+		seen[tx.TenantID] = true
+	}
+	for key := range r.evaluations {
+		seen[key.tenantID] = true
+	}
+	out := make([]string, 0, len(seen))
+	for tenantID := range seen {
+		out = append(out, tenantID)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// PurgeTenantData deletes the tenant's activity dated before the given time.
+// The evaluation log is deleted whole once its newest record is before it.
+func (r *Repository) PurgeTenantData(ctx context.Context, tenantID string, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for key, tx := range r.transactions {
+		if key.tenantID == tenantID && tx.CreatedAt.Before(before) {
+			delete(r.transactions, key)
+			purged++
+		}
+	}
+	for key, eval := range r.evaluations {
+		if key.tenantID == tenantID && eval.Timestamp.Before(before) {
+			delete(r.evaluations, key)
+			purged++
+		}
+	}
+	for key, alert := range r.alerts {
+		if key.tenantID == tenantID && alert.CreatedAt.Before(before) {
+			delete(r.alerts, key)
+			purged++
+		}
+	}
+	for key, events := range r.alertEvents {
+		if key.tenantID != tenantID {
+			continue
+		}
+		var n int64
+		r.alertEvents[key], n = purgeBefore(events, before, func(e *domain.AlertEvent) time.Time { return e.At })
+		purged += n
+	}
+	var n int64
+	r.samples[tenantID], n = purgeBefore(r.samples[tenantID], before, func(s *domain.ActivationSample) time.Time { return s.CreatedAt })
+	purged += n
+	r.outcomes[tenantID], n = purgeBefore(r.outcomes[tenantID], before, func(o *domain.EvaluationOutcome) time.Time { return o.CreatedAt })
+	purged += n
+	r.deadLetters[tenantID], n = purgeBefore(r.deadLetters[tenantID], before, func(d *domain.DeadLetter) time.Time { return d.FailedAt })
+	purged += n
+	for key, delivery := range r.deliveries {
+		if key.tenantID == tenantID && delivery.CreatedAt.Before(before) {
+			delete(r.deliveries, key)
+			purged++
+		}
+	}
+	for id, job := range r.jobs {
+		if job.TenantID != tenantID || !job.CreatedAt.Before(before) {
+			continue
+		}
+		delete(r.jobs, id)
+		purged++
+		for key := range r.jobFiles {
+			if key.tenantID == tenantID && strings.HasPrefix(key.id, id+"\x00") {
+				delete(r.jobFiles, key)
+				purged++
+			}
+		}
+	}
+	for key, edge := range r.edges {
+		if key.tenantID == tenantID && edge.LastSeen.Before(before) {
+			delete(r.edges, key)
+			purged++
+		}
+	}
+	for key, claim := range r.claims {
+		if key.tenantID == tenantID && claim.ClaimedAt.Before(before) {
+			delete(r.claims, key)
+			purged++
+		}
+	}
+	for key, baseline := range r.baselines {
+		if key.tenantID == tenantID && baseline.UpdatedAt.Before(before) {
+			delete(r.baselines, key)
+			purged++
+		}
+	}
+	for key, balance := range r.balances {
+		if key.tenantID == tenantID && balance.UpdatedAt.Before(before) {
+			delete(r.balances, key)
+			purged++
+		}
+	}
+	if log := r.evalLog[tenantID]; len(log) > 0 && log[len(log)-1].CreatedAt.Before(before) {
+		purged += int64(len(log))
+		delete(r.evalLog, tenantID)
+	}
+	return purged, nil
+}
+
+// ExpireTenantData deletes the tenant's activity of a retention class dated
+// before the given time. Archived rows are the records as JSON objects,
+// under the table names of the SQL repository.
+func (r *Repository) ExpireTenantData(ctx context.Context, tenantID string, class string, before time.Time, archive domain.RetentionArchive) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return 0, err
+	}
+
+	// Each expired record with how to delete it, so nothing is deleted
+	// before the archive is closed
+	type expiry struct {
+		table  string
+		record any
+		drop   func()
+	}
+	var expired []expiry
+	add := func(table string, record any, drop func()) {
+		expired = append(expired, expiry{table, record, drop})
+	}
+
+	switch class {
+	case domain.RetentionTransactions:
+		for key, tx := range r.transactions {
+			if key.tenantID == tenantID && tx.CreatedAt.Before(before) {
+				add("transactions", tx, func() { delete(r.transactions, key) })
+			}
+		}
+		for key, edge := range r.edges {
+			if key.tenantID == tenantID && edge.LastSeen.Before(before) {
+				add("counterparty_edges", edge, func() { delete(r.edges, key) })
+			}
+		}
+	case domain.RetentionEvaluations:
+		for key, eval := range r.evaluations {
+			if key.tenantID == tenantID && eval.Timestamp.Before(before) {
+				add("evaluations", eval, func() { delete(r.evaluations, key) })
+			}
+		}
+		for _, outcome := range r.outcomes[tenantID] {
+			if outcome.CreatedAt.Before(before) {
+				add("evaluation_outcomes", outcome, func() {})
+			}
+		}
+		for _, sample := range r.samples[tenantID] {
+			if sample.CreatedAt.Before(before) {
+				add("activation_samples", sample, func() {})
+			}
+		}
+		if log := r.evalLog[tenantID]; len(log) > 0 && log[len(log)-1].CreatedAt.Before(before) {
+			for _, record := range log {
+				add("evaluation_log", record, func() {})
+			}
+		}
+	case domain.RetentionAlerts:
+		for key, alert := range r.alerts {
+			if key.tenantID == tenantID && alert.CreatedAt.Before(before) {
+				add("alerts", alert, func() { delete(r.alerts, key) })
+			}
+		}
+		for key, events := range r.alertEvents {
+			if key.tenantID != tenantID {
+				continue
+			}
+			for _, event := range events {
+				if event.At.Before(before) {
+					add("alert_events", event, func() {})
+				}
+			}
+		}
+	default:
+		return 0, fmt.Errorf("%w: unknown retention class %q", repository.ErrInvalidInput, class)
+	}
+
+	if archive != nil {
+		for _, e := range expired {
+			data, err := json.Marshal(e.record)
+			if err != nil {
+				return 0, err
+			}
+			var row map[string]any
+			if err := json.Unmarshal(data, &row); err != nil {
+				return 0, err
+			}
+			if err := archive.ArchiveRow(e.table, row); err != nil {
+				return 0, err
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, e := range expired {
+		e.drop()
+	}
+	switch class {
+	case domain.RetentionEvaluations:
+		r.outcomes[tenantID], _ = purgeBefore(r.outcomes[tenantID], before, func(o *domain.EvaluationOutcome) time.Time { return o.CreatedAt })
+		r.samples[tenantID], _ = purgeBefore(r.samples[tenantID], before, func(s *domain.ActivationSample) time.Time { return s.CreatedAt })
+		if log := r.evalLog[tenantID]; len(log) > 0 && log[len(log)-1].CreatedAt.Before(before) {
+			delete(r.evalLog, tenantID)
+		}
+	case domain.RetentionAlerts:
+		for key, events := range r.alertEvents {
+			if key.tenantID == tenantID {
+				r.alertEvents[key], _ = purgeBefore(events, before, func(e *domain.AlertEvent) time.Time { return e.At })
+			}
+		}
+	}
+	return int64(len(expired)), nil
+}
+
+// purgeBefore drops the items dated before the given time and returns the
+// rest with the number dropped.
+func purgeBefore[T any](items []T, before time.Time, at func(T) time.Time) ([]T, int64) {
+	var kept []T
+	for _, item := range items {
+		if !at(item).Before(before) {
+			kept = append(kept, item)
+		}
+	}
+	return kept, int64(len(items) - len(kept))
+}
+
+// Evaluations returns every stored evaluation for a tenant, ordered by
+// timestamp. It is not part of domain.Repository; tests use it to assert on
+// what a pipeline saved.
+func (r *Repository) Evaluations(tenantID string) []*domain.Evaluation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []*domain.Evaluation
+	for key, eval := range r.evaluations {
+		if key.tenantID != tenantID {
+			continue
+		}
+		copied := *eval
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out
+}
+
+// AppendEvaluationLog appends the next log record. Duplicate sequence numbers are rejected.
+func (r *Repository) AppendEvaluationLog(ctx context.Context, tenantID string, record *domain.EvaluationLogRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	for _, existing := range r.evalLog[tenantID] {
+		if existing.Seq == record.Seq {
+			return fmt.Errorf("evaluation log seq %d already exists", record.Seq)
+		}
+	}
+	stored := *record
+	stored.TenantID = tenantID
+	records := append(r.evalLog[tenantID], &stored)
+	sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+	r.evalLog[tenantID] = records
+	return nil
+}
+
+// GetLastEvaluationLog retrieves the newest log record.
+func (r *Repository) GetLastEvaluationLog(ctx context.Context, tenantID string) (*domain.EvaluationLogRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	records := r.evalLog[tenantID]
+	if len(records) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	out := *records[len(records)-1]
+	return &out, nil
+}
+
+// ListEvaluationLog retrieves up to limit records with seq > afterSeq, in chain order.
+func (r *Repository) ListEvaluationLog(ctx context.Context, tenantID string, afterSeq int64, limit int) ([]*domain.EvaluationLogRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.EvaluationLogRecord
+	for _, rec := range r.evalLog[tenantID] {
+		if rec.Seq <= afterSeq {
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		copied := *rec
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// AppendAuditEntry appends the next audit log entry. Duplicate sequence numbers are rejected.
+func (r *Repository) AppendAuditEntry(ctx context.Context, tenantID string, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	for _, existing := range r.auditLog[tenantID] {
+		if existing.Seq == entry.Seq {
+			return fmt.Errorf("audit log seq %d already exists", entry.Seq)
+		}
+	}
+	stored := *entry
+	stored.TenantID = tenantID
+	entries := append(r.auditLog[tenantID], &stored)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	r.auditLog[tenantID] = entries
+	return nil
+}
+
+// GetLastAuditEntry retrieves the newest audit log entry.
+func (r *Repository) GetLastAuditEntry(ctx context.Context, tenantID string) (*domain.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	entries := r.auditLog[tenantID]
+	if len(entries) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	out := *entries[len(entries)-1]
+	return &out, nil
+}
+
+// GetAuditEntry retrieves one audit log entry.
+func (r *Repository) GetAuditEntry(ctx context.Context, tenantID string, seq int64) (*domain.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range r.auditLog[tenantID] {
+		if entry.Seq == seq {
+			out := *entry
+			return &out, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// ListAuditEntries retrieves audit log entries matching the filter, in chain order.
+func (r *Repository) ListAuditEntries(ctx context.Context, tenantID string, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var out []*domain.AuditEntry
+	for _, entry := range r.auditLog[tenantID] {
+		if entry.Seq <= filter.AfterSeq ||
+			(filter.Resource != "" && entry.Resource != filter.Resource) ||
+			(filter.ResourceID != "" && entry.ResourceID != filter.ResourceID) ||
+			(filter.Action != "" && entry.Action != filter.Action) {
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		copied := *entry
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// SaveTypology upserts a typology version.
+func (r *Repository) SaveTypology(ctx context.Context, tenantID string, typology *domain.Typology) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := versionKey{tenantID, typology.ID, typology.Version}
+	now := r.clock.Now()
+	stored := *typology
+	stored.TenantID = tenantID
+	stored.CreatedAt = now
+	if existing, ok := r.typologies[key]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	stored.UpdatedAt = now
+	r.typologies[key] = &stored
+	return nil
+}
+
+// GetTypology retrieves the latest enabled version of a typology.
+func (r *Repository) GetTypology(ctx context.Context, tenantID string, typologyID string) (*domain.Typology, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var latest *domain.Typology
+	for key, t := range r.typologies {
+		if key.tenantID != tenantID || key.id != typologyID || !t.Enabled {
+			continue
+		}
+		if latest == nil || t.Version > latest.Version {
+			latest = t
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrNotFound
+	}
+	out := *latest
+	return &out, nil
+}
+
+// ListTypologies retrieves all enabled typologies, ordered by name.
+func (r *Repository) ListTypologies(ctx context.Context, tenantID string) ([]*domain.Typology, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.Typology
+	for key, t := range r.typologies {
+		if key.tenantID != tenantID || !t.Enabled {
+			continue
+		}
+		copied := *t
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// ListAllTypologies returns every tenant's enabled typologies.
+func (r *Repository) ListAllTypologies(ctx context.Context) ([]*domain.Typology, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var out []*domain.Typology
+	for _, t := range r.typologies {
+		if !t.Enabled {
+			continue
+		}
+		copied := *t
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+// DeleteTypology soft-deletes every version of a typology.
+func (r *Repository) DeleteTypology(ctx context.Context, tenantID string, typologyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	found := false
+	for key, t := range r.typologies {
+		if key.tenantID == tenantID && key.id == typologyID {
+			t.Enabled = false
+			t.UpdatedAt = r.clock.Now()
+			found = true
+		}
+	}
+	if !found {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// SavePartyKYC upserts a party's KYC profile.
+func (r *Repository) SavePartyKYC(ctx context.Context, tenantID string, kyc *domain.PartyKYC) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if kyc.EntityID == "" {
+		return fmt.Errorf("%w: entityID is required", repository.ErrInvalidInput)
+	}
+
+	stored := *kyc
+	stored.TenantID = tenantID
+	stored.UpdatedAt = r.clock.Now()
+	r.parties[tenantKey{tenantID, kyc.EntityID}] = &stored
+	return nil
+}
+
+// GetPartyKYC retrieves a party's KYC profile.
+func (r *Repository) GetPartyKYC(ctx context.Context, tenantID string, entityID string) (*domain.PartyKYC, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	kyc, ok := r.parties[tenantKey{tenantID, entityID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *kyc
+	return &out, nil
+}
+
+// ListPartyKYC retrieves the tenant's KYC profiles matching the filter,
+// sorted by entity ID.
+func (r *Repository) ListPartyKYC(ctx context.Context, tenantID string, filter domain.PartyFilter) ([]*domain.PartyKYC, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.PartyKYC
+	for key, kyc := range r.parties {
+		if key.tenantID != tenantID {
+			continue
+		}
+		if filter.RiskRating != "" && kyc.RiskRating != filter.RiskRating {
+			continue
+		}
+		if filter.Segment != "" && kyc.Segment != filter.Segment {
+			continue
+		}
+		copied := *kyc
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EntityID < out[j].EntityID })
+	return out, nil
+}
+
+// DeletePartyKYC deletes a party's KYC profile.
+func (r *Repository) DeletePartyKYC(ctx context.Context, tenantID string, entityID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, entityID}
+	if _, ok := r.parties[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.parties, key)
+	return nil
+}
+
+// SaveCorridorRisk upserts a corridor risk override.
+func (r *Repository) SaveCorridorRisk(ctx context.Context, tenantID string, corridor *domain.CorridorRisk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if corridor.Origin == "" || corridor.Destination == "" {
+		return fmt.Errorf("%w: origin and destination are required", repository.ErrInvalidInput)
+	}
+
+	stored := *corridor
+	stored.TenantID = tenantID
+	stored.UpdatedAt = r.clock.Now()
+	r.corridors[tenantKey{tenantID, corridorID(corridor.Origin, corridor.Destination)}] = &stored
+	return nil
+}
+
+// ListCorridorRisks retrieves all corridor overrides, ordered by origin and destination.
+func (r *Repository) ListCorridorRisks(ctx context.Context, tenantID string) ([]*domain.CorridorRisk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.CorridorRisk
+	for key, c := range r.corridors {
+		if key.tenantID != tenantID {
+			continue
+		}
+		copied := *c
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return corridorID(out[i].Origin, out[i].Destination) < corridorID(out[j].Origin, out[j].Destination)
+	})
+	return out, nil
+}
+
+// DeleteCorridorRisk removes a corridor override.
+func (r *Repository) DeleteCorridorRisk(ctx context.Context, tenantID string, origin string, destination string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, corridorID(origin, destination)}
+	if _, ok := r.corridors[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.corridors, key)
+	return nil
+}
+
+func corridorID(origin, destination string) string {
+	return origin + "\x00" + destination
+}
+
+// SaveWatchlistEntry upserts a watchlist entry.
+func (r *Repository) SaveWatchlistEntry(ctx context.Context, tenantID string, entry *domain.WatchlistEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if entry.ID == "" || entry.ListType == "" {
+		return fmt.Errorf("%w: id and listType are required", repository.ErrInvalidInput)
+	}
+	if entry.PartyID == "" && entry.AccountID == "" && entry.Name == "" {
+		return fmt.Errorf("%w: partyId, accountId or name is required", repository.ErrInvalidInput)
+	}
+
+	key := tenantKey{tenantID, entry.ID}
+	stored := *entry
+	stored.TenantID = tenantID
+	stored.UpdatedAt = r.clock.Now()
+	if existing, ok := r.watchlist[key]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	} else if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = stored.UpdatedAt
+	}
+	r.watchlist[key] = &stored
+	return nil
+}
+
+// GetWatchlistEntry retrieves a watchlist entry.
+func (r *Repository) GetWatchlistEntry(ctx context.Context, tenantID string, entryID string) (*domain.WatchlistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	entry, ok := r.watchlist[tenantKey{tenantID, entryID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+// ListWatchlistEntries lists watchlist entries ordered by normalized name,
+// optionally of one list type.
+func (r *Repository) ListWatchlistEntries(ctx context.Context, tenantID string, listType string, offset, limit int) ([]*domain.WatchlistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	out := r.watchlistWhere(tenantID, func(e *domain.WatchlistEntry) bool {
+		return listType == "" || e.ListType == listType
+	})
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if ka, kb := domain.NormalizeWatchlistName(a.Name), domain.NormalizeWatchlistName(b.Name); ka != kb {
+			return ka < kb
+		}
+		if a.PartyID != b.PartyID {
+			return a.PartyID < b.PartyID
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.ID < b.ID
+	})
+
+	if offset >= len(out) {
+		return nil, nil
+	}
+	out = out[offset:]
+	if limit >= 0 && limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// DeleteWatchlistEntry removes a watchlist entry.
+func (r *Repository) DeleteWatchlistEntry(ctx context.Context, tenantID string, entryID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, entryID}
+	if _, ok := r.watchlist[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.watchlist, key)
+	return nil
+}
+
+// MatchWatchlist returns the entries naming the subject, like the SQL
+// repository.
+func (r *Repository) MatchWatchlist(ctx context.Context, tenantID string, subject domain.WatchlistSubject) ([]*domain.WatchlistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	nameKey := domain.NormalizeWatchlistName(subject.Name)
+	out := r.watchlistWhere(tenantID, func(e *domain.WatchlistEntry) bool {
+		switch {
+		case e.PartyID != "" && e.PartyID == subject.PartyID:
+			return true
+		case e.AccountID != "" && e.AccountID == subject.AccountID:
+			return true
+		case nameKey != "" && domain.NormalizeWatchlistName(e.Name) == nameKey:
+			return e.Country == "" || subject.Country == "" || e.Country == subject.Country
+		}
+		return false
+	})
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ListType != out[j].ListType {
+			return out[i].ListType < out[j].ListType
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// watchlistWhere copies a tenant's watchlist entries that satisfy keep.
+func (r *Repository) watchlistWhere(tenantID string, keep func(*domain.WatchlistEntry) bool) []*domain.WatchlistEntry {
+	var out []*domain.WatchlistEntry
+	for key, e := range r.watchlist {
+		if key.tenantID != tenantID || !keep(e) {
+			continue
+		}
+		copied := *e
+		out = append(out, &copied)
+	}
+	return out
+}
+
+// SaveJob upserts a background job.
+func (r *Repository) SaveJob(ctx context.Context, tenantID string, job *domain.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if job.ID == "" {
+		return fmt.Errorf("%w: job id is required", repository.ErrInvalidInput)
+	}
+
+	stored := *job
+	stored.TenantID = tenantID
+	stored.UpdatedAt = r.clock.Now()
+	if existing, ok := r.jobs[job.ID]; ok {
+		// Like the SQL upsert, type, tenant and creation time are fixed on insert
+		stored.TenantID = existing.TenantID
+		stored.Type = existing.Type
+		stored.CreatedAt = existing.CreatedAt
+	}
+	if job.Params != nil {
+		params := *job.Params
+		stored.Params = &params
+	}
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+// GetJob retrieves a background job by ID.
+func (r *Repository) GetJob(ctx context.Context, tenantID string, jobID string) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	job, ok := r.jobs[jobID]
+	if !ok || job.TenantID != tenantID {
+		return nil, repository.ErrNotFound
+	}
+	out := *job
+	return &out, nil
+}
+
+// ListJobs retrieves a tenant's most recent jobs, newest first.
+func (r *Repository) ListJobs(ctx context.Context, tenantID string, limit int) ([]*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.Job
+	for _, job := range r.jobs {
+		if job.TenantID != tenantID {
+			continue
+		}
+		copied := *job
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit >= 0 && limit < len(out) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// SaveJobFile stores an input or result file for a job.
+func (r *Repository) SaveJobFile(ctx context.Context, tenantID string, jobID string, kind string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	r.jobFiles[tenantKey{tenantID, jobID + "\x00" + kind}] = append([]byte(nil), data...)
+	return nil
+}
+
+// GetJobFile retrieves an input or result file for a job.
+func (r *Repository) GetJobFile(ctx context.Context, tenantID string, jobID string, kind string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	data, ok := r.jobFiles[tenantKey{tenantID, jobID + "\x00" + kind}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// SaveFeatureFlag upserts a feature flag override.
+func (r *Repository) SaveFeatureFlag(ctx context.Context, tenantID string, flag *domain.FeatureFlag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if flag.Name == "" {
+		return fmt.Errorf("%w: flag name is required", repository.ErrInvalidInput)
+	}
+
+	stored := *flag
+	stored.TenantID = tenantID
+	stored.UpdatedAt = r.clock.Now()
+	r.flags[tenantKey{tenantID, flag.Name}] = &stored
+	return nil
+}
+
+// ListFeatureFlags retrieves a tenant's feature flag overrides, ordered by name.
+func (r *Repository) ListFeatureFlags(ctx context.Context, tenantID string) ([]*domain.FeatureFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.FeatureFlag
+	for key, flag := range r.flags {
+		if key.tenantID != tenantID {
+			continue
+		}
+		copied := *flag
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeleteFeatureFlag removes a feature flag override.
+func (r *Repository) DeleteFeatureFlag(ctx context.Context, tenantID string, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, name}
+	if _, ok := r.flags[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.flags, key)
+	return nil
+}
+
+// SaveAlert stores an alert. An existing alert is left unchanged.
+func (r *Repository) SaveAlert(ctx context.Context, tenantID string, alert *domain.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if alert.ID == "" {
+		return fmt.Errorf("%w: alert ID is required", repository.ErrInvalidInput)
+	}
+
+	key := tenantKey{tenantID, alert.ID}
+	if _, ok := r.alerts[key]; ok {
+		return nil
+	}
+	stored := *alert
+	stored.TenantID = tenantID
+	stored.History = nil
+	if stored.Status == "" {
+		stored.Status = domain.AlertOpen
+	}
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = stored.CreatedAt
+	}
+	r.alerts[key] = &stored
+	return nil
+}
+
+// GetAlert retrieves an alert.
+func (r *Repository) GetAlert(ctx context.Context, tenantID string, alertID string) (*domain.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	alert, ok := r.alerts[tenantKey{tenantID, alertID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *alert
+	return &out, nil
+}
+
+// ListAlerts retrieves a tenant's alerts, newest first.
+func (r *Repository) ListAlerts(ctx context.Context, tenantID string, filter domain.AlertFilter) ([]*domain.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.Alert
+	for key, alert := range r.alerts {
+		if key.tenantID != tenantID || (filter.Unacked && alert.Acked()) {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, alert.Status) {
+			continue
+		}
+		if (filter.Assignee != "" && alert.Assignee != filter.Assignee) ||
+			(filter.TxID != "" && alert.TxID != filter.TxID) ||
+			alert.Score < filter.MinScore {
+			continue
+		}
+		if filter.RuleID != "" || filter.TypologyID != "" {
+			eval, ok := r.evaluations[tenantKey{tenantID, alert.ID}]
+			if !ok || !eval.Contributed(filter.RuleID, filter.TypologyID, filter.MinContribution) {
+				continue
+			}
+		}
+		a := *alert
+		out = append(out, &a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// AckAlert records an acknowledgment. Returns repository.ErrNotFound if the
+// alert doesn't exist or is already acknowledged.
+func (r *Repository) AckAlert(ctx context.Context, tenantID string, alertID string, by string, note string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	alert, ok := r.alerts[tenantKey{tenantID, alertID}]
+	if !ok || alert.Acked() {
+		return repository.ErrNotFound
+	}
+	at = at.UTC()
+	alert.AckedAt = &at
+	alert.AckedBy = by
+	alert.AckNote = note
+	return nil
+}
+
+// EscalateAlert moves an unacknowledged alert from level-1 to level.
+func (r *Repository) EscalateAlert(ctx context.Context, tenantID string, alertID string, level int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	alert, ok := r.alerts[tenantKey{tenantID, alertID}]
+	if !ok || alert.Acked() || alert.EscalationLevel != level-1 {
+		return repository.ErrNotFound
+	}
+	alert.EscalationLevel = level
+	alert.LastNotifiedAt = at.UTC()
+	return nil
+}
+
+// UpdateAlertCase sets an alert's status and assignee if it is still in fromStatus.
+func (r *Repository) UpdateAlertCase(ctx context.Context, tenantID string, alertID string, fromStatus string, status string, assignee string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	alert, ok := r.alerts[tenantKey{tenantID, alertID}]
+	if !ok || alert.Status != fromStatus {
+		return repository.ErrNotFound
+	}
+	alert.Status = status
+	alert.Assignee = assignee
+	alert.UpdatedAt = at.UTC()
+	return nil
+}
+
+// SaveAlertEvent appends an entry to an alert's history.
+func (r *Repository) SaveAlertEvent(ctx context.Context, tenantID string, event *domain.AlertEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if event.ID == "" || event.AlertID == "" {
+		return fmt.Errorf("%w: event and alert ID are required", repository.ErrInvalidInput)
+	}
+
+	key := tenantKey{tenantID, event.AlertID}
+	stored := *event
+	r.alertEvents[key] = append(r.alertEvents[key], &stored)
+	return nil
+}
+
+// ListAlertEvents retrieves an alert's history, oldest first.
+func (r *Repository) ListAlertEvents(ctx context.Context, tenantID string, alertID string) ([]*domain.AlertEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.AlertEvent
+	for _, event := range r.alertEvents[tenantKey{tenantID, alertID}] {
+		e := *event
+		out = append(out, &e)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// ListDueAlerts retrieves unacknowledged, unsuppressed alerts across all
+// tenants that were last notified before notifiedBefore and are below
+// maxLevel, oldest first.
+func (r *Repository) ListDueAlerts(ctx context.Context, notifiedBefore time.Time, maxLevel int, limit int) ([]*domain.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var out []*domain.Alert
+	for _, alert := range r.alerts {
+		if alert.Acked() || !alert.LastNotifiedAt.Before(notifiedBefore) || alert.EscalationLevel >= maxLevel || alert.SuppressedByMaintenance != "" {
+			continue
+		}
+		a := *alert
+		out = append(out, &a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastNotifiedAt.Before(out[j].LastNotifiedAt) })
+
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// SaveActivationSample stores a sampled rule activation.
+func (r *Repository) SaveActivationSample(ctx context.Context, tenantID string, sample *domain.ActivationSample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	stored := *sample
+	stored.TenantID = tenantID
+	r.samples[tenantID] = append(r.samples[tenantID], &stored)
+	return nil
+}
+
+// ListActivationSamples retrieves a rule's samples, newest first. A
+// non-positive limit defaults to 50.
+func (r *Repository) ListActivationSamples(ctx context.Context, tenantID string, ruleID string, limit int) ([]*domain.ActivationSample, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var out []*domain.ActivationSample
+	samples := r.samples[tenantID]
+	for i := len(samples) - 1; i >= 0 && len(out) < limit; i-- {
+		if samples[i].RuleID == ruleID {
+			s := *samples[i]
+			out = append(out, &s)
+		}
+	}
+	return out, nil
+}
+
+// SaveOutcome stores an evaluation outcome. An outcome with an existing ID
+// is left unchanged.
+func (r *Repository) SaveOutcome(ctx context.Context, tenantID string, outcome *domain.EvaluationOutcome) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	for _, o := range r.outcomes[tenantID] {
+		if o.ID == outcome.ID {
+			return nil
+		}
+	}
+	stored := *outcome
+	stored.TenantID = tenantID
+	r.outcomes[tenantID] = append(r.outcomes[tenantID], &stored)
+	return nil
+}
+
+// ListOutcomes retrieves a tenant's outcomes, latest first. A non-positive
+// limit defaults to 100.
+func (r *Repository) ListOutcomes(ctx context.Context, tenantID string, filter domain.OutcomeFilter) ([]*domain.EvaluationOutcome, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var out []*domain.EvaluationOutcome
+	for _, o := range r.outcomes[tenantID] {
+		if filter.EvaluationID != "" && o.EvaluationID != filter.EvaluationID {
+			continue
+		}
+		if filter.PartyID != "" && o.DebtorID != filter.PartyID && o.CreditorID != filter.PartyID {
+			continue
+		}
+		if filter.Outcome != "" && o.Outcome != filter.Outcome {
+			continue
+		}
+		if filter.Label != "" && o.Label != filter.Label {
+			continue
+		}
+		if !filter.Since.IsZero() && o.OccurredAt.Before(filter.Since) {
+			continue
+		}
+		copied := *o
+		out = append(out, &copied)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].OccurredAt.Equal(out[j].OccurredAt) {
+			return out[i].OccurredAt.After(out[j].OccurredAt)
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// SaveWebhook stores a webhook.
+func (r *Repository) SaveWebhook(ctx context.Context, tenantID string, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if webhook.ID == "" || webhook.URL == "" {
+		return fmt.Errorf("%w: webhook ID and URL are required", repository.ErrInvalidInput)
+	}
+
+	stored := *webhook
+	stored.TenantID = tenantID
+	if stored.Feed == "" {
+		stored.Feed = domain.WebhookFeedAlerts
+	}
+	r.webhooks[tenantKey{tenantID, webhook.ID}] = &stored
+	return nil
+}
+
+// GetWebhook retrieves a webhook, including its secret.
+func (r *Repository) GetWebhook(ctx context.Context, tenantID string, webhookID string) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	webhook, ok := r.webhooks[tenantKey{tenantID, webhookID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	out := *webhook
+	return &out, nil
+}
+
+// ListWebhooks retrieves a tenant's webhooks in creation order.
+func (r *Repository) ListWebhooks(ctx context.Context, tenantID string) ([]*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.Webhook
+	for key, webhook := range r.webhooks {
+		if key.tenantID == tenantID {
+			w := *webhook
+			out = append(out, &w)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// DeleteWebhook removes a webhook. Its deliveries are kept.
+func (r *Repository) DeleteWebhook(ctx context.Context, tenantID string, webhookID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, webhookID}
+	if _, ok := r.webhooks[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.webhooks, key)
+	return nil
+}
+
+// SaveWebhookDelivery upserts a webhook delivery.
+func (r *Repository) SaveWebhookDelivery(ctx context.Context, tenantID string, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if delivery.ID == "" {
+		return fmt.Errorf("%w: delivery ID is required", repository.ErrInvalidInput)
+	}
+
+	stored := *delivery
+	stored.TenantID = tenantID
+	r.deliveries[tenantKey{tenantID, delivery.ID}] = &stored
+	return nil
+}
+
+// ListWebhookDeliveries retrieves a webhook's deliveries, newest first. A
+// non-positive limit defaults to 100.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, tenantID string, webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var out []*domain.WebhookDelivery
+	for key, delivery := range r.deliveries {
+		if key.tenantID == tenantID && delivery.WebhookID == webhookID {
+			d := *delivery
+			out = append(out, &d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// ClaimWebhookDelivery counts an attempt on a pending delivery and pushes its
+// next attempt to leaseUntil.
+func (r *Repository) ClaimWebhookDelivery(ctx context.Context, tenantID string, deliveryID string, attempts int, leaseUntil time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	delivery, ok := r.deliveries[tenantKey{tenantID, deliveryID}]
+	if !ok || delivery.Status != domain.DeliveryPending || delivery.Attempts != attempts {
+		return repository.ErrNotFound
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = leaseUntil.UTC()
+	return nil
+}
+
+// ListDueWebhookDeliveries retrieves pending deliveries across all tenants
+// whose next attempt is before before, oldest first.
+func (r *Repository) ListDueWebhookDeliveries(ctx context.Context, before time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var out []*domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status != domain.DeliveryPending || delivery.NextAttemptAt.After(before) {
+			continue
+		}
+		d := *delivery
+		out = append(out, &d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NextAttemptAt.Before(out[j].NextAttemptAt) })
+
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// SaveDeadLetter stores a dead letter.
+func (r *Repository) SaveDeadLetter(ctx context.Context, tenantID string, dead *domain.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	for _, d := range r.deadLetters[tenantID] {
+		if d.ID == dead.ID {
+			return fmt.Errorf("dead letter %s already exists", dead.ID)
+		}
+	}
+
+	stored := *dead
+	stored.TenantID = tenantID
+	r.deadLetters[tenantID] = append(r.deadLetters[tenantID], &stored)
+	return nil
+}
+
+// GetDeadLetter retrieves a dead letter.
+func (r *Repository) GetDeadLetter(ctx context.Context, tenantID string, deadLetterID string) (*domain.DeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	for _, d := range r.deadLetters[tenantID] {
+		if d.ID == deadLetterID {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// ListDeadLetters retrieves a tenant's dead letters, latest first. A
+// non-positive limit defaults to 100.
+func (r *Repository) ListDeadLetters(ctx context.Context, tenantID string, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var out []*domain.DeadLetter
+	for _, d := range r.deadLetters[tenantID] {
+		if filter.Pending && d.ReplayedAt != nil {
+			continue
+		}
+		copied := *d
+		out = append(out, &copied)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].FailedAt.Equal(out[j].FailedAt) {
+			return out[i].FailedAt.After(out[j].FailedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// MarkDeadLetterReplayed records that a dead letter was replayed. It fails
+// with repository.ErrNotFound if it doesn't exist or was already replayed.
+func (r *Repository) MarkDeadLetterReplayed(ctx context.Context, tenantID string, deadLetterID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	for _, d := range r.deadLetters[tenantID] {
+		if d.ID == deadLetterID && d.ReplayedAt == nil {
+			replayedAt := at
+			d.ReplayedAt = &replayedAt
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// SaveMaintenanceWindow stores a maintenance window.
+func (r *Repository) SaveMaintenanceWindow(ctx context.Context, tenantID string, window *domain.MaintenanceWindow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if window.ID == "" || !window.EndsAt.After(window.StartsAt) {
+		return fmt.Errorf("%w: maintenance window ID and a positive duration are required", repository.ErrInvalidInput)
+	}
+
+	stored := *window
+	stored.TenantID = tenantID
+	r.maintenance[tenantKey{tenantID, window.ID}] = &stored
+	return nil
+}
+
+// GetMaintenanceWindow retrieves a maintenance window.
+func (r *Repository) GetMaintenanceWindow(ctx context.Context, tenantID string, windowID string) (*domain.MaintenanceWindow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	window, ok := r.maintenance[tenantKey{tenantID, windowID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *window
+	return &copied, nil
+}
+
+// ListMaintenanceWindows retrieves a tenant's maintenance windows, including
+// past and cancelled ones, latest start first.
+func (r *Repository) ListMaintenanceWindows(ctx context.Context, tenantID string) ([]*domain.MaintenanceWindow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.MaintenanceWindow
+	for key, window := range r.maintenance {
+		if key.tenantID == tenantID {
+			copied := *window
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartsAt.Equal(out[j].StartsAt) {
+			return out[i].StartsAt.After(out[j].StartsAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// CancelMaintenanceWindow records who cancelled a window and when. It fails
+// with repository.ErrNotFound if it doesn't exist or was already cancelled.
+func (r *Repository) CancelMaintenanceWindow(ctx context.Context, tenantID string, windowID string, by string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	window, ok := r.maintenance[tenantKey{tenantID, windowID}]
+	if !ok || window.CancelledAt != nil {
+		return repository.ErrNotFound
+	}
+	cancelledAt := at
+	window.CancelledBy = by
+	window.CancelledAt = &cancelledAt
+	return nil
+}
+
+// ActiveMaintenanceWindow returns the uncancelled window active at t that
+// ends last, or repository.ErrNotFound.
+func (r *Repository) ActiveMaintenanceWindow(ctx context.Context, tenantID string, t time.Time) (*domain.MaintenanceWindow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var active *domain.MaintenanceWindow
+	for key, window := range r.maintenance {
+		if key.tenantID == tenantID && window.Active(t) && (active == nil || window.EndsAt.After(active.EndsAt)) {
+			active = window
+		}
+	}
+	if active == nil {
+		return nil, repository.ErrNotFound
+	}
+	copied := *active
+	return &copied, nil
+}
+
+// edgeKey identifies the edge from a debtor to a creditor within a tenant.
+func edgeKey(tenantID, debtorID, creditorID string) tenantKey {
+	return tenantKey{tenantID, debtorID + "\x00" + creditorID}
+}
+
+// RecordCounterpartyEdge adds a transaction to the edge from its debtor to
+// its creditor.
+func (r *Repository) RecordCounterpartyEdge(ctx context.Context, tenantID string, tx *domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if tx.DebtorID == "" || tx.CreditorID == "" {
+		return fmt.Errorf("%w: debtorID and creditorID are required", repository.ErrInvalidInput)
+	}
+
+	at := tx.Timestamp.UTC()
+	key := edgeKey(tenantID, tx.DebtorID, tx.CreditorID)
+	edge, ok := r.edges[key]
+	if !ok {
+		r.edges[key] = &domain.CounterpartyEdge{
+			TenantID:   tenantID,
+			DebtorID:   tx.DebtorID,
+			CreditorID: tx.CreditorID,
+			Count:      1,
+			Amount:     tx.Amount.Float64(),
+			FirstSeen:  at,
+			LastSeen:   at,
+		}
+		return nil
+	}
+	edge.Count++
+	edge.Amount += tx.Amount.Float64()
+	if at.Before(edge.FirstSeen) {
+		edge.FirstSeen = at
+	}
+	if at.After(edge.LastSeen) {
+		edge.LastSeen = at
+	}
+	return nil
+}
+
+// GetCounterpartyEdge retrieves the edge from a debtor to a creditor.
+func (r *Repository) GetCounterpartyEdge(ctx context.Context, tenantID string, debtorID string, creditorID string) (*domain.CounterpartyEdge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	edge, ok := r.edges[edgeKey(tenantID, debtorID, creditorID)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *edge
+	return &copied, nil
+}
+
+// ListCounterpartyEdges retrieves the edges from and to a party last seen at
+// or after since, latest first.
+func (r *Repository) ListCounterpartyEdges(ctx context.Context, tenantID string, partyID string, since time.Time) ([]*domain.CounterpartyEdge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.CounterpartyEdge
+	for key, edge := range r.edges {
+		if key.tenantID == tenantID && (edge.DebtorID == partyID || edge.CreditorID == partyID) && !edge.LastSeen.Before(since) {
+			copied := *edge
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		if out[i].DebtorID != out[j].DebtorID {
+			return out[i].DebtorID < out[j].DebtorID
+		}
+		return out[i].CreditorID < out[j].CreditorID
+	})
+	return out, nil
+}
+
+// SaveScoringConfig upserts the tenant's scoring config.
+func (r *Repository) SaveScoringConfig(ctx context.Context, tenantID string, cfg *domain.ScoringConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	stored := *cfg
+	stored.TenantID = tenantID
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = r.clock.Now()
+	}
+	r.scoring[tenantID] = &stored
+	return nil
+}
+
+// GetScoringConfig retrieves the tenant's scoring config.
+func (r *Repository) GetScoringConfig(ctx context.Context, tenantID string) (*domain.ScoringConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	cfg, ok := r.scoring[tenantID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *cfg
+	return &copied, nil
+}
+
+// DeleteScoringConfig deletes the tenant's scoring config.
+func (r *Repository) DeleteScoringConfig(ctx context.Context, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	if _, ok := r.scoring[tenantID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.scoring, tenantID)
+	return nil
+}
+
+// SaveRetentionPolicy upserts the tenant's retention policy.
+func (r *Repository) SaveRetentionPolicy(ctx context.Context, tenantID string, policy *domain.RetentionPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	stored := *policy
+	stored.TenantID = tenantID
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = r.clock.Now()
+	}
+	r.retention[tenantID] = &stored
+	return nil
+}
+
+// GetRetentionPolicy retrieves the tenant's retention policy.
+func (r *Repository) GetRetentionPolicy(ctx context.Context, tenantID string) (*domain.RetentionPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	policy, ok := r.retention[tenantID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *policy
+	return &copied, nil
+}
+
+// DeleteRetentionPolicy deletes the tenant's retention policy.
+func (r *Repository) DeleteRetentionPolicy(ctx context.Context, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	if _, ok := r.retention[tenantID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.retention, tenantID)
+	return nil
+}
+
+// SaveNamedList creates or replaces one of the tenant's named lists.
+func (r *Repository) SaveNamedList(ctx context.Context, tenantID string, list *domain.NamedList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if list.Name == "" {
+		return fmt.Errorf("%w: name is required", repository.ErrInvalidInput)
+	}
+
+	stored := *list
+	stored.TenantID = tenantID
+	stored.Values = append([]string{}, list.Values...)
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = r.clock.Now()
+	}
+	r.namedLists[tenantKey{tenantID, list.Name}] = &stored
+	return nil
+}
+
+// GetNamedList retrieves one of the tenant's named lists.
+func (r *Repository) GetNamedList(ctx context.Context, tenantID string, name string) (*domain.NamedList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	list, ok := r.namedLists[tenantKey{tenantID, name}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return copyNamedList(list), nil
+}
+
+// ListNamedLists returns the tenant's named lists by name.
+func (r *Repository) ListNamedLists(ctx context.Context, tenantID string) ([]*domain.NamedList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.NamedList
+	for key, list := range r.namedLists {
+		if key.tenantID == tenantID {
+			out = append(out, copyNamedList(list))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// ListAllNamedLists returns every tenant's named lists, by tenant and name.
+func (r *Repository) ListAllNamedLists(ctx context.Context) ([]*domain.NamedList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var out []*domain.NamedList
+	for _, list := range r.namedLists {
+		out = append(out, copyNamedList(list))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// DeleteNamedList deletes one of the tenant's named lists.
+func (r *Repository) DeleteNamedList(ctx context.Context, tenantID string, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, name}
+	if _, ok := r.namedLists[key]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.namedLists, key)
+	return nil
+}
+
+func copyNamedList(list *domain.NamedList) *domain.NamedList {
+	copied := *list
+	copied.Values = append([]string{}, list.Values...)
+	return &copied
+}
+
+// ClaimReviewItem claims a review queue item, or renews the claimant's own
+// claim. It fails with ErrNotFound if another analyst's claim is active.
+func (r *Repository) ClaimReviewItem(ctx context.Context, tenantID string, claim *domain.ReviewClaim) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if claim.ItemID == "" || claim.ClaimedBy == "" {
+		return fmt.Errorf("%w: item ID and claimant are required", repository.ErrInvalidInput)
+	}
+
+	key := tenantKey{tenantID, claim.ItemID}
+	if held, ok := r.claims[key]; ok && held.ClaimedBy != claim.ClaimedBy && held.Active(claim.ClaimedAt) {
+		return repository.ErrNotFound
+	}
+	stored := *claim
+	stored.TenantID = tenantID
+	r.claims[key] = &stored
+	return nil
+}
+
+// ReleaseReviewItem drops by's claim on a review queue item.
+func (r *Repository) ReleaseReviewItem(ctx context.Context, tenantID string, itemID string, by string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+
+	key := tenantKey{tenantID, itemID}
+	if held, ok := r.claims[key]; !ok || held.ClaimedBy != by {
+		return repository.ErrNotFound
+	}
+	delete(r.claims, key)
+	return nil
+}
+
+// ListReviewClaims returns the tenant's claims active at t, by item ID.
+func (r *Repository) ListReviewClaims(ctx context.Context, tenantID string, at time.Time) ([]*domain.ReviewClaim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	var out []*domain.ReviewClaim
+	for key, claim := range r.claims {
+		if key.tenantID == tenantID && claim.Active(at) {
+			copied := *claim
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ItemID < out[j].ItemID })
+	return out, nil
+}
+
+// copyBaselineStats returns stats with its own counterparty map.
+func copyBaselineStats(stats domain.BaselineStats) domain.BaselineStats {
+	if stats.Counterparties != nil {
+		counterparties := make(map[string]float64, len(stats.Counterparties))
+		for id, share := range stats.Counterparties {
+			counterparties[id] = share
+		}
+		stats.Counterparties = counterparties
+	}
+	return stats
+}
+
+// SaveEntityBaseline creates or replaces an entity's baseline.
+func (r *Repository) SaveEntityBaseline(ctx context.Context, tenantID string, baseline *domain.EntityBaseline) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if baseline.EntityID == "" {
+		return fmt.Errorf("%w: entityID is required", repository.ErrInvalidInput)
+	}
+
+	stored := *baseline
+	stored.TenantID = tenantID
+	stored.Stats = copyBaselineStats(baseline.Stats)
+	stored.Prior = copyBaselineStats(baseline.Prior)
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = r.clock.Now()
+	}
+	r.baselines[tenantKey{tenantID, baseline.EntityID}] = &stored
+	return nil
+}
+
+// GetEntityBaseline retrieves an entity's baseline.
+func (r *Repository) GetEntityBaseline(ctx context.Context, tenantID string, entityID string) (*domain.EntityBaseline, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	baseline, ok := r.baselines[tenantKey{tenantID, entityID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *baseline
+	copied.Stats = copyBaselineStats(baseline.Stats)
+	copied.Prior = copyBaselineStats(baseline.Prior)
+	return &copied, nil
+}
+
+// SaveAccountBalance creates or replaces an account's balance.
+func (r *Repository) SaveAccountBalance(ctx context.Context, tenantID string, balance *domain.AccountBalance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return err
+	}
+	if balance.AccountID == "" {
+		return fmt.Errorf("%w: accountID is required", repository.ErrInvalidInput)
+	}
+
+	stored := *balance
+	stored.TenantID = tenantID
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = r.clock.Now()
+	}
+	r.balances[tenantKey{tenantID, balance.AccountID}] = &stored
+	return nil
+}
+
+// GetAccountBalance retrieves an account's balance.
+func (r *Repository) GetAccountBalance(ctx context.Context, tenantID string, accountID string) (*domain.AccountBalance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.check(tenantID); err != nil {
+		return nil, err
+	}
+
+	balance, ok := r.balances[tenantKey{tenantID, accountID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *balance
+	return &copied, nil
+}
+
+// Ping reports the injected error, if any.
+func (r *Repository) Ping(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close is a no-op; the repository stays usable so tests can inspect it afterwards.
+func (r *Repository) Close() error {
+	return nil
+}
+
+var _ domain.Repository = (*Repository)(nil)
@@ -474,7 +474,9 @@ func TestDifferentCurrencies_RuleFires(t *testing.T) {
 	   SCENARIO: Verify the engine handles different currencies consistently
 
 	   BEHAVIOR:
-	   - Current implementation evaluates RAW amounts without FX conversion
+	   - `amount` is the raw amount, without FX conversion; rules that should
+	     compare amounts across currencies use amount_base, which is only set
+	     when OSPREY_FX_SOURCE is configured
 	   - A €50,000 transaction triggers high-value rule
 	   - But single rule alone doesn't trigger ALRT (needs multiple signals)
 